@@ -0,0 +1,65 @@
+// Command backfill_ratings rates the finished games that were played before
+// ratings existed. Games are replayed in the order they finished, so the
+// resulting ratings match what rating each game at its end would have given.
+//
+// Ratings depend on the order games are rated in, so the command refuses to
+// run once any game has been rated. --reset deletes every rating first and
+// replays all finished games; use it to rerun an interrupted backfill. Stop
+// the servers while it runs, or games they rate meanwhile are rated out of
+// order.
+//
+// Usage:
+//
+//	go run ./cmd/backfill_ratings/ --db postgres://... [--reset]
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"os"
+
+	_ "github.com/lib/pq"
+
+	"github.com/freeeve/polite-betrayal/api/internal/repository/postgres"
+	"github.com/freeeve/polite-betrayal/api/internal/service"
+)
+
+func main() {
+	dbURL := flag.String("db", os.Getenv("DATABASE_URL"), "Postgres connection URL")
+	verbose := flag.Bool("v", false, "Log every game")
+	reset := flag.Bool("reset", false, "Delete all ratings and rate every finished game again")
+	flag.Parse()
+
+	if *dbURL == "" {
+		log.Fatal("--db or DATABASE_URL is required")
+	}
+
+	db, err := postgres.Connect(*dbURL)
+	if err != nil {
+		log.Fatalf("connect to postgres: %v", err)
+	}
+	defer db.Close()
+
+	ratingSvc := service.NewRatingService(postgres.NewGameRepo(db), postgres.NewPhaseRepo(db), postgres.NewRatingRepo(db))
+	processed := 0
+	stats, err := ratingSvc.Backfill(context.Background(), *reset, func(gameID string, rated bool) {
+		processed++
+		switch {
+		case *verbose && rated:
+			log.Printf("rated %s", gameID)
+		case *verbose:
+			log.Printf("skipped %s", gameID)
+		case processed%100 == 0:
+			log.Printf("%d games processed", processed)
+		}
+	})
+	if errors.Is(err, service.ErrRatingsExist) {
+		log.Fatalf("backfill: %v; rerun with --reset to rate every game again in order", err)
+	}
+	if err != nil {
+		log.Fatalf("backfill: %v (rated %d games before failing; rerun with --reset)", err, stats.Rated)
+	}
+	log.Printf("done: rated %d games, skipped %d", stats.Rated, stats.Skipped)
+}
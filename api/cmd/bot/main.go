@@ -16,7 +16,8 @@ import (
 
 func main() {
 	url := flag.String("url", "http://localhost:3009", "server base URL")
-	strategyName := flag.String("strategy", "random", "bot strategy (hold, random)")
+	strategyName := flag.String("strategy", "random", "bot strategy: scripted or any registered strategy (see GET /bot-strategies)")
+	scriptPath := flag.String("script", "", "order script for the scripted strategy (JSON: phase -> power -> DSON)")
 	turnDuration := flag.Duration("turn-duration", 10*time.Second, "turn duration for the game")
 	debug := flag.Bool("debug", false, "enable debug logging")
 	flag.Parse()
@@ -28,12 +29,22 @@ func main() {
 		zerolog.SetGlobalLevel(zerolog.InfoLevel)
 	}
 
+	if err := bot.RegisterStrategyVariants(os.Getenv("BOT_STRATEGIES")); err != nil {
+		log.Fatal().Err(err).Msg("Invalid BOT_STRATEGIES")
+	}
 	var strategy bot.Strategy
-	switch *strategyName {
-	case "hold":
-		strategy = bot.HoldStrategy{}
-	default:
-		strategy = bot.RandomStrategy{}
+	if *strategyName == "scripted" {
+		scripted, err := bot.LoadScriptedStrategy(*scriptPath)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to load order script")
+		}
+		strategy = scripted
+	} else {
+		reg, ok := bot.LookupStrategy(*strategyName)
+		if !ok {
+			log.Fatal().Str("strategy", *strategyName).Msg("Unknown strategy")
+		}
+		strategy = reg.New(nil)
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/freeeve/polite-betrayal/api/internal/bot"
+	"github.com/freeeve/polite-betrayal/api/internal/bot/neural"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// benchCase is a position, the power a strategy plays in it, and the fixed
+// orders of every other power.
+type benchCase struct {
+	position
+	Power    diplomacy.Power
+	Opponent []diplomacy.Order
+	Before   float64 // the power's evaluation of the position
+}
+
+// report summarizes one strategy's run over the corpus.
+type report struct {
+	Strategy  string  `json:"strategy"`
+	Positions int     `json:"positions"`
+	Errors    int     `json:"errors"`
+	P50ms     float64 `json:"p50_ms"`
+	P90ms     float64 `json:"p90_ms"`
+	P99ms     float64 `json:"p99_ms"`
+	MaxMs     float64 `json:"max_ms"`
+	MeanMs    float64 `json:"mean_ms"`
+	EvalDelta float64 `json:"eval_delta"` // mean change in the power's evaluation after its orders resolve
+	Invalid   float64 `json:"invalid"`    // mean orders per position voided as invalid
+}
+
+// newCases picks the power each position is played for, rotating through
+// the powers with units, and fixes the other powers' orders by running the
+// baseline strategy once so every benchmarked strategy faces the same moves.
+func newCases(positions []position, baseline bot.Strategy, m *diplomacy.DiplomacyMap) []benchCase {
+	cases := make([]benchCase, 0, len(positions))
+	for i, p := range positions {
+		var alive []diplomacy.Power
+		for _, pw := range diplomacy.AllPowers() {
+			if p.State.UnitCount(pw) > 0 {
+				alive = append(alive, pw)
+			}
+		}
+		if len(alive) == 0 {
+			continue
+		}
+		c := benchCase{position: p, Power: alive[i%len(alive)]}
+		for _, pw := range alive {
+			if pw != c.Power {
+				c.Opponent = append(c.Opponent, bot.OrderInputsToOrders(baseline.GenerateMovementOrders(p.State.Clone(), pw, m), pw)...)
+			}
+		}
+		c.Before = neural.Evaluate(c.Power, p.State, m)
+		cases = append(cases, c)
+	}
+	return cases
+}
+
+// benchStrategy runs the strategy on every case, timing each call, and
+// scores its orders by resolving them against the opponents' orders. A call
+// taking longer than timeout is cancelled if the strategy supports it.
+func benchStrategy(ctx context.Context, name string, s bot.Strategy, cases []benchCase, m *diplomacy.DiplomacyMap, timeout time.Duration) report {
+	r := report{Strategy: name, Positions: len(cases)}
+	latencies := make([]time.Duration, 0, len(cases))
+	var evalSum float64
+	var invalid int
+	for _, c := range cases {
+		callCtx, cancel := context.WithTimeout(ctx, timeout)
+		gc := &bot.GameContext{State: c.State.Clone(), Power: c.Power, Map: m}
+		start := time.Now()
+		inputs, err := bot.GenerateOrders(callCtx, s, gc)
+		elapsed := time.Since(start)
+		cancel()
+		if err != nil {
+			r.Errors++
+			continue
+		}
+		latencies = append(latencies, elapsed)
+
+		delta, voided := score(c, bot.OrderInputsToOrders(inputs, c.Power), m)
+		evalSum += delta
+		invalid += voided
+	}
+	if n := len(latencies); n > 0 {
+		slices.Sort(latencies)
+		var total time.Duration
+		for _, d := range latencies {
+			total += d
+		}
+		r.P50ms = ms(percentile(latencies, 50))
+		r.P90ms = ms(percentile(latencies, 90))
+		r.P99ms = ms(percentile(latencies, 99))
+		r.MaxMs = ms(latencies[n-1])
+		r.MeanMs = ms(total / time.Duration(n))
+		r.EvalDelta = round(evalSum / float64(n))
+		r.Invalid = round(float64(invalid) / float64(n))
+	}
+	return r
+}
+
+// score resolves the power's orders against the opponents' and returns the
+// change in the power's evaluation and how many of its orders were invalid.
+func score(c benchCase, orders []diplomacy.Order, m *diplomacy.DiplomacyMap) (float64, int) {
+	gs := c.State.Clone()
+	all := append(slices.Clone(c.Opponent), orders...)
+	validated, voided := diplomacy.ValidateAndDefaultOrders(all, gs, m)
+	n := 0
+	for _, v := range voided {
+		if v.Order.Power == c.Power {
+			n++
+		}
+	}
+	results, dislodged := diplomacy.ResolveOrders(validated, gs, m)
+	diplomacy.ApplyResolution(gs, m, results, dislodged)
+	return neural.Evaluate(c.Power, gs, m) - c.Before, n
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[max(rank-1, 0)]
+}
+
+func ms(d time.Duration) float64 {
+	return round(float64(d) / float64(time.Millisecond))
+}
+
+func round(f float64) float64 {
+	return math.Round(f*100) / 100
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/freeeve/polite-betrayal/api/internal/bot"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+func TestReadCorpus(t *testing.T) {
+	initial := diplomacy.EncodeDFEN(diplomacy.NewInitialState())
+	input := "# corpus\n" + initial + "\n\n" +
+		`{"game_id":1,"phases":[{"dfen":"` + initial + `"},{"dfen":"` + initial + `"}]}` + "\n"
+	positions, err := readCorpus(strings.NewReader(input), "test")
+	if err != nil {
+		t.Fatalf("readCorpus: %v", err)
+	}
+	if len(positions) != 3 {
+		t.Fatalf("expected a DFEN line and two record phases, got %d positions", len(positions))
+	}
+	if positions[0].Source != "test:2" || positions[2].Source != "test:4#1" {
+		t.Errorf("unexpected sources %q, %q", positions[0].Source, positions[2].Source)
+	}
+
+	// Duplicates collapse to one position.
+	if got := selectPositions(positions, 0, 1); len(got) != 1 {
+		t.Errorf("expected duplicates removed, got %d", len(got))
+	}
+
+	if _, err := readCorpus(strings.NewReader("not a dfen\n"), "bad"); err == nil {
+		t.Error("expected an error for a malformed line")
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	var sorted []time.Duration
+	for i := 1; i <= 100; i++ {
+		sorted = append(sorted, time.Duration(i)*time.Millisecond)
+	}
+	for p, want := range map[float64]time.Duration{50: 50 * time.Millisecond, 99: 99 * time.Millisecond, 100: 100 * time.Millisecond} {
+		if got := percentile(sorted, p); got != want {
+			t.Errorf("p%v: got %v, want %v", p, got, want)
+		}
+	}
+	if got := percentile(sorted[:1], 90); got != time.Millisecond {
+		t.Errorf("single sample: got %v", got)
+	}
+}
+
+// badStrategy orders every unit to a province it can't reach.
+type badStrategy struct{ bot.HoldStrategy }
+
+func (badStrategy) GenerateMovementOrders(gs *diplomacy.GameState, power diplomacy.Power, _ *diplomacy.DiplomacyMap) []bot.OrderInput {
+	var orders []bot.OrderInput
+	for _, u := range gs.UnitsOf(power) {
+		orders = append(orders, bot.OrderInput{UnitType: u.Type.String(), Location: u.Province, OrderType: "move", Target: "mos"})
+	}
+	return orders
+}
+
+func TestBenchStrategy(t *testing.T) {
+	m := diplomacy.StandardMap()
+	positions := []position{{Source: "initial", State: diplomacy.NewInitialState()}}
+	cases := newCases(positions, bot.HoldStrategy{}, m)
+	if len(cases) != 1 || cases[0].Power != diplomacy.AllPowers()[0] {
+		t.Fatalf("expected one case for the first power, got %+v", cases)
+	}
+
+	hold := benchStrategy(context.Background(), "hold", bot.HoldStrategy{}, cases, m, time.Second)
+	if hold.Positions != 1 || hold.Errors != 0 || hold.EvalDelta != 0 || hold.Invalid != 0 {
+		t.Errorf("expected holds against holds to change nothing, got %+v", hold)
+	}
+
+	bad := benchStrategy(context.Background(), "bad", badStrategy{}, cases, m, time.Second)
+	if bad.Invalid != 3 {
+		t.Errorf("expected all three of Austria's orders voided, got %+v", bad)
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/internal/repository"
+	"github.com/freeeve/polite-betrayal/api/internal/service"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// position is one benchmark position and where it came from.
+type position struct {
+	Source string // "file:line" or "game-id/phase-id"
+	State  *diplomacy.GameState
+}
+
+// readCorpus reads positions from r, one per line: either a DFEN or a
+// selfplay JSONL record (as written by cmd/selfplay and cmd/export_games),
+// whose phases each become a position. Blank lines and # comments are
+// skipped.
+func readCorpus(r io.Reader, name string) ([]position, error) {
+	var positions []position
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		src := fmt.Sprintf("%s:%d", name, n)
+		if !strings.HasPrefix(line, "{") {
+			gs, err := diplomacy.DecodeDFEN(line)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", src, err)
+			}
+			positions = append(positions, position{Source: src, State: gs})
+			continue
+		}
+		var rec struct {
+			Phases []struct {
+				DFEN string `json:"dfen"`
+			} `json:"phases"`
+		}
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			return nil, fmt.Errorf("%s: %w", src, err)
+		}
+		for i, ph := range rec.Phases {
+			gs, err := diplomacy.DecodeDFEN(ph.DFEN)
+			if err != nil {
+				return nil, fmt.Errorf("%s phase %d: %w", src, i, err)
+			}
+			positions = append(positions, position{Source: fmt.Sprintf("%s#%d", src, i), State: gs})
+		}
+	}
+	return positions, sc.Err()
+}
+
+// loadGames reads the starting position of every phase of the most recently
+// finished games, at most limit of them (0 for all).
+func loadGames(ctx context.Context, gameRepo repository.GameRepository, phaseRepo repository.PhaseRepository, limit int) ([]position, error) {
+	var games []model.Game
+	err := service.EachFinishedGame(ctx, gameRepo, func(g model.Game) bool {
+		games = append(games, g)
+		return limit <= 0 || len(games) < limit
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list finished games: %w", err)
+	}
+	var positions []position
+	for _, g := range games {
+		phases, err := phaseRepo.ListPhases(ctx, g.ID)
+		if err != nil {
+			return nil, fmt.Errorf("list phases of %s: %w", g.ID, err)
+		}
+		for _, ph := range phases {
+			var gs diplomacy.GameState
+			if err := json.Unmarshal(ph.StateBefore, &gs); err != nil {
+				return nil, fmt.Errorf("phase %s: %w", ph.ID, err)
+			}
+			positions = append(positions, position{Source: g.ID + "/" + ph.ID, State: &gs})
+		}
+	}
+	return positions, nil
+}
+
+// selectPositions keeps the distinct movement positions with units on the
+// board and, if there are more than limit (0 for no limit), samples limit of
+// them with the given seed so runs compare like with like.
+func selectPositions(all []position, limit int, seed int64) []position {
+	seen := make(map[string]bool, len(all))
+	var keep []position
+	for _, p := range all {
+		if p.State.Phase != diplomacy.PhaseMovement || len(p.State.Units) == 0 {
+			continue
+		}
+		key := diplomacy.EncodeDFEN(p.State)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		keep = append(keep, p)
+	}
+	if limit > 0 && len(keep) > limit {
+		rng := rand.New(rand.NewSource(seed))
+		rng.Shuffle(len(keep), func(i, j int) { keep[i], keep[j] = keep[j], keep[i] })
+		keep = keep[:limit]
+	}
+	return keep
+}
@@ -0,0 +1,153 @@
+// Command botbench measures bot strategies on a fixed corpus of positions,
+// so a change to strategy code can be checked for latency and play-quality
+// regressions with numbers instead of impressions.
+//
+// Usage:
+//
+//	go run ./cmd/botbench/ -corpus games.jsonl -strategies easy,medium,hard
+//	go run ./cmd/botbench/ -db postgres://... -games 20 -positions 200 -json
+//
+// The corpus is either a file of DFENs or selfplay JSONL (one position per
+// line or per recorded phase), or the phases of recently finished games in
+// the database. Only movement positions are used. In each position one
+// power, rotating through those with units, is played by the strategy under
+// test while the others play fixed orders from the -baseline strategy.
+//
+// For each strategy it reports latency percentiles of its order generation
+// and two order-quality proxies: the mean change in the power's heuristic
+// evaluation once its orders resolve, and the mean number of its orders
+// voided as invalid. Any registered strategy, including external engines
+// and BOT_STRATEGIES variants, can be benchmarked.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/freeeve/polite-betrayal/api/internal/bot"
+	"github.com/freeeve/polite-betrayal/api/internal/repository/postgres"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+func main() {
+	log.Logger = zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr}).With().Timestamp().Logger()
+
+	corpus := flag.String("corpus", "", "File of DFENs or selfplay JSONL records")
+	dbURL := flag.String("db", "", "Read positions from finished games in this database (or use DATABASE_URL env)")
+	games := flag.Int("games", 10, "Most recently finished games to read with -db (0 = all)")
+	limit := flag.Int("positions", 100, "Positions to sample from the corpus (0 = all)")
+	strategies := flag.String("strategies", "easy,medium,hard", "Comma-separated strategies to benchmark")
+	baseline := flag.String("baseline", "easy", "Strategy playing the other powers")
+	timeout := flag.Duration("timeout", 30*time.Second, "Cancel a strategy call after this long")
+	seed := flag.Int64("seed", 1, "Seed for position sampling and bot randomness")
+	jsonOut := flag.Bool("json", false, "Output results as JSON")
+	flag.Parse()
+
+	if err := bot.RegisterStrategyVariants(os.Getenv("BOT_STRATEGIES")); err != nil {
+		log.Fatal().Err(err).Msg("Invalid BOT_STRATEGIES")
+	}
+	names := strings.Split(*strategies, ",")
+	for _, name := range append(names, *baseline) {
+		if _, ok := bot.LookupStrategy(name); !ok {
+			log.Fatal().Str("strategy", name).Msg("Unknown strategy")
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sig
+		log.Info().Msg("Shutting down...")
+		cancel()
+	}()
+
+	all, err := loadPositions(ctx, *corpus, *dbURL, *games)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to load positions")
+	}
+	m := diplomacy.StandardMap()
+	bot.SeedBotRng(*seed)
+	cases := newCases(selectPositions(all, *limit, *seed), bot.NewStrategy(*baseline, nil), m)
+	if len(cases) == 0 {
+		log.Fatal().Int("read", len(all)).Msg("No movement positions in the corpus")
+	}
+	log.Info().Int("read", len(all)).Int("positions", len(cases)).Msg("Corpus loaded")
+
+	var reports []report
+	for _, name := range names {
+		if ctx.Err() != nil {
+			break
+		}
+		s := bot.NewStrategy(name, nil)
+		// Warm up outside the timings, so model loading and other one-off
+		// setup isn't counted against the first position.
+		bot.GenerateOrders(ctx, s, &bot.GameContext{State: cases[0].State.Clone(), Power: cases[0].Power, Map: m})
+
+		bot.SeedBotRng(*seed)
+		r := benchStrategy(ctx, name, s, cases, m, *timeout)
+		log.Info().Str("strategy", name).Float64("p50_ms", r.P50ms).Float64("eval_delta", r.EvalDelta).Msg("Strategy benchmarked")
+		reports = append(reports, r)
+		if c, ok := s.(io.Closer); ok {
+			c.Close()
+		}
+	}
+
+	if *jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(struct {
+			Positions int      `json:"positions"`
+			Baseline  string   `json:"baseline"`
+			Results   []report `json:"results"`
+		}{len(cases), *baseline, reports})
+		return
+	}
+	printTable(reports, len(cases), *baseline)
+}
+
+// loadPositions reads the corpus file, or the database if no file is given.
+func loadPositions(ctx context.Context, corpus, dbURL string, games int) ([]position, error) {
+	if corpus != "" {
+		f, err := os.Open(corpus)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return readCorpus(f, corpus)
+	}
+	if dbURL == "" {
+		dbURL = os.Getenv("DATABASE_URL")
+	}
+	if dbURL == "" {
+		return nil, fmt.Errorf("-corpus, -db or DATABASE_URL is required")
+	}
+	db, err := postgres.Connect(dbURL)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	return loadGames(ctx, postgres.NewGameRepo(db), postgres.NewPhaseRepo(db), games)
+}
+
+func printTable(reports []report, positions int, baseline string) {
+	fmt.Printf("\nStrategy latency and quality (%d positions, others play %s):\n", positions, baseline)
+	fmt.Printf("  %-16s %9s %9s %9s %9s %9s %8s %8s %6s\n",
+		"strategy", "p50 ms", "p90 ms", "p99 ms", "max ms", "mean ms", "Δeval", "invalid", "errors")
+	for _, r := range reports {
+		fmt.Printf("  %-16s %9.2f %9.2f %9.2f %9.2f %9.2f %+8.2f %8.2f %6d\n",
+			r.Strategy, r.P50ms, r.P90ms, r.P99ms, r.MaxMs, r.MeanMs, r.EvalDelta, r.Invalid, r.Errors)
+	}
+}
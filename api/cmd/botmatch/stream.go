@@ -0,0 +1,239 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/freeeve/polite-betrayal/api/internal/bot"
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// streamPollInterval is how often a streamed game is checked for completion.
+const streamPollInterval = 2 * time.Second
+
+// streamClient drives games through a running server's REST API, so the
+// server resolves every phase and spectators receive the usual WebSocket
+// events.
+type streamClient struct {
+	baseURL string // server root, e.g. http://localhost:8009
+	token   string
+	http    *http.Client
+}
+
+// newStreamClient creates a client authenticated with token, or, if token is
+// empty, with a dev-login token for devUser (requires DEV_MODE on the server).
+func newStreamClient(ctx context.Context, baseURL, token, devUser string) (*streamClient, error) {
+	c := &streamClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		http:    &http.Client{Timeout: 30 * time.Second},
+	}
+	if c.token != "" {
+		return c, nil
+	}
+	if devUser == "" {
+		return nil, fmt.Errorf("--token or --dev-user is required with --stream")
+	}
+	var tokens struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/auth/dev?name="+url.QueryEscape(devUser), nil, &tokens); err != nil {
+		return nil, fmt.Errorf("dev login: %w", err)
+	}
+	c.token = tokens.AccessToken
+	return c, nil
+}
+
+// api calls an /api/v1 endpoint.
+func (c *streamClient) api(ctx context.Context, method, path string, body, out any) error {
+	return c.do(ctx, method, "/api/v1"+path, body, out)
+}
+
+// do sends a JSON request and decodes a JSON response into out, if non-nil.
+// Non-2xx responses are returned as errors carrying the server's message.
+func (c *streamClient) do(ctx context.Context, method, path string, body, out any) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("marshal request: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		return fmt.Errorf("%s %s: %d %s", method, path, resp.StatusCode, apiErr.Error)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode %s %s: %w", method, path, err)
+	}
+	return nil
+}
+
+// runStreamGame creates a bot-only game on the server with each power played
+// at its configured difficulty, starts it, and waits for the server to finish
+// it. Games still running after maxYear are stopped and count as draws.
+func runStreamGame(ctx context.Context, c *streamClient, name string, powers, personalities map[diplomacy.Power]string, maxYear int) (*bot.ArenaResult, error) {
+	var game model.Game
+	err := c.api(ctx, http.MethodPost, "/games", map[string]any{
+		"name":             name,
+		"bot_only":         true,
+		"power_assignment": "manual",
+	}, &game)
+	if err != nil {
+		return nil, fmt.Errorf("create game: %w", err)
+	}
+	if len(game.Players) != len(diplomacy.AllPowers()) {
+		return nil, fmt.Errorf("create game: got %d players, want %d", len(game.Players), len(diplomacy.AllPowers()))
+	}
+
+	for i, p := range diplomacy.AllPowers() {
+		userID := game.Players[i].UserID
+		diff, ok := powers[p]
+		if !ok {
+			diff = "easy"
+		}
+		if err := c.api(ctx, http.MethodPatch, "/games/"+game.ID+"/players/"+userID+"/power", map[string]string{"power": string(p)}, nil); err != nil {
+			return nil, fmt.Errorf("assign %s: %w", p, err)
+		}
+		if err := c.api(ctx, http.MethodPatch, "/games/"+game.ID+"/players/"+userID+"/bot-difficulty", map[string]string{"difficulty": diff}, nil); err != nil {
+			return nil, fmt.Errorf("set %s difficulty: %w", p, err)
+		}
+		if spec, ok := personalities[p]; ok {
+			personality, err := bot.ParsePersonality(spec)
+			if err != nil {
+				return nil, err
+			}
+			if err := c.api(ctx, http.MethodPatch, "/games/"+game.ID+"/players/"+userID+"/bot-personality", personality, nil); err != nil {
+				return nil, fmt.Errorf("set %s personality: %w", p, err)
+			}
+		}
+	}
+
+	if err := c.api(ctx, http.MethodPost, "/games/"+game.ID+"/start", nil, nil); err != nil {
+		return nil, fmt.Errorf("start game: %w", err)
+	}
+	log.Info().Str("gameId", game.ID).Str("name", name).Msg("Streaming game")
+
+	for game.Status != "finished" {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(streamPollInterval):
+		}
+
+		if err := c.api(ctx, http.MethodGet, "/games/"+game.ID, nil, &game); err != nil {
+			return nil, fmt.Errorf("poll game: %w", err)
+		}
+		if game.Status == "finished" {
+			break
+		}
+
+		var phase model.Phase
+		if err := c.api(ctx, http.MethodGet, "/games/"+game.ID+"/phases/current", nil, &phase); err != nil {
+			continue // between phases; try again next poll
+		}
+		if phase.Year > maxYear {
+			if err := c.api(ctx, http.MethodPost, "/games/"+game.ID+"/stop", nil, &game); err != nil {
+				return nil, fmt.Errorf("stop game at year limit: %w", err)
+			}
+		}
+	}
+
+	var phases []model.Phase
+	if err := c.api(ctx, http.MethodGet, "/games/"+game.ID+"/phases", nil, &phases); err != nil {
+		return nil, fmt.Errorf("list phases: %w", err)
+	}
+	return streamResult(game, phases)
+}
+
+// streamResult summarizes a finished streamed game from its phase history.
+func streamResult(game model.Game, phases []model.Phase) (*bot.ArenaResult, error) {
+	result := &bot.ArenaResult{
+		GameID:      game.ID,
+		Winner:      game.Winner,
+		TotalPhases: len(phases),
+		SCCounts:    make(map[string]int),
+		SCTimeline:  make(map[string][]int),
+	}
+	if len(phases) == 0 {
+		return result, nil
+	}
+
+	// SC ownership changes after each Fall; it is first visible in the state
+	// the following phase starts from.
+	for i := 1; i < len(phases); i++ {
+		prev, next := phases[i-1], phases[i]
+		if prev.Season != "fall" || prev.PhaseType == "build" {
+			continue
+		}
+		if next.Season == "fall" && next.PhaseType == "retreat" {
+			continue
+		}
+		gs, err := decodeState(next.StateBefore)
+		if err != nil {
+			return nil, err
+		}
+		result.TimelineYears = append(result.TimelineYears, prev.Year)
+		for _, p := range diplomacy.AllPowers() {
+			result.SCTimeline[string(p)] = append(result.SCTimeline[string(p)], gs.SupplyCenterCount(p))
+		}
+	}
+
+	final := phases[len(phases)-1]
+	raw := final.StateAfter
+	if len(raw) == 0 {
+		raw = final.StateBefore
+	}
+	gs, err := decodeState(raw)
+	if err != nil {
+		return nil, err
+	}
+	result.FinalYear = final.Year
+	result.FinalSeason = final.Season
+	for _, p := range diplomacy.AllPowers() {
+		result.SCCounts[string(p)] = gs.SupplyCenterCount(p)
+	}
+	return result, nil
+}
+
+func decodeState(raw json.RawMessage) (*diplomacy.GameState, error) {
+	var gs diplomacy.GameState
+	if err := json.Unmarshal(raw, &gs); err != nil {
+		return nil, fmt.Errorf("unmarshal game state: %w", err)
+	}
+	return &gs, nil
+}
@@ -0,0 +1,564 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/freeeve/polite-betrayal/api/internal/bot"
+	"github.com/freeeve/polite-betrayal/api/internal/repository/postgres"
+	"github.com/freeeve/polite-betrayal/api/internal/service"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+const tournamentUsage = `Usage: botmatch tournament -roster hard,medium,easy [flags]
+
+Plays every pairing of roster entries (round-robin) or pairs entries on
+their running score (swiss). Each pairing is a match of -games games in
+which the two entries split the seven powers; each odd game swaps the sides
+of the game before it, so an even number of games plays every power from
+both sides equally often, and the split rotates over 14 games. A solo wins the game for its side, anything
+else is a draw worth half a point to each side.
+
+Roster entries are strategy names, optionally with a personality for all of
+the entry's powers, e.g. hard@aggressive or medium@loyalty:0.5/aggression:-0.5.
+
+Flags:
+`
+
+// Tournament formats accepted by -format.
+const (
+	formatRoundRobin = "round-robin"
+	formatSwiss      = "swiss"
+)
+
+// tournamentEntry is one roster entry: a strategy, optionally played with a
+// personality.
+type tournamentEntry struct {
+	Name        string `json:"name"`
+	Strategy    string `json:"strategy"`
+	Personality string `json:"personality,omitempty"`
+}
+
+// parseRoster parses a comma-separated list of strategy[@personality]
+// entries. Entries must be distinct and there must be at least two.
+func parseRoster(s string) ([]tournamentEntry, error) {
+	var entries []tournamentEntry
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		strategy, pers, _ := strings.Cut(part, "@")
+		if _, ok := bot.LookupStrategy(strategy); !ok {
+			return nil, fmt.Errorf("roster entry %q: unknown strategy %q", part, strategy)
+		}
+		if pers != "" {
+			if _, err := bot.ParsePersonality(pers); err != nil {
+				return nil, fmt.Errorf("roster entry %q: %w", part, err)
+			}
+		}
+		if seen[part] {
+			return nil, fmt.Errorf("roster entry %q listed twice", part)
+		}
+		seen[part] = true
+		entries = append(entries, tournamentEntry{Name: part, Strategy: strategy, Personality: pers})
+	}
+	if len(entries) < 2 {
+		return nil, fmt.Errorf("roster needs at least two entries, got %d", len(entries))
+	}
+	return entries, nil
+}
+
+// pairing is a match between roster entries A and B, by index.
+type pairing struct {
+	A, B int
+}
+
+// roundRobinPairings returns every pairing of n entries once.
+func roundRobinPairings(n int) []pairing {
+	var out []pairing
+	for a := 0; a < n; a++ {
+		for b := a + 1; b < n; b++ {
+			out = append(out, pairing{A: a, B: b})
+		}
+	}
+	return out
+}
+
+// swissRounds is the default number of Swiss rounds: enough for a single
+// entry to finish ahead of everyone else.
+func swissRounds(n int) int {
+	return max(1, int(math.Ceil(math.Log2(float64(n)))))
+}
+
+// swissPairings pairs entries for the next Swiss round. Entries are ranked by
+// points (ties keep roster order) and each unpaired entry meets the
+// highest-ranked unpaired entry it hasn't played yet, or the next one down if
+// it has played them all. With an odd count, the lowest-ranked entry with the
+// fewest byes sits out; bye is -1 otherwise.
+func swissPairings(points []float64, byes []int, played map[pairing]bool) (pairs []pairing, bye int) {
+	order := make([]int, len(points))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return points[order[i]] > points[order[j]] })
+
+	bye = -1
+	if len(order)%2 == 1 {
+		for i := len(order) - 1; i >= 0; i-- {
+			if bye < 0 || byes[order[i]] < byes[bye] {
+				bye = order[i]
+			}
+		}
+	}
+
+	paired := make(map[int]bool)
+	for i, a := range order {
+		if a == bye || paired[a] {
+			continue
+		}
+		opp := -1
+		for _, b := range order[i+1:] {
+			if b == bye || paired[b] {
+				continue
+			}
+			if opp < 0 {
+				opp = b
+			}
+			if !played[newPairing(a, b)] {
+				opp = b
+				break
+			}
+		}
+		if opp < 0 {
+			break
+		}
+		paired[a], paired[opp] = true, true
+		pairs = append(pairs, pairing{A: a, B: opp})
+	}
+	return pairs, bye
+}
+
+// newPairing returns the pairing of a and b with the lower index first, so it
+// can be used as a key regardless of order.
+func newPairing(a, b int) pairing {
+	if a > b {
+		a, b = b, a
+	}
+	return pairing{A: a, B: b}
+}
+
+// seating assigns the seven powers to the sides of game k of a match: side 0
+// (entry A) or 1 (entry B). The sides alternate around the board starting
+// at power k/2, and odd games mirror the game before them, so every two
+// games seat each power once on each side and give each side the four
+// powers once. Fourteen games rotate the split through every power.
+func seating(k int) map[diplomacy.Power]int {
+	powers := diplomacy.AllPowers()
+	out := make(map[diplomacy.Power]int, len(powers))
+	start := k / 2
+	for i := range powers {
+		out[powers[(i+start)%len(powers)]] = (i + k) % 2
+	}
+	return out
+}
+
+// tournamentGame is one game of a match and its result from the sides'
+// point of view.
+type tournamentGame struct {
+	Round   int               `json:"round"`
+	A       string            `json:"a"`
+	B       string            `json:"b"`
+	Game    int               `json:"game"`
+	GameID  string            `json:"game_id,omitempty"`
+	Seats   map[string]string `json:"seats"` // power -> entry
+	Winner  string            `json:"winner,omitempty"`
+	Year    int               `json:"final_year"`
+	PointsA float64           `json:"points_a"`
+	PointsB float64           `json:"points_b"`
+	SCsA    int               `json:"scs_a"`
+	SCsB    int               `json:"scs_b"`
+
+	a, b  int
+	sides map[diplomacy.Power]int
+}
+
+// standing is an entry's tournament record.
+type standing struct {
+	Entry   string  `json:"entry"`
+	Games   int     `json:"games"`
+	Wins    int     `json:"wins"`
+	Draws   int     `json:"draws"`
+	Losses  int     `json:"losses"`
+	Byes    int     `json:"byes,omitempty"`
+	Points  float64 `json:"points"`
+	Centers int     `json:"centers"` // held by the entry's powers at the end of its games
+}
+
+// tournament accumulates results into standings and a cross-table.
+type tournament struct {
+	entries   []tournamentEntry
+	standings []standing
+	cross     [][]float64 // cross[i][j]: points entry i scored against entry j
+	played    map[pairing]bool
+	games     []tournamentGame
+}
+
+func newTournament(entries []tournamentEntry) *tournament {
+	t := &tournament{
+		entries:   entries,
+		standings: make([]standing, len(entries)),
+		cross:     make([][]float64, len(entries)),
+		played:    make(map[pairing]bool),
+	}
+	for i, e := range entries {
+		t.standings[i].Entry = e.Name
+		t.cross[i] = make([]float64, len(entries))
+	}
+	return t
+}
+
+// points returns each entry's points so far, by roster index.
+func (t *tournament) points() []float64 {
+	out := make([]float64, len(t.standings))
+	for i, s := range t.standings {
+		out[i] = s.Points
+	}
+	return out
+}
+
+// byes returns each entry's byes so far, by roster index.
+func (t *tournament) byes() []int {
+	out := make([]int, len(t.standings))
+	for i, s := range t.standings {
+		out[i] = s.Byes
+	}
+	return out
+}
+
+// recordBye scores a bye as a drawn match of the given number of games.
+func (t *tournament) recordBye(entry, games int) {
+	t.standings[entry].Byes++
+	t.standings[entry].Points += 0.5 * float64(games)
+}
+
+// record scores a finished game of a match.
+func (t *tournament) record(g tournamentGame, r *bot.ArenaResult) {
+	a, b := g.a, g.b
+	t.played[newPairing(a, b)] = true
+	for p, side := range g.sides {
+		if side == 0 {
+			g.SCsA += r.SCCounts[string(p)]
+		} else {
+			g.SCsB += r.SCCounts[string(p)]
+		}
+	}
+	g.GameID, g.Winner, g.Year = r.GameID, r.Winner, r.FinalYear
+
+	sa, sb := &t.standings[a], &t.standings[b]
+	sa.Games++
+	sb.Games++
+	sa.Centers += g.SCsA
+	sb.Centers += g.SCsB
+	if side, ok := g.sides[diplomacy.Power(r.Winner)]; ok {
+		winner, loser := sa, sb
+		g.PointsA = 1
+		if side == 1 {
+			winner, loser = sb, sa
+			g.PointsA, g.PointsB = 0, 1
+		}
+		winner.Wins++
+		loser.Losses++
+	} else {
+		sa.Draws++
+		sb.Draws++
+		g.PointsA, g.PointsB = 0.5, 0.5
+	}
+	sa.Points += g.PointsA
+	sb.Points += g.PointsB
+	t.cross[a][b] += g.PointsA
+	t.cross[b][a] += g.PointsB
+	t.games = append(t.games, g)
+}
+
+// ranked returns the standings ordered by points, then centers.
+func (t *tournament) ranked() []standing {
+	out := append([]standing(nil), t.standings...)
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Points != out[j].Points {
+			return out[i].Points > out[j].Points
+		}
+		return out[i].Centers > out[j].Centers
+	})
+	return out
+}
+
+// newGame sets up game k of round's match between entries a and b.
+func (t *tournament) newGame(round, a, b, k int) tournamentGame {
+	g := tournamentGame{
+		Round: round,
+		A:     t.entries[a].Name,
+		B:     t.entries[b].Name,
+		Game:  k + 1,
+		Seats: make(map[string]string),
+		a:     a,
+		b:     b,
+		sides: seating(k),
+	}
+	for p, side := range g.sides {
+		g.Seats[string(p)] = t.entries[[]int{a, b}[side]].Name
+	}
+	return g
+}
+
+// arenaConfig returns the strategies and personalities that play g.
+func (t *tournament) arenaConfig(g tournamentGame) (map[diplomacy.Power]string, map[diplomacy.Power]string) {
+	powers := make(map[diplomacy.Power]string)
+	personalities := make(map[diplomacy.Power]string)
+	for p, side := range g.sides {
+		e := t.entries[[]int{g.a, g.b}[side]]
+		powers[p] = e.Strategy
+		if e.Personality != "" {
+			personalities[p] = e.Personality
+		}
+	}
+	return powers, personalities
+}
+
+// runTournament implements the tournament subcommand.
+func runTournament(args []string) {
+	fs := flag.NewFlagSet("tournament", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), tournamentUsage)
+		fs.PrintDefaults()
+	}
+	roster := fs.String("roster", "", "Comma-separated roster of strategy[@personality] entries")
+	format := fs.String("format", formatRoundRobin, "Pairing format: round-robin or swiss")
+	rounds := fs.Int("rounds", 0, "Swiss rounds (0 = log2 of the roster size, rounded up)")
+	games := fs.Int("games", 2, "Games per pairing; even counts play every power from both sides equally")
+	workers := fs.Int("workers", 1, "Concurrency (parallel games)")
+	dbURL := fs.String("db", "", "Database URL (or use DATABASE_URL env)")
+	maxYear := fs.Int("max-year", 1920, "Max year before draw")
+	victory := fs.Int("victory-scs", 18, "Supply centers needed for a solo")
+	seed := fs.Int64("seed", 0, "Base seed (0 = random)")
+	dryRun := fs.Bool("dry-run", false, "Skip database writes")
+	jsonOut := fs.Bool("json", false, "Output results as JSON")
+	outFile := fs.String("out", "", "Also write the JSON results to this file")
+	fs.Parse(args)
+
+	entries, err := parseRoster(*roster)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid roster")
+	}
+	if *format != formatRoundRobin && *format != formatSwiss {
+		log.Fatal().Str("format", *format).Msg("Unknown tournament format")
+	}
+	if *games < 1 {
+		log.Fatal().Int("games", *games).Msg("Need at least one game per pairing")
+	}
+	numRounds := 1
+	if *format == formatSwiss {
+		numRounds = *rounds
+		if numRounds <= 0 {
+			numRounds = swissRounds(len(entries))
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sig
+		log.Info().Msg("Shutting down...")
+		cancel()
+	}()
+
+	var gameRepo *postgres.GameRepo
+	var phaseRepo *postgres.PhaseRepo
+	var userRepo *postgres.UserRepo
+	var ratingSvc *service.RatingService
+	if !*dryRun {
+		db, err := postgres.Connect(resolveDBURL(*dbURL))
+		if err != nil {
+			log.Fatal().Err(err).Msg("Database connection failed")
+		}
+		defer db.Close()
+		gameRepo = postgres.NewGameRepo(db)
+		phaseRepo = postgres.NewPhaseRepo(db)
+		userRepo = postgres.NewUserRepo(db)
+		ratingSvc = service.NewRatingService(gameRepo, phaseRepo, postgres.NewRatingRepo(db))
+	}
+
+	t := newTournament(entries)
+	errCount, gameIdx := 0, 0
+	for round := 1; round <= numRounds && ctx.Err() == nil; round++ {
+		pairs := roundRobinPairings(len(entries))
+		if *format == formatSwiss {
+			var bye int
+			pairs, bye = swissPairings(t.points(), t.byes(), t.played)
+			if bye >= 0 {
+				log.Info().Int("round", round).Str("entry", entries[bye].Name).Msg("Bye")
+				t.recordBye(bye, *games)
+			}
+		}
+
+		var scheduled []tournamentGame
+		for _, pr := range pairs {
+			for k := 0; k < *games; k++ {
+				scheduled = append(scheduled, t.newGame(round, pr.A, pr.B, k))
+			}
+		}
+
+		results := make([]*bot.ArenaResult, len(scheduled))
+		base := gameIdx
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, *workers)
+		for i, g := range scheduled {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(idx int, g tournamentGame) {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				gameSeed := *seed
+				if *seed != 0 {
+					gameSeed = *seed + int64(base+idx)
+				}
+				powers, personalities := t.arenaConfig(g)
+				cfg := bot.ArenaConfig{
+					GameName:      fmt.Sprintf("tournament: %s vs %s r%d #%d", g.A, g.B, g.Round, g.Game),
+					PowerConfig:   powers,
+					MaxYear:       *maxYear,
+					VictorySCs:    *victory,
+					Seed:          gameSeed,
+					DryRun:        *dryRun,
+					Personalities: personalities,
+				}
+				result, err := bot.RunGame(ctx, cfg, gameRepo, phaseRepo, userRepo)
+				if err != nil {
+					log.Error().Err(err).Str("game", cfg.GameName).Msg("Game failed")
+					mu.Lock()
+					errCount++
+					mu.Unlock()
+					return
+				}
+				if ratingSvc != nil {
+					if _, err := ratingSvc.RateGame(ctx, result.GameID); err != nil {
+						log.Warn().Err(err).Str("game", cfg.GameName).Msg("Failed to update strategy ratings")
+					}
+				}
+				mu.Lock()
+				results[idx] = result
+				mu.Unlock()
+				log.Info().Str("game", cfg.GameName).Str("winner", result.Winner).Int("year", result.FinalYear).Msg("Game completed")
+			}(i, g)
+		}
+		wg.Wait()
+		gameIdx += len(scheduled)
+
+		// Record in schedule order so standings don't depend on which game
+		// finished first.
+		for i, g := range scheduled {
+			if r := results[i]; r != nil {
+				t.record(g, r)
+			}
+		}
+	}
+
+	if *jsonOut {
+		printTournamentJSON(os.Stdout, t, *format, errCount)
+	} else {
+		printStandings(t, *format, errCount)
+	}
+	if *outFile != "" {
+		f, err := os.Create(*outFile)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to write results")
+		}
+		defer f.Close()
+		printTournamentJSON(f, t, *format, errCount)
+	}
+}
+
+func printStandings(t *tournament, format string, errCount int) {
+	fmt.Printf("\nStandings (%s, %d entries, %d games):\n", format, len(t.entries), len(t.games))
+	if errCount > 0 {
+		fmt.Printf("  (%d games failed)\n", errCount)
+	}
+	width := len("Entry")
+	for _, e := range t.entries {
+		width = max(width, len(e.Name))
+	}
+	fmt.Printf("  %2s  %-*s  %5s  %3s  %3s  %3s  %6s  %7s\n", "#", width, "Entry", "Games", "W", "D", "L", "Points", "Avg SCs")
+	for i, s := range t.ranked() {
+		avg := 0.0
+		if s.Games > 0 {
+			avg = float64(s.Centers) / float64(s.Games)
+		}
+		fmt.Printf("  %2d  %-*s  %5d  %3d  %3d  %3d  %6.1f  %7.1f\n", i+1, width, s.Entry, s.Games, s.Wins, s.Draws, s.Losses, s.Points, avg)
+	}
+
+	fmt.Printf("\nCross-table (points scored by row against column):\n")
+	fmt.Printf("  %-*s", width, "")
+	for _, e := range t.entries {
+		fmt.Printf("  %*s", max(len(e.Name), 5), e.Name)
+	}
+	fmt.Println()
+	for i, e := range t.entries {
+		fmt.Printf("  %-*s", width, e.Name)
+		for j, col := range t.entries {
+			cell := "-"
+			if i != j && t.played[newPairing(i, j)] {
+				cell = fmt.Sprintf("%.1f", t.cross[i][j])
+			}
+			fmt.Printf("  %*s", max(len(col.Name), 5), cell)
+		}
+		fmt.Println()
+	}
+}
+
+func printTournamentJSON(w io.Writer, t *tournament, format string, errCount int) {
+	cross := make(map[string]map[string]float64, len(t.entries))
+	for i, e := range t.entries {
+		row := make(map[string]float64)
+		for j, col := range t.entries {
+			if i != j && t.played[newPairing(i, j)] {
+				row[col.Name] = t.cross[i][j]
+			}
+		}
+		cross[e.Name] = row
+	}
+	out := struct {
+		Format     string                        `json:"format"`
+		Entries    []tournamentEntry             `json:"entries"`
+		Errors     int                           `json:"errors"`
+		Standings  []standing                    `json:"standings"`
+		CrossTable map[string]map[string]float64 `json:"cross_table"`
+		Games      []tournamentGame              `json:"games"`
+	}{
+		Format:     format,
+		Entries:    t.entries,
+		Errors:     errCount,
+		Standings:  t.ranked(),
+		CrossTable: cross,
+		Games:      t.games,
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(out)
+}
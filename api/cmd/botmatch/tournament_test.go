@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/freeeve/polite-betrayal/api/internal/bot"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+func TestParseRoster(t *testing.T) {
+	entries, err := parseRoster("hard, medium@aggressive,easy")
+	if err != nil {
+		t.Fatalf("parseRoster: %v", err)
+	}
+	if len(entries) != 3 || entries[1] != (tournamentEntry{Name: "medium@aggressive", Strategy: "medium", Personality: "aggressive"}) {
+		t.Errorf("unexpected entries %+v", entries)
+	}
+	for _, bad := range []string{"hard", "hard,hard", "hard,nope", "hard,easy@grumpy"} {
+		if _, err := parseRoster(bad); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+}
+
+func TestSeatingRotatesPowers(t *testing.T) {
+	counts := make(map[diplomacy.Power][2]int)
+	for k := 0; k < 14; k++ {
+		sides := seating(k)
+		if len(sides) != 7 {
+			t.Fatalf("game %d: seated %d powers", k, len(sides))
+		}
+		n := 0
+		for p, side := range sides {
+			c := counts[p]
+			c[side]++
+			counts[p] = c
+			n += side
+		}
+		if n != 3 && n != 4 {
+			t.Errorf("game %d: side B has %d powers", k, n)
+		}
+	}
+	for p, c := range counts {
+		if c[0] != 7 || c[1] != 7 {
+			t.Errorf("%s: played %d times by A and %d by B over 14 games", p, c[0], c[1])
+		}
+	}
+}
+
+func TestSeatingBalancesEachPair(t *testing.T) {
+	for k := 0; k < 14; k += 2 {
+		first, second := seating(k), seating(k+1)
+		for p, side := range first {
+			if second[p] == side {
+				t.Errorf("games %d and %d: %s played by the same side twice", k, k+1, p)
+			}
+		}
+	}
+}
+
+func TestRoundRobinPairings(t *testing.T) {
+	pairs := roundRobinPairings(4)
+	if len(pairs) != 6 {
+		t.Fatalf("expected 6 pairings, got %d", len(pairs))
+	}
+	seen := make(map[pairing]bool)
+	for _, p := range pairs {
+		if p.A == p.B || seen[newPairing(p.A, p.B)] {
+			t.Errorf("bad or repeated pairing %+v", p)
+		}
+		seen[newPairing(p.A, p.B)] = true
+	}
+}
+
+func TestSwissPairings(t *testing.T) {
+	// Top two have met, so the leader drops to the third-ranked entry.
+	points := []float64{1, 3, 0, 2}
+	played := map[pairing]bool{newPairing(1, 3): true}
+	pairs, bye := swissPairings(points, []int{0, 0, 0, 0}, played)
+	if bye != -1 {
+		t.Errorf("expected no bye, got %d", bye)
+	}
+	want := []pairing{{A: 1, B: 0}, {A: 3, B: 2}}
+	if len(pairs) != 2 || pairs[0] != want[0] || pairs[1] != want[1] {
+		t.Errorf("expected %+v, got %+v", want, pairs)
+	}
+
+	// The lowest-ranked entry without a bye sits out.
+	pairs, bye = swissPairings([]float64{2, 1, 0}, []int{0, 0, 1}, nil)
+	if bye != 1 || len(pairs) != 1 || pairs[0] != (pairing{A: 0, B: 2}) {
+		t.Errorf("expected entry 1 to sit out and 0 to meet 2, got bye %d, %+v", bye, pairs)
+	}
+}
+
+func TestTournamentRecord(t *testing.T) {
+	tr := newTournament([]tournamentEntry{{Name: "hard", Strategy: "hard"}, {Name: "easy", Strategy: "easy"}})
+
+	win := tr.newGame(1, 0, 1, 0)
+	var winner diplomacy.Power
+	for p, side := range win.sides {
+		if side == 0 {
+			winner = p
+		}
+	}
+	tr.record(win, &bot.ArenaResult{Winner: string(winner), SCCounts: map[string]int{string(winner): 18}})
+	tr.record(tr.newGame(1, 0, 1, 1), &bot.ArenaResult{SCCounts: map[string]int{}})
+	tr.recordBye(1, 2)
+
+	hard, easy := tr.standings[0], tr.standings[1]
+	if hard.Wins != 1 || hard.Draws != 1 || hard.Points != 1.5 || hard.Centers != 18 {
+		t.Errorf("unexpected hard record %+v", hard)
+	}
+	if easy.Losses != 1 || easy.Draws != 1 || easy.Byes != 1 || easy.Points != 1.5 {
+		t.Errorf("unexpected easy record %+v", easy)
+	}
+	if tr.cross[0][1] != 1.5 || tr.cross[1][0] != 0.5 {
+		t.Errorf("unexpected cross-table %v", tr.cross)
+	}
+	if ranked := tr.ranked(); ranked[0].Entry != "hard" {
+		t.Errorf("expected hard first on centers, got %+v", ranked)
+	}
+}
+
+func TestTournamentGameDryRun(t *testing.T) {
+	tr := newTournament([]tournamentEntry{{Name: "random", Strategy: "random"}, {Name: "easy@cautious", Strategy: "easy", Personality: "cautious"}})
+	g := tr.newGame(1, 0, 1, 0)
+	powers, personalities := tr.arenaConfig(g)
+	result, err := bot.RunGame(context.Background(), bot.ArenaConfig{
+		GameName:      "tournament-test",
+		PowerConfig:   powers,
+		Personalities: personalities,
+		MaxYear:       1902,
+		Seed:          1,
+		DryRun:        true,
+	}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("RunGame: %v", err)
+	}
+	tr.record(g, result)
+	if g.Seats["austria"] != "random" || len(personalities) != 3 {
+		t.Errorf("unexpected seating %v, personalities %v", g.Seats, personalities)
+	}
+	if got := tr.games[0]; got.SCsA+got.SCsB == 0 || got.PointsA+got.PointsB != 1 {
+		t.Errorf("unexpected game record %+v", got)
+	}
+}
@@ -0,0 +1,183 @@
+// Command build_book mines an opening book from finished games: the positions
+// powers reached in the movement phases of 1901 to 1903 and the orders they
+// played from them, weighted by how often each was played and how well the
+// power went on to do. The result is written as the mined book embedded by the
+// bot package, which bots consult after the hand-written book.
+//
+// Usage:
+//
+//	go run ./cmd/build_book/ games.jsonl more.jsonl
+//	go run ./cmd/build_book/ --db postgres://... --min-games 5
+//
+// Input files are self-play JSONL, as written by the Rust selfplay binary or
+// cmd/export_games. With --db, the finished games in the database are mined
+// as well, including imported self-play games and games played by humans.
+// A game's result for a power is 1 for a solo win, a share of the draw for a
+// survivor of a drawn game and 0 otherwise.
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	_ "github.com/lib/pq"
+
+	"github.com/freeeve/polite-betrayal/api/internal/bot"
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/internal/repository/postgres"
+	"github.com/freeeve/polite-betrayal/api/internal/service"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+func main() {
+	dbURL := flag.String("db", "", "Also mine the finished games in this Postgres database")
+	output := flag.String("output", "internal/bot/opening_book_mined.json", "Where to write the book")
+	minGames := flag.Int("min-games", 3, "Keep positions reached in at least this many games")
+	maxOptions := flag.Int("max-options", 4, "Keep at most this many order sets per position")
+	flag.Parse()
+
+	if flag.NArg() == 0 && *dbURL == "" {
+		log.Fatal("give self-play JSONL files to mine, --db, or both")
+	}
+
+	m := diplomacy.StandardMap()
+	b := bot.NewBookBuilder(*minGames, *maxOptions)
+	for _, path := range flag.Args() {
+		f, err := os.Open(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		n, err := mineFile(f, b, m)
+		f.Close()
+		if err != nil {
+			log.Fatalf("%s: %v", path, err)
+		}
+		log.Printf("%s: mined %d games", path, n)
+	}
+	if *dbURL != "" {
+		n, err := mineDB(*dbURL, b, m)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("database: mined %d games", n)
+	}
+
+	book := b.Book()
+	data, err := json.MarshalIndent(book, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*output, append(data, '\n'), 0o644); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("wrote %d positions from %d games to %s", len(book.Entries), book.Games, *output)
+}
+
+// mineFile adds every game in a self-play JSONL stream to b and returns how
+// many it added. Records that cannot be read are logged and skipped.
+func mineFile(r io.Reader, b *bot.BookBuilder, m *diplomacy.DiplomacyMap) (int, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+	mined := 0
+	for n := 1; sc.Scan(); n++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var rec service.SelfPlayRecord
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			log.Printf("line %d: %v", n, err)
+			continue
+		}
+		if err := mineRecord(&rec, b, m); err != nil {
+			log.Printf("line %d: %v", n, err)
+			continue
+		}
+		mined++
+	}
+	return mined, sc.Err()
+}
+
+// mineDB adds every finished game in the database to b.
+func mineDB(dbURL string, b *bot.BookBuilder, m *diplomacy.DiplomacyMap) (int, error) {
+	db, err := postgres.Connect(dbURL)
+	if err != nil {
+		return 0, fmt.Errorf("connect to postgres: %w", err)
+	}
+	defer db.Close()
+
+	gameRepo := postgres.NewGameRepo(db)
+	exportSvc := service.NewExportService(gameRepo, postgres.NewPhaseRepo(db), postgres.NewMessageRepo(db))
+	ctx := context.Background()
+	mined := 0
+	err = service.EachFinishedGame(ctx, gameRepo, func(g model.Game) bool {
+		rec, err := exportSvc.ExportSelfPlay(ctx, g.ID)
+		if err == nil {
+			err = mineRecord(rec, b, m)
+		}
+		if err != nil {
+			log.Printf("skip game %s: %v", g.ID, err)
+			return true
+		}
+		mined++
+		return true
+	})
+	if err != nil {
+		return mined, fmt.Errorf("list finished games: %w", err)
+	}
+	return mined, nil
+}
+
+// mineRecord adds one game to b. Phases after BookLastYear are not decoded.
+func mineRecord(rec *service.SelfPlayRecord, b *bot.BookBuilder, m *diplomacy.DiplomacyMap) error {
+	var phases []bot.BookPhase
+	for i, ph := range rec.Phases {
+		if ph.Year > bot.BookLastYear {
+			break
+		}
+		if ph.Phase != "m" {
+			continue
+		}
+		gs, err := diplomacy.DecodeDFEN(ph.DFEN)
+		if err != nil {
+			return fmt.Errorf("phase %d: %w", i, err)
+		}
+		orders := make(map[diplomacy.Power][]diplomacy.DSONOrder, len(ph.Orders))
+		for power, text := range ph.Orders {
+			dson, err := diplomacy.ParseDSON(text)
+			if err != nil {
+				return fmt.Errorf("phase %d %s: %w", i, power, err)
+			}
+			orders[diplomacy.Power(power)] = dson
+		}
+		phases = append(phases, bot.BookPhase{State: gs, Orders: orders})
+	}
+	b.AddGame(phases, results(rec), m)
+	return nil
+}
+
+// results scores each power's outcome of a game: 1 for the solo winner, an
+// equal share of the draw for every power still holding centers when a game
+// is drawn, and 0 otherwise.
+func results(rec *service.SelfPlayRecord) map[diplomacy.Power]float64 {
+	res := make(map[diplomacy.Power]float64)
+	if rec.Winner != nil {
+		res[diplomacy.Power(*rec.Winner)] = 1
+		return res
+	}
+	var survivors []diplomacy.Power
+	for i, p := range diplomacy.AllPowers() {
+		if i < len(rec.FinalSCCount) && rec.FinalSCCount[i] > 0 {
+			survivors = append(survivors, p)
+		}
+	}
+	for _, p := range survivors {
+		res[p] = 1 / float64(len(survivors))
+	}
+	return res
+}
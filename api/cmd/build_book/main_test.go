@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/freeeve/polite-betrayal/api/internal/bot"
+	"github.com/freeeve/polite-betrayal/api/internal/service"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+func TestResults(t *testing.T) {
+	winner := "france"
+	res := results(&service.SelfPlayRecord{Winner: &winner})
+	if res[diplomacy.France] != 1 || len(res) != 1 {
+		t.Errorf("solo: got %v", res)
+	}
+
+	res = results(&service.SelfPlayRecord{FinalSCCount: []int{0, 10, 12, 0, 0, 0, 12}})
+	for _, p := range []diplomacy.Power{diplomacy.England, diplomacy.France, diplomacy.Turkey} {
+		if res[p] != 1.0/3 {
+			t.Errorf("draw: %s got %v, want 1/3", p, res[p])
+		}
+	}
+	if res[diplomacy.Austria] != 0 {
+		t.Errorf("draw: eliminated Austria got %v", res[diplomacy.Austria])
+	}
+}
+
+func TestMineFile(t *testing.T) {
+	gs := diplomacy.NewInitialState()
+	phase := service.SelfPlayPhase{
+		DFEN:   diplomacy.EncodeDFEN(gs),
+		Year:   1901,
+		Season: "s",
+		Phase:  "m",
+		Orders: map[string]string{"austria": "A vie - gal ; A bud - ser ; F tri - alb"},
+	}
+	late := phase
+	late.Year = bot.BookLastYear + 1
+	late.DFEN = "not decoded"
+	winner := "austria"
+	line, err := json.Marshal(service.SelfPlayRecord{Winner: &winner, Phases: []service.SelfPlayPhase{phase, late}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := string(line) + "\n" + string(line) + "\n\nnot json\n"
+
+	b := bot.NewBookBuilder(2, 4)
+	n, err := mineFile(strings.NewReader(input), b, diplomacy.StandardMap())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("mined %d games, want 2", n)
+	}
+	book := b.Book()
+	if len(book.Entries) != 1 {
+		t.Fatalf("expected 1 position, got %d", len(book.Entries))
+	}
+	if opt := book.Entries[0].Options[0]; opt.Games != 2 || opt.Score != 1 {
+		t.Errorf("unexpected option %+v", opt)
+	}
+}
@@ -0,0 +1,203 @@
+// Command dfen checks and prints DFEN positions, for debugging bad engine
+// output and training data.
+//
+// Usage:
+//
+//	go run ./cmd/dfen/ validate games.jsonl
+//	go run ./cmd/dfen/ validate --lenient - < positions.txt
+//	go run ./cmd/dfen/ pretty "1901sm/Aabud,Aftri,Aavie,.../Abud,.../-"
+//
+// validate reads one position per line, either a bare DFEN string or a
+// self-play JSONL record whose phases each carry one. Blank lines and lines
+// starting with # are ignored. Every bad position is reported with its line,
+// the offending token and a caret under it, and the command exits with
+// status 1 if any were found. Positions are checked against the standard
+// map; --lenient reports every bad entry in a position instead of only the
+// first.
+//
+// pretty prints a position by power: its units, supply centers and any
+// dislodged units.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+const usage = `usage:
+  dfen validate [--lenient] [file|-]
+  dfen pretty <dfen>
+`
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	switch os.Args[1] {
+	case "validate":
+		fs := flag.NewFlagSet("validate", flag.ExitOnError)
+		lenient := fs.Bool("lenient", false, "report every bad entry in a position, not just the first")
+		fs.Parse(os.Args[2:])
+		mode := diplomacy.DFENStrict
+		if *lenient {
+			mode = diplomacy.DFENLenient
+		}
+		name, r := "-", io.Reader(os.Stdin)
+		if fs.NArg() > 0 && fs.Arg(0) != "-" {
+			f, err := os.Open(fs.Arg(0))
+			if err != nil {
+				log.Fatal(err)
+			}
+			defer f.Close()
+			name, r = fs.Arg(0), f
+		}
+		checked, bad, err := validate(r, os.Stdout, name, mode)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("%d positions, %d invalid\n", checked, bad)
+		if bad > 0 {
+			os.Exit(1)
+		}
+	case "pretty":
+		if len(os.Args) != 3 {
+			fmt.Fprint(os.Stderr, usage)
+			os.Exit(2)
+		}
+		if err := pretty(os.Stdout, os.Args[2]); err != nil {
+			log.Fatal(err)
+		}
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+// validate checks every position in r, reporting the bad ones to w, and
+// returns how many positions it checked and how many were bad.
+func validate(r io.Reader, w io.Writer, name string, mode diplomacy.DFENMode) (checked, bad int, err error) {
+	check := func(src, dfen string) {
+		checked++
+		if _, err := diplomacy.DecodeDFENMode(dfen, mode); err != nil {
+			bad++
+			report(w, src, dfen, err)
+		}
+	}
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		src := fmt.Sprintf("%s:%d", name, n)
+		if !strings.HasPrefix(line, "{") {
+			check(src, line)
+			continue
+		}
+		var rec struct {
+			Phases []struct {
+				DFEN string `json:"dfen"`
+			} `json:"phases"`
+		}
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			checked++
+			bad++
+			fmt.Fprintf(w, "%s: %v\n", src, err)
+			continue
+		}
+		for i, ph := range rec.Phases {
+			check(fmt.Sprintf("%s phase %d", src, i), ph.DFEN)
+		}
+	}
+	return checked, bad, sc.Err()
+}
+
+// report writes the problems with dfen, each with a caret under its token.
+func report(w io.Writer, src, dfen string, err error) {
+	var errs diplomacy.DFENErrors
+	if !errors.As(err, &errs) {
+		var de *diplomacy.DFENError
+		if !errors.As(err, &de) {
+			fmt.Fprintf(w, "%s: %v\n", src, err)
+			return
+		}
+		errs = diplomacy.DFENErrors{de}
+	}
+	for _, de := range errs {
+		fmt.Fprintf(w, "%s: %v\n", src, de)
+		fmt.Fprintf(w, "  %s\n  %s^\n", dfen, strings.Repeat(" ", de.Offset))
+	}
+}
+
+// pretty writes the position dfen grouped by power. Bad entries are left
+// out and listed at the end.
+func pretty(w io.Writer, dfen string) error {
+	gs, err := diplomacy.DecodeDFENMode(dfen, diplomacy.DFENLenient)
+	var skipped diplomacy.DFENErrors
+	if err != nil && !errors.As(err, &skipped) {
+		return err
+	}
+
+	fmt.Fprintf(w, "%s %d %s\n", gs.Season, gs.Year, gs.Phase)
+	for _, power := range append(diplomacy.AllPowers(), diplomacy.Neutral) {
+		var units []string
+		for _, u := range gs.UnitsOf(power) {
+			units = append(units, formatUnit(u))
+		}
+		var centers []string
+		for prov, owner := range gs.SupplyCenters {
+			if owner == power {
+				centers = append(centers, prov)
+			}
+		}
+		if len(units) == 0 && len(centers) == 0 {
+			continue
+		}
+		sort.Strings(units)
+		sort.Strings(centers)
+		name := string(power)
+		if power == diplomacy.Neutral {
+			name = "neutral"
+		}
+		fmt.Fprintf(w, "%s: %d centers, %d units\n", name, len(centers), len(units))
+		if len(units) > 0 {
+			fmt.Fprintf(w, "  units:   %s\n", strings.Join(units, ", "))
+		}
+		if len(centers) > 0 {
+			fmt.Fprintf(w, "  centers: %s\n", strings.Join(centers, ", "))
+		}
+	}
+	for _, d := range gs.Dislodged {
+		fmt.Fprintf(w, "dislodged: %s %s from %s\n", d.Unit.Power, formatUnit(d.Unit), d.AttackerFrom)
+	}
+	for _, de := range skipped {
+		fmt.Fprintf(w, "skipped: %v\n", de)
+	}
+	return nil
+}
+
+// formatUnit writes u as e.g. "A vie" or "F stp/sc".
+func formatUnit(u diplomacy.Unit) string {
+	s := "A "
+	if u.Type == diplomacy.Fleet {
+		s = "F "
+	}
+	s += u.Province
+	if u.Coast != diplomacy.NoCoast {
+		s += "/" + string(u.Coast)
+	}
+	return s
+}
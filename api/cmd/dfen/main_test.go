@@ -0,0 +1,73 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+func TestValidate(t *testing.T) {
+	initial := diplomacy.EncodeDFEN(diplomacy.NewInitialState())
+	input := strings.Join([]string{
+		"# corpus",
+		initial,
+		"",
+		"1901sm/Aavie,Axbud/Avie/-",
+		`{"phases":[{"dfen":"` + initial + `"},{"dfen":"1901fm/Aanth/Avie/-"}]}`,
+	}, "\n")
+
+	var out strings.Builder
+	checked, bad, err := validate(strings.NewReader(input), &out, "corpus.txt", diplomacy.DFENStrict)
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if checked != 4 || bad != 2 {
+		t.Errorf("got %d checked, %d bad; want 4 checked, 2 bad\n%s", checked, bad, out.String())
+	}
+	report := out.String()
+	for _, want := range []string{
+		"corpus.txt:4: dfen: units \"Axbud\" at offset 13",
+		"  1901sm/Aavie,Axbud/Avie/-\n               ^\n",
+		"corpus.txt:5 phase 1: dfen: units \"Aanth\"",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report missing %q:\n%s", want, report)
+		}
+	}
+}
+
+func TestValidateLenient(t *testing.T) {
+	var out strings.Builder
+	_, bad, err := validate(strings.NewReader("1901sm/Axvie,Aanth/Aboh/-\n"), &out, "-", diplomacy.DFENLenient)
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if bad != 1 || strings.Count(out.String(), "^") != 3 {
+		t.Errorf("expected one bad position with 3 problems, got:\n%s", out.String())
+	}
+}
+
+func TestPretty(t *testing.T) {
+	var out strings.Builder
+	if err := pretty(&out, "1901fr/Aavie,Rfstp.sc/Avie,Rstp,Nbel/Aabud<gal,Aaxyz"); err != nil {
+		t.Fatalf("pretty: %v", err)
+	}
+	got := out.String()
+	for _, want := range []string{
+		"fall 1901 retreat\n",
+		"austria: 1 centers, 1 units\n  units:   A vie\n",
+		"  units:   F stp/sc\n",
+		"neutral: 1 centers, 0 units\n  centers: bel\n",
+		"dislodged: austria A bud from gal\n",
+		"skipped: dfen: dislodged \"Aaxyz\"",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+
+	if err := pretty(&out, "1901sm/-"); err == nil {
+		t.Error("expected an error for a malformed layout")
+	}
+}
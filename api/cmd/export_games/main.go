@@ -0,0 +1,119 @@
+// Command export_games writes finished games as selfplay JSONL, the format
+// produced by the Rust selfplay binary and read by cmd/import_selfplay, so
+// games played on the server can feed back into training pipelines.
+//
+// Usage:
+//
+//	go run ./cmd/export_games/ --db postgres://... --output games.jsonl
+//	go run ./cmd/export_games/ --db postgres://... --game <game-id>
+//
+// Without --game, every finished game is exported. Games are written one
+// line at a time as they are read, numbered from --first-id.
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"log"
+	"os"
+
+	_ "github.com/lib/pq"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/internal/repository/postgres"
+	"github.com/freeeve/polite-betrayal/api/internal/service"
+)
+
+func main() {
+	dbURL := flag.String("db", os.Getenv("DATABASE_URL"), "Postgres connection URL")
+	gameID := flag.String("game", "", "Export only this game")
+	output := flag.String("output", "", "Output JSONL file (default stdout)")
+	pressKey := flag.String("press-key", os.Getenv("PRESS_ENCRYPTION_KEY"), "Base64 key private press is encrypted with, if any")
+	firstID := flag.Int("first-id", 1, "game_id of the first exported record")
+	flag.Parse()
+
+	if *dbURL == "" {
+		log.Fatal("--db or DATABASE_URL is required")
+	}
+
+	db, err := postgres.Connect(*dbURL)
+	if err != nil {
+		log.Fatalf("connect to postgres: %v", err)
+	}
+	defer db.Close()
+
+	gameRepo := postgres.NewGameRepo(db)
+	messageRepo := postgres.NewMessageRepo(db)
+	if *pressKey != "" {
+		key, err := postgres.DecodePressKey(*pressKey)
+		if err == nil {
+			err = messageRepo.SetEncryptionKey(key)
+		}
+		if err != nil {
+			log.Fatalf("press key: %v", err)
+		}
+	}
+	exportSvc := service.NewExportService(gameRepo, postgres.NewPhaseRepo(db), messageRepo)
+	exportSvc.SetChannelRepo(postgres.NewChannelRepo(db))
+	ctx := context.Background()
+
+	gameIDs := []string{*gameID}
+	if *gameID == "" {
+		gameIDs = gameIDs[:0]
+		err := service.EachFinishedGame(ctx, gameRepo, func(g model.Game) bool {
+			gameIDs = append(gameIDs, g.ID)
+			return true
+		})
+		if err != nil {
+			log.Fatalf("list finished games: %v", err)
+		}
+	}
+
+	var out io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			log.Fatalf("create output: %v", err)
+		}
+		defer f.Close()
+		out = f
+	}
+	w := bufio.NewWriter(out)
+	defer w.Flush()
+
+	written := exportGames(ctx, exportSvc.ExportSelfPlay, gameIDs, *firstID, w)
+	log.Printf("done: wrote %d of %d games", written, len(gameIDs))
+}
+
+// exportGames writes one JSONL record per game, skipping games that fail to
+// export, and returns how many it wrote. Each line is flushed as soon as it is
+// written so a long export can be followed.
+func exportGames(
+	ctx context.Context,
+	export func(ctx context.Context, gameID string) (*service.SelfPlayRecord, error),
+	gameIDs []string,
+	firstID int,
+	w *bufio.Writer,
+) int {
+	enc := json.NewEncoder(w)
+	written := 0
+	for _, id := range gameIDs {
+		rec, err := export(ctx, id)
+		if err != nil {
+			log.Printf("skip game %s: %v", id, err)
+			continue
+		}
+		rec.GameID = firstID + written
+		if err := enc.Encode(rec); err != nil {
+			log.Fatalf("write game %s: %v", id, err)
+		}
+		if err := w.Flush(); err != nil {
+			log.Fatalf("flush: %v", err)
+		}
+		written++
+	}
+	return written
+}
@@ -0,0 +1,114 @@
+// Command export_training writes press-aware training data from finished
+// games as JSONL: one (state, press, deals, orders) sample per phase and
+// power, with the press each power could see and the deals it was party to.
+//
+// Usage:
+//
+//	go run ./cmd/export_training/ --db postgres://... --output samples.jsonl
+//	go run ./cmd/export_training/ --db postgres://... --game <game-id>
+//
+// Without --game, the most recently finished games are exported.
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"log"
+	"os"
+
+	_ "github.com/lib/pq"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/internal/repository/postgres"
+	"github.com/freeeve/polite-betrayal/api/internal/service"
+)
+
+func main() {
+	dbURL := flag.String("db", os.Getenv("DATABASE_URL"), "Postgres connection URL")
+	gameID := flag.String("game", "", "Export only this game")
+	output := flag.String("output", "", "Output JSONL file (default stdout)")
+	pressKey := flag.String("press-key", os.Getenv("PRESS_ENCRYPTION_KEY"), "Base64 key private press is encrypted with, if any")
+	flag.Parse()
+
+	if *dbURL == "" {
+		log.Fatal("--db or DATABASE_URL is required")
+	}
+
+	db, err := postgres.Connect(*dbURL)
+	if err != nil {
+		log.Fatalf("connect to postgres: %v", err)
+	}
+	defer db.Close()
+
+	gameRepo := postgres.NewGameRepo(db)
+	messageRepo := postgres.NewMessageRepo(db)
+	if *pressKey != "" {
+		key, err := postgres.DecodePressKey(*pressKey)
+		if err == nil {
+			err = messageRepo.SetEncryptionKey(key)
+		}
+		if err != nil {
+			log.Fatalf("press key: %v", err)
+		}
+	}
+	exportSvc := service.NewExportService(gameRepo, postgres.NewPhaseRepo(db), messageRepo)
+	exportSvc.SetChannelRepo(postgres.NewChannelRepo(db))
+	ctx := context.Background()
+
+	gameIDs := []string{*gameID}
+	if *gameID == "" {
+		gameIDs = gameIDs[:0]
+		err := service.EachFinishedGame(ctx, gameRepo, func(g model.Game) bool {
+			gameIDs = append(gameIDs, g.ID)
+			return true
+		})
+		if err != nil {
+			log.Fatalf("list finished games: %v", err)
+		}
+	}
+
+	var out io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			log.Fatalf("create output: %v", err)
+		}
+		defer f.Close()
+		out = f
+	}
+	w := bufio.NewWriter(out)
+	defer w.Flush()
+
+	total := 0
+	for _, id := range gameIDs {
+		n, err := exportGame(ctx, exportSvc, id, w)
+		if err != nil {
+			log.Printf("skip game %s: %v", id, err)
+			continue
+		}
+		total += n
+	}
+	log.Printf("done: wrote %d samples from %d games", total, len(gameIDs))
+}
+
+// exportGame writes one game's training samples to w and returns how many it wrote.
+func exportGame(ctx context.Context, exportSvc *service.ExportService, gameID string, w io.Writer) (int, error) {
+	export, err := exportSvc.ExportGame(ctx, gameID)
+	if err != nil {
+		return 0, err
+	}
+	samples, err := service.TrainingSamples(export)
+	if err != nil {
+		return 0, err
+	}
+	enc := json.NewEncoder(w)
+	for _, s := range samples {
+		if err := enc.Encode(s); err != nil {
+			return 0, err
+		}
+	}
+	return len(samples), nil
+}
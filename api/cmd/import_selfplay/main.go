@@ -5,18 +5,49 @@
 //
 //	go run ./cmd/import_selfplay/ --input games.jsonl --db postgres://...
 //	go run ./cmd/import_selfplay/ --input games.jsonl --db postgres://... --follow
+//	go run ./cmd/import_selfplay/ --input games.jsonl --db postgres://... --force
+//	go run ./cmd/import_selfplay/ --input games.jsonl --db postgres://... --skip-existing --resume
+//	go run ./cmd/import_selfplay/ --input games.jsonl --db postgres://... --live --player <user-id> --power france
+//
+// Each game is stored with a hash of its content, so importing the same file
+// twice skips games that are already present. --force replaces them instead.
+// Games also record an external ID made of the name prefix and the record's
+// game_id; --skip-existing skips a record as soon as its external ID is
+// found, without reading the rest of it. Every game is imported in one
+// transaction, so a record that fails partway leaves nothing behind.
+// --strict also rejects records whose positions are well-formed DFEN but
+// impossible on the standard map, such as an army at sea.
+//
+// --resume records in <input>.progress the byte offset up to which the input
+// has been imported, and starts from there on the next run, so a large file
+// can be imported across several runs. Progress stops advancing at the first
+// record that fails, so the next run retries it.
+//
+// With --live, games that have no winner yet are imported as active games
+// instead of finished ones: every phase but the last is stored resolved, the
+// last phase is left open with a fresh deadline, the given player takes one
+// power and bots play the rest. Live games are not deduplicated, so the same
+// position can be continued more than once.
 package main
 
 import (
 	"bufio"
 	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"io/fs"
 	"log"
 	"os"
 	"os/signal"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -25,6 +56,8 @@ import (
 
 	"github.com/freeeve/polite-betrayal/api/internal/model"
 	"github.com/freeeve/polite-betrayal/api/internal/repository/postgres"
+	redisrepo "github.com/freeeve/polite-betrayal/api/internal/repository/redis"
+	"github.com/freeeve/polite-betrayal/api/internal/service"
 	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
 )
 
@@ -55,11 +88,75 @@ type jsonPhaseEntry struct {
 	SCCounts []int             `json:"sc_counts"`
 }
 
+// importStats counts the outcome of each game record read from the input.
+type importStats struct {
+	imported int
+	skipped  int // duplicates of games already in the database
+	failed   int
+}
+
+// liveOptions configures how in-progress games are continued on import.
+type liveOptions struct {
+	playerID   string
+	power      string
+	difficulty string // bot difficulty for the other six powers
+	turnDur    time.Duration
+	retreatDur time.Duration
+	buildDur   time.Duration
+	phaseSvc   *service.PhaseService
+	cache      *redisrepo.Client
+}
+
+// phaseDuration returns how long the given phase type stays open.
+func (o *liveOptions) phaseDuration(phaseType string) time.Duration {
+	switch phaseType {
+	case "retreat":
+		return o.retreatDur
+	case "build":
+		return o.buildDur
+	default:
+		return o.turnDur
+	}
+}
+
+// importer imports game records, each in its own transaction, and with
+// --resume records how far into the input it has got.
+type importer struct {
+	db           *sql.DB
+	gameRepo     *postgres.GameRepo
+	phaseRepo    *postgres.PhaseRepo
+	userRepo     *postgres.UserRepo
+	namePrefix   string
+	force        bool
+	skipExisting bool
+	live         *liveOptions
+	dfenMode     diplomacy.DFENMode // DFENStrict with --strict
+	progressFile string             // empty unless resuming
+	stats        importStats
+
+	// stalled is set once a record fails to import. Progress is no longer
+	// recorded past it, so a resumed run retries it; records imported
+	// after it are skipped as duplicates on that run.
+	stalled bool
+}
+
 func main() {
 	inputFile := flag.String("input", "", "Path to JSONL file")
 	dbURL := flag.String("db", os.Getenv("DATABASE_URL"), "Postgres connection URL")
 	namePrefix := flag.String("name-prefix", "selfplay", "Game name prefix")
 	follow := flag.Bool("follow", false, "Watch file for new lines (like tail -f)")
+	force := flag.Bool("force", false, "Replace games that were already imported instead of skipping them")
+	skipExisting := flag.Bool("skip-existing", false, "Skip records whose external ID (name prefix and game_id) was already imported, without comparing content")
+	resume := flag.Bool("resume", false, "Continue from the offset recorded in <input>.progress and keep it updated")
+	strict := flag.Bool("strict", false, "Reject records with positions impossible on the standard map, not just malformed DFEN")
+	live := flag.Bool("live", false, "Import games with no winner as active games continuing from their last phase")
+	redisURL := flag.String("redis", os.Getenv("REDIS_URL"), "Redis connection URL (required with --live)")
+	playerID := flag.String("player", "", "User ID that takes over --power in live games")
+	power := flag.String("power", "", "Power the player controls in live games")
+	botDifficulty := flag.String("bot-difficulty", "hard", "Bot difficulty for the other powers in live games")
+	turnDur := flag.Duration("turn-duration", 24*time.Hour, "Movement phase duration in live games")
+	retreatDur := flag.Duration("retreat-duration", 12*time.Hour, "Retreat phase duration in live games")
+	buildDur := flag.Duration("build-duration", 12*time.Hour, "Build phase duration in live games")
 	flag.Parse()
 
 	if *inputFile == "" {
@@ -68,6 +165,9 @@ func main() {
 	if *dbURL == "" {
 		log.Fatal("--db or DATABASE_URL is required")
 	}
+	if *force && *skipExisting {
+		log.Fatal("--force and --skip-existing cannot be combined")
+	}
 
 	db, err := postgres.Connect(*dbURL)
 	if err != nil {
@@ -75,75 +175,91 @@ func main() {
 	}
 	defer db.Close()
 
-	gameRepo := postgres.NewGameRepo(db)
-	phaseRepo := postgres.NewPhaseRepo(db)
-	userRepo := postgres.NewUserRepo(db)
-	ctx := context.Background()
-
-	if *follow {
-		runFollow(ctx, *inputFile, *namePrefix, gameRepo, phaseRepo, userRepo)
-	} else {
-		runBatch(ctx, *inputFile, *namePrefix, gameRepo, phaseRepo, userRepo)
+	im := &importer{
+		db:           db,
+		gameRepo:     postgres.NewGameRepo(db),
+		phaseRepo:    postgres.NewPhaseRepo(db),
+		userRepo:     postgres.NewUserRepo(db),
+		namePrefix:   *namePrefix,
+		force:        *force,
+		skipExisting: *skipExisting,
 	}
-}
-
-// runBatch imports all lines from the JSONL file and exits.
-func runBatch(
-	ctx context.Context,
-	inputFile, namePrefix string,
-	gameRepo *postgres.GameRepo,
-	phaseRepo *postgres.PhaseRepo,
-	userRepo *postgres.UserRepo,
-) {
-	f, err := os.Open(inputFile)
-	if err != nil {
-		log.Fatalf("open input: %v", err)
+	if *strict {
+		im.dfenMode = diplomacy.DFENStrict
 	}
-	defer f.Close()
-
-	scanner := bufio.NewScanner(f)
-	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	ctx := context.Background()
 
-	imported := 0
-	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.TrimSpace(line) == "" {
-			continue
+	if *live {
+		if *redisURL == "" {
+			log.Fatal("--redis or REDIS_URL is required with --live")
+		}
+		if *playerID == "" || !slices.Contains(powerOrder, diplomacy.Power(*power)) {
+			log.Fatal("--player and a valid --power are required with --live")
+		}
+		user, err := im.userRepo.FindByID(ctx, *playerID)
+		if err != nil {
+			log.Fatalf("find player: %v", err)
+		}
+		if user == nil {
+			log.Fatalf("player %s not found", *playerID)
 		}
 
-		var rec jsonGameRecord
-		if err := json.Unmarshal([]byte(line), &rec); err != nil {
-			log.Printf("WARN: skip line (bad JSON): %v", err)
-			continue
+		redisClient, err := redisrepo.NewClient(*redisURL)
+		if err != nil {
+			log.Fatalf("connect to redis: %v", err)
+		}
+		defer redisClient.Close()
+
+		im.live = &liveOptions{
+			playerID:   *playerID,
+			power:      *power,
+			difficulty: *botDifficulty,
+			turnDur:    *turnDur,
+			retreatDur: *retreatDur,
+			buildDur:   *buildDur,
+			phaseSvc:   service.NewPhaseService(im.gameRepo, im.phaseRepo, redisClient, nil),
+			cache:      redisClient,
 		}
+	}
 
-		gameName := fmt.Sprintf("%s-%03d", namePrefix, rec.GameID)
-		gameID, err := importGame(ctx, gameRepo, phaseRepo, userRepo, rec, gameName)
+	var start int64
+	if *resume {
+		im.progressFile = *inputFile + ".progress"
+		start, err = readProgress(im.progressFile)
 		if err != nil {
-			log.Printf("ERROR: import game %d: %v", rec.GameID, err)
-			continue
+			log.Fatalf("read progress: %v", err)
+		}
+		if start > 0 {
+			log.Printf("resuming %s at byte %d", *inputFile, start)
 		}
+	}
 
-		imported++
-		log.Printf("imported game %d -> %s (id=%s, %d phases)", rec.GameID, gameName, gameID, len(rec.Phases))
+	if *follow {
+		im.runFollow(ctx, *inputFile, start)
+	} else {
+		im.runBatch(ctx, *inputFile, start)
 	}
+}
 
-	if err := scanner.Err(); err != nil {
+// runBatch imports all lines from the JSONL file, starting at the given
+// byte offset, and exits.
+func (im *importer) runBatch(ctx context.Context, inputFile string, start int64) {
+	f, err := openInput(inputFile, start)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	if _, err := im.readLines(ctx, f, start, true); err != nil {
 		log.Fatalf("read input: %v", err)
 	}
 
-	log.Printf("done: imported %d games", imported)
+	log.Printf("done: imported %d games, skipped %d duplicates, %d failed", im.stats.imported, im.stats.skipped, im.stats.failed)
 }
 
 // runFollow imports existing lines then watches the file for new lines, polling every 2 seconds.
 // It handles the file not existing yet by waiting for it to be created.
-func runFollow(
-	ctx context.Context,
-	inputFile, namePrefix string,
-	gameRepo *postgres.GameRepo,
-	phaseRepo *postgres.PhaseRepo,
-	userRepo *postgres.UserRepo,
-) {
+func (im *importer) runFollow(ctx context.Context, inputFile string, start int64) {
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 
@@ -161,18 +277,18 @@ func runFollow(
 		}
 	}
 
-	f, err := os.Open(inputFile)
+	f, err := openInput(inputFile, start)
 	if err != nil {
-		log.Fatalf("open input: %v", err)
+		log.Fatal(err)
 	}
 	defer f.Close()
 
-	imported := 0
-	var offset int64
-
 	// Import existing lines.
-	imported, offset = followReadLines(ctx, f, offset, namePrefix, imported, gameRepo, phaseRepo, userRepo)
-	log.Printf("imported %d existing games, watching for new games...", imported)
+	offset, err := im.readLines(ctx, f, start, false)
+	if err != nil {
+		log.Printf("WARN: read input: %v", err)
+	}
+	log.Printf("imported %d existing games, skipped %d duplicates, watching for new games...", im.stats.imported, im.stats.skipped)
 
 	// Poll for new lines.
 	ticker := time.NewTicker(2 * time.Second)
@@ -181,68 +297,250 @@ func runFollow(
 	for {
 		select {
 		case <-sigCh:
-			log.Printf("interrupted: imported %d games total", imported)
+			log.Printf("interrupted: imported %d games total, skipped %d duplicates, %d failed", im.stats.imported, im.stats.skipped, im.stats.failed)
 			return
 		case <-ticker.C:
-			imported, offset = followReadLines(ctx, f, offset, namePrefix, imported, gameRepo, phaseRepo, userRepo)
+			if offset, err = im.readLines(ctx, f, offset, false); err != nil {
+				log.Printf("WARN: read input: %v", err)
+			}
 		}
 	}
 }
 
-// followReadLines seeks to the given offset, reads any complete new lines, imports them,
-// and returns the updated count and offset.
-func followReadLines(
-	ctx context.Context,
-	f *os.File,
-	offset int64,
-	namePrefix string,
-	imported int,
-	gameRepo *postgres.GameRepo,
-	phaseRepo *postgres.PhaseRepo,
-	userRepo *postgres.UserRepo,
-) (int, int64) {
+// openInput opens the input file, checking that it is at least as long as
+// the offset a resumed import starts from.
+func openInput(inputFile string, start int64) (*os.File, error) {
+	f, err := os.Open(inputFile)
+	if err != nil {
+		return nil, fmt.Errorf("open input: %w", err)
+	}
+	if start > 0 {
+		info, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("stat input: %w", err)
+		}
+		if info.Size() < start {
+			f.Close()
+			return nil, fmt.Errorf("%s is shorter than its recorded progress (%d bytes); remove %s.progress to start over", inputFile, start, inputFile)
+		}
+	}
+	return f, nil
+}
+
+// readLines seeks to the given offset, imports every complete line after it
+// and returns the offset past the last one read. When final is set, a last
+// line without a trailing newline is imported too; otherwise it is left for
+// the next call, since the writer may not have finished it.
+func (im *importer) readLines(ctx context.Context, f *os.File, offset int64, final bool) (int64, error) {
 	if _, err := f.Seek(offset, io.SeekStart); err != nil {
-		log.Printf("WARN: seek failed: %v", err)
-		return imported, offset
+		return offset, fmt.Errorf("seek: %w", err)
 	}
 
-	reader := bufio.NewReader(f)
+	reader := bufio.NewReaderSize(f, 64*1024)
 	for {
 		line, err := reader.ReadString('\n')
-		if err != nil {
-			// No complete line available yet; do not advance offset past partial data.
-			break
+		if err != nil && !(final && err == io.EOF && line != "") {
+			if err == io.EOF {
+				err = nil
+			}
+			return offset, err
 		}
 
 		offset += int64(len(line))
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
+		if !im.importLine(ctx, line) {
+			im.stalled = true
 		}
-
-		var rec jsonGameRecord
-		if err := json.Unmarshal([]byte(line), &rec); err != nil {
-			log.Printf("WARN: skip line (bad JSON): %v", err)
-			continue
+		if !im.stalled {
+			im.saveProgress(offset)
 		}
+	}
+}
+
+// importLine imports the game record on one line of the input and logs the
+// outcome. It returns false if the record could not be imported, so a
+// resumed run should try it again; malformed lines are logged and passed.
+func (im *importer) importLine(ctx context.Context, line string) bool {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return true
+	}
 
-		gameName := fmt.Sprintf("%s-%03d", namePrefix, rec.GameID)
-		winnerStr := "draw"
-		if rec.Winner != nil {
-			winnerStr = fmt.Sprintf("%s wins", *rec.Winner)
+	var rec jsonGameRecord
+	if err := json.Unmarshal([]byte(line), &rec); err != nil {
+		log.Printf("WARN: skip line (bad JSON): %v", err)
+		return true
+	}
+
+	gameName := fmt.Sprintf("%s-%03d", im.namePrefix, rec.GameID)
+	gameID, skipped, err := im.importRecord(ctx, rec, gameName)
+	if err != nil {
+		im.stats.failed++
+		log.Printf("ERROR: import game %d: %v", rec.GameID, err)
+		return false
+	}
+	if skipped {
+		im.stats.skipped++
+		log.Printf("skipped game %d: already imported (id=%s)", rec.GameID, gameID)
+		return true
+	}
+
+	im.stats.imported++
+	outcome := "draw"
+	if rec.Winner != nil {
+		outcome = fmt.Sprintf("%s wins", *rec.Winner)
+	} else if im.live != nil {
+		outcome = "live"
+	}
+	log.Printf("imported game %d -> %s (id=%s, %s in %d, %d phases)", rec.GameID, gameName, gameID, outcome, rec.FinalYear, len(rec.Phases))
+	return true
+}
+
+// readProgress returns the input offset recorded by an earlier run, or 0
+// if there is none.
+func readProgress(path string) (int64, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	offset, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("%s: invalid offset %q", path, strings.TrimSpace(string(data)))
+	}
+	return offset, nil
+}
+
+// saveProgress records that the input has been imported up to offset. The
+// file is replaced by rename, so an interrupted write leaves the previous
+// offset in place.
+func (im *importer) saveProgress(offset int64) {
+	if im.progressFile == "" {
+		return
+	}
+	tmp := im.progressFile + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strconv.FormatInt(offset, 10)+"\n"), 0o644); err != nil {
+		log.Printf("WARN: save progress: %v", err)
+		return
+	}
+	if err := os.Rename(tmp, im.progressFile); err != nil {
+		log.Printf("WARN: save progress: %v", err)
+	}
+}
+
+// inTx runs fn with repositories bound to a new transaction. It commits if
+// fn succeeds and otherwise rolls back everything fn did, so a record that
+// fails partway leaves no trace.
+func (im *importer) inTx(ctx context.Context, fn func(gameRepo *postgres.GameRepo, phaseRepo *postgres.PhaseRepo) error) error {
+	tx, err := im.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(im.gameRepo.WithTx(tx), im.phaseRepo.WithTx(tx)); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+// importRecord imports a game record unless it is already in the database.
+// A record is a duplicate if a game with the same content hash exists or,
+// with --skip-existing, if a game was imported under the same external ID;
+// its ID is returned with skipped=true. With --force the existing games are
+// deleted and the record is imported again in their place. When live is set,
+// a record with no winner is imported as an active game instead and is never
+// treated as a duplicate.
+func (im *importer) importRecord(ctx context.Context, rec jsonGameRecord, gameName string) (string, bool, error) {
+	if im.live != nil && rec.Winner == nil {
+		gameID, err := im.importLiveGame(ctx, rec, gameName)
+		return gameID, false, err
+	}
+
+	extID := externalID(im.namePrefix, rec.GameID)
+	byExtID, err := im.gameRepo.FindIDByExternalID(ctx, extID)
+	if err != nil {
+		return "", false, err
+	}
+	if byExtID != "" && im.skipExisting {
+		return byExtID, true, nil
+	}
+
+	hash := contentHash(rec)
+	byHash, err := im.gameRepo.FindIDByContentHash(ctx, hash)
+	if err != nil {
+		return "", false, err
+	}
+	if byHash != "" && !im.force {
+		return byHash, true, nil
+	}
+
+	var gameID string
+	err = im.inTx(ctx, func(gameRepo *postgres.GameRepo, phaseRepo *postgres.PhaseRepo) error {
+		replace := []string{byHash}
+		if im.force && byExtID != byHash {
+			replace = append(replace, byExtID)
+		}
+		for _, id := range replace {
+			if id == "" {
+				continue
+			}
+			if err := gameRepo.Delete(ctx, id); err != nil {
+				return fmt.Errorf("delete duplicate %s: %w", id, err)
+			}
 		}
 
-		gameID, err := importGame(ctx, gameRepo, phaseRepo, userRepo, rec, gameName)
+		var err error
+		gameID, err = importGame(ctx, gameRepo, phaseRepo, im.userRepo, rec, gameName, im.dfenMode)
 		if err != nil {
-			log.Printf("ERROR: import game %d: %v", rec.GameID, err)
-			continue
+			return err
 		}
-
-		imported++
-		log.Printf("imported game %d: %s in %d (id=%s)", imported, winnerStr, rec.FinalYear, gameID)
+		if err := gameRepo.SetContentHash(ctx, gameID, hash); err != nil {
+			return err
+		}
+		if byExtID != "" && !im.force {
+			// Another game, e.g. from an earlier file with the same prefix,
+			// already has this external ID.
+			log.Printf("WARN: game %d: external ID %s belongs to game %s with different content; importing without it", rec.GameID, extID, byExtID)
+			return nil
+		}
+		return gameRepo.SetExternalID(ctx, gameID, extID)
+	})
+	if err != nil {
+		return "", false, err
 	}
+	return gameID, false, nil
+}
 
-	return imported, offset
+// externalID identifies a record by where it came from: the name prefix it
+// was imported under and its selfplay game_id.
+func externalID(namePrefix string, gameID int) string {
+	return fmt.Sprintf("%s:%d", namePrefix, gameID)
+}
+
+// contentHash identifies a game record by its play: the DFEN of every phase
+// and the orders each power submitted in it. The selfplay game_id is left out
+// so a renumbered copy of a game still matches.
+func contentHash(rec jsonGameRecord) string {
+	h := sha256.New()
+	for _, pe := range rec.Phases {
+		fmt.Fprintf(h, "%s\n", pe.DFEN)
+		powers := make([]string, 0, len(pe.Orders))
+		for power := range pe.Orders {
+			powers = append(powers, power)
+		}
+		sort.Strings(powers)
+		for _, power := range powers {
+			fmt.Fprintf(h, "%s:%s\n", power, pe.Orders[power])
+		}
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
 }
 
 // importGame creates a game, players, and phases in the database.
@@ -253,6 +551,121 @@ func importGame(
 	userRepo *postgres.UserRepo,
 	rec jsonGameRecord,
 	gameName string,
+	mode diplomacy.DFENMode,
+) (string, error) {
+	gameID, err := createGame(ctx, gameRepo, userRepo, gameName, nil)
+	if err != nil {
+		return "", err
+	}
+
+	phases, err := resolvedPhases(rec.Phases, len(rec.Phases), mode)
+	if err != nil {
+		return "", err
+	}
+	if err := savePhases(ctx, phaseRepo, gameID, phases, rec.Phases); err != nil {
+		return "", err
+	}
+
+	// Mark game finished.
+	winner := ""
+	if rec.Winner != nil {
+		winner = *rec.Winner
+	}
+	if err := gameRepo.SetFinished(ctx, gameID, winner); err != nil {
+		return "", fmt.Errorf("set finished: %w", err)
+	}
+
+	return gameID, nil
+}
+
+// importLiveGame creates an active game from an in-progress record. All but
+// the last phase are imported resolved; the last phase becomes the current
+// phase with a fresh deadline, its Redis state and timer are set, and the bots
+// submit their orders for it.
+func (im *importer) importLiveGame(ctx context.Context, rec jsonGameRecord, gameName string) (string, error) {
+	live := im.live
+	if len(rec.Phases) == 0 {
+		return "", fmt.Errorf("no phases to continue from")
+	}
+	last := rec.Phases[len(rec.Phases)-1]
+	gs, err := diplomacy.DecodeDFENMode(last.DFEN, im.dfenMode)
+	if err != nil {
+		return "", fmt.Errorf("decode last DFEN: %w", err)
+	}
+	if gs.SupplyCenterCount(diplomacy.Power(live.power)) == 0 && len(gs.UnitsOf(diplomacy.Power(live.power))) == 0 {
+		return "", fmt.Errorf("%s is eliminated in the last phase", live.power)
+	}
+	stateBefore, err := json.Marshal(gs)
+	if err != nil {
+		return "", fmt.Errorf("marshal state_before: %w", err)
+	}
+	history := rec.Phases[:len(rec.Phases)-1]
+	phases, err := resolvedPhases(rec.Phases, len(history), im.dfenMode)
+	if err != nil {
+		return "", err
+	}
+	phaseType := expandPhase(last.Phase)
+	deadline := time.Now().Add(live.phaseDuration(phaseType))
+	phases = append(phases, model.Phase{
+		Year:        last.Year,
+		Season:      expandSeason(last.Season),
+		PhaseType:   phaseType,
+		StateBefore: stateBefore,
+		Deadline:    deadline,
+	})
+
+	var gameID string
+	err = im.inTx(ctx, func(gameRepo *postgres.GameRepo, phaseRepo *postgres.PhaseRepo) error {
+		var err error
+		gameID, err = createGame(ctx, gameRepo, im.userRepo, gameName, live)
+		if err != nil {
+			return err
+		}
+		return savePhases(ctx, phaseRepo, gameID, phases, history)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// The phase service reads the game through its own connections, so it
+	// can only start once the game is committed. If it fails, the game is
+	// deleted again rather than left without its Redis state.
+	if err := live.phaseSvc.InitializeGame(ctx, gameID, gs, deadline); err != nil {
+		return "", im.discardLiveGame(ctx, gameID, fmt.Errorf("initialize live game: %w", err))
+	}
+	if err := live.phaseSvc.SubmitBotOrders(ctx, gameID); err != nil {
+		return "", im.discardLiveGame(ctx, gameID, fmt.Errorf("submit bot orders: %w", err))
+	}
+
+	return gameID, nil
+}
+
+// discardLiveGame deletes a live game that failed to start, along with any
+// Redis state it got, and returns the error that caused it.
+func (im *importer) discardLiveGame(ctx context.Context, gameID string, cause error) error {
+	powers := make([]string, len(powerOrder))
+	for i, p := range powerOrder {
+		powers[i] = string(p)
+	}
+	if err := im.live.cache.DeleteGameData(ctx, gameID, powers); err != nil {
+		log.Printf("WARN: delete redis state of %s: %v", gameID, err)
+	}
+	if err := im.gameRepo.Delete(ctx, gameID); err != nil {
+		log.Printf("WARN: delete game %s: %v", gameID, err)
+	}
+	return cause
+}
+
+// createGame creates a game with one player per power and assigns the powers,
+// returning the game ID. Every power is played by a selfplay bot, except that
+// in a live game the player takes their chosen power and the game uses the
+// live phase durations.
+func createGame(
+	ctx context.Context,
+	gameRepo *postgres.GameRepo,
+	userRepo *postgres.UserRepo,
+	gameName string,
+	live *liveOptions,
 ) (string, error) {
 	// Create bot users for each power.
 	type botInfo struct {
@@ -271,14 +684,29 @@ func importGame(
 	}
 
 	// Create the game.
-	game, err := gameRepo.Create(ctx, gameName, bots[0].userID, "1 hours", "1 hours", "1 hours", "manual")
+	creatorID := bots[0].userID
+	turnDur, retreatDur, buildDur := "1 hours", "1 hours", "1 hours"
+	difficulty := "realpolitik"
+	if live != nil {
+		creatorID = live.playerID
+		turnDur, retreatDur, buildDur = pgInterval(live.turnDur), pgInterval(live.retreatDur), pgInterval(live.buildDur)
+		difficulty = live.difficulty
+	}
+	game, err := gameRepo.Create(ctx, gameName, creatorID, turnDur, retreatDur, buildDur, "manual", "", diplomacy.ScenarioStandard, model.GameRules{})
 	if err != nil {
 		return "", fmt.Errorf("create game: %w", err)
 	}
 
-	// Join all bots.
-	for _, b := range bots {
-		if err := gameRepo.JoinGameAsBot(ctx, game.ID, b.userID, "realpolitik"); err != nil {
+	// Join all bots, seating the live player in place of their power's bot.
+	for i, b := range bots {
+		if live != nil && string(b.power) == live.power {
+			if err := gameRepo.JoinGame(ctx, game.ID, live.playerID); err != nil {
+				return "", fmt.Errorf("join player: %w", err)
+			}
+			bots[i].userID = live.playerID
+			continue
+		}
+		if err := gameRepo.JoinGameAsBot(ctx, game.ID, b.userID, difficulty); err != nil {
 			return "", fmt.Errorf("join bot %s: %w", b.power, err)
 		}
 	}
@@ -292,87 +720,72 @@ func importGame(
 		return "", fmt.Errorf("assign powers: %w", err)
 	}
 
-	// Import phases.
-	for i, pe := range rec.Phases {
-		if err := importPhase(ctx, phaseRepo, game.ID, pe, rec.Phases, i); err != nil {
-			return "", fmt.Errorf("import phase %d: %w", i, err)
-		}
-	}
-
-	// Mark game finished.
-	winner := ""
-	if rec.Winner != nil {
-		winner = *rec.Winner
-	}
-	if err := gameRepo.SetFinished(ctx, game.ID, winner); err != nil {
-		return "", fmt.Errorf("set finished: %w", err)
-	}
-
 	return game.ID, nil
 }
 
-// importPhase creates a single phase record with state_before, state_after, and orders.
-func importPhase(
-	ctx context.Context,
-	phaseRepo *postgres.PhaseRepo,
-	gameID string,
-	pe jsonPhaseEntry,
-	allPhases []jsonPhaseEntry,
-	idx int,
-) error {
-	// Decode DFEN to get state_before.
-	gsBefore, err := diplomacy.DecodeDFEN(pe.DFEN)
-	if err != nil {
-		return fmt.Errorf("decode DFEN: %w", err)
-	}
-	stateBefore, err := json.Marshal(gsBefore)
-	if err != nil {
-		return fmt.Errorf("marshal state_before: %w", err)
-	}
-
-	season := expandSeason(pe.Season)
-	phaseType := expandPhase(pe.Phase)
-
-	deadline := time.Now().Add(-24 * time.Hour) // dummy past deadline
-	phase, err := phaseRepo.CreatePhase(ctx, gameID, pe.Year, season, phaseType, stateBefore, deadline)
-	if err != nil {
-		return fmt.Errorf("create phase: %w", err)
+// pgInterval formats a duration as a PostgreSQL interval, e.g. "1440 minutes".
+func pgInterval(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("%d seconds", int(d.Seconds()))
 	}
+	return fmt.Sprintf("%d minutes", int(d.Minutes()))
+}
 
-	// Compute state_after: use the next phase's DFEN, or for the last phase use the same DFEN.
-	var stateAfter json.RawMessage
-	if idx+1 < len(allPhases) {
-		gsAfter, err := diplomacy.DecodeDFEN(allPhases[idx+1].DFEN)
+// resolvedPhases converts the first n phase entries into resolved phases,
+// decoding their positions as mode says. Each phase's state after is the
+// position of the entry following it, or its own position if that is
+// missing or unreadable (as for the last phase of a finished game).
+func resolvedPhases(entries []jsonPhaseEntry, n int, mode diplomacy.DFENMode) ([]model.Phase, error) {
+	states := make([]json.RawMessage, min(n+1, len(entries)))
+	for i := range states {
+		gs, err := diplomacy.DecodeDFENMode(entries[i].DFEN, mode)
+		if err == nil {
+			states[i], err = json.Marshal(gs)
+		}
 		if err != nil {
-			// Fall back to current state.
-			stateAfter = stateBefore
-		} else {
-			stateAfter, err = json.Marshal(gsAfter)
-			if err != nil {
-				stateAfter = stateBefore
+			if i < n {
+				return nil, fmt.Errorf("phase %d: decode DFEN: %w", i, err)
 			}
+			log.Printf("phase %d: unreadable DFEN, using the previous phase's position as its state after: %v", i, err)
 		}
-	} else {
-		// Last phase: state_after = state_before (game ended).
-		stateAfter = stateBefore
 	}
 
-	if err := phaseRepo.ResolvePhase(ctx, phase.ID, stateAfter); err != nil {
-		return fmt.Errorf("resolve phase: %w", err)
+	deadline := time.Now().Add(-24 * time.Hour) // dummy past deadline
+	phases := make([]model.Phase, n)
+	for i, pe := range entries[:n] {
+		stateAfter := states[i]
+		if i+1 < len(states) && states[i+1] != nil {
+			stateAfter = states[i+1]
+		}
+		phases[i] = model.Phase{
+			Year:        pe.Year,
+			Season:      expandSeason(pe.Season),
+			PhaseType:   expandPhase(pe.Phase),
+			StateBefore: states[i],
+			StateAfter:  stateAfter,
+			Deadline:    deadline,
+		}
 	}
+	return phases, nil
+}
 
-	// Parse and save orders.
-	var modelOrders []model.Order
-	for power, dsonStr := range pe.Orders {
-		orders := parseDSONOrders(dsonStr, power, phase.ID)
-		modelOrders = append(modelOrders, orders...)
+// savePhases inserts a game's phases and then the orders of the entries
+// they were built from, one batch each; entries may be shorter than phases.
+func savePhases(ctx context.Context, phaseRepo *postgres.PhaseRepo, gameID string, phases []model.Phase, entries []jsonPhaseEntry) error {
+	ids, err := phaseRepo.CreatePhases(ctx, gameID, phases)
+	if err != nil {
+		return err
 	}
-	if len(modelOrders) > 0 {
-		if err := phaseRepo.SaveOrders(ctx, modelOrders); err != nil {
-			return fmt.Errorf("save orders: %w", err)
+
+	var orders []model.Order
+	for i, pe := range entries {
+		for power, dsonStr := range pe.Orders {
+			orders = append(orders, parseDSONOrders(dsonStr, power, ids[i])...)
 		}
 	}
-
+	if err := phaseRepo.SaveOrders(ctx, orders); err != nil {
+		return fmt.Errorf("save orders: %w", err)
+	}
 	return nil
 }
 
@@ -575,12 +988,13 @@ func dsonUnitType(s string) string {
 	return "army"
 }
 
-// splitLocation splits "stp/nc" into ("stp", "nc") or "vie" into ("vie", "").
+// splitLocation splits "stp/nc" into ("stp", "nc") or "vie" into ("vie", ""),
+// canonicalizing province and coast aliases.
 func splitLocation(s string) (string, string) {
 	if idx := strings.IndexByte(s, '/'); idx >= 0 {
-		return s[:idx], s[idx+1:]
+		return diplomacy.CanonicalProvince(s[:idx]), string(diplomacy.CanonicalCoast(s[idx+1:]))
 	}
-	return s, ""
+	return diplomacy.CanonicalProvince(s), ""
 }
 
 // expandSeason converts "s"/"f" to "spring"/"fall".
@@ -1,7 +1,13 @@
 package main
 
 import (
+	"context"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
 )
 
 func TestExpandSeason(t *testing.T) {
@@ -47,6 +53,8 @@ func TestSplitLocation(t *testing.T) {
 		{"stp/nc", "stp", "nc"},
 		{"spa/sc", "spa", "sc"},
 		{"bul/ec", "bul", "ec"},
+		{"lyo", "gol", ""},
+		{"stp/ncs", "stp", "nc"},
 	}
 	for _, tt := range tests {
 		prov, coast := splitLocation(tt.in)
@@ -219,3 +227,171 @@ func TestParseDSONOrders_Complex(t *testing.T) {
 		t.Errorf("order 2: got %+v", orders[2])
 	}
 }
+
+func TestContentHash(t *testing.T) {
+	base := func() jsonGameRecord {
+		return jsonGameRecord{
+			GameID: 1,
+			Phases: []jsonPhaseEntry{
+				{DFEN: "1901sm/a", Orders: map[string]string{"austria": "A vie - gal", "england": "F lon - nth"}},
+				{DFEN: "1901fm/b", Orders: map[string]string{"austria": "A gal - war"}},
+			},
+		}
+	}
+
+	h := contentHash(base())
+	if len(h) != 64 {
+		t.Fatalf("contentHash length = %d, want 64", len(h))
+	}
+
+	renumbered := base()
+	renumbered.GameID = 42
+	if got := contentHash(renumbered); got != h {
+		t.Errorf("hash changed with game_id: %s != %s", got, h)
+	}
+
+	changedOrder := base()
+	changedOrder.Phases[1].Orders["austria"] = "A gal H"
+	if contentHash(changedOrder) == h {
+		t.Error("hash unchanged after changing an order")
+	}
+
+	changedDFEN := base()
+	changedDFEN.Phases[0].DFEN = "1901sm/c"
+	if contentHash(changedDFEN) == h {
+		t.Error("hash unchanged after changing a DFEN")
+	}
+
+	// Orders moved to another phase must not collide.
+	shifted := base()
+	shifted.Phases[0].Orders["austria"] = "A gal - war"
+	shifted.Phases[1].Orders["austria"] = "A vie - gal"
+	if contentHash(shifted) == h {
+		t.Error("hash unchanged after swapping orders between phases")
+	}
+}
+
+func TestPgInterval(t *testing.T) {
+	tests := []struct {
+		in   time.Duration
+		want string
+	}{
+		{30 * time.Second, "30 seconds"},
+		{5 * time.Minute, "5 minutes"},
+		{24 * time.Hour, "1440 minutes"},
+	}
+	for _, tt := range tests {
+		if got := pgInterval(tt.in); got != tt.want {
+			t.Errorf("pgInterval(%v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLivePhaseDuration(t *testing.T) {
+	live := &liveOptions{turnDur: 24 * time.Hour, retreatDur: time.Hour, buildDur: 2 * time.Hour}
+	tests := []struct {
+		phase string
+		want  time.Duration
+	}{
+		{"movement", 24 * time.Hour},
+		{"retreat", time.Hour},
+		{"build", 2 * time.Hour},
+	}
+	for _, tt := range tests {
+		if got := live.phaseDuration(tt.phase); got != tt.want {
+			t.Errorf("phaseDuration(%q) = %v, want %v", tt.phase, got, tt.want)
+		}
+	}
+}
+
+func TestExternalID(t *testing.T) {
+	if got := externalID("selfplay", 7); got != "selfplay:7" {
+		t.Errorf("externalID = %q, want selfplay:7", got)
+	}
+}
+
+func TestProgress(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "games.jsonl")
+	// Lines that import nothing, so no database is needed; the last has no
+	// trailing newline.
+	data := "\n{not json}\n\n{still not"
+	if err := os.WriteFile(input, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if offset, err := readProgress(input + ".progress"); err != nil || offset != 0 {
+		t.Fatalf("expected no progress yet, got %d, %v", offset, err)
+	}
+
+	f, err := os.Open(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	im := &importer{progressFile: input + ".progress"}
+	offset, err := im.readLines(context.Background(), f, 0, false)
+	if err != nil {
+		t.Fatalf("readLines: %v", err)
+	}
+	if want := int64(len(data) - len("{still not")); offset != want {
+		t.Errorf("follow offset = %d, want %d (unterminated line left unread)", offset, want)
+	}
+	if saved, _ := readProgress(im.progressFile); saved != offset {
+		t.Errorf("saved progress = %d, want %d", saved, offset)
+	}
+
+	offset, err = im.readLines(context.Background(), f, offset, true)
+	if err != nil {
+		t.Fatalf("readLines: %v", err)
+	}
+	if offset != int64(len(data)) {
+		t.Errorf("batch offset = %d, want %d", offset, len(data))
+	}
+	if saved, _ := readProgress(im.progressFile); saved != int64(len(data)) {
+		t.Errorf("saved progress = %d, want %d", saved, len(data))
+	}
+
+	if _, err := openInput(input, int64(len(data))+1); err == nil {
+		t.Error("expected an error resuming past the end of the input")
+	}
+	os.WriteFile(im.progressFile, []byte("soon\n"), 0o644)
+	if _, err := readProgress(im.progressFile); err == nil {
+		t.Error("expected an error for a malformed progress file")
+	}
+}
+
+func TestResolvedPhases(t *testing.T) {
+	initial := diplomacy.EncodeDFEN(diplomacy.NewInitialState())
+	entries := []jsonPhaseEntry{
+		{DFEN: initial, Year: 1901, Season: "s", Phase: "m"},
+		{DFEN: initial, Year: 1901, Season: "f", Phase: "m"},
+		{DFEN: "unreadable", Year: 1901, Season: "f", Phase: "r"},
+	}
+
+	phases, err := resolvedPhases(entries, 2, diplomacy.DFENGrammar)
+	if err != nil {
+		t.Fatalf("resolvedPhases: %v", err)
+	}
+	if len(phases) != 2 || phases[1].Season != "fall" || phases[1].PhaseType != "movement" {
+		t.Fatalf("unexpected phases %+v", phases)
+	}
+	// An unreadable next position falls back to the phase's own.
+	if string(phases[1].StateAfter) != string(phases[1].StateBefore) || phases[0].StateAfter == nil {
+		t.Error("expected every phase to have a state after")
+	}
+
+	if _, err := resolvedPhases(entries, 3, diplomacy.DFENGrammar); err == nil {
+		t.Error("expected an error for an unreadable phase position")
+	}
+
+	// An army at sea is well-formed but rejected in strict mode.
+	entries[1].DFEN = "1901fm/Aanth/Avie/-"
+	if _, err := resolvedPhases(entries, 2, diplomacy.DFENGrammar); err != nil {
+		t.Errorf("grammar-only decode rejected a well-formed position: %v", err)
+	}
+	if _, err := resolvedPhases(entries, 2, diplomacy.DFENStrict); err == nil {
+		t.Error("expected strict decoding to reject an army at sea")
+	}
+}
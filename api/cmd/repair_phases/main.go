@@ -0,0 +1,277 @@
+// Command repair_phases fixes replay discontinuities in imported self-play
+// games. When import_selfplay could not decode a phase's successor DFEN it
+// stored state_before as the phase's state_after; this tool re-derives those
+// states by resolving the stored orders against state_before with the current
+// engine and writes the result back in place.
+//
+// Usage:
+//
+//	go run ./cmd/repair_phases/ --db postgres://...
+//	go run ./cmd/repair_phases/ --db postgres://... --game <game-id>
+//	go run ./cmd/repair_phases/ --db postgres://... --dry-run
+//
+// Without --game, every game whose name starts with --name-prefix is checked.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	_ "github.com/lib/pq"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/internal/repository/postgres"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// repairStats counts the outcome of each phase inspected.
+type repairStats struct {
+	games    int
+	checked  int // resolved phases whose state_after equals state_before
+	repaired int
+	failed   int
+}
+
+func main() {
+	dbURL := flag.String("db", os.Getenv("DATABASE_URL"), "Postgres connection URL")
+	gameID := flag.String("game", "", "Repair only this game")
+	namePrefix := flag.String("name-prefix", "selfplay", "Repair games whose name starts with this prefix")
+	dryRun := flag.Bool("dry-run", false, "Report phases that would be repaired without writing them")
+	flag.Parse()
+
+	if *dbURL == "" {
+		log.Fatal("--db or DATABASE_URL is required")
+	}
+
+	db, err := postgres.Connect(*dbURL)
+	if err != nil {
+		log.Fatalf("connect to postgres: %v", err)
+	}
+	defer db.Close()
+
+	gameRepo := postgres.NewGameRepo(db)
+	phaseRepo := postgres.NewPhaseRepo(db)
+	ctx := context.Background()
+
+	gameIDs := []string{*gameID}
+	if *gameID == "" {
+		gameIDs, err = gameRepo.ListIDsByNamePrefix(ctx, *namePrefix)
+		if err != nil {
+			log.Fatalf("list games: %v", err)
+		}
+	}
+
+	var stats repairStats
+	for _, id := range gameIDs {
+		if err := repairGame(ctx, phaseRepo, id, *dryRun, &stats); err != nil {
+			log.Fatalf("game %s: %v", id, err)
+		}
+	}
+
+	verb := "repaired"
+	if *dryRun {
+		verb = "would repair"
+	}
+	log.Printf("done: checked %d games, %d suspect phases, %s %d, failed %d",
+		stats.games, stats.checked, verb, stats.repaired, stats.failed)
+}
+
+// repairGame re-derives state_after for every resolved phase of a game whose
+// stored state_after is a copy of its state_before.
+func repairGame(ctx context.Context, phaseRepo *postgres.PhaseRepo, gameID string, dryRun bool, stats *repairStats) error {
+	phases, err := phaseRepo.ListPhases(ctx, gameID)
+	if err != nil {
+		return err
+	}
+	stats.games++
+
+	for _, phase := range phases {
+		if phase.ResolvedAt == nil || !bytes.Equal(phase.StateAfter, phase.StateBefore) {
+			continue
+		}
+		stats.checked++
+
+		orders, err := phaseRepo.OrdersByPhase(ctx, phase.ID)
+		if err != nil {
+			return err
+		}
+		if len(orders) == 0 {
+			continue
+		}
+
+		label := fmt.Sprintf("%s %d %s %s", gameID, phase.Year, phase.Season, phase.PhaseType)
+		stateAfter, changed, err := rederiveStateAfter(phase, orders)
+		if err != nil {
+			log.Printf("FAILED %s: %v", label, err)
+			stats.failed++
+			continue
+		}
+		if !changed {
+			continue // the orders really did leave the board unchanged
+		}
+
+		if !dryRun {
+			if err := phaseRepo.UpdateStateAfter(ctx, phase.ID, stateAfter); err != nil {
+				return err
+			}
+		}
+		log.Printf("repaired %s (%d orders)", label, len(orders))
+		stats.repaired++
+	}
+	return nil
+}
+
+// rederiveStateAfter resolves a phase's stored orders against its
+// state_before the same way the phase service does, and reports whether the
+// resulting state differs from state_before.
+func rederiveStateAfter(phase model.Phase, orders []model.Order) (json.RawMessage, bool, error) {
+	var gs diplomacy.GameState
+	if err := json.Unmarshal(phase.StateBefore, &gs); err != nil {
+		return nil, false, fmt.Errorf("unmarshal state before: %w", err)
+	}
+	before := gs.Clone()
+	m := diplomacy.StandardMap()
+
+	switch phase.PhaseType {
+	case "movement":
+		results, dislodged := diplomacy.ResolveOrders(movementOrders(orders, &gs, m), &gs, m)
+		diplomacy.ApplyResolution(&gs, m, results, dislodged)
+	case "retreat":
+		results := diplomacy.ResolveRetreats(retreatOrders(orders, &gs, m), &gs, m)
+		diplomacy.ApplyRetreats(&gs, results, m)
+	case "build":
+		results := diplomacy.ResolveBuildOrders(buildOrders(orders, m), &gs, m)
+		diplomacy.ApplyBuildOrders(&gs, results)
+	default:
+		return nil, false, fmt.Errorf("unknown phase type %q", phase.PhaseType)
+	}
+
+	if gs.IsLastSeason() && (gs.Phase == diplomacy.PhaseMovement || gs.Phase == diplomacy.PhaseRetreat) {
+		diplomacy.UpdateSupplyCenterOwnership(&gs)
+	}
+
+	after, err := json.Marshal(&gs)
+	if err != nil {
+		return nil, false, fmt.Errorf("marshal state after: %w", err)
+	}
+	unchanged, err := json.Marshal(before)
+	if err != nil {
+		return nil, false, fmt.Errorf("marshal state before: %w", err)
+	}
+	return after, !bytes.Equal(after, unchanged), nil
+}
+
+// movementOrders converts stored movement orders to engine orders. Imported
+// orders drop coasts, so the unit's coast is taken from the board and the
+// target coast is inferred when the fleet can reach only one.
+func movementOrders(orders []model.Order, gs *diplomacy.GameState, m *diplomacy.DiplomacyMap) []diplomacy.Order {
+	var out []diplomacy.Order
+	for _, o := range orders {
+		eo := diplomacy.Order{
+			UnitType:    parseUnitType(o.UnitType),
+			Power:       diplomacy.Power(o.Power),
+			Location:    o.Location,
+			Target:      o.Target,
+			AuxLoc:      o.AuxLoc,
+			AuxTarget:   o.AuxTarget,
+			AuxUnitType: parseUnitType(o.AuxUnitType),
+		}
+		switch o.OrderType {
+		case "move":
+			eo.Type = diplomacy.OrderMove
+		case "support":
+			eo.Type = diplomacy.OrderSupport
+		case "convoy":
+			eo.Type = diplomacy.OrderConvoy
+		default:
+			eo.Type = diplomacy.OrderHold
+		}
+		if u := gs.UnitAt(o.Location); u != nil {
+			eo.Coast = u.Coast
+		}
+		if eo.Type == diplomacy.OrderMove && eo.UnitType == diplomacy.Fleet {
+			eo.TargetCoast = inferCoast(m, eo.Location, eo.Coast, eo.Target)
+		}
+		out = append(out, eo)
+	}
+	return out
+}
+
+// retreatOrders converts stored retreat orders to engine retreat orders.
+func retreatOrders(orders []model.Order, gs *diplomacy.GameState, m *diplomacy.DiplomacyMap) []diplomacy.RetreatOrder {
+	var out []diplomacy.RetreatOrder
+	for _, o := range orders {
+		ro := diplomacy.RetreatOrder{
+			UnitType: parseUnitType(o.UnitType),
+			Power:    diplomacy.Power(o.Power),
+			Location: o.Location,
+			Type:     diplomacy.RetreatDisband,
+		}
+		for _, d := range gs.Dislodged {
+			if d.DislodgedFrom == o.Location && d.Unit.Power == ro.Power {
+				ro.Coast = d.Unit.Coast
+			}
+		}
+		if o.OrderType == "retreat_move" {
+			ro.Type = diplomacy.RetreatMove
+			ro.Target = o.Target
+			if ro.UnitType == diplomacy.Fleet {
+				ro.TargetCoast = inferCoast(m, ro.Location, ro.Coast, ro.Target)
+			}
+		}
+		out = append(out, ro)
+	}
+	return out
+}
+
+// buildOrders converts stored build orders to engine build orders. Waives
+// need no order. Fleets built on split-coast provinces get the first coast,
+// since the import does not record which one was chosen.
+func buildOrders(orders []model.Order, m *diplomacy.DiplomacyMap) []diplomacy.BuildOrder {
+	var out []diplomacy.BuildOrder
+	for _, o := range orders {
+		bo := diplomacy.BuildOrder{
+			Power:    diplomacy.Power(o.Power),
+			UnitType: parseUnitType(o.UnitType),
+			Location: o.Location,
+		}
+		switch o.OrderType {
+		case "build":
+			bo.Type = diplomacy.BuildUnit
+			if prov := m.Provinces[o.Location]; bo.UnitType == diplomacy.Fleet && prov != nil && len(prov.Coasts) > 0 {
+				bo.Coast = prov.Coasts[0]
+			}
+		case "disband", "retreat_disband": // the import stores build-phase disbands as retreat_disband
+			bo.Type = diplomacy.DisbandUnit
+		default:
+			continue
+		}
+		out = append(out, bo)
+	}
+	return out
+}
+
+// inferCoast returns the coast of dst a fleet at src must be moving to, or
+// NoCoast if dst has no split coasts or the coast is ambiguous.
+func inferCoast(m *diplomacy.DiplomacyMap, src string, srcCoast diplomacy.Coast, dst string) diplomacy.Coast {
+	if !m.HasCoasts(dst) {
+		return diplomacy.NoCoast
+	}
+	coasts := m.FleetCoastsTo(src, srcCoast, dst)
+	if len(coasts) != 1 {
+		return diplomacy.NoCoast
+	}
+	return coasts[0]
+}
+
+func parseUnitType(s string) diplomacy.UnitType {
+	if s == "fleet" {
+		return diplomacy.Fleet
+	}
+	return diplomacy.Army
+}
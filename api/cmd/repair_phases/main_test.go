@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+func TestRederiveStateAfter_Movement(t *testing.T) {
+	before, err := json.Marshal(diplomacy.NewInitialState())
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	phase := model.Phase{Year: 1901, Season: "spring", PhaseType: "movement", StateBefore: before, StateAfter: before, ResolvedAt: &now}
+	orders := []model.Order{
+		{Power: "france", UnitType: "army", Location: "par", OrderType: "move", Target: "bur"},
+		{Power: "russia", UnitType: "fleet", Location: "stp", OrderType: "move", Target: "bot"},
+		{Power: "england", UnitType: "fleet", Location: "lon", OrderType: "hold"},
+	}
+
+	raw, changed, err := rederiveStateAfter(phase, orders)
+	if err != nil {
+		t.Fatalf("rederiveStateAfter: %v", err)
+	}
+	if !changed {
+		t.Fatal("expected state to change")
+	}
+	var gs diplomacy.GameState
+	if err := json.Unmarshal(raw, &gs); err != nil {
+		t.Fatal(err)
+	}
+	if u := gs.UnitAt("bur"); u == nil || u.Power != diplomacy.France {
+		t.Errorf("expected French army in bur, got %+v", u)
+	}
+	if u := gs.UnitAt("bot"); u == nil || u.Power != diplomacy.Russia {
+		t.Errorf("expected Russian fleet in bot, got %+v", u)
+	}
+	if gs.UnitAt("par") != nil {
+		t.Error("expected par to be empty")
+	}
+}
+
+func TestRederiveStateAfter_AllHoldsUnchanged(t *testing.T) {
+	before, _ := json.Marshal(diplomacy.NewInitialState())
+	phase := model.Phase{Year: 1901, Season: "spring", PhaseType: "movement", StateBefore: before}
+	orders := []model.Order{{Power: "austria", UnitType: "army", Location: "vie", OrderType: "hold"}}
+
+	_, changed, err := rederiveStateAfter(phase, orders)
+	if err != nil {
+		t.Fatalf("rederiveStateAfter: %v", err)
+	}
+	if changed {
+		t.Error("expected holds to leave the state unchanged")
+	}
+}
+
+func TestInferCoast(t *testing.T) {
+	m := diplomacy.StandardMap()
+	tests := []struct {
+		src, dst string
+		want     diplomacy.Coast
+	}{
+		{"mao", "spa", diplomacy.NoCoast}, // both coasts reachable
+		{"gol", "spa", diplomacy.SouthCoast},
+		{"bar", "stp", diplomacy.NorthCoast},
+		{"eng", "bre", diplomacy.NoCoast}, // no split coasts
+	}
+	for _, tt := range tests {
+		if got := inferCoast(m, tt.src, diplomacy.NoCoast, tt.dst); got != tt.want {
+			t.Errorf("inferCoast(%s, %s) = %q, want %q", tt.src, tt.dst, got, tt.want)
+		}
+	}
+}
+
+func TestBuildOrders(t *testing.T) {
+	m := diplomacy.StandardMap()
+	got := buildOrders([]model.Order{
+		{Power: "russia", UnitType: "fleet", Location: "stp", OrderType: "build"},
+		{Power: "turkey", UnitType: "army", Location: "con", OrderType: "retreat_disband"},
+		{Power: "italy", OrderType: "waive"},
+	}, m)
+	if len(got) != 2 {
+		t.Fatalf("got %d build orders, want 2", len(got))
+	}
+	if got[0].Type != diplomacy.BuildUnit || got[0].Coast == diplomacy.NoCoast {
+		t.Errorf("fleet build on stp = %+v, want a build with a coast", got[0])
+	}
+	if got[1].Type != diplomacy.DisbandUnit {
+		t.Errorf("disband = %+v, want DisbandUnit", got[1])
+	}
+}
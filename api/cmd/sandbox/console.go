@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/freeeve/polite-betrayal/api/internal/bot"
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+const consoleHelp = `Enter this phase's orders in DSON, separated by ";", e.g.
+  A par - bur ; A mar S A par - bur ; F bre - mao
+  A vie R boh          (retreat)      A par B ; F bre B ; W   (builds)
+An empty line submits nothing: units hold, dislodged units disband and
+missing builds are waived. Commands: board, help, quit.
+`
+
+// consoleStrategy is a bot.Strategy whose orders are typed at the terminal.
+type consoleStrategy struct {
+	power diplomacy.Power
+	in    *bufio.Scanner
+	out   io.Writer
+	quit  func() // stops the game
+	done  bool
+}
+
+func newConsoleStrategy(power diplomacy.Power, in io.Reader, out io.Writer, quit func()) *consoleStrategy {
+	return &consoleStrategy{power: power, in: bufio.NewScanner(in), out: out, quit: quit}
+}
+
+func (c *consoleStrategy) Name() string { return "console" }
+
+func (c *consoleStrategy) GenerateMovementOrders(gs *diplomacy.GameState, _ diplomacy.Power, m *diplomacy.DiplomacyMap) []bot.OrderInput {
+	return c.prompt(gs, m)
+}
+
+func (c *consoleStrategy) GenerateRetreatOrders(gs *diplomacy.GameState, _ diplomacy.Power, m *diplomacy.DiplomacyMap) []bot.OrderInput {
+	return c.prompt(gs, m)
+}
+
+func (c *consoleStrategy) GenerateBuildOrders(gs *diplomacy.GameState, _ diplomacy.Power, m *diplomacy.DiplomacyMap) []bot.OrderInput {
+	return c.prompt(gs, m)
+}
+
+// prompt shows the power's position and reads orders until a line parses
+// and validates, or the player quits.
+func (c *consoleStrategy) prompt(gs *diplomacy.GameState, m *diplomacy.DiplomacyMap) []bot.OrderInput {
+	if c.done {
+		return nil
+	}
+	c.printPosition(gs)
+	for {
+		fmt.Fprintf(c.out, "%s %s> ", diplomacy.EncodeDFENPhase(gs), c.power)
+		if !c.in.Scan() {
+			c.stop()
+			return nil
+		}
+		line := strings.TrimSpace(c.in.Text())
+		switch line {
+		case "":
+			return nil
+		case "quit", "exit":
+			c.stop()
+			return nil
+		case "help", "?":
+			fmt.Fprint(c.out, consoleHelp)
+			continue
+		case "board":
+			printBoard(c.out, gs)
+			continue
+		}
+		orders, err := parseOrders(line, c.power, gs, m)
+		if err != nil {
+			fmt.Fprintf(c.out, "error: %v\n", err)
+			continue
+		}
+		return bot.DSONInputs(orders, c.power, gs.Phase)
+	}
+}
+
+func (c *consoleStrategy) stop() {
+	fmt.Fprintln(c.out)
+	c.done = true
+	c.quit()
+}
+
+// parseOrders parses a line of DSON and validates each order for power in
+// the current phase.
+func parseOrders(line string, power diplomacy.Power, gs *diplomacy.GameState, m *diplomacy.DiplomacyMap) ([]diplomacy.DSONOrder, error) {
+	orders, err := diplomacy.ParseDSON(line)
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range orders {
+		text := diplomacy.FormatDSON([]diplomacy.DSONOrder{o})
+		if !bot.DSONAllowedIn(o.Type, gs.Phase) {
+			return nil, fmt.Errorf("%q is not a %s order", text, gs.Phase)
+		}
+		switch gs.Phase {
+		case diplomacy.PhaseRetreat:
+			err = diplomacy.ValidateRetreatOrder(diplomacy.DSONToRetreatOrder(o, power), gs, m)
+		case diplomacy.PhaseBuild:
+			err = diplomacy.ValidateBuildOrder(diplomacy.DSONToBuildOrder(o, power), gs, m)
+		default:
+			err = diplomacy.ValidateOrder(diplomacy.DSONToOrder(o, power), gs, m)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", text, err)
+		}
+	}
+	return orders, nil
+}
+
+// printPosition shows what the power has to order this phase.
+func (c *consoleStrategy) printPosition(gs *diplomacy.GameState) {
+	fmt.Fprintf(c.out, "\n== %s %d %s: %s ==\n", gs.Season, gs.Year, gs.Phase, c.power)
+	switch gs.Phase {
+	case diplomacy.PhaseRetreat:
+		for _, d := range gs.Dislodged {
+			if d.Unit.Power == c.power {
+				fmt.Fprintf(c.out, "dislodged: %s (attacked from %s)\n", unitString(d.Unit), d.AttackerFrom)
+			}
+		}
+	case diplomacy.PhaseBuild:
+		adj := diplomacy.Adjustments(gs)[c.power]
+		fmt.Fprintf(c.out, "centers %d, units %d", adj.SupplyCenters, adj.Units)
+		if adj.Builds > 0 {
+			fmt.Fprintf(c.out, ", builds %d in %s", adj.Builds, strings.Join(adj.OpenHomeCenters, " "))
+		}
+		if adj.Disbands > 0 {
+			fmt.Fprintf(c.out, ", disbands %d", adj.Disbands)
+		}
+		fmt.Fprintln(c.out)
+	default:
+		var units []string
+		for _, u := range gs.UnitsOf(c.power) {
+			units = append(units, unitString(u))
+		}
+		fmt.Fprintf(c.out, "units: %s\ncenters: %d\n", strings.Join(units, ", "), gs.SupplyCenterCount(c.power))
+	}
+}
+
+// printBoard lists every power's units and supply centers.
+func printBoard(out io.Writer, gs *diplomacy.GameState) {
+	for _, p := range diplomacy.AllPowers() {
+		var units, centers []string
+		for _, u := range gs.UnitsOf(p) {
+			units = append(units, unitString(u))
+		}
+		for sc, owner := range gs.SupplyCenters {
+			if owner == p {
+				centers = append(centers, sc)
+			}
+		}
+		if len(units) == 0 && len(centers) == 0 {
+			continue
+		}
+		sort.Strings(centers)
+		fmt.Fprintf(out, "%-8s %2d: %s | %s\n", p, len(centers), strings.Join(units, ", "), strings.Join(centers, " "))
+	}
+}
+
+// printResults lists a resolved phase's orders with their results.
+func printResults(out io.Writer, gs *diplomacy.GameState, orders []model.Order) {
+	fmt.Fprintf(out, "-- %s results --\n", diplomacy.EncodeDFENPhase(gs))
+	sort.SliceStable(orders, func(i, j int) bool {
+		if orders[i].Power != orders[j].Power {
+			return orders[i].Power < orders[j].Power
+		}
+		return orders[i].Location < orders[j].Location
+	})
+	for _, o := range orders {
+		fmt.Fprintf(out, "%-8s %-22s %s\n", o.Power, orderString(o), o.Result)
+	}
+}
+
+// unitString formats a unit as "A par" or "F stp/sc".
+func unitString(u diplomacy.Unit) string {
+	s := "A " + u.Province
+	if u.Type == diplomacy.Fleet {
+		s = "F " + u.Province
+	}
+	if u.Coast != diplomacy.NoCoast {
+		s += "/" + string(u.Coast)
+	}
+	return s
+}
+
+// orderString formats a resolved order in DSON-like notation.
+func orderString(o model.Order) string {
+	unit := "A " + o.Location
+	if o.UnitType == diplomacy.Fleet.String() {
+		unit = "F " + o.Location
+	}
+	switch o.OrderType {
+	case "move":
+		return unit + " - " + o.Target
+	case "support":
+		if o.AuxTarget == "" || o.AuxTarget == o.AuxLoc {
+			return unit + " S " + o.AuxLoc + " H"
+		}
+		return unit + " S " + o.AuxLoc + " - " + o.AuxTarget
+	case "convoy":
+		return unit + " C A " + o.AuxLoc + " - " + o.AuxTarget
+	case "retreat_move":
+		return unit + " R " + o.Target
+	case "retreat_disband", "disband":
+		return unit + " D"
+	case "build":
+		return unit + " B"
+	default:
+		return unit + " H"
+	}
+}
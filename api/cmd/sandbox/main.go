@@ -0,0 +1,92 @@
+// Command sandbox plays a game entirely in memory, with no Postgres or Redis:
+// you type DSON orders for one power at the terminal and bots play the rest.
+// It is meant for stepping through positions while debugging strategies.
+//
+// Usage:
+//
+//	go run ./cmd/sandbox/ --power france
+//	go run ./cmd/sandbox/ --power england --bots "germany=hard,*=medium" --seed 42
+//	go run ./cmd/sandbox/ --power france --quiet < orders.txt
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/freeeve/polite-betrayal/api/internal/bot"
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+func main() {
+	power := flag.String("power", "france", "power you play")
+	bots := flag.String("bots", "*=easy", "strategies for the other powers (e.g. germany=hard,*=medium)")
+	maxYear := flag.Int("max-year", 1920, "year the game ends in a draw")
+	seed := flag.Int64("seed", 0, "bot RNG seed (0 = random)")
+	quiet := flag.Bool("quiet", false, "don't print every power's orders after each phase")
+	flag.Parse()
+
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: "15:04:05"})
+	zerolog.SetGlobalLevel(zerolog.WarnLevel)
+
+	if err := bot.RegisterStrategyVariants(os.Getenv("BOT_STRATEGIES")); err != nil {
+		log.Fatal().Err(err).Msg("Invalid BOT_STRATEGIES")
+	}
+	human := diplomacy.Power(*power)
+	if !slices.Contains(diplomacy.AllPowers(), human) {
+		log.Fatal().Str("power", *power).Msg("Unknown power")
+	}
+	powers := bot.ParsePowerConfig(*bots)
+	for p, name := range powers {
+		if _, ok := bot.LookupStrategy(name); !ok && p != human {
+			log.Fatal().Str("power", string(p)).Str("strategy", name).Msg("Unknown strategy")
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fmt.Print(consoleHelp)
+	console := newConsoleStrategy(human, os.Stdin, os.Stdout, cancel)
+	result, err := bot.RunGame(ctx, bot.ArenaConfig{
+		GameName:    "sandbox",
+		PowerConfig: powers,
+		MaxYear:     *maxYear,
+		Seed:        *seed,
+		DryRun:      true,
+		Strategies:  map[diplomacy.Power]bot.Strategy{human: console},
+		OnPhase: func(gs *diplomacy.GameState, orders []model.Order) {
+			if !*quiet {
+				printResults(os.Stdout, gs, orders)
+			}
+		},
+	}, nil, nil, nil)
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	if err != nil {
+		log.Fatal().Err(err).Msg("Sandbox game failed")
+	}
+
+	if result.Winner != "" {
+		fmt.Printf("\n%s wins in %s %d\n", result.Winner, result.FinalSeason, result.FinalYear)
+	} else {
+		fmt.Printf("\nDraw at %s %d\n", result.FinalSeason, result.FinalYear)
+	}
+	names := make([]string, 0, len(result.SCCounts))
+	for p := range result.SCCounts {
+		names = append(names, p)
+	}
+	sort.Slice(names, func(i, j int) bool { return result.SCCounts[names[i]] > result.SCCounts[names[j]] })
+	for _, p := range names {
+		fmt.Printf("  %-8s %2d\n", p, result.SCCounts[p])
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+func TestParseOrders(t *testing.T) {
+	gs := diplomacy.NewInitialState()
+	m := diplomacy.StandardMap()
+
+	orders, err := parseOrders("A par - bur ; A mar S A par - bur ; F bre - mao", diplomacy.France, gs, m)
+	if err != nil {
+		t.Fatalf("parseOrders: %v", err)
+	}
+	if len(orders) != 3 {
+		t.Errorf("expected 3 orders, got %d", len(orders))
+	}
+
+	for _, line := range []string{
+		"A par -",           // malformed
+		"F bre - mun",       // fleet inland
+		"A ber - sil",       // not france's unit
+		"A par B",           // build in a movement phase
+		"A par - bur ; A x", // one bad order rejects the line
+	} {
+		if _, err := parseOrders(line, diplomacy.France, gs, m); err == nil {
+			t.Errorf("parseOrders(%q) succeeded, want error", line)
+		}
+	}
+}
+
+func TestConsoleStrategyPrompt(t *testing.T) {
+	gs := diplomacy.NewInitialState()
+	m := diplomacy.StandardMap()
+	in := strings.NewReader("board\nF bre - mun\nF bre - mao\nquit\n")
+	var out strings.Builder
+	quit := false
+	c := newConsoleStrategy(diplomacy.France, in, &out, func() { quit = true })
+
+	orders := c.GenerateMovementOrders(gs, diplomacy.France, m)
+	if len(orders) != 1 || orders[0].Location != "bre" || orders[0].Target != "mao" {
+		t.Errorf("orders = %+v, want F bre - mao", orders)
+	}
+	if !strings.Contains(out.String(), "error:") || !strings.Contains(out.String(), "germany") {
+		t.Errorf("expected the board and a validation error in output:\n%s", out.String())
+	}
+
+	if orders := c.GenerateMovementOrders(gs, diplomacy.France, m); orders != nil || !quit {
+		t.Errorf("quit: orders = %v, quit = %v", orders, quit)
+	}
+	if orders := c.GenerateBuildOrders(gs, diplomacy.France, m); orders != nil {
+		t.Errorf("prompt after quit returned %v", orders)
+	}
+}
+
+func TestOrderString(t *testing.T) {
+	tests := map[string]model.Order{
+		"A par - bur":         {UnitType: "army", Location: "par", OrderType: "move", Target: "bur"},
+		"F bre H":             {UnitType: "fleet", Location: "bre", OrderType: "hold"},
+		"A mar S par - bur":   {UnitType: "army", Location: "mar", OrderType: "support", AuxLoc: "par", AuxTarget: "bur"},
+		"F eng C A lon - bre": {UnitType: "fleet", Location: "eng", OrderType: "convoy", AuxLoc: "lon", AuxTarget: "bre"},
+		"A mun R boh":         {UnitType: "army", Location: "mun", OrderType: "retreat_move", Target: "boh"},
+	}
+	for want, o := range tests {
+		if got := orderString(o); got != want {
+			t.Errorf("orderString(%+v) = %q, want %q", o, got, want)
+		}
+	}
+}
@@ -0,0 +1,323 @@
+// Command seed fills a local database with demo data: a handful of users,
+// a few finished games played out by bots, and one active game left in
+// spring 1905 with its bots' orders in and a few messages exchanged. The
+// data comes from the fixtures embedded next to this file, and seeding the
+// same database twice skips the games that are already there.
+//
+// The demo users sign in through the dev login (DEV_MODE=true), e.g.
+// /auth/dev?name=alice. The database must already be migrated.
+//
+// Usage:
+//
+//	go run ./cmd/seed/ --db postgres://... --redis redis://...
+//	go run ./cmd/seed/ --db postgres://... --only finished
+package main
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	_ "github.com/lib/pq"
+
+	"github.com/freeeve/polite-betrayal/api/internal/bot"
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/internal/repository/postgres"
+	"github.com/freeeve/polite-betrayal/api/internal/repository/redis"
+	"github.com/freeeve/polite-betrayal/api/internal/service"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+//go:embed fixtures.json
+var fixturesJSON []byte
+
+// fixtures is the demo dataset.
+type fixtures struct {
+	Users    []string         `json:"users"`
+	Finished []gameFixture    `json:"finished"`
+	Active   gameFixture      `json:"active"`
+	Messages []messageFixture `json:"messages"`
+}
+
+// gameFixture describes a game for the bots to play. Players seats demo
+// users by power; their orders are played by the bots too.
+type gameFixture struct {
+	Name     string            `json:"name"`
+	Seed     int64             `json:"seed"`
+	Bots     string            `json:"bots"` // power config, e.g. "*=easy,france=hard"
+	Players  map[string]string `json:"players"`
+	MaxYear  int               `json:"max_year"`
+	StopYear int               `json:"stop_year"`
+}
+
+// messageFixture is a message in the active game between demo users.
+// An empty To sends it to everyone.
+type messageFixture struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Content string `json:"content"`
+}
+
+// loadFixtures parses the fixtures and checks that they refer only to
+// known powers, strategies and demo users.
+func loadFixtures(data []byte) (*fixtures, error) {
+	var f fixtures
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parse fixtures: %w", err)
+	}
+	if f.Active.StopYear == 0 {
+		return nil, fmt.Errorf("active game %q needs a stop_year", f.Active.Name)
+	}
+	for _, g := range append(slices.Clone(f.Finished), f.Active) {
+		if g.Name == "" {
+			return nil, fmt.Errorf("game without a name")
+		}
+		for power, diff := range bot.ParsePowerConfig(g.Bots) {
+			if _, ok := bot.LookupStrategy(diff); !ok {
+				return nil, fmt.Errorf("game %q: unknown strategy %q for %s", g.Name, diff, power)
+			}
+		}
+		for power, user := range g.Players {
+			if !slices.Contains(diplomacy.AllPowers(), diplomacy.Power(power)) {
+				return nil, fmt.Errorf("game %q: unknown power %q", g.Name, power)
+			}
+			if !slices.Contains(f.Users, user) {
+				return nil, fmt.Errorf("game %q: unknown user %q", g.Name, user)
+			}
+		}
+	}
+	seated := func(user string) bool {
+		for _, u := range f.Active.Players {
+			if u == user {
+				return true
+			}
+		}
+		return false
+	}
+	for _, m := range f.Messages {
+		if !seated(m.From) || (m.To != "" && !seated(m.To)) {
+			return nil, fmt.Errorf("message %q: sender and recipient must play in the active game", m.Content)
+		}
+	}
+	return &f, nil
+}
+
+// seeder writes the fixtures to the database.
+type seeder struct {
+	gameRepo    *postgres.GameRepo
+	phaseRepo   *postgres.PhaseRepo
+	userRepo    *postgres.UserRepo
+	messageRepo *postgres.MessageRepo
+
+	ratingSvc      *service.RatingService
+	summarySvc     *service.SummaryService
+	achievementSvc *service.AchievementService
+
+	users    map[string]string // demo user name -> user ID
+	existing map[string]bool   // names of games the demo users already play in
+}
+
+func main() {
+	dbURL := flag.String("db", os.Getenv("DATABASE_URL"), "Postgres connection URL")
+	redisURL := flag.String("redis", os.Getenv("REDIS_URL"), "Redis connection URL (needed for the active game)")
+	only := flag.String("only", "finished,active", "Comma-separated parts to seed: finished, active")
+	flag.Parse()
+
+	if *dbURL == "" {
+		log.Fatal("--db or DATABASE_URL is required")
+	}
+	parts := strings.Split(*only, ",")
+	for _, p := range parts {
+		if p != "finished" && p != "active" {
+			log.Fatalf("unknown part %q (want finished or active)", p)
+		}
+	}
+	if slices.Contains(parts, "active") && *redisURL == "" {
+		log.Fatal("--redis or REDIS_URL is required to seed the active game")
+	}
+
+	f, err := loadFixtures(fixturesJSON)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db, err := postgres.Connect(*dbURL)
+	if err != nil {
+		log.Fatalf("connect to postgres: %v", err)
+	}
+	defer db.Close()
+
+	s := &seeder{
+		gameRepo:    postgres.NewGameRepo(db),
+		phaseRepo:   postgres.NewPhaseRepo(db),
+		userRepo:    postgres.NewUserRepo(db),
+		messageRepo: postgres.NewMessageRepo(db),
+	}
+	s.ratingSvc = service.NewRatingService(s.gameRepo, s.phaseRepo, postgres.NewRatingRepo(db))
+	s.summarySvc = service.NewSummaryService(s.gameRepo, s.phaseRepo, postgres.NewSummaryRepo(db))
+	s.achievementSvc = service.NewAchievementService(s.gameRepo, s.phaseRepo, postgres.NewAchievementRepo(db))
+
+	ctx := context.Background()
+	if err := s.seedUsers(ctx, f.Users); err != nil {
+		log.Fatal(err)
+	}
+
+	if slices.Contains(parts, "finished") {
+		for _, g := range f.Finished {
+			if err := s.seedFinished(ctx, g); err != nil {
+				log.Fatalf("seed %q: %v", g.Name, err)
+			}
+		}
+	}
+	if slices.Contains(parts, "active") {
+		rdb, err := redis.NewClient(*redisURL)
+		if err != nil {
+			log.Fatalf("connect to redis: %v", err)
+		}
+		defer rdb.Close()
+		phaseSvc := service.NewPhaseService(s.gameRepo, s.phaseRepo, rdb, nil)
+		if err := s.seedActive(ctx, f.Active, f.Messages, phaseSvc); err != nil {
+			log.Fatalf("seed %q: %v", f.Active.Name, err)
+		}
+	}
+
+	log.Printf("done: sign in with DEV_MODE=true at /auth/dev?name=%s", f.Users[0])
+}
+
+// seedUsers creates the demo users as dev logins and notes the games they
+// already play in, active or finished.
+func (s *seeder) seedUsers(ctx context.Context, names []string) error {
+	s.users = make(map[string]string)
+	s.existing = make(map[string]bool)
+	demo := make(map[string]bool, len(names))
+	for _, name := range names {
+		u, err := s.userRepo.Upsert(ctx, "dev", "dev-"+name, name, "")
+		if err != nil {
+			return fmt.Errorf("upsert user %s: %w", name, err)
+		}
+		s.users[name] = u.ID
+		demo[u.ID] = true
+		games, err := s.gameRepo.ListActiveByUser(ctx, u.ID)
+		if err != nil {
+			return fmt.Errorf("list games of %s: %w", name, err)
+		}
+		for _, g := range games {
+			s.existing[g.Name] = true
+		}
+	}
+	err := service.EachFinishedGame(ctx, s.gameRepo, func(g model.Game) bool {
+		if demo[g.CreatorID] {
+			s.existing[g.Name] = true
+			return true
+		}
+		for _, p := range g.Players {
+			if demo[p.UserID] {
+				s.existing[g.Name] = true
+				break
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return fmt.Errorf("list finished games: %w", err)
+	}
+	log.Printf("%d demo users", len(names))
+	return nil
+}
+
+// play runs a fixture game with the bots, seating the demo users.
+func (s *seeder) play(ctx context.Context, g gameFixture) (*bot.ArenaResult, error) {
+	players := make(map[diplomacy.Power]string)
+	for power, user := range g.Players {
+		players[diplomacy.Power(power)] = s.users[user]
+	}
+	cfg := bot.ArenaConfig{
+		GameName:    g.Name,
+		PowerConfig: bot.ParsePowerConfig(g.Bots),
+		MaxYear:     g.MaxYear,
+		Seed:        g.Seed,
+		Players:     players,
+		StopYear:    g.StopYear,
+
+		CoordinateRetreats: true,
+	}
+	return bot.RunGame(ctx, cfg, s.gameRepo, s.phaseRepo, s.userRepo)
+}
+
+// seedFinished plays a game to the end and rates, summarizes and awards
+// achievements for it as the server does when a game finishes.
+func (s *seeder) seedFinished(ctx context.Context, g gameFixture) error {
+	if s.existing[g.Name] {
+		log.Printf("skipped %q: already seeded", g.Name)
+		return nil
+	}
+	result, err := s.play(ctx, g)
+	if err != nil {
+		return err
+	}
+	if _, err := s.ratingSvc.RateGame(ctx, result.GameID); err != nil {
+		return fmt.Errorf("rate: %w", err)
+	}
+	if _, err := s.summarySvc.GenerateSummary(ctx, result.GameID); err != nil {
+		return fmt.Errorf("summarize: %w", err)
+	}
+	if _, err := s.achievementSvc.EvaluateGame(ctx, result.GameID); err != nil {
+		return fmt.Errorf("evaluate achievements: %w", err)
+	}
+	winner := result.Winner
+	if winner == "" {
+		winner = "draw"
+	}
+	log.Printf("finished %q in %d (%s)", g.Name, result.FinalYear, winner)
+	return nil
+}
+
+// seedActive plays a game up to its stop year and leaves it running: the
+// current phase gets a day's deadline, the bots submit their orders and
+// the messages are posted in it.
+func (s *seeder) seedActive(ctx context.Context, g gameFixture, messages []messageFixture, phaseSvc *service.PhaseService) error {
+	if s.existing[g.Name] {
+		log.Printf("skipped %q: already seeded", g.Name)
+		return nil
+	}
+	result, err := s.play(ctx, g)
+	if err != nil {
+		return err
+	}
+	phase, err := s.phaseRepo.CurrentPhase(ctx, result.GameID)
+	if err != nil {
+		return fmt.Errorf("current phase: %w", err)
+	}
+	deadline := time.Now().Add(24 * time.Hour)
+	if err := s.phaseRepo.UpdateDeadline(ctx, phase.ID, deadline); err != nil {
+		return fmt.Errorf("update deadline: %w", err)
+	}
+	var gs diplomacy.GameState
+	if err := json.Unmarshal(phase.StateBefore, &gs); err != nil {
+		return fmt.Errorf("unmarshal state: %w", err)
+	}
+	if err := phaseSvc.InitializeGame(ctx, result.GameID, &gs, deadline); err != nil {
+		return err
+	}
+	if err := phaseSvc.SubmitBotOrders(ctx, result.GameID); err != nil {
+		return fmt.Errorf("submit bot orders: %w", err)
+	}
+	for _, m := range messages {
+		to := ""
+		if m.To != "" {
+			to = s.users[m.To]
+		}
+		if _, err := s.messageRepo.Create(ctx, result.GameID, s.users[m.From], to, m.Content, phase.ID, nil); err != nil {
+			return fmt.Errorf("create message: %w", err)
+		}
+	}
+	log.Printf("active %q in %s %d with %d messages", g.Name, gs.Season, gs.Year, len(messages))
+	return nil
+}
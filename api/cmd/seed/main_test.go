@@ -0,0 +1,39 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEmbeddedFixtures(t *testing.T) {
+	f, err := loadFixtures(fixturesJSON)
+	if err != nil {
+		t.Fatalf("embedded fixtures: %v", err)
+	}
+	if len(f.Users) == 0 || len(f.Finished) == 0 || len(f.Messages) == 0 {
+		t.Errorf("expected users, finished games and messages, got %+v", f)
+	}
+	if f.Active.StopYear != 1905 {
+		t.Errorf("expected the active game stopped in 1905, got %d", f.Active.StopYear)
+	}
+}
+
+func TestLoadFixturesRejects(t *testing.T) {
+	tests := []struct {
+		name, json, want string
+	}{
+		{"no stop year", `{"users":["a"],"active":{"name":"x"}}`, "stop_year"},
+		{"unknown strategy", `{"users":["a"],"active":{"name":"x","bots":"*=wizard","stop_year":1905}}`, "unknown strategy"},
+		{"unknown power", `{"users":["a"],"active":{"name":"x","players":{"prussia":"a"},"stop_year":1905}}`, "unknown power"},
+		{"unknown user", `{"users":["a"],"finished":[{"name":"y","players":{"france":"b"}}],"active":{"name":"x","stop_year":1905}}`, "unknown user"},
+		{"unseated sender", `{"users":["a","b"],"active":{"name":"x","players":{"france":"a"},"stop_year":1905},"messages":[{"from":"b","content":"hi"}]}`, "must play"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadFixtures([]byte(tt.json))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
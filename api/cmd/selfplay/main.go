@@ -0,0 +1,225 @@
+// Command selfplay generates training data by playing the Go bot strategies
+// against each other. Each finished game is written as one JSONL line in the
+// format of the Rust selfplay binary — DFEN and per-power DSON orders for
+// every phase, with heuristic values and supply-center counts — plus the
+// per-power value targets derived from the game's outcome. The output can be
+// imported with import_selfplay or converted with convert_selfplay.py.
+//
+// Usage:
+//
+//	go run ./cmd/selfplay/ -n 100 -workers 4 -p '*=hard' -o games.jsonl
+//	go run ./cmd/selfplay/ -n 70 -matchup hard-vs-medium -o games.jsonl
+//
+// With -matchup a-vs-b, one power plays strategy a and the other six play b;
+// the lone power rotates through all seven from game to game. -seed makes
+// runs reproducible, but only with a single worker since the bots share one
+// random source.
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/freeeve/polite-betrayal/api/internal/bot"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+func main() {
+	log.Logger = zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr}).With().Timestamp().Logger()
+
+	powerCfg := flag.String("p", "", "Power config (e.g. france=hard,*=medium)")
+	matchup := flag.String("matchup", "", "One power playing a against six playing b, rotating the power (e.g. hard-vs-medium)")
+	numGames := flag.Int("n", 10, "Number of games to play")
+	workers := flag.Int("workers", 1, "Games played concurrently")
+	maxYear := flag.Int("max-year", 1920, "Year after which games end as draws")
+	seed := flag.Int64("seed", 0, "Base seed, game i uses seed+i (0 = random; requires -workers 1)")
+	output := flag.String("o", "", "Output JSONL file, appended to (default stdout)")
+	coordRet := flag.Bool("coordinate-retreats", true, "Keep bots from retreating into the same province")
+	flag.Parse()
+
+	if *seed != 0 && *workers > 1 {
+		log.Fatal().Msg("-seed requires -workers 1: the bots share a single random source")
+	}
+	if err := bot.RegisterStrategyVariants(os.Getenv("BOT_STRATEGIES")); err != nil {
+		log.Fatal().Err(err).Msg("Invalid BOT_STRATEGIES")
+	}
+	configFor, err := matchups(*powerCfg, *matchup)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid matchup")
+	}
+
+	out := io.Writer(os.Stdout)
+	if *output != "" {
+		f, err := os.OpenFile(*output, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to open output")
+		}
+		defer f.Close()
+		out = f
+	}
+	w := &recordWriter{w: bufio.NewWriter(out)}
+
+	// The first signal stops new games from starting and lets the ones in
+	// progress finish and be written; a second aborts them.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stopping := make(chan struct{})
+	sig := make(chan os.Signal, 2)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sig
+		log.Info().Msg("Stopping after the games in progress; interrupt again to abort them")
+		close(stopping)
+		<-sig
+		log.Info().Msg("Aborting the games in progress")
+		cancel()
+	}()
+
+	games := make(chan int)
+	var wg sync.WaitGroup
+	var failed, written int
+	var mu sync.Mutex
+	for range max(1, *workers) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range games {
+				powers := configFor(idx)
+				rec, err := playGame(ctx, idx, powers, *maxYear, *seed, *coordRet)
+				if err == nil {
+					err = w.write(rec)
+				}
+				mu.Lock()
+				if err != nil {
+					failed++
+				} else {
+					written++
+				}
+				mu.Unlock()
+				if err != nil {
+					if ctx.Err() == nil {
+						log.Error().Err(err).Int("game", idx).Msg("Game failed")
+					}
+					continue
+				}
+				winner := "draw"
+				if rec.Winner != nil {
+					winner = *rec.Winner
+				}
+				log.Info().Int("game", idx).Str("winner", winner).Int("year", rec.FinalYear).Int("phases", len(rec.Phases)).Msg("Game recorded")
+			}
+		}()
+	}
+dispatch:
+	for i := 0; i < *numGames; i++ {
+		select {
+		case <-stopping:
+			break dispatch
+		default:
+		}
+		select {
+		case games <- i:
+		case <-stopping:
+			break dispatch
+		}
+	}
+	close(games)
+	wg.Wait()
+
+	log.Info().Int("written", written).Int("failed", failed).Msg("Self-play finished")
+	if failed > 0 && ctx.Err() == nil {
+		os.Exit(1)
+	}
+}
+
+// playGame plays one arena game without touching the database and returns
+// its record.
+func playGame(ctx context.Context, idx int, powers map[diplomacy.Power]string, maxYear int, seed int64, coordRet bool) (gameRecord, error) {
+	rec := newRecorder()
+	cfg := bot.ArenaConfig{
+		GameName:           fmt.Sprintf("selfplay-%d", idx),
+		PowerConfig:        powers,
+		MaxYear:            maxYear,
+		DryRun:             true,
+		CoordinateRetreats: coordRet,
+		OnOrders:           rec.onOrders,
+	}
+	if seed != 0 {
+		cfg.Seed = seed + int64(idx)
+	}
+	result, err := bot.RunGame(ctx, cfg, nil, nil, nil)
+	if err != nil {
+		return gameRecord{}, err
+	}
+	return rec.record(idx, result, powers), nil
+}
+
+// matchups returns the power config for each game from the -p or -matchup
+// flag. Unknown strategies are rejected up front rather than silently
+// replaced by the default.
+func matchups(powerCfg, matchup string) (func(idx int) map[diplomacy.Power]string, error) {
+	var configFor func(idx int) map[diplomacy.Power]string
+	switch {
+	case powerCfg != "" && matchup != "":
+		return nil, fmt.Errorf("-p and -matchup cannot be combined")
+	case matchup != "":
+		lone, rest, ok := strings.Cut(matchup, "-vs-")
+		if !ok || lone == "" || rest == "" {
+			return nil, fmt.Errorf("matchup %q: want a-vs-b", matchup)
+		}
+		powers := diplomacy.AllPowers()
+		configFor = func(idx int) map[diplomacy.Power]string {
+			cfg := make(map[diplomacy.Power]string, len(powers))
+			for i, p := range powers {
+				cfg[p] = rest
+				if i == idx%len(powers) {
+					cfg[p] = lone
+				}
+			}
+			return cfg
+		}
+	default:
+		cfg := bot.ParsePowerConfig(powerCfg)
+		if powerCfg == "" {
+			cfg = bot.ParsePowerConfig("*=easy")
+		}
+		configFor = func(int) map[diplomacy.Power]string { return cfg }
+	}
+	for _, name := range configFor(0) {
+		if _, ok := bot.LookupStrategy(name); !ok {
+			return nil, fmt.Errorf("unknown strategy %q", name)
+		}
+	}
+	return configFor, nil
+}
+
+// recordWriter writes records as JSONL, one complete line per game, so a
+// reader following the file never sees a partial game.
+type recordWriter struct {
+	mu sync.Mutex
+	w  *bufio.Writer
+}
+
+func (rw *recordWriter) write(rec gameRecord) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("marshal game %d: %w", rec.GameID, err)
+	}
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	rw.w.Write(line)
+	rw.w.WriteByte('\n')
+	return rw.w.Flush()
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"math"
+
+	"github.com/freeeve/polite-betrayal/api/internal/bot"
+	"github.com/freeeve/polite-betrayal/api/internal/bot/neural"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// powerOrder matches the Rust ALL_POWERS ordering used for the per-power
+// arrays, so records read the same as the Rust selfplay binary's.
+var powerOrder = []diplomacy.Power{
+	diplomacy.Austria, diplomacy.England, diplomacy.France,
+	diplomacy.Germany, diplomacy.Italy, diplomacy.Russia, diplomacy.Turkey,
+}
+
+// Quality thresholds, as in the Rust selfplay defaults.
+const (
+	earlyDominationSCs  = 14
+	earlyDominationYear = 1905
+)
+
+// gameRecord is one JSONL line. The fields match the Rust selfplay output
+// read by import_selfplay and data/scripts/convert_selfplay.py; Strategies
+// and ValueTargets are additions those readers ignore.
+type gameRecord struct {
+	GameID       int                  `json:"game_id"`
+	Winner       *string              `json:"winner"` // null for draw
+	FinalYear    int                  `json:"final_year"`
+	FinalSCCount []int                `json:"final_sc_counts"`
+	Quality      gameQuality          `json:"quality"`
+	Strategies   map[string]string    `json:"strategies"`    // power -> strategy that played it
+	ValueTargets map[string][]float64 `json:"value_targets"` // power -> [sc share, win, draw, survival]
+	Phases       []phaseRecord        `json:"phases"`
+}
+
+// gameQuality flags games that make poor training data.
+type gameQuality struct {
+	EarlyStalemate  bool `json:"early_stalemate"` // never set: arena games run to the year limit
+	EarlyDomination bool `json:"early_domination"`
+}
+
+// phaseRecord is the position at the start of a phase and the orders
+// played from it.
+type phaseRecord struct {
+	DFEN     string            `json:"dfen"`
+	Year     int               `json:"year"`
+	Season   string            `json:"season"` // "s" or "f"
+	Phase    string            `json:"phase"`  // "m", "r" or "b"
+	Orders   map[string]string `json:"orders"` // power -> DSON
+	Values   []float64         `json:"values"` // heuristic evaluation per power
+	SCCounts []int             `json:"sc_counts"`
+}
+
+// recorder collects the phases of one arena game.
+type recorder struct {
+	m      *diplomacy.DiplomacyMap
+	phases []phaseRecord
+}
+
+func newRecorder() *recorder {
+	return &recorder{m: diplomacy.StandardMap()}
+}
+
+// onOrders is the arena's OnOrders hook.
+func (r *recorder) onOrders(before *diplomacy.GameState, orders map[diplomacy.Power][]diplomacy.DSONOrder) {
+	phase := diplomacy.EncodeDFENPhase(before)
+	rec := phaseRecord{
+		DFEN:     diplomacy.EncodeDFEN(before),
+		Year:     before.Year,
+		Season:   phase[len(phase)-2 : len(phase)-1],
+		Phase:    phase[len(phase)-1:],
+		Orders:   make(map[string]string, len(orders)),
+		Values:   make([]float64, len(powerOrder)),
+		SCCounts: scCounts(before),
+	}
+	for p, dson := range orders {
+		if len(dson) > 0 {
+			rec.Orders[string(p)] = diplomacy.FormatDSON(dson)
+		}
+	}
+	for i, p := range powerOrder {
+		rec.Values[i] = math.Round(neural.Evaluate(p, before, r.m)*1e4) / 1e4
+	}
+	r.phases = append(r.phases, rec)
+}
+
+// record builds the game's record from the arena result.
+func (r *recorder) record(gameID int, result *bot.ArenaResult, strategies map[diplomacy.Power]string) gameRecord {
+	rec := gameRecord{
+		GameID:       gameID,
+		FinalYear:    result.FinalYear,
+		FinalSCCount: make([]int, len(powerOrder)),
+		Strategies:   make(map[string]string, len(strategies)),
+		Phases:       r.phases,
+	}
+	if result.Winner != "" {
+		w := result.Winner
+		rec.Winner = &w
+	}
+	for i, p := range powerOrder {
+		rec.FinalSCCount[i] = result.SCCounts[string(p)]
+	}
+	for p, s := range strategies {
+		rec.Strategies[string(p)] = s
+	}
+	rec.ValueTargets = valueTargets(rec.FinalSCCount, result.Winner)
+	for _, ph := range r.phases {
+		if ph.Year > earlyDominationYear {
+			break
+		}
+		for _, n := range ph.SCCounts {
+			if n >= earlyDominationSCs {
+				rec.Quality.EarlyDomination = true
+			}
+		}
+	}
+	return rec
+}
+
+// valueTargets returns each power's value network targets from the game's
+// outcome: its final share of the supply centers, then whether it won, drew
+// and survived. It matches compute_value_labels in convert_selfplay.py.
+func valueTargets(finalSCs []int, winner string) map[string][]float64 {
+	targets := make(map[string][]float64, len(powerOrder))
+	for i, p := range powerOrder {
+		sc := finalSCs[i]
+		v := []float64{float64(sc) / 34, 0, 0, 0}
+		switch {
+		case winner == string(p):
+			v[1], v[3] = 1, 1
+		case winner == "" && sc > 0:
+			v[2], v[3] = 1, 1
+		case sc > 0:
+			v[3] = 1
+		}
+		targets[string(p)] = v
+	}
+	return targets
+}
+
+// scCounts returns the supply-center count of each power in powerOrder.
+func scCounts(gs *diplomacy.GameState) []int {
+	counts := make([]int, len(powerOrder))
+	for i, p := range powerOrder {
+		counts[i] = gs.SupplyCenterCount(p)
+	}
+	return counts
+}
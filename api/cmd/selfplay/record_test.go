@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/freeeve/polite-betrayal/api/internal/bot"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+func TestValueTargets(t *testing.T) {
+	// Austria, England, France, Germany, Italy, Russia, Turkey.
+	won := valueTargets([]int{0, 3, 18, 4, 3, 3, 3}, "france")
+	if got := won["france"]; !slices.Equal(got, []float64{18.0 / 34, 1, 0, 1}) {
+		t.Errorf("winner: got %v", got)
+	}
+	if got := won["england"]; !slices.Equal(got, []float64{3.0 / 34, 0, 0, 1}) {
+		t.Errorf("survivor: got %v", got)
+	}
+	if got := won["austria"]; !slices.Equal(got, []float64{0, 0, 0, 0}) {
+		t.Errorf("eliminated: got %v", got)
+	}
+
+	drawn := valueTargets([]int{0, 5, 6, 5, 6, 6, 6}, "")
+	if got := drawn["italy"]; !slices.Equal(got, []float64{6.0 / 34, 0, 1, 1}) {
+		t.Errorf("draw: got %v", got)
+	}
+	if got := drawn["austria"]; !slices.Equal(got, []float64{0, 0, 0, 0}) {
+		t.Errorf("eliminated in a draw: got %v", got)
+	}
+}
+
+func TestRecordGame(t *testing.T) {
+	powers := bot.ParsePowerConfig("*=easy")
+	rec, err := playGame(context.Background(), 3, powers, 1902, 42, true)
+	if err != nil {
+		t.Fatalf("playGame: %v", err)
+	}
+
+	if rec.GameID != 3 || rec.FinalYear < 1902 || len(rec.Phases) < 5 {
+		t.Fatalf("unexpected record: id %d, year %d, %d phases", rec.GameID, rec.FinalYear, len(rec.Phases))
+	}
+	first := rec.Phases[0]
+	if first.DFEN != diplomacy.EncodeDFEN(diplomacy.NewInitialState()) {
+		t.Errorf("first phase should be the initial position, got %s", first.DFEN)
+	}
+	if first.Year != 1901 || first.Season != "s" || first.Phase != "m" {
+		t.Errorf("first phase: got %d%s%s", first.Year, first.Season, first.Phase)
+	}
+	if !slices.Equal(first.SCCounts, []int{3, 3, 3, 3, 3, 4, 3}) {
+		t.Errorf("initial sc counts: got %v", first.SCCounts)
+	}
+	if len(first.Orders) != 7 || len(first.Values) != 7 {
+		t.Errorf("expected orders and values for all seven powers, got %d and %d", len(first.Orders), len(first.Values))
+	}
+	for p, dson := range first.Orders {
+		if _, err := diplomacy.ParseDSON(dson); err != nil {
+			t.Errorf("%s orders %q: %v", p, dson, err)
+		}
+	}
+	if len(rec.ValueTargets) != 7 || rec.Strategies["france"] != "easy" {
+		t.Errorf("expected value targets and strategies for all powers, got %v and %v", rec.ValueTargets, rec.Strategies)
+	}
+
+	// The record round-trips as the JSONL that import_selfplay reads.
+	line, err := json.Marshal(rec)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var back struct {
+		Phases []struct {
+			DFEN   string            `json:"dfen"`
+			Orders map[string]string `json:"orders"`
+		} `json:"phases"`
+		FinalSCCounts []int `json:"final_sc_counts"`
+	}
+	if err := json.Unmarshal(line, &back); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(back.Phases) != len(rec.Phases) || len(back.FinalSCCounts) != 7 {
+		t.Errorf("round trip lost data: %+v", back)
+	}
+}
+
+func TestMatchups(t *testing.T) {
+	configFor, err := matchups("", "medium-vs-easy")
+	if err != nil {
+		t.Fatalf("matchups: %v", err)
+	}
+	for idx, lone := range diplomacy.AllPowers() {
+		cfg := configFor(idx)
+		for p, s := range cfg {
+			want := "easy"
+			if p == lone {
+				want = "medium"
+			}
+			if s != want {
+				t.Errorf("game %d: %s plays %s, want %s", idx, p, s, want)
+			}
+		}
+	}
+
+	if _, err := matchups("", "medium"); err == nil {
+		t.Error("expected an error for a matchup without -vs-")
+	}
+	if _, err := matchups("", "nonesuch-vs-easy"); err == nil {
+		t.Error("expected an error for an unknown strategy")
+	}
+	if _, err := matchups("*=easy", "medium-vs-easy"); err == nil {
+		t.Error("expected an error when combining -p and -matchup")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
@@ -14,11 +15,16 @@ import (
 	"github.com/freeeve/polite-betrayal/api/internal/bot"
 	"github.com/freeeve/polite-betrayal/api/internal/config"
 	"github.com/freeeve/polite-betrayal/api/internal/handler"
+	"github.com/freeeve/polite-betrayal/api/internal/jobs"
 	"github.com/freeeve/polite-betrayal/api/internal/logger"
 	"github.com/freeeve/polite-betrayal/api/internal/middleware"
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/internal/render"
 	"github.com/freeeve/polite-betrayal/api/internal/repository/postgres"
 	redisrepo "github.com/freeeve/polite-betrayal/api/internal/repository/redis"
 	"github.com/freeeve/polite-betrayal/api/internal/service"
+	"github.com/freeeve/polite-betrayal/api/internal/storage"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
 )
 
 func main() {
@@ -26,10 +32,18 @@ func main() {
 	cfg := config.Load()
 	bot.ExternalEnginePath = os.Getenv("REALPOLITIK_PATH")
 	bot.GonnxModelPath = os.Getenv("GONNX_MODEL_PATH")
+	bot.RemoteStrategyAddress = os.Getenv("REMOTE_STRATEGY_ADDR")
+	bot.EngineRetryInterval = cfg.EngineRetryInterval
+	if err := bot.RegisterStrategyVariants(cfg.BotStrategies); err != nil {
+		log.Fatal().Err(err).Msg("Invalid BOT_STRATEGIES")
+	}
+	if err := bot.Models.AddSpec(cfg.GonnxModels); err != nil {
+		log.Fatal().Err(err).Msg("Invalid GONNX_MODELS")
+	}
 	log.Info().Str("databaseURL", cfg.DatabaseURL).Msg("Config loaded")
 
 	// Database
-	db, err := postgres.Connect(cfg.DatabaseURL)
+	db, err := postgres.ConnectWithStatementTimeout(cfg.DatabaseURL, cfg.DBStatementTimeout)
 	if err != nil {
 		log.Fatal().Err(err).Msg("Database connection failed")
 	}
@@ -52,6 +66,39 @@ func main() {
 	gameRepo := postgres.NewGameRepo(db)
 	phaseRepo := postgres.NewPhaseRepo(db)
 	messageRepo := postgres.NewMessageRepo(db)
+	achievementRepo := postgres.NewAchievementRepo(db)
+	summaryRepo := postgres.NewSummaryRepo(db)
+	apiKeyRepo := postgres.NewAPIKeyRepo(db)
+	prefRepo := postgres.NewPreferenceRepo(db)
+	jobRepo := postgres.NewJobRepo(db)
+	ratingRepo := postgres.NewRatingRepo(db)
+	variantRepo := postgres.NewVariantRepo(db)
+	computeRepo := postgres.NewComputeRepo(db)
+	channelRepo := postgres.NewChannelRepo(db)
+	notificationRepo := postgres.NewNotificationRepo(db)
+	userRepo.SetQueryTimeout(cfg.DBQueryTimeout)
+	gameRepo.SetQueryTimeout(cfg.DBQueryTimeout)
+	phaseRepo.SetQueryTimeout(cfg.DBQueryTimeout)
+	messageRepo.SetQueryTimeout(cfg.DBQueryTimeout)
+	achievementRepo.SetQueryTimeout(cfg.DBQueryTimeout)
+	summaryRepo.SetQueryTimeout(cfg.DBQueryTimeout)
+	apiKeyRepo.SetQueryTimeout(cfg.DBQueryTimeout)
+	prefRepo.SetQueryTimeout(cfg.DBQueryTimeout)
+	jobRepo.SetQueryTimeout(cfg.DBQueryTimeout)
+	ratingRepo.SetQueryTimeout(cfg.DBQueryTimeout)
+	variantRepo.SetQueryTimeout(cfg.DBQueryTimeout)
+	computeRepo.SetQueryTimeout(cfg.DBQueryTimeout)
+	channelRepo.SetQueryTimeout(cfg.DBQueryTimeout)
+	notificationRepo.SetQueryTimeout(cfg.DBQueryTimeout)
+	if cfg.PressEncryptionKey != "" {
+		key, err := postgres.DecodePressKey(cfg.PressEncryptionKey)
+		if err == nil {
+			err = messageRepo.SetEncryptionKey(key)
+		}
+		if err != nil {
+			log.Fatal().Err(err).Msg("Invalid PRESS_ENCRYPTION_KEY")
+		}
+	}
 
 	// Auth
 	jwtMgr := auth.NewJWTManager(cfg.JWTSecret)
@@ -63,15 +110,91 @@ func main() {
 
 	// WebSocket hub
 	wsHub := handler.NewHub()
+	wsHub.SetEventLog(redisClient)
+	wsHub.SetRelay(redisClient, instanceID())
 
 	// Services
 	gameSvc := service.NewGameService(gameRepo, phaseRepo, userRepo)
-	orderSvc := service.NewOrderService(gameRepo, phaseRepo, redisClient)
-	phaseSvc := service.NewPhaseService(gameRepo, phaseRepo, redisClient, wsHub)
+	gameSvc.SetDeletedRetention(cfg.DeletedGameRetention)
+	gameSvc.SetDeadlineJitter(cfg.DeadlineJitter)
+	// Submissions are mirrored to Postgres so a restart mid-phase keeps them.
+	gameCache := service.NewDurableCache(redisClient, phaseRepo)
+	gameSvc.SetGameCache(gameCache)
+	gameSvc.SetBroadcaster(wsHub)
+	orderSvc := service.NewOrderService(gameRepo, phaseRepo, gameCache)
+	orderSvc.SetMessageRepo(messageRepo)
+	phaseSvc := service.NewPhaseService(gameRepo, phaseRepo, gameCache, wsHub)
+	orderSvc.SetGameLocker(phaseSvc)
 	phaseSvc.SetMessageRepo(messageRepo)
+	phaseSvc.SetUserRepo(userRepo)
+	phaseSvc.SetDeadlineJitter(cfg.DeadlineJitter)
+	phaseSvc.SetGameService(gameSvc)
+	phaseSvc.SetLeaseStore(redisClient, instanceID())
+	achievementSvc := service.NewAchievementService(gameRepo, phaseRepo, achievementRepo)
+	phaseSvc.SetAchievementService(achievementSvc)
+	ratingSvc := service.NewRatingService(gameRepo, phaseRepo, ratingRepo)
+	phaseSvc.SetRatingService(ratingSvc)
+	computeSvc := service.NewComputeService(computeRepo, gameRepo)
+	computeSvc.SetGameCap(cfg.BotComputeCap)
+	phaseSvc.SetComputeService(computeSvc)
+	analysisSvc := service.NewAnalysisService(gameRepo, phaseRepo)
+	if bot.GonnxModelPath != "" {
+		if vn, err := bot.NewValueNetwork(); err != nil {
+			log.Warn().Err(err).Msg("Value network unavailable, replay analysis will be heuristic only")
+		} else {
+			analysisSvc.SetValueNetwork(vn)
+			analysisSvc.SetReviewStrategy(bot.StrategyForDifficulty("hard-gonnx"))
+		}
+	}
+	analysisSvc.SetEvaluationCache(redisClient)
+	phaseSvc.SetAllianceStore(redisClient)
+	phaseSvc.SetAnalysisService(analysisSvc)
+	notificationSvc := service.NewNotificationService(notificationRepo, gameRepo, phaseRepo, redisClient)
+	if cfg.SMTPURL != "" {
+		sender, err := service.NewSMTPSender(cfg.SMTPURL, cfg.SMTPFrom)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Invalid SMTP_URL")
+		}
+		notificationSvc.SetSender(model.NotifyByEmail, sender)
+		notificationSvc.SetConfirmURL(cfg.PublicURL + "/notifications/confirm")
+	}
+	phaseSvc.SetNotificationService(notificationSvc)
+
+	// Background jobs
+	jobQueue := jobs.NewQueue(jobRepo, jobs.Options{Workers: cfg.JobWorkers, MaxAttempts: cfg.JobMaxAttempts})
+	analysisSvc.RegisterJobs(jobQueue)
+	notificationSvc.RegisterJobs(jobQueue)
+	phaseSvc.SetJobQueue(jobQueue)
+	summarySvc := service.NewSummaryService(gameRepo, phaseRepo, summaryRepo)
+	phaseSvc.SetSummaryService(summarySvc)
+	flagSvc := service.NewFlagService(redisClient)
+	supportSvc := service.NewSupportService(gameRepo, phaseRepo)
+	supportSvc.SetUserRepo(userRepo)
+	exportSvc := service.NewExportService(gameRepo, phaseRepo, messageRepo)
+	exportSvc.SetChannelRepo(channelRepo)
+	channelSvc := service.NewChannelService(channelRepo, gameRepo)
+	replaySvc := service.NewReplayService(gameRepo, phaseRepo)
+	reportSvc := service.NewReportService(gameRepo, messageRepo, userRepo, replaySvc, summarySvc)
+	reportSvc.SetMapRenderer(render.MapSVG)
+	dashboardSvc := service.NewDashboardService(gameRepo, phaseRepo, messageRepo, redisClient)
+	apiKeySvc := service.NewAPIKeyService(apiKeyRepo)
+	prefSvc := service.NewPreferenceService(prefRepo)
+	publicSvc := service.NewPublicService(gameRepo, phaseRepo, achievementSvc)
+	variantSvc := service.NewVariantService(variantRepo)
+	trendingSvc := service.NewTrendingService(gameRepo, redisClient, instanceID())
+	// Games on custom variants load them from the database on any server.
+	diplomacy.SetScenarioLoader(variantSvc.LoadScenario)
+	gameSvc.SetVariantService(variantSvc)
 
 	// Timer listener (auto-resolve on expiry)
 	timerListener := service.NewTimerListener(redisClient.Underlying(), phaseSvc, phaseRepo)
+	resolutionPool := service.NewResolutionPool(phaseSvc.ResolvePhase, service.ResolutionPoolOptions{
+		Workers:   cfg.ResolutionWorkers,
+		QueueSize: cfg.ResolutionQueueSize,
+	})
+	timerListener.SetResolutionPool(resolutionPool)
+	timerListener.SetNotificationService(notificationSvc)
+	timerListener.SetLeaseStore(redisClient, instanceID())
 
 	// Handlers
 	authHandler := handler.NewAuthHandler(googleOAuth, jwtMgr, userRepo)
@@ -80,11 +203,51 @@ func main() {
 	orderHandler := handler.NewOrderHandler(orderSvc, phaseSvc, wsHub)
 	phaseHandler := handler.NewPhaseHandler(phaseRepo)
 	messageHandler := handler.NewMessageHandler(messageRepo, phaseRepo, wsHub)
+	messageHandler.SetUserRepo(userRepo)
+	messageHandler.SetGameRepo(gameRepo)
+	messageHandler.SetChannelService(channelSvc)
+	channelHandler := handler.NewChannelHandler(channelSvc, wsHub)
 	wsHandler := handler.NewWSHandler(wsHub, jwtMgr)
+	adminHandler := handler.NewAdminHandler(flagSvc, gameSvc)
+	adminHandler.SetResolutionPool(resolutionPool)
+	adminHandler.SetComputeService(computeSvc)
+	adminHandler.SetJobQueue(jobQueue)
+	supportHandler := handler.NewSupportHandler(supportSvc)
+	achievementHandler := handler.NewAchievementHandler(achievementSvc)
+	ratingHandler := handler.NewRatingHandler(ratingSvc)
+	exportHandler := handler.NewExportHandler(exportSvc)
+	exportHandler.SetAdminIDs(cfg.AdminIDs)
+	replayHandler := handler.NewReplayHandler(replaySvc)
+	reportHandler := handler.NewReportHandler(reportSvc)
+	analysisHandler := handler.NewAnalysisHandler(analysisSvc)
+	summaryHandler := handler.NewSummaryHandler(summarySvc)
+	var blobStore storage.Store
+	if cfg.BlobStoreURL != "" {
+		blobSecret := []byte(cfg.BlobURLSecret)
+		if len(blobSecret) == 0 {
+			blobSecret = storage.DeriveSecret([]byte(cfg.JWTSecret), "blob-url")
+		}
+		blobStore, err = storage.Open(cfg.BlobStoreURL, blobSecret)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Invalid BLOB_STORE_URL")
+		}
+		artifactSvc := service.NewArtifactService(blobStore, cfg.ArtifactURLTTL)
+		summaryHandler.SetArtifactService(artifactSvc)
+		reportHandler.SetArtifactService(artifactSvc)
+		exportHandler.SetArtifactService(artifactSvc)
+	}
+	dashboardHandler := handler.NewDashboardHandler(dashboardSvc)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeySvc)
+	prefHandler := handler.NewPreferenceHandler(prefSvc)
+	notificationHandler := handler.NewNotificationHandler(notificationSvc)
+	publicHandler := handler.NewPublicHandler(publicSvc)
+	variantHandler := handler.NewVariantHandler(variantSvc)
+	trendingHandler := handler.NewTrendingHandler(trendingSvc)
 
 	// Router
 	mux := http.NewServeMux()
 	authMw := auth.Middleware(jwtMgr)
+	adminMw := auth.RequireAdmin(cfg.AdminIDs)
 
 	// Health
 	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
@@ -92,39 +255,122 @@ func main() {
 		w.Write([]byte(`{"status":"ok"}`))
 	})
 
+	// Stored artifacts behind signed links
+	if local, ok := blobStore.(*storage.LocalStore); ok {
+		mux.Handle("GET /blobs/", local.Handler())
+	}
+
+	// Server clock, for client countdowns
+	mux.HandleFunc("GET /time", handler.ServerTime)
+
 	// Auth (public)
 	mux.HandleFunc("GET /auth/google/login", authHandler.GoogleLogin)
 	mux.HandleFunc("GET /auth/google/callback", authHandler.GoogleCallback)
 	mux.HandleFunc("POST /auth/refresh", authHandler.RefreshToken)
 	mux.HandleFunc("GET /auth/dev", authHandler.DevLogin)
+	mux.HandleFunc("GET /notifications/confirm", notificationHandler.ConfirmChannel)
 
 	// Protected API routes
 	api := http.NewServeMux()
 	api.HandleFunc("GET /users/me", userHandler.GetMe)
 	api.HandleFunc("PATCH /users/me", userHandler.UpdateMe)
+	api.HandleFunc("GET /users/me/dashboard", dashboardHandler.GetDashboard)
+	api.HandleFunc("POST /users/me/api-keys", apiKeyHandler.CreateKey)
+	api.HandleFunc("GET /users/me/api-keys", apiKeyHandler.ListKeys)
+	api.HandleFunc("DELETE /users/me/api-keys/{id}", apiKeyHandler.RevokeKey)
+	api.HandleFunc("GET /users/me/preferences", prefHandler.GetPreferences)
+	api.HandleFunc("PATCH /users/me/preferences", prefHandler.UpdatePreferences)
+	api.HandleFunc("GET /users/me/notification-channels", notificationHandler.ListChannels)
+	api.HandleFunc("POST /users/me/notification-channels", notificationHandler.CreateChannel)
+	api.HandleFunc("PATCH /users/me/notification-channels/{id}", notificationHandler.UpdateChannel)
+	api.HandleFunc("DELETE /users/me/notification-channels/{id}", notificationHandler.DeleteChannel)
+	api.HandleFunc("POST /users/me/notification-channels/{id}/confirmation", notificationHandler.ResendConfirmation)
+	api.HandleFunc("GET /users/me/notifications", notificationHandler.ListNotifications)
 	api.HandleFunc("GET /users/{id}", userHandler.GetUser)
+	api.HandleFunc("GET /users/{id}/stats", achievementHandler.UserStats)
+	api.HandleFunc("GET /hall-of-fame", achievementHandler.HallOfFame)
+	api.HandleFunc("GET /leaderboard", ratingHandler.Leaderboard)
+	api.HandleFunc("GET /game-presets", gameHandler.ListPresets)
+	api.HandleFunc("GET /bot-strategies", gameHandler.ListBotStrategies)
+	api.HandleFunc("GET /scenarios", gameHandler.ListScenarios)
+	api.HandleFunc("GET /variants", variantHandler.List)
+	api.HandleFunc("GET /variants/{name}", variantHandler.Get)
 	api.HandleFunc("POST /games", gameHandler.CreateGame)
 	api.HandleFunc("GET /games", gameHandler.ListGames)
+	api.HandleFunc("GET /games/trending", trendingHandler.Trending)
+	api.HandleFunc("POST /games/ready", orderHandler.MarkReadyMany)
 	api.HandleFunc("GET /games/{id}", gameHandler.GetGame)
 	api.HandleFunc("POST /games/{id}/join", gameHandler.JoinGame)
+	api.HandleFunc("POST /games/{id}/spectate", gameHandler.SpectateGame)
 	api.HandleFunc("POST /games/{id}/start", gameHandler.StartGame)
 	api.HandleFunc("POST /games/{id}/draw/vote", gameHandler.VoteForDraw)
 	api.HandleFunc("DELETE /games/{id}/draw/vote", gameHandler.RemoveDrawVote)
 	api.HandleFunc("DELETE /games/{id}", gameHandler.DeleteGame)
+	api.HandleFunc("POST /games/{id}/restore", gameHandler.RestoreGame)
 	api.HandleFunc("POST /games/{id}/stop", gameHandler.StopGame)
+	api.HandleFunc("POST /games/{id}/pause", gameHandler.PauseGame)
+	api.HandleFunc("POST /games/{id}/resume", gameHandler.ResumeGame)
 	api.HandleFunc("PATCH /games/{id}/players/{userId}/bot-difficulty", gameHandler.UpdateBotDifficulty)
+	api.HandleFunc("PATCH /games/{id}/players/{userId}/bot-personality", gameHandler.UpdateBotPersonality)
+	api.HandleFunc("GET /games/{id}/bot-difficulty-changes", gameHandler.BotDifficultyChanges)
+	api.Handle("POST /games/{id}/players/{userId}/replace-with-bot", adminMw(http.HandlerFunc(gameHandler.ReplaceWithBot)))
 	api.HandleFunc("PATCH /games/{id}/players/{userId}/power", gameHandler.UpdatePlayerPower)
+	api.HandleFunc("PATCH /games/{id}/bot-press", gameHandler.UpdateBotPress)
 	api.HandleFunc("POST /games/{id}/orders", orderHandler.SubmitOrders)
+	api.HandleFunc("POST /games/{id}/orders/validate", orderHandler.ValidateOrders)
+	api.HandleFunc("PUT /games/{id}/orders/{location}", orderHandler.SaveOrder)
 	api.HandleFunc("POST /games/{id}/orders/ready", orderHandler.MarkReady)
 	api.HandleFunc("DELETE /games/{id}/orders/ready", orderHandler.UnmarkReady)
+	api.HandleFunc("GET /games/{id}/export", exportHandler.ExportGame)
+	api.HandleFunc("GET /games/{id}/replay", replayHandler.GetReplay)
+	api.HandleFunc("GET /games/{id}/report", reportHandler.GetReport)
+	api.HandleFunc("GET /games/{id}/evaluation", analysisHandler.GetEvaluation)
+	api.HandleFunc("GET /games/{id}/analysis", analysisHandler.GetAnalysis)
+	api.HandleFunc("GET /games/{id}/summary", summaryHandler.GetSummary)
+	api.HandleFunc("GET /games/{id}/summary/{image}", summaryHandler.GetSummaryImage)
+	api.HandleFunc("GET /games/{id}/state/compact", orderHandler.CompactState)
 	api.HandleFunc("GET /games/{id}/phases", phaseHandler.ListPhases)
 	api.HandleFunc("GET /games/{id}/phases/current", phaseHandler.CurrentPhase)
 	api.HandleFunc("GET /games/{id}/phases/{phaseId}/orders", phaseHandler.PhaseOrders)
+	api.HandleFunc("POST /games/{id}/phases/{phaseId}/resimulate", replayHandler.Resimulate)
+	api.HandleFunc("GET /games/{id}/support-opportunities", supportHandler.TalkingPoints)
 	api.HandleFunc("GET /games/{id}/messages", messageHandler.ListMessages)
 	api.HandleFunc("POST /games/{id}/messages", messageHandler.SendMessage)
+	api.HandleFunc("POST /games/{id}/messages/{messageId}/import", orderHandler.ImportProposedOrders)
+	api.HandleFunc("GET /games/{id}/channels", channelHandler.ListChannels)
+	api.HandleFunc("POST /games/{id}/channels", channelHandler.CreateChannel)
+	api.HandleFunc("POST /games/{id}/channels/{channelId}/members", channelHandler.AddMember)
+	api.HandleFunc("DELETE /games/{id}/channels/{channelId}/members/{userId}", channelHandler.RemoveMember)
+
+	// Admin (restricted to ADMIN_USER_IDS)
+	api.Handle("GET /admin/games/{id}/flags", adminMw(http.HandlerFunc(adminHandler.GetGameFlags)))
+	api.Handle("PUT /admin/games/{id}/flags/{flag}", adminMw(http.HandlerFunc(adminHandler.SetGameFlag)))
+	api.Handle("DELETE /admin/games/{id}/flags/{flag}", adminMw(http.HandlerFunc(adminHandler.ClearGameFlag)))
+	api.Handle("PUT /admin/flags/{flag}/rollout", adminMw(http.HandlerFunc(adminHandler.SetFlagRollout)))
+	api.Handle("POST /admin/games/purge", adminMw(http.HandlerFunc(adminHandler.PurgeDeletedGames)))
+	api.Handle("GET /admin/engines", adminMw(http.HandlerFunc(adminHandler.EngineStatus)))
+	api.Handle("GET /admin/models", adminMw(http.HandlerFunc(adminHandler.ModelStatus)))
+	api.Handle("POST /admin/models/reload", adminMw(http.HandlerFunc(adminHandler.ReloadModels)))
+	api.Handle("GET /admin/resolution", adminMw(http.HandlerFunc(adminHandler.ResolutionStats)))
+	api.Handle("GET /admin/compute", adminMw(http.HandlerFunc(adminHandler.ComputeUsage)))
+	api.Handle("GET /admin/games/{id}/compute", adminMw(http.HandlerFunc(adminHandler.GameCompute)))
+	api.Handle("GET /admin/jobs", adminMw(http.HandlerFunc(adminHandler.ListJobs)))
+	api.Handle("POST /admin/jobs/{id}/retry", adminMw(http.HandlerFunc(adminHandler.RetryJob)))
+	api.Handle("POST /admin/variants", adminMw(http.HandlerFunc(variantHandler.Upload)))
+	api.Handle("GET /admin/variants/template", adminMw(http.HandlerFunc(variantHandler.Template)))
 
 	mux.Handle("/api/v1/", http.StripPrefix("/api/v1", authMw(api)))
 
+	// Public read-only API (API key, rate limited per key)
+	public := http.NewServeMux()
+	public.HandleFunc("GET /games", publicHandler.ListGames)
+	public.HandleFunc("GET /games/{id}", publicHandler.GetGame)
+	public.HandleFunc("GET /stats/openings", publicHandler.OpeningStats)
+	public.HandleFunc("GET /leaderboard", publicHandler.Leaderboard)
+	publicLimiter := auth.NewRateLimiter(float64(cfg.PublicAPIRatePerMinute)/60, cfg.PublicAPIBurst)
+	publicMw := auth.APIKeyMiddleware(apiKeySvc.Lookup, publicLimiter)
+	mux.Handle("/public/v1/", http.StripPrefix("/public/v1", publicMw(public)))
+
 	// WebSocket (auth via query param, not middleware)
 	mux.HandleFunc("GET /api/v1/ws", wsHandler.ServeWS)
 
@@ -140,7 +386,7 @@ func main() {
 	}
 
 	// Recover active games (rehydrate Redis from Postgres after restart)
-	if err := phaseSvc.RecoverActiveGames(context.Background()); err != nil {
+	if err := phaseSvc.RecoverActiveGames(context.Background(), service.DefaultRecoveryOptions); err != nil {
 		log.Error().Err(err).Msg("Failed to recover active games (non-fatal)")
 	}
 
@@ -149,6 +395,35 @@ func main() {
 	defer cancel()
 	go timerListener.Start(ctx)
 
+	// Deliver events broadcast by other replicas to this one's clients
+	go wsHub.RunRelay(ctx)
+
+	// Permanently remove deleted games once their restore window has passed
+	go gameSvc.RunPurgeJob(ctx, time.Hour)
+
+	// Share this server's viewer counts for the trending games list
+	go trendingSvc.RunReporter(ctx, wsHub, 15*time.Second)
+
+	// Run queued background jobs
+	jobQueue.Start(ctx)
+
+	// Reload gonnx models when their files change
+	if cfg.ModelWatchInterval > 0 {
+		go bot.Models.Watch(ctx, cfg.ModelWatchInterval)
+	}
+
+	// Let DAIDE bots connect over TCP
+	if addr := os.Getenv("DAIDE_ADDR"); addr != "" {
+		daideHost := service.NewDaideHost(gameSvc, orderSvc, phaseSvc, flagSvc, apiKeySvc)
+		daideHost.SetWatcher(wsHub)
+		daideSrv := bot.NewDaideServer(daideHost)
+		go func() {
+			if err := daideSrv.ListenAndServe(ctx, addr); err != nil {
+				log.Error().Err(err).Msg("DAIDE server error")
+			}
+		}()
+	}
+
 	go func() {
 		log.Info().Str("port", cfg.Port).Msg("Server listening")
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -170,3 +445,12 @@ func main() {
 	}
 	log.Info().Msg("Server stopped")
 }
+
+// instanceID identifies this server process among those sharing Redis.
+func instanceID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return host + ":" + strconv.Itoa(os.Getpid())
+}
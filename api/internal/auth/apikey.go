@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const apiKeyIDKey contextKey = "api_key_id"
+
+// APIKeyLookup resolves a presented API key to its ID. It returns ok=false
+// for unknown or revoked keys and an error only when the lookup itself fails.
+type APIKeyLookup func(ctx context.Context, token string) (keyID string, ok bool, err error)
+
+// APIKeyMiddleware returns an HTTP middleware for the public API. It accepts
+// the key as "Authorization: Bearer <key>" or "X-API-Key: <key>", rejects
+// unknown keys and applies the limiter per key.
+func APIKeyMiddleware(lookup APIKeyLookup, limiter *RateLimiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := r.Header.Get("X-API-Key")
+			if token == "" {
+				if scheme, t, ok := strings.Cut(r.Header.Get("Authorization"), " "); ok && strings.EqualFold(scheme, "bearer") {
+					token = t
+				}
+			}
+			if token == "" {
+				http.Error(w, `{"error":"missing api key"}`, http.StatusUnauthorized)
+				return
+			}
+
+			keyID, ok, err := lookup(r.Context(), token)
+			if err != nil {
+				http.Error(w, `{"error":"failed to check api key"}`, http.StatusInternalServerError)
+				return
+			}
+			if !ok {
+				http.Error(w, `{"error":"invalid or revoked api key"}`, http.StatusUnauthorized)
+				return
+			}
+			if wait, allowed := limiter.Allow(keyID); !allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				http.Error(w, `{"error":"rate limit exceeded"}`, http.StatusTooManyRequests)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), apiKeyIDKey, keyID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// APIKeyIDFromContext returns the ID of the API key the request was made with.
+func APIKeyIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(apiKeyIDKey).(string)
+	return id
+}
+
+// RateLimiter is a token bucket per key: each key may burst up to burst
+// requests and then refills at rate requests per second.
+type RateLimiter struct {
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter creates a limiter allowing rate requests per second per
+// key, with bursts of up to burst requests.
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	if rate <= 0 {
+		rate = 1
+	}
+	return &RateLimiter{
+		rate:    rate,
+		burst:   float64(max(burst, 1)),
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Allow takes a token from key's bucket. When the bucket is empty it reports
+// false and how long until the next token is available.
+func (l *RateLimiter) Allow(key string) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+		l.evictIdle(now)
+	}
+	b.tokens = min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, true
+	}
+	return time.Duration((1 - b.tokens) / l.rate * float64(time.Second)), false
+}
+
+// evictIdle drops buckets that have refilled completely, which behave the
+// same as a missing bucket. Callers hold l.mu.
+func (l *RateLimiter) evictIdle(now time.Time) {
+	if len(l.buckets) < 1024 {
+		return
+	}
+	full := time.Duration(l.burst / l.rate * float64(time.Second))
+	for k, b := range l.buckets {
+		if now.Sub(b.last) > full {
+			delete(l.buckets, k)
+		}
+	}
+}
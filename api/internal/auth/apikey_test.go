@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterBurstAndRefill(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := NewRateLimiter(1, 2)
+	l.now = func() time.Time { return now }
+
+	for i := range 2 {
+		if _, ok := l.Allow("k"); !ok {
+			t.Fatalf("request %d within burst was limited", i)
+		}
+	}
+	wait, ok := l.Allow("k")
+	if ok || wait != time.Second {
+		t.Errorf("expected third request limited for 1s, got ok=%v wait=%v", ok, wait)
+	}
+	if _, ok := l.Allow("other"); !ok {
+		t.Error("keys should have separate buckets")
+	}
+	now = now.Add(time.Second)
+	if _, ok := l.Allow("k"); !ok {
+		t.Error("expected a token after refilling for 1s")
+	}
+}
+
+func TestAPIKeyMiddleware(t *testing.T) {
+	lookup := func(_ context.Context, token string) (string, bool, error) {
+		return "key-1", token == "pb_good", nil
+	}
+	var gotKey string
+	inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotKey = APIKeyIDFromContext(r.Context())
+	})
+	handler := APIKeyMiddleware(lookup, NewRateLimiter(1, 1))(inner)
+
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		want   int
+	}{
+		{"missing", "", "", http.StatusUnauthorized},
+		{"invalid", "X-API-Key", "pb_bad", http.StatusUnauthorized},
+		{"bearer", "Authorization", "Bearer pb_good", http.StatusOK},
+		{"limited", "X-API-Key", "pb_good", http.StatusTooManyRequests},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/games", nil)
+		if tt.header != "" {
+			req.Header.Set(tt.header, tt.value)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.want, rec.Code)
+		}
+		if tt.want == http.StatusTooManyRequests && rec.Header().Get("Retry-After") != "1" {
+			t.Errorf("%s: expected Retry-After 1, got %q", tt.name, rec.Header().Get("Retry-After"))
+		}
+	}
+	if gotKey != "key-1" {
+		t.Errorf("expected key ID in context, got %q", gotKey)
+	}
+}
@@ -45,3 +45,21 @@ func UserIDFromContext(ctx context.Context) string {
 	id, _ := ctx.Value(userIDKey).(string)
 	return id
 }
+
+// RequireAdmin returns an HTTP middleware that only lets through requests whose
+// authenticated user ID is in adminIDs. It must run after Middleware.
+func RequireAdmin(adminIDs []string) func(http.Handler) http.Handler {
+	allowed := make(map[string]bool, len(adminIDs))
+	for _, id := range adminIDs {
+		allowed[id] = true
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !allowed[UserIDFromContext(r.Context())] {
+				http.Error(w, `{"error":"admin access required"}`, http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
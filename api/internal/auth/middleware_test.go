@@ -122,3 +122,28 @@ func TestUserIDFromContextEmpty(t *testing.T) {
 		t.Errorf("expected empty user ID from context without auth, got %s", id)
 	}
 }
+
+func TestRequireAdmin(t *testing.T) {
+	inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := RequireAdmin([]string{"admin-1"})(inner)
+
+	tests := []struct {
+		userID string
+		want   int
+	}{
+		{"admin-1", http.StatusOK},
+		{"user-2", http.StatusForbidden},
+		{"", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+		req = req.WithContext(SetUserIDForTest(req.Context(), tt.userID))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("user %q: expected %d, got %d", tt.userID, tt.want, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package bot
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+const (
+	// DefaultPactYears is how many years a bot honors a non-aggression pact
+	// before it considers breaking it.
+	DefaultPactYears = 2
+	// DefaultBetrayalGain is how many centers a betrayal must be expected to
+	// win before a bot breaks a pact past its horizon.
+	DefaultBetrayalGain = 2.0
+
+	initialTrust   = 0.5
+	pactTrustBonus = 0.1  // trust gained when a pact is agreed
+	honorTrustGain = 0.02 // trust gained each phase a partner keeps the pact
+	takenTrustLoss = 0.3  // trust lost when a power takes one of our centers
+	brokenPactLoss = 0.2  // further trust lost when that power had a pact with us
+)
+
+// Pact is a non-aggression pact a bot has agreed with another power.
+type Pact struct {
+	Year   int  `json:"year"`             // year the pact was agreed
+	Broken bool `json:"broken,omitempty"` // broken by either side; never renewed
+}
+
+// AllianceState is one bot's view of its pacts and how far it trusts each
+// other power, carried from phase to phase of a game.
+type AllianceState struct {
+	Pacts    map[diplomacy.Power]*Pact   `json:"pacts"`
+	Proposed map[diplomacy.Power]bool    `json:"proposed,omitempty"` // pacts we offered and await an answer to
+	Trust    map[diplomacy.Power]float64 `json:"trust"`
+	Held     []string                    `json:"held,omitempty"`     // our centers when last observed
+	Observed string                      `json:"observed,omitempty"` // phase last observed
+}
+
+// NewAllianceState returns a state with no pacts and neutral trust.
+func NewAllianceState() *AllianceState {
+	return &AllianceState{
+		Pacts:    make(map[diplomacy.Power]*Pact),
+		Proposed: make(map[diplomacy.Power]bool),
+		Trust:    make(map[diplomacy.Power]float64),
+	}
+}
+
+// DecodeAllianceState decodes a state saved as JSON; empty data decodes to a
+// new state.
+func DecodeAllianceState(data []byte) (*AllianceState, error) {
+	a := NewAllianceState()
+	if len(data) == 0 {
+		return a, nil
+	}
+	if err := json.Unmarshal(data, a); err != nil {
+		return nil, err
+	}
+	if a.Pacts == nil {
+		a.Pacts = make(map[diplomacy.Power]*Pact)
+	}
+	if a.Proposed == nil {
+		a.Proposed = make(map[diplomacy.Power]bool)
+	}
+	if a.Trust == nil {
+		a.Trust = make(map[diplomacy.Power]float64)
+	}
+	return a, nil
+}
+
+// TrustIn returns the bot's trust in p, from 0 to 1.
+func (a *AllianceState) TrustIn(p diplomacy.Power) float64 {
+	if t, ok := a.Trust[p]; ok {
+		return t
+	}
+	return initialTrust
+}
+
+func (a *AllianceState) adjustTrust(p diplomacy.Power, delta float64) {
+	a.Trust[p] = min(1, max(0, a.TrustIn(p)+delta))
+}
+
+// ActivePact returns the unbroken pact with p, or nil.
+func (a *AllianceState) ActivePact(p diplomacy.Power) *Pact {
+	if pact := a.Pacts[p]; pact != nil && !pact.Broken {
+		return pact
+	}
+	return nil
+}
+
+// RecordProposal notes that the bot offered p a pact.
+func (a *AllianceState) RecordProposal(p diplomacy.Power) {
+	if a.Pacts[p] == nil {
+		a.Proposed[p] = true
+	}
+}
+
+// RecordPact records a pact with p agreed in year. A pact already agreed,
+// or one that was broken, is left as it is.
+func (a *AllianceState) RecordPact(p diplomacy.Power, year int) {
+	delete(a.Proposed, p)
+	if a.Pacts[p] != nil {
+		return
+	}
+	a.Pacts[p] = &Pact{Year: year}
+	a.adjustTrust(p, pactTrustBonus)
+}
+
+// RecordIntents updates pacts from a phase's press: requests received from
+// other powers, and the bot's replies and proposals. A pact is agreed when
+// the bot accepts another power's proposal, or another power accepts one of
+// the bot's. Pact intents are those proposing non-aggression or alliance.
+// Intents guessed from free text never make a pact, since the words may not
+// mean what the keywords suggest.
+func (a *AllianceState) RecordIntents(year int, received, sent []DiplomaticIntent) {
+	offered := make(map[diplomacy.Power]bool)
+	for _, in := range received {
+		if in.Guessed {
+			continue
+		}
+		switch in.Type {
+		case IntentProposeNonAggression, IntentProposeAlliance:
+			offered[in.From] = true
+		case IntentAccept:
+			if a.Proposed[in.From] {
+				a.RecordPact(in.From, year)
+			}
+		}
+	}
+	for _, out := range sent {
+		switch out.Type {
+		case IntentProposeNonAggression, IntentProposeAlliance:
+			a.RecordProposal(out.To)
+		case IntentAccept:
+			if offered[out.To] {
+				a.RecordPact(out.To, year)
+			}
+		}
+	}
+}
+
+// Observe updates trust from the board in phase, once per phase: a power
+// that took one of power's centers since the last observation loses trust,
+// and breaks its pact if it had one, while partners keeping their pacts
+// gain a little.
+func (a *AllianceState) Observe(phaseID string, gs *diplomacy.GameState, power diplomacy.Power) {
+	if a.Observed == phaseID {
+		return
+	}
+	a.Observed = phaseID
+	for _, sc := range a.Held {
+		owner := gs.SupplyCenters[sc]
+		if owner == "" || owner == power || owner == diplomacy.Neutral {
+			continue
+		}
+		a.adjustTrust(owner, -takenTrustLoss)
+		if pact := a.ActivePact(owner); pact != nil {
+			pact.Broken = true
+			a.adjustTrust(owner, -brokenPactLoss)
+		}
+	}
+	for p, pact := range a.Pacts {
+		if !pact.Broken {
+			a.adjustTrust(p, honorTrustGain)
+		}
+	}
+	a.Held = a.Held[:0]
+	for sc, owner := range gs.SupplyCenters {
+		if owner == power {
+			a.Held = append(a.Held, sc)
+		}
+	}
+	sort.Strings(a.Held)
+}
+
+// RecordOrders marks as broken the pacts with every power whose centers or
+// units the bot's orders attack.
+func (a *AllianceState) RecordOrders(gs *diplomacy.GameState, power diplomacy.Power, orders []OrderInput) {
+	for _, o := range orders {
+		if o.OrderType != "move" {
+			continue
+		}
+		for _, p := range attackedPowers(o.Target, gs, power) {
+			if pact := a.ActivePact(p); pact != nil {
+				pact.Broken = true
+			}
+		}
+	}
+}
+
+// attackedPowers returns the powers a move into target attacks: the owner of
+// a center there and the power of a unit there, other than power itself.
+func attackedPowers(target string, gs *diplomacy.GameState, power diplomacy.Power) []diplomacy.Power {
+	var out []diplomacy.Power
+	if owner := gs.SupplyCenters[target]; owner != "" && owner != power && owner != diplomacy.Neutral {
+		out = append(out, owner)
+	}
+	if u := gs.UnitAt(target); u != nil && u.Power != power && (len(out) == 0 || u.Power != out[0]) {
+		out = append(out, u.Power)
+	}
+	return out
+}
+
+// betrayalGain estimates how many of target's centers candidate would take:
+// a move into one of them counts when its strength (the move plus the
+// candidate's supports for it) beats the defender holding it and every
+// unit of target's that could support the hold.
+func betrayalGain(candidate []OrderInput, gs *diplomacy.GameState, target diplomacy.Power, m *diplomacy.DiplomacyMap) float64 {
+	gain := 0.0
+	for _, o := range candidate {
+		if o.OrderType != "move" || gs.SupplyCenters[o.Target] != target {
+			continue
+		}
+		strength := 1
+		for _, s := range candidate {
+			if s.OrderType == "support" && s.AuxLoc == o.Location && s.AuxTarget == o.Target {
+				strength++
+			}
+		}
+		defense := 0
+		if u := gs.UnitAt(o.Target); u != nil {
+			defense++
+		}
+		for _, u := range gs.UnitsOf(target) {
+			if u.Province != o.Target && unitCanReach(u, o.Target, m) {
+				defense++
+			}
+		}
+		if strength > defense {
+			gain++
+		}
+	}
+	return gain
+}
@@ -0,0 +1,150 @@
+package bot
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+func TestAllianceStateRecordsPacts(t *testing.T) {
+	a := NewAllianceState()
+	received := []DiplomaticIntent{{Type: IntentProposeNonAggression, From: diplomacy.Germany, To: diplomacy.France}}
+	sent := []DiplomaticIntent{
+		{Type: IntentAccept, From: diplomacy.France, To: diplomacy.Germany},
+		{Type: IntentProposeNonAggression, From: diplomacy.France, To: diplomacy.England},
+	}
+	a.RecordIntents(1901, received, sent)
+	if a.ActivePact(diplomacy.Germany) == nil {
+		t.Fatal("expected a pact with Germany after accepting its proposal")
+	}
+	if a.ActivePact(diplomacy.England) != nil || !a.Proposed[diplomacy.England] {
+		t.Fatal("expected England's pact to await an answer")
+	}
+
+	a.RecordIntents(1902, []DiplomaticIntent{{Type: IntentAccept, From: diplomacy.England}}, nil)
+	if pact := a.ActivePact(diplomacy.England); pact == nil || pact.Year != 1902 {
+		t.Fatalf("expected a 1902 pact with England, got %+v", pact)
+	}
+	if a.TrustIn(diplomacy.England) <= a.TrustIn(diplomacy.Italy) {
+		t.Errorf("a pact should raise trust: england %.2f, italy %.2f", a.TrustIn(diplomacy.England), a.TrustIn(diplomacy.Italy))
+	}
+
+	data, err := json.Marshal(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := DecodeAllianceState(data)
+	if err != nil || decoded.ActivePact(diplomacy.Germany) == nil {
+		t.Fatalf("round trip lost the pact: %+v, %v", decoded, err)
+	}
+	if empty, err := DecodeAllianceState(nil); err != nil || empty.Proposed == nil {
+		t.Fatalf("empty data should decode to a new state: %+v, %v", empty, err)
+	}
+}
+
+func TestAllianceStateIgnoresGuessedIntents(t *testing.T) {
+	a := NewAllianceState()
+	a.RecordProposal(diplomacy.Germany)
+	received := []DiplomaticIntent{
+		{Type: IntentAccept, From: diplomacy.Germany, Guessed: true},
+		{Type: IntentProposeAlliance, From: diplomacy.England, Guessed: true},
+	}
+	sent := []DiplomaticIntent{{Type: IntentAccept, From: diplomacy.France, To: diplomacy.England}}
+	a.RecordIntents(1901, received, sent)
+	if a.ActivePact(diplomacy.Germany) != nil || a.ActivePact(diplomacy.England) != nil {
+		t.Error("expected no pact from intents guessed out of free text")
+	}
+}
+
+func TestAllianceStateObserveBetrayal(t *testing.T) {
+	gs := diplomacy.NewInitialState()
+	a := NewAllianceState()
+	a.RecordPact(diplomacy.Germany, 1901)
+	a.Observe("p1", gs, diplomacy.France)
+	trust := a.TrustIn(diplomacy.Germany)
+
+	a.Observe("p1", gs, diplomacy.France)
+	if a.TrustIn(diplomacy.Germany) != trust {
+		t.Error("observing the same phase twice changed trust")
+	}
+
+	gs.SupplyCenters["par"] = diplomacy.Germany
+	a.Observe("p2", gs, diplomacy.France)
+	if a.ActivePact(diplomacy.Germany) != nil {
+		t.Error("taking Paris should break Germany's pact")
+	}
+	if a.TrustIn(diplomacy.Germany) >= trust-takenTrustLoss {
+		t.Errorf("trust in Germany %.2f, want well below %.2f", a.TrustIn(diplomacy.Germany), trust)
+	}
+}
+
+// betrayalState has France with a pact with Germany, able to take Munich
+// with support from Burgundy and Ruhr against a lone army.
+func betrayalState() *diplomacy.GameState {
+	return &diplomacy.GameState{
+		Year:   1905,
+		Season: diplomacy.Spring,
+		Phase:  diplomacy.PhaseMovement,
+		Units: []diplomacy.Unit{
+			{Type: diplomacy.Army, Power: diplomacy.France, Province: "bur"},
+			{Type: diplomacy.Army, Power: diplomacy.France, Province: "ruh"},
+			{Type: diplomacy.Army, Power: diplomacy.France, Province: "tyr"},
+			{Type: diplomacy.Army, Power: diplomacy.Germany, Province: "mun"},
+		},
+		SupplyCenters: map[string]diplomacy.Power{"mun": diplomacy.Germany, "ber": diplomacy.Germany, "par": diplomacy.France},
+	}
+}
+
+func TestCooperationPenaltyHonorsPacts(t *testing.T) {
+	m := diplomacy.StandardMap()
+	gs := betrayalState()
+	attack := []OrderInput{
+		{UnitType: "army", Location: "bur", OrderType: "move", Target: "mun"},
+		{UnitType: "army", Location: "ruh", OrderType: "support", AuxLoc: "bur", AuxTarget: "mun", AuxUnitType: "army"},
+		{UnitType: "army", Location: "tyr", OrderType: "support", AuxLoc: "bur", AuxTarget: "mun", AuxUnitType: "army"},
+	}
+	if g := betrayalGain(attack, gs, diplomacy.Germany, m); g != 1 {
+		t.Fatalf("betrayal gain %v, want 1", g)
+	}
+
+	s := HardStrategy{}
+	if p := s.cooperationPenalty(attack, gs, diplomacy.France, m, nil); p != 0 {
+		t.Errorf("single-front attack without alliances: penalty %v, want 0", p)
+	}
+
+	a := NewAllianceState()
+	a.RecordPact(diplomacy.Germany, 1904)
+	if p := s.cooperationPenalty(attack, gs, diplomacy.France, m, a); p < pactPenalty {
+		t.Errorf("attacking a fresh partner: penalty %v, want at least %v", p, pactPenalty)
+	}
+
+	// Past the horizon, the betrayal is taken only if it gains enough.
+	s = HardStrategy{PactYears: 1, BetrayalGain: 2}
+	if p := s.cooperationPenalty(attack, gs, diplomacy.France, m, a); p < pactPenalty {
+		t.Errorf("betrayal gaining 1 center below threshold 2: penalty %v, want at least %v", p, pactPenalty)
+	}
+	s.BetrayalGain = 1
+	if p := s.cooperationPenalty(attack, gs, diplomacy.France, m, a); p != 0 {
+		t.Errorf("timed betrayal: penalty %v, want 0", p)
+	}
+
+	a.RecordOrders(gs, diplomacy.France, attack)
+	if a.ActivePact(diplomacy.Germany) != nil {
+		t.Error("attacking Munich should break the pact")
+	}
+}
+
+func TestHardStrategyPactOptions(t *testing.T) {
+	s := NewStrategy("hard", StrategyOptions{"pact_years": "4", "betray_gain": "3"})
+	hard, ok := s.(*HardStrategy)
+	if !ok {
+		t.Fatalf("expected *HardStrategy, got %T", s)
+	}
+	if hard.pactYears() != 4 || hard.betrayalGain() != 3 {
+		t.Errorf("options not applied: %+v", hard)
+	}
+	if d := (HardStrategy{}); d.pactYears() != DefaultPactYears || d.betrayalGain() != DefaultBetrayalGain {
+		t.Errorf("zero value should use the defaults")
+	}
+}
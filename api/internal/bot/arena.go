@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"slices"
 	"time"
 
 	"github.com/rs/zerolog/log"
@@ -19,8 +20,36 @@ type ArenaConfig struct {
 	GameName    string
 	PowerConfig map[diplomacy.Power]string // power -> difficulty level
 	MaxYear     int                        // cap year for draw (e.g. 1920)
+	VictorySCs  int                        // centers for a solo; 0 = 18
 	Seed        int64                      // 0 = random
 	DryRun      bool                       // skip DB writes
+
+	// Players seats these user IDs as human players instead of bot users;
+	// their orders still come from PowerConfig.
+	Players map[diplomacy.Power]string
+	// StopYear, if set, leaves the game active once the first phase of that
+	// year has been created, instead of playing it to the end.
+	StopYear int
+
+	// CoordinateRetreats runs CoordinateRetreats over the bots' retreat
+	// orders so that two bots never retreat into the same province and
+	// destroy each other. Powers driven by Strategies are left alone.
+	CoordinateRetreats bool
+
+	// Strategies overrides PowerConfig with ready-made strategies, e.g. one
+	// driven by a human at a terminal.
+	Strategies map[diplomacy.Power]Strategy
+	// Personalities gives bots a personality: a preset, "random" or traits,
+	// as read by ParsePersonality. Random ones come from the seeded bot RNG.
+	Personalities map[diplomacy.Power]string
+	// OnPhase, if set, is called after each phase resolves with the resolved
+	// state (before it advances) and the orders with their results.
+	OnPhase func(gs *diplomacy.GameState, orders []model.Order)
+	// OnOrders, if set, is called after each phase resolves with the state
+	// the phase started from and the orders each power played, in DSON and
+	// with coasts intact. Movement orders include the holds given to
+	// unordered units.
+	OnOrders func(before *diplomacy.GameState, orders map[diplomacy.Power][]diplomacy.DSONOrder)
 }
 
 // ArenaResult describes the outcome of a completed arena game.
@@ -30,9 +59,10 @@ type ArenaResult struct {
 	FinalYear     int
 	FinalSeason   string
 	TotalPhases   int
-	SCCounts      map[string]int   // power -> final SC count
-	SCTimeline    map[string][]int // power -> SC count after each Fall (indexed by year - 1901)
-	TimelineYears []int            // years corresponding to SCTimeline entries
+	SCCounts      map[string]int         // power -> final SC count
+	SCTimeline    map[string][]int       // power -> SC count after each Fall (indexed by year - 1901)
+	TimelineYears []int                  // years corresponding to SCTimeline entries
+	Personalities map[string]Personality // power -> personality its bot played with
 }
 
 // RunGame plays a full Diplomacy game using bot strategies, saving results to Postgres.
@@ -47,6 +77,9 @@ func RunGame(
 	if cfg.MaxYear == 0 {
 		cfg.MaxYear = 1930
 	}
+	if cfg.VictorySCs < 0 || cfg.VictorySCs > len(diplomacy.NewInitialState().SupplyCenters) {
+		return nil, fmt.Errorf("victory SCs %d out of range", cfg.VictorySCs)
+	}
 
 	// Seed the package-level RNG for reproducible bot behavior.
 	if cfg.Seed != 0 {
@@ -54,15 +87,35 @@ func RunGame(
 		defer ResetBotRng()
 	}
 
+	// Draw personalities before any strategy needs closing
+	personalities := make(map[string]Personality)
+	for _, p := range diplomacy.AllPowers() {
+		if spec, ok := cfg.Personalities[p]; ok {
+			personality, err := ParsePersonality(spec)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", p, err)
+			}
+			personalities[string(p)] = personality
+		}
+	}
+
 	// Build strategies per power
 	strategies := make(map[diplomacy.Power]Strategy)
 	for _, p := range diplomacy.AllPowers() {
+		if s, ok := cfg.Strategies[p]; ok {
+			strategies[p] = s
+			continue
+		}
 		diff, ok := cfg.PowerConfig[p]
 		if !ok {
 			diff = "easy"
 		}
-		s := StrategyForDifficulty(diff)
-		strategies[p] = s
+		strategies[p] = StrategyForDifficulty(diff)
+		if personality, ok := personalities[string(p)]; ok {
+			if _, err := WithPersonality(strategies[p], personality); err != nil {
+				return nil, fmt.Errorf("%s: %w", p, err)
+			}
+		}
 	}
 	// Close strategies that implement io.Closer (e.g. ExternalStrategy) on exit.
 	defer func() {
@@ -85,13 +138,16 @@ func RunGame(
 
 	// Initialize game state
 	gs := diplomacy.NewInitialState()
+	gs.YearLimit = cfg.MaxYear
+	gs.VictorySCs = cfg.VictorySCs
 	m := diplomacy.StandardMap()
 	resolver := diplomacy.NewResolver(34)
 
 	result := &ArenaResult{
-		GameID:     gameID,
-		SCCounts:   make(map[string]int),
-		SCTimeline: make(map[string][]int),
+		GameID:        gameID,
+		SCCounts:      make(map[string]int),
+		SCTimeline:    make(map[string][]int),
+		Personalities: personalities,
 	}
 
 	for {
@@ -99,8 +155,6 @@ func RunGame(
 			return nil, ctx.Err()
 		}
 
-		result.TotalPhases++
-
 		// Serialize state before
 		stateBefore, err := json.Marshal(gs)
 		if err != nil {
@@ -117,27 +171,57 @@ func RunGame(
 			}
 			phaseID = phase.ID
 		}
+		if cfg.StopYear > 0 && gs.Year >= cfg.StopYear {
+			result.FinalYear = gs.Year
+			result.FinalSeason = string(gs.Season)
+			fillSCCounts(result, gs)
+			log.Info().Str("gameId", gameID).Int("year", gs.Year).Msg("Arena game stopped")
+			return result, nil
+		}
+		result.TotalPhases++
 
 		// Generate and resolve orders based on phase type
+		var before *diplomacy.GameState
+		var played map[diplomacy.Power][]diplomacy.DSONOrder
+		if cfg.OnOrders != nil {
+			before = gs.Clone()
+			played = make(map[diplomacy.Power][]diplomacy.DSONOrder)
+		}
 		var modelOrders []model.Order
 		switch gs.Phase {
 		case diplomacy.PhaseMovement:
-			modelOrders, err = resolveMovementPhase(gs, m, resolver, strategies, phaseID)
+			modelOrders, err = resolveMovementPhase(gs, m, resolver, strategies, phaseID, played)
 		case diplomacy.PhaseRetreat:
-			modelOrders, err = resolveRetreatPhase(gs, m, strategies, phaseID)
+			var coordinated map[diplomacy.Power]bool
+			if cfg.CoordinateRetreats {
+				coordinated = make(map[diplomacy.Power]bool)
+				for p := range strategies {
+					_, overridden := cfg.Strategies[p]
+					coordinated[p] = !overridden
+				}
+			}
+			modelOrders, err = resolveRetreatPhase(gs, m, strategies, coordinated, phaseID, played)
 		case diplomacy.PhaseBuild:
-			modelOrders, err = resolveBuildPhase(gs, m, strategies, phaseID)
+			modelOrders, err = resolveBuildPhase(gs, m, strategies, phaseID, played)
 		}
 		if err != nil {
 			return nil, fmt.Errorf("resolve %s phase (year %d %s): %w", gs.Phase, gs.Year, gs.Season, err)
 		}
+		if cfg.OnOrders != nil {
+			cfg.OnOrders(before, played)
+		}
 
-		// After Fall movement/retreat, update SC ownership before saving stateAfter
-		// so the resolved phase reflects the correct final SC distribution.
-		if gs.Season == diplomacy.Fall && (gs.Phase == diplomacy.PhaseMovement || gs.Phase == diplomacy.PhaseRetreat) {
+		// After Fall (the year's last season) movement/retreat, update SC ownership
+		// before saving stateAfter so the resolved phase reflects the correct final
+		// SC distribution.
+		if gs.IsLastSeason() && (gs.Phase == diplomacy.PhaseMovement || gs.Phase == diplomacy.PhaseRetreat) {
 			diplomacy.UpdateSupplyCenterOwnership(gs)
 		}
 
+		if cfg.OnPhase != nil {
+			cfg.OnPhase(gs, modelOrders)
+		}
+
 		// Save state after and orders
 		stateAfter, err := json.Marshal(gs)
 		if err != nil {
@@ -157,13 +241,13 @@ func RunGame(
 
 		// Advance game state (updates year/season/phase, SC ownership)
 		hasDislodgements := len(gs.Dislodged) > 0
-		prevSeason := gs.Season
+		prevLastSeason := gs.IsLastSeason()
 		prevPhase := gs.Phase
 		prevYear := gs.Year
 		diplomacy.AdvanceState(gs, hasDislodgements)
 
 		// Snapshot SC counts after Fall resolution (SC ownership just updated)
-		if prevSeason == diplomacy.Fall && (prevPhase == diplomacy.PhaseMovement || prevPhase == diplomacy.PhaseRetreat) {
+		if prevLastSeason && (prevPhase == diplomacy.PhaseMovement || prevPhase == diplomacy.PhaseRetreat) {
 			result.TimelineYears = append(result.TimelineYears, prevYear)
 			for _, p := range diplomacy.AllPowers() {
 				pStr := string(p)
@@ -188,7 +272,7 @@ func RunGame(
 		}
 
 		// Check year limit
-		if gs.Year > cfg.MaxYear {
+		if diplomacy.IsYearLimitReached(gs) {
 			result.Winner = ""
 			result.FinalYear = gs.Year
 			result.FinalSeason = string(gs.Season)
@@ -210,13 +294,15 @@ func RunGame(
 	}
 }
 
-// resolveMovementPhase generates movement orders, resolves them, and applies results.
+// resolveMovementPhase generates movement orders, resolves them, and applies
+// results. If played is non-nil, the validated orders are added to it.
 func resolveMovementPhase(
 	gs *diplomacy.GameState,
 	m *diplomacy.DiplomacyMap,
 	resolver *diplomacy.Resolver,
 	strategies map[diplomacy.Power]Strategy,
 	phaseID string,
+	played map[diplomacy.Power][]diplomacy.DSONOrder,
 ) ([]model.Order, error) {
 	var allOrders []diplomacy.Order
 
@@ -234,6 +320,11 @@ func resolveMovementPhase(
 
 	// Validate and default unordered units to hold
 	validated, _ := diplomacy.ValidateAndDefaultOrders(allOrders, gs, m)
+	if played != nil {
+		for _, o := range validated {
+			played[o.Power] = append(played[o.Power], diplomacy.OrderToDSON(o))
+		}
+	}
 
 	// Resolve
 	results, dislodged := resolver.Resolve(validated, gs, m)
@@ -250,14 +341,18 @@ func resolveMovementPhase(
 	return resolvedOrdersToModel(phaseID, resultsCopy), nil
 }
 
-// resolveRetreatPhase generates retreat orders, resolves them, and applies results.
+// resolveRetreatPhase generates retreat orders, resolves them, and applies
+// results. The orders of the coordinated powers are first deconflicted with
+// CoordinateRetreats. If played is non-nil, the orders are added to it.
 func resolveRetreatPhase(
 	gs *diplomacy.GameState,
 	m *diplomacy.DiplomacyMap,
 	strategies map[diplomacy.Power]Strategy,
+	coordinated map[diplomacy.Power]bool,
 	phaseID string,
+	played map[diplomacy.Power][]diplomacy.DSONOrder,
 ) ([]model.Order, error) {
-	var allOrders []diplomacy.RetreatOrder
+	inputs := make(map[diplomacy.Power][]OrderInput)
 
 	for _, power := range diplomacy.AllPowers() {
 		strategy := strategies[power]
@@ -277,24 +372,48 @@ func resolveRetreatPhase(
 			continue
 		}
 
-		inputs := strategy.GenerateRetreatOrders(gs, power, m)
-		for _, in := range inputs {
+		inputs[power] = strategy.GenerateRetreatOrders(gs, power, m)
+	}
+
+	if len(coordinated) > 0 {
+		botInputs := make(map[diplomacy.Power][]OrderInput)
+		for power, in := range inputs {
+			if coordinated[power] {
+				botInputs[power] = in
+			}
+		}
+		if n := CoordinateRetreats(gs, m, botInputs); n > 0 {
+			log.Debug().Int("changed", n).Msg("Coordinated bot retreats")
+		}
+	}
+
+	var allOrders []diplomacy.RetreatOrder
+	for _, power := range diplomacy.AllPowers() {
+		for _, in := range inputs[power] {
 			allOrders = append(allOrders, inputToRetreatOrder(in, power))
 		}
 	}
 
+	if played != nil {
+		for _, o := range allOrders {
+			played[o.Power] = append(played[o.Power], diplomacy.RetreatOrderToDSON(o))
+		}
+	}
+
 	results := diplomacy.ResolveRetreats(allOrders, gs, m)
 	diplomacy.ApplyRetreats(gs, results, m)
 
 	return retreatResultsToModel(phaseID, results), nil
 }
 
-// resolveBuildPhase generates build orders, resolves them, and applies results.
+// resolveBuildPhase generates build orders, resolves them, and applies
+// results. If played is non-nil, the orders are added to it.
 func resolveBuildPhase(
 	gs *diplomacy.GameState,
 	m *diplomacy.DiplomacyMap,
 	strategies map[diplomacy.Power]Strategy,
 	phaseID string,
+	played map[diplomacy.Power][]diplomacy.DSONOrder,
 ) ([]model.Order, error) {
 	var allOrders []diplomacy.BuildOrder
 
@@ -304,9 +423,7 @@ func resolveBuildPhase(
 			continue
 		}
 
-		scCount := gs.SupplyCenterCount(power)
-		unitCount := gs.UnitCount(power)
-		if scCount == unitCount {
+		if gs.AdjustmentDelta(power) == 0 {
 			continue
 		}
 
@@ -316,6 +433,12 @@ func resolveBuildPhase(
 		}
 	}
 
+	if played != nil {
+		for _, o := range allOrders {
+			played[o.Power] = append(played[o.Power], diplomacy.BuildOrderToDSON(o))
+		}
+	}
+
 	results := diplomacy.ResolveBuildOrders(allOrders, gs, m)
 	diplomacy.ApplyBuildOrders(gs, results)
 
@@ -329,11 +452,12 @@ func createArenaGame(
 	gameRepo repository.GameRepository,
 	userRepo repository.UserRepository,
 ) (string, error) {
-	// Create bot users and collect user IDs
+	// Create bot users, or take the given players, and collect user IDs
 	type botInfo struct {
 		userID     string
 		power      diplomacy.Power
 		difficulty string
+		human      bool
 	}
 	var bots []botInfo
 
@@ -343,6 +467,10 @@ func createArenaGame(
 		if diff == "" {
 			diff = "easy"
 		}
+		if userID, ok := cfg.Players[power]; ok {
+			bots = append(bots, botInfo{userID: userID, power: power, human: true})
+			continue
+		}
 
 		providerID := fmt.Sprintf("botmatch-%s-%s", power, diff)
 		displayName := fmt.Sprintf("Bot %s (%s)", power, diff)
@@ -353,19 +481,29 @@ func createArenaGame(
 		bots = append(bots, botInfo{userID: user.ID, power: power, difficulty: diff})
 	}
 
-	// Create the game with the first bot as creator
+	// Create the game with the first player, or else the first bot, as creator
 	gameName := cfg.GameName
 	if gameName == "" {
 		gameName = "botmatch"
 	}
+	creator := bots[0].userID
+	if i := slices.IndexFunc(bots, func(b botInfo) bool { return b.human }); i >= 0 {
+		creator = bots[i].userID
+	}
 
-	game, err := gameRepo.Create(ctx, gameName, bots[0].userID, "1 hours", "1 hours", "1 hours", "manual")
+	game, err := gameRepo.Create(ctx, gameName, creator, "1 hours", "1 hours", "1 hours", "manual", "", diplomacy.ScenarioStandard, model.GameRules{})
 	if err != nil {
 		return "", fmt.Errorf("create game: %w", err)
 	}
 
 	// Join all bots
 	for _, b := range bots {
+		if b.human {
+			if err := gameRepo.JoinGame(ctx, game.ID, b.userID); err != nil {
+				return "", fmt.Errorf("join player %s: %w", b.power, err)
+			}
+			continue
+		}
 		if err := gameRepo.JoinGameAsBot(ctx, game.ID, b.userID, b.difficulty); err != nil {
 			return "", fmt.Errorf("join bot %s: %w", b.power, err)
 		}
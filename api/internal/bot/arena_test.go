@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/freeeve/polite-betrayal/api/internal/model"
 	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
 )
 
@@ -47,6 +48,37 @@ func TestRunGameDryRun(t *testing.T) {
 	}
 }
 
+func TestRunGameStrategyOverrideAndOnPhase(t *testing.T) {
+	phases := 0
+	frenchMoved := false
+	cfg := ArenaConfig{
+		PowerConfig: ParsePowerConfig("*=random"),
+		MaxYear:     1902,
+		Seed:        7,
+		DryRun:      true,
+		Strategies:  map[diplomacy.Power]Strategy{diplomacy.France: HoldStrategy{}},
+		OnPhase: func(gs *diplomacy.GameState, orders []model.Order) {
+			phases++
+			for _, o := range orders {
+				if o.Power == "france" && o.OrderType == "move" {
+					frenchMoved = true
+				}
+			}
+		},
+	}
+
+	result, err := RunGame(context.Background(), cfg, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("RunGame failed: %v", err)
+	}
+	if phases != result.TotalPhases {
+		t.Errorf("OnPhase called %d times for %d phases", phases, result.TotalPhases)
+	}
+	if frenchMoved {
+		t.Error("france should only hold with the HoldStrategy override")
+	}
+}
+
 func TestRunGameCompletes(t *testing.T) {
 	// Verify that a game with mixed difficulties completes without error.
 	ctx := context.Background()
@@ -101,6 +133,83 @@ func TestRunGameMaxYear(t *testing.T) {
 	t.Logf("Result: winner=%q year=%d phases=%d", result.Winner, result.FinalYear, result.TotalPhases)
 }
 
+func TestRunGameVictorySCs(t *testing.T) {
+	ctx := context.Background()
+	cfg := ArenaConfig{
+		GameName:    "test-victory-scs",
+		PowerConfig: ParsePowerConfig("*=easy"),
+		MaxYear:     1910,
+		VictorySCs:  5,
+		Seed:        11,
+		DryRun:      true,
+	}
+
+	result, err := RunGame(ctx, cfg, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("RunGame failed: %v", err)
+	}
+	if result.Winner == "" || result.SCCounts[result.Winner] < 5 {
+		t.Errorf("expected a solo at 5 centers, got winner=%q counts=%v", result.Winner, result.SCCounts)
+	}
+
+	cfg.VictorySCs = 35
+	if _, err := RunGame(ctx, cfg, nil, nil, nil); err == nil {
+		t.Error("expected more centers than the board has to be rejected")
+	}
+}
+
+func TestRunGamePersonalities(t *testing.T) {
+	ctx := context.Background()
+	cfg := ArenaConfig{
+		GameName:      "test-personalities",
+		PowerConfig:   ParsePowerConfig("*=easy"),
+		MaxYear:       1902,
+		Seed:          5,
+		DryRun:        true,
+		Personalities: map[diplomacy.Power]string{diplomacy.France: "aggressive", diplomacy.Germany: PersonalityRandom},
+	}
+
+	result, err := RunGame(ctx, cfg, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("RunGame failed: %v", err)
+	}
+	if got := result.Personalities["france"]; got != personalityPresets["aggressive"] {
+		t.Errorf("france: expected the aggressive preset, got %+v", got)
+	}
+	if got, ok := result.Personalities["germany"]; !ok || got == (Personality{}) {
+		t.Errorf("germany: expected a random personality, got %+v", got)
+	}
+	if _, ok := result.Personalities["italy"]; ok {
+		t.Error("italy: expected no personality")
+	}
+
+	cfg.Personalities = map[diplomacy.Power]string{diplomacy.France: "grumpy"}
+	if _, err := RunGame(ctx, cfg, nil, nil, nil); err == nil {
+		t.Error("expected an unknown personality to be rejected")
+	}
+}
+
+func TestRunGameStopYear(t *testing.T) {
+	cfg := ArenaConfig{
+		GameName:    "test-stop-year",
+		PowerConfig: ParsePowerConfig("*=easy"),
+		StopYear:    1903,
+		Seed:        7,
+		DryRun:      true,
+	}
+
+	result, err := RunGame(context.Background(), cfg, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("RunGame failed: %v", err)
+	}
+	if result.FinalYear != 1903 || result.FinalSeason != "spring" || result.Winner != "" {
+		t.Errorf("expected the game left in spring 1903, got %+v", result)
+	}
+	if len(result.TimelineYears) != 2 {
+		t.Errorf("expected 1901 and 1902 played, got %v", result.TimelineYears)
+	}
+}
+
 func TestRunGameAllDifficulties(t *testing.T) {
 	difficulties := []struct {
 		name    string
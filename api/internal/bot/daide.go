@@ -0,0 +1,666 @@
+package bot
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// DAIDE message types. Every message is a 4-byte header (type, padding,
+// big-endian body length) followed by its body.
+const (
+	daideIM = 0 // initial message: version and magic number
+	daideRM = 1 // representation message: province names for non-standard maps
+	daideDM = 2 // diplomacy message: a token string
+	daideFM = 3 // final message: orderly close
+	daideEM = 4 // error message: an error code, then close
+)
+
+const (
+	daideVersion    = 1
+	daideMagic      = 0xDA10
+	daideMaxMessage = 1 << 14
+)
+
+// DAIDE error codes sent in EM messages.
+const (
+	daideErrIMTimeout    = 0x01
+	daideErrIMNotFirst   = 0x02
+	daideErrIMEndian     = 0x03
+	daideErrIMMagic      = 0x04
+	daideErrVersion      = 0x05
+	daideErrIMRepeated   = 0x06
+	daideErrUnknownType  = 0x08
+	daideErrShortMessage = 0x09
+	daideErrRMFromClient = 0x0D
+	daideErrBadToken     = 0x0E
+)
+
+var errDaideMalformed = errors.New("malformed DAIDE message")
+
+// readDaideMessage reads one framed message.
+func readDaideMessage(r io.Reader) (byte, []byte, error) {
+	var hdr [4]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return 0, nil, err
+	}
+	n := binary.BigEndian.Uint16(hdr[2:])
+	if n > daideMaxMessage {
+		return 0, nil, fmt.Errorf("%w: %d byte body", errDaideMalformed, n)
+	}
+	body := make([]byte, n)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return hdr[0], body, nil
+}
+
+// writeDaideMessage writes one framed message.
+func writeDaideMessage(w io.Writer, typ byte, body []byte) error {
+	msg := make([]byte, 4, 4+len(body))
+	msg[0] = typ
+	binary.BigEndian.PutUint16(msg[2:], uint16(len(body)))
+	_, err := w.Write(append(msg, body...))
+	return err
+}
+
+func encodeDaideTokens(toks []daideToken) []byte {
+	body := make([]byte, 2*len(toks))
+	for i, t := range toks {
+		binary.BigEndian.PutUint16(body[2*i:], uint16(t))
+	}
+	return body
+}
+
+func decodeDaideTokens(body []byte) ([]daideToken, error) {
+	if len(body)%2 != 0 {
+		return nil, fmt.Errorf("%w: odd token body", errDaideMalformed)
+	}
+	toks := make([]daideToken, len(body)/2)
+	for i := range toks {
+		toks[i] = daideToken(binary.BigEndian.Uint16(body[2*i:]))
+	}
+	return toks, nil
+}
+
+// daideExpr is one element of a DAIDE message: a single token, or a
+// bracketed list of elements. raw holds the tokens it spans, brackets
+// included, so an element can be echoed back as sent.
+type daideExpr struct {
+	tok    daideToken
+	list   []daideExpr
+	isList bool
+	raw    []daideToken
+}
+
+// parseDaideExprs splits a token string into its top-level elements.
+func parseDaideExprs(toks []daideToken) ([]daideExpr, error) {
+	exprs, rest, err := parseDaideList(toks)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("%w: unbalanced brackets", errDaideMalformed)
+	}
+	return exprs, nil
+}
+
+func parseDaideList(toks []daideToken) ([]daideExpr, []daideToken, error) {
+	var exprs []daideExpr
+	for len(toks) > 0 {
+		switch toks[0] {
+		case tokKET:
+			return exprs, toks, nil
+		case tokBRA:
+			sub, rest, err := parseDaideList(toks[1:])
+			if err != nil {
+				return nil, nil, err
+			}
+			if len(rest) == 0 {
+				return nil, nil, fmt.Errorf("%w: unbalanced brackets", errDaideMalformed)
+			}
+			n := len(toks) - len(rest) + 1
+			exprs = append(exprs, daideExpr{list: sub, isList: true, raw: toks[:n]})
+			toks = rest[1:]
+		default:
+			exprs = append(exprs, daideExpr{tok: toks[0], raw: toks[:1]})
+			toks = toks[1:]
+		}
+	}
+	return exprs, nil, nil
+}
+
+// text returns the string held by a bracketed text element.
+func (e daideExpr) text() (string, bool) {
+	if !e.isList {
+		return "", false
+	}
+	var b strings.Builder
+	for _, c := range e.list {
+		if c.isList || c.tok.category() != daideCatText {
+			return "", false
+		}
+		b.WriteByte(byte(c.tok))
+	}
+	return b.String(), true
+}
+
+// daideOrder is an order parsed from a SUB message.
+type daideOrder struct {
+	raw         []daideToken // as sent, for echoing in THX
+	power       diplomacy.Power
+	kind        daideToken // HLD, MTO, SUP, CVY, CTO, RTO, DSB, BLD, REM or WVE
+	unit        diplomacy.Unit
+	target      string
+	targetCoast diplomacy.Coast
+	aux         diplomacy.Unit // supported or convoyed unit
+	auxTarget   string         // where the supported or convoyed unit is moving
+}
+
+// parseDaideOrder parses one bracketed order.
+func parseDaideOrder(e daideExpr) (daideOrder, error) {
+	o := daideOrder{raw: e.raw}
+	items := e.list
+	if !e.isList || len(items) < 2 || items[1].isList {
+		return o, errDaideMalformed
+	}
+	if items[1].tok == tokWVE {
+		p, ok := daidePowers[items[0].tok]
+		if len(items) != 2 || items[0].isList || !ok {
+			return o, errDaideMalformed
+		}
+		o.power, o.kind = p, tokWVE
+		return o, nil
+	}
+
+	unit, err := parseDaideUnit(items[0])
+	if err != nil {
+		return o, err
+	}
+	o.unit, o.power, o.kind = unit, unit.Power, items[1].tok
+	args := items[2:]
+	switch o.kind {
+	case tokHLD, tokDSB, tokBLD, tokREM:
+		if len(args) != 0 {
+			return o, errDaideMalformed
+		}
+	case tokMTO, tokRTO:
+		if len(args) != 1 {
+			return o, errDaideMalformed
+		}
+		o.target, o.targetCoast, err = parseDaideLocation(args[0])
+	case tokCTO:
+		// The VIA route is left to the adjudicator.
+		if len(args) != 1 && (len(args) != 3 || args[1].tok != tokVIA || !args[2].isList) {
+			return o, errDaideMalformed
+		}
+		o.target, err = parseDaideProvince(args[0])
+	case tokSUP:
+		if len(args) != 1 && (len(args) != 3 || args[1].tok != tokMTO) {
+			return o, errDaideMalformed
+		}
+		if o.aux, err = parseDaideUnit(args[0]); err == nil && len(args) == 3 {
+			o.auxTarget, err = parseDaideProvince(args[2])
+		}
+	case tokCVY:
+		if len(args) != 3 || args[1].tok != tokCTO {
+			return o, errDaideMalformed
+		}
+		if o.aux, err = parseDaideUnit(args[0]); err == nil {
+			o.auxTarget, err = parseDaideProvince(args[2])
+		}
+	default:
+		return o, errDaideMalformed
+	}
+	return o, err
+}
+
+// parseDaideUnit parses (power unit-type location).
+func parseDaideUnit(e daideExpr) (diplomacy.Unit, error) {
+	var u diplomacy.Unit
+	if !e.isList || len(e.list) != 3 || e.list[0].isList || e.list[1].isList {
+		return u, errDaideMalformed
+	}
+	p, ok := daidePowers[e.list[0].tok]
+	if !ok {
+		return u, errDaideMalformed
+	}
+	u.Power = p
+	switch e.list[1].tok {
+	case tokAMY:
+		u.Type = diplomacy.Army
+	case tokFLT:
+		u.Type = diplomacy.Fleet
+	default:
+		return u, errDaideMalformed
+	}
+	var err error
+	u.Province, u.Coast, err = parseDaideLocation(e.list[2])
+	return u, err
+}
+
+// parseDaideLocation parses a province or a bracketed (province coast).
+func parseDaideLocation(e daideExpr) (string, diplomacy.Coast, error) {
+	if !e.isList {
+		prov, err := parseDaideProvince(e)
+		return prov, diplomacy.NoCoast, err
+	}
+	if len(e.list) != 2 || e.list[1].isList {
+		return "", "", errDaideMalformed
+	}
+	prov, err := parseDaideProvince(e.list[0])
+	if err != nil {
+		return "", "", err
+	}
+	coast, ok := daideCoasts[e.list[1].tok]
+	if !ok {
+		return "", "", errDaideMalformed
+	}
+	return prov, coast, nil
+}
+
+func parseDaideProvince(e daideExpr) (string, error) {
+	if id, ok := daideProvinceID[e.tok]; ok && !e.isList {
+		return id, nil
+	}
+	return "", errDaideMalformed
+}
+
+// daidePhaseKinds lists the order kinds each phase accepts.
+var daidePhaseKinds = map[diplomacy.PhaseType][]daideToken{
+	diplomacy.PhaseMovement: {tokHLD, tokMTO, tokSUP, tokCVY, tokCTO},
+	diplomacy.PhaseRetreat:  {tokRTO, tokDSB},
+	diplomacy.PhaseBuild:    {tokBLD, tokREM, tokWVE},
+}
+
+// daideBuildNotes maps build validation failures to the note returned for them.
+var daideBuildNotes = map[string]daideToken{
+	"no builds available":       tokNMB,
+	"not a supply center":       tokNSC,
+	"not a home supply center":  tokHSC,
+	"supply center not current": tokYSC,
+	"province is occupied":      tokESC,
+	"must specify coast":        tokCST,
+	"no disbands required":      tokNMR,
+	"no unit at location":       tokNSU,
+	"unit belongs to another":   tokNYU,
+}
+
+// checkDaideOrder validates o as an order from power and returns it as an
+// OrderInput, with MBV if it is valid or the note explaining why not.
+// Limits on how many builds or removals a power may order are checked by
+// the caller, which sees the power's whole order set.
+func checkDaideOrder(o daideOrder, power diplomacy.Power, gs *diplomacy.GameState, m *diplomacy.DiplomacyMap) (OrderInput, daideToken) {
+	if o.power != power {
+		return OrderInput{}, tokNYU
+	}
+	if !slices.Contains(daidePhaseKinds[gs.Phase], o.kind) {
+		return OrderInput{}, tokNRS
+	}
+
+	switch gs.Phase {
+	case diplomacy.PhaseMovement:
+		u := gs.UnitAt(o.unit.Province)
+		if u == nil || u.Type != o.unit.Type {
+			return OrderInput{}, tokNSU
+		}
+		if u.Power != power {
+			return OrderInput{}, tokNYU
+		}
+		order := diplomacy.Order{UnitType: u.Type, Power: power, Location: u.Province, Coast: u.Coast}
+		switch o.kind {
+		case tokHLD:
+			order.Type = diplomacy.OrderHold
+		case tokMTO, tokCTO:
+			order.Type, order.Target, order.TargetCoast = diplomacy.OrderMove, o.target, o.targetCoast
+		case tokSUP, tokCVY:
+			if aux := gs.UnitAt(o.aux.Province); aux == nil || aux.Type != o.aux.Type {
+				return OrderInput{}, tokNSU
+			}
+			order.Type, order.AuxLoc, order.AuxTarget, order.AuxUnitType = diplomacy.OrderSupport, o.aux.Province, o.auxTarget, o.aux.Type
+			if o.kind == tokCVY {
+				if u.Type != diplomacy.Fleet {
+					return OrderInput{}, tokNSF
+				}
+				if m.Provinces[u.Province].Type != diplomacy.Sea {
+					return OrderInput{}, tokNAS
+				}
+				if o.aux.Type != diplomacy.Army {
+					return OrderInput{}, tokNSA
+				}
+				order.Type = diplomacy.OrderConvoy
+			}
+		}
+		if err := diplomacy.ValidateOrder(order, gs, m); err != nil {
+			return OrderInput{}, tokFAR
+		}
+		return orderToInput(order), tokMBV
+
+	case diplomacy.PhaseRetreat:
+		var d *diplomacy.DislodgedUnit
+		for i := range gs.Dislodged {
+			if gs.Dislodged[i].DislodgedFrom == o.unit.Province && gs.Dislodged[i].Unit.Type == o.unit.Type {
+				d = &gs.Dislodged[i]
+			}
+		}
+		if d == nil {
+			return OrderInput{}, tokNRN
+		}
+		if d.Unit.Power != power {
+			return OrderInput{}, tokNYU
+		}
+		order := diplomacy.RetreatOrder{UnitType: d.Unit.Type, Power: power, Location: d.DislodgedFrom, Coast: d.Unit.Coast, Type: diplomacy.RetreatDisband}
+		if o.kind == tokRTO {
+			order.Type, order.Target, order.TargetCoast = diplomacy.RetreatMove, o.target, o.targetCoast
+		}
+		if err := diplomacy.ValidateRetreatOrder(order, gs, m); err != nil {
+			return OrderInput{}, tokNVR
+		}
+		return retreatOrderToInput(order), tokMBV
+
+	default:
+		order := diplomacy.BuildOrder{Power: power, Type: diplomacy.WaiveBuild}
+		switch o.kind {
+		case tokWVE:
+			if gs.AdjustmentDelta(power) <= 0 {
+				return OrderInput{}, tokNMB
+			}
+			return buildOrderToInput(order), tokMBV
+		case tokBLD:
+			order.Type = diplomacy.BuildUnit
+		case tokREM:
+			order.Type = diplomacy.DisbandUnit
+		}
+		order.UnitType, order.Location, order.Coast = o.unit.Type, o.unit.Province, o.unit.Coast
+		if err := diplomacy.ValidateBuildOrder(order, gs, m); err != nil {
+			var verr *diplomacy.ValidationError
+			if errors.As(err, &verr) {
+				for prefix, note := range daideBuildNotes {
+					if strings.HasPrefix(verr.Message, prefix) {
+						return OrderInput{}, note
+					}
+				}
+			}
+			return OrderInput{}, tokHSC
+		}
+		return buildOrderToInput(order), tokMBV
+	}
+}
+
+// daideLocationTokens renders a province, with its coast if it has one.
+func daideLocationTokens(prov string, coast diplomacy.Coast) []daideToken {
+	tok := daideProvinceOf[prov]
+	if c, ok := daideCoastOf[coast]; ok {
+		return daideWrap(tok, c)
+	}
+	return []daideToken{tok}
+}
+
+// daideUnitTokens renders (power unit-type location).
+func daideUnitTokens(u diplomacy.Unit) []daideToken {
+	power, ok := daidePowerOf[u.Power]
+	if !ok {
+		power = tokUNO
+	}
+	kind := tokAMY
+	if u.Type == diplomacy.Fleet {
+		kind = tokFLT
+	}
+	return daideWrap(append([]daideToken{power, kind}, daideLocationTokens(u.Province, u.Coast)...)...)
+}
+
+// daideTurn renders (season year) for the phase gs is in. DAIDE knows only
+// the standard year, so the scenario's last season plays as FAL and any
+// earlier one as SPR.
+func daideTurn(gs *diplomacy.GameState) []daideToken {
+	season := tokSPR
+	switch {
+	case gs.Phase == diplomacy.PhaseBuild:
+		season = tokWIN
+	case gs.IsLastSeason() && gs.Phase == diplomacy.PhaseRetreat:
+		season = tokAUT
+	case gs.IsLastSeason():
+		season = tokFAL
+	case gs.Phase == diplomacy.PhaseRetreat:
+		season = tokSUM
+	}
+	return daideWrap(season, daideInt(gs.Year))
+}
+
+// daideRetreatOptions lists where a dislodged unit may retreat.
+func daideRetreatOptions(d diplomacy.DislodgedUnit, gs *diplomacy.GameState, m *diplomacy.DiplomacyMap) []daideToken {
+	var opts []daideToken
+	isFleet := d.Unit.Type == diplomacy.Fleet
+	for _, to := range m.ProvincesAdjacentTo(d.DislodgedFrom, d.Unit.Coast, isFleet) {
+		for _, c := range diplomacy.RetreatCoasts(d, to, m) {
+			order := diplomacy.RetreatOrder{UnitType: d.Unit.Type, Power: d.Unit.Power, Location: d.DislodgedFrom,
+				Coast: d.Unit.Coast, Type: diplomacy.RetreatMove, Target: to, TargetCoast: c}
+			if diplomacy.ValidateRetreatOrder(order, gs, m) == nil {
+				opts = append(opts, daideLocationTokens(to, c)...)
+			}
+		}
+	}
+	return opts
+}
+
+// daideDislodgedTokens renders a dislodged unit with its retreat options:
+// (power unit-type location MRT (options)).
+func daideDislodgedTokens(d diplomacy.DislodgedUnit, gs *diplomacy.GameState, m *diplomacy.DiplomacyMap) []daideToken {
+	u := d.Unit
+	u.Province = d.DislodgedFrom
+	toks := daideUnitTokens(u)
+	toks = append(toks[:len(toks)-1], tokMRT)
+	toks = append(toks, daideWrap(daideRetreatOptions(d, gs, m)...)...)
+	return append(toks, tokKET)
+}
+
+// daideNow builds NOW (turn) (unit)..., listing dislodged units with
+// their retreat options during a retreat phase.
+func daideNow(gs *diplomacy.GameState, m *diplomacy.DiplomacyMap) []daideToken {
+	msg := append([]daideToken{tokNOW}, daideTurn(gs)...)
+	for _, u := range gs.Units {
+		msg = append(msg, daideUnitTokens(u)...)
+	}
+	for _, d := range gs.Dislodged {
+		msg = append(msg, daideDislodgedTokens(d, gs, m)...)
+	}
+	return msg
+}
+
+// daideSupplyCentres lists centres by owner, one bracketed group per
+// power followed by unowned centres under UNO.
+func daideSupplyCentres(m *diplomacy.DiplomacyMap, owner func(string) diplomacy.Power) []daideToken {
+	groups := map[diplomacy.Power][]daideToken{}
+	for _, tok := range daideProvinces {
+		id := daideProvinceID[tok]
+		if p := m.Provinces[id]; p != nil && p.IsSupplyCenter {
+			groups[owner(id)] = append(groups[owner(id)], tok)
+		}
+	}
+	var toks []daideToken
+	for _, p := range diplomacy.AllPowers() {
+		toks = append(toks, daideWrap(append([]daideToken{daidePowerOf[p]}, groups[p]...)...)...)
+	}
+	if len(groups[diplomacy.Neutral]) > 0 {
+		toks = append(toks, daideWrap(append([]daideToken{tokUNO}, groups[diplomacy.Neutral]...)...)...)
+	}
+	return toks
+}
+
+// daideSCO builds SCO (power centre...)... for the current owners.
+func daideSCO(gs *diplomacy.GameState, m *diplomacy.DiplomacyMap) []daideToken {
+	return append([]daideToken{tokSCO}, daideSupplyCentres(m, func(id string) diplomacy.Power {
+		return gs.SupplyCenters[id]
+	})...)
+}
+
+// daideMDF builds the map definition: MDF (powers) ((home centres)
+// (other provinces)) (adjacencies).
+func daideMDF(m *diplomacy.DiplomacyMap) []daideToken {
+	msg := []daideToken{tokMDF, tokBRA}
+	for _, p := range diplomacy.AllPowers() {
+		msg = append(msg, daidePowerOf[p])
+	}
+	msg = append(msg, tokKET, tokBRA)
+	msg = append(msg, daideWrap(daideSupplyCentres(m, func(id string) diplomacy.Power {
+		return m.Provinces[id].HomePower
+	})...)...)
+	var others []daideToken
+	for _, tok := range daideProvinces {
+		if p := m.Provinces[daideProvinceID[tok]]; p != nil && !p.IsSupplyCenter {
+			others = append(others, tok)
+		}
+	}
+	msg = append(msg, daideWrap(others...)...)
+	msg = append(msg, tokKET, tokBRA)
+	for _, tok := range daideProvinces {
+		msg = append(msg, daideAdjacencies(tok, m)...)
+	}
+	return append(msg, tokKET)
+}
+
+// daideAdjacencies renders (province (AMY adj...) (FLT adj...)), with one
+// ((FLT coast) adj...) group per coast for split-coast provinces.
+func daideAdjacencies(tok daideToken, m *diplomacy.DiplomacyMap) []daideToken {
+	id := daideProvinceID[tok]
+	prov := m.Provinces[id]
+	if prov == nil {
+		return nil
+	}
+	toks := []daideToken{tokBRA, tok}
+	if prov.Type != diplomacy.Sea {
+		var adj []diplomacy.UnitPosition
+		for _, to := range m.ProvincesAdjacentTo(id, diplomacy.NoCoast, false) {
+			adj = append(adj, diplomacy.UnitPosition{Province: to})
+		}
+		toks = append(toks, daideWrap(append([]daideToken{tokAMY}, daideAdjacentTokens(adj)...)...)...)
+	}
+	if prov.Type == diplomacy.Land {
+		return append(toks, tokKET)
+	}
+	coasts := prov.Coasts
+	if len(coasts) == 0 {
+		coasts = []diplomacy.Coast{diplomacy.NoCoast}
+	}
+	for _, c := range coasts {
+		group := []daideToken{tokFLT}
+		if c != diplomacy.NoCoast {
+			group = daideWrap(tokFLT, daideCoastOf[c])
+		}
+		var adj []diplomacy.UnitPosition
+		for _, a := range m.Adjacencies[id] {
+			pos := diplomacy.UnitPosition{Province: a.To, Coast: a.ToCoast}
+			if a.FleetOK && (c == diplomacy.NoCoast || a.FromCoast == c) && !slices.Contains(adj, pos) {
+				adj = append(adj, pos)
+			}
+		}
+		toks = append(toks, daideWrap(append(group, daideAdjacentTokens(adj)...)...)...)
+	}
+	return append(toks, tokKET)
+}
+
+// daideAdjacentTokens renders adjacent locations in province number order.
+func daideAdjacentTokens(adj []diplomacy.UnitPosition) []daideToken {
+	slices.SortFunc(adj, func(a, b diplomacy.UnitPosition) int {
+		if d := int(daideProvinceOf[a.Province]) - int(daideProvinceOf[b.Province]); d != 0 {
+			return d
+		}
+		return int(daideCoastOf[a.Coast]) - int(daideCoastOf[b.Coast])
+	})
+	var toks []daideToken
+	for _, p := range adj {
+		toks = append(toks, daideLocationTokens(p.Province, p.Coast)...)
+	}
+	return toks
+}
+
+// daideResultOrder renders an adjudicated order as DAIDE tokens. gs is the
+// state the order was given in, used to find the powers of supported and
+// convoyed units.
+func daideResultOrder(r DaideResult, gs *diplomacy.GameState) []daideToken {
+	in := r.Order
+	power := diplomacy.Power(r.Power)
+	if in.OrderType == "waive" {
+		return daideWrap(daidePowerOf[power], tokWVE)
+	}
+	unitType := diplomacy.Army
+	if in.UnitType == "fleet" {
+		unitType = diplomacy.Fleet
+	}
+	toks := append([]daideToken{tokBRA}, daideUnitTokens(diplomacy.Unit{Type: unitType, Power: power,
+		Province: in.Location, Coast: diplomacy.Coast(in.Coast)})...)
+	switch in.OrderType {
+	case "move":
+		toks = append(toks, tokMTO)
+		toks = append(toks, daideLocationTokens(in.Target, diplomacy.Coast(in.TargetCoast))...)
+	case "support", "convoy":
+		aux := diplomacy.Unit{Province: in.AuxLoc}
+		if in.AuxUnitType == "fleet" {
+			aux.Type = diplomacy.Fleet
+		}
+		if u := gs.UnitAt(in.AuxLoc); u != nil {
+			aux.Power, aux.Coast = u.Power, u.Coast
+		}
+		if in.OrderType == "convoy" {
+			toks = append(toks, tokCVY)
+			toks = append(toks, daideUnitTokens(aux)...)
+			toks = append(toks, tokCTO, daideProvinceOf[in.AuxTarget])
+			break
+		}
+		toks = append(toks, tokSUP)
+		toks = append(toks, daideUnitTokens(aux)...)
+		if in.AuxTarget != "" {
+			toks = append(toks, tokMTO, daideProvinceOf[in.AuxTarget])
+		}
+	case "retreat_move":
+		toks = append(toks, tokRTO)
+		toks = append(toks, daideLocationTokens(in.Target, diplomacy.Coast(in.TargetCoast))...)
+	case "retreat_disband":
+		toks = append(toks, tokDSB)
+	case "build":
+		toks = append(toks, tokBLD)
+	case "disband":
+		toks = append(toks, tokREM)
+	default:
+		toks = append(toks, tokHLD)
+	}
+	return append(toks, tokKET)
+}
+
+// daideResultNote renders an order's outcome, e.g. (BNC) or (RET).
+func daideResultNote(r DaideResult, phase diplomacy.PhaseType) []daideToken {
+	switch r.Result {
+	case "succeeds":
+		return daideWrap(tokSUC)
+	case "dislodged":
+		return daideWrap(tokRET)
+	case "bounced":
+		return daideWrap(tokBNC)
+	case "cut":
+		return daideWrap(tokCUT)
+	case "void":
+		return daideWrap(tokNSO)
+	}
+	switch {
+	case phase == diplomacy.PhaseBuild:
+		return daideWrap(tokFLD)
+	case r.Order.OrderType == "convoy":
+		return daideWrap(tokDSR)
+	case r.Order.OrderType == "move", phase == diplomacy.PhaseRetreat:
+		return daideWrap(tokBNC)
+	default:
+		return daideWrap(tokFLD)
+	}
+}
+
+// daideORD builds ORD (turn) (order) (result) for one adjudicated order.
+func daideORD(r DaideResult, gs *diplomacy.GameState) []daideToken {
+	msg := append([]daideToken{tokORD}, daideTurn(gs)...)
+	msg = append(msg, daideResultOrder(r, gs)...)
+	return append(msg, daideResultNote(r, gs.Phase)...)
+}
@@ -0,0 +1,613 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// DaideSeat identifies the player a DAIDE client plays as.
+type DaideSeat struct {
+	GameID string
+	UserID string
+}
+
+// DaidePosition is the state of a seat's game.
+type DaidePosition struct {
+	Status   diplomacy.GameStatus
+	Power    diplomacy.Power // empty until powers are assigned
+	PhaseID  string
+	State    *diplomacy.GameState // nil until the game starts
+	Deadline time.Time            // zero if the phase has no deadline
+	Winner   diplomacy.Power      // empty for a draw
+}
+
+// DaideResult is one adjudicated order of a resolved phase.
+type DaideResult struct {
+	Power  string
+	Order  OrderInput
+	Result string // succeeds, fails, dislodged, bounced, cut or void
+}
+
+// DaideHost is the game server a DaideServer seats its clients in.
+type DaideHost interface {
+	// Join seats the owner of key, a client that introduced itself with
+	// NME, in a game.
+	Join(ctx context.Context, name, key, version string) (DaideSeat, error)
+	// Events returns a channel that receives a value whenever the seat's
+	// game has news, until ctx is done. A host that cannot tell returns
+	// nil, and the server polls Position instead.
+	Events(ctx context.Context, seat DaideSeat) <-chan struct{}
+	// Position reports the current phase of the seat's game.
+	Position(ctx context.Context, seat DaideSeat) (*DaidePosition, error)
+	// Results returns every power's adjudicated orders for a resolved phase.
+	Results(ctx context.Context, seat DaideSeat, phaseID string) ([]DaideResult, error)
+	// SubmitOrders replaces the seat's orders for the current phase.
+	SubmitOrders(ctx context.Context, seat DaideSeat, orders []OrderInput) error
+	// SetReady marks the seat ready for the phase to resolve, or not.
+	SetReady(ctx context.Context, seat DaideSeat, ready bool) error
+}
+
+// DaideServer exposes games over the DAIDE protocol (level 0, no press) so
+// community bots such as Albert and DumbBot can play against our bots and
+// players. Each connection plays one power in a game the host seats it in.
+// DAIDE has no login, so a client names itself NME ('name:key') with one
+// of its owner's API keys after the colon.
+type DaideServer struct {
+	host         DaideHost
+	pollInterval time.Duration
+	imTimeout    time.Duration
+}
+
+// NewDaideServer returns a server that seats clients through host.
+func NewDaideServer(host DaideHost) *DaideServer {
+	return &DaideServer{host: host, pollInterval: 2 * time.Second, imTimeout: 30 * time.Second}
+}
+
+// SetPollInterval sets how often sessions check their game for a new phase
+// when the host cannot tell them about its events.
+func (s *DaideServer) SetPollInterval(d time.Duration) {
+	s.pollInterval = d
+}
+
+// ListenAndServe accepts DAIDE clients on addr until ctx is done.
+func (s *DaideServer) ListenAndServe(ctx context.Context, addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("daide listen: %w", err)
+	}
+	log.Info().Str("addr", addr).Msg("DAIDE server listening")
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+	}()
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("daide accept: %w", err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.Serve(ctx, conn)
+		}()
+	}
+}
+
+// Serve runs one client session on conn, closing it when done.
+func (s *DaideServer) Serve(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+	sess := &daideSession{server: s, conn: conn, m: diplomacy.StandardMap()}
+	if err := sess.run(ctx); err != nil && !errors.Is(err, net.ErrClosed) {
+		log.Debug().Err(err).Str("remote", conn.RemoteAddr().String()).Msg("DAIDE session ended")
+	}
+}
+
+// daideSession is one connected client. Only run's goroutine writes to
+// conn; a reader goroutine hands it incoming messages.
+type daideSession struct {
+	server *DaideServer
+	conn   net.Conn
+	m      *diplomacy.DiplomacyMap
+
+	seat    *DaideSeat
+	events  <-chan struct{} // news of the seat's game; nil to poll
+	pos     *DaidePosition
+	mapSent bool // MAP sent; waiting for the client to accept it
+	started bool // client accepted the map and was sent HLO
+	phaseID string
+	sco     string
+	orders  map[string]OrderInput // this phase's accepted orders, by unit or build slot
+}
+
+// daideMessage is a diplomacy message from the client, or the error that
+// ended the connection with the EM code to answer it with, if any.
+type daideMessage struct {
+	toks []daideToken
+	err  error
+	code int
+}
+
+var (
+	errDaideClosed   = errors.New("client closed the connection")
+	errDaideGameOver = errors.New("game over")
+)
+
+func (d *daideSession) run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	d.conn.SetReadDeadline(time.Now().Add(d.server.imTimeout))
+	typ, body, err := readDaideMessage(d.conn)
+	if err != nil {
+		if isTimeout(err) {
+			d.sendError(daideErrIMTimeout)
+		}
+		return err
+	}
+	if code := checkDaideIM(typ, body); code != 0 {
+		d.sendError(code)
+		return fmt.Errorf("bad initial message: error %#x", code)
+	}
+	d.conn.SetReadDeadline(time.Time{})
+	if err := writeDaideMessage(d.conn, daideRM, nil); err != nil {
+		return err
+	}
+
+	msgs := make(chan daideMessage)
+	done := make(chan struct{})
+	defer close(done)
+	go d.read(msgs, done)
+
+	ticker := time.NewTicker(d.server.pollInterval)
+	defer ticker.Stop()
+	for {
+		var tick <-chan time.Time
+		if d.seat != nil && d.events == nil {
+			tick = ticker.C
+		}
+		select {
+		case <-ctx.Done():
+			d.send(tokOFF)
+			return ctx.Err()
+		case msg := <-msgs:
+			if msg.err != nil {
+				if msg.code != 0 {
+					d.sendError(msg.code)
+				}
+				return msg.err
+			}
+			if err := d.handle(ctx, msg.toks); err != nil {
+				return err
+			}
+		case <-d.events:
+			if err := d.poll(ctx); err != nil {
+				return err
+			}
+		case <-tick:
+			if err := d.poll(ctx); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func isTimeout(err error) bool {
+	var ne net.Error
+	return errors.As(err, &ne) && ne.Timeout()
+}
+
+// checkDaideIM returns the error code for a bad initial message, or 0.
+func checkDaideIM(typ byte, body []byte) int {
+	switch {
+	case typ == daideRM:
+		return daideErrRMFromClient
+	case typ != daideIM:
+		return daideErrIMNotFirst
+	case len(body) < 4:
+		return daideErrShortMessage
+	}
+	version, magic := int(body[0])<<8|int(body[1]), int(body[2])<<8|int(body[3])
+	switch {
+	case magic == 0x10DA:
+		return daideErrIMEndian
+	case magic != daideMagic:
+		return daideErrIMMagic
+	case version != daideVersion:
+		return daideErrVersion
+	}
+	return 0
+}
+
+// read feeds diplomacy messages to run until the connection ends.
+func (d *daideSession) read(msgs chan<- daideMessage, done <-chan struct{}) {
+	deliver := func(msg daideMessage) bool {
+		select {
+		case msgs <- msg:
+			return true
+		case <-done:
+			return false
+		}
+	}
+	for {
+		typ, body, err := readDaideMessage(d.conn)
+		if err != nil {
+			deliver(daideMessage{err: err})
+			return
+		}
+		switch typ {
+		case daideDM:
+			toks, err := decodeDaideTokens(body)
+			if err != nil {
+				deliver(daideMessage{err: err, code: daideErrBadToken})
+				return
+			}
+			if !deliver(daideMessage{toks: toks}) {
+				return
+			}
+		case daideFM:
+			deliver(daideMessage{err: errDaideClosed})
+			return
+		case daideEM:
+			deliver(daideMessage{err: fmt.Errorf("client sent error %x", body)})
+			return
+		case daideIM:
+			deliver(daideMessage{err: errors.New("repeated initial message"), code: daideErrIMRepeated})
+			return
+		case daideRM:
+			deliver(daideMessage{err: errors.New("representation message from client"), code: daideErrRMFromClient})
+			return
+		default:
+			deliver(daideMessage{err: fmt.Errorf("unknown message type %d", typ), code: daideErrUnknownType})
+			return
+		}
+	}
+}
+
+func (d *daideSession) send(toks ...daideToken) error {
+	return writeDaideMessage(d.conn, daideDM, encodeDaideTokens(toks))
+}
+
+func (d *daideSession) sendError(code int) {
+	writeDaideMessage(d.conn, daideEM, []byte{0, byte(code)})
+}
+
+// reply answers msg with a command wrapping it, such as YES (msg).
+func (d *daideSession) reply(cmd daideToken, msg []daideToken) error {
+	return d.send(append([]daideToken{cmd}, daideWrap(msg...)...)...)
+}
+
+// huh reports a message the server could not understand.
+func (d *daideSession) huh(msg []daideToken) error {
+	return d.reply(tokHUH, append([]daideToken{tokERR}, msg...))
+}
+
+// handle answers one message from the client.
+func (d *daideSession) handle(ctx context.Context, toks []daideToken) error {
+	exprs, err := parseDaideExprs(toks)
+	if err != nil || len(exprs) == 0 || exprs[0].isList {
+		return d.huh(toks)
+	}
+	args := exprs[1:]
+	switch exprs[0].tok {
+	case tokNME:
+		return d.handleNME(ctx, toks, args)
+	case tokMAP:
+		if !d.mapSent || len(args) != 0 {
+			return d.reply(tokREJ, toks)
+		}
+		return d.send(daideMapName()...)
+	case tokMDF:
+		if !d.mapSent {
+			return d.reply(tokREJ, toks)
+		}
+		return d.send(daideMDF(d.m)...)
+	case tokYES:
+		if len(args) == 1 && args[0].isList && len(args[0].list) > 0 && args[0].list[0].tok == tokMAP && d.mapSent && !d.started {
+			return d.start(ctx)
+		}
+		return nil
+	case tokREJ:
+		if len(args) == 1 && args[0].isList && len(args[0].list) > 0 && args[0].list[0].tok == tokMAP {
+			d.send(tokOFF)
+			return errors.New("client rejected the map")
+		}
+		return nil
+	case tokHLO, tokSCO, tokNOW:
+		if !d.started || len(args) != 0 {
+			return d.reply(tokREJ, toks)
+		}
+		switch exprs[0].tok {
+		case tokHLO:
+			return d.send(d.hello()...)
+		case tokSCO:
+			return d.send(daideSCO(d.pos.State, d.m)...)
+		default:
+			return d.send(daideNow(d.pos.State, d.m)...)
+		}
+	case tokSUB:
+		return d.handleSUB(ctx, toks, args)
+	case tokMIS:
+		if !d.started {
+			return d.reply(tokREJ, toks)
+		}
+		return d.send(d.missing()...)
+	case tokGOF:
+		return d.setReady(ctx, toks, true)
+	case tokNOT:
+		if len(args) == 1 && args[0].isList && len(args[0].list) == 1 && args[0].list[0].tok == tokGOF {
+			return d.setReady(ctx, toks, false)
+		}
+		return d.reply(tokREJ, toks)
+	case tokTME:
+		if !d.started || len(args) != 0 || d.pos.Deadline.IsZero() {
+			return d.reply(tokREJ, toks)
+		}
+		left := max(0, int(time.Until(d.pos.Deadline).Seconds()))
+		return d.send(tokTME, tokBRA, daideInt(min(left, 0x1FFF)), tokKET)
+	case tokOBS, tokIAM, tokHST, tokDRW, tokSND, tokADM, tokCCD, tokOUT, tokPRN, tokFRM, tokSVE, tokLOD:
+		// Observers, reconnection, history, draws and press are not
+		// supported at level 0 here.
+		return d.reply(tokREJ, toks)
+	}
+	return d.huh(toks)
+}
+
+func (d *daideSession) handleNME(ctx context.Context, toks []daideToken, args []daideExpr) error {
+	if len(args) != 2 {
+		return d.huh(toks)
+	}
+	text, ok1 := args[0].text()
+	version, ok2 := args[1].text()
+	name, key, ok3 := strings.Cut(text, ":")
+	if !ok1 || !ok2 || !ok3 || name == "" || key == "" || d.seat != nil {
+		return d.reply(tokREJ, toks)
+	}
+	seat, err := d.server.host.Join(ctx, name, key, version)
+	if err != nil {
+		log.Info().Err(err).Str("name", name).Msg("DAIDE client could not join a game")
+		return d.reply(tokREJ, toks)
+	}
+	d.seat = &seat
+	d.events = d.server.host.Events(ctx, seat)
+	log.Info().Str("name", name).Str("version", version).Str("gameId", seat.GameID).Msg("DAIDE client joined")
+	if err := d.reply(tokYES, toks); err != nil {
+		return err
+	}
+	return d.poll(ctx)
+}
+
+// daideMapName builds MAP ('STANDARD').
+func daideMapName() []daideToken {
+	return append([]daideToken{tokMAP}, daideWrap(daideText("STANDARD")...)...)
+}
+
+// start greets a client that accepted the map with its power and the
+// current position.
+func (d *daideSession) start(ctx context.Context) error {
+	d.started = true
+	if err := d.send(d.hello()...); err != nil {
+		return err
+	}
+	return d.sendPhase(ctx)
+}
+
+// hello builds HLO (power) (passcode) ((LVL 0)).
+func (d *daideSession) hello() []daideToken {
+	h := fnv.New32a()
+	h.Write([]byte(d.seat.GameID + "/" + d.seat.UserID))
+	passcode := int(h.Sum32() % 0x2000)
+	msg := []daideToken{tokHLO}
+	msg = append(msg, daideWrap(daidePowerOf[d.pos.Power])...)
+	msg = append(msg, daideWrap(daideInt(passcode))...)
+	return append(msg, daideWrap(daideWrap(tokLVL, daideInt(0))...)...)
+}
+
+// poll refreshes the seat's position, offering the map once the game
+// starts, reporting each new phase and signing off when the game ends.
+func (d *daideSession) poll(ctx context.Context) error {
+	if d.seat == nil {
+		return nil
+	}
+	pos, err := d.server.host.Position(ctx, *d.seat)
+	if err != nil {
+		log.Warn().Err(err).Str("gameId", d.seat.GameID).Msg("DAIDE position check failed")
+		return nil
+	}
+	if pos.Status == diplomacy.StatusFinished {
+		if d.started {
+			if err := d.sendResults(ctx, d.pos); err != nil {
+				return err
+			}
+		}
+		if pos.Winner != "" {
+			d.send(tokSLO, tokBRA, daidePowerOf[pos.Winner], tokKET)
+		} else {
+			d.send(tokDRW)
+		}
+		d.send(tokOFF)
+		return errDaideGameOver
+	}
+	if pos.State == nil {
+		return nil
+	}
+	prev := d.pos
+	d.pos = pos
+	if !d.mapSent {
+		d.mapSent = true
+		return d.send(daideMapName()...)
+	}
+	if !d.started || pos.PhaseID == d.phaseID {
+		return nil
+	}
+	if err := d.sendResults(ctx, prev); err != nil {
+		return err
+	}
+	return d.sendPhase(ctx)
+}
+
+// sendResults reports the orders of the phase prev was in, once resolved.
+func (d *daideSession) sendResults(ctx context.Context, prev *DaidePosition) error {
+	if d.phaseID == "" || prev == nil || prev.State == nil {
+		return nil
+	}
+	results, err := d.server.host.Results(ctx, *d.seat, d.phaseID)
+	if err != nil {
+		log.Warn().Err(err).Str("phaseId", d.phaseID).Msg("DAIDE results lookup failed")
+		return nil
+	}
+	for _, r := range results {
+		if err := d.send(daideORD(r, prev.State)...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sendPhase sends SCO when centres changed hands, then NOW, and starts
+// collecting orders for the new phase.
+func (d *daideSession) sendPhase(ctx context.Context) error {
+	d.phaseID = d.pos.PhaseID
+	d.orders = map[string]OrderInput{}
+	sco := daideSCO(d.pos.State, d.m)
+	if s := formatDaide(sco); s != d.sco {
+		d.sco = s
+		if err := d.send(sco...); err != nil {
+			return err
+		}
+	}
+	return d.send(daideNow(d.pos.State, d.m)...)
+}
+
+// handleSUB checks each submitted order, answering THX (order) (note) for
+// each, and passes the power's accepted orders on to the host.
+func (d *daideSession) handleSUB(ctx context.Context, toks []daideToken, args []daideExpr) error {
+	if !d.started || len(args) == 0 {
+		return d.reply(tokREJ, toks)
+	}
+	gs := d.pos.State
+	delta := gs.AdjustmentDelta(d.pos.Power)
+	var replies [][]daideToken
+	changed := false
+	for _, arg := range args {
+		o, err := parseDaideOrder(arg)
+		if err != nil {
+			return d.huh(toks)
+		}
+		in, note := checkDaideOrder(o, d.pos.Power, gs, d.m)
+		key := in.Location
+		if note == tokMBV && gs.Phase == diplomacy.PhaseBuild {
+			key, note = d.buildSlot(in, delta)
+		}
+		if note == tokMBV {
+			d.orders[key] = in
+			changed = true
+		}
+		thx := append([]daideToken{tokTHX}, o.raw...)
+		replies = append(replies, append(thx, tokBRA, note, tokKET))
+	}
+	if changed {
+		orders := make([]OrderInput, 0, len(d.orders))
+		for _, in := range d.orders {
+			orders = append(orders, in)
+		}
+		if err := d.server.host.SubmitOrders(ctx, *d.seat, orders); err != nil {
+			log.Warn().Err(err).Str("gameId", d.seat.GameID).Msg("DAIDE order submission failed")
+		}
+	}
+	for _, r := range replies {
+		if err := d.send(r...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildSlot keys a valid adjustment order, refusing it with NMB or NMR if
+// the power has already ordered all the builds or removals it may.
+func (d *daideSession) buildSlot(in OrderInput, delta int) (string, daideToken) {
+	if in.OrderType == "disband" {
+		if _, ok := d.orders[in.Location]; !ok && d.countOrders("disband") >= -delta {
+			return "", tokNMR
+		}
+		return in.Location, tokMBV
+	}
+	if _, ok := d.orders[in.Location]; !ok && d.countOrders("build", "waive") >= delta {
+		return "", tokNMB
+	}
+	if in.OrderType == "waive" {
+		return fmt.Sprintf("waive-%d", d.countOrders("waive")), tokMBV
+	}
+	return in.Location, tokMBV
+}
+
+func (d *daideSession) countOrders(types ...string) int {
+	n := 0
+	for _, in := range d.orders {
+		for _, t := range types {
+			if in.OrderType == t {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// missing builds MIS listing the power's units still without orders, or
+// MIS (n) in adjustment phases with n the builds (negative) or removals
+// (positive) still to order.
+func (d *daideSession) missing() []daideToken {
+	gs, power := d.pos.State, d.pos.Power
+	msg := []daideToken{tokMIS}
+	switch gs.Phase {
+	case diplomacy.PhaseMovement:
+		for _, u := range gs.UnitsOf(power) {
+			if _, ok := d.orders[u.Province]; !ok {
+				msg = append(msg, daideUnitTokens(u)...)
+			}
+		}
+	case diplomacy.PhaseRetreat:
+		for _, du := range gs.Dislodged {
+			if _, ok := d.orders[du.DislodgedFrom]; !ok && du.Unit.Power == power {
+				msg = append(msg, daideDislodgedTokens(du, gs, d.m)...)
+			}
+		}
+	case diplomacy.PhaseBuild:
+		delta := gs.AdjustmentDelta(power)
+		if delta > 0 {
+			delta -= d.countOrders("build", "waive")
+			if delta > 0 {
+				msg = append(msg, tokBRA, daideInt(-delta), tokKET)
+			}
+		} else if delta < 0 {
+			if n := -delta - d.countOrders("disband"); n > 0 {
+				msg = append(msg, tokBRA, daideInt(n), tokKET)
+			}
+		}
+	}
+	return msg
+}
+
+func (d *daideSession) setReady(ctx context.Context, toks []daideToken, ready bool) error {
+	if !d.started {
+		return d.reply(tokREJ, toks)
+	}
+	if err := d.server.host.SetReady(ctx, *d.seat, ready); err != nil {
+		log.Warn().Err(err).Str("gameId", d.seat.GameID).Msg("DAIDE ready update failed")
+		return d.reply(tokREJ, toks)
+	}
+	return d.reply(tokYES, toks)
+}
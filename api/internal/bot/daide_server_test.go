@@ -0,0 +1,217 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// fakeDaideHost seats every client with the key pb_test as France in one
+// game whose position the test moves along, telling the session of each
+// move.
+type fakeDaideHost struct {
+	mu        sync.Mutex
+	pos       DaidePosition
+	results   map[string][]DaideResult
+	submitted []OrderInput
+	ready     bool
+	events    chan struct{}
+}
+
+func (h *fakeDaideHost) Join(_ context.Context, name, key, _ string) (DaideSeat, error) {
+	if key != "pb_test" {
+		return DaideSeat{}, errors.New("bad key")
+	}
+	return DaideSeat{GameID: "game-1", UserID: name}, nil
+}
+
+func (h *fakeDaideHost) Events(context.Context, DaideSeat) <-chan struct{} {
+	return h.events
+}
+
+func (h *fakeDaideHost) Position(context.Context, DaideSeat) (*DaidePosition, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	pos := h.pos
+	return &pos, nil
+}
+
+func (h *fakeDaideHost) Results(_ context.Context, _ DaideSeat, phaseID string) ([]DaideResult, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.results[phaseID], nil
+}
+
+func (h *fakeDaideHost) SubmitOrders(_ context.Context, _ DaideSeat, orders []OrderInput) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.submitted = orders
+	return nil
+}
+
+func (h *fakeDaideHost) SetReady(_ context.Context, _ DaideSeat, ready bool) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.ready = ready
+	return nil
+}
+
+func (h *fakeDaideHost) set(f func(h *fakeDaideHost)) {
+	h.mu.Lock()
+	f(h)
+	h.mu.Unlock()
+	select {
+	case h.events <- struct{}{}:
+	default:
+	}
+}
+
+// daideClient drives the client end of a session in text notation.
+type daideClient struct {
+	t    *testing.T
+	conn net.Conn
+}
+
+func (c *daideClient) send(s string) {
+	c.t.Helper()
+	toks, err := parseDaide(s)
+	if err != nil {
+		c.t.Fatalf("parse %q: %v", s, err)
+	}
+	if err := writeDaideMessage(c.conn, daideDM, encodeDaideTokens(toks)); err != nil {
+		c.t.Fatalf("send %q: %v", s, err)
+	}
+}
+
+func (c *daideClient) recv() string {
+	c.t.Helper()
+	c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	typ, body, err := readDaideMessage(c.conn)
+	if err != nil {
+		c.t.Fatalf("recv: %v", err)
+	}
+	if typ != daideDM {
+		c.t.Fatalf("got message type %d, want DM", typ)
+	}
+	toks, _ := decodeDaideTokens(body)
+	return formatDaide(toks)
+}
+
+func (c *daideClient) expect(prefix string) string {
+	c.t.Helper()
+	got := c.recv()
+	if !strings.HasPrefix(got, prefix) {
+		c.t.Fatalf("got %q, want a message starting %q", got, prefix)
+	}
+	return got
+}
+
+func TestDaideSession(t *testing.T) {
+	gs := diplomacy.NewInitialState()
+	host := &fakeDaideHost{pos: DaidePosition{
+		Status: diplomacy.StatusActive, Power: diplomacy.France, PhaseID: "phase-1", State: gs,
+		Deadline: time.Now().Add(time.Hour),
+	}, events: make(chan struct{}, 1)}
+	srv := NewDaideServer(host)
+	srv.SetPollInterval(time.Hour) // only the host's events move the session along
+	serverConn, clientConn := net.Pipe()
+	done := make(chan struct{})
+	go func() {
+		srv.Serve(context.Background(), serverConn)
+		close(done)
+	}()
+	c := &daideClient{t: t, conn: clientConn}
+
+	if err := writeDaideMessage(clientConn, daideIM, []byte{0, daideVersion, 0xDA, 0x10}); err != nil {
+		t.Fatal(err)
+	}
+	if typ, body, err := readDaideMessage(clientConn); err != nil || typ != daideRM || len(body) != 0 {
+		t.Fatalf("expected an empty RM, got type %d body %x err %v", typ, body, err)
+	}
+
+	c.send("HLO")
+	c.expect("REJ (HLO)")
+	c.send("NME ('DumbBot') ('v1')")
+	c.expect("REJ (NME ('DumbBot') ('v1'))")
+	c.send("NME ('DumbBot:pb_wrong') ('v1')")
+	c.expect("REJ (NME ('DumbBot:pb_wrong') ('v1'))")
+	c.send("NME ('DumbBot:pb_test') ('v1')")
+	c.expect("YES (NME ('DumbBot:pb_test') ('v1'))")
+	c.expect("MAP ('STANDARD')")
+	c.send("MDF")
+	c.expect("MDF (AUS ENG FRA GER ITA RUS TUR)")
+	c.send("YES (MAP ('STANDARD'))")
+	c.expect("HLO (FRA) (")
+	c.expect("SCO (AUS BUD VIE TRI)")
+	c.expect("NOW (SPR 1901) ")
+
+	c.send("SUB ((FRA AMY PAR) MTO BUR) ((FRA FLT BRE) MTO MUN)")
+	c.expect("THX ((FRA AMY PAR) MTO BUR) (MBV)")
+	c.expect("THX ((FRA FLT BRE) MTO MUN) (FAR)")
+	host.set(func(h *fakeDaideHost) {
+		want := OrderInput{UnitType: "army", Location: "par", OrderType: "move", Target: "bur", AuxUnitType: "army"}
+		if len(h.submitted) != 1 || h.submitted[0] != want {
+			t.Errorf("submitted %+v, want just %+v", h.submitted, want)
+		}
+	})
+	c.send("MIS")
+	if got := c.expect("MIS "); !strings.Contains(got, "(FRA FLT BRE)") || strings.Contains(got, "PAR") {
+		t.Errorf("MIS = %q, want Brest but not Paris", got)
+	}
+	c.send("TME")
+	c.expect("TME (35")
+	c.send("GOF")
+	c.expect("YES (GOF)")
+	host.set(func(h *fakeDaideHost) {
+		if !h.ready {
+			t.Error("GOF did not mark the seat ready")
+		}
+	})
+	c.send("MTO PAR")
+	c.expect("HUH (ERR MTO PAR)")
+	c.send("DRW")
+	c.expect("REJ (DRW)")
+
+	next := gs.Clone()
+	next.Season = diplomacy.Fall
+	next.Units[slices.IndexFunc(next.Units, func(u diplomacy.Unit) bool { return u.Province == "par" })].Province = "bur"
+	host.set(func(h *fakeDaideHost) {
+		h.pos.PhaseID, h.pos.State = "phase-2", next
+		h.results = map[string][]DaideResult{"phase-1": {{
+			Power: "france", Order: OrderInput{UnitType: "army", Location: "par", OrderType: "move", Target: "bur"}, Result: "succeeds",
+		}}}
+	})
+	c.expect("ORD (SPR 1901) ((FRA AMY PAR) MTO BUR) (SUC)")
+	if got := c.expect("NOW (FAL 1901) "); !strings.Contains(got, "(FRA AMY BUR)") {
+		t.Errorf("NOW = %q, want the army in Burgundy", got)
+	}
+
+	host.set(func(h *fakeDaideHost) {
+		h.pos.Status, h.pos.Winner = diplomacy.StatusFinished, diplomacy.France
+		h.results = nil
+	})
+	c.expect("SLO (FRA)")
+	c.expect("OFF")
+	<-done
+}
+
+func TestDaideSessionRejectsBadIM(t *testing.T) {
+	srv := NewDaideServer(&fakeDaideHost{})
+	serverConn, clientConn := net.Pipe()
+	go srv.Serve(context.Background(), serverConn)
+
+	if err := writeDaideMessage(clientConn, daideIM, []byte{0, daideVersion, 0xBE, 0xEF}); err != nil {
+		t.Fatal(err)
+	}
+	typ, body, err := readDaideMessage(clientConn)
+	if err != nil || typ != daideEM || len(body) != 2 || body[1] != daideErrIMMagic {
+		t.Fatalf("expected EM with the bad magic code, got type %d body %x err %v", typ, body, err)
+	}
+}
@@ -0,0 +1,189 @@
+package bot
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+func TestDaideProvinces(t *testing.T) {
+	m := diplomacy.StandardMap()
+	if len(daideProvinces) != len(m.Provinces) {
+		t.Fatalf("%d DAIDE provinces, want %d", len(daideProvinces), len(m.Provinces))
+	}
+	for id := range m.Provinces {
+		if _, ok := daideProvinceOf[id]; !ok {
+			t.Errorf("province %s has no DAIDE token", id)
+		}
+	}
+	for tok, want := range map[daideToken]string{0x5000: "boh", 0x521D: "nrg", 0x5214: "eng", 0x5748: "bul", 0x574A: "stp"} {
+		if got := daideProvinceID[tok]; got != want {
+			t.Errorf("token %#x is %q, want %q", uint16(tok), got, want)
+		}
+	}
+}
+
+func TestDaideTextRoundTrip(t *testing.T) {
+	for _, s := range []string{
+		"NME ('Albert') ('v6.0')",
+		"MIS (-2)",
+		"SUB ((ENG FLT (STP NCS)) MTO NWG) ((ENG AMY LVP) HLD)",
+		"HUH (ERR SUB ((FRA AMY PAR) MTO))",
+	} {
+		toks, err := parseDaide(s)
+		if err != nil {
+			t.Fatalf("parse %q: %v", s, err)
+		}
+		if got := formatDaide(toks); got != s {
+			t.Errorf("round trip of %q gave %q", s, got)
+		}
+		decoded, err := decodeDaideTokens(encodeDaideTokens(toks))
+		if err != nil || formatDaide(decoded) != s {
+			t.Errorf("wire round trip of %q gave %q, %v", s, formatDaide(decoded), err)
+		}
+	}
+	if toks, _ := parseDaide("MIS (-2)"); toks[2].intValue() != -2 {
+		t.Errorf("MIS count = %d, want -2", toks[2].intValue())
+	}
+}
+
+func TestDaideFraming(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeDaideMessage(&buf, daideDM, encodeDaideTokens([]daideToken{tokHLO})); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.Bytes(); !bytes.Equal(got, []byte{daideDM, 0, 0, 2, 0x48, 0x04}) {
+		t.Fatalf("framed HLO = % x", got)
+	}
+	typ, body, err := readDaideMessage(&buf)
+	if err != nil || typ != daideDM || !bytes.Equal(body, []byte{0x48, 0x04}) {
+		t.Errorf("read back type %d body % x, err %v", typ, body, err)
+	}
+	if code := checkDaideIM(daideIM, []byte{0, 1, 0x10, 0xDA}); code != daideErrIMEndian {
+		t.Errorf("byte-swapped IM gave code %d, want %d", code, daideErrIMEndian)
+	}
+}
+
+// checkDaideText parses one order in text form and checks it for power.
+func checkDaideText(t *testing.T, s string, power diplomacy.Power, gs *diplomacy.GameState) (OrderInput, string) {
+	t.Helper()
+	toks, err := parseDaide(s)
+	if err != nil {
+		t.Fatalf("parse %q: %v", s, err)
+	}
+	exprs, err := parseDaideExprs(toks)
+	if err != nil || len(exprs) != 1 {
+		t.Fatalf("split %q: %v", s, err)
+	}
+	o, err := parseDaideOrder(exprs[0])
+	if err != nil {
+		return OrderInput{}, "HUH"
+	}
+	in, note := checkDaideOrder(o, power, gs, diplomacy.StandardMap())
+	return in, daideNames[note]
+}
+
+func TestCheckDaideOrder(t *testing.T) {
+	gs := diplomacy.NewInitialState()
+	tests := []struct {
+		order string
+		power diplomacy.Power
+		note  string
+		want  OrderInput
+	}{
+		{"((FRA AMY PAR) MTO BUR)", diplomacy.France, "MBV", OrderInput{UnitType: "army", Location: "par", OrderType: "move", Target: "bur", AuxUnitType: "army"}},
+		{"((FRA FLT BRE) SUP (FRA AMY PAR) MTO PIC)", diplomacy.France, "MBV", OrderInput{UnitType: "fleet", Location: "bre", OrderType: "support", AuxLoc: "par", AuxTarget: "pic", AuxUnitType: "army"}},
+		{"((RUS FLT (STP SCS)) MTO GOB)", diplomacy.Russia, "MBV", OrderInput{UnitType: "fleet", Location: "stp", Coast: "sc", OrderType: "move", Target: "bot", AuxUnitType: "army"}},
+		{"((ENG FLT LON) MTO ECH)", diplomacy.England, "MBV", OrderInput{UnitType: "fleet", Location: "lon", OrderType: "move", Target: "eng", AuxUnitType: "army"}},
+		{"((FRA FLT BRE) MTO MUN)", diplomacy.France, "FAR", OrderInput{}},
+		{"((FRA AMY BUR) HLD)", diplomacy.France, "NSU", OrderInput{}},
+		{"((GER AMY BER) HLD)", diplomacy.France, "NYU", OrderInput{}},
+		{"((FRA AMY PAR) RTO BUR)", diplomacy.France, "NRS", OrderInput{}},
+		{"((FRA AMY PAR) MTO)", diplomacy.France, "HUH", OrderInput{}},
+	}
+	for _, tt := range tests {
+		in, note := checkDaideText(t, tt.order, tt.power, gs)
+		if note != tt.note || in != tt.want {
+			t.Errorf("%s: got %s %+v, want %s %+v", tt.order, note, in, tt.note, tt.want)
+		}
+	}
+}
+
+func TestCheckDaideBuildOrder(t *testing.T) {
+	gs := diplomacy.NewInitialState()
+	gs.Phase, gs.Season = diplomacy.PhaseBuild, diplomacy.Fall
+	gs.SupplyCenters["spa"] = diplomacy.France
+	gs.Units = slices.DeleteFunc(gs.Units, func(u diplomacy.Unit) bool { return u.Province == "par" })
+
+	tests := []struct {
+		order string
+		note  string
+	}{
+		{"((FRA AMY PAR) BLD)", "MBV"},
+		{"((FRA FLT BRE) BLD)", "ESC"},
+		{"((FRA AMY SPA) BLD)", "HSC"},
+		{"((FRA AMY PIC) BLD)", "NSC"},
+		{"(FRA WVE)", "MBV"},
+		{"((FRA AMY MAR) REM)", "NMR"},
+	}
+	for _, tt := range tests {
+		if _, note := checkDaideText(t, tt.order, diplomacy.France, gs); note != tt.note {
+			t.Errorf("%s: got %s, want %s", tt.order, note, tt.note)
+		}
+	}
+}
+
+func TestDaideMessages(t *testing.T) {
+	m := diplomacy.StandardMap()
+	gs := diplomacy.NewInitialState()
+
+	mdf := formatDaide(daideMDF(m))
+	for _, want := range []string{
+		"MDF (AUS ENG FRA GER ITA RUS TUR) (((AUS BUD VIE TRI) (ENG EDI LON LVP)",
+		"(UNO SER BEL DEN GRE HOL NWY POR RUM SWE TUN BUL SPA))",
+		"(STP (AMY MOS FIN LVN NWY) ((FLT NCS) BAR NWY) ((FLT SCS) GOB FIN LVN))",
+		"(BOH (AMY GAL SIL TYR MUN VIE))",
+		"(MAO (FLT ECH IRI NAO WES GAS NAF BRE POR (SPA NCS) (SPA SCS)))",
+	} {
+		if !strings.Contains(mdf, want) {
+			t.Errorf("MDF missing %q", want)
+		}
+	}
+
+	now := formatDaide(daideNow(gs, m))
+	if !strings.HasPrefix(now, "NOW (SPR 1901) (") {
+		t.Errorf("NOW = %q", now)
+	}
+	if !strings.Contains(now, "(RUS FLT (STP SCS))") {
+		t.Errorf("NOW should show the St Petersburg fleet's coast: %q", now)
+	}
+	sco := formatDaide(daideSCO(gs, m))
+	if !strings.HasPrefix(sco, "SCO (AUS BUD VIE TRI) (ENG EDI LON LVP)") {
+		t.Errorf("SCO = %q", sco)
+	}
+
+	gs.Phase, gs.Season = diplomacy.PhaseRetreat, diplomacy.Fall
+	gs.Dislodged = []diplomacy.DislodgedUnit{{
+		Unit:          diplomacy.Unit{Type: diplomacy.Army, Power: diplomacy.Austria, Province: "bud"},
+		DislodgedFrom: "bud",
+		AttackerFrom:  "gal",
+	}}
+	gs.Units = slices.DeleteFunc(gs.Units, func(u diplomacy.Unit) bool { return u.Province == "bud" })
+	gs.Units = append(gs.Units, diplomacy.Unit{Type: diplomacy.Army, Power: diplomacy.Russia, Province: "bud"})
+	if now := formatDaide(daideNow(gs, m)); !strings.Contains(now, "NOW (AUT 1901)") ||
+		!strings.Contains(now, "(AUS AMY BUD MRT (RUM SER))") {
+		t.Errorf("retreat NOW = %q", now)
+	}
+
+	ord := formatDaide(daideORD(DaideResult{
+		Power:  "france",
+		Order:  OrderInput{UnitType: "army", Location: "mar", OrderType: "support", AuxLoc: "par", AuxTarget: "bur", AuxUnitType: "army"},
+		Result: "cut",
+	}, diplomacy.NewInitialState()))
+	if ord != "ORD (SPR 1901) ((FRA AMY MAR) SUP (FRA AMY PAR) MTO BUR) (CUT)" {
+		t.Errorf("ORD = %q", ord)
+	}
+}
@@ -0,0 +1,330 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// daideToken is a single 16-bit DAIDE token. The high byte is the token's
+// category and the low byte its value within that category.
+type daideToken uint16
+
+// daideCatText is the category of text tokens, whose low byte is an ASCII
+// character.
+const daideCatText = 0x4B
+
+// Brackets, units, orders, results, notes, seasons, commands and
+// parameters used by the level 0 protocol.
+const (
+	tokBRA daideToken = 0x4000
+	tokKET daideToken = 0x4001
+
+	tokAUS daideToken = 0x4100
+	tokENG daideToken = 0x4101
+	tokFRA daideToken = 0x4102
+	tokGER daideToken = 0x4103
+	tokITA daideToken = 0x4104
+	tokRUS daideToken = 0x4105
+	tokTUR daideToken = 0x4106
+
+	tokAMY daideToken = 0x4200
+	tokFLT daideToken = 0x4201
+
+	tokCTO daideToken = 0x4320
+	tokCVY daideToken = 0x4321
+	tokHLD daideToken = 0x4322
+	tokMTO daideToken = 0x4323
+	tokSUP daideToken = 0x4324
+	tokVIA daideToken = 0x4325
+	tokDSB daideToken = 0x4340
+	tokRTO daideToken = 0x4341
+	tokBLD daideToken = 0x4380
+	tokREM daideToken = 0x4381
+	tokWVE daideToken = 0x4382
+
+	tokMBV daideToken = 0x4400
+	tokBPR daideToken = 0x4401
+	tokCST daideToken = 0x4402
+	tokESC daideToken = 0x4403
+	tokFAR daideToken = 0x4404
+	tokHSC daideToken = 0x4405
+	tokNAS daideToken = 0x4406
+	tokNMB daideToken = 0x4407
+	tokNMR daideToken = 0x4408
+	tokNRN daideToken = 0x4409
+	tokNRS daideToken = 0x440A
+	tokNSA daideToken = 0x440B
+	tokNSC daideToken = 0x440C
+	tokNSF daideToken = 0x440D
+	tokNSP daideToken = 0x440E
+	tokNSU daideToken = 0x4410
+	tokNVR daideToken = 0x4411
+	tokNYU daideToken = 0x4412
+	tokYSC daideToken = 0x4413
+
+	tokSUC daideToken = 0x4500
+	tokBNC daideToken = 0x4501
+	tokCUT daideToken = 0x4502
+	tokDSR daideToken = 0x4503
+	tokFLD daideToken = 0x4504
+	tokNSO daideToken = 0x4505
+	tokRET daideToken = 0x4506
+
+	tokNCS daideToken = 0x4600
+	tokNEC daideToken = 0x4602
+	tokECS daideToken = 0x4604
+	tokSEC daideToken = 0x4606
+	tokSCS daideToken = 0x4608
+	tokSWC daideToken = 0x460A
+	tokWCS daideToken = 0x460C
+	tokNWC daideToken = 0x460E
+
+	tokSPR daideToken = 0x4700
+	tokSUM daideToken = 0x4701
+	tokFAL daideToken = 0x4702
+	tokAUT daideToken = 0x4703
+	tokWIN daideToken = 0x4704
+
+	tokCCD daideToken = 0x4800
+	tokDRW daideToken = 0x4801
+	tokFRM daideToken = 0x4802
+	tokGOF daideToken = 0x4803
+	tokHLO daideToken = 0x4804
+	tokHST daideToken = 0x4805
+	tokHUH daideToken = 0x4806
+	tokIAM daideToken = 0x4807
+	tokLOD daideToken = 0x4808
+	tokMAP daideToken = 0x4809
+	tokMDF daideToken = 0x480A
+	tokMIS daideToken = 0x480B
+	tokNME daideToken = 0x480C
+	tokNOT daideToken = 0x480D
+	tokNOW daideToken = 0x480E
+	tokOBS daideToken = 0x480F
+	tokOFF daideToken = 0x4810
+	tokORD daideToken = 0x4811
+	tokOUT daideToken = 0x4812
+	tokPRN daideToken = 0x4813
+	tokREJ daideToken = 0x4814
+	tokSCO daideToken = 0x4815
+	tokSLO daideToken = 0x4816
+	tokSND daideToken = 0x4817
+	tokSUB daideToken = 0x4818
+	tokSVE daideToken = 0x4819
+	tokTHX daideToken = 0x481A
+	tokTME daideToken = 0x481B
+	tokYES daideToken = 0x481C
+	tokADM daideToken = 0x481D
+	tokSMR daideToken = 0x481E
+
+	tokAOA daideToken = 0x4900
+	tokBTL daideToken = 0x4901
+	tokERR daideToken = 0x4902
+	tokLVL daideToken = 0x4903
+	tokMRT daideToken = 0x4904
+	tokMTL daideToken = 0x4905
+	tokNPB daideToken = 0x4906
+	tokNPR daideToken = 0x4907
+	tokPDA daideToken = 0x4908
+	tokPTL daideToken = 0x4909
+	tokRTL daideToken = 0x490A
+	tokUNO daideToken = 0x490B
+	tokDSD daideToken = 0x490D
+)
+
+// daideNames maps each named token to its three-letter mnemonic.
+var daideNames = map[daideToken]string{
+	tokAUS: "AUS", tokENG: "ENG", tokFRA: "FRA", tokGER: "GER", tokITA: "ITA", tokRUS: "RUS", tokTUR: "TUR",
+	tokAMY: "AMY", tokFLT: "FLT",
+	tokCTO: "CTO", tokCVY: "CVY", tokHLD: "HLD", tokMTO: "MTO", tokSUP: "SUP", tokVIA: "VIA",
+	tokDSB: "DSB", tokRTO: "RTO", tokBLD: "BLD", tokREM: "REM", tokWVE: "WVE",
+	tokMBV: "MBV", tokBPR: "BPR", tokCST: "CST", tokESC: "ESC", tokFAR: "FAR", tokHSC: "HSC", tokNAS: "NAS",
+	tokNMB: "NMB", tokNMR: "NMR", tokNRN: "NRN", tokNRS: "NRS", tokNSA: "NSA", tokNSC: "NSC", tokNSF: "NSF",
+	tokNSP: "NSP", tokNSU: "NSU", tokNVR: "NVR", tokNYU: "NYU", tokYSC: "YSC",
+	tokSUC: "SUC", tokBNC: "BNC", tokCUT: "CUT", tokDSR: "DSR", tokFLD: "FLD", tokNSO: "NSO", tokRET: "RET",
+	tokNCS: "NCS", tokNEC: "NEC", tokECS: "ECS", tokSEC: "SEC", tokSCS: "SCS", tokSWC: "SWC", tokWCS: "WCS", tokNWC: "NWC",
+	tokSPR: "SPR", tokSUM: "SUM", tokFAL: "FAL", tokAUT: "AUT", tokWIN: "WIN",
+	tokCCD: "CCD", tokDRW: "DRW", tokFRM: "FRM", tokGOF: "GOF", tokHLO: "HLO", tokHST: "HST", tokHUH: "HUH",
+	tokIAM: "IAM", tokLOD: "LOD", tokMAP: "MAP", tokMDF: "MDF", tokMIS: "MIS", tokNME: "NME", tokNOT: "NOT",
+	tokNOW: "NOW", tokOBS: "OBS", tokOFF: "OFF", tokORD: "ORD", tokOUT: "OUT", tokPRN: "PRN", tokREJ: "REJ",
+	tokSCO: "SCO", tokSLO: "SLO", tokSND: "SND", tokSUB: "SUB", tokSVE: "SVE", tokTHX: "THX", tokTME: "TME",
+	tokYES: "YES", tokADM: "ADM", tokSMR: "SMR",
+	tokAOA: "AOA", tokBTL: "BTL", tokERR: "ERR", tokLVL: "LVL", tokMRT: "MRT", tokMTL: "MTL", tokNPB: "NPB",
+	tokNPR: "NPR", tokPDA: "PDA", tokPTL: "PTL", tokRTL: "RTL", tokUNO: "UNO", tokDSD: "DSD",
+}
+
+// daideProvinceCategories lists the standard map's provinces by DAIDE
+// category. Provinces are numbered in this order across all categories, so
+// the order must not change.
+var daideProvinceCategories = []struct {
+	cat   byte
+	names []string
+}{
+	{0x50, []string{"BOH", "BUR", "GAL", "RUH", "SIL", "TYR", "UKR"}},
+	{0x51, []string{"BUD", "MOS", "MUN", "PAR", "SER", "VIE", "WAR"}},
+	{0x52, []string{"ADR", "AEG", "BAL", "BAR", "BLA", "EAS", "ECH", "GOB", "GOL", "HEL", "ION", "IRI", "MAO", "NAO", "NTH", "NWG", "SKA", "TYS", "WES"}},
+	{0x54, []string{"ALB", "APU", "ARM", "CLY", "FIN", "GAS", "LVN", "NAF", "PIC", "PIE", "PRU", "SYR", "TUS", "WAL", "YOR"}},
+	{0x55, []string{"ANK", "BEL", "BER", "BRE", "CON", "DEN", "EDI", "GRE", "HOL", "KIE", "LON", "LVP", "MAR", "NAP", "NWY", "POR", "ROM", "RUM", "SEV", "SMY", "SWE", "TRI", "TUN", "VEN"}},
+	{0x57, []string{"BUL", "SPA", "STP"}},
+}
+
+var (
+	daideByName     = map[string]daideToken{}
+	daideProvinces  []daideToken              // in DAIDE number order
+	daideProvinceOf = map[string]daideToken{} // our province ID -> token
+	daideProvinceID = map[daideToken]string{} // token -> our province ID
+	daidePowerOf    = map[diplomacy.Power]daideToken{}
+	daidePowers     = map[daideToken]diplomacy.Power{}
+	daideCoastOf    = map[diplomacy.Coast]daideToken{}
+	daideCoasts     = map[daideToken]diplomacy.Coast{}
+)
+
+func init() {
+	n := 0
+	for _, c := range daideProvinceCategories {
+		for _, name := range c.names {
+			tok := daideToken(c.cat)<<8 | daideToken(n)
+			n++
+			daideNames[tok] = name
+			daideProvinces = append(daideProvinces, tok)
+			// DAIDE abbreviates a few seas differently (ECH, NWG, GOB).
+			id := diplomacy.CanonicalProvince(strings.ToLower(name))
+			daideProvinceOf[id] = tok
+			daideProvinceID[tok] = id
+		}
+	}
+	for tok, name := range daideNames {
+		daideByName[name] = tok
+	}
+	for i, p := range diplomacy.AllPowers() {
+		daidePowerOf[p] = tokAUS + daideToken(i)
+		daidePowers[tokAUS+daideToken(i)] = p
+	}
+	for c, tok := range map[diplomacy.Coast]daideToken{
+		diplomacy.NorthCoast: tokNCS,
+		diplomacy.SouthCoast: tokSCS,
+		diplomacy.EastCoast:  tokECS,
+		diplomacy.WestCoast:  tokWCS,
+	} {
+		daideCoastOf[c] = tok
+		daideCoasts[tok] = c
+	}
+}
+
+func (t daideToken) category() byte { return byte(t >> 8) }
+
+func (t daideToken) isInt() bool { return t < 0x4000 }
+
+// daideInt encodes n as a 14-bit two's complement integer token.
+func daideInt(n int) daideToken { return daideToken(n & 0x3FFF) }
+
+// intValue decodes an integer token.
+func (t daideToken) intValue() int {
+	if t&0x2000 != 0 {
+		return int(t) - 0x4000
+	}
+	return int(t)
+}
+
+// daideText encodes s as text tokens.
+func daideText(s string) []daideToken {
+	toks := make([]daideToken, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		toks = append(toks, daideToken(daideCatText)<<8|daideToken(s[i]))
+	}
+	return toks
+}
+
+// daideWrap brackets toks.
+func daideWrap(toks ...daideToken) []daideToken {
+	out := make([]daideToken, 0, len(toks)+2)
+	out = append(out, tokBRA)
+	out = append(out, toks...)
+	return append(out, tokKET)
+}
+
+// formatDaide renders tokens in the protocol's text notation, e.g.
+// "NME ('Albert') ('v6.0')".
+func formatDaide(toks []daideToken) string {
+	var b strings.Builder
+	inText := false
+	for i, t := range toks {
+		if t.category() == daideCatText {
+			if !inText {
+				if i > 0 && toks[i-1] != tokBRA {
+					b.WriteByte(' ')
+				}
+				b.WriteByte('\'')
+				inText = true
+			}
+			b.WriteByte(byte(t))
+			continue
+		}
+		if inText {
+			b.WriteByte('\'')
+			inText = false
+		}
+		if i > 0 && t != tokKET && toks[i-1] != tokBRA {
+			b.WriteByte(' ')
+		}
+		switch {
+		case t == tokBRA:
+			b.WriteByte('(')
+		case t == tokKET:
+			b.WriteByte(')')
+		case t.isInt():
+			b.WriteString(strconv.Itoa(t.intValue()))
+		case daideNames[t] != "":
+			b.WriteString(daideNames[t])
+		default:
+			fmt.Fprintf(&b, "0x%04X", uint16(t))
+		}
+	}
+	if inText {
+		b.WriteByte('\'')
+	}
+	return b.String()
+}
+
+// parseDaide reads the protocol's text notation back into tokens.
+func parseDaide(s string) ([]daideToken, error) {
+	var toks []daideToken
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ':
+			i++
+		case c == '(':
+			toks = append(toks, tokBRA)
+			i++
+		case c == ')':
+			toks = append(toks, tokKET)
+			i++
+		case c == '\'':
+			end := strings.IndexByte(s[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated text at %d", i)
+			}
+			toks = append(toks, daideText(s[i+1:i+1+end])...)
+			i += end + 2
+		default:
+			j := i
+			for j < len(s) && s[j] != ' ' && s[j] != '(' && s[j] != ')' {
+				j++
+			}
+			word := s[i:j]
+			if tok, ok := daideByName[strings.ToUpper(word)]; ok {
+				toks = append(toks, tok)
+			} else if n, err := strconv.Atoi(word); err == nil {
+				toks = append(toks, daideInt(n))
+			} else {
+				return nil, fmt.Errorf("unknown token %q", word)
+			}
+			i = j
+		}
+	}
+	return toks, nil
+}
@@ -18,6 +18,7 @@ const (
 	IntentOfferDeal
 	IntentAccept
 	IntentReject
+	IntentProposeOrders
 )
 
 // DiplomaticIntent is the structured interpretation of a diplomatic message.
@@ -25,8 +26,11 @@ type DiplomaticIntent struct {
 	Type        IntentType
 	From        diplomacy.Power
 	To          diplomacy.Power
-	Provinces   []string        // relevant provinces
-	TargetPower diplomacy.Power // e.g. "alliance against Turkey"
+	Provinces   []string              // relevant provinces
+	TargetPower diplomacy.Power       // e.g. "alliance against Turkey"
+	Orders      []diplomacy.DSONOrder // proposed order set for IntentProposeOrders
+	Channel     string                // press channel ID; set To is ignored when sending
+	Guessed     bool                  // read from free text by keyword, so never binding
 }
 
 // BotDiplomacyState tracks promises and trust for a single bot.
@@ -64,54 +68,27 @@ func (it IntentType) String() string {
 		return "accept"
 	case IntentReject:
 		return "reject"
+	case IntentProposeOrders:
+		return "propose_orders"
 	default:
 		return "unknown"
 	}
 }
 
-// FormatCannedMessage converts a DiplomaticIntent into a human-readable message.
-func FormatCannedMessage(intent DiplomaticIntent) string {
-	switch intent.Type {
-	case IntentRequestSupport:
-		if len(intent.Provinces) >= 2 {
-			return fmt.Sprintf("Request support from %s to %s", intent.Provinces[0], intent.Provinces[1])
-		}
-		if len(intent.Provinces) == 1 {
-			return fmt.Sprintf("Request support at %s", intent.Provinces[0])
-		}
-		return "Request support"
-
-	case IntentProposeNonAggression:
-		if len(intent.Provinces) > 0 {
-			return fmt.Sprintf("Please don't attack %s, I won't attack yours", intent.Provinces[0])
-		}
-		return "Let's agree not to attack each other"
-
-	case IntentProposeAlliance:
-		if intent.TargetPower != "" {
-			return fmt.Sprintf("Let's work together against %s", powerLabel(intent.TargetPower))
-		}
-		return "Let's work together"
-
-	case IntentThreaten:
-		if len(intent.Provinces) > 0 {
-			return fmt.Sprintf("I'm coming for %s — back off", intent.Provinces[0])
-		}
-		return "Back off or face consequences"
-
-	case IntentOfferDeal:
-		if len(intent.Provinces) >= 2 {
-			return fmt.Sprintf("Deal: I take %s, you take %s", intent.Provinces[0], intent.Provinces[1])
-		}
-		return "I'd like to make a deal"
-
-	case IntentAccept:
-		return "Agreed"
-
-	case IntentReject:
-		return "No deal"
+// IsProposal reports whether an intent asks for an answer: a request,
+// proposal or offer, as opposed to a threat or a reply.
+func (it IntentType) IsProposal() bool {
+	switch it {
+	case IntentRequestSupport, IntentProposeNonAggression, IntentProposeAlliance, IntentOfferDeal, IntentProposeOrders:
+		return true
 	}
-	return ""
+	return false
+}
+
+// FormatCannedMessage converts a DiplomaticIntent into a human-readable
+// English message.
+func FormatCannedMessage(intent DiplomaticIntent) string {
+	return FormatCannedMessageLocale(intent, DefaultLocale)
 }
 
 // ParseCannedMessage converts a canned message string into a DiplomaticIntent.
@@ -172,6 +149,14 @@ func ParseCannedMessage(content string) (*DiplomaticIntent, error) {
 		}, nil
 	}
 
+	if rest, ok := cutPrefixFold(strings.TrimSpace(content), "here's the plan:"); ok {
+		orders, err := diplomacy.ParseDSON(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, fmt.Errorf("invalid order proposal: %w", err)
+		}
+		return &DiplomaticIntent{Type: IntentProposeOrders, Orders: orders}, nil
+	}
+
 	if rest, ok := strings.CutPrefix(lower, "deal: i take "); ok {
 		parts := strings.SplitN(rest, ", you take ", 2)
 		if len(parts) == 2 {
@@ -224,6 +209,15 @@ func AdjustOrdersForDiplomacy(scores map[string]float64, dipState *BotDiplomacyS
 					scores[key] += 3.0
 				}
 			}
+
+		case IntentProposeOrders:
+			// Boost the orders the sender proposed for our units
+			for _, d := range req.Orders {
+				key := proposedOrderKey(power, d)
+				if _, ok := scores[key]; ok {
+					scores[key] += 4.0 * compliance * trust
+				}
+			}
 		}
 	}
 }
@@ -240,6 +234,64 @@ func holdKey(power diplomacy.Power, loc string) string {
 	return fmt.Sprintf("%s:hold:%s", power, loc)
 }
 
+// proposedOrderKey maps a proposed DSON order onto the score key of the
+// matching order, or "" if the order type is not scored.
+func proposedOrderKey(power diplomacy.Power, d diplomacy.DSONOrder) string {
+	switch d.Type {
+	case diplomacy.DSONMove:
+		return moveKey(power, d.Target)
+	case diplomacy.DSONSupportMove:
+		return supportKey(power, d.AuxLocation, d.AuxTarget)
+	case diplomacy.DSONHold:
+		return holdKey(power, d.Location)
+	}
+	return ""
+}
+
+// ProposedOrdersFor returns the orders in a proposal that apply to units
+// owned by power in the given state, converted to engine orders.
+func ProposedOrdersFor(intent DiplomaticIntent, power diplomacy.Power, gs *diplomacy.GameState) []diplomacy.Order {
+	var orders []diplomacy.Order
+	for _, d := range intent.Orders {
+		u := gs.UnitAt(d.Location)
+		if u == nil || u.Power != power || u.Type != d.UnitType {
+			continue
+		}
+		orders = append(orders, diplomacy.DSONToOrder(d, power))
+	}
+	return orders
+}
+
+// ProposalIsFriendly reports whether an order proposal leaves power alone:
+// no unit of another power is proposed to move into a province where power
+// has a unit or owns a supply center.
+func ProposalIsFriendly(intent DiplomaticIntent, power diplomacy.Power, gs *diplomacy.GameState) bool {
+	for _, d := range intent.Orders {
+		if d.Type != diplomacy.DSONMove {
+			continue
+		}
+		if u := gs.UnitAt(d.Location); u != nil && u.Power == power {
+			continue
+		}
+		if u := gs.UnitAt(d.Target); u != nil && u.Power == power {
+			return false
+		}
+		if gs.SupplyCenters[d.Target] == power {
+			return false
+		}
+	}
+	return true
+}
+
+// cutPrefixFold is strings.CutPrefix with ASCII case-insensitive matching,
+// preserving the case of the remainder (DSON unit letters are upper case).
+func cutPrefixFold(s, prefix string) (string, bool) {
+	if len(s) < len(prefix) || !strings.EqualFold(s[:len(prefix)], prefix) {
+		return s, false
+	}
+	return s[len(prefix):], true
+}
+
 func powerLabel(p diplomacy.Power) string {
 	s := string(p)
 	if len(s) > 0 {
@@ -256,6 +308,7 @@ func CannedMessageTemplates() []string {
 		"Let's work together against {power}",
 		"I'm coming for {province} — back off",
 		"Deal: I take {province}, you take {province}",
+		"Here's the plan: {orders}",
 		"Agreed",
 		"No deal",
 	}
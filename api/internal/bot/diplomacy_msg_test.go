@@ -202,7 +202,61 @@ func TestAdjustOrdersForDiplomacy_NilState(t *testing.T) {
 
 func TestCannedMessageTemplates(t *testing.T) {
 	templates := CannedMessageTemplates()
-	if len(templates) != 7 {
-		t.Errorf("expected 7 templates, got %d", len(templates))
+	if len(templates) != 8 {
+		t.Errorf("expected 8 templates, got %d", len(templates))
+	}
+}
+
+func TestFormatAndParseCannedMessage_ProposeOrders(t *testing.T) {
+	orders, err := diplomacy.ParseDSON("A mun - bur ; A ber - kie")
+	if err != nil {
+		t.Fatalf("parse DSON: %v", err)
+	}
+	msg := FormatCannedMessage(DiplomaticIntent{Type: IntentProposeOrders, Orders: orders})
+	if msg != "Here's the plan: A mun - bur ; A ber - kie" {
+		t.Errorf("unexpected message: %q", msg)
+	}
+	parsed, err := ParseCannedMessage(msg)
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if parsed.Type != IntentProposeOrders {
+		t.Errorf("expected ProposeOrders, got %d", parsed.Type)
+	}
+	if len(parsed.Orders) != 2 || parsed.Orders[0].Target != "bur" {
+		t.Errorf("unexpected orders: %+v", parsed.Orders)
+	}
+}
+
+func TestProposedOrdersFor(t *testing.T) {
+	gs := diplomacy.NewInitialState()
+	orders, _ := diplomacy.ParseDSON("A mun - bur ; F lon - nth ; A vie H")
+	intent := DiplomaticIntent{Type: IntentProposeOrders, Orders: orders}
+
+	got := ProposedOrdersFor(intent, diplomacy.Germany, gs)
+	if len(got) != 1 {
+		t.Fatalf("expected 1 German order, got %d", len(got))
+	}
+	if got[0].Location != "mun" || got[0].Target != "bur" || got[0].Power != diplomacy.Germany {
+		t.Errorf("unexpected order: %+v", got[0])
+	}
+}
+
+func TestProposalIsFriendly(t *testing.T) {
+	gs := diplomacy.NewInitialState()
+
+	elsewhere, _ := diplomacy.ParseDSON("A mun - bur ; F kie - hol")
+	if !ProposalIsFriendly(DiplomaticIntent{Type: IntentProposeOrders, Orders: elsewhere}, diplomacy.Russia, gs) {
+		t.Error("moves far from Russia should be friendly to Russia")
+	}
+
+	hostile, _ := diplomacy.ParseDSON("A gal - war")
+	if ProposalIsFriendly(DiplomaticIntent{Type: IntentProposeOrders, Orders: hostile}, diplomacy.Russia, gs) {
+		t.Error("a move into Warsaw should not be friendly to Russia")
+	}
+
+	own, _ := diplomacy.ParseDSON("A war - gal")
+	if !ProposalIsFriendly(DiplomaticIntent{Type: IntentProposeOrders, Orders: own}, diplomacy.Russia, gs) {
+		t.Error("a proposal only moving Russia's own units should be friendly")
 	}
 }
@@ -0,0 +1,73 @@
+package bot
+
+import (
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+const (
+	// ConcedeWinProb is the win probability below which a bot counts a year
+	// as hopeless.
+	ConcedeWinProb = 0.05
+	// ConcedeYears is how many consecutive hopeless years make a bot concede.
+	ConcedeYears = 2
+
+	// drawRivalMargin is how far a rival's win probability must exceed the
+	// bot's own for the bot to prefer a draw to playing on.
+	drawRivalMargin = 0.15
+)
+
+// DrawDecision is a value network's verdict on a draw vote for one power.
+type DrawDecision struct {
+	Vote    bool    // vote for a draw
+	Concede bool    // voting because winning has looked hopeless for ConcedeYears
+	WinProb float64 // the power's win probability in the current position
+}
+
+// ValueDrawVoter decides draw votes from the value network's win
+// probabilities rather than supply center counts. history holds the
+// position at the start of each recent year, oldest first, ending with the
+// current position. ok is false if the value network is unavailable, in
+// which case the strategy's DrawVoter decides.
+type ValueDrawVoter interface {
+	DecideDraw(history []*diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap) (d DrawDecision, ok bool)
+}
+
+// decideDrawByValue votes for a draw when a rival is clearly likelier to
+// solo than power, and concedes when power's own win probability has been
+// below ConcedeWinProb at the start of each of the last ConcedeYears years.
+func decideDrawByValue(vn ValueNetwork, history []*diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap) (DrawDecision, bool) {
+	if len(history) == 0 {
+		return DrawDecision{}, false
+	}
+	current := history[len(history)-1]
+	var d DrawDecision
+	found := false
+	rival := 0.0
+	for _, e := range EvaluatePowers(current, m, vn) {
+		if e.WinProb == nil {
+			return DrawDecision{}, false
+		}
+		if e.Power == power {
+			d.WinProb, found = *e.WinProb, true
+		} else if current.PowerIsAlive(e.Power) {
+			rival = max(rival, *e.WinProb)
+		}
+	}
+	if !found {
+		return DrawDecision{}, false
+	}
+	d.Vote = rival >= d.WinProb+drawRivalMargin
+
+	if d.WinProb < ConcedeWinProb && len(history) >= ConcedeYears {
+		d.Concede = true
+		for _, gs := range history[len(history)-ConcedeYears : len(history)-1] {
+			v, err := vn.RunValueNetwork(gs, power, m)
+			if err != nil || float64(v[1]) >= ConcedeWinProb {
+				d.Concede = false
+				break
+			}
+		}
+		d.Vote = d.Vote || d.Concede
+	}
+	return d, true
+}
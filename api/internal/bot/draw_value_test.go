@@ -0,0 +1,89 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// yearlyValueNetwork returns a win probability per power and year, and 0.1
+// for anything unlisted.
+type yearlyValueNetwork map[int]map[diplomacy.Power]float32
+
+func (v yearlyValueNetwork) RunValueNetwork(gs *diplomacy.GameState, power diplomacy.Power, _ *diplomacy.DiplomacyMap) ([4]float32, error) {
+	win, ok := v[gs.Year][power]
+	if !ok {
+		win = 0.1
+	}
+	return [4]float32{0, win, 0, 1}, nil
+}
+
+func stateInYear(year int) *diplomacy.GameState {
+	gs := diplomacy.NewInitialState()
+	gs.Year = year
+	return gs
+}
+
+func TestDecideDrawByValue(t *testing.T) {
+	m := diplomacy.StandardMap()
+	history := []*diplomacy.GameState{stateInYear(1905), stateInYear(1906)}
+
+	tests := []struct {
+		name        string
+		vn          yearlyValueNetwork
+		wantVote    bool
+		wantConcede bool
+	}{
+		{
+			name: "even board plays on",
+			vn:   yearlyValueNetwork{},
+		},
+		{
+			name:     "rival far ahead votes draw",
+			vn:       yearlyValueNetwork{1906: {diplomacy.Turkey: 0.6}},
+			wantVote: true,
+		},
+		{
+			name: "leader refuses draw",
+			vn:   yearlyValueNetwork{1906: {diplomacy.France: 0.6, diplomacy.Turkey: 0.3}},
+		},
+		{
+			name: "one hopeless year plays on",
+			vn:   yearlyValueNetwork{1906: {diplomacy.France: 0.01}},
+		},
+		{
+			name: "hopeless for two years concedes",
+			vn: yearlyValueNetwork{
+				1905: {diplomacy.France: 0.02},
+				1906: {diplomacy.France: 0.01},
+			},
+			wantVote:    true,
+			wantConcede: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, ok := decideDrawByValue(tt.vn, history, diplomacy.France, m)
+			if !ok {
+				t.Fatal("expected a decision")
+			}
+			if d.Vote != tt.wantVote || d.Concede != tt.wantConcede {
+				t.Errorf("got vote=%v concede=%v, want vote=%v concede=%v", d.Vote, d.Concede, tt.wantVote, tt.wantConcede)
+			}
+		})
+	}
+}
+
+func TestDecideDrawByValueShortHistory(t *testing.T) {
+	vn := yearlyValueNetwork{1901: {diplomacy.France: 0.01}}
+	d, ok := decideDrawByValue(vn, []*diplomacy.GameState{stateInYear(1901)}, diplomacy.France, diplomacy.StandardMap())
+	if !ok {
+		t.Fatal("expected a decision")
+	}
+	if d.Concede {
+		t.Error("expected no concession before ConcedeYears years have passed")
+	}
+	if _, ok := decideDrawByValue(vn, nil, diplomacy.France, diplomacy.StandardMap()); ok {
+		t.Error("expected no decision without a position")
+	}
+}
@@ -0,0 +1,151 @@
+package bot
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Circuit breaker settings for external engines. Set these at startup,
+// alongside ExternalEnginePath, before creating strategies.
+var (
+	// EngineFailureThreshold is how many consecutive failures disable an engine.
+	EngineFailureThreshold = 3
+	// EngineRetryInterval is how long a disabled engine stays off before one
+	// trial spawn is allowed.
+	EngineRetryInterval = 5 * time.Minute
+)
+
+// Engine breaker states.
+const (
+	EngineHealthy  = "healthy"  // engine is used normally
+	EngineDisabled = "disabled" // too many failures; strategies fall back to hard
+	EngineProbing  = "probing"  // retry interval elapsed; one trial is in flight
+)
+
+// EngineStatus is a snapshot of an external engine's health.
+type EngineStatus struct {
+	Path                string     `json:"path"`
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	TotalFailures       int        `json:"total_failures"`
+	LastError           string     `json:"last_error,omitempty"`
+	LastFailureAt       *time.Time `json:"last_failure_at,omitempty"`
+	LastSuccessAt       *time.Time `json:"last_success_at,omitempty"`
+	RetryAt             *time.Time `json:"retry_at,omitempty"` // when a disabled engine is next tried
+}
+
+// engineHealth is a circuit breaker for one engine binary. After
+// EngineFailureThreshold consecutive spawn or query failures it opens and
+// stops new spawns; once EngineRetryInterval has passed it lets a single
+// trial through, closing again once the trial generates orders. A trial that
+// never reports back is abandoned after another retry interval.
+type engineHealth struct {
+	mu  sync.Mutex
+	now func() time.Time
+
+	status       EngineStatus
+	openedAt     time.Time // zero while healthy
+	probing      bool
+	probeStarted time.Time
+}
+
+var (
+	enginesMu sync.Mutex
+	engines   = map[string]*engineHealth{} // engine path -> health
+)
+
+// engineHealthFor returns the breaker tracking the engine at path.
+func engineHealthFor(path string) *engineHealth {
+	enginesMu.Lock()
+	defer enginesMu.Unlock()
+	h, ok := engines[path]
+	if !ok {
+		h = &engineHealth{now: time.Now, status: EngineStatus{Path: path, State: EngineHealthy}}
+		engines[path] = h
+	}
+	return h
+}
+
+// EngineStatuses reports the health of every external engine used so far,
+// sorted by path.
+func EngineStatuses() []EngineStatus {
+	enginesMu.Lock()
+	hs := make([]*engineHealth, 0, len(engines))
+	for _, h := range engines {
+		hs = append(hs, h)
+	}
+	enginesMu.Unlock()
+
+	out := make([]EngineStatus, 0, len(hs))
+	for _, h := range hs {
+		out = append(out, h.snapshot())
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out
+}
+
+// allow reports whether the engine may be used. While disabled it returns
+// false until the retry interval passes, then true for a single trial.
+func (h *engineHealth) allow() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.openedAt.IsZero() {
+		return true
+	}
+	now := h.now()
+	if h.probing && now.Before(h.probeStarted.Add(EngineRetryInterval)) {
+		return false
+	}
+	if now.Before(h.openedAt.Add(EngineRetryInterval)) {
+		return false
+	}
+	h.probing, h.probeStarted = true, now
+	return true
+}
+
+// success records a query that generated orders and re-enables the engine.
+// Spawning alone is not a success: an engine that starts but cannot answer
+// must still trip the breaker.
+func (h *engineHealth) success() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := h.now()
+	h.status.LastSuccessAt = &now
+	h.status.ConsecutiveFailures = 0
+	h.openedAt = time.Time{}
+	h.probing = false
+}
+
+// failure records a failed spawn or query, disabling the engine once the
+// threshold is reached or when a trial fails.
+func (h *engineHealth) failure(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := h.now()
+	h.status.LastFailureAt = &now
+	h.status.LastError = err.Error()
+	h.status.ConsecutiveFailures++
+	h.status.TotalFailures++
+	if h.probing || h.status.ConsecutiveFailures >= EngineFailureThreshold {
+		h.openedAt = now
+		h.probing = false
+	}
+}
+
+func (h *engineHealth) snapshot() EngineStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s := h.status
+	switch {
+	case h.openedAt.IsZero():
+		s.State = EngineHealthy
+	case h.probing:
+		s.State = EngineProbing
+	default:
+		s.State = EngineDisabled
+		retry := h.openedAt.Add(EngineRetryInterval)
+		s.RetryAt = &retry
+	}
+	return s
+}
@@ -0,0 +1,100 @@
+package bot
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+func TestEngineHealth_Breaker(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := &engineHealth{now: func() time.Time { return now }, status: EngineStatus{Path: "test"}}
+	boom := errors.New("boom")
+
+	for i := 0; i < EngineFailureThreshold; i++ {
+		if !h.allow() {
+			t.Fatalf("engine disabled after %d failures", i)
+		}
+		h.failure(boom)
+	}
+	if h.allow() {
+		t.Fatal("engine should be disabled after reaching the threshold")
+	}
+	s := h.snapshot()
+	if s.State != EngineDisabled || s.RetryAt == nil || s.LastError != "boom" {
+		t.Errorf("status = %+v, want disabled with retry time and last error", s)
+	}
+
+	// After the retry interval, exactly one trial is let through.
+	now = now.Add(EngineRetryInterval)
+	if !h.allow() {
+		t.Fatal("expected a trial after the retry interval")
+	}
+	if h.allow() {
+		t.Error("only one trial should run at a time")
+	}
+	if s := h.snapshot(); s.State != EngineProbing {
+		t.Errorf("state = %s, want probing", s.State)
+	}
+
+	// A failed trial disables the engine again immediately.
+	h.failure(boom)
+	if h.allow() {
+		t.Fatal("failed trial should disable the engine again")
+	}
+
+	now = now.Add(EngineRetryInterval)
+	if !h.allow() {
+		t.Fatal("expected another trial")
+	}
+	// A trial that never reports back is abandoned after another interval.
+	now = now.Add(EngineRetryInterval)
+	if !h.allow() {
+		t.Fatal("expected a stuck trial to be replaced")
+	}
+	h.success()
+	if s := h.snapshot(); s.State != EngineHealthy || s.ConsecutiveFailures != 0 || s.TotalFailures != EngineFailureThreshold+1 {
+		t.Errorf("status after success = %+v", s)
+	}
+	if !h.allow() {
+		t.Error("engine should be enabled after a successful trial")
+	}
+}
+
+func TestNewExternalOrFallback_StopsSpawningBrokenEngine(t *testing.T) {
+	path := "/nonexistent/realpolitik-breaker-test"
+	for i := 0; i < EngineFailureThreshold+2; i++ {
+		if s := newExternalOrFallback(path); s.Name() != "hard" {
+			t.Fatalf("got %s, want hard fallback", s.Name())
+		}
+	}
+	for _, s := range EngineStatuses() {
+		if s.Path != path {
+			continue
+		}
+		if s.State != EngineDisabled || s.TotalFailures != EngineFailureThreshold {
+			t.Errorf("status = %+v, want disabled after %d spawn attempts", s, EngineFailureThreshold)
+		}
+		return
+	}
+	t.Errorf("no status reported for %s", path)
+}
+
+func TestNewExternalOrFallback_SpawnIsNotSuccess(t *testing.T) {
+	bin := buildMockEngine(t, mockCrashEngineSource)
+	gs := initialGameState()
+	m := diplomacy.StandardMap()
+	for i := 0; i < EngineFailureThreshold; i++ {
+		s := newExternalOrFallback(bin)
+		if s.Name() != "realpolitik" {
+			t.Fatalf("spawn %d: got %s, want the engine", i, s.Name())
+		}
+		s.GenerateMovementOrders(gs, diplomacy.Austria, m)
+		s.(*ExternalStrategy).Close()
+	}
+	if s := newExternalOrFallback(bin); s.Name() != "hard" {
+		t.Errorf("got %s, want hard once the engine failed every query", s.Name())
+	}
+}
@@ -21,24 +21,44 @@ var (
 	distOnce           sync.Once
 	stdFleetDistMatrix *distMatrix
 	fleetDistOnce      sync.Once
+
+	customDistMatrices      sync.Map // *diplomacy.DiplomacyMap -> *distMatrix
+	customFleetDistMatrices sync.Map // *diplomacy.DiplomacyMap -> *distMatrix
 )
 
-// getDistMatrix returns the cached distance matrix for the standard map.
+// getDistMatrix returns the cached distance matrix for the map.
 func getDistMatrix(m *diplomacy.DiplomacyMap) *distMatrix {
+	if m != diplomacy.StandardMap() {
+		return customDistMatrix(&customDistMatrices, m, buildDistMatrix)
+	}
 	distOnce.Do(func() {
 		stdDistMatrix = buildDistMatrix(m)
 	})
 	return stdDistMatrix
 }
 
-// getFleetDistMatrix returns the cached fleet-move distance matrix for the standard map.
+// getFleetDistMatrix returns the cached fleet-move distance matrix for the map.
 func getFleetDistMatrix(m *diplomacy.DiplomacyMap) *distMatrix {
+	if m != diplomacy.StandardMap() {
+		return customDistMatrix(&customFleetDistMatrices, m, buildFleetDistMatrix)
+	}
 	fleetDistOnce.Do(func() {
 		stdFleetDistMatrix = buildFleetDistMatrix(m)
 	})
 	return stdFleetDistMatrix
 }
 
+// customDistMatrix returns the matrix cached for a custom variant's map,
+// building it on first use. Variant maps are frozen and registered once per
+// version, so they can key the cache.
+func customDistMatrix(cache *sync.Map, m *diplomacy.DiplomacyMap, build func(*diplomacy.DiplomacyMap) *distMatrix) *distMatrix {
+	if dm, ok := cache.Load(m); ok {
+		return dm.(*distMatrix)
+	}
+	dm, _ := cache.LoadOrStore(m, build(m))
+	return dm.(*distMatrix)
+}
+
 // buildFleetDistMatrix builds a distance matrix using FleetOK adjacencies.
 // This allows fleet-based pathfinding across sea provinces and coastal connections.
 func buildFleetDistMatrix(m *diplomacy.DiplomacyMap) *distMatrix {
@@ -0,0 +1,41 @@
+package bot
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// TestStrategiesShareStandardMap runs strategies for every power at once
+// against the shared standard map. Run with -race to catch unsynchronised
+// writes; map debugging panics if any strategy mutates the map.
+func TestStrategiesShareStandardMap(t *testing.T) {
+	diplomacy.SetMapDebug(true)
+	defer diplomacy.SetMapDebug(false)
+	m := diplomacy.StandardMap()
+	strategies := []Strategy{RandomStrategy{}, HeuristicStrategy{}, TacticalStrategy{}}
+
+	var wg sync.WaitGroup
+	for _, power := range diplomacy.AllPowers() {
+		for _, s := range strategies {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				gs := diplomacy.NewInitialState()
+				for range 3 {
+					orders := s.GenerateMovementOrders(gs, power, diplomacy.StandardMap())
+					if len(orders) != len(gs.UnitsOf(power)) {
+						t.Errorf("%s/%s: got %d orders", s.Name(), power, len(orders))
+						return
+					}
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	if err := m.Verify(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,86 @@
+package bot
+
+import "github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+
+// mercyPenalty is taken off a bot's score for moving into, or supporting a
+// move into, a home center the game's mercy setting still spares. It
+// outweighs the bonus for an enemy center, so such attacks come last
+// without being ruled out.
+const mercyPenalty = 15.0
+
+// mercyCost returns mercyPenalty if target is another power's home center
+// that the mercy setting spares this year, and 0 otherwise.
+func mercyCost(gs *diplomacy.GameState, power diplomacy.Power, target string, m *diplomacy.DiplomacyMap) float64 {
+	if gs.MercyUntil == 0 {
+		return 0
+	}
+	prov := m.Provinces[target]
+	if prov == nil || prov.HomePower == "" || prov.HomePower == power || !gs.MercyProtects(prov.HomePower) {
+		return 0
+	}
+	return mercyPenalty
+}
+
+// mercyPositionCost returns mercyPenalty for each spared home center power
+// stands on or owns in gs, so searches that score resolved positions steer
+// away from lines that take them.
+func mercyPositionCost(gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap) float64 {
+	if gs.MercyUntil == 0 {
+		return 0
+	}
+	cost := 0.0
+	for _, u := range gs.Units {
+		if u.Power == power {
+			cost += mercyCost(gs, power, u.Province, m)
+		}
+	}
+	for prov, owner := range gs.SupplyCenters {
+		if owner == power {
+			cost += mercyCost(gs, power, prov, m)
+		}
+	}
+	return cost
+}
+
+// mercyOrdersCost returns mercyPenalty for each of orders that moves into,
+// or supports a move into, a home center the mercy setting spares this
+// year. Searches charge it to a candidate as a whole, since the positions
+// they look ahead to may fall after the mercy has ended.
+func mercyOrdersCost(orders []OrderInput, gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap) float64 {
+	if gs.MercyUntil == 0 {
+		return 0
+	}
+	cost := 0.0
+	for _, o := range orders {
+		switch o.OrderType {
+		case "move":
+			cost += mercyCost(gs, power, o.Target, m)
+		case "support":
+			if o.AuxTarget != "" {
+				cost += mercyCost(gs, power, o.AuxTarget, m)
+			}
+		}
+	}
+	return cost
+}
+
+// attacksSpared reports whether orders move into, or support a move into, a
+// home center the mercy setting spares this year.
+func attacksSpared(orders []OrderInput, gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap) bool {
+	return mercyOrdersCost(orders, gs, power, m) > 0
+}
+
+// mercifulOptions returns the book options that leave spared home centers
+// alone.
+func mercifulOptions(options []BookOption, gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap) []BookOption {
+	if gs.MercyUntil == 0 {
+		return options
+	}
+	var kept []BookOption
+	for _, o := range options {
+		if !attacksSpared(o.Orders, gs, power, m) {
+			kept = append(kept, o)
+		}
+	}
+	return kept
+}
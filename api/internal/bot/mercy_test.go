@@ -0,0 +1,111 @@
+package bot
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+func TestMercyCost(t *testing.T) {
+	m := diplomacy.StandardMap()
+	gs := diplomacy.NewInitialState()
+	gs.MercyPowers = []diplomacy.Power{diplomacy.France}
+	gs.MercyUntil = 1902
+
+	if c := mercyCost(gs, diplomacy.Germany, "par", m); c != mercyPenalty {
+		t.Errorf("expected Paris to be spared, got cost %v", c)
+	}
+	if c := mercyCost(gs, diplomacy.Germany, "bel", m); c != 0 {
+		t.Errorf("expected no cost for a neutral center, got %v", c)
+	}
+	if c := mercyCost(gs, diplomacy.France, "par", m); c != 0 {
+		t.Errorf("expected no cost for France's own center, got %v", c)
+	}
+	if c := mercyCost(gs, diplomacy.Germany, "ber", m); c != 0 {
+		t.Errorf("expected no cost for an unprotected power's center, got %v", c)
+	}
+	gs.Year = 1903
+	if c := mercyCost(gs, diplomacy.Germany, "par", m); c != 0 {
+		t.Errorf("expected mercy to end after 1902, got cost %v", c)
+	}
+}
+
+func TestHardScoreMovesMercy(t *testing.T) {
+	m := diplomacy.StandardMap()
+	gs := diplomacy.NewInitialState()
+	gs.Units = append(gs.Units, diplomacy.Unit{Type: diplomacy.Army, Power: diplomacy.Germany, Province: "bur"})
+	bur := []diplomacy.Unit{*gs.UnitAt("bur")}
+
+	parisScore := func() float64 {
+		for _, c := range hardScoreMoves(gs, diplomacy.Germany, bur, m, "aggressive") {
+			if c.target == "par" {
+				return c.score
+			}
+		}
+		t.Fatal("no candidate move to par")
+		return 0
+	}
+	before := parisScore()
+	gs.MercyPowers = []diplomacy.Power{diplomacy.France}
+	gs.MercyUntil = 1903
+	// Scores carry up to 0.5 of random noise.
+	if after := parisScore(); math.Abs(before-mercyPenalty-after) > 0.5 {
+		t.Errorf("expected the move to par to cost %v under mercy, got %v -> %v", mercyPenalty, before, after)
+	}
+}
+
+func TestMercifulOptions(t *testing.T) {
+	m := diplomacy.StandardMap()
+	gs := diplomacy.NewInitialState()
+	gs.MercyPowers = []diplomacy.Power{diplomacy.France}
+	gs.MercyUntil = 1902
+
+	attack := BookOption{Name: "attack", Weight: 1, Orders: []OrderInput{
+		{UnitType: "army", Location: "bur", OrderType: "move", Target: "par"},
+	}}
+	support := BookOption{Name: "support", Weight: 1, Orders: []OrderInput{
+		{UnitType: "army", Location: "mun", OrderType: "support", AuxLoc: "bur", AuxTarget: "mar", AuxUnitType: "army"},
+	}}
+	quiet := BookOption{Name: "quiet", Weight: 1, Orders: []OrderInput{
+		{UnitType: "army", Location: "bur", OrderType: "move", Target: "bel"},
+	}}
+	kept := mercifulOptions([]BookOption{attack, support, quiet}, gs, diplomacy.Germany, m)
+	if len(kept) != 1 || kept[0].Name != "quiet" {
+		t.Errorf("expected only the quiet line kept, got %+v", kept)
+	}
+}
+
+// TestMercyKeepsBotsOffSparedCenters plays each strategy that claims the
+// mercy capability against an undefended France and checks none of them
+// takes a spared French home center.
+func TestMercyKeepsBotsOffSparedCenters(t *testing.T) {
+	SeedBotRng(7)
+	defer ResetBotRng()
+	m := diplomacy.StandardMap()
+	for _, reg := range Strategies() {
+		if !reg.Capabilities.Mercy {
+			continue
+		}
+		t.Run(reg.Name, func(t *testing.T) {
+			gs := diplomacy.NewInitialState()
+			gs.Year, gs.Season = 1902, diplomacy.Fall
+			gs.Units = slices.DeleteFunc(gs.Units, func(u diplomacy.Unit) bool { return u.Power == diplomacy.France })
+			gs.Units = append(gs.Units,
+				diplomacy.Unit{Type: diplomacy.Army, Power: diplomacy.Germany, Province: "bur"},
+				diplomacy.Unit{Type: diplomacy.Army, Power: diplomacy.Germany, Province: "pic"},
+				diplomacy.Unit{Type: diplomacy.Army, Power: diplomacy.Germany, Province: "gas"},
+			)
+			gs.SupplyCenters["bel"] = diplomacy.Germany
+			gs.MercyPowers = []diplomacy.Power{diplomacy.France}
+			gs.MercyUntil = 1902
+
+			for _, o := range reg.New(nil).GenerateMovementOrders(gs, diplomacy.Germany, m) {
+				if attacksSpared([]OrderInput{o}, gs, diplomacy.Germany, m) {
+					t.Errorf("%s attacked a spared center: %+v", reg.Name, o)
+				}
+			}
+		})
+	}
+}
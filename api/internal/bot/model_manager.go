@@ -0,0 +1,353 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	gonnx "github.com/advancedclimatesystems/gonnx"
+)
+
+// DefaultModel is the model hard-gonnx uses unless given another. It is
+// loaded from GonnxModelPath unless added to the manager under this name.
+const DefaultModel = "default"
+
+// Model file names within a model directory.
+const (
+	policyModelFile = "policy_v2.onnx"
+	valueModelFile  = "value_v2.onnx"
+)
+
+// Models is the model manager the gonnx strategies load their models from.
+var Models = NewModelManager()
+
+// ModelStatus is a snapshot of one managed model.
+type ModelStatus struct {
+	Name      string     `json:"name"`
+	Path      string     `json:"path"`
+	Loaded    bool       `json:"loaded"`
+	Value     bool       `json:"value"` // value model available
+	LoadedAt  *time.Time `json:"loaded_at,omitempty"`
+	LastError string     `json:"last_error,omitempty"` // from the last failed load
+}
+
+// gonnxModels is one loaded policy and value model pair. It is never
+// modified once loaded, so any number of games can run inference on it.
+type gonnxModels struct {
+	policy *modelPool
+	value  *modelPool // nil if the directory has no value model
+}
+
+// modelPool runs one ONNX model from many goroutines. A gonnx model reshapes
+// its parameters while it runs, so each run borrows an instance of its own,
+// parsed from the model bytes when none is free.
+type modelPool struct {
+	data []byte
+	pool sync.Pool
+}
+
+// newModelPool parses data once to check it is a valid model.
+func newModelPool(data []byte) (*modelPool, error) {
+	model, err := gonnx.NewModelFromBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	p := &modelPool{data: data}
+	p.pool.Put(model)
+	return p, nil
+}
+
+// Run runs the model on inputs with an instance no other run is using.
+func (p *modelPool) Run(inputs gonnx.Tensors) (gonnx.Tensors, error) {
+	model, ok := p.pool.Get().(*gonnx.Model)
+	if !ok {
+		var err error
+		if model, err = gonnx.NewModelFromBytes(p.data); err != nil {
+			return nil, err
+		}
+	}
+	defer p.pool.Put(model)
+	return model.Run(inputs)
+}
+
+// managedModel tracks the models currently loaded for a name.
+type managedModel struct {
+	current *gonnxModels
+	modTime time.Time // newest model file modification when loaded
+	status  ModelStatus
+}
+
+// modelLoad is a load in progress that other callers of get wait for.
+type modelLoad struct {
+	done   chan struct{}
+	models *gonnxModels
+	err    error
+}
+
+// ModelManager loads named gonnx model directories and reloads them when
+// their files change or on request. Strategies look their model up on every
+// inference, so a reload takes effect for bots already playing; a failed
+// reload keeps the previous models.
+type ModelManager struct {
+	mu      sync.Mutex
+	dirs    map[string]string // name -> directory
+	models  map[string]*managedModel
+	loading map[string]*modelLoad // first loads in progress, by name
+
+	load func(dir string) (*gonnxModels, error)
+	now  func() time.Time
+}
+
+// NewModelManager creates an empty ModelManager.
+func NewModelManager() *ModelManager {
+	return &ModelManager{
+		dirs:    make(map[string]string),
+		models:  make(map[string]*managedModel),
+		loading: make(map[string]*modelLoad),
+		load:    loadGonnxModels,
+		now:     time.Now,
+	}
+}
+
+// Add registers the model directory dir under name. It is loaded on first
+// use; a model already loaded under name is replaced on the next reload.
+func (m *ModelManager) Add(name, dir string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.dirs[name] = dir
+}
+
+// AddSpec registers the models in a comma-separated spec of name=dir
+// entries, e.g. "ckpt-a=/models/a,ckpt-b=/models/b".
+func (m *ModelManager) AddSpec(spec string) error {
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, dir, ok := strings.Cut(entry, "=")
+		if !ok || name == "" || dir == "" {
+			return fmt.Errorf("model %q: want name=dir", entry)
+		}
+		m.Add(name, dir)
+	}
+	return nil
+}
+
+// dir returns the directory of the named model. Callers hold m.mu.
+func (m *ModelManager) dir(name string) (string, bool) {
+	if dir, ok := m.dirs[name]; ok {
+		return dir, true
+	}
+	if name != DefaultModel {
+		return "", false
+	}
+	if GonnxModelPath != "" {
+		return GonnxModelPath, true
+	}
+	return "engine/models", true
+}
+
+// get returns the named models, loading them on first use or when their
+// directory has changed. Models are read without holding the lock, once
+// however many games ask for them meanwhile.
+func (m *ModelManager) get(name string) (*gonnxModels, error) {
+	m.mu.Lock()
+	dir, ok := m.dir(name)
+	if !ok {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("unknown model %q", name)
+	}
+	if mm, ok := m.models[name]; ok && mm.current != nil && mm.status.Path == dir {
+		m.mu.Unlock()
+		return mm.current, nil
+	}
+	if l, ok := m.loading[name]; ok {
+		m.mu.Unlock()
+		<-l.done
+		return l.models, l.err
+	}
+	l := &modelLoad{done: make(chan struct{})}
+	m.loading[name] = l
+	m.mu.Unlock()
+
+	modTime := modelModTime(dir)
+	l.models, l.err = m.load(dir)
+
+	m.mu.Lock()
+	m.record(name, dir, l.models, modTime, l.err)
+	delete(m.loading, name)
+	m.mu.Unlock()
+	close(l.done)
+	if l.err != nil {
+		return nil, l.err
+	}
+	return l.models, nil
+}
+
+// record stores the outcome of loading name. Callers hold m.mu.
+func (m *ModelManager) record(name, dir string, models *gonnxModels, modTime time.Time, err error) {
+	mm, ok := m.models[name]
+	if !ok {
+		mm = &managedModel{}
+		m.models[name] = mm
+	}
+	mm.status.Name = name
+	mm.status.Path = dir
+	if err != nil {
+		mm.status.LastError = err.Error()
+		return
+	}
+	now := m.now()
+	mm.current = models
+	mm.modTime = modTime
+	mm.status.Loaded = true
+	mm.status.Value = models.value != nil
+	mm.status.LoadedAt = &now
+	mm.status.LastError = ""
+}
+
+// reload loads name afresh from its directory and swaps it in. The models
+// are read without holding the lock so inference carries on meanwhile.
+func (m *ModelManager) reload(name string) error {
+	m.mu.Lock()
+	dir, ok := m.dir(name)
+	m.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("unknown model %q", name)
+	}
+	modTime := modelModTime(dir)
+	models, err := m.load(dir)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.record(name, dir, models, modTime, err)
+	return err
+}
+
+// names returns every registered or loaded model name, sorted.
+func (m *ModelManager) names() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	set := make(map[string]bool, len(m.dirs)+len(m.models))
+	for name := range m.dirs {
+		set[name] = true
+	}
+	for name := range m.models {
+		set[name] = true
+	}
+	out := make([]string, 0, len(set))
+	for name := range set {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Reload reloads every registered or previously loaded model and reports
+// the result. Models that fail to load keep serving their previous version.
+func (m *ModelManager) Reload() []ModelStatus {
+	for _, name := range m.names() {
+		if err := m.reload(name); err != nil {
+			log.Printf("bot/gonnx: reload of model %s failed: %v", name, err)
+		}
+	}
+	return m.Statuses()
+}
+
+// Watch reloads loaded models whose files change, checking every interval
+// until ctx is done.
+func (m *ModelManager) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, name := range m.changed() {
+				if err := m.reload(name); err != nil {
+					log.Printf("bot/gonnx: reload of changed model %s failed: %v", name, err)
+				} else {
+					log.Printf("bot/gonnx: reloaded changed model %s", name)
+				}
+			}
+		}
+	}
+}
+
+// changed returns the loaded models whose files are newer than when they
+// were loaded.
+func (m *ModelManager) changed() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []string
+	for name, mm := range m.models {
+		if mm.current == nil {
+			continue
+		}
+		if modelModTime(mm.status.Path).After(mm.modTime) {
+			out = append(out, name)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Statuses reports every registered or loaded model, sorted by name.
+func (m *ModelManager) Statuses() []ModelStatus {
+	names := m.names()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]ModelStatus, 0, len(names))
+	for _, name := range names {
+		if mm, ok := m.models[name]; ok {
+			out = append(out, mm.status)
+			continue
+		}
+		dir, _ := m.dir(name)
+		out = append(out, ModelStatus{Name: name, Path: dir})
+	}
+	return out
+}
+
+// modelModTime returns the newest modification time of the model files in
+// dir, or the zero time if there are none.
+func modelModTime(dir string) time.Time {
+	var newest time.Time
+	for _, file := range []string{policyModelFile, valueModelFile} {
+		if info, err := os.Stat(filepath.Join(dir, file)); err == nil && info.ModTime().After(newest) {
+			newest = info.ModTime()
+		}
+	}
+	return newest
+}
+
+// loadGonnxModels loads the policy model in dir, and the value model if
+// there is one.
+func loadGonnxModels(dir string) (*gonnxModels, error) {
+	policy, err := loadModelPool(filepath.Join(dir, policyModelFile))
+	if err != nil {
+		return nil, err
+	}
+	valuePath := filepath.Join(dir, valueModelFile)
+	value, err := loadModelPool(valuePath)
+	if err != nil {
+		log.Printf("bot/gonnx: value model not found at %s: %v (value eval disabled)", valuePath, err)
+	}
+	return &gonnxModels{policy: policy, value: value}, nil
+}
+
+// loadModelPool reads the model file at path.
+func loadModelPool(path string) (*modelPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return newModelPool(data)
+}
@@ -0,0 +1,173 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stubModelManager returns a manager whose loads succeed with fresh empty
+// models unless fail is set, counting loads per directory.
+func stubModelManager(fail *bool) (*ModelManager, map[string]int) {
+	loads := make(map[string]int)
+	m := NewModelManager()
+	m.load = func(dir string) (*gonnxModels, error) {
+		loads[dir]++
+		if *fail {
+			return nil, errors.New("corrupt model")
+		}
+		return &gonnxModels{}, nil
+	}
+	return m, loads
+}
+
+func TestModelManagerAddSpec(t *testing.T) {
+	m := NewModelManager()
+	if err := m.AddSpec("a=/models/a, b=/models/b"); err != nil {
+		t.Fatal(err)
+	}
+	statuses := m.Statuses()
+	if len(statuses) != 2 || statuses[0].Name != "a" || statuses[1].Path != "/models/b" {
+		t.Errorf("unexpected statuses %+v", statuses)
+	}
+	if statuses[0].Loaded {
+		t.Error("models should load on first use, not when added")
+	}
+	for _, bad := range []string{"a", "=/models/a", "a="} {
+		if err := m.AddSpec(bad); err == nil {
+			t.Errorf("AddSpec(%q) should fail", bad)
+		}
+	}
+}
+
+func TestModelManagerGet(t *testing.T) {
+	fail := false
+	m, loads := stubModelManager(&fail)
+	m.Add("a", "/models/a")
+
+	first, err := m.get("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _ := m.get("a")
+	if first != again || loads["/models/a"] != 1 {
+		t.Errorf("expected the model loaded once, loaded %d times", loads["/models/a"])
+	}
+	if _, err := m.get("missing"); err == nil {
+		t.Error("expected an error for an unknown model")
+	}
+
+	orig := GonnxModelPath
+	defer func() { GonnxModelPath = orig }()
+	GonnxModelPath = "/models/default"
+	if _, err := m.get(DefaultModel); err != nil || loads["/models/default"] != 1 {
+		t.Errorf("expected the default model loaded from GonnxModelPath, err %v", err)
+	}
+}
+
+func TestModelManagerGetLoadsOnceOutsideLock(t *testing.T) {
+	m := NewModelManager()
+	m.Add("a", "/models/a")
+	m.Add("b", "/models/b")
+	release := make(chan struct{})
+	var loads atomic.Int32
+	m.load = func(dir string) (*gonnxModels, error) {
+		loads.Add(1)
+		if dir == "/models/a" {
+			<-release
+		}
+		return &gonnxModels{}, nil
+	}
+
+	var wg sync.WaitGroup
+	got := make([]*gonnxModels, 4)
+	for i := range got {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got[i], _ = m.get("a")
+		}()
+	}
+	// Another model loads while a is still being read.
+	if _, err := m.get("b"); err != nil {
+		t.Fatal(err)
+	}
+	close(release)
+	wg.Wait()
+	for _, models := range got {
+		if models == nil || models != got[0] {
+			t.Fatal("expected every caller to get the same models")
+		}
+	}
+	if n := loads.Load(); n != 2 {
+		t.Errorf("expected one load per model, got %d", n)
+	}
+}
+
+func TestModelManagerReloadKeepsModelOnFailure(t *testing.T) {
+	fail := false
+	m, _ := stubModelManager(&fail)
+	m.Add("a", "/models/a")
+	s, err := newGonnxStrategyFor(m, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := s.current()
+
+	m.Reload()
+	if s.current() == old {
+		t.Error("expected the strategy to pick up the reloaded model")
+	}
+
+	reloaded := s.current()
+	fail = true
+	statuses := m.Reload()
+	if s.current() != reloaded {
+		t.Error("a failed reload should keep the previous model")
+	}
+	if !statuses[0].Loaded || statuses[0].LastError != "corrupt model" {
+		t.Errorf("expected the failure reported on a loaded model, got %+v", statuses[0])
+	}
+}
+
+func TestModelManagerWatch(t *testing.T) {
+	dir := t.TempDir()
+	policy := filepath.Join(dir, policyModelFile)
+	if err := os.WriteFile(policy, []byte("v1"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fail := false
+	m, loads := stubModelManager(&fail)
+	m.Add("a", dir)
+	if _, err := m.get("a"); err != nil {
+		t.Fatal(err)
+	}
+	if changed := m.changed(); len(changed) != 0 {
+		t.Fatalf("nothing changed yet, got %v", changed)
+	}
+
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(policy, later, later); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		m.Watch(ctx, 5*time.Millisecond)
+		close(done)
+	}()
+	deadline := time.Now().Add(2 * time.Second)
+	for len(m.changed()) > 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	<-done
+	if loads[dir] != 2 {
+		t.Errorf("expected the changed model reloaded once, loaded %d times", loads[dir])
+	}
+}
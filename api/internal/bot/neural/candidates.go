@@ -675,7 +675,7 @@ func GenerateCandidates(
 		coordinateCandidateSupports(candidates[ci], perUnit, provs, power)
 	}
 
-	return candidates
+	return ensureDiversity(power, perUnit, provs, count, rng, candidates, seen)
 }
 
 // injectCoordinatedCandidates injects candidates that pair support orders with
@@ -1035,5 +1035,5 @@ func GenerateCandidatesNeural(
 		coordinateCandidateSupports(candidates[ci], blendedAsScored, blendedProvs, power)
 	}
 
-	return candidates
+	return ensureDiversity(power, blendedAsScored, blendedProvs, count, rng, candidates, seen)
 }
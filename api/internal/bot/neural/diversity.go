@@ -0,0 +1,89 @@
+package neural
+
+import (
+	"math/rand"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// Diversity records how many distinct candidate sets a generator kept out of
+// how many it was asked for.
+type Diversity struct {
+	Requested int
+	Unique    int
+}
+
+// Ratio returns the fraction of requested candidates that were distinct.
+func (d Diversity) Ratio() float64 {
+	if d.Requested == 0 {
+		return 1
+	}
+	return float64(d.Unique) / float64(d.Requested)
+}
+
+// Degenerate reports whether every generated candidate was the same order set,
+// leaving regret matching nothing to choose between.
+func (d Diversity) Degenerate() bool {
+	return d.Requested > 1 && d.Unique <= 1
+}
+
+// MinDiverseCandidates is the fewest distinct candidates worth searching over
+// when count were requested. Below it, generators force perturbations.
+func MinDiverseCandidates(count int) int {
+	return min(count, max(2, count/4))
+}
+
+// ensureDiversity tops up a low-diversity candidate list with forced
+// perturbations of the first (greedy) candidate, swapping one to three units
+// to a random alternative from perUnit. It logs the diversity achieved and
+// returns the possibly extended list.
+func ensureDiversity(
+	power diplomacy.Power,
+	perUnit [][]scoredCandidate,
+	unitProvs []string,
+	count int,
+	rng *rand.Rand,
+	candidates [][]CandidateOrder,
+	seen [][]CandidateOrder,
+) [][]CandidateOrder {
+	before := Diversity{Requested: count, Unique: len(candidates)}
+	target := MinDiverseCandidates(count)
+	if len(candidates) == 0 || len(candidates) >= target {
+		return candidates
+	}
+
+	base := candidates[0]
+	for range count * 3 {
+		if len(candidates) >= target {
+			break
+		}
+		cand := copyCandidates(base)
+		swaps := 1 + rng.Intn(min(3, len(cand)))
+		for _, ui := range rng.Perm(len(cand))[:swaps] {
+			if ui >= len(perUnit) || len(perUnit[ui]) < 2 {
+				continue
+			}
+			alt := perUnit[ui][rng.Intn(len(perUnit[ui]))].order
+			cand[ui] = CandidateOrder{Order: alt, Power: power}
+		}
+		coordinateCandidateSupports(cand, perUnit, unitProvs, power)
+		if !containsCandidateSet(seen, cand) {
+			seen = append(seen, copyCandidates(cand))
+			candidates = append(candidates, cand)
+		}
+	}
+
+	after := Diversity{Requested: count, Unique: len(candidates)}
+	ev := log.Debug()
+	if before.Degenerate() {
+		ev = log.Warn()
+	}
+	ev.Str("power", string(power)).
+		Int("requested", count).
+		Int("unique", before.Unique).
+		Int("uniqueAfterPerturbation", after.Unique).
+		Msg("Low candidate diversity; forced perturbations")
+	return candidates
+}
@@ -0,0 +1,68 @@
+package neural
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+func TestDiversity(t *testing.T) {
+	d := Diversity{Requested: 16, Unique: 1}
+	if !d.Degenerate() {
+		t.Error("expected 1 of 16 to be degenerate")
+	}
+	if d.Ratio() != 1.0/16 {
+		t.Errorf("Ratio() = %v, want %v", d.Ratio(), 1.0/16)
+	}
+	if (Diversity{Requested: 1, Unique: 1}).Degenerate() {
+		t.Error("a single requested candidate is not degenerate")
+	}
+	if (Diversity{}).Ratio() != 1 {
+		t.Error("empty diversity should have ratio 1")
+	}
+}
+
+func TestEnsureDiversity_PerturbsSingleCandidate(t *testing.T) {
+	gs := diplomacy.NewInitialState()
+	m := diplomacy.StandardMap()
+	rng := rand.New(rand.NewSource(7))
+	power := diplomacy.Russia
+
+	perUnit := TopKPerUnit(power, gs, m, 5)
+	provs := getUnitProvinces(perUnit)
+	greedy := dedupGreedyOrders(perUnit, power)
+	cands := [][]CandidateOrder{greedy}
+	seen := [][]CandidateOrder{copyCandidates(greedy)}
+
+	got := ensureDiversity(power, perUnit, provs, 16, rng, cands, seen)
+	if want := MinDiverseCandidates(16); len(got) < want {
+		t.Fatalf("got %d candidates, want at least %d", len(got), want)
+	}
+	if !candidateOrdersEqual(got[0], greedy) {
+		t.Error("greedy candidate should stay first")
+	}
+	for i := range got {
+		if len(got[i]) != len(greedy) {
+			t.Errorf("candidate %d: %d orders, want %d", i, len(got[i]), len(greedy))
+		}
+		for j := i + 1; j < len(got); j++ {
+			if candidateOrdersEqual(got[i], got[j]) {
+				t.Errorf("candidates %d and %d are duplicates", i, j)
+			}
+		}
+	}
+}
+
+func TestEnsureDiversity_LeavesDiverseSetAlone(t *testing.T) {
+	gs := diplomacy.NewInitialState()
+	m := diplomacy.StandardMap()
+	rng := rand.New(rand.NewSource(42))
+
+	cands := GenerateCandidates(diplomacy.Austria, gs, m, 16, rng)
+	perUnit := TopKPerUnit(diplomacy.Austria, gs, m, 5)
+	got := ensureDiversity(diplomacy.Austria, perUnit, getUnitProvinces(perUnit), 16, rng, cands, nil)
+	if len(got) != len(cands) {
+		t.Errorf("expected %d candidates unchanged, got %d", len(cands), len(got))
+	}
+}
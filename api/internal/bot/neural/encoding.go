@@ -280,10 +280,9 @@ func isSupplyCenter(provID string, m *diplomacy.DiplomacyMap) bool {
 // encodeBuildDisband sets build/disband flags during adjustment phases.
 func encodeBuildDisband(tensor []float32, gs *diplomacy.GameState, m *diplomacy.DiplomacyMap) {
 	for _, power := range diplomacy.AllPowers() {
-		numUnits := gs.UnitCount(power)
-		numSCs := gs.SupplyCenterCount(power)
+		delta := gs.AdjustmentDelta(power)
 
-		if numSCs > numUnits {
+		if delta > 0 {
 			// Can build on owned home centers that are unoccupied.
 			homes := diplomacy.HomeCenters(power)
 			for _, h := range homes {
@@ -294,7 +293,7 @@ func encodeBuildDisband(tensor []float32, gs *diplomacy.GameState, m *diplomacy.
 					}
 				}
 			}
-		} else if numUnits > numSCs {
+		} else if delta < 0 {
 			// Must disband: mark all of this power's units.
 			for i := range gs.Units {
 				u := &gs.Units[i]
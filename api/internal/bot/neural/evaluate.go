@@ -224,6 +224,12 @@ func unoccupiedHomeSCCount(power diplomacy.Power, gs *diplomacy.GameState, m *di
 // Position evaluation
 // ---------------------------------------------------------------------------
 
+// scThreshold converts a center count written for the standard 18-center
+// solo into one the same distance from gs's victory threshold.
+func scThreshold(gs *diplomacy.GameState, standard int) int {
+	return standard - 18 + gs.VictoryCenters()
+}
+
 // Evaluate scores a board position for the given power. Returns a score in
 // centipawn-like units. Ported from Rust evaluate() in engine/src/eval/heuristic.rs.
 func Evaluate(power diplomacy.Power, gs *diplomacy.GameState, m *diplomacy.DiplomacyMap) float64 {
@@ -232,17 +238,17 @@ func Evaluate(power diplomacy.Power, gs *diplomacy.GameState, m *diplomacy.Diplo
 	ownSCs := gs.SupplyCenterCount(power)
 	score += 10.0 * float64(ownSCs)
 
-	if ownSCs > 10 {
-		bonus := float64(ownSCs - 10)
+	if base := scThreshold(gs, 10); ownSCs > base {
+		bonus := float64(ownSCs - base)
 		score += bonus * bonus * 2.0
 	}
 
-	if ownSCs >= 18 {
+	if ownSCs >= gs.VictoryCenters() {
 		score += 500.0
 	}
 
 	pendingBonus := 8.0
-	if gs.Season == diplomacy.Fall {
+	if gs.IsLastSeason() {
 		pendingBonus = 12.0
 	}
 
@@ -281,9 +287,9 @@ func Evaluate(power diplomacy.Power, gs *diplomacy.GameState, m *diplomacy.Diplo
 		defense := provinceDefense(id, power, gs, m)
 		if threat > defense {
 			penalty := 2.0 * float64(threat-defense)
-			if ownSCs >= 16 {
+			if ownSCs >= scThreshold(gs, 16) {
 				penalty *= 0.2
-			} else if ownSCs >= 14 {
+			} else if ownSCs >= scThreshold(gs, 14) {
 				penalty *= 0.5
 			}
 			score -= penalty
@@ -414,18 +420,18 @@ func RmEvaluate(power diplomacy.Power, gs *diplomacy.GameState, m *diplomacy.Dip
 		}
 	}
 
-	// Solo threat penalty for enemies near 18 SCs.
+	// Solo threat penalty for enemies near a solo.
 	soloPenalty := 0.0
 	for _, p := range diplomacy.AllPowers() {
 		if p == power {
 			continue
 		}
 		sc := gs.SupplyCenterCount(p)
-		if sc >= 16 {
+		if sc >= scThreshold(gs, 16) {
 			soloPenalty += 20.0
-		} else if sc >= 14 {
+		} else if sc >= scThreshold(gs, 14) {
 			soloPenalty += 10.0
-		} else if sc >= 12 {
+		} else if sc >= scThreshold(gs, 12) {
 			soloPenalty += 4.0
 		}
 	}
@@ -468,12 +474,10 @@ func scoreHold(order diplomacy.Order, gs *diplomacy.GameState, power diplomacy.P
 	}
 	score -= 1.0
 
-	// Fall penalty: holding on a home SC when we need builds blocks construction.
-	if gs.Season == diplomacy.Fall && prov != nil && prov.IsSupplyCenter &&
+	// Last-season (Fall) penalty: holding on a home SC when we need builds blocks construction.
+	if gs.IsLastSeason() && prov != nil && prov.IsSupplyCenter &&
 		prov.HomePower == power && gs.SupplyCenters[order.Location] == power {
-		scCount := gs.SupplyCenterCount(power)
-		unitCount := gs.UnitCount(power)
-		pendingBuilds := scCount - unitCount
+		pendingBuilds := gs.AdjustmentDelta(power)
 		if pendingBuilds > 0 {
 			freeHomes := unoccupiedHomeSCCount(power, gs, m)
 			if freeHomes < pendingBuilds {
@@ -509,18 +513,16 @@ func scoreMove(order diplomacy.Order, gs *diplomacy.GameState, power diplomacy.P
 		}
 	}
 
-	// Fall penalty for leaving an unowned SC you occupy.
-	if gs.Season == diplomacy.Fall && srcProv != nil && srcProv.IsSupplyCenter &&
+	// Last-season (Fall) penalty for leaving an unowned SC you occupy.
+	if gs.IsLastSeason() && srcProv != nil && srcProv.IsSupplyCenter &&
 		gs.SupplyCenters[src] != power {
 		score -= 12.0
 	}
 
-	// Fall home SC vacating bonus.
-	if gs.Season == diplomacy.Fall && srcProv != nil && srcProv.IsSupplyCenter &&
+	// Last-season (Fall) home SC vacating bonus.
+	if gs.IsLastSeason() && srcProv != nil && srcProv.IsSupplyCenter &&
 		srcProv.HomePower == power && gs.SupplyCenters[src] == power {
-		scCount := gs.SupplyCenterCount(power)
-		unitCount := gs.UnitCount(power)
-		pendingBuilds := scCount - unitCount
+		pendingBuilds := gs.AdjustmentDelta(power)
 		if pendingBuilds > 0 {
 			freeHomes := unoccupiedHomeSCCount(power, gs, m)
 			if freeHomes < pendingBuilds {
@@ -553,8 +555,8 @@ func scoreMove(order diplomacy.Order, gs *diplomacy.GameState, power diplomacy.P
 		score += 3.0 / float32(dist)
 	}
 
-	// Spring positioning bonus.
-	if gs.Season == diplomacy.Spring && dstProv != nil && dstProv.IsSupplyCenter {
+	// Positioning bonus in the earlier seasons (Spring).
+	if !gs.IsLastSeason() && dstProv != nil && dstProv.IsSupplyCenter {
 		if gs.SupplyCenters[dst] != power {
 			score += 4.0
 		}
@@ -308,10 +308,7 @@ func heuristicRetreatOrders(gs *diplomacy.GameState, power diplomacy.Power, m *d
 		var options []retreatOption
 
 		for _, target := range adj {
-			if target == d.AttackerFrom {
-				continue
-			}
-			if gs.UnitAt(target) != nil {
+			if !diplomacy.CanRetreatTo(gs, d, target) {
 				continue
 			}
 			prov := m.Provinces[target]
@@ -395,9 +392,7 @@ func heuristicRetreatOrders(gs *diplomacy.GameState, power diplomacy.Power, m *d
 // Builds on home SCs closest to frontline (nearest unowned SC).
 // Disbands units farthest from action.
 func heuristicBuildOrders(gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap) []diplomacy.BuildOrder {
-	scCount := gs.SupplyCenterCount(power)
-	unitCount := gs.UnitCount(power)
-	diff := scCount - unitCount
+	diff := gs.AdjustmentDelta(power)
 
 	if diff > 0 {
 		return heuristicBuilds(gs, power, m, diff)
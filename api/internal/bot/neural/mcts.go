@@ -0,0 +1,302 @@
+package neural
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// MCTS search constants.
+const (
+	MCTSExploration = 1.4 // PUCT exploration constant
+	MCTSMaxDepth    = 3   // movement phases in the tree, counting the root
+	mctsChildCands  = 8   // candidates per power below the root
+)
+
+// mctsPower is one power's decoupled statistics at a tree node: its
+// candidate order sets and how each has fared when chosen.
+type mctsPower struct {
+	power      diplomacy.Power
+	candidates [][]CandidateOrder
+	prior      []float64 // sums to 1
+	visits     []int
+	value      []float64 // sum of returns
+	penalty    []float64 // subtracted from returns, e.g. cooperation penalties
+}
+
+// mctsNode is a movement phase in the search tree. Every power picks a
+// candidate independently; each joint choice leads to its own child, keyed
+// by the choices packed a byte per power.
+type mctsNode struct {
+	state    *diplomacy.GameState
+	powers   []mctsPower // nil until expanded
+	children map[uint64]*mctsNode
+	visits   int
+}
+
+// mctsSearch holds what a single MCTSSearch call shares between simulations.
+type mctsSearch struct {
+	power       diplomacy.Power
+	m           *diplomacy.DiplomacyMap
+	valueScores *[4]float32
+	startYear   int
+	rng         *rand.Rand
+	cache       *GreedyOrderCache
+	resolver    *diplomacy.Resolver
+	nodes       uint64
+}
+
+// MCTSSearch runs decoupled Monte Carlo Tree Search over the joint order
+// space of all alive powers until deadline or until ctx is done, and returns
+// the orders most visited for power.
+//
+// Each node is a movement phase. Every power selects one of its candidate
+// order sets with PUCT, independently of the others; the joint choice is
+// adjudicated to reach a child node, and leaves are scored for every power
+// after a short greedy rollout. Below the root, candidates are generated
+// heuristically. When policyLogits is non-nil, root candidates blend neural
+// and heuristic scores and the policy is the prior for power's candidates;
+// strength (1-100) sets the neural weight as in RegretMatchingSearch.
+// valueScores, if non-nil, blend into power's evaluation.
+func MCTSSearch(
+	ctx context.Context,
+	power diplomacy.Power,
+	gs *diplomacy.GameState,
+	m *diplomacy.DiplomacyMap,
+	deadline time.Time,
+	policyLogits []float32,
+	valueScores *[4]float32,
+	strength int,
+) SearchResult {
+	start := time.Now()
+	s := &mctsSearch{
+		power:       power,
+		m:           m,
+		valueScores: valueScores,
+		startYear:   gs.Year,
+		rng:         rand.New(rand.NewSource(start.UnixNano())),
+		cache:       NewGreedyOrderCache(),
+		resolver:    diplomacy.NewResolver(34),
+	}
+	neuralWeight := min(max(float32(strength)/100.0, 0), 1)
+
+	root := &mctsNode{state: gs}
+	s.expandRoot(root, policyLogits, neuralWeight)
+	ours := root.powerIndex(power)
+	if ours < 0 {
+		return SearchResult{}
+	}
+	if len(root.powers[ours].candidates) == 1 {
+		return SearchResult{Orders: candidateOrders(root.powers[ours].candidates[0]), Nodes: 1}
+	}
+
+	// If time runs out before the first simulation, the first candidate is
+	// played.
+	var simulations uint64
+	for ctx.Err() == nil && time.Now().Before(deadline) {
+		s.simulate(root, 1)
+		simulations++
+	}
+
+	best := root.powers[ours]
+	bestIdx := 0
+	for i, n := range best.visits {
+		if n > best.visits[bestIdx] {
+			bestIdx = i
+		}
+	}
+	return SearchResult{
+		Orders:     candidateOrders(best.candidates[bestIdx]),
+		Score:      evaluateBlended(power, gs, m, valueScores),
+		Nodes:      s.nodes,
+		Iterations: simulations,
+	}
+}
+
+// expandRoot generates the root's candidates the way RegretMatchingSearch
+// does, with the policy as the prior for the searching power.
+func (s *mctsSearch) expandRoot(root *mctsNode, policyLogits []float32, neuralWeight float32) {
+	gs := root.state
+	for _, p := range diplomacy.AllPowers() {
+		n := gs.UnitCount(p)
+		if n == 0 {
+			continue
+		}
+		var cands [][]CandidateOrder
+		if policyLogits != nil {
+			cands = GenerateCandidatesNeural(p, gs, s.m, numCandidates(n), neuralWeight, policyLogits, s.rng)
+		} else {
+			cands = GenerateCandidates(p, gs, s.m, numCandidates(n), s.rng)
+		}
+		if len(cands) == 0 {
+			continue
+		}
+		mp := newMCTSPower(p, cands)
+		if p == s.power {
+			if init := policyGuidedInit(policyLogits, p, gs, s.m, cands); len(init) == len(cands) {
+				total := 0.0
+				for _, w := range init {
+					total += w
+				}
+				for i, w := range init {
+					mp.prior[i] = w / total
+				}
+			}
+			for i, cand := range cands {
+				mp.penalty[i] = CooperationPenalty(candidateOrders(cand), gs, p)
+			}
+		}
+		root.powers = append(root.powers, mp)
+	}
+	root.children = make(map[uint64]*mctsNode)
+}
+
+// expand generates heuristic candidates for every power at a node below the
+// root.
+func (s *mctsSearch) expand(node *mctsNode) {
+	for _, p := range diplomacy.AllPowers() {
+		if node.state.UnitCount(p) == 0 {
+			continue
+		}
+		if cands := GenerateCandidates(p, node.state, s.m, mctsChildCands, s.rng); len(cands) > 0 {
+			node.powers = append(node.powers, newMCTSPower(p, cands))
+		}
+	}
+	node.children = make(map[uint64]*mctsNode)
+}
+
+// newMCTSPower creates unvisited statistics with a uniform prior.
+func newMCTSPower(p diplomacy.Power, cands [][]CandidateOrder) mctsPower {
+	k := len(cands)
+	mp := mctsPower{
+		power:      p,
+		candidates: cands,
+		prior:      make([]float64, k),
+		visits:     make([]int, k),
+		value:      make([]float64, k),
+		penalty:    make([]float64, k),
+	}
+	for i := range mp.prior {
+		mp.prior[i] = 1 / float64(k)
+	}
+	return mp
+}
+
+// powerIndex returns the position of p among the node's powers, or -1.
+func (n *mctsNode) powerIndex(p diplomacy.Power) int {
+	for i, mp := range n.powers {
+		if mp.power == p {
+			return i
+		}
+	}
+	return -1
+}
+
+// simulate runs one selection, expansion, evaluation and backup pass from
+// node and returns the value of the outcome for every power, indexed by
+// PowerIndex.
+func (s *mctsSearch) simulate(node *mctsNode, depth int) [NumPowers + 1]float64 {
+	if node.powers == nil {
+		if node.visits > 0 && depth <= MCTSMaxDepth && node.state.Year <= s.startYear+2 {
+			s.expand(node)
+		}
+		if len(node.powers) == 0 {
+			node.visits++
+			return s.evaluateLeaf(node.state)
+		}
+	}
+
+	choice := make([]int, len(node.powers))
+	var key uint64
+	for i := range node.powers {
+		choice[i] = node.powers[i].selectPUCT(node.visits)
+		key = key<<8 | uint64(choice[i])
+	}
+	child, ok := node.children[key]
+	if !ok {
+		child = &mctsNode{state: s.advance(node, choice)}
+		node.children[key] = child
+	}
+
+	values := s.simulate(child, depth+1)
+	node.visits++
+	for i := range node.powers {
+		mp := &node.powers[i]
+		c := choice[i]
+		mp.visits[c]++
+		mp.value[c] += values[PowerIndex(mp.power)] - mp.penalty[c]
+	}
+	return values
+}
+
+// selectPUCT picks the candidate maximizing mean value plus a prior-weighted
+// exploration bonus. Values are rescaled to the range seen at this node so
+// the exploration constant doesn't depend on the evaluation's scale.
+func (mp *mctsPower) selectPUCT(parentVisits int) int {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for i, n := range mp.visits {
+		if n > 0 {
+			q := mp.value[i] / float64(n)
+			lo, hi = min(lo, q), max(hi, q)
+		}
+	}
+	spread := hi - lo
+	sqrtN := math.Sqrt(float64(parentVisits) + 1)
+
+	best, bestScore := 0, math.Inf(-1)
+	for i, n := range mp.visits {
+		q := 0.5 // unvisited candidates count as average
+		if n > 0 && spread > 0 {
+			q = (mp.value[i]/float64(n) - lo) / spread
+		}
+		score := q + MCTSExploration*mp.prior[i]*sqrtN/float64(1+n)
+		if score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	return best
+}
+
+// advance adjudicates a joint choice at node and plays any retreat and
+// build phases that follow with simple heuristics, returning the next
+// movement phase.
+func (s *mctsSearch) advance(node *mctsNode, choice []int) *diplomacy.GameState {
+	orders := make([]diplomacy.Order, 0, 34)
+	for i, mp := range node.powers {
+		for _, co := range mp.candidates[choice[i]] {
+			orders = append(orders, co.Order)
+		}
+	}
+	next := node.state.Clone()
+	s.resolver.Resolve(orders, next, s.m)
+	s.resolver.Apply(next, s.m)
+	diplomacy.AdvanceState(next, len(next.Dislodged) > 0)
+	s.nodes++
+
+	for next.Phase != diplomacy.PhaseMovement {
+		phase := next.Phase
+		next = SimulateNPhases(next, s.m, 1, next.Year, s.cache)
+		if next.Phase == phase {
+			break
+		}
+	}
+	return next
+}
+
+// evaluateLeaf scores a position for every power after a greedy rollout.
+func (s *mctsSearch) evaluateLeaf(gs *diplomacy.GameState) [NumPowers + 1]float64 {
+	future := SimulateNPhases(gs, s.m, LookaheadDepth, s.startYear, s.cache)
+	s.nodes++
+	var values [NumPowers + 1]float64
+	for _, p := range diplomacy.AllPowers() {
+		if p == s.power {
+			values[PowerIndex(p)] = evaluateBlended(p, future, s.m, s.valueScores)
+		} else {
+			values[PowerIndex(p)] = RmEvaluate(p, future, s.m)
+		}
+	}
+	return values
+}
@@ -0,0 +1,83 @@
+package neural
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+func TestMCTSSearch_InitialPosition(t *testing.T) {
+	gs := diplomacy.NewInitialState()
+	m := diplomacy.StandardMap()
+
+	result := MCTSSearch(context.Background(), diplomacy.France, gs, m, time.Now().Add(200*time.Millisecond), nil, nil, 80)
+	if len(result.Orders) != 3 {
+		t.Fatalf("expected an order for each of France's 3 units, got %d", len(result.Orders))
+	}
+	for _, o := range result.Orders {
+		if o.Power != diplomacy.France {
+			t.Errorf("expected only French orders, got %+v", o)
+		}
+		if err := diplomacy.ValidateOrder(o, gs, m); err != nil {
+			t.Errorf("illegal order: %v", err)
+		}
+	}
+	if result.Iterations == 0 {
+		t.Error("expected some simulations")
+	}
+}
+
+func TestMCTSSearch_StopsAtDeadlineAndContext(t *testing.T) {
+	gs := diplomacy.NewInitialState()
+	m := diplomacy.StandardMap()
+
+	start := time.Now()
+	result := MCTSSearch(context.Background(), diplomacy.France, gs, m, start.Add(50*time.Millisecond), nil, nil, 80)
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("expected the search to stop near its deadline, took %v", elapsed)
+	}
+	if len(result.Orders) != 3 {
+		t.Errorf("expected orders for France's 3 units, got %d", len(result.Orders))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result = MCTSSearch(ctx, diplomacy.France, gs, m, time.Now().Add(time.Minute), nil, nil, 80)
+	if result.Iterations != 0 || len(result.Orders) != 3 {
+		t.Errorf("expected no simulations but orders once ctx is done, got %d simulations and %d orders", result.Iterations, len(result.Orders))
+	}
+}
+
+func TestMCTSSearch_NoUnits(t *testing.T) {
+	gs := diplomacy.NewInitialState()
+	var units []diplomacy.Unit
+	for _, u := range gs.Units {
+		if u.Power != diplomacy.Italy {
+			units = append(units, u)
+		}
+	}
+	gs.Units = units
+
+	if result := MCTSSearch(context.Background(), diplomacy.Italy, gs, diplomacy.StandardMap(), time.Now().Add(50*time.Millisecond), nil, nil, 80); len(result.Orders) != 0 {
+		t.Errorf("expected no orders without units, got %+v", result.Orders)
+	}
+}
+
+func TestSelectPUCT(t *testing.T) {
+	mp := newMCTSPower(diplomacy.France, make([][]CandidateOrder, 3))
+
+	// Unvisited candidates are tried in prior order.
+	mp.prior = []float64{0.2, 0.7, 0.1}
+	if got := mp.selectPUCT(0); got != 1 {
+		t.Errorf("expected the highest prior first, got %d", got)
+	}
+
+	// With enough visits the best mean value wins.
+	mp.visits = []int{50, 50, 50}
+	mp.value = []float64{50, 10, 25}
+	if got := mp.selectPUCT(150); got != 0 {
+		t.Errorf("expected the best candidate, got %d", got)
+	}
+}
@@ -283,10 +283,7 @@ func DecodeRetreatLogits(
 		}
 
 		for _, target := range adj {
-			if target == d.AttackerFrom {
-				continue
-			}
-			if gs.UnitAt(target) != nil {
+			if !diplomacy.CanRetreatTo(gs, d, target) {
 				continue
 			}
 			prov := m.Provinces[target]
@@ -300,35 +297,30 @@ func DecodeRetreatLogits(
 				continue
 			}
 
-			targetCoast := ""
-			if isFleet && m.HasCoasts(target) {
-				coasts := m.FleetCoastsTo(d.DislodgedFrom, d.Unit.Coast, target)
-				if len(coasts) == 0 {
+			// Each reachable coast of a split-coast province is its own
+			// destination area, so the policy picks the coast too.
+			for _, coast := range diplomacy.RetreatCoasts(d, target, m) {
+				ro := diplomacy.RetreatOrder{
+					UnitType: d.Unit.Type, Power: power, Location: d.DislodgedFrom,
+					Coast: d.Unit.Coast, Type: diplomacy.RetreatMove,
+					Target: target, TargetCoast: coast,
+				}
+				if diplomacy.ValidateRetreatOrder(ro, gs, m) != nil {
 					continue
 				}
-				targetCoast = string(coasts[0])
-			}
-
-			ro := diplomacy.RetreatOrder{
-				UnitType: d.Unit.Type, Power: power, Location: d.DislodgedFrom,
-				Coast: d.Unit.Coast, Type: diplomacy.RetreatMove,
-				Target: target, TargetCoast: diplomacy.Coast(targetCoast),
-			}
-			if diplomacy.ValidateRetreatOrder(ro, gs, m) != nil {
-				continue
-			}
 
-			dstArea := areaForTarget(target, targetCoast)
-			score := unitLogits[OrderTypeRetreat] + unitLogits[SrcOffset+srcArea] + unitLogits[DstOffset+dstArea]
-			if score > bestOrder.Score {
-				bestOrder = ScoredOrder{
-					OrderType:   "retreat_move",
-					Location:    d.DislodgedFrom,
-					Coast:       string(d.Unit.Coast),
-					Target:      target,
-					TargetCoast: targetCoast,
-					UnitType:    d.Unit.Type.String(),
-					Score:       score,
+				dstArea := areaForTarget(target, string(coast))
+				score := unitLogits[OrderTypeRetreat] + unitLogits[SrcOffset+srcArea] + unitLogits[DstOffset+dstArea]
+				if score > bestOrder.Score {
+					bestOrder = ScoredOrder{
+						OrderType:   "retreat_move",
+						Location:    d.DislodgedFrom,
+						Coast:       string(d.Unit.Coast),
+						Target:      target,
+						TargetCoast: string(coast),
+						UnitType:    d.Unit.Type.String(),
+						Score:       score,
+					}
 				}
 			}
 		}
@@ -345,9 +337,7 @@ func DecodeBuildLogits(
 	power diplomacy.Power,
 	m *diplomacy.DiplomacyMap,
 ) []ScoredOrder {
-	scCount := gs.SupplyCenterCount(power)
-	unitCount := gs.UnitCount(power)
-	diff := scCount - unitCount
+	diff := gs.AdjustmentDelta(power)
 
 	if diff == 0 {
 		return nil
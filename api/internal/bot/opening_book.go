@@ -69,6 +69,8 @@ type BookCondition struct {
 type BookOption struct {
 	Name   string       `json:"name"`
 	Weight float64      `json:"weight"`
+	Games  int          `json:"games,omitempty"` // mined book: games it was played in
+	Score  float64      `json:"score,omitempty"` // mined book: the power's mean result in them
 	Orders []OrderInput `json:"orders"`
 }
 
@@ -484,8 +486,22 @@ func parsePowerStr(s string) diplomacy.Power {
 }
 
 // LookupOpening returns a validated set of opening book orders for the given
-// power and game state, or nil if no opening matches.
+// power and game state, or nil if no opening matches. The hand-written book
+// is tried first and the mined book after it. Both are built from seven-power
+// games without garrisons, so partial boards such as duels and garrisoned
+// games never use them.
 func LookupOpening(gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap) []OrderInput {
+	if gs.IsPartialBoard() || len(gs.Garrisons()) > 0 {
+		return nil
+	}
+	if orders := lookupBook(gs, power, m); orders != nil {
+		return orders
+	}
+	return lookupMined(gs, power, m)
+}
+
+// lookupBook matches the hand-written opening book.
+func lookupBook(gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap) []OrderInput {
 	book := getBook()
 	cfg := bookMatchMode
 
@@ -547,8 +563,9 @@ func LookupOpening(gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.
 		topOptions = append(topOptions, mt.entry.Options...)
 	}
 
-	// Weighted select from the combined top-tier options.
-	selected := bookWeightedSelect(topOptions)
+	// Weighted select from the combined top-tier options, skipping lines
+	// that attack centers a teaching game spares.
+	selected := bookWeightedSelect(mercifulOptions(topOptions, gs, power, m))
 	if selected == nil {
 		return nil
 	}
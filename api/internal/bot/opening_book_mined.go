@@ -0,0 +1,282 @@
+package bot
+
+import (
+	_ "embed"
+	"encoding/json"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// BookLastYear is the last year the mined opening book covers. The
+// hand-written book covers only 1901.
+const BookLastYear = 1903
+
+// bookYear reports whether bots consult the opening book in year: in 1901,
+// which the hand-written book covers, and through BookLastYear only once
+// the mined book has entries.
+func bookYear(year int) bool {
+	return year == 1901 || (year <= BookLastYear && len(getMinedIndex()) > 0)
+}
+
+//go:embed opening_book_mined.json
+var minedBookJSON []byte
+
+// MinedBook is an opening book built by cmd/build_book from finished games:
+// for each movement position a power reached in the opening years, the order
+// sets it played from there, weighted by how often they were played and how
+// well the power went on to do.
+type MinedBook struct {
+	Games   int          `json:"games"` // games the book was mined from
+	Entries []MinedEntry `json:"entries"`
+}
+
+// MinedEntry is one position of the mined book.
+type MinedEntry struct {
+	Power    string       `json:"power"`
+	Year     int          `json:"year"`
+	Season   string       `json:"season"`
+	Position string       `json:"position"` // see bookPosition
+	Games    int          `json:"games"`
+	Options  []BookOption `json:"options"`
+}
+
+var (
+	minedMu    sync.RWMutex
+	minedIndex map[string]*MinedEntry
+	minedOnce  sync.Once
+)
+
+// getMinedIndex lazily loads the embedded mined book, indexed by minedKey.
+func getMinedIndex() map[string]*MinedEntry {
+	minedOnce.Do(func() {
+		book := &MinedBook{}
+		if err := json.Unmarshal(minedBookJSON, book); err != nil {
+			log.Printf("mined opening book: failed to parse JSON: %v", err)
+			book = &MinedBook{}
+		}
+		minedMu.Lock()
+		minedIndex = indexMinedBook(book)
+		minedMu.Unlock()
+	})
+	minedMu.RLock()
+	defer minedMu.RUnlock()
+	return minedIndex
+}
+
+// SetMinedBook replaces the embedded mined book, e.g. with a freshly built
+// one to benchmark it before embedding.
+func SetMinedBook(book *MinedBook) {
+	minedOnce.Do(func() {})
+	minedMu.Lock()
+	minedIndex = indexMinedBook(book)
+	minedMu.Unlock()
+}
+
+func indexMinedBook(book *MinedBook) map[string]*MinedEntry {
+	index := make(map[string]*MinedEntry, len(book.Entries))
+	for i := range book.Entries {
+		e := &book.Entries[i]
+		index[minedKey(e.Power, e.Year, e.Season, e.Position)] = e
+	}
+	return index
+}
+
+func minedKey(power string, year int, season, position string) string {
+	return power + "|" + strconv.Itoa(year) + "|" + season + "|" + position
+}
+
+// bookPosition fingerprints a power's position for the mined book: its units,
+// its supply centers and the foreign units next to its units, which between
+// them decide what it can and has to do. The three parts are separated by
+// slashes, e.g. "A bud F tri/bud tri vie/russia:A gal".
+func bookPosition(gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap) string {
+	var own, centers, near []string
+	reach := make(map[string]bool)
+	for _, u := range gs.UnitsOf(power) {
+		own = append(own, bookUnit(u))
+		for _, adj := range m.Adjacencies[u.Province] {
+			reach[adj.To] = true
+		}
+	}
+	for prov, owner := range gs.SupplyCenters {
+		if owner == power {
+			centers = append(centers, prov)
+		}
+	}
+	for _, u := range gs.Units {
+		if u.Power != power && reach[u.Province] {
+			near = append(near, string(u.Power)+":"+bookUnit(u))
+		}
+	}
+	sort.Strings(own)
+	sort.Strings(centers)
+	sort.Strings(near)
+	return strings.Join(own, " ") + "/" + strings.Join(centers, " ") + "/" + strings.Join(near, " ")
+}
+
+// bookUnit writes u as e.g. "A vie" or "F stp/sc".
+func bookUnit(u diplomacy.Unit) string {
+	s := "A " + u.Province
+	if u.Type == diplomacy.Fleet {
+		s = "F " + u.Province
+	}
+	if u.Coast != diplomacy.NoCoast {
+		s += "/" + string(u.Coast)
+	}
+	return s
+}
+
+// lookupMined returns validated orders from the mined book for the power's
+// exact position, or nil if the book has never seen it.
+func lookupMined(gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap) []OrderInput {
+	if gs.Phase != diplomacy.PhaseMovement || gs.Year > BookLastYear {
+		return nil
+	}
+	index := getMinedIndex()
+	if len(index) == 0 {
+		return nil
+	}
+	e := index[minedKey(string(power), gs.Year, string(gs.Season), bookPosition(gs, power, m))]
+	if e == nil {
+		return nil
+	}
+	selected := bookWeightedSelect(mercifulOptions(e.Options, gs, power, m))
+	if selected == nil || len(selected.Orders) != gs.UnitCount(power) {
+		return nil
+	}
+	return validateOrders(selected.Orders, gs, power, m)
+}
+
+// BookPhase is one movement phase of a game fed to a BookBuilder: the
+// position before it resolved and the orders each power played.
+type BookPhase struct {
+	State  *diplomacy.GameState
+	Orders map[diplomacy.Power][]diplomacy.DSONOrder
+}
+
+// BookBuilder mines a MinedBook from finished games.
+type BookBuilder struct {
+	minGames   int
+	maxOptions int
+	games      int
+	positions  map[string]*minedPosition
+}
+
+type minedPosition struct {
+	entry   MinedEntry
+	options map[string]*minedOption
+}
+
+type minedOption struct {
+	dson   string
+	orders []OrderInput
+	games  int
+	score  float64 // sum of the power's results in games it was played
+}
+
+// NewBookBuilder returns a builder that keeps positions reached in at least
+// minGames games and at most maxOptions order sets for each.
+func NewBookBuilder(minGames, maxOptions int) *BookBuilder {
+	return &BookBuilder{minGames: minGames, maxOptions: maxOptions, positions: make(map[string]*minedPosition)}
+}
+
+// AddGame mines one game's movement phases up to BookLastYear. results holds
+// each power's result from 0 (eliminated or beaten) to 1 (solo win). A power
+// is skipped in a phase unless it ordered every one of its units.
+func (b *BookBuilder) AddGame(phases []BookPhase, results map[diplomacy.Power]float64, m *diplomacy.DiplomacyMap) {
+	b.games++
+	for _, ph := range phases {
+		gs := ph.State
+		if gs.Phase != diplomacy.PhaseMovement || gs.Year > BookLastYear {
+			continue
+		}
+		for power, dson := range ph.Orders {
+			if len(dson) == 0 || len(dson) != gs.UnitCount(power) {
+				continue
+			}
+			b.add(gs, power, dson, results[power], m)
+		}
+	}
+}
+
+func (b *BookBuilder) add(gs *diplomacy.GameState, power diplomacy.Power, dson []diplomacy.DSONOrder, result float64, m *diplomacy.DiplomacyMap) {
+	position := bookPosition(gs, power, m)
+	key := minedKey(string(power), gs.Year, string(gs.Season), position)
+	pos := b.positions[key]
+	if pos == nil {
+		pos = &minedPosition{
+			entry:   MinedEntry{Power: string(power), Year: gs.Year, Season: string(gs.Season), Position: position},
+			options: make(map[string]*minedOption),
+		}
+		b.positions[key] = pos
+	}
+	pos.entry.Games++
+
+	sorted := append([]diplomacy.DSONOrder(nil), dson...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Location < sorted[j].Location })
+	text := diplomacy.FormatDSON(sorted)
+	opt := pos.options[text]
+	if opt == nil {
+		opt = &minedOption{dson: text, orders: DSONInputs(sorted, power, diplomacy.PhaseMovement)}
+		pos.options[text] = opt
+	}
+	opt.games++
+	opt.score += result
+}
+
+// Book returns the mined book, its entries and options in a stable order.
+// Each option is weighted by how often it was played times its
+// Laplace-smoothed result, so common moves that went well come first.
+func (b *BookBuilder) Book() *MinedBook {
+	book := &MinedBook{Games: b.games, Entries: []MinedEntry{}}
+	for _, pos := range b.positions {
+		if pos.entry.Games < b.minGames {
+			continue
+		}
+		e := pos.entry
+		for _, opt := range pos.options {
+			smoothed := (opt.score + 1) / float64(opt.games+2)
+			e.Options = append(e.Options, BookOption{
+				Name:   opt.dson,
+				Weight: round3(float64(opt.games) * smoothed),
+				Games:  opt.games,
+				Score:  round3(opt.score / float64(opt.games)),
+				Orders: opt.orders,
+			})
+		}
+		sort.Slice(e.Options, func(i, j int) bool {
+			if e.Options[i].Weight != e.Options[j].Weight {
+				return e.Options[i].Weight > e.Options[j].Weight
+			}
+			return e.Options[i].Name < e.Options[j].Name
+		})
+		if b.maxOptions > 0 && len(e.Options) > b.maxOptions {
+			e.Options = e.Options[:b.maxOptions]
+		}
+		book.Entries = append(book.Entries, e)
+	}
+	sort.Slice(book.Entries, func(i, j int) bool {
+		a, c := book.Entries[i], book.Entries[j]
+		if a.Year != c.Year {
+			return a.Year < c.Year
+		}
+		if a.Season != c.Season {
+			return a.Season == string(diplomacy.Spring)
+		}
+		if a.Power != c.Power {
+			return a.Power < c.Power
+		}
+		return a.Position < c.Position
+	})
+	return book
+}
+
+func round3(x float64) float64 {
+	return math.Round(x*1000) / 1000
+}
@@ -1,6 +1,7 @@
 package bot
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
@@ -684,3 +685,95 @@ func TestDefaultConfigValues(t *testing.T) {
 		t.Error("position weight should be highest (exact match is most specific)")
 	}
 }
+
+// TestBookBuilderMinesOpenings verifies that mined options are weighted by
+// games times smoothed result and that rare positions are dropped.
+func TestBookBuilderMinesOpenings(t *testing.T) {
+	m := diplomacy.StandardMap()
+	gs := diplomacy.NewInitialState()
+	play := func(text string) map[diplomacy.Power][]diplomacy.DSONOrder {
+		dson, err := diplomacy.ParseDSON(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return map[diplomacy.Power][]diplomacy.DSONOrder{diplomacy.Austria: dson}
+	}
+	common := play("A vie - gal ; A bud - ser ; F tri - alb")
+	winning := play("A vie - tri ; A bud - ser ; F tri - alb")
+
+	b := NewBookBuilder(3, 0)
+	for range 3 {
+		b.AddGame([]BookPhase{{State: gs, Orders: common}}, nil, m)
+	}
+	for range 2 {
+		b.AddGame([]BookPhase{{State: gs, Orders: winning}}, map[diplomacy.Power]float64{diplomacy.Austria: 1}, m)
+	}
+	b.AddGame([]BookPhase{{State: gs, Orders: map[diplomacy.Power][]diplomacy.DSONOrder{
+		diplomacy.Italy: {{Type: diplomacy.DSONHold, UnitType: diplomacy.Army, Location: "rom"}},
+	}}}, nil, m)
+
+	book := b.Book()
+	if book.Games != 6 {
+		t.Errorf("games = %d, want 6", book.Games)
+	}
+	if len(book.Entries) != 1 {
+		t.Fatalf("expected only Austria's position, got %d entries", len(book.Entries))
+	}
+	e := book.Entries[0]
+	if e.Power != "austria" || e.Year != 1901 || e.Season != "spring" || e.Games != 5 {
+		t.Errorf("unexpected entry %s %d %s games %d", e.Power, e.Year, e.Season, e.Games)
+	}
+	if len(e.Options) != 2 {
+		t.Fatalf("expected 2 options, got %d", len(e.Options))
+	}
+	// 2 * 3/4 beats 3 * 1/5.
+	if e.Options[0].Weight != 1.5 || e.Options[1].Weight != 0.6 {
+		t.Errorf("weights = %v, %v; want 1.5, 0.6", e.Options[0].Weight, e.Options[1].Weight)
+	}
+	if e.Options[0].Name != "A bud - ser ; F tri - alb ; A vie - tri" || e.Options[0].Score != 1 {
+		t.Errorf("unexpected best option %q score %v", e.Options[0].Name, e.Options[0].Score)
+	}
+}
+
+// TestLookupOpeningMined verifies that LookupOpening falls back to the mined
+// book after 1901 and only for the exact positions it has seen.
+func TestLookupOpeningMined(t *testing.T) {
+	defer func() {
+		var book MinedBook
+		json.Unmarshal(minedBookJSON, &book)
+		SetMinedBook(&book)
+	}()
+	m := diplomacy.StandardMap()
+	gs := diplomacy.NewInitialState()
+	gs.Year = 1902
+	dson, _ := diplomacy.ParseDSON("A vie - tyr ; A bud - rum ; F tri - ven")
+
+	SetMinedBook(&MinedBook{})
+	if bookYear(1902) || !bookYear(1901) {
+		t.Error("expected only 1901 to consult the book while the mined book is empty")
+	}
+
+	b := NewBookBuilder(1, 1)
+	b.AddGame([]BookPhase{{State: gs, Orders: map[diplomacy.Power][]diplomacy.DSONOrder{diplomacy.Austria: dson}}}, nil, m)
+	SetMinedBook(b.Book())
+	if !bookYear(1902) || bookYear(BookLastYear+1) {
+		t.Error("expected the book consulted through its last year once it has entries")
+	}
+
+	orders := LookupOpening(gs, diplomacy.Austria, m)
+	if len(orders) != 3 {
+		t.Fatalf("expected 3 mined orders, got %v", orders)
+	}
+	for _, o := range orders {
+		if o.Location == "bud" && o.Target != "rum" {
+			t.Errorf("expected bud - rum, got %+v", o)
+		}
+	}
+	if LookupOpening(gs, diplomacy.Italy, m) != nil {
+		t.Error("expected no orders for a position the book has not seen")
+	}
+	gs.Year = BookLastYear + 1
+	if LookupOpening(gs, diplomacy.Austria, m) != nil {
+		t.Error("expected no mined orders after the book's last year")
+	}
+}
@@ -0,0 +1,205 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// Personality varies how a bot plays within its strategy, so bots of the same
+// difficulty don't all play the same game. Each trait runs from -1 to 1 and 0
+// is the strategy's usual play, so the zero Personality changes nothing.
+//   - Aggression weighs attacking other powers' centers over consolidating.
+//   - Loyalty sets how long pacts are honored and how much gain it takes to
+//     break one.
+//   - RiskTolerance weighs immediate gains over leaving its own centers
+//     exposed.
+//   - Talkativeness sets how much unsolicited press the bot sends.
+type Personality struct {
+	Aggression    float64 `json:"aggression"`
+	Loyalty       float64 `json:"loyalty"`
+	RiskTolerance float64 `json:"risk_tolerance"`
+	Talkativeness float64 `json:"talkativeness"`
+}
+
+// PersonalityRandom draws a random personality when given to
+// ParsePersonality.
+const PersonalityRandom = "random"
+
+// personalityPresets are named personalities accepted by ParsePersonality.
+var personalityPresets = map[string]Personality{
+	"balanced":    {},
+	"aggressive":  {Aggression: 0.8, RiskTolerance: 0.4},
+	"cautious":    {Aggression: -0.4, RiskTolerance: -0.8},
+	"loyal":       {Loyalty: 0.8},
+	"treacherous": {Aggression: 0.3, Loyalty: -0.8},
+	"chatty":      {Talkativeness: 0.8},
+	"quiet":       {Talkativeness: -0.8},
+}
+
+// PersonalityPresets returns the names of the preset personalities, sorted.
+func PersonalityPresets() []string {
+	names := make([]string, 0, len(personalityPresets))
+	for name := range personalityPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupPersonality returns the preset personality with the given name.
+func LookupPersonality(name string) (Personality, bool) {
+	p, ok := personalityPresets[name]
+	return p, ok
+}
+
+// RandomPersonality draws each trait uniformly from -1 to 1 using the bot
+// RNG, so seeded games draw the same personalities.
+func RandomPersonality() Personality {
+	trait := func() float64 { return math.Round((botFloat64()*2-1)*100) / 100 }
+	return Personality{Aggression: trait(), Loyalty: trait(), RiskTolerance: trait(), Talkativeness: trait()}
+}
+
+// ParsePersonality parses a preset name, "random", or traits like
+// "aggression:0.8/risk_tolerance:-0.5"; unnamed traits are 0.
+func ParsePersonality(s string) (Personality, error) {
+	if s == PersonalityRandom {
+		return RandomPersonality(), nil
+	}
+	if p, ok := personalityPresets[s]; ok {
+		return p, nil
+	}
+	var p Personality
+	for _, part := range strings.Split(s, "/") {
+		name, val, ok := strings.Cut(part, ":")
+		if !ok {
+			return Personality{}, fmt.Errorf("personality %q: want a preset (%s), random, or trait:value/...", s, strings.Join(PersonalityPresets(), ", "))
+		}
+		v, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return Personality{}, fmt.Errorf("personality %q: trait %s: %w", s, name, err)
+		}
+		switch name {
+		case "aggression":
+			p.Aggression = v
+		case "loyalty":
+			p.Loyalty = v
+		case "risk_tolerance":
+			p.RiskTolerance = v
+		case "talkativeness":
+			p.Talkativeness = v
+		default:
+			return Personality{}, fmt.Errorf("personality %q: unknown trait %q", s, name)
+		}
+	}
+	return p, p.Validate()
+}
+
+// Validate reports an error if a trait lies outside -1 to 1.
+func (p Personality) Validate() error {
+	for _, t := range []struct {
+		name string
+		v    float64
+	}{
+		{"aggression", p.Aggression},
+		{"loyalty", p.Loyalty},
+		{"risk_tolerance", p.RiskTolerance},
+		{"talkativeness", p.Talkativeness},
+	} {
+		if math.IsNaN(t.v) || t.v < -1 || t.v > 1 {
+			return fmt.Errorf("%s must be between -1 and 1, got %g", t.name, t.v)
+		}
+	}
+	return nil
+}
+
+// ErrNoPersonality is returned by WithPersonality for strategies that play
+// the same whatever their personality, such as external engines.
+var ErrNoPersonality = errors.New("strategy does not support a personality")
+
+// WithPersonality returns s set to play with personality p, or
+// ErrNoPersonality if s does not declare the personality capability.
+func WithPersonality(s Strategy, p Personality) (Strategy, error) {
+	switch st := s.(type) {
+	case *HeuristicStrategy:
+		st.Personality = p
+	case *TacticalStrategy:
+		st.Personality = p
+	case *HardStrategy:
+		st.Personality = p
+	default:
+		return s, fmt.Errorf("%w: %s", ErrNoPersonality, s.Name())
+	}
+	return s, nil
+}
+
+const (
+	// personalityAttackBonus is what an aggression of 1 adds to a candidate
+	// per move into another power's center or unit.
+	personalityAttackBonus = 1.5
+	// personalityExposureBonus is what a risk tolerance of 1 adds to a
+	// candidate per threatened own center it leaves empty; cautious bots
+	// pay it instead.
+	personalityExposureBonus = 2.0
+)
+
+// attackWeight scales the value of taking another power's center.
+func (p Personality) attackWeight() float64 {
+	return 1 + 0.5*p.Aggression
+}
+
+// plyWeights returns how the medium bot blends its three lookahead plies:
+// risk takers trust the position after their own moves, cautious bots the
+// one after opponents reply.
+func (p Personality) plyWeights() (ply1, ply2, ply3 float64) {
+	return 0.5 + 0.1*p.RiskTolerance, 0.2 - 0.1*p.RiskTolerance, 0.3
+}
+
+// candidateBias scores an order set by the personality's taste: aggression
+// rewards moves into other powers' centers and units, and risk tolerance
+// rewards (or, when negative, penalizes) leaving threatened own centers
+// empty.
+func (p Personality) candidateBias(orders []OrderInput, gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap) float64 {
+	if p.Aggression == 0 && p.RiskTolerance == 0 {
+		return 0
+	}
+	attacks, exposed := 0, 0
+	arriving := make(map[string]bool, len(orders))
+	for _, o := range orders {
+		if o.OrderType == "move" {
+			arriving[o.Target] = true
+			if len(attackedPowers(o.Target, gs, power)) > 0 {
+				attacks++
+			}
+		}
+	}
+	if p.RiskTolerance != 0 {
+		for _, o := range orders {
+			if o.OrderType != "move" || arriving[o.Location] {
+				continue
+			}
+			if prov := m.Provinces[o.Location]; prov != nil && prov.IsSupplyCenter && gs.SupplyCenters[o.Location] == power &&
+				ProvinceThreat(o.Location, power, gs, m) > 0 {
+				exposed++
+			}
+		}
+	}
+	return personalityAttackBonus*p.Aggression*float64(attacks) + personalityExposureBonus*p.RiskTolerance*float64(exposed)
+}
+
+// keepUnsolicited reports whether a quiet bot sends an unsolicited message;
+// replies are always sent. Bots at or above 0 talkativeness send them all.
+func (p Personality) keepUnsolicited() bool {
+	return p.Talkativeness >= 0 || botFloat64() < 1+p.Talkativeness
+}
+
+// volunteersAlliance reports whether a talkative bot also proposes an
+// alliance to a neighbour.
+func (p Personality) volunteersAlliance() bool {
+	return p.Talkativeness > 0 && botFloat64() < p.Talkativeness
+}
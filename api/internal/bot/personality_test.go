@@ -0,0 +1,145 @@
+package bot
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+func TestParsePersonality(t *testing.T) {
+	p, err := ParsePersonality("aggressive")
+	if err != nil || p != personalityPresets["aggressive"] {
+		t.Errorf("preset: got %+v, %v", p, err)
+	}
+	p, err = ParsePersonality("aggression:0.5/risk_tolerance:-1")
+	if err != nil || p != (Personality{Aggression: 0.5, RiskTolerance: -1}) {
+		t.Errorf("traits: got %+v, %v", p, err)
+	}
+
+	SeedBotRng(3)
+	defer ResetBotRng()
+	p, err = ParsePersonality(PersonalityRandom)
+	if err != nil || p == (Personality{}) {
+		t.Errorf("random: got %+v, %v", p, err)
+	}
+
+	for _, bad := range []string{"grumpy", "aggression:2", "loyalty:x", "charm:0.5"} {
+		if _, err := ParsePersonality(bad); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+}
+
+func TestWithPersonality(t *testing.T) {
+	p := Personality{Aggression: 1}
+	for _, name := range []string{"easy", "medium", "hard"} {
+		reg, _ := LookupStrategy(name)
+		if !reg.Capabilities.Personality {
+			t.Errorf("%s: expected the personality capability", name)
+		}
+		s, err := WithPersonality(NewStrategy(name, nil), p)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		switch s := s.(type) {
+		case *HeuristicStrategy:
+			if s.Personality != p {
+				t.Errorf("easy: personality not set")
+			}
+		case *TacticalStrategy:
+			if s.Personality != p {
+				t.Errorf("medium: personality not set")
+			}
+		case *HardStrategy:
+			if s.Personality != p {
+				t.Errorf("hard: personality not set")
+			}
+		default:
+			t.Errorf("%s: unexpected strategy %T", name, s)
+		}
+	}
+	for _, name := range []string{"random", "hold"} {
+		reg, _ := LookupStrategy(name)
+		if reg.Capabilities.Personality {
+			t.Errorf("%s: unexpected personality capability", name)
+		}
+		if _, err := WithPersonality(NewStrategy(name, nil), p); !errors.Is(err, ErrNoPersonality) {
+			t.Errorf("%s: expected ErrNoPersonality, got %v", name, err)
+		}
+	}
+}
+
+func TestHardLoyalty(t *testing.T) {
+	neutral := HardStrategy{}
+	loyal := HardStrategy{Personality: Personality{Loyalty: 1}}
+	disloyal := HardStrategy{Personality: Personality{Loyalty: -1}}
+	if neutral.pactYears() != DefaultPactYears || neutral.betrayalGain() != DefaultBetrayalGain {
+		t.Errorf("neutral personality changed pacts: %d years, %g gain", neutral.pactYears(), neutral.betrayalGain())
+	}
+	if loyal.pactYears() <= neutral.pactYears() || loyal.betrayalGain() <= neutral.betrayalGain() {
+		t.Errorf("loyal bot should keep pacts longer: %d years, %g gain", loyal.pactYears(), loyal.betrayalGain())
+	}
+	if disloyal.pactYears() < 1 || disloyal.betrayalGain() >= neutral.betrayalGain() {
+		t.Errorf("disloyal bot should break pacts sooner: %d years, %g gain", disloyal.pactYears(), disloyal.betrayalGain())
+	}
+}
+
+func TestPersonalityCandidateBias(t *testing.T) {
+	gs := diplomacy.NewInitialState()
+	m := diplomacy.StandardMap()
+	// Italy leaves Venice, threatened by the fleet in Trieste, to attack it.
+	orders := []OrderInput{{UnitType: "army", Location: "ven", OrderType: "move", Target: "tri"}}
+
+	if bias := (Personality{}).candidateBias(orders, gs, diplomacy.Italy, m); bias != 0 {
+		t.Errorf("neutral: expected no bias, got %g", bias)
+	}
+	if bias := (Personality{Aggression: 1}).candidateBias(orders, gs, diplomacy.Italy, m); bias != personalityAttackBonus {
+		t.Errorf("aggressive: expected %g, got %g", personalityAttackBonus, bias)
+	}
+	if bias := (Personality{RiskTolerance: -1}).candidateBias(orders, gs, diplomacy.Italy, m); bias != -personalityExposureBonus {
+		t.Errorf("cautious: expected %g, got %g", -personalityExposureBonus, bias)
+	}
+}
+
+func TestHeuristicAggressionScoresEnemyCenters(t *testing.T) {
+	gs := diplomacy.NewInitialState()
+	m := diplomacy.StandardMap()
+	units := gs.UnitsOf(diplomacy.Italy)
+	score := func(p Personality) float64 {
+		SeedBotRng(7)
+		defer ResetBotRng()
+		for _, c := range (HeuristicStrategy{Personality: p}).scoreMoves(gs, diplomacy.Italy, units, m) {
+			if c.unit.Province == "ven" && c.target == "tri" {
+				return c.score
+			}
+		}
+		t.Fatal("no ven-tri candidate")
+		return 0
+	}
+	if diff := score(Personality{Aggression: 1}) - score(Personality{}); diff != 3.5 {
+		t.Errorf("expected aggression to add half the enemy center value, got %g", diff)
+	}
+}
+
+func TestTacticalTalkativeness(t *testing.T) {
+	gs := diplomacy.NewInitialState()
+	m := diplomacy.StandardMap()
+	received := []DiplomaticIntent{{Type: IntentProposeNonAggression, From: diplomacy.Austria, To: diplomacy.Italy}}
+
+	quiet := TacticalStrategy{Personality: Personality{Talkativeness: -1}}.GenerateDiplomaticMessages(gs, diplomacy.Italy, m, received)
+	if len(quiet) != 1 || quiet[0].Type != IntentAccept {
+		t.Errorf("quiet bot should only reply, got %+v", quiet)
+	}
+
+	neutral := TacticalStrategy{}.GenerateDiplomaticMessages(gs, diplomacy.Italy, m, received)
+	chatty := TacticalStrategy{Personality: Personality{Talkativeness: 1}}.GenerateDiplomaticMessages(gs, diplomacy.Italy, m, received)
+	if len(chatty) <= len(neutral) {
+		t.Fatalf("chatty bot should send more press: %d vs %d messages", len(chatty), len(neutral))
+	}
+	for _, msg := range chatty {
+		if msg.Type == IntentProposeAlliance && (msg.TargetPower == "" || msg.TargetPower == msg.To || msg.TargetPower == diplomacy.Italy) {
+			t.Errorf("alliance proposal with a bad target: %+v", msg)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package bot
+
+import (
+	"slices"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// Playbook is a power's strategic profile past the opening book: where it
+// should expand, which provinces it must hold, and who it usually allies with.
+// Move scoring and position evaluation both consult it so bots keep a
+// coherent plan instead of drifting toward whichever SC is nearest.
+type Playbook struct {
+	Theaters []Theater         // preferred expansion theaters
+	Targets  []string          // supply centers to take, in priority order
+	Hold     []string          // chokepoints and key provinces to keep covered
+	Allies   []diplomacy.Power // typical mid-game alliance partners
+
+	targetRank map[string]int
+	hold       map[string]bool
+	theaters   map[Theater]bool
+}
+
+func newPlaybook(theaters []Theater, targets, hold []string, allies []diplomacy.Power) *Playbook {
+	pb := &Playbook{
+		Theaters:   theaters,
+		Targets:    targets,
+		Hold:       hold,
+		Allies:     allies,
+		targetRank: make(map[string]int, len(targets)),
+		hold:       make(map[string]bool, len(hold)),
+		theaters:   make(map[Theater]bool, len(theaters)),
+	}
+	for i, t := range targets {
+		pb.targetRank[t] = i
+	}
+	for _, h := range hold {
+		pb.hold[h] = true
+	}
+	for _, t := range theaters {
+		pb.theaters[t] = true
+	}
+	return pb
+}
+
+var playbooks = map[diplomacy.Power]*Playbook{
+	diplomacy.Austria: newPlaybook(
+		[]Theater{TheaterBalkans, TheaterCenter},
+		[]string{"ser", "gre", "rum", "bul", "ven"},
+		[]string{"gal", "tri", "tyr"},
+		[]diplomacy.Power{diplomacy.Italy, diplomacy.Germany},
+	),
+	diplomacy.England: newPlaybook(
+		[]Theater{TheaterScan, TheaterWest},
+		[]string{"nwy", "bel", "hol", "den", "swe", "bre"},
+		[]string{"nth", "eng", "nrg"},
+		[]diplomacy.Power{diplomacy.France, diplomacy.Germany},
+	),
+	diplomacy.France: newPlaybook(
+		[]Theater{TheaterWest, TheaterMed},
+		[]string{"spa", "por", "bel", "tun", "lon"},
+		[]string{"mao", "eng", "bur"},
+		[]diplomacy.Power{diplomacy.England, diplomacy.Germany},
+	),
+	diplomacy.Germany: newPlaybook(
+		[]Theater{TheaterCenter, TheaterScan},
+		[]string{"den", "hol", "bel", "swe", "war"},
+		[]string{"ruh", "sil", "bal"},
+		[]diplomacy.Power{diplomacy.England, diplomacy.Austria},
+	),
+	diplomacy.Italy: newPlaybook(
+		[]Theater{TheaterMed, TheaterBalkans},
+		[]string{"tun", "gre", "tri", "smy", "mar"},
+		[]string{"ion", "tys", "ven"},
+		[]diplomacy.Power{diplomacy.Austria, diplomacy.Germany},
+	),
+	diplomacy.Russia: newPlaybook(
+		[]Theater{TheaterEast, TheaterScan},
+		[]string{"swe", "rum", "nwy", "ank", "bud"},
+		[]string{"stp", "fin", "bot", "sev"},
+		[]diplomacy.Power{diplomacy.Turkey, diplomacy.Germany},
+	),
+	diplomacy.Turkey: newPlaybook(
+		[]Theater{TheaterBalkans, TheaterMed},
+		[]string{"bul", "gre", "rum", "sev", "ser"},
+		[]string{"bla", "aeg", "arm"},
+		[]diplomacy.Power{diplomacy.Russia, diplomacy.Italy},
+	),
+}
+
+// PlaybookFor returns the strategic profile for a power, or nil if it has none.
+func PlaybookFor(power diplomacy.Power) *Playbook {
+	return playbooks[power]
+}
+
+// IsAlly reports whether p is one of the playbook's usual allies.
+func (pb *Playbook) IsAlly(p diplomacy.Power) bool {
+	return slices.Contains(pb.Allies, p)
+}
+
+// targetBonus rewards taking a playbook target, tapering with its priority.
+func (pb *Playbook) targetBonus(province string) float64 {
+	rank, ok := pb.targetRank[province]
+	if !ok {
+		return 0
+	}
+	return max(3.0-0.5*float64(rank), 1.0)
+}
+
+// playbookMoveScore adjusts the score of moving unit u into target using the
+// power's playbook: targets and preferred theaters pull units in, key
+// provinces are not abandoned under threat, and usual allies are left alone
+// unless they have pulled well ahead.
+func playbookMoveScore(pb *Playbook, gs *diplomacy.GameState, power diplomacy.Power, u diplomacy.Unit, target string, m *diplomacy.DiplomacyMap) float64 {
+	if pb == nil {
+		return 0
+	}
+	score := 0.0
+	owner := gs.SupplyCenters[target]
+	if owner != power {
+		score += pb.targetBonus(target)
+	}
+	if pb.theaters[ProvinceTheater(target)] {
+		score += 1.0
+	}
+	if pb.hold[target] {
+		score += 2.0
+	}
+	if pb.hold[u.Province] && target != u.Province {
+		if threat := ProvinceThreat(u.Province, power, gs, m); threat > 0 {
+			score -= 3.0 * float64(threat)
+		}
+	}
+	if owner != "" && owner != power && pb.IsAlly(owner) &&
+		gs.SupplyCenterCount(owner) < gs.SupplyCenterCount(power)+3 {
+		score -= 3.0
+	}
+	return score
+}
+
+// playbookPositionScore rewards a position for following the power's playbook:
+// owning its targets, covering its key provinces, and keeping units in its
+// preferred theaters. Enemy units sitting on key provinces are penalized.
+func playbookPositionScore(gs *diplomacy.GameState, power diplomacy.Power) float64 {
+	pb := playbooks[power]
+	if pb == nil {
+		return 0
+	}
+	score := 0.0
+	for _, t := range pb.Targets {
+		if gs.SupplyCenters[t] == power {
+			score += 0.5 * pb.targetBonus(t)
+		}
+	}
+	for i := range gs.Units {
+		u := &gs.Units[i]
+		if u.Power != power {
+			if pb.hold[u.Province] {
+				score -= 2.0
+			}
+			continue
+		}
+		if pb.hold[u.Province] {
+			score += 1.0
+		}
+		if pb.theaters[ProvinceTheater(u.Province)] {
+			score += 0.5
+		}
+	}
+	return score
+}
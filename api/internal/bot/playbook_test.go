@@ -0,0 +1,73 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+func TestPlaybooksCoverAllPowers(t *testing.T) {
+	m := diplomacy.StandardMap()
+	for _, p := range diplomacy.AllPowers() {
+		pb := PlaybookFor(p)
+		if pb == nil {
+			t.Errorf("%s has no playbook", p)
+			continue
+		}
+		for _, sc := range pb.Targets {
+			if prov := m.Provinces[sc]; prov == nil || !prov.IsSupplyCenter {
+				t.Errorf("%s target %q is not a supply center", p, sc)
+			}
+		}
+		for _, h := range pb.Hold {
+			if m.Provinces[h] == nil {
+				t.Errorf("%s hold province %q is not on the map", p, h)
+			}
+		}
+		for _, a := range pb.Allies {
+			if a == p {
+				t.Errorf("%s lists itself as an ally", p)
+			}
+		}
+	}
+}
+
+func TestPlaybookMoveScoreItaly(t *testing.T) {
+	gs := diplomacy.NewInitialState()
+	m := diplomacy.StandardMap()
+	pb := PlaybookFor(diplomacy.Italy)
+	fleet := diplomacy.Unit{Type: diplomacy.Fleet, Power: diplomacy.Italy, Province: "ion"}
+
+	toTunis := playbookMoveScore(pb, gs, diplomacy.Italy, fleet, "tun", m)
+	toAlbania := playbookMoveScore(pb, gs, diplomacy.Italy, fleet, "alb", m)
+	if toTunis <= toAlbania {
+		t.Errorf("Italy should prefer Tunis over Albania: tun=%.1f alb=%.1f", toTunis, toAlbania)
+	}
+
+	army := diplomacy.Unit{Type: diplomacy.Army, Power: diplomacy.Italy, Province: "ven"}
+	if s := playbookMoveScore(pb, gs, diplomacy.Italy, army, "tri", m); s >= pb.targetBonus("tri")+1 {
+		t.Errorf("attacking ally Austria should be discounted, got %.1f", s)
+	}
+}
+
+func TestPlaybookPositionScoreRussiaNorth(t *testing.T) {
+	gs := diplomacy.NewInitialState()
+	base := playbookPositionScore(gs, diplomacy.Russia)
+
+	gs.Units = append(gs.Units, diplomacy.Unit{Type: diplomacy.Fleet, Power: diplomacy.England, Province: "bot"})
+	if got := playbookPositionScore(gs, diplomacy.Russia); got >= base {
+		t.Errorf("an enemy fleet in the Gulf of Bothnia should lower Russia's score: %.1f >= %.1f", got, base)
+	}
+}
+
+func TestPlaybookNilSafe(t *testing.T) {
+	gs := diplomacy.NewInitialState()
+	m := diplomacy.StandardMap()
+	u := diplomacy.Unit{Type: diplomacy.Army, Power: diplomacy.Neutral, Province: "mun"}
+	if s := playbookMoveScore(nil, gs, diplomacy.Neutral, u, "bur", m); s != 0 {
+		t.Errorf("nil playbook should score 0, got %.1f", s)
+	}
+	if s := playbookPositionScore(gs, diplomacy.Neutral); s != 0 {
+		t.Errorf("no playbook should score 0, got %.1f", s)
+	}
+}
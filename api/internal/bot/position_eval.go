@@ -0,0 +1,52 @@
+package bot
+
+import (
+	"github.com/freeeve/polite-betrayal/api/internal/bot/neural"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// ValueNetwork is a neural value head that scores a position for one power,
+// returning [sc_share, win_prob, draw_prob, survival_prob].
+type ValueNetwork interface {
+	RunValueNetwork(gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap) ([4]float32, error)
+}
+
+// PowerEvaluation is one power's standing in a position.
+type PowerEvaluation struct {
+	Power     diplomacy.Power
+	Heuristic float64  // EvaluatePosition score
+	Share     float64  // heuristic share of the board among alive powers, 0..1
+	Neural    *float64 // neural scalar, nil without a value network
+	WinProb   *float64 // value network win probability, nil without one
+	DrawProb  *float64 // value network draw probability, nil without one
+}
+
+// EvaluatePowers scores a position from each active power's perspective, as
+// shown on a replay evaluation bar. Eliminated powers get a zero share. vn may
+// be nil; value network errors leave the neural fields unset.
+func EvaluatePowers(gs *diplomacy.GameState, m *diplomacy.DiplomacyMap, vn ValueNetwork) []PowerEvaluation {
+	powers := gs.ActivePowers()
+	evals := make([]PowerEvaluation, 0, len(powers))
+	total := 0.0
+	for _, p := range powers {
+		e := PowerEvaluation{Power: p, Heuristic: EvaluatePosition(gs, p, m)}
+		if gs.PowerIsAlive(p) {
+			e.Share = max(e.Heuristic, 0)
+			total += e.Share
+		}
+		if vn != nil {
+			if v, err := vn.RunValueNetwork(gs, p, m); err == nil {
+				scalar := neural.NeuralValueToScalar(v)
+				win, draw := float64(v[1]), float64(v[2])
+				e.Neural, e.WinProb, e.DrawProb = &scalar, &win, &draw
+			}
+		}
+		evals = append(evals, e)
+	}
+	for i := range evals {
+		if total > 0 {
+			evals[i].Share /= total
+		}
+	}
+	return evals
+}
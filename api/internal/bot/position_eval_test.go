@@ -0,0 +1,59 @@
+package bot
+
+import (
+	"math"
+	"testing"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+type fixedValueNetwork [4]float32
+
+func (v fixedValueNetwork) RunValueNetwork(*diplomacy.GameState, diplomacy.Power, *diplomacy.DiplomacyMap) ([4]float32, error) {
+	return v, nil
+}
+
+func TestEvaluatePowers(t *testing.T) {
+	m := diplomacy.StandardMap()
+	gs := diplomacy.NewInitialState()
+	gs.Units = gs.Units[:0:0]
+	for _, u := range diplomacy.NewInitialState().Units {
+		if u.Power != diplomacy.Turkey {
+			gs.Units = append(gs.Units, u)
+		}
+	}
+	for sc, owner := range gs.SupplyCenters {
+		if owner == diplomacy.Turkey {
+			gs.SupplyCenters[sc] = diplomacy.Russia
+		}
+	}
+
+	evals := EvaluatePowers(gs, m, fixedValueNetwork{0.2, 0.1, 0.3, 0.9})
+	if len(evals) != 7 {
+		t.Fatalf("expected 7 evaluations, got %d", len(evals))
+	}
+	total := 0.0
+	for _, e := range evals {
+		total += e.Share
+		if e.Power == diplomacy.Turkey && e.Share != 0 {
+			t.Errorf("expected eliminated Turkey to have no share, got %f", e.Share)
+		}
+		if e.Neural == nil || e.WinProb == nil || math.Abs(*e.WinProb-0.1) > 1e-6 {
+			t.Errorf("%s: expected neural scores from the value network, got %v %v", e.Power, e.Neural, e.WinProb)
+		}
+	}
+	if math.Abs(total-1) > 1e-9 {
+		t.Errorf("expected shares to sum to 1, got %f", total)
+	}
+}
+
+func TestEvaluatePowers_DuelBoard(t *testing.T) {
+	sc, _ := diplomacy.LookupScenario("france-austria")
+	evals := EvaluatePowers(sc.InitialState(), sc.Map(), nil)
+	if len(evals) != 2 {
+		t.Fatalf("expected only the two active powers, got %d", len(evals))
+	}
+	if evals[0].Neural != nil {
+		t.Error("expected no neural score without a value network")
+	}
+}
@@ -0,0 +1,173 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// DefaultLocale is used when a recipient has no locale or an unsupported one.
+// Its templates are the canonical canned messages understood by ParseCannedMessage.
+const DefaultLocale = "en"
+
+// pressTemplate holds the renderings of one intent type in one locale.
+// Variants are chosen by how many arguments the intent carries; an empty
+// variant falls back to the next less specific one.
+type pressTemplate struct {
+	two  string // two arguments, e.g. from/to provinces
+	one  string // one argument: a province, an alliance target, or a DSON order set
+	none string
+}
+
+// pressCatalog maps locale -> intent type -> templates.
+var pressCatalog = map[string]map[IntentType]pressTemplate{
+	"en": {
+		IntentRequestSupport:       {two: "Request support from %s to %s", one: "Request support at %s", none: "Request support"},
+		IntentProposeNonAggression: {one: "Please don't attack %s, I won't attack yours", none: "Let's agree not to attack each other"},
+		IntentProposeAlliance:      {one: "Let's work together against %s", none: "Let's work together"},
+		IntentThreaten:             {one: "I'm coming for %s — back off", none: "Back off or face consequences"},
+		IntentOfferDeal:            {two: "Deal: I take %s, you take %s", none: "I'd like to make a deal"},
+		IntentAccept:               {none: "Agreed"},
+		IntentReject:               {none: "No deal"},
+		IntentProposeOrders:        {one: "Here's the plan: %s", none: "Here's the plan"},
+	},
+	"de": {
+		IntentRequestSupport:       {two: "Bitte um Unterstützung von %s nach %s", one: "Bitte um Unterstützung in %s", none: "Bitte um Unterstützung"},
+		IntentProposeNonAggression: {one: "Bitte greif %s nicht an, ich greife deine nicht an", none: "Lass uns vereinbaren, einander nicht anzugreifen"},
+		IntentProposeAlliance:      {one: "Lass uns gemeinsam gegen %s vorgehen", none: "Lass uns zusammenarbeiten"},
+		IntentThreaten:             {one: "Ich komme nach %s — zieh dich zurück", none: "Zieh dich zurück oder trage die Folgen"},
+		IntentOfferDeal:            {two: "Abmachung: Ich nehme %s, du nimmst %s", none: "Ich möchte eine Abmachung treffen"},
+		IntentAccept:               {none: "Einverstanden"},
+		IntentReject:               {none: "Keine Abmachung"},
+		IntentProposeOrders:        {one: "Hier ist der Plan: %s", none: "Hier ist der Plan"},
+	},
+	"es": {
+		IntentRequestSupport:       {two: "Solicito apoyo de %s a %s", one: "Solicito apoyo en %s", none: "Solicito apoyo"},
+		IntentProposeNonAggression: {one: "Por favor no ataques %s, yo no atacaré los tuyos", none: "Acordemos no atacarnos"},
+		IntentProposeAlliance:      {one: "Trabajemos juntos contra %s", none: "Trabajemos juntos"},
+		IntentThreaten:             {one: "Voy a por %s — retírate", none: "Retírate o atente a las consecuencias"},
+		IntentOfferDeal:            {two: "Trato: yo tomo %s, tú tomas %s", none: "Me gustaría hacer un trato"},
+		IntentAccept:               {none: "De acuerdo"},
+		IntentReject:               {none: "No hay trato"},
+		IntentProposeOrders:        {one: "Este es el plan: %s", none: "Este es el plan"},
+	},
+	"fr": {
+		IntentRequestSupport:       {two: "Demande de soutien de %s vers %s", one: "Demande de soutien en %s", none: "Demande de soutien"},
+		IntentProposeNonAggression: {one: "Ne m'attaque pas en %s, je n'attaquerai pas les tiens", none: "Convenons de ne pas nous attaquer"},
+		IntentProposeAlliance:      {one: "Travaillons ensemble contre %s", none: "Travaillons ensemble"},
+		IntentThreaten:             {one: "Je viens pour %s — recule", none: "Recule ou subis les conséquences"},
+		IntentOfferDeal:            {two: "Marché : je prends %s, tu prends %s", none: "J'aimerais conclure un marché"},
+		IntentAccept:               {none: "D'accord"},
+		IntentReject:               {none: "Pas d'accord"},
+		IntentProposeOrders:        {one: "Voici le plan : %s", none: "Voici le plan"},
+	},
+}
+
+// SupportedLocales returns the locales canned press can be rendered in.
+func SupportedLocales() []string {
+	return []string{"de", "en", "es", "fr"}
+}
+
+// NormalizeLocale reduces a locale tag such as "fr-CA" to its supported base
+// language. It reports false if the language has no press catalog.
+func NormalizeLocale(locale string) (string, bool) {
+	lang := strings.ToLower(strings.TrimSpace(locale))
+	if i := strings.IndexAny(lang, "-_"); i >= 0 {
+		lang = lang[:i]
+	}
+	_, ok := pressCatalog[lang]
+	return lang, ok
+}
+
+// FormatCannedMessageLocale renders a DiplomaticIntent in the given locale,
+// falling back to DefaultLocale for unsupported locales.
+func FormatCannedMessageLocale(intent DiplomaticIntent, locale string) string {
+	lang, ok := NormalizeLocale(locale)
+	if !ok {
+		lang = DefaultLocale
+	}
+	tmpl, ok := pressCatalog[lang][intent.Type]
+	if !ok {
+		return ""
+	}
+
+	args := intent.Provinces
+	switch intent.Type {
+	case IntentProposeAlliance:
+		args = nil
+		if intent.TargetPower != "" {
+			args = []string{powerLabel(intent.TargetPower)}
+		}
+	case IntentProposeOrders:
+		args = nil
+		if len(intent.Orders) > 0 {
+			args = []string{diplomacy.FormatDSON(intent.Orders)}
+		}
+	}
+	switch {
+	case len(args) >= 2 && tmpl.two != "":
+		return fmt.Sprintf(tmpl.two, args[0], args[1])
+	case len(args) >= 1 && tmpl.one != "":
+		return fmt.Sprintf(tmpl.one, args[0])
+	}
+	return tmpl.none
+}
+
+// ParseIntentType is the inverse of IntentType.String.
+func ParseIntentType(name string) (IntentType, bool) {
+	for t := IntentRequestSupport; t <= IntentProposeOrders; t++ {
+		if t.String() == name {
+			return t, true
+		}
+	}
+	return 0, false
+}
+
+// IntentAttachment converts an intent into the machine-readable form stored on
+// a message. Sender and recipient are implied by the message itself.
+func IntentAttachment(intent DiplomaticIntent) *model.MessageIntent {
+	a := &model.MessageIntent{
+		Type:        intent.Type.String(),
+		Provinces:   intent.Provinces,
+		TargetPower: string(intent.TargetPower),
+	}
+	if len(intent.Orders) > 0 {
+		a.Orders = diplomacy.FormatDSON(intent.Orders)
+	}
+	return a
+}
+
+// IntentFromAttachment converts a stored message intent back into a DiplomaticIntent.
+func IntentFromAttachment(a *model.MessageIntent) (*DiplomaticIntent, error) {
+	t, ok := ParseIntentType(a.Type)
+	if !ok {
+		return nil, fmt.Errorf("unknown intent type: %s", a.Type)
+	}
+	intent := &DiplomaticIntent{
+		Type:        t,
+		Provinces:   a.Provinces,
+		TargetPower: diplomacy.Power(a.TargetPower),
+	}
+	if a.Orders != "" {
+		orders, err := diplomacy.ParseDSON(a.Orders)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proposed orders: %w", err)
+		}
+		intent.Orders = orders
+	}
+	if t == IntentProposeOrders && len(intent.Orders) == 0 {
+		return nil, fmt.Errorf("order proposal has no orders")
+	}
+	return intent, nil
+}
+
+// IntentFromMessage returns the intent of a message, preferring its attachment
+// and falling back to parsing English canned text for older messages.
+func IntentFromMessage(msg model.Message) (*DiplomaticIntent, error) {
+	if msg.Intent != nil {
+		return IntentFromAttachment(msg.Intent)
+	}
+	return ParseCannedMessage(msg.Content)
+}
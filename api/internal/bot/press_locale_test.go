@@ -0,0 +1,73 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+func TestFormatCannedMessageLocale(t *testing.T) {
+	deal := DiplomaticIntent{Type: IntentOfferDeal, Provinces: []string{"bel", "hol"}}
+	tests := []struct {
+		locale string
+		want   string
+	}{
+		{"en", "Deal: I take bel, you take hol"},
+		{"fr", "Marché : je prends bel, tu prends hol"},
+		{"de-AT", "Abmachung: Ich nehme bel, du nimmst hol"},
+		{"xx", "Deal: I take bel, you take hol"},
+		{"", "Deal: I take bel, you take hol"},
+	}
+	for _, tt := range tests {
+		if got := FormatCannedMessageLocale(deal, tt.locale); got != tt.want {
+			t.Errorf("locale %q: got %q, want %q", tt.locale, got, tt.want)
+		}
+	}
+
+	alliance := DiplomaticIntent{Type: IntentProposeAlliance, TargetPower: diplomacy.Turkey}
+	if got := FormatCannedMessageLocale(alliance, "es"); got != "Trabajemos juntos contra Turkey" {
+		t.Errorf("unexpected alliance rendering: %q", got)
+	}
+}
+
+func TestPressCatalogComplete(t *testing.T) {
+	for locale, templates := range pressCatalog {
+		for it := IntentRequestSupport; it <= IntentProposeOrders; it++ {
+			if templates[it].none == "" {
+				t.Errorf("locale %s missing fallback template for %s", locale, it)
+			}
+		}
+	}
+}
+
+func TestIntentAttachmentRoundTrip(t *testing.T) {
+	intent := DiplomaticIntent{Type: IntentRequestSupport, Provinces: []string{"bur", "mun"}}
+	got, err := IntentFromAttachment(IntentAttachment(intent))
+	if err != nil {
+		t.Fatalf("IntentFromAttachment: %v", err)
+	}
+	if got.Type != intent.Type || len(got.Provinces) != 2 || got.Provinces[1] != "mun" {
+		t.Errorf("round trip mismatch: %+v", got)
+	}
+
+	if _, err := IntentFromAttachment(&model.MessageIntent{Type: "bribe"}); err == nil {
+		t.Error("expected error for unknown intent type")
+	}
+}
+
+func TestIntentFromMessagePrefersAttachment(t *testing.T) {
+	msg := model.Message{
+		Content: "Pas d'accord",
+		Intent:  &model.MessageIntent{Type: "reject"},
+	}
+	got, err := IntentFromMessage(msg)
+	if err != nil || got.Type != IntentReject {
+		t.Errorf("expected reject from attachment, got %+v, %v", got, err)
+	}
+
+	got, err = IntentFromMessage(model.Message{Content: "Agreed"})
+	if err != nil || got.Type != IntentAccept {
+		t.Errorf("expected accept parsed from English text, got %+v, %v", got, err)
+	}
+}
@@ -0,0 +1,335 @@
+package bot
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// powerWords maps the names and adjectives players use for each power.
+var powerWords = map[string]diplomacy.Power{
+	"austria": diplomacy.Austria, "austrian": diplomacy.Austria, "austrians": diplomacy.Austria,
+	"england": diplomacy.England, "english": diplomacy.England, "britain": diplomacy.England, "british": diplomacy.England,
+	"france": diplomacy.France, "french": diplomacy.France,
+	"germany": diplomacy.Germany, "german": diplomacy.Germany, "germans": diplomacy.Germany,
+	"italy": diplomacy.Italy, "italian": diplomacy.Italy, "italians": diplomacy.Italy,
+	"russia": diplomacy.Russia, "russian": diplomacy.Russia, "russians": diplomacy.Russia,
+	"turkey": diplomacy.Turkey, "turkish": diplomacy.Turkey, "turks": diplomacy.Turkey, "ottoman": diplomacy.Turkey, "ottomans": diplomacy.Turkey,
+}
+
+// ambiguousAbbrevs are province IDs that are also everyday English words;
+// in free text they only name a province when written in upper case.
+var ambiguousAbbrevs = map[string]bool{
+	"arm": true, "bar": true, "bot": true, "con": true, "den": true, "gas": true,
+	"hel": true, "mar": true, "nap": true, "pie": true, "war": true, "eng": true,
+	"bud": true, "gal": true, "rum": true,
+}
+
+// pressKeywords lists, per intent type, phrases that signal it in free text,
+// in the order the types are tried: a message asking for support that also
+// says "deal" is a support request, not an acceptance. Order proposals are
+// only understood as canned press, since they need DSON.
+var pressKeywords = []struct {
+	intent  IntentType
+	phrases []string
+}{
+	{IntentReject, []string{"no deal", "no thanks", "not interested", "i decline", "i refuse", "i can't agree", "i cannot agree", "i'll pass", "no way", "doesn't work for me"}},
+	{IntentThreaten, []string{"coming for", "back off", "or else", "you'll regret", "you will regret", "i will attack", "i'll attack", "i'm taking", "pull back", "last warning"}},
+	{IntentOfferDeal, []string{"you take", "you get", "you can have", "let me have", "is yours", "i take", "i get", "split"}},
+	{IntentRequestSupport, []string{"support", "help me", "back me", "lend a hand", "back my", "hold the line"}},
+	{IntentProposeAlliance, []string{"alliance", "ally", "allies", "work together", "team up", "teamed up", "join forces", "together", "coordinate"}},
+	{IntentProposeNonAggression, []string{"don't attack", "do not attack", "won't attack", "will not attack", "non-aggression", "nonaggression", "peace", "truce", "stay out", "stay clear", "keep away", "leave each other", "no designs", "untouched", "dmz", "demilitarize"}},
+	{IntentAccept, []string{"agreed", "agree", "deal", "accept", "yes", "sure", "sounds good", "works for me", "ok", "okay", "done", "fine by me", "count me in"}},
+}
+
+// negators are the words that, shortly before a keyword, deny it: "I don't
+// agree" is no acceptance and "I won't attack you" no threat.
+var negators = map[string]bool{
+	"not": true, "never": true, "don't": true, "dont": true, "won't": true, "wont": true,
+	"can't": true, "cant": true, "cannot": true, "doesn't": true, "isn't": true,
+	"wouldn't": true, "shouldn't": true, "didn't": true, "neither": true, "nor": true,
+}
+
+// negationReach is how many words before a keyword a negator may stand.
+const negationReach = 3
+
+// ParsePress interprets a free-text message as a diplomatic intent by
+// looking for keywords of each intent type and naming the provinces and
+// powers it mentions, using m for province names. Keywords a negator
+// denies are skipped, and a denied acceptance reads as a rejection.
+// Acceptances and rejections answer something, so they are only read when
+// pending reports that the sender has a proposal to answer. Canned press is
+// parsed exactly; anything else is a best guess, marked Guessed, so callers
+// should not treat it as binding. It returns an error when no intent is
+// recognized.
+func ParsePress(content string, m *diplomacy.DiplomacyMap, pending bool) (*DiplomaticIntent, error) {
+	if intent, err := ParseCannedMessage(content); err == nil {
+		return intent, nil
+	}
+	text := " " + normalizePress(content) + " "
+	provinces, spans := pressProvinces(content, text, m)
+	powers := pressPowers(text, spans)
+
+	for _, kw := range pressKeywords {
+		reply := kw.intent == IntentAccept || kw.intent == IntentReject
+		if reply && !pending {
+			continue
+		}
+		at, denied := -1, false
+		for _, phrase := range kw.phrases {
+			i, neg := findPhrase(text, phrase, kw.intent != IntentReject)
+			if i >= 0 && (at < 0 || i < at) {
+				at = i
+			}
+			denied = denied || neg
+		}
+		if at < 0 {
+			if kw.intent == IntentAccept && denied {
+				return &DiplomaticIntent{Type: IntentReject, Guessed: true}, nil
+			}
+			continue
+		}
+		intent := &DiplomaticIntent{Type: kw.intent, Guessed: true}
+		switch kw.intent {
+		case IntentThreaten, IntentProposeNonAggression:
+			if len(provinces) > 0 {
+				intent.Provinces = []string{provinces[0].id}
+			}
+		case IntentRequestSupport:
+			intent.Provinces = supportProvinces(text, provinces)
+		case IntentOfferDeal:
+			mine, yours := splitDeal(text, provinces)
+			if mine == "" || yours == "" {
+				continue // no split named; perhaps an acceptance
+			}
+			intent.Provinces = []string{mine, yours}
+		case IntentProposeAlliance:
+			intent.TargetPower = allianceTarget(text, powers)
+		}
+		return intent, nil
+	}
+	return nil, fmt.Errorf("unrecognized press: %s", content)
+}
+
+// findPhrase returns the offset in text of the first mention of phrase,
+// or -1. With negatable set, mentions a negator denies are passed over,
+// and denied reports whether there were any.
+func findPhrase(text, phrase string, negatable bool) (at int, denied bool) {
+	word := " " + phrase + " "
+	for from := 0; ; {
+		i := strings.Index(text[from:], word)
+		if i < 0 {
+			return -1, denied
+		}
+		i += from
+		if !negatable || !negatedAt(text, i) {
+			return i, denied
+		}
+		denied = true
+		from = i + 1
+	}
+}
+
+// negatedAt reports whether a negator stands within negationReach words
+// before offset i of text.
+func negatedAt(text string, i int) bool {
+	words := strings.Fields(text[:i])
+	for k := len(words) - 1; k >= 0 && k >= len(words)-negationReach; k-- {
+		if negators[words[k]] {
+			return true
+		}
+	}
+	return false
+}
+
+// pressMention is a province or power named in a message, at a byte offset
+// of the normalized text.
+type pressMention struct {
+	id  string
+	pos int
+}
+
+// normalizePress lower-cases content and turns everything but letters,
+// digits, apostrophes and hyphens into single spaces.
+func normalizePress(content string) string {
+	var b strings.Builder
+	space := true
+	for _, r := range strings.ToLower(content) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '\'' || r == '-':
+			b.WriteRune(r)
+			space = false
+		case r == '’':
+			b.WriteRune('\'')
+			space = false
+		case !space:
+			b.WriteByte(' ')
+			space = true
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// pressProvinces returns the provinces named in text, in order of mention,
+// by full name or by ID, and the spans their full names cover so power
+// adjectives inside them ("English Channel") are not read as powers.
+// Ambiguous IDs only count when written in upper case in the original.
+func pressProvinces(content, text string, m *diplomacy.DiplomacyMap) ([]pressMention, [][2]int) {
+	var found []pressMention
+	var spans [][2]int
+	seen := make(map[string]bool)
+	covered := func(i int) bool {
+		for _, s := range spans {
+			if i >= s[0] && i < s[1] {
+				return true
+			}
+		}
+		return false
+	}
+	// Longest names first, so "North Africa" is not also read as "North Sea".
+	ids := make([]string, 0, len(m.Provinces))
+	for id := range m.Provinces {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := m.Provinces[ids[i]].Name, m.Provinces[ids[j]].Name
+		if len(a) != len(b) {
+			return len(a) > len(b)
+		}
+		return ids[i] < ids[j]
+	})
+	for _, id := range ids {
+		name := " " + normalizePress(m.Provinces[id].Name) + " "
+		if i := strings.Index(text, name); i >= 0 && !covered(i+1) {
+			found = append(found, pressMention{id, i})
+			spans = append(spans, [2]int{i + 1, i + len(name) - 1})
+			seen[id] = true
+		}
+	}
+	upper := make(map[string]bool)
+	for _, w := range strings.FieldsFunc(content, func(r rune) bool { return !unicode.IsLetter(r) }) {
+		if w == strings.ToUpper(w) {
+			upper[strings.ToLower(w)] = true
+		}
+	}
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		word := " " + id + " "
+		i := strings.Index(text, word)
+		if i < 0 || covered(i+1) {
+			continue
+		}
+		if ambiguousAbbrevs[id] && !upper[id] {
+			continue
+		}
+		found = append(found, pressMention{id, i})
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].pos < found[j].pos })
+	return found, spans
+}
+
+// pressPowers returns the powers named in text outside spans, in order of
+// mention.
+func pressPowers(text string, spans [][2]int) []pressMention {
+	var found []pressMention
+	pos := 0
+	for _, w := range strings.Split(text, " ") {
+		start := pos
+		pos += len(w) + 1
+		p, ok := powerWords[w]
+		if !ok {
+			continue
+		}
+		inside := false
+		for _, s := range spans {
+			if start >= s[0] && start < s[1] {
+				inside = true
+			}
+		}
+		if !inside {
+			found = append(found, pressMention{string(p), start})
+		}
+	}
+	return found
+}
+
+// supportProvinces reads the provinces of a support request: the supporting
+// province follows "from" and the target is the other one named. With no
+// "from", they are taken in order of mention; a lone province is where
+// support is wanted.
+func supportProvinces(text string, provinces []pressMention) []string {
+	switch len(provinces) {
+	case 0:
+		return nil
+	case 1:
+		return []string{provinces[0].id}
+	}
+	if i := strings.Index(text, " from "); i >= 0 {
+		for k, p := range provinces {
+			if p.pos > i {
+				to := provinces[0]
+				if k == 0 {
+					to = provinces[1]
+				}
+				return []string{p.id, to.id}
+			}
+		}
+	}
+	return []string{provinces[0].id, provinces[1].id}
+}
+
+// splitDeal reads a proposed split of two provinces: the recipient's is the
+// one following "you take" or preceding "is yours", and the sender's is the
+// other. Without such a phrase the sender's is named first.
+func splitDeal(text string, provinces []pressMention) (mine, yours string) {
+	if len(provinces) < 2 {
+		return "", ""
+	}
+	mine, yours = provinces[0].id, provinces[1].id
+	for _, phrase := range []string{" you take ", " you get ", " you can have "} {
+		if i := strings.Index(text, phrase); i >= 0 {
+			for _, p := range provinces {
+				if p.pos > i {
+					yours = p.id
+					break
+				}
+			}
+		}
+	}
+	for _, phrase := range []string{" goes to you ", " is yours "} {
+		if i := strings.Index(text, phrase); i >= 0 {
+			for _, p := range provinces {
+				if p.pos < i {
+					yours = p.id
+				}
+			}
+		}
+	}
+	for _, p := range provinces {
+		if p.id != yours {
+			return p.id, yours
+		}
+	}
+	return mine, yours
+}
+
+// allianceTarget returns the power an alliance proposal is against: the
+// first power named after "against", or else the only power named.
+func allianceTarget(text string, powers []pressMention) diplomacy.Power {
+	if i := strings.Index(text, " against "); i >= 0 {
+		for _, p := range powers {
+			if p.pos > i {
+				return diplomacy.Power(p.id)
+			}
+		}
+	}
+	if len(powers) == 1 {
+		return diplomacy.Power(powers[0].id)
+	}
+	return ""
+}
@@ -0,0 +1,60 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+func TestParsePress(t *testing.T) {
+	m := diplomacy.StandardMap()
+	tests := []struct {
+		text      string
+		intent    IntentType
+		provinces []string
+		target    diplomacy.Power
+	}{
+		{"Agreed", IntentAccept, nil, ""},
+		{"Sure, sounds good to me!", IntentAccept, nil, ""},
+		{"No thanks, I'm not interested.", IntentReject, nil, ""},
+		{"Can you support me from Burgundy into Munich?", IntentRequestSupport, []string{"bur", "mun"}, ""},
+		{"I'm moving on mun, please support from ber", IntentRequestSupport, []string{"ber", "mun"}, ""},
+		{"Want to team up against the Turks?", IntentProposeAlliance, nil, diplomacy.Turkey},
+		{"Let's form an alliance and crush Austria", IntentProposeAlliance, nil, diplomacy.Austria},
+		{"Please stay out of the English Channel and we'll have peace", IntentProposeNonAggression, []string{"eng"}, ""},
+		{"Truce?", IntentProposeNonAggression, nil, ""},
+		{"Back off from St. Petersburg or else.", IntentThreaten, []string{"stp"}, ""},
+		{"You take Holland, I take Belgium.", IntentOfferDeal, []string{"bel", "hol"}, ""},
+		{"I'll go to war if you move to the Black Sea, or else", IntentThreaten, []string{"bla"}, ""},
+		{"I don't agree with that at all", IntentReject, nil, ""},
+		{"That's not ok", IntentReject, nil, ""},
+		{"I won't attack Burgundy, let's have peace", IntentProposeNonAggression, []string{"bur"}, ""},
+		{"I'm not coming for you; can you support me into Munich?", IntentRequestSupport, []string{"mun"}, ""},
+	}
+	for _, tt := range tests {
+		got, err := ParsePress(tt.text, m, true)
+		if err != nil {
+			t.Errorf("%q: %v", tt.text, err)
+			continue
+		}
+		if _, canned := ParseCannedMessage(tt.text); canned != nil && !got.Guessed {
+			t.Errorf("%q: expected a free-text intent to be marked guessed", tt.text)
+		}
+		if got.Type != tt.intent || got.TargetPower != tt.target || strings.Join(got.Provinces, ",") != strings.Join(tt.provinces, ",") {
+			t.Errorf("%q parsed as %s %v %q, want %s %v %q", tt.text, got.Type, got.Provinces, got.TargetPower, tt.intent, tt.provinces, tt.target)
+		}
+	}
+
+	if _, err := ParsePress("How was your weekend?", m, true); err == nil {
+		t.Error("expected small talk to be unrecognized")
+	}
+	for _, text := range []string{"Sure, sounds good to me!", "No thanks, I'm not interested.", "ok"} {
+		if got, err := ParsePress(text, m, false); err == nil {
+			t.Errorf("%q: expected no answer without a pending proposal, got %s", text, got.Type)
+		}
+	}
+	if got, err := ParsePress("Sure, let's work together against Austria", m, false); err != nil || got.Type != IntentProposeAlliance {
+		t.Errorf("expected an alliance proposal without a pending one, got %+v, %v", got, err)
+	}
+}
@@ -0,0 +1,131 @@
+package bot
+
+import (
+	"hash/fnv"
+	"strings"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// Bot press styles, chosen per game.
+const (
+	PressStylePersonality = "personality" // canned press wrapped in a character voice
+	PressStyleTerse       = "terse"       // canned press only
+	PressStyleNatural     = "natural"     // varied natural-language press in a character voice
+)
+
+// ValidPressStyle reports whether style is a known bot press style.
+func ValidPressStyle(style string) bool {
+	return style == PressStylePersonality || style == PressStyleTerse || style == PressStyleNatural
+}
+
+// pressVoice is a power's character: lines said before and after the
+// canned sentence. Closers are only used on friendly intents.
+type pressVoice struct {
+	openers []string
+	closers []string
+}
+
+// pressVoices gives each power a recognisable voice. Flavor is English only;
+// other locales get the plain canned text.
+var pressVoices = map[diplomacy.Power]pressVoice{
+	diplomacy.Austria: {
+		openers: []string{"From Vienna, with the Emperor's compliments.", "The Habsburg court has considered the matter.", "Vienna sends word."},
+		closers: []string{"Let us keep the Balkans civil.", "The Empire remembers its friends.", "May the Danube run calm between us."},
+	},
+	diplomacy.England: {
+		openers: []string{"A word from London, old chap.", "The Admiralty sends its regards.", "Between gentlemen, then."},
+		closers: []string{"Rule, Britannia and all that.", "Tea and a treaty, what could be better?", "Do keep your fleets tidy."},
+	},
+	diplomacy.France: {
+		openers: []string{"Mon ami, a proposition from Paris.", "Paris has been thinking of you.", "Allow me, in the spirit of la belle France."},
+		closers: []string{"Vive l'entente!", "We shall toast to it in Burgundy.", "À bientôt, mon ami."},
+	},
+	diplomacy.Germany: {
+		openers: []string{"Berlin speaks plainly.", "The General Staff has reviewed the map.", "Efficiency demands I be direct."},
+		closers: []string{"Order on the continent benefits us both.", "Discipline will carry the day.", "Berlin keeps its word."},
+	},
+	diplomacy.Italy: {
+		openers: []string{"Amico, Rome has a thought.", "A whisper from the Eternal City.", "Ciao! Italy has been watching the board."},
+		closers: []string{"Rome was not built in a day, but together we build quickly.", "Salute to our understanding!", "Italy never forgets a kindness."},
+	},
+	diplomacy.Russia: {
+		openers: []string{"Comrade, the Tsar speaks.", "Moscow is vast, and so is its patience.", "A message carried across the steppe."},
+		closers: []string{"Russia has a long memory and a longer winter.", "To the friendship of great powers!", "The bear is generous to its friends."},
+	},
+	diplomacy.Turkey: {
+		openers: []string{"Greetings from the Sublime Porte.", "Constantinople sends word across the straits.", "The Sultan extends an open hand."},
+		closers: []string{"The straits are wide enough for friends.", "May our fortunes rise like the crescent moon.", "The Porte honors its bargains."},
+	},
+}
+
+// pressTones colors each difficulty's press: easy bots are chatty and warm,
+// hard bots are curt and a little menacing.
+var pressTones = map[string]struct {
+	friendly []string
+	hostile  []string
+}{
+	"easy": {
+		friendly: []string{"I really hope we can be friends!", "Honestly, this would make my day.", "No pressure, of course!"},
+		hostile:  []string{"Sorry, but I have to say it.", "Please don't make this awkward."},
+	},
+	"medium": {
+		friendly: []string{"I think this works for both of us.", "Let me know your thoughts.", "A fair arrangement, I believe."},
+		hostile:  []string{"I'd rather not, but I will.", "Consider this a fair warning."},
+	},
+	"hard": {
+		friendly: []string{"Think it over carefully.", "An offer like this won't come twice.", "Choose wisely."},
+		hostile:  []string{"You have been warned.", "I never bluff.", "Make your peace with it."},
+	},
+}
+
+// FlavorPress wraps a canned message in the sending power's voice and the
+// bot difficulty's tone. The canned sentence is kept verbatim so players can
+// still read the offer at a glance; the structured intent travels separately
+// as the message attachment. seed makes the choice of lines deterministic,
+// so the same message renders the same way every time.
+func FlavorPress(intent DiplomaticIntent, text string, from diplomacy.Power, difficulty string, seed string) string {
+	voice, ok := pressVoices[from]
+	if !ok || text == "" {
+		return text
+	}
+	h := fnv.New64a()
+	h.Write([]byte(seed))
+	h.Write([]byte(intent.Type.String()))
+	r := h.Sum64()
+	pick := func(lines []string) string {
+		if len(lines) == 0 {
+			return ""
+		}
+		line := lines[r%uint64(len(lines))]
+		r /= uint64(len(lines))
+		return line
+	}
+
+	tone, ok := pressTones[difficulty]
+	if !ok {
+		tone = pressTones["medium"]
+	}
+	if intent.Type == IntentProposeOrders {
+		// Keep the DSON order set last so it reads cleanly.
+		return strings.Join([]string{pick(voice.openers), pick(tone.friendly), text}, " ")
+	}
+	parts := []string{pick(voice.openers), sentence(text)}
+	switch intent.Type {
+	case IntentThreaten, IntentReject:
+		parts = append(parts, pick(tone.hostile))
+	case IntentAccept:
+		parts = append(parts, pick(voice.closers))
+	default:
+		parts = append(parts, pick(tone.friendly), pick(voice.closers))
+	}
+	return strings.Join(parts, " ")
+}
+
+// sentence ends text with punctuation so flavor lines read as prose.
+func sentence(text string) string {
+	if strings.HasSuffix(text, ".") || strings.HasSuffix(text, "!") || strings.HasSuffix(text, "?") {
+		return text
+	}
+	return text + "."
+}
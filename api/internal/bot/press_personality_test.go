@@ -0,0 +1,51 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+func TestFlavorPressKeepsCannedText(t *testing.T) {
+	intent := DiplomaticIntent{Type: IntentProposeNonAggression, Provinces: []string{"bur"}}
+	canned := FormatCannedMessage(intent)
+
+	for _, power := range diplomacy.AllPowers() {
+		for _, difficulty := range []string{"easy", "medium", "hard", "gonnx"} {
+			got := FlavorPress(intent, canned, power, difficulty, "phase-1")
+			if !strings.Contains(got, canned) || got == canned {
+				t.Errorf("%s/%s: expected flavored text around %q, got %q", power, difficulty, canned, got)
+			}
+			if again := FlavorPress(intent, canned, power, difficulty, "phase-1"); again != got {
+				t.Errorf("%s/%s: flavor is not deterministic: %q vs %q", power, difficulty, got, again)
+			}
+		}
+	}
+}
+
+func TestFlavorPressVoices(t *testing.T) {
+	threat := DiplomaticIntent{Type: IntentThreaten, Provinces: []string{"mun"}}
+	got := FlavorPress(threat, FormatCannedMessage(threat), diplomacy.Russia, "hard", "s")
+	if !strings.Contains(got, "I'm coming for mun — back off.") {
+		t.Errorf("expected the threat as a sentence, got %q", got)
+	}
+	if !containsAny(got, pressVoices[diplomacy.Russia].openers) || !containsAny(got, pressTones["hard"].hostile) {
+		t.Errorf("expected a Russian opener and a hard hostile line, got %q", got)
+	}
+
+	orders := DiplomaticIntent{Type: IntentProposeOrders, Orders: []diplomacy.DSONOrder{{Type: diplomacy.DSONMove, Location: "mun", Target: "bur"}}}
+	canned := FormatCannedMessage(orders)
+	if got := FlavorPress(orders, canned, diplomacy.Germany, "medium", "s"); !strings.HasSuffix(got, canned) {
+		t.Errorf("expected proposed orders to end the message, got %q", got)
+	}
+}
+
+func containsAny(s string, lines []string) bool {
+	for _, l := range lines {
+		if strings.Contains(s, l) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+package bot
+
+import (
+	"fmt"
+	"hash/fnv"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// pressPhrasings holds the English wordings of each intent type. Like
+// pressTemplate, variants are chosen by how many arguments the intent
+// carries; arguments are referenced by index so a wording may reorder them.
+var pressPhrasings = map[IntentType]struct {
+	two, one, none []string
+}{
+	IntentRequestSupport: {
+		two: []string{
+			"Could you lend a hand from %[1]s into %[2]s this turn?",
+			"I'm moving on %[2]s and a support from %[1]s would make all the difference.",
+			"Back my push into %[2]s from %[1]s and I won't forget it.",
+		},
+		one: []string{
+			"I could use your support in %[1]s.",
+			"Would you hold the line with me at %[1]s?",
+			"%[1]s is under pressure. Can I count on your support there?",
+		},
+		none: []string{
+			"I could use a little help this turn.",
+			"Any chance of some support from your side?",
+		},
+	},
+	IntentProposeNonAggression: {
+		one: []string{
+			"Let's keep our armies out of each other's way. Stay clear of %[1]s and I'll leave your lands alone.",
+			"I have no designs on your centers. All I ask is that %[1]s stays untouched.",
+			"Keep away from %[1]s and you'll find me a quiet neighbour.",
+		},
+		none: []string{
+			"Neither of us gains by fighting. Shall we agree to leave each other be?",
+			"How about a truce? You stay out of my way and I'll stay out of yours.",
+			"I'd rather not waste units on our border. Peace between us?",
+		},
+	},
+	IntentProposeAlliance: {
+		one: []string{
+			"%[1]s is getting too comfortable. Shall we do something about it together?",
+			"If we coordinate, %[1]s won't know what hit them.",
+			"I think it's time we teamed up against %[1]s.",
+		},
+		none: []string{
+			"We'd be stronger together. What do you say to an alliance?",
+			"I'd like us to coordinate from here on.",
+			"Let's join forces; there is plenty on the board for both of us.",
+		},
+	},
+	IntentThreaten: {
+		one: []string{
+			"%[1]s is mine. Pull back while you still can.",
+			"I'm taking %[1]s, with or without your blessing.",
+			"Stand in my way at %[1]s and you'll regret it.",
+		},
+		none: []string{
+			"Back off, or this ends badly for you.",
+			"You're testing my patience.",
+		},
+	},
+	IntentOfferDeal: {
+		two: []string{
+			"Here's a fair split: %[1]s goes to me and %[2]s goes to you.",
+			"You take %[2]s, I take %[1]s, and we both come out ahead.",
+			"Let me have %[1]s and I'll see that %[2]s is yours.",
+		},
+		none: []string{
+			"I think we can strike a bargain.",
+			"There's a deal to be made here, if you're willing.",
+		},
+	},
+	IntentAccept: {
+		none: []string{"Agreed.", "You have a deal.", "That works for me.", "Consider it done."},
+	},
+	IntentReject: {
+		none: []string{"No deal.", "I'm afraid I can't agree to that.", "That doesn't work for me.", "I'll pass."},
+	},
+	IntentProposeOrders: {
+		one: []string{
+			"Here's what I have in mind: %[1]s",
+			"This is how I see the turn going: %[1]s",
+			"If you're in, these are the moves: %[1]s",
+		},
+		none: []string{"I have a plan, if you'll hear it."},
+	},
+}
+
+// RenderPress words intent as natural English press from power from, with
+// province and power names spelled out, in the voice of FlavorPress. seed
+// picks the wording deterministically, so a message renders the same way
+// every time while different phases and recipients read differently. It
+// returns "" for intent types it cannot word.
+func RenderPress(intent DiplomaticIntent, from diplomacy.Power, difficulty, seed string, m *diplomacy.DiplomacyMap) string {
+	phrasings, ok := pressPhrasings[intent.Type]
+	if !ok {
+		return ""
+	}
+	var args []any
+	switch intent.Type {
+	case IntentProposeAlliance:
+		if intent.TargetPower != "" {
+			args = []any{powerLabel(intent.TargetPower)}
+		}
+	case IntentProposeOrders:
+		if len(intent.Orders) > 0 {
+			args = []any{diplomacy.FormatDSON(intent.Orders)}
+		}
+	default:
+		for _, id := range intent.Provinces {
+			args = append(args, provinceLabel(id, m))
+		}
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(seed))
+	h.Write([]byte("phrasing"))
+	r := h.Sum64()
+	pick := func(lines []string) string {
+		return lines[r%uint64(len(lines))]
+	}
+	var text string
+	switch {
+	case len(args) >= 2 && len(phrasings.two) > 0:
+		text = fmt.Sprintf(pick(phrasings.two), args[0], args[1])
+	case len(args) >= 1 && len(phrasings.one) > 0:
+		text = fmt.Sprintf(pick(phrasings.one), args[0])
+	default:
+		text = pick(phrasings.none)
+	}
+	return FlavorPress(intent, text, from, difficulty, seed)
+}
+
+// provinceLabel returns the display name of a province, or its ID if the map
+// does not know it.
+func provinceLabel(id string, m *diplomacy.DiplomacyMap) string {
+	if m != nil {
+		if p, ok := m.Provinces[id]; ok && p.Name != "" {
+			return p.Name
+		}
+	}
+	return id
+}
@@ -0,0 +1,60 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+func TestRenderPressVariesAndNamesProvinces(t *testing.T) {
+	m := diplomacy.StandardMap()
+	intent := DiplomaticIntent{Type: IntentRequestSupport, Provinces: []string{"bur", "mun"}}
+
+	seen := make(map[string]bool)
+	for _, seed := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		got := RenderPress(intent, diplomacy.France, "medium", seed, m)
+		if !strings.Contains(got, "Burgundy") || !strings.Contains(got, "Munich") {
+			t.Errorf("expected province names, got %q", got)
+		}
+		if strings.Contains(got, FormatCannedMessage(intent)) {
+			t.Errorf("expected natural wording, got the canned text %q", got)
+		}
+		if again := RenderPress(intent, diplomacy.France, "medium", seed, m); again != got {
+			t.Errorf("rendering is not deterministic: %q vs %q", got, again)
+		}
+		seen[got] = true
+	}
+	if len(seen) < 3 {
+		t.Errorf("expected varied phrasing, got %d distinct messages", len(seen))
+	}
+}
+
+func TestRenderPressRoundTrips(t *testing.T) {
+	m := diplomacy.StandardMap()
+	intents := []DiplomaticIntent{
+		{Type: IntentRequestSupport, Provinces: []string{"bur", "mun"}},
+		{Type: IntentRequestSupport, Provinces: []string{"gal"}},
+		{Type: IntentProposeNonAggression, Provinces: []string{"tyr"}},
+		{Type: IntentProposeNonAggression},
+		{Type: IntentProposeAlliance, TargetPower: diplomacy.Turkey},
+		{Type: IntentThreaten, Provinces: []string{"stp"}},
+		{Type: IntentOfferDeal, Provinces: []string{"bel", "hol"}},
+		{Type: IntentAccept},
+		{Type: IntentReject},
+	}
+	for _, want := range intents {
+		for _, seed := range []string{"s1", "s2", "s3", "s4", "s5", "s6"} {
+			// Voice lines are not part of the intent; parse the wording alone.
+			text := RenderPress(want, "", "medium", seed, m)
+			got, err := ParsePress(text, m, true)
+			if err != nil {
+				t.Errorf("%s: %q: %v", want.Type, text, err)
+				continue
+			}
+			if got.Type != want.Type || got.TargetPower != want.TargetPower || strings.Join(got.Provinces, ",") != strings.Join(want.Provinces, ",") {
+				t.Errorf("%q parsed as %+v, want %+v", text, *got, want)
+			}
+		}
+	}
+}
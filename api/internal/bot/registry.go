@@ -0,0 +1,224 @@
+package bot
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// DefaultStrategy is the strategy used when a requested name is not registered.
+const DefaultStrategy = "easy"
+
+// StrategyCapabilities describes which optional behaviours a strategy supports.
+type StrategyCapabilities struct {
+	Diplomacy   bool `json:"diplomacy"`    // implements DiplomaticStrategy
+	DrawVoting  bool `json:"draw_voting"`  // implements DrawVoter
+	TimeControl bool `json:"time_control"` // implements StrategyV2 and honours deadlines
+	Personality bool `json:"personality"`  // plays differently under WithPersonality
+	CustomMaps  bool `json:"custom_maps"`  // plays on custom variant maps, not only the standard map
+	Mercy       bool `json:"mercy"`        // keeps off the home centers a teaching game's mercy setting spares
+}
+
+// StrategyOption documents a constructor option a strategy accepts.
+type StrategyOption struct {
+	Name        string `json:"name"`
+	Default     string `json:"default,omitempty"`
+	Description string `json:"description"`
+}
+
+// StrategyOptions holds constructor option values keyed by StrategyOption.Name.
+type StrategyOptions map[string]string
+
+// Get returns the option value, or def if it is unset or empty.
+func (o StrategyOptions) Get(name, def string) string {
+	if v := o[name]; v != "" {
+		return v
+	}
+	return def
+}
+
+// StrategyRegistration describes a strategy and how to construct it.
+type StrategyRegistration struct {
+	Name         string               `json:"name"`
+	Aliases      []string             `json:"aliases,omitempty"`
+	Description  string               `json:"description"`
+	Capabilities StrategyCapabilities `json:"capabilities"`
+	Options      []StrategyOption     `json:"options,omitempty"`
+	Base         string               `json:"base,omitempty"` // strategy a variant was registered from
+	// New builds the strategy. It must return a usable Strategy, falling back
+	// to a simpler one if its own dependencies are unavailable.
+	New func(opts StrategyOptions) Strategy `json:"-"`
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]*StrategyRegistration{} // name or alias -> registration
+	registered []*StrategyRegistration
+)
+
+// RegisterStrategy adds a strategy to the registry. Strategies register
+// themselves from init functions; it panics if the name or an alias is
+// already taken or New is nil.
+func RegisterStrategy(reg StrategyRegistration) {
+	if err := register(reg); err != nil {
+		panic("bot: " + err.Error())
+	}
+}
+
+func register(reg StrategyRegistration) error {
+	if reg.Name == "" || reg.New == nil {
+		return fmt.Errorf("RegisterStrategy requires a name and constructor")
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	names := append([]string{reg.Name}, reg.Aliases...)
+	for _, name := range names {
+		if _, dup := registry[name]; dup {
+			return fmt.Errorf("strategy %q registered twice", name)
+		}
+	}
+	r := &reg
+	for _, name := range names {
+		registry[name] = r
+	}
+	registered = append(registered, r)
+	return nil
+}
+
+// RegisterStrategyVariant registers name as the base strategy with preset
+// options, e.g. a "tournament" realpolitik with its own engine_path. Options
+// passed when constructing the variant override the presets.
+func RegisterStrategyVariant(name, base string, presets StrategyOptions) error {
+	reg, ok := LookupStrategy(base)
+	if !ok {
+		return fmt.Errorf("strategy %q: unknown base strategy %q", name, base)
+	}
+	newBase := reg.New
+	return register(StrategyRegistration{
+		Name:         name,
+		Description:  fmt.Sprintf("%s (variant of %s)", reg.Description, reg.Name),
+		Capabilities: reg.Capabilities,
+		Options:      reg.Options,
+		Base:         cmp.Or(reg.Base, reg.Name),
+		New: func(opts StrategyOptions) Strategy {
+			merged := make(StrategyOptions, len(presets)+len(opts))
+			maps.Copy(merged, presets)
+			for k, v := range opts {
+				if v != "" {
+					merged[k] = v
+				}
+			}
+			return newBase(merged)
+		},
+	})
+}
+
+// RegisterStrategyVariants registers the variants in a comma-separated spec
+// of name=base entries with optional URL-encoded options, e.g.
+// "tournament=realpolitik?engine_path=/opt/rp,sparring=medium".
+func RegisterStrategyVariants(spec string) error {
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, base, ok := strings.Cut(entry, "=")
+		if !ok || name == "" || base == "" {
+			return fmt.Errorf("strategy variant %q: want name=base[?option=value&...]", entry)
+		}
+		base, query, _ := strings.Cut(base, "?")
+		values, err := url.ParseQuery(query)
+		if err != nil {
+			return fmt.Errorf("strategy variant %q: %w", name, err)
+		}
+		presets := make(StrategyOptions, len(values))
+		for k := range values {
+			presets[k] = values.Get(k)
+		}
+		if err := RegisterStrategyVariant(name, base, presets); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LookupStrategy returns the registration for a strategy name or alias.
+func LookupStrategy(name string) (StrategyRegistration, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	r, ok := registry[name]
+	if !ok {
+		return StrategyRegistration{}, false
+	}
+	return *r, true
+}
+
+// Strategies returns the registered strategies sorted by name.
+func Strategies() []StrategyRegistration {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	out := make([]StrategyRegistration, 0, len(registered))
+	for _, r := range registered {
+		out = append(out, *r)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// NewStrategy constructs the named strategy with the given options. Unknown
+// names fall back to DefaultStrategy.
+func NewStrategy(name string, opts StrategyOptions) Strategy {
+	reg, ok := LookupStrategy(name)
+	if !ok {
+		reg, _ = LookupStrategy(DefaultStrategy)
+	}
+	return reg.New(opts)
+}
+
+// NewStrategyForMap returns the named strategy like NewStrategy, or
+// DefaultStrategy if m is a custom variant map the strategy can't play.
+// Every strategy plays maps cut from the standard one, such as duel boards.
+func NewStrategyForMap(name string, opts StrategyOptions, m *diplomacy.DiplomacyMap) Strategy {
+	if !m.CutFromStandard() {
+		if reg, ok := LookupStrategy(name); !ok || !reg.Capabilities.CustomMaps {
+			return NewStrategy(DefaultStrategy, nil)
+		}
+	}
+	return NewStrategy(name, opts)
+}
+
+// cheaperStrategies maps each costly built-in strategy to the next cheaper
+// one, ending at DefaultStrategy.
+var cheaperStrategies = map[string]string{
+	"realpolitik": "hard",
+	"expert":      "hard",
+	"hard-gonnx":  "hard",
+	"hard":        "medium",
+	"medium":      DefaultStrategy,
+}
+
+// CheaperStrategy returns the strategy to downgrade name to when a game runs
+// over its compute budget, or "" if name is already among the cheapest.
+// Aliases and variants downgrade like the strategy they are based on;
+// unknown costly strategies drop straight to DefaultStrategy.
+func CheaperStrategy(name string) string {
+	reg, ok := LookupStrategy(name)
+	if !ok {
+		return ""
+	}
+	base := cmp.Or(reg.Base, reg.Name)
+	if next, ok := cheaperStrategies[base]; ok {
+		return next
+	}
+	switch base {
+	case DefaultStrategy, "random", "hold":
+		return ""
+	}
+	return DefaultStrategy
+}
@@ -0,0 +1,175 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+func TestStrategyForDifficulty_Registered(t *testing.T) {
+	tests := map[string]string{
+		"easy":    "easy",
+		"medium":  "medium",
+		"hard":    "hard",
+		"expert":  "expert",
+		"random":  "random",
+		"":        "easy",
+		"unknown": "easy",
+	}
+	for difficulty, want := range tests {
+		if got := StrategyForDifficulty(difficulty).Name(); got != want {
+			t.Errorf("StrategyForDifficulty(%q) = %s, want %s", difficulty, got, want)
+		}
+	}
+}
+
+func TestLookupStrategy_Alias(t *testing.T) {
+	for _, name := range []string{"realpolitik", "impossible", "external"} {
+		reg, ok := LookupStrategy(name)
+		if !ok {
+			t.Fatalf("%s not registered", name)
+		}
+		if reg.Name != "realpolitik" {
+			t.Errorf("%s resolved to %s, want realpolitik", name, reg.Name)
+		}
+	}
+}
+
+func TestStrategies_CapabilitiesMatchInterfaces(t *testing.T) {
+	for _, reg := range Strategies() {
+		if len(reg.Options) > 0 {
+			// Strategies with options may need external resources to construct.
+			continue
+		}
+		s := reg.New(nil)
+		if s.Name() != reg.Name {
+			// Fell back to another strategy; nothing to check.
+			continue
+		}
+		_, diplomatic := s.(DiplomaticStrategy)
+		_, voter := s.(DrawVoter)
+		_, v2 := s.(StrategyV2)
+		if diplomatic != reg.Capabilities.Diplomacy {
+			t.Errorf("%s: diplomacy capability %v, implements %v", reg.Name, reg.Capabilities.Diplomacy, diplomatic)
+		}
+		if voter != reg.Capabilities.DrawVoting {
+			t.Errorf("%s: draw voting capability %v, implements %v", reg.Name, reg.Capabilities.DrawVoting, voter)
+		}
+		if v2 != reg.Capabilities.TimeControl {
+			t.Errorf("%s: time control capability %v, implements %v", reg.Name, reg.Capabilities.TimeControl, v2)
+		}
+	}
+}
+
+func TestRegisterStrategy_DuplicatePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic on duplicate registration")
+		}
+	}()
+	RegisterStrategy(StrategyRegistration{
+		Name: "medium",
+		New:  func(StrategyOptions) Strategy { return &TacticalStrategy{} },
+	})
+}
+
+func TestRegisterStrategyVariants(t *testing.T) {
+	var got StrategyOptions
+	RegisterStrategy(StrategyRegistration{
+		Name:    "variant-base",
+		Options: []StrategyOption{{Name: "depth"}, {Name: "style"}},
+		New: func(opts StrategyOptions) Strategy {
+			got = opts
+			return HoldStrategy{}
+		},
+	})
+	if err := RegisterStrategyVariants("deep=variant-base?depth=3&style=calm, shallow=variant-base"); err != nil {
+		t.Fatalf("RegisterStrategyVariants: %v", err)
+	}
+
+	reg, ok := LookupStrategy("deep")
+	if !ok {
+		t.Fatal("deep not registered")
+	}
+	if len(reg.Options) != 2 {
+		t.Errorf("variant options = %v, want the base's", reg.Options)
+	}
+	NewStrategy("deep", StrategyOptions{"style": "wild"})
+	if got["depth"] != "3" || got["style"] != "wild" {
+		t.Errorf("deep constructed with %v, want depth=3 style=wild", got)
+	}
+	if _, ok := LookupStrategy("shallow"); !ok {
+		t.Error("shallow not registered")
+	}
+
+	for _, spec := range []string{"deep=variant-base", "x=no-such-base", "missing-base", "=variant-base"} {
+		if err := RegisterStrategyVariants(spec); err == nil {
+			t.Errorf("RegisterStrategyVariants(%q) succeeded, want error", spec)
+		}
+	}
+}
+
+func TestCheaperStrategy(t *testing.T) {
+	if err := RegisterStrategyVariant("cheaper-test-hard", "hard", nil); err != nil {
+		t.Fatalf("RegisterStrategyVariant: %v", err)
+	}
+	cases := map[string]string{
+		"impossible":        "hard",
+		"expert":            "hard",
+		"hard":              "medium",
+		"cheaper-test-hard": "medium",
+		"medium":            "easy",
+		"easy":              "",
+		"random":            "",
+		"no-such-strategy":  "",
+	}
+	for name, want := range cases {
+		if got := CheaperStrategy(name); got != want {
+			t.Errorf("CheaperStrategy(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestNewStrategyForMap_CustomMap(t *testing.T) {
+	v := &diplomacy.VariantDefinition{
+		Name:           "test-bot-map",
+		Powers:         []diplomacy.Power{diplomacy.France, diplomacy.Germany},
+		VictoryCenters: 3,
+		Provinces: []diplomacy.VariantProvince{
+			{ID: "a", Type: "coastal", SupplyCenter: true, Home: diplomacy.France},
+			{ID: "b", Type: "land", SupplyCenter: true},
+			{ID: "c", Type: "coastal", SupplyCenter: true, Home: diplomacy.Germany},
+			{ID: "s", Type: "sea"},
+		},
+		Adjacencies: []diplomacy.VariantAdjacency{
+			{From: "a", To: "b", Army: true}, {From: "b", To: "a", Army: true},
+			{From: "b", To: "c", Army: true}, {From: "c", To: "b", Army: true},
+			{From: "a", To: "s", Fleet: true}, {From: "s", To: "a", Fleet: true},
+			{From: "c", To: "s", Fleet: true}, {From: "s", To: "c", Fleet: true},
+		},
+		Units: []diplomacy.VariantUnit{
+			{Power: diplomacy.France, Type: "army", Province: "a"},
+			{Power: diplomacy.Germany, Type: "army", Province: "c"},
+		},
+	}
+	if err := v.Validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	sc := v.Scenario()
+	m := sc.Map()
+
+	if got := NewStrategyForMap("hard", nil, m).Name(); got != DefaultStrategy {
+		t.Errorf("expected hard to fall back to %s on a custom map, got %s", DefaultStrategy, got)
+	}
+	if got := NewStrategyForMap("hard", nil, diplomacy.StandardMap()).Name(); got != "hard" {
+		t.Errorf("expected hard on the standard map, got %s", got)
+	}
+
+	gs := sc.InitialState()
+	s := NewStrategyForMap("easy", nil, m)
+	for _, o := range s.GenerateMovementOrders(gs, diplomacy.France, m) {
+		if err := diplomacy.ValidateOrder(orderInputToOrder(o, diplomacy.France), gs, m); err != nil {
+			t.Errorf("illegal order %+v on the custom map: %v", o, err)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package bot
+
+import (
+	"slices"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// retreatTarget is a province a dislodged unit can legally retreat to.
+type retreatTarget struct {
+	province string
+	coast    string
+}
+
+// retreatTargets lists the legal retreat destinations of a dislodged unit,
+// with one entry per reachable coast of a split-coast province.
+func retreatTargets(gs *diplomacy.GameState, d diplomacy.DislodgedUnit, m *diplomacy.DiplomacyMap) []retreatTarget {
+	isFleet := d.Unit.Type == diplomacy.Fleet
+	var targets []retreatTarget
+	for _, target := range m.ProvincesAdjacentTo(d.DislodgedFrom, d.Unit.Coast, isFleet) {
+		if !diplomacy.CanRetreatTo(gs, d, target) {
+			continue
+		}
+		prov := m.Provinces[target]
+		if prov == nil {
+			continue
+		}
+		if isFleet && prov.Type == diplomacy.Land {
+			continue
+		}
+		if !isFleet && prov.Type == diplomacy.Sea {
+			continue
+		}
+
+		for _, coast := range diplomacy.RetreatCoasts(d, target, m) {
+			ro := diplomacy.RetreatOrder{
+				UnitType:    d.Unit.Type,
+				Power:       d.Unit.Power,
+				Location:    d.DislodgedFrom,
+				Coast:       d.Unit.Coast,
+				Type:        diplomacy.RetreatMove,
+				Target:      target,
+				TargetCoast: coast,
+			}
+			if diplomacy.ValidateRetreatOrder(ro, gs, m) == nil {
+				targets = append(targets, retreatTarget{province: target, coast: string(coast)})
+			}
+		}
+	}
+	return targets
+}
+
+// retreatContenders counts the dislodged units of other powers that could
+// also retreat to a province. If any of them does, both units are destroyed.
+func retreatContenders(gs *diplomacy.GameState, power diplomacy.Power, province string, m *diplomacy.DiplomacyMap) int {
+	n := 0
+	for _, d := range gs.Dislodged {
+		if d.Unit.Power == power {
+			continue
+		}
+		if slices.ContainsFunc(retreatTargets(gs, d, m), func(t retreatTarget) bool { return t.province == province }) {
+			n++
+		}
+	}
+	return n
+}
+
+// CoordinateRetreats resolves conflicts between the retreat orders of
+// several bot powers, for games where every retreating power is a bot (arena
+// and selfplay games). When two units retreat to the same province both are
+// destroyed; instead, the unit with fewer alternatives keeps the destination
+// and the others move to a free legal destination if one exists, or disband.
+// It edits orders in place and returns how many orders it changed.
+func CoordinateRetreats(gs *diplomacy.GameState, m *diplomacy.DiplomacyMap, orders map[diplomacy.Power][]OrderInput) int {
+	type retreat struct {
+		power   diplomacy.Power
+		idx     int
+		targets []retreatTarget
+	}
+	claims := make(map[string][]retreat)
+	var retreats []retreat
+	for _, power := range diplomacy.AllPowers() {
+		for i, o := range orders[power] {
+			if o.OrderType != "retreat_move" {
+				continue
+			}
+			r := retreat{power: power, idx: i}
+			for _, d := range gs.Dislodged {
+				if d.Unit.Power == power && d.DislodgedFrom == o.Location {
+					r.targets = retreatTargets(gs, d, m)
+					break
+				}
+			}
+			claims[o.Target] = append(claims[o.Target], r)
+			retreats = append(retreats, r)
+		}
+	}
+
+	changed := 0
+	for _, r := range retreats {
+		o := &orders[r.power][r.idx]
+		contenders := claims[o.Target]
+		if len(contenders) < 2 {
+			continue
+		}
+		// The most constrained unit keeps the destination.
+		keeper := slices.MinFunc(contenders, func(a, b retreat) int { return len(a.targets) - len(b.targets) })
+		if keeper.power == r.power && keeper.idx == r.idx {
+			continue
+		}
+
+		claims[o.Target] = slices.DeleteFunc(contenders, func(c retreat) bool { return c.power == r.power && c.idx == r.idx })
+		alt := slices.IndexFunc(r.targets, func(t retreatTarget) bool { return len(claims[t.province]) == 0 })
+		if alt < 0 {
+			o.OrderType, o.Target, o.TargetCoast = "retreat_disband", "", ""
+		} else {
+			o.Target, o.TargetCoast = r.targets[alt].province, r.targets[alt].coast
+			claims[o.Target] = []retreat{r}
+		}
+		changed++
+	}
+	return changed
+}
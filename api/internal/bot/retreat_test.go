@@ -0,0 +1,111 @@
+package bot
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+func army(power diplomacy.Power, prov string) diplomacy.Unit {
+	return diplomacy.Unit{Type: diplomacy.Army, Power: power, Province: prov, Coast: diplomacy.NoCoast}
+}
+
+func TestHeuristicRetreatsNeverShareDestination(t *testing.T) {
+	// Both French armies can only retreat to bel or par.
+	gs := &diplomacy.GameState{
+		Year:   1902,
+		Season: diplomacy.Spring,
+		Phase:  diplomacy.PhaseRetreat,
+		Units: []diplomacy.Unit{
+			army(diplomacy.Germany, "bur"), army(diplomacy.England, "pic"),
+			army(diplomacy.Germany, "gas"), army(diplomacy.Germany, "mun"), army(diplomacy.Germany, "ruh"),
+		},
+		Dislodged: []diplomacy.DislodgedUnit{
+			{Unit: army(diplomacy.France, "bur"), DislodgedFrom: "bur", AttackerFrom: "mar"},
+			{Unit: army(diplomacy.France, "pic"), DislodgedFrom: "pic", AttackerFrom: "bre"},
+		},
+		SupplyCenters: diplomacy.NewInitialState().SupplyCenters,
+	}
+	m := diplomacy.StandardMap()
+
+	for i := 0; i < 20; i++ {
+		orders := HeuristicStrategy{}.GenerateRetreatOrders(gs, diplomacy.France, m)
+		if len(orders) != 2 {
+			t.Fatalf("expected 2 orders, got %d", len(orders))
+		}
+		if orders[0].OrderType != "retreat_move" || orders[1].OrderType != "retreat_move" {
+			t.Fatalf("expected both units to retreat, got %+v", orders)
+		}
+		if orders[0].Target == orders[1].Target {
+			t.Fatalf("both units retreat to %s", orders[0].Target)
+		}
+	}
+}
+
+func TestCoordinateRetreats(t *testing.T) {
+	gs := &diplomacy.GameState{
+		Year:   1902,
+		Season: diplomacy.Spring,
+		Phase:  diplomacy.PhaseRetreat,
+		Units:  []diplomacy.Unit{army(diplomacy.Italy, "bur"), army(diplomacy.Russia, "ruh")},
+		Dislodged: []diplomacy.DislodgedUnit{
+			{Unit: army(diplomacy.France, "bur"), DislodgedFrom: "bur", AttackerFrom: "mar"},
+			{Unit: army(diplomacy.Germany, "ruh"), DislodgedFrom: "ruh", AttackerFrom: "kie"},
+		},
+	}
+	m := diplomacy.StandardMap()
+	orders := map[diplomacy.Power][]OrderInput{
+		diplomacy.France:  {{UnitType: "army", Location: "bur", OrderType: "retreat_move", Target: "bel"}},
+		diplomacy.Germany: {{UnitType: "army", Location: "ruh", OrderType: "retreat_move", Target: "bel"}},
+	}
+
+	if n := CoordinateRetreats(gs, m, orders); n != 1 {
+		t.Fatalf("expected 1 changed order, got %d", n)
+	}
+	// Germany has fewer ways out, so it keeps bel.
+	if got := orders[diplomacy.Germany][0]; got.Target != "bel" {
+		t.Errorf("expected germany to keep bel, got %+v", got)
+	}
+	fr := orders[diplomacy.France][0]
+	if fr.OrderType != "retreat_move" || fr.Target == "bel" || fr.Target == "" {
+		t.Errorf("expected france to retreat elsewhere, got %+v", fr)
+	}
+
+	if n := CoordinateRetreats(gs, m, orders); n != 0 {
+		t.Errorf("expected no changes once deconflicted, got %d", n)
+	}
+}
+
+func TestRetreatTargetsSplitCoasts(t *testing.T) {
+	// The French fleet in mao has only spa left, reachable on either coast.
+	gs := &diplomacy.GameState{
+		Year:   1902,
+		Season: diplomacy.Spring,
+		Phase:  diplomacy.PhaseRetreat,
+		Units: []diplomacy.Unit{
+			army(diplomacy.England, "gas"), army(diplomacy.England, "por"), army(diplomacy.Italy, "naf"),
+		},
+		Dislodged: []diplomacy.DislodgedUnit{{
+			Unit:          diplomacy.Unit{Type: diplomacy.Fleet, Power: diplomacy.France, Province: "mao"},
+			DislodgedFrom: "mao",
+			AttackerFrom:  "bre",
+		}},
+		SupplyCenters: diplomacy.NewInitialState().SupplyCenters,
+	}
+	for _, p := range []string{"nao", "iri", "eng", "wes"} {
+		gs.Units = append(gs.Units, diplomacy.Unit{Type: diplomacy.Fleet, Power: diplomacy.England, Province: p})
+	}
+	m := diplomacy.StandardMap()
+
+	targets := retreatTargets(gs, gs.Dislodged[0], m)
+	want := []retreatTarget{{"spa", "nc"}, {"spa", "sc"}}
+	if len(targets) != 2 || !slices.Contains(targets, want[0]) || !slices.Contains(targets, want[1]) {
+		t.Fatalf("targets = %+v, want %+v", targets, want)
+	}
+
+	orders := HeuristicStrategy{}.GenerateRetreatOrders(gs, diplomacy.France, m)
+	if len(orders) != 1 || orders[0].Target != "spa" || orders[0].TargetCoast == "" {
+		t.Fatalf("expected a retreat to a coast of spa, got %+v", orders)
+	}
+}
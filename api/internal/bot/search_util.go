@@ -349,10 +349,11 @@ func scoreMoveOrder(order diplomacy.Order, gs *diplomacy.GameState, power diplom
 			score += 1
 		}
 	}
+	score -= mercyCost(gs, power, target, m)
 
-	// Fall departure penalty: moving away from an unowned SC during Fall
-	// forfeits the imminent capture at year-end.
-	if gs.Season == diplomacy.Fall {
+	// Departure penalty: moving away from an unowned SC in the year's last
+	// season (Fall) forfeits the imminent capture at year-end.
+	if gs.IsLastSeason() {
 		srcProv := m.Provinces[order.Location]
 		if srcProv != nil && srcProv.IsSupplyCenter && gs.SupplyCenters[order.Location] != power {
 			score -= 12
@@ -388,10 +389,11 @@ func scoreSupportOrder(order diplomacy.Order, gs *diplomacy.GameState, power dip
 	// Support friendly move
 	target := order.AuxTarget
 	prov := m.Provinces[target]
+	score := 5.0
 	if prov != nil && prov.IsSupplyCenter && gs.SupplyCenters[target] != power {
-		return 9.0 // support friendly move to unowned SC
+		score = 9.0 // support friendly move to unowned SC
 	}
-	return 5.0
+	return score - mercyCost(gs, power, target, m)
 }
 
 // TopKOrders sorts orders by ScoreOrder desc and returns the top K.
@@ -513,12 +515,15 @@ func EvaluatePosition(gs *diplomacy.GameState, power diplomacy.Power, m *diploma
 		}
 	}
 
-	score += 10.0 * float64(ownSCs)
-	if ownSCs > 10 {
-		bonus := float64(ownSCs - 10)
-		score += bonus * bonus * 2.0
+	// Near the year limit a solo is worth less than banking SCs, so the
+	// accelerating solo bonus fades out and raw SC count is weighted up.
+	pressure := yearLimitPressure(gs)
+	score += (10.0 + 5.0*pressure) * float64(ownSCs)
+	if base := scThreshold(gs, 10); ownSCs > base {
+		bonus := float64(ownSCs - base)
+		score += bonus * bonus * 2.0 * (1 - pressure)
 	}
-	if ownSCs >= 18 {
+	if ownSCs >= gs.VictoryCenters() {
 		score += 500.0
 	}
 
@@ -539,7 +544,7 @@ func EvaluatePosition(gs *diplomacy.GameState, power diplomacy.Power, m *diploma
 	// Also count enemies with units but no SCs as alive.
 	// Use a single pass over units for this + own unit stats.
 	pendingBonus := 8.0
-	if gs.Season == diplomacy.Fall {
+	if gs.IsLastSeason() {
 		pendingBonus = 12.0
 	}
 	unitCount := 0
@@ -617,13 +622,14 @@ func EvaluatePosition(gs *diplomacy.GameState, power diplomacy.Power, m *diploma
 	if ownSCs <= 4 {
 		basePenalty = 6.0
 	}
+	basePenalty *= 1 + pressure
 	for _, td := range tdMap {
 		diff := int(td.threat) - int(td.defense)
 		if diff > 0 {
 			penalty := basePenalty * float64(diff)
-			if ownSCs >= 16 {
+			if ownSCs >= scThreshold(gs, 16) {
 				penalty *= 0.2
-			} else if ownSCs >= 14 {
+			} else if ownSCs >= scThreshold(gs, 14) {
 				penalty *= 0.5
 			}
 			score -= penalty
@@ -633,11 +639,20 @@ func EvaluatePosition(gs *diplomacy.GameState, power diplomacy.Power, m *diploma
 	score -= float64(totalEnemy)
 	score -= 0.5 * float64(maxEnemy)
 
-	// Bonus for having fewer alive enemies (rewards elimination)
-	eliminatedBonus := float64(6-aliveEnemies) * 8.0
+	score += playbookPositionScore(gs, power)
+
+	// Bonus for having fewer alive enemies (rewards elimination). Inactive
+	// powers on partial boards never count as eliminated enemies.
+	eliminatedBonus := float64(len(gs.ActivePowers())-1-aliveEnemies) * 8.0 * (1 - 0.5*pressure)
 	score += eliminatedBonus
 
-	return score
+	return score - mercyPositionCost(gs, power, m)
+}
+
+// isGarrisoned reports whether a neutral garrison holds province.
+func isGarrisoned(gs *diplomacy.GameState, province string) bool {
+	u := gs.UnitAt(province)
+	return u != nil && u.Power == diplomacy.Neutral
 }
 
 // GenerateOpponentOrders uses HeuristicStrategy to predict moves for one opponent.
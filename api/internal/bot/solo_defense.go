@@ -0,0 +1,288 @@
+package bot
+
+import (
+	"sort"
+	"time"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// soloThreatSCs is the leader's center count, in the standard 18-center
+// game, at which a trailing medium or hard bot stops chasing centers and
+// mans whatever defensive blocks it can.
+const soloThreatSCs = 14
+
+// blockReach is how many moves away a unit may be from the post it is
+// assigned; further blocks are not worth walking to under pressure.
+const blockReach = 2
+
+// soloDefenseSearchTime bounds the search that orders the units left over
+// once the blocks are manned.
+const soloDefenseSearchTime = 100 * time.Millisecond
+
+// DefensiveBlock is a cluster of mutually adjacent posts on the standard map
+// around a group of home or regional centers. Blocks are not proven
+// stalemate lines, and a leader with enough units can break most of them;
+// manned and supporting one another, they make the leader dislodge
+// supported units to take the centers they cover, which slows a solo push
+// and often stalls it short of the win.
+type DefensiveBlock struct {
+	Name   string
+	Posts  []string
+	Covers []string
+}
+
+// DefensiveBlocks are the standard-map blocks bots know, from the home
+// clusters of each power to the regional groups they fall back on.
+var DefensiveBlocks = []DefensiveBlock{
+	{Name: "british isles", Posts: []string{"nrg", "nth", "eng", "iri", "nao"}, Covers: []string{"lon", "lvp", "edi"}},
+	{Name: "iberia", Posts: []string{"spa", "mao", "wes", "gol"}, Covers: []string{"spa", "por"}},
+	{Name: "france", Posts: []string{"bre", "par", "bur", "mar", "gas"}, Covers: []string{"bre", "par", "mar"}},
+	{Name: "italian boot", Posts: []string{"tus", "ven", "adr", "ion", "tys"}, Covers: []string{"ven", "rom", "nap"}},
+	{Name: "germany", Posts: []string{"kie", "ber", "mun", "ruh", "sil"}, Covers: []string{"kie", "ber", "mun"}},
+	{Name: "austria", Posts: []string{"tyr", "tri", "vie", "bud", "gal"}, Covers: []string{"vie", "bud", "tri"}},
+	{Name: "scandinavia", Posts: []string{"nwy", "swe", "den", "ska"}, Covers: []string{"nwy", "swe", "den"}},
+	{Name: "russian heartland", Posts: []string{"stp", "mos", "war", "ukr", "sev"}, Covers: []string{"stp", "mos", "war", "sev"}},
+	{Name: "balkans", Posts: []string{"ser", "bul", "rum", "gre", "alb"}, Covers: []string{"ser", "bul", "rum", "gre"}},
+	{Name: "anatolia", Posts: []string{"con", "ank", "smy", "arm", "aeg"}, Covers: []string{"con", "ank", "smy"}},
+}
+
+// soloDefenseNeeded reports whether another power is close enough to a
+// solo that power should man blocks rather than chase centers.
+func soloDefenseNeeded(gs *diplomacy.GameState, power diplomacy.Power) bool {
+	if !soloStillPossible(gs) {
+		return false
+	}
+	own := gs.SupplyCenterCount(power)
+	leader := scThreshold(gs, soloThreatSCs)
+	for _, p := range diplomacy.AllPowers() {
+		if p == power {
+			continue
+		}
+		if sc := gs.SupplyCenterCount(p); sc >= leader && sc > own {
+			return true
+		}
+	}
+	return false
+}
+
+// blockAssignment is a block chosen for power, with the unit (by province)
+// assigned to each of its posts.
+type blockAssignment struct {
+	block DefensiveBlock
+	units map[string]diplomacy.Unit // post -> unit
+}
+
+// chooseBlocks picks the blocks power can man, best first: those covering
+// the most of its centers, where no foreign unit sits on a post and every
+// post can be given its own unit within blockReach moves. Each unit mans at
+// most one post.
+func chooseBlocks(gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap) []blockAssignment {
+	type ranked struct {
+		block DefensiveBlock
+		owned int
+	}
+	var blocks []ranked
+	for _, b := range DefensiveBlocks {
+		owned := 0
+		for _, sc := range b.Covers {
+			if gs.SupplyCenters[sc] == power {
+				owned++
+			}
+		}
+		if owned >= 2 {
+			blocks = append(blocks, ranked{b, owned})
+		}
+	}
+	sort.SliceStable(blocks, func(i, j int) bool { return blocks[i].owned > blocks[j].owned })
+
+	used := make(map[string]bool) // provinces of units already assigned
+	var chosen []blockAssignment
+	for _, r := range blocks {
+		if a, ok := assignPosts(gs, power, r.block, used, m); ok {
+			for _, u := range a.units {
+				used[u.Province] = true
+			}
+			chosen = append(chosen, a)
+		}
+	}
+	return chosen
+}
+
+// assignPosts gives each post of block the nearest free unit of power,
+// closest pairs first. It fails if a foreign unit holds a post or a post
+// has no free unit within blockReach.
+func assignPosts(gs *diplomacy.GameState, power diplomacy.Power, block DefensiveBlock, used map[string]bool, m *diplomacy.DiplomacyMap) (blockAssignment, bool) {
+	type pair struct {
+		post string
+		unit diplomacy.Unit
+		dist int
+	}
+	var pairs []pair
+	for _, post := range block.Posts {
+		if m.Provinces[post] == nil {
+			return blockAssignment{}, false
+		}
+		if u := gs.UnitAt(post); u != nil && u.Power != power {
+			return blockAssignment{}, false
+		}
+		for _, u := range gs.UnitsOf(power) {
+			if used[u.Province] {
+				continue
+			}
+			d := UnitBFSDistance(u.Province, post, m, u.Type == diplomacy.Fleet)
+			if d >= 0 && d <= blockReach {
+				pairs = append(pairs, pair{post, u, d})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].dist < pairs[j].dist })
+
+	a := blockAssignment{block: block, units: make(map[string]diplomacy.Unit, len(block.Posts))}
+	taken := make(map[string]bool)
+	for _, p := range pairs {
+		if _, ok := a.units[p.post]; ok || taken[p.unit.Province] {
+			continue
+		}
+		a.units[p.post] = p.unit
+		taken[p.unit.Province] = true
+	}
+	return a, len(a.units) == len(block.Posts)
+}
+
+// SoloDefenseOrders returns orders that man and hold the defensive blocks
+// power can reach, or nil if no other power is near a solo or power cannot
+// man any block. Units short of their posts advance toward them and units
+// on threatened posts hold. Each threatened post is backed with a support
+// for every attacker it faces beyond the first, from block units on safe
+// posts first and then from units without a post. The units still left
+// over are ordered by the medium bot's search around the block orders.
+func SoloDefenseOrders(gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap) []OrderInput {
+	if m != diplomacy.StandardMap() || !soloDefenseNeeded(gs, power) {
+		return nil
+	}
+	blocks := chooseBlocks(gs, power, m)
+	if len(blocks) == 0 {
+		return nil
+	}
+
+	postOf := make(map[string]string) // unit province -> assigned post
+	posts := make(map[string]bool)
+	for _, a := range blocks {
+		for post, u := range a.units {
+			postOf[u.Province] = post
+			posts[post] = true
+		}
+	}
+
+	units := gs.UnitsOf(power)
+	legal := make([][]diplomacy.Order, len(units))
+	unitOrders := make([][]diplomacy.Order, len(units))
+	claimed := make(map[string]bool) // destinations of the block units' moves
+	var threatened []string          // held posts enemy units can reach
+	var spare, free []int            // block units on safe posts; units without a post
+	for i, u := range units {
+		legal[i] = LegalOrdersForUnit(u, gs, m)
+		post, assigned := postOf[u.Province]
+		switch {
+		case !assigned:
+			free = append(free, i)
+		case post != u.Province:
+			order := advanceToward(gs, u, post, legal[i], postOf, claimed, m)
+			if order.Type == diplomacy.OrderMove {
+				claimed[order.Target] = true
+			}
+			unitOrders[i] = []diplomacy.Order{order}
+		case ProvinceThreat(post, power, gs, m) > 0:
+			threatened = append(threatened, post)
+			unitOrders[i] = legal[i][:1] // hold
+		default:
+			spare = append(spare, i)
+		}
+	}
+
+	threat := make(map[string]int, len(threatened))
+	for _, post := range threatened {
+		threat[post] = ProvinceThreat(post, power, gs, m)
+	}
+	sort.SliceStable(threatened, func(i, j int) bool { return threat[threatened[i]] > threat[threatened[j]] })
+	backers := append(spare, free...)
+	for _, post := range threatened {
+		need := threat[post] - 1
+		for _, i := range backers {
+			if need == 0 {
+				break
+			}
+			if unitOrders[i] != nil {
+				continue
+			}
+			if sup, ok := supportHoldOf(legal[i], post); ok {
+				unitOrders[i] = []diplomacy.Order{sup}
+				need--
+			}
+		}
+	}
+	for _, i := range spare {
+		if unitOrders[i] == nil {
+			unitOrders[i] = legal[i][:1] // hold
+		}
+	}
+
+	k := max(adaptiveK(len(free), 5000), 3)
+	for _, i := range free {
+		if unitOrders[i] != nil {
+			continue
+		}
+		for _, o := range TopKOrders(legal[i], k, gs, power, m) {
+			if o.Type == diplomacy.OrderMove && (claimed[o.Target] || posts[o.Target]) {
+				continue // leave the blocks' posts and destinations to them
+			}
+			unitOrders[i] = append(unitOrders[i], o)
+		}
+		if unitOrders[i] == nil {
+			unitOrders[i] = legal[i][:1]
+		}
+	}
+
+	var opponentOrders []diplomacy.Order
+	for _, p := range diplomacy.AllPowers() {
+		if p != power && gs.PowerIsAlive(p) {
+			opponentOrders = append(opponentOrders, GenerateOpponentOrders(gs, p, m)...)
+		}
+	}
+	orders, _ := searchBestOrders(gs, power, m, unitOrders, opponentOrders, time.Now().Add(soloDefenseSearchTime))
+	return OrdersToOrderInputs(orders)
+}
+
+// supportHoldOf returns the legal support-hold of the unit on post.
+func supportHoldOf(legal []diplomacy.Order, post string) (diplomacy.Order, bool) {
+	for _, o := range legal {
+		if o.Type == diplomacy.OrderSupport && o.AuxTarget == "" && o.AuxLoc == post {
+			return o, true
+		}
+	}
+	return diplomacy.Order{}, false
+}
+
+// advanceToward returns the legal move bringing u closest to post, avoiding
+// provinces another move already claims and those of own units that stay
+// put. It holds if no move gets closer.
+func advanceToward(gs *diplomacy.GameState, u diplomacy.Unit, post string, legal []diplomacy.Order, postOf map[string]string, claimed map[string]bool, m *diplomacy.DiplomacyMap) diplomacy.Order {
+	isFleet := u.Type == diplomacy.Fleet
+	best := legal[0]
+	bestDist := UnitBFSDistance(u.Province, post, m, isFleet)
+	for _, o := range legal {
+		if o.Type != diplomacy.OrderMove || claimed[o.Target] {
+			continue
+		}
+		if occ := gs.UnitAt(o.Target); occ != nil && occ.Power == u.Power {
+			if p, ok := postOf[o.Target]; !ok || p == o.Target {
+				continue
+			}
+		}
+		if d := UnitBFSDistance(o.Target, post, m, isFleet); d >= 0 && d < bestDist {
+			best, bestDist = o, d
+		}
+	}
+	return best
+}
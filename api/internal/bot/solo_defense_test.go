@@ -0,0 +1,197 @@
+package bot
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// soloDefenseTestState has Italy, on ten centers, facing a French leader on
+// fifteen: Italy's units are on or next to the Italian boot block.
+func soloDefenseTestState(frenchSCs int) *diplomacy.GameState {
+	gs := &diplomacy.GameState{
+		Year:   1908,
+		Season: diplomacy.Spring,
+		Phase:  diplomacy.PhaseMovement,
+		Units: []diplomacy.Unit{
+			{Type: diplomacy.Army, Power: diplomacy.Italy, Province: "ven"},
+			{Type: diplomacy.Fleet, Power: diplomacy.Italy, Province: "ion"},
+			{Type: diplomacy.Army, Power: diplomacy.Italy, Province: "rom"},
+			{Type: diplomacy.Fleet, Power: diplomacy.Italy, Province: "nap"},
+			{Type: diplomacy.Fleet, Power: diplomacy.Italy, Province: "alb"},
+			{Type: diplomacy.Fleet, Power: diplomacy.Italy, Province: "tun"},
+			{Type: diplomacy.Army, Power: diplomacy.Italy, Province: "apu"},
+			{Type: diplomacy.Army, Power: diplomacy.France, Province: "pie"},
+			{Type: diplomacy.Army, Power: diplomacy.France, Province: "tyr"},
+			{Type: diplomacy.Fleet, Power: diplomacy.France, Province: "gol"},
+			{Type: diplomacy.Fleet, Power: diplomacy.France, Province: "wes"},
+		},
+		SupplyCenters: map[string]diplomacy.Power{},
+	}
+	for _, sc := range []string{"ven", "rom", "nap", "tun", "gre", "ser", "tri", "vie", "bud", "alb"} {
+		gs.SupplyCenters[sc] = diplomacy.Italy
+	}
+	french := []string{"bre", "par", "mar", "spa", "por", "lon", "lvp", "edi", "bel", "hol", "mun", "kie", "ber", "den", "nwy", "swe"}
+	for _, sc := range french[:frenchSCs] {
+		gs.SupplyCenters[sc] = diplomacy.France
+	}
+	return gs
+}
+
+func TestSoloDefenseOrders_MansItalianBoot(t *testing.T) {
+	m := diplomacy.StandardMap()
+	gs := soloDefenseTestState(15)
+
+	orders := SoloDefenseOrders(gs, diplomacy.Italy, m)
+	if len(orders) != len(gs.UnitsOf(diplomacy.Italy)) {
+		t.Fatalf("expected one order per unit, got %v", orders)
+	}
+	manned := make(map[string]bool)
+	for _, o := range orders {
+		if err := diplomacy.ValidateOrder(orderInputToOrder(o, diplomacy.Italy), gs, m); err != nil {
+			t.Errorf("invalid order %+v: %v", o, err)
+		}
+		switch o.OrderType {
+		case "move":
+			manned[o.Target] = true
+		case "hold", "support":
+			manned[o.Location] = true
+		}
+	}
+	for _, post := range []string{"tus", "ven", "adr", "ion", "tys"} {
+		if !manned[post] {
+			t.Errorf("post %s is not manned: %v", post, orders)
+		}
+	}
+	for _, o := range orders {
+		if o.OrderType == "move" && (o.Location == "ven" || o.Location == "ion") {
+			t.Errorf("unit leaves its post: %+v", o)
+		}
+	}
+
+	medium := make(map[string]bool)
+	for _, o := range (TacticalStrategy{}).GenerateMovementOrders(gs, diplomacy.Italy, m) {
+		if o.OrderType == "move" {
+			medium[o.Target] = true
+		} else {
+			medium[o.Location] = true
+		}
+	}
+	for _, post := range []string{"tus", "ven", "adr", "ion", "tys"} {
+		if !medium[post] {
+			t.Errorf("medium bot did not man post %s", post)
+		}
+	}
+}
+
+// TestSoloDefenseOrders_HoldsAPush mans the Italian boot against a French
+// leader and tries every push France can make, each unit holding, moving
+// into the block or supporting such a move: none dislodges an Italian unit
+// or gets a French unit onto a post or an Italian home center.
+func TestSoloDefenseOrders_HoldsAPush(t *testing.T) {
+	m := diplomacy.StandardMap()
+	gs := soloDefenseTestState(15)
+	gs.Season = diplomacy.Fall
+	gs.Units = []diplomacy.Unit{
+		{Type: diplomacy.Army, Power: diplomacy.Italy, Province: "tus"},
+		{Type: diplomacy.Army, Power: diplomacy.Italy, Province: "ven"},
+		{Type: diplomacy.Fleet, Power: diplomacy.Italy, Province: "adr"},
+		{Type: diplomacy.Fleet, Power: diplomacy.Italy, Province: "ion"},
+		{Type: diplomacy.Fleet, Power: diplomacy.Italy, Province: "tys"},
+		{Type: diplomacy.Army, Power: diplomacy.Italy, Province: "rom"},
+		{Type: diplomacy.Fleet, Power: diplomacy.Italy, Province: "nap"},
+		{Type: diplomacy.Army, Power: diplomacy.France, Province: "pie"},
+		{Type: diplomacy.Army, Power: diplomacy.France, Province: "tyr"},
+		{Type: diplomacy.Fleet, Power: diplomacy.France, Province: "gol"},
+		{Type: diplomacy.Fleet, Power: diplomacy.France, Province: "wes"},
+	}
+
+	italian := OrderInputsToOrders(SoloDefenseOrders(gs, diplomacy.Italy, m), diplomacy.Italy)
+	if len(italian) != 7 {
+		t.Fatalf("expected an order per Italian unit, got %v", italian)
+	}
+	guarded := []string{"tus", "ven", "adr", "ion", "tys", "rom", "nap"}
+	var french [][]diplomacy.Order
+	for _, u := range gs.UnitsOf(diplomacy.France) {
+		var push []diplomacy.Order
+		for _, o := range LegalOrdersForUnit(u, gs, m) {
+			if o.Type == diplomacy.OrderHold || slices.Contains(guarded, o.Target) || slices.Contains(guarded, o.AuxTarget) {
+				push = append(push, o)
+			}
+		}
+		french = append(french, push)
+	}
+	pushes := 0
+	idx := make([]int, len(french))
+	for {
+		orders := slices.Clone(italian)
+		for i, j := range idx {
+			orders = append(orders, french[i][j])
+		}
+		next := gs.Clone()
+		results, dislodged := diplomacy.ResolveOrders(orders, next, m)
+		diplomacy.ApplyResolution(next, m, results, dislodged)
+		for _, d := range dislodged {
+			if d.Unit.Power == diplomacy.Italy {
+				t.Fatalf("French orders %v dislodge the Italian unit in %s", orders[len(italian):], d.DislodgedFrom)
+			}
+		}
+		for _, prov := range guarded {
+			if u := next.UnitAt(prov); u != nil && u.Power == diplomacy.France {
+				t.Fatalf("French orders %v take %s", orders[len(italian):], prov)
+			}
+		}
+		pushes++
+
+		i := len(idx) - 1
+		for ; i >= 0; i-- {
+			if idx[i]++; idx[i] < len(french[i]) {
+				break
+			}
+			idx[i] = 0
+		}
+		if i < 0 {
+			break
+		}
+	}
+	if pushes < 100 {
+		t.Errorf("expected to try every French order set, tried %d", pushes)
+	}
+}
+
+func TestSoloDefenseOrders_OnlyWhenBehindALeader(t *testing.T) {
+	m := diplomacy.StandardMap()
+	if orders := SoloDefenseOrders(soloDefenseTestState(12), diplomacy.Italy, m); orders != nil {
+		t.Errorf("no power near a solo, expected nil, got %v", orders)
+	}
+	gs := soloDefenseTestState(15)
+	if orders := SoloDefenseOrders(gs, diplomacy.France, m); orders != nil {
+		t.Errorf("the leader itself should not man blocks, got %v", orders)
+	}
+}
+
+func TestDefensiveBlocksAreConnected(t *testing.T) {
+	m := diplomacy.StandardMap()
+	for _, l := range DefensiveBlocks {
+		for _, post := range l.Posts {
+			if m.Provinces[post] == nil {
+				t.Fatalf("%s: unknown post %s", l.Name, post)
+			}
+			linked := false
+			for _, other := range l.Posts {
+				if other != post && (BFSDistance(post, other, m) == 1 || FleetBFSDistance(post, other, m) == 1) {
+					linked = true
+				}
+			}
+			if !linked {
+				t.Errorf("%s: post %s is not adjacent to another post", l.Name, post)
+			}
+		}
+		for _, sc := range l.Covers {
+			if p := m.Provinces[sc]; p == nil || !p.IsSupplyCenter {
+				t.Errorf("%s: %s is not a supply center", l.Name, sc)
+			}
+		}
+	}
+}
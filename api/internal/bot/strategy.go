@@ -1,7 +1,9 @@
 package bot
 
 import (
+	"context"
 	"log"
+	"time"
 
 	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
 )
@@ -14,6 +16,102 @@ type Strategy interface {
 	GenerateBuildOrders(gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap) []OrderInput
 }
 
+// GameContext is what a StrategyV2 sees when generating orders: the position
+// plus the game metadata a Strategy has no access to.
+type GameContext struct {
+	GameID    string
+	PhaseID   string
+	State     *diplomacy.GameState
+	Power     diplomacy.Power
+	Map       *diplomacy.DiplomacyMap
+	Deadline  time.Time          // when the phase resolves; zero if unknown
+	Budget    SearchBudget       // time the caller allows for this call; zero for the strategy's default effort
+	Received  []DiplomaticIntent // press sent to Power so far this game
+	Diplomacy *BotDiplomacyState // requests received and trust toward other powers
+	Alliances *AllianceState     // pacts agreed so far this game; nil if not tracked
+}
+
+// SearchBudget is how long the caller lets a StrategyV2 think about a phase.
+// Given a budget, anytime searches keep refining their orders until it runs
+// out instead of stopping after their default fixed effort.
+type SearchBudget struct {
+	Deadline time.Time // when the orders must be returned; zero means no budget
+}
+
+// Anytime reports whether the budget asks the search to use all of its time.
+func (b SearchBudget) Anytime() bool {
+	return !b.Deadline.IsZero()
+}
+
+// StrategyV2 extends Strategy with a single cancellable entry point that
+// receives the full GameContext. Implementations should stop searching when
+// ctx is done or the phase deadline nears and return the best orders found so
+// far; an error is returned only when no orders could be produced.
+// Not all strategies implement it; use GenerateOrders to call either kind.
+type StrategyV2 interface {
+	Strategy
+	GenerateOrders(ctx context.Context, gc *GameContext) ([]OrderInput, error)
+}
+
+// GenerateOrders produces orders for the phase in gc.State, preferring the
+// strategy's StrategyV2 implementation and falling back to the per-phase
+// Strategy methods. Strategies without V2 cannot be interrupted, so ctx is
+// only checked before they start.
+func GenerateOrders(ctx context.Context, s Strategy, gc *GameContext) ([]OrderInput, error) {
+	if v2, ok := s.(StrategyV2); ok {
+		return v2.GenerateOrders(ctx, gc)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	switch gc.State.Phase {
+	case diplomacy.PhaseRetreat:
+		return s.GenerateRetreatOrders(gc.State, gc.Power, gc.Map), nil
+	case diplomacy.PhaseBuild:
+		return s.GenerateBuildOrders(gc.State, gc.Power, gc.Map), nil
+	default:
+		return s.GenerateMovementOrders(gc.State, gc.Power, gc.Map), nil
+	}
+}
+
+// OutOfProcess reports whether s computes its orders in another process,
+// so the calling goroutine's CPU time doesn't reflect what they cost.
+func OutOfProcess(s Strategy) bool {
+	switch s.(type) {
+	case *ExternalStrategy, *RemoteStrategy:
+		return true
+	}
+	return false
+}
+
+// searchDeadline returns when a search starting now with the given default
+// budget must stop: the budget (or gc's SearchBudget less a margin, if set),
+// the context deadline, or the phase deadline less a safety margin,
+// whichever comes first.
+func searchDeadline(ctx context.Context, gc *GameContext, budget time.Duration) time.Time {
+	deadline := time.Now().Add(budget)
+	if gc != nil && gc.Budget.Anytime() {
+		deadline = gc.Budget.Deadline.Add(-searchBudgetMargin)
+	}
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if gc != nil && !gc.Deadline.IsZero() {
+		if d := gc.Deadline.Add(-phaseDeadlineMargin); d.Before(deadline) {
+			deadline = d
+		}
+	}
+	return deadline
+}
+
+// phaseDeadlineMargin is how long before the phase deadline a search stops so
+// its orders are submitted in time.
+const phaseDeadlineMargin = 2 * time.Second
+
+// searchBudgetMargin is how long before a SearchBudget runs out a search
+// stops, leaving time to convert and return its orders.
+const searchBudgetMargin = 250 * time.Millisecond
+
 // DrawVoter decides whether a bot should vote for a draw.
 // Not all strategies support draw voting; use a type assertion to check.
 type DrawVoter interface {
@@ -36,39 +134,64 @@ var ExternalEnginePath string
 // Set this alongside ExternalEnginePath to configure model path, eval mode, etc.
 var ExternalEngineOptions []ExternalOption
 
-// StrategyForDifficulty returns the appropriate strategy for a bot difficulty level.
+// StrategyForDifficulty returns the registered strategy for a bot difficulty
+// level, falling back to DefaultStrategy for unknown names.
 func StrategyForDifficulty(difficulty string) Strategy {
-	switch difficulty {
-	case "medium":
-		return &TacticalStrategy{}
-	case "hard":
-		return &HardStrategy{}
-	case "hard-gonnx":
-		return newGonnxOrFallback()
-	case "random":
-		return &RandomStrategy{}
-	case "realpolitik", "impossible", "external":
-		return newExternalOrFallback(difficulty)
-	default:
-		return &HeuristicStrategy{}
-	}
+	return NewStrategy(difficulty, nil)
+}
+
+func init() {
+	RegisterStrategy(StrategyRegistration{
+		Name:        "realpolitik",
+		Aliases:     []string{"impossible", "external"},
+		Description: "External DUI engine; falls back to hard if unavailable.",
+		Capabilities: StrategyCapabilities{
+			Diplomacy:   true,
+			TimeControl: true,
+		},
+		Options: []StrategyOption{
+			{Name: "engine_path", Description: "Path to the DUI engine binary; defaults to REALPOLITIK_PATH."},
+		},
+		New: func(opts StrategyOptions) Strategy {
+			return newExternalOrFallback(opts.Get("engine_path", ExternalEnginePath))
+		},
+	})
+	RegisterStrategy(StrategyRegistration{
+		Name:         "random",
+		Description:  "Random legal orders, for testing.",
+		Capabilities: StrategyCapabilities{CustomMaps: true},
+		New:          func(StrategyOptions) Strategy { return &RandomStrategy{} },
+	})
+	RegisterStrategy(StrategyRegistration{
+		Name:         "hold",
+		Description:  "Holds every unit, for testing.",
+		Capabilities: StrategyCapabilities{Mercy: true},
+		New:          func(StrategyOptions) Strategy { return HoldStrategy{} },
+	})
 }
 
-// newExternalOrFallback attempts to create an ExternalStrategy. If the engine
-// path is not configured or the engine fails to start, it falls back to
-// HardStrategy so the game can proceed.
-func newExternalOrFallback(difficulty string) Strategy {
-	if ExternalEnginePath == "" {
-		log.Printf("bot: %s difficulty requested but ExternalEnginePath not set; falling back to hard", difficulty)
+// newExternalOrFallback attempts to create an ExternalStrategy for the engine
+// at path. If no path is configured, the engine fails to start, or it has
+// been disabled by repeated failures, it falls back to HardStrategy so the
+// game can proceed.
+func newExternalOrFallback(path string) Strategy {
+	if path == "" {
+		log.Printf("bot: external engine requested but no engine path set; falling back to hard")
+		return &HardStrategy{}
+	}
+	health := engineHealthFor(path)
+	if !health.allow() {
 		return &HardStrategy{}
 	}
 	// Power is set per-query via setpower, so we use a placeholder here.
 	// The actual power is passed in each Generate* call.
-	es, err := NewExternalStrategy(ExternalEnginePath, "", ExternalEngineOptions...)
+	es, err := NewExternalStrategy(path, "", ExternalEngineOptions...)
 	if err != nil {
-		log.Printf("bot: failed to start external engine %q: %v; falling back to hard", ExternalEnginePath, err)
+		health.failure(err)
+		log.Printf("bot: failed to start external engine %q: %v; falling back to hard", path, err)
 		return &HardStrategy{}
 	}
+	es.health = health
 	return es
 }
 
@@ -227,12 +350,8 @@ func (RandomStrategy) GenerateRetreatOrders(gs *diplomacy.GameState, power diplo
 		perm := botPerm(len(adj))
 		for _, idx := range perm {
 			target := adj[idx]
-			// Cannot retreat to attacker's origin
-			if target == d.AttackerFrom {
-				continue
-			}
-			// Cannot retreat to occupied province
-			if gs.UnitAt(target) != nil {
+			// Cannot retreat to attacker's origin, an occupied province or a standoff
+			if !diplomacy.CanRetreatTo(gs, d, target) {
 				continue
 			}
 			prov := m.Provinces[target]
@@ -294,9 +413,7 @@ func (RandomStrategy) GenerateRetreatOrders(gs *diplomacy.GameState, power diplo
 
 // GenerateBuildOrders builds units on open home SCs or disbands excess units.
 func (RandomStrategy) GenerateBuildOrders(gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap) []OrderInput {
-	scCount := gs.SupplyCenterCount(power)
-	unitCount := gs.UnitCount(power)
-	diff := scCount - unitCount
+	diff := gs.AdjustmentDelta(power)
 
 	var orders []OrderInput
 
@@ -1,6 +1,7 @@
 package bot
 
 import (
+	"slices"
 	"sort"
 
 	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
@@ -8,10 +9,23 @@ import (
 
 // HeuristicStrategy generates orders using simple heuristics: score-based
 // greedy movement, opportunistic supports, and sensible build decisions.
-type HeuristicStrategy struct{}
+// Its Personality scales how much it values other powers' centers and how
+// readily it leaves threatened centers of its own.
+type HeuristicStrategy struct {
+	Personality Personality
+}
 
 func (HeuristicStrategy) Name() string { return "easy" }
 
+func init() {
+	RegisterStrategy(StrategyRegistration{
+		Name:         "easy",
+		Description:  "Greedy heuristic moves with opportunistic supports.",
+		Capabilities: StrategyCapabilities{DrawVoting: true, Personality: true, CustomMaps: true, Mercy: true},
+		New:          func(StrategyOptions) Strategy { return &HeuristicStrategy{} },
+	})
+}
+
 // ShouldVoteDraw always accepts a draw for easy bots.
 func (HeuristicStrategy) ShouldVoteDraw(_ *diplomacy.GameState, _ diplomacy.Power) bool {
 	return true
@@ -122,6 +136,40 @@ func (h HeuristicStrategy) GenerateMovementOrders(gs *diplomacy.GameState, power
 		}
 	}
 
+	// --- Garrisons ---
+	// A garrison holds with strength 1, so attacking one alone always
+	// bounces. An unsupported attack takes an idle unit as its supporter if
+	// one can reach; otherwise it is dropped and the unit supports another
+	// move or holds instead.
+	kept := moves[:0]
+	for _, mv := range moves {
+		if supportedMoves[mv.target] || supportConverted[mv.unit.Province] || !isGarrisoned(gs, mv.target) {
+			kept = append(kept, mv)
+			continue
+		}
+		i := slices.IndexFunc(units, func(u diplomacy.Unit) bool {
+			return !assignedUnits[u.Province] && CanSupportMove(u.Province, mv.unit.Province, mv.target, u, gs, m)
+		})
+		if i < 0 {
+			delete(assignedUnits, mv.unit.Province)
+			continue
+		}
+		sup := units[i]
+		assignedUnits[sup.Province] = true
+		supportedMoves[mv.target] = true
+		supportOrders = append(supportOrders, OrderInput{
+			UnitType:    sup.Type.String(),
+			Location:    sup.Province,
+			Coast:       string(sup.Coast),
+			OrderType:   "support",
+			AuxLoc:      mv.unit.Province,
+			AuxTarget:   mv.target,
+			AuxUnitType: mv.unit.Type.String(),
+		})
+		kept = append(kept, mv)
+	}
+	moves = kept
+
 	// --- Convoy planning ---
 	convoyConverted := make(map[string]bool)
 	var convoyOrders []OrderInput
@@ -195,9 +243,9 @@ type convoyPlan struct {
 }
 
 // planConvoys finds armies that would benefit from convoy transport and matches
-// them with available fleets. Handles both fleets already at sea and fleets
-// that need to move to sea provinces. Returns convoy orders and the set of
-// unit provinces consumed by convoy plans.
+// them with chains of available fleets, supporting the landing with a spare
+// unit when it may be contested. Returns convoy orders and the set of unit
+// provinces consumed by convoy plans.
 func (h HeuristicStrategy) planConvoys(
 	gs *diplomacy.GameState,
 	power diplomacy.Power,
@@ -242,7 +290,7 @@ func (h HeuristicStrategy) planConvoys(
 		armyCandidates = append(armyCandidates, ai)
 	}
 
-	// Find convoy plans: for each army, try to find a 1-hop convoy to a valuable SC
+	// Find convoy plans: for each army, try to find a convoy to a valuable SC
 	var plans []convoyPlan
 	for _, ai := range armyCandidates {
 		if supportConverted[ai.unit.Province] {
@@ -351,50 +399,89 @@ func (h HeuristicStrategy) planConvoys(
 			OrderType: "move",
 			Target:    plan.dest,
 		})
+
+		// Support the landing with a spare unit when it may be contested
+		if !landingContested(plan.dest, power, gs, m) {
+			continue
+		}
+		for _, u := range allUnits {
+			if assignedUnits[u.Province] || convoyConverted[u.Province] || supportConverted[u.Province] {
+				continue
+			}
+			if CanSupportMove(u.Province, plan.army.Province, plan.dest, u, gs, m) {
+				convoyConverted[u.Province] = true
+				convoyOrders = append(convoyOrders, OrderInput{
+					UnitType:    u.Type.String(),
+					Location:    u.Province,
+					Coast:       string(u.Coast),
+					OrderType:   "support",
+					AuxLoc:      plan.army.Province,
+					AuxTarget:   plan.dest,
+					AuxUnitType: "army",
+				})
+				break
+			}
+		}
 	}
 
 	return convoyOrders, convoyConverted
 }
 
-// findConvoyPlans finds all single-hop convoy routes for an army using available fleets.
-// Each plan uses one fleet in a sea province adjacent to both the army and the destination.
-func findConvoyPlans(army diplomacy.Unit, power diplomacy.Power, fleets []diplomacy.Unit, gs *diplomacy.GameState, m *diplomacy.DiplomacyMap) []convoyPlan {
-	var plans []convoyPlan
+// maxConvoyFleets is the longest convoy chain bots plan; the Lepanto's
+// A apu - ion - eas - syr takes two fleets.
+const maxConvoyFleets = 3
 
-	// Find sea provinces adjacent to the army
-	armySeaNeighbors := make(map[string]bool)
-	for _, adj := range m.Adjacencies[army.Province] {
-		if adj.FleetOK {
-			p := m.Provinces[adj.To]
-			if p != nil && p.Type == diplomacy.Sea {
-				armySeaNeighbors[adj.To] = true
-			}
+// findConvoyPlans finds convoy routes for an army through chains of up to
+// maxConvoyFleets of the power's fleets at sea, the first next to the army
+// and each next to the one before. Destinations are only planned by their
+// shortest chains, and never when the army could walk there instead.
+func findConvoyPlans(army diplomacy.Unit, power diplomacy.Power, fleets []diplomacy.Unit, gs *diplomacy.GameState, m *diplomacy.DiplomacyMap) []convoyPlan {
+	atSea := make(map[string]diplomacy.Unit)
+	for _, f := range fleets {
+		if p := m.Provinces[f.Province]; f.Power == power && p != nil && p.Type == diplomacy.Sea {
+			atSea[f.Province] = f
 		}
 	}
 
-	// For each fleet in a sea province adjacent to the army, find coastal
-	// destinations adjacent to that sea province
-	for _, fleet := range fleets {
-		if fleet.Power != power {
-			continue
-		}
-		seaProv := fleet.Province
-		p := m.Provinces[seaProv]
-		if p == nil || p.Type != diplomacy.Sea {
-			continue
+	// Breadth-first over the fleets, so every sea is reached by a shortest chain.
+	var chains, frontier [][]string
+	seen := make(map[string]bool)
+	for _, adj := range m.Adjacencies[army.Province] {
+		if _, ok := atSea[adj.To]; ok && adj.FleetOK && !seen[adj.To] {
+			seen[adj.To] = true
+			frontier = append(frontier, []string{adj.To})
 		}
-		if !armySeaNeighbors[seaProv] {
-			continue
+	}
+	for len(frontier) > 0 {
+		chains = append(chains, frontier...)
+		var next [][]string
+		for _, chain := range frontier {
+			if len(chain) == maxConvoyFleets {
+				continue
+			}
+			for _, adj := range m.Adjacencies[chain[len(chain)-1]] {
+				if _, ok := atSea[adj.To]; ok && !seen[adj.To] {
+					seen[adj.To] = true
+					next = append(next, append(slices.Clone(chain), adj.To))
+				}
+			}
 		}
+		frontier = next
+	}
 
-		// Find destinations reachable from the sea province
-		for _, adj := range m.Adjacencies[seaProv] {
+	var plans []convoyPlan
+	shortest := make(map[string]int) // destination -> fleets in its shortest chain
+	for _, chain := range chains {
+		for _, adj := range m.Adjacencies[chain[len(chain)-1]] {
 			dest := adj.To
 			destProv := m.Provinces[dest]
 			if destProv == nil || destProv.Type == diplomacy.Sea {
 				continue
 			}
-			if dest == army.Province {
+			if dest == army.Province || m.Adjacent(army.Province, army.Coast, dest, diplomacy.NoCoast, false) {
+				continue
+			}
+			if n, ok := shortest[dest]; ok && n < len(chain) {
 				continue
 			}
 
@@ -422,11 +509,16 @@ func findConvoyPlans(army diplomacy.Unit, power diplomacy.Power, fleets []diplom
 				continue
 			}
 
+			shortest[dest] = len(chain)
+			chainFleets := make([]diplomacy.Unit, len(chain))
+			for i, sea := range chain {
+				chainFleets[i] = atSea[sea]
+			}
 			plans = append(plans, convoyPlan{
 				army:      army,
 				dest:      dest,
-				seaChain:  []string{seaProv},
-				fleets:    []diplomacy.Unit{fleet},
+				seaChain:  chain,
+				fleets:    chainFleets,
 				destScore: score,
 			})
 		}
@@ -435,6 +527,20 @@ func findConvoyPlans(army diplomacy.Unit, power diplomacy.Power, fleets []diplom
 	return plans
 }
 
+// landingContested reports whether a convoyed army landing in dest may be
+// bounced: dest is held, or another power's unit could move in.
+func landingContested(dest string, power diplomacy.Power, gs *diplomacy.GameState, m *diplomacy.DiplomacyMap) bool {
+	if u := gs.UnitAt(dest); u != nil && u.Power != power {
+		return true
+	}
+	for _, u := range gs.Units {
+		if u.Power != power && m.Adjacent(u.Province, u.Coast, dest, diplomacy.NoCoast, u.Type == diplomacy.Fleet) {
+			return true
+		}
+	}
+	return false
+}
+
 // nearestSCCache caches NearestUnownedSCByUnit results keyed by province+isFleet.
 // The game state doesn't change within a single order-generation call, so
 // results are stable for the duration.
@@ -507,6 +613,13 @@ func (h HeuristicStrategy) scoreMoves(gs *diplomacy.GameState, power diplomacy.P
 		isFleet := u.Type == diplomacy.Fleet
 		adj := m.ProvincesAdjacentTo(u.Province, u.Coast, isFleet)
 
+		// Risk takers readily leave a threatened center of their own; cautious
+		// bots are reluctant to.
+		leaveBonus := 0.0
+		if h.Personality.RiskTolerance != 0 && gs.SupplyCenters[u.Province] == power {
+			leaveBonus = personalityExposureBonus * h.Personality.RiskTolerance * float64(ProvinceThreat(u.Province, power, gs, m))
+		}
+
 		for _, target := range adj {
 			prov := m.Provinces[target]
 			if prov == nil {
@@ -528,15 +641,19 @@ func (h HeuristicStrategy) scoreMoves(gs *diplomacy.GameState, power diplomacy.P
 				case owner == "":
 					score += 10 // unowned neutral SC
 				case owner != power:
-					score += 7 // enemy SC
+					score += 7 * h.Personality.attackWeight() // enemy SC
 				default:
 					score += 1 // own SC (low priority to move there)
 				}
 			}
+			score += leaveBonus
+
+			// Teaching games: spare the human players' home centers
+			score -= mercyCost(gs, power, target, m)
 
-			// Fall departure penalty: moving away from an unowned SC during Fall
-			// forfeits the imminent capture at year-end.
-			if gs.Season == diplomacy.Fall {
+			// Departure penalty: moving away from an unowned SC in the year's last
+			// season (Fall) forfeits the imminent capture at year-end.
+			if gs.IsLastSeason() {
 				srcProv := m.Provinces[u.Province]
 				if srcProv != nil && srcProv.IsSupplyCenter && gs.SupplyCenters[u.Province] != power {
 					score -= 12
@@ -613,79 +730,55 @@ func (h HeuristicStrategy) scoreMoves(gs *diplomacy.GameState, power diplomacy.P
 	return candidates
 }
 
-// GenerateRetreatOrders scores retreat destinations and picks the best valid one.
+// GenerateRetreatOrders scores retreat destinations and picks the best valid
+// one. Two units retreating to the same province are both destroyed, so the
+// power's own units never share a destination and destinations another
+// power's dislodged units could also reach are penalized.
 func (HeuristicStrategy) GenerateRetreatOrders(gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap) []OrderInput {
-	var orders []OrderInput
+	type dislodged struct {
+		unit    diplomacy.DislodgedUnit
+		targets []retreatTarget
+	}
+	var units []dislodged
 	for _, d := range gs.Dislodged {
-		if d.Unit.Power != power {
-			continue
+		if d.Unit.Power == power {
+			units = append(units, dislodged{d, retreatTargets(gs, d, m)})
 		}
+	}
+	// The most constrained units choose first.
+	sort.SliceStable(units, func(i, j int) bool { return len(units[i].targets) < len(units[j].targets) })
 
-		isFleet := d.Unit.Type == diplomacy.Fleet
-		adj := m.ProvincesAdjacentTo(d.DislodgedFrom, d.Unit.Coast, isFleet)
-
-		type retreatOption struct {
-			target string
-			coast  string
-			score  float64
-		}
-		var options []retreatOption
-
-		for _, target := range adj {
-			if target == d.AttackerFrom {
-				continue
-			}
-			if gs.UnitAt(target) != nil {
-				continue
-			}
-			prov := m.Provinces[target]
-			if prov == nil {
-				continue
-			}
-			if isFleet && prov.Type == diplomacy.Land {
-				continue
-			}
-			if !isFleet && prov.Type == diplomacy.Sea {
+	var orders []OrderInput
+	claimed := make(map[string]bool)
+	for _, u := range units {
+		d := u.unit
+		best, bestScore := -1, 0.0
+		for i, t := range u.targets {
+			if claimed[t.province] {
 				continue
 			}
-
 			score := float64(0)
 			// Prefer retreating to own SCs for defense
-			if prov.IsSupplyCenter && gs.SupplyCenters[target] == power {
+			if m.Provinces[t.province].IsSupplyCenter && gs.SupplyCenters[t.province] == power {
 				score += 5
 			}
 			// Penalize threatened destinations
-			score -= 2 * float64(ProvinceThreat(target, power, gs, m))
+			score -= 2 * float64(ProvinceThreat(t.province, power, gs, m))
+			// Penalize destinations another power's retreat may bounce off
+			score -= 3 * float64(retreatContenders(gs, power, t.province, m))
+			// Of two coasts, prefer the one with more onward moves
+			if t.coast != "" {
+				score += 0.1 * float64(len(m.ProvincesAdjacentTo(t.province, diplomacy.Coast(t.coast), true)))
+			}
 			// Small random factor
 			score += botFloat64()
 
-			targetCoast := ""
-			if isFleet && m.HasCoasts(target) {
-				coasts := m.FleetCoastsTo(d.DislodgedFrom, d.Unit.Coast, target)
-				if len(coasts) == 0 {
-					continue
-				}
-				targetCoast = string(coasts[0])
+			if best < 0 || score > bestScore {
+				best, bestScore = i, score
 			}
-
-			// Validate
-			ro := diplomacy.RetreatOrder{
-				UnitType:    d.Unit.Type,
-				Power:       power,
-				Location:    d.DislodgedFrom,
-				Coast:       d.Unit.Coast,
-				Type:        diplomacy.RetreatMove,
-				Target:      target,
-				TargetCoast: diplomacy.Coast(targetCoast),
-			}
-			if diplomacy.ValidateRetreatOrder(ro, gs, m) != nil {
-				continue
-			}
-
-			options = append(options, retreatOption{target, targetCoast, score})
 		}
 
-		if len(options) == 0 {
+		if best < 0 {
 			orders = append(orders, OrderInput{
 				UnitType:  d.Unit.Type.String(),
 				Location:  d.DislodgedFrom,
@@ -695,17 +788,14 @@ func (HeuristicStrategy) GenerateRetreatOrders(gs *diplomacy.GameState, power di
 			continue
 		}
 
-		sort.Slice(options, func(i, j int) bool {
-			return options[i].score > options[j].score
-		})
-		best := options[0]
+		claimed[u.targets[best].province] = true
 		orders = append(orders, OrderInput{
 			UnitType:    d.Unit.Type.String(),
 			Location:    d.DislodgedFrom,
 			Coast:       string(d.Unit.Coast),
 			OrderType:   "retreat_move",
-			Target:      best.target,
-			TargetCoast: best.coast,
+			Target:      u.targets[best].province,
+			TargetCoast: u.targets[best].coast,
 		})
 	}
 	return orders
@@ -715,9 +805,7 @@ func (HeuristicStrategy) GenerateRetreatOrders(gs *diplomacy.GameState, power di
 // prefer fleets to maintain convoy capability. Disbands protect convoy-capable
 // fleets and penalize stranded armies.
 func (HeuristicStrategy) GenerateBuildOrders(gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap) []OrderInput {
-	scCount := gs.SupplyCenterCount(power)
-	unitCount := gs.UnitCount(power)
-	diff := scCount - unitCount
+	diff := gs.AdjustmentDelta(power)
 
 	var orders []OrderInput
 
@@ -734,13 +822,13 @@ func (HeuristicStrategy) GenerateBuildOrders(gs *diplomacy.GameState, power dipl
 // other power's home SC by army movement alone (i.e., separated by sea).
 func isIslandPower(power diplomacy.Power, m *diplomacy.DiplomacyMap) bool {
 	dm := getDistMatrix(m)
-	homes := diplomacy.HomeCenters(power)
+	homes := m.HomeCenters(power)
 	for _, home := range homes {
 		for _, otherPower := range diplomacy.AllPowers() {
 			if otherPower == power {
 				continue
 			}
-			for _, otherHome := range diplomacy.HomeCenters(otherPower) {
+			for _, otherHome := range m.HomeCenters(otherPower) {
 				if dm.Distance(home, otherHome) >= 0 {
 					return false
 				}
@@ -776,7 +864,7 @@ func isNavalPower(power diplomacy.Power) bool {
 // generateBuilds picks home SCs closest to nearest unowned SC and decides unit type.
 // Island powers and powers with stranded armies heavily prefer fleets.
 func generateBuilds(gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap, count int) []OrderInput {
-	homes := diplomacy.HomeCenters(power)
+	homes := m.HomeCenters(power)
 
 	type buildOption struct {
 		loc  string
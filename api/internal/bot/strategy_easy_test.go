@@ -1,6 +1,7 @@
 package bot
 
 import (
+	"slices"
 	"testing"
 
 	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
@@ -279,6 +280,27 @@ func TestHeuristicStrategy_GenerateBuildOrders_Disbands(t *testing.T) {
 	}
 }
 
+// TestHeuristicStrategy_GenerateBuildOrders_Credits verifies that a power
+// with as many units as centers still rebuilds a unit it lost to a retreat
+// under the retreat credits house rule.
+func TestHeuristicStrategy_GenerateBuildOrders_Credits(t *testing.T) {
+	gs := diplomacy.NewInitialState()
+	gs.Season, gs.Phase = diplomacy.Fall, diplomacy.PhaseBuild
+	for i, u := range gs.Units {
+		if u.Province == "par" {
+			gs.Units = append(gs.Units[:i], gs.Units[i+1:]...)
+			break
+		}
+	}
+	delete(gs.SupplyCenters, "bre")
+	gs.BuildCredits = map[diplomacy.Power]int{diplomacy.France: 1}
+
+	orders := HeuristicStrategy{}.GenerateBuildOrders(gs, diplomacy.France, diplomacy.StandardMap())
+	if len(orders) != 1 || orders[0].OrderType != "build" {
+		t.Fatalf("expected one build from the credit, got %+v", orders)
+	}
+}
+
 func TestHeuristicStrategy_GenerateBuildOrders_Balanced(t *testing.T) {
 	gs := diplomacy.NewInitialState()
 	m := diplomacy.StandardMap()
@@ -525,3 +547,134 @@ func TestHeuristicStrategy_ConvoyGeneration(t *testing.T) {
 		t.Error("expected at least one convoy order pair across all iterations, got none")
 	}
 }
+
+func TestHeuristicStrategy_BudgetsForGarrisons(t *testing.T) {
+	m := diplomacy.StandardMap()
+	s := HeuristicStrategy{}
+	newState := func(units ...diplomacy.Unit) *diplomacy.GameState {
+		gs := diplomacy.NewInitialState()
+		gs.Units = append(units, diplomacy.Unit{Type: diplomacy.Army, Power: diplomacy.Neutral, Province: "bel"})
+		return gs
+	}
+
+	// A lone army never walks into a garrison it can't dislodge.
+	lone := newState(diplomacy.Unit{Type: diplomacy.Army, Power: diplomacy.France, Province: "bur"})
+	for range 20 {
+		for _, o := range s.GenerateMovementOrders(lone, diplomacy.France, m) {
+			if o.OrderType == "move" && o.Target == "bel" {
+				t.Fatalf("expected no unsupported attack on the garrison, got %+v", o)
+			}
+		}
+	}
+
+	// Two armies attack it with support.
+	pair := newState(
+		diplomacy.Unit{Type: diplomacy.Army, Power: diplomacy.France, Province: "bur"},
+		diplomacy.Unit{Type: diplomacy.Army, Power: diplomacy.France, Province: "pic"},
+	)
+	for range 20 {
+		orders := s.GenerateMovementOrders(pair, diplomacy.France, m)
+		var attacks, supports int
+		for _, o := range orders {
+			if o.OrderType == "move" && o.Target == "bel" {
+				attacks++
+			}
+			if o.OrderType == "support" && o.AuxTarget == "bel" {
+				supports++
+			}
+		}
+		if attacks > 0 && supports == 0 {
+			t.Fatalf("expected the attack on the garrison to be supported, got %+v", orders)
+		}
+	}
+}
+
+// TestFindConvoyPlans_Chains verifies that convoy routes chain through up to
+// maxConvoyFleets fleets and no further.
+func TestFindConvoyPlans_Chains(t *testing.T) {
+	m := diplomacy.StandardMap()
+	army := diplomacy.Unit{Type: diplomacy.Army, Power: diplomacy.Italy, Province: "apu"}
+	var fleets []diplomacy.Unit
+	for _, sea := range []string{"ion", "eas", "tys", "wes", "mao"} {
+		fleets = append(fleets, diplomacy.Unit{Type: diplomacy.Fleet, Power: diplomacy.Italy, Province: sea})
+	}
+	gs := &diplomacy.GameState{
+		Year:   1902,
+		Season: diplomacy.Spring,
+		Phase:  diplomacy.PhaseMovement,
+		Units:  append([]diplomacy.Unit{army}, fleets...),
+		SupplyCenters: map[string]diplomacy.Power{
+			"nap": diplomacy.Italy, "rom": diplomacy.Italy, "ven": diplomacy.Italy,
+			"tun": diplomacy.Italy, "gre": diplomacy.Italy, "smy": diplomacy.Turkey,
+		},
+	}
+
+	chains := make(map[string][]string)
+	for _, p := range findConvoyPlans(army, diplomacy.Italy, fleets, gs, m) {
+		if len(p.fleets) != len(p.seaChain) {
+			t.Errorf("%s: %d fleets for chain %v", p.dest, len(p.fleets), p.seaChain)
+		}
+		chains[p.dest] = p.seaChain
+	}
+	if got := chains["smy"]; !slices.Equal(got, []string{"ion", "eas"}) {
+		t.Errorf("smy: chain %v, want [ion eas]", got)
+	}
+	if got := chains["spa"]; !slices.Equal(got, []string{"ion", "tys", "wes"}) {
+		t.Errorf("spa: chain %v, want [ion tys wes]", got)
+	}
+	if got, ok := chains["por"]; ok {
+		t.Errorf("por needs four fleets, got chain %v", got)
+	}
+	if _, ok := chains["nap"]; ok {
+		t.Error("expected no convoy to a province the army can walk to")
+	}
+}
+
+// TestPlanConvoys_LepantoResolves plays a planned two-fleet convoy into a
+// held center through the adjudicator: the spare fleet supports the landing
+// and the army takes Smyrna.
+func TestPlanConvoys_LepantoResolves(t *testing.T) {
+	m := diplomacy.StandardMap()
+	units := []diplomacy.Unit{
+		{Type: diplomacy.Army, Power: diplomacy.Italy, Province: "apu"},
+		{Type: diplomacy.Fleet, Power: diplomacy.Italy, Province: "ion"},
+		{Type: diplomacy.Fleet, Power: diplomacy.Italy, Province: "eas"},
+		{Type: diplomacy.Fleet, Power: diplomacy.Italy, Province: "aeg"},
+	}
+	gs := &diplomacy.GameState{
+		Year:   1902,
+		Season: diplomacy.Spring,
+		Phase:  diplomacy.PhaseMovement,
+		Units:  append(slices.Clone(units), diplomacy.Unit{Type: diplomacy.Army, Power: diplomacy.Turkey, Province: "smy"}),
+		SupplyCenters: map[string]diplomacy.Power{
+			"nap": diplomacy.Italy, "rom": diplomacy.Italy, "ven": diplomacy.Italy, "tun": diplomacy.Italy,
+			"gre": diplomacy.Italy, "bul": diplomacy.Italy, "con": diplomacy.Italy, "smy": diplomacy.Turkey,
+		},
+	}
+
+	inputs, used := HeuristicStrategy{}.planConvoys(gs, diplomacy.Italy, m, nil, map[string]bool{}, units, map[string]bool{})
+	if len(used) != len(units) {
+		t.Fatalf("expected every unit in the convoy, got %v", inputs)
+	}
+	orders := []diplomacy.Order{{UnitType: diplomacy.Army, Power: diplomacy.Turkey, Location: "smy", Type: diplomacy.OrderHold}}
+	supported := false
+	for _, o := range inputs {
+		if o.OrderType == "support" && o.AuxLoc == "apu" && o.AuxTarget == "smy" {
+			supported = true
+		}
+		orders = append(orders, orderInputToOrder(o, diplomacy.Italy))
+	}
+	if !supported {
+		t.Errorf("expected the landing to be supported, got %v", inputs)
+	}
+
+	results, dislodged := diplomacy.ResolveOrders(orders, gs, m)
+	for _, r := range results {
+		if r.Order.Location == "apu" && (r.Order.Target != "smy" || r.Result != diplomacy.ResultSucceeded) {
+			t.Errorf("convoyed army: %+v", r)
+		}
+	}
+	if len(dislodged) != 1 || dislodged[0].Unit.Power != diplomacy.Turkey {
+		t.Errorf("expected the Turkish army dislodged, got %v", dislodged)
+	}
+}
@@ -0,0 +1,129 @@
+package bot
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/freeeve/polite-betrayal/api/internal/bot/neural"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+const (
+	expertTimeBudget = 10 * time.Second
+	expertStrength   = 80 // neural weight of root candidates, as for hard-gonnx
+)
+
+func init() {
+	RegisterStrategy(StrategyRegistration{
+		Name:         "expert",
+		Description:  "Monte Carlo Tree Search over joint orders with the neural policy as prior; uses heuristic priors if models are missing.",
+		Capabilities: StrategyCapabilities{Diplomacy: true, DrawVoting: true, TimeControl: true},
+		New:          func(StrategyOptions) Strategy { return newExpertStrategy() },
+	})
+}
+
+// ExpertStrategy searches the joint order space of all powers with Monte
+// Carlo Tree Search, using the neural policy as the prior over its own
+// candidates when the gonnx models are available. Retreats, builds and
+// diplomacy follow hard-gonnx, or medium without models.
+type ExpertStrategy struct {
+	net *GonnxStrategy // nil without models
+}
+
+// newExpertStrategy loads the gonnx models, searching without a prior if
+// they are missing.
+func newExpertStrategy() *ExpertStrategy {
+	net, err := newGonnxStrategy()
+	if err != nil {
+		log.Printf("bot: expert model load failed: %v; searching with heuristic priors", err)
+		return &ExpertStrategy{}
+	}
+	return &ExpertStrategy{net: net}
+}
+
+func (*ExpertStrategy) Name() string { return "expert" }
+
+// ShouldVoteDraw votes like HardStrategy.
+func (*ExpertStrategy) ShouldVoteDraw(gs *diplomacy.GameState, power diplomacy.Power) bool {
+	return HardStrategy{}.ShouldVoteDraw(gs, power)
+}
+
+// DecideDraw implements ValueDrawVoter with the hard-gonnx value model.
+func (s *ExpertStrategy) DecideDraw(history []*diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap) (DrawDecision, bool) {
+	if s.net == nil {
+		return DrawDecision{}, false
+	}
+	return s.net.DecideDraw(history, power, m)
+}
+
+func (*ExpertStrategy) GenerateDiplomaticMessages(
+	gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap,
+	received []DiplomaticIntent,
+) []DiplomaticIntent {
+	return TacticalStrategy{}.GenerateDiplomaticMessages(gs, power, m, received)
+}
+
+func (s *ExpertStrategy) GenerateRetreatOrders(gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap) []OrderInput {
+	if s.net != nil {
+		return s.net.GenerateRetreatOrders(gs, power, m)
+	}
+	return TacticalStrategy{}.GenerateRetreatOrders(gs, power, m)
+}
+
+func (s *ExpertStrategy) GenerateBuildOrders(gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap) []OrderInput {
+	if s.net != nil {
+		return s.net.GenerateBuildOrders(gs, power, m)
+	}
+	return TacticalStrategy{}.GenerateBuildOrders(gs, power, m)
+}
+
+// GenerateOrders implements StrategyV2. Movement search stops at the time
+// budget, when ctx is done, or shortly before the phase deadline, whichever
+// comes first.
+func (s *ExpertStrategy) GenerateOrders(ctx context.Context, gc *GameContext) ([]OrderInput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	switch gc.State.Phase {
+	case diplomacy.PhaseRetreat:
+		return s.GenerateRetreatOrders(gc.State, gc.Power, gc.Map), nil
+	case diplomacy.PhaseBuild:
+		return s.GenerateBuildOrders(gc.State, gc.Power, gc.Map), nil
+	default:
+		return s.movementOrders(ctx, gc.State, gc.Power, gc.Map, searchDeadline(ctx, gc, expertTimeBudget)), nil
+	}
+}
+
+// GenerateMovementOrders searches for the full time budget.
+func (s *ExpertStrategy) GenerateMovementOrders(gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap) []OrderInput {
+	return s.movementOrders(context.Background(), gs, power, m, time.Now().Add(expertTimeBudget))
+}
+
+// movementOrders plays the opening book while it lasts and otherwise runs MCTS
+// until deadline or until ctx is done, falling back to medium if the search
+// finds nothing.
+func (s *ExpertStrategy) movementOrders(ctx context.Context, gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap, deadline time.Time) []OrderInput {
+	if len(gs.UnitsOf(power)) == 0 {
+		return nil
+	}
+	if bookYear(gs.Year) {
+		if opening := LookupOpening(gs, power, m); opening != nil {
+			return opening
+		}
+	}
+
+	var logits []float32
+	var valueScores *[4]float32
+	if s.net != nil {
+		logits = s.net.runPolicy(gs, power, m)
+		if vs, err := s.net.RunValueNetwork(gs, power, m); err == nil {
+			valueScores = &vs
+		}
+	}
+	result := neural.MCTSSearch(ctx, power, gs, m, deadline, logits, valueScores, expertStrength)
+	if len(result.Orders) == 0 {
+		return TacticalStrategy{}.GenerateMovementOrders(gs, power, m)
+	}
+	return OrdersToOrderInputs(result.Orders)
+}
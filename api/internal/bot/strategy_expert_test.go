@@ -0,0 +1,36 @@
+package bot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+func TestExpertStrategy_GenerateOrdersStopsAtContextDeadline(t *testing.T) {
+	gs := diplomacy.NewInitialState()
+	gs.Year = 1902 // skip the opening book so the search runs
+	m := diplomacy.StandardMap()
+	gc := &GameContext{State: gs, Power: diplomacy.Austria, Map: m}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	orders, err := newExpertStrategy().GenerateOrders(ctx, gc)
+	if err != nil {
+		t.Fatalf("GenerateOrders: %v", err)
+	}
+	if len(orders) != len(gs.UnitsOf(diplomacy.Austria)) {
+		t.Errorf("expected %d orders, got %d", len(gs.UnitsOf(diplomacy.Austria)), len(orders))
+	}
+	for _, o := range OrderInputsToOrders(orders, diplomacy.Austria) {
+		if err := diplomacy.ValidateOrder(o, gs, m); err != nil {
+			t.Errorf("illegal order: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("search ran %v despite a 200ms context deadline", elapsed)
+	}
+}
@@ -2,6 +2,7 @@ package bot
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"io"
 	"log"
@@ -70,6 +71,9 @@ type ExternalStrategy struct {
 	engineVersion string
 	// modelHash is the 8-char hex model hash reported via "info string model_hash".
 	modelHash string
+
+	// health, if set, records query outcomes for the engine's circuit breaker.
+	health *engineHealth
 }
 
 // NewExternalStrategy spawns the engine process, performs the DUI handshake
@@ -97,6 +101,10 @@ func NewExternalStrategy(enginePath string, power diplomacy.Power, opts ...Exter
 	return e, nil
 }
 
+// garrisonFallback plays positions with neutral garrisons, which the engine's
+// DFEN parser does not accept.
+var garrisonFallback Strategy = &HardStrategy{}
+
 // Name returns the strategy name.
 func (e *ExternalStrategy) Name() string { return "realpolitik" }
 
@@ -108,7 +116,10 @@ func (e *ExternalStrategy) ModelHash() string { return e.modelHash }
 
 // GenerateMovementOrders sends the position to the engine and converts the DSON
 // bestorders response into movement-phase OrderInputs.
-func (e *ExternalStrategy) GenerateMovementOrders(gs *diplomacy.GameState, power diplomacy.Power, _ *diplomacy.DiplomacyMap) []OrderInput {
+func (e *ExternalStrategy) GenerateMovementOrders(gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap) []OrderInput {
+	if len(gs.Garrisons()) > 0 {
+		return garrisonFallback.GenerateMovementOrders(gs, power, m)
+	}
 	dsonOrders, err := e.queryEngine(gs, power)
 	if err != nil {
 		log.Printf("external strategy: movement orders failed: %v; falling back to hold", err)
@@ -125,7 +136,10 @@ func (e *ExternalStrategy) GenerateMovementOrders(gs *diplomacy.GameState, power
 
 // GenerateRetreatOrders sends the position to the engine and converts the DSON
 // bestorders response into retreat-phase OrderInputs.
-func (e *ExternalStrategy) GenerateRetreatOrders(gs *diplomacy.GameState, power diplomacy.Power, _ *diplomacy.DiplomacyMap) []OrderInput {
+func (e *ExternalStrategy) GenerateRetreatOrders(gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap) []OrderInput {
+	if len(gs.Garrisons()) > 0 {
+		return garrisonFallback.GenerateRetreatOrders(gs, power, m)
+	}
 	dsonOrders, err := e.queryEngine(gs, power)
 	if err != nil {
 		log.Printf("external strategy: retreat orders failed: %v; falling back to disband", err)
@@ -142,7 +156,10 @@ func (e *ExternalStrategy) GenerateRetreatOrders(gs *diplomacy.GameState, power
 
 // GenerateBuildOrders sends the position to the engine and converts the DSON
 // bestorders response into build-phase OrderInputs.
-func (e *ExternalStrategy) GenerateBuildOrders(gs *diplomacy.GameState, power diplomacy.Power, _ *diplomacy.DiplomacyMap) []OrderInput {
+func (e *ExternalStrategy) GenerateBuildOrders(gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap) []OrderInput {
+	if len(gs.Garrisons()) > 0 {
+		return garrisonFallback.GenerateBuildOrders(gs, power, m)
+	}
 	dsonOrders, err := e.queryEngine(gs, power)
 	if err != nil {
 		log.Printf("external strategy: build orders failed: %v; falling back to waive/civil disorder", err)
@@ -157,6 +174,53 @@ func (e *ExternalStrategy) GenerateBuildOrders(gs *diplomacy.GameState, power di
 	return inputs
 }
 
+// GenerateOrders implements StrategyV2. It sends the press received this game
+// to the engine before the position and shortens the engine's movetime so the
+// answer arrives before ctx or the phase deadline expires. Engine failures
+// fall back to the same safe orders as the per-phase methods.
+func (e *ExternalStrategy) GenerateOrders(ctx context.Context, gc *GameContext) ([]OrderInput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	gs, power := gc.State, gc.Power
+	if len(gs.Garrisons()) > 0 {
+		return GenerateOrders(ctx, garrisonFallback, gc)
+	}
+
+	moveTime := time.Duration(e.moveTimeMs) * time.Millisecond
+	moveTime = min(moveTime, max(time.Until(searchDeadline(ctx, gc, moveTime)), minEngineMoveTime))
+
+	dsonOrders, err := e.queryEngineTimed(gs, power, gc.Received, int(moveTime.Milliseconds()))
+	if err != nil {
+		log.Printf("external strategy: %s orders failed: %v; falling back", gs.Phase, err)
+		switch gs.Phase {
+		case diplomacy.PhaseRetreat:
+			return disbandAllDislodged(gs, power), nil
+		case diplomacy.PhaseBuild:
+			return nil, nil
+		default:
+			return holdAll(gs, power), nil
+		}
+	}
+
+	var inputs []OrderInput
+	for _, d := range dsonOrders {
+		switch gs.Phase {
+		case diplomacy.PhaseRetreat:
+			inputs = append(inputs, retreatOrderToInput(diplomacy.DSONToRetreatOrder(d, power)))
+		case diplomacy.PhaseBuild:
+			inputs = append(inputs, buildOrderToInput(diplomacy.DSONToBuildOrder(d, power)))
+		default:
+			inputs = append(inputs, orderToInput(diplomacy.DSONToOrder(d, power)))
+		}
+	}
+	return inputs, nil
+}
+
+// minEngineMoveTime is the shortest movetime sent to the engine, even when the
+// deadline has nearly passed.
+const minEngineMoveTime = 100 * time.Millisecond
+
 // Close sends "quit" to the engine and waits for process exit. If the process
 // does not exit within 3 seconds, it is forcefully killed.
 func (e *ExternalStrategy) Close() error {
@@ -254,6 +318,25 @@ func (e *ExternalStrategy) queryEngine(gs *diplomacy.GameState, power diplomacy.
 // queryEngineWithPress sends press messages, position, setpower, and go to the engine.
 // Returns parsed DSONOrders and captures press_out responses.
 func (e *ExternalStrategy) queryEngineWithPress(gs *diplomacy.GameState, power diplomacy.Power, pressMessages []DiplomaticIntent) ([]diplomacy.DSONOrder, error) {
+	return e.queryEngineTimed(gs, power, pressMessages, e.moveTimeMs)
+}
+
+// queryEngineTimed is queryEngineWithPress with an explicit movetime in
+// milliseconds.
+func (e *ExternalStrategy) queryEngineTimed(gs *diplomacy.GameState, power diplomacy.Power, pressMessages []DiplomaticIntent, moveTimeMs int) ([]diplomacy.DSONOrder, error) {
+	orders, err := e.runQuery(gs, power, pressMessages, moveTimeMs)
+	if e.health != nil {
+		if err != nil {
+			e.health.failure(err)
+		} else {
+			e.health.success()
+		}
+	}
+	return orders, err
+}
+
+// runQuery sends one query to the engine and parses its bestorders reply.
+func (e *ExternalStrategy) runQuery(gs *diplomacy.GameState, power diplomacy.Power, pressMessages []DiplomaticIntent, moveTimeMs int) ([]diplomacy.DSONOrder, error) {
 	e.mu.Lock()
 	if e.closed {
 		e.mu.Unlock()
@@ -266,6 +349,9 @@ func (e *ExternalStrategy) queryEngineWithPress(gs *diplomacy.GameState, power d
 	}
 
 	dfen := diplomacy.EncodeDFEN(gs)
+	if _, err := diplomacy.DecodeDFENMode(dfen, diplomacy.DFENStrict); err != nil {
+		return nil, fmt.Errorf("position is not valid DFEN: %w", err)
+	}
 	e.send(fmt.Sprintf("position %s", dfen))
 	e.send(fmt.Sprintf("setpower %s", string(power)))
 
@@ -277,7 +363,7 @@ func (e *ExternalStrategy) queryEngineWithPress(gs *diplomacy.GameState, power d
 		}
 	}
 
-	e.send(fmt.Sprintf("go movetime %d", e.moveTimeMs))
+	e.send(fmt.Sprintf("go movetime %d", moveTimeMs))
 
 	resp, err := e.readEngineResponse()
 	if err != nil {
@@ -501,7 +587,7 @@ func buildOrderToInput(bo diplomacy.BuildOrder) OrderInput {
 // GenerateDiplomaticMessages implements DiplomaticStrategy for ExternalStrategy.
 // Sends received press to the engine and returns outbound press from the engine.
 func (e *ExternalStrategy) GenerateDiplomaticMessages(gs *diplomacy.GameState, power diplomacy.Power, _ *diplomacy.DiplomacyMap, received []DiplomaticIntent) []DiplomaticIntent {
-	// The press was already sent during the last queryEngineWithPress call.
+	// The press was already sent during the last query made by GenerateOrders.
 	// Parse any press_out lines from the engine into DiplomaticIntents.
 	var responses []DiplomaticIntent
 	for _, line := range e.lastPressOut {
@@ -1,9 +1,10 @@
 package bot
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
-	"sync"
 	"time"
 
 	gonnx "github.com/advancedclimatesystems/gonnx"
@@ -16,10 +17,27 @@ import (
 // Set at startup from GONNX_MODEL_PATH env var or default to "engine/models".
 var GonnxModelPath string
 
-// newGonnxOrFallback attempts to create a GonnxStrategy. If loading fails,
-// it falls back to HardStrategy.
-func newGonnxOrFallback() Strategy {
-	s, err := newGonnxStrategy()
+var errValueModelMissing = errors.New("value model not loaded")
+
+// gonnxTimeBudget is how long a movement search runs without a SearchBudget.
+const gonnxTimeBudget = 5 * time.Second
+
+func init() {
+	RegisterStrategy(StrategyRegistration{
+		Name:         "hard-gonnx",
+		Description:  "Neural policy and value networks run in pure Go; falls back to hard if models are missing.",
+		Capabilities: StrategyCapabilities{DrawVoting: true, TimeControl: true},
+		Options: []StrategyOption{
+			{Name: "model", Default: DefaultModel, Description: "Model to play with, as added to the model manager from GONNX_MODELS."},
+		},
+		New: func(opts StrategyOptions) Strategy { return newGonnxOrFallback(opts.Get("model", DefaultModel)) },
+	})
+}
+
+// newGonnxOrFallback attempts to create a GonnxStrategy playing the named
+// model. If loading fails, it falls back to HardStrategy.
+func newGonnxOrFallback(model string) Strategy {
+	s, err := newGonnxStrategyFor(Models, model)
 	if err != nil {
 		log.Printf("bot: hard-gonnx requested but model load failed: %v; falling back to hard", err)
 		return &HardStrategy{}
@@ -28,48 +46,96 @@ func newGonnxOrFallback() Strategy {
 }
 
 // GonnxStrategy uses gonnx (pure Go ONNX runtime) to run neural network
-// inference for order generation. It loads policy and value ONNX models
-// and decodes policy logits into scored legal orders.
+// inference for order generation. It takes its policy and value models
+// from a ModelManager, so they can be reloaded mid-game, and decodes
+// policy logits into scored legal orders.
 type GonnxStrategy struct {
-	policy *gonnx.Model
-	value  *gonnx.Model
+	models *ModelManager
+	model  string
 	adj    []float32
-	mu     sync.Mutex
 }
 
-// newGonnxStrategy loads models and builds the adjacency matrix.
+// newGonnxStrategy loads the default model and builds the adjacency matrix.
 func newGonnxStrategy() (*GonnxStrategy, error) {
-	path := GonnxModelPath
-	if path == "" {
-		path = "engine/models"
+	return newGonnxStrategyFor(Models, DefaultModel)
+}
+
+// newGonnxStrategyFor loads the named model from models and builds the
+// adjacency matrix.
+func newGonnxStrategyFor(models *ModelManager, model string) (*GonnxStrategy, error) {
+	if _, err := models.get(model); err != nil {
+		return nil, err
 	}
+	m := diplomacy.StandardMap()
+	return &GonnxStrategy{
+		models: models,
+		model:  model,
+		adj:    neural.BuildAdjacencyMatrix(m),
+	}, nil
+}
 
-	policyPath := path + "/policy_v2.onnx"
-	policy, err := gonnx.NewModelFromFile(policyPath)
+// current returns the models to run now, or nil if there are none.
+func (s *GonnxStrategy) current() *gonnxModels {
+	if s.models == nil {
+		return nil
+	}
+	models, err := s.models.get(s.model)
 	if err != nil {
-		return nil, err
+		return nil
 	}
+	return models
+}
 
-	valuePath := path + "/value_v2.onnx"
-	value, err := gonnx.NewModelFromFile(valuePath)
+// NewValueNetwork loads the gonnx value model from GonnxModelPath.
+func NewValueNetwork() (ValueNetwork, error) {
+	s, err := newGonnxStrategy()
 	if err != nil {
-		log.Printf("bot/gonnx: value model not found at %s: %v (value eval disabled)", valuePath, err)
+		return nil, err
+	}
+	if s.current().value == nil {
+		return nil, errValueModelMissing
 	}
+	return s, nil
+}
 
-	m := diplomacy.StandardMap()
-	adj := neural.BuildAdjacencyMatrix(m)
+func (s *GonnxStrategy) Name() string { return "hard-gonnx" }
 
-	return &GonnxStrategy{
-		policy: policy,
-		value:  value,
-		adj:    adj,
-	}, nil
+// ShouldVoteDraw votes like HardStrategy. It is used when the value model
+// is unavailable; DecideDraw takes precedence otherwise.
+func (*GonnxStrategy) ShouldVoteDraw(gs *diplomacy.GameState, power diplomacy.Power) bool {
+	return HardStrategy{}.ShouldVoteDraw(gs, power)
 }
 
-func (s *GonnxStrategy) Name() string { return "hard-gonnx" }
+// DecideDraw implements ValueDrawVoter with the value model.
+func (s *GonnxStrategy) DecideDraw(history []*diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap) (DrawDecision, bool) {
+	return decideDrawByValue(s, history, power, m)
+}
+
+// GenerateOrders implements StrategyV2. Movement search runs until the time
+// budget, the SearchBudget if one is given, ctx's deadline or shortly before
+// the phase deadline, whichever comes first.
+func (s *GonnxStrategy) GenerateOrders(ctx context.Context, gc *GameContext) ([]OrderInput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	switch gc.State.Phase {
+	case diplomacy.PhaseRetreat:
+		return s.GenerateRetreatOrders(gc.State, gc.Power, gc.Map), nil
+	case diplomacy.PhaseBuild:
+		return s.GenerateBuildOrders(gc.State, gc.Power, gc.Map), nil
+	default:
+		moveTime := time.Until(searchDeadline(ctx, gc, gonnxTimeBudget))
+		return s.movementOrders(gc.State, gc.Power, gc.Map, moveTime), nil
+	}
+}
 
 // GenerateMovementOrders runs RM+ search with neural policy and value guidance.
 func (s *GonnxStrategy) GenerateMovementOrders(gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap) []OrderInput {
+	return s.movementOrders(gs, power, m, gonnxTimeBudget)
+}
+
+// movementOrders runs the RM+ search for up to moveTime.
+func (s *GonnxStrategy) movementOrders(gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap, moveTime time.Duration) []OrderInput {
 	logits := s.runPolicy(gs, power, m)
 	if logits == nil {
 		log.Printf("bot/gonnx: policy inference failed for %s, falling back to medium", power)
@@ -77,14 +143,14 @@ func (s *GonnxStrategy) GenerateMovementOrders(gs *diplomacy.GameState, power di
 	}
 
 	var valueScores *[4]float32
-	if s.value != nil {
+	if models := s.current(); models != nil && models.value != nil {
 		vs, err := s.RunValueNetwork(gs, power, m)
 		if err == nil {
 			valueScores = &vs
 		}
 	}
 
-	result := neural.RegretMatchingSearch(power, gs, m, 5*time.Second, logits, valueScores, 80)
+	result := neural.RegretMatchingSearch(power, gs, m, moveTime, logits, valueScores, 80)
 	if len(result.Orders) == 0 {
 		log.Printf("bot/gonnx: RM+ search returned no orders for %s, falling back to policy greedy", power)
 		perUnit := neural.DecodePolicyLogits(logits, gs, power, m, 1)
@@ -167,9 +233,11 @@ func (s *GonnxStrategy) runPolicy(gs *diplomacy.GameState, power diplomacy.Power
 		"power_indices": powerTensor,
 	}
 
-	s.mu.Lock()
-	outputs, err := s.policy.Run(inputs)
-	s.mu.Unlock()
+	models := s.current()
+	if models == nil {
+		return nil
+	}
+	outputs, err := models.policy.Run(inputs)
 	if err != nil {
 		log.Printf("bot/gonnx: policy run error: %v", err)
 		return nil
@@ -200,8 +268,9 @@ func (s *GonnxStrategy) runPolicy(gs *diplomacy.GameState, power diplomacy.Power
 // RunValueNetwork encodes state and runs the value model, returning
 // [sc_share, win_prob, draw_prob, survival_prob].
 func (s *GonnxStrategy) RunValueNetwork(gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap) ([4]float32, error) {
-	if s.value == nil {
-		return [4]float32{}, fmt.Errorf("value model not loaded")
+	models := s.current()
+	if models == nil || models.value == nil {
+		return [4]float32{}, errValueModelMissing
 	}
 
 	boardData := neural.EncodeBoard(gs, m, nil)
@@ -229,9 +298,7 @@ func (s *GonnxStrategy) RunValueNetwork(gs *diplomacy.GameState, power diplomacy
 		"power_indices": powerTensor,
 	}
 
-	s.mu.Lock()
-	outputs, err := s.value.Run(inputs)
-	s.mu.Unlock()
+	outputs, err := models.value.Run(inputs)
 	if err != nil {
 		return [4]float32{}, fmt.Errorf("value run error: %w", err)
 	}
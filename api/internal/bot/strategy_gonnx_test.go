@@ -13,7 +13,7 @@ func TestGonnxStrategyName(t *testing.T) {
 	defer func() { GonnxModelPath = orig }()
 	GonnxModelPath = "/nonexistent"
 
-	s := newGonnxOrFallback()
+	s := newGonnxOrFallback(DefaultModel)
 	// Should fall back to hard since models don't exist at /nonexistent.
 	if s.Name() != "hard" {
 		t.Errorf("expected fallback to hard, got %q", s.Name())
@@ -100,7 +100,7 @@ func TestGonnxValueModelLoads(t *testing.T) {
 	if err != nil {
 		t.Fatalf("newGonnxStrategy failed: %v", err)
 	}
-	if gs.value == nil {
+	if gs.current().value == nil {
 		t.Fatal("expected value model to be loaded")
 	}
 }
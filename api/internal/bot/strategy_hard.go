@@ -1,12 +1,17 @@
 package bot
 
 import (
+	"context"
 	"math"
 	"math/rand"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/rs/zerolog/log"
+
+	"github.com/freeeve/polite-betrayal/api/internal/bot/neural"
 	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
 )
 
@@ -31,13 +36,68 @@ var chokepoints = map[string]bool{
 //   - Regret matching over candidates as the core decision loop
 //   - Medium-level opponent modeling (TacticalStrategy) for predicting opponent moves
 //   - Cicero-style evaluation: territorial cohesion, chokepoints, solo threat, cooperation
+//   - Nation playbooks: per-power expansion targets, key provinces, and usual allies
 //   - Human regularization: penalize moves that attack multiple neighbors simultaneously
-type HardStrategy struct{}
+//   - Alliances: honor non-aggression pacts, weighted by trust, and break them
+//     once past PactYears when a betrayal should win BetrayalGain centers
+//   - Personality: loyalty stretches or shortens pacts, aggression and risk
+//     tolerance bias which candidates regret matching favors
+type HardStrategy struct {
+	PactYears    int     // years a pact is honored unconditionally; 0 means DefaultPactYears
+	BetrayalGain float64 // centers a betrayal must be expected to win; 0 means DefaultBetrayalGain
+	Personality  Personality
+}
 
 func (HardStrategy) Name() string { return "hard" }
 
-// ShouldVoteDraw accepts a draw only if the leader has at least 2 more SCs.
+func init() {
+	RegisterStrategy(StrategyRegistration{
+		Name:         "hard",
+		Description:  "Regret matching over strategic candidates with lookahead.",
+		Capabilities: StrategyCapabilities{Diplomacy: true, DrawVoting: true, TimeControl: true, Personality: true, Mercy: true},
+		Options: []StrategyOption{
+			{Name: "pact_years", Default: strconv.Itoa(DefaultPactYears), Description: "Years a non-aggression pact is honored before betrayal is considered."},
+			{Name: "betray_gain", Default: strconv.FormatFloat(DefaultBetrayalGain, 'g', -1, 64), Description: "Centers a betrayal must be expected to win before a pact is broken."},
+		},
+		New: func(opts StrategyOptions) Strategy {
+			s := &HardStrategy{}
+			if n, err := strconv.Atoi(opts.Get("pact_years", "")); err == nil && n >= 0 {
+				s.PactYears = n
+			}
+			if g, err := strconv.ParseFloat(opts.Get("betray_gain", ""), 64); err == nil && g > 0 {
+				s.BetrayalGain = g
+			}
+			return s
+		},
+	})
+}
+
+// pactYears is PactYears, or the default, moved by up to two years by
+// loyalty but never below one.
+func (s HardStrategy) pactYears() int {
+	years := DefaultPactYears
+	if s.PactYears > 0 {
+		years = s.PactYears
+	}
+	return max(1, years+int(math.Round(2*s.Personality.Loyalty)))
+}
+
+// betrayalGain is BetrayalGain, or the default, scaled from half to one and
+// a half times by loyalty.
+func (s HardStrategy) betrayalGain() float64 {
+	gain := DefaultBetrayalGain
+	if s.BetrayalGain > 0 {
+		gain = s.BetrayalGain
+	}
+	return gain * (1 + 0.5*s.Personality.Loyalty)
+}
+
+// ShouldVoteDraw accepts a draw only if the leader has at least 2 more SCs,
+// or when the year limit leaves no power able to solo.
 func (HardStrategy) ShouldVoteDraw(gs *diplomacy.GameState, power diplomacy.Power) bool {
+	if !soloStillPossible(gs) {
+		return true
+	}
 	ownSCs := gs.SupplyCenterCount(power)
 	maxSCs := 0
 	for _, p := range diplomacy.AllPowers() {
@@ -59,52 +119,90 @@ func (HardStrategy) GenerateBuildOrders(gs *diplomacy.GameState, power diplomacy
 	return TacticalStrategy{}.GenerateBuildOrders(gs, power, m)
 }
 
-func (HardStrategy) GenerateDiplomaticMessages(
+func (s HardStrategy) GenerateDiplomaticMessages(
 	gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap,
 	received []DiplomaticIntent,
 ) []DiplomaticIntent {
-	return TacticalStrategy{}.GenerateDiplomaticMessages(gs, power, m, received)
+	return TacticalStrategy{Personality: s.Personality}.GenerateDiplomaticMessages(gs, power, m, received)
+}
+
+// GenerateOrders implements StrategyV2. Movement search stops at the time
+// budget, when ctx is done, or shortly before the phase deadline, whichever
+// comes first, and returns the best candidate found by then. Without a
+// SearchBudget it also stops after hardRMIterations; with one it keeps
+// iterating until the budget is spent.
+func (s HardStrategy) GenerateOrders(ctx context.Context, gc *GameContext) ([]OrderInput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	switch gc.State.Phase {
+	case diplomacy.PhaseRetreat:
+		return s.GenerateRetreatOrders(gc.State, gc.Power, gc.Map), nil
+	case diplomacy.PhaseBuild:
+		return s.GenerateBuildOrders(gc.State, gc.Power, gc.Map), nil
+	default:
+		iters := hardRMIterations
+		if gc.Budget.Anytime() {
+			iters = math.MaxInt
+		}
+		return s.movementOrders(ctx, gc.State, gc.Power, gc.Map, gc.Alliances, searchDeadline(ctx, gc, hardTimeBudget), iters), nil
+	}
 }
 
 // GenerateMovementOrders is the main entry point. Generates diverse candidates
 // using independent strategic postures, then uses regret matching to select
 // the best candidate against medium-level opponent predictions.
 func (s HardStrategy) GenerateMovementOrders(gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap) []OrderInput {
+	return s.movementOrders(context.Background(), gs, power, m, nil, time.Now().Add(hardTimeBudget), hardRMIterations)
+}
+
+// movementOrders runs the movement search for at most maxIters regret
+// matching iterations, stopping early at deadline or when ctx is done.
+// alliances, if not nil, holds the pacts candidates are weighed against.
+func (s HardStrategy) movementOrders(ctx context.Context, gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap, alliances *AllianceState, deadline time.Time, maxIters int) []OrderInput {
 	units := gs.UnitsOf(power)
 	if len(units) == 0 {
 		return nil
 	}
 
-	if gs.Year == 1901 {
+	if bookYear(gs.Year) {
 		if opening := LookupOpening(gs, power, m); opening != nil {
 			return opening
 		}
 	}
 
-	deadline := time.Now().Add(hardTimeBudget)
+	// Man defensive blocks when another power is closing on a solo
+	if orders := SoloDefenseOrders(gs, power, m); orders != nil {
+		return orders
+	}
 
 	candidates := s.generateCandidates(gs, power, units, m)
 	if len(candidates) == 0 {
-		return TacticalStrategy{}.GenerateMovementOrders(gs, power, m)
+		return TacticalStrategy{Personality: s.Personality}.GenerateMovementOrders(gs, power, m)
 	}
 
 	// Generate medium-level opponent prediction samples
-	opSamples := s.sampleOpponentPredictions(gs, power, m, deadline)
+	opSamples := s.sampleOpponentPredictions(ctx, gs, power, m, deadline)
 
 	// Regret matching selects the equilibrium candidate
-	bestIdx := s.regretMatchSelect(gs, power, m, candidates, opSamples, deadline)
+	bestIdx := s.regretMatchSelect(ctx, gs, power, m, alliances, candidates, opSamples, deadline, maxIters)
 	return candidates[bestIdx]
 }
 
-// generateCandidates builds structurally diverse order sets.
+// generateCandidates builds structurally diverse order sets. If the posture
+// and stochastic generators collapse onto too few distinct sets, it forces
+// wider perturbations of the first candidate so regret matching has real
+// alternatives to weigh.
 func (s HardStrategy) generateCandidates(gs *diplomacy.GameState, power diplomacy.Power, units []diplomacy.Unit, m *diplomacy.DiplomacyMap) [][]OrderInput {
 	var candidates [][]OrderInput
 	seen := make(map[string]bool)
+	requested := 0
 
 	add := func(cand []OrderInput) {
 		if len(cand) == 0 {
 			return
 		}
+		requested++
 		key := candidateKey(cand)
 		if !seen[key] {
 			seen[key] = true
@@ -122,14 +220,14 @@ func (s HardStrategy) generateCandidates(gs *diplomacy.GameState, power diplomac
 	add(s.expansionistCandidate(gs, power, units, m))
 
 	ownSCs := gs.SupplyCenterCount(power)
-	if ownSCs >= 14 {
+	if ownSCs >= scThreshold(gs, 14) {
 		for range max(1, (hardNumCandidates-len(candidates))/2) {
 			add(s.closingCandidate(gs, power, units, m))
 		}
 	}
 	if len(candidates) > 0 {
 		for range min(4, hardNumCandidates-len(candidates)) {
-			add(s.perturbedCandidate(gs, power, units, m, candidates[0]))
+			add(s.perturbedCandidate(gs, power, units, m, candidates[0], 1+botIntn(min(2, len(candidates[0])))))
 		}
 	}
 	for range hardNumCandidates * 3 {
@@ -139,6 +237,25 @@ func (s HardStrategy) generateCandidates(gs *diplomacy.GameState, power diplomac
 		}
 	}
 
+	div := neural.Diversity{Requested: requested, Unique: len(candidates)}
+	if target := neural.MinDiverseCandidates(hardNumCandidates); len(candidates) > 0 && len(candidates) < target {
+		base := candidates[0]
+		for range hardNumCandidates * 3 {
+			add(s.perturbedCandidate(gs, power, units, m, base, max(2, len(base)/2)))
+			if len(candidates) >= target {
+				break
+			}
+		}
+		ev := log.Debug()
+		if div.Degenerate() {
+			ev = log.Warn()
+		}
+		ev.Str("power", string(power)).Int("year", gs.Year).
+			Int("requested", div.Requested).Int("unique", div.Unique).
+			Int("uniqueAfterPerturbation", len(candidates)).
+			Msg("Hard bot: low candidate diversity; forced perturbations")
+	}
+
 	return candidates
 }
 
@@ -151,6 +268,7 @@ func hardScoreMoves(gs *diplomacy.GameState, power diplomacy.Power, units []dipl
 	}
 
 	ownSCs := gs.SupplyCenterCount(power)
+	pb := PlaybookFor(power)
 
 	var candidates []moveCandidate
 	for _, u := range units {
@@ -178,8 +296,11 @@ func hardScoreMoves(gs *diplomacy.GameState, power diplomacy.Power, units []dipl
 				}
 			}
 
-			// Fall: don't leave unowned SC
-			if gs.Season == diplomacy.Fall {
+			// Teaching games: spare the human players' home centers
+			score -= mercyCost(gs, power, target, m)
+
+			// Last season of the year (Fall): don't leave unowned SC
+			if gs.IsLastSeason() {
 				srcProv := m.Provinces[u.Province]
 				if srcProv != nil && srcProv.IsSupplyCenter && gs.SupplyCenters[u.Province] != power {
 					score -= 12
@@ -200,8 +321,8 @@ func hardScoreMoves(gs *diplomacy.GameState, power diplomacy.Power, units []dipl
 				score -= 0.5 * float64(dist)
 			}
 
-			// Spring positioning: adjacency to unowned SCs
-			if gs.Season == diplomacy.Spring {
+			// Earlier seasons (Spring): position next to unowned SCs
+			if !gs.IsLastSeason() {
 				adjSCCount := 0
 				for _, a := range m.Adjacencies[target] {
 					ap := m.Provinces[a.To]
@@ -217,6 +338,9 @@ func hardScoreMoves(gs *diplomacy.GameState, power diplomacy.Power, units []dipl
 				score += 3.0
 			}
 
+			// Nation playbook: expansion targets, key provinces, usual allies
+			score += playbookMoveScore(pb, gs, power, u, target, m)
+
 			// Strategic bias adjustments
 			switch bias {
 			case "aggressive":
@@ -224,7 +348,7 @@ func hardScoreMoves(gs *diplomacy.GameState, power diplomacy.Power, units []dipl
 					score += 5.0
 				}
 				// Late game: push harder
-				if ownSCs >= 10 {
+				if ownSCs >= scThreshold(gs, 10) {
 					score += 3.0
 				}
 			case "defensive":
@@ -471,15 +595,14 @@ func (s HardStrategy) stochasticCandidate(gs *diplomacy.GameState, power diploma
 }
 
 // perturbedCandidate creates a DORA-style local variant of an existing candidate
-// by randomly swapping 1-2 unit orders with alternative legal moves.
-func (s HardStrategy) perturbedCandidate(gs *diplomacy.GameState, power diplomacy.Power, units []diplomacy.Unit, m *diplomacy.DiplomacyMap, base []OrderInput) []OrderInput {
+// by randomly swapping up to swapCount unit orders with alternative legal moves.
+func (s HardStrategy) perturbedCandidate(gs *diplomacy.GameState, power diplomacy.Power, units []diplomacy.Unit, m *diplomacy.DiplomacyMap, base []OrderInput, swapCount int) []OrderInput {
 	if len(base) == 0 {
 		return nil
 	}
 	result := make([]OrderInput, len(base))
 	copy(result, base)
 
-	swapCount := 1 + botIntn(min(2, len(result)))
 	for _, idx := range botPerm(len(result)) {
 		if swapCount <= 0 {
 			break
@@ -641,9 +764,9 @@ func focusedAttack(gs *diplomacy.GameState, power diplomacy.Power, units []diplo
 }
 
 // sampleOpponentPredictions generates multiple stochastic medium-level
-// predictions for all opponents. Stops early if the deadline is exceeded
-// after at least 1 sample.
-func (s HardStrategy) sampleOpponentPredictions(gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap, deadline time.Time) [][]diplomacy.Order {
+// predictions for all opponents. Stops early if the deadline is exceeded or
+// ctx is done after at least 1 sample.
+func (s HardStrategy) sampleOpponentPredictions(ctx context.Context, gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap, deadline time.Time) [][]diplomacy.Order {
 	medium := TacticalStrategy{}
 	samples := make([][]diplomacy.Order, 0, hardOpSamples)
 	for i := range hardOpSamples {
@@ -656,24 +779,28 @@ func (s HardStrategy) sampleOpponentPredictions(gs *diplomacy.GameState, power d
 			opOrders = append(opOrders, OrderInputsToOrders(inputs, p)...)
 		}
 		samples = append(samples, opOrders)
-		if i > 0 && time.Now().After(deadline) {
+		if i > 0 && (time.Now().After(deadline) || ctx.Err() != nil) {
 			break
 		}
 	}
 	return samples
 }
 
-// regretMatchSelect runs RM+ over candidate order sets. Each iteration samples
-// a candidate and opponent prediction, evaluates with lookahead, and updates
-// regrets. Returns the index of the best candidate after convergence or when
-// the time budget is exceeded (after at least 1 full iteration).
+// regretMatchSelect runs up to maxIters RM+ iterations over candidate order
+// sets. Each iteration samples a candidate and opponent prediction, evaluates
+// with lookahead, and updates regrets. Returns the index of the best candidate
+// after the iterations or when the time budget is exceeded or ctx is done
+// (after at least 1 full iteration).
 func (s HardStrategy) regretMatchSelect(
+	ctx context.Context,
 	gs *diplomacy.GameState,
 	power diplomacy.Power,
 	m *diplomacy.DiplomacyMap,
+	alliances *AllianceState,
 	candidates [][]OrderInput,
 	opSamples [][]diplomacy.Order,
 	deadline time.Time,
+	maxIters int,
 ) int {
 	k := len(candidates)
 	if k == 1 {
@@ -691,10 +818,12 @@ func (s HardStrategy) regretMatchSelect(
 		candOrders[i] = OrderInputsToOrders(cand, power)
 	}
 
-	// Pre-compute cooperation penalties (static per candidate)
+	// Pre-compute cooperation and mercy penalties (static per candidate),
+	// less what the personality likes about the candidate
 	coopPenalties := make([]float64, k)
 	for i, cand := range candidates {
-		coopPenalties[i] = cooperationPenalty(cand, gs, power)
+		coopPenalties[i] = s.cooperationPenalty(cand, gs, power, m, alliances) + mercyOrdersCost(cand, gs, power, m) -
+			s.Personality.candidateBias(cand, gs, power, m)
 	}
 
 	resolver := diplomacy.NewResolver(34)
@@ -727,8 +856,10 @@ func (s HardStrategy) regretMatchSelect(
 		cumRegret[i] = math.Max(0, score)
 	}
 
-	for iter := range hardRMIterations {
-		if iter > 0 && time.Now().After(deadline) {
+	completed := 0
+iterations:
+	for iter := range maxIters {
+		if iter > 0 && (time.Now().After(deadline) || ctx.Err() != nil) {
 			break
 		}
 
@@ -776,6 +907,10 @@ func (s HardStrategy) regretMatchSelect(
 			if j == sampled {
 				continue
 			}
+			// Lookahead is expensive; a cancelled search abandons the sweep.
+			if ctx.Err() != nil {
+				break iterations
+			}
 			orderBuf = orderBuf[:len(candOrders[j])]
 			copy(orderBuf, candOrders[j])
 			orderBuf = append(orderBuf, opOrders...)
@@ -795,6 +930,12 @@ func (s HardStrategy) regretMatchSelect(
 		for j := range k {
 			totalWeight[j] += strategy[j]
 		}
+		completed++
+	}
+
+	// Cancelled before a full iteration: fall back to the warm-start scores.
+	if completed == 0 {
+		totalWeight = cumRegret
 	}
 
 	// Select by best average weight (average strategy, not final iteration)
@@ -822,38 +963,58 @@ func weightedSample(probs []float64, rng *rand.Rand) int {
 	return len(probs) - 1
 }
 
+// pactPenalty is the penalty for attacking a pact partner, scaled by trust
+// in it from half to one and a half times, and again by loyalty.
+const pactPenalty = 6.0
+
 // cooperationPenalty implements simplified piKL human regularization:
-// penalizes candidates that attack multiple distinct enemy powers simultaneously.
-func cooperationPenalty(candidate []OrderInput, gs *diplomacy.GameState, power diplomacy.Power) float64 {
+// penalizes candidates that attack multiple distinct enemy powers
+// simultaneously. With alliances, attacking a pact partner is penalized by
+// trust in it, unless the pact is at least PactYears old and the attack is
+// expected to win BetrayalGain of the partner's centers: a betrayal worth
+// timing.
+func (s HardStrategy) cooperationPenalty(candidate []OrderInput, gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap, alliances *AllianceState) float64 {
 	// Use a fixed-size array indexed by power to avoid map allocation.
 	var attacked [8]bool
+	var attackedPower [8]diplomacy.Power
 	n := 0
 	for _, o := range candidate {
 		if o.OrderType != "move" {
 			continue
 		}
-		// SC ownership attack
-		owner := gs.SupplyCenters[o.Target]
-		if owner != "" && owner != power && owner != diplomacy.Neutral {
-			idx := powerIndex(owner)
-			if idx < 7 && !attacked[idx] {
-				attacked[idx] = true
-				n++
-			}
-		}
-		// Unit dislodge attempt
-		if u := gs.UnitAt(o.Target); u != nil && u.Power != power {
-			idx := powerIndex(u.Power)
+		// SC ownership attacks and unit dislodge attempts
+		for _, p := range attackedPowers(o.Target, gs, power) {
+			idx := powerIndex(p)
 			if idx < 7 && !attacked[idx] {
 				attacked[idx] = true
+				attackedPower[idx] = p
 				n++
 			}
 		}
 	}
-	if n <= 1 {
-		return 0
+	penalty := 0.0
+	if n > 1 {
+		// Aggressive bots mind fighting on several fronts less.
+		penalty = 2.0 * float64(n-1) * (1 - 0.5*s.Personality.Aggression)
 	}
-	return 2.0 * float64(n-1)
+	if alliances == nil {
+		return penalty
+	}
+	for idx, hit := range attacked[:7] {
+		if !hit {
+			continue
+		}
+		partner := attackedPower[idx]
+		pact := alliances.ActivePact(partner)
+		if pact == nil {
+			continue
+		}
+		if gs.Year-pact.Year >= s.pactYears() && betrayalGain(candidate, gs, partner, m) >= s.betrayalGain() {
+			continue
+		}
+		penalty += pactPenalty * (0.5 + alliances.TrustIn(partner)) * (1 + 0.5*s.Personality.Loyalty)
+	}
+	return penalty
 }
 
 // powerIndex maps a Power string to a small integer for use in fixed-size arrays.
@@ -957,16 +1118,20 @@ func simulateHardPhase(gs *diplomacy.GameState, power diplomacy.Power, m *diplom
 func hardEvaluatePosition(gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap) float64 {
 	score := 0.0
 	ownSCs := gs.SupplyCenterCount(power)
+	pressure := yearLimitPressure(gs)
 
-	// SC count (dominant factor)
-	score += 15.0 * float64(ownSCs)
+	// SC count (dominant factor); weighted up as the year limit nears since
+	// the final SC count is what a draw is scored on.
+	score += (15.0 + 5.0*pressure) * float64(ownSCs)
 
-	// Victory proximity: increasing reward approaching 18
-	if ownSCs >= 10 {
-		score += 3.0 * float64(ownSCs-9)
+	// Victory proximity: increasing reward approaching a solo, fading out
+	// once the year limit makes a solo unreachable.
+	soloWeight := 1 - pressure
+	if near := scThreshold(gs, 10); ownSCs >= near {
+		score += 3.0 * float64(ownSCs-near+1) * soloWeight
 	}
-	if ownSCs >= 15 {
-		score += 10.0 * float64(ownSCs-14)
+	if near := scThreshold(gs, 15); ownSCs >= near {
+		score += 10.0 * float64(ownSCs-near+1) * soloWeight
 	}
 
 	// SC lead bonus
@@ -986,7 +1151,7 @@ func hardEvaluatePosition(gs *diplomacy.GameState, power diplomacy.Power, m *dip
 	// Unit scoring: pending captures, proximity to targets
 	unitCount := 0
 	pendingBonus := 10.0
-	if gs.Season == diplomacy.Fall {
+	if gs.IsLastSeason() {
 		pendingBonus = 15.0
 	}
 	for i := range gs.Units {
@@ -1017,8 +1182,8 @@ func hardEvaluatePosition(gs *diplomacy.GameState, power diplomacy.Power, m *dip
 		threat := ProvinceThreat(prov, power, gs, m)
 		defense := ProvinceDefense(prov, power, gs, m)
 		if threat > defense {
-			penalty := 3.0 * float64(threat-defense)
-			if ownSCs >= 12 {
+			penalty := 3.0 * float64(threat-defense) * (1 + pressure)
+			if ownSCs >= scThreshold(gs, 12) {
 				penalty *= 0.5
 			}
 			score -= penalty
@@ -1039,6 +1204,8 @@ func hardEvaluatePosition(gs *diplomacy.GameState, power diplomacy.Power, m *dip
 		score += 0.5 * float64(min(neighbors, 3))
 	}
 
+	score += playbookPositionScore(gs, power)
+
 	// Chokepoint control and solo threat detection
 	for _, u := range gs.Units {
 		if u.Power == power && chokepoints[u.Province] {
@@ -1050,16 +1217,16 @@ func hardEvaluatePosition(gs *diplomacy.GameState, power diplomacy.Power, m *dip
 			continue
 		}
 		sc := gs.SupplyCenterCount(p)
-		if sc >= 16 {
+		if sc >= scThreshold(gs, 16) {
 			score -= 20.0
-		} else if sc >= 14 {
+		} else if sc >= scThreshold(gs, 14) {
 			score -= 10.0
-		} else if sc >= 12 {
+		} else if sc >= scThreshold(gs, 12) {
 			score -= 4.0
 		}
 	}
 
-	return score
+	return score - mercyPositionCost(gs, power, m)
 }
 
 // candidateKey creates a string key for deduplication.
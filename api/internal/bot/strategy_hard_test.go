@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/freeeve/polite-betrayal/api/internal/bot/neural"
 	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
 )
 
@@ -228,6 +229,47 @@ func TestHardStrategy_CandidateGeneration(t *testing.T) {
 	}
 }
 
+func TestHardStrategy_CandidateDiversityFloor(t *testing.T) {
+	gs := diplomacy.NewInitialState()
+	m := diplomacy.StandardMap()
+	s := HardStrategy{}
+
+	want := neural.MinDiverseCandidates(hardNumCandidates)
+	for _, power := range diplomacy.AllPowers() {
+		candidates := s.generateCandidates(gs, power, gs.UnitsOf(power), m)
+		if len(candidates) < want {
+			t.Errorf("%s: got %d distinct candidates, want at least %d", power, len(candidates), want)
+		}
+		seen := make(map[string]bool)
+		for _, c := range candidates {
+			key := candidateKey(c)
+			if seen[key] {
+				t.Errorf("%s: duplicate candidate %s", power, key)
+			}
+			seen[key] = true
+		}
+	}
+}
+
+func TestHardStrategy_PerturbedCandidateSwapCount(t *testing.T) {
+	gs := diplomacy.NewInitialState()
+	m := diplomacy.StandardMap()
+	s := HardStrategy{}
+	units := gs.UnitsOf(diplomacy.Russia)
+
+	base := s.defensiveCandidate(gs, diplomacy.Russia, units, m)
+	got := s.perturbedCandidate(gs, diplomacy.Russia, units, m, base, len(base))
+	changed := 0
+	for i := range base {
+		if got[i] != base[i] {
+			changed++
+		}
+	}
+	if changed == 0 || changed > len(base) {
+		t.Errorf("expected 1..%d changed orders, got %d", len(base), changed)
+	}
+}
+
 func TestHardStrategy_EvalImprovements(t *testing.T) {
 	gs := diplomacy.NewInitialState()
 	m := diplomacy.StandardMap()
@@ -1,6 +1,7 @@
 package bot
 
 import (
+	"slices"
 	"sort"
 	"time"
 
@@ -9,14 +10,30 @@ import (
 
 // TacticalStrategy generates orders for the "medium" difficulty bot.
 // Uses the opening book for known positions, then generates multiple
-// candidate order sets and picks the best via 1-ply lookahead.
-type TacticalStrategy struct{}
+// candidate order sets and picks the best via 1-ply lookahead. Its
+// Personality shapes the heuristic candidates, how they are weighed and how
+// much unsolicited press it sends.
+type TacticalStrategy struct {
+	Personality Personality
+}
 
 func (TacticalStrategy) Name() string { return "medium" }
 
+func init() {
+	RegisterStrategy(StrategyRegistration{
+		Name:         "medium",
+		Description:  "Tactical search with support coordination and press.",
+		Capabilities: StrategyCapabilities{Diplomacy: true, DrawVoting: true, Personality: true, Mercy: true},
+		New:          func(StrategyOptions) Strategy { return &TacticalStrategy{} },
+	})
+}
+
 // ShouldVoteDraw rejects draws when in the lead, only accepting when
-// significantly behind the leader.
+// significantly behind the leader or when the year limit rules out a solo.
 func (TacticalStrategy) ShouldVoteDraw(gs *diplomacy.GameState, power diplomacy.Power) bool {
+	if !soloStillPossible(gs) {
+		return true
+	}
 	ownSCs := gs.SupplyCenterCount(power)
 	maxSCs := 0
 	for _, p := range diplomacy.AllPowers() {
@@ -39,13 +56,18 @@ func (s TacticalStrategy) GenerateMovementOrders(gs *diplomacy.GameState, power
 		return nil
 	}
 
-	// Use opening book for 1901
-	if gs.Year == 1901 {
+	// Use the opening book while it lasts
+	if bookYear(gs.Year) {
 		if opening := LookupOpening(gs, power, m); opening != nil {
 			return opening
 		}
 	}
 
+	// Man defensive blocks when another power is closing on a solo
+	if orders := SoloDefenseOrders(gs, power, m); orders != nil {
+		return orders
+	}
+
 	// Generate opponent orders once for all evaluations.
 	var opponentOrders []diplomacy.Order
 	for _, p := range diplomacy.AllPowers() {
@@ -66,7 +88,7 @@ func (s TacticalStrategy) GenerateMovementOrders(gs *diplomacy.GameState, power
 		candidates = append(candidates, searchCandidate)
 	}
 	for range numSamples {
-		candidates = append(candidates, HeuristicStrategy{}.GenerateMovementOrders(gs, power, m))
+		candidates = append(candidates, HeuristicStrategy{Personality: s.Personality}.GenerateMovementOrders(gs, power, m))
 	}
 
 	// Phase 3: Add candidates via buildOrdersFromScored with strategic scoring.
@@ -119,7 +141,8 @@ func (s TacticalStrategy) searchOrders(
 }
 
 // pickBestCandidate blends all three ply evaluations to pick the best
-// candidate order set. Score = 0.5 * eval(ply1) + 0.2 * eval(ply2) + 0.3 * eval(ply3).
+// candidate order set. Score = 0.5 * eval(ply1) + 0.2 * eval(ply2) + 0.3 * eval(ply3)
+// for a neutral personality, plus the personality's bias for the candidate.
 func (s TacticalStrategy) pickBestCandidate(
 	gs *diplomacy.GameState,
 	power diplomacy.Power,
@@ -136,6 +159,7 @@ func (s TacticalStrategy) pickBestCandidate(
 
 	bestScore := float64(-1e9)
 	bestIdx := 0
+	w1, w2, w3 := s.Personality.plyWeights()
 
 	rv := diplomacy.NewResolver(34)
 	ply1State := gs.Clone()
@@ -191,7 +215,8 @@ func (s TacticalStrategy) pickBestCandidate(
 		rv.Apply(ply3State, m)
 		ply3Score := EvaluatePosition(ply3State, power, m)
 
-		score := 0.5*ply1Score + 0.2*ply2Score + 0.3*ply3Score
+		score := w1*ply1Score + w2*ply2Score + w3*ply3Score + s.Personality.candidateBias(cand, gs, power, m) -
+			mercyOrdersCost(cand, gs, power, m)
 
 		if score > bestScore {
 			bestScore = score
@@ -212,9 +237,7 @@ func (TacticalStrategy) GenerateRetreatOrders(gs *diplomacy.GameState, power dip
 // chosen based on whether the front is land or naval. Disbands remove the
 // unit furthest from the action.
 func (TacticalStrategy) GenerateBuildOrders(gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap) []OrderInput {
-	scCount := gs.SupplyCenterCount(power)
-	unitCount := gs.UnitCount(power)
-	diff := scCount - unitCount
+	diff := gs.AdjustmentDelta(power)
 
 	if diff > 0 {
 		return frontAwareBuilds(gs, power, m, diff)
@@ -434,9 +457,13 @@ func frontAwareDisbands(gs *diplomacy.GameState, power diplomacy.Power, m *diplo
 	return orders
 }
 
-// GenerateDiplomaticMessages proposes non-aggression pacts to bordering powers
-// and responds to incoming diplomatic messages with simple accept/reject logic.
-func (TacticalStrategy) GenerateDiplomaticMessages(
+// GenerateDiplomaticMessages proposes non-aggression pacts to bordering powers,
+// asks for the no-cost supports other powers could give it, and responds to
+// incoming diplomatic messages with simple accept/reject logic. Replies to
+// channel messages go to the same channel. Quiet personalities skip some of
+// the unsolicited messages; talkative ones also propose alliances against
+// the leading power.
+func (s TacticalStrategy) GenerateDiplomaticMessages(
 	gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap,
 	received []DiplomaticIntent,
 ) []DiplomaticIntent {
@@ -446,19 +473,44 @@ func (TacticalStrategy) GenerateDiplomaticMessages(
 		switch req.Type {
 		case IntentRequestSupport, IntentProposeNonAggression, IntentProposeAlliance:
 			messages = append(messages, DiplomaticIntent{
-				Type: IntentAccept,
-				From: power,
-				To:   req.From,
+				Type:    IntentAccept,
+				From:    power,
+				To:      req.From,
+				Channel: req.Channel,
 			})
 		case IntentThreaten:
 			messages = append(messages, DiplomaticIntent{
-				Type: IntentReject,
-				From: power,
-				To:   req.From,
+				Type:    IntentReject,
+				From:    power,
+				To:      req.From,
+				Channel: req.Channel,
+			})
+		case IntentProposeOrders:
+			reply := IntentReject
+			if ProposalIsFriendly(req, power, gs) {
+				reply = IntentAccept
+			}
+			messages = append(messages, DiplomaticIntent{
+				Type:    reply,
+				From:    power,
+				To:      req.From,
+				Channel: req.Channel,
 			})
 		}
 	}
 
+	// Ask each power that can support us at no cost for one such support.
+	asked := make(map[diplomacy.Power]bool)
+	for _, opp := range SupportOpportunities(gs, m) {
+		if opp.Beneficiary != power || asked[opp.Supporter] {
+			continue
+		}
+		asked[opp.Supporter] = true
+		if s.Personality.keepUnsolicited() {
+			messages = append(messages, opp.Intent())
+		}
+	}
+
 	ourReach := make(map[string]bool)
 	for _, u := range gs.UnitsOf(power) {
 		isFleet := u.Type == diplomacy.Fleet
@@ -483,14 +535,40 @@ func (TacticalStrategy) GenerateDiplomaticMessages(
 				break
 			}
 		}
-		if bordering {
+		if bordering && s.Personality.keepUnsolicited() {
 			messages = append(messages, DiplomaticIntent{
 				Type: IntentProposeNonAggression,
 				From: power,
 				To:   p,
 			})
 		}
+		if bordering && s.Personality.volunteersAlliance() {
+			if leader := leadingPower(gs, power, p); leader != "" {
+				messages = append(messages, DiplomaticIntent{
+					Type:        IntentProposeAlliance,
+					From:        power,
+					To:          p,
+					TargetPower: leader,
+				})
+			}
+		}
 	}
 
 	return messages
 }
+
+// leadingPower returns the living power with the most supply centers other
+// than the given ones, or "" if there is none.
+func leadingPower(gs *diplomacy.GameState, except ...diplomacy.Power) diplomacy.Power {
+	var leader diplomacy.Power
+	best := 0
+	for _, p := range diplomacy.AllPowers() {
+		if slices.Contains(except, p) || !gs.PowerIsAlive(p) {
+			continue
+		}
+		if sc := gs.SupplyCenterCount(p); sc > best {
+			leader, best = p, sc
+		}
+	}
+	return leader
+}
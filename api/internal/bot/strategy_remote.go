@@ -0,0 +1,401 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// RemoteStrategyAddress is the default address of the remote strategy
+// service used by the "remote" strategy, as host:port for cleartext HTTP/2
+// or an https:// URL for TLS. Set this at startup (e.g. from an environment
+// variable) before creating strategies.
+var RemoteStrategyAddress string
+
+// remoteOrdersMethod is the gRPC path of the Orders call defined in
+// proto/strategy.proto.
+const remoteOrdersMethod = "/politebetrayal.strategy.v1.Strategy/Orders"
+
+// remoteTimeout is how long a remote strategy may take when neither the
+// caller nor the phase deadline sets a budget.
+const remoteTimeout = 10 * time.Second
+
+func init() {
+	RegisterStrategy(StrategyRegistration{
+		Name:        "remote",
+		Description: "Remote strategy service over gRPC; holds if unavailable.",
+		Capabilities: StrategyCapabilities{
+			Diplomacy:   true,
+			TimeControl: true,
+		},
+		Options: []StrategyOption{
+			{Name: "address", Description: "host:port or https:// URL of the strategy service; defaults to REMOTE_STRATEGY_ADDR."},
+		},
+		New: func(opts StrategyOptions) Strategy {
+			return NewRemoteStrategy(opts.Get("address", RemoteStrategyAddress))
+		},
+	})
+}
+
+// RemoteStrategy implements Strategy by calling a strategy service that
+// implements proto/strategy.proto, so agents written in other languages can
+// play without speaking DUI. The service is sent the position and the press
+// received, and answers with orders and press to send. Calls that fail fall
+// back to the same safe orders as ExternalStrategy, and repeated failures
+// trip the same circuit breaker, keyed by address.
+type RemoteStrategy struct {
+	address string
+	baseURL string
+	client  *http.Client
+	health  *engineHealth
+
+	mu       sync.Mutex
+	pressOut map[diplomacy.Power][]DiplomaticIntent // from each power's last call
+}
+
+// NewRemoteStrategy returns a strategy calling the service at address.
+// No connection is made until orders are first requested.
+func NewRemoteStrategy(address string) *RemoteStrategy {
+	var protocols http.Protocols
+	baseURL := address
+	if strings.HasPrefix(address, "https://") {
+		protocols.SetHTTP2(true)
+	} else {
+		protocols.SetUnencryptedHTTP2(true)
+		baseURL = "http://" + strings.TrimPrefix(address, "http://")
+	}
+	return &RemoteStrategy{
+		address: address,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client: &http.Client{Transport: &http.Transport{
+			Protocols:       &protocols,
+			TLSClientConfig: &tls.Config{MinVersion: tls.VersionTLS12},
+		}},
+		health:   engineHealthFor(address),
+		pressOut: make(map[diplomacy.Power][]DiplomaticIntent),
+	}
+}
+
+// Name returns the strategy name.
+func (r *RemoteStrategy) Name() string { return "remote" }
+
+// GenerateMovementOrders asks the service for movement-phase orders.
+func (r *RemoteStrategy) GenerateMovementOrders(gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap) []OrderInput {
+	orders, _ := r.GenerateOrders(context.Background(), &GameContext{State: gs, Power: power, Map: m})
+	return orders
+}
+
+// GenerateRetreatOrders asks the service for retreat-phase orders.
+func (r *RemoteStrategy) GenerateRetreatOrders(gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap) []OrderInput {
+	orders, _ := r.GenerateOrders(context.Background(), &GameContext{State: gs, Power: power, Map: m})
+	return orders
+}
+
+// GenerateBuildOrders asks the service for build-phase orders.
+func (r *RemoteStrategy) GenerateBuildOrders(gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap) []OrderInput {
+	orders, _ := r.GenerateOrders(context.Background(), &GameContext{State: gs, Power: power, Map: m})
+	return orders
+}
+
+// GenerateOrders implements StrategyV2. The call is given until the search
+// deadline for gc, which the service sees as deadline_unix_ms and as the
+// gRPC timeout. Failures fall back to holds, disbands or waived builds.
+func (r *RemoteStrategy) GenerateOrders(ctx context.Context, gc *GameContext) ([]OrderInput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	gs, power := gc.State, gc.Power
+
+	var (
+		resp *remoteOrdersResponse
+		err  error
+	)
+	if r.address == "" {
+		err = fmt.Errorf("no address set")
+	} else if !r.health.allow() {
+		err = fmt.Errorf("service disabled after repeated failures")
+	} else {
+		deadline := searchDeadline(ctx, gc, remoteTimeout)
+		callCtx, cancel := context.WithDeadline(ctx, deadline)
+		resp, err = r.call(callCtx, gc, deadline)
+		cancel()
+		if err != nil {
+			r.health.failure(err)
+		} else {
+			r.health.success()
+		}
+	}
+
+	r.mu.Lock()
+	r.pressOut[power] = nil
+	if resp != nil {
+		r.pressOut[power] = resp.pressOut
+	}
+	r.mu.Unlock()
+
+	if err != nil {
+		log.Printf("remote strategy %s: %s orders failed: %v; falling back", r.address, gs.Phase, err)
+		switch gs.Phase {
+		case diplomacy.PhaseRetreat:
+			return disbandAllDislodged(gs, power), nil
+		case diplomacy.PhaseBuild:
+			return nil, nil
+		default:
+			return holdAll(gs, power), nil
+		}
+	}
+	return resp.orders, nil
+}
+
+// GenerateDiplomaticMessages implements DiplomaticStrategy, returning the
+// press the service sent back with power's last orders.
+func (r *RemoteStrategy) GenerateDiplomaticMessages(_ *diplomacy.GameState, power diplomacy.Power, _ *diplomacy.DiplomacyMap, _ []DiplomaticIntent) []DiplomaticIntent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := r.pressOut[power]
+	delete(r.pressOut, power)
+	return out
+}
+
+// call makes one Orders call.
+func (r *RemoteStrategy) call(ctx context.Context, gc *GameContext, deadline time.Time) (*remoteOrdersResponse, error) {
+	reqMsg, err := encodeOrdersRequest(gc, deadline)
+	if err != nil {
+		return nil, err
+	}
+	frame := make([]byte, 5, 5+len(reqMsg))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(reqMsg)))
+	frame = append(frame, reqMsg...)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.baseURL+remoteOrdersMethod, bytes.NewReader(frame))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/grpc+proto")
+	req.Header.Set("TE", "trailers")
+	req.Header.Set("Grpc-Timeout", strconv.FormatInt(max(time.Until(deadline).Milliseconds(), 1), 10)+"m")
+
+	httpResp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", httpResp.StatusCode)
+	}
+
+	// Trailers arrive after the body; a failed call may put them in the
+	// headers instead.
+	status := httpResp.Trailer.Get("Grpc-Status")
+	message := httpResp.Trailer.Get("Grpc-Message")
+	if status == "" {
+		status, message = httpResp.Header.Get("Grpc-Status"), httpResp.Header.Get("Grpc-Message")
+	}
+	if status != "0" {
+		if status == "" {
+			return nil, fmt.Errorf("response has no grpc-status")
+		}
+		msg, _ := url.PathUnescape(message)
+		return nil, fmt.Errorf("grpc status %s: %s", status, msg)
+	}
+
+	if len(body) < 5 {
+		return nil, fmt.Errorf("short response")
+	}
+	if body[0] != 0 {
+		return nil, fmt.Errorf("compressed responses are not supported")
+	}
+	n := binary.BigEndian.Uint32(body[1:5])
+	if uint32(len(body)-5) < n {
+		return nil, fmt.Errorf("truncated response")
+	}
+	return decodeOrdersResponse(body[5:5+n], gc.Power)
+}
+
+// remoteOrdersResponse is a decoded OrdersResponse.
+type remoteOrdersResponse struct {
+	orders   []OrderInput
+	pressOut []DiplomaticIntent
+}
+
+// encodeOrdersRequest builds the OrdersRequest for gc.
+func encodeOrdersRequest(gc *GameContext, deadline time.Time) ([]byte, error) {
+	state, err := json.Marshal(gc.State)
+	if err != nil {
+		return nil, fmt.Errorf("encode state: %w", err)
+	}
+	var b []byte
+	b = appendProtoString(b, 1, gc.GameID)
+	b = appendProtoString(b, 2, gc.PhaseID)
+	b = appendProtoString(b, 3, string(gc.Power))
+	b = appendProtoString(b, 4, diplomacy.EncodeDFEN(gc.State))
+	b = appendProtoString(b, 5, string(state))
+	if !deadline.IsZero() {
+		b = protowire.AppendTag(b, 6, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(deadline.UnixMilli()))
+	}
+	for _, intent := range gc.Received {
+		b = protowire.AppendTag(b, 7, protowire.BytesType)
+		b = protowire.AppendBytes(b, encodePress(intent))
+	}
+	return b, nil
+}
+
+// encodePress builds a Press message for intent.
+func encodePress(intent DiplomaticIntent) []byte {
+	var b []byte
+	b = appendProtoString(b, 1, string(intent.From))
+	b = appendProtoString(b, 2, string(intent.To))
+	b = appendProtoString(b, 3, intent.Type.String())
+	for _, p := range intent.Provinces {
+		b = protowire.AppendTag(b, 4, protowire.BytesType)
+		b = protowire.AppendString(b, p)
+	}
+	b = appendProtoString(b, 5, string(intent.TargetPower))
+	if len(intent.Orders) > 0 {
+		b = appendProtoString(b, 6, diplomacy.FormatDSON(intent.Orders))
+	}
+	b = appendProtoString(b, 7, intent.Channel)
+	return b
+}
+
+// decodeOrdersResponse parses an OrdersResponse for power. Press the API
+// can't represent is dropped.
+func decodeOrdersResponse(b []byte, power diplomacy.Power) (*remoteOrdersResponse, error) {
+	resp := &remoteOrdersResponse{}
+	err := consumeProtoFields(b, func(num protowire.Number, v []byte) error {
+		switch num {
+		case 1:
+			o, err := decodeOrder(v)
+			if err != nil {
+				return err
+			}
+			resp.orders = append(resp.orders, o)
+		case 2:
+			intent, err := decodePress(v, power)
+			if err != nil {
+				return err
+			}
+			if intent != nil {
+				resp.pressOut = append(resp.pressOut, *intent)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	return resp, nil
+}
+
+// decodeOrder parses an Order message.
+func decodeOrder(b []byte) (OrderInput, error) {
+	var o OrderInput
+	fields := map[protowire.Number]*string{
+		1: &o.UnitType, 2: &o.Location, 3: &o.Coast, 4: &o.OrderType, 5: &o.Target,
+		6: &o.TargetCoast, 7: &o.AuxLoc, 8: &o.AuxTarget, 9: &o.AuxUnitType,
+	}
+	err := consumeProtoFields(b, func(num protowire.Number, v []byte) error {
+		if f, ok := fields[num]; ok {
+			*f = string(v)
+		}
+		return nil
+	})
+	return o, err
+}
+
+// decodePress parses a Press message sent by power, returning nil for an
+// unknown type or unparseable proposed orders.
+func decodePress(b []byte, power diplomacy.Power) (*DiplomaticIntent, error) {
+	intent := &DiplomaticIntent{From: power}
+	var typ, orders string
+	err := consumeProtoFields(b, func(num protowire.Number, v []byte) error {
+		switch num {
+		case 2:
+			intent.To = diplomacy.Power(v)
+		case 3:
+			typ = string(v)
+		case 4:
+			intent.Provinces = append(intent.Provinces, string(v))
+		case 5:
+			intent.TargetPower = diplomacy.Power(v)
+		case 6:
+			orders = string(v)
+		case 7:
+			intent.Channel = string(v)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	t, ok := ParseIntentType(typ)
+	if !ok {
+		return nil, nil
+	}
+	intent.Type = t
+	if orders != "" {
+		if intent.Orders, err = diplomacy.ParseDSON(orders); err != nil {
+			return nil, nil
+		}
+	}
+	return intent, nil
+}
+
+// appendProtoString appends a string field, omitting it when empty as
+// proto3 does.
+func appendProtoString(b []byte, num protowire.Number, s string) []byte {
+	if s == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+// consumeProtoFields calls fn with each length-delimited field of a
+// protobuf message, skipping fields of other wire types.
+func consumeProtoFields(b []byte, fn func(num protowire.Number, v []byte) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		if typ != protowire.BytesType {
+			n = protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			b = b[n:]
+			continue
+		}
+		v, n := protowire.ConsumeBytes(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		if err := fn(num, v); err != nil {
+			return err
+		}
+		b = b[n:]
+	}
+	return nil
+}
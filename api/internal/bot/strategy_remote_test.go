@@ -0,0 +1,119 @@
+package bot
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// newRemoteStrategyServer starts a cleartext HTTP/2 server answering Orders
+// calls with handle, which gets the request message and returns the
+// response message and gRPC status.
+func newRemoteStrategyServer(t *testing.T, handle func(req []byte) ([]byte, string)) *RemoteStrategy {
+	t.Helper()
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != remoteOrdersMethod || r.ProtoMajor != 2 {
+			t.Errorf("unexpected call %s over %s", r.URL.Path, r.Proto)
+		}
+		body, _ := io.ReadAll(r.Body)
+		resp, status := handle(body[5:])
+		w.Header().Set("Content-Type", "application/grpc+proto")
+		frame := make([]byte, 5, 5+len(resp))
+		binary.BigEndian.PutUint32(frame[1:], uint32(len(resp)))
+		w.Write(append(frame, resp...))
+		w.Header().Set(http.TrailerPrefix+"Grpc-Status", status)
+	}))
+	srv.Config.Protocols = new(http.Protocols)
+	srv.Config.Protocols.SetUnencryptedHTTP2(true)
+	srv.Start()
+	t.Cleanup(srv.Close)
+	return NewRemoteStrategy(strings.TrimPrefix(srv.URL, "http://"))
+}
+
+func TestRemoteStrategyOrders(t *testing.T) {
+	var power, dfen string
+	var pressIn []DiplomaticIntent
+	r := newRemoteStrategyServer(t, func(req []byte) ([]byte, string) {
+		consumeProtoFields(req, func(num protowire.Number, v []byte) error {
+			switch num {
+			case 3:
+				power = string(v)
+			case 4:
+				dfen = string(v)
+			case 7:
+				intent, _ := decodePress(v, "")
+				pressIn = append(pressIn, *intent)
+			}
+			return nil
+		})
+
+		order := appendProtoString(nil, 1, "army")
+		order = appendProtoString(order, 2, "par")
+		order = appendProtoString(order, 4, "move")
+		order = appendProtoString(order, 5, "bur")
+		press := encodePress(DiplomaticIntent{To: diplomacy.Germany, Type: IntentProposeAlliance, TargetPower: diplomacy.England})
+		var resp []byte
+		resp = protowire.AppendTag(resp, 1, protowire.BytesType)
+		resp = protowire.AppendBytes(resp, order)
+		resp = protowire.AppendTag(resp, 2, protowire.BytesType)
+		resp = protowire.AppendBytes(resp, press)
+		return resp, "0"
+	})
+
+	gc := &GameContext{
+		State:    diplomacy.NewInitialState(),
+		Power:    diplomacy.France,
+		Map:      diplomacy.StandardMap(),
+		Received: []DiplomaticIntent{{Type: IntentAccept, From: diplomacy.Germany, To: diplomacy.France}},
+	}
+	orders, err := r.GenerateOrders(context.Background(), gc)
+	if err != nil {
+		t.Fatalf("GenerateOrders: %v", err)
+	}
+	if power != "france" || dfen == "" {
+		t.Errorf("request had power %q and dfen %q", power, dfen)
+	}
+	if len(pressIn) != 1 || pressIn[0].Type != IntentAccept || pressIn[0].To != diplomacy.France {
+		t.Errorf("press in = %+v, want an accept sent to france", pressIn)
+	}
+	want := OrderInput{UnitType: "army", Location: "par", OrderType: "move", Target: "bur"}
+	if len(orders) != 1 || orders[0] != want {
+		t.Errorf("orders = %+v, want %+v", orders, want)
+	}
+
+	press := r.GenerateDiplomaticMessages(gc.State, diplomacy.France, gc.Map, nil)
+	if len(press) != 1 || press[0].Type != IntentProposeAlliance || press[0].From != diplomacy.France ||
+		press[0].To != diplomacy.Germany || press[0].TargetPower != diplomacy.England {
+		t.Errorf("press out = %+v, want an alliance against england proposed to germany", press)
+	}
+	if press := r.GenerateDiplomaticMessages(gc.State, diplomacy.France, gc.Map, nil); len(press) != 0 {
+		t.Errorf("press out should be sent once, got %+v again", press)
+	}
+}
+
+func TestRemoteStrategyFallsBackOnError(t *testing.T) {
+	r := newRemoteStrategyServer(t, func([]byte) ([]byte, string) {
+		return nil, "13"
+	})
+	gs := diplomacy.NewInitialState()
+	orders := r.GenerateMovementOrders(gs, diplomacy.Italy, diplomacy.StandardMap())
+	if len(orders) != 3 {
+		t.Fatalf("expected holds for Italy's 3 units, got %+v", orders)
+	}
+	for _, o := range orders {
+		if o.OrderType != "hold" {
+			t.Errorf("expected hold, got %+v", o)
+		}
+	}
+	if s := r.health.snapshot(); s.ConsecutiveFailures != 1 || !strings.Contains(s.LastError, "grpc status 13") {
+		t.Errorf("health = %+v, want one recorded failure", s)
+	}
+}
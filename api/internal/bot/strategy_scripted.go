@@ -0,0 +1,124 @@
+package bot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// OrderScript maps a DFEN phase ("1901sm") to each power's DSON orders for
+// that phase, e.g. {"1901sm": {"france": "A par - bur ; A mar - spa ; F bre - mao"}}.
+type OrderScript map[string]map[string]string
+
+// ScriptedStrategy plays orders read from an OrderScript so end-to-end tests
+// can drive deterministic games. Phases or powers missing from the script
+// fall back to HoldStrategy.
+type ScriptedStrategy struct {
+	orders map[string]map[diplomacy.Power][]diplomacy.DSONOrder
+}
+
+// NewScriptedStrategy parses and validates a script: phase keys must be
+// valid DFEN phases, powers must exist, and orders must be valid DSON of a
+// kind allowed in that phase.
+func NewScriptedStrategy(script OrderScript) (*ScriptedStrategy, error) {
+	s := &ScriptedStrategy{orders: make(map[string]map[diplomacy.Power][]diplomacy.DSONOrder, len(script))}
+	for key, byPower := range script {
+		_, _, phase, err := diplomacy.DecodeDFENPhase(key)
+		if err != nil {
+			return nil, fmt.Errorf("script phase %q: %w", key, err)
+		}
+		s.orders[key] = make(map[diplomacy.Power][]diplomacy.DSONOrder, len(byPower))
+		for power, dson := range byPower {
+			if !slices.Contains(diplomacy.AllPowers(), diplomacy.Power(power)) {
+				return nil, fmt.Errorf("script phase %s: unknown power %q", key, power)
+			}
+			orders, err := diplomacy.ParseDSON(dson)
+			if err != nil {
+				return nil, fmt.Errorf("script phase %s, %s: %w", key, power, err)
+			}
+			for _, o := range orders {
+				if !DSONAllowedIn(o.Type, phase) {
+					return nil, fmt.Errorf("script phase %s, %s: %q is not a %s order", key, power, diplomacy.FormatDSON([]diplomacy.DSONOrder{o}), phase)
+				}
+			}
+			s.orders[key][diplomacy.Power(power)] = orders
+		}
+	}
+	return s, nil
+}
+
+// LoadScriptedStrategy reads a JSON OrderScript from path.
+func LoadScriptedStrategy(path string) (*ScriptedStrategy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read script: %w", err)
+	}
+	var script OrderScript
+	if err := json.Unmarshal(data, &script); err != nil {
+		return nil, fmt.Errorf("parse script %s: %w", path, err)
+	}
+	return NewScriptedStrategy(script)
+}
+
+func (s *ScriptedStrategy) Name() string { return "scripted" }
+
+// scripted returns the power's orders for the current phase, if scripted.
+func (s *ScriptedStrategy) scripted(gs *diplomacy.GameState, power diplomacy.Power) ([]diplomacy.DSONOrder, bool) {
+	orders, ok := s.orders[diplomacy.EncodeDFENPhase(gs)][power]
+	return orders, ok
+}
+
+func (s *ScriptedStrategy) GenerateMovementOrders(gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap) []OrderInput {
+	dson, ok := s.scripted(gs, power)
+	if !ok {
+		return HoldStrategy{}.GenerateMovementOrders(gs, power, m)
+	}
+	return DSONInputs(dson, power, diplomacy.PhaseMovement)
+}
+
+func (s *ScriptedStrategy) GenerateRetreatOrders(gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap) []OrderInput {
+	dson, ok := s.scripted(gs, power)
+	if !ok {
+		return HoldStrategy{}.GenerateRetreatOrders(gs, power, m)
+	}
+	return DSONInputs(dson, power, diplomacy.PhaseRetreat)
+}
+
+func (s *ScriptedStrategy) GenerateBuildOrders(gs *diplomacy.GameState, power diplomacy.Power, m *diplomacy.DiplomacyMap) []OrderInput {
+	dson, ok := s.scripted(gs, power)
+	if !ok {
+		return HoldStrategy{}.GenerateBuildOrders(gs, power, m)
+	}
+	return DSONInputs(dson, power, diplomacy.PhaseBuild)
+}
+
+// DSONInputs converts a power's DSON orders into OrderInputs for a phase.
+func DSONInputs(dson []diplomacy.DSONOrder, power diplomacy.Power, phase diplomacy.PhaseType) []OrderInput {
+	orders := make([]OrderInput, 0, len(dson))
+	for _, d := range dson {
+		switch phase {
+		case diplomacy.PhaseRetreat:
+			orders = append(orders, retreatOrderToInput(diplomacy.DSONToRetreatOrder(d, power)))
+		case diplomacy.PhaseBuild:
+			orders = append(orders, buildOrderToInput(diplomacy.DSONToBuildOrder(d, power)))
+		default:
+			orders = append(orders, orderToInput(diplomacy.DSONToOrder(d, power)))
+		}
+	}
+	return orders
+}
+
+// DSONAllowedIn reports whether a DSON order kind can be played in phase.
+func DSONAllowedIn(t diplomacy.DSONOrderType, phase diplomacy.PhaseType) bool {
+	switch phase {
+	case diplomacy.PhaseRetreat:
+		return t == diplomacy.DSONRetreat || t == diplomacy.DSONDisband
+	case diplomacy.PhaseBuild:
+		return t == diplomacy.DSONBuild || t == diplomacy.DSONDisband || t == diplomacy.DSONWaive
+	default:
+		return t <= diplomacy.DSONConvoy
+	}
+}
@@ -0,0 +1,59 @@
+package bot
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+func TestScriptedStrategy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "script.json")
+	os.WriteFile(path, []byte(`{
+		"1901sm": {"france": "A par - bur ; A mar S A par - bur ; F bre - mao"},
+		"1901fb": {"france": "A par B ; W"}
+	}`), 0o644)
+	s, err := LoadScriptedStrategy(path)
+	if err != nil {
+		t.Fatalf("LoadScriptedStrategy: %v", err)
+	}
+	m := diplomacy.StandardMap()
+	gs := diplomacy.NewInitialState()
+
+	orders := s.GenerateMovementOrders(gs, diplomacy.France, m)
+	if len(orders) != 3 || orders[0].OrderType != "move" || orders[0].Target != "bur" ||
+		orders[1].OrderType != "support" || orders[1].AuxLoc != "par" || orders[1].AuxTarget != "bur" {
+		t.Errorf("unexpected scripted orders: %+v", orders)
+	}
+
+	// Unscripted powers and phases hold.
+	for _, o := range s.GenerateMovementOrders(gs, diplomacy.Germany, m) {
+		if o.OrderType != "hold" {
+			t.Errorf("expected unscripted power to hold, got %+v", o)
+		}
+	}
+	gs.Season = diplomacy.Fall
+	if orders := s.GenerateMovementOrders(gs, diplomacy.France, m); len(orders) != 3 || orders[0].OrderType != "hold" {
+		t.Errorf("expected unscripted phase to hold, got %+v", orders)
+	}
+
+	gs.Phase = diplomacy.PhaseBuild
+	builds := s.GenerateBuildOrders(gs, diplomacy.France, m)
+	if len(builds) != 2 || builds[0].OrderType != "build" || builds[0].Location != "par" || builds[1].OrderType != "waive" {
+		t.Errorf("unexpected scripted builds: %+v", builds)
+	}
+}
+
+func TestScriptedStrategy_Invalid(t *testing.T) {
+	for name, script := range map[string]OrderScript{
+		"bad phase":        {"1901xm": {"france": "A par H"}},
+		"unknown power":    {"1901sm": {"spain": "A mad H"}},
+		"bad dson":         {"1901sm": {"france": "A par ?"}},
+		"wrong phase kind": {"1901sm": {"france": "A par B"}},
+	} {
+		if _, err := NewScriptedStrategy(script); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
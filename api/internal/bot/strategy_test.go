@@ -1,7 +1,10 @@
 package bot
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
 )
@@ -349,3 +352,123 @@ func parseTestUnitType(s string) diplomacy.UnitType {
 	}
 	return diplomacy.Army
 }
+
+func TestGenerateOrders_FallsBackToStrategyV1(t *testing.T) {
+	gs := diplomacy.NewInitialState()
+	m := diplomacy.StandardMap()
+	gc := &GameContext{State: gs, Power: diplomacy.France, Map: m}
+
+	orders, err := GenerateOrders(context.Background(), HoldStrategy{}, gc)
+	if err != nil {
+		t.Fatalf("GenerateOrders: %v", err)
+	}
+	if len(orders) != len(gs.UnitsOf(diplomacy.France)) {
+		t.Errorf("expected %d hold orders, got %d", len(gs.UnitsOf(diplomacy.France)), len(orders))
+	}
+
+	gs.Phase = diplomacy.PhaseBuild
+	orders, err = GenerateOrders(context.Background(), HoldStrategy{}, gc)
+	if err != nil || orders != nil {
+		t.Errorf("build phase: got %v, %v; want nil, nil", orders, err)
+	}
+}
+
+func TestGenerateOrders_CancelledContext(t *testing.T) {
+	gs := diplomacy.NewInitialState()
+	gc := &GameContext{State: gs, Power: diplomacy.France, Map: diplomacy.StandardMap()}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, s := range []Strategy{HoldStrategy{}, HardStrategy{}} {
+		if _, err := GenerateOrders(ctx, s, gc); !errors.Is(err, context.Canceled) {
+			t.Errorf("%s: expected context.Canceled, got %v", s.Name(), err)
+		}
+	}
+}
+
+func TestHardStrategy_GenerateOrdersStopsAtContextDeadline(t *testing.T) {
+	gs := diplomacy.NewInitialState()
+	gs.Year = 1902 // skip the opening book so the search runs
+	gc := &GameContext{State: gs, Power: diplomacy.Germany, Map: diplomacy.StandardMap()}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	orders, err := HardStrategy{}.GenerateOrders(ctx, gc)
+	if err != nil {
+		t.Fatalf("GenerateOrders: %v", err)
+	}
+	if len(orders) != len(gs.UnitsOf(diplomacy.Germany)) {
+		t.Errorf("expected %d orders, got %d", len(gs.UnitsOf(diplomacy.Germany)), len(orders))
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("search ran %v despite a 50ms context deadline", elapsed)
+	}
+}
+
+func TestSearchDeadline(t *testing.T) {
+	ctx := context.Background()
+	budget := time.Minute
+
+	if d := searchDeadline(ctx, nil, budget); time.Until(d) > budget || time.Until(d) < budget-time.Second {
+		t.Errorf("no limits: deadline %v from now, want about %v", time.Until(d), budget)
+	}
+
+	phaseEnd := time.Now().Add(10 * time.Second)
+	d := searchDeadline(ctx, &GameContext{Deadline: phaseEnd}, budget)
+	if !d.Equal(phaseEnd.Add(-phaseDeadlineMargin)) {
+		t.Errorf("phase deadline: got %v, want %v", d, phaseEnd.Add(-phaseDeadlineMargin))
+	}
+
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second))
+	defer cancel()
+	ctxEnd, _ := ctx.Deadline()
+	if d := searchDeadline(ctx, &GameContext{Deadline: phaseEnd}, budget); !d.Equal(ctxEnd) {
+		t.Errorf("context deadline: got %v, want %v", d, ctxEnd)
+	}
+}
+
+func TestSearchDeadline_Budget(t *testing.T) {
+	ctx := context.Background()
+	phaseEnd := time.Now().Add(time.Hour)
+
+	// A search budget replaces the default budget, whether longer or shorter.
+	for _, d := range []time.Duration{time.Second, 10 * time.Minute} {
+		gc := &GameContext{Deadline: phaseEnd, Budget: SearchBudget{Deadline: time.Now().Add(d)}}
+		if got, want := searchDeadline(ctx, gc, time.Minute), gc.Budget.Deadline.Add(-searchBudgetMargin); !got.Equal(want) {
+			t.Errorf("budget %v: got %v, want %v", d, got, want)
+		}
+	}
+
+	// The phase deadline still wins.
+	gc := &GameContext{Deadline: phaseEnd, Budget: SearchBudget{Deadline: phaseEnd.Add(time.Hour)}}
+	if got := searchDeadline(ctx, gc, time.Minute); !got.Equal(phaseEnd.Add(-phaseDeadlineMargin)) {
+		t.Errorf("phase deadline: got %v, want %v", got, phaseEnd.Add(-phaseDeadlineMargin))
+	}
+}
+
+func TestStrategies_DuelBoard(t *testing.T) {
+	sc, _ := diplomacy.LookupScenario("france-austria")
+	gs := sc.InitialState()
+	m := sc.Map()
+
+	if LookupOpening(gs, diplomacy.France, m) != nil {
+		t.Error("opening book should not be used on a duel board")
+	}
+	for _, name := range []string{"easy", "medium", "hard"} {
+		s := NewStrategyForMap(name, nil, m)
+		if s.Name() != name {
+			t.Errorf("%s: replaced by %s on a duel board", name, s.Name())
+		}
+		for _, power := range sc.Powers {
+			orders := s.GenerateMovementOrders(gs, power, m)
+			if len(orders) != gs.UnitCount(power) {
+				t.Errorf("%s/%s: expected %d orders, got %d", s.Name(), power, gs.UnitCount(power), len(orders))
+			}
+		}
+		if orders := s.GenerateMovementOrders(gs, diplomacy.England, m); len(orders) != 0 {
+			t.Errorf("%s: inactive England should have no orders, got %d", s.Name(), len(orders))
+		}
+	}
+}
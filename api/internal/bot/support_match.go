@@ -0,0 +1,196 @@
+package bot
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// SupportOpportunity is a concrete support one power could give another this
+// phase: Supporter's unit supports Beneficiary's move from Origin into Target.
+type SupportOpportunity struct {
+	Supporter   diplomacy.Power
+	Beneficiary diplomacy.Power
+	SupportUnit diplomacy.Unit    // supporter's unit giving the support
+	MoveUnit    diplomacy.Unit    // beneficiary's unit making the move
+	Target      string            // supply center the beneficiary moves into
+	ContestedBy []diplomacy.Power // third powers that can also reach Target
+}
+
+// Order returns the support order the supporter would submit.
+func (o SupportOpportunity) Order() diplomacy.Order {
+	return diplomacy.Order{
+		UnitType:    o.SupportUnit.Type,
+		Power:       o.Supporter,
+		Location:    o.SupportUnit.Province,
+		Coast:       o.SupportUnit.Coast,
+		Type:        diplomacy.OrderSupport,
+		AuxLoc:      o.MoveUnit.Province,
+		AuxTarget:   o.Target,
+		AuxUnitType: o.MoveUnit.Type,
+	}
+}
+
+// Intent returns the support request the beneficiary would send the supporter.
+func (o SupportOpportunity) Intent() DiplomaticIntent {
+	return DiplomaticIntent{
+		Type:      IntentRequestSupport,
+		From:      o.Beneficiary,
+		To:        o.Supporter,
+		Provinces: []string{o.MoveUnit.Province, o.Target},
+	}
+}
+
+// ContestedTargets predicts which supply centers will be fought over this
+// phase: those that units of two or more powers occupy or can move into.
+// Each contested center maps to the powers involved, sorted by name.
+func ContestedTargets(gs *diplomacy.GameState, m *diplomacy.DiplomacyMap) map[string][]diplomacy.Power {
+	reach := make(map[string][]diplomacy.Power)
+	add := func(prov string, p diplomacy.Power) {
+		if !slices.Contains(reach[prov], p) {
+			reach[prov] = append(reach[prov], p)
+		}
+	}
+	for _, u := range gs.Units {
+		if prov := m.Provinces[u.Province]; prov != nil && prov.IsSupplyCenter {
+			add(u.Province, u.Power)
+		}
+		for _, adj := range m.Adjacencies[u.Province] {
+			if prov := m.Provinces[adj.To]; prov != nil && prov.IsSupplyCenter && unitCanReach(u, adj.To, m) {
+				add(adj.To, u.Power)
+			}
+		}
+	}
+	contested := make(map[string][]diplomacy.Power)
+	for prov, powers := range reach {
+		if len(powers) >= 2 {
+			slices.Sort(powers)
+			contested[prov] = powers
+		}
+	}
+	return contested
+}
+
+// SupportOpportunities lists, for every pair of powers, the supports one could
+// give the other at no cost to itself: the target is a contested supply
+// center the supporter neither owns, occupies, nor has in its playbook, a
+// third power is also contesting it, and the supporting unit is not needed
+// to defend a threatened home of its own. Results are sorted so the same
+// position always yields the same list.
+func SupportOpportunities(gs *diplomacy.GameState, m *diplomacy.DiplomacyMap) []SupportOpportunity {
+	contested := ContestedTargets(gs, m)
+	var opps []SupportOpportunity
+	for _, mover := range gs.Units {
+		isFleet := mover.Type == diplomacy.Fleet
+		for _, target := range m.ProvincesAdjacentTo(mover.Province, mover.Coast, isFleet) {
+			powers, ok := contested[target]
+			if !ok || gs.SupplyCenters[target] == mover.Power {
+				continue
+			}
+			if occupant := gs.UnitAt(target); occupant != nil && occupant.Power == mover.Power {
+				continue
+			}
+			move := diplomacy.Order{
+				UnitType: mover.Type, Power: mover.Power, Location: mover.Province, Coast: mover.Coast,
+				Type: diplomacy.OrderMove, Target: target,
+			}
+			if isFleet && m.HasCoasts(target) {
+				coasts := m.FleetCoastsTo(mover.Province, mover.Coast, target)
+				if len(coasts) == 0 {
+					continue
+				}
+				move.TargetCoast = coasts[0]
+			}
+			if diplomacy.ValidateOrder(move, gs, m) != nil {
+				continue
+			}
+			for _, sup := range gs.Units {
+				if sup.Power == mover.Power || !supportCostsNothing(sup, mover.Power, target, powers, gs, m) {
+					continue
+				}
+				if !CanSupportMove(sup.Province, mover.Province, target, sup, gs, m) {
+					continue
+				}
+				var rivals []diplomacy.Power
+				for _, p := range powers {
+					if p != sup.Power && p != mover.Power {
+						rivals = append(rivals, p)
+					}
+				}
+				opps = append(opps, SupportOpportunity{
+					Supporter:   sup.Power,
+					Beneficiary: mover.Power,
+					SupportUnit: sup,
+					MoveUnit:    mover,
+					Target:      target,
+					ContestedBy: rivals,
+				})
+			}
+		}
+	}
+	sort.Slice(opps, func(i, j int) bool {
+		a, b := opps[i], opps[j]
+		if a.Supporter != b.Supporter {
+			return a.Supporter < b.Supporter
+		}
+		if a.Beneficiary != b.Beneficiary {
+			return a.Beneficiary < b.Beneficiary
+		}
+		if a.Target != b.Target {
+			return a.Target < b.Target
+		}
+		if a.MoveUnit.Province != b.MoveUnit.Province {
+			return a.MoveUnit.Province < b.MoveUnit.Province
+		}
+		return a.SupportUnit.Province < b.SupportUnit.Province
+	})
+	return opps
+}
+
+// supportCostsNothing reports whether sup's power can support beneficiary into
+// target without giving anything up.
+func supportCostsNothing(sup diplomacy.Unit, beneficiary diplomacy.Power, target string, contestants []diplomacy.Power, gs *diplomacy.GameState, m *diplomacy.DiplomacyMap) bool {
+	owner := gs.SupplyCenters[target]
+	if owner == sup.Power {
+		return false
+	}
+	if occupant := gs.UnitAt(target); occupant != nil && occupant.Power == sup.Power {
+		return false
+	}
+	if pb := PlaybookFor(sup.Power); pb != nil && pb.targetBonus(target) > 0 {
+		return false
+	}
+	thirdParty := false
+	for _, p := range contestants {
+		if p != sup.Power && p != beneficiary {
+			thirdParty = true
+			break
+		}
+	}
+	if !thirdParty {
+		return false
+	}
+	// A unit sitting on its own threatened supply center should stay put.
+	if gs.SupplyCenters[sup.Province] == sup.Power && ProvinceThreat(sup.Province, sup.Power, gs, m) > 0 {
+		return false
+	}
+	return true
+}
+
+// MutualSupportPairs returns the pairs of powers that each have at least one
+// no-cost support to offer the other, keyed by the pair in name order.
+func MutualSupportPairs(opps []SupportOpportunity) map[[2]diplomacy.Power]bool {
+	offers := make(map[[2]diplomacy.Power]bool)
+	for _, o := range opps {
+		offers[[2]diplomacy.Power{o.Supporter, o.Beneficiary}] = true
+	}
+	mutual := make(map[[2]diplomacy.Power]bool)
+	for pair := range offers {
+		a, b := pair[0], pair[1]
+		if a < b && offers[[2]diplomacy.Power{b, a}] {
+			mutual[[2]diplomacy.Power{a, b}] = true
+		}
+	}
+	return mutual
+}
@@ -0,0 +1,93 @@
+package bot
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// supportPosition has Germany able to take Holland from Ruhr against an
+// English fleet in the North Sea, with a French fleet in Belgium that can help.
+func supportPosition() *diplomacy.GameState {
+	return &diplomacy.GameState{
+		Year:   1903,
+		Season: diplomacy.Spring,
+		Phase:  diplomacy.PhaseMovement,
+		Units: []diplomacy.Unit{
+			{Type: diplomacy.Army, Power: diplomacy.Germany, Province: "ruh"},
+			{Type: diplomacy.Fleet, Power: diplomacy.England, Province: "nth"},
+			{Type: diplomacy.Fleet, Power: diplomacy.France, Province: "bel"},
+		},
+		SupplyCenters: map[string]diplomacy.Power{
+			"mun": diplomacy.Germany, "lon": diplomacy.England, "par": diplomacy.France,
+		},
+	}
+}
+
+func TestContestedTargets(t *testing.T) {
+	contested := ContestedTargets(supportPosition(), diplomacy.StandardMap())
+	want := []diplomacy.Power{diplomacy.England, diplomacy.France, diplomacy.Germany}
+	if !reflect.DeepEqual(contested["hol"], want) {
+		t.Errorf("hol contested by %v, want %v", contested["hol"], want)
+	}
+	if _, ok := contested["mun"]; ok {
+		t.Error("mun is only reachable by Germany and should not be contested")
+	}
+}
+
+func TestSupportOpportunities(t *testing.T) {
+	gs := supportPosition()
+	m := diplomacy.StandardMap()
+	opps := SupportOpportunities(gs, m)
+
+	var found *SupportOpportunity
+	for i, o := range opps {
+		if o.Supporter == diplomacy.France && o.Beneficiary == diplomacy.Germany && o.Target == "hol" {
+			found = &opps[i]
+		}
+		if o.Supporter == diplomacy.Germany && o.Target == "hol" {
+			t.Error("Germany wants Holland itself and should not offer support into it")
+		}
+	}
+	if found == nil {
+		t.Fatalf("expected France to be able to support Germany into Holland, got %+v", opps)
+	}
+	if !reflect.DeepEqual(found.ContestedBy, []diplomacy.Power{diplomacy.England}) {
+		t.Errorf("expected England as the third party, got %v", found.ContestedBy)
+	}
+	if err := diplomacy.ValidateOrder(found.Order(), gs, m); err != nil {
+		t.Errorf("support order should be legal: %v", err)
+	}
+	intent := found.Intent()
+	if intent.Type != IntentRequestSupport || intent.To != diplomacy.France || intent.Provinces[1] != "hol" {
+		t.Errorf("unexpected request intent: %+v", intent)
+	}
+
+	if again := SupportOpportunities(gs, m); !reflect.DeepEqual(opps, again) {
+		t.Error("opportunities should be deterministic for the same position")
+	}
+}
+
+func TestMutualSupportPairs(t *testing.T) {
+	opps := []SupportOpportunity{
+		{Supporter: diplomacy.France, Beneficiary: diplomacy.Germany},
+		{Supporter: diplomacy.Germany, Beneficiary: diplomacy.France},
+		{Supporter: diplomacy.Italy, Beneficiary: diplomacy.Austria},
+	}
+	mutual := MutualSupportPairs(opps)
+	if len(mutual) != 1 || !mutual[[2]diplomacy.Power{diplomacy.France, diplomacy.Germany}] {
+		t.Errorf("expected only france/germany to be mutual, got %v", mutual)
+	}
+}
+
+func TestTacticalStrategy_RequestsSupport(t *testing.T) {
+	gs := supportPosition()
+	msgs := TacticalStrategy{}.GenerateDiplomaticMessages(gs, diplomacy.Germany, diplomacy.StandardMap(), nil)
+	for _, msg := range msgs {
+		if msg.Type == IntentRequestSupport && msg.To == diplomacy.France {
+			return
+		}
+	}
+	t.Errorf("expected Germany to ask France for support, got %+v", msgs)
+}
@@ -0,0 +1,61 @@
+package bot
+
+import "github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+
+const (
+	// yearLimitHorizon is how many SC adjustments before the year limit the
+	// bots start shifting from solo play toward draw-score play.
+	yearLimitHorizon = 4
+
+	// maxSCGainPerYear is an optimistic bound on how many supply centers a
+	// single power can gain in one year, used to rule out a solo.
+	maxSCGainPerYear = 4
+
+	// standardVictorySCs is the solo threshold the bots' center-count
+	// thresholds are written for.
+	standardVictorySCs = 18
+)
+
+// scThreshold converts a center count written for the standard 18-center
+// solo into one the same distance from gs's victory threshold, so games
+// played to a different count are evaluated as near to or far from a solo
+// as they really are.
+func scThreshold(gs *diplomacy.GameState, standard int) int {
+	return standard - standardVictorySCs + gs.VictoryCenters()
+}
+
+// adjustmentsRemaining returns how many Fall SC ownership updates are still
+// to be played before the game reaches its year limit.
+func adjustmentsRemaining(gs *diplomacy.GameState) int {
+	n := gs.LastYear() - gs.Year
+	if gs.Phase != diplomacy.PhaseBuild {
+		n++ // this year's Fall update is still ahead
+	}
+	return max(n, 0)
+}
+
+// yearLimitPressure returns a weight in [0, 1] describing how close the game
+// is to its year limit: 0 while more than yearLimitHorizon adjustments remain,
+// rising to 1 when the final adjustment is the only one left.
+func yearLimitPressure(gs *diplomacy.GameState) float64 {
+	left := adjustmentsRemaining(gs)
+	if left > yearLimitHorizon {
+		return 0
+	}
+	if left <= 1 {
+		return 1
+	}
+	return float64(yearLimitHorizon+1-left) / float64(yearLimitHorizon)
+}
+
+// soloStillPossible reports whether any power could plausibly reach the
+// victory threshold before the year limit ends the game as a draw.
+func soloStillPossible(gs *diplomacy.GameState) bool {
+	reach := maxSCGainPerYear * adjustmentsRemaining(gs)
+	for _, p := range diplomacy.AllPowers() {
+		if gs.SupplyCenterCount(p)+reach >= gs.VictoryCenters() {
+			return true
+		}
+	}
+	return false
+}
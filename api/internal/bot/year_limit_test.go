@@ -0,0 +1,85 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+func TestAdjustmentsRemaining(t *testing.T) {
+	tests := []struct {
+		year  int
+		phase diplomacy.PhaseType
+		want  int
+	}{
+		{1920, diplomacy.PhaseMovement, 1},
+		{1920, diplomacy.PhaseBuild, 0},
+		{1918, diplomacy.PhaseMovement, 3},
+		{1921, diplomacy.PhaseMovement, 0},
+	}
+	for _, tt := range tests {
+		gs := &diplomacy.GameState{Year: tt.year, Phase: tt.phase, YearLimit: 1920}
+		if got := adjustmentsRemaining(gs); got != tt.want {
+			t.Errorf("adjustmentsRemaining(%d %s) = %d, want %d", tt.year, tt.phase, got, tt.want)
+		}
+	}
+}
+
+func TestYearLimitPressure(t *testing.T) {
+	gs := diplomacy.NewInitialState()
+	if p := yearLimitPressure(gs); p != 0 {
+		t.Errorf("default limit should exert no pressure, got %v", p)
+	}
+
+	gs.YearLimit = 1901
+	if p := yearLimitPressure(gs); p != 1 {
+		t.Errorf("final year should exert full pressure, got %v", p)
+	}
+
+	gs.YearLimit = 1903
+	mid := yearLimitPressure(gs)
+	if mid <= 0 || mid >= 1 {
+		t.Errorf("expected partial pressure 3 adjustments out, got %v", mid)
+	}
+}
+
+func TestShouldVoteDrawAtYearLimit(t *testing.T) {
+	gs := diplomacy.NewInitialState()
+	gs.Year = 1910
+	if (HardStrategy{}).ShouldVoteDraw(gs, diplomacy.Russia) {
+		t.Error("hard should not vote draw at the start with no year limit")
+	}
+
+	gs.YearLimit = 1910
+	if !(HardStrategy{}).ShouldVoteDraw(gs, diplomacy.Russia) {
+		t.Error("hard should vote draw when no power can solo before the limit")
+	}
+	if !(TacticalStrategy{}).ShouldVoteDraw(gs, diplomacy.Russia) {
+		t.Error("medium should vote draw when no power can solo before the limit")
+	}
+}
+
+func TestSCThresholdFollowsVictoryCenters(t *testing.T) {
+	gs := diplomacy.NewInitialState()
+	if got := scThreshold(gs, 14); got != 14 {
+		t.Errorf("standard game: got %d, want 14", got)
+	}
+	gs.VictorySCs = 12
+	if got := scThreshold(gs, 14); got != 8 {
+		t.Errorf("12-center game: got %d, want 8", got)
+	}
+
+	// Russia one center short of a 6-center solo should worry Austria far
+	// more than in the standard game.
+	m := diplomacy.StandardMap()
+	gs.VictorySCs = 0
+	gs.SupplyCenters["rum"] = diplomacy.Russia
+	standard := hardEvaluatePosition(gs, diplomacy.Austria, m)
+	gs.VictorySCs = 6
+	if short := hardEvaluatePosition(gs, diplomacy.Austria, m); short >= standard {
+		t.Errorf("expected a near-solo to lower Austria's score: %v vs %v", short, standard)
+	}
+	if !soloStillPossible(gs) {
+		t.Error("a solo should be possible at 6 centers")
+	}
+}
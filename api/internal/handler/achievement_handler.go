@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/freeeve/polite-betrayal/api/internal/auth"
+	"github.com/freeeve/polite-betrayal/api/internal/service"
+)
+
+// AchievementHandler serves user stats and the hall of fame.
+type AchievementHandler struct {
+	achievementSvc *service.AchievementService
+}
+
+// NewAchievementHandler creates an AchievementHandler.
+func NewAchievementHandler(achievementSvc *service.AchievementService) *AchievementHandler {
+	return &AchievementHandler{achievementSvc: achievementSvc}
+}
+
+// UserStats handles GET /api/v1/users/{id}/stats. The id "me" is the caller.
+func (h *AchievementHandler) UserStats(w http.ResponseWriter, r *http.Request) {
+	userID := r.PathValue("id")
+	if userID == "me" {
+		userID = auth.UserIDFromContext(r.Context())
+	}
+	stats, err := h.achievementSvc.UserStats(r.Context(), userID)
+	if err != nil {
+		writeError(w, internalErrorStatus(err), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// HallOfFame handles GET /api/v1/hall-of-fame?limit=N
+func (h *AchievementHandler) HallOfFame(w http.ResponseWriter, r *http.Request) {
+	limit := service.DefaultHallOfFameSize
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 100 {
+			writeError(w, http.StatusBadRequest, "limit must be between 1 and 100")
+			return
+		}
+		limit = n
+	}
+	hof, err := h.achievementSvc.HallOfFame(r.Context(), limit)
+	if err != nil {
+		writeError(w, internalErrorStatus(err), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, hof)
+}
@@ -0,0 +1,253 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/freeeve/polite-betrayal/api/internal/bot"
+	"github.com/freeeve/polite-betrayal/api/internal/jobs"
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/internal/service"
+)
+
+// AdminHandler handles operator-only endpoints. Routes must be wrapped in
+// auth.RequireAdmin.
+type AdminHandler struct {
+	flagSvc *service.FlagService
+	gameSvc *service.GameService
+	pool    *service.ResolutionPool // optional: enables ResolutionStats
+	compute *service.ComputeService // optional: enables the compute reports
+	jobs    *jobs.Queue             // optional: enables the job endpoints
+}
+
+// NewAdminHandler creates an AdminHandler.
+func NewAdminHandler(flagSvc *service.FlagService, gameSvc *service.GameService) *AdminHandler {
+	return &AdminHandler{flagSvc: flagSvc, gameSvc: gameSvc}
+}
+
+// SetResolutionPool configures the phase resolution pool reported by
+// ResolutionStats.
+func (h *AdminHandler) SetResolutionPool(pool *service.ResolutionPool) {
+	h.pool = pool
+}
+
+// SetComputeService configures the bot compute accounting reported by
+// ComputeUsage and GameCompute.
+func (h *AdminHandler) SetComputeService(svc *service.ComputeService) {
+	h.compute = svc
+}
+
+// SetJobQueue configures the background job queue reported by ListJobs and
+// retried by RetryJob.
+func (h *AdminHandler) SetJobQueue(q *jobs.Queue) {
+	h.jobs = q
+}
+
+// ListJobs handles GET /api/v1/admin/jobs?status=dead&limit=N, returning job
+// counts by status and the most recently updated jobs, optionally filtered
+// by status.
+func (h *AdminHandler) ListJobs(w http.ResponseWriter, r *http.Request) {
+	if h.jobs == nil {
+		writeError(w, http.StatusNotFound, "job queue not enabled")
+		return
+	}
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", model.JobPending, model.JobRunning, model.JobDone, model.JobDead:
+	default:
+		writeError(w, http.StatusBadRequest, "status must be pending, running, done or dead")
+		return
+	}
+	limit := 50
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 500 {
+			writeError(w, http.StatusBadRequest, "limit must be between 1 and 500")
+			return
+		}
+		limit = n
+	}
+	stats, err := h.jobs.Stats(r.Context())
+	if err != nil {
+		writeError(w, internalErrorStatus(err), err.Error())
+		return
+	}
+	list, err := h.jobs.List(r.Context(), status, limit)
+	if err != nil {
+		writeError(w, internalErrorStatus(err), err.Error())
+		return
+	}
+	if list == nil {
+		list = []model.Job{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"stats": stats, "jobs": list})
+}
+
+// RetryJob handles POST /api/v1/admin/jobs/{id}/retry, requeueing a
+// dead-lettered job with a fresh set of attempts.
+func (h *AdminHandler) RetryJob(w http.ResponseWriter, r *http.Request) {
+	if h.jobs == nil {
+		writeError(w, http.StatusNotFound, "job queue not enabled")
+		return
+	}
+	ok, err := h.jobs.Retry(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, internalErrorStatus(err), err.Error())
+		return
+	}
+	if !ok {
+		writeError(w, http.StatusNotFound, "no dead job with that id")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ComputeUsage handles GET /api/v1/admin/compute?since=24h&limit=N, listing
+// the games that used the most bot compute in the window.
+func (h *AdminHandler) ComputeUsage(w http.ResponseWriter, r *http.Request) {
+	if h.compute == nil {
+		writeError(w, http.StatusNotFound, "compute accounting not enabled")
+		return
+	}
+	since := 24 * time.Hour
+	if v := r.URL.Query().Get("since"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			writeError(w, http.StatusBadRequest, "since must be a positive duration such as 24h")
+			return
+		}
+		since = d
+	}
+	limit := 20
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 100 {
+			writeError(w, http.StatusBadRequest, "limit must be between 1 and 100")
+			return
+		}
+		limit = n
+	}
+	games, err := h.compute.TopGames(r.Context(), time.Now().Add(-since), limit)
+	if err != nil {
+		writeError(w, internalErrorStatus(err), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, games)
+}
+
+// GameCompute handles GET /api/v1/admin/games/{id}/compute, breaking a
+// game's bot compute time down by phase and power.
+func (h *AdminHandler) GameCompute(w http.ResponseWriter, r *http.Request) {
+	if h.compute == nil {
+		writeError(w, http.StatusNotFound, "compute accounting not enabled")
+		return
+	}
+	report, err := h.compute.GameReport(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, internalErrorStatus(err), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// ResolutionStats handles GET /api/v1/admin/resolution, reporting the phase
+// resolution pool's queue depth and wait times.
+func (h *AdminHandler) ResolutionStats(w http.ResponseWriter, r *http.Request) {
+	if h.pool == nil {
+		writeError(w, http.StatusNotFound, "resolution pool not enabled")
+		return
+	}
+	writeJSON(w, http.StatusOK, h.pool.Stats())
+}
+
+// EngineStatus handles GET /api/v1/admin/engines, reporting the circuit
+// breaker state of each external engine.
+func (h *AdminHandler) EngineStatus(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, bot.EngineStatuses())
+}
+
+// ModelStatus handles GET /api/v1/admin/models, listing the gonnx models
+// bots can play with and when each was loaded.
+func (h *AdminHandler) ModelStatus(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, bot.Models.Statuses())
+}
+
+// ReloadModels handles POST /api/v1/admin/models/reload, reloading every
+// gonnx model from disk. Models that fail to load keep their previous
+// version and report the error.
+func (h *AdminHandler) ReloadModels(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, bot.Models.Reload())
+}
+
+// GetGameFlags handles GET /api/v1/admin/games/{id}/flags
+func (h *AdminHandler) GetGameFlags(w http.ResponseWriter, r *http.Request) {
+	flags, err := h.flagSvc.GameFlags(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, internalErrorStatus(err), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, flags)
+}
+
+// SetGameFlag handles PUT /api/v1/admin/games/{id}/flags/{flag}
+func (h *AdminHandler) SetGameFlag(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Enabled bool `json:"enabled"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := h.flagSvc.SetGameFlag(r.Context(), r.PathValue("id"), r.PathValue("flag"), req.Enabled); err != nil {
+		writeFlagError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"flag": r.PathValue("flag"), "enabled": req.Enabled})
+}
+
+// ClearGameFlag handles DELETE /api/v1/admin/games/{id}/flags/{flag}
+func (h *AdminHandler) ClearGameFlag(w http.ResponseWriter, r *http.Request) {
+	if err := h.flagSvc.ClearGameFlag(r.Context(), r.PathValue("id"), r.PathValue("flag")); err != nil {
+		writeFlagError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "cleared"})
+}
+
+// SetFlagRollout handles PUT /api/v1/admin/flags/{flag}/rollout
+func (h *AdminHandler) SetFlagRollout(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Percent int `json:"percent"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := h.flagSvc.SetRollout(r.Context(), r.PathValue("flag"), req.Percent); err != nil {
+		writeFlagError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"flag": r.PathValue("flag"), "percent": req.Percent})
+}
+
+// PurgeDeletedGames handles POST /api/v1/admin/games/purge
+func (h *AdminHandler) PurgeDeletedGames(w http.ResponseWriter, r *http.Request) {
+	n, err := h.gameSvc.PurgeDeletedGames(r.Context())
+	if err != nil {
+		writeError(w, internalErrorStatus(err), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]int{"purged": n})
+}
+
+func writeFlagError(w http.ResponseWriter, err error) {
+	status := internalErrorStatus(err)
+	if errors.Is(err, service.ErrUnknownFlag) {
+		status = http.StatusNotFound
+	} else if errors.Is(err, service.ErrInvalidRollout) {
+		status = http.StatusBadRequest
+	}
+	writeError(w, status, err.Error())
+}
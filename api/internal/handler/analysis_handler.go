@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/freeeve/polite-betrayal/api/internal/auth"
+	"github.com/freeeve/polite-betrayal/api/internal/service"
+)
+
+// AnalysisHandler serves position evaluations and order reviews of games.
+type AnalysisHandler struct {
+	analysisSvc *service.AnalysisService
+}
+
+// NewAnalysisHandler creates an AnalysisHandler.
+func NewAnalysisHandler(analysisSvc *service.AnalysisService) *AnalysisHandler {
+	return &AnalysisHandler{analysisSvc: analysisSvc}
+}
+
+// GetEvaluation handles GET /api/v1/games/{id}/evaluation, returning each
+// power's standing and, when a value network is loaded, its win and draw
+// probabilities in the current phase, or the final position of a finished
+// game. With ?phases=all it returns the position after every phase too.
+// Players seated in a live game get no win or draw probabilities.
+func (h *AnalysisHandler) GetEvaluation(w http.ResponseWriter, r *http.Request) {
+	phases := r.URL.Query().Get("phases")
+	if phases != "" && phases != "current" && phases != "all" {
+		writeError(w, http.StatusBadRequest, "phases must be current or all")
+		return
+	}
+	evals, err := h.analysisSvc.Evaluations(r.Context(), r.PathValue("id"), auth.UserIDFromContext(r.Context()), phases == "all")
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrGameNotFound):
+			writeError(w, http.StatusNotFound, "game not found")
+		case errors.Is(err, service.ErrGameNotActive):
+			writeError(w, http.StatusConflict, err.Error())
+		default:
+			writeError(w, internalErrorStatus(err), err.Error())
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, evals)
+}
+
+// GetAnalysis handles GET /api/v1/games/{id}/analysis, returning the review
+// of each human player's movement orders in a finished game against what a
+// bot would have played, with the phases in which they blundered flagged.
+func (h *AnalysisHandler) GetAnalysis(w http.ResponseWriter, r *http.Request) {
+	report, err := h.analysisSvc.Report(r.Context(), r.PathValue("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrGameNotFound):
+			writeError(w, http.StatusNotFound, "game not found")
+		case errors.Is(err, service.ErrGameNotFinished):
+			writeError(w, http.StatusConflict, err.Error())
+		default:
+			writeError(w, internalErrorStatus(err), err.Error())
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
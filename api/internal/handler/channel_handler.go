@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/freeeve/polite-betrayal/api/internal/auth"
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/internal/service"
+)
+
+// ChannelHandler handles press channel endpoints. Messages are sent to a
+// channel through MessageHandler with a channel_id.
+type ChannelHandler struct {
+	channelSvc *service.ChannelService
+	hub        *Hub
+}
+
+// NewChannelHandler creates a ChannelHandler.
+func NewChannelHandler(channelSvc *service.ChannelService, hub *Hub) *ChannelHandler {
+	return &ChannelHandler{channelSvc: channelSvc, hub: hub}
+}
+
+// CreateChannel handles POST /api/v1/games/{id}/channels
+func (h *ChannelHandler) CreateChannel(w http.ResponseWriter, r *http.Request) {
+	gameID := r.PathValue("id")
+	var req struct {
+		Name   string   `json:"name"`
+		Powers []string `json:"powers"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	c, err := h.channelSvc.CreateChannel(r.Context(), gameID, auth.UserIDFromContext(r.Context()), req.Name, req.Powers)
+	if err != nil {
+		writeChannelError(w, err)
+		return
+	}
+	h.notifyMembers(r.Context(), c, c.Members)
+	h.writeChannel(w, r, http.StatusCreated, c)
+}
+
+// ListChannels handles GET /api/v1/games/{id}/channels, returning the
+// channels the user belongs to.
+func (h *ChannelHandler) ListChannels(w http.ResponseWriter, r *http.Request) {
+	gameID, userID := r.PathValue("id"), auth.UserIDFromContext(r.Context())
+	channels, err := h.channelSvc.ListChannels(r.Context(), gameID, userID)
+	if err == nil {
+		channels, err = h.channelSvc.ForViewer(r.Context(), gameID, channels, userID)
+	}
+	if err != nil {
+		writeError(w, internalErrorStatus(err), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, channels)
+}
+
+// AddMember handles POST /api/v1/games/{id}/channels/{channelId}/members
+func (h *ChannelHandler) AddMember(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Power string `json:"power"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	c, err := h.channelSvc.AddMember(r.Context(), r.PathValue("id"), r.PathValue("channelId"), auth.UserIDFromContext(r.Context()), req.Power)
+	if err != nil {
+		writeChannelError(w, err)
+		return
+	}
+	h.notifyMembers(r.Context(), c, c.Members)
+	h.writeChannel(w, r, http.StatusOK, c)
+}
+
+// RemoveMember handles DELETE /api/v1/games/{id}/channels/{channelId}/members/{userId}
+func (h *ChannelHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	c, removedID, err := h.channelSvc.RemoveMember(r.Context(), r.PathValue("id"), r.PathValue("channelId"), auth.UserIDFromContext(r.Context()), r.PathValue("userId"))
+	if err != nil {
+		writeChannelError(w, err)
+		return
+	}
+	h.notifyMembers(r.Context(), c, append(c.Members, removedID))
+	h.writeChannel(w, r, http.StatusOK, c)
+}
+
+// notifyMembers sends the channel's new membership to the given users.
+func (h *ChannelHandler) notifyMembers(ctx context.Context, c *model.Channel, userIDs []string) {
+	for _, userID := range userIDs {
+		if view, err := h.channelSvc.ForViewer(ctx, c.GameID, []model.Channel{*c}, userID); err == nil {
+			h.hub.BroadcastToUser(userID, WSEvent{Type: EventChannelUpdated, GameID: c.GameID, Data: view[0]})
+		}
+	}
+}
+
+// writeChannel writes a channel as the requesting user may see it.
+func (h *ChannelHandler) writeChannel(w http.ResponseWriter, r *http.Request, status int, c *model.Channel) {
+	view, err := h.channelSvc.ForViewer(r.Context(), c.GameID, []model.Channel{*c}, auth.UserIDFromContext(r.Context()))
+	if err != nil {
+		writeError(w, internalErrorStatus(err), err.Error())
+		return
+	}
+	writeJSON(w, status, view[0])
+}
+
+func writeChannelError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrGameNotFound), errors.Is(err, service.ErrChannelNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrNotInGame), errors.Is(err, service.ErrNotChannelMember), errors.Is(err, service.ErrNotChannelCreator),
+		errors.Is(err, service.ErrPressNotAllowed):
+		writeError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrInvalidChannel), errors.Is(err, service.ErrInvalidPower), errors.Is(err, service.ErrGameNotActive):
+		writeError(w, http.StatusBadRequest, err.Error())
+	default:
+		writeError(w, internalErrorStatus(err), err.Error())
+	}
+}
@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/freeeve/polite-betrayal/api/internal/auth"
+	"github.com/freeeve/polite-betrayal/api/internal/service"
+)
+
+// DashboardHandler serves the multi-game dashboard.
+type DashboardHandler struct {
+	dashboardSvc *service.DashboardService
+}
+
+// NewDashboardHandler creates a DashboardHandler.
+func NewDashboardHandler(dashboardSvc *service.DashboardService) *DashboardHandler {
+	return &DashboardHandler{dashboardSvc: dashboardSvc}
+}
+
+// GetDashboard handles GET /api/v1/users/me/dashboard
+func (h *DashboardHandler) GetDashboard(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserIDFromContext(r.Context())
+	dashboard, err := h.dashboardSvc.Dashboard(r.Context(), userID)
+	if err != nil {
+		writeError(w, internalErrorStatus(err), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, dashboard)
+}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/freeeve/polite-betrayal/api/internal/auth"
+	"github.com/freeeve/polite-betrayal/api/internal/service"
+)
+
+// ExportHandler serves exports of finished games.
+type ExportHandler struct {
+	exportSvc   *service.ExportService
+	artifactSvc *service.ArtifactService
+	adminIDs    []string
+}
+
+// NewExportHandler creates an ExportHandler.
+func NewExportHandler(exportSvc *service.ExportService) *ExportHandler {
+	return &ExportHandler{exportSvc: exportSvc}
+}
+
+// SetArtifactService enables ?link=true on exports.
+func (h *ExportHandler) SetArtifactService(artifactSvc *service.ArtifactService) {
+	h.artifactSvc = artifactSvc
+}
+
+// SetAdminIDs lists the users who get unfiltered exports of any game.
+func (h *ExportHandler) SetAdminIDs(ids []string) {
+	h.adminIDs = ids
+}
+
+// ExportGame handles GET /api/v1/games/{id}/export. With ?format=training it
+// returns per-power (state, press, deals, orders) samples instead of the
+// phase-by-phase export; with ?format=selfplay it returns one JSONL line in
+// the selfplay format read by cmd/import_selfplay. With ?link=true it returns
+// a signed link to the stored export instead. Exports hold only what the
+// caller may see: see service.ExportService.ExportGameFor. The selfplay
+// format carries no press or user IDs, so it is the same for everyone.
+func (h *ExportHandler) ExportGame(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if wantsLink(r) {
+		h.exportLink(w, r, format)
+		return
+	}
+	if format == "selfplay" {
+		h.exportSelfPlay(w, r)
+		return
+	}
+	export, err := h.export(r, r.PathValue("id"))
+	if err != nil {
+		writeExportError(w, err)
+		return
+	}
+
+	switch format {
+	case "", "full":
+		writeJSON(w, http.StatusOK, export)
+	case "training":
+		samples, err := service.TrainingSamples(export)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, samples)
+	default:
+		writeError(w, http.StatusBadRequest, "format must be full, training or selfplay")
+	}
+}
+
+// export builds the caller's view of a game's export.
+func (h *ExportHandler) export(r *http.Request, gameID string) (*service.GameExport, error) {
+	userID := auth.UserIDFromContext(r.Context())
+	if slices.Contains(h.adminIDs, userID) {
+		return h.exportSvc.ExportGame(r.Context(), gameID)
+	}
+	return h.exportSvc.ExportGameFor(r.Context(), gameID, userID)
+}
+
+// exportScope names the view of an export the caller gets, so stored
+// exports are only ever linked to callers entitled to them.
+func (h *ExportHandler) exportScope(r *http.Request) string {
+	userID := auth.UserIDFromContext(r.Context())
+	if slices.Contains(h.adminIDs, userID) {
+		return "admin"
+	}
+	return "user-" + userID
+}
+
+func (h *ExportHandler) exportSelfPlay(w http.ResponseWriter, r *http.Request) {
+	rec, err := h.exportSvc.ExportSelfPlay(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeExportError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+rec.SourceID+`.jsonl"`)
+	json.NewEncoder(w).Encode(rec)
+}
+
+// exportLink stores the export in the requested format and writes a signed
+// link to it. The export is built on every request, so the caller's access
+// is checked and the link always points at the current content.
+func (h *ExportHandler) exportLink(w http.ResponseWriter, r *http.Request, format string) {
+	gameID := r.PathValue("id")
+	var v any
+	var err error
+	name, contentType := h.exportScope(r)+"/export.json", "application/json"
+	switch format {
+	case "", "full":
+		v, err = h.export(r, gameID)
+	case "training":
+		name = h.exportScope(r) + "/training.json"
+		var export *service.GameExport
+		if export, err = h.export(r, gameID); err == nil {
+			v, err = service.TrainingSamples(export)
+		}
+	case "selfplay":
+		name, contentType = "selfplay.jsonl", "application/x-ndjson"
+		v, err = h.exportSvc.ExportSelfPlay(r.Context(), gameID)
+	default:
+		writeError(w, http.StatusBadRequest, "format must be full, training or selfplay")
+		return
+	}
+	if err != nil {
+		writeExportError(w, err)
+		return
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	data = append(data, '\n')
+	writeArtifactLink(w, r, h.artifactSvc, "games/"+gameID+"/"+name, service.ContentVersion(data), contentType, func() ([]byte, error) {
+		return data, nil
+	})
+}
+
+func writeExportError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrGameNotFound):
+		writeError(w, http.StatusNotFound, "game not found")
+	case errors.Is(err, service.ErrGameNotFinished):
+		writeError(w, http.StatusConflict, err.Error())
+	default:
+		writeError(w, internalErrorStatus(err), err.Error())
+	}
+}
@@ -1,14 +1,18 @@
 package handler
 
 import (
+	"cmp"
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/rs/zerolog/log"
 
 	"github.com/freeeve/polite-betrayal/api/internal/auth"
+	"github.com/freeeve/polite-betrayal/api/internal/bot"
+	"github.com/freeeve/polite-betrayal/api/internal/model"
 	"github.com/freeeve/polite-betrayal/api/internal/service"
 )
 
@@ -34,7 +38,20 @@ func (h *GameHandler) CreateGame(w http.ResponseWriter, r *http.Request) {
 		BuildDuration   string `json:"build_duration,omitempty"`
 		BotDifficulty   string `json:"bot_difficulty,omitempty"`
 		PowerAssignment string `json:"power_assignment,omitempty"`
+		Preset          string `json:"preset,omitempty"`
+		Scenario        string `json:"scenario,omitempty"`
 		BotOnly         bool   `json:"bot_only,omitempty"`
+		BotPress        string `json:"bot_press,omitempty"`       // personality (default), natural or terse
+		PressMode       string `json:"press_mode,omitempty"`      // full (default), public_only or none
+		Anonymous       bool   `json:"anonymous,omitempty"`       // show players only by power until the game ends
+		Garrisons       bool   `json:"garrisons,omitempty"`       // start unowned centers with hold-only neutral armies
+		RetreatCredits  bool   `json:"retreat_credits,omitempty"` // let disbanded retreats be rebuilt at the next adjustment
+		MercyYears      int    `json:"mercy_years,omitempty"`     // years bots spare the human players' home centers (teaching games)
+		ReadyQuorum     int    `json:"ready_quorum,omitempty"`    // percent of powers whose readiness resolves a movement phase early
+		QuorumDelay     string `json:"quorum_delay,omitempty"`    // how long the quorum must hold, e.g. "30m" (default 10m)
+
+		CivilDisorderAfter *int   `json:"civil_disorder_after,omitempty"` // missed deadlines in a row before civil disorder (default 3, 0 off)
+		CivilDisorderBot   string `json:"civil_disorder_bot,omitempty"`   // strategy of the bot taking over abandoned powers
 	}
 	if err := decodeJSON(r, &req); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid request body")
@@ -44,15 +61,117 @@ func (h *GameHandler) CreateGame(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "name is required")
 		return
 	}
+	if req.BotPress != "" && !bot.ValidPressStyle(req.BotPress) {
+		writeError(w, http.StatusBadRequest, service.ErrUnknownPress.Error())
+		return
+	}
+	if req.PressMode == "" {
+		req.PressMode = model.PressFull
+	}
+	if !service.ValidPressMode(req.PressMode) {
+		writeError(w, http.StatusBadRequest, service.ErrUnknownPressMode.Error())
+		return
+	}
+	if _, err := service.ParseReadyQuorum(req.ReadyQuorum, req.QuorumDelay); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.MercyYears < 0 || req.MercyYears > service.MaxMercyYears {
+		writeError(w, http.StatusBadRequest, service.ErrInvalidMercy.Error())
+		return
+	}
+	if req.MercyYears > 0 {
+		for _, difficulty := range []string{cmp.Or(req.BotDifficulty, bot.DefaultStrategy), req.CivilDisorderBot} {
+			if err := service.ValidateMercyStrategy(difficulty); err != nil {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+	}
+	civilDisorderAfter := service.DefaultCivilDisorderAfter
+	if req.CivilDisorderAfter != nil {
+		civilDisorderAfter = *req.CivilDisorderAfter
+	}
+	if err := service.ValidateCivilDisorder(civilDisorderAfter, req.CivilDisorderBot); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-	game, err := h.gameSvc.CreateGame(r.Context(), req.Name, userID, req.TurnDuration, req.RetreatDuration, req.BuildDuration, req.BotDifficulty, req.PowerAssignment, req.BotOnly)
+	rules := model.GameRules{
+		RetreatCredits:     req.RetreatCredits,
+		CivilDisorderAfter: civilDisorderAfter,
+		CivilDisorderBot:   req.CivilDisorderBot,
+		ReadyQuorum:        req.ReadyQuorum,
+		QuorumDelay:        req.QuorumDelay,
+	}
+	var (
+		game *model.Game
+		err  error
+	)
+	if req.Preset != "" {
+		game, err = h.gameSvc.CreateGameWithPreset(r.Context(), req.Name, userID, req.Preset, req.BotDifficulty, req.PowerAssignment, req.Scenario, req.BotOnly, rules)
+	} else {
+		game, err = h.gameSvc.CreateGame(r.Context(), req.Name, userID, req.TurnDuration, req.RetreatDuration, req.BuildDuration, req.BotDifficulty, req.PowerAssignment, req.Scenario, req.BotOnly, rules)
+	}
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		if errors.Is(err, service.ErrUnknownPreset) || errors.Is(err, service.ErrUnknownScenario) {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeError(w, internalErrorStatus(err), err.Error())
 		return
 	}
+	if req.BotPress != "" && req.BotPress != bot.PressStylePersonality {
+		if err := h.gameSvc.UpdateBotPress(r.Context(), game.ID, userID, req.BotPress); err != nil {
+			writeError(w, internalErrorStatus(err), err.Error())
+			return
+		}
+		game.BotPress = req.BotPress
+	}
+	if req.PressMode != model.PressFull || req.Anonymous {
+		if err := h.gameSvc.UpdatePressSettings(r.Context(), game.ID, userID, req.PressMode, req.Anonymous); err != nil {
+			writeError(w, internalErrorStatus(err), err.Error())
+			return
+		}
+		game.PressMode, game.Anonymous = req.PressMode, req.Anonymous
+	}
+	if req.Garrisons {
+		if err := h.gameSvc.UpdateGarrisons(r.Context(), game.ID, userID, true); err != nil {
+			writeError(w, internalErrorStatus(err), err.Error())
+			return
+		}
+		game.Garrisons = true
+	}
+	if req.MercyYears > 0 {
+		if err := h.gameSvc.UpdateMercyYears(r.Context(), game.ID, userID, req.MercyYears); err != nil {
+			writeError(w, internalErrorStatus(err), err.Error())
+			return
+		}
+		game.MercyYears = req.MercyYears
+	}
 	writeJSON(w, http.StatusCreated, game)
 }
 
+// ListPresets handles GET /api/v1/game-presets
+func (h *GameHandler) ListPresets(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, service.GamePresets())
+}
+
+// ListScenarios handles GET /api/v1/scenarios
+func (h *GameHandler) ListScenarios(w http.ResponseWriter, r *http.Request) {
+	scenarios, err := h.gameSvc.ListScenarios(r.Context())
+	if err != nil {
+		writeError(w, internalErrorStatus(err), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, scenarios)
+}
+
+// ListBotStrategies handles GET /api/v1/bot-strategies
+func (h *GameHandler) ListBotStrategies(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, bot.Strategies())
+}
+
 // ListGames handles GET /api/v1/games
 func (h *GameHandler) ListGames(w http.ResponseWriter, r *http.Request) {
 	userID := auth.UserIDFromContext(r.Context())
@@ -60,7 +179,7 @@ func (h *GameHandler) ListGames(w http.ResponseWriter, r *http.Request) {
 	search := r.URL.Query().Get("search")
 	games, err := h.gameSvc.ListGames(r.Context(), userID, filter, search)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeError(w, internalErrorStatus(err), err.Error())
 		return
 	}
 	if games == nil {
@@ -79,7 +198,7 @@ func (h *GameHandler) GetGame(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusNotFound, "game not found")
 			return
 		}
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeError(w, internalErrorStatus(err), err.Error())
 		return
 	}
 
@@ -87,12 +206,13 @@ func (h *GameHandler) GetGame(w http.ResponseWriter, r *http.Request) {
 		if count, err := h.phaseSvc.ReadyCount(r.Context(), gameID); err == nil {
 			game.ReadyCount = count
 		}
-		if count, err := h.phaseSvc.DrawVoteCount(r.Context(), gameID); err == nil {
-			game.DrawVoteCount = count
+		if votes, err := h.phaseSvc.DrawVotePowers(r.Context(), gameID); err == nil {
+			game.DrawVoteCount = len(votes)
+			game.DrawVotes = votes
 		}
 	}
 
-	writeJSON(w, http.StatusOK, game)
+	writeJSON(w, http.StatusOK, service.AnonymizeGame(game, auth.UserIDFromContext(r.Context())))
 }
 
 // VoteForDraw handles POST /api/v1/games/{id}/draw/vote
@@ -106,7 +226,7 @@ func (h *GameHandler) VoteForDraw(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusNotFound, "game not found")
 			return
 		}
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeError(w, internalErrorStatus(err), err.Error())
 		return
 	}
 	if game.Status != "active" {
@@ -127,7 +247,7 @@ func (h *GameHandler) VoteForDraw(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.phaseSvc.VoteForDraw(r.Context(), gameID, power); err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeError(w, internalErrorStatus(err), err.Error())
 		return
 	}
 	writeJSON(w, http.StatusOK, map[string]string{"status": "voted"})
@@ -144,7 +264,7 @@ func (h *GameHandler) RemoveDrawVote(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusNotFound, "game not found")
 			return
 		}
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeError(w, internalErrorStatus(err), err.Error())
 		return
 	}
 	if game.Status != "active" {
@@ -165,7 +285,7 @@ func (h *GameHandler) RemoveDrawVote(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.phaseSvc.RemoveDrawVote(r.Context(), gameID, power); err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeError(w, internalErrorStatus(err), err.Error())
 		return
 	}
 	writeJSON(w, http.StatusOK, map[string]string{"status": "removed"})
@@ -177,7 +297,7 @@ func (h *GameHandler) DeleteGame(w http.ResponseWriter, r *http.Request) {
 	userID := auth.UserIDFromContext(r.Context())
 
 	if err := h.gameSvc.DeleteGame(r.Context(), gameID, userID); err != nil {
-		status := http.StatusInternalServerError
+		status := internalErrorStatus(err)
 		if errors.Is(err, service.ErrGameNotFound) {
 			status = http.StatusNotFound
 		} else if errors.Is(err, service.ErrGameNotWaiting) {
@@ -192,6 +312,30 @@ func (h *GameHandler) DeleteGame(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
 }
 
+// RestoreGame handles POST /api/v1/games/{id}/restore
+func (h *GameHandler) RestoreGame(w http.ResponseWriter, r *http.Request) {
+	gameID := r.PathValue("id")
+	userID := auth.UserIDFromContext(r.Context())
+
+	game, err := h.gameSvc.RestoreGame(r.Context(), gameID, userID)
+	if err != nil {
+		status := internalErrorStatus(err)
+		if errors.Is(err, service.ErrGameNotFound) {
+			status = http.StatusNotFound
+		} else if errors.Is(err, service.ErrGameNotDeleted) {
+			status = http.StatusBadRequest
+		} else if errors.Is(err, service.ErrNotCreator) {
+			status = http.StatusForbidden
+		} else if errors.Is(err, service.ErrRetentionEnded) {
+			status = http.StatusGone
+		}
+		writeError(w, status, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, service.AnonymizeGame(game, auth.UserIDFromContext(r.Context())))
+}
+
 // StopGame handles POST /api/v1/games/{id}/stop
 func (h *GameHandler) StopGame(w http.ResponseWriter, r *http.Request) {
 	gameID := r.PathValue("id")
@@ -199,7 +343,7 @@ func (h *GameHandler) StopGame(w http.ResponseWriter, r *http.Request) {
 
 	game, err := h.gameSvc.StopGame(r.Context(), gameID, userID)
 	if err != nil {
-		status := http.StatusInternalServerError
+		status := internalErrorStatus(err)
 		if errors.Is(err, service.ErrGameNotFound) {
 			status = http.StatusNotFound
 		} else if errors.Is(err, service.ErrGameNotActive) {
@@ -215,10 +359,109 @@ func (h *GameHandler) StopGame(w http.ResponseWriter, r *http.Request) {
 		log.Error().Err(err).Str("gameId", gameID).Msg("Failed to cleanup stopped game")
 	}
 
-	writeJSON(w, http.StatusOK, game)
+	writeJSON(w, http.StatusOK, service.AnonymizeGame(game, auth.UserIDFromContext(r.Context())))
+}
+
+// PauseGame handles POST /api/v1/games/{id}/pause
+func (h *GameHandler) PauseGame(w http.ResponseWriter, r *http.Request) {
+	game, err := h.phaseSvc.PauseGame(r.Context(), r.PathValue("id"), auth.UserIDFromContext(r.Context()))
+	if err != nil {
+		writePauseError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, service.AnonymizeGame(game, auth.UserIDFromContext(r.Context())))
+}
+
+// ResumeGame handles POST /api/v1/games/{id}/resume
+func (h *GameHandler) ResumeGame(w http.ResponseWriter, r *http.Request) {
+	game, err := h.phaseSvc.ResumeGame(r.Context(), r.PathValue("id"), auth.UserIDFromContext(r.Context()))
+	if err != nil {
+		writePauseError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, service.AnonymizeGame(game, auth.UserIDFromContext(r.Context())))
 }
 
-// UpdateBotDifficulty handles PATCH /api/v1/games/{id}/players/{userId}/bot-difficulty
+func writePauseError(w http.ResponseWriter, err error) {
+	status := internalErrorStatus(err)
+	switch {
+	case errors.Is(err, service.ErrGameNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, service.ErrGameNotActive), errors.Is(err, service.ErrGameNotPaused):
+		status = http.StatusBadRequest
+	case errors.Is(err, service.ErrNotCreator):
+		status = http.StatusForbidden
+	}
+	writeError(w, status, err.Error())
+}
+
+// UpdateBotPersonality handles PATCH /api/v1/games/{id}/players/{userId}/bot-personality.
+// The body names a preset (or "random") and/or traits from -1 to 1; traits
+// override the preset's. An empty body restores the strategy's usual play.
+func (h *GameHandler) UpdateBotPersonality(w http.ResponseWriter, r *http.Request) {
+	gameID := r.PathValue("id")
+	botUserID := r.PathValue("userId")
+	userID := auth.UserIDFromContext(r.Context())
+
+	var req struct {
+		Preset        string   `json:"preset,omitempty"`
+		Aggression    *float64 `json:"aggression,omitempty"`
+		Loyalty       *float64 `json:"loyalty,omitempty"`
+		RiskTolerance *float64 `json:"risk_tolerance,omitempty"`
+		Talkativeness *float64 `json:"talkativeness,omitempty"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	var personality *bot.Personality
+	if req.Preset != "" || req.Aggression != nil || req.Loyalty != nil || req.RiskTolerance != nil || req.Talkativeness != nil {
+		var p bot.Personality
+		switch {
+		case req.Preset == bot.PersonalityRandom:
+			p = bot.RandomPersonality()
+		case req.Preset != "":
+			var ok bool
+			if p, ok = bot.LookupPersonality(req.Preset); !ok {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown personality preset %q", req.Preset))
+				return
+			}
+		}
+		for _, t := range []struct {
+			v   *float64
+			dst *float64
+		}{
+			{req.Aggression, &p.Aggression},
+			{req.Loyalty, &p.Loyalty},
+			{req.RiskTolerance, &p.RiskTolerance},
+			{req.Talkativeness, &p.Talkativeness},
+		} {
+			if t.v != nil {
+				*t.dst = *t.v
+			}
+		}
+		personality = &p
+	}
+
+	player, err := h.gameSvc.UpdateBotPersonality(r.Context(), gameID, userID, botUserID, personality)
+	if err != nil {
+		status := internalErrorStatus(err)
+		if errors.Is(err, service.ErrGameNotFound) {
+			status = http.StatusNotFound
+		} else if errors.Is(err, service.ErrNotCreator) || errors.Is(err, service.ErrGameNotActive) ||
+			errors.Is(err, service.ErrInvalidPersonality) || errors.Is(err, service.ErrNotBot) {
+			status = http.StatusBadRequest
+		}
+		writeError(w, status, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"status": "updated", "player": player})
+}
+
+// UpdateBotDifficulty handles PATCH /api/v1/games/{id}/players/{userId}/bot-difficulty.
+// During play the change takes effect from the next phase; with "now" it
+// applies at once and the bot's orders for this phase are regenerated.
 func (h *GameHandler) UpdateBotDifficulty(w http.ResponseWriter, r *http.Request) {
 	gameID := r.PathValue("id")
 	botUserID := r.PathValue("userId")
@@ -226,17 +469,124 @@ func (h *GameHandler) UpdateBotDifficulty(w http.ResponseWriter, r *http.Request
 
 	var req struct {
 		Difficulty string `json:"difficulty"`
+		Now        bool   `json:"now,omitempty"`
 	}
 	if err := decodeJSON(r, &req); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
 
-	if err := h.gameSvc.UpdateBotDifficulty(r.Context(), gameID, userID, botUserID, req.Difficulty); err != nil {
-		status := http.StatusInternalServerError
+	change, err := h.gameSvc.UpdateBotDifficulty(r.Context(), gameID, userID, botUserID, req.Difficulty, req.Now)
+	if err != nil {
+		status := internalErrorStatus(err)
 		if errors.Is(err, service.ErrGameNotFound) {
 			status = http.StatusNotFound
-		} else if errors.Is(err, service.ErrNotCreator) || errors.Is(err, service.ErrGameNotWaiting) {
+		} else if errors.Is(err, service.ErrNotCreator) || errors.Is(err, service.ErrGameNotActive) ||
+			errors.Is(err, service.ErrUnknownStrategy) || errors.Is(err, service.ErrNotBot) || errors.Is(err, service.ErrMercyUnsupported) {
+			status = http.StatusBadRequest
+		}
+		writeError(w, status, err.Error())
+		return
+	}
+
+	if req.Now && change.PhaseID != "" && change.Power != "" {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			if err := h.phaseSvc.RegenerateBotOrders(ctx, gameID, change.Power); err != nil {
+				log.Error().Err(err).Str("gameId", gameID).Str("power", change.Power).Msg("Failed to regenerate bot orders")
+			}
+		}()
+	}
+
+	status := "updated"
+	if change.AppliedAt == nil {
+		status = "pending"
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"status": status, "change": change})
+}
+
+// ReplaceWithBot handles POST /api/v1/games/{id}/players/{userId}/replace-with-bot,
+// letting an admin hand an abandoned power to a bot. The player stays on as
+// a spectator.
+func (h *GameHandler) ReplaceWithBot(w http.ResponseWriter, r *http.Request) {
+	gameID := r.PathValue("id")
+
+	var req struct {
+		Difficulty string `json:"difficulty,omitempty"`
+	}
+	if r.ContentLength != 0 {
+		if err := decodeJSON(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+	}
+
+	player, err := h.gameSvc.ReplaceWithBot(r.Context(), gameID, r.PathValue("userId"), req.Difficulty)
+	if err != nil {
+		status := internalErrorStatus(err)
+		if errors.Is(err, service.ErrGameNotFound) {
+			status = http.StatusNotFound
+		} else if errors.Is(err, service.ErrGameNotActive) || errors.Is(err, service.ErrUnknownStrategy) || errors.Is(err, service.ErrNotHumanPlayer) ||
+			errors.Is(err, service.ErrMercyUnsupported) {
+			status = http.StatusBadRequest
+		}
+		writeError(w, status, err.Error())
+		return
+	}
+
+	h.wsHub.BroadcastToGame(gameID, WSEvent{
+		Type:   EventPlayerReplaced,
+		GameID: gameID,
+		Data:   map[string]string{"power": player.Power, "bot_difficulty": player.BotDifficulty, "reason": "admin"},
+	})
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := h.phaseSvc.RegenerateBotOrders(ctx, gameID, player.Power); err != nil {
+			log.Error().Err(err).Str("gameId", gameID).Str("power", player.Power).Msg("Failed to generate orders for replacement bot")
+		}
+	}()
+
+	writeJSON(w, http.StatusOK, map[string]any{"status": "replaced", "player": player})
+}
+
+// BotDifficultyChanges handles GET /api/v1/games/{id}/bot-difficulty-changes,
+// the history of the game's bot difficulty changes.
+func (h *GameHandler) BotDifficultyChanges(w http.ResponseWriter, r *http.Request) {
+	changes, err := h.gameSvc.BotDifficultyChanges(r.Context(), r.PathValue("id"), auth.UserIDFromContext(r.Context()))
+	if err != nil {
+		status := internalErrorStatus(err)
+		if errors.Is(err, service.ErrGameNotFound) {
+			status = http.StatusNotFound
+		} else if errors.Is(err, service.ErrNotCreator) {
+			status = http.StatusForbidden
+		}
+		writeError(w, status, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, changes)
+}
+
+// UpdateBotPress handles PATCH /api/v1/games/{id}/bot-press, switching the
+// game's bots between personality, natural and terse press.
+func (h *GameHandler) UpdateBotPress(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Style string `json:"style"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	err := h.gameSvc.UpdateBotPress(r.Context(), r.PathValue("id"), auth.UserIDFromContext(r.Context()), req.Style)
+	if err != nil {
+		status := internalErrorStatus(err)
+		switch {
+		case errors.Is(err, service.ErrGameNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, service.ErrNotCreator):
+			status = http.StatusForbidden
+		case errors.Is(err, service.ErrUnknownPress), errors.Is(err, service.ErrGameNotActive):
 			status = http.StatusBadRequest
 		}
 		writeError(w, status, err.Error())
@@ -260,7 +610,7 @@ func (h *GameHandler) UpdatePlayerPower(w http.ResponseWriter, r *http.Request)
 	}
 
 	if err := h.gameSvc.UpdatePlayerPower(r.Context(), gameID, targetUserID, requestingUserID, req.Power); err != nil {
-		status := http.StatusInternalServerError
+		status := internalErrorStatus(err)
 		if errors.Is(err, service.ErrGameNotFound) {
 			status = http.StatusNotFound
 		} else if errors.Is(err, service.ErrGameNotWaiting) || errors.Is(err, service.ErrNotManualMode) || errors.Is(err, service.ErrInvalidPower) || errors.Is(err, service.ErrPowerTaken) {
@@ -287,11 +637,11 @@ func (h *GameHandler) JoinGame(w http.ResponseWriter, r *http.Request) {
 	userID := auth.UserIDFromContext(r.Context())
 
 	if err := h.gameSvc.JoinGame(r.Context(), gameID, userID); err != nil {
-		status := http.StatusInternalServerError
+		status := internalErrorStatus(err)
 		if errors.Is(err, service.ErrGameNotFound) {
 			status = http.StatusNotFound
 		} else if errors.Is(err, service.ErrGameFull) || errors.Is(err, service.ErrGameNotWaiting) || errors.Is(err, service.ErrAlreadyJoined) {
-			status = http.StatusBadRequest
+			status = http.StatusConflict
 		}
 		writeError(w, status, err.Error())
 		return
@@ -299,6 +649,32 @@ func (h *GameHandler) JoinGame(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]string{"status": "joined"})
 }
 
+// SpectateGame handles POST /api/v1/games/{id}/spectate. Spectators then
+// subscribe to the game over WebSocket like players do.
+func (h *GameHandler) SpectateGame(w http.ResponseWriter, r *http.Request) {
+	gameID := r.PathValue("id")
+	userID := auth.UserIDFromContext(r.Context())
+
+	game, err := h.gameSvc.SpectateGame(r.Context(), gameID, userID)
+	if err != nil {
+		status := internalErrorStatus(err)
+		if errors.Is(err, service.ErrGameNotFound) {
+			status = http.StatusNotFound
+		} else if errors.Is(err, service.ErrAlreadyJoined) {
+			status = http.StatusBadRequest
+		}
+		writeError(w, status, err.Error())
+		return
+	}
+
+	h.wsHub.BroadcastToGame(gameID, WSEvent{
+		Type:   EventSpectatorJoined,
+		GameID: gameID,
+		Data:   map[string]any{"user_id": userID, "spectators": len(game.Spectators)},
+	})
+	writeJSON(w, http.StatusOK, map[string]string{"status": "spectating"})
+}
+
 // StartGame handles POST /api/v1/games/{id}/start
 func (h *GameHandler) StartGame(w http.ResponseWriter, r *http.Request) {
 	gameID := r.PathValue("id")
@@ -306,7 +682,7 @@ func (h *GameHandler) StartGame(w http.ResponseWriter, r *http.Request) {
 
 	game, err := h.gameSvc.StartGame(r.Context(), gameID, userID)
 	if err != nil {
-		status := http.StatusInternalServerError
+		status := internalErrorStatus(err)
 		if errors.Is(err, service.ErrGameNotFound) {
 			status = http.StatusNotFound
 		} else if errors.Is(err, service.ErrNotCreator) || errors.Is(err, service.ErrNotEnough) || errors.Is(err, service.ErrGameNotWaiting) {
@@ -325,5 +701,5 @@ func (h *GameHandler) StartGame(w http.ResponseWriter, r *http.Request) {
 		}
 	}()
 
-	writeJSON(w, http.StatusOK, game)
+	writeJSON(w, http.StatusOK, service.AnonymizeGame(game, auth.UserIDFromContext(r.Context())))
 }
@@ -3,16 +3,21 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"sort"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/freeeve/polite-betrayal/api/internal/auth"
 	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/internal/repository"
 	"github.com/freeeve/polite-betrayal/api/internal/service"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
 )
 
 // --- Mock Repositories ---
@@ -73,29 +78,64 @@ func (m *mockUserRepo) UpdateDisplayName(_ context.Context, id, displayName stri
 	return nil
 }
 
+func (m *mockUserRepo) UpdateLocale(_ context.Context, id, locale string) error {
+	u, ok := m.users[id]
+	if !ok {
+		return fmt.Errorf("user not found")
+	}
+	u.Locale = locale
+	return nil
+}
+
+func (m *mockUserRepo) UpdateSharePress(_ context.Context, id string, share bool) error {
+	u, ok := m.users[id]
+	if !ok {
+		return fmt.Errorf("user not found")
+	}
+	u.SharePress = share
+	return nil
+}
+
 type mockGameRepo struct {
-	games   map[string]*model.Game
-	players map[string][]model.GamePlayer
+	games       map[string]*model.Game
+	players     map[string][]model.GamePlayer
+	spectators  map[string][]string
+	deletedFrom map[string]string // gameID -> status before soft delete
+
+	difficultyChanges map[string][]model.BotDifficultyChange
+	currentPhaseID    map[string]string // phase recorded against immediate difficulty changes
 }
 
 func newMockGameRepo() *mockGameRepo {
 	return &mockGameRepo{
-		games:   make(map[string]*model.Game),
-		players: make(map[string][]model.GamePlayer),
+		games:       make(map[string]*model.Game),
+		players:     make(map[string][]model.GamePlayer),
+		spectators:  make(map[string][]string),
+		deletedFrom: make(map[string]string),
+
+		difficultyChanges: make(map[string][]model.BotDifficultyChange),
+		currentPhaseID:    make(map[string]string),
 	}
 }
 
-func (m *mockGameRepo) Create(_ context.Context, name, creatorID, turnDur, retreatDur, buildDur, powerAssignment string) (*model.Game, error) {
+func (m *mockGameRepo) Create(_ context.Context, name, creatorID, turnDur, retreatDur, buildDur, powerAssignment, speedPreset, scenario string, rules model.GameRules) (*model.Game, error) {
 	g := &model.Game{
-		ID:              "game-1",
-		Name:            name,
-		CreatorID:       creatorID,
-		Status:          "waiting",
-		TurnDuration:    turnDur,
-		RetreatDuration: retreatDur,
-		BuildDuration:   buildDur,
-		PowerAssignment: powerAssignment,
-		CreatedAt:       time.Now(),
+		ID:                 "game-1",
+		Name:               name,
+		CreatorID:          creatorID,
+		Status:             "waiting",
+		TurnDuration:       turnDur,
+		RetreatDuration:    retreatDur,
+		BuildDuration:      buildDur,
+		PowerAssignment:    powerAssignment,
+		SpeedPreset:        speedPreset,
+		Scenario:           scenario,
+		RetreatCredits:     rules.RetreatCredits,
+		CivilDisorderAfter: rules.CivilDisorderAfter,
+		CivilDisorderBot:   rules.CivilDisorderBot,
+		ReadyQuorum:        rules.ReadyQuorum,
+		QuorumDelay:        rules.QuorumDelay,
+		CreatedAt:          time.Now(),
 	}
 	m.games[g.ID] = g
 	return g, nil
@@ -107,6 +147,7 @@ func (m *mockGameRepo) FindByID(_ context.Context, id string) (*model.Game, erro
 		return nil, nil
 	}
 	g.Players = m.players[id]
+	g.Spectators = m.spectators[id]
 	return g, nil
 }
 
@@ -125,7 +166,7 @@ func (m *mockGameRepo) ListByUser(_ context.Context, userID string) ([]model.Gam
 	for gameID, players := range m.players {
 		for _, p := range players {
 			if p.UserID == userID {
-				if g, ok := m.games[gameID]; ok {
+				if g, ok := m.games[gameID]; ok && g.Status != "deleted" {
 					result = append(result, *g)
 				}
 			}
@@ -135,6 +176,7 @@ func (m *mockGameRepo) ListByUser(_ context.Context, userID string) ([]model.Gam
 }
 
 func (m *mockGameRepo) JoinGame(_ context.Context, gameID, userID string) error {
+	m.spectators[gameID] = slices.DeleteFunc(m.spectators[gameID], func(id string) bool { return id == userID })
 	m.players[gameID] = append(m.players[gameID], model.GamePlayer{
 		GameID:   gameID,
 		UserID:   userID,
@@ -143,6 +185,13 @@ func (m *mockGameRepo) JoinGame(_ context.Context, gameID, userID string) error
 	return nil
 }
 
+func (m *mockGameRepo) Spectate(_ context.Context, gameID, userID string) error {
+	if !slices.Contains(m.spectators[gameID], userID) {
+		m.spectators[gameID] = append(m.spectators[gameID], userID)
+	}
+	return nil
+}
+
 func (m *mockGameRepo) JoinGameAsBot(_ context.Context, gameID, userID, difficulty string) error {
 	if difficulty == "" {
 		difficulty = "easy"
@@ -157,19 +206,27 @@ func (m *mockGameRepo) JoinGameAsBot(_ context.Context, gameID, userID, difficul
 	return nil
 }
 
-func (m *mockGameRepo) ReplaceBot(_ context.Context, gameID, newUserID string) error {
+func (m *mockGameRepo) ClaimSeat(_ context.Context, gameID, userID string, seats int) error {
+	if g, ok := m.games[gameID]; !ok || g.Status != "waiting" {
+		return repository.ErrNotJoinable
+	}
 	players := m.players[gameID]
-	for i, p := range players {
-		if p.IsBot {
-			m.players[gameID] = append(players[:i], append([]model.GamePlayer{{
-				GameID:   gameID,
-				UserID:   newUserID,
-				JoinedAt: time.Now(),
-			}}, players[i+1:]...)...)
-			return nil
+	for _, p := range players {
+		if p.UserID == userID {
+			return repository.ErrAlreadySeated
 		}
 	}
-	return fmt.Errorf("no bot to replace")
+	player := model.GamePlayer{GameID: gameID, UserID: userID, JoinedAt: time.Now()}
+	switch i := slices.IndexFunc(players, func(p model.GamePlayer) bool { return p.IsBot }); {
+	case len(players) < seats:
+		m.players[gameID] = append(players, player)
+	case i >= 0:
+		players[i] = player
+	default:
+		return repository.ErrSeatTaken
+	}
+	m.spectators[gameID] = slices.DeleteFunc(m.spectators[gameID], func(id string) bool { return id == userID })
+	return nil
 }
 
 func (m *mockGameRepo) PlayerCount(_ context.Context, gameID string) (int, error) {
@@ -192,15 +249,19 @@ func (m *mockGameRepo) AssignPowers(_ context.Context, gameID string, assignment
 	return nil
 }
 
-func (m *mockGameRepo) ListActive(_ context.Context) ([]model.Game, error) {
+func (m *mockGameRepo) ListActive(_ context.Context, afterID string, limit int) ([]model.Game, error) {
 	var result []model.Game
 	for _, g := range m.games {
-		if g.Status == "active" {
+		if g.Status == "active" && g.ID > afterID {
 			cp := *g
 			cp.Players = m.players[g.ID]
 			result = append(result, cp)
 		}
 	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	if len(result) > limit {
+		result = result[:limit]
+	}
 	return result, nil
 }
 
@@ -225,6 +286,58 @@ func (m *mockGameRepo) SearchFinished(_ context.Context, search string) ([]model
 	return result, nil
 }
 
+func (m *mockGameRepo) ListActiveByUser(_ context.Context, _ string) ([]model.Game, error) {
+	return nil, nil
+}
+
+func (m *mockGameRepo) CountUserGames(_ context.Context, userID string) (*model.UserStats, error) {
+	return &model.UserStats{UserID: userID}, nil
+}
+
+func (m *mockGameRepo) ListFinishedPage(_ context.Context, search, afterID string, limit int) ([]model.Game, error) {
+	lower := strings.ToLower(search)
+	var result []model.Game
+	for _, g := range m.games {
+		if g.Status == "finished" && g.ID > afterID && strings.Contains(strings.ToLower(g.Name), lower) {
+			result = append(result, *g)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (m *mockGameRepo) RankLive(_ context.Context, activity []model.GameActivity, limit int) ([]model.LiveGame, error) {
+	games := []model.LiveGame{}
+	for _, a := range activity {
+		g, ok := m.games[a.GameID]
+		if !ok || (g.Status != "active" && g.Status != "paused") {
+			continue
+		}
+		botOnly := false
+		for _, p := range m.players[a.GameID] {
+			if !p.IsBot {
+				botOnly = false
+				break
+			}
+			botOnly = true
+		}
+		games = append(games, model.LiveGame{GameActivity: a, Name: g.Name, Status: g.Status, Scenario: g.Scenario, Anonymous: g.Anonymous, BotOnly: botOnly})
+	}
+	sort.SliceStable(games, func(i, j int) bool {
+		if games[i].Viewers != games[j].Viewers {
+			return games[i].Viewers > games[j].Viewers
+		}
+		return games[i].LastActivity.After(games[j].LastActivity)
+	})
+	if len(games) > limit {
+		games = games[:limit]
+	}
+	return games, nil
+}
+
 func (m *mockGameRepo) SetFinished(_ context.Context, gameID, winner string) error {
 	if g, ok := m.games[gameID]; ok {
 		g.Status = "finished"
@@ -239,15 +352,199 @@ func (m *mockGameRepo) Delete(_ context.Context, gameID string) error {
 	return nil
 }
 
-func (m *mockGameRepo) UpdateBotDifficulty(_ context.Context, gameID, botUserID, difficulty string) error {
+func (m *mockGameRepo) SoftDelete(_ context.Context, gameID string) error {
+	if g, ok := m.games[gameID]; ok && g.Status != "deleted" {
+		now := time.Now()
+		m.deletedFrom[gameID] = g.Status
+		g.Status = "deleted"
+		g.DeletedAt = &now
+	}
+	return nil
+}
+
+func (m *mockGameRepo) Restore(_ context.Context, gameID string) error {
+	if g, ok := m.games[gameID]; ok && g.Status == "deleted" {
+		g.Status = m.deletedFrom[gameID]
+		g.DeletedAt = nil
+		delete(m.deletedFrom, gameID)
+	}
+	return nil
+}
+
+func (m *mockGameRepo) PurgeDeleted(_ context.Context, before time.Time) (int, error) {
+	n := 0
+	for id, g := range m.games {
+		if g.Status == "deleted" && g.DeletedAt != nil && g.DeletedAt.Before(before) {
+			delete(m.games, id)
+			delete(m.players, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *mockGameRepo) UpdateBotDifficulty(_ context.Context, gameID, botUserID, difficulty, reason, changedBy string) (*model.BotDifficultyChange, error) {
 	players := m.players[gameID]
 	for i, p := range players {
 		if p.UserID == botUserID && p.IsBot {
 			players[i].BotDifficulty = difficulty
-			return nil
+			m.dropPendingDifficulty(gameID, botUserID)
+			now := time.Now()
+			c := model.BotDifficultyChange{
+				ID: int64(len(m.difficultyChanges[gameID]) + 1), GameID: gameID, UserID: botUserID, Power: p.Power,
+				From: p.BotDifficulty, To: difficulty, Reason: reason, ChangedBy: changedBy,
+				PhaseID: m.currentPhaseID[gameID], RequestedAt: now, AppliedAt: &now,
+			}
+			m.difficultyChanges[gameID] = append(m.difficultyChanges[gameID], c)
+			return &c, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockGameRepo) RequestBotDifficulty(_ context.Context, gameID, botUserID, difficulty, changedBy string) (*model.BotDifficultyChange, error) {
+	for _, p := range m.players[gameID] {
+		if p.UserID == botUserID && p.IsBot {
+			m.dropPendingDifficulty(gameID, botUserID)
+			c := model.BotDifficultyChange{
+				ID: int64(len(m.difficultyChanges[gameID]) + 1), GameID: gameID, UserID: botUserID, Power: p.Power,
+				From: p.BotDifficulty, To: difficulty, Reason: model.BotDifficultyByCreator, ChangedBy: changedBy,
+				RequestedAt: time.Now(),
+			}
+			m.difficultyChanges[gameID] = append(m.difficultyChanges[gameID], c)
+			return &c, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockGameRepo) dropPendingDifficulty(gameID, userID string) {
+	m.difficultyChanges[gameID] = slices.DeleteFunc(m.difficultyChanges[gameID], func(c model.BotDifficultyChange) bool {
+		return c.UserID == userID && c.AppliedAt == nil
+	})
+}
+
+func (m *mockGameRepo) ApplyPendingBotDifficulties(_ context.Context, gameID, phaseID string) ([]model.BotDifficultyChange, error) {
+	var applied []model.BotDifficultyChange
+	changes := m.difficultyChanges[gameID]
+	for i := range changes {
+		c := &changes[i]
+		if c.AppliedAt != nil {
+			continue
+		}
+		players := m.players[gameID]
+		for j := range players {
+			if players[j].UserID == c.UserID {
+				c.From = players[j].BotDifficulty
+				players[j].BotDifficulty = c.To
+			}
+		}
+		now := time.Now()
+		c.AppliedAt, c.PhaseID = &now, phaseID
+		applied = append(applied, *c)
+	}
+	return applied, nil
+}
+
+func (m *mockGameRepo) BotDifficultyChanges(_ context.Context, gameID string) ([]model.BotDifficultyChange, error) {
+	return slices.Clone(m.difficultyChanges[gameID]), nil
+}
+
+func (m *mockGameRepo) UpdateBotPress(_ context.Context, gameID, style string) error {
+	if g, ok := m.games[gameID]; ok {
+		g.BotPress = style
+	}
+	return nil
+}
+
+func (m *mockGameRepo) UpdatePressSettings(_ context.Context, gameID, pressMode string, anonymous bool) error {
+	if g, ok := m.games[gameID]; ok {
+		g.PressMode, g.Anonymous = pressMode, anonymous
+	}
+	return nil
+}
+
+func (m *mockGameRepo) UpdateGarrisons(_ context.Context, gameID string, garrisons bool) error {
+	if g, ok := m.games[gameID]; ok {
+		g.Garrisons = garrisons
+	}
+	return nil
+}
+
+func (m *mockGameRepo) UpdateRetreatCredits(_ context.Context, gameID string, retreatCredits bool) error {
+	if g, ok := m.games[gameID]; ok {
+		g.RetreatCredits = retreatCredits
+	}
+	return nil
+}
+
+func (m *mockGameRepo) UpdateMercyYears(_ context.Context, gameID string, years int) error {
+	if g, ok := m.games[gameID]; ok {
+		g.MercyYears = years
+	}
+	return nil
+}
+
+func (m *mockGameRepo) UpdateReadyQuorum(_ context.Context, gameID string, percent int, delay string) error {
+	if g, ok := m.games[gameID]; ok {
+		g.ReadyQuorum, g.QuorumDelay = percent, delay
+	}
+	return nil
+}
+
+func (m *mockGameRepo) UpdateCivilDisorder(_ context.Context, gameID string, after int, botDifficulty string) error {
+	if g, ok := m.games[gameID]; ok {
+		g.CivilDisorderAfter, g.CivilDisorderBot = after, botDifficulty
+	}
+	return nil
+}
+
+func (m *mockGameRepo) RecordDeadline(_ context.Context, gameID string, missed, acted []string) ([]model.GamePlayer, error) {
+	after := 0
+	if g, ok := m.games[gameID]; ok {
+		after = g.CivilDisorderAfter
+	}
+	var result []model.GamePlayer
+	for i := range m.players[gameID] {
+		p := &m.players[gameID][i]
+		switch {
+		case slices.Contains(acted, p.UserID):
+			p.MissedDeadlines, p.CivilDisorder = 0, false
+		case slices.Contains(missed, p.UserID) && !p.IsBot:
+			p.MissedDeadlines++
+			p.CivilDisorder = p.CivilDisorder || (after > 0 && p.MissedDeadlines >= after)
+			result = append(result, *p)
 		}
 	}
-	return fmt.Errorf("bot not found")
+	return result, nil
+}
+
+func (m *mockGameRepo) ReplaceWithBot(_ context.Context, gameID, userID, botUserID, difficulty string) (*model.GamePlayer, error) {
+	for i := range m.players[gameID] {
+		p := &m.players[gameID][i]
+		if p.UserID == userID && !p.IsBot {
+			*p = model.GamePlayer{GameID: gameID, UserID: botUserID, Power: p.Power, IsBot: true, BotDifficulty: difficulty, JoinedAt: time.Now()}
+			m.spectators[gameID] = append(m.spectators[gameID], userID)
+			cp := *p
+			return &cp, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockGameRepo) PowerAssignments(_ context.Context, _ string) ([]model.PowerAssignment, error) {
+	return nil, nil
+}
+
+func (m *mockGameRepo) SetPaused(_ context.Context, gameID string, paused bool) error {
+	if g, ok := m.games[gameID]; ok {
+		if paused && g.Status == "active" {
+			g.Status = "paused"
+		} else if !paused && g.Status == "paused" {
+			g.Status = "active"
+		}
+	}
+	return nil
 }
 
 func (m *mockGameRepo) UpdatePlayerPower(_ context.Context, gameID, userID, power string) error {
@@ -261,6 +558,27 @@ func (m *mockGameRepo) UpdatePlayerPower(_ context.Context, gameID, userID, powe
 	return fmt.Errorf("player not found")
 }
 
+func (m *mockGameRepo) UpdateBotPersonality(_ context.Context, gameID, botUserID string, personality *model.BotPersonality) error {
+	for i, p := range m.players[gameID] {
+		if p.UserID == botUserID && p.IsBot {
+			m.players[gameID][i].BotPersonality = personality
+		}
+	}
+	return nil
+}
+
+func (m *mockGameRepo) SetEliminated(_ context.Context, gameID, power, by string, year int) error {
+	players := m.players[gameID]
+	for i, p := range players {
+		if p.Power == power {
+			players[i].EliminatedBy = by
+			players[i].EliminatedYear = year
+			return nil
+		}
+	}
+	return fmt.Errorf("player not found")
+}
+
 type mockPhaseRepo struct {
 	phases map[string]*model.Phase
 	orders map[string][]model.Order
@@ -297,6 +615,16 @@ func (m *mockPhaseRepo) CurrentPhase(_ context.Context, gameID string) (*model.P
 	return nil, nil
 }
 
+func (m *mockPhaseRepo) CurrentPhases(ctx context.Context, gameIDs []string) (map[string]model.Phase, error) {
+	result := make(map[string]model.Phase)
+	for _, id := range gameIDs {
+		if p, _ := m.CurrentPhase(ctx, id); p != nil {
+			result[id] = *p
+		}
+	}
+	return result, nil
+}
+
 func (m *mockPhaseRepo) ListPhases(_ context.Context, gameID string) ([]model.Phase, error) {
 	var result []model.Phase
 	for _, p := range m.phases {
@@ -316,6 +644,13 @@ func (m *mockPhaseRepo) ResolvePhase(_ context.Context, phaseID string, stateAft
 	return nil
 }
 
+func (m *mockPhaseRepo) UpdateDeadline(_ context.Context, phaseID string, deadline time.Time) error {
+	if p, ok := m.phases[phaseID]; ok && p.ResolvedAt == nil {
+		p.Deadline = deadline
+	}
+	return nil
+}
+
 func (m *mockPhaseRepo) SaveOrders(_ context.Context, orders []model.Order) error {
 	for _, o := range orders {
 		m.orders[o.PhaseID] = append(m.orders[o.PhaseID], o)
@@ -327,19 +662,60 @@ func (m *mockPhaseRepo) OrdersByPhase(_ context.Context, phaseID string) ([]mode
 	return m.orders[phaseID], nil
 }
 
+func (m *mockPhaseRepo) OpeningTallies(_ context.Context) (int, []model.OpeningTally, error) {
+	return 0, nil, nil
+}
+
 func (m *mockPhaseRepo) ListExpired(_ context.Context) ([]model.Phase, error) {
 	return nil, nil
 }
 
+func (m *mockPhaseRepo) ListDueBefore(_ context.Context, _ time.Time) ([]model.Phase, error) {
+	return nil, nil
+}
+
+func (m *mockPhaseRepo) SaveEvaluations(_ context.Context, _ []model.PhaseEvaluation) error {
+	return nil
+}
+
+func (m *mockPhaseRepo) EvaluationsByGame(_ context.Context, _ string) ([]model.PhaseEvaluation, error) {
+	return nil, nil
+}
+
+func (m *mockPhaseRepo) SaveOrderReviews(_ context.Context, _ []model.OrderReview) error {
+	return nil
+}
+
+func (m *mockPhaseRepo) OrderReviewsByGame(_ context.Context, _ string) ([]model.OrderReview, error) {
+	return nil, nil
+}
+
+func (m *mockPhaseRepo) SaveSubmittedOrders(_ context.Context, _, _ string, _ json.RawMessage) error {
+	return nil
+}
+
+func (m *mockPhaseRepo) ClearSubmission(_ context.Context, _, _ string) error {
+	return nil
+}
+
+func (m *mockPhaseRepo) SetSubmittedReady(_ context.Context, _, _ string, _ bool) error {
+	return nil
+}
+
+func (m *mockPhaseRepo) Submissions(_ context.Context, _ string) ([]model.Submission, error) {
+	return nil, nil
+}
+
 type mockMessageRepo struct {
 	messages []model.Message
+	readAt   map[string]time.Time // gameID/userID -> last read
 }
 
 func newMockMessageRepo() *mockMessageRepo {
 	return &mockMessageRepo{}
 }
 
-func (m *mockMessageRepo) Create(_ context.Context, gameID, senderID, recipientID, content, phaseID string) (*model.Message, error) {
+func (m *mockMessageRepo) Create(_ context.Context, gameID, senderID, recipientID, content, phaseID string, intent *model.MessageIntent) (*model.Message, error) {
 	msg := &model.Message{
 		ID:          fmt.Sprintf("msg-%d", len(m.messages)+1),
 		GameID:      gameID,
@@ -347,16 +723,73 @@ func (m *mockMessageRepo) Create(_ context.Context, gameID, senderID, recipientI
 		RecipientID: recipientID,
 		Content:     content,
 		PhaseID:     phaseID,
+		Intent:      intent,
 		CreatedAt:   time.Now(),
 	}
 	m.messages = append(m.messages, *msg)
 	return msg, nil
 }
 
+func (m *mockMessageRepo) CreateInChannel(ctx context.Context, gameID, senderID, channelID, content, phaseID string, intent *model.MessageIntent) (*model.Message, error) {
+	msg, _ := m.Create(ctx, gameID, senderID, "", content, phaseID, intent)
+	msg.ChannelID = channelID
+	m.messages[len(m.messages)-1].ChannelID = channelID
+	return msg, nil
+}
+
+func (m *mockMessageRepo) FindByID(_ context.Context, id string) (*model.Message, error) {
+	for _, msg := range m.messages {
+		if msg.ID == id {
+			return &msg, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockMessageRepo) MarkRead(_ context.Context, gameID, userID string) error {
+	if m.readAt == nil {
+		m.readAt = make(map[string]time.Time)
+	}
+	m.readAt[gameID+"/"+userID] = time.Now()
+	return nil
+}
+
+func (m *mockMessageRepo) CountUnread(_ context.Context, gameID, userID string) (int, error) {
+	readAt := m.readAt[gameID+"/"+userID]
+	n := 0
+	for _, msg := range m.messages {
+		if msg.GameID == gameID && msg.SenderID != userID && msg.ChannelID == "" && (msg.RecipientID == "" || msg.RecipientID == userID) &&
+			msg.CreatedAt.After(readAt) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *mockMessageRepo) CountUnreadByGame(ctx context.Context, gameIDs []string, userID string) (map[string]int, error) {
+	result := make(map[string]int)
+	for _, id := range gameIDs {
+		if n, _ := m.CountUnread(ctx, id, userID); n > 0 {
+			result[id] = n
+		}
+	}
+	return result, nil
+}
+
 func (m *mockMessageRepo) ListByGame(_ context.Context, gameID, userID string) ([]model.Message, error) {
 	var result []model.Message
 	for _, msg := range m.messages {
-		if msg.GameID == gameID && (msg.RecipientID == "" || msg.SenderID == userID || msg.RecipientID == userID) {
+		if msg.GameID == gameID && ((msg.RecipientID == "" && msg.ChannelID == "") || msg.SenderID == userID || msg.RecipientID == userID) {
+			result = append(result, msg)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockMessageRepo) ListAllByGame(_ context.Context, gameID string) ([]model.Message, error) {
+	var result []model.Message
+	for _, msg := range m.messages {
+		if msg.GameID == gameID {
 			result = append(result, msg)
 		}
 	}
@@ -450,6 +883,29 @@ func TestUpdateMeEmptyName(t *testing.T) {
 	}
 }
 
+func TestUpdateMeLocale(t *testing.T) {
+	repo := newMockUserRepo()
+	repo.users["user-1"] = &model.User{ID: "user-1", DisplayName: "Alice", Locale: "en"}
+	h := NewUserHandler(repo)
+
+	req := reqWithUserID(http.MethodPatch, "/users/me", `{"locale":"de-DE"}`, "user-1")
+	rec := httptest.NewRecorder()
+	h.UpdateMe(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if repo.users["user-1"].Locale != "de" || repo.users["user-1"].DisplayName != "Alice" {
+		t.Errorf("expected locale de and unchanged name, got %+v", repo.users["user-1"])
+	}
+
+	req = reqWithUserID(http.MethodPatch, "/users/me", `{"locale":"tlh"}`, "user-1")
+	rec = httptest.NewRecorder()
+	h.UpdateMe(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for unsupported locale, got %d", rec.Code)
+	}
+}
+
 func TestUpdateMeInvalidJSON(t *testing.T) {
 	repo := newMockUserRepo()
 	h := NewUserHandler(repo)
@@ -519,6 +975,39 @@ func TestListGamesEmpty(t *testing.T) {
 	}
 }
 
+func TestListBotStrategies(t *testing.T) {
+	h := NewGameHandler(nil, nil, NewHub())
+
+	req := reqWithUserID(http.MethodGet, "/bot-strategies", "", "user-1")
+	rec := httptest.NewRecorder()
+	h.ListBotStrategies(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var strategies []struct {
+		Name         string `json:"name"`
+		Capabilities struct {
+			Diplomacy bool `json:"diplomacy"`
+		} `json:"capabilities"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &strategies); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	found := false
+	for _, s := range strategies {
+		if s.Name == "hard" {
+			found = true
+			if !s.Capabilities.Diplomacy {
+				t.Error("expected hard to advertise diplomacy")
+			}
+		}
+	}
+	if !found {
+		t.Errorf("expected hard in %s", rec.Body.String())
+	}
+}
+
 func TestGetGameNotFound(t *testing.T) {
 	gameRepo := newMockGameRepo()
 	phaseRepo := newMockPhaseRepo()
@@ -535,6 +1024,43 @@ func TestGetGameNotFound(t *testing.T) {
 	}
 }
 
+func TestDeleteAndRestoreGame(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	gameSvc := service.NewGameService(gameRepo, phaseRepo, newMockUserRepo())
+	h := NewGameHandler(gameSvc, nil, NewHub())
+	game, _ := gameSvc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "", "", false, model.GameRules{})
+
+	req := reqWithUserID(http.MethodDelete, "/games/"+game.ID, "", "user-1")
+	req.SetPathValue("id", game.ID)
+	rec := httptest.NewRecorder()
+	h.DeleteGame(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("delete: expected 200, got %d", rec.Code)
+	}
+
+	req = reqWithUserID(http.MethodPost, "/games/"+game.ID+"/restore", "", "user-2")
+	req.SetPathValue("id", game.ID)
+	rec = httptest.NewRecorder()
+	h.RestoreGame(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("restore by non-creator: expected 403, got %d", rec.Code)
+	}
+
+	req = reqWithUserID(http.MethodPost, "/games/"+game.ID+"/restore", "", "user-1")
+	req.SetPathValue("id", game.ID)
+	rec = httptest.NewRecorder()
+	h.RestoreGame(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("restore: expected 200, got %d", rec.Code)
+	}
+	var restored model.Game
+	json.NewDecoder(rec.Body).Decode(&restored)
+	if restored.Status != "waiting" {
+		t.Errorf("expected waiting after restore, got %s", restored.Status)
+	}
+}
+
 func TestJoinGameNotFound(t *testing.T) {
 	gameRepo := newMockGameRepo()
 	phaseRepo := newMockPhaseRepo()
@@ -551,6 +1077,212 @@ func TestJoinGameNotFound(t *testing.T) {
 	}
 }
 
+func TestJoinGameConflict(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	gameSvc := service.NewGameService(gameRepo, phaseRepo, newMockUserRepo())
+	h := NewGameHandler(gameSvc, nil, NewHub())
+	game, _ := gameSvc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "", "", false, model.GameRules{})
+
+	req := reqWithUserID(http.MethodPost, "/games/"+game.ID+"/join", "", "user-1")
+	req.SetPathValue("id", game.ID)
+	rec := httptest.NewRecorder()
+	h.JoinGame(rec, req)
+
+	if rec.Code != http.StatusConflict {
+		t.Errorf("expected 409 for a duplicate join, got %d", rec.Code)
+	}
+}
+
+func TestUpdateBotPersonality(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	gameSvc := service.NewGameService(gameRepo, phaseRepo, newMockUserRepo())
+	h := NewGameHandler(gameSvc, nil, NewHub())
+	game, _ := gameSvc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "", "", false, model.GameRules{})
+	gameRepo.JoinGameAsBot(context.Background(), game.ID, "bot-1", "hard")
+
+	patch := func(body string) *httptest.ResponseRecorder {
+		req := reqWithUserID(http.MethodPatch, "/games/"+game.ID+"/players/bot-1/bot-personality", body, "user-1")
+		req.SetPathValue("id", game.ID)
+		req.SetPathValue("userId", "bot-1")
+		rec := httptest.NewRecorder()
+		h.UpdateBotPersonality(rec, req)
+		return rec
+	}
+
+	rec := patch(`{"preset":"aggressive","loyalty":-0.5}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Player model.GamePlayer `json:"player"`
+	}
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if p := resp.Player.BotPersonality; p == nil || p.Aggression != 0.8 || p.Loyalty != -0.5 {
+		t.Errorf("expected aggressive traits with loyalty overridden, got %+v", p)
+	}
+
+	if rec := patch(`{"preset":"grumpy"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown preset: expected 400, got %d", rec.Code)
+	}
+	if rec := patch(`{"talkativeness":3}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("out of range trait: expected 400, got %d", rec.Code)
+	}
+	rec = patch(`{}`)
+	resp.Player = model.GamePlayer{}
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if rec.Code != http.StatusOK || resp.Player.BotPersonality != nil {
+		t.Errorf("empty body: expected the personality cleared, got %d %+v", rec.Code, resp.Player.BotPersonality)
+	}
+}
+
+func TestSpectateGameBlocksPress(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	gameSvc := service.NewGameService(gameRepo, phaseRepo, newMockUserRepo())
+	h := NewGameHandler(gameSvc, nil, NewHub())
+	game, _ := gameSvc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "", "", false, model.GameRules{})
+
+	req := reqWithUserID(http.MethodPost, "/games/"+game.ID+"/spectate", "", "user-2")
+	req.SetPathValue("id", game.ID)
+	rec := httptest.NewRecorder()
+	h.SpectateGame(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("spectate: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	req = reqWithUserID(http.MethodPost, "/games/"+game.ID+"/spectate", "", "user-1")
+	req.SetPathValue("id", game.ID)
+	rec = httptest.NewRecorder()
+	h.SpectateGame(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("spectate as player: expected 400, got %d", rec.Code)
+	}
+
+	mh := NewMessageHandler(newMockMessageRepo(), phaseRepo, NewHub())
+	mh.SetGameRepo(gameRepo)
+	req = reqWithUserID(http.MethodPost, "/games/"+game.ID+"/messages", `{"content":"Hi"}`, "user-2")
+	req.SetPathValue("id", game.ID)
+	rec = httptest.NewRecorder()
+	mh.SendMessage(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("spectator press: expected 403, got %d", rec.Code)
+	}
+}
+
+// failingGameRepo is a game repository whose lookups fail.
+type failingGameRepo struct{ *mockGameRepo }
+
+func (failingGameRepo) FindByID(context.Context, string) (*model.Game, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestMessagesFailClosedOnGameLookupError(t *testing.T) {
+	mh := NewMessageHandler(newMockMessageRepo(), newMockPhaseRepo(), NewHub())
+	mh.SetGameRepo(failingGameRepo{newMockGameRepo()})
+
+	req := reqWithUserID(http.MethodPost, "/games/g1/messages", `{"content":"Hi"}`, "user-2")
+	req.SetPathValue("id", "g1")
+	rec := httptest.NewRecorder()
+	mh.SendMessage(rec, req)
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("send: expected 500, got %d", rec.Code)
+	}
+
+	req = reqWithUserID(http.MethodGet, "/games/g1/messages", "", "user-2")
+	req.SetPathValue("id", "g1")
+	rec = httptest.NewRecorder()
+	mh.ListMessages(rec, req)
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("list: expected 500, got %d", rec.Code)
+	}
+}
+
+func TestPressModeAndAnonymity(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	msgRepo := newMockMessageRepo()
+	gameSvc := service.NewGameService(gameRepo, phaseRepo, newMockUserRepo())
+	h := NewGameHandler(gameSvc, nil, NewHub())
+
+	req := reqWithUserID(http.MethodPost, "/games", `{"name":"Gunboat","press_mode":"whisper"}`, "user-1")
+	rec := httptest.NewRecorder()
+	h.CreateGame(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown press mode: expected 400, got %d", rec.Code)
+	}
+	req = reqWithUserID(http.MethodPost, "/games", `{"name":"Gunboat","press_mode":"public_only","anonymous":true}`, "user-1")
+	rec = httptest.NewRecorder()
+	h.CreateGame(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var game model.Game
+	json.Unmarshal(rec.Body.Bytes(), &game)
+	if game.PressMode != model.PressPublicOnly || !game.Anonymous {
+		t.Fatalf("expected public-only anonymous game, got %q %v", game.PressMode, game.Anonymous)
+	}
+
+	assignments := make(map[string]string)
+	for i, p := range gameRepo.players[game.ID] {
+		assignments[p.UserID] = string(diplomacy.AllPowers()[i])
+	}
+	gameRepo.AssignPowers(context.Background(), game.ID, assignments)
+	gameRepo.games[game.ID].Status = "paused"
+	other := gameRepo.players[game.ID][1]
+
+	req = reqWithUserID(http.MethodGet, "/games/"+game.ID, "", "user-1")
+	req.SetPathValue("id", game.ID)
+	rec = httptest.NewRecorder()
+	h.GetGame(rec, req)
+	json.Unmarshal(rec.Body.Bytes(), &game)
+	for _, p := range game.Players {
+		want := service.AnonymousID(p.Power)
+		if assignments["user-1"] == p.Power {
+			want = "user-1"
+		}
+		if p.UserID != want || p.IsBot {
+			t.Errorf("%s: expected user %q shown as a person, got %q (bot %v)", p.Power, want, p.UserID, p.IsBot)
+		}
+	}
+
+	mh := NewMessageHandler(msgRepo, phaseRepo, NewHub())
+	mh.SetGameRepo(gameRepo)
+	send := func(body string) *httptest.ResponseRecorder {
+		req := reqWithUserID(http.MethodPost, "/games/"+game.ID+"/messages", body, "user-1")
+		req.SetPathValue("id", game.ID)
+		rec := httptest.NewRecorder()
+		mh.SendMessage(rec, req)
+		return rec
+	}
+	if rec := send(`{"content":"Psst","recipient_id":"` + service.AnonymousID(other.Power) + `"}`); rec.Code != http.StatusForbidden {
+		t.Errorf("private press in public-only game: expected 403, got %d", rec.Code)
+	}
+	if rec := send(`{"content":"Hello all"}`); rec.Code != http.StatusCreated {
+		t.Errorf("public press: expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	gameRepo.UpdatePressSettings(context.Background(), game.ID, model.PressFull, true)
+	rec = send(`{"content":"Psst","recipient_id":"` + service.AnonymousID(other.Power) + `"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("private press: expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if last := msgRepo.messages[len(msgRepo.messages)-1]; last.RecipientID != other.UserID {
+		t.Errorf("expected the anonymous recipient resolved to %s, got %s", other.UserID, last.RecipientID)
+	}
+	var msg model.Message
+	json.Unmarshal(rec.Body.Bytes(), &msg)
+	if msg.SenderID != "user-1" || msg.RecipientID != service.AnonymousID(other.Power) {
+		t.Errorf("expected the response to hide only the recipient, got %+v", msg)
+	}
+
+	gameRepo.UpdatePressSettings(context.Background(), game.ID, model.PressNone, true)
+	if rec := send(`{"content":"Hello all"}`); rec.Code != http.StatusForbidden {
+		t.Errorf("press in gunboat game: expected 403, got %d", rec.Code)
+	}
+}
+
 // --- Message Handler Tests ---
 
 func TestSendAndListMessages(t *testing.T) {
@@ -585,6 +1317,49 @@ func TestSendAndListMessages(t *testing.T) {
 	if messages[0].Content != "Hello everyone!" {
 		t.Errorf("expected 'Hello everyone!', got %s", messages[0].Content)
 	}
+	if _, ok := msgRepo.readAt["game-1/user-1"]; !ok {
+		t.Error("expected listing messages to mark them read")
+	}
+}
+
+func TestSendCannedMessageInRecipientLocale(t *testing.T) {
+	msgRepo := newMockMessageRepo()
+	phaseRepo := newMockPhaseRepo()
+	userRepo := newMockUserRepo()
+	userRepo.users["user-2"] = &model.User{ID: "user-2", Locale: "fr"}
+	h := NewMessageHandler(msgRepo, phaseRepo, NewHub())
+	h.SetUserRepo(userRepo)
+
+	body := `{"recipient_id":"user-2","content":"Deal: I take bel, you take hol"}`
+	req := reqWithUserID(http.MethodPost, "/games/game-1/messages", body, "user-1")
+	req.SetPathValue("id", "game-1")
+	rec := httptest.NewRecorder()
+	h.SendMessage(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var msg model.Message
+	json.Unmarshal(rec.Body.Bytes(), &msg)
+	if msg.Content != "Marché : je prends bel, tu prends hol" {
+		t.Errorf("expected French rendering, got %q", msg.Content)
+	}
+	if msg.Intent == nil || msg.Intent.Type != "offer_deal" || len(msg.Intent.Provinces) != 2 {
+		t.Errorf("expected offer_deal intent attachment, got %+v", msg.Intent)
+	}
+}
+
+func TestSendMessageInvalidIntent(t *testing.T) {
+	h := NewMessageHandler(newMockMessageRepo(), newMockPhaseRepo(), NewHub())
+
+	req := reqWithUserID(http.MethodPost, "/games/game-1/messages", `{"intent":{"type":"bribe"}}`, "user-1")
+	req.SetPathValue("id", "game-1")
+	rec := httptest.NewRecorder()
+	h.SendMessage(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
 }
 
 func TestSendMessageEmptyContent(t *testing.T) {
@@ -641,6 +1416,24 @@ func TestListPhasesEmpty(t *testing.T) {
 	}
 }
 
+func TestServerTime(t *testing.T) {
+	before := time.Now().UnixMilli()
+	rec := httptest.NewRecorder()
+	ServerTime(rec, httptest.NewRequest(http.MethodGet, "/time", nil))
+
+	var resp struct {
+		ServerTime   time.Time `json:"server_time"`
+		ServerTimeMs int64     `json:"server_time_ms"`
+	}
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if resp.ServerTimeMs < before || resp.ServerTimeMs > time.Now().UnixMilli() {
+		t.Errorf("expected the current time, got %d", resp.ServerTimeMs)
+	}
+	if resp.ServerTime.UnixMilli() != resp.ServerTimeMs {
+		t.Errorf("expected matching timestamps, got %v and %d", resp.ServerTime, resp.ServerTimeMs)
+	}
+}
+
 func TestCurrentPhaseNotFound(t *testing.T) {
 	phaseRepo := newMockPhaseRepo()
 	h := NewPhaseHandler(phaseRepo)
@@ -655,6 +1448,69 @@ func TestCurrentPhaseNotFound(t *testing.T) {
 	}
 }
 
+func TestCurrentPhaseCivilDisorder(t *testing.T) {
+	phaseRepo := newMockPhaseRepo()
+	gs := diplomacy.GameState{
+		Year:   1901,
+		Season: diplomacy.Fall,
+		Phase:  diplomacy.PhaseBuild,
+		Units: []diplomacy.Unit{
+			{Type: diplomacy.Army, Power: diplomacy.France, Province: "par"},
+			{Type: diplomacy.Army, Power: diplomacy.France, Province: "por"},
+			{Type: diplomacy.Army, Power: diplomacy.Germany, Province: "mun"},
+		},
+		SupplyCenters: map[string]diplomacy.Power{"par": diplomacy.France, "mun": diplomacy.Germany},
+	}
+	state, _ := json.Marshal(gs)
+	phaseRepo.CreatePhase(context.Background(), "game-1", 1901, "fall", "build", state, time.Now().Add(time.Hour))
+	h := NewPhaseHandler(phaseRepo)
+
+	req := reqWithUserID(http.MethodGet, "/games/game-1/phases/current", "", "user-1")
+	req.SetPathValue("id", "game-1")
+	rec := httptest.NewRecorder()
+	h.CurrentPhase(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		ID            string `json:"id"`
+		DeadlineEpoch int64  `json:"deadline_epoch_ms"`
+		ServerTime    int64  `json:"server_time_ms"`
+		BuildPreview  map[string]struct {
+			SupplyCenters int `json:"supply_centers"`
+			Units         int `json:"units"`
+			Disbands      int `json:"disbands"`
+		} `json:"build_preview"`
+		CivilDisorder map[string]struct {
+			Disbands int `json:"disbands"`
+			Ranked   []struct {
+				Location string `json:"location"`
+			} `json:"ranked"`
+		} `json:"civil_disorder"`
+	}
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if resp.ID != "phase-1" {
+		t.Errorf("expected phase fields in response, got id %q", resp.ID)
+	}
+	if left := resp.DeadlineEpoch - resp.ServerTime; left <= 0 || left > time.Hour.Milliseconds() {
+		t.Errorf("expected the deadline within the hour of server time, got %dms", left)
+	}
+	france, ok := resp.CivilDisorder["france"]
+	if !ok || len(resp.CivilDisorder) != 1 {
+		t.Fatalf("expected civil disorder defaults for france only, got %+v", resp.CivilDisorder)
+	}
+	if france.Disbands != 1 || len(france.Ranked) != 2 || france.Ranked[0].Location != "por" {
+		t.Errorf("expected por ranked first of 2 with 1 disband, got %+v", france)
+	}
+	if p := resp.BuildPreview["france"]; p.SupplyCenters != 1 || p.Units != 2 || p.Disbands != 1 {
+		t.Errorf("france build preview = %+v, want 1 SC, 2 units, 1 disband", p)
+	}
+	if p, ok := resp.BuildPreview["germany"]; !ok || p.Disbands != 0 || len(resp.BuildPreview) != 2 {
+		t.Errorf("build preview = %+v, want france and germany", resp.BuildPreview)
+	}
+}
+
 // --- Auth Handler Tests ---
 
 func TestRefreshTokenValid(t *testing.T) {
@@ -705,3 +1561,61 @@ func TestRefreshTokenBadBody(t *testing.T) {
 		t.Errorf("expected 400, got %d", rec.Code)
 	}
 }
+
+// --- Order Handler Tests ---
+
+func TestMarkReadyManyRejectsBadLists(t *testing.T) {
+	h := NewOrderHandler(nil, nil, NewHub())
+	many := `{"game_ids":["g"` + strings.Repeat(`,"g"`, maxBulkReadyGames) + `]}`
+	for _, body := range []string{`{"game_ids":[]}`, many, "not json"} {
+		rec := httptest.NewRecorder()
+		h.MarkReadyMany(rec, reqWithUserID(http.MethodPost, "/games/ready", body, "user-1"))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%.20s...: expected 400, got %d", body, rec.Code)
+		}
+	}
+}
+
+func TestGetEvaluationRejectsUnknownPhases(t *testing.T) {
+	h := NewAnalysisHandler(nil)
+	rec := httptest.NewRecorder()
+	h.GetEvaluation(rec, reqWithUserID(http.MethodGet, "/games/g/evaluation?phases=some", "", "user-1"))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
+
+func TestCreateGameWithHouseRules(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	gameSvc := service.NewGameService(gameRepo, newMockPhaseRepo(), newMockUserRepo())
+	h := NewGameHandler(gameSvc, nil, NewHub())
+
+	req := reqWithUserID(http.MethodPost, "/games", `{"name":"Credits","retreat_credits":true,"civil_disorder_after":2,"civil_disorder_bot":"medium"}`, "user-1")
+	rec := httptest.NewRecorder()
+	h.CreateGame(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var game model.Game
+	json.Unmarshal(rec.Body.Bytes(), &game)
+	stored := gameRepo.games[game.ID]
+	if !stored.RetreatCredits || stored.CivilDisorderAfter != 2 || stored.CivilDisorderBot != "medium" {
+		t.Errorf("expected the game created with its house rules, got %+v", stored)
+	}
+
+	req = reqWithUserID(http.MethodPost, "/games", `{"name":"Defaults"}`, "user-1")
+	rec = httptest.NewRecorder()
+	h.CreateGame(rec, req)
+	var defaults model.Game
+	json.Unmarshal(rec.Body.Bytes(), &defaults)
+	if defaults.CivilDisorderAfter != service.DefaultCivilDisorderAfter || defaults.RetreatCredits {
+		t.Errorf("expected default house rules, got %+v", defaults)
+	}
+
+	req = reqWithUserID(http.MethodPost, "/games", `{"name":"Mercy","mercy_years":2,"bot_difficulty":"expert"}`, "user-1")
+	rec = httptest.NewRecorder()
+	h.CreateGame(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("mercy with expert bots: expected 400, got %d", rec.Code)
+	}
+}
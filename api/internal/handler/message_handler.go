@@ -1,16 +1,27 @@
 package handler
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"slices"
+
+	"github.com/rs/zerolog/log"
 
 	"github.com/freeeve/polite-betrayal/api/internal/auth"
+	"github.com/freeeve/polite-betrayal/api/internal/bot"
+	"github.com/freeeve/polite-betrayal/api/internal/model"
 	"github.com/freeeve/polite-betrayal/api/internal/repository"
+	"github.com/freeeve/polite-betrayal/api/internal/service"
 )
 
 // MessageHandler handles in-game messaging endpoints.
 type MessageHandler struct {
 	messageRepo repository.MessageRepository
 	phaseRepo   repository.PhaseRepository
+	userRepo    repository.UserRepository // optional: renders canned press in the recipient's locale
+	gameRepo    repository.GameRepository // optional: enforces press modes, anonymity and keeps spectators from sending press
+	channelSvc  *service.ChannelService   // optional: enables press channels
 	hub         *Hub
 }
 
@@ -19,15 +30,42 @@ func NewMessageHandler(messageRepo repository.MessageRepository, phaseRepo repos
 	return &MessageHandler{messageRepo: messageRepo, phaseRepo: phaseRepo, hub: hub}
 }
 
+// SetUserRepo configures the optional user repository used to look up recipient locales.
+func (h *MessageHandler) SetUserRepo(repo repository.UserRepository) {
+	h.userRepo = repo
+}
+
+// SetGameRepo configures the optional game repository used to enforce the
+// game's press mode and anonymity and to reject press from spectators.
+func (h *MessageHandler) SetGameRepo(repo repository.GameRepository) {
+	h.gameRepo = repo
+}
+
+// SetChannelService enables sending messages to press channels.
+func (h *MessageHandler) SetChannelService(svc *service.ChannelService) {
+	h.channelSvc = svc
+}
+
 // ListMessages handles GET /api/v1/games/{id}/messages
 func (h *MessageHandler) ListMessages(w http.ResponseWriter, r *http.Request) {
 	gameID := r.PathValue("id")
 	userID := auth.UserIDFromContext(r.Context())
 	messages, err := h.messageRepo.ListByGame(r.Context(), gameID, userID)
+	if err != nil {
+		writeError(w, internalErrorStatus(err), err.Error())
+		return
+	}
+	game, err := h.game(r.Context(), gameID)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	if game != nil {
+		messages = service.AnonymizeMessages(game, messages, userID)
+	}
+	if err := h.messageRepo.MarkRead(r.Context(), gameID, userID); err != nil {
+		log.Warn().Err(err).Str("gameId", gameID).Str("userId", userID).Msg("Failed to mark messages read")
+	}
 	if messages == nil {
 		writeJSON(w, http.StatusOK, []struct{}{})
 		return
@@ -41,18 +79,75 @@ func (h *MessageHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
 	userID := auth.UserIDFromContext(r.Context())
 
 	var req struct {
-		RecipientID string `json:"recipient_id,omitempty"`
-		Content     string `json:"content"`
+		RecipientID string               `json:"recipient_id,omitempty"`
+		ChannelID   string               `json:"channel_id,omitempty"`
+		Content     string               `json:"content"`
+		Intent      *model.MessageIntent `json:"intent,omitempty"`
 	}
 	if err := decodeJSON(r, &req); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
-	if req.Content == "" {
+	if req.Content == "" && req.Intent == nil {
 		writeError(w, http.StatusBadRequest, "content is required")
 		return
 	}
 
+	game, err := h.game(r.Context(), gameID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if game != nil {
+		if slices.Contains(game.Spectators, userID) {
+			writeError(w, http.StatusForbidden, "spectators cannot send messages")
+			return
+		}
+		if !service.AllowsPress(game, req.RecipientID != "" || req.ChannelID != "") {
+			writeError(w, http.StatusForbidden, service.ErrPressNotAllowed.Error())
+			return
+		}
+		req.RecipientID = service.ResolveUserID(game, req.RecipientID)
+	}
+	var channel *model.Channel
+	if req.ChannelID != "" {
+		if req.RecipientID != "" {
+			writeError(w, http.StatusBadRequest, "set recipient_id or channel_id, not both")
+			return
+		}
+		if h.channelSvc == nil {
+			writeError(w, http.StatusBadRequest, "press channels are not enabled")
+			return
+		}
+		if channel, err = h.channelSvc.Member(r.Context(), gameID, req.ChannelID, userID); err != nil {
+			writeChannelError(w, err)
+			return
+		}
+	}
+
+	// Canned press carries a machine-readable intent. Private canned messages
+	// are rendered in the recipient's locale; clients can re-render from the intent.
+	var intent *bot.DiplomaticIntent
+	if req.Intent != nil {
+		if intent, err = bot.IntentFromAttachment(req.Intent); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	} else if parsed, err := bot.ParseCannedMessage(req.Content); err == nil {
+		intent = parsed
+	}
+	var attachment *model.MessageIntent
+	if intent != nil {
+		attachment = bot.IntentAttachment(*intent)
+		locale := bot.DefaultLocale
+		if req.RecipientID != "" {
+			locale = h.recipientLocale(r.Context(), req.RecipientID)
+		}
+		if req.Content == "" || req.RecipientID != "" {
+			req.Content = bot.FormatCannedMessageLocale(*intent, locale)
+		}
+	}
+
 	// Get current phase ID for message context
 	phaseID := ""
 	phase, err := h.phaseRepo.CurrentPhase(r.Context(), gameID)
@@ -60,20 +155,63 @@ func (h *MessageHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
 		phaseID = phase.ID
 	}
 
-	msg, err := h.messageRepo.Create(r.Context(), gameID, userID, req.RecipientID, req.Content, phaseID)
+	var msg *model.Message
+	if channel != nil {
+		msg, err = h.messageRepo.CreateInChannel(r.Context(), gameID, userID, channel.ID, req.Content, phaseID, attachment)
+	} else {
+		msg, err = h.messageRepo.Create(r.Context(), gameID, userID, req.RecipientID, req.Content, phaseID, attachment)
+	}
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeError(w, internalErrorStatus(err), err.Error())
 		return
 	}
 
-	// Broadcast: private messages go to recipient only, public to the game
-	event := WSEvent{Type: EventMessage, GameID: gameID, Data: msg}
-	if req.RecipientID != "" {
-		h.hub.BroadcastToUser(req.RecipientID, event)
-		h.hub.BroadcastToUser(userID, event) // also to sender
-	} else {
-		h.hub.BroadcastToGame(gameID, event)
+	// Broadcast: private messages go to recipient only, channel messages to
+	// the members, public to the game. Anonymous games show each viewer
+	// the others by power.
+	viewedBy := func(viewerID string) *model.Message {
+		if game == nil {
+			return msg
+		}
+		return &service.AnonymizeMessages(game, []model.Message{*msg}, viewerID)[0]
+	}
+	switch {
+	case channel != nil:
+		for _, memberID := range channel.Members {
+			h.hub.BroadcastToUser(memberID, WSEvent{Type: EventMessage, GameID: gameID, Data: viewedBy(memberID)})
+		}
+	case req.RecipientID != "":
+		h.hub.BroadcastToUser(req.RecipientID, WSEvent{Type: EventMessage, GameID: gameID, Data: viewedBy(req.RecipientID)})
+		h.hub.BroadcastToUser(userID, WSEvent{Type: EventMessage, GameID: gameID, Data: viewedBy(userID)}) // also to sender
+	default:
+		h.hub.BroadcastToGame(gameID, WSEvent{Type: EventMessage, GameID: gameID, Data: viewedBy("")})
 	}
 
-	writeJSON(w, http.StatusCreated, msg)
+	writeJSON(w, http.StatusCreated, viewedBy(userID))
+}
+
+// game returns the game, or nil without a game repository. Spectators only
+// see public press, which ListByGame already limits them to. A failed lookup
+// is an error rather than nil, so it can't skip the press and anonymity checks.
+func (h *MessageHandler) game(ctx context.Context, gameID string) (*model.Game, error) {
+	if h.gameRepo == nil {
+		return nil, nil
+	}
+	game, err := h.gameRepo.FindByID(ctx, gameID)
+	if err != nil {
+		return nil, fmt.Errorf("load game: %w", err)
+	}
+	return game, nil
+}
+
+// recipientLocale returns the press locale of a user, defaulting to English.
+func (h *MessageHandler) recipientLocale(ctx context.Context, userID string) string {
+	if h.userRepo == nil {
+		return bot.DefaultLocale
+	}
+	u, err := h.userRepo.FindByID(ctx, userID)
+	if err != nil || u == nil || u.Locale == "" {
+		return bot.DefaultLocale
+	}
+	return u.Locale
 }
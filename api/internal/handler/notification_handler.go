@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/freeeve/polite-betrayal/api/internal/auth"
+	"github.com/freeeve/polite-betrayal/api/internal/service"
+)
+
+// NotificationHandler lets users manage where their game notifications are
+// sent and see how recent ones were delivered.
+type NotificationHandler struct {
+	notifSvc *service.NotificationService
+}
+
+// NewNotificationHandler creates a NotificationHandler.
+func NewNotificationHandler(notifSvc *service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{notifSvc: notifSvc}
+}
+
+// writeNotificationError maps notification errors to HTTP statuses.
+func writeNotificationError(w http.ResponseWriter, err error) {
+	status := internalErrorStatus(err)
+	switch {
+	case errors.Is(err, service.ErrNotificationKind), errors.Is(err, service.ErrNotificationTarget),
+		errors.Is(err, service.ErrNotificationEvent), errors.Is(err, service.ErrNotificationDisabled):
+		status = http.StatusBadRequest
+	case errors.Is(err, service.ErrTooManyChannels), errors.Is(err, service.ErrChannelConfirmed):
+		status = http.StatusConflict
+	case errors.Is(err, service.ErrConfirmationInvalid):
+		status = http.StatusNotFound
+	case errors.Is(err, service.ErrNotificationChannelNotFound):
+		status = http.StatusNotFound
+	}
+	writeError(w, status, err.Error())
+}
+
+// ListChannels handles GET /api/v1/users/me/notification-channels
+func (h *NotificationHandler) ListChannels(w http.ResponseWriter, r *http.Request) {
+	channels, err := h.notifSvc.ListChannels(r.Context(), auth.UserIDFromContext(r.Context()))
+	if err != nil {
+		writeNotificationError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, channels)
+}
+
+// CreateChannel handles POST /api/v1/users/me/notification-channels. The
+// body gives the kind (webhook, discord or email), its target URL or
+// address, and the events to send; none means all of them. Email channels
+// are created unconfirmed and mailed a confirmation link.
+func (h *NotificationHandler) CreateChannel(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Kind   string   `json:"kind"`
+		Target string   `json:"target"`
+		Events []string `json:"events"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	ch, err := h.notifSvc.CreateChannel(r.Context(), auth.UserIDFromContext(r.Context()), req.Kind, req.Target, req.Events)
+	if err != nil {
+		writeNotificationError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, ch)
+}
+
+// UpdateChannel handles PATCH /api/v1/users/me/notification-channels/{id},
+// changing its events or enabling and disabling it.
+func (h *NotificationHandler) UpdateChannel(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Events  []string `json:"events"`
+		Enabled *bool    `json:"enabled"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	ch, err := h.notifSvc.UpdateChannel(r.Context(), auth.UserIDFromContext(r.Context()), r.PathValue("id"), req.Events, req.Enabled)
+	if err != nil {
+		writeNotificationError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, ch)
+}
+
+// ResendConfirmation handles POST
+// /api/v1/users/me/notification-channels/{id}/confirmation, mailing an
+// unconfirmed email channel a new confirmation link.
+func (h *NotificationHandler) ResendConfirmation(w http.ResponseWriter, r *http.Request) {
+	if err := h.notifSvc.ResendConfirmation(r.Context(), auth.UserIDFromContext(r.Context()), r.PathValue("id")); err != nil {
+		writeNotificationError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ConfirmChannel handles GET /notifications/confirm?token=..., the link
+// mailed to a new email channel. It needs no login: the token is the proof.
+func (h *NotificationHandler) ConfirmChannel(w http.ResponseWriter, r *http.Request) {
+	ch, err := h.notifSvc.ConfirmChannel(r.Context(), r.URL.Query().Get("token"))
+	if err != nil {
+		writeNotificationError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, ch)
+}
+
+// DeleteChannel handles DELETE /api/v1/users/me/notification-channels/{id}
+func (h *NotificationHandler) DeleteChannel(w http.ResponseWriter, r *http.Request) {
+	if err := h.notifSvc.DeleteChannel(r.Context(), auth.UserIDFromContext(r.Context()), r.PathValue("id")); err != nil {
+		writeNotificationError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListNotifications handles GET /api/v1/users/me/notifications?limit=N
+func (h *NotificationHandler) ListNotifications(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 200 {
+			writeError(w, http.StatusBadRequest, "limit must be between 1 and 200")
+			return
+		}
+		limit = n
+	}
+	notifications, err := h.notifSvc.ListNotifications(r.Context(), auth.UserIDFromContext(r.Context()), limit)
+	if err != nil {
+		writeNotificationError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, notifications)
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"time"
 
@@ -24,7 +25,9 @@ func NewOrderHandler(orderSvc *service.OrderService, phaseSvc *service.PhaseServ
 	return &OrderHandler{orderSvc: orderSvc, phaseSvc: phaseSvc, hub: hub}
 }
 
-// SubmitOrders handles POST /api/v1/games/{id}/orders
+// SubmitOrders handles POST /api/v1/games/{id}/orders. With "ready": true
+// it also marks the player ready, in the same phase as the orders, and
+// answers with the orders and the ready count instead of the orders alone.
 func (h *OrderHandler) SubmitOrders(w http.ResponseWriter, r *http.Request) {
 	gameID := r.PathValue("id")
 	userID := auth.UserIDFromContext(r.Context())
@@ -35,12 +38,94 @@ func (h *OrderHandler) SubmitOrders(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	orders, err := h.orderSvc.SubmitOrders(r.Context(), gameID, userID, req.Orders)
+	if !req.Ready {
+		orders, err := h.orderSvc.SubmitOrders(r.Context(), gameID, userID, req.Orders)
+		if err != nil {
+			writeError(w, submitErrorStatus(err), err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, orders)
+		return
+	}
+
+	orders, readyCount, totalPowers, err := h.orderSvc.SubmitOrdersAndReady(r.Context(), gameID, userID, req.Orders)
 	if err != nil {
-		status := http.StatusInternalServerError
-		if errors.Is(err, service.ErrGameNotFound) {
+		writeError(w, submitErrorStatus(err), err.Error())
+		return
+	}
+	h.readied(r.Context(), gameID, readyCount, totalPowers)
+	writeJSON(w, http.StatusOK, map[string]any{
+		"orders":       orders,
+		"ready_count":  readyCount,
+		"total_powers": totalPowers,
+		"all_ready":    int(readyCount) >= totalPowers,
+	})
+}
+
+// SaveOrder handles PUT /api/v1/games/{id}/orders/{location}
+func (h *OrderHandler) SaveOrder(w http.ResponseWriter, r *http.Request) {
+	gameID := r.PathValue("id")
+	userID := auth.UserIDFromContext(r.Context())
+
+	var req service.OrderInput
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	orders, err := h.orderSvc.SaveOrder(r.Context(), gameID, userID, r.PathValue("location"), req)
+	if err != nil {
+		writeError(w, submitErrorStatus(err), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, orders)
+}
+
+// ValidateOrders handles POST /api/v1/games/{id}/orders/validate
+func (h *OrderHandler) ValidateOrders(w http.ResponseWriter, r *http.Request) {
+	gameID := r.PathValue("id")
+	userID := auth.UserIDFromContext(r.Context())
+
+	var req service.OrderSubmission
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	checks, err := h.orderSvc.ValidateOrders(r.Context(), gameID, userID, req.Orders)
+	if err != nil {
+		writeError(w, submitErrorStatus(err), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, checks)
+}
+
+// submitErrorStatus maps an order submission error to its HTTP status.
+func submitErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrGameNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrNotInGame) || errors.Is(err, service.ErrNoActivePhase):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrInvalidOrder):
+		return http.StatusUnprocessableEntity
+	default:
+		return internalErrorStatus(err)
+	}
+}
+
+// ImportProposedOrders handles POST /api/v1/games/{id}/messages/{messageId}/import
+func (h *OrderHandler) ImportProposedOrders(w http.ResponseWriter, r *http.Request) {
+	gameID := r.PathValue("id")
+	userID := auth.UserIDFromContext(r.Context())
+
+	orders, err := h.orderSvc.ImportProposedOrders(r.Context(), gameID, userID, r.PathValue("messageId"))
+	if err != nil {
+		status := internalErrorStatus(err)
+		if errors.Is(err, service.ErrGameNotFound) || errors.Is(err, service.ErrMessageNotFound) {
 			status = http.StatusNotFound
-		} else if errors.Is(err, service.ErrNotInGame) || errors.Is(err, service.ErrNoActivePhase) {
+		} else if errors.Is(err, service.ErrNotInGame) || errors.Is(err, service.ErrNoActivePhase) ||
+			errors.Is(err, service.ErrNoOrderProposal) || errors.Is(err, service.ErrNotMovementPhase) {
 			status = http.StatusBadRequest
 		} else if errors.Is(err, service.ErrInvalidOrder) {
 			status = http.StatusUnprocessableEntity
@@ -51,6 +136,25 @@ func (h *OrderHandler) SubmitOrders(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, orders)
 }
 
+// CompactState handles GET /api/v1/games/{id}/state/compact?power=france
+func (h *OrderHandler) CompactState(w http.ResponseWriter, r *http.Request) {
+	gameID := r.PathValue("id")
+	userID := auth.UserIDFromContext(r.Context())
+
+	state, err := h.orderSvc.CompactState(r.Context(), gameID, userID, r.URL.Query().Get("power"))
+	if err != nil {
+		status := internalErrorStatus(err)
+		if errors.Is(err, service.ErrGameNotFound) || errors.Is(err, service.ErrNoActivePhase) {
+			status = http.StatusNotFound
+		} else if errors.Is(err, service.ErrNotInGame) || errors.Is(err, service.ErrNotYourPower) {
+			status = http.StatusForbidden
+		}
+		writeError(w, status, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, state)
+}
+
 // MarkReady handles POST /api/v1/games/{id}/orders/ready
 func (h *OrderHandler) MarkReady(w http.ResponseWriter, r *http.Request) {
 	gameID := r.PathValue("id")
@@ -58,7 +162,7 @@ func (h *OrderHandler) MarkReady(w http.ResponseWriter, r *http.Request) {
 
 	readyCount, totalPowers, err := h.orderSvc.MarkReady(r.Context(), gameID, userID)
 	if err != nil {
-		status := http.StatusInternalServerError
+		status := internalErrorStatus(err)
 		if errors.Is(err, service.ErrGameNotFound) {
 			status = http.StatusNotFound
 		} else if errors.Is(err, service.ErrNotInGame) {
@@ -68,7 +172,47 @@ func (h *OrderHandler) MarkReady(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Broadcast ready status
+	h.readied(r.Context(), gameID, readyCount, totalPowers)
+	writeJSON(w, http.StatusOK, map[string]any{
+		"ready_count":  readyCount,
+		"total_powers": totalPowers,
+		"all_ready":    int(readyCount) >= totalPowers,
+	})
+}
+
+// maxBulkReadyGames caps how many games one MarkReadyMany request covers.
+const maxBulkReadyGames = 50
+
+// MarkReadyMany handles POST /api/v1/games/ready, marking the user ready in
+// every game listed in {"game_ids": [...]}. Each game's outcome is reported
+// separately; the request only fails as a whole if it is malformed.
+func (h *OrderHandler) MarkReadyMany(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserIDFromContext(r.Context())
+
+	var req struct {
+		GameIDs []string `json:"game_ids"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if len(req.GameIDs) == 0 || len(req.GameIDs) > maxBulkReadyGames {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("game_ids must list 1 to %d games", maxBulkReadyGames))
+		return
+	}
+
+	results := h.orderSvc.MarkReadyMany(r.Context(), userID, req.GameIDs)
+	for _, res := range results {
+		if res.Error == "" {
+			h.readied(r.Context(), res.GameID, res.ReadyCount, res.TotalPowers)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"results": results})
+}
+
+// readied tells the game a player is ready and resolves the phase early
+// once every power is, or checks the ready quorum otherwise.
+func (h *OrderHandler) readied(ctx context.Context, gameID string, readyCount int64, totalPowers int) {
 	h.hub.BroadcastToGame(gameID, WSEvent{
 		Type:   EventPlayerReady,
 		GameID: gameID,
@@ -78,7 +222,6 @@ func (h *OrderHandler) MarkReady(w http.ResponseWriter, r *http.Request) {
 		},
 	})
 
-	// If all powers are ready, trigger early resolution.
 	// Use a detached context since the request context is cancelled on handler return.
 	if int(readyCount) >= totalPowers {
 		go func() {
@@ -88,13 +231,9 @@ func (h *OrderHandler) MarkReady(w http.ResponseWriter, r *http.Request) {
 				log.Error().Err(err).Str("gameId", gameID).Msg("Early resolution failed")
 			}
 		}()
+	} else if err := h.phaseSvc.CheckReadyQuorum(ctx, gameID); err != nil {
+		log.Error().Err(err).Str("gameId", gameID).Msg("Ready quorum check failed")
 	}
-
-	writeJSON(w, http.StatusOK, map[string]any{
-		"ready_count":  readyCount,
-		"total_powers": totalPowers,
-		"all_ready":    int(readyCount) >= totalPowers,
-	})
 }
 
 // UnmarkReady handles DELETE /api/v1/games/{id}/orders/ready
@@ -103,7 +242,7 @@ func (h *OrderHandler) UnmarkReady(w http.ResponseWriter, r *http.Request) {
 	userID := auth.UserIDFromContext(r.Context())
 
 	if err := h.orderSvc.UnmarkReady(r.Context(), gameID, userID); err != nil {
-		status := http.StatusInternalServerError
+		status := internalErrorStatus(err)
 		if errors.Is(err, service.ErrGameNotFound) {
 			status = http.StatusNotFound
 		} else if errors.Is(err, service.ErrNotInGame) {
@@ -113,6 +252,10 @@ func (h *OrderHandler) UnmarkReady(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := h.phaseSvc.CheckReadyQuorum(r.Context(), gameID); err != nil {
+		log.Error().Err(err).Str("gameId", gameID).Msg("Ready quorum check failed")
+	}
+
 	// Broadcast updated ready count.
 	readyCount, _ := h.phaseSvc.ReadyCount(r.Context(), gameID)
 	totalPowers := 0
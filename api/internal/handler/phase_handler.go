@@ -1,9 +1,13 @@
 package handler
 
 import (
+	"encoding/json"
 	"net/http"
+	"time"
 
+	"github.com/freeeve/polite-betrayal/api/internal/model"
 	"github.com/freeeve/polite-betrayal/api/internal/repository"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
 )
 
 // PhaseHandler handles phase-related endpoints.
@@ -21,14 +25,32 @@ func (h *PhaseHandler) ListPhases(w http.ResponseWriter, r *http.Request) {
 	gameID := r.PathValue("id")
 	phases, err := h.phaseRepo.ListPhases(r.Context(), gameID)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeError(w, internalErrorStatus(err), err.Error())
 		return
 	}
-	if phases == nil {
-		writeJSON(w, http.StatusOK, []struct{}{})
-		return
+	now := time.Now()
+	resp := make([]phaseResponse, len(phases))
+	for i := range phases {
+		resp[i] = phaseResponse{Phase: &phases[i], phaseTiming: newPhaseTiming(&phases[i], now)}
 	}
-	writeJSON(w, http.StatusOK, phases)
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// phaseTiming gives a phase's deadline as an epoch alongside the server's
+// clock, so clients can render countdowns despite clock skew.
+type phaseTiming struct {
+	DeadlineEpoch int64 `json:"deadline_epoch_ms"` // Unix milliseconds
+	ServerTime    int64 `json:"server_time_ms"`    // Unix milliseconds
+}
+
+func newPhaseTiming(phase *model.Phase, now time.Time) phaseTiming {
+	return phaseTiming{DeadlineEpoch: phase.Deadline.UnixMilli(), ServerTime: now.UnixMilli()}
+}
+
+// phaseResponse is a phase with its timing.
+type phaseResponse struct {
+	*model.Phase
+	phaseTiming
 }
 
 // CurrentPhase handles GET /api/v1/games/{id}/phases/current
@@ -36,14 +58,71 @@ func (h *PhaseHandler) CurrentPhase(w http.ResponseWriter, r *http.Request) {
 	gameID := r.PathValue("id")
 	phase, err := h.phaseRepo.CurrentPhase(r.Context(), gameID)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeError(w, internalErrorStatus(err), err.Error())
 		return
 	}
 	if phase == nil {
 		writeError(w, http.StatusNotFound, "no active phase")
 		return
 	}
-	writeJSON(w, http.StatusOK, phase)
+	writeJSON(w, http.StatusOK, newCurrentPhaseResponse(phase))
+}
+
+// currentPhaseResponse is a phase plus, in build phases, each power's
+// adjustment preview and the disbands it will get under civil disorder if it
+// submits none.
+type currentPhaseResponse struct {
+	*model.Phase
+	phaseTiming
+	BuildPreview  map[string]diplomacy.Adjustment `json:"build_preview,omitempty"`
+	CivilDisorder map[string]civilDisorderDefault `json:"civil_disorder,omitempty"`
+}
+
+func newCurrentPhaseResponse(phase *model.Phase) currentPhaseResponse {
+	resp := currentPhaseResponse{Phase: phase, phaseTiming: newPhaseTiming(phase, time.Now())}
+	if phase.PhaseType != string(diplomacy.PhaseBuild) || len(phase.StateBefore) == 0 {
+		return resp
+	}
+	var gs diplomacy.GameState
+	if err := json.Unmarshal(phase.StateBefore, &gs); err != nil {
+		return resp
+	}
+	resp.BuildPreview = make(map[string]diplomacy.Adjustment)
+	for power, adj := range diplomacy.Adjustments(&gs) {
+		resp.BuildPreview[string(power)] = adj
+	}
+	resp.CivilDisorder = civilDisorderDefaults(&gs)
+	return resp
+}
+
+// civilDisorderDefault lists a power's units in the order they are disbanded
+// when it does not order enough disbands; the first Disbands units go.
+type civilDisorderDefault struct {
+	Disbands int             `json:"disbands"`
+	Ranked   []disbandChoice `json:"ranked"`
+}
+
+type disbandChoice struct {
+	UnitType string `json:"unit_type"`
+	Location string `json:"location"`
+}
+
+// civilDisorderDefaults computes the default disbands for a build phase.
+func civilDisorderDefaults(gs *diplomacy.GameState) map[string]civilDisorderDefault {
+	pending := diplomacy.PendingDisbands(gs)
+	if len(pending) == 0 {
+		return nil
+	}
+	m := gs.Map()
+	defaults := make(map[string]civilDisorderDefault, len(pending))
+	for power, n := range pending {
+		var ranked []disbandChoice
+		for _, u := range diplomacy.DisbandPriority(power, gs, m) {
+			ranked = append(ranked, disbandChoice{UnitType: u.Type.String(), Location: u.Province})
+		}
+		defaults[string(power)] = civilDisorderDefault{Disbands: n, Ranked: ranked}
+	}
+	return defaults
 }
 
 // PhaseOrders handles GET /api/v1/games/{id}/phases/{phaseId}/orders
@@ -51,7 +130,7 @@ func (h *PhaseHandler) PhaseOrders(w http.ResponseWriter, r *http.Request) {
 	phaseID := r.PathValue("phaseId")
 	orders, err := h.phaseRepo.OrdersByPhase(r.Context(), phaseID)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeError(w, internalErrorStatus(err), err.Error())
 		return
 	}
 	if orders == nil {
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/freeeve/polite-betrayal/api/internal/auth"
+	"github.com/freeeve/polite-betrayal/api/internal/service"
+)
+
+// maxPreferencePatchBytes caps a preferences PATCH body: every namespace at
+// its size limit, plus room for the keys.
+const maxPreferencePatchBytes = service.MaxPreferenceNamespaces*service.MaxPreferenceBytes + 64<<10
+
+// PreferenceHandler serves the signed-in user's roaming UI and game
+// preferences.
+type PreferenceHandler struct {
+	prefSvc *service.PreferenceService
+}
+
+// NewPreferenceHandler creates a PreferenceHandler.
+func NewPreferenceHandler(prefSvc *service.PreferenceService) *PreferenceHandler {
+	return &PreferenceHandler{prefSvc: prefSvc}
+}
+
+// GetPreferences handles GET /api/v1/users/me/preferences, returning an
+// object keyed by namespace.
+func (h *PreferenceHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	prefs, err := h.prefSvc.GetPreferences(r.Context(), auth.UserIDFromContext(r.Context()))
+	if err != nil {
+		writeError(w, internalErrorStatus(err), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, prefs)
+}
+
+// UpdatePreferences handles PATCH /api/v1/users/me/preferences. The body is
+// an object keyed by namespace; each namespace given is replaced, or
+// removed if null, and the others are kept.
+func (h *PreferenceHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxPreferencePatchBytes)
+	var patch map[string]json.RawMessage
+	if err := decodeJSON(r, &patch); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	prefs, err := h.prefSvc.UpdatePreferences(r.Context(), auth.UserIDFromContext(r.Context()), patch)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrPreferenceNamespace),
+			errors.Is(err, service.ErrPreferenceValue),
+			errors.Is(err, service.ErrPreferenceSchema),
+			errors.Is(err, service.ErrPreferenceTooLarge):
+			writeError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrTooManyPreferences):
+			writeError(w, http.StatusConflict, err.Error())
+		default:
+			writeError(w, internalErrorStatus(err), err.Error())
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, prefs)
+}
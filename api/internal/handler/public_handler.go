@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/freeeve/polite-betrayal/api/internal/auth"
+	"github.com/freeeve/polite-betrayal/api/internal/service"
+)
+
+// PublicHandler serves the read-only public API. Routes must be wrapped in
+// auth.APIKeyMiddleware.
+type PublicHandler struct {
+	publicSvc *service.PublicService
+}
+
+// NewPublicHandler creates a PublicHandler.
+func NewPublicHandler(publicSvc *service.PublicService) *PublicHandler {
+	return &PublicHandler{publicSvc: publicSvc}
+}
+
+// ListGames handles GET /public/v1/games?search=&after=&limit=N
+func (h *PublicHandler) ListGames(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	limit := service.DefaultPublicGamesPage
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > service.MaxPublicGamesPage {
+			writeError(w, http.StatusBadRequest, "limit must be between 1 and 100")
+			return
+		}
+		limit = n
+	}
+	games, err := h.publicSvc.ListGames(r.Context(), q.Get("search"), q.Get("after"), limit)
+	if err != nil {
+		writeError(w, internalErrorStatus(err), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, games)
+}
+
+// GetGame handles GET /public/v1/games/{id}. Games still in progress are
+// reported as not found so the API doesn't reveal them.
+func (h *PublicHandler) GetGame(w http.ResponseWriter, r *http.Request) {
+	game, err := h.publicSvc.GetGame(r.Context(), r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, service.ErrGameNotFound) || errors.Is(err, service.ErrGameNotFinished) {
+			writeError(w, http.StatusNotFound, "game not found")
+			return
+		}
+		writeError(w, internalErrorStatus(err), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, game)
+}
+
+// OpeningStats handles GET /public/v1/stats/openings
+func (h *PublicHandler) OpeningStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.publicSvc.OpeningStats(r.Context())
+	if err != nil {
+		writeError(w, internalErrorStatus(err), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// Leaderboard handles GET /public/v1/leaderboard?limit=N
+func (h *PublicHandler) Leaderboard(w http.ResponseWriter, r *http.Request) {
+	limit := service.DefaultHallOfFameSize
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 100 {
+			writeError(w, http.StatusBadRequest, "limit must be between 1 and 100")
+			return
+		}
+		limit = n
+	}
+	lb, err := h.publicSvc.Leaderboard(r.Context(), limit)
+	if err != nil {
+		writeError(w, internalErrorStatus(err), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, lb)
+}
+
+// APIKeyHandler lets users manage their public API keys.
+type APIKeyHandler struct {
+	keySvc *service.APIKeyService
+}
+
+// NewAPIKeyHandler creates an APIKeyHandler.
+func NewAPIKeyHandler(keySvc *service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{keySvc: keySvc}
+}
+
+// CreateKey handles POST /api/v1/users/me/api-keys. The response carries the
+// full key, which is not retrievable afterwards.
+func (h *APIKeyHandler) CreateKey(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	key, token, err := h.keySvc.CreateKey(r.Context(), auth.UserIDFromContext(r.Context()), req.Name)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrAPIKeyName):
+			writeError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrTooManyAPIKeys):
+			writeError(w, http.StatusConflict, err.Error())
+		default:
+			writeError(w, internalErrorStatus(err), err.Error())
+		}
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]any{"key": key, "token": token})
+}
+
+// ListKeys handles GET /api/v1/users/me/api-keys
+func (h *APIKeyHandler) ListKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.keySvc.ListKeys(r.Context(), auth.UserIDFromContext(r.Context()))
+	if err != nil {
+		writeError(w, internalErrorStatus(err), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, keys)
+}
+
+// RevokeKey handles DELETE /api/v1/users/me/api-keys/{id}
+func (h *APIKeyHandler) RevokeKey(w http.ResponseWriter, r *http.Request) {
+	err := h.keySvc.RevokeKey(r.Context(), auth.UserIDFromContext(r.Context()), r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, service.ErrAPIKeyNotFound) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeError(w, internalErrorStatus(err), err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
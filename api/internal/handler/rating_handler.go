@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/freeeve/polite-betrayal/api/internal/service"
+)
+
+// RatingHandler serves the Elo leaderboards.
+type RatingHandler struct {
+	ratingSvc *service.RatingService
+}
+
+// NewRatingHandler creates a RatingHandler.
+func NewRatingHandler(ratingSvc *service.RatingService) *RatingHandler {
+	return &RatingHandler{ratingSvc: ratingSvc}
+}
+
+// Leaderboard handles GET /api/v1/leaderboard?limit=N, listing the top rated
+// users and bot strategies.
+func (h *RatingHandler) Leaderboard(w http.ResponseWriter, r *http.Request) {
+	limit := service.DefaultHallOfFameSize
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 100 {
+			writeError(w, http.StatusBadRequest, "limit must be between 1 and 100")
+			return
+		}
+		limit = n
+	}
+	lb, err := h.ratingSvc.Leaderboard(r.Context(), limit)
+	if err != nil {
+		writeError(w, internalErrorStatus(err), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, lb)
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/freeeve/polite-betrayal/api/internal/service"
+)
+
+// ReplayHandler serves turn-by-turn game replays.
+type ReplayHandler struct {
+	replaySvc *service.ReplayService
+}
+
+// NewReplayHandler creates a ReplayHandler.
+func NewReplayHandler(replaySvc *service.ReplayService) *ReplayHandler {
+	return &ReplayHandler{replaySvc: replaySvc}
+}
+
+// GetReplay handles GET /api/v1/games/{id}/replay
+func (h *ReplayHandler) GetReplay(w http.ResponseWriter, r *http.Request) {
+	replay, err := h.replaySvc.Replay(r.Context(), r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, service.ErrGameNotFound) {
+			writeError(w, http.StatusNotFound, "game not found")
+			return
+		}
+		writeError(w, internalErrorStatus(err), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, replay)
+}
+
+// Resimulate handles POST /api/v1/games/{id}/phases/{phaseId}/resimulate,
+// replaying a resolved phase with alternate orders for some powers and
+// returning the outcome without storing it. The body maps powers to orders
+// in the format orders are submitted in.
+func (h *ReplayHandler) Resimulate(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Orders map[string][]service.OrderInput `json:"orders"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	sim, err := h.replaySvc.Resimulate(r.Context(), r.PathValue("id"), r.PathValue("phaseId"), req.Orders)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrGameNotFound) || errors.Is(err, service.ErrPhaseNotFound):
+			writeError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, service.ErrPhaseNotResolved):
+			writeError(w, http.StatusConflict, err.Error())
+		case errors.Is(err, service.ErrInvalidPower):
+			writeError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrInvalidOrder):
+			writeError(w, http.StatusUnprocessableEntity, err.Error())
+		default:
+			writeError(w, internalErrorStatus(err), err.Error())
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, sim)
+}
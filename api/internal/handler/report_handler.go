@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/freeeve/polite-betrayal/api/internal/service"
+)
+
+// ReportHandler serves after-action reports of finished games.
+type ReportHandler struct {
+	reportSvc   *service.ReportService
+	artifactSvc *service.ArtifactService
+}
+
+// NewReportHandler creates a ReportHandler.
+func NewReportHandler(reportSvc *service.ReportService) *ReportHandler {
+	return &ReportHandler{reportSvc: reportSvc}
+}
+
+// SetArtifactService enables ?link=true on reports.
+func (h *ReportHandler) SetArtifactService(artifactSvc *service.ArtifactService) {
+	h.artifactSvc = artifactSvc
+}
+
+// GetReport handles GET /api/v1/games/{id}/report, downloading the report
+// as Markdown or, with ?format=pdf, as PDF. With ?link=true it returns a
+// signed link to the stored report instead.
+func (h *ReportHandler) GetReport(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "markdown" && format != "pdf" {
+		writeError(w, http.StatusBadRequest, "format must be markdown or pdf")
+		return
+	}
+	report, err := h.reportSvc.Report(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeExportError(w, err)
+		return
+	}
+	if wantsLink(r) {
+		ext, contentType := "md", "text/markdown; charset=utf-8"
+		if format == "pdf" {
+			ext, contentType = "pdf", "application/pdf"
+		}
+		// The PDF is rendered from the Markdown, so both share its version.
+		version := service.ContentVersion(report.Markdown())
+		writeArtifactLink(w, r, h.artifactSvc, "games/"+report.GameID+"/report."+ext, version, contentType, func() ([]byte, error) {
+			if format == "pdf" {
+				return report.PDF(), nil
+			}
+			return report.Markdown(), nil
+		})
+		return
+	}
+
+	body, contentType, ext := report.Markdown(), "text/markdown; charset=utf-8", "md"
+	if format == "pdf" {
+		body, contentType, ext = report.PDF(), "application/pdf", "pdf"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="`+report.GameID+`-report.`+ext+`"`)
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
@@ -2,9 +2,14 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/rs/zerolog/log"
+
+	"github.com/freeeve/polite-betrayal/api/internal/repository"
+	"github.com/freeeve/polite-betrayal/api/internal/service"
+	"github.com/freeeve/polite-betrayal/api/internal/storage"
 )
 
 // writeJSON writes a JSON response with the given status code.
@@ -21,8 +26,43 @@ func writeError(w http.ResponseWriter, status int, msg string) {
 	writeJSON(w, status, map[string]string{"error": msg})
 }
 
+// internalErrorStatus returns the status for an error with no more specific
+// mapping: 503 when the database timed out (the request can be retried),
+// 500 otherwise.
+func internalErrorStatus(err error) int {
+	if errors.Is(err, repository.ErrTimeout) {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
 // decodeJSON reads and decodes JSON from a request body.
 func decodeJSON(r *http.Request, v any) error {
 	defer r.Body.Close()
 	return json.NewDecoder(r.Body).Decode(v)
 }
+
+// wantsLink reports whether the request asked for a signed link to an
+// artifact (?link=true) rather than the artifact itself.
+func wantsLink(r *http.Request) bool {
+	return r.URL.Query().Get("link") == "true"
+}
+
+// writeArtifactLink stores version of the artifact under key, rendering it
+// if needed, and writes a signed link to it.
+func writeArtifactLink(w http.ResponseWriter, r *http.Request, artifactSvc *service.ArtifactService, key, version, contentType string, render func() ([]byte, error)) {
+	link, err := artifactSvc.Link(r.Context(), key, version, contentType, render)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNoArtifactStore):
+			writeError(w, http.StatusNotImplemented, err.Error())
+			return
+		case errors.Is(err, storage.ErrInvalidKey):
+			writeError(w, http.StatusNotFound, "game not found")
+			return
+		}
+		writeExportError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, link)
+}
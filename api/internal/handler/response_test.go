@@ -2,10 +2,14 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+
+	"github.com/freeeve/polite-betrayal/api/internal/repository"
 )
 
 func TestWriteJSON(t *testing.T) {
@@ -112,3 +116,12 @@ func TestWriteJSONEmptySlice(t *testing.T) {
 		t.Errorf("expected [], got %s", body)
 	}
 }
+
+func TestInternalErrorStatus(t *testing.T) {
+	if got := internalErrorStatus(fmt.Errorf("find game: %w", repository.ErrTimeout)); got != http.StatusServiceUnavailable {
+		t.Errorf("timeout: expected 503, got %d", got)
+	}
+	if got := internalErrorStatus(errors.New("boom")); got != http.StatusInternalServerError {
+		t.Errorf("other: expected 500, got %d", got)
+	}
+}
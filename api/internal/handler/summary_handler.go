@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/internal/service"
+)
+
+// SummaryHandler serves end-of-game summaries.
+type SummaryHandler struct {
+	summarySvc  *service.SummaryService
+	artifactSvc *service.ArtifactService
+}
+
+// NewSummaryHandler creates a SummaryHandler.
+func NewSummaryHandler(summarySvc *service.SummaryService) *SummaryHandler {
+	return &SummaryHandler{summarySvc: summarySvc}
+}
+
+// SetArtifactService enables ?link=true on summary images.
+func (h *SummaryHandler) SetArtifactService(artifactSvc *service.ArtifactService) {
+	h.artifactSvc = artifactSvc
+}
+
+// GetSummary handles GET /api/v1/games/{id}/summary
+func (h *SummaryHandler) GetSummary(w http.ResponseWriter, r *http.Request) {
+	summary, ok := h.summary(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, summary)
+}
+
+// GetSummaryImage handles GET /api/v1/games/{id}/summary/{image}, serving
+// map.svg or sc-graph.svg on its own so it can be embedded or attached.
+// With ?link=true it returns a signed link to the stored image instead.
+func (h *SummaryHandler) GetSummaryImage(w http.ResponseWriter, r *http.Request) {
+	var pick func(*model.GameSummary) string
+	switch r.PathValue("image") {
+	case "map.svg":
+		pick = func(s *model.GameSummary) string { return s.MapSVG }
+	case "sc-graph.svg":
+		pick = func(s *model.GameSummary) string { return s.SCGraphSVG }
+	default:
+		writeError(w, http.StatusNotFound, "image must be map.svg or sc-graph.svg")
+		return
+	}
+	summary, ok := h.summary(w, r)
+	if !ok {
+		return
+	}
+	if wantsLink(r) {
+		image := []byte(pick(summary))
+		key := "games/" + summary.GameID + "/summary/" + r.PathValue("image")
+		writeArtifactLink(w, r, h.artifactSvc, key, service.ContentVersion(image), "image/svg+xml", func() ([]byte, error) {
+			return image, nil
+		})
+		return
+	}
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(pick(summary)))
+}
+
+// summary loads the game's summary, writing the error response on failure.
+func (h *SummaryHandler) summary(w http.ResponseWriter, r *http.Request) (*model.GameSummary, bool) {
+	summary, err := h.summarySvc.Summary(r.Context(), r.PathValue("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrGameNotFound):
+			writeError(w, http.StatusNotFound, "game not found")
+		case errors.Is(err, service.ErrGameNotFinished):
+			writeError(w, http.StatusConflict, err.Error())
+		default:
+			writeError(w, internalErrorStatus(err), err.Error())
+		}
+		return nil, false
+	}
+	return summary, true
+}
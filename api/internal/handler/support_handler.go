@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/freeeve/polite-betrayal/api/internal/auth"
+	"github.com/freeeve/polite-betrayal/api/internal/service"
+)
+
+// SupportHandler exposes cross-power support opportunities as talking points.
+type SupportHandler struct {
+	supportSvc *service.SupportService
+}
+
+// NewSupportHandler creates a SupportHandler.
+func NewSupportHandler(supportSvc *service.SupportService) *SupportHandler {
+	return &SupportHandler{supportSvc: supportSvc}
+}
+
+// TalkingPoints handles GET /api/v1/games/{id}/support-opportunities
+func (h *SupportHandler) TalkingPoints(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserIDFromContext(r.Context())
+	points, err := h.supportSvc.TalkingPoints(r.Context(), r.PathValue("id"), userID)
+	if err != nil {
+		status := internalErrorStatus(err)
+		if errors.Is(err, service.ErrGameNotFound) {
+			status = http.StatusNotFound
+		} else if errors.Is(err, service.ErrNotInGame) || errors.Is(err, service.ErrNoActivePhase) {
+			status = http.StatusBadRequest
+		}
+		writeError(w, status, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, points)
+}
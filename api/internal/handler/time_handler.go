@@ -0,0 +1,16 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+)
+
+// ServerTime handles GET /time, the server's clock, so clients can measure
+// their skew and render deadlines accurately.
+func ServerTime(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	writeJSON(w, http.StatusOK, map[string]any{
+		"server_time":    now.UTC().Format(time.RFC3339Nano),
+		"server_time_ms": now.UnixMilli(),
+	})
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/freeeve/polite-betrayal/api/internal/service"
+)
+
+// TrendingHandler serves the live games most worth watching.
+type TrendingHandler struct {
+	trendingSvc *service.TrendingService
+}
+
+// NewTrendingHandler creates a TrendingHandler.
+func NewTrendingHandler(trendingSvc *service.TrendingService) *TrendingHandler {
+	return &TrendingHandler{trendingSvc: trendingSvc}
+}
+
+// Trending handles GET /api/v1/games/trending?limit=N, listing live games by
+// current viewers and recent activity.
+func (h *TrendingHandler) Trending(w http.ResponseWriter, r *http.Request) {
+	limit := service.MaxTrendingGames
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > service.MaxTrendingGames {
+			writeError(w, http.StatusBadRequest, "limit must be between 1 and "+strconv.Itoa(service.MaxTrendingGames))
+			return
+		}
+		limit = n
+	}
+	games, err := h.trendingSvc.Trending(r.Context(), limit)
+	if err != nil {
+		writeError(w, internalErrorStatus(err), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, games)
+}
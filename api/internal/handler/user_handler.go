@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/freeeve/polite-betrayal/api/internal/auth"
+	"github.com/freeeve/polite-betrayal/api/internal/bot"
 	"github.com/freeeve/polite-betrayal/api/internal/repository"
 )
 
@@ -22,7 +23,7 @@ func (h *UserHandler) GetMe(w http.ResponseWriter, r *http.Request) {
 	userID := auth.UserIDFromContext(r.Context())
 	user, err := h.userRepo.FindByID(r.Context(), userID)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeError(w, internalErrorStatus(err), err.Error())
 		return
 	}
 	if user == nil {
@@ -36,20 +37,41 @@ func (h *UserHandler) GetMe(w http.ResponseWriter, r *http.Request) {
 func (h *UserHandler) UpdateMe(w http.ResponseWriter, r *http.Request) {
 	userID := auth.UserIDFromContext(r.Context())
 	var req struct {
-		DisplayName string `json:"display_name"`
+		DisplayName string  `json:"display_name"`
+		Locale      *string `json:"locale,omitempty"`
+		SharePress  *bool   `json:"share_press,omitempty"`
 	}
 	if err := decodeJSON(r, &req); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
-	if req.DisplayName == "" {
+	if req.DisplayName == "" && req.Locale == nil && req.SharePress == nil {
 		writeError(w, http.StatusBadRequest, "display_name is required")
 		return
 	}
 
-	if err := h.userRepo.UpdateDisplayName(r.Context(), userID, req.DisplayName); err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
-		return
+	if req.Locale != nil {
+		locale, ok := bot.NormalizeLocale(*req.Locale)
+		if !ok {
+			writeError(w, http.StatusBadRequest, "unsupported locale")
+			return
+		}
+		if err := h.userRepo.UpdateLocale(r.Context(), userID, locale); err != nil {
+			writeError(w, internalErrorStatus(err), err.Error())
+			return
+		}
+	}
+	if req.SharePress != nil {
+		if err := h.userRepo.UpdateSharePress(r.Context(), userID, *req.SharePress); err != nil {
+			writeError(w, internalErrorStatus(err), err.Error())
+			return
+		}
+	}
+	if req.DisplayName != "" {
+		if err := h.userRepo.UpdateDisplayName(r.Context(), userID, req.DisplayName); err != nil {
+			writeError(w, internalErrorStatus(err), err.Error())
+			return
+		}
 	}
 
 	user, _ := h.userRepo.FindByID(r.Context(), userID)
@@ -61,7 +83,7 @@ func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 	user, err := h.userRepo.FindByID(r.Context(), id)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeError(w, internalErrorStatus(err), err.Error())
 		return
 	}
 	if user == nil {
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/freeeve/polite-betrayal/api/internal/auth"
+	"github.com/freeeve/polite-betrayal/api/internal/service"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// maxVariantBytes caps uploaded variant definitions; geometry can be large.
+const maxVariantBytes = 4 << 20
+
+// VariantHandler serves custom variants. Upload and Template must be wrapped
+// in auth.RequireAdmin.
+type VariantHandler struct {
+	variantSvc *service.VariantService
+}
+
+// NewVariantHandler creates a VariantHandler.
+func NewVariantHandler(variantSvc *service.VariantService) *VariantHandler {
+	return &VariantHandler{variantSvc: variantSvc}
+}
+
+// Upload handles POST /api/v1/admin/variants. The body is a
+// diplomacy.VariantDefinition; validation problems are returned as a list.
+func (h *VariantHandler) Upload(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxVariantBytes)
+	var def diplomacy.VariantDefinition
+	if err := decodeJSON(r, &def); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	v, err := h.variantSvc.Upload(r.Context(), auth.UserIDFromContext(r.Context()), &def)
+	if err != nil {
+		var verr *diplomacy.VariantError
+		if errors.As(err, &verr) {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid variant", "problems": verr.Problems})
+			return
+		}
+		writeError(w, internalErrorStatus(err), err.Error())
+		return
+	}
+	v.Definition = nil
+	writeJSON(w, http.StatusCreated, v)
+}
+
+// Template handles GET /api/v1/admin/variants/template, returning the
+// standard map as a definition to start editing from.
+func (h *VariantHandler) Template(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, diplomacy.StandardVariant())
+}
+
+// List handles GET /api/v1/variants
+func (h *VariantHandler) List(w http.ResponseWriter, r *http.Request) {
+	variants, err := h.variantSvc.List(r.Context())
+	if err != nil {
+		writeError(w, internalErrorStatus(err), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, variants)
+}
+
+// Get handles GET /api/v1/variants/{name}, including the full definition so
+// clients can draw the map.
+func (h *VariantHandler) Get(w http.ResponseWriter, r *http.Request) {
+	v, err := h.variantSvc.Get(r.Context(), r.PathValue("name"))
+	if err != nil {
+		if errors.Is(err, service.ErrVariantNotFound) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeError(w, internalErrorStatus(err), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, v)
+}
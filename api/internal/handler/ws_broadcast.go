@@ -8,3 +8,21 @@ func (h *Hub) BroadcastGameEvent(gameID string, eventType string, data any) {
 		Data:   data,
 	})
 }
+
+// BroadcastUserEvent implements service.Broadcaster using the WebSocket hub.
+func (h *Hub) BroadcastUserEvent(gameID, userID string, eventType string, data any) {
+	h.BroadcastToUser(userID, WSEvent{
+		Type:   eventType,
+		GameID: gameID,
+		Data:   data,
+	})
+}
+
+// BroadcastLobbyEvent implements service.Broadcaster using the WebSocket hub.
+func (h *Hub) BroadcastLobbyEvent(gameID string, eventType string, data any) {
+	h.BroadcastToLobby(WSEvent{
+		Type:   eventType,
+		GameID: gameID,
+		Data:   data,
+	})
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"time"
@@ -109,10 +110,26 @@ func (h *WSHandler) readPump(c *WSConn) {
 			continue
 		}
 
+		if msg.Topic == TopicLobby {
+			switch msg.Action {
+			case "subscribe":
+				h.hub.SubscribeLobby(c)
+			case "unsubscribe":
+				h.hub.UnsubscribeLobby(c)
+			}
+			continue
+		}
+
 		switch msg.Action {
 		case "subscribe":
 			if msg.GameID != "" {
+				// Subscribe before replaying so no event falls between the two.
 				h.hub.Subscribe(c, msg.GameID)
+				if msg.Since > 0 {
+					ctx, cancel := context.WithTimeout(context.Background(), eventLogTimeout)
+					h.hub.Replay(ctx, c, msg.GameID, msg.Since)
+					cancel()
+				}
 			}
 		case "unsubscribe":
 			if msg.GameID != "" {
@@ -1,11 +1,16 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"sync"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/rs/zerolog/log"
+
+	"github.com/freeeve/polite-betrayal/api/internal/repository"
 )
 
 // Event types sent over WebSocket.
@@ -17,19 +22,69 @@ const (
 	EventGameStarted   = "game_started"
 	EventGameEnded     = "game_ended"
 	EventPowerChanged  = "power_changed"
+	EventDrawVote      = "draw_vote"
+	// EventDrawVotesReset follows phase_changed when votes cast in the
+	// resolved phase expire and must be re-confirmed.
+	EventDrawVotesReset = "draw_votes_reset"
+	// EventSpectatorJoined carries the spectator's user ID and the new
+	// spectator count.
+	EventSpectatorJoined = "spectator_joined"
+	// EventChannelUpdated carries a press channel after it is created or its
+	// members change; it goes to the members only.
+	EventChannelUpdated = "channel_updated"
+	// EventCivilDisorder carries the power of a player who missed too many
+	// deadlines in a row, and how many.
+	EventCivilDisorder = "civil_disorder"
+	// EventPlayerReplaced carries a power handed to a bot, the bot's
+	// difficulty and why.
+	EventPlayerReplaced = "player_replaced"
+	// EventGameCreated, EventSeatFilled, EventGameFull, EventGameStarted
+	// and EventGameFinished go to the lobby topic as games move through
+	// their lifecycle; their data carries the game's name, status, scenario,
+	// human player count and seats. EventGameFull follows the seat_filled of
+	// the join that leaves no seat to a bot.
+	EventGameCreated  = "game_created"
+	EventSeatFilled   = "seat_filled"
+	EventGameFull     = "game_full"
+	EventGameFinished = "game_finished"
+	// EventResync tells a client resuming a subscription that the events it
+	// missed are no longer available and it must refetch the game; its data
+	// carries the game's latest sequence number.
+	EventResync = "resync"
 )
 
-// WSEvent is the envelope for all WebSocket messages.
+// eventLogTimeout bounds the event log calls made while broadcasting.
+const eventLogTimeout = 2 * time.Second
+
+// TopicLobby is the topic of game lifecycle events for the game browser.
+// Events without a topic belong to the game they name.
+const TopicLobby = "lobby"
+
+// WSEvent is the envelope for all WebSocket messages. Game events carry a
+// per-game sequence number when the hub has an event log; lobby events are
+// neither numbered nor logged.
 type WSEvent struct {
 	Type   string `json:"type"`
+	Topic  string `json:"topic,omitempty"`
 	GameID string `json:"game_id"`
+	Seq    int64  `json:"seq,omitempty"`
 	Data   any    `json:"data"`
+	// ServerTime is when the server sent the event, in Unix milliseconds, so
+	// clients can correct countdowns for clock skew. Replayed events keep the
+	// time they were first sent.
+	ServerTime int64 `json:"server_time_ms,omitempty"`
 }
 
-// ClientMessage is the envelope for messages sent from the client.
+// ClientMessage is the envelope for messages sent from the client. A
+// subscribe with Since set replays the game's events after that sequence
+// number before live ones; events may arrive twice around the switch, so
+// clients drop any seq they have already applied. With Topic set to
+// TopicLobby, (un)subscribe follows the lobby instead of a game.
 type ClientMessage struct {
 	Action string `json:"action"` // "subscribe" or "unsubscribe"
+	Topic  string `json:"topic,omitempty"`
 	GameID string `json:"game_id"`
+	Since  int64  `json:"since,omitempty"`
 }
 
 // WSConn wraps a WebSocket connection with its user and subscriptions.
@@ -44,6 +99,31 @@ type Hub struct {
 	mu          sync.RWMutex
 	connections map[*WSConn]bool
 	games       map[string]map[*WSConn]bool // gameID -> set of connections
+	lobby       map[*WSConn]bool
+	watchers    map[string]map[chan struct{}]bool // gameID -> in-process listeners
+
+	activityMu sync.Mutex
+	activity   map[string]time.Time // gameID -> last game broadcast, until taken
+
+	events repository.EventLog
+
+	relay  repository.EventRelay
+	origin string // this instance, to skip its own relayed events
+}
+
+// Relayed event kinds: which local connections a relayed event goes to.
+const (
+	relayGame  = "game"
+	relayUser  = "user"
+	relayLobby = "lobby"
+)
+
+// relayedEvent is an encoded event passed between server instances.
+type relayedEvent struct {
+	Origin string          `json:"origin"`
+	Kind   string          `json:"kind"`
+	Target string          `json:"target,omitempty"` // game or user ID
+	Event  json.RawMessage `json:"event"`
 }
 
 // NewHub creates a new Hub.
@@ -51,6 +131,88 @@ func NewHub() *Hub {
 	return &Hub{
 		connections: make(map[*WSConn]bool),
 		games:       make(map[string]map[*WSConn]bool),
+		lobby:       make(map[*WSConn]bool),
+		watchers:    make(map[string]map[chan struct{}]bool),
+		activity:    make(map[string]time.Time),
+	}
+}
+
+// SetEventLog makes the hub number each game's events and log them, so
+// subscribers can resume after a disconnect.
+func (h *Hub) SetEventLog(events repository.EventLog) {
+	h.events = events
+}
+
+// SetRelay makes the hub pass every event it broadcasts to the other server
+// instances sharing relay, and deliver theirs, so clients get every game's
+// events whichever instance they are connected to. origin must be unique
+// among the instances. RunRelay delivers the other instances' events.
+func (h *Hub) SetRelay(relay repository.EventRelay, origin string) {
+	h.relay = relay
+	h.origin = origin
+}
+
+// RunRelay delivers the events other instances broadcast to this
+// instance's connections until ctx is done, resubscribing if the
+// subscription drops.
+func (h *Hub) RunRelay(ctx context.Context) {
+	if h.relay == nil {
+		return
+	}
+	for {
+		events, err := h.relay.SubscribeEvents(ctx)
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to subscribe to relayed WebSocket events")
+		} else {
+			for msg := range events {
+				h.deliverRelayed(msg)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(relayRetry):
+		}
+	}
+}
+
+// relayRetry is how long RunRelay waits before resubscribing.
+const relayRetry = time.Second
+
+// deliverRelayed sends an event relayed from another instance to the local
+// connections it is for.
+func (h *Hub) deliverRelayed(msg []byte) {
+	var ev relayedEvent
+	if err := json.Unmarshal(msg, &ev); err != nil {
+		log.Warn().Err(err).Msg("Dropping malformed relayed WebSocket event")
+		return
+	}
+	if ev.Origin == h.origin {
+		return
+	}
+	switch ev.Kind {
+	case relayGame:
+		h.deliverToGame(ev.Target, ev.Event)
+	case relayUser:
+		h.deliverToUser(ev.Target, ev.Event)
+	case relayLobby:
+		h.deliverToLobby(ev.Event)
+	}
+}
+
+// publish relays an encoded event to the other instances.
+func (h *Hub) publish(kind, target string, data []byte) {
+	if h.relay == nil {
+		return
+	}
+	msg, err := json.Marshal(relayedEvent{Origin: h.origin, Kind: kind, Target: target, Event: data})
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), eventLogTimeout)
+	defer cancel()
+	if err := h.relay.PublishEvent(ctx, msg); err != nil {
+		log.Warn().Err(err).Str("kind", kind).Str("target", target).Msg("Failed to relay WebSocket event")
 	}
 }
 
@@ -66,6 +228,7 @@ func (h *Hub) Unregister(c *WSConn) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 	delete(h.connections, c)
+	delete(h.lobby, c)
 	for gameID, conns := range h.games {
 		delete(conns, c)
 		if len(conns) == 0 {
@@ -97,14 +260,100 @@ func (h *Hub) Unsubscribe(c *WSConn, gameID string) {
 	}
 }
 
-// BroadcastToGame sends an event to all connections subscribed to a game.
-func (h *Hub) BroadcastToGame(gameID string, event WSEvent) {
+// WatchGame returns a channel that receives a value after each event of a
+// game, whether broadcast here or relayed from another instance, for
+// in-process listeners such as DAIDE sessions. Values do not queue: a
+// listener that falls behind sees one for any number of events. Call the
+// returned function to stop watching.
+func (h *Hub) WatchGame(gameID string) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.watchers[gameID] == nil {
+		h.watchers[gameID] = make(map[chan struct{}]bool)
+	}
+	h.watchers[gameID][ch] = true
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.watchers[gameID], ch)
+		if len(h.watchers[gameID]) == 0 {
+			delete(h.watchers, gameID)
+		}
+	}
+}
+
+// SubscribeLobby adds a connection to the lobby topic.
+func (h *Hub) SubscribeLobby(c *WSConn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lobby[c] = true
+}
+
+// UnsubscribeLobby removes a connection from the lobby topic.
+func (h *Hub) UnsubscribeLobby(c *WSConn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.lobby, c)
+}
+
+// BroadcastToLobby sends an event to all connections following the lobby.
+func (h *Hub) BroadcastToLobby(event WSEvent) {
+	event.Topic = TopicLobby
+	event.ServerTime = time.Now().UnixMilli()
 	data, err := json.Marshal(event)
+	if err != nil {
+		log.Error().Err(err).Str("gameId", event.GameID).Msg("Failed to marshal WebSocket lobby event")
+		return
+	}
+	h.deliverToLobby(data)
+	h.publish(relayLobby, "", data)
+}
+
+// deliverToLobby sends an encoded event to this instance's lobby followers.
+func (h *Hub) deliverToLobby(data []byte) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for c := range h.lobby {
+		select {
+		case c.send <- data:
+		default:
+			log.Warn().Str("userId", c.userID).Msg("Dropping WebSocket lobby message, buffer full")
+		}
+	}
+}
+
+// LobbySubscriberCount returns the number of connections following the lobby.
+func (h *Hub) LobbySubscriberCount() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.lobby)
+}
+
+// BroadcastToGame sends an event to all connections subscribed to a game,
+// numbering and logging it first if the hub has an event log. An event the
+// log fails to take is still sent, without a sequence number. With a relay
+// set, subscribers on other instances get it too.
+func (h *Hub) BroadcastToGame(gameID string, event WSEvent) {
+	event.ServerTime = time.Now().UnixMilli()
+	data, err := h.logEvent(gameID, event)
 	if err != nil {
 		log.Error().Err(err).Str("gameId", gameID).Msg("Failed to marshal WebSocket event")
 		return
 	}
 
+	h.activityMu.Lock()
+	h.activity[gameID] = time.Now()
+	h.activityMu.Unlock()
+
+	h.deliverToGame(gameID, data)
+	h.publish(relayGame, gameID, data)
+}
+
+// deliverToGame sends an encoded event to this instance's subscribers of a
+// game.
+func (h *Hub) deliverToGame(gameID string, data []byte) {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
@@ -115,16 +364,84 @@ func (h *Hub) BroadcastToGame(gameID string, event WSEvent) {
 			log.Warn().Str("userId", c.userID).Str("gameId", gameID).Msg("Dropping WebSocket message, buffer full")
 		}
 	}
+	for ch := range h.watchers[gameID] {
+		select {
+		case ch <- struct{}{}:
+		default: // the listener has news pending already
+		}
+	}
+}
+
+// logEvent numbers the event and appends it to the event log, returning its
+// encoding.
+func (h *Hub) logEvent(gameID string, event WSEvent) ([]byte, error) {
+	if h.events == nil {
+		return json.Marshal(event)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), eventLogTimeout)
+	defer cancel()
+	seq, err := h.events.NextEventSeq(ctx, gameID)
+	if err != nil {
+		log.Warn().Err(err).Str("gameId", gameID).Msg("Failed to number WebSocket event")
+		return json.Marshal(event)
+	}
+	event.Seq = seq
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.events.AppendGameEvent(ctx, gameID, seq, data); err != nil {
+		log.Warn().Err(err).Str("gameId", gameID).Int64("seq", seq).Msg("Failed to log WebSocket event")
+	}
+	return data, nil
+}
+
+// Replay sends a connection the game's logged events after since, or a
+// resync event if some of them are gone. It does nothing without an event
+// log.
+func (h *Hub) Replay(ctx context.Context, c *WSConn, gameID string, since int64) {
+	if h.events == nil {
+		return
+	}
+	events, latest, err := h.events.GameEventsSince(ctx, gameID, since)
+	if errors.Is(err, repository.ErrEventsExpired) {
+		data, _ := json.Marshal(WSEvent{Type: EventResync, GameID: gameID, Data: map[string]int64{"seq": latest}, ServerTime: time.Now().UnixMilli()})
+		events = [][]byte{data}
+	} else if err != nil {
+		log.Warn().Err(err).Str("gameId", gameID).Msg("Failed to read WebSocket event log")
+		return
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if !h.connections[c] {
+		return
+	}
+	for _, data := range events {
+		select {
+		case c.send <- data:
+		default:
+			log.Warn().Str("userId", c.userID).Str("gameId", gameID).Msg("Dropping WebSocket replay, buffer full")
+			return
+		}
+	}
 }
 
 // BroadcastToUser sends an event to a specific user across all their connections.
 func (h *Hub) BroadcastToUser(userID string, event WSEvent) {
+	event.ServerTime = time.Now().UnixMilli()
 	data, err := json.Marshal(event)
 	if err != nil {
 		log.Error().Err(err).Str("userId", userID).Msg("Failed to marshal WebSocket event")
 		return
 	}
+	h.deliverToUser(userID, data)
+	h.publish(relayUser, userID, data)
+}
 
+// deliverToUser sends an encoded event to a user's connections on this
+// instance.
+func (h *Hub) deliverToUser(userID string, data []byte) {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
@@ -151,3 +468,29 @@ func (h *Hub) GameSubscriberCount(gameID string) int {
 	defer h.mu.RUnlock()
 	return len(h.games[gameID])
 }
+
+// ViewerCounts returns the number of distinct users subscribed to each game
+// on this server.
+func (h *Hub) ViewerCounts() map[string]int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	counts := make(map[string]int, len(h.games))
+	for gameID, conns := range h.games {
+		users := make(map[string]bool, len(conns))
+		for c := range conns {
+			users[c.userID] = true
+		}
+		counts[gameID] = len(users)
+	}
+	return counts
+}
+
+// TakeActivity returns when each game last had an event broadcast since the
+// previous call.
+func (h *Hub) TakeActivity() map[string]time.Time {
+	h.activityMu.Lock()
+	defer h.activityMu.Unlock()
+	activity := h.activity
+	h.activity = make(map[string]time.Time)
+	return activity
+}
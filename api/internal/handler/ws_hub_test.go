@@ -1,10 +1,14 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"slices"
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/freeeve/polite-betrayal/api/internal/repository"
 )
 
 func newTestConn(userID string) *WSConn {
@@ -198,6 +202,40 @@ func TestHubBroadcastGameEvent(t *testing.T) {
 	}
 }
 
+func TestHubWatchGame(t *testing.T) {
+	hub := NewHub()
+	events, stop := hub.WatchGame("game-1")
+
+	hub.BroadcastGameEvent("game-2", "phase_changed", nil)
+	select {
+	case <-events:
+		t.Fatal("expected no news of another game")
+	default:
+	}
+
+	// Several events before the watcher looks collapse into one value.
+	hub.BroadcastGameEvent("game-1", "phase_changed", nil)
+	hub.BroadcastGameEvent("game-1", "player_ready", nil)
+	select {
+	case <-events:
+	default:
+		t.Fatal("expected news of the watched game")
+	}
+	select {
+	case <-events:
+		t.Fatal("expected events to collapse into one value")
+	default:
+	}
+
+	stop()
+	hub.BroadcastGameEvent("game-1", "phase_changed", nil)
+	select {
+	case <-events:
+		t.Error("expected no news after stopping")
+	default:
+	}
+}
+
 func TestWSEventSerialization(t *testing.T) {
 	event := WSEvent{
 		Type:   EventGameStarted,
@@ -238,3 +276,239 @@ func TestClientMessageSerialization(t *testing.T) {
 		t.Errorf("expected game-1, got %s", parsed.GameID)
 	}
 }
+
+func TestHubViewerCountsAndActivity(t *testing.T) {
+	hub := NewHub()
+	c1 := newTestConn("user-1")
+	c2 := newTestConn("user-1") // second tab of the same user
+	c3 := newTestConn("user-2")
+	for _, c := range []*WSConn{c1, c2, c3} {
+		hub.Register(c)
+		defer hub.Unregister(c)
+	}
+	hub.Subscribe(c1, "game-1")
+	hub.Subscribe(c2, "game-1")
+	hub.Subscribe(c3, "game-1")
+	hub.Subscribe(c3, "game-2")
+
+	counts := hub.ViewerCounts()
+	if counts["game-1"] != 2 || counts["game-2"] != 1 {
+		t.Errorf("expected distinct viewers 2 and 1, got %v", counts)
+	}
+
+	hub.BroadcastToGame("game-2", WSEvent{Type: "test", GameID: "game-2"})
+	activity := hub.TakeActivity()
+	if _, ok := activity["game-2"]; !ok || len(activity) != 1 {
+		t.Errorf("expected activity for game-2 only, got %v", activity)
+	}
+	if activity := hub.TakeActivity(); len(activity) != 0 {
+		t.Errorf("expected activity to be reset, got %v", activity)
+	}
+}
+
+// memEventLog is an in-memory repository.EventLog keeping the last size
+// events of each game.
+type memEventLog struct {
+	mu     sync.Mutex
+	size   int
+	seq    map[string]int64
+	events map[string][][]byte
+}
+
+func newMemEventLog(size int) *memEventLog {
+	return &memEventLog{size: size, seq: map[string]int64{}, events: map[string][][]byte{}}
+}
+
+func (l *memEventLog) NextEventSeq(_ context.Context, gameID string) (int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.seq[gameID]++
+	return l.seq[gameID], nil
+}
+
+func (l *memEventLog) AppendGameEvent(_ context.Context, gameID string, _ int64, event []byte) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	events := append(l.events[gameID], event)
+	l.events[gameID] = events[max(len(events)-l.size, 0):]
+	return nil
+}
+
+func (l *memEventLog) GameEventsSince(_ context.Context, gameID string, seq int64) ([][]byte, int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	latest := l.seq[gameID]
+	if seq > latest {
+		return nil, latest, repository.ErrEventsExpired
+	}
+	events := l.events[gameID]
+	missed := int(latest - seq)
+	if missed > len(events) {
+		return nil, latest, repository.ErrEventsExpired
+	}
+	return events[len(events)-missed:], latest, nil
+}
+
+func TestHubReplay(t *testing.T) {
+	hub := NewHub()
+	hub.SetEventLog(newMemEventLog(3))
+	c := newTestConn("user-1")
+	hub.Register(c)
+	defer hub.Unregister(c)
+
+	for i := 0; i < 4; i++ {
+		hub.BroadcastGameEvent("game-1", EventPhaseResolved, i)
+	}
+
+	// Resuming after seq 2 replays events 3 and 4.
+	hub.Subscribe(c, "game-1")
+	hub.Replay(context.Background(), c, "game-1", 2)
+	for _, want := range []int64{3, 4} {
+		var ev WSEvent
+		json.Unmarshal(<-c.send, &ev)
+		if ev.Seq != want || ev.Type != EventPhaseResolved {
+			t.Fatalf("expected replayed event %d, got %+v", want, ev)
+		}
+	}
+
+	// Event 1 has dropped out of the log, so the client must refetch.
+	hub.Replay(context.Background(), c, "game-1", 0)
+	var ev WSEvent
+	json.Unmarshal(<-c.send, &ev)
+	if ev.Type != EventResync || ev.Data.(map[string]any)["seq"] != float64(4) {
+		t.Fatalf("expected a resync at seq 4, got %+v", ev)
+	}
+
+	hub.BroadcastGameEvent("game-1", EventPhaseChanged, nil)
+	json.Unmarshal(<-c.send, &ev)
+	if ev.Seq != 5 {
+		t.Fatalf("expected live events to continue the sequence, got %+v", ev)
+	}
+}
+
+func TestHubBroadcastToLobby(t *testing.T) {
+	hub := NewHub()
+	lobby := newTestConn("user-1")
+	player := newTestConn("user-2") // follows the game only
+	hub.Register(lobby)
+	hub.Register(player)
+	defer hub.Unregister(player)
+
+	hub.SubscribeLobby(lobby)
+	hub.Subscribe(player, "game-1")
+	hub.BroadcastLobbyEvent("game-1", EventGameCreated, map[string]any{"name": "Test"})
+
+	select {
+	case data := <-lobby.send:
+		var event WSEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+		if event.Type != EventGameCreated || event.Topic != TopicLobby || event.GameID != "game-1" {
+			t.Errorf("unexpected lobby event %+v", event)
+		}
+		if event.Seq != 0 {
+			t.Errorf("expected lobby events to be unnumbered, got seq %d", event.Seq)
+		}
+	default:
+		t.Fatal("expected the lobby subscriber to get the event")
+	}
+	select {
+	case data := <-player.send:
+		t.Errorf("expected game subscribers not to get lobby events, got %s", data)
+	default:
+	}
+
+	hub.Unregister(lobby)
+	if n := hub.LobbySubscriberCount(); n != 0 {
+		t.Errorf("expected unregistering to leave the lobby, got %d subscribers", n)
+	}
+}
+
+// memRelay is an in-memory EventRelay shared by hubs standing in for
+// server instances.
+type memRelay struct {
+	mu   sync.Mutex
+	subs []chan []byte
+}
+
+func (r *memRelay) PublishEvent(_ context.Context, msg []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, ch := range r.subs {
+		ch <- msg
+	}
+	return nil
+}
+
+func (r *memRelay) SubscribeEvents(ctx context.Context) (<-chan []byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ch := make(chan []byte, 16)
+	r.subs = append(r.subs, ch)
+	go func() {
+		<-ctx.Done()
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.subs = slices.DeleteFunc(r.subs, func(c chan []byte) bool { return c == ch })
+		close(ch)
+	}()
+	return ch, nil
+}
+
+func TestHubRelaysBetweenInstances(t *testing.T) {
+	relay := &memRelay{}
+	leader, replica := NewHub(), NewHub()
+	leader.SetRelay(relay, "leader")
+	replica.SetRelay(relay, "replica")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go leader.RunRelay(ctx)
+	go replica.RunRelay(ctx)
+	for {
+		relay.mu.Lock()
+		n := len(relay.subs)
+		relay.mu.Unlock()
+		if n == 2 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	local, remote, user := newTestConn("user-1"), newTestConn("user-2"), newTestConn("user-3")
+	leader.Register(local)
+	replica.Register(remote)
+	replica.Register(user)
+	leader.Subscribe(local, "game-1")
+	replica.Subscribe(remote, "game-1")
+
+	leader.BroadcastGameEvent("game-1", EventPhaseChanged, nil)
+	leader.BroadcastUserEvent("game-1", "user-3", EventMessage, nil)
+
+	receive := func(c *WSConn) WSEvent {
+		t.Helper()
+		select {
+		case data := <-c.send:
+			var event WSEvent
+			json.Unmarshal(data, &event)
+			return event
+		case <-time.After(time.Second):
+			t.Fatalf("%s: expected an event", c.userID)
+			return WSEvent{}
+		}
+	}
+	if ev := receive(local); ev.Type != EventPhaseChanged {
+		t.Errorf("local subscriber: expected phase_changed, got %+v", ev)
+	}
+	if ev := receive(remote); ev.Type != EventPhaseChanged {
+		t.Errorf("subscriber on the other instance: expected phase_changed, got %+v", ev)
+	}
+	if ev := receive(user); ev.Type != EventMessage {
+		t.Errorf("user on the other instance: expected message, got %+v", ev)
+	}
+	select {
+	case data := <-local.send:
+		t.Errorf("expected the leader not to deliver its own relayed event again, got %s", data)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,315 @@
+// Package jobs runs background work from a Postgres-backed queue. Jobs
+// survive restarts, are shared between servers, are retried with backoff
+// when they fail, and end up dead-lettered for an admin to inspect once
+// they run out of attempts.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/internal/repository"
+)
+
+// ErrUnknownKind is returned when enqueuing a kind with no registered handler.
+var ErrUnknownKind = errors.New("no handler registered for job kind")
+
+// Handler runs one job. Returning an error retries the job after a backoff
+// until it runs out of attempts; wrap the error with Permanent to
+// dead-letter it straight away.
+type Handler func(ctx context.Context, payload json.RawMessage) error
+
+// Options configures a Queue.
+type Options struct {
+	Workers      int           // jobs run concurrently on this server
+	MaxAttempts  int           // attempts before a job is dead-lettered
+	JobTimeout   time.Duration // deadline for a single attempt
+	PollInterval time.Duration // how often idle workers check for due jobs
+	RetryBase    time.Duration // delay before the first retry, doubled each attempt
+	RetryMax     time.Duration // longest delay between attempts
+	Retention    time.Duration // how long finished jobs are kept
+}
+
+// DefaultOptions suits the post-game work the server queues: a handful of
+// slow jobs at a time, retried over roughly an hour.
+var DefaultOptions = Options{
+	Workers:      2,
+	MaxAttempts:  5,
+	JobTimeout:   5 * time.Minute,
+	PollInterval: 5 * time.Second,
+	RetryBase:    30 * time.Second,
+	RetryMax:     30 * time.Minute,
+	Retention:    7 * 24 * time.Hour,
+}
+
+// permanentError marks a failure that retrying can't fix.
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so the job is dead-lettered without further attempts,
+// e.g. for a malformed payload.
+func Permanent(err error) error {
+	return permanentError{err: err}
+}
+
+// IsPermanent reports whether err was wrapped with Permanent.
+func IsPermanent(err error) bool {
+	return errors.As(err, new(permanentError))
+}
+
+// Stats summarises the queue for admins.
+type Stats struct {
+	Workers int            `json:"workers"`
+	Kinds   []string       `json:"kinds"`
+	Counts  map[string]int `json:"counts"` // jobs by status, across all servers
+}
+
+// Queue enqueues jobs and runs the ones whose kinds have a handler
+// registered on this server.
+type Queue struct {
+	repo repository.JobRepository
+	opts Options
+
+	mu       sync.RWMutex
+	handlers map[string]Handler
+	wake     chan struct{}
+}
+
+// NewQueue creates a Queue. Zero options take their DefaultOptions value.
+// Register handlers, then call Start to run the workers.
+func NewQueue(repo repository.JobRepository, opts Options) *Queue {
+	if opts.Workers <= 0 {
+		opts.Workers = DefaultOptions.Workers
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = DefaultOptions.MaxAttempts
+	}
+	if opts.JobTimeout <= 0 {
+		opts.JobTimeout = DefaultOptions.JobTimeout
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultOptions.PollInterval
+	}
+	if opts.RetryBase <= 0 {
+		opts.RetryBase = DefaultOptions.RetryBase
+	}
+	if opts.RetryMax <= 0 {
+		opts.RetryMax = DefaultOptions.RetryMax
+	}
+	if opts.Retention <= 0 {
+		opts.Retention = DefaultOptions.Retention
+	}
+	return &Queue{
+		repo:     repo,
+		opts:     opts,
+		handlers: make(map[string]Handler),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Register sets the handler for a kind of job, replacing any previous one.
+func (q *Queue) Register(kind string, h Handler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers[kind] = h
+}
+
+// Enqueue adds a job to run as soon as a worker is free. The payload is
+// marshalled to JSON.
+func (q *Queue) Enqueue(ctx context.Context, kind string, payload any) (*model.Job, error) {
+	return q.EnqueueAt(ctx, kind, payload, time.Now())
+}
+
+// EnqueueAt adds a job that runs no earlier than runAt.
+func (q *Queue) EnqueueAt(ctx context.Context, kind string, payload any, runAt time.Time) (*model.Job, error) {
+	if q.handler(kind) == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKind, kind)
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("marshal %s payload: %w", kind, err)
+	}
+	job, err := q.repo.Enqueue(ctx, kind, data, q.opts.MaxAttempts, runAt)
+	if err != nil {
+		return nil, err
+	}
+	if !runAt.After(time.Now()) {
+		select {
+		case q.wake <- struct{}{}:
+		default:
+		}
+	}
+	return job, nil
+}
+
+// MaxAttempts returns how many times a job is attempted before it is
+// dead-lettered.
+func (q *Queue) MaxAttempts() int {
+	return q.opts.MaxAttempts
+}
+
+// Start runs the workers and the stale job reaper until ctx is cancelled.
+// Jobs running then are abandoned and retried by the reaper later.
+func (q *Queue) Start(ctx context.Context) {
+	for range q.opts.Workers {
+		go q.work(ctx)
+	}
+	go q.reap(ctx)
+}
+
+// Stats reports the registered kinds and job counts.
+func (q *Queue) Stats(ctx context.Context) (Stats, error) {
+	counts, err := q.repo.CountByStatus(ctx)
+	if err != nil {
+		return Stats{}, err
+	}
+	return Stats{Workers: q.opts.Workers, Kinds: q.kinds(), Counts: counts}, nil
+}
+
+// List returns recent jobs with the given status, or any status if empty.
+func (q *Queue) List(ctx context.Context, status string, limit int) ([]model.Job, error) {
+	return q.repo.List(ctx, status, limit)
+}
+
+// Retry requeues a dead job with a fresh set of attempts. It reports false
+// if there is no such dead job.
+func (q *Queue) Retry(ctx context.Context, id string) (bool, error) {
+	ok, err := q.repo.Retry(ctx, id)
+	if ok {
+		select {
+		case q.wake <- struct{}{}:
+		default:
+		}
+	}
+	return ok, err
+}
+
+func (q *Queue) handler(kind string) Handler {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return q.handlers[kind]
+}
+
+func (q *Queue) kinds() []string {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	kinds := make([]string, 0, len(q.handlers))
+	for k := range q.handlers {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// work runs due jobs until none are left, then sleeps until the next poll
+// or a local enqueue.
+func (q *Queue) work(ctx context.Context) {
+	ticker := time.NewTicker(q.opts.PollInterval)
+	defer ticker.Stop()
+	for {
+		for ctx.Err() == nil && q.RunOne(ctx) {
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-q.wake:
+		}
+	}
+}
+
+// RunOne claims and runs a single due job, reporting whether there was one.
+// Workers call it in a loop; it is exported for tools and tests that drain
+// the queue synchronously.
+func (q *Queue) RunOne(ctx context.Context) bool {
+	job, err := q.repo.Claim(ctx, q.kinds())
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Error().Err(err).Msg("Failed to claim job")
+		}
+		return false
+	}
+	if job == nil {
+		return false
+	}
+
+	err = q.run(ctx, job)
+	// Record the outcome even if ctx was cancelled mid-job.
+	recordCtx := context.WithoutCancel(ctx)
+	switch {
+	case err == nil:
+		if err := q.repo.Complete(recordCtx, job.ID); err != nil {
+			log.Error().Err(err).Str("jobId", job.ID).Msg("Failed to complete job")
+		}
+	case IsPermanent(err) || job.Attempts >= job.MaxAttempts:
+		log.Error().Err(err).Str("jobId", job.ID).Str("kind", job.Kind).Int("attempts", job.Attempts).Msg("Job dead-lettered")
+		if err := q.repo.Bury(recordCtx, job.ID, err.Error()); err != nil {
+			log.Error().Err(err).Str("jobId", job.ID).Msg("Failed to bury job")
+		}
+	default:
+		retryAt := time.Now().Add(q.backoff(job.Attempts))
+		log.Warn().Err(err).Str("jobId", job.ID).Str("kind", job.Kind).Int("attempts", job.Attempts).Time("retryAt", retryAt).Msg("Job failed, will retry")
+		if err := q.repo.Reschedule(recordCtx, job.ID, err.Error(), retryAt); err != nil {
+			log.Error().Err(err).Str("jobId", job.ID).Msg("Failed to reschedule job")
+		}
+	}
+	return true
+}
+
+// run calls the job's handler with the job timeout, turning panics into
+// errors so one bad job can't take down the worker.
+func (q *Queue) run(ctx context.Context, job *model.Job) (err error) {
+	h := q.handler(job.Kind)
+	if h == nil {
+		return Permanent(fmt.Errorf("%w: %s", ErrUnknownKind, job.Kind))
+	}
+	ctx, cancel := context.WithTimeout(ctx, q.opts.JobTimeout)
+	defer cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return h(ctx, job.Payload)
+}
+
+// backoff returns the delay after the given failed attempt.
+func (q *Queue) backoff(attempt int) time.Duration {
+	d := q.opts.RetryBase
+	for i := 1; i < attempt && d < q.opts.RetryMax; i++ {
+		d *= 2
+	}
+	return min(d, q.opts.RetryMax)
+}
+
+// reap periodically requeues jobs whose worker died mid-run and deletes
+// finished jobs past their retention.
+func (q *Queue) reap(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if n, err := q.repo.RequeueStale(ctx, time.Now().Add(-2*q.opts.JobTimeout)); err != nil {
+			log.Error().Err(err).Msg("Failed to requeue stale jobs")
+		} else if n > 0 {
+			log.Warn().Int64("jobs", n).Msg("Requeued jobs abandoned by their worker")
+		}
+		if _, err := q.repo.DeleteDone(ctx, time.Now().Add(-q.opts.Retention)); err != nil {
+			log.Error().Err(err).Msg("Failed to delete finished jobs")
+		}
+	}
+}
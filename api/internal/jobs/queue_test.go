@@ -0,0 +1,268 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+)
+
+// memRepo is an in-memory JobRepository.
+type memRepo struct {
+	mu   sync.Mutex
+	jobs []*model.Job
+}
+
+func (m *memRepo) Enqueue(_ context.Context, kind string, payload json.RawMessage, maxAttempts int, runAt time.Time) (*model.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j := &model.Job{ID: fmt.Sprintf("job-%d", len(m.jobs)+1), Kind: kind, Payload: payload,
+		Status: model.JobPending, MaxAttempts: maxAttempts, RunAt: runAt, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	m.jobs = append(m.jobs, j)
+	cp := *j
+	return &cp, nil
+}
+
+func (m *memRepo) Claim(_ context.Context, kinds []string) (*model.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, j := range m.jobs {
+		if j.Status == model.JobPending && !j.RunAt.After(time.Now()) && slices.Contains(kinds, j.Kind) {
+			j.Status = model.JobRunning
+			j.Attempts++
+			cp := *j
+			return &cp, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memRepo) find(id string) *model.Job {
+	for _, j := range m.jobs {
+		if j.ID == id {
+			return j
+		}
+	}
+	return nil
+}
+
+func (m *memRepo) Complete(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.find(id).Status = model.JobDone
+	return nil
+}
+
+func (m *memRepo) Reschedule(_ context.Context, id, lastError string, runAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j := m.find(id)
+	j.Status, j.LastError, j.RunAt = model.JobPending, lastError, runAt
+	return nil
+}
+
+func (m *memRepo) Bury(_ context.Context, id, lastError string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j := m.find(id)
+	j.Status, j.LastError = model.JobDead, lastError
+	return nil
+}
+
+func (m *memRepo) RequeueStale(context.Context, time.Time) (int64, error) { return 0, nil }
+
+func (m *memRepo) Retry(_ context.Context, id string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j := m.find(id)
+	if j == nil || j.Status != model.JobDead {
+		return false, nil
+	}
+	j.Status, j.Attempts, j.RunAt = model.JobPending, 0, time.Now()
+	return true, nil
+}
+
+func (m *memRepo) DeleteDone(context.Context, time.Time) (int64, error) { return 0, nil }
+
+func (m *memRepo) List(_ context.Context, status string, limit int) ([]model.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []model.Job
+	for _, j := range m.jobs {
+		if status == "" || j.Status == status {
+			result = append(result, *j)
+		}
+	}
+	return result[:min(limit, len(result))], nil
+}
+
+func (m *memRepo) CountByStatus(context.Context) (map[string]int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	counts := make(map[string]int)
+	for _, j := range m.jobs {
+		counts[j.Status]++
+	}
+	return counts, nil
+}
+
+// get returns a copy of the job, for inspecting its state.
+func (m *memRepo) get(id string) model.Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return *m.find(id)
+}
+
+// makeDue moves a rescheduled job's run time to now.
+func (m *memRepo) makeDue(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.find(id).RunAt = time.Now()
+}
+
+func TestQueueRunsJobs(t *testing.T) {
+	ctx := context.Background()
+	repo := &memRepo{}
+	q := NewQueue(repo, Options{})
+
+	var got []string
+	q.Register("greet", func(_ context.Context, payload json.RawMessage) error {
+		var p struct{ Name string }
+		if err := json.Unmarshal(payload, &p); err != nil {
+			return Permanent(err)
+		}
+		got = append(got, p.Name)
+		return nil
+	})
+
+	if _, err := q.Enqueue(ctx, "unknown", nil); !errors.Is(err, ErrUnknownKind) {
+		t.Errorf("expected ErrUnknownKind, got %v", err)
+	}
+	job, err := q.Enqueue(ctx, "greet", map[string]string{"Name": "Vienna"})
+	if err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	later, _ := q.EnqueueAt(ctx, "greet", map[string]string{"Name": "Paris"}, time.Now().Add(time.Hour))
+
+	for q.RunOne(ctx) {
+	}
+	if !slices.Equal(got, []string{"Vienna"}) {
+		t.Errorf("expected only the due job to run, got %v", got)
+	}
+	if s := repo.get(job.ID).Status; s != model.JobDone {
+		t.Errorf("expected job done, got %s", s)
+	}
+	if s := repo.get(later.ID).Status; s != model.JobPending {
+		t.Errorf("expected the delayed job to stay pending, got %s", s)
+	}
+}
+
+func TestQueueRetriesAndDeadLetters(t *testing.T) {
+	ctx := context.Background()
+	repo := &memRepo{}
+	q := NewQueue(repo, Options{MaxAttempts: 3, RetryBase: time.Minute, RetryMax: 3 * time.Minute})
+
+	calls := 0
+	q.Register("flaky", func(context.Context, json.RawMessage) error {
+		calls++
+		if calls == 2 {
+			panic("boom")
+		}
+		return errors.New("unavailable")
+	})
+	job, _ := q.Enqueue(ctx, "flaky", nil)
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		start := time.Now()
+		if !q.RunOne(ctx) {
+			t.Fatalf("attempt %d: expected the job to run", attempt)
+		}
+		j := repo.get(job.ID)
+		if attempt < 3 {
+			if j.Status != model.JobPending {
+				t.Fatalf("attempt %d: expected a retry, got %s", attempt, j.Status)
+			}
+			if want := q.backoff(attempt); j.RunAt.Before(start.Add(want)) {
+				t.Errorf("attempt %d: expected a backoff of %s, retry at %s", attempt, want, j.RunAt.Sub(start))
+			}
+			if q.RunOne(ctx) {
+				t.Fatalf("attempt %d: expected the retry to wait for its backoff", attempt)
+			}
+			repo.makeDue(job.ID)
+		} else if j.Status != model.JobDead || j.LastError != "unavailable" {
+			t.Fatalf("expected the job dead-lettered after 3 attempts, got %+v", j)
+		}
+	}
+	if got := q.backoff(5); got != 3*time.Minute {
+		t.Errorf("expected backoff capped at 3m, got %s", got)
+	}
+
+	// An admin retry gives the job a fresh set of attempts.
+	if ok, _ := q.Retry(ctx, job.ID); !ok {
+		t.Fatal("expected the dead job to be retried")
+	}
+	if ok, _ := q.Retry(ctx, job.ID); ok {
+		t.Error("expected a pending job not to be retried")
+	}
+	if !q.RunOne(ctx) || repo.get(job.ID).Attempts != 1 {
+		t.Errorf("expected the retried job to run again, got %+v", repo.get(job.ID))
+	}
+}
+
+func TestQueuePermanentFailure(t *testing.T) {
+	ctx := context.Background()
+	repo := &memRepo{}
+	q := NewQueue(repo, Options{})
+	q.Register("strict", func(context.Context, json.RawMessage) error {
+		return Permanent(errors.New("bad payload"))
+	})
+	job, _ := q.Enqueue(ctx, "strict", nil)
+
+	q.RunOne(ctx)
+	if j := repo.get(job.ID); j.Status != model.JobDead || j.Attempts != 1 {
+		t.Errorf("expected the job dead-lettered on its first attempt, got %+v", j)
+	}
+
+	stats, err := q.Stats(ctx)
+	if err != nil || stats.Counts[model.JobDead] != 1 || !slices.Equal(stats.Kinds, []string{"strict"}) {
+		t.Errorf("unexpected stats %+v, %v", stats, err)
+	}
+}
+
+func TestQueueWorkers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	repo := &memRepo{}
+	q := NewQueue(repo, Options{Workers: 2, PollInterval: time.Hour})
+	done := make(chan string, 4)
+	q.Register("echo", func(_ context.Context, payload json.RawMessage) error {
+		done <- string(payload)
+		return nil
+	})
+	q.Start(ctx)
+
+	// Enqueueing wakes an idle worker without waiting for the poll.
+	for i := range 3 {
+		if _, err := q.Enqueue(ctx, "echo", i); err != nil {
+			t.Fatalf("Enqueue: %v", err)
+		}
+	}
+	seen := map[string]bool{}
+	for range 3 {
+		select {
+		case p := <-done:
+			seen[p] = true
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for jobs, ran %v", seen)
+		}
+	}
+	if len(seen) != 3 {
+		t.Errorf("expected three distinct jobs, got %v", seen)
+	}
+}
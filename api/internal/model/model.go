@@ -12,37 +12,128 @@ type User struct {
 	ProviderID  string    `json:"provider_id"`
 	DisplayName string    `json:"display_name"`
 	AvatarURL   string    `json:"avatar_url,omitempty"`
+	Locale      string    `json:"locale"`
+	SharePress  bool      `json:"share_press"` // consents to quoting their press in game reports
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
+// APIKey is a token for the public read-only API. Only a hash of the key is
+// stored; the key itself is shown once, when it is created.
+type APIKey struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"user_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"` // first characters of the key, to tell keys apart
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
 // Game represents a Diplomacy game.
 type Game struct {
-	ID              string       `json:"id"`
-	Name            string       `json:"name"`
-	CreatorID       string       `json:"creator_id"`
-	Status          string       `json:"status"` // waiting, active, finished
-	Winner          string       `json:"winner,omitempty"`
-	TurnDuration    string       `json:"turn_duration"`
-	RetreatDuration string       `json:"retreat_duration"`
-	BuildDuration   string       `json:"build_duration"`
-	PowerAssignment string       `json:"power_assignment"`
-	CreatedAt       time.Time    `json:"created_at"`
-	StartedAt       *time.Time   `json:"started_at,omitempty"`
-	FinishedAt      *time.Time   `json:"finished_at,omitempty"`
-	Players         []GamePlayer `json:"players,omitempty"`
-	ReadyCount      int          `json:"ready_count,omitempty"`
-	DrawVoteCount   int          `json:"draw_vote_count,omitempty"`
+	ID                 string       `json:"id"`
+	Name               string       `json:"name"`
+	CreatorID          string       `json:"creator_id"`
+	Status             string       `json:"status"` // waiting, active, finished, deleted
+	Winner             string       `json:"winner,omitempty"`
+	TurnDuration       string       `json:"turn_duration"`
+	RetreatDuration    string       `json:"retreat_duration"`
+	BuildDuration      string       `json:"build_duration"`
+	PowerAssignment    string       `json:"power_assignment"`
+	SpeedPreset        string       `json:"speed_preset,omitempty"`         // blitz, live, async; empty for custom durations
+	Scenario           string       `json:"scenario"`                       // standard or a duel such as france-austria
+	BotPress           string       `json:"bot_press,omitempty"`            // personality, natural or terse; set by FindByID only
+	PressMode          string       `json:"press_mode,omitempty"`           // full, public_only or none; set by FindByID only
+	Anonymous          bool         `json:"anonymous,omitempty"`            // players are shown only by power until the game ends; set by FindByID only
+	Garrisons          bool         `json:"garrisons,omitempty"`            // neutral centers start with hold-only armies; set by FindByID only
+	RetreatCredits     bool         `json:"retreat_credits,omitempty"`      // disbanded retreats may be rebuilt at the next adjustment
+	MercyYears         int          `json:"mercy_years,omitempty"`          // years bots spare the human players' home centers; 0 = off; set by FindByID only
+	ReadyQuorum        int          `json:"ready_quorum,omitempty"`         // percent of powers whose readiness resolves a movement phase early; 0 = off
+	QuorumDelay        string       `json:"quorum_delay,omitempty"`         // how long the quorum must hold first, as a Postgres interval
+	CivilDisorderAfter int          `json:"civil_disorder_after,omitempty"` // missed deadlines in a row that put a player in civil disorder; 0 = never
+	CivilDisorderBot   string       `json:"civil_disorder_bot,omitempty"`   // strategy of the bot that replaces a player in civil disorder; empty = none
+	CreatedAt          time.Time    `json:"created_at"`
+	StartedAt          *time.Time   `json:"started_at,omitempty"`
+	FinishedAt         *time.Time   `json:"finished_at,omitempty"`
+	DeletedAt          *time.Time   `json:"deleted_at,omitempty"`
+	Players            []GamePlayer `json:"players,omitempty"`
+	Spectators         []string     `json:"spectators,omitempty"` // user IDs; set by FindByID only
+	ReadyCount         int          `json:"ready_count,omitempty"`
+	DrawVoteCount      int          `json:"draw_vote_count,omitempty"`
+	DrawVotes          []string     `json:"draw_votes,omitempty"` // powers voting for a draw this phase
+}
+
+// GameRules holds the house rules a game is created with.
+type GameRules struct {
+	RetreatCredits     bool   // disbanded retreats may be rebuilt at the next adjustment
+	CivilDisorderAfter int    // missed deadlines in a row that put a player in civil disorder; 0 = never
+	CivilDisorderBot   string // strategy of the bot that replaces a player in civil disorder; empty = none
+	ReadyQuorum        int    // percent of human powers whose readiness resolves a movement phase early; 0 = off
+	QuorumDelay        string // how long the quorum must hold first, as a Go duration; empty = DefaultQuorumDelay
 }
 
+// Press modes: which press players may send in a game.
+const (
+	PressFull       = "full"        // public, private and channel press
+	PressPublicOnly = "public_only" // public press only
+	PressNone       = "none"        // gunboat: no press at all
+)
+
 // GamePlayer represents a player's membership in a game.
 type GamePlayer struct {
-	GameID        string    `json:"game_id"`
-	UserID        string    `json:"user_id"`
-	Power         string    `json:"power,omitempty"`
-	IsBot         bool      `json:"is_bot"`
-	BotDifficulty string    `json:"bot_difficulty"`
-	JoinedAt      time.Time `json:"joined_at"`
+	GameID          string          `json:"game_id"`
+	UserID          string          `json:"user_id"`
+	Power           string          `json:"power,omitempty"`
+	IsBot           bool            `json:"is_bot"`
+	BotDifficulty   string          `json:"bot_difficulty"`
+	BotPersonality  *BotPersonality `json:"bot_personality,omitempty"` // nil plays the strategy's usual game
+	JoinedAt        time.Time       `json:"joined_at"`
+	MissedDeadlines int             `json:"missed_deadlines,omitempty"` // deadlines missed in a row
+	CivilDisorder   bool            `json:"civil_disorder,omitempty"`   // missed the game's CivilDisorderAfter deadlines in a row
+	EliminatedBy    string          `json:"eliminated_by,omitempty"`    // the power that took most of its last centers
+	EliminatedYear  int             `json:"eliminated_year,omitempty"`  // year it lost its last supply center
+}
+
+// BotPersonality varies how a bot player plays within its strategy. Each
+// trait runs from -1 to 1; 0 is the strategy's usual play.
+type BotPersonality struct {
+	Aggression    float64 `json:"aggression"`
+	Loyalty       float64 `json:"loyalty"`
+	RiskTolerance float64 `json:"risk_tolerance"`
+	Talkativeness float64 `json:"talkativeness"`
+}
+
+// Reasons a bot's difficulty changed.
+const (
+	BotDifficultyByCreator    = "creator"     // the game's creator chose it
+	BotDifficultyByComputeCap = "compute_cap" // downgraded to stay within the game's compute cap
+)
+
+// BotDifficultyChange records a change of a bot player's strategy. A change
+// requested mid-game is pending, with no AppliedAt, until the next phase
+// starts; PhaseID is the phase in play once it took effect.
+type BotDifficultyChange struct {
+	ID          int64      `json:"id"`
+	GameID      string     `json:"game_id"`
+	UserID      string     `json:"user_id"`
+	Power       string     `json:"power,omitempty"`
+	From        string     `json:"from"`
+	To          string     `json:"to"`
+	Reason      string     `json:"reason"`
+	ChangedBy   string     `json:"changed_by,omitempty"`
+	PhaseID     string     `json:"phase_id,omitempty"`
+	RequestedAt time.Time  `json:"requested_at"`
+	AppliedAt   *time.Time `json:"applied_at,omitempty"`
+}
+
+// PowerAssignment records a player taking over a power: when the game
+// started, or when a bot replaced a human.
+type PowerAssignment struct {
+	Power      string    `json:"power"`
+	UserID     string    `json:"user_id"`
+	IsBot      bool      `json:"is_bot"`
+	AssignedAt time.Time `json:"assigned_at"`
 }
 
 // Phase represents a game phase (movement, retreat, or build).
@@ -73,15 +164,336 @@ type Order struct {
 	AuxUnitType string    `json:"aux_unit_type,omitempty"`
 	Result      string    `json:"result,omitempty"`
 	CreatedAt   time.Time `json:"created_at"`
+	Warnings    []string  `json:"warnings,omitempty"` // non-blocking hints, not persisted
+
+	// Set when the server replaced an invalid submitted order with a hold:
+	// the order as submitted (DSON) and why it was rejected.
+	SubmittedOrder  string `json:"submitted_order,omitempty"`
+	DowngradeReason string `json:"downgrade_reason,omitempty"`
+}
+
+// Submission is what a power has submitted so far in an unresolved phase:
+// its orders in the live order encoding, nil if none, and whether it is
+// ready.
+type Submission struct {
+	PhaseID string          `json:"phase_id"`
+	Power   string          `json:"power"`
+	Orders  json.RawMessage `json:"orders,omitempty"`
+	Ready   bool            `json:"ready"`
+}
+
+// PhaseEvaluation is one power's standing in the position after a phase
+// resolved, or at the start of a phase still being played. It drives the
+// replay evaluation bar and the live win odds.
+type PhaseEvaluation struct {
+	PhaseID   string   `json:"phase_id"`
+	Power     string   `json:"power"`
+	Heuristic float64  `json:"heuristic"`
+	Share     float64  `json:"share"`               // fraction of the eval bar, 0..1
+	Neural    *float64 `json:"neural,omitempty"`    // set when a value network was loaded
+	WinProb   *float64 `json:"win_prob,omitempty"`  // value network win probability
+	DrawProb  *float64 `json:"draw_prob,omitempty"` // value network draw probability
+}
+
+// OrderReview compares the orders a human player gave in a movement phase
+// with those the review strategy would have given, with every other power's
+// orders as played. Evals are the power's heuristic share of the board once
+// the phase resolves.
+type OrderReview struct {
+	PhaseID       string  `json:"phase_id"`
+	Power         string  `json:"power"`
+	Orders        string  `json:"orders"`         // as played, in DSON
+	Suggested     string  `json:"suggested"`      // the review strategy's orders, in DSON
+	Eval          float64 `json:"eval"`           // after the played orders
+	SuggestedEval float64 `json:"suggested_eval"` // after the suggested orders
+	Delta         float64 `json:"delta"`          // SuggestedEval - Eval
+	Blunder       bool    `json:"blunder"`
 }
 
 // Message represents an in-game diplomacy message.
 type Message struct {
-	ID          string    `json:"id"`
-	GameID      string    `json:"game_id"`
-	SenderID    string    `json:"sender_id"`
-	RecipientID string    `json:"recipient_id,omitempty"` // empty = public broadcast
-	Content     string    `json:"content"`
-	PhaseID     string    `json:"phase_id,omitempty"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID          string         `json:"id"`
+	GameID      string         `json:"game_id"`
+	SenderID    string         `json:"sender_id"`
+	RecipientID string         `json:"recipient_id,omitempty"` // empty = public broadcast or channel
+	ChannelID   string         `json:"channel_id,omitempty"`   // set for press channel messages
+	Content     string         `json:"content"`
+	PhaseID     string         `json:"phase_id,omitempty"`
+	Intent      *MessageIntent `json:"intent,omitempty"` // set for canned press
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+// Channel is a press channel: a group of players in a game, e.g. an
+// alliance, whose messages only its members see.
+type Channel struct {
+	ID        string    `json:"id"`
+	GameID    string    `json:"game_id"`
+	Name      string    `json:"name"`
+	CreatedBy string    `json:"created_by"`
+	Members   []string  `json:"members"` // user IDs
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// MessageIntent is the machine-readable form of a canned press message, kept
+// alongside the rendered text so clients can re-render it in their own locale.
+type MessageIntent struct {
+	Type        string   `json:"type"` // e.g. "request_support", "offer_deal"
+	Provinces   []string `json:"provinces,omitempty"`
+	TargetPower string   `json:"target_power,omitempty"`
+	Orders      string   `json:"orders,omitempty"` // proposed order set in DSON, e.g. "A mun - bur ; A ruh S A mun - bur"
+}
+
+// Achievement kinds awarded at game end.
+const (
+	AchievementFirstSolo     = "first_solo"      // a user's first solo victory
+	AchievementEarlySolo     = "early_solo"      // reached 18 SCs by 1907
+	AchievementLastStandDraw = "last_stand_draw" // held on with a single SC until a draw
+	AchievementAustrianSolo  = "austrian_solo"   // won a solo as Austria
+)
+
+// Achievement is an accomplishment a user earned in a finished game.
+type Achievement struct {
+	UserID        string    `json:"user_id"`
+	Kind          string    `json:"kind"`
+	GameID        string    `json:"game_id"`
+	Power         string    `json:"power"`
+	Year          int       `json:"year"`
+	SupplyCenters int       `json:"supply_centers"`
+	AwardedAt     time.Time `json:"awarded_at"`
+}
+
+// GameRecord is a solo victory kept for the hall of fame.
+type GameRecord struct {
+	GameID        string    `json:"game_id"`
+	GameName      string    `json:"game_name"`
+	WinnerID      string    `json:"winner_id"`
+	DisplayName   string    `json:"display_name"`
+	Power         string    `json:"power"`
+	Year          int       `json:"year"`
+	SupplyCenters int       `json:"supply_centers"`
+	FinishedAt    time.Time `json:"finished_at"`
+}
+
+// AchieverCount is a user's total number of achievements.
+type AchieverCount struct {
+	UserID       string `json:"user_id"`
+	DisplayName  string `json:"display_name"`
+	Achievements int    `json:"achievements"`
+}
+
+// OpeningTally is how many finished games a power opened with one set of
+// Spring 1901 orders, and how many of those games it won.
+type OpeningTally struct {
+	Power  string  `json:"power"`
+	Orders []Order `json:"orders"`
+	Games  int     `json:"games"`
+	Wins   int     `json:"wins"`
+}
+
+// Rating subject types: humans are rated per account, bots per strategy
+// since bot accounts are shared across games.
+const (
+	RatingSubjectUser     = "user"
+	RatingSubjectStrategy = "strategy"
+)
+
+// Rating is the Elo rating of a user or bot strategy.
+type Rating struct {
+	SubjectType string    `json:"subject_type"`
+	SubjectID   string    `json:"subject_id"`
+	DisplayName string    `json:"display_name,omitempty"` // users only
+	Rating      float64   `json:"rating"`
+	Games       int       `json:"games"`
+	Wins        int       `json:"wins"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// RatingUpdate is one subject's rating change from a single game.
+type RatingUpdate struct {
+	SubjectType string
+	SubjectID   string
+	Initial     float64 // rating to start from if the subject is unrated
+	Delta       float64
+	Won         bool
+}
+
+// Leaderboard ranks users and bot strategies by rating.
+type Leaderboard struct {
+	Users      []Rating `json:"users"`
+	Strategies []Rating `json:"strategies"`
+}
+
+// BotCompute is the time one bot spent generating its orders for a phase.
+type BotCompute struct {
+	GameID    string    `json:"game_id"`
+	PhaseID   string    `json:"phase_id"`
+	Power     string    `json:"power"`
+	Strategy  string    `json:"strategy"`
+	ComputeMS int64     `json:"compute_ms"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// GameActivity is how many are watching a game and when it last had an
+// event; a zero LastActivity means none recently.
+type GameActivity struct {
+	GameID       string
+	Viewers      int
+	LastActivity time.Time
+}
+
+// LiveGame is an active or paused game with its activity. BotOnly reports
+// whether every player is a bot.
+type LiveGame struct {
+	GameActivity
+	Name      string
+	Status    string
+	Scenario  string
+	Anonymous bool
+	BotOnly   bool
+}
+
+// GameCompute totals the bot compute time a game has used.
+type GameCompute struct {
+	GameID    string    `json:"game_id"`
+	GameName  string    `json:"game_name"`
+	ComputeMS int64     `json:"compute_ms"`
+	Phases    int       `json:"phases"`
+	LastAt    time.Time `json:"last_at"`
+}
+
+// Variant is an uploaded custom variant. Each upload is a new Version;
+// games play the version they were created with. Definition holds the full
+// diplomacy.VariantDefinition and is omitted from listings.
+type Variant struct {
+	Name        string          `json:"name"`
+	Version     int             `json:"version"`
+	Description string          `json:"description"`
+	Playable    bool            `json:"playable"`
+	CreatedBy   string          `json:"created_by,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	Definition  json.RawMessage `json:"definition,omitempty"`
+}
+
+// HallOfFame lists the server's record games and most decorated players.
+type HallOfFame struct {
+	FastestSolos []GameRecord    `json:"fastest_solos"`
+	TopAchievers []AchieverCount `json:"top_achievers"`
+}
+
+// UserStats summarizes a user's finished games and achievements.
+type UserStats struct {
+	UserID        string        `json:"user_id"`
+	GamesPlayed   int           `json:"games_played"`
+	GamesFinished int           `json:"games_finished"`
+	Solos         int           `json:"solos"`
+	Draws         int           `json:"draws"`
+	Achievements  []Achievement `json:"achievements"`
+}
+
+// GameSummary is the end-of-game artifact: final map, supply-center graph
+// and per-power statistics, generated once when a game finishes.
+type GameSummary struct {
+	GameID     string           `json:"game_id"`
+	Winner     string           `json:"winner,omitempty"` // empty = draw
+	FinalYear  int              `json:"final_year"`
+	Powers     []PowerSummary   `json:"powers"`
+	SCHistory  []SCHistoryPoint `json:"sc_history"`
+	MapSVG     string           `json:"map_svg"`
+	SCGraphSVG string           `json:"sc_graph_svg"`
+	CreatedAt  time.Time        `json:"created_at"`
+}
+
+// PowerSummary is one power's statistics for a GameSummary.
+type PowerSummary struct {
+	Power           string `json:"power"`
+	UserID          string `json:"user_id,omitempty"`
+	IsBot           bool   `json:"is_bot"`
+	FinalCenters    int    `json:"final_centers"`
+	PeakCenters     int    `json:"peak_centers"`
+	PeakYear        int    `json:"peak_year"`
+	EliminatedYear  int    `json:"eliminated_year,omitempty"`
+	EliminatedBy    string `json:"eliminated_by,omitempty"`
+	EliminationRank int    `json:"elimination_rank,omitempty"` // 1 for the first power out; powers out the same year share a rank
+	OrdersIssued    int    `json:"orders_issued"`
+	OrdersSucceeded int    `json:"orders_succeeded"`
+}
+
+// SCHistoryPoint is each power's supply-center count at the end of a year.
+type SCHistoryPoint struct {
+	Year    int            `json:"year"`
+	Centers map[string]int `json:"centers"`
+}
+
+// Job statuses.
+const (
+	JobPending = "pending" // waiting for run_at or a free worker
+	JobRunning = "running"
+	JobDone    = "done"
+	JobDead    = "dead" // out of attempts or failed permanently; retried only by an admin
+)
+
+// Job is a unit of background work in the jobs queue.
+type Job struct {
+	ID          string          `json:"id"`
+	Kind        string          `json:"kind"`
+	Payload     json.RawMessage `json:"payload"`
+	Status      string          `json:"status"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts"`
+	LastError   string          `json:"last_error,omitempty"`
+	RunAt       time.Time       `json:"run_at"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// Events a user can have notifications sent for.
+const (
+	NotifyDeadline      = "deadline"       // a phase deadline is an hour away
+	NotifyPhaseResolved = "phase_resolved" // a phase of one of the user's games resolved
+	NotifyOrdersMissing = "orders_missing" // a deadline is near and the user has no orders in
+	NotifyEliminated    = "eliminated"     // a power in one of the user's games lost its last center
+)
+
+// Kinds of notification channel.
+const (
+	NotifyByWebhook = "webhook" // a JSON POST to an HTTPS URL
+	NotifyByDiscord = "discord" // a Discord webhook
+	NotifyByEmail   = "email"
+)
+
+// NotificationChannel is somewhere a user has notifications sent, and the
+// events they want there.
+type NotificationChannel struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	Kind      string    `json:"kind"`
+	Target    string    `json:"target"` // URL or email address
+	Events    []string  `json:"events"`
+	Enabled   bool      `json:"enabled"`
+	Confirmed bool      `json:"confirmed"` // email channels send nothing until confirmed
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Notification delivery statuses.
+const (
+	NotificationPending = "pending" // not yet delivered; retried until it is or it fails
+	NotificationSent    = "sent"
+	NotificationFailed  = "failed" // gave up, see LastError
+)
+
+// Notification is one message for a user over one of their channels.
+type Notification struct {
+	ID        string     `json:"id"`
+	UserID    string     `json:"user_id"`
+	ChannelID string     `json:"channel_id"`
+	Event     string     `json:"event"`
+	GameID    string     `json:"game_id"`
+	PhaseID   string     `json:"phase_id,omitempty"`
+	Message   string     `json:"message"`
+	Status    string     `json:"status"`
+	Attempts  int        `json:"attempts"`
+	LastError string     `json:"last_error,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	SentAt    *time.Time `json:"sent_at,omitempty"`
 }
@@ -0,0 +1,196 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Page geometry of PDF documents, in points: US Letter with one-inch margins.
+const (
+	pageWidth, pageHeight = 612, 792
+	pageMargin            = 72
+)
+
+// PDF fonts, all standard Type 1 fonts every reader provides.
+const (
+	fontRegular = "F1" // Helvetica
+	fontBold    = "F2" // Helvetica-Bold
+	fontItalic  = "F3" // Helvetica-Oblique
+)
+
+// helveticaWidths are the advance widths of ASCII 32-126 in Helvetica, in
+// thousandths of the font size.
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// PDF builds a simple paginated text document of headings and wrapped
+// paragraphs. Text outside Latin-1 is replaced with '?'; there is no
+// support for images.
+type PDF struct {
+	pages []*bytes.Buffer
+	y     float64 // baseline of the next line on the last page
+}
+
+// NewPDF creates an empty document.
+func NewPDF() *PDF {
+	return &PDF{}
+}
+
+// Heading adds a section heading.
+func (p *PDF) Heading(text string) {
+	p.space(12)
+	p.lines(fontBold, 16, 0, text)
+	p.space(4)
+}
+
+// Subheading adds a heading within a section.
+func (p *PDF) Subheading(text string) {
+	p.space(8)
+	p.lines(fontBold, 12, 0, text)
+	p.space(2)
+}
+
+// Paragraph adds a paragraph of body text.
+func (p *PDF) Paragraph(text string) {
+	p.lines(fontRegular, 10, 0, text)
+	p.space(4)
+}
+
+// Quote adds an indented paragraph in italics.
+func (p *PDF) Quote(text string) {
+	p.lines(fontItalic, 10, 18, text)
+	p.space(4)
+}
+
+// space leaves a vertical gap, unless at the top of a page.
+func (p *PDF) space(points float64) {
+	if len(p.pages) > 0 && p.y < pageHeight-pageMargin {
+		p.y -= points
+	}
+}
+
+// lines wraps text to the page width and writes it, starting new pages as
+// needed.
+func (p *PDF) lines(font string, size, indent float64, text string) {
+	lead := size * 1.3
+	for _, line := range wrapText(text, font, size, pageWidth-2*pageMargin-indent) {
+		if len(p.pages) == 0 || p.y-lead < pageMargin {
+			p.pages = append(p.pages, new(bytes.Buffer))
+			p.y = pageHeight - pageMargin + lead - size
+		}
+		p.y -= lead
+		fmt.Fprintf(p.pages[len(p.pages)-1], "BT /%s %g Tf %g %g Td (%s) Tj ET\n",
+			font, size, pageMargin+indent, p.y, pdfString(line))
+	}
+}
+
+// wrapText breaks text into lines no wider than width points, keeping
+// explicit line breaks.
+func wrapText(text, font string, size, width float64) []string {
+	var lines []string
+	for _, para := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(para) {
+			next := word
+			if line != "" {
+				next = line + " " + word
+			}
+			if line != "" && textWidth(next, font, size) > width {
+				lines = append(lines, line)
+				next = word
+			}
+			line = next
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// textWidth estimates the width of s in points. Bold text is set a little
+// wider than its regular widths.
+func textWidth(s, font string, size float64) float64 {
+	total := 0
+	for _, r := range s {
+		if r >= 32 && r <= 126 {
+			total += helveticaWidths[r-32]
+		} else {
+			total += 556
+		}
+	}
+	w := float64(total) * size / 1000
+	if font == fontBold {
+		w *= 1.1
+	}
+	return w
+}
+
+// pdfString escapes s for a PDF literal string in WinAnsiEncoding.
+func pdfString(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 32 && r <= 126:
+			b.WriteRune(r)
+		case r >= 160 && r <= 255:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// Bytes returns the finished document.
+func (p *PDF) Bytes() []byte {
+	pages := p.pages
+	if len(pages) == 0 {
+		pages = []*bytes.Buffer{new(bytes.Buffer)}
+	}
+
+	// Objects: 1 catalog, 2 page tree, 3-5 fonts, then a page and its
+	// content stream for each page.
+	var objects []string
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 6+2*i)
+	}
+	objects = append(objects,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Oblique /Encoding /WinAnsiEncoding >>",
+	)
+	for i, content := range pages {
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /%s 3 0 R /%s 4 0 R /%s 5 0 R >> >> /Contents %d 0 R >>",
+				pageWidth, pageHeight, fontRegular, fontBold, fontItalic, 7+2*i),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+		)
+	}
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return b.Bytes()
+}
@@ -0,0 +1,174 @@
+// Package render draws game artifacts as standalone SVG images: the board
+// (from the same map the UI uses) and supply-center graphs. It also writes
+// simple text PDFs for downloadable reports.
+package render
+
+import (
+	_ "embed"
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// mapSVG is a copy of ui/assets/map/diplomacy_map.svg.
+//
+//go:embed map.svg
+var mapSVG string
+
+// landFill is the fill of unowned land provinces in mapSVG.
+const landFill = `fill="#F5E6CC"`
+
+var powerColors = map[diplomacy.Power]string{
+	diplomacy.Austria: "#FFEB3B",
+	diplomacy.England: "#C62828",
+	diplomacy.France:  "#1565C0",
+	diplomacy.Germany: "#795548",
+	diplomacy.Italy:   "#2E7D32",
+	diplomacy.Russia:  "#7B1FA2",
+	diplomacy.Turkey:  "#EF6C00",
+}
+
+// PowerColor returns the color the UI uses for a power, or grey for neutral.
+func PowerColor(p diplomacy.Power) string {
+	if c, ok := powerColors[p]; ok {
+		return c
+	}
+	return "#9E9E9E"
+}
+
+var (
+	polygonRe = regexp.MustCompile(`<polygon id="([a-z]+)(?:-[a-z]+)?" ` + landFill)
+	elementRe = regexp.MustCompile(`<(polygon|text)\b[^>]*?(?:id="([a-z]+)|x="([\d.]+)" y="([\d.]+)")`)
+
+	labelsOnce sync.Once
+	labels     map[string][2]float64 // province -> label position
+)
+
+// labelPositions returns where each province's name is drawn; units are
+// placed just above it.
+func labelPositions() map[string][2]float64 {
+	labelsOnce.Do(func() {
+		labels = make(map[string][2]float64)
+		last := ""
+		for _, m := range elementRe.FindAllStringSubmatch(mapSVG, -1) {
+			if m[1] == "polygon" {
+				if m[2] != "" {
+					last = m[2]
+				}
+				continue
+			}
+			if _, seen := labels[last]; last == "" || seen || m[3] == "" {
+				continue
+			}
+			x, _ := strconv.ParseFloat(m[3], 64)
+			y, _ := strconv.ParseFloat(m[4], 64)
+			labels[last] = [2]float64{x, y}
+		}
+	})
+	return labels
+}
+
+// MapSVG renders the board with supply centers shaded in their owner's
+// color and units drawn over their provinces. A non-empty caption is
+// printed in the bottom-left corner.
+func MapSVG(gs *diplomacy.GameState, caption string) []byte {
+	svg := polygonRe.ReplaceAllStringFunc(mapSVG, func(s string) string {
+		id := polygonRe.FindStringSubmatch(s)[1]
+		owner, ok := gs.SupplyCenters[id]
+		if !ok || owner == diplomacy.Neutral {
+			return s
+		}
+		return strings.Replace(s, landFill, fmt.Sprintf(`fill="%s" fill-opacity="0.55"`, PowerColor(owner)), 1)
+	})
+
+	var b strings.Builder
+	pos := labelPositions()
+	for _, u := range gs.Units {
+		p, ok := pos[u.Province]
+		if !ok {
+			continue
+		}
+		x, y := p[0], p[1]-10
+		if u.Type == diplomacy.Army {
+			fmt.Fprintf(&b, `  <circle cx="%g" cy="%g" r="5" fill="%s" stroke="#000000" stroke-width="1"/>`+"\n", x, y, PowerColor(u.Power))
+		} else {
+			fmt.Fprintf(&b, `  <polygon points="%g,%g %g,%g %g,%g" fill="%s" stroke="#000000" stroke-width="1"/>`+"\n",
+				x-6, y+4, x+6, y+4, x, y-6, PowerColor(u.Power))
+		}
+	}
+	if caption != "" {
+		fmt.Fprintf(&b, `  <text font-family="sans-serif" font-size="12" font-weight="bold" fill="#333333" x="8" y="550">%s</text>`+"\n", html.EscapeString(caption))
+	}
+	i := strings.LastIndex(svg, "</svg>")
+	return []byte(svg[:i] + b.String() + svg[i:])
+}
+
+// SCGraphSVG renders supply-center counts over time as one line per power.
+// years labels the x axis; each series holds one count per year. A dashed
+// line marks victory if it is positive.
+func SCGraphSVG(years []int, series map[diplomacy.Power][]int, victory int) []byte {
+	const (
+		width, height            = 610, 320
+		left, right, top, bottom = 40, 90, 20, 30
+	)
+	maxY := max(victory, 1)
+	for _, counts := range series {
+		for _, c := range counts {
+			maxY = max(maxY, c)
+		}
+	}
+	plotW, plotH := float64(width-left-right), float64(height-top-bottom)
+	px := func(i int) float64 {
+		if len(years) < 2 {
+			return left + plotW/2
+		}
+		return left + plotW*float64(i)/float64(len(years)-1)
+	}
+	py := func(v int) float64 { return top + plotH*(1-float64(v)/float64(maxY)) }
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<?xml version="1.0" encoding="UTF-8"?>
+<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" width="%d" height="%d" font-family="sans-serif" font-size="10">
+  <rect x="0" y="0" width="%d" height="%d" fill="#FFFFFF"/>
+`, width, height, width, height, width, height)
+
+	// Axes with a gridline every six centers.
+	for v := 0; v <= maxY; v += 6 {
+		fmt.Fprintf(&b, `  <line x1="%d" y1="%g" x2="%g" y2="%g" stroke="#E0E0E0"/>`+"\n", left, py(v), left+plotW, py(v))
+		fmt.Fprintf(&b, `  <text x="%d" y="%g" text-anchor="end" fill="#333333">%d</text>`+"\n", left-4, py(v)+3, v)
+	}
+	step := max(1, (len(years)+9)/10)
+	for i, y := range years {
+		if i%step == 0 || i == len(years)-1 {
+			fmt.Fprintf(&b, `  <text x="%g" y="%d" text-anchor="middle" fill="#333333">%d</text>`+"\n", px(i), height-bottom+14, y)
+		}
+	}
+	if victory > 0 {
+		fmt.Fprintf(&b, `  <line x1="%d" y1="%g" x2="%g" y2="%g" stroke="#333333" stroke-dasharray="4,3"/>`+"\n", left, py(victory), left+plotW, py(victory))
+	}
+
+	legend := 0
+	for _, p := range diplomacy.AllPowers() {
+		counts, ok := series[p]
+		if !ok {
+			continue
+		}
+		pts := make([]string, 0, len(counts))
+		for i, c := range counts {
+			pts = append(pts, fmt.Sprintf("%g,%g", px(i), py(c)))
+		}
+		color := PowerColor(p)
+		fmt.Fprintf(&b, `  <polyline points="%s" fill="none" stroke="%s" stroke-width="2"/>`+"\n", strings.Join(pts, " "), color)
+		ly := top + 14*legend
+		fmt.Fprintf(&b, `  <rect x="%d" y="%d" width="10" height="10" fill="%s"/>`+"\n", width-right+10, ly, color)
+		fmt.Fprintf(&b, `  <text x="%d" y="%d" fill="#333333">%s</text>`+"\n", width-right+24, ly+9, p)
+		legend++
+	}
+	b.WriteString("</svg>\n")
+	return []byte(b.String())
+}
@@ -0,0 +1,97 @@
+package render
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+func TestLabelPositionsCoverMap(t *testing.T) {
+	pos := labelPositions()
+	for id := range diplomacy.StandardMap().Provinces {
+		if _, ok := pos[id]; !ok {
+			t.Errorf("no label position for %s", id)
+		}
+	}
+}
+
+func TestMapSVG(t *testing.T) {
+	gs := diplomacy.NewInitialState()
+	gs.SupplyCenters["bel"] = diplomacy.France
+	svg := string(MapSVG(gs, "Fall 1901: <draw>"))
+
+	if err := xml.Unmarshal([]byte(svg), new(struct{})); err != nil {
+		t.Fatalf("invalid SVG: %v", err)
+	}
+	if !strings.Contains(svg, `<polygon id="bel" fill="`+PowerColor(diplomacy.France)+`"`) {
+		t.Error("expected bel shaded in France's color")
+	}
+	if !strings.Contains(svg, `<polygon id="hol" fill="#F5E6CC"`) {
+		t.Error("expected neutral hol left unshaded")
+	}
+	if n := strings.Count(svg, `stroke="#000000" stroke-width="1"`); n != len(gs.Units) {
+		t.Errorf("expected %d unit markers, got %d", len(gs.Units), n)
+	}
+	if !strings.Contains(svg, "Fall 1901: &lt;draw&gt;") {
+		t.Error("expected escaped caption")
+	}
+}
+
+func TestSCGraphSVG(t *testing.T) {
+	svg := string(SCGraphSVG([]int{1901, 1902, 1903}, map[diplomacy.Power][]int{
+		diplomacy.France:  {5, 7, 9},
+		diplomacy.Germany: {5, 4, 2},
+	}, 18))
+	if err := xml.Unmarshal([]byte(svg), new(struct{})); err != nil {
+		t.Fatalf("invalid SVG: %v", err)
+	}
+	if n := strings.Count(svg, "<polyline"); n != 2 {
+		t.Errorf("expected a line per power, got %d", n)
+	}
+	if !strings.Contains(svg, "stroke-dasharray") {
+		t.Error("expected a victory line")
+	}
+}
+
+func TestPDF(t *testing.T) {
+	doc := NewPDF()
+	doc.Heading("Spring 1901 (Movement)")
+	for range 80 {
+		doc.Paragraph("France moves A par - bur and takes a long walk through the countryside (again), while Germany looks on.")
+	}
+	doc.Quote("Café société \\ ☃")
+	pdf := string(doc.Bytes())
+
+	if !strings.HasPrefix(pdf, "%PDF-1.4\n") || !strings.HasSuffix(pdf, "%%EOF\n") {
+		t.Fatal("expected PDF header and trailer")
+	}
+	if n := strings.Count(pdf, "/Type /Page "); n < 2 {
+		t.Errorf("expected the text to span pages, got %d", n)
+	}
+	if !strings.Contains(pdf, `(Caf\351 soci\351t\351 \\ ?) Tj`) {
+		t.Error("expected Latin-1 escaped and other runes replaced")
+	}
+	// Every xref offset must point at its object.
+	xref := pdf[strings.LastIndex(pdf, "xref\n"):]
+	for i, line := range strings.Split(xref, "\n")[3:] {
+		if !strings.HasSuffix(line, " n ") {
+			break
+		}
+		var off int
+		fmt.Sscanf(line, "%d", &off)
+		if want := fmt.Sprintf("%d 0 obj", i+1); !strings.HasPrefix(pdf[off:], want) {
+			t.Errorf("xref entry %d points at %q", i+1, pdf[off:off+10])
+		}
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	lines := wrapText("one two three four\nfive", fontRegular, 10, textWidth("three four", fontRegular, 10))
+	want := []string{"one two", "three four", "five"}
+	if strings.Join(lines, "|") != strings.Join(want, "|") {
+		t.Errorf("got %q, want %q", lines, want)
+	}
+}
@@ -0,0 +1,23 @@
+package repository
+
+import "errors"
+
+// ErrTimeout is returned (wrapped) when a repository operation exceeds its
+// deadline or the database cancels a statement for running too long.
+var ErrTimeout = errors.New("repository operation timed out")
+
+// ErrEventsExpired is returned when a game's event log no longer holds
+// every event after the requested sequence number.
+var ErrEventsExpired = errors.New("game events expired from the log")
+
+// ErrSeatTaken is returned when a player cannot be seated because every seat
+// is held, or another join claimed the seat first.
+var ErrSeatTaken = errors.New("no free seat in game")
+
+// ErrAlreadySeated is returned when a user claiming a seat is already a
+// player in the game.
+var ErrAlreadySeated = errors.New("user already holds a seat in game")
+
+// ErrNotJoinable is returned when claiming a seat in a game that is no
+// longer waiting for players.
+var ErrNotJoinable = errors.New("game is not waiting for players")
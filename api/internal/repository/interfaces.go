@@ -14,43 +14,188 @@ type UserRepository interface {
 	FindByProviderID(ctx context.Context, provider, providerID string) (*model.User, error)
 	Upsert(ctx context.Context, provider, providerID, displayName, avatarURL string) (*model.User, error)
 	UpdateDisplayName(ctx context.Context, id, displayName string) error
+	UpdateLocale(ctx context.Context, id, locale string) error
+	UpdateSharePress(ctx context.Context, id string, share bool) error
+}
+
+// APIKeyRepository defines public API key data operations.
+type APIKeyRepository interface {
+	Create(ctx context.Context, userID, name, prefix, keyHash string) (*model.APIKey, error)
+	FindByHash(ctx context.Context, keyHash string) (*model.APIKey, error)
+	ListByUser(ctx context.Context, userID string) ([]model.APIKey, error)
+	Revoke(ctx context.Context, id, userID string) (bool, error)
+	Touch(ctx context.Context, id string) error
+}
+
+// PreferenceRepository defines per-user preference data operations. Values
+// are JSON keyed by namespace; Set deletes namespaces whose value is nil.
+type PreferenceRepository interface {
+	Get(ctx context.Context, userID string) (map[string]json.RawMessage, error)
+	Set(ctx context.Context, userID string, values map[string]json.RawMessage) error
+}
+
+// JobRepository defines background job queue operations. Claim must be safe
+// to call from several servers at once.
+type JobRepository interface {
+	Enqueue(ctx context.Context, kind string, payload json.RawMessage, maxAttempts int, runAt time.Time) (*model.Job, error)
+	Claim(ctx context.Context, kinds []string) (*model.Job, error)
+	Complete(ctx context.Context, id string) error
+	Reschedule(ctx context.Context, id, lastError string, runAt time.Time) error
+	Bury(ctx context.Context, id, lastError string) error
+	RequeueStale(ctx context.Context, lockedBefore time.Time) (int64, error)
+	Retry(ctx context.Context, id string) (bool, error)
+	DeleteDone(ctx context.Context, before time.Time) (int64, error)
+	List(ctx context.Context, status string, limit int) ([]model.Job, error)
+	CountByStatus(ctx context.Context) (map[string]int, error)
 }
 
 // GameRepository defines game and player data operations.
 type GameRepository interface {
-	Create(ctx context.Context, name, creatorID, turnDur, retreatDur, buildDur, powerAssignment string) (*model.Game, error)
+	Create(ctx context.Context, name, creatorID, turnDur, retreatDur, buildDur, powerAssignment, speedPreset, scenario string, rules model.GameRules) (*model.Game, error)
 	FindByID(ctx context.Context, id string) (*model.Game, error)
 	ListOpen(ctx context.Context) ([]model.Game, error)
 	ListByUser(ctx context.Context, userID string) ([]model.Game, error)
+	ListActiveByUser(ctx context.Context, userID string) ([]model.Game, error)
+	CountUserGames(ctx context.Context, userID string) (*model.UserStats, error)
 	ListFinished(ctx context.Context) ([]model.Game, error)
 	SearchFinished(ctx context.Context, search string) ([]model.Game, error)
+	ListFinishedPage(ctx context.Context, search, afterID string, limit int) ([]model.Game, error)
 	JoinGame(ctx context.Context, gameID, userID string) error
+	ClaimSeat(ctx context.Context, gameID, userID string, seats int) error
 	JoinGameAsBot(ctx context.Context, gameID, userID, difficulty string) error
-	ReplaceBot(ctx context.Context, gameID, newUserID string) error
+	Spectate(ctx context.Context, gameID, userID string) error
 	PlayerCount(ctx context.Context, gameID string) (int, error)
 	AssignPowers(ctx context.Context, gameID string, assignments map[string]string) error
-	ListActive(ctx context.Context) ([]model.Game, error)
+	ListActive(ctx context.Context, afterID string, limit int) ([]model.Game, error)
+	RankLive(ctx context.Context, activity []model.GameActivity, limit int) ([]model.LiveGame, error)
 	SetFinished(ctx context.Context, gameID, winner string) error
 	Delete(ctx context.Context, gameID string) error
-	UpdateBotDifficulty(ctx context.Context, gameID, botUserID, difficulty string) error
+	SoftDelete(ctx context.Context, gameID string) error
+	Restore(ctx context.Context, gameID string) error
+	PurgeDeleted(ctx context.Context, before time.Time) (int, error)
+	UpdateBotDifficulty(ctx context.Context, gameID, botUserID, difficulty, reason, changedBy string) (*model.BotDifficultyChange, error)
+	RequestBotDifficulty(ctx context.Context, gameID, botUserID, difficulty, changedBy string) (*model.BotDifficultyChange, error)
+	ApplyPendingBotDifficulties(ctx context.Context, gameID, phaseID string) ([]model.BotDifficultyChange, error)
+	BotDifficultyChanges(ctx context.Context, gameID string) ([]model.BotDifficultyChange, error)
 	UpdatePlayerPower(ctx context.Context, gameID, userID, power string) error
+	UpdateBotPersonality(ctx context.Context, gameID, botUserID string, personality *model.BotPersonality) error
+	SetEliminated(ctx context.Context, gameID, power, by string, year int) error
+	UpdateBotPress(ctx context.Context, gameID, style string) error
+	UpdatePressSettings(ctx context.Context, gameID, pressMode string, anonymous bool) error
+	UpdateGarrisons(ctx context.Context, gameID string, garrisons bool) error
+	UpdateRetreatCredits(ctx context.Context, gameID string, retreatCredits bool) error
+	UpdateMercyYears(ctx context.Context, gameID string, years int) error
+	UpdateReadyQuorum(ctx context.Context, gameID string, percent int, delay string) error
+	UpdateCivilDisorder(ctx context.Context, gameID string, after int, botDifficulty string) error
+	RecordDeadline(ctx context.Context, gameID string, missed, acted []string) ([]model.GamePlayer, error)
+	ReplaceWithBot(ctx context.Context, gameID, userID, botUserID, difficulty string) (*model.GamePlayer, error)
+	PowerAssignments(ctx context.Context, gameID string) ([]model.PowerAssignment, error)
+	SetPaused(ctx context.Context, gameID string, paused bool) error
 }
 
 // PhaseRepository defines phase and order data operations.
 type PhaseRepository interface {
 	CreatePhase(ctx context.Context, gameID string, year int, season, phaseType string, stateBefore json.RawMessage, deadline time.Time) (*model.Phase, error)
 	CurrentPhase(ctx context.Context, gameID string) (*model.Phase, error)
+	CurrentPhases(ctx context.Context, gameIDs []string) (map[string]model.Phase, error)
 	ListPhases(ctx context.Context, gameID string) ([]model.Phase, error)
 	ResolvePhase(ctx context.Context, phaseID string, stateAfter json.RawMessage) error
+	UpdateDeadline(ctx context.Context, phaseID string, deadline time.Time) error
 	SaveOrders(ctx context.Context, orders []model.Order) error
 	OrdersByPhase(ctx context.Context, phaseID string) ([]model.Order, error)
+	OpeningTallies(ctx context.Context) (int, []model.OpeningTally, error)
 	ListExpired(ctx context.Context) ([]model.Phase, error)
+	ListDueBefore(ctx context.Context, before time.Time) ([]model.Phase, error)
+	SaveEvaluations(ctx context.Context, evals []model.PhaseEvaluation) error
+	EvaluationsByGame(ctx context.Context, gameID string) ([]model.PhaseEvaluation, error)
+	SaveOrderReviews(ctx context.Context, reviews []model.OrderReview) error
+	OrderReviewsByGame(ctx context.Context, gameID string) ([]model.OrderReview, error)
+	SaveSubmittedOrders(ctx context.Context, phaseID, power string, orders json.RawMessage) error
+	SetSubmittedReady(ctx context.Context, phaseID, power string, ready bool) error
+	ClearSubmission(ctx context.Context, gameID, power string) error
+	Submissions(ctx context.Context, phaseID string) ([]model.Submission, error)
 }
 
 // MessageRepository defines message data operations.
 type MessageRepository interface {
-	Create(ctx context.Context, gameID, senderID, recipientID, content, phaseID string) (*model.Message, error)
+	Create(ctx context.Context, gameID, senderID, recipientID, content, phaseID string, intent *model.MessageIntent) (*model.Message, error)
+	CreateInChannel(ctx context.Context, gameID, senderID, channelID, content, phaseID string, intent *model.MessageIntent) (*model.Message, error)
 	ListByGame(ctx context.Context, gameID, userID string) ([]model.Message, error)
+	ListAllByGame(ctx context.Context, gameID string) ([]model.Message, error)
+	FindByID(ctx context.Context, id string) (*model.Message, error)
+	MarkRead(ctx context.Context, gameID, userID string) error
+	CountUnread(ctx context.Context, gameID, userID string) (int, error)
+	CountUnreadByGame(ctx context.Context, gameIDs []string, userID string) (map[string]int, error)
+}
+
+// ChannelRepository defines press channel data operations.
+type ChannelRepository interface {
+	Create(ctx context.Context, gameID, name, createdBy string, members []string) (*model.Channel, error)
+	FindByID(ctx context.Context, id string) (*model.Channel, error)
+	ListByMember(ctx context.Context, gameID, userID string) ([]model.Channel, error)
+	ListByGame(ctx context.Context, gameID string) ([]model.Channel, error)
+	AddMember(ctx context.Context, channelID, userID string) error
+	RemoveMember(ctx context.Context, channelID, userID string) error
+}
+
+// AchievementRepository defines achievement and hall-of-fame data operations.
+type AchievementRepository interface {
+	Award(ctx context.Context, a model.Achievement) error
+	ListByUser(ctx context.Context, userID string) ([]model.Achievement, error)
+	SaveRecord(ctx context.Context, rec model.GameRecord) error
+	FastestSolos(ctx context.Context, limit int) ([]model.GameRecord, error)
+	TopAchievers(ctx context.Context, limit int) ([]model.AchieverCount, error)
+}
+
+// RatingRepository defines data access for Elo ratings.
+type RatingRepository interface {
+	Find(ctx context.Context, subjectType, subjectID string) (*model.Rating, error)
+	ApplyGame(ctx context.Context, gameID string, updates []model.RatingUpdate) (bool, error)
+	Top(ctx context.Context, subjectType string, limit int) ([]model.Rating, error)
+	ListUnrated(ctx context.Context) ([]string, error)
+	CountRated(ctx context.Context) (int, error)
+	Reset(ctx context.Context) error
+}
+
+// ComputeRepository defines data access for bot compute accounting.
+type ComputeRepository interface {
+	Record(ctx context.Context, records []model.BotCompute) error
+	GameTotal(ctx context.Context, gameID string) (int64, error)
+	ListByGame(ctx context.Context, gameID string) ([]model.BotCompute, error)
+	TopGames(ctx context.Context, since time.Time, limit int) ([]model.GameCompute, error)
+}
+
+// VariantRepository defines data access for uploaded custom variants.
+type VariantRepository interface {
+	Save(ctx context.Context, v *model.Variant) error
+	FindByName(ctx context.Context, name string) (*model.Variant, error)
+	FindVersion(ctx context.Context, name string, version int) (*model.Variant, error)
+	List(ctx context.Context) ([]model.Variant, error)
+	ListPlayable(ctx context.Context) ([]model.Variant, error)
+}
+
+// SummaryRepository defines end-of-game summary data operations.
+type SummaryRepository interface {
+	Save(ctx context.Context, summary *model.GameSummary) error
+	FindByGame(ctx context.Context, gameID string) (*model.GameSummary, error)
+}
+
+// NotificationRepository defines notification channel and delivery
+// operations.
+type NotificationRepository interface {
+	CreateChannel(ctx context.Context, userID, kind, target string, events []string, confirmTokenHash string) (*model.NotificationChannel, error)
+	ListChannels(ctx context.Context, userID string) ([]model.NotificationChannel, error)
+	FindChannel(ctx context.Context, id string) (*model.NotificationChannel, error)
+	UpdateChannel(ctx context.Context, id, userID string, events []string, enabled bool) (*model.NotificationChannel, error)
+	DeleteChannel(ctx context.Context, id, userID string) (bool, error)
+	SetConfirmToken(ctx context.Context, id, userID, tokenHash string) (bool, error)
+	ConfirmChannel(ctx context.Context, tokenHash string, sentAfter time.Time) (*model.NotificationChannel, error)
+	SubscribedChannels(ctx context.Context, userIDs []string, event string) ([]model.NotificationChannel, error)
+	ClaimReminder(ctx context.Context, phaseID, event string) (bool, error)
+	CreateNotifications(ctx context.Context, notifications []model.Notification) ([]model.Notification, error)
+	FindNotification(ctx context.Context, id string) (*model.Notification, error)
+	RecordAttempt(ctx context.Context, id, status, lastError string) error
+	ListNotifications(ctx context.Context, userID string, limit int) ([]model.Notification, error)
 }
 
 // GameCache defines live game state operations (Redis).
@@ -59,6 +204,7 @@ type GameCache interface {
 	GetGameState(ctx context.Context, gameID string) (json.RawMessage, error)
 	SetOrders(ctx context.Context, gameID, power string, orders json.RawMessage) error
 	GetOrders(ctx context.Context, gameID, power string) (json.RawMessage, error)
+	UpdateOrders(ctx context.Context, gameID, power string, update func(orders json.RawMessage) (json.RawMessage, error)) error
 	GetAllOrders(ctx context.Context, gameID string, powers []string) (map[string]json.RawMessage, error)
 	MarkReady(ctx context.Context, gameID, power string) error
 	UnmarkReady(ctx context.Context, gameID, power string) error
@@ -66,10 +212,79 @@ type GameCache interface {
 	ReadyPowers(ctx context.Context, gameID string) ([]string, error)
 	SetTimer(ctx context.Context, gameID string, deadline time.Time) error
 	ClearTimer(ctx context.Context, gameID string) error
+	PauseTimer(ctx context.Context, gameID string) (time.Duration, bool, error)
+	ResumeTimer(ctx context.Context, gameID string) (time.Duration, bool, error)
+	SetQuorumDeadline(ctx context.Context, gameID string, original time.Time) (bool, error)
+	ClearQuorumDeadline(ctx context.Context, gameID string) (time.Time, bool, error)
 	AddDrawVote(ctx context.Context, gameID, power string) error
 	RemoveDrawVote(ctx context.Context, gameID, power string) error
 	DrawVoteCount(ctx context.Context, gameID string) (int64, error)
 	DrawVotePowers(ctx context.Context, gameID string) ([]string, error)
 	ClearPhaseData(ctx context.Context, gameID string, powers []string) error
+	ClearPowerData(ctx context.Context, gameID, power string) error
 	DeleteGameData(ctx context.Context, gameID string, powers []string) error
 }
+
+// ViewerStore tracks live viewer counts and game activity across server
+// instances (Redis).
+type ViewerStore interface {
+	ReportViewers(ctx context.Context, instanceID string, counts map[string]int, activity map[string]time.Time) error
+	LiveViewers(ctx context.Context, since time.Time) (map[string]int, error)
+	RecentActivity(ctx context.Context, since time.Time) (map[string]time.Time, error)
+}
+
+// EventLog keeps a short, sequenced log of each game's broadcast events so
+// reconnecting clients can catch up on what they missed (Redis).
+type EventLog interface {
+	NextEventSeq(ctx context.Context, gameID string) (int64, error)
+	AppendGameEvent(ctx context.Context, gameID string, seq int64, event []byte) error
+	GameEventsSince(ctx context.Context, gameID string, seq int64) ([][]byte, int64, error)
+}
+
+// EventRelay carries broadcast events between the server instances sharing
+// it (Redis pub/sub), so clients connected to any instance receive events
+// raised on another. SubscribeEvents delivers every published message,
+// including this instance's own, until ctx is done.
+type EventRelay interface {
+	PublishEvent(ctx context.Context, msg []byte) error
+	SubscribeEvents(ctx context.Context) (<-chan []byte, error)
+}
+
+// EvaluationCache keeps each phase's position evaluations, as JSON, so the
+// value network runs once per position however many clients ask (Redis).
+// stage tells the position at the start of a phase from the one after it
+// resolved. A miss returns nil data.
+type EvaluationCache interface {
+	PhaseEvaluations(ctx context.Context, phaseID, stage string) ([]byte, error)
+	SetPhaseEvaluations(ctx context.Context, phaseID, stage string, data []byte) error
+}
+
+// AllianceStore keeps each bot's alliance state in a game, as JSON, from
+// phase to phase (Redis). A miss returns nil data. UpdateBotAlliances
+// applies update atomically, so concurrent updates are not lost.
+type AllianceStore interface {
+	BotAlliances(ctx context.Context, gameID, power string) ([]byte, error)
+	SetBotAlliances(ctx context.Context, gameID, power string, data []byte) error
+	UpdateBotAlliances(ctx context.Context, gameID, power string, update func(data []byte) ([]byte, error)) error
+}
+
+// LeaseStore grants short-lived exclusive leases shared by every server
+// instance (Redis), for locking and leader election across replicas. A lease
+// is held by its owner until it expires or is released, and only the owner
+// can renew or release it.
+type LeaseStore interface {
+	AcquireLease(ctx context.Context, key, owner string, ttl time.Duration) (bool, error)
+	RenewLease(ctx context.Context, key, owner string, ttl time.Duration) (bool, error)
+	ReleaseLease(ctx context.Context, key, owner string) error
+}
+
+// FeatureFlagStore defines per-game feature flag operations (Redis).
+// A game's explicit override wins; otherwise the flag's rollout percentage
+// decides whether the game gets it.
+type FeatureFlagStore interface {
+	GameFlags(ctx context.Context, gameID string) (map[string]bool, error)
+	SetGameFlag(ctx context.Context, gameID, flag string, enabled bool) error
+	ClearGameFlag(ctx context.Context, gameID, flag string) error
+	FlagRollouts(ctx context.Context) (map[string]int, error)
+	SetFlagRollout(ctx context.Context, flag string, percent int) error
+}
@@ -0,0 +1,132 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+)
+
+// AchievementRepo handles achievement and hall-of-fame database operations.
+type AchievementRepo struct {
+	db *timedDB
+}
+
+// NewAchievementRepo creates an AchievementRepo.
+func NewAchievementRepo(db *sql.DB) *AchievementRepo {
+	return &AchievementRepo{db: &timedDB{db: db}}
+}
+
+// SetQueryTimeout bounds how long each AchievementRepo operation may run. Zero
+// (the default) leaves only the caller's context deadline.
+func (r *AchievementRepo) SetQueryTimeout(d time.Duration) {
+	r.db.timeout = d
+}
+
+// Award records an achievement. Awarding the same achievement for the same
+// game twice is a no-op, so re-evaluating a game is safe.
+func (r *AchievementRepo) Award(ctx context.Context, a model.Achievement) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO user_achievements (user_id, kind, game_id, power, year, supply_centers)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 ON CONFLICT (user_id, kind, game_id) DO NOTHING`,
+		a.UserID, a.Kind, a.GameID, a.Power, a.Year, a.SupplyCenters,
+	)
+	if err != nil {
+		return fmt.Errorf("award achievement: %w", err)
+	}
+	return nil
+}
+
+// ListByUser returns a user's achievements, oldest first.
+func (r *AchievementRepo) ListByUser(ctx context.Context, userID string) ([]model.Achievement, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT user_id, kind, game_id, power, year, supply_centers, awarded_at
+		 FROM user_achievements WHERE user_id = $1
+		 ORDER BY awarded_at, kind`, userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list achievements: %w", err)
+	}
+	defer rows.Close()
+
+	var achievements []model.Achievement
+	for rows.Next() {
+		var a model.Achievement
+		if err := rows.Scan(&a.UserID, &a.Kind, &a.GameID, &a.Power, &a.Year, &a.SupplyCenters, &a.AwardedAt); err != nil {
+			return nil, fmt.Errorf("scan achievement: %w", err)
+		}
+		achievements = append(achievements, a)
+	}
+	return achievements, rows.Err()
+}
+
+// SaveRecord stores a solo victory for the hall of fame.
+func (r *AchievementRepo) SaveRecord(ctx context.Context, rec model.GameRecord) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO game_records (game_id, winner_id, power, year, supply_centers)
+		 VALUES ($1, $2, $3, $4, $5)
+		 ON CONFLICT (game_id) DO NOTHING`,
+		rec.GameID, rec.WinnerID, rec.Power, rec.Year, rec.SupplyCenters,
+	)
+	if err != nil {
+		return fmt.Errorf("save game record: %w", err)
+	}
+	return nil
+}
+
+// FastestSolos returns the solo victories reached in the earliest years,
+// breaking ties by the most supply centers held.
+func (r *AchievementRepo) FastestSolos(ctx context.Context, limit int) ([]model.GameRecord, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT gr.game_id, g.name, gr.winner_id, u.display_name, gr.power, gr.year, gr.supply_centers, gr.finished_at
+		 FROM game_records gr
+		 JOIN games g ON g.id = gr.game_id
+		 JOIN users u ON u.id = gr.winner_id
+		 WHERE g.status <> 'deleted'
+		 ORDER BY gr.year, gr.supply_centers DESC, gr.finished_at
+		 LIMIT $1`, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list fastest solos: %w", err)
+	}
+	defer rows.Close()
+
+	var records []model.GameRecord
+	for rows.Next() {
+		var rec model.GameRecord
+		if err := rows.Scan(&rec.GameID, &rec.GameName, &rec.WinnerID, &rec.DisplayName, &rec.Power, &rec.Year, &rec.SupplyCenters, &rec.FinishedAt); err != nil {
+			return nil, fmt.Errorf("scan game record: %w", err)
+		}
+		records = append(records, rec)
+	}
+	return records, rows.Err()
+}
+
+// TopAchievers returns the users with the most achievements.
+func (r *AchievementRepo) TopAchievers(ctx context.Context, limit int) ([]model.AchieverCount, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT a.user_id, u.display_name, COUNT(*)
+		 FROM user_achievements a
+		 JOIN users u ON u.id = a.user_id
+		 GROUP BY a.user_id, u.display_name
+		 ORDER BY COUNT(*) DESC, u.display_name
+		 LIMIT $1`, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list top achievers: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []model.AchieverCount
+	for rows.Next() {
+		var c model.AchieverCount
+		if err := rows.Scan(&c.UserID, &c.DisplayName, &c.Achievements); err != nil {
+			return nil, fmt.Errorf("scan achiever: %w", err)
+		}
+		counts = append(counts, c)
+	}
+	return counts, rows.Err()
+}
@@ -0,0 +1,116 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+)
+
+// APIKeyRepo handles public API key database operations.
+type APIKeyRepo struct {
+	db *timedDB
+}
+
+// NewAPIKeyRepo creates an APIKeyRepo.
+func NewAPIKeyRepo(db *sql.DB) *APIKeyRepo {
+	return &APIKeyRepo{db: &timedDB{db: db}}
+}
+
+// SetQueryTimeout bounds how long each APIKeyRepo operation may run. Zero
+// (the default) leaves only the caller's context deadline.
+func (r *APIKeyRepo) SetQueryTimeout(d time.Duration) {
+	r.db.timeout = d
+}
+
+const apiKeyColumns = `id, user_id, name, prefix, created_at, last_used_at, revoked_at`
+
+func scanAPIKey(scan func(dest ...any) error) (*model.APIKey, error) {
+	var k model.APIKey
+	var lastUsed, revoked sql.NullTime
+	if err := scan(&k.ID, &k.UserID, &k.Name, &k.Prefix, &k.CreatedAt, &lastUsed, &revoked); err != nil {
+		return nil, err
+	}
+	if lastUsed.Valid {
+		k.LastUsedAt = &lastUsed.Time
+	}
+	if revoked.Valid {
+		k.RevokedAt = &revoked.Time
+	}
+	return &k, nil
+}
+
+// Create stores a new key by its hash.
+func (r *APIKeyRepo) Create(ctx context.Context, userID, name, prefix, keyHash string) (*model.APIKey, error) {
+	k, err := scanAPIKey(r.db.QueryRowContext(ctx,
+		`INSERT INTO api_keys (user_id, name, prefix, key_hash) VALUES ($1, $2, $3, $4)
+		 RETURNING `+apiKeyColumns,
+		userID, name, prefix, keyHash,
+	).Scan)
+	if err != nil {
+		return nil, fmt.Errorf("create api key: %w", err)
+	}
+	return k, nil
+}
+
+// FindByHash returns the key with the given hash, or nil if there is none.
+// Revoked keys are returned too; callers check RevokedAt.
+func (r *APIKeyRepo) FindByHash(ctx context.Context, keyHash string) (*model.APIKey, error) {
+	k, err := scanAPIKey(r.db.QueryRowContext(ctx,
+		`SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = $1`, keyHash,
+	).Scan)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("find api key: %w", err)
+	}
+	return k, nil
+}
+
+// ListByUser returns a user's keys, newest first.
+func (r *APIKeyRepo) ListByUser(ctx context.Context, userID string) ([]model.APIKey, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+apiKeyColumns+` FROM api_keys WHERE user_id = $1 ORDER BY created_at DESC`, userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list api keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []model.APIKey
+	for rows.Next() {
+		k, err := scanAPIKey(rows.Scan)
+		if err != nil {
+			return nil, fmt.Errorf("scan api key: %w", err)
+		}
+		keys = append(keys, *k)
+	}
+	return keys, rows.Err()
+}
+
+// Revoke disables one of a user's keys. It reports false if the user has no
+// such active key.
+func (r *APIKeyRepo) Revoke(ctx context.Context, id, userID string) (bool, error) {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE api_keys SET revoked_at = now()
+		 WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`, id, userID)
+	if err != nil {
+		return false, fmt.Errorf("revoke api key: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("revoke api key: %w", err)
+	}
+	return n > 0, nil
+}
+
+// Touch records that a key was just used.
+func (r *APIKeyRepo) Touch(ctx context.Context, id string) error {
+	if _, err := r.db.ExecContext(ctx, `UPDATE api_keys SET last_used_at = now() WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("touch api key: %w", err)
+	}
+	return nil
+}
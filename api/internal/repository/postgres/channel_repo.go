@@ -0,0 +1,127 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+)
+
+// ChannelRepo handles press channel database operations.
+type ChannelRepo struct {
+	db *timedDB
+}
+
+// NewChannelRepo creates a ChannelRepo.
+func NewChannelRepo(db *sql.DB) *ChannelRepo {
+	return &ChannelRepo{db: &timedDB{db: db}}
+}
+
+// SetQueryTimeout bounds how long each ChannelRepo operation may run. Zero
+// (the default) leaves only the caller's context deadline.
+func (r *ChannelRepo) SetQueryTimeout(d time.Duration) {
+	r.db.timeout = d
+}
+
+// Create inserts a channel with its initial members.
+func (r *ChannelRepo) Create(ctx context.Context, gameID, name, createdBy string, members []string) (*model.Channel, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	c := model.Channel{GameID: gameID, Name: name, CreatedBy: createdBy, Members: members}
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO press_channels (game_id, name, created_by) VALUES ($1, $2, $3)
+		 RETURNING id, created_at`,
+		gameID, name, createdBy,
+	).Scan(&c.ID, &c.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("create channel: %w", err)
+	}
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO press_channel_members (channel_id, user_id)
+		 SELECT $1, unnest($2::uuid[]) ON CONFLICT DO NOTHING`,
+		c.ID, pq.Array(members),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("add channel members: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit channel: %w", err)
+	}
+	return &c, nil
+}
+
+// FindByID returns a channel with its members, or nil if there is none.
+func (r *ChannelRepo) FindByID(ctx context.Context, id string) (*model.Channel, error) {
+	channels, err := r.list(ctx, `WHERE c.id = $1`, id)
+	if err != nil || len(channels) == 0 {
+		return nil, err
+	}
+	return &channels[0], nil
+}
+
+// ListByMember returns the channels of a game a user belongs to.
+func (r *ChannelRepo) ListByMember(ctx context.Context, gameID, userID string) ([]model.Channel, error) {
+	return r.list(ctx,
+		`WHERE c.game_id = $1 AND c.id IN (SELECT channel_id FROM press_channel_members WHERE user_id = $2)`,
+		gameID, userID)
+}
+
+// ListByGame returns every channel of a game.
+func (r *ChannelRepo) ListByGame(ctx context.Context, gameID string) ([]model.Channel, error) {
+	return r.list(ctx, `WHERE c.game_id = $1`, gameID)
+}
+
+// list returns the channels matching a WHERE clause over press_channels c,
+// oldest first, with their members in join order.
+func (r *ChannelRepo) list(ctx context.Context, where string, args ...any) ([]model.Channel, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT c.id, c.game_id, c.name, c.created_by, c.created_at,
+		        COALESCE(array_agg(m.user_id::text ORDER BY m.joined_at) FILTER (WHERE m.user_id IS NOT NULL), '{}')
+		 FROM press_channels c LEFT JOIN press_channel_members m ON m.channel_id = c.id
+		 `+where+`
+		 GROUP BY c.id ORDER BY c.created_at`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list channels: %w", err)
+	}
+	defer rows.Close()
+
+	var channels []model.Channel
+	for rows.Next() {
+		var c model.Channel
+		if err := rows.Scan(&c.ID, &c.GameID, &c.Name, &c.CreatedBy, &c.CreatedAt, pq.Array(&c.Members)); err != nil {
+			return nil, fmt.Errorf("scan channel: %w", err)
+		}
+		channels = append(channels, c)
+	}
+	return channels, rows.Err()
+}
+
+// AddMember adds a user to a channel.
+func (r *ChannelRepo) AddMember(ctx context.Context, channelID, userID string) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO press_channel_members (channel_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+		channelID, userID)
+	if err != nil {
+		return fmt.Errorf("add channel member: %w", err)
+	}
+	return nil
+}
+
+// RemoveMember removes a user from a channel.
+func (r *ChannelRepo) RemoveMember(ctx context.Context, channelID, userID string) error {
+	_, err := r.db.ExecContext(ctx,
+		`DELETE FROM press_channel_members WHERE channel_id = $1 AND user_id = $2`,
+		channelID, userID)
+	if err != nil {
+		return fmt.Errorf("remove channel member: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+)
+
+// ComputeRepo handles bot compute accounting database operations.
+type ComputeRepo struct {
+	db *timedDB
+}
+
+// NewComputeRepo creates a ComputeRepo.
+func NewComputeRepo(db *sql.DB) *ComputeRepo {
+	return &ComputeRepo{db: &timedDB{db: db}}
+}
+
+// SetQueryTimeout bounds how long each ComputeRepo operation may run. Zero
+// (the default) leaves only the caller's context deadline.
+func (r *ComputeRepo) SetQueryTimeout(d time.Duration) {
+	r.db.timeout = d
+}
+
+// Record stores one phase's bot compute records.
+func (r *ComputeRepo) Record(ctx context.Context, records []model.BotCompute) error {
+	if len(records) == 0 {
+		return nil
+	}
+	query := `INSERT INTO bot_compute (game_id, phase_id, power, strategy, compute_ms) VALUES `
+	args := make([]any, 0, len(records)*5)
+	for i, rec := range records {
+		if i > 0 {
+			query += ", "
+		}
+		n := i * 5
+		query += fmt.Sprintf("($%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5)
+		args = append(args, rec.GameID, rec.PhaseID, rec.Power, rec.Strategy, rec.ComputeMS)
+	}
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("record bot compute: %w", err)
+	}
+	return nil
+}
+
+// GameTotal returns the milliseconds of bot compute a game has used.
+func (r *ComputeRepo) GameTotal(ctx context.Context, gameID string) (int64, error) {
+	var total int64
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COALESCE(SUM(compute_ms), 0) FROM bot_compute WHERE game_id = $1`, gameID,
+	).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("game bot compute: %w", err)
+	}
+	return total, nil
+}
+
+// ListByGame returns a game's compute records, oldest first.
+func (r *ComputeRepo) ListByGame(ctx context.Context, gameID string) ([]model.BotCompute, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT game_id, phase_id, power, strategy, compute_ms, created_at
+		 FROM bot_compute WHERE game_id = $1 ORDER BY id`, gameID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list bot compute: %w", err)
+	}
+	defer rows.Close()
+
+	var records []model.BotCompute
+	for rows.Next() {
+		var rec model.BotCompute
+		if err := rows.Scan(&rec.GameID, &rec.PhaseID, &rec.Power, &rec.Strategy, &rec.ComputeMS, &rec.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan bot compute: %w", err)
+		}
+		records = append(records, rec)
+	}
+	return records, rows.Err()
+}
+
+// TopGames returns the games that used the most bot compute since the given
+// time.
+func (r *ComputeRepo) TopGames(ctx context.Context, since time.Time, limit int) ([]model.GameCompute, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT c.game_id, g.name, SUM(c.compute_ms), COUNT(DISTINCT c.phase_id), MAX(c.created_at)
+		 FROM bot_compute c JOIN games g ON g.id = c.game_id
+		 WHERE c.created_at >= $1
+		 GROUP BY c.game_id, g.name
+		 ORDER BY SUM(c.compute_ms) DESC
+		 LIMIT $2`, since, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("top bot compute games: %w", err)
+	}
+	defer rows.Close()
+
+	var games []model.GameCompute
+	for rows.Next() {
+		var gc model.GameCompute
+		if err := rows.Scan(&gc.GameID, &gc.GameName, &gc.ComputeMS, &gc.Phases, &gc.LastAt); err != nil {
+			return nil, fmt.Errorf("scan bot compute game: %w", err)
+		}
+		games = append(games, gc)
+	}
+	return games, rows.Err()
+}
@@ -3,13 +3,28 @@ package postgres
 import (
 	"database/sql"
 	"fmt"
+	"net/url"
+	"strings"
+	"time"
 
 	_ "github.com/lib/pq"
 )
 
 // Connect opens a connection pool to the PostgreSQL database.
 func Connect(databaseURL string) (*sql.DB, error) {
-	db, err := sql.Open("postgres", databaseURL)
+	return ConnectWithStatementTimeout(databaseURL, 0)
+}
+
+// ConnectWithStatementTimeout opens a connection pool whose sessions abort
+// any statement running longer than statementTimeout, so a stuck query
+// cannot hold a connection (or a caller's lock) indefinitely. Zero keeps
+// the server default.
+func ConnectWithStatementTimeout(databaseURL string, statementTimeout time.Duration) (*sql.DB, error) {
+	dsn, err := withStatementTimeout(databaseURL, statementTimeout)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("postgres", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("postgres open: %w", err)
 	}
@@ -20,3 +35,24 @@ func Connect(databaseURL string) (*sql.DB, error) {
 	}
 	return db, nil
 }
+
+// withStatementTimeout adds a statement_timeout run-time parameter to a URL
+// or key/value connection string. lib/pq sends unrecognized parameters to
+// the server at startup.
+func withStatementTimeout(databaseURL string, d time.Duration) (string, error) {
+	if d <= 0 {
+		return databaseURL, nil
+	}
+	ms := fmt.Sprint(d.Milliseconds())
+	if !strings.Contains(databaseURL, "://") {
+		return databaseURL + " statement_timeout=" + ms, nil
+	}
+	u, err := url.Parse(databaseURL)
+	if err != nil {
+		return "", fmt.Errorf("parse database url: %w", err)
+	}
+	q := u.Query()
+	q.Set("statement_timeout", ms)
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
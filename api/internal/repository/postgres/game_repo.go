@@ -3,46 +3,70 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
+
+	"github.com/lib/pq"
 
 	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/internal/repository"
 )
 
 // GameRepo handles game and game_player database operations.
 type GameRepo struct {
-	db *sql.DB
+	db *timedDB
 }
 
 // NewGameRepo creates a GameRepo.
 func NewGameRepo(db *sql.DB) *GameRepo {
-	return &GameRepo{db: db}
+	return &GameRepo{db: &timedDB{db: db}}
+}
+
+// SetQueryTimeout bounds how long each GameRepo operation may run. Zero
+// (the default) leaves only the caller's context deadline.
+func (r *GameRepo) SetQueryTimeout(d time.Duration) {
+	r.db.timeout = d
+}
+
+// WithTx returns a GameRepo whose operations run inside tx, so they commit
+// or roll back together with the caller's other work.
+func (r *GameRepo) WithTx(tx *sql.Tx) *GameRepo {
+	return &GameRepo{db: r.db.withTx(tx)}
 }
 
 // Create inserts a new game.
-func (r *GameRepo) Create(ctx context.Context, name, creatorID, turnDur, retreatDur, buildDur, powerAssignment string) (*model.Game, error) {
+func (r *GameRepo) Create(ctx context.Context, name, creatorID, turnDur, retreatDur, buildDur, powerAssignment, speedPreset, scenario string, rules model.GameRules) (*model.Game, error) {
 	var g model.Game
 	err := r.db.QueryRowContext(ctx,
-		`INSERT INTO games (name, creator_id, turn_duration, retreat_duration, build_duration, power_assignment)
-		 VALUES ($1, $2, $3::interval, $4::interval, $5::interval, $6)
-		 RETURNING id, name, creator_id, status, turn_duration, retreat_duration, build_duration, power_assignment, created_at`,
-		name, creatorID, turnDur, retreatDur, buildDur, powerAssignment,
-	).Scan(&g.ID, &g.Name, &g.CreatorID, &g.Status, &g.TurnDuration, &g.RetreatDuration, &g.BuildDuration, &g.PowerAssignment, &g.CreatedAt)
+		`INSERT INTO games (name, creator_id, turn_duration, retreat_duration, build_duration, power_assignment, speed_preset, scenario,
+		                    retreat_credits, civil_disorder_after, civil_disorder_bot, ready_quorum, quorum_delay)
+		 VALUES ($1, $2, $3::interval, $4::interval, $5::interval, $6, $7, $8, $9, $10, $11, $12, $13::interval)
+		 RETURNING id, name, creator_id, status, turn_duration, retreat_duration, build_duration, power_assignment, speed_preset, scenario,
+		           retreat_credits, civil_disorder_after, civil_disorder_bot, ready_quorum, quorum_delay, created_at`,
+		name, creatorID, turnDur, retreatDur, buildDur, powerAssignment, speedPreset, scenario,
+		rules.RetreatCredits, rules.CivilDisorderAfter, rules.CivilDisorderBot, rules.ReadyQuorum, rules.QuorumDelay,
+	).Scan(&g.ID, &g.Name, &g.CreatorID, &g.Status, &g.TurnDuration, &g.RetreatDuration, &g.BuildDuration, &g.PowerAssignment, &g.SpeedPreset, &g.Scenario,
+		&g.RetreatCredits, &g.CivilDisorderAfter, &g.CivilDisorderBot, &g.ReadyQuorum, &g.QuorumDelay, &g.CreatedAt)
 	if err != nil {
 		return nil, fmt.Errorf("create game: %w", err)
 	}
 	return &g, nil
 }
 
-// FindByID returns a game by ID with its players.
+// FindByID returns a game by ID with its players and spectators.
 func (r *GameRepo) FindByID(ctx context.Context, id string) (*model.Game, error) {
 	var g model.Game
 	var winner sql.NullString
 	err := r.db.QueryRowContext(ctx,
 		`SELECT id, name, creator_id, status, winner, turn_duration, retreat_duration, build_duration,
-		        power_assignment, created_at, started_at, finished_at
+		        power_assignment, speed_preset, scenario, bot_press, press_mode, anonymous, garrisons, retreat_credits, mercy_years, ready_quorum, quorum_delay,
+		        civil_disorder_after, civil_disorder_bot, created_at, started_at, finished_at, deleted_at
 		 FROM games WHERE id = $1`, id,
 	).Scan(&g.ID, &g.Name, &g.CreatorID, &g.Status, &winner, &g.TurnDuration, &g.RetreatDuration, &g.BuildDuration,
-		&g.PowerAssignment, &g.CreatedAt, &g.StartedAt, &g.FinishedAt)
+		&g.PowerAssignment, &g.SpeedPreset, &g.Scenario, &g.BotPress, &g.PressMode, &g.Anonymous, &g.Garrisons, &g.RetreatCredits, &g.MercyYears, &g.ReadyQuorum, &g.QuorumDelay,
+		&g.CivilDisorderAfter, &g.CivilDisorderBot, &g.CreatedAt, &g.StartedAt, &g.FinishedAt, &g.DeletedAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -56,13 +80,19 @@ func (r *GameRepo) FindByID(ctx context.Context, id string) (*model.Game, error)
 		return nil, err
 	}
 	g.Players = players
+
+	spectators, err := r.ListSpectators(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	g.Spectators = spectators
 	return &g, nil
 }
 
 // ListOpen returns games in "waiting" status.
 func (r *GameRepo) ListOpen(ctx context.Context) ([]model.Game, error) {
 	rows, err := r.db.QueryContext(ctx,
-		`SELECT id, name, creator_id, status, turn_duration, retreat_duration, build_duration, power_assignment, created_at
+		`SELECT id, name, creator_id, status, turn_duration, retreat_duration, build_duration, power_assignment, speed_preset, scenario, created_at
 		 FROM games WHERE status = 'waiting' ORDER BY created_at DESC LIMIT 50`)
 	if err != nil {
 		return nil, fmt.Errorf("list open games: %w", err)
@@ -72,7 +102,7 @@ func (r *GameRepo) ListOpen(ctx context.Context) ([]model.Game, error) {
 	var games []model.Game
 	for rows.Next() {
 		var g model.Game
-		if err := rows.Scan(&g.ID, &g.Name, &g.CreatorID, &g.Status, &g.TurnDuration, &g.RetreatDuration, &g.BuildDuration, &g.PowerAssignment, &g.CreatedAt); err != nil {
+		if err := rows.Scan(&g.ID, &g.Name, &g.CreatorID, &g.Status, &g.TurnDuration, &g.RetreatDuration, &g.BuildDuration, &g.PowerAssignment, &g.SpeedPreset, &g.Scenario, &g.CreatedAt); err != nil {
 			return nil, fmt.Errorf("scan game: %w", err)
 		}
 		games = append(games, g)
@@ -81,12 +111,13 @@ func (r *GameRepo) ListOpen(ctx context.Context) ([]model.Game, error) {
 }
 
 // ListByUser returns all games a user is part of (as player or creator).
+// Games the user only spectates are left out.
 func (r *GameRepo) ListByUser(ctx context.Context, userID string) ([]model.Game, error) {
 	rows, err := r.db.QueryContext(ctx,
 		`SELECT DISTINCT g.id, g.name, g.creator_id, g.status, g.winner, g.turn_duration, g.retreat_duration, g.build_duration,
-		        g.power_assignment, g.created_at, g.started_at, g.finished_at
-		 FROM games g LEFT JOIN game_players gp ON g.id = gp.game_id AND gp.user_id = $1
-		 WHERE gp.user_id = $1 OR g.creator_id = $1
+		        g.power_assignment, g.speed_preset, g.scenario, g.created_at, g.started_at, g.finished_at
+		 FROM games g LEFT JOIN game_players gp ON g.id = gp.game_id AND gp.user_id = $1 AND NOT gp.spectator
+		 WHERE (gp.user_id = $1 OR g.creator_id = $1) AND g.status <> 'deleted'
 		 ORDER BY g.created_at DESC LIMIT 50`, userID)
 	if err != nil {
 		return nil, fmt.Errorf("list user games: %w", err)
@@ -98,7 +129,7 @@ func (r *GameRepo) ListByUser(ctx context.Context, userID string) ([]model.Game,
 		var g model.Game
 		var winner sql.NullString
 		if err := rows.Scan(&g.ID, &g.Name, &g.CreatorID, &g.Status, &winner, &g.TurnDuration, &g.RetreatDuration, &g.BuildDuration,
-			&g.PowerAssignment, &g.CreatedAt, &g.StartedAt, &g.FinishedAt); err != nil {
+			&g.PowerAssignment, &g.SpeedPreset, &g.Scenario, &g.CreatedAt, &g.StartedAt, &g.FinishedAt); err != nil {
 			return nil, fmt.Errorf("scan game: %w", err)
 		}
 		g.Winner = winner.String
@@ -107,11 +138,72 @@ func (r *GameRepo) ListByUser(ctx context.Context, userID string) ([]model.Game,
 	return games, rows.Err()
 }
 
+// ListActiveByUser returns every active game userID holds a seat in,
+// including their players.
+func (r *GameRepo) ListActiveByUser(ctx context.Context, userID string) ([]model.Game, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT g.id, g.name, g.creator_id, g.status, g.turn_duration, g.retreat_duration, g.build_duration,
+		        g.power_assignment, g.speed_preset, g.scenario, g.created_at, g.started_at
+		 FROM games g JOIN game_players gp ON gp.game_id = g.id
+		 WHERE gp.user_id = $1 AND NOT gp.spectator AND g.status = 'active'
+		 ORDER BY g.created_at DESC`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list active user games: %w", err)
+	}
+	defer rows.Close()
+
+	var games []model.Game
+	var ids []string
+	for rows.Next() {
+		var g model.Game
+		if err := rows.Scan(&g.ID, &g.Name, &g.CreatorID, &g.Status, &g.TurnDuration, &g.RetreatDuration, &g.BuildDuration,
+			&g.PowerAssignment, &g.SpeedPreset, &g.Scenario, &g.CreatedAt, &g.StartedAt); err != nil {
+			return nil, fmt.Errorf("scan game: %w", err)
+		}
+		games = append(games, g)
+		ids = append(ids, g.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(games) == 0 {
+		return games, nil
+	}
+
+	players, err := r.listPlayersForGames(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range games {
+		games[i].Players = players[games[i].ID]
+	}
+	return games, nil
+}
+
+// CountUserGames counts the started games userID holds a seat in: how many
+// they played, how many finished, and of those how many they won outright
+// and how many were drawn. Achievements are left empty.
+func (r *GameRepo) CountUserGames(ctx context.Context, userID string) (*model.UserStats, error) {
+	stats := &model.UserStats{UserID: userID}
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*),
+		        COUNT(*) FILTER (WHERE g.status = 'finished'),
+		        COUNT(*) FILTER (WHERE g.status = 'finished' AND g.winner = gp.power),
+		        COUNT(*) FILTER (WHERE g.status = 'finished' AND COALESCE(g.winner, '') = '')
+		 FROM games g JOIN game_players gp ON gp.game_id = g.id
+		 WHERE gp.user_id = $1 AND NOT gp.spectator AND g.status IN ('active', 'finished')`, userID,
+	).Scan(&stats.GamesPlayed, &stats.GamesFinished, &stats.Solos, &stats.Draws)
+	if err != nil {
+		return nil, fmt.Errorf("count user games: %w", err)
+	}
+	return stats, nil
+}
+
 // ListFinished returns all finished games, most recent first.
 func (r *GameRepo) ListFinished(ctx context.Context) ([]model.Game, error) {
 	rows, err := r.db.QueryContext(ctx,
 		`SELECT g.id, g.name, g.creator_id, g.status, g.winner, g.turn_duration, g.retreat_duration, g.build_duration,
-		        g.power_assignment, g.created_at, g.started_at, g.finished_at
+		        g.power_assignment, g.speed_preset, g.scenario, g.created_at, g.started_at, g.finished_at
 		 FROM games g
 		 WHERE g.status = 'finished'
 		 ORDER BY g.finished_at DESC LIMIT 100`)
@@ -125,7 +217,7 @@ func (r *GameRepo) ListFinished(ctx context.Context) ([]model.Game, error) {
 		var g model.Game
 		var winner sql.NullString
 		if err := rows.Scan(&g.ID, &g.Name, &g.CreatorID, &g.Status, &winner, &g.TurnDuration, &g.RetreatDuration, &g.BuildDuration,
-			&g.PowerAssignment, &g.CreatedAt, &g.StartedAt, &g.FinishedAt); err != nil {
+			&g.PowerAssignment, &g.SpeedPreset, &g.Scenario, &g.CreatedAt, &g.StartedAt, &g.FinishedAt); err != nil {
 			return nil, fmt.Errorf("scan game: %w", err)
 		}
 		g.Winner = winner.String
@@ -138,7 +230,7 @@ func (r *GameRepo) ListFinished(ctx context.Context) ([]model.Game, error) {
 func (r *GameRepo) SearchFinished(ctx context.Context, search string) ([]model.Game, error) {
 	rows, err := r.db.QueryContext(ctx,
 		`SELECT g.id, g.name, g.creator_id, g.status, g.winner, g.turn_duration, g.retreat_duration, g.build_duration,
-		        g.power_assignment, g.created_at, g.started_at, g.finished_at
+		        g.power_assignment, g.speed_preset, g.scenario, g.created_at, g.started_at, g.finished_at
 		 FROM games g
 		 WHERE g.status = 'finished' AND g.name ILIKE '%' || $1 || '%'
 		 ORDER BY g.finished_at DESC LIMIT 100`, search)
@@ -152,7 +244,7 @@ func (r *GameRepo) SearchFinished(ctx context.Context, search string) ([]model.G
 		var g model.Game
 		var winner sql.NullString
 		if err := rows.Scan(&g.ID, &g.Name, &g.CreatorID, &g.Status, &winner, &g.TurnDuration, &g.RetreatDuration, &g.BuildDuration,
-			&g.PowerAssignment, &g.CreatedAt, &g.StartedAt, &g.FinishedAt); err != nil {
+			&g.PowerAssignment, &g.SpeedPreset, &g.Scenario, &g.CreatedAt, &g.StartedAt, &g.FinishedAt); err != nil {
 			return nil, fmt.Errorf("scan game: %w", err)
 		}
 		g.Winner = winner.String
@@ -161,23 +253,157 @@ func (r *GameRepo) SearchFinished(ctx context.Context, search string) ([]model.G
 	return games, rows.Err()
 }
 
-// JoinGame adds a player to a game.
+// ListFinishedPage returns up to limit finished games, most recently finished
+// first, including their players. A non-empty search keeps games whose name
+// contains it (case-insensitive). Pass an empty afterID for the first page and
+// the last returned ID for each following page.
+func (r *GameRepo) ListFinishedPage(ctx context.Context, search, afterID string, limit int) ([]model.Game, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT g.id, g.name, g.creator_id, g.status, g.winner, g.turn_duration, g.retreat_duration, g.build_duration,
+		        g.power_assignment, g.speed_preset, g.scenario, g.created_at, g.started_at, g.finished_at
+		 FROM games g
+		 WHERE g.status = 'finished'
+		   AND ($1 = '' OR g.name ILIKE '%' || $1 || '%')
+		   AND ($2 = '' OR (COALESCE(g.finished_at, g.created_at), g.id) <
+		       (SELECT COALESCE(finished_at, created_at), id FROM games WHERE id = NULLIF($2, '')::uuid))
+		 ORDER BY COALESCE(g.finished_at, g.created_at) DESC, g.id DESC LIMIT $3`, search, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list finished games: %w", err)
+	}
+	defer rows.Close()
+
+	var games []model.Game
+	var ids []string
+	for rows.Next() {
+		var g model.Game
+		var winner sql.NullString
+		if err := rows.Scan(&g.ID, &g.Name, &g.CreatorID, &g.Status, &winner, &g.TurnDuration, &g.RetreatDuration, &g.BuildDuration,
+			&g.PowerAssignment, &g.SpeedPreset, &g.Scenario, &g.CreatedAt, &g.StartedAt, &g.FinishedAt); err != nil {
+			return nil, fmt.Errorf("scan game: %w", err)
+		}
+		g.Winner = winner.String
+		games = append(games, g)
+		ids = append(ids, g.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(games) == 0 {
+		return games, nil
+	}
+
+	players, err := r.listPlayersForGames(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range games {
+		games[i].Players = players[games[i].ID]
+	}
+	return games, nil
+}
+
+// nextSeat is the seat after the highest taken in game $1. Two concurrent
+// inserts computing the same seat collide on the unique seat index.
+const nextSeat = `(SELECT COALESCE(MAX(seat) + 1, 0) FROM game_players WHERE game_id = $1)`
+
+// JoinGame adds a player to a game in the next seat. A spectator joining
+// becomes a player; joining again as a player does nothing.
 func (r *GameRepo) JoinGame(ctx context.Context, gameID, userID string) error {
 	_, err := r.db.ExecContext(ctx,
-		`INSERT INTO game_players (game_id, user_id) VALUES ($1, $2)
-		 ON CONFLICT DO NOTHING`,
+		`INSERT INTO game_players (game_id, user_id, seat) VALUES ($1, $2, `+nextSeat+`)
+		 ON CONFLICT (game_id, user_id) DO UPDATE SET spectator = false, seat = EXCLUDED.seat, joined_at = now()
+		 WHERE game_players.spectator`,
 		gameID, userID,
 	)
 	if err != nil {
-		return fmt.Errorf("join game: %w", err)
+		return fmt.Errorf("join game: %w", seatError(err))
 	}
 	return nil
 }
 
-// ListPlayers returns all players in a game.
+// ClaimSeat seats userID in a waiting game with the given number of seats,
+// taking the lowest free seat or, when every seat is held, a bot's. The game
+// row stays locked until the claim commits, so concurrent joins are seated
+// one at a time, and the unique seat index rejects any claim that slips
+// past. It returns repository.ErrNotJoinable, ErrAlreadySeated or
+// ErrSeatTaken when the user cannot be seated.
+func (r *GameRepo) ClaimSeat(ctx context.Context, gameID, userID string, seats int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var status string
+	err = tx.QueryRowContext(ctx, `SELECT status FROM games WHERE id = $1 FOR UPDATE`, gameID).Scan(&status)
+	if err != nil {
+		return fmt.Errorf("lock game: %w", err)
+	}
+	if status != "waiting" {
+		return repository.ErrNotJoinable
+	}
+
+	var seated bool
+	var seat sql.NullInt64
+	err = tx.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM game_players WHERE game_id = $1 AND user_id = $2 AND NOT spectator),
+		        (SELECT MIN(s) FROM generate_series(0, $3 - 1) s
+		         WHERE NOT EXISTS (SELECT 1 FROM game_players WHERE game_id = $1 AND seat = s))`,
+		gameID, userID, seats,
+	).Scan(&seated, &seat)
+	if err != nil {
+		return fmt.Errorf("find free seat: %w", err)
+	}
+	if seated {
+		return repository.ErrAlreadySeated
+	}
+	if !seat.Valid {
+		// Every seat is held; take the most recently seated bot's.
+		err = tx.QueryRowContext(ctx,
+			`DELETE FROM game_players WHERE game_id = $1 AND user_id = (
+			     SELECT user_id FROM game_players WHERE game_id = $1 AND is_bot AND NOT spectator
+			     ORDER BY seat DESC LIMIT 1)
+			 RETURNING seat`,
+			gameID,
+		).Scan(&seat)
+		if err == sql.ErrNoRows {
+			return repository.ErrSeatTaken
+		}
+		if err != nil {
+			return fmt.Errorf("remove bot: %w", err)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO game_players (game_id, user_id, seat) VALUES ($1, $2, $3)
+		 ON CONFLICT (game_id, user_id) DO UPDATE SET spectator = false, seat = EXCLUDED.seat, joined_at = now()`,
+		gameID, userID, seat.Int64,
+	)
+	if err != nil {
+		return fmt.Errorf("claim seat: %w", seatError(err))
+	}
+	return tx.Commit()
+}
+
+// pqUniqueViolation is the SQLSTATE Postgres reports when an insert or
+// update breaks a unique index.
+const pqUniqueViolation = "23505"
+
+// seatError maps a violation of the unique seat index to
+// repository.ErrSeatTaken.
+func seatError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == pqUniqueViolation && pqErr.Constraint == "game_players_seat_key" {
+		return repository.ErrSeatTaken
+	}
+	return err
+}
+
+// ListPlayers returns all players in a game, excluding spectators.
 func (r *GameRepo) ListPlayers(ctx context.Context, gameID string) ([]model.GamePlayer, error) {
 	rows, err := r.db.QueryContext(ctx,
-		`SELECT game_id, user_id, power, is_bot, bot_difficulty, joined_at FROM game_players WHERE game_id = $1 ORDER BY joined_at`,
+		`SELECT game_id, user_id, power, is_bot, bot_difficulty, bot_personality, joined_at, missed_deadlines, civil_disorder, eliminated_by, eliminated_year FROM game_players
+		 WHERE game_id = $1 AND NOT spectator ORDER BY joined_at`,
 		gameID,
 	)
 	if err != nil {
@@ -189,73 +415,168 @@ func (r *GameRepo) ListPlayers(ctx context.Context, gameID string) ([]model.Game
 	for rows.Next() {
 		var p model.GamePlayer
 		var power sql.NullString
-		if err := rows.Scan(&p.GameID, &p.UserID, &power, &p.IsBot, &p.BotDifficulty, &p.JoinedAt); err != nil {
+		var personality []byte
+		if err := rows.Scan(&p.GameID, &p.UserID, &power, &p.IsBot, &p.BotDifficulty, &personality, &p.JoinedAt, &p.MissedDeadlines, &p.CivilDisorder, &p.EliminatedBy, &p.EliminatedYear); err != nil {
 			return nil, fmt.Errorf("scan player: %w", err)
 		}
 		p.Power = power.String
+		if p.BotPersonality, err = decodeBotPersonality(personality); err != nil {
+			return nil, err
+		}
 		players = append(players, p)
 	}
 	return players, rows.Err()
 }
 
+// Spectate adds a user to a game as a spectator. Players stay players.
+func (r *GameRepo) Spectate(ctx context.Context, gameID, userID string) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO game_players (game_id, user_id, spectator) VALUES ($1, $2, true)
+		 ON CONFLICT DO NOTHING`,
+		gameID, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("spectate game: %w", err)
+	}
+	return nil
+}
+
+// ListSpectators returns the user IDs spectating a game, in join order.
+func (r *GameRepo) ListSpectators(ctx context.Context, gameID string) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT user_id FROM game_players WHERE game_id = $1 AND spectator ORDER BY joined_at`,
+		gameID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list spectators: %w", err)
+	}
+	defer rows.Close()
+
+	var spectators []string
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("scan spectator: %w", err)
+		}
+		spectators = append(spectators, userID)
+	}
+	return spectators, rows.Err()
+}
+
 // JoinGameAsBot adds a bot player to a game with the given difficulty level.
 func (r *GameRepo) JoinGameAsBot(ctx context.Context, gameID, userID, difficulty string) error {
 	if difficulty == "" {
 		difficulty = "easy"
 	}
 	_, err := r.db.ExecContext(ctx,
-		`INSERT INTO game_players (game_id, user_id, is_bot, bot_difficulty) VALUES ($1, $2, true, $3)
-		 ON CONFLICT DO NOTHING`,
+		`INSERT INTO game_players (game_id, user_id, is_bot, bot_difficulty, seat) VALUES ($1, $2, true, $3, `+nextSeat+`)
+		 ON CONFLICT (game_id, user_id) DO NOTHING`,
 		gameID, userID, difficulty,
 	)
 	if err != nil {
-		return fmt.Errorf("join game as bot: %w", err)
+		return fmt.Errorf("join game as bot: %w", seatError(err))
 	}
 	return nil
 }
 
-// ReplaceBot atomically removes one bot from the game and inserts the human player.
-func (r *GameRepo) ReplaceBot(ctx context.Context, gameID, newUserID string) error {
+// RecordDeadline counts a missed deadline against each human player in
+// missed, flagging those reaching the game's civil disorder threshold, and
+// clears the count and flag of those in acted. It returns the missed
+// players as updated.
+func (r *GameRepo) RecordDeadline(ctx context.Context, gameID string, missed, acted []string) ([]model.GamePlayer, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`WITH reset AS (
+		     UPDATE game_players SET missed_deadlines = 0, civil_disorder = false
+		     WHERE game_id = $1 AND user_id = ANY($3::uuid[]) AND (missed_deadlines > 0 OR civil_disorder)
+		 )
+		 UPDATE game_players gp
+		 SET missed_deadlines = gp.missed_deadlines + 1,
+		     civil_disorder = gp.civil_disorder OR (g.civil_disorder_after > 0 AND gp.missed_deadlines + 1 >= g.civil_disorder_after)
+		 FROM games g
+		 WHERE g.id = gp.game_id AND gp.game_id = $1 AND gp.user_id = ANY($2::uuid[]) AND NOT gp.is_bot AND NOT gp.spectator
+		 RETURNING gp.game_id, gp.user_id, gp.power, gp.is_bot, gp.bot_difficulty, gp.bot_personality, gp.joined_at, gp.missed_deadlines, gp.civil_disorder, gp.eliminated_by, gp.eliminated_year`,
+		gameID, pq.Array(missed), pq.Array(acted),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("record deadline: %w", err)
+	}
+	defer rows.Close()
+
+	var players []model.GamePlayer
+	for rows.Next() {
+		var p model.GamePlayer
+		var power sql.NullString
+		var personality []byte
+		if err := rows.Scan(&p.GameID, &p.UserID, &power, &p.IsBot, &p.BotDifficulty, &personality, &p.JoinedAt, &p.MissedDeadlines, &p.CivilDisorder, &p.EliminatedBy, &p.EliminatedYear); err != nil {
+			return nil, fmt.Errorf("scan player: %w", err)
+		}
+		p.Power = power.String
+		if p.BotPersonality, err = decodeBotPersonality(personality); err != nil {
+			return nil, err
+		}
+		players = append(players, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return players, nil
+}
+
+// ReplaceWithBot hands a human player's power to a bot user, which plays it
+// with the given strategy; the human stays on as a spectator. It returns the
+// bot's player record, or nil if userID isn't a human player in the game.
+func (r *GameRepo) ReplaceWithBot(ctx context.Context, gameID, userID, botUserID, difficulty string) (*model.GamePlayer, error) {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("begin tx: %w", err)
+		return nil, fmt.Errorf("begin tx: %w", err)
 	}
 	defer tx.Rollback()
 
-	// Find one bot to remove
-	var botUserID string
+	var p model.GamePlayer
+	var power sql.NullString
+	var personality []byte
 	err = tx.QueryRowContext(ctx,
-		`SELECT user_id FROM game_players WHERE game_id = $1 AND is_bot = true LIMIT 1`,
-		gameID,
-	).Scan(&botUserID)
-	if err != nil {
-		return fmt.Errorf("find bot to replace: %w", err)
+		`UPDATE game_players
+		 SET user_id = $3, is_bot = true, bot_difficulty = $4, bot_personality = NULL, missed_deadlines = 0, civil_disorder = false, joined_at = now()
+		 WHERE game_id = $1 AND user_id = $2 AND NOT is_bot AND NOT spectator
+		 RETURNING game_id, user_id, power, is_bot, bot_difficulty, bot_personality, joined_at, missed_deadlines, civil_disorder, eliminated_by, eliminated_year`,
+		gameID, userID, botUserID, difficulty,
+	).Scan(&p.GameID, &p.UserID, &power, &p.IsBot, &p.BotDifficulty, &personality, &p.JoinedAt, &p.MissedDeadlines, &p.CivilDisorder, &p.EliminatedBy, &p.EliminatedYear)
+	if err == sql.ErrNoRows {
+		return nil, nil
 	}
-
-	_, err = tx.ExecContext(ctx,
-		`DELETE FROM game_players WHERE game_id = $1 AND user_id = $2`,
-		gameID, botUserID,
-	)
 	if err != nil {
-		return fmt.Errorf("remove bot: %w", err)
+		return nil, fmt.Errorf("replace with bot: %w", err)
 	}
+	p.Power = power.String
 
 	_, err = tx.ExecContext(ctx,
-		`INSERT INTO game_players (game_id, user_id) VALUES ($1, $2)`,
-		gameID, newUserID,
+		`INSERT INTO game_players (game_id, user_id, spectator) VALUES ($1, $2, true)`,
+		gameID, userID,
 	)
 	if err != nil {
-		return fmt.Errorf("insert human: %w", err)
+		return nil, fmt.Errorf("keep replaced player as spectator: %w", err)
 	}
-
-	return tx.Commit()
+	if p.Power != "" {
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO power_assignments (game_id, power, user_id, is_bot) VALUES ($1, $2, $3, true)`,
+			gameID, p.Power, botUserID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("record power assignment: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit replace with bot: %w", err)
+	}
+	return &p, nil
 }
 
 // PlayerCount returns the number of players in a game.
 func (r *GameRepo) PlayerCount(ctx context.Context, gameID string) (int, error) {
 	var count int
 	err := r.db.QueryRowContext(ctx,
-		`SELECT COUNT(*) FROM game_players WHERE game_id = $1`, gameID,
+		`SELECT COUNT(*) FROM game_players WHERE game_id = $1 AND NOT spectator`, gameID,
 	).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("player count: %w", err)
@@ -281,6 +602,15 @@ func (r *GameRepo) AssignPowers(ctx context.Context, gameID string, assignments
 		}
 	}
 
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO power_assignments (game_id, power, user_id, is_bot)
+		 SELECT game_id, power, user_id, is_bot FROM game_players
+		 WHERE game_id = $1 AND power IS NOT NULL AND NOT spectator`, gameID,
+	)
+	if err != nil {
+		return fmt.Errorf("record power assignments: %w", err)
+	}
+
 	_, err = tx.ExecContext(ctx,
 		`UPDATE games SET status = 'active', started_at = now() WHERE id = $1`, gameID,
 	)
@@ -291,39 +621,336 @@ func (r *GameRepo) AssignPowers(ctx context.Context, gameID string, assignments
 	return tx.Commit()
 }
 
-// ListActive returns all games with status 'active', including their players.
-func (r *GameRepo) ListActive(ctx context.Context) ([]model.Game, error) {
+// PowerAssignments returns who took over each of a game's powers and when,
+// oldest first.
+func (r *GameRepo) PowerAssignments(ctx context.Context, gameID string) ([]model.PowerAssignment, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT power, user_id, is_bot, assigned_at FROM power_assignments
+		 WHERE game_id = $1 ORDER BY assigned_at, id`, gameID)
+	if err != nil {
+		return nil, fmt.Errorf("list power assignments: %w", err)
+	}
+	defer rows.Close()
+	var assignments []model.PowerAssignment
+	for rows.Next() {
+		var a model.PowerAssignment
+		if err := rows.Scan(&a.Power, &a.UserID, &a.IsBot, &a.AssignedAt); err != nil {
+			return nil, fmt.Errorf("scan power assignment: %w", err)
+		}
+		assignments = append(assignments, a)
+	}
+	return assignments, rows.Err()
+}
+
+// ListActive returns up to limit games with status 'active' whose ID sorts
+// after afterID, including their players. Pass an empty afterID for the first
+// page and the last returned ID for each following page.
+func (r *GameRepo) ListActive(ctx context.Context, afterID string, limit int) ([]model.Game, error) {
+	if afterID == "" {
+		afterID = "00000000-0000-0000-0000-000000000000"
+	}
 	rows, err := r.db.QueryContext(ctx,
-		`SELECT id, name, creator_id, status, turn_duration, retreat_duration, build_duration, power_assignment, created_at
-		 FROM games WHERE status = 'active' ORDER BY created_at`)
+		`SELECT id, name, creator_id, status, turn_duration, retreat_duration, build_duration, power_assignment, speed_preset, scenario, created_at
+		 FROM games WHERE status = 'active' AND id > $1 ORDER BY id LIMIT $2`, afterID, limit)
 	if err != nil {
 		return nil, fmt.Errorf("list active games: %w", err)
 	}
 	defer rows.Close()
 
 	var games []model.Game
+	var ids []string
 	for rows.Next() {
 		var g model.Game
-		if err := rows.Scan(&g.ID, &g.Name, &g.CreatorID, &g.Status, &g.TurnDuration, &g.RetreatDuration, &g.BuildDuration, &g.PowerAssignment, &g.CreatedAt); err != nil {
+		if err := rows.Scan(&g.ID, &g.Name, &g.CreatorID, &g.Status, &g.TurnDuration, &g.RetreatDuration, &g.BuildDuration, &g.PowerAssignment, &g.SpeedPreset, &g.Scenario, &g.CreatedAt); err != nil {
 			return nil, fmt.Errorf("scan game: %w", err)
 		}
-		players, err := r.ListPlayers(ctx, g.ID)
-		if err != nil {
-			return nil, err
+		games = append(games, g)
+		ids = append(ids, g.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(games) == 0 {
+		return games, nil
+	}
+
+	players, err := r.listPlayersForGames(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range games {
+		games[i].Players = players[games[i].ID]
+	}
+	return games, nil
+}
+
+// RankLive returns up to limit of the given games that are active or paused,
+// ranked by viewers, then by most recent activity.
+func (r *GameRepo) RankLive(ctx context.Context, activity []model.GameActivity, limit int) ([]model.LiveGame, error) {
+	ids := make([]string, len(activity))
+	viewers := make([]int64, len(activity))
+	activeMS := make([]int64, len(activity))
+	for i, a := range activity {
+		ids[i], viewers[i] = a.GameID, int64(a.Viewers)
+		if !a.LastActivity.IsZero() {
+			activeMS[i] = a.LastActivity.UnixMilli()
+		}
+	}
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT g.id, g.name, g.status, g.scenario, g.anonymous, c.viewers, c.active_ms,
+		        EXISTS (SELECT 1 FROM game_players p WHERE p.game_id = g.id AND NOT p.spectator)
+		        AND NOT EXISTS (SELECT 1 FROM game_players p WHERE p.game_id = g.id AND NOT p.spectator AND NOT p.is_bot)
+		 FROM unnest($1::uuid[], $2::bigint[], $3::bigint[]) AS c(game_id, viewers, active_ms)
+		 JOIN games g ON g.id = c.game_id
+		 WHERE g.status IN ('active', 'paused')
+		 ORDER BY c.viewers DESC, c.active_ms DESC LIMIT $4`,
+		pq.Array(ids), pq.Array(viewers), pq.Array(activeMS), limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("rank live games: %w", err)
+	}
+	defer rows.Close()
+
+	games := []model.LiveGame{}
+	for rows.Next() {
+		var g model.LiveGame
+		var activeAt int64
+		if err := rows.Scan(&g.GameID, &g.Name, &g.Status, &g.Scenario, &g.Anonymous, &g.Viewers, &activeAt, &g.BotOnly); err != nil {
+			return nil, fmt.Errorf("scan live game: %w", err)
+		}
+		if activeAt > 0 {
+			g.LastActivity = time.UnixMilli(activeAt)
 		}
-		g.Players = players
 		games = append(games, g)
 	}
 	return games, rows.Err()
 }
 
-// UpdateBotDifficulty changes the difficulty level of a bot player.
-func (r *GameRepo) UpdateBotDifficulty(ctx context.Context, gameID, botUserID, difficulty string) error {
-	_, err := r.db.ExecContext(ctx,
-		`UPDATE game_players SET bot_difficulty = $1 WHERE game_id = $2 AND user_id = $3 AND is_bot = true`,
-		difficulty, gameID, botUserID)
+// listPlayersForGames loads the players of several games in one query.
+func (r *GameRepo) listPlayersForGames(ctx context.Context, gameIDs []string) (map[string][]model.GamePlayer, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT game_id, user_id, power, is_bot, bot_difficulty, bot_personality, joined_at, missed_deadlines, civil_disorder, eliminated_by, eliminated_year FROM game_players
+		 WHERE game_id = ANY($1::uuid[]) AND NOT spectator ORDER BY game_id, joined_at`,
+		pq.Array(gameIDs),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list players: %w", err)
+	}
+	defer rows.Close()
+
+	players := make(map[string][]model.GamePlayer, len(gameIDs))
+	for rows.Next() {
+		var p model.GamePlayer
+		var power sql.NullString
+		var personality []byte
+		if err := rows.Scan(&p.GameID, &p.UserID, &power, &p.IsBot, &p.BotDifficulty, &personality, &p.JoinedAt, &p.MissedDeadlines, &p.CivilDisorder, &p.EliminatedBy, &p.EliminatedYear); err != nil {
+			return nil, fmt.Errorf("scan player: %w", err)
+		}
+		p.Power = power.String
+		if p.BotPersonality, err = decodeBotPersonality(personality); err != nil {
+			return nil, err
+		}
+		players[p.GameID] = append(players[p.GameID], p)
+	}
+	return players, rows.Err()
+}
+
+// botDifficultyColumns are the bot_difficulty_changes columns read by
+// scanBotDifficultyChange.
+const botDifficultyColumns = `id, game_id, user_id, power, from_difficulty, to_difficulty, reason, changed_by, phase_id, requested_at, applied_at`
+
+func scanBotDifficultyChange(scan func(dest ...any) error) (*model.BotDifficultyChange, error) {
+	var c model.BotDifficultyChange
+	var power, changedBy, phaseID sql.NullString
+	if err := scan(&c.ID, &c.GameID, &c.UserID, &power, &c.From, &c.To, &c.Reason, &changedBy, &phaseID, &c.RequestedAt, &c.AppliedAt); err != nil {
+		return nil, err
+	}
+	c.Power, c.ChangedBy, c.PhaseID = power.String, changedBy.String, phaseID.String
+	return &c, nil
+}
+
+// UpdateBotDifficulty changes the difficulty level of a bot player now,
+// replacing any pending change, and records it in the player's history
+// against the game's current phase. It returns nil if the player is not a
+// bot in the game. changedBy may be empty for changes the server makes.
+func (r *GameRepo) UpdateBotDifficulty(ctx context.Context, gameID, botUserID, difficulty, reason, changedBy string) (*model.BotDifficultyChange, error) {
+	c, err := scanBotDifficultyChange(r.db.QueryRowContext(ctx,
+		`WITH player AS (
+			SELECT user_id, power, bot_difficulty FROM game_players
+			WHERE game_id = $1::uuid AND user_id = $2::uuid AND is_bot = true
+			FOR UPDATE
+		), updated AS (
+			UPDATE game_players gp SET bot_difficulty = $3
+			FROM player WHERE gp.game_id = $1::uuid AND gp.user_id = player.user_id
+		), dropped AS (
+			DELETE FROM bot_difficulty_changes WHERE game_id = $1::uuid AND user_id = $2::uuid AND applied_at IS NULL
+		)
+		INSERT INTO bot_difficulty_changes (game_id, user_id, power, from_difficulty, to_difficulty, reason, changed_by, phase_id, applied_at)
+		SELECT $1::uuid, user_id, power, bot_difficulty, $3, $4, NULLIF($5, '')::uuid,
+			(SELECT id FROM phases WHERE game_id = $1::uuid ORDER BY created_at DESC LIMIT 1), now()
+		FROM player
+		RETURNING `+botDifficultyColumns,
+		gameID, botUserID, difficulty, reason, changedBy).Scan)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("update bot difficulty: %w", err)
+	}
+	return c, nil
+}
+
+// RequestBotDifficulty records a change of a bot player's difficulty to
+// take effect when the next phase starts, replacing any pending change. It
+// returns nil if the player is not a bot in the game.
+func (r *GameRepo) RequestBotDifficulty(ctx context.Context, gameID, botUserID, difficulty, changedBy string) (*model.BotDifficultyChange, error) {
+	c, err := scanBotDifficultyChange(r.db.QueryRowContext(ctx,
+		`INSERT INTO bot_difficulty_changes (game_id, user_id, power, from_difficulty, to_difficulty, reason, changed_by)
+		SELECT game_id, user_id, power, bot_difficulty, $3, $4, NULLIF($5, '')::uuid
+		FROM game_players WHERE game_id = $1::uuid AND user_id = $2::uuid AND is_bot = true
+		ON CONFLICT (game_id, user_id) WHERE applied_at IS NULL DO UPDATE
+		SET to_difficulty = EXCLUDED.to_difficulty, changed_by = EXCLUDED.changed_by, requested_at = now()
+		RETURNING `+botDifficultyColumns,
+		gameID, botUserID, difficulty, model.BotDifficultyByCreator, changedBy).Scan)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("request bot difficulty: %w", err)
+	}
+	return c, nil
+}
+
+// ApplyPendingBotDifficulties puts a game's pending difficulty changes into
+// effect from the given phase and returns them.
+func (r *GameRepo) ApplyPendingBotDifficulties(ctx context.Context, gameID, phaseID string) ([]model.BotDifficultyChange, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`WITH applied AS (
+			UPDATE bot_difficulty_changes c
+			SET applied_at = now(), phase_id = $2::uuid, from_difficulty = gp.bot_difficulty, power = gp.power
+			FROM game_players gp
+			WHERE c.game_id = $1::uuid AND c.applied_at IS NULL
+			  AND gp.game_id = c.game_id AND gp.user_id = c.user_id AND gp.is_bot = true
+			RETURNING c.*
+		), updated AS (
+			UPDATE game_players gp SET bot_difficulty = applied.to_difficulty
+			FROM applied WHERE gp.game_id = $1::uuid AND gp.user_id = applied.user_id
+		)
+		SELECT `+botDifficultyColumns+` FROM applied ORDER BY id`,
+		gameID, phaseID)
+	if err != nil {
+		return nil, fmt.Errorf("apply bot difficulties: %w", err)
+	}
+	defer rows.Close()
+	var changes []model.BotDifficultyChange
+	for rows.Next() {
+		c, err := scanBotDifficultyChange(rows.Scan)
+		if err != nil {
+			return nil, fmt.Errorf("scan bot difficulty change: %w", err)
+		}
+		changes = append(changes, *c)
+	}
+	return changes, rows.Err()
+}
+
+// BotDifficultyChanges returns a game's bot difficulty changes, pending ones
+// included, oldest first.
+func (r *GameRepo) BotDifficultyChanges(ctx context.Context, gameID string) ([]model.BotDifficultyChange, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+botDifficultyColumns+` FROM bot_difficulty_changes WHERE game_id = $1 ORDER BY requested_at, id`,
+		gameID)
+	if err != nil {
+		return nil, fmt.Errorf("list bot difficulty changes: %w", err)
+	}
+	defer rows.Close()
+	var changes []model.BotDifficultyChange
+	for rows.Next() {
+		c, err := scanBotDifficultyChange(rows.Scan)
+		if err != nil {
+			return nil, fmt.Errorf("scan bot difficulty change: %w", err)
+		}
+		changes = append(changes, *c)
+	}
+	return changes, rows.Err()
+}
+
+// UpdatePressSettings sets which press the game allows and whether it hides
+// player identities.
+func (r *GameRepo) UpdatePressSettings(ctx context.Context, gameID, pressMode string, anonymous bool) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE games SET press_mode = $1, anonymous = $2 WHERE id = $3`, pressMode, anonymous, gameID)
+	if err != nil {
+		return fmt.Errorf("update press settings: %w", err)
+	}
+	return nil
+}
+
+// UpdateGarrisons sets whether the game starts with neutral garrisons.
+func (r *GameRepo) UpdateGarrisons(ctx context.Context, gameID string, garrisons bool) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE games SET garrisons = $1 WHERE id = $2`, garrisons, gameID)
 	if err != nil {
-		return fmt.Errorf("update bot difficulty: %w", err)
+		return fmt.Errorf("update garrisons: %w", err)
+	}
+	return nil
+}
+
+// UpdateRetreatCredits sets whether the game plays the retreat credits house rule.
+func (r *GameRepo) UpdateRetreatCredits(ctx context.Context, gameID string, retreatCredits bool) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE games SET retreat_credits = $1 WHERE id = $2`, retreatCredits, gameID)
+	if err != nil {
+		return fmt.Errorf("update retreat credits: %w", err)
+	}
+	return nil
+}
+
+// UpdateMercyYears sets for how many years bots spare the human players'
+// home centers.
+func (r *GameRepo) UpdateMercyYears(ctx context.Context, gameID string, years int) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE games SET mercy_years = $1 WHERE id = $2`, years, gameID)
+	if err != nil {
+		return fmt.Errorf("update mercy years: %w", err)
+	}
+	return nil
+}
+
+// UpdateReadyQuorum sets the percentage of powers whose readiness resolves a
+// movement phase early, and how long the quorum must hold first.
+func (r *GameRepo) UpdateReadyQuorum(ctx context.Context, gameID string, percent int, delay string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE games SET ready_quorum = $1, quorum_delay = $2::interval WHERE id = $3`, percent, delay, gameID)
+	if err != nil {
+		return fmt.Errorf("update ready quorum: %w", err)
+	}
+	return nil
+}
+
+// UpdateCivilDisorder sets how many deadlines a player may miss in a row
+// before civil disorder, and the strategy of the bot that then replaces
+// them; empty leaves them in place.
+func (r *GameRepo) UpdateCivilDisorder(ctx context.Context, gameID string, after int, botDifficulty string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE games SET civil_disorder_after = $1, civil_disorder_bot = $2 WHERE id = $3`, after, botDifficulty, gameID)
+	if err != nil {
+		return fmt.Errorf("update civil disorder: %w", err)
+	}
+	return nil
+}
+
+// UpdateBotPress sets how the game's bots word their press.
+func (r *GameRepo) UpdateBotPress(ctx context.Context, gameID, style string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE games SET bot_press = $1 WHERE id = $2`, style, gameID)
+	if err != nil {
+		return fmt.Errorf("update bot press: %w", err)
+	}
+	return nil
+}
+
+// SetPaused moves a game between the active and paused statuses.
+func (r *GameRepo) SetPaused(ctx context.Context, gameID string, paused bool) error {
+	from, to := "active", "paused"
+	if !paused {
+		from, to = to, from
+	}
+	_, err := r.db.ExecContext(ctx, `UPDATE games SET status = $1 WHERE id = $2 AND status = $3`, to, gameID, from)
+	if err != nil {
+		return fmt.Errorf("set game paused: %w", err)
 	}
 	return nil
 }
@@ -340,6 +967,51 @@ func (r *GameRepo) UpdatePlayerPower(ctx context.Context, gameID, userID, power
 	return nil
 }
 
+// UpdateBotPersonality sets a bot player's personality; nil restores its
+// strategy's usual play.
+func (r *GameRepo) UpdateBotPersonality(ctx context.Context, gameID, botUserID string, personality *model.BotPersonality) error {
+	var raw []byte
+	if personality != nil {
+		var err error
+		if raw, err = json.Marshal(personality); err != nil {
+			return fmt.Errorf("marshal bot personality: %w", err)
+		}
+	}
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE game_players SET bot_personality = $1 WHERE game_id = $2 AND user_id = $3 AND is_bot`,
+		raw, gameID, botUserID,
+	)
+	if err != nil {
+		return fmt.Errorf("update bot personality: %w", err)
+	}
+	return nil
+}
+
+// decodeBotPersonality unmarshals a nullable bot_personality column.
+func decodeBotPersonality(raw []byte) (*model.BotPersonality, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	var p model.BotPersonality
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return nil, fmt.Errorf("unmarshal bot personality: %w", err)
+	}
+	return &p, nil
+}
+
+// SetEliminated records the year a power lost its last supply center and
+// the power that took most of them.
+func (r *GameRepo) SetEliminated(ctx context.Context, gameID, power, by string, year int) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE game_players SET eliminated_by = $1, eliminated_year = $2 WHERE game_id = $3 AND power = $4 AND NOT spectator`,
+		by, year, gameID, power,
+	)
+	if err != nil {
+		return fmt.Errorf("set eliminated: %w", err)
+	}
+	return nil
+}
+
 // Delete removes a game and all associated data (cascades to players, phases, orders, messages).
 func (r *GameRepo) Delete(ctx context.Context, gameID string) error {
 	_, err := r.db.ExecContext(ctx, `DELETE FROM games WHERE id = $1`, gameID)
@@ -349,6 +1021,114 @@ func (r *GameRepo) Delete(ctx context.Context, gameID string) error {
 	return nil
 }
 
+// FindIDByContentHash returns the ID of the game imported with the given
+// content hash, or "" if there is none.
+func (r *GameRepo) FindIDByContentHash(ctx context.Context, hash string) (string, error) {
+	var id string
+	err := r.db.QueryRowContext(ctx, `SELECT id FROM games WHERE content_hash = $1`, hash).Scan(&id)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("find game by content hash: %w", err)
+	}
+	return id, nil
+}
+
+// SetContentHash stores the hash of an imported game's content so the same
+// game is not imported twice.
+func (r *GameRepo) SetContentHash(ctx context.Context, gameID, hash string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE games SET content_hash = $1 WHERE id = $2`, hash, gameID)
+	if err != nil {
+		return fmt.Errorf("set content hash: %w", err)
+	}
+	return nil
+}
+
+// FindIDByExternalID returns the ID of the game imported under the given
+// external ID, or "" if there is none.
+func (r *GameRepo) FindIDByExternalID(ctx context.Context, externalID string) (string, error) {
+	var id string
+	err := r.db.QueryRowContext(ctx, `SELECT id FROM games WHERE external_id = $1`, externalID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("find game by external id: %w", err)
+	}
+	return id, nil
+}
+
+// SetExternalID records the identifier an imported game has in its source,
+// such as a selfplay file's game number, so it can be found again on
+// re-import.
+func (r *GameRepo) SetExternalID(ctx context.Context, gameID, externalID string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE games SET external_id = $1 WHERE id = $2`, externalID, gameID)
+	if err != nil {
+		return fmt.Errorf("set external id: %w", err)
+	}
+	return nil
+}
+
+// ListIDsByNamePrefix returns the IDs of all games whose name starts with
+// prefix, oldest first. Used by admin tools to find imported games.
+func (r *GameRepo) ListIDsByNamePrefix(ctx context.Context, prefix string) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id FROM games WHERE starts_with(name, $1) ORDER BY created_at, id`, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("list games by name prefix: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan game id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// SoftDelete marks a game deleted, hiding it from lists while keeping its data
+// until PurgeDeleted removes it. The previous status is kept for Restore.
+func (r *GameRepo) SoftDelete(ctx context.Context, gameID string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE games SET status_before_delete = status, status = 'deleted', deleted_at = now()
+		 WHERE id = $1 AND status <> 'deleted'`, gameID)
+	if err != nil {
+		return fmt.Errorf("soft delete game: %w", err)
+	}
+	return nil
+}
+
+// Restore undoes SoftDelete, returning the game to its previous status.
+func (r *GameRepo) Restore(ctx context.Context, gameID string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE games SET status = status_before_delete, status_before_delete = NULL, deleted_at = NULL
+		 WHERE id = $1 AND status = 'deleted'`, gameID)
+	if err != nil {
+		return fmt.Errorf("restore game: %w", err)
+	}
+	return nil
+}
+
+// PurgeDeleted permanently removes games soft-deleted before the cutoff and
+// returns how many were removed.
+func (r *GameRepo) PurgeDeleted(ctx context.Context, before time.Time) (int, error) {
+	res, err := r.db.ExecContext(ctx,
+		`DELETE FROM games WHERE status = 'deleted' AND deleted_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("purge deleted games: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("purge deleted games: %w", err)
+	}
+	return int(n), nil
+}
+
 // SetFinished marks a game as finished.
 func (r *GameRepo) SetFinished(ctx context.Context, gameID, winner string) error {
 	_, err := r.db.ExecContext(ctx,
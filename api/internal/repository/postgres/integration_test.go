@@ -6,10 +6,13 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/internal/repository"
 	"github.com/freeeve/polite-betrayal/api/internal/testutil"
 )
 
@@ -264,6 +267,56 @@ func TestGameJoinIdempotent(t *testing.T) {
 	}
 }
 
+func TestGameClaimSeatConcurrent(t *testing.T) {
+	setup(t)
+	userRepo := NewUserRepo(testDB)
+	gameRepo := NewGameRepo(testDB)
+	ctx := context.Background()
+
+	creator := createTestUser(t, userRepo, "seat-creator")
+	g, _ := gameRepo.Create(ctx, "Seat Race", creator.ID, "24 hours", "12 hours", "12 hours", "", "", "")
+	const seats = 3
+	if err := gameRepo.ClaimSeat(ctx, g.ID, creator.ID, seats); err != nil {
+		t.Fatalf("creator claim: %v", err)
+	}
+
+	var joiners []*model.User
+	for i := 0; i < 6; i++ {
+		joiners = append(joiners, createTestUser(t, userRepo, "seat"+string(rune('a'+i))))
+	}
+	errs := make(chan error, len(joiners))
+	var wg sync.WaitGroup
+	for _, u := range joiners {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- gameRepo.ClaimSeat(ctx, g.ID, u.ID, seats)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	claimed := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			claimed++
+		case !errors.Is(err, repository.ErrSeatTaken):
+			t.Errorf("expected ErrSeatTaken, got %v", err)
+		}
+	}
+	if claimed != seats-1 {
+		t.Fatalf("expected %d claims to win, got %d", seats-1, claimed)
+	}
+	if count, _ := gameRepo.PlayerCount(ctx, g.ID); count != seats {
+		t.Fatalf("expected %d players, got %d", seats, count)
+	}
+
+	if err := gameRepo.ClaimSeat(ctx, g.ID, creator.ID, seats); !errors.Is(err, repository.ErrAlreadySeated) {
+		t.Fatalf("expected ErrAlreadySeated for a repeat claim, got %v", err)
+	}
+}
+
 func TestGamePlayerCount(t *testing.T) {
 	setup(t)
 	userRepo := NewUserRepo(testDB)
@@ -554,7 +607,7 @@ func TestMessageCreatePublic(t *testing.T) {
 	g, _ := gameRepo.Create(context.Background(), "Msg Test", sender.ID, "24 hours", "12 hours", "12 hours")
 	gameRepo.JoinGame(context.Background(), g.ID, sender.ID)
 
-	msg, err := msgRepo.Create(context.Background(), g.ID, sender.ID, "", "Hello everyone!", "")
+	msg, err := msgRepo.Create(context.Background(), g.ID, sender.ID, "", "Hello everyone!", "", nil)
 	if err != nil {
 		t.Fatalf("create public message: %v", err)
 	}
@@ -581,7 +634,7 @@ func TestMessageCreatePrivate(t *testing.T) {
 	gameRepo.JoinGame(context.Background(), g.ID, sender.ID)
 	gameRepo.JoinGame(context.Background(), g.ID, recipient.ID)
 
-	msg, err := msgRepo.Create(context.Background(), g.ID, sender.ID, recipient.ID, "Secret deal", "")
+	msg, err := msgRepo.Create(context.Background(), g.ID, sender.ID, recipient.ID, "Secret deal", "", nil)
 	if err != nil {
 		t.Fatalf("create private message: %v", err)
 	}
@@ -605,11 +658,11 @@ func TestMessageListByGameVisibility(t *testing.T) {
 	gameRepo.JoinGame(context.Background(), g.ID, charlie.ID)
 
 	// Public message
-	msgRepo.Create(context.Background(), g.ID, alice.ID, "", "Public hello", "")
+	msgRepo.Create(context.Background(), g.ID, alice.ID, "", "Public hello", "", nil)
 	// Private: Alice -> Bob
-	msgRepo.Create(context.Background(), g.ID, alice.ID, bob.ID, "Secret to Bob", "")
+	msgRepo.Create(context.Background(), g.ID, alice.ID, bob.ID, "Secret to Bob", "", nil)
 	// Private: Bob -> Charlie
-	msgRepo.Create(context.Background(), g.ID, bob.ID, charlie.ID, "Secret to Charlie", "")
+	msgRepo.Create(context.Background(), g.ID, bob.ID, charlie.ID, "Secret to Charlie", "", nil)
 
 	// Alice sees: public + her private to Bob (as sender) = 2
 	aliceMsgs, err := msgRepo.ListByGame(context.Background(), g.ID, alice.ID)
@@ -632,3 +685,13 @@ func TestMessageListByGameVisibility(t *testing.T) {
 		t.Fatalf("charlie expected 2 messages, got %d", len(charlieMsgs))
 	}
 }
+
+func TestQueryTimeoutMapsToErrTimeout(t *testing.T) {
+	setup(t)
+	tdb := &timedDB{db: testDB, timeout: 50 * time.Millisecond}
+
+	_, err := tdb.ExecContext(context.Background(), `SELECT pg_sleep(1)`)
+	if !errors.Is(err, repository.ErrTimeout) {
+		t.Fatalf("expected ErrTimeout, got %v", err)
+	}
+}
@@ -0,0 +1,196 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+)
+
+// JobRepo handles background job queue database operations.
+type JobRepo struct {
+	db *timedDB
+}
+
+// NewJobRepo creates a JobRepo.
+func NewJobRepo(db *sql.DB) *JobRepo {
+	return &JobRepo{db: &timedDB{db: db}}
+}
+
+// SetQueryTimeout bounds how long each JobRepo operation may run. Zero
+// (the default) leaves only the caller's context deadline.
+func (r *JobRepo) SetQueryTimeout(d time.Duration) {
+	r.db.timeout = d
+}
+
+const jobColumns = `id, kind, payload, status, attempts, max_attempts, last_error, run_at, created_at, updated_at`
+
+func scanJob(scan func(dest ...any) error) (*model.Job, error) {
+	var j model.Job
+	var payload []byte
+	if err := scan(&j.ID, &j.Kind, &payload, &j.Status, &j.Attempts, &j.MaxAttempts,
+		&j.LastError, &j.RunAt, &j.CreatedAt, &j.UpdatedAt); err != nil {
+		return nil, err
+	}
+	j.Payload = payload
+	return &j, nil
+}
+
+// Enqueue adds a pending job that becomes runnable at runAt.
+func (r *JobRepo) Enqueue(ctx context.Context, kind string, payload json.RawMessage, maxAttempts int, runAt time.Time) (*model.Job, error) {
+	j, err := scanJob(r.db.QueryRowContext(ctx,
+		`INSERT INTO jobs (kind, payload, max_attempts, run_at) VALUES ($1, $2, $3, $4)
+		 RETURNING `+jobColumns,
+		kind, []byte(payload), maxAttempts, runAt,
+	).Scan)
+	if err != nil {
+		return nil, fmt.Errorf("enqueue job: %w", err)
+	}
+	return j, nil
+}
+
+// Claim marks the oldest runnable job of one of the given kinds as running
+// and counts the attempt. It returns nil if there is none. Jobs claimed by
+// another transaction are skipped rather than waited for.
+func (r *JobRepo) Claim(ctx context.Context, kinds []string) (*model.Job, error) {
+	j, err := scanJob(r.db.QueryRowContext(ctx,
+		`UPDATE jobs SET status = 'running', attempts = attempts + 1, locked_at = now(), updated_at = now()
+		 WHERE id = (
+		     SELECT id FROM jobs
+		     WHERE status = 'pending' AND run_at <= now() AND kind = ANY($1)
+		     ORDER BY run_at, id
+		     LIMIT 1
+		     FOR UPDATE SKIP LOCKED
+		 )
+		 RETURNING `+jobColumns,
+		pq.Array(kinds),
+	).Scan)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("claim job: %w", err)
+	}
+	return j, nil
+}
+
+// Complete marks a running job as done.
+func (r *JobRepo) Complete(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE jobs SET status = 'done', locked_at = NULL, updated_at = now() WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("complete job: %w", err)
+	}
+	return nil
+}
+
+// Reschedule returns a failed job to the queue to run again at runAt.
+func (r *JobRepo) Reschedule(ctx context.Context, id, lastError string, runAt time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE jobs SET status = 'pending', last_error = $2, run_at = $3, locked_at = NULL, updated_at = now()
+		 WHERE id = $1`, id, lastError, runAt)
+	if err != nil {
+		return fmt.Errorf("reschedule job: %w", err)
+	}
+	return nil
+}
+
+// Bury moves a failed job to the dead-letter state.
+func (r *JobRepo) Bury(ctx context.Context, id, lastError string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE jobs SET status = 'dead', last_error = $2, locked_at = NULL, updated_at = now()
+		 WHERE id = $1`, id, lastError)
+	if err != nil {
+		return fmt.Errorf("bury job: %w", err)
+	}
+	return nil
+}
+
+// RequeueStale returns running jobs claimed before lockedBefore to the
+// queue, for workers that died mid-job. Jobs out of attempts are buried.
+func (r *JobRepo) RequeueStale(ctx context.Context, lockedBefore time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE jobs SET
+		     status = CASE WHEN attempts >= max_attempts THEN 'dead' ELSE 'pending' END,
+		     last_error = 'worker lost while running', run_at = now(), locked_at = NULL, updated_at = now()
+		 WHERE status = 'running' AND locked_at < $1`, lockedBefore)
+	if err != nil {
+		return 0, fmt.Errorf("requeue stale jobs: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// Retry gives a dead job a fresh set of attempts. It reports false if there
+// is no such dead job.
+func (r *JobRepo) Retry(ctx context.Context, id string) (bool, error) {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE jobs SET status = 'pending', attempts = 0, run_at = now(), updated_at = now()
+		 WHERE id = $1 AND status = 'dead'`, id)
+	if err != nil {
+		return false, fmt.Errorf("retry job: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("retry job: %w", err)
+	}
+	return n > 0, nil
+}
+
+// DeleteDone removes jobs that finished before the given time.
+func (r *JobRepo) DeleteDone(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx,
+		`DELETE FROM jobs WHERE status = 'done' AND updated_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("delete done jobs: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// List returns up to limit jobs with the given status, or of any status if
+// it is empty, most recently updated first.
+func (r *JobRepo) List(ctx context.Context, status string, limit int) ([]model.Job, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+jobColumns+` FROM jobs
+		 WHERE $1 = '' OR status = $1
+		 ORDER BY updated_at DESC LIMIT $2`, status, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []model.Job
+	for rows.Next() {
+		j, err := scanJob(rows.Scan)
+		if err != nil {
+			return nil, fmt.Errorf("scan job: %w", err)
+		}
+		jobs = append(jobs, *j)
+	}
+	return jobs, rows.Err()
+}
+
+// CountByStatus returns the number of jobs in each status.
+func (r *JobRepo) CountByStatus(ctx context.Context) (map[string]int, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT status, count(*) FROM jobs GROUP BY status`)
+	if err != nil {
+		return nil, fmt.Errorf("count jobs: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var status string
+		var n int
+		if err := rows.Scan(&status, &n); err != nil {
+			return nil, fmt.Errorf("scan job count: %w", err)
+		}
+		counts[status] = n
+	}
+	return counts, rows.Err()
+}
@@ -3,48 +3,184 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"time"
+
+	"github.com/lib/pq"
 
 	"github.com/freeeve/polite-betrayal/api/internal/model"
 )
 
+// visibleTo is the condition for a messages row being visible to the user
+// bound to $2.
+const visibleTo = `(sender_id = $2 OR recipient_id = $2
+	OR (recipient_id IS NULL AND channel_id IS NULL)
+	OR channel_id IN (SELECT channel_id FROM press_channel_members WHERE user_id = $2))`
+
 // MessageRepo handles message database operations.
 type MessageRepo struct {
-	db *sql.DB
+	db    *timedDB
+	press *pressCipher // nil stores press in plaintext
 }
 
 // NewMessageRepo creates a MessageRepo.
 func NewMessageRepo(db *sql.DB) *MessageRepo {
-	return &MessageRepo{db: db}
+	return &MessageRepo{db: &timedDB{db: db}}
 }
 
-// Create inserts a new message. RecipientID may be empty for public broadcasts.
-func (r *MessageRepo) Create(ctx context.Context, gameID, senderID, recipientID, content, phaseID string) (*model.Message, error) {
-	var m model.Message
-	var recip, phase sql.NullString
+// SetQueryTimeout bounds how long each MessageRepo operation may run. Zero
+// (the default) leaves only the caller's context deadline.
+func (r *MessageRepo) SetQueryTimeout(d time.Duration) {
+	r.db.timeout = d
+}
+
+// SetEncryptionKey turns on encryption at rest of the content and intent of
+// private press (messages with a recipient or channel) under per-game data
+// keys wrapped by key, which must be 32 bytes. Messages are decrypted transparently when read; public
+// press and messages stored before encryption was enabled stay plaintext.
+func (r *MessageRepo) SetEncryptionKey(key []byte) error {
+	press, err := newPressCipher(key)
+	if err != nil {
+		return err
+	}
+	r.press = press
+	return nil
+}
+
+// Create inserts a new message. RecipientID may be empty for public broadcasts
+// and intent may be nil for free-text messages.
+func (r *MessageRepo) Create(ctx context.Context, gameID, senderID, recipientID, content, phaseID string, intent *model.MessageIntent) (*model.Message, error) {
+	return r.create(ctx, gameID, senderID, recipientID, "", content, phaseID, intent)
+}
+
+// CreateInChannel inserts a message to a press channel, visible to its members.
+func (r *MessageRepo) CreateInChannel(ctx context.Context, gameID, senderID, channelID, content, phaseID string, intent *model.MessageIntent) (*model.Message, error) {
+	return r.create(ctx, gameID, senderID, "", channelID, content, phaseID, intent)
+}
+
+func (r *MessageRepo) create(ctx context.Context, gameID, senderID, recipientID, channelID, content, phaseID string, intent *model.MessageIntent) (*model.Message, error) {
+	var intentJSON []byte
+	if intent != nil {
+		var err error
+		if intentJSON, err = json.Marshal(intent); err != nil {
+			return nil, fmt.Errorf("marshal message intent: %w", err)
+		}
+	}
+
+	stored, encrypted := content, false
+	if r.press != nil && (recipientID != "" || channelID != "") {
+		var err error
+		if stored, err = r.press.encrypt(ctx, r.db, gameID, content); err != nil {
+			return nil, err
+		}
+		if intentJSON != nil {
+			if intentJSON, err = r.press.encryptIntent(ctx, r.db, gameID, intentJSON); err != nil {
+				return nil, err
+			}
+		}
+		encrypted = true
+	}
+
+	m := model.Message{Content: content}
+	var recip, channel, phase sql.NullString
 	err := r.db.QueryRowContext(ctx,
-		`INSERT INTO messages (game_id, sender_id, recipient_id, content, phase_id)
-		 VALUES ($1, $2, $3, $4, $5)
-		 RETURNING id, game_id, sender_id, recipient_id, content, phase_id, created_at`,
-		gameID, senderID, nullStr(recipientID), content, nullStr(phaseID),
-	).Scan(&m.ID, &m.GameID, &m.SenderID, &recip, &m.Content, &phase, &m.CreatedAt)
+		`INSERT INTO messages (game_id, sender_id, recipient_id, channel_id, content, phase_id, intent, encrypted)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		 RETURNING id, game_id, sender_id, recipient_id, channel_id, phase_id, created_at`,
+		gameID, senderID, nullStr(recipientID), nullStr(channelID), stored, nullStr(phaseID), intentJSON, encrypted,
+	).Scan(&m.ID, &m.GameID, &m.SenderID, &recip, &channel, &phase, &m.CreatedAt)
 	if err != nil {
 		return nil, fmt.Errorf("create message: %w", err)
 	}
 	m.RecipientID = recip.String
+	m.ChannelID = channel.String
 	m.PhaseID = phase.String
+	m.Intent = intent
 	return &m, nil
 }
 
 // ListByGame returns messages visible to a user in a game.
-// A user can see public messages (no recipient) and private messages sent to/from them.
+// A user can see public messages (no recipient or channel), private messages
+// sent to/from them and messages in channels they belong to.
 func (r *MessageRepo) ListByGame(ctx context.Context, gameID, userID string) ([]model.Message, error) {
+	return r.list(ctx,
+		`SELECT id, game_id, sender_id, COALESCE(recipient_id::text, ''), COALESCE(channel_id::text, ''), content, COALESCE(phase_id::text, ''), intent, created_at, encrypted
+		 FROM messages
+		 WHERE game_id = $1 AND `+visibleTo+`
+		 ORDER BY created_at`, gameID, userID)
+}
+
+// ListAllByGame returns every message in a game, private ones included, in
+// the order they were sent. It is meant for exports of finished games.
+func (r *MessageRepo) ListAllByGame(ctx context.Context, gameID string) ([]model.Message, error) {
+	return r.list(ctx,
+		`SELECT id, game_id, sender_id, COALESCE(recipient_id::text, ''), COALESCE(channel_id::text, ''), content, COALESCE(phase_id::text, ''), intent, created_at, encrypted
+		 FROM messages
+		 WHERE game_id = $1
+		 ORDER BY created_at`, gameID)
+}
+
+// MarkRead records that a user has read every message in a game so far.
+func (r *MessageRepo) MarkRead(ctx context.Context, gameID, userID string) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO message_reads (game_id, user_id, last_read_at) VALUES ($1, $2, now())
+		 ON CONFLICT (game_id, user_id) DO UPDATE SET last_read_at = EXCLUDED.last_read_at`,
+		gameID, userID)
+	if err != nil {
+		return fmt.Errorf("mark messages read: %w", err)
+	}
+	return nil
+}
+
+// CountUnread returns how many messages visible to a user in a game were
+// sent by others since the user last read the game's messages.
+func (r *MessageRepo) CountUnread(ctx context.Context, gameID, userID string) (int, error) {
+	var n int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*)
+		 FROM messages
+		 LEFT JOIN message_reads mr ON mr.game_id = messages.game_id AND mr.user_id = $2
+		 WHERE messages.game_id = $1 AND sender_id <> $2 AND `+visibleTo+`
+		   AND (mr.last_read_at IS NULL OR created_at > mr.last_read_at)`,
+		gameID, userID).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("count unread messages: %w", err)
+	}
+	return n, nil
+}
+
+// CountUnreadByGame counts, for each of gameIDs, the messages visible to
+// userID that arrived since they last read the game's press. Games without
+// unread messages are omitted.
+func (r *MessageRepo) CountUnreadByGame(ctx context.Context, gameIDs []string, userID string) (map[string]int, error) {
 	rows, err := r.db.QueryContext(ctx,
-		`SELECT id, game_id, sender_id, COALESCE(recipient_id::text, ''), content, COALESCE(phase_id::text, ''), created_at
+		`SELECT messages.game_id, COUNT(*)
 		 FROM messages
-		 WHERE game_id = $1 AND (recipient_id IS NULL OR sender_id = $2 OR recipient_id = $2)
-		 ORDER BY created_at`, gameID, userID,
-	)
+		 LEFT JOIN message_reads mr ON mr.game_id = messages.game_id AND mr.user_id = $2
+		 WHERE messages.game_id = ANY($1::uuid[]) AND sender_id <> $2 AND `+visibleTo+`
+		   AND (mr.last_read_at IS NULL OR created_at > mr.last_read_at)
+		 GROUP BY messages.game_id`,
+		pq.Array(gameIDs), userID)
+	if err != nil {
+		return nil, fmt.Errorf("count unread messages: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int, len(gameIDs))
+	for rows.Next() {
+		var gameID string
+		var n int
+		if err := rows.Scan(&gameID, &n); err != nil {
+			return nil, fmt.Errorf("scan unread count: %w", err)
+		}
+		counts[gameID] = n
+	}
+	return counts, rows.Err()
+}
+
+func (r *MessageRepo) list(ctx context.Context, query string, args ...any) ([]model.Message, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("list messages: %w", err)
 	}
@@ -53,10 +189,72 @@ func (r *MessageRepo) ListByGame(ctx context.Context, gameID, userID string) ([]
 	var messages []model.Message
 	for rows.Next() {
 		var m model.Message
-		if err := rows.Scan(&m.ID, &m.GameID, &m.SenderID, &m.RecipientID, &m.Content, &m.PhaseID, &m.CreatedAt); err != nil {
+		var intent []byte
+		var encrypted bool
+		if err := rows.Scan(&m.ID, &m.GameID, &m.SenderID, &m.RecipientID, &m.ChannelID, &m.Content, &m.PhaseID, &intent, &m.CreatedAt, &encrypted); err != nil {
 			return nil, fmt.Errorf("scan message: %w", err)
 		}
+		if err := r.reveal(ctx, &m, intent, encrypted); err != nil {
+			return nil, err
+		}
 		messages = append(messages, m)
 	}
 	return messages, rows.Err()
 }
+
+// FindByID returns a message by ID, or nil if it does not exist.
+func (r *MessageRepo) FindByID(ctx context.Context, id string) (*model.Message, error) {
+	var m model.Message
+	var intent []byte
+	var encrypted bool
+	err := r.db.QueryRowContext(ctx,
+		`SELECT id, game_id, sender_id, COALESCE(recipient_id::text, ''), COALESCE(channel_id::text, ''), content, COALESCE(phase_id::text, ''), intent, created_at, encrypted
+		 FROM messages WHERE id = $1`, id,
+	).Scan(&m.ID, &m.GameID, &m.SenderID, &m.RecipientID, &m.ChannelID, &m.Content, &m.PhaseID, &intent, &m.CreatedAt, &encrypted)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("find message: %w", err)
+	}
+	if err := r.reveal(ctx, &m, intent, encrypted); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// reveal sets the message's intent from its stored column and, if the
+// message is encrypted, replaces its content and intent with their
+// plaintext. Callers only pass messages their query already found visible.
+func (r *MessageRepo) reveal(ctx context.Context, m *model.Message, intent []byte, encrypted bool) error {
+	if encrypted {
+		if r.press == nil {
+			return fmt.Errorf("message %s: %w", m.ID, ErrPressKeyMissing)
+		}
+		content, err := r.press.decrypt(ctx, r.db, m.GameID, m.Content)
+		if err != nil {
+			return fmt.Errorf("message %s: %w", m.ID, err)
+		}
+		m.Content = content
+		if intent != nil {
+			if intent, err = r.press.decryptIntent(ctx, r.db, m.GameID, intent); err != nil {
+				return fmt.Errorf("message %s: %w", m.ID, err)
+			}
+		}
+	}
+	var err error
+	m.Intent, err = decodeIntent(intent)
+	return err
+}
+
+// decodeIntent unmarshals a nullable intent column.
+func decodeIntent(raw []byte) (*model.MessageIntent, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	var intent model.MessageIntent
+	if err := json.Unmarshal(raw, &intent); err != nil {
+		return nil, fmt.Errorf("unmarshal message intent: %w", err)
+	}
+	return &intent, nil
+}
@@ -0,0 +1,275 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+)
+
+// NotificationRepo handles notification channel and delivery database
+// operations.
+type NotificationRepo struct {
+	db *timedDB
+}
+
+// NewNotificationRepo creates a NotificationRepo.
+func NewNotificationRepo(db *sql.DB) *NotificationRepo {
+	return &NotificationRepo{db: &timedDB{db: db}}
+}
+
+// SetQueryTimeout bounds how long each NotificationRepo operation may run.
+// Zero (the default) leaves only the caller's context deadline.
+func (r *NotificationRepo) SetQueryTimeout(d time.Duration) {
+	r.db.timeout = d
+}
+
+const notificationChannelColumns = `id, user_id, kind, target, events, enabled, confirmed, created_at`
+
+func scanNotificationChannel(scan func(dest ...any) error) (*model.NotificationChannel, error) {
+	var c model.NotificationChannel
+	if err := scan(&c.ID, &c.UserID, &c.Kind, &c.Target, pq.Array(&c.Events), &c.Enabled, &c.Confirmed, &c.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *NotificationRepo) queryChannels(ctx context.Context, query string, args ...any) ([]model.NotificationChannel, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list notification channels: %w", err)
+	}
+	defer rows.Close()
+
+	var channels []model.NotificationChannel
+	for rows.Next() {
+		c, err := scanNotificationChannel(rows.Scan)
+		if err != nil {
+			return nil, fmt.Errorf("scan notification channel: %w", err)
+		}
+		channels = append(channels, *c)
+	}
+	return channels, rows.Err()
+}
+
+// CreateChannel stores a new enabled channel for a user. With a confirmation
+// token hash the channel is unconfirmed until ConfirmChannel is given it.
+func (r *NotificationRepo) CreateChannel(ctx context.Context, userID, kind, target string, events []string, confirmTokenHash string) (*model.NotificationChannel, error) {
+	c, err := scanNotificationChannel(r.db.QueryRowContext(ctx,
+		`INSERT INTO notification_channels (user_id, kind, target, events, confirmed, confirm_token_hash, confirm_sent_at)
+		 VALUES ($1, $2, $3, $4, $5 = '', NULLIF($5, ''), CASE WHEN $5 <> '' THEN now() END)
+		 RETURNING `+notificationChannelColumns,
+		userID, kind, target, pq.Array(events), confirmTokenHash,
+	).Scan)
+	if err != nil {
+		return nil, fmt.Errorf("create notification channel: %w", err)
+	}
+	return c, nil
+}
+
+// ListChannels returns a user's channels, oldest first.
+func (r *NotificationRepo) ListChannels(ctx context.Context, userID string) ([]model.NotificationChannel, error) {
+	return r.queryChannels(ctx,
+		`SELECT `+notificationChannelColumns+` FROM notification_channels WHERE user_id = $1 ORDER BY created_at`, userID)
+}
+
+// FindChannel returns a channel by ID, or nil if there is none.
+func (r *NotificationRepo) FindChannel(ctx context.Context, id string) (*model.NotificationChannel, error) {
+	c, err := scanNotificationChannel(r.db.QueryRowContext(ctx,
+		`SELECT `+notificationChannelColumns+` FROM notification_channels WHERE id = $1`, id,
+	).Scan)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("find notification channel: %w", err)
+	}
+	return c, nil
+}
+
+// UpdateChannel sets the events and enabled flag of one of a user's
+// channels. It returns nil if the user has no such channel.
+func (r *NotificationRepo) UpdateChannel(ctx context.Context, id, userID string, events []string, enabled bool) (*model.NotificationChannel, error) {
+	c, err := scanNotificationChannel(r.db.QueryRowContext(ctx,
+		`UPDATE notification_channels SET events = $3, enabled = $4 WHERE id = $1 AND user_id = $2
+		 RETURNING `+notificationChannelColumns,
+		id, userID, pq.Array(events), enabled,
+	).Scan)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("update notification channel: %w", err)
+	}
+	return c, nil
+}
+
+// DeleteChannel removes one of a user's channels and its notifications. It
+// reports false if the user has no such channel.
+func (r *NotificationRepo) DeleteChannel(ctx context.Context, id, userID string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM notification_channels WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return false, fmt.Errorf("delete notification channel: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("delete notification channel: %w", err)
+	}
+	return n > 0, nil
+}
+
+// SetConfirmToken replaces the confirmation token of one of a user's
+// unconfirmed channels. It reports false if the user has no such channel.
+func (r *NotificationRepo) SetConfirmToken(ctx context.Context, id, userID, tokenHash string) (bool, error) {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE notification_channels SET confirm_token_hash = $3, confirm_sent_at = now()
+		 WHERE id = $1 AND user_id = $2 AND NOT confirmed`,
+		id, userID, tokenHash)
+	if err != nil {
+		return false, fmt.Errorf("set notification channel confirmation: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("set notification channel confirmation: %w", err)
+	}
+	return n > 0, nil
+}
+
+// ConfirmChannel confirms the channel whose token was sent after sentAfter.
+// It returns nil if no channel is waiting on the token.
+func (r *NotificationRepo) ConfirmChannel(ctx context.Context, tokenHash string, sentAfter time.Time) (*model.NotificationChannel, error) {
+	c, err := scanNotificationChannel(r.db.QueryRowContext(ctx,
+		`UPDATE notification_channels SET confirmed = true, confirm_token_hash = NULL, confirm_sent_at = NULL
+		 WHERE confirm_token_hash = $1 AND confirm_sent_at > $2
+		 RETURNING `+notificationChannelColumns,
+		tokenHash, sentAfter,
+	).Scan)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("confirm notification channel: %w", err)
+	}
+	return c, nil
+}
+
+// SubscribedChannels returns the enabled, confirmed channels of the given
+// users that want an event.
+func (r *NotificationRepo) SubscribedChannels(ctx context.Context, userIDs []string, event string) ([]model.NotificationChannel, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	return r.queryChannels(ctx,
+		`SELECT `+notificationChannelColumns+` FROM notification_channels
+		 WHERE user_id = ANY($1::uuid[]) AND enabled AND confirmed AND $2 = ANY(events)
+		 ORDER BY user_id, created_at`,
+		pq.Array(userIDs), event)
+}
+
+// ClaimReminder records that a phase's reminder for an event is being sent.
+// It reports false if it already was, by this server or another.
+func (r *NotificationRepo) ClaimReminder(ctx context.Context, phaseID, event string) (bool, error) {
+	res, err := r.db.ExecContext(ctx,
+		`INSERT INTO notification_reminders (phase_id, event) VALUES ($1, $2) ON CONFLICT DO NOTHING`, phaseID, event)
+	if err != nil {
+		return false, fmt.Errorf("claim reminder: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("claim reminder: %w", err)
+	}
+	return n > 0, nil
+}
+
+const notificationColumns = `id, user_id, channel_id, event, game_id, phase_id, message, status, attempts, last_error, created_at, sent_at`
+
+func scanNotification(scan func(dest ...any) error) (*model.Notification, error) {
+	var n model.Notification
+	var phaseID, lastError sql.NullString
+	if err := scan(&n.ID, &n.UserID, &n.ChannelID, &n.Event, &n.GameID, &phaseID, &n.Message, &n.Status, &n.Attempts, &lastError, &n.CreatedAt, &n.SentAt); err != nil {
+		return nil, err
+	}
+	n.PhaseID, n.LastError = phaseID.String, lastError.String
+	return &n, nil
+}
+
+func (r *NotificationRepo) queryNotifications(ctx context.Context, query string, args ...any) ([]model.Notification, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list notifications: %w", err)
+	}
+	defer rows.Close()
+
+	var notifications []model.Notification
+	for rows.Next() {
+		n, err := scanNotification(rows.Scan)
+		if err != nil {
+			return nil, fmt.Errorf("scan notification: %w", err)
+		}
+		notifications = append(notifications, *n)
+	}
+	return notifications, rows.Err()
+}
+
+// CreateNotifications stores pending notifications and returns them with
+// their IDs, in order.
+func (r *NotificationRepo) CreateNotifications(ctx context.Context, notifications []model.Notification) ([]model.Notification, error) {
+	if len(notifications) == 0 {
+		return nil, nil
+	}
+	users := make([]string, len(notifications))
+	channels := make([]string, len(notifications))
+	events := make([]string, len(notifications))
+	games := make([]string, len(notifications))
+	phases := make([]sql.NullString, len(notifications))
+	messages := make([]string, len(notifications))
+	for i, n := range notifications {
+		users[i], channels[i], events[i], games[i], messages[i] = n.UserID, n.ChannelID, n.Event, n.GameID, n.Message
+		phases[i] = nullStr(n.PhaseID)
+	}
+	return r.queryNotifications(ctx,
+		`INSERT INTO notifications (user_id, channel_id, event, game_id, phase_id, message)
+		 SELECT * FROM unnest($1::uuid[], $2::uuid[], $3::text[], $4::uuid[], $5::uuid[], $6::text[])
+		 RETURNING `+notificationColumns,
+		pq.Array(users), pq.Array(channels), pq.Array(events), pq.Array(games), pq.Array(phases), pq.Array(messages))
+}
+
+// FindNotification returns a notification by ID, or nil if there is none.
+func (r *NotificationRepo) FindNotification(ctx context.Context, id string) (*model.Notification, error) {
+	n, err := scanNotification(r.db.QueryRowContext(ctx,
+		`SELECT `+notificationColumns+` FROM notifications WHERE id = $1`, id,
+	).Scan)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("find notification: %w", err)
+	}
+	return n, nil
+}
+
+// RecordAttempt counts a delivery attempt and sets the notification's
+// status and last error.
+func (r *NotificationRepo) RecordAttempt(ctx context.Context, id, status, lastError string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE notifications SET attempts = attempts + 1, status = $2, last_error = $3,
+		        sent_at = CASE WHEN $2 = 'sent' THEN now() END
+		 WHERE id = $1`,
+		id, status, nullStr(lastError))
+	if err != nil {
+		return fmt.Errorf("record notification attempt: %w", err)
+	}
+	return nil
+}
+
+// ListNotifications returns a user's most recent notifications, newest
+// first.
+func (r *NotificationRepo) ListNotifications(ctx context.Context, userID string, limit int) ([]model.Notification, error) {
+	return r.queryNotifications(ctx,
+		`SELECT `+notificationColumns+` FROM notifications WHERE user_id = $1 ORDER BY created_at DESC LIMIT $2`,
+		userID, limit)
+}
@@ -7,17 +7,31 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/lib/pq"
+
 	"github.com/freeeve/polite-betrayal/api/internal/model"
 )
 
 // PhaseRepo handles phase and order database operations.
 type PhaseRepo struct {
-	db *sql.DB
+	db *timedDB
 }
 
 // NewPhaseRepo creates a PhaseRepo.
 func NewPhaseRepo(db *sql.DB) *PhaseRepo {
-	return &PhaseRepo{db: db}
+	return &PhaseRepo{db: &timedDB{db: db}}
+}
+
+// SetQueryTimeout bounds how long each PhaseRepo operation may run. Zero
+// (the default) leaves only the caller's context deadline.
+func (r *PhaseRepo) SetQueryTimeout(d time.Duration) {
+	r.db.timeout = d
+}
+
+// WithTx returns a PhaseRepo whose operations run inside tx, so they commit
+// or roll back together with the caller's other work.
+func (r *PhaseRepo) WithTx(tx *sql.Tx) *PhaseRepo {
+	return &PhaseRepo{db: r.db.withTx(tx)}
 }
 
 // CreatePhase inserts a new phase.
@@ -35,6 +49,66 @@ func (r *PhaseRepo) CreatePhase(ctx context.Context, gameID string, year int, se
 	return &p, nil
 }
 
+// CreatePhases inserts a game's phases in one statement and returns their
+// IDs in the same order. A phase with a StateAfter is stored already
+// resolved; one without is left open until its Deadline. Importers use
+// this instead of a CreatePhase and ResolvePhase round trip per phase.
+func (r *PhaseRepo) CreatePhases(ctx context.Context, gameID string, phases []model.Phase) ([]string, error) {
+	if len(phases) == 0 {
+		return nil, nil
+	}
+	years := make([]int64, len(phases))
+	seasons := make([]string, len(phases))
+	types := make([]string, len(phases))
+	before := make([]string, len(phases))
+	after := make([]sql.NullString, len(phases))
+	deadlines := make([]string, len(phases))
+	for i, p := range phases {
+		years[i] = int64(p.Year)
+		seasons[i] = p.Season
+		types[i] = p.PhaseType
+		before[i] = string(p.StateBefore)
+		if p.StateAfter != nil {
+			after[i] = sql.NullString{String: string(p.StateAfter), Valid: true}
+		}
+		deadlines[i] = p.Deadline.Format(time.RFC3339Nano)
+	}
+
+	// The IDs are drawn in a CTE referenced twice, which Postgres
+	// materializes, so they can be returned in input order.
+	rows, err := r.db.QueryContext(ctx,
+		`WITH input AS (
+		   SELECT gen_random_uuid() AS id, t.*
+		   FROM unnest($2::int[], $3::text[], $4::text[], $5::jsonb[], $6::jsonb[], $7::timestamptz[])
+		        WITH ORDINALITY AS t(year, season, phase_type, state_before, state_after, deadline, ord)
+		 ), inserted AS (
+		   INSERT INTO phases (id, game_id, year, season, phase_type, state_before, state_after, deadline, resolved_at)
+		   SELECT id, $1::uuid, year, season, phase_type, state_before, state_after, deadline,
+		          CASE WHEN state_after IS NULL THEN NULL ELSE now() END
+		   FROM input
+		 )
+		 SELECT id FROM input ORDER BY ord`,
+		gameID, pq.Array(years), pq.Array(seasons), pq.Array(types), pq.Array(before), pq.Array(after), pq.Array(deadlines),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create phases: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make([]string, 0, len(phases))
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan phase id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("create phases: %w", err)
+	}
+	return ids, nil
+}
+
 // CurrentPhase returns the latest unresolved phase for a game.
 func (r *PhaseRepo) CurrentPhase(ctx context.Context, gameID string) (*model.Phase, error) {
 	var p model.Phase
@@ -56,6 +130,30 @@ func (r *PhaseRepo) CurrentPhase(ctx context.Context, gameID string) (*model.Pha
 	return &p, nil
 }
 
+// CurrentPhases returns the unresolved phase of each of gameIDs that has
+// one, keyed by game ID. States are not loaded.
+func (r *PhaseRepo) CurrentPhases(ctx context.Context, gameIDs []string) (map[string]model.Phase, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT DISTINCT ON (game_id) id, game_id, year, season, phase_type, deadline, created_at
+		 FROM phases WHERE game_id = ANY($1::uuid[]) AND resolved_at IS NULL
+		 ORDER BY game_id, created_at DESC`, pq.Array(gameIDs),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("current phases: %w", err)
+	}
+	defer rows.Close()
+
+	phases := make(map[string]model.Phase, len(gameIDs))
+	for rows.Next() {
+		var p model.Phase
+		if err := rows.Scan(&p.ID, &p.GameID, &p.Year, &p.Season, &p.PhaseType, &p.Deadline, &p.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan phase: %w", err)
+		}
+		phases[p.GameID] = p
+	}
+	return phases, rows.Err()
+}
+
 // ListPhases returns all phases for a game in chronological order.
 func (r *PhaseRepo) ListPhases(ctx context.Context, gameID string) ([]model.Phase, error) {
 	rows, err := r.db.QueryContext(ctx,
@@ -88,7 +186,8 @@ func (r *PhaseRepo) ListPhases(ctx context.Context, gameID string) ([]model.Phas
 // ResolvePhase marks a phase as resolved and stores the resulting state.
 func (r *PhaseRepo) ResolvePhase(ctx context.Context, phaseID string, stateAfter json.RawMessage) error {
 	_, err := r.db.ExecContext(ctx,
-		`UPDATE phases SET state_after = $1, resolved_at = now() WHERE id = $2`,
+		`WITH cleared AS (DELETE FROM phase_submissions WHERE phase_id = $2)
+		 UPDATE phases SET state_after = $1, resolved_at = now() WHERE id = $2`,
 		stateAfter, phaseID,
 	)
 	if err != nil {
@@ -97,36 +196,74 @@ func (r *PhaseRepo) ResolvePhase(ctx context.Context, phaseID string, stateAfter
 	return nil
 }
 
-// SaveOrders inserts a batch of orders for a phase.
+// UpdateDeadline moves an unresolved phase's deadline.
+func (r *PhaseRepo) UpdateDeadline(ctx context.Context, phaseID string, deadline time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE phases SET deadline = $1 WHERE id = $2 AND resolved_at IS NULL`,
+		deadline, phaseID,
+	)
+	if err != nil {
+		return fmt.Errorf("update phase deadline: %w", err)
+	}
+	return nil
+}
+
+// UpdateStateAfter replaces the stored state of an already resolved phase
+// without touching its resolution time.
+func (r *PhaseRepo) UpdateStateAfter(ctx context.Context, phaseID string, stateAfter json.RawMessage) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE phases SET state_after = $1 WHERE id = $2 AND resolved_at IS NOT NULL`,
+		stateAfter, phaseID,
+	)
+	if err != nil {
+		return fmt.Errorf("update state after: %w", err)
+	}
+	return nil
+}
+
+// SaveOrders inserts a batch of orders, which may span phases, with a
+// single COPY.
 func (r *PhaseRepo) SaveOrders(ctx context.Context, orders []model.Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("begin tx: %w", err)
 	}
 	defer tx.Rollback()
 
-	stmt, err := tx.PrepareContext(ctx,
-		`INSERT INTO orders (phase_id, power, unit_type, location, order_type, target, aux_loc, aux_target, aux_unit_type, result)
-		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`)
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("orders",
+		"phase_id", "power", "unit_type", "location", "order_type", "target", "aux_loc", "aux_target", "aux_unit_type", "result",
+		"submitted_order", "downgrade_reason"))
 	if err != nil {
-		return fmt.Errorf("prepare insert order: %w", err)
+		return fmt.Errorf("prepare copy orders: %w", err)
 	}
 	defer stmt.Close()
 
 	for _, o := range orders {
 		_, err := stmt.ExecContext(ctx, o.PhaseID, o.Power, o.UnitType, o.Location, o.OrderType,
-			nullStr(o.Target), nullStr(o.AuxLoc), nullStr(o.AuxTarget), nullStr(o.AuxUnitType), nullStr(o.Result))
+			nullStr(o.Target), nullStr(o.AuxLoc), nullStr(o.AuxTarget), nullStr(o.AuxUnitType), nullStr(o.Result),
+			nullStr(o.SubmittedOrder), nullStr(o.DowngradeReason))
 		if err != nil {
-			return fmt.Errorf("insert order: %w", err)
+			return fmt.Errorf("copy order: %w", err)
 		}
 	}
+	// An Exec with no arguments flushes the buffered rows.
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return fmt.Errorf("copy orders: %w", mapTimeout(err))
+	}
+	if err := stmt.Close(); err != nil {
+		return fmt.Errorf("copy orders: %w", err)
+	}
 	return tx.Commit()
 }
 
 // OrdersByPhase returns all orders for a phase.
 func (r *PhaseRepo) OrdersByPhase(ctx context.Context, phaseID string) ([]model.Order, error) {
 	rows, err := r.db.QueryContext(ctx,
-		`SELECT id, phase_id, power, unit_type, location, order_type, target, aux_loc, aux_target, aux_unit_type, result, created_at
+		`SELECT id, phase_id, power, unit_type, location, order_type, target, aux_loc, aux_target, aux_unit_type, result, created_at,
+		        submitted_order, downgrade_reason
 		 FROM orders WHERE phase_id = $1 ORDER BY power, location`, phaseID,
 	)
 	if err != nil {
@@ -137,9 +274,9 @@ func (r *PhaseRepo) OrdersByPhase(ctx context.Context, phaseID string) ([]model.
 	var orders []model.Order
 	for rows.Next() {
 		var o model.Order
-		var target, auxLoc, auxTarget, auxUnitType, result sql.NullString
+		var target, auxLoc, auxTarget, auxUnitType, result, submitted, reason sql.NullString
 		if err := rows.Scan(&o.ID, &o.PhaseID, &o.Power, &o.UnitType, &o.Location, &o.OrderType,
-			&target, &auxLoc, &auxTarget, &auxUnitType, &result, &o.CreatedAt); err != nil {
+			&target, &auxLoc, &auxTarget, &auxUnitType, &result, &o.CreatedAt, &submitted, &reason); err != nil {
 			return nil, fmt.Errorf("scan order: %w", err)
 		}
 		o.Target = target.String
@@ -147,11 +284,59 @@ func (r *PhaseRepo) OrdersByPhase(ctx context.Context, phaseID string) ([]model.
 		o.AuxTarget = auxTarget.String
 		o.AuxUnitType = auxUnitType.String
 		o.Result = result.String
+		o.SubmittedOrder = submitted.String
+		o.DowngradeReason = reason.String
 		orders = append(orders, o)
 	}
 	return orders, rows.Err()
 }
 
+// OpeningTallies aggregates the Spring 1901 orders of finished standard games
+// by power and order set. It returns the number of games counted and one
+// tally per distinct opening.
+func (r *PhaseRepo) OpeningTallies(ctx context.Context) (int, []model.OpeningTally, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`WITH openings AS (
+		     SELECT g.id AS game_id, g.winner, o.power,
+		            json_agg(json_build_object(
+		                'unit_type', o.unit_type, 'location', o.location, 'order_type', o.order_type,
+		                'target', COALESCE(o.target, ''), 'aux_loc', COALESCE(o.aux_loc, ''),
+		                'aux_target', COALESCE(o.aux_target, ''), 'aux_unit_type', COALESCE(o.aux_unit_type, ''))
+		                ORDER BY o.location)::text AS orders
+		     FROM games g
+		     JOIN phases p ON p.game_id = g.id
+		     JOIN orders o ON o.phase_id = p.id
+		     WHERE g.status = 'finished' AND g.scenario IN ('', 'standard')
+		       AND p.year = 1901 AND p.season = 'spring' AND p.phase_type = 'movement' AND p.resolved_at IS NOT NULL
+		     GROUP BY g.id, g.winner, o.power
+		 )
+		 SELECT power, orders, COUNT(*), COUNT(*) FILTER (WHERE winner = power),
+		        (SELECT COUNT(DISTINCT game_id) FROM openings)
+		 FROM openings GROUP BY power, orders`)
+	if err != nil {
+		return 0, nil, fmt.Errorf("opening tallies: %w", err)
+	}
+	defer rows.Close()
+
+	var games int
+	var tallies []model.OpeningTally
+	for rows.Next() {
+		var t model.OpeningTally
+		var orders []byte
+		if err := rows.Scan(&t.Power, &orders, &t.Games, &t.Wins, &games); err != nil {
+			return 0, nil, fmt.Errorf("scan opening tally: %w", err)
+		}
+		if err := json.Unmarshal(orders, &t.Orders); err != nil {
+			return 0, nil, fmt.Errorf("decode opening orders: %w", err)
+		}
+		for i := range t.Orders {
+			t.Orders[i].Power = t.Power
+		}
+		tallies = append(tallies, t)
+	}
+	return games, tallies, rows.Err()
+}
+
 // ListExpired returns the latest unresolved phase per game where the deadline has passed.
 // Uses DISTINCT ON to avoid returning orphaned old phases from previous race conditions.
 func (r *PhaseRepo) ListExpired(ctx context.Context) ([]model.Phase, error) {
@@ -177,9 +362,224 @@ func (r *PhaseRepo) ListExpired(ctx context.Context) ([]model.Phase, error) {
 	return phases, rows.Err()
 }
 
+// ListDueBefore returns the latest unresolved phase of each active game
+// whose deadline is still ahead but before the given time.
+func (r *PhaseRepo) ListDueBefore(ctx context.Context, before time.Time) ([]model.Phase, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id, game_id, year, season, phase_type, state_before, deadline, created_at FROM (
+			SELECT DISTINCT ON (p.game_id) p.id, p.game_id, p.year, p.season, p.phase_type, p.state_before, p.deadline, p.created_at
+			FROM phases p
+			JOIN games g ON g.id = p.game_id
+			WHERE p.resolved_at IS NULL AND g.status = 'active'
+			ORDER BY p.game_id, p.created_at DESC
+		 ) latest
+		 WHERE deadline > now() AND deadline <= $1`, before)
+	if err != nil {
+		return nil, fmt.Errorf("list due phases: %w", err)
+	}
+	defer rows.Close()
+
+	var phases []model.Phase
+	for rows.Next() {
+		var p model.Phase
+		if err := rows.Scan(&p.ID, &p.GameID, &p.Year, &p.Season, &p.PhaseType, &p.StateBefore, &p.Deadline, &p.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan due phase: %w", err)
+		}
+		phases = append(phases, p)
+	}
+	return phases, rows.Err()
+}
+
+// SaveEvaluations stores phase evaluations, replacing any earlier analysis of
+// the same phase and power.
+func (r *PhaseRepo) SaveEvaluations(ctx context.Context, evals []model.PhaseEvaluation) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx,
+		`INSERT INTO phase_evaluations (phase_id, power, heuristic, share, neural, win_prob, draw_prob)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)
+		 ON CONFLICT (phase_id, power) DO UPDATE
+		 SET heuristic = EXCLUDED.heuristic, share = EXCLUDED.share,
+		     neural = EXCLUDED.neural, win_prob = EXCLUDED.win_prob,
+		     draw_prob = EXCLUDED.draw_prob, created_at = now()`)
+	if err != nil {
+		return fmt.Errorf("prepare insert evaluation: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, e := range evals {
+		if _, err := stmt.ExecContext(ctx, e.PhaseID, e.Power, e.Heuristic, e.Share, e.Neural, e.WinProb, e.DrawProb); err != nil {
+			return fmt.Errorf("insert evaluation: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// EvaluationsByGame returns all phase evaluations for a game.
+func (r *PhaseRepo) EvaluationsByGame(ctx context.Context, gameID string) ([]model.PhaseEvaluation, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT e.phase_id, e.power, e.heuristic, e.share, e.neural, e.win_prob, e.draw_prob
+		 FROM phase_evaluations e
+		 JOIN phases p ON p.id = e.phase_id
+		 WHERE p.game_id = $1 ORDER BY e.phase_id, e.power`, gameID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("evaluations by game: %w", err)
+	}
+	defer rows.Close()
+
+	var evals []model.PhaseEvaluation
+	for rows.Next() {
+		var e model.PhaseEvaluation
+		var neural, winProb, drawProb sql.NullFloat64
+		if err := rows.Scan(&e.PhaseID, &e.Power, &e.Heuristic, &e.Share, &neural, &winProb, &drawProb); err != nil {
+			return nil, fmt.Errorf("scan evaluation: %w", err)
+		}
+		if neural.Valid {
+			e.Neural = &neural.Float64
+		}
+		if winProb.Valid {
+			e.WinProb = &winProb.Float64
+		}
+		if drawProb.Valid {
+			e.DrawProb = &drawProb.Float64
+		}
+		evals = append(evals, e)
+	}
+	return evals, rows.Err()
+}
+
+// SaveOrderReviews stores order reviews, replacing any earlier review of the
+// same phase and power.
+func (r *PhaseRepo) SaveOrderReviews(ctx context.Context, reviews []model.OrderReview) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx,
+		`INSERT INTO order_reviews (phase_id, power, orders, suggested, eval, suggested_eval, delta, blunder)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		 ON CONFLICT (phase_id, power) DO UPDATE
+		 SET orders = EXCLUDED.orders, suggested = EXCLUDED.suggested,
+		     eval = EXCLUDED.eval, suggested_eval = EXCLUDED.suggested_eval,
+		     delta = EXCLUDED.delta, blunder = EXCLUDED.blunder, created_at = now()`)
+	if err != nil {
+		return fmt.Errorf("prepare insert order review: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, rv := range reviews {
+		if _, err := stmt.ExecContext(ctx, rv.PhaseID, rv.Power, rv.Orders, rv.Suggested, rv.Eval, rv.SuggestedEval, rv.Delta, rv.Blunder); err != nil {
+			return fmt.Errorf("insert order review: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// OrderReviewsByGame returns all order reviews for a game, in phase order.
+func (r *PhaseRepo) OrderReviewsByGame(ctx context.Context, gameID string) ([]model.OrderReview, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT o.phase_id, o.power, o.orders, o.suggested, o.eval, o.suggested_eval, o.delta, o.blunder
+		 FROM order_reviews o
+		 JOIN phases p ON p.id = o.phase_id
+		 WHERE p.game_id = $1 ORDER BY p.created_at, o.power`, gameID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("order reviews by game: %w", err)
+	}
+	defer rows.Close()
+
+	var reviews []model.OrderReview
+	for rows.Next() {
+		var rv model.OrderReview
+		if err := rows.Scan(&rv.PhaseID, &rv.Power, &rv.Orders, &rv.Suggested, &rv.Eval, &rv.SuggestedEval, &rv.Delta, &rv.Blunder); err != nil {
+			return nil, fmt.Errorf("scan order review: %w", err)
+		}
+		reviews = append(reviews, rv)
+	}
+	return reviews, rows.Err()
+}
+
 func nullStr(s string) sql.NullString {
 	if s == "" {
 		return sql.NullString{}
 	}
 	return sql.NullString{String: s, Valid: true}
 }
+
+// SaveSubmittedOrders records a power's orders for a phase. Nothing is
+// recorded once the phase has resolved.
+func (r *PhaseRepo) SaveSubmittedOrders(ctx context.Context, phaseID, power string, orders json.RawMessage) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO phase_submissions (phase_id, power, orders)
+		 SELECT id, $2, $3 FROM phases WHERE id = $1 AND resolved_at IS NULL
+		 ON CONFLICT (phase_id, power) DO UPDATE SET orders = EXCLUDED.orders, updated_at = now()`,
+		phaseID, power, orders,
+	)
+	if err != nil {
+		return fmt.Errorf("save submitted orders: %w", err)
+	}
+	return nil
+}
+
+// SetSubmittedReady records whether a power is ready in a phase. Nothing is
+// recorded once the phase has resolved.
+func (r *PhaseRepo) SetSubmittedReady(ctx context.Context, phaseID, power string, ready bool) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO phase_submissions (phase_id, power, ready)
+		 SELECT id, $2, $3 FROM phases WHERE id = $1 AND resolved_at IS NULL
+		 ON CONFLICT (phase_id, power) DO UPDATE SET ready = EXCLUDED.ready, updated_at = now()`,
+		phaseID, power, ready,
+	)
+	if err != nil {
+		return fmt.Errorf("set submitted ready: %w", err)
+	}
+	return nil
+}
+
+// ClearSubmission forgets what a power has submitted in its game's current
+// phase.
+func (r *PhaseRepo) ClearSubmission(ctx context.Context, gameID, power string) error {
+	_, err := r.db.ExecContext(ctx,
+		`DELETE FROM phase_submissions WHERE power = $2 AND phase_id = (
+		   SELECT id FROM phases WHERE game_id = $1 AND resolved_at IS NULL
+		   ORDER BY created_at DESC LIMIT 1)`,
+		gameID, power,
+	)
+	if err != nil {
+		return fmt.Errorf("clear submission: %w", err)
+	}
+	return nil
+}
+
+// Submissions returns what each power has submitted in an unresolved phase.
+func (r *PhaseRepo) Submissions(ctx context.Context, phaseID string) ([]model.Submission, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT phase_id, power, orders, ready FROM phase_submissions WHERE phase_id = $1 ORDER BY power`,
+		phaseID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list submissions: %w", err)
+	}
+	defer rows.Close()
+
+	var subs []model.Submission
+	for rows.Next() {
+		var s model.Submission
+		var orders []byte
+		if err := rows.Scan(&s.PhaseID, &s.Power, &orders, &s.Ready); err != nil {
+			return nil, fmt.Errorf("scan submission: %w", err)
+		}
+		if orders != nil {
+			s.Orders = json.RawMessage(orders)
+		}
+		subs = append(subs, s)
+	}
+	return subs, rows.Err()
+}
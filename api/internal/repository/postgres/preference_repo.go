@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// PreferenceRepo handles per-user preference database operations.
+type PreferenceRepo struct {
+	db *timedDB
+}
+
+// NewPreferenceRepo creates a PreferenceRepo.
+func NewPreferenceRepo(db *sql.DB) *PreferenceRepo {
+	return &PreferenceRepo{db: &timedDB{db: db}}
+}
+
+// SetQueryTimeout bounds how long each PreferenceRepo operation may run. Zero
+// (the default) leaves only the caller's context deadline.
+func (r *PreferenceRepo) SetQueryTimeout(d time.Duration) {
+	r.db.timeout = d
+}
+
+// Get returns a user's preferences keyed by namespace.
+func (r *PreferenceRepo) Get(ctx context.Context, userID string) (map[string]json.RawMessage, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT namespace, value FROM user_preferences WHERE user_id = $1`, userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("get preferences: %w", err)
+	}
+	defer rows.Close()
+
+	prefs := make(map[string]json.RawMessage)
+	for rows.Next() {
+		var ns string
+		var value []byte
+		if err := rows.Scan(&ns, &value); err != nil {
+			return nil, fmt.Errorf("scan preference: %w", err)
+		}
+		prefs[ns] = value
+	}
+	return prefs, rows.Err()
+}
+
+// Set replaces the given namespaces in one transaction. A nil value deletes
+// the namespace; namespaces not in values are left alone.
+func (r *PreferenceRepo) Set(ctx context.Context, userID string, values map[string]json.RawMessage) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	for ns, value := range values {
+		if value == nil {
+			_, err = tx.ExecContext(ctx,
+				`DELETE FROM user_preferences WHERE user_id = $1 AND namespace = $2`, userID, ns)
+		} else {
+			_, err = tx.ExecContext(ctx,
+				`INSERT INTO user_preferences (user_id, namespace, value) VALUES ($1, $2, $3)
+				 ON CONFLICT (user_id, namespace) DO UPDATE SET value = EXCLUDED.value, updated_at = now()`,
+				userID, ns, []byte(value))
+		}
+		if err != nil {
+			return fmt.Errorf("set preference %s: %w", ns, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit preferences: %w", err)
+	}
+	return nil
+}
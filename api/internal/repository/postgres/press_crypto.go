@@ -0,0 +1,196 @@
+package postgres
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrPressKeyMissing is returned when encrypted messages are read by a
+// MessageRepo that has no encryption key.
+var ErrPressKeyMissing = errors.New("press encryption key not configured")
+
+// pressKeyCacheGames bounds how many games' unwrapped data keys are kept in
+// memory; the least recently used is dropped first and unwrapped again from
+// its row when next needed.
+const pressKeyCacheGames = 1024
+
+// pressCipher encrypts private press at rest. Each game gets its own random
+// data key, stored wrapped (encrypted) by the server's key, so deleting a
+// game's key row, or the game, leaves its press unreadable.
+type pressCipher struct {
+	kek cipher.AEAD // wraps the per-game data keys
+
+	mu   sync.Mutex
+	keys map[string]*cachedKey // game ID -> unwrapped data key
+}
+
+// cachedKey is an unwrapped data key and when it was last used.
+type cachedKey struct {
+	aead cipher.AEAD
+	used time.Time
+}
+
+// DecodePressKey decodes a base64 press encryption key, as given in the
+// PRESS_ENCRYPTION_KEY environment variable.
+func DecodePressKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("decode press encryption key: %w", err)
+	}
+	return key, nil
+}
+
+func newPressCipher(key []byte) (*pressCipher, error) {
+	kek, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	return &pressCipher{kek: kek, keys: make(map[string]*cachedKey)}, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("press encryption key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// gameKey returns the game's data key, creating and storing one the first
+// time it is needed. Concurrent creators agree on whichever row lands first.
+func (c *pressCipher) gameKey(ctx context.Context, db *timedDB, gameID string) (cipher.AEAD, error) {
+	c.mu.Lock()
+	cached, ok := c.keys[gameID]
+	if ok {
+		cached.used = time.Now()
+	}
+	c.mu.Unlock()
+	if ok {
+		return cached.aead, nil
+	}
+
+	var wrapped []byte
+	err := db.QueryRowContext(ctx, `SELECT wrapped_key FROM game_press_keys WHERE game_id = $1`, gameID).Scan(&wrapped)
+	if err == sql.ErrNoRows {
+		dataKey := make([]byte, 32)
+		if _, err := rand.Read(dataKey); err != nil {
+			return nil, fmt.Errorf("generate press key: %w", err)
+		}
+		err = db.QueryRowContext(ctx,
+			`INSERT INTO game_press_keys (game_id, wrapped_key) VALUES ($1, $2)
+			 ON CONFLICT (game_id) DO UPDATE SET game_id = EXCLUDED.game_id
+			 RETURNING wrapped_key`,
+			gameID, c.seal(c.kek, dataKey, gameID)).Scan(&wrapped)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load press key: %w", err)
+	}
+
+	dataKey, err := c.open(c.kek, wrapped, gameID)
+	if err != nil {
+		return nil, fmt.Errorf("unwrap press key of game %s: %w", gameID, err)
+	}
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	c.remember(gameID, aead)
+	return aead, nil
+}
+
+// remember caches the game's data key, dropping the least recently used
+// one if the cache is full.
+func (c *pressCipher) remember(gameID string, aead cipher.AEAD) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.keys[gameID]; !ok && len(c.keys) >= pressKeyCacheGames {
+		var oldest string
+		var at time.Time
+		for id, k := range c.keys {
+			if oldest == "" || k.used.Before(at) {
+				oldest, at = id, k.used
+			}
+		}
+		delete(c.keys, oldest)
+	}
+	c.keys[gameID] = &cachedKey{aead: aead, used: time.Now()}
+}
+
+// encrypt returns content sealed with the game's data key, base64-encoded
+// for the text column. The game ID is bound in, so ciphertext copied to
+// another game doesn't decrypt.
+func (c *pressCipher) encrypt(ctx context.Context, db *timedDB, gameID, content string) (string, error) {
+	aead, err := c.gameKey(ctx, db, gameID)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(c.seal(aead, []byte(content), gameID)), nil
+}
+
+// decrypt reverses encrypt.
+func (c *pressCipher) decrypt(ctx context.Context, db *timedDB, gameID, content string) (string, error) {
+	aead, err := c.gameKey(ctx, db, gameID)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(content)
+	if err != nil {
+		return "", fmt.Errorf("decode encrypted message: %w", err)
+	}
+	plain, err := c.open(aead, sealed, gameID)
+	if err != nil {
+		return "", fmt.Errorf("decrypt message: %w", err)
+	}
+	return string(plain), nil
+}
+
+// encryptIntent returns a message intent's JSON sealed like encrypt, as a
+// JSON string for the JSONB intent column.
+func (c *pressCipher) encryptIntent(ctx context.Context, db *timedDB, gameID string, intent []byte) ([]byte, error) {
+	sealed, err := c.encrypt(ctx, db, gameID, string(intent))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(sealed)
+}
+
+// decryptIntent reverses encryptIntent. Intents stored as JSON objects,
+// before they were encrypted, are returned as they are.
+func (c *pressCipher) decryptIntent(ctx context.Context, db *timedDB, gameID string, stored []byte) ([]byte, error) {
+	var sealed string
+	if json.Unmarshal(stored, &sealed) != nil {
+		return stored, nil
+	}
+	intent, err := c.decrypt(ctx, db, gameID, sealed)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt message intent: %w", err)
+	}
+	return []byte(intent), nil
+}
+
+// seal encrypts plaintext under a fresh random nonce, which it prepends.
+func (c *pressCipher) seal(aead cipher.AEAD, plaintext []byte, gameID string) []byte {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	rand.Read(nonce)
+	return aead.Seal(nonce, nonce, plaintext, []byte(gameID))
+}
+
+func (c *pressCipher) open(aead cipher.AEAD, sealed []byte, gameID string) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, []byte(gameID))
+}
@@ -0,0 +1,92 @@
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestPressCipher(t *testing.T) {
+	if _, err := newPressCipher(make([]byte, 16)); err == nil {
+		t.Error("expected a short key to be rejected")
+	}
+	c, err := newPressCipher(bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatalf("newPressCipher: %v", err)
+	}
+
+	// Wrapping a data key round-trips only for the same game.
+	dataKey := bytes.Repeat([]byte{2}, 32)
+	wrapped := c.seal(c.kek, dataKey, "game-1")
+	if got, err := c.open(c.kek, wrapped, "game-1"); err != nil || !bytes.Equal(got, dataKey) {
+		t.Fatalf("unwrap: %v", err)
+	}
+	if _, err := c.open(c.kek, wrapped, "game-2"); err == nil {
+		t.Error("expected a key wrapped for another game to fail")
+	}
+
+	// Seed the key cache so no database is needed.
+	for _, id := range []string{"game-1", "game-2"} {
+		aead, _ := newGCM(bytes.Repeat([]byte(id[len(id)-1:]), 32))
+		c.remember(id, aead)
+	}
+	ctx := context.Background()
+	sealed, err := c.encrypt(ctx, nil, "game-1", "Meet me in Burgundy")
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	if sealed == "Meet me in Burgundy" {
+		t.Fatal("expected ciphertext")
+	}
+	if plain, err := c.decrypt(ctx, nil, "game-1", sealed); err != nil || plain != "Meet me in Burgundy" {
+		t.Errorf("decrypt = %q, %v", plain, err)
+	}
+	if _, err := c.decrypt(ctx, nil, "game-2", sealed); err == nil {
+		t.Error("expected a message moved to another game not to decrypt")
+	}
+
+	// Intents, which carry proposed orders, are stored as a sealed JSON string.
+	intent := []byte(`{"type":"propose_orders","target_power":"germany","orders":"A par - bur"}`)
+	stored, err := c.encryptIntent(ctx, nil, "game-1", intent)
+	if err != nil {
+		t.Fatalf("encryptIntent: %v", err)
+	}
+	if !json.Valid(stored) || bytes.Contains(stored, []byte("bur")) || bytes.Contains(stored, []byte("germany")) {
+		t.Errorf("expected the stored intent to be unreadable JSON, got %s", stored)
+	}
+	if plain, err := c.decryptIntent(ctx, nil, "game-1", stored); err != nil || !bytes.Equal(plain, intent) {
+		t.Errorf("decryptIntent = %s, %v", plain, err)
+	}
+	if plain, err := c.decryptIntent(ctx, nil, "game-1", intent); err != nil || !bytes.Equal(plain, intent) {
+		t.Errorf("expected an intent stored before encryption to be kept, got %s, %v", plain, err)
+	}
+
+	if _, err := DecodePressKey("not base64!"); err == nil {
+		t.Error("expected an error for a malformed key")
+	}
+}
+
+func TestPressCipherKeyCacheBounded(t *testing.T) {
+	c, _ := newPressCipher(bytes.Repeat([]byte{1}, 32))
+	aead, _ := newGCM(bytes.Repeat([]byte{2}, 32))
+	start := time.Now().Add(-time.Hour)
+	for i := range pressKeyCacheGames {
+		id := fmt.Sprintf("game-%d", i)
+		c.remember(id, aead)
+		c.keys[id].used = start.Add(time.Duration(i) * time.Second)
+	}
+	// Using the first game keeps it; the second is now the least recent.
+	if _, err := c.gameKey(context.Background(), nil, "game-0"); err != nil {
+		t.Fatalf("gameKey: %v", err)
+	}
+	c.remember("game-new", aead)
+	if len(c.keys) != pressKeyCacheGames {
+		t.Errorf("expected %d cached keys, got %d", pressKeyCacheGames, len(c.keys))
+	}
+	if c.keys["game-0"] == nil || c.keys["game-1"] != nil || c.keys["game-new"] == nil {
+		t.Error("expected the least recently used key dropped")
+	}
+}
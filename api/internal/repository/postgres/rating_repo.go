@@ -0,0 +1,164 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+)
+
+// RatingRepo handles Elo rating database operations.
+type RatingRepo struct {
+	db *timedDB
+}
+
+// NewRatingRepo creates a RatingRepo.
+func NewRatingRepo(db *sql.DB) *RatingRepo {
+	return &RatingRepo{db: &timedDB{db: db}}
+}
+
+// SetQueryTimeout bounds how long each RatingRepo operation may run. Zero
+// (the default) leaves only the caller's context deadline.
+func (r *RatingRepo) SetQueryTimeout(d time.Duration) {
+	r.db.timeout = d
+}
+
+// Find returns a subject's rating, or nil if it has never been rated.
+func (r *RatingRepo) Find(ctx context.Context, subjectType, subjectID string) (*model.Rating, error) {
+	rt := model.Rating{SubjectType: subjectType, SubjectID: subjectID}
+	err := r.db.QueryRowContext(ctx,
+		`SELECT rating, games, wins, updated_at FROM ratings WHERE subject_type = $1 AND subject_id = $2`,
+		subjectType, subjectID,
+	).Scan(&rt.Rating, &rt.Games, &rt.Wins, &rt.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("find rating: %w", err)
+	}
+	return &rt, nil
+}
+
+// ApplyGame adds a game's rating changes. Deltas are added to the stored
+// ratings rather than overwriting them, so games finishing at the same time
+// don't lose each other's updates. It reports false, changing nothing, if
+// the game was already rated.
+func (r *RatingRepo) ApplyGame(ctx context.Context, gameID string, updates []model.RatingUpdate) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		`INSERT INTO rated_games (game_id) VALUES ($1) ON CONFLICT DO NOTHING`, gameID)
+	if err != nil {
+		return false, fmt.Errorf("mark game rated: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return false, err
+	}
+
+	for _, u := range updates {
+		wins := 0
+		if u.Won {
+			wins = 1
+		}
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO ratings (subject_type, subject_id, rating, games, wins)
+			 VALUES ($1, $2, $3 + $4, 1, $5)
+			 ON CONFLICT (subject_type, subject_id) DO UPDATE
+			 SET rating = ratings.rating + $4, games = ratings.games + 1,
+			     wins = ratings.wins + $5, updated_at = now()`,
+			u.SubjectType, u.SubjectID, u.Initial, u.Delta, wins,
+		)
+		if err != nil {
+			return false, fmt.Errorf("update rating: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("commit: %w", err)
+	}
+	return true, nil
+}
+
+// Top returns the highest rated subjects of a type. User ratings carry the
+// user's display name.
+func (r *RatingRepo) Top(ctx context.Context, subjectType string, limit int) ([]model.Rating, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT r.subject_id, COALESCE(u.display_name, ''), r.rating, r.games, r.wins, r.updated_at
+		 FROM ratings r
+		 LEFT JOIN users u ON r.subject_type = 'user' AND u.id::text = r.subject_id
+		 WHERE r.subject_type = $1
+		 ORDER BY r.rating DESC, r.subject_id
+		 LIMIT $2`, subjectType, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list ratings: %w", err)
+	}
+	defer rows.Close()
+
+	var ratings []model.Rating
+	for rows.Next() {
+		rt := model.Rating{SubjectType: subjectType}
+		if err := rows.Scan(&rt.SubjectID, &rt.DisplayName, &rt.Rating, &rt.Games, &rt.Wins, &rt.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan rating: %w", err)
+		}
+		ratings = append(ratings, rt)
+	}
+	return ratings, rows.Err()
+}
+
+// CountRated returns how many games have been rated.
+func (r *RatingRepo) CountRated(ctx context.Context) (int, error) {
+	var n int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM rated_games`).Scan(&n); err != nil {
+		return 0, fmt.Errorf("count rated games: %w", err)
+	}
+	return n, nil
+}
+
+// Reset deletes every rating and marks every game unrated.
+func (r *RatingRepo) Reset(ctx context.Context) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, `DELETE FROM ratings`); err != nil {
+		return fmt.Errorf("delete ratings: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM rated_games`); err != nil {
+		return fmt.Errorf("delete rated games: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+// ListUnrated returns the IDs of finished games that haven't been rated,
+// in the order they finished.
+func (r *RatingRepo) ListUnrated(ctx context.Context) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT g.id FROM games g
+		 WHERE g.status = 'finished'
+		   AND NOT EXISTS (SELECT 1 FROM rated_games rg WHERE rg.game_id = g.id)
+		 ORDER BY g.finished_at, g.id`)
+	if err != nil {
+		return nil, fmt.Errorf("list unrated games: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan game id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+)
+
+// SummaryRepo handles end-of-game summary database operations.
+type SummaryRepo struct {
+	db *timedDB
+}
+
+// NewSummaryRepo creates a SummaryRepo.
+func NewSummaryRepo(db *sql.DB) *SummaryRepo {
+	return &SummaryRepo{db: &timedDB{db: db}}
+}
+
+// SetQueryTimeout bounds how long each SummaryRepo operation may run. Zero
+// (the default) leaves only the caller's context deadline.
+func (r *SummaryRepo) SetQueryTimeout(d time.Duration) {
+	r.db.timeout = d
+}
+
+// Save stores a game's summary, replacing any earlier one.
+func (r *SummaryRepo) Save(ctx context.Context, s *model.GameSummary) error {
+	powers, err := json.Marshal(s.Powers)
+	if err != nil {
+		return fmt.Errorf("marshal powers: %w", err)
+	}
+	history, err := json.Marshal(s.SCHistory)
+	if err != nil {
+		return fmt.Errorf("marshal sc history: %w", err)
+	}
+	err = r.db.QueryRowContext(ctx,
+		`INSERT INTO game_summaries (game_id, winner, final_year, powers, sc_history, map_svg, sc_graph_svg)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)
+		 ON CONFLICT (game_id) DO UPDATE
+		 SET winner = EXCLUDED.winner, final_year = EXCLUDED.final_year, powers = EXCLUDED.powers,
+		     sc_history = EXCLUDED.sc_history, map_svg = EXCLUDED.map_svg,
+		     sc_graph_svg = EXCLUDED.sc_graph_svg, created_at = now()
+		 RETURNING created_at`,
+		s.GameID, s.Winner, s.FinalYear, powers, history, s.MapSVG, s.SCGraphSVG,
+	).Scan(&s.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("save summary: %w", err)
+	}
+	return nil
+}
+
+// FindByGame returns a game's summary, or nil if none has been generated.
+func (r *SummaryRepo) FindByGame(ctx context.Context, gameID string) (*model.GameSummary, error) {
+	s := model.GameSummary{GameID: gameID}
+	var powers, history []byte
+	err := r.db.QueryRowContext(ctx,
+		`SELECT winner, final_year, powers, sc_history, map_svg, sc_graph_svg, created_at
+		 FROM game_summaries WHERE game_id = $1`, gameID,
+	).Scan(&s.Winner, &s.FinalYear, &powers, &history, &s.MapSVG, &s.SCGraphSVG, &s.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("find summary: %w", err)
+	}
+	if err := json.Unmarshal(powers, &s.Powers); err != nil {
+		return nil, fmt.Errorf("unmarshal powers: %w", err)
+	}
+	if err := json.Unmarshal(history, &s.SCHistory); err != nil {
+		return nil, fmt.Errorf("unmarshal sc history: %w", err)
+	}
+	return &s, nil
+}
@@ -0,0 +1,168 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/freeeve/polite-betrayal/api/internal/repository"
+)
+
+// pqQueryCanceled is the SQLSTATE Postgres reports when statement_timeout
+// (or a cancel request) aborts a statement.
+const pqQueryCanceled = "57014"
+
+// timedDB wraps a connection pool so every statement runs under a
+// per-operation deadline, and timeouts surface as repository.ErrTimeout.
+// A zero timeout leaves the caller's context untouched. When tx is set,
+// statements run inside that transaction instead (see GameRepo.WithTx).
+type timedDB struct {
+	db      *sql.DB
+	tx      *sql.Tx
+	timeout time.Duration
+}
+
+// conn is what statements run on: the pool or a shared transaction.
+type conn interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// txn is the part of *sql.Tx the repositories use, so a repository bound
+// to a shared transaction can hand out a savepoint in its place.
+type txn interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	Commit() error
+	Rollback() error
+}
+
+func (t *timedDB) conn() conn {
+	if t.tx != nil {
+		return t.tx
+	}
+	return t.db
+}
+
+// withTx returns a copy of t that runs its statements inside tx.
+func (t *timedDB) withTx(tx *sql.Tx) *timedDB {
+	return &timedDB{db: t.db, tx: tx, timeout: t.timeout}
+}
+
+func (t *timedDB) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if t.timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, t.timeout)
+}
+
+func (t *timedDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	ctx, cancel := t.withTimeout(ctx)
+	defer cancel()
+	res, err := t.conn().ExecContext(ctx, query, args...)
+	return res, mapTimeout(err)
+}
+
+func (t *timedDB) QueryContext(ctx context.Context, query string, args ...any) (*timedRows, error) {
+	ctx, cancel := t.withTimeout(ctx)
+	rows, err := t.conn().QueryContext(ctx, query, args...)
+	if err != nil {
+		cancel()
+		return nil, mapTimeout(err)
+	}
+	return &timedRows{Rows: rows, cancel: cancel}, nil
+}
+
+func (t *timedDB) QueryRowContext(ctx context.Context, query string, args ...any) *timedRow {
+	ctx, cancel := t.withTimeout(ctx)
+	return &timedRow{row: t.conn().QueryRowContext(ctx, query, args...), cancel: cancel}
+}
+
+// BeginTx starts a transaction bound to the caller's context. Statements
+// inside it are still bounded by the server-side statement_timeout. Inside
+// a shared transaction it opens a savepoint instead, so the repository's
+// own commit and rollback only release or undo its part of the work.
+func (t *timedDB) BeginTx(ctx context.Context, opts *sql.TxOptions) (txn, error) {
+	if t.tx != nil {
+		if _, err := t.tx.ExecContext(ctx, "SAVEPOINT repo"); err != nil {
+			return nil, mapTimeout(err)
+		}
+		return &savepoint{Tx: t.tx, ctx: ctx}, nil
+	}
+	tx, err := t.db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, mapTimeout(err)
+	}
+	return tx, nil
+}
+
+// savepoint stands in for a transaction nested in a shared one. Like
+// *sql.Tx, only the first Commit or Rollback has any effect.
+type savepoint struct {
+	*sql.Tx
+	ctx  context.Context
+	done bool
+}
+
+func (s *savepoint) Commit() error {
+	return s.end("RELEASE SAVEPOINT repo")
+}
+
+func (s *savepoint) Rollback() error {
+	return s.end("ROLLBACK TO SAVEPOINT repo")
+}
+
+func (s *savepoint) end(stmt string) error {
+	if s.done {
+		return sql.ErrTxDone
+	}
+	s.done = true
+	_, err := s.Tx.ExecContext(s.ctx, stmt)
+	return mapTimeout(err)
+}
+
+// timedRows releases its deadline when closed.
+type timedRows struct {
+	*sql.Rows
+	cancel context.CancelFunc
+}
+
+func (r *timedRows) Close() error {
+	defer r.cancel()
+	return r.Rows.Close()
+}
+
+func (r *timedRows) Err() error {
+	return mapTimeout(r.Rows.Err())
+}
+
+// timedRow releases its deadline once scanned.
+type timedRow struct {
+	row    *sql.Row
+	cancel context.CancelFunc
+}
+
+func (r *timedRow) Scan(dest ...any) error {
+	defer r.cancel()
+	return mapTimeout(r.row.Scan(dest...))
+}
+
+// mapTimeout wraps deadline and statement-timeout errors in
+// repository.ErrTimeout, leaving all other errors (including sql.ErrNoRows)
+// unchanged.
+func mapTimeout(err error) error {
+	if err == nil {
+		return nil
+	}
+	var pqErr *pq.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &pqErr) && pqErr.Code == pqQueryCanceled) {
+		return fmt.Errorf("%w: %v", repository.ErrTimeout, err)
+	}
+	return err
+}
@@ -0,0 +1,60 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/freeeve/polite-betrayal/api/internal/repository"
+)
+
+func TestMapTimeout(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"deadline", fmt.Errorf("query: %w", context.DeadlineExceeded), true},
+		{"statement timeout", &pq.Error{Code: pqQueryCanceled}, true},
+		{"other pq error", &pq.Error{Code: "23505"}, false},
+		{"no rows", sql.ErrNoRows, false},
+	}
+	for _, tt := range tests {
+		err := mapTimeout(tt.err)
+		if got := errors.Is(err, repository.ErrTimeout); got != tt.want {
+			t.Errorf("%s: errors.Is(ErrTimeout) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if mapTimeout(sql.ErrNoRows) != sql.ErrNoRows {
+		t.Error("sql.ErrNoRows must pass through unchanged")
+	}
+	if mapTimeout(nil) != nil {
+		t.Error("nil must stay nil")
+	}
+}
+
+func TestWithStatementTimeout(t *testing.T) {
+	tests := []struct {
+		in   string
+		d    time.Duration
+		want string
+	}{
+		{"postgres://u:p@localhost/db?sslmode=disable", 5 * time.Second, "postgres://u:p@localhost/db?sslmode=disable&statement_timeout=5000"},
+		{"host=localhost dbname=db", 250 * time.Millisecond, "host=localhost dbname=db statement_timeout=250"},
+		{"postgres://localhost/db", 0, "postgres://localhost/db"},
+	}
+	for _, tt := range tests {
+		got, err := withStatementTimeout(tt.in, tt.d)
+		if err != nil {
+			t.Fatalf("withStatementTimeout(%q): %v", tt.in, err)
+		}
+		if got != tt.want {
+			t.Errorf("withStatementTimeout(%q, %v) = %q, want %q", tt.in, tt.d, got, tt.want)
+		}
+	}
+}
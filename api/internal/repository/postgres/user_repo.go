@@ -4,18 +4,25 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/freeeve/polite-betrayal/api/internal/model"
 )
 
 // UserRepo handles user database operations.
 type UserRepo struct {
-	db *sql.DB
+	db *timedDB
 }
 
 // NewUserRepo creates a UserRepo.
 func NewUserRepo(db *sql.DB) *UserRepo {
-	return &UserRepo{db: db}
+	return &UserRepo{db: &timedDB{db: db}}
+}
+
+// SetQueryTimeout bounds how long each UserRepo operation may run. Zero
+// (the default) leaves only the caller's context deadline.
+func (r *UserRepo) SetQueryTimeout(d time.Duration) {
+	r.db.timeout = d
 }
 
 // FindByProviderID looks up a user by OAuth provider and provider-specific ID.
@@ -23,10 +30,10 @@ func (r *UserRepo) FindByProviderID(ctx context.Context, provider, providerID st
 	var u model.User
 	var avatar sql.NullString
 	err := r.db.QueryRowContext(ctx,
-		`SELECT id, provider, provider_id, display_name, avatar_url, created_at, updated_at
+		`SELECT id, provider, provider_id, display_name, avatar_url, locale, share_press, created_at, updated_at
 		 FROM users WHERE provider = $1 AND provider_id = $2`,
 		provider, providerID,
-	).Scan(&u.ID, &u.Provider, &u.ProviderID, &u.DisplayName, &avatar, &u.CreatedAt, &u.UpdatedAt)
+	).Scan(&u.ID, &u.Provider, &u.ProviderID, &u.DisplayName, &avatar, &u.Locale, &u.SharePress, &u.CreatedAt, &u.UpdatedAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -42,10 +49,10 @@ func (r *UserRepo) FindByID(ctx context.Context, id string) (*model.User, error)
 	var u model.User
 	var avatar sql.NullString
 	err := r.db.QueryRowContext(ctx,
-		`SELECT id, provider, provider_id, display_name, avatar_url, created_at, updated_at
+		`SELECT id, provider, provider_id, display_name, avatar_url, locale, share_press, created_at, updated_at
 		 FROM users WHERE id = $1`,
 		id,
-	).Scan(&u.ID, &u.Provider, &u.ProviderID, &u.DisplayName, &avatar, &u.CreatedAt, &u.UpdatedAt)
+	).Scan(&u.ID, &u.Provider, &u.ProviderID, &u.DisplayName, &avatar, &u.Locale, &u.SharePress, &u.CreatedAt, &u.UpdatedAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -65,9 +72,9 @@ func (r *UserRepo) Upsert(ctx context.Context, provider, providerID, displayName
 		 VALUES ($1, $2, $3, $4)
 		 ON CONFLICT (provider, provider_id)
 		 DO UPDATE SET display_name = EXCLUDED.display_name, avatar_url = EXCLUDED.avatar_url, updated_at = now()
-		 RETURNING id, provider, provider_id, display_name, avatar_url, created_at, updated_at`,
+		 RETURNING id, provider, provider_id, display_name, avatar_url, locale, share_press, created_at, updated_at`,
 		provider, providerID, displayName, avatarURL,
-	).Scan(&u.ID, &u.Provider, &u.ProviderID, &u.DisplayName, &u.AvatarURL, &u.CreatedAt, &u.UpdatedAt)
+	).Scan(&u.ID, &u.Provider, &u.ProviderID, &u.DisplayName, &u.AvatarURL, &u.Locale, &u.SharePress, &u.CreatedAt, &u.UpdatedAt)
 	if err != nil {
 		return nil, fmt.Errorf("upsert user: %w", err)
 	}
@@ -85,3 +92,28 @@ func (r *UserRepo) UpdateDisplayName(ctx context.Context, id, displayName string
 	}
 	return nil
 }
+
+// UpdateLocale updates the locale used to render canned press sent to a user.
+func (r *UserRepo) UpdateLocale(ctx context.Context, id, locale string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE users SET locale = $1, updated_at = now() WHERE id = $2`,
+		locale, id,
+	)
+	if err != nil {
+		return fmt.Errorf("update locale: %w", err)
+	}
+	return nil
+}
+
+// UpdateSharePress records whether a user consents to their press being
+// quoted in game reports.
+func (r *UserRepo) UpdateSharePress(ctx context.Context, id string, share bool) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE users SET share_press = $1, updated_at = now() WHERE id = $2`,
+		share, id,
+	)
+	if err != nil {
+		return fmt.Errorf("update share press: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+)
+
+// VariantRepo handles custom variant database operations.
+type VariantRepo struct {
+	db *timedDB
+}
+
+// NewVariantRepo creates a VariantRepo.
+func NewVariantRepo(db *sql.DB) *VariantRepo {
+	return &VariantRepo{db: &timedDB{db: db}}
+}
+
+// SetQueryTimeout bounds how long each VariantRepo operation may run. Zero
+// (the default) leaves only the caller's context deadline.
+func (r *VariantRepo) SetQueryTimeout(d time.Duration) {
+	r.db.timeout = d
+}
+
+// Save stores a new version of a variant: version 1 of a new name, or the
+// next version of an existing one, keeping its original creator. Earlier
+// versions are kept unchanged for the games that play them. It sets
+// v.Version.
+func (r *VariantRepo) Save(ctx context.Context, v *model.Variant) error {
+	err := r.db.QueryRowContext(ctx,
+		`WITH saved AS (
+		     INSERT INTO variants (name, description, definition, playable, created_by)
+		     VALUES ($1, $2, $3, $4, NULLIF($5, '')::uuid)
+		     ON CONFLICT (name) DO UPDATE
+		     SET description = EXCLUDED.description, definition = EXCLUDED.definition,
+		         playable = EXCLUDED.playable, version = variants.version + 1, updated_at = now()
+		     RETURNING name, version, definition, playable, created_by, created_at, updated_at
+		 ), versioned AS (
+		     INSERT INTO variant_versions (name, version, definition, playable, created_by)
+		     SELECT name, version, definition, playable, NULLIF($5, '')::uuid FROM saved
+		 )
+		 SELECT version, COALESCE(created_by::text, ''), created_at, updated_at FROM saved`,
+		v.Name, v.Description, []byte(v.Definition), v.Playable, v.CreatedBy,
+	).Scan(&v.Version, &v.CreatedBy, &v.CreatedAt, &v.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("save variant: %w", err)
+	}
+	return nil
+}
+
+// FindByName returns the latest version of a variant with its definition,
+// or nil if there is none.
+func (r *VariantRepo) FindByName(ctx context.Context, name string) (*model.Variant, error) {
+	var v model.Variant
+	err := r.db.QueryRowContext(ctx,
+		`SELECT name, version, description, playable, COALESCE(created_by::text, ''), created_at, updated_at, definition
+		 FROM variants WHERE name = $1`, name,
+	).Scan(&v.Name, &v.Version, &v.Description, &v.Playable, &v.CreatedBy, &v.CreatedAt, &v.UpdatedAt, &v.Definition)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("find variant: %w", err)
+	}
+	return &v, nil
+}
+
+// FindVersion returns one version of a variant with its definition, or nil
+// if there is none. CreatedBy and CreatedAt are the version's uploader and
+// upload time.
+func (r *VariantRepo) FindVersion(ctx context.Context, name string, version int) (*model.Variant, error) {
+	var v model.Variant
+	err := r.db.QueryRowContext(ctx,
+		`SELECT vv.name, vv.version, v.description, vv.playable, COALESCE(vv.created_by::text, ''),
+		        vv.created_at, vv.created_at, vv.definition
+		 FROM variant_versions vv JOIN variants v ON v.name = vv.name
+		 WHERE vv.name = $1 AND vv.version = $2`, name, version,
+	).Scan(&v.Name, &v.Version, &v.Description, &v.Playable, &v.CreatedBy, &v.CreatedAt, &v.UpdatedAt, &v.Definition)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("find variant version: %w", err)
+	}
+	return &v, nil
+}
+
+// List returns all variants by name, without their definitions.
+func (r *VariantRepo) List(ctx context.Context) ([]model.Variant, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT name, version, description, playable, COALESCE(created_by::text, ''), created_at, updated_at
+		 FROM variants ORDER BY name`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list variants: %w", err)
+	}
+	defer rows.Close()
+
+	var variants []model.Variant
+	for rows.Next() {
+		var v model.Variant
+		if err := rows.Scan(&v.Name, &v.Version, &v.Description, &v.Playable, &v.CreatedBy, &v.CreatedAt, &v.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan variant: %w", err)
+		}
+		variants = append(variants, v)
+	}
+	return variants, rows.Err()
+}
+
+// ListPlayable returns the latest version of each playable variant with its
+// definition.
+func (r *VariantRepo) ListPlayable(ctx context.Context) ([]model.Variant, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT name, version, description, playable, COALESCE(created_by::text, ''), created_at, updated_at, definition
+		 FROM variants WHERE playable ORDER BY name`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list playable variants: %w", err)
+	}
+	defer rows.Close()
+
+	var variants []model.Variant
+	for rows.Next() {
+		var v model.Variant
+		if err := rows.Scan(&v.Name, &v.Version, &v.Description, &v.Playable, &v.CreatedBy, &v.CreatedAt, &v.UpdatedAt, &v.Definition); err != nil {
+			return nil, fmt.Errorf("scan variant: %w", err)
+		}
+		variants = append(variants, v)
+	}
+	return variants, rows.Err()
+}
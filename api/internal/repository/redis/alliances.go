@@ -0,0 +1,77 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// alliancesKey holds a bot's alliance state in a game.
+func alliancesKey(gameID, power string) string {
+	return "game:" + gameID + ":alliances:" + power
+}
+
+// allianceRetention is how long a bot's alliance state outlives its last
+// update, so abandoned games do not keep it forever.
+const allianceRetention = 90 * 24 * time.Hour
+
+// BotAlliances returns the alliance state of the bot playing power, or nil
+// if it has none yet.
+func (c *Client) BotAlliances(ctx context.Context, gameID, power string) ([]byte, error) {
+	data, err := c.rdb.Get(ctx, alliancesKey(gameID, power)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get bot alliances: %w", err)
+	}
+	return data, nil
+}
+
+// allianceUpdateAttempts bounds how often UpdateBotAlliances retries when
+// another writer changes the state under it.
+const allianceUpdateAttempts = 5
+
+// SetBotAlliances saves the alliance state of the bot playing power.
+func (c *Client) SetBotAlliances(ctx context.Context, gameID, power string, data []byte) error {
+	if err := c.rdb.Set(ctx, alliancesKey(gameID, power), data, allianceRetention).Err(); err != nil {
+		return fmt.Errorf("set bot alliances: %w", err)
+	}
+	return nil
+}
+
+// UpdateBotAlliances applies update to the alliance state of the bot playing
+// power, passing nil data if it has none yet. The read and write run in a
+// WATCH transaction, retried if another writer got in between, so concurrent
+// updates from press handling and order generation are not lost.
+func (c *Client) UpdateBotAlliances(ctx context.Context, gameID, power string, update func(data []byte) ([]byte, error)) error {
+	key := alliancesKey(gameID, power)
+	txf := func(tx *redis.Tx) error {
+		data, err := tx.Get(ctx, key).Bytes()
+		if err != nil && err != redis.Nil {
+			return err
+		}
+		next, err := update(data)
+		if err != nil {
+			return err
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, next, allianceRetention)
+			return nil
+		})
+		return err
+	}
+	for range allianceUpdateAttempts {
+		err := c.rdb.Watch(ctx, txf, key)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, redis.TxFailedErr) {
+			return fmt.Errorf("update bot alliances: %w", err)
+		}
+	}
+	return fmt.Errorf("update bot alliances: %w", redis.TxFailedErr)
+}
@@ -0,0 +1,39 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// evaluationKey holds the evaluations of a phase's position at a stage.
+func evaluationKey(phaseID, stage string) string {
+	return "phase:" + phaseID + ":evaluation:" + stage
+}
+
+// evaluationRetention is how long cached evaluations are kept. A phase's
+// positions never change, so this only bounds memory.
+const evaluationRetention = 7 * 24 * time.Hour
+
+// PhaseEvaluations returns the cached evaluations of a phase's position, or
+// nil if there are none.
+func (c *Client) PhaseEvaluations(ctx context.Context, phaseID, stage string) ([]byte, error) {
+	data, err := c.rdb.Get(ctx, evaluationKey(phaseID, stage)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get phase evaluations: %w", err)
+	}
+	return data, nil
+}
+
+// SetPhaseEvaluations caches the evaluations of a phase's position.
+func (c *Client) SetPhaseEvaluations(ctx context.Context, phaseID, stage string, data []byte) error {
+	if err := c.rdb.Set(ctx, evaluationKey(phaseID, stage), data, evaluationRetention).Err(); err != nil {
+		return fmt.Errorf("set phase evaluations: %w", err)
+	}
+	return nil
+}
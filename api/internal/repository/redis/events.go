@@ -0,0 +1,82 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/freeeve/polite-betrayal/api/internal/repository"
+)
+
+// Keys for the per-game event log. The sequence counter outlives the log by
+// a wide margin so a game idle past the log's retention keeps counting up
+// instead of reusing sequence numbers a client may already have seen.
+func eventSeqKey(gameID string) string { return "game:" + gameID + ":event_seq" }
+func eventLogKey(gameID string) string { return "game:" + gameID + ":events" } // seq -> event
+
+const (
+	eventLogSize      = 200
+	eventLogRetention = time.Hour
+	eventSeqRetention = 30 * 24 * time.Hour
+)
+
+// NextEventSeq allocates the next sequence number of a game's events.
+func (c *Client) NextEventSeq(ctx context.Context, gameID string) (int64, error) {
+	pipe := c.rdb.TxPipeline()
+	incr := pipe.Incr(ctx, eventSeqKey(gameID))
+	pipe.Expire(ctx, eventSeqKey(gameID), eventSeqRetention)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("next event seq: %w", err)
+	}
+	return incr.Val(), nil
+}
+
+// AppendGameEvent adds an encoded event to the game's log under its
+// sequence number, keeping only the most recent events.
+func (c *Client) AppendGameEvent(ctx context.Context, gameID string, seq int64, event []byte) error {
+	key := eventLogKey(gameID)
+	pipe := c.rdb.TxPipeline()
+	pipe.ZAdd(ctx, key, redis.Z{Score: float64(seq), Member: event})
+	pipe.ZRemRangeByRank(ctx, key, 0, -eventLogSize-1)
+	pipe.Expire(ctx, key, eventLogRetention)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("append game event: %w", err)
+	}
+	return nil
+}
+
+// GameEventsSince returns the logged events after seq, oldest first, and
+// the game's latest sequence number. It returns ErrEventsExpired, with the
+// latest sequence number, when the log no longer holds every event after
+// seq or seq is ahead of the game's counter.
+func (c *Client) GameEventsSince(ctx context.Context, gameID string, seq int64) ([][]byte, int64, error) {
+	latest, err := c.rdb.Get(ctx, eventSeqKey(gameID)).Int64()
+	if err != nil && err != redis.Nil {
+		return nil, 0, fmt.Errorf("get event seq: %w", err)
+	}
+	if seq > latest {
+		return nil, latest, repository.ErrEventsExpired
+	}
+	if seq == latest {
+		return nil, latest, nil
+	}
+	zs, err := c.rdb.ZRangeByScoreWithScores(ctx, eventLogKey(gameID), &redis.ZRangeBy{
+		Min: "(" + strconv.FormatInt(seq, 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, 0, fmt.Errorf("get game events: %w", err)
+	}
+	if len(zs) == 0 || int64(zs[0].Score) != seq+1 {
+		return nil, latest, repository.ErrEventsExpired
+	}
+	events := make([][]byte, len(zs))
+	for i, z := range zs {
+		s, _ := z.Member.(string)
+		events[i] = []byte(s)
+	}
+	return events, latest, nil
+}
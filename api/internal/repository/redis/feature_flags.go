@@ -0,0 +1,62 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// Key patterns for feature flags. Per-game overrides live beside the rest of
+// the game's keys; rollout percentages are global.
+func flagsKey(gameID string) string { return "game:" + gameID + ":flags" }
+
+const flagRolloutKey = "feature_flags:rollout"
+
+// GameFlags returns the explicit flag overrides set for a game.
+func (c *Client) GameFlags(ctx context.Context, gameID string) (map[string]bool, error) {
+	raw, err := c.rdb.HGetAll(ctx, flagsKey(gameID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("get game flags: %w", err)
+	}
+	flags := make(map[string]bool, len(raw))
+	for name, v := range raw {
+		flags[name] = v == "1"
+	}
+	return flags, nil
+}
+
+// SetGameFlag overrides a flag for a single game.
+func (c *Client) SetGameFlag(ctx context.Context, gameID, flag string, enabled bool) error {
+	v := "0"
+	if enabled {
+		v = "1"
+	}
+	return c.rdb.HSet(ctx, flagsKey(gameID), flag, v).Err()
+}
+
+// ClearGameFlag removes a game's override so the rollout default applies again.
+func (c *Client) ClearGameFlag(ctx context.Context, gameID, flag string) error {
+	return c.rdb.HDel(ctx, flagsKey(gameID), flag).Err()
+}
+
+// FlagRollouts returns the rollout percentage configured for each flag.
+func (c *Client) FlagRollouts(ctx context.Context) (map[string]int, error) {
+	raw, err := c.rdb.HGetAll(ctx, flagRolloutKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("get flag rollouts: %w", err)
+	}
+	rollouts := make(map[string]int, len(raw))
+	for name, v := range raw {
+		pct, err := strconv.Atoi(v)
+		if err != nil {
+			continue
+		}
+		rollouts[name] = pct
+	}
+	return rollouts, nil
+}
+
+// SetFlagRollout sets the percentage of games (0-100) that get a flag by default.
+func (c *Client) SetFlagRollout(ctx context.Context, flag string, percent int) error {
+	return c.rdb.HSet(ctx, flagRolloutKey, flag, percent).Err()
+}
@@ -3,6 +3,7 @@ package redis
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -15,6 +16,8 @@ func ordersKey(gameID, power string) string { return "game:" + gameID + ":orders
 func readyKey(gameID string) string         { return "game:" + gameID + ":ready" }
 func timerKey(gameID string) string         { return "game:" + gameID + ":timer" }
 func drawVoteKey(gameID string) string      { return "game:" + gameID + ":draw_votes" }
+func pausedKey(gameID string) string        { return "game:" + gameID + ":paused_remaining" }
+func quorumKey(gameID string) string        { return "game:" + gameID + ":quorum_deadline" }
 
 // SetGameState stores the live game state JSON.
 func (c *Client) SetGameState(ctx context.Context, gameID string, state json.RawMessage) error {
@@ -50,6 +53,43 @@ func (c *Client) GetOrders(ctx context.Context, gameID, power string) (json.RawM
 	return json.RawMessage(data), nil
 }
 
+// orderUpdateAttempts bounds how often UpdateOrders retries when another
+// writer changes a power's orders under it.
+const orderUpdateAttempts = 5
+
+// UpdateOrders applies update to a power's orders, passing nil if it has
+// none. The read and write run in a WATCH transaction, retried if another
+// writer got in between, so concurrent edits of single orders are not lost.
+// update may be called more than once.
+func (c *Client) UpdateOrders(ctx context.Context, gameID, power string, update func(orders json.RawMessage) (json.RawMessage, error)) error {
+	key := ordersKey(gameID, power)
+	txf := func(tx *redis.Tx) error {
+		data, err := tx.Get(ctx, key).Bytes()
+		if err != nil && err != redis.Nil {
+			return err
+		}
+		next, err := update(data)
+		if err != nil {
+			return err
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, []byte(next), 0)
+			return nil
+		})
+		return err
+	}
+	for range orderUpdateAttempts {
+		err := c.rdb.Watch(ctx, txf, key)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, redis.TxFailedErr) {
+			return err
+		}
+	}
+	return fmt.Errorf("update orders: %w", redis.TxFailedErr)
+}
+
 // GetAllOrders retrieves orders from all powers that have submitted.
 func (c *Client) GetAllOrders(ctx context.Context, gameID string, powers []string) (map[string]json.RawMessage, error) {
 	result := make(map[string]json.RawMessage)
@@ -105,6 +145,60 @@ func (c *Client) ClearTimer(ctx context.Context, gameID string) error {
 	return c.rdb.Del(ctx, timerKey(gameID)).Err()
 }
 
+// PauseTimer suspends a game's timer, storing the time left until its
+// deadline. It reports false if the game had no running timer.
+func (c *Client) PauseTimer(ctx context.Context, gameID string) (time.Duration, bool, error) {
+	unix, err := c.rdb.Get(ctx, timerKey(gameID)).Int64()
+	if err == redis.Nil {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("get timer: %w", err)
+	}
+	remaining := max(time.Until(time.Unix(unix, 0)), 0)
+	if _, err := c.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, pausedKey(gameID), remaining.Milliseconds(), 0)
+		pipe.Del(ctx, timerKey(gameID))
+		return nil
+	}); err != nil {
+		return 0, false, fmt.Errorf("pause timer: %w", err)
+	}
+	return remaining, true, nil
+}
+
+// ResumeTimer removes and returns the time left stored by PauseTimer. It
+// reports false if none was stored. The caller restarts the timer.
+func (c *Client) ResumeTimer(ctx context.Context, gameID string) (time.Duration, bool, error) {
+	ms, err := c.rdb.GetDel(ctx, pausedKey(gameID)).Int64()
+	if err == redis.Nil {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("resume timer: %w", err)
+	}
+	return time.Duration(ms) * time.Millisecond, true, nil
+}
+
+// SetQuorumDeadline stores the phase deadline that a ready quorum pulled
+// forward, so it can be restored if the quorum breaks. It reports false,
+// keeping the first stored deadline, if the quorum was already reached.
+func (c *Client) SetQuorumDeadline(ctx context.Context, gameID string, original time.Time) (bool, error) {
+	return c.rdb.SetNX(ctx, quorumKey(gameID), original.Unix(), 0).Result()
+}
+
+// ClearQuorumDeadline removes and returns the deadline stored by
+// SetQuorumDeadline. It reports false if none was stored.
+func (c *Client) ClearQuorumDeadline(ctx context.Context, gameID string) (time.Time, bool, error) {
+	unix, err := c.rdb.GetDel(ctx, quorumKey(gameID)).Int64()
+	if err == redis.Nil {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("clear quorum deadline: %w", err)
+	}
+	return time.Unix(unix, 0), true, nil
+}
+
 // AddDrawVote adds a power to the draw vote set.
 func (c *Client) AddDrawVote(ctx context.Context, gameID, power string) error {
 	return c.rdb.SAdd(ctx, drawVoteKey(gameID), power).Err()
@@ -125,21 +219,36 @@ func (c *Client) DrawVotePowers(ctx context.Context, gameID string) ([]string, e
 	return c.rdb.SMembers(ctx, drawVoteKey(gameID)).Result()
 }
 
-// ClearPhaseData removes all orders, ready status, and timer for a game.
-// Called after phase resolution to prepare for the next phase.
+// ClearPhaseData removes all orders, ready status, and timers for a game.
+// Called after phase resolution to prepare for the next phase. Bot alliances
+// span phases and are left for DeleteGameData.
 func (c *Client) ClearPhaseData(ctx context.Context, gameID string, powers []string) error {
-	keys := []string{readyKey(gameID), timerKey(gameID), drawVoteKey(gameID)}
+	keys := []string{readyKey(gameID), timerKey(gameID), drawVoteKey(gameID), quorumKey(gameID)}
 	for _, power := range powers {
 		keys = append(keys, ordersKey(gameID, power))
 	}
 	return c.rdb.Del(ctx, keys...).Err()
 }
 
+// ClearPowerData removes one power's orders, ready flag and draw vote,
+// leaving the rest of the phase alone. Called when a power changes hands.
+func (c *Client) ClearPowerData(ctx context.Context, gameID, power string) error {
+	if _, err := c.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, ordersKey(gameID, power))
+		pipe.SRem(ctx, readyKey(gameID), power)
+		pipe.SRem(ctx, drawVoteKey(gameID), power)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("clear power data: %w", err)
+	}
+	return nil
+}
+
 // DeleteGameData removes all Redis data for a game (on game end).
 func (c *Client) DeleteGameData(ctx context.Context, gameID string, powers []string) error {
-	keys := []string{stateKey(gameID), readyKey(gameID), timerKey(gameID), drawVoteKey(gameID)}
+	keys := []string{stateKey(gameID), readyKey(gameID), timerKey(gameID), drawVoteKey(gameID), flagsKey(gameID), pausedKey(gameID), quorumKey(gameID)}
 	for _, power := range powers {
-		keys = append(keys, ordersKey(gameID, power))
+		keys = append(keys, ordersKey(gameID, power), alliancesKey(gameID, power))
 	}
 	return c.rdb.Del(ctx, keys...).Err()
 }
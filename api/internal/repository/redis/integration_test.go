@@ -5,11 +5,15 @@ package redis
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
 	goredis "github.com/redis/go-redis/v9"
 
+	"github.com/freeeve/polite-betrayal/api/internal/repository"
 	"github.com/freeeve/polite-betrayal/api/internal/testutil"
 )
 
@@ -181,6 +185,32 @@ func TestTimerWithTTL(t *testing.T) {
 	}
 }
 
+func TestPauseResumeTimer(t *testing.T) {
+	c := setup(t)
+	ctx := context.Background()
+	gameID := "test-game-pause"
+
+	if _, ok, err := c.PauseTimer(ctx, gameID); ok || err != nil {
+		t.Fatalf("expected no timer to pause, got ok=%v err=%v", ok, err)
+	}
+	c.SetTimer(ctx, gameID, time.Now().Add(time.Hour))
+	remaining, ok, err := c.PauseTimer(ctx, gameID)
+	if err != nil || !ok || remaining < 59*time.Minute || remaining > time.Hour {
+		t.Fatalf("expected ~1h left, got %v ok=%v err=%v", remaining, ok, err)
+	}
+	if testRDB.Exists(ctx, timerKey(gameID)).Val() != 0 {
+		t.Fatal("expected the timer key to be removed while paused")
+	}
+
+	resumed, ok, err := c.ResumeTimer(ctx, gameID)
+	if err != nil || !ok || resumed != remaining.Truncate(time.Millisecond) {
+		t.Fatalf("expected %v back, got %v ok=%v err=%v", remaining, resumed, ok, err)
+	}
+	if _, ok, _ := c.ResumeTimer(ctx, gameID); ok {
+		t.Fatal("expected the stored time to be consumed")
+	}
+}
+
 func TestTimerPastDeadline(t *testing.T) {
 	c := setup(t)
 	ctx := context.Background()
@@ -236,6 +266,39 @@ func TestClearPhaseData(t *testing.T) {
 	}
 }
 
+func TestClearPowerData(t *testing.T) {
+	c := setup(t)
+	ctx := context.Background()
+	gameID := "test-game-power"
+
+	for _, power := range []string{"france", "germany"} {
+		c.SetOrders(ctx, gameID, power, json.RawMessage(`[]`))
+		c.MarkReady(ctx, gameID, power)
+		c.AddDrawVote(ctx, gameID, power)
+	}
+	c.SetTimer(ctx, gameID, time.Now().Add(10*time.Second))
+
+	if err := c.ClearPowerData(ctx, gameID, "france"); err != nil {
+		t.Fatalf("clear power data: %v", err)
+	}
+
+	if fr, _ := c.GetOrders(ctx, gameID, "france"); fr != nil {
+		t.Fatal("expected france orders cleared")
+	}
+	if ready, _ := c.ReadyPowers(ctx, gameID); len(ready) != 1 || ready[0] != "germany" {
+		t.Fatalf("expected only germany ready, got %v", ready)
+	}
+	if votes, _ := c.DrawVotePowers(ctx, gameID); len(votes) != 1 || votes[0] != "germany" {
+		t.Fatalf("expected only germany's draw vote, got %v", votes)
+	}
+	if de, _ := c.GetOrders(ctx, gameID, "germany"); de == nil {
+		t.Fatal("expected germany orders kept")
+	}
+	if testRDB.Exists(ctx, timerKey(gameID)).Val() == 0 {
+		t.Fatal("expected the timer kept")
+	}
+}
+
 func TestDeleteGameData(t *testing.T) {
 	c := setup(t)
 	ctx := context.Background()
@@ -265,3 +328,253 @@ func TestDeleteGameData(t *testing.T) {
 		t.Fatal("expected ready deleted")
 	}
 }
+
+func TestFeatureFlags(t *testing.T) {
+	c := setup(t)
+	ctx := context.Background()
+	gameID := "test-game-8"
+
+	if err := c.SetGameFlag(ctx, gameID, "fog", true); err != nil {
+		t.Fatalf("set flag: %v", err)
+	}
+	c.SetGameFlag(ctx, gameID, "reveal_delay", false)
+	flags, err := c.GameFlags(ctx, gameID)
+	if err != nil {
+		t.Fatalf("get flags: %v", err)
+	}
+	if !flags["fog"] || flags["reveal_delay"] {
+		t.Fatalf("unexpected flags: %v", flags)
+	}
+
+	c.ClearGameFlag(ctx, gameID, "fog")
+	flags, _ = c.GameFlags(ctx, gameID)
+	if _, ok := flags["fog"]; ok {
+		t.Fatal("expected fog override cleared")
+	}
+
+	if err := c.SetFlagRollout(ctx, "fog", 25); err != nil {
+		t.Fatalf("set rollout: %v", err)
+	}
+	rollouts, _ := c.FlagRollouts(ctx)
+	if rollouts["fog"] != 25 {
+		t.Fatalf("expected fog rollout 25, got %d", rollouts["fog"])
+	}
+
+	c.DeleteGameData(ctx, gameID, nil)
+	flags, _ = c.GameFlags(ctx, gameID)
+	if len(flags) != 0 {
+		t.Fatalf("expected flags deleted with game data, got %v", flags)
+	}
+}
+
+func TestViewers(t *testing.T) {
+	c := setup(t)
+	ctx := context.Background()
+	start := time.Now().Add(-time.Second)
+	active := time.Now()
+
+	if err := c.ReportViewers(ctx, "server-a", map[string]int{"g1": 3, "g2": 1}, map[string]time.Time{"g1": active}); err != nil {
+		t.Fatalf("report: %v", err)
+	}
+	c.ReportViewers(ctx, "server-b", map[string]int{"g1": 2}, nil)
+	viewers, err := c.LiveViewers(ctx, start)
+	if err != nil {
+		t.Fatalf("live viewers: %v", err)
+	}
+	if viewers["g1"] != 5 || viewers["g2"] != 1 {
+		t.Fatalf("expected counts summed over servers, got %v", viewers)
+	}
+
+	c.ReportViewers(ctx, "server-a", map[string]int{"g2": 0}, nil)
+	viewers, _ = c.LiveViewers(ctx, start)
+	if _, ok := viewers["g2"]; ok {
+		t.Fatalf("expected g2 cleared, got %v", viewers)
+	}
+
+	activity, err := c.RecentActivity(ctx, start)
+	if err != nil {
+		t.Fatalf("recent activity: %v", err)
+	}
+	if activity["g1"].Unix() != active.Unix() {
+		t.Fatalf("expected g1 active at %v, got %v", active, activity)
+	}
+}
+
+func TestGameEventLog(t *testing.T) {
+	c := setup(t)
+	ctx := context.Background()
+
+	for i := 1; i <= 3; i++ {
+		seq, err := c.NextEventSeq(ctx, "g1")
+		if err != nil || seq != int64(i) {
+			t.Fatalf("expected seq %d, got %d, %v", i, seq, err)
+		}
+		if err := c.AppendGameEvent(ctx, "g1", seq, []byte(fmt.Sprintf(`{"seq":%d}`, seq))); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+
+	events, latest, err := c.GameEventsSince(ctx, "g1", 1)
+	if err != nil || latest != 3 || len(events) != 2 || string(events[0]) != `{"seq":2}` {
+		t.Fatalf("expected events 2 and 3, got %q, %d, %v", events, latest, err)
+	}
+	if events, _, err := c.GameEventsSince(ctx, "g1", 3); err != nil || len(events) != 0 {
+		t.Fatalf("expected nothing after the latest event, got %q, %v", events, err)
+	}
+	if _, _, err := c.GameEventsSince(ctx, "g1", 7); !errors.Is(err, repository.ErrEventsExpired) {
+		t.Fatalf("expected a seq ahead of the counter to need a resync, got %v", err)
+	}
+
+	c.rdb.Del(ctx, eventLogKey("g1"))
+	if _, latest, err := c.GameEventsSince(ctx, "g1", 1); !errors.Is(err, repository.ErrEventsExpired) || latest != 3 {
+		t.Fatalf("expected an expired log to need a resync, got %d, %v", latest, err)
+	}
+}
+
+func TestLeases(t *testing.T) {
+	c := setup(t)
+	ctx := context.Background()
+
+	if ok, err := c.AcquireLease(ctx, "game:g1", "a", time.Minute); err != nil || !ok {
+		t.Fatalf("expected a to acquire the lease, got %v, %v", ok, err)
+	}
+	if ok, err := c.AcquireLease(ctx, "game:g1", "b", time.Minute); err != nil || ok {
+		t.Fatalf("expected b to be refused a held lease, got %v, %v", ok, err)
+	}
+	if ok, err := c.RenewLease(ctx, "game:g1", "b", time.Minute); err != nil || ok {
+		t.Fatalf("expected b not to renew a's lease, got %v, %v", ok, err)
+	}
+	if ok, err := c.RenewLease(ctx, "game:g1", "a", time.Minute); err != nil || !ok {
+		t.Fatalf("expected a to renew its lease, got %v, %v", ok, err)
+	}
+
+	// Only the owner can release it.
+	if err := c.ReleaseLease(ctx, "game:g1", "b"); err != nil {
+		t.Fatalf("release by b: %v", err)
+	}
+	if ok, _ := c.AcquireLease(ctx, "game:g1", "b", time.Minute); ok {
+		t.Fatal("expected the lease to survive a release by b")
+	}
+	if err := c.ReleaseLease(ctx, "game:g1", "a"); err != nil {
+		t.Fatalf("release by a: %v", err)
+	}
+	if ok, err := c.AcquireLease(ctx, "game:g1", "b", time.Minute); err != nil || !ok {
+		t.Fatalf("expected b to acquire the released lease, got %v, %v", ok, err)
+	}
+}
+
+func TestPhaseEvaluations(t *testing.T) {
+	c := setup(t)
+	ctx := context.Background()
+
+	if data, err := c.PhaseEvaluations(ctx, "phase-1", "before"); err != nil || data != nil {
+		t.Fatalf("expected a miss, got %s, %v", data, err)
+	}
+	if err := c.SetPhaseEvaluations(ctx, "phase-1", "before", []byte(`[{"power":"france"}]`)); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	data, err := c.PhaseEvaluations(ctx, "phase-1", "before")
+	if err != nil || string(data) != `[{"power":"france"}]` {
+		t.Errorf("expected the cached evaluations, got %s, %v", data, err)
+	}
+	if data, _ := c.PhaseEvaluations(ctx, "phase-1", "after"); data != nil {
+		t.Errorf("expected stages to be cached apart, got %s", data)
+	}
+}
+
+func TestBotAlliances(t *testing.T) {
+	c := setup(t)
+	ctx := context.Background()
+
+	if data, err := c.BotAlliances(ctx, "game-1", "france"); err != nil || data != nil {
+		t.Fatalf("expected a miss, got %s, %v", data, err)
+	}
+	if err := c.SetBotAlliances(ctx, "game-1", "france", []byte(`{"pacts":{"germany":{"year":1902}}}`)); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if data, err := c.BotAlliances(ctx, "game-1", "france"); err != nil || string(data) != `{"pacts":{"germany":{"year":1902}}}` {
+		t.Errorf("expected the saved state, got %s, %v", data, err)
+	}
+	if err := c.ClearPhaseData(ctx, "game-1", []string{"france"}); err != nil {
+		t.Fatalf("clear phase: %v", err)
+	}
+	if data, _ := c.BotAlliances(ctx, "game-1", "france"); data == nil {
+		t.Error("expected alliances to survive the end of a phase")
+	}
+	if err := c.DeleteGameData(ctx, "game-1", []string{"france"}); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if data, _ := c.BotAlliances(ctx, "game-1", "france"); data != nil {
+		t.Errorf("expected game deletion to drop alliances, got %s", data)
+	}
+}
+
+func TestUpdateBotAlliancesConcurrent(t *testing.T) {
+	c := setup(t)
+	ctx := context.Background()
+
+	const writers = 20
+	var wg sync.WaitGroup
+	for range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				err := c.UpdateBotAlliances(ctx, "game-1", "france", func(data []byte) ([]byte, error) {
+					n := 0
+					if data != nil {
+						fmt.Sscan(string(data), &n)
+					}
+					return []byte(fmt.Sprint(n + 1)), nil
+				})
+				if !errors.Is(err, goredis.TxFailedErr) {
+					if err != nil {
+						t.Errorf("update: %v", err)
+					}
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if data, _ := c.BotAlliances(ctx, "game-1", "france"); string(data) != fmt.Sprint(writers) {
+		t.Errorf("expected %d updates to be kept, got %s", writers, data)
+	}
+}
+
+func TestUpdateOrdersConcurrent(t *testing.T) {
+	c := setup(t)
+	ctx := context.Background()
+
+	// Each writer adds its own order to the set, as saving single orders does.
+	const writers = 20
+	var wg sync.WaitGroup
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				err := c.UpdateOrders(ctx, "game-1", "france", func(orders json.RawMessage) (json.RawMessage, error) {
+					var set []int
+					if orders != nil {
+						json.Unmarshal(orders, &set)
+					}
+					return json.Marshal(append(set, i))
+				})
+				if !errors.Is(err, goredis.TxFailedErr) {
+					if err != nil {
+						t.Errorf("update: %v", err)
+					}
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	raw, _ := c.GetOrders(ctx, "game-1", "france")
+	var set []int
+	json.Unmarshal(raw, &set)
+	if len(set) != writers {
+		t.Errorf("expected %d orders to be kept, got %s", writers, raw)
+	}
+}
@@ -0,0 +1,57 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Leases live under their own prefix so their expiry is never mistaken for a
+// game timer's.
+func leaseKey(key string) string { return "lease:" + key }
+
+// Renewing and releasing check the owner in the same step, so an instance
+// whose lease has already passed to another can't extend or drop it.
+var (
+	renewLeaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+	releaseLeaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+)
+
+// AcquireLease takes the lease on key for owner for ttl, reporting false if
+// another owner holds it.
+func (c *Client) AcquireLease(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	ok, err := c.rdb.SetNX(ctx, leaseKey(key), owner, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("acquire lease: %w", err)
+	}
+	return ok, nil
+}
+
+// RenewLease extends owner's lease on key to ttl from now, reporting false if
+// owner no longer holds it.
+func (c *Client) RenewLease(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	n, err := renewLeaseScript.Run(ctx, c.rdb, []string{leaseKey(key)}, owner, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, fmt.Errorf("renew lease: %w", err)
+	}
+	return n == 1, nil
+}
+
+// ReleaseLease gives up owner's lease on key; it does nothing if owner no
+// longer holds it.
+func (c *Client) ReleaseLease(ctx context.Context, key, owner string) error {
+	if err := releaseLeaseScript.Run(ctx, c.rdb, []string{leaseKey(key)}, owner).Err(); err != nil {
+		return fmt.Errorf("release lease: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+)
+
+// eventRelayChannel is the pub/sub channel WebSocket events are relayed on.
+const eventRelayChannel = "ws:events"
+
+// PublishEvent sends a relayed event to every subscribed server instance.
+func (c *Client) PublishEvent(ctx context.Context, msg []byte) error {
+	if err := c.rdb.Publish(ctx, eventRelayChannel, msg).Err(); err != nil {
+		return fmt.Errorf("publish event: %w", err)
+	}
+	return nil
+}
+
+// SubscribeEvents returns the events published by every server instance,
+// until ctx is done. The subscription is in place when it returns.
+func (c *Client) SubscribeEvents(ctx context.Context) (<-chan []byte, error) {
+	pubsub := c.rdb.Subscribe(ctx, eventRelayChannel)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, fmt.Errorf("subscribe events: %w", err)
+	}
+	out := make(chan []byte, 256)
+	go func() {
+		defer close(out)
+		defer pubsub.Close()
+		ch := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+				select {
+				case out <- []byte(msg.Payload):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out, nil
+}
@@ -0,0 +1,111 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Keys for live viewer counts. Each server instance reports the viewers
+// connected to it per game; counts from instances that stop reporting are
+// ignored once their heartbeat is stale.
+func viewersKey(gameID string) string { return "game:" + gameID + ":viewers" } // instance -> viewers
+
+const (
+	viewerHeartbeatKey = "viewers:heartbeat" // instance -> last report
+	liveGamesKey       = "viewers:games"     // game -> last report with viewers
+	gameActivityKey    = "games:activity"    // game -> last event
+)
+
+// viewerRetention bounds how long idle entries stay in the index sets.
+const viewerRetention = 24 * time.Hour
+
+// ReportViewers records this instance's viewer count for each game, zero
+// clearing it, and the time each game last had activity.
+func (c *Client) ReportViewers(ctx context.Context, instanceID string, counts map[string]int, activity map[string]time.Time) error {
+	now := time.Now()
+	pipe := c.rdb.TxPipeline()
+	for gameID, n := range counts {
+		if n <= 0 {
+			pipe.ZRem(ctx, viewersKey(gameID), instanceID)
+			continue
+		}
+		pipe.ZAdd(ctx, viewersKey(gameID), redis.Z{Score: float64(n), Member: instanceID})
+		pipe.Expire(ctx, viewersKey(gameID), viewerRetention)
+		pipe.ZAdd(ctx, liveGamesKey, redis.Z{Score: float64(now.Unix()), Member: gameID})
+	}
+	for gameID, t := range activity {
+		pipe.ZAddGT(ctx, gameActivityKey, redis.Z{Score: float64(t.Unix()), Member: gameID})
+	}
+	pipe.ZAdd(ctx, viewerHeartbeatKey, redis.Z{Score: float64(now.Unix()), Member: instanceID})
+
+	cutoff := strconv.FormatInt(now.Add(-viewerRetention).Unix(), 10)
+	for _, key := range []string{viewerHeartbeatKey, liveGamesKey, gameActivityKey} {
+		pipe.ZRemRangeByScore(ctx, key, "-inf", "("+cutoff)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("report viewers: %w", err)
+	}
+	return nil
+}
+
+// LiveViewers returns the viewers of every game, summed over the instances
+// that have reported since the given time.
+func (c *Client) LiveViewers(ctx context.Context, since time.Time) (map[string]int, error) {
+	from := strconv.FormatInt(since.Unix(), 10)
+	instances, err := c.rdb.ZRangeByScore(ctx, viewerHeartbeatKey, &redis.ZRangeBy{Min: from, Max: "+inf"}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("list viewer instances: %w", err)
+	}
+	fresh := make(map[string]bool, len(instances))
+	for _, id := range instances {
+		fresh[id] = true
+	}
+	games, err := c.rdb.ZRangeByScore(ctx, liveGamesKey, &redis.ZRangeBy{Min: from, Max: "+inf"}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("list watched games: %w", err)
+	}
+
+	pipe := c.rdb.Pipeline()
+	cmds := make([]*redis.ZSliceCmd, len(games))
+	for i, gameID := range games {
+		cmds[i] = pipe.ZRangeWithScores(ctx, viewersKey(gameID), 0, -1)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("get viewers: %w", err)
+	}
+	viewers := make(map[string]int, len(games))
+	for i, gameID := range games {
+		for _, z := range cmds[i].Val() {
+			if instance, _ := z.Member.(string); fresh[instance] {
+				viewers[gameID] += int(z.Score)
+			}
+		}
+		if viewers[gameID] == 0 {
+			delete(viewers, gameID)
+		}
+	}
+	return viewers, nil
+}
+
+// RecentActivity returns the games with activity since the given time and
+// when each was last active.
+func (c *Client) RecentActivity(ctx context.Context, since time.Time) (map[string]time.Time, error) {
+	zs, err := c.rdb.ZRangeByScoreWithScores(ctx, gameActivityKey, &redis.ZRangeBy{
+		Min: strconv.FormatInt(since.Unix(), 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("get game activity: %w", err)
+	}
+	activity := make(map[string]time.Time, len(zs))
+	for _, z := range zs {
+		if gameID, ok := z.Member.(string); ok {
+			activity[gameID] = time.Unix(int64(z.Score), 0)
+		}
+	}
+	return activity, nil
+}
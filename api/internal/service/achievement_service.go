@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/internal/repository"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// earlySoloYear is the last year in which a solo earns AchievementEarlySolo.
+const earlySoloYear = 1907
+
+// DefaultHallOfFameSize is how many entries each hall-of-fame list holds.
+const DefaultHallOfFameSize = 10
+
+// AchievementService awards achievements when games end and serves user
+// stats and the hall of fame.
+type AchievementService struct {
+	gameRepo        repository.GameRepository
+	phaseRepo       repository.PhaseRepository
+	achievementRepo repository.AchievementRepository
+}
+
+// NewAchievementService creates an AchievementService.
+func NewAchievementService(gameRepo repository.GameRepository, phaseRepo repository.PhaseRepository, achievementRepo repository.AchievementRepository) *AchievementService {
+	return &AchievementService{gameRepo: gameRepo, phaseRepo: phaseRepo, achievementRepo: achievementRepo}
+}
+
+// EvaluateGame awards achievements to the human players of a finished game,
+// judged from the final stored phase, and records solos for the hall of fame.
+// It returns the achievements awarded. Re-evaluating a game awards nothing new.
+func (s *AchievementService) EvaluateGame(ctx context.Context, gameID string) ([]model.Achievement, error) {
+	game, err := s.gameRepo.FindByID(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	if game == nil {
+		return nil, ErrGameNotFound
+	}
+	if game.Status != "finished" {
+		return nil, nil
+	}
+
+	final, gs, err := finalState(ctx, s.phaseRepo, gameID)
+	if err != nil || final == nil {
+		return nil, err
+	}
+
+	var awarded []model.Achievement
+	for _, p := range game.Players {
+		if p.IsBot || p.Power == "" {
+			continue
+		}
+		power := diplomacy.Power(p.Power)
+		scs := gs.SupplyCenterCount(power)
+		base := model.Achievement{UserID: p.UserID, GameID: gameID, Power: p.Power, Year: final.Year, SupplyCenters: scs}
+
+		var kinds []string
+		switch {
+		case game.Winner == p.Power:
+			if err := s.achievementRepo.SaveRecord(ctx, model.GameRecord{
+				GameID: gameID, WinnerID: p.UserID, Power: p.Power, Year: final.Year, SupplyCenters: scs,
+			}); err != nil {
+				return awarded, err
+			}
+			first, err := s.isFirstSolo(ctx, p.UserID, gameID)
+			if err != nil {
+				return awarded, err
+			}
+			if first {
+				kinds = append(kinds, model.AchievementFirstSolo)
+			}
+			if final.Year <= earlySoloYear {
+				kinds = append(kinds, model.AchievementEarlySolo)
+			}
+			if power == diplomacy.Austria {
+				kinds = append(kinds, model.AchievementAustrianSolo)
+			}
+		case game.Winner == "" && scs == 1:
+			kinds = append(kinds, model.AchievementLastStandDraw)
+		}
+
+		for _, kind := range kinds {
+			a := base
+			a.Kind = kind
+			if err := s.achievementRepo.Award(ctx, a); err != nil {
+				return awarded, err
+			}
+			awarded = append(awarded, a)
+		}
+	}
+	return awarded, nil
+}
+
+// finalState returns the last stored phase of a game and the position it
+// ended in: the resolved state if the phase was resolved, otherwise the state
+// it started from (e.g. a game ended by draw vote mid-phase).
+func finalState(ctx context.Context, phaseRepo repository.PhaseRepository, gameID string) (*model.Phase, *diplomacy.GameState, error) {
+	phases, err := phaseRepo.ListPhases(ctx, gameID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(phases) == 0 {
+		return nil, nil, nil
+	}
+	final := &phases[len(phases)-1]
+	raw := final.StateAfter
+	if len(raw) == 0 {
+		raw = final.StateBefore
+	}
+	var gs diplomacy.GameState
+	if err := json.Unmarshal(raw, &gs); err != nil {
+		return nil, nil, fmt.Errorf("unmarshal final state: %w", err)
+	}
+	return final, &gs, nil
+}
+
+// isFirstSolo reports whether the user has no first-solo achievement from
+// another game.
+func (s *AchievementService) isFirstSolo(ctx context.Context, userID, gameID string) (bool, error) {
+	existing, err := s.achievementRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	for _, a := range existing {
+		if a.Kind == model.AchievementFirstSolo && a.GameID != gameID {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// UserStats summarizes all of a user's started games and lists their
+// achievements.
+func (s *AchievementService) UserStats(ctx context.Context, userID string) (*model.UserStats, error) {
+	stats, err := s.gameRepo.CountUserGames(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	stats.Achievements = []model.Achievement{}
+
+	achievements, err := s.achievementRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if achievements != nil {
+		stats.Achievements = achievements
+	}
+	return stats, nil
+}
+
+// HallOfFame returns the fastest solos and the most decorated players.
+func (s *AchievementService) HallOfFame(ctx context.Context, limit int) (*model.HallOfFame, error) {
+	if limit <= 0 {
+		limit = DefaultHallOfFameSize
+	}
+	solos, err := s.achievementRepo.FastestSolos(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	achievers, err := s.achievementRepo.TopAchievers(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	hof := &model.HallOfFame{FastestSolos: solos, TopAchievers: achievers}
+	if hof.FastestSolos == nil {
+		hof.FastestSolos = []model.GameRecord{}
+	}
+	if hof.TopAchievers == nil {
+		hof.TopAchievers = []model.AchieverCount{}
+	}
+	return hof, nil
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// finishGame stores a final resolved phase in which owner holds scs supply
+// centers and every other power holds one, then marks the game finished.
+func finishGame(t *testing.T, gameRepo *mockGameRepo, phaseRepo *mockPhaseRepo, gameID string, year int, owner diplomacy.Power, scs int, winner string) {
+	t.Helper()
+	ctx := context.Background()
+	gs := diplomacy.NewInitialState()
+	gs.Year = year
+	gs.SupplyCenters = make(map[string]diplomacy.Power)
+	var provs []string
+	for prov := range diplomacy.StandardMap().Provinces {
+		if diplomacy.StandardMap().Provinces[prov].IsSupplyCenter {
+			provs = append(provs, prov)
+		}
+	}
+	i := 0
+	for ; i < scs; i++ {
+		gs.SupplyCenters[provs[i]] = owner
+	}
+	for _, p := range diplomacy.AllPowers() {
+		if p != owner {
+			gs.SupplyCenters[provs[i]] = p
+			i++
+		}
+	}
+	state, _ := json.Marshal(gs)
+	phase, _ := phaseRepo.CreatePhase(ctx, gameID, year, "fall", "movement", state, time.Now())
+	phaseRepo.ResolvePhase(ctx, phase.ID, state)
+	gameRepo.SetFinished(ctx, gameID, winner)
+}
+
+func userForPower(gameRepo *mockGameRepo, gameID string, power diplomacy.Power) string {
+	for _, p := range gameRepo.players[gameID] {
+		if p.Power == string(power) {
+			return p.UserID
+		}
+	}
+	return ""
+}
+
+func kindsOf(achievements []model.Achievement) map[string]bool {
+	kinds := make(map[string]bool)
+	for _, a := range achievements {
+		kinds[a.Kind] = true
+	}
+	return kinds
+}
+
+func TestEvaluateGameAustrianEarlySolo(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	achRepo := newMockAchievementRepo()
+	svc := NewAchievementService(gameRepo, phaseRepo, achRepo)
+	ctx := context.Background()
+
+	gameID, _ := setupActiveGame(t, gameRepo, phaseRepo, newMockCache())
+	finishGame(t, gameRepo, phaseRepo, gameID, 1906, diplomacy.Austria, 18, "austria")
+
+	awarded, err := svc.EvaluateGame(ctx, gameID)
+	if err != nil {
+		t.Fatalf("EvaluateGame: %v", err)
+	}
+	kinds := kindsOf(awarded)
+	for _, want := range []string{model.AchievementFirstSolo, model.AchievementEarlySolo, model.AchievementAustrianSolo} {
+		if !kinds[want] {
+			t.Errorf("expected %s, got %v", want, kinds)
+		}
+	}
+	winner := userForPower(gameRepo, gameID, diplomacy.Austria)
+	for _, a := range awarded {
+		if a.UserID != winner || a.Year != 1906 || a.SupplyCenters != 18 {
+			t.Errorf("unexpected achievement %+v", a)
+		}
+	}
+
+	hof, _ := svc.HallOfFame(ctx, 0)
+	if len(hof.FastestSolos) != 1 || hof.FastestSolos[0].WinnerID != winner {
+		t.Errorf("expected the solo in the hall of fame, got %+v", hof.FastestSolos)
+	}
+	if len(hof.TopAchievers) != 1 || hof.TopAchievers[0].Achievements != 3 {
+		t.Errorf("expected one achiever with 3 achievements, got %+v", hof.TopAchievers)
+	}
+
+	// Re-evaluating stores nothing new.
+	svc.EvaluateGame(ctx, gameID)
+	if len(achRepo.achievements) != 3 {
+		t.Errorf("expected 3 stored achievements after re-evaluation, got %d", len(achRepo.achievements))
+	}
+}
+
+func TestEvaluateGameFirstSoloOnlyOnce(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	achRepo := newMockAchievementRepo()
+	svc := NewAchievementService(gameRepo, phaseRepo, achRepo)
+	ctx := context.Background()
+
+	first, _ := setupActiveGame(t, gameRepo, phaseRepo, newMockCache())
+	finishGame(t, gameRepo, phaseRepo, first, 1910, diplomacy.France, 18, "france")
+	winner := userForPower(gameRepo, first, diplomacy.France)
+	achRepo.Award(ctx, model.Achievement{UserID: winner, Kind: model.AchievementFirstSolo, GameID: "earlier-game"})
+
+	awarded, err := svc.EvaluateGame(ctx, first)
+	if err != nil {
+		t.Fatalf("EvaluateGame: %v", err)
+	}
+	if len(awarded) != 0 {
+		t.Errorf("late solo by a previous winner should earn nothing, got %+v", awarded)
+	}
+}
+
+func TestEvaluateGameLastStandDraw(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	achRepo := newMockAchievementRepo()
+	svc := NewAchievementService(gameRepo, phaseRepo, achRepo)
+	ctx := context.Background()
+
+	gameID, _ := setupActiveGame(t, gameRepo, phaseRepo, newMockCache())
+	finishGame(t, gameRepo, phaseRepo, gameID, 1915, diplomacy.Russia, 10, "")
+
+	awarded, err := svc.EvaluateGame(ctx, gameID)
+	if err != nil {
+		t.Fatalf("EvaluateGame: %v", err)
+	}
+	// Every power other than Russia ends the draw on one SC.
+	if len(awarded) != 6 {
+		t.Fatalf("expected 6 last-stand achievements, got %d", len(awarded))
+	}
+	for _, a := range awarded {
+		if a.Kind != model.AchievementLastStandDraw || a.Power == string(diplomacy.Russia) {
+			t.Errorf("unexpected achievement %+v", a)
+		}
+	}
+}
+
+func TestEvaluateGameNotFinished(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	svc := NewAchievementService(gameRepo, phaseRepo, newMockAchievementRepo())
+
+	gameID, _ := setupActiveGame(t, gameRepo, phaseRepo, newMockCache())
+	awarded, err := svc.EvaluateGame(context.Background(), gameID)
+	if err != nil || awarded != nil {
+		t.Errorf("active game should award nothing, got %v, %v", awarded, err)
+	}
+}
+
+func TestUserStats(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	achRepo := newMockAchievementRepo()
+	svc := NewAchievementService(gameRepo, phaseRepo, achRepo)
+	ctx := context.Background()
+
+	won, _ := setupActiveGame(t, gameRepo, phaseRepo, newMockCache())
+	winnerPower := diplomacy.Power(gameRepo.players[won][0].Power)
+	finishGame(t, gameRepo, phaseRepo, won, 1908, winnerPower, 18, string(winnerPower))
+	svc.EvaluateGame(ctx, won)
+
+	drawn, _ := setupActiveGame(t, gameRepo, phaseRepo, newMockCache())
+	finishGame(t, gameRepo, phaseRepo, drawn, 1912, diplomacy.Turkey, 10, "")
+
+	setupActiveGame(t, gameRepo, phaseRepo, newMockCache())
+
+	stats, err := svc.UserStats(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("UserStats: %v", err)
+	}
+	if stats.GamesPlayed != 3 || stats.GamesFinished != 2 || stats.Draws != 1 {
+		t.Errorf("unexpected counts: %+v", stats)
+	}
+	winnerID := gameRepo.players[won][0].UserID
+	if winnerID == "user-1" {
+		if stats.Solos != 1 || len(stats.Achievements) == 0 {
+			t.Errorf("expected user-1's solo and achievements, got %+v", stats)
+		}
+	} else if stats.Solos != 0 {
+		t.Errorf("user-1 did not win, got %d solos", stats.Solos)
+	}
+}
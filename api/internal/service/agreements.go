@@ -0,0 +1,165 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/freeeve/polite-betrayal/api/internal/bot"
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// AgreementKind identifies the type of accepted press deal.
+type AgreementKind string
+
+const (
+	AgreementDMZ     AgreementKind = "dmz"     // bound power must not move into the provinces
+	AgreementSupport AgreementKind = "support" // bound power must support Provinces[0] -> Provinces[1]
+)
+
+// Agreement is an accepted canned-press deal that constrains one power's orders.
+// A deal becomes binding when the recipient of a proposal replies "Agreed".
+type Agreement struct {
+	Kind      AgreementKind `json:"kind"`
+	Bound     string        `json:"bound"` // power whose orders are constrained
+	With      string        `json:"with"`  // counterparty power
+	Provinces []string      `json:"provinces"`
+	MessageID string        `json:"message_id"` // the accepted proposal
+}
+
+// acceptedAgreements derives the binding agreements from a conversation history.
+// Messages must be in chronological order. An "Agreed" reply accepts the most
+// recent pending proposal sent by the reply's recipient to its sender.
+func acceptedAgreements(messages []model.Message, powerByUser map[string]string) []Agreement {
+	type pair struct{ from, to string }
+	pending := make(map[pair]model.Message)
+	pendingIntent := make(map[pair]*bot.DiplomaticIntent)
+
+	var agreements []Agreement
+	for _, msg := range messages {
+		if msg.RecipientID == "" {
+			continue // public messages are not binding
+		}
+		intent, err := bot.IntentFromMessage(msg)
+		if err != nil {
+			continue
+		}
+		from, to := powerByUser[msg.SenderID], powerByUser[msg.RecipientID]
+		if from == "" || to == "" {
+			continue
+		}
+
+		switch intent.Type {
+		case bot.IntentAccept:
+			key := pair{from: to, to: from}
+			proposal, ok := pending[key]
+			if !ok {
+				continue
+			}
+			agreements = append(agreements, agreementsFromProposal(pendingIntent[key], proposal.ID, to, from)...)
+			delete(pending, key)
+			delete(pendingIntent, key)
+		case bot.IntentReject:
+			delete(pending, pair{from: to, to: from})
+			delete(pendingIntent, pair{from: to, to: from})
+		default:
+			pending[pair{from: from, to: to}] = msg
+			pendingIntent[pair{from: from, to: to}] = intent
+		}
+	}
+	return agreements
+}
+
+// agreementsFromProposal converts an accepted proposal into the agreements it
+// creates. The acceptor is always bound; for an even deal the proposer is too.
+func agreementsFromProposal(intent *bot.DiplomaticIntent, messageID, proposer, acceptor string) []Agreement {
+	switch intent.Type {
+	case bot.IntentProposeNonAggression:
+		if len(intent.Provinces) == 0 {
+			return nil
+		}
+		return []Agreement{{Kind: AgreementDMZ, Bound: acceptor, With: proposer, Provinces: intent.Provinces, MessageID: messageID}}
+	case bot.IntentRequestSupport:
+		if len(intent.Provinces) < 2 {
+			return nil
+		}
+		return []Agreement{{Kind: AgreementSupport, Bound: acceptor, With: proposer, Provinces: intent.Provinces[:2], MessageID: messageID}}
+	case bot.IntentOfferDeal:
+		if len(intent.Provinces) < 2 {
+			return nil
+		}
+		// "I take X, you take Y": the acceptor keeps out of X, the proposer out of Y.
+		return []Agreement{
+			{Kind: AgreementDMZ, Bound: acceptor, With: proposer, Provinces: intent.Provinces[:1], MessageID: messageID},
+			{Kind: AgreementDMZ, Bound: proposer, With: acceptor, Provinces: intent.Provinces[1:2], MessageID: messageID},
+		}
+	}
+	return nil
+}
+
+// agreementConflicts checks a power's movement orders against its agreements.
+// It returns warnings indexed like orders; conflicts never block submission.
+func agreementConflicts(orders []diplomacy.Order, agreements []Agreement, power string, gs *diplomacy.GameState, m *diplomacy.DiplomacyMap) [][]string {
+	warnings := make([][]string, len(orders))
+	for _, a := range agreements {
+		if a.Bound != power {
+			continue
+		}
+		switch a.Kind {
+		case AgreementDMZ:
+			for i, o := range orders {
+				if o.Type != diplomacy.OrderMove {
+					continue
+				}
+				for _, prov := range a.Provinces {
+					if o.Target == prov {
+						warnings[i] = append(warnings[i], fmt.Sprintf("move to %s breaks agreement with %s not to attack %s", prov, a.With, prov))
+					}
+				}
+			}
+		case AgreementSupport:
+			from, to := a.Provinces[0], a.Provinces[1]
+			if hasSupport(orders, from, to) {
+				continue
+			}
+			if i := firstCapableSupporter(orders, from, to, gs, m); i >= 0 {
+				warnings[i] = append(warnings[i], fmt.Sprintf("agreed to support %s from %s to %s, but no unit supports it", a.With, from, to))
+			}
+		}
+	}
+	return warnings
+}
+
+// hasSupport reports whether any order supports the move from -> to.
+func hasSupport(orders []diplomacy.Order, from, to string) bool {
+	for _, o := range orders {
+		if o.Type == diplomacy.OrderSupport && o.AuxLoc == from && o.AuxTarget == to {
+			return true
+		}
+	}
+	return false
+}
+
+// firstCapableSupporter returns the index of the first ordered unit that could
+// legally support from -> to, or -1 if none could.
+func firstCapableSupporter(orders []diplomacy.Order, from, to string, gs *diplomacy.GameState, m *diplomacy.DiplomacyMap) int {
+	supported := gs.UnitAt(from)
+	if supported == nil {
+		return -1
+	}
+	for i, o := range orders {
+		candidate := diplomacy.Order{
+			UnitType:    o.UnitType,
+			Power:       o.Power,
+			Location:    o.Location,
+			Coast:       o.Coast,
+			Type:        diplomacy.OrderSupport,
+			AuxLoc:      from,
+			AuxTarget:   to,
+			AuxUnitType: supported.Type,
+		}
+		if diplomacy.ValidateOrder(candidate, gs, m) == nil {
+			return i
+		}
+	}
+	return -1
+}
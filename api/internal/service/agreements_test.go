@@ -0,0 +1,103 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+var testPowerByUser = map[string]string{
+	"u-fra": "france",
+	"u-ger": "germany",
+	"u-eng": "england",
+}
+
+func TestAcceptedAgreementsDMZ(t *testing.T) {
+	messages := []model.Message{
+		{ID: "m1", SenderID: "u-fra", RecipientID: "u-ger", Content: "Please don't attack bur, I won't attack yours"},
+		{ID: "m2", SenderID: "u-ger", RecipientID: "u-fra", Content: "Agreed"},
+	}
+	got := acceptedAgreements(messages, testPowerByUser)
+	if len(got) != 1 {
+		t.Fatalf("expected 1 agreement, got %d", len(got))
+	}
+	a := got[0]
+	if a.Kind != AgreementDMZ || a.Bound != "germany" || a.With != "france" || a.Provinces[0] != "bur" || a.MessageID != "m1" {
+		t.Errorf("unexpected agreement: %+v", a)
+	}
+}
+
+func TestAcceptedAgreementsIgnoresRejectedAndPublic(t *testing.T) {
+	messages := []model.Message{
+		{ID: "m1", SenderID: "u-fra", RecipientID: "u-ger", Content: "Please don't attack bur, I won't attack yours"},
+		{ID: "m2", SenderID: "u-ger", RecipientID: "u-fra", Content: "No deal"},
+		{ID: "m3", SenderID: "u-ger", RecipientID: "u-fra", Content: "Agreed"},
+		{ID: "m4", SenderID: "u-eng", Content: "Request support from lon to nth"},
+		{ID: "m5", SenderID: "u-ger", Content: "Agreed"},
+	}
+	if got := acceptedAgreements(messages, testPowerByUser); len(got) != 0 {
+		t.Errorf("expected no agreements, got %+v", got)
+	}
+}
+
+func TestAcceptedAgreementsDealBindsBoth(t *testing.T) {
+	messages := []model.Message{
+		{ID: "m1", SenderID: "u-eng", RecipientID: "u-fra", Content: "Deal: I take bel, you take spa"},
+		{ID: "m2", SenderID: "u-fra", RecipientID: "u-eng", Content: "Agreed"},
+	}
+	got := acceptedAgreements(messages, testPowerByUser)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 agreements, got %d", len(got))
+	}
+	if got[0].Bound != "france" || got[0].Provinces[0] != "bel" {
+		t.Errorf("expected france kept out of bel, got %+v", got[0])
+	}
+	if got[1].Bound != "england" || got[1].Provinces[0] != "spa" {
+		t.Errorf("expected england kept out of spa, got %+v", got[1])
+	}
+}
+
+func TestAgreementConflictsDMZ(t *testing.T) {
+	gs := diplomacy.NewInitialState()
+	m := diplomacy.StandardMap()
+	agreements := []Agreement{{Kind: AgreementDMZ, Bound: "germany", With: "france", Provinces: []string{"bur"}}}
+	orders := []diplomacy.Order{
+		{UnitType: diplomacy.Army, Power: diplomacy.Germany, Location: "mun", Type: diplomacy.OrderMove, Target: "bur"},
+		{UnitType: diplomacy.Army, Power: diplomacy.Germany, Location: "ber", Type: diplomacy.OrderMove, Target: "kie"},
+	}
+
+	warnings := agreementConflicts(orders, agreements, "germany", gs, m)
+	if len(warnings[0]) != 1 {
+		t.Errorf("expected warning on mun-bur, got %v", warnings[0])
+	}
+	if len(warnings[1]) != 0 {
+		t.Errorf("expected no warning on ber-kie, got %v", warnings[1])
+	}
+
+	if w := agreementConflicts(orders, agreements, "france", gs, m); len(w[0]) != 0 {
+		t.Errorf("agreement should not bind france, got %v", w[0])
+	}
+}
+
+func TestAgreementConflictsSupport(t *testing.T) {
+	gs := diplomacy.NewInitialState()
+	m := diplomacy.StandardMap()
+	agreements := []Agreement{{Kind: AgreementSupport, Bound: "germany", With: "austria", Provinces: []string{"vie", "tyr"}}}
+
+	holding := []diplomacy.Order{
+		{UnitType: diplomacy.Army, Power: diplomacy.Germany, Location: "mun", Type: diplomacy.OrderHold},
+	}
+	warnings := agreementConflicts(holding, agreements, "germany", gs, m)
+	if len(warnings[0]) != 1 {
+		t.Errorf("expected broken support warning on mun, got %v", warnings[0])
+	}
+
+	supporting := []diplomacy.Order{
+		{UnitType: diplomacy.Army, Power: diplomacy.Germany, Location: "mun", Type: diplomacy.OrderSupport, AuxLoc: "vie", AuxTarget: "tyr", AuxUnitType: diplomacy.Army},
+	}
+	warnings = agreementConflicts(supporting, agreements, "germany", gs, m)
+	if len(warnings[0]) != 0 {
+		t.Errorf("expected no warning when support is given, got %v", warnings[0])
+	}
+}
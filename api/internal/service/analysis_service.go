@@ -0,0 +1,513 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/freeeve/polite-betrayal/api/internal/bot"
+	"github.com/freeeve/polite-betrayal/api/internal/jobs"
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/internal/repository"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// AnalysisService evaluates every phase of a finished game from each power's
+// perspective so replays can show an evaluation bar, and reviews the orders
+// human players gave against what a bot would have played.
+type AnalysisService struct {
+	gameRepo  repository.GameRepository
+	phaseRepo repository.PhaseRepository
+	value     bot.ValueNetwork           // optional: adds neural scores
+	cache     repository.EvaluationCache // optional: caches evaluations per phase
+	reviewer  bot.Strategy               // plays the suggested orders in reviews; hard if nil
+}
+
+// NewAnalysisService creates an AnalysisService.
+func NewAnalysisService(gameRepo repository.GameRepository, phaseRepo repository.PhaseRepository) *AnalysisService {
+	return &AnalysisService{gameRepo: gameRepo, phaseRepo: phaseRepo}
+}
+
+// SetValueNetwork configures the optional value network used for neural
+// evaluations alongside the heuristic ones.
+func (s *AnalysisService) SetValueNetwork(vn bot.ValueNetwork) {
+	s.value = vn
+}
+
+// SetEvaluationCache configures where Evaluations caches its results, so
+// each position is evaluated only once.
+func (s *AnalysisService) SetEvaluationCache(cache repository.EvaluationCache) {
+	s.cache = cache
+}
+
+// SetReviewStrategy configures the strategy whose orders human players'
+// orders are reviewed against, instead of the hard bot.
+func (s *AnalysisService) SetReviewStrategy(st bot.Strategy) {
+	s.reviewer = st
+}
+
+// JobAnalyzeGame is the job kind that runs AnalyzeGame for a finished game.
+const JobAnalyzeGame = "analyze_game"
+
+// analyzeGamePayload is the payload of a JobAnalyzeGame job.
+type analyzeGamePayload struct {
+	GameID string `json:"game_id"`
+}
+
+// RegisterJobs registers the analysis job handlers with q.
+func (s *AnalysisService) RegisterJobs(q *jobs.Queue) {
+	q.Register(JobAnalyzeGame, s.analyzeGameJob)
+}
+
+func (s *AnalysisService) analyzeGameJob(ctx context.Context, payload json.RawMessage) error {
+	var p analyzeGamePayload
+	if err := json.Unmarshal(payload, &p); err != nil || p.GameID == "" {
+		return jobs.Permanent(fmt.Errorf("invalid %s payload %s", JobAnalyzeGame, payload))
+	}
+	err := s.AnalyzeGame(ctx, p.GameID)
+	if errors.Is(err, ErrGameNotFound) {
+		return jobs.Permanent(err)
+	}
+	return err
+}
+
+// AnalyzeGame evaluates the position after each resolved phase of a finished
+// game and reviews its human players' movement orders, storing the results
+// and replacing any earlier analysis. Unfinished games are ignored.
+func (s *AnalysisService) AnalyzeGame(ctx context.Context, gameID string) error {
+	game, err := s.gameRepo.FindByID(ctx, gameID)
+	if err != nil {
+		return err
+	}
+	if game == nil {
+		return ErrGameNotFound
+	}
+	if game.Status != "finished" {
+		return nil
+	}
+
+	phases, err := s.phaseRepo.ListPhases(ctx, gameID)
+	if err != nil {
+		return err
+	}
+	m := gameScenario(game).Map()
+	var evals []model.PhaseEvaluation
+	for _, phase := range phases {
+		if phase.StateAfter == nil {
+			continue
+		}
+		var gs diplomacy.GameState
+		if err := json.Unmarshal(phase.StateAfter, &gs); err != nil {
+			return fmt.Errorf("phase %s: unmarshal state: %w", phase.ID, err)
+		}
+		evals = append(evals, s.evaluate(phase.ID, &gs, m)...)
+	}
+	if err := s.phaseRepo.SaveEvaluations(ctx, evals); err != nil {
+		return err
+	}
+
+	reviews, err := s.reviewOrders(ctx, game, phases, m)
+	if err != nil {
+		return err
+	}
+	return s.phaseRepo.SaveOrderReviews(ctx, reviews)
+}
+
+// Stages of a phase whose position is evaluated.
+const (
+	evalStageBefore = "before" // at the start of a phase still being played
+	evalStageAfter  = "after"  // once the phase resolved
+)
+
+// MaxEvaluationHistory bounds how many phases one Evaluations call
+// evaluates with history; longer games return their latest phases only.
+const MaxEvaluationHistory = 100
+
+// Evaluations evaluates a game's latest position from each power's
+// perspective: the start of the phase being played, or the end of the last
+// one once the game is over. With history it also evaluates the position
+// after up to MaxEvaluationHistory earlier phases, oldest first. Finished
+// games are served from the evaluations AnalyzeGame stored when there are
+// any. Win and draw probabilities are only set with a value network, and
+// are withheld from viewerID while they hold a seat in a game still being
+// played. Evaluations are cached per phase when an EvaluationCache is
+// configured.
+func (s *AnalysisService) Evaluations(ctx context.Context, gameID, viewerID string, history bool) ([]model.PhaseEvaluation, error) {
+	game, err := s.gameRepo.FindByID(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	if game == nil || game.Status == "deleted" {
+		return nil, ErrGameNotFound
+	}
+	if game.Status == "waiting" {
+		return nil, ErrGameNotActive
+	}
+	phases, err := s.phaseRepo.ListPhases(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	if len(phases) == 0 {
+		return nil, ErrGameNotActive
+	}
+	if !history {
+		phases = phases[len(phases)-1:]
+	} else if len(phases) > MaxEvaluationHistory {
+		phases = phases[len(phases)-MaxEvaluationHistory:]
+	}
+
+	if game.Status == "finished" {
+		stored, err := s.storedEvaluations(ctx, gameID, phases)
+		if err != nil {
+			return nil, err
+		}
+		if len(stored) > 0 {
+			return stored, nil
+		}
+	}
+
+	m := gameScenario(game).Map()
+	evals := []model.PhaseEvaluation{}
+	for _, phase := range phases {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		stage, state := evalStageAfter, phase.StateAfter
+		if state == nil {
+			stage, state = evalStageBefore, phase.StateBefore
+		}
+		phaseEvals, err := s.cachedEvaluations(ctx, phase.ID, stage, state, m)
+		if err != nil {
+			return nil, err
+		}
+		evals = append(evals, phaseEvals...)
+	}
+	if game.Status == "active" && isSeated(game, viewerID) {
+		withholdOdds(evals)
+	}
+	return evals, nil
+}
+
+// storedEvaluations returns the evaluations AnalyzeGame stored for phases,
+// in phase order, or none if the game has not been analysed.
+func (s *AnalysisService) storedEvaluations(ctx context.Context, gameID string, phases []model.Phase) ([]model.PhaseEvaluation, error) {
+	all, err := s.phaseRepo.EvaluationsByGame(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	order := make(map[string]int, len(phases))
+	for i, p := range phases {
+		order[p.ID] = i
+	}
+	var evals []model.PhaseEvaluation
+	for _, e := range all {
+		if _, ok := order[e.PhaseID]; ok {
+			evals = append(evals, e)
+		}
+	}
+	sort.SliceStable(evals, func(i, j int) bool {
+		if order[evals[i].PhaseID] != order[evals[j].PhaseID] {
+			return order[evals[i].PhaseID] < order[evals[j].PhaseID]
+		}
+		return evals[i].Power < evals[j].Power
+	})
+	return evals, nil
+}
+
+// isSeated reports whether userID plays a power in game.
+func isSeated(game *model.Game, userID string) bool {
+	for _, p := range game.Players {
+		if p.UserID == userID && p.Power != "" && !p.IsBot {
+			return true
+		}
+	}
+	return false
+}
+
+// withholdOdds clears the value network's scores, which would tell a
+// player in a live game how the engine rates their rivals' chances.
+func withholdOdds(evals []model.PhaseEvaluation) {
+	for i := range evals {
+		evals[i].Neural, evals[i].WinProb, evals[i].DrawProb = nil, nil, nil
+	}
+}
+
+// cachedEvaluations returns the evaluations of a phase's position at stage,
+// from the cache if it has them. Cache failures are logged and the position
+// evaluated afresh.
+func (s *AnalysisService) cachedEvaluations(ctx context.Context, phaseID, stage string, state json.RawMessage, m *diplomacy.DiplomacyMap) ([]model.PhaseEvaluation, error) {
+	if s.cache != nil {
+		data, err := s.cache.PhaseEvaluations(ctx, phaseID, stage)
+		if err != nil {
+			log.Warn().Err(err).Str("phaseId", phaseID).Msg("Read cached evaluations failed")
+		} else if data != nil {
+			var evals []model.PhaseEvaluation
+			if err := json.Unmarshal(data, &evals); err == nil {
+				return evals, nil
+			}
+		}
+	}
+
+	var gs diplomacy.GameState
+	if err := json.Unmarshal(state, &gs); err != nil {
+		return nil, fmt.Errorf("phase %s: unmarshal state: %w", phaseID, err)
+	}
+	evals := s.evaluate(phaseID, &gs, m)
+	if s.cache != nil {
+		data, _ := json.Marshal(evals)
+		if err := s.cache.SetPhaseEvaluations(ctx, phaseID, stage, data); err != nil {
+			log.Warn().Err(err).Str("phaseId", phaseID).Msg("Cache evaluations failed")
+		}
+	}
+	return evals, nil
+}
+
+// evaluate scores a position for every power, keyed by phaseID.
+func (s *AnalysisService) evaluate(phaseID string, gs *diplomacy.GameState, m *diplomacy.DiplomacyMap) []model.PhaseEvaluation {
+	var evals []model.PhaseEvaluation
+	for _, e := range bot.EvaluatePowers(gs, m, s.value) {
+		evals = append(evals, model.PhaseEvaluation{
+			PhaseID:   phaseID,
+			Power:     string(e.Power),
+			Heuristic: e.Heuristic,
+			Share:     e.Share,
+			Neural:    e.Neural,
+			WinProb:   e.WinProb,
+			DrawProb:  e.DrawProb,
+		})
+	}
+	return evals
+}
+
+// BlunderThreshold is how much of the board, as a heuristic share, a power
+// must have lost by its orders compared with the review strategy's for the
+// orders to count as a blunder.
+const BlunderThreshold = 0.05
+
+// AnalysisReport is the order review of a finished game, by phase.
+type AnalysisReport struct {
+	GameID           string        `json:"game_id"`
+	BlunderThreshold float64       `json:"blunder_threshold"`
+	Phases           []PhaseReview `json:"phases"`
+}
+
+// PhaseReview is the order reviews of one movement phase.
+type PhaseReview struct {
+	PhaseID   string              `json:"phase_id"`
+	Year      int                 `json:"year"`
+	Season    string              `json:"season"`
+	PhaseType string              `json:"phase_type"`
+	Reviews   []model.OrderReview `json:"reviews"`
+}
+
+// Report returns the order review of a finished game, listing the phases in
+// which a human player gave orders. It is empty until AnalyzeGame has run
+// for the game.
+func (s *AnalysisService) Report(ctx context.Context, gameID string) (*AnalysisReport, error) {
+	game, err := s.gameRepo.FindByID(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	if game == nil || game.Status == "deleted" {
+		return nil, ErrGameNotFound
+	}
+	if game.Status != "finished" {
+		return nil, ErrGameNotFinished
+	}
+	phases, err := s.phaseRepo.ListPhases(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	reviews, err := s.phaseRepo.OrderReviewsByGame(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	byPhase := make(map[string][]model.OrderReview)
+	for _, rv := range reviews {
+		byPhase[rv.PhaseID] = append(byPhase[rv.PhaseID], rv)
+	}
+
+	report := &AnalysisReport{GameID: gameID, BlunderThreshold: BlunderThreshold, Phases: []PhaseReview{}}
+	for _, phase := range phases {
+		rvs := byPhase[phase.ID]
+		if len(rvs) == 0 {
+			continue
+		}
+		sort.Slice(rvs, func(i, j int) bool { return rvs[i].Power < rvs[j].Power })
+		report.Phases = append(report.Phases, PhaseReview{
+			PhaseID:   phase.ID,
+			Year:      phase.Year,
+			Season:    phase.Season,
+			PhaseType: phase.PhaseType,
+			Reviews:   rvs,
+		})
+	}
+	return report, nil
+}
+
+// reviewBudget bounds how long reviewing a game's orders may take, well
+// inside the analysis job's timeout. Phases left when it runs out are not
+// reviewed.
+const reviewBudget = 2 * time.Minute
+
+// reviewOrders reviews the orders human players gave in each resolved
+// movement phase of a game. A power counts as human in a phase if a human
+// held it when the phase resolved, so players later replaced by bots are
+// still reviewed for the phases they played.
+func (s *AnalysisService) reviewOrders(ctx context.Context, game *model.Game, phases []model.Phase, m *diplomacy.DiplomacyMap) ([]model.OrderReview, error) {
+	assignments, err := s.gameRepo.PowerAssignments(ctx, game.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.ContainsFunc(assignments, func(a model.PowerAssignment) bool { return !a.IsBot }) {
+		return nil, nil
+	}
+	reviewer := s.reviewer
+	if reviewer == nil {
+		reviewer = bot.StrategyForDifficulty("hard")
+	}
+
+	var movement []model.Phase
+	for _, phase := range phases {
+		if phase.StateAfter != nil && phase.PhaseType == "movement" {
+			movement = append(movement, phase)
+		}
+	}
+	deadline := time.Now().Add(reviewBudget)
+	var reviews []model.OrderReview
+	for i, phase := range movement {
+		left := time.Until(deadline)
+		if left <= 0 {
+			log.Warn().Str("gameId", game.ID).Int("phases", len(movement)-i).Msg("Order review ran out of time; later phases not reviewed")
+			break
+		}
+		resolved := phase.CreatedAt
+		if phase.ResolvedAt != nil {
+			resolved = *phase.ResolvedAt
+		}
+		humans := humansAt(assignments, resolved)
+		if len(humans) == 0 {
+			continue
+		}
+		var gs diplomacy.GameState
+		if err := json.Unmarshal(phase.StateBefore, &gs); err != nil {
+			return nil, fmt.Errorf("phase %s: unmarshal state: %w", phase.ID, err)
+		}
+		stored, err := s.phaseRepo.OrdersByPhase(ctx, phase.ID)
+		if err != nil {
+			return nil, err
+		}
+		// Each remaining phase gets an equal share of what is left.
+		phaseCtx, cancel := context.WithTimeout(ctx, left/time.Duration(len(movement)-i))
+		reviews = append(reviews, reviewPhase(phaseCtx, phase.ID, &gs, stored, humans, reviewer, m)...)
+		cancel()
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return reviews, nil
+}
+
+// humansAt returns the powers held by humans at t, going by the player
+// last assigned each power by then. assignments are oldest first. A power
+// first assigned after t went to a bot replacing a human who played it
+// before the history was recorded.
+func humansAt(assignments []model.PowerAssignment, t time.Time) []diplomacy.Power {
+	bots := make(map[string]bool) // power -> held by a bot at t
+	for _, a := range assignments {
+		if a.AssignedAt.After(t) {
+			if _, ok := bots[a.Power]; !ok {
+				bots[a.Power] = false
+			}
+			continue
+		}
+		bots[a.Power] = a.IsBot
+	}
+	var humans []diplomacy.Power
+	for power, isBot := range bots {
+		if !isBot {
+			humans = append(humans, diplomacy.Power(power))
+		}
+	}
+	slices.Sort(humans)
+	return humans
+}
+
+// reviewPhase compares each human power's orders in a movement phase with
+// the reviewer's, resolving both against the other powers' orders as
+// played. Powers with no stored orders are skipped, as are powers the
+// reviewer found no orders for before ctx was done.
+func reviewPhase(ctx context.Context, phaseID string, gs *diplomacy.GameState, stored []model.Order, humans []diplomacy.Power, reviewer bot.Strategy, m *diplomacy.DiplomacyMap) []model.OrderReview {
+	byPower := make(map[diplomacy.Power][]diplomacy.Order)
+	for _, o := range stored {
+		if d, ok := modelOrderToDSON(o); ok {
+			power := diplomacy.Power(o.Power)
+			byPower[power] = append(byPower[power], diplomacy.DSONToOrder(d, power))
+		}
+	}
+
+	var reviews []model.OrderReview
+	for _, power := range humans {
+		played := byPower[power]
+		if len(played) == 0 {
+			continue
+		}
+		inputs, err := bot.GenerateOrders(ctx, reviewer, &bot.GameContext{PhaseID: phaseID, State: gs.Clone(), Power: power, Map: m})
+		if err != nil {
+			continue
+		}
+		var suggested []diplomacy.Order
+		for _, in := range inputs {
+			suggested = append(suggested, toEngineOrder(botInputToServiceInput(in), power))
+		}
+
+		rv := model.OrderReview{
+			PhaseID:   phaseID,
+			Power:     string(power),
+			Orders:    formatOrders(played),
+			Suggested: formatOrders(suggested),
+			Eval:      shareAfter(gs, byPower, power, played, m),
+		}
+		rv.SuggestedEval = shareAfter(gs, byPower, power, suggested, m)
+		rv.Delta = rv.SuggestedEval - rv.Eval
+		rv.Blunder = rv.Delta >= BlunderThreshold
+		reviews = append(reviews, rv)
+	}
+	return reviews
+}
+
+// shareAfter resolves the phase with power giving orders and every other
+// power its orders in byPower, and returns power's heuristic share of the
+// resulting position.
+func shareAfter(gs *diplomacy.GameState, byPower map[diplomacy.Power][]diplomacy.Order, power diplomacy.Power, orders []diplomacy.Order, m *diplomacy.DiplomacyMap) float64 {
+	all := append([]diplomacy.Order{}, orders...)
+	for p, os := range byPower {
+		if p != power {
+			all = append(all, os...)
+		}
+	}
+	clone := gs.Clone()
+	results, dislodged := diplomacy.ResolveOrders(all, clone, m)
+	diplomacy.ApplyResolution(clone, m, results, dislodged)
+	for _, e := range bot.EvaluatePowers(clone, m, nil) {
+		if e.Power == power {
+			return e.Share
+		}
+	}
+	return 0
+}
+
+// formatOrders writes movement orders in DSON.
+func formatOrders(orders []diplomacy.Order) string {
+	dson := make([]diplomacy.DSONOrder, len(orders))
+	for i, o := range orders {
+		dson[i] = diplomacy.OrderToDSON(o)
+	}
+	return diplomacy.FormatDSON(dson)
+}
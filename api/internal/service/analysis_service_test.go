@@ -0,0 +1,299 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/freeeve/polite-betrayal/api/internal/bot"
+	"github.com/freeeve/polite-betrayal/api/internal/jobs"
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+func TestAnalyzeGameServedWithExport(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	game, phaseID, _ := setupFinishedGame(t, gameRepo, phaseRepo)
+	ctx := context.Background()
+
+	// France has taken Belgium; everyone else is where they started.
+	gs := diplomacy.NewInitialState()
+	gs.SupplyCenters["bel"] = diplomacy.France
+	after, _ := json.Marshal(gs)
+	phaseRepo.ResolvePhase(ctx, phaseID, after)
+
+	svc := NewAnalysisService(gameRepo, phaseRepo)
+	if err := svc.AnalyzeGame(ctx, game.ID); err != nil {
+		t.Fatalf("AnalyzeGame: %v", err)
+	}
+	// Re-analyzing replaces rather than duplicates.
+	if err := svc.AnalyzeGame(ctx, game.ID); err != nil {
+		t.Fatalf("AnalyzeGame again: %v", err)
+	}
+
+	export, err := NewExportService(gameRepo, phaseRepo, newMockMessageRepo()).ExportGame(ctx, game.ID)
+	if err != nil {
+		t.Fatalf("ExportGame: %v", err)
+	}
+	evals := export.Phases[0].Evaluations
+	if len(evals) != 7 {
+		t.Fatalf("expected one evaluation per power, got %d", len(evals))
+	}
+	total := 0.0
+	share := make(map[string]float64)
+	for _, e := range evals {
+		total += e.Share
+		share[e.Power] = e.Share
+		if e.Neural != nil {
+			t.Errorf("%s: expected no neural score without a value network", e.Power)
+		}
+	}
+	if math.Abs(total-1) > 1e-9 {
+		t.Errorf("expected shares to sum to 1, got %f", total)
+	}
+	if share["france"] <= share["germany"] {
+		t.Errorf("expected France ahead after taking bel, got %f vs %f", share["france"], share["germany"])
+	}
+}
+
+func TestAnalyzeGameSkipsUnfinished(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	cache := newMockCache()
+	gameID, _ := setupActiveGame(t, gameRepo, phaseRepo, cache)
+
+	if err := NewAnalysisService(gameRepo, phaseRepo).AnalyzeGame(context.Background(), gameID); err != nil {
+		t.Fatalf("AnalyzeGame: %v", err)
+	}
+	if len(phaseRepo.evals) != 0 {
+		t.Errorf("expected no evaluations for an active game, got %v", phaseRepo.evals)
+	}
+}
+
+func TestAnalyzeGameJob(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	game, phaseID, _ := setupFinishedGame(t, gameRepo, phaseRepo)
+	ctx := context.Background()
+	after, _ := json.Marshal(diplomacy.NewInitialState())
+	phaseRepo.ResolvePhase(ctx, phaseID, after)
+	svc := NewAnalysisService(gameRepo, phaseRepo)
+
+	payload, _ := json.Marshal(analyzeGamePayload{GameID: game.ID})
+	if err := svc.analyzeGameJob(ctx, payload); err != nil {
+		t.Fatalf("analyzeGameJob: %v", err)
+	}
+	if len(phaseRepo.evals) == 0 {
+		t.Error("expected the job to store evaluations")
+	}
+
+	// Payloads that can never succeed are not retried.
+	for _, bad := range []string{`{}`, `{"game_id":"missing"}`} {
+		if err := svc.analyzeGameJob(ctx, json.RawMessage(bad)); !jobs.IsPermanent(err) {
+			t.Errorf("%s: expected a permanent error, got %v", bad, err)
+		}
+	}
+}
+
+// countingValueNetwork gives every power the same value network scores and
+// counts its runs.
+type countingValueNetwork struct {
+	scores [4]float32
+	runs   int
+}
+
+func (v *countingValueNetwork) RunValueNetwork(*diplomacy.GameState, diplomacy.Power, *diplomacy.DiplomacyMap) ([4]float32, error) {
+	v.runs++
+	return v.scores, nil
+}
+
+func TestEvaluations(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	gameID, _ := setupActiveGame(t, gameRepo, phaseRepo, newMockCache())
+	ctx := context.Background()
+
+	vn := &countingValueNetwork{scores: [4]float32{0.14, 0.2, 0.1, 0.9}}
+	svc := NewAnalysisService(gameRepo, phaseRepo)
+	svc.SetValueNetwork(vn)
+	svc.SetEvaluationCache(newMockEvaluationCache())
+	evals, err := svc.Evaluations(ctx, gameID, "", false)
+	if err != nil {
+		t.Fatalf("Evaluations: %v", err)
+	}
+	if len(evals) != 7 {
+		t.Fatalf("expected one evaluation per power, got %d", len(evals))
+	}
+	for _, e := range evals {
+		if e.WinProb == nil || math.Abs(*e.WinProb-0.2) > 1e-6 {
+			t.Errorf("%s: expected win probability 0.2, got %v", e.Power, e.WinProb)
+		}
+		if e.DrawProb == nil || math.Abs(*e.DrawProb-0.1) > 1e-6 {
+			t.Errorf("%s: expected draw probability 0.1, got %v", e.Power, e.DrawProb)
+		}
+	}
+
+	runs := vn.runs
+	if _, err := svc.Evaluations(ctx, gameID, "", false); err != nil {
+		t.Fatalf("Evaluations again: %v", err)
+	}
+	if vn.runs != runs {
+		t.Errorf("expected the second request to be served from the cache, got %d more runs", vn.runs-runs)
+	}
+
+	if _, err := svc.Evaluations(ctx, "missing", "", false); !errors.Is(err, ErrGameNotFound) {
+		t.Errorf("expected ErrGameNotFound, got %v", err)
+	}
+
+	seated, err := svc.Evaluations(ctx, gameID, "user-1", false)
+	if err != nil {
+		t.Fatalf("Evaluations as a player: %v", err)
+	}
+	for _, e := range seated {
+		if e.WinProb != nil || e.DrawProb != nil || e.Neural != nil {
+			t.Fatalf("expected odds withheld from a seated player, got %+v", e)
+		}
+	}
+}
+
+func TestEvaluationsHistory(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	game, phaseID, _ := setupFinishedGame(t, gameRepo, phaseRepo)
+	ctx := context.Background()
+
+	gs := diplomacy.NewInitialState()
+	gs.SupplyCenters["bel"] = diplomacy.France
+	after, _ := json.Marshal(gs)
+	phaseRepo.ResolvePhase(ctx, phaseID, after)
+	gs.Year = 1902
+	before, _ := json.Marshal(gs)
+	next, _ := phaseRepo.CreatePhase(ctx, game.ID, 1902, "spring", "movement", before, time.Now())
+	phaseRepo.ResolvePhase(ctx, next.ID, before)
+
+	svc := NewAnalysisService(gameRepo, phaseRepo)
+	latest, err := svc.Evaluations(ctx, game.ID, "", false)
+	if err != nil {
+		t.Fatalf("Evaluations: %v", err)
+	}
+	if len(latest) != 7 || latest[0].PhaseID != next.ID {
+		t.Errorf("expected the final position only, got %+v", latest)
+	}
+	all, err := svc.Evaluations(ctx, game.ID, "", true)
+	if err != nil {
+		t.Fatalf("Evaluations with history: %v", err)
+	}
+	if len(all) != 14 || all[0].PhaseID != phaseID || all[13].PhaseID != next.ID {
+		t.Errorf("expected both phases oldest first, got %+v", all)
+	}
+	if all[0].WinProb != nil {
+		t.Error("expected no win probability without a value network")
+	}
+
+	phaseRepo.SaveEvaluations(ctx, []model.PhaseEvaluation{
+		{PhaseID: next.ID, Power: "france", Share: 0.5},
+		{PhaseID: phaseID, Power: "france", Share: 0.25},
+	})
+	stored, err := svc.Evaluations(ctx, game.ID, "", true)
+	if err != nil {
+		t.Fatalf("Evaluations after analysis: %v", err)
+	}
+	if len(stored) != 2 || stored[0].PhaseID != phaseID || stored[1].Share != 0.5 {
+		t.Errorf("expected the stored evaluations oldest first, got %+v", stored)
+	}
+}
+
+// fixedMovementStrategy suggests the same movement orders in every position.
+type fixedMovementStrategy struct {
+	bot.Strategy
+	orders []bot.OrderInput
+}
+
+func (s fixedMovementStrategy) GenerateMovementOrders(*diplomacy.GameState, diplomacy.Power, *diplomacy.DiplomacyMap) []bot.OrderInput {
+	return s.orders
+}
+
+func TestAnalyzeGameReviewsOrders(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	game, phaseID, users := setupFinishedGame(t, gameRepo, phaseRepo)
+	ctx := context.Background()
+	gameRepo.assignments[game.ID] = nil
+	for i := range gameRepo.players[game.ID] {
+		p := &gameRepo.players[game.ID][i]
+		p.IsBot = p.Power != "france"
+		gameRepo.assignments[game.ID] = append(gameRepo.assignments[game.ID], model.PowerAssignment{Power: p.Power, UserID: p.UserID, IsBot: p.IsBot})
+	}
+
+	// France holds in Burgundy against a supported German attack instead of
+	// stepping aside to Belgium.
+	gs := diplomacy.NewInitialState()
+	gs.Units = append(gs.Units,
+		diplomacy.Unit{Type: diplomacy.Army, Power: diplomacy.France, Province: "bur"},
+		diplomacy.Unit{Type: diplomacy.Army, Power: diplomacy.Germany, Province: "ruh"},
+	)
+	phaseRepo.phases[phaseID].StateBefore, _ = json.Marshal(gs)
+	after, _ := json.Marshal(gs)
+	phaseRepo.ResolvePhase(ctx, phaseID, after)
+	phaseRepo.SaveOrders(ctx, []model.Order{
+		{PhaseID: phaseID, Power: "france", UnitType: "army", Location: "bur", OrderType: "hold"},
+		{PhaseID: phaseID, Power: "germany", UnitType: "army", Location: "ruh", OrderType: "move", Target: "bur"},
+		{PhaseID: phaseID, Power: "germany", UnitType: "army", Location: "mun", OrderType: "support", AuxUnitType: "army", AuxLoc: "ruh", AuxTarget: "bur"},
+	})
+	// France's player is replaced by a bot after the phase; their orders
+	// are still reviewed.
+	gameRepo.ReplaceWithBot(ctx, game.ID, users["france"], "bot-8", "easy")
+
+	svc := NewAnalysisService(gameRepo, phaseRepo)
+	svc.SetReviewStrategy(fixedMovementStrategy{orders: []bot.OrderInput{
+		{UnitType: "army", Location: "bur", OrderType: "move", Target: "bel"},
+	}})
+	if err := svc.AnalyzeGame(ctx, game.ID); err != nil {
+		t.Fatalf("AnalyzeGame: %v", err)
+	}
+
+	report, err := svc.Report(ctx, game.ID)
+	if err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	if len(report.Phases) != 1 || len(report.Phases[0].Reviews) != 1 {
+		t.Fatalf("expected one review of France's orders, got %+v", report.Phases)
+	}
+	rv := report.Phases[0].Reviews[0]
+	if rv.Power != "france" || rv.Orders != "A bur H" || rv.Suggested != "A bur - bel" {
+		t.Errorf("unexpected review %+v", rv)
+	}
+	if math.Abs(rv.Delta-(rv.SuggestedEval-rv.Eval)) > 1e-9 || rv.Delta < BlunderThreshold || !rv.Blunder {
+		t.Errorf("expected losing Burgundy to be a blunder, got %+v", rv)
+	}
+
+	// Playing the suggested orders is no blunder.
+	svc.SetReviewStrategy(fixedMovementStrategy{orders: []bot.OrderInput{
+		{UnitType: "army", Location: "bur", OrderType: "hold"},
+	}})
+	if err := svc.AnalyzeGame(ctx, game.ID); err != nil {
+		t.Fatalf("AnalyzeGame again: %v", err)
+	}
+	report, _ = svc.Report(ctx, game.ID)
+	if rv := report.Phases[0].Reviews[0]; rv.Delta != 0 || rv.Blunder {
+		t.Errorf("expected matching orders to cost nothing, got %+v", rv)
+	}
+}
+
+func TestReportRequiresFinishedGame(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	gameID, _ := setupActiveGame(t, gameRepo, phaseRepo, newMockCache())
+
+	svc := NewAnalysisService(gameRepo, phaseRepo)
+	if _, err := svc.Report(context.Background(), gameID); !errors.Is(err, ErrGameNotFinished) {
+		t.Errorf("expected ErrGameNotFinished, got %v", err)
+	}
+	if _, err := svc.Report(context.Background(), "missing"); !errors.Is(err, ErrGameNotFound) {
+		t.Errorf("expected ErrGameNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+)
+
+// anonymousPrefix starts the stand-in user IDs of anonymous games.
+const anonymousPrefix = "power:"
+
+// AnonymousID is the stand-in user ID shown for the player of a power while
+// a game hides identities, e.g. "power:france". Clients can read the power
+// from it; requests may use it wherever a player's user ID is expected.
+func AnonymousID(power string) string {
+	return anonymousPrefix + power
+}
+
+// HidesIdentities reports whether a game currently shows its players only
+// by power. Anonymous games do from the start until they finish; the lobby
+// shows who joined so the creator can manage it.
+func HidesIdentities(game *model.Game) bool {
+	return game.Anonymous && (game.Status == "active" || game.Status == "paused")
+}
+
+// AnonymizeGame returns a copy of game as viewerID may see it: while the
+// game hides identities, every other player's user ID is replaced with
+// their AnonymousID and bots can't be told from people.
+func AnonymizeGame(game *model.Game, viewerID string) *model.Game {
+	if !HidesIdentities(game) {
+		return game
+	}
+	anon := *game
+	anon.Players = slices.Clone(game.Players)
+	for i, p := range anon.Players {
+		if p.UserID == viewerID {
+			continue
+		}
+		anon.Players[i].UserID = AnonymousID(p.Power)
+		anon.Players[i].IsBot = false
+		anon.Players[i].BotDifficulty = ""
+	}
+	anon.CreatorID = anonymizeUserID(game, game.CreatorID, viewerID)
+	return &anon
+}
+
+// AnonymizeMessages replaces the sender and recipient IDs of messages as
+// AnonymizeGame does for players. An empty viewerID hides everyone, for
+// messages broadcast to the whole game.
+func AnonymizeMessages(game *model.Game, messages []model.Message, viewerID string) []model.Message {
+	if !HidesIdentities(game) {
+		return messages
+	}
+	anon := make([]model.Message, len(messages))
+	for i, msg := range messages {
+		msg.SenderID = anonymizeUserID(game, msg.SenderID, viewerID)
+		msg.RecipientID = anonymizeUserID(game, msg.RecipientID, viewerID)
+		anon[i] = msg
+	}
+	return anon
+}
+
+// anonymizeUserID returns the AnonymousID of the player userID, or "" for
+// users without a power. The viewer's own ID is kept.
+func anonymizeUserID(game *model.Game, userID, viewerID string) string {
+	if userID == "" || userID == viewerID {
+		return userID
+	}
+	for _, p := range game.Players {
+		if p.UserID == userID && p.Power != "" {
+			return AnonymousID(p.Power)
+		}
+	}
+	return ""
+}
+
+// ResolveUserID maps an AnonymousID back to the user playing that power.
+// Other IDs are returned unchanged.
+func ResolveUserID(game *model.Game, id string) string {
+	power, ok := strings.CutPrefix(id, anonymousPrefix)
+	if !ok {
+		return id
+	}
+	if userID := botUserIDFor(game, power); userID != "" {
+		return userID
+	}
+	return id
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+)
+
+func TestAnonymizeGame(t *testing.T) {
+	game := &model.Game{
+		Status:    "active",
+		Anonymous: true,
+		CreatorID: "alice",
+		Players: []model.GamePlayer{
+			{UserID: "alice", Power: "france"},
+			{UserID: "bob", Power: "germany"},
+			{UserID: "bot-1", Power: "italy", IsBot: true, BotDifficulty: "hard"},
+		},
+	}
+
+	anon := AnonymizeGame(game, "bob")
+	want := []string{"power:france", "bob", "power:italy"}
+	for i, p := range anon.Players {
+		if p.UserID != want[i] || p.IsBot || p.BotDifficulty != "" {
+			t.Errorf("player %d: expected %s shown as a person, got %+v", i, want[i], p)
+		}
+	}
+	if anon.CreatorID != "power:france" {
+		t.Errorf("expected the creator hidden, got %s", anon.CreatorID)
+	}
+	if game.Players[0].UserID != "alice" {
+		t.Error("expected the original game left unchanged")
+	}
+	if got := ResolveUserID(game, "power:italy"); got != "bot-1" {
+		t.Errorf("expected power:italy to resolve to bot-1, got %s", got)
+	}
+
+	msgs := AnonymizeMessages(game, []model.Message{{SenderID: "alice", RecipientID: "bob"}}, "")
+	if msgs[0].SenderID != "power:france" || msgs[0].RecipientID != "power:germany" {
+		t.Errorf("expected everyone hidden in broadcasts, got %+v", msgs[0])
+	}
+
+	game.Status = "finished"
+	if AnonymizeGame(game, "bob") != game {
+		t.Error("expected identities revealed once the game finishes")
+	}
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/internal/repository"
+)
+
+// API key errors.
+var (
+	ErrInvalidAPIKey  = errors.New("invalid or revoked api key")
+	ErrTooManyAPIKeys = errors.New("too many active api keys")
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	ErrAPIKeyName     = errors.New("name must be 1-64 characters")
+)
+
+// apiKeyPrefix marks public API keys so they are recognisable in config
+// files and secret scanners.
+const apiKeyPrefix = "pb_"
+
+// MaxAPIKeysPerUser caps a user's active public API keys.
+const MaxAPIKeysPerUser = 5
+
+// apiKeyTouchInterval is how stale a key's last_used_at may get before
+// Authenticate refreshes it, so busy keys cost one write per interval rather
+// than one per request.
+const apiKeyTouchInterval = time.Minute
+
+// APIKeyService issues and checks keys for the public read-only API.
+type APIKeyService struct {
+	keyRepo repository.APIKeyRepository
+}
+
+// NewAPIKeyService creates an APIKeyService.
+func NewAPIKeyService(keyRepo repository.APIKeyRepository) *APIKeyService {
+	return &APIKeyService{keyRepo: keyRepo}
+}
+
+// CreateKey issues a new key for the user. The returned token is the only
+// time the key is available in full.
+func (s *APIKeyService) CreateKey(ctx context.Context, userID, name string) (*model.APIKey, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 64 {
+		return nil, "", ErrAPIKeyName
+	}
+	keys, err := s.keyRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	active := 0
+	for _, k := range keys {
+		if k.RevokedAt == nil {
+			active++
+		}
+	}
+	if active >= MaxAPIKeysPerUser {
+		return nil, "", ErrTooManyAPIKeys
+	}
+
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", err
+	}
+	token := apiKeyPrefix + hex.EncodeToString(secret)
+	key, err := s.keyRepo.Create(ctx, userID, name, token[:len(apiKeyPrefix)+6], hashToken(token))
+	if err != nil {
+		return nil, "", err
+	}
+	return key, token, nil
+}
+
+// ListKeys returns the user's keys, without their secrets.
+func (s *APIKeyService) ListKeys(ctx context.Context, userID string) ([]model.APIKey, error) {
+	keys, err := s.keyRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return nonNil(keys), nil
+}
+
+// RevokeKey disables one of the user's keys.
+func (s *APIKeyService) RevokeKey(ctx context.Context, userID, keyID string) error {
+	ok, err := s.keyRepo.Revoke(ctx, keyID, userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// Authenticate returns the active key matching token, or ErrInvalidAPIKey.
+func (s *APIKeyService) Authenticate(ctx context.Context, token string) (*model.APIKey, error) {
+	if !strings.HasPrefix(token, apiKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+	key, err := s.keyRepo.FindByHash(ctx, hashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if key == nil || key.RevokedAt != nil {
+		return nil, ErrInvalidAPIKey
+	}
+	if key.LastUsedAt == nil || time.Since(*key.LastUsedAt) >= apiKeyTouchInterval {
+		if err := s.keyRepo.Touch(ctx, key.ID); err != nil {
+			log.Warn().Err(err).Str("keyId", key.ID).Msg("Failed to record api key use")
+		}
+	}
+	return key, nil
+}
+
+// hashToken returns the hex SHA-256 of a secret token, which is stored in
+// its place.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Lookup adapts Authenticate to auth.APIKeyLookup for the public API
+// middleware.
+func (s *APIKeyService) Lookup(ctx context.Context, token string) (string, bool, error) {
+	key, err := s.Authenticate(ctx, token)
+	if errors.Is(err, ErrInvalidAPIKey) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return key.ID, true, nil
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAPIKeyLifecycle(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockAPIKeyRepo()
+	svc := NewAPIKeyService(repo)
+
+	key, token, err := svc.CreateKey(ctx, "user-1", " stats bot ")
+	if err != nil {
+		t.Fatalf("CreateKey: %v", err)
+	}
+	if !strings.HasPrefix(token, "pb_") || !strings.HasPrefix(token, key.Prefix) || key.Name != "stats bot" {
+		t.Errorf("unexpected key %+v for token %s", key, token)
+	}
+	if repo.hashes[key.ID] == token {
+		t.Error("key stored in plaintext")
+	}
+
+	got, err := svc.Authenticate(ctx, token)
+	if err != nil || got.ID != key.ID {
+		t.Fatalf("Authenticate: got %+v, %v", got, err)
+	}
+	if repo.keys[0].LastUsedAt == nil {
+		t.Error("expected Authenticate to record the key's use")
+	}
+	if _, err := svc.Authenticate(ctx, token+"x"); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("expected ErrInvalidAPIKey for a wrong key, got %v", err)
+	}
+
+	if err := svc.RevokeKey(ctx, "user-2", key.ID); !errors.Is(err, ErrAPIKeyNotFound) {
+		t.Errorf("expected another user's revoke to fail, got %v", err)
+	}
+	if err := svc.RevokeKey(ctx, "user-1", key.ID); err != nil {
+		t.Fatalf("RevokeKey: %v", err)
+	}
+	if _, ok, err := svc.Lookup(ctx, token); ok || err != nil {
+		t.Errorf("expected revoked key to be rejected, got ok=%v err=%v", ok, err)
+	}
+}
+
+func TestAPIKeyTouchThrottled(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockAPIKeyRepo()
+	svc := NewAPIKeyService(repo)
+	_, token, err := svc.CreateKey(ctx, "user-1", "key")
+	if err != nil {
+		t.Fatalf("CreateKey: %v", err)
+	}
+
+	svc.Authenticate(ctx, token)
+	first := repo.keys[0].LastUsedAt
+	svc.Authenticate(ctx, token)
+	if repo.keys[0].LastUsedAt != first {
+		t.Error("expected a recently used key not to be touched again")
+	}
+
+	stale := time.Now().Add(-2 * apiKeyTouchInterval)
+	repo.keys[0].LastUsedAt = &stale
+	svc.Authenticate(ctx, token)
+	if !repo.keys[0].LastUsedAt.After(stale) {
+		t.Error("expected a stale key to be touched")
+	}
+}
+
+func TestAPIKeyLimitPerUser(t *testing.T) {
+	ctx := context.Background()
+	svc := NewAPIKeyService(newMockAPIKeyRepo())
+	if _, _, err := svc.CreateKey(ctx, "user-1", ""); !errors.Is(err, ErrAPIKeyName) {
+		t.Errorf("expected ErrAPIKeyName for an empty name, got %v", err)
+	}
+	var first string
+	for i := range MaxAPIKeysPerUser {
+		key, _, err := svc.CreateKey(ctx, "user-1", "key")
+		if err != nil {
+			t.Fatalf("CreateKey %d: %v", i, err)
+		}
+		if i == 0 {
+			first = key.ID
+		}
+	}
+	if _, _, err := svc.CreateKey(ctx, "user-1", "key"); !errors.Is(err, ErrTooManyAPIKeys) {
+		t.Fatalf("expected ErrTooManyAPIKeys, got %v", err)
+	}
+	// Revoked keys don't count towards the limit.
+	svc.RevokeKey(ctx, "user-1", first)
+	if _, _, err := svc.CreateKey(ctx, "user-1", "key"); err != nil {
+		t.Errorf("expected a slot after revoking, got %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/freeeve/polite-betrayal/api/internal/storage"
+)
+
+// ErrNoArtifactStore is returned when a link is requested but no blob store
+// is configured.
+var ErrNoArtifactStore = errors.New("artifact storage is not configured")
+
+// ArtifactLink is a time-limited URL to a stored artifact.
+type ArtifactLink struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ArtifactService stores rendered maps, reports and exports of finished
+// games in a blob store and hands out signed links to them. Artifacts are
+// stored once per key and content version, so links never serve a copy
+// older than the content they were requested for.
+type ArtifactService struct {
+	store storage.Store
+	ttl   time.Duration
+}
+
+// NewArtifactService creates an ArtifactService whose links are valid for ttl.
+func NewArtifactService(store storage.Store, ttl time.Duration) *ArtifactService {
+	return &ArtifactService{store: store, ttl: ttl}
+}
+
+// ContentVersion names the version of an artifact by a digest of the
+// content it is rendered from.
+func ContentVersion(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// Link returns a signed URL to version of the artifact named key, calling
+// render and storing its output first if that version is not yet stored.
+func (s *ArtifactService) Link(ctx context.Context, key, version, contentType string, render func() ([]byte, error)) (*ArtifactLink, error) {
+	if s == nil || s.store == nil {
+		return nil, ErrNoArtifactStore
+	}
+	key = path.Join(path.Dir(key), version, path.Base(key))
+	exists, err := s.store.Exists(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("check artifact %s: %w", key, err)
+	}
+	if !exists {
+		data, err := render()
+		if err != nil {
+			return nil, err
+		}
+		if err := s.store.Put(ctx, key, data, contentType); err != nil {
+			return nil, fmt.Errorf("store artifact %s: %w", key, err)
+		}
+	}
+	expiresAt := time.Now().Add(s.ttl)
+	url, err := s.store.SignedURL(ctx, key, s.ttl)
+	if err != nil {
+		return nil, fmt.Errorf("sign artifact %s: %w", key, err)
+	}
+	return &ArtifactLink{URL: url, ExpiresAt: expiresAt.UTC().Truncate(time.Second)}, nil
+}
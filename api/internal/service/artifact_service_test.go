@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/freeeve/polite-betrayal/api/internal/storage"
+)
+
+func TestArtifactLinkRendersOnce(t *testing.T) {
+	store, err := storage.NewLocalStore(t.TempDir(), "", []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := NewArtifactService(store, time.Hour)
+	renders := 0
+	render := func() ([]byte, error) {
+		renders++
+		return []byte("<svg/>"), nil
+	}
+
+	ctx := context.Background()
+	for range 2 {
+		link, err := svc.Link(ctx, "games/g1/summary/map.svg", "v1", "image/svg+xml", render)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(link.URL, "/blobs/games/g1/summary/v1/map.svg?expires=") {
+			t.Errorf("unexpected URL %s", link.URL)
+		}
+		if until := time.Until(link.ExpiresAt); until < 59*time.Minute || until > time.Hour {
+			t.Errorf("link expires in %s, want about an hour", until)
+		}
+	}
+	if renders != 1 {
+		t.Errorf("rendered %d times, want 1", renders)
+	}
+	if _, err := svc.Link(ctx, "games/g1/summary/map.svg", "v2", "image/svg+xml", render); err != nil || renders != 2 {
+		t.Errorf("expected a new version to render again, got %d renders, %v", renders, err)
+	}
+
+	if _, err := svc.Link(ctx, "games/g2/report.pdf", "v1", "application/pdf", func() ([]byte, error) {
+		return nil, ErrGameNotFinished
+	}); !errors.Is(err, ErrGameNotFinished) {
+		t.Errorf("render error: got %v", err)
+	}
+
+	if ContentVersion([]byte("a")) == ContentVersion([]byte("b")) {
+		t.Error("expected different content to have different versions")
+	}
+
+	var none *ArtifactService
+	if _, err := none.Link(ctx, "games/g1/report.pdf", "v1", "application/pdf", render); !errors.Is(err, ErrNoArtifactStore) {
+		t.Errorf("unconfigured: got %v, want ErrNoArtifactStore", err)
+	}
+}
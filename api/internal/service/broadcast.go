@@ -4,9 +4,18 @@ package service
 // Implemented by the WebSocket hub.
 type Broadcaster interface {
 	BroadcastGameEvent(gameID string, eventType string, data any)
+	// BroadcastUserEvent sends a game event to a single user only.
+	BroadcastUserEvent(gameID, userID string, eventType string, data any)
+	// BroadcastLobbyEvent sends a game lifecycle event to the clients
+	// following the lobby rather than the game itself.
+	BroadcastLobbyEvent(gameID string, eventType string, data any)
 }
 
 // NoopBroadcaster is a no-op implementation for testing or when WS is disabled.
 type NoopBroadcaster struct{}
 
 func (NoopBroadcaster) BroadcastGameEvent(string, string, any) {}
+
+func (NoopBroadcaster) BroadcastUserEvent(string, string, string, any) {}
+
+func (NoopBroadcaster) BroadcastLobbyEvent(string, string, any) {}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/internal/repository"
+)
+
+// Press channel errors.
+var (
+	ErrChannelNotFound   = errors.New("channel not found")
+	ErrNotChannelMember  = errors.New("you are not in this channel")
+	ErrInvalidChannel    = errors.New("a channel needs a name of at most 50 characters and at least one other power")
+	ErrNotChannelCreator = errors.New("only the channel creator can remove other members")
+)
+
+const maxChannelName = 50
+
+// ChannelService manages press channels: named groups of players, such as an
+// alliance, whose messages only the members see. Global press and direct
+// messages need no channel.
+type ChannelService struct {
+	channelRepo repository.ChannelRepository
+	gameRepo    repository.GameRepository
+}
+
+// NewChannelService creates a ChannelService.
+func NewChannelService(channelRepo repository.ChannelRepository, gameRepo repository.GameRepository) *ChannelService {
+	return &ChannelService{channelRepo: channelRepo, gameRepo: gameRepo}
+}
+
+// CreateChannel creates a channel between the user and the players of the
+// given powers. Only games with full press have channels.
+func (s *ChannelService) CreateChannel(ctx context.Context, gameID, userID, name string, powers []string) (*model.Channel, error) {
+	game, err := s.playingGame(ctx, gameID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !AllowsPress(game, true) {
+		return nil, ErrPressNotAllowed
+	}
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxChannelName {
+		return nil, ErrInvalidChannel
+	}
+	members := []string{userID}
+	for _, power := range powers {
+		memberID := botUserIDFor(game, power)
+		if memberID == "" {
+			return nil, ErrInvalidPower
+		}
+		if !slices.Contains(members, memberID) {
+			members = append(members, memberID)
+		}
+	}
+	if len(members) < 2 {
+		return nil, ErrInvalidChannel
+	}
+	return s.channelRepo.Create(ctx, gameID, name, userID, members)
+}
+
+// ListChannels returns the channels of a game the user belongs to.
+func (s *ChannelService) ListChannels(ctx context.Context, gameID, userID string) ([]model.Channel, error) {
+	channels, err := s.channelRepo.ListByMember(ctx, gameID, userID)
+	return nonNil(channels), err
+}
+
+// Member returns a channel of the game if the user belongs to it.
+func (s *ChannelService) Member(ctx context.Context, gameID, channelID, userID string) (*model.Channel, error) {
+	c, err := s.channelRepo.FindByID(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil || c.GameID != gameID {
+		return nil, ErrChannelNotFound
+	}
+	if !slices.Contains(c.Members, userID) {
+		return nil, ErrNotChannelMember
+	}
+	return c, nil
+}
+
+// AddMember lets a channel member bring in the player of another power.
+func (s *ChannelService) AddMember(ctx context.Context, gameID, channelID, userID, power string) (*model.Channel, error) {
+	c, err := s.Member(ctx, gameID, channelID, userID)
+	if err != nil {
+		return nil, err
+	}
+	game, err := s.playingGame(ctx, gameID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !AllowsPress(game, true) {
+		return nil, ErrPressNotAllowed
+	}
+	memberID := botUserIDFor(game, power)
+	if memberID == "" {
+		return nil, ErrInvalidPower
+	}
+	if slices.Contains(c.Members, memberID) {
+		return c, nil
+	}
+	if err := s.channelRepo.AddMember(ctx, channelID, memberID); err != nil {
+		return nil, err
+	}
+	c.Members = append(c.Members, memberID)
+	return c, nil
+}
+
+// RemoveMember removes a member from a channel and returns it with the
+// removed user's ID. Members may leave; only the creator may remove others.
+// memberID may be an AnonymousID.
+func (s *ChannelService) RemoveMember(ctx context.Context, gameID, channelID, userID, memberID string) (*model.Channel, string, error) {
+	c, err := s.Member(ctx, gameID, channelID, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	game, err := s.gameRepo.FindByID(ctx, gameID)
+	if err != nil {
+		return nil, "", err
+	}
+	if game != nil {
+		memberID = ResolveUserID(game, memberID)
+	}
+	if memberID != userID && c.CreatedBy != userID {
+		return nil, "", ErrNotChannelCreator
+	}
+	if !slices.Contains(c.Members, memberID) {
+		return nil, "", ErrNotChannelMember
+	}
+	if err := s.channelRepo.RemoveMember(ctx, channelID, memberID); err != nil {
+		return nil, "", err
+	}
+	c.Members = slices.DeleteFunc(c.Members, func(id string) bool { return id == memberID })
+	return c, memberID, nil
+}
+
+// ForViewer returns channels as viewerID may see them: while the game hides
+// identities, other members are shown by their AnonymousID.
+func (s *ChannelService) ForViewer(ctx context.Context, gameID string, channels []model.Channel, viewerID string) ([]model.Channel, error) {
+	game, err := s.gameRepo.FindByID(ctx, gameID)
+	if err != nil || game == nil || !HidesIdentities(game) {
+		return channels, err
+	}
+	anon := make([]model.Channel, len(channels))
+	for i, c := range channels {
+		c.CreatedBy = anonymizeUserID(game, c.CreatedBy, viewerID)
+		c.Members = make([]string, len(channels[i].Members))
+		for j, memberID := range channels[i].Members {
+			c.Members[j] = anonymizeUserID(game, memberID, viewerID)
+		}
+		anon[i] = c
+	}
+	return anon, nil
+}
+
+// playingGame loads a game in progress in which the user holds a power.
+func (s *ChannelService) playingGame(ctx context.Context, gameID, userID string) (*model.Game, error) {
+	game, err := s.gameRepo.FindByID(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	if game == nil || game.Status == "deleted" {
+		return nil, ErrGameNotFound
+	}
+	if game.Status != "active" && game.Status != "paused" {
+		return nil, ErrGameNotActive
+	}
+	for _, p := range game.Players {
+		if p.UserID == userID && p.Power != "" {
+			return game, nil
+		}
+	}
+	return nil, ErrNotInGame
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/freeeve/polite-betrayal/api/internal/bot"
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+func TestChannelMembership(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	channelRepo := newMockChannelRepo()
+	ctx := context.Background()
+	gameID, _ := setupActiveGame(t, gameRepo, phaseRepo, newMockCache())
+	france := userForPower(gameRepo, gameID, diplomacy.France)
+	germany := userForPower(gameRepo, gameID, diplomacy.Germany)
+	italy := userForPower(gameRepo, gameID, diplomacy.Italy)
+	svc := NewChannelService(channelRepo, gameRepo)
+
+	if _, err := svc.CreateChannel(ctx, gameID, france, "Western Triple", nil); !errors.Is(err, ErrInvalidChannel) {
+		t.Errorf("expected ErrInvalidChannel without other powers, got %v", err)
+	}
+	if _, err := svc.CreateChannel(ctx, gameID, france, "Western Triple", []string{"narnia"}); !errors.Is(err, ErrInvalidPower) {
+		t.Errorf("expected ErrInvalidPower, got %v", err)
+	}
+	c, err := svc.CreateChannel(ctx, gameID, france, " Western Triple ", []string{"germany", "france"})
+	if err != nil {
+		t.Fatalf("CreateChannel: %v", err)
+	}
+	if c.Name != "Western Triple" || len(c.Members) != 2 {
+		t.Errorf("unexpected channel %+v", c)
+	}
+
+	if _, err := svc.Member(ctx, gameID, c.ID, italy); !errors.Is(err, ErrNotChannelMember) {
+		t.Errorf("expected ErrNotChannelMember for italy, got %v", err)
+	}
+	if _, err := svc.AddMember(ctx, gameID, c.ID, germany, "italy"); err != nil {
+		t.Fatalf("AddMember: %v", err)
+	}
+	if channels, _ := svc.ListChannels(ctx, gameID, italy); len(channels) != 1 {
+		t.Errorf("expected italy to see the channel, got %d", len(channels))
+	}
+
+	if _, _, err := svc.RemoveMember(ctx, gameID, c.ID, germany, italy); !errors.Is(err, ErrNotChannelCreator) {
+		t.Errorf("expected only the creator to remove others, got %v", err)
+	}
+	if _, _, err := svc.RemoveMember(ctx, gameID, c.ID, italy, italy); err != nil {
+		t.Fatalf("leave channel: %v", err)
+	}
+	if _, err := svc.Member(ctx, gameID, c.ID, italy); !errors.Is(err, ErrNotChannelMember) {
+		t.Errorf("expected italy to have left, got %v", err)
+	}
+}
+
+func TestChannelMessageVisibility(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	channelRepo := newMockChannelRepo()
+	messageRepo := &mockMessageRepo{channels: channelRepo}
+	ctx := context.Background()
+	gameID, _ := setupActiveGame(t, gameRepo, phaseRepo, newMockCache())
+	france := userForPower(gameRepo, gameID, diplomacy.France)
+	germany := userForPower(gameRepo, gameID, diplomacy.Germany)
+	italy := userForPower(gameRepo, gameID, diplomacy.Italy)
+
+	c, _ := NewChannelService(channelRepo, gameRepo).CreateChannel(ctx, gameID, france, "West", []string{"germany"})
+	messageRepo.CreateInChannel(ctx, gameID, france, c.ID, "Let's take Belgium", "", nil)
+	messageRepo.Create(ctx, gameID, italy, "", "Hello all", "", nil)
+
+	for user, want := range map[string]int{france: 2, germany: 2, italy: 1} {
+		msgs, _ := messageRepo.ListByGame(ctx, gameID, user)
+		if len(msgs) != want {
+			t.Errorf("user %s: expected %d visible messages, got %d", user, want, len(msgs))
+		}
+	}
+}
+
+func TestTrainingSamplesChannelPress(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	channelRepo := newMockChannelRepo()
+	messageRepo := &mockMessageRepo{channels: channelRepo}
+	game, phaseID, users := setupFinishedGame(t, gameRepo, phaseRepo)
+	ctx := context.Background()
+
+	gameRepo.games[game.ID].Status = "active"
+	c, err := NewChannelService(channelRepo, gameRepo).CreateChannel(ctx, game.ID, users["france"], "West", []string{"germany"})
+	if err != nil {
+		t.Fatalf("CreateChannel: %v", err)
+	}
+	gameRepo.games[game.ID].Status = "finished"
+	messageRepo.CreateInChannel(ctx, game.ID, users["germany"], c.ID, "Belgium is yours", phaseID, nil)
+
+	svc := NewExportService(gameRepo, phaseRepo, messageRepo)
+	svc.SetChannelRepo(channelRepo)
+	export, err := svc.ExportGame(ctx, game.ID)
+	if err != nil {
+		t.Fatalf("ExportGame: %v", err)
+	}
+	samples, err := TrainingSamples(export)
+	if err != nil {
+		t.Fatalf("TrainingSamples: %v", err)
+	}
+	for _, s := range samples {
+		want := 0
+		if s.Power == "france" || s.Power == "germany" {
+			want = 1
+		}
+		if len(s.Press) != want {
+			t.Errorf("%s: expected %d press, got %d", s.Power, want, len(s.Press))
+		}
+	}
+}
+
+func TestBotRepliesInChannel(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	channelRepo := newMockChannelRepo()
+	messageRepo := &mockMessageRepo{channels: channelRepo}
+	ctx := context.Background()
+	gameID, _ := setupActiveGame(t, gameRepo, phaseRepo, newMockCache())
+	france := userForPower(gameRepo, gameID, diplomacy.France)
+	germany := userForPower(gameRepo, gameID, diplomacy.Germany)
+
+	c, _ := NewChannelService(channelRepo, gameRepo).CreateChannel(ctx, gameID, france, "West", []string{"germany"})
+	proposal := bot.DiplomaticIntent{Type: bot.IntentProposeAlliance, From: diplomacy.France, To: diplomacy.Germany, TargetPower: diplomacy.England}
+	messageRepo.CreateInChannel(ctx, gameID, france, c.ID, bot.FormatCannedMessage(proposal), "", bot.IntentAttachment(proposal))
+
+	phaseSvc := NewPhaseService(gameRepo, phaseRepo, newMockCache(), &recordingBroadcaster{})
+	phaseSvc.SetMessageRepo(messageRepo)
+	game, _ := gameRepo.FindByID(ctx, gameID)
+	gs := diplomacy.NewInitialState()
+	phaseSvc.handleBotDiplomacy(ctx, gameID, "", game, "germany", bot.TacticalStrategy{}, gs, diplomacy.StandardMap())
+
+	var reply *model.Message
+	for i, msg := range messageRepo.messages {
+		if msg.SenderID == germany && msg.Intent != nil && msg.Intent.Type == bot.IntentAccept.String() {
+			reply = &messageRepo.messages[i]
+		}
+	}
+	if reply == nil {
+		t.Fatal("expected germany to accept the proposal")
+	}
+	if reply.ChannelID != c.ID || reply.RecipientID != "" {
+		t.Errorf("expected the reply in the channel, got channel %q recipient %q", reply.ChannelID, reply.RecipientID)
+	}
+}
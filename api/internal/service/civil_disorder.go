@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/freeeve/polite-betrayal/api/internal/bot"
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+)
+
+var (
+	ErrInvalidCivilDisorder = errors.New("civil disorder must be 0 (off) or between 1 and 10 missed deadlines")
+	ErrNotHumanPlayer       = errors.New("player is not a human player in this game")
+)
+
+// Civil disorder limits: how many deadlines in a row a player may miss
+// before their power is considered abandoned.
+const (
+	DefaultCivilDisorderAfter = 3
+	MaxCivilDisorderAfter     = 10
+)
+
+// CivilDisorder is a player who has just reached the game's civil disorder
+// threshold. Replacement is the bot now playing their power, if the game
+// hands abandoned powers to a bot.
+type CivilDisorder struct {
+	UserID          string
+	Power           string
+	MissedDeadlines int
+	Replacement     *model.GamePlayer
+}
+
+// UpdateCivilDisorder sets after how many missed deadlines in a row a
+// player falls into civil disorder, 0 turning it off, and the strategy of
+// the bot that then takes over their power; an empty strategy leaves the
+// power to default orders until the player returns. Only the creator can
+// change it, and only before the game starts.
+func (s *GameService) UpdateCivilDisorder(ctx context.Context, gameID, userID string, after int, botDifficulty string) error {
+	if err := ValidateCivilDisorder(after, botDifficulty); err != nil {
+		return err
+	}
+	game, err := s.gameRepo.FindByID(ctx, gameID)
+	if err != nil {
+		return err
+	}
+	if game == nil {
+		return ErrGameNotFound
+	}
+	if game.CreatorID != userID {
+		return ErrNotCreator
+	}
+	if game.Status != "waiting" {
+		return ErrGameNotWaiting
+	}
+	if game.MercyYears > 0 {
+		if err := ValidateMercyStrategy(botDifficulty); err != nil {
+			return err
+		}
+	}
+	return s.gameRepo.UpdateCivilDisorder(ctx, gameID, after, botDifficulty)
+}
+
+// ValidateCivilDisorder checks a civil disorder threshold and bot strategy.
+func ValidateCivilDisorder(after int, botDifficulty string) error {
+	if after < 0 || after > MaxCivilDisorderAfter {
+		return ErrInvalidCivilDisorder
+	}
+	if botDifficulty != "" {
+		if _, ok := bot.LookupStrategy(botDifficulty); !ok {
+			return fmt.Errorf("%w: %q", ErrUnknownStrategy, botDifficulty)
+		}
+	}
+	return nil
+}
+
+// RecordDeadline records who missed a phase's deadline: missed holds the
+// human players who had orders to give and neither gave them nor marked
+// ready, acted those who did. It returns the players who have just fallen
+// into civil disorder, replacing each with a bot if the game asks for one.
+func (s *GameService) RecordDeadline(ctx context.Context, game *model.Game, missed, acted []string) ([]CivilDisorder, error) {
+	if len(missed) == 0 && len(acted) == 0 {
+		return nil, nil
+	}
+	players, err := s.gameRepo.RecordDeadline(ctx, game.ID, missed, acted)
+	if err != nil {
+		return nil, err
+	}
+
+	var disorders []CivilDisorder
+	for _, p := range players {
+		if !p.CivilDisorder || p.MissedDeadlines != game.CivilDisorderAfter {
+			continue
+		}
+		d := CivilDisorder{UserID: p.UserID, Power: p.Power, MissedDeadlines: p.MissedDeadlines}
+		if game.CivilDisorderBot != "" {
+			d.Replacement, err = s.replaceWithBot(ctx, game, p.UserID, game.CivilDisorderBot)
+			if err != nil {
+				log.Warn().Err(err).Str("gameId", game.ID).Str("power", p.Power).Msg("Failed to replace player in civil disorder")
+			}
+		}
+		disorders = append(disorders, d)
+	}
+	return disorders, nil
+}
+
+// ReplaceWithBot hands a human player's power to a bot playing difficulty,
+// the game's civil disorder bot if empty, or easy if it has none. The
+// player stays on as a spectator. It is meant for admins dealing with an
+// abandoned power, so doesn't check who is asking.
+func (s *GameService) ReplaceWithBot(ctx context.Context, gameID, userID, difficulty string) (*model.GamePlayer, error) {
+	game, err := s.gameRepo.FindByID(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	if game == nil || game.Status == "deleted" {
+		return nil, ErrGameNotFound
+	}
+	if game.Status != "active" && game.Status != "paused" {
+		return nil, ErrGameNotActive
+	}
+	if difficulty == "" {
+		difficulty = game.CivilDisorderBot
+	}
+	if difficulty == "" {
+		difficulty = "easy"
+	}
+	if _, ok := bot.LookupStrategy(difficulty); !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownStrategy, difficulty)
+	}
+	if game.MercyYears > 0 {
+		if err := ValidateMercyStrategy(difficulty); err != nil {
+			return nil, err
+		}
+	}
+	return s.replaceWithBot(ctx, game, ResolveUserID(game, userID), difficulty)
+}
+
+// replaceWithBot does the work of ReplaceWithBot, with a bot user not yet
+// in the game, and updates game's players to match. Whatever the player
+// submitted this phase is dropped, so the bot starts from a clean slate.
+func (s *GameService) replaceWithBot(ctx context.Context, game *model.Game, userID, difficulty string) (*model.GamePlayer, error) {
+	i := slices.IndexFunc(game.Players, func(p model.GamePlayer) bool { return p.UserID == userID && !p.IsBot })
+	if i < 0 {
+		return nil, ErrNotHumanPlayer
+	}
+	botUser, err := s.spareBotUser(ctx, game)
+	if err != nil {
+		return nil, err
+	}
+	p, err := s.gameRepo.ReplaceWithBot(ctx, game.ID, userID, botUser.ID, difficulty)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, ErrNotHumanPlayer
+	}
+	game.Players[i] = *p
+	s.clearPowerData(ctx, game.ID, p.Power)
+	log.Info().Str("gameId", game.ID).Str("power", p.Power).Str("difficulty", difficulty).Msg("Player replaced by bot")
+	return p, nil
+}
+
+// spareBotUser returns a bot user not already playing in game. Bots join a
+// game as bot-1, bot-2 and so on, so the one after its last is free unless
+// a bot has since left.
+func (s *GameService) spareBotUser(ctx context.Context, game *model.Game) (*model.User, error) {
+	bots := 0
+	for _, p := range game.Players {
+		if p.IsBot {
+			bots++
+		}
+	}
+	for i := bots + 1; ; i++ {
+		botUser, err := s.userRepo.Upsert(ctx, "bot", fmt.Sprintf("bot-%d", i), fmt.Sprintf("Bot %d", i), "")
+		if err != nil {
+			return nil, fmt.Errorf("create bot user %d: %w", i, err)
+		}
+		if !slices.ContainsFunc(game.Players, func(p model.GamePlayer) bool { return p.UserID == botUser.ID }) {
+			return botUser, nil
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+func TestUpdateCivilDisorder(t *testing.T) {
+	ctx := context.Background()
+	gameRepo := newMockGameRepo()
+	svc := NewGameService(gameRepo, newMockPhaseRepo(), newMockUserRepo())
+	game, _ := svc.CreateGame(ctx, "Abandoned", "user-1", "", "", "", "", "", "", false, model.GameRules{})
+
+	if err := svc.UpdateCivilDisorder(ctx, game.ID, "user-1", MaxCivilDisorderAfter+1, ""); !errors.Is(err, ErrInvalidCivilDisorder) {
+		t.Errorf("expected ErrInvalidCivilDisorder, got %v", err)
+	}
+	if err := svc.UpdateCivilDisorder(ctx, game.ID, "user-1", 2, "grandmaster"); !errors.Is(err, ErrUnknownStrategy) {
+		t.Errorf("expected ErrUnknownStrategy, got %v", err)
+	}
+	if err := svc.UpdateCivilDisorder(ctx, game.ID, "user-2", 2, "easy"); !errors.Is(err, ErrNotCreator) {
+		t.Errorf("expected ErrNotCreator, got %v", err)
+	}
+	if err := svc.UpdateCivilDisorder(ctx, game.ID, "user-1", 2, "easy"); err != nil {
+		t.Fatalf("UpdateCivilDisorder: %v", err)
+	}
+	if got, _ := gameRepo.FindByID(ctx, game.ID); got.CivilDisorderAfter != 2 || got.CivilDisorderBot != "easy" {
+		t.Errorf("expected civil disorder after 2 with easy bot, got %d %q", got.CivilDisorderAfter, got.CivilDisorderBot)
+	}
+}
+
+func TestCivilDisorderReplacesAbsentPlayer(t *testing.T) {
+	ctx := context.Background()
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	cache := newMockCache()
+	gameID, powers := setupActiveGame(t, gameRepo, phaseRepo, cache)
+	gameRepo.games[gameID].CivilDisorderAfter = 2
+	gameRepo.games[gameID].CivilDisorderBot = "easy"
+
+	gameSvc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
+	broadcaster := &recordingBroadcaster{}
+	phaseSvc := NewPhaseService(gameRepo, phaseRepo, cache, broadcaster)
+	phaseSvc.SetGameService(gameSvc)
+
+	absent := gameRepo.players[gameID][0]
+	for round := 1; round <= 2; round++ {
+		for _, p := range powers {
+			if p != absent.Power {
+				cache.MarkReady(ctx, gameID, p)
+			}
+		}
+		if err := phaseSvc.ResolvePhaseEarly(ctx, gameID); err != nil {
+			t.Fatalf("round %d: ResolvePhaseEarly: %v", round, err)
+		}
+		if round == 1 {
+			if n := len(broadcaster.eventsOfType("civil_disorder")); n != 0 {
+				t.Fatalf("expected no civil disorder after one missed deadline, got %d", n)
+			}
+			if p := gameRepo.players[gameID][0]; p.UserID != absent.UserID || p.MissedDeadlines != 1 {
+				t.Fatalf("expected one missed deadline, got %+v", p)
+			}
+			if p := gameRepo.players[gameID][1]; p.MissedDeadlines != 0 {
+				t.Fatalf("expected players who were ready to miss nothing, got %+v", p)
+			}
+		}
+	}
+
+	if n := len(broadcaster.eventsOfType("civil_disorder")); n != 1 {
+		t.Fatalf("expected one civil_disorder event, got %d", n)
+	}
+	replaced := broadcaster.eventsOfType("player_replaced")
+	if len(replaced) != 1 {
+		t.Fatalf("expected one player_replaced event, got %d", len(replaced))
+	}
+	if data := replaced[0].data.(map[string]any); data["power"] != absent.Power || data["bot_difficulty"] != "easy" {
+		t.Errorf("unexpected player_replaced data %v", data)
+	}
+	p := gameRepo.players[gameID][0]
+	if !p.IsBot || p.Power != absent.Power || p.BotDifficulty != "easy" {
+		t.Errorf("expected an easy bot playing %s, got %+v", absent.Power, p)
+	}
+	if !slices.Contains(gameRepo.spectators[gameID], absent.UserID) {
+		t.Errorf("expected %s to stay on as a spectator", absent.UserID)
+	}
+}
+
+func TestReplaceWithBot(t *testing.T) {
+	ctx := context.Background()
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	gameID, _ := setupActiveGame(t, gameRepo, phaseRepo, newMockCache())
+	svc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
+	target := gameRepo.players[gameID][2]
+
+	if _, err := svc.ReplaceWithBot(ctx, gameID, target.UserID, "grandmaster"); !errors.Is(err, ErrUnknownStrategy) {
+		t.Errorf("expected ErrUnknownStrategy, got %v", err)
+	}
+	if _, err := svc.ReplaceWithBot(ctx, gameID, "user-99", ""); !errors.Is(err, ErrNotHumanPlayer) {
+		t.Errorf("expected ErrNotHumanPlayer, got %v", err)
+	}
+	p, err := svc.ReplaceWithBot(ctx, gameID, AnonymousID(target.Power), "")
+	if err != nil {
+		t.Fatalf("ReplaceWithBot: %v", err)
+	}
+	if !p.IsBot || p.Power != target.Power || p.BotDifficulty != "easy" {
+		t.Errorf("expected an easy bot playing %s, got %+v", target.Power, p)
+	}
+	if _, err := svc.ReplaceWithBot(ctx, gameID, target.UserID, ""); !errors.Is(err, ErrNotHumanPlayer) {
+		t.Errorf("replacing twice: expected ErrNotHumanPlayer, got %v", err)
+	}
+}
+
+func TestReplaceWithBotPicksSpareBot(t *testing.T) {
+	ctx := context.Background()
+	gameRepo := newMockGameRepo()
+	userRepo := newMockUserRepo()
+	svc := NewGameService(gameRepo, newMockPhaseRepo(), userRepo)
+	game, _ := svc.CreateGame(ctx, "Bots", "user-1", "", "", "", "", "", "", false, model.GameRules{})
+	gameRepo.games[game.ID].Status = "active"
+
+	userRepo.upserts = 0
+	p, err := svc.ReplaceWithBot(ctx, game.ID, "user-1", "")
+	if err != nil {
+		t.Fatalf("ReplaceWithBot: %v", err)
+	}
+	if userRepo.upserts != 1 {
+		t.Errorf("expected one bot user upsert, got %d", userRepo.upserts)
+	}
+	seats := 0
+	for _, other := range gameRepo.players[game.ID] {
+		if other.UserID == p.UserID {
+			seats++
+		}
+	}
+	if seats != 1 {
+		t.Errorf("expected replacement bot %s in one seat, got %d", p.UserID, seats)
+	}
+}
+
+func TestReplaceWithBotThenResolve(t *testing.T) {
+	ctx := context.Background()
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	cache := newMockCache()
+	durable := NewDurableCache(cache, phaseRepo)
+	gameID, powers := setupActiveGame(t, gameRepo, phaseRepo, cache)
+	gameSvc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
+	gameSvc.SetGameCache(durable)
+	phaseSvc := NewPhaseService(gameRepo, phaseRepo, durable, nil)
+
+	// England's player orders F lon-nth, marks ready and votes to draw,
+	// then is replaced before the phase resolves.
+	orders, _ := json.Marshal([]diplomacy.Order{
+		{UnitType: diplomacy.Fleet, Power: diplomacy.England, Location: "lon", Type: diplomacy.OrderMove, Target: "nth"},
+	})
+	phase, _ := phaseRepo.CurrentPhase(ctx, gameID)
+	phaseCtx := withPhase(ctx, phase.ID)
+	durable.SetOrders(phaseCtx, gameID, "england", orders)
+	durable.MarkReady(phaseCtx, gameID, "england")
+	cache.AddDrawVote(ctx, gameID, "england")
+	i := slices.IndexFunc(gameRepo.players[gameID], func(p model.GamePlayer) bool { return p.Power == "england" })
+	if _, err := gameSvc.ReplaceWithBot(ctx, gameID, gameRepo.players[gameID][i].UserID, ""); err != nil {
+		t.Fatalf("ReplaceWithBot: %v", err)
+	}
+
+	if cache.ready[gameID]["england"] || cache.drawVotes[gameID]["england"] {
+		t.Error("expected the replaced player's ready flag and draw vote cleared")
+	}
+	if subs, _ := phaseRepo.Submissions(ctx, phase.ID); len(subs) != 0 {
+		t.Errorf("expected the recorded submission cleared, got %+v", subs)
+	}
+
+	for _, p := range powers {
+		if p != "england" {
+			cache.MarkReady(ctx, gameID, p)
+		}
+	}
+	if err := phaseSvc.ResolvePhaseEarly(ctx, gameID); err != nil {
+		t.Fatalf("ResolvePhaseEarly: %v", err)
+	}
+	var gs diplomacy.GameState
+	json.Unmarshal(cache.states[gameID], &gs)
+	if u := gs.UnitAt("lon"); u == nil || u.Power != diplomacy.England {
+		t.Errorf("expected the replaced player's move not played, fleet still in lon; nth holds %+v", gs.UnitAt("nth"))
+	}
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// ErrNotYourPower is returned when a player asks for another power's view.
+var ErrNotYourPower = errors.New("you can only view your own power")
+
+// CompactState is one power's view of the current phase for bandwidth-limited
+// clients. Keys are single letters; units and orders use DSON notation.
+type CompactState struct {
+	Phase     string              `json:"p"`           // DFEN phase, e.g. "1901sm"
+	Deadline  int64               `json:"d"`           // Unix seconds
+	Now       int64               `json:"n"`           // server time, Unix seconds
+	Remaining int64               `json:"l"`           // seconds left until the deadline, 0 once passed
+	Power     string              `json:"w"`           // the viewing power
+	Units     []string            `json:"u"`           // own units, e.g. "A par", "F stp/sc"
+	Dislodged []string            `json:"x,omitempty"` // own units that must retreat
+	Orders    string              `json:"o,omitempty"` // own submitted orders in DSON
+	Centers   map[string][]string `json:"c"`           // power -> owned centers; neutrals omitted
+	Ready     int                 `json:"r"`           // powers ready
+	Total     int                 `json:"t"`           // powers playing
+	IsReady   bool                `json:"k,omitempty"` // whether the viewing power is ready
+}
+
+// CompactState returns the current phase as seen by the user's power. If
+// power is set it must be the user's own.
+func (s *OrderService) CompactState(ctx context.Context, gameID, userID, power string) (*CompactState, error) {
+	game, own, phase, gs, err := s.playerPhase(ctx, gameID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if power != "" && power != own {
+		return nil, ErrNotYourPower
+	}
+	p := diplomacy.Power(own)
+
+	now := time.Now()
+	cs := &CompactState{
+		Phase:     diplomacy.EncodeDFENPhase(gs),
+		Deadline:  phase.Deadline.Unix(),
+		Now:       now.Unix(),
+		Remaining: max(0, int64(phase.Deadline.Sub(now).Seconds())),
+		Power:     own,
+		Units:     []string{},
+		Centers:   make(map[string][]string),
+		Total:     len(activePowersFromGame(game)),
+	}
+	for _, u := range gs.UnitsOf(p) {
+		cs.Units = append(cs.Units, dsonUnit(u))
+	}
+	for _, d := range gs.Dislodged {
+		if d.Unit.Power == p {
+			cs.Dislodged = append(cs.Dislodged, dsonUnit(d.Unit))
+		}
+	}
+	for sc, owner := range gs.SupplyCenters {
+		if owner != diplomacy.Neutral {
+			cs.Centers[string(owner)] = append(cs.Centers[string(owner)], sc)
+		}
+	}
+	for _, scs := range cs.Centers {
+		sort.Strings(scs)
+	}
+
+	if cs.Orders, err = s.submittedDSON(ctx, gameID, own, gs.Phase); err != nil {
+		return nil, err
+	}
+	ready, err := s.cache.ReadyPowers(ctx, gameID)
+	if err != nil {
+		return nil, fmt.Errorf("ready powers: %w", err)
+	}
+	cs.Ready = len(ready)
+	cs.IsReady = slices.Contains(ready, own)
+	return cs, nil
+}
+
+// submittedDSON returns the orders a power has submitted for the current
+// phase in DSON, or "" if it has none.
+func (s *OrderService) submittedDSON(ctx context.Context, gameID, power string, phase diplomacy.PhaseType) (string, error) {
+	dson, err := s.submittedOrders(ctx, gameID, power, phase)
+	if err != nil || dson == nil {
+		return "", err
+	}
+	return diplomacy.FormatDSON(dson), nil
+}
+
+// submittedOrders returns the orders a power has submitted for the current
+// phase as DSON orders, or nil if it has none.
+func (s *OrderService) submittedOrders(ctx context.Context, gameID, power string, phase diplomacy.PhaseType) ([]diplomacy.DSONOrder, error) {
+	raw, err := s.cache.GetOrders(ctx, gameID, power)
+	if err != nil {
+		return nil, fmt.Errorf("get orders: %w", err)
+	}
+	return decodeSubmittedOrders(raw, phase)
+}
+
+// decodeSubmittedOrders decodes a power's orders as stored in the game cache
+// for a phase of the given type.
+func decodeSubmittedOrders(raw json.RawMessage, phase diplomacy.PhaseType) ([]diplomacy.DSONOrder, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	var dson []diplomacy.DSONOrder
+	switch phase {
+	case diplomacy.PhaseRetreat:
+		var orders []diplomacy.RetreatOrder
+		if err := json.Unmarshal(raw, &orders); err != nil {
+			return nil, fmt.Errorf("unmarshal orders: %w", err)
+		}
+		for _, o := range orders {
+			dson = append(dson, diplomacy.RetreatOrderToDSON(o))
+		}
+	case diplomacy.PhaseBuild:
+		var orders []diplomacy.BuildOrder
+		if err := json.Unmarshal(raw, &orders); err != nil {
+			return nil, fmt.Errorf("unmarshal orders: %w", err)
+		}
+		for _, o := range orders {
+			dson = append(dson, diplomacy.BuildOrderToDSON(o))
+		}
+	default:
+		var orders []diplomacy.Order
+		if err := json.Unmarshal(raw, &orders); err != nil {
+			return nil, fmt.Errorf("unmarshal orders: %w", err)
+		}
+		for _, o := range orders {
+			dson = append(dson, diplomacy.OrderToDSON(o))
+		}
+	}
+	return dson, nil
+}
+
+// dsonUnit formats a unit as "A par" or "F stp/sc".
+func dsonUnit(u diplomacy.Unit) string {
+	var b strings.Builder
+	if u.Type == diplomacy.Army {
+		b.WriteString("A ")
+	} else {
+		b.WriteString("F ")
+	}
+	b.WriteString(u.Province)
+	if u.Coast != diplomacy.NoCoast {
+		b.WriteByte('/')
+		b.WriteString(string(u.Coast))
+	}
+	return b.String()
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCompactState(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	cache := newMockCache()
+	gameID, _ := setupActiveGame(t, gameRepo, phaseRepo, cache)
+	orderSvc := NewOrderService(gameRepo, phaseRepo, cache)
+	ctx := context.Background()
+
+	power, units := playerUnits(t, gameRepo, gameID, "user-1")
+	if _, err := orderSvc.SubmitOrders(ctx, gameID, "user-1", []OrderInput{
+		{UnitType: units[0].Type.String(), Location: units[0].Province, Coast: string(units[0].Coast), OrderType: "hold"},
+	}); err != nil {
+		t.Fatalf("SubmitOrders: %v", err)
+	}
+	if _, _, err := orderSvc.MarkReady(ctx, gameID, "user-1"); err != nil {
+		t.Fatalf("MarkReady: %v", err)
+	}
+
+	cs, err := orderSvc.CompactState(ctx, gameID, "user-1", power)
+	if err != nil {
+		t.Fatalf("CompactState: %v", err)
+	}
+	if cs.Phase != "1901sm" || cs.Power != power {
+		t.Errorf("expected 1901sm for %s, got %s for %s", power, cs.Phase, cs.Power)
+	}
+	if len(cs.Units) != len(units) {
+		t.Errorf("expected %d units, got %v", len(units), cs.Units)
+	}
+	if want := dsonUnit(units[0]) + " H"; cs.Orders != want {
+		t.Errorf("expected orders %q, got %q", want, cs.Orders)
+	}
+	if len(cs.Centers) != 7 || len(cs.Centers[power]) != len(units) {
+		t.Errorf("unexpected centers: %v", cs.Centers)
+	}
+	if cs.Ready != 1 || cs.Total != 7 || !cs.IsReady {
+		t.Errorf("expected 1/7 ready including self, got %d/%d ready=%v", cs.Ready, cs.Total, cs.IsReady)
+	}
+	if cs.Remaining <= 0 || cs.Now+cs.Remaining > cs.Deadline || cs.Deadline-cs.Now-cs.Remaining > 1 {
+		t.Errorf("expected remaining time to match the deadline, got now %d, remaining %d, deadline %d", cs.Now, cs.Remaining, cs.Deadline)
+	}
+
+	// Omitting power defaults to the caller's own; another power is refused.
+	if cs, err := orderSvc.CompactState(ctx, gameID, "user-2", ""); err != nil || cs.Orders != "" || cs.IsReady {
+		t.Errorf("expected empty own view for user-2, got %+v, %v", cs, err)
+	}
+	if _, err := orderSvc.CompactState(ctx, gameID, "user-2", power); !errors.Is(err, ErrNotYourPower) {
+		t.Errorf("expected ErrNotYourPower, got %v", err)
+	}
+	if _, err := orderSvc.CompactState(ctx, gameID, "stranger", ""); !errors.Is(err, ErrNotInGame) {
+		t.Errorf("expected ErrNotInGame, got %v", err)
+	}
+}
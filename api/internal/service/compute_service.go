@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"runtime"
+	"time"
+
+	"github.com/freeeve/polite-betrayal/api/internal/bot"
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/internal/repository"
+)
+
+// ComputeHook receives each phase's bot compute records once they are
+// stored, e.g. to forward usage to a billing system. Hooks run synchronously
+// on the bot order path and must be quick.
+type ComputeHook func(ctx context.Context, records []model.BotCompute)
+
+// ComputeService accounts for the time bots spend generating orders and
+// enforces a per-game budget by downgrading bots that exceed it. Compute
+// time is the CPU time a strategy uses, as measured by measureCompute.
+type ComputeService struct {
+	computeRepo repository.ComputeRepository
+	gameRepo    repository.GameRepository
+	gameCap     time.Duration // 0 = unlimited
+	hooks       []ComputeHook
+}
+
+// NewComputeService creates a ComputeService with no per-game cap.
+func NewComputeService(computeRepo repository.ComputeRepository, gameRepo repository.GameRepository) *ComputeService {
+	return &ComputeService{computeRepo: computeRepo, gameRepo: gameRepo}
+}
+
+// SetGameCap sets the bot compute time a game may use before its bots are
+// downgraded. Zero disables the cap.
+func (s *ComputeService) SetGameCap(d time.Duration) {
+	s.gameCap = d
+}
+
+// AddHook registers a hook called with every phase's compute records.
+func (s *ComputeService) AddHook(h ComputeHook) {
+	s.hooks = append(s.hooks, h)
+}
+
+// RecordPhase stores a phase's compute records and runs the hooks. Once the
+// game is over its cap, every bot that can be is downgraded one step to a
+// cheaper strategy. Each downgrade buys a bot another cap's worth of
+// compute, so a bot steps down for the nth time only once the game has
+// used n times its cap rather than on every phase over it. It returns the
+// new strategy of each downgraded power.
+func (s *ComputeService) RecordPhase(ctx context.Context, game *model.Game, records []model.BotCompute) (map[string]string, error) {
+	if err := s.computeRepo.Record(ctx, records); err != nil {
+		return nil, err
+	}
+	for _, h := range s.hooks {
+		h(ctx, records)
+	}
+	if s.gameCap <= 0 {
+		return nil, nil
+	}
+	total, err := s.computeRepo.GameTotal(ctx, game.ID)
+	if err != nil {
+		return nil, err
+	}
+	capMS := s.gameCap.Milliseconds()
+	if total < capMS {
+		return nil, nil
+	}
+	changes, err := s.gameRepo.BotDifficultyChanges(ctx, game.ID)
+	if err != nil {
+		return nil, err
+	}
+	steps := make(map[string]int64)
+	for _, c := range changes {
+		if c.Reason == model.BotDifficultyByComputeCap {
+			steps[c.UserID]++
+		}
+	}
+
+	downgraded := make(map[string]string)
+	for i, p := range game.Players {
+		if !p.IsBot || p.Power == "" || total < (steps[p.UserID]+1)*capMS {
+			continue
+		}
+		next := bot.CheaperStrategy(p.BotDifficulty)
+		if next == "" {
+			continue
+		}
+		if _, err := s.gameRepo.UpdateBotDifficulty(ctx, game.ID, p.UserID, next, model.BotDifficultyByComputeCap, ""); err != nil {
+			return downgraded, err
+		}
+		game.Players[i].BotDifficulty = next
+		downgraded[p.Power] = next
+	}
+	return downgraded, nil
+}
+
+// measureCompute runs generate and returns the CPU time it used. Strategies
+// search on the goroutine that calls them, so that is the CPU time of its
+// thread. An engine in another process is charged the wall-clock time spent
+// waiting for it, as is everything on platforms without per-thread CPU time.
+func measureCompute(outOfProcess bool, generate func()) time.Duration {
+	start := time.Now()
+	if outOfProcess {
+		generate()
+		return time.Since(start)
+	}
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	cpuStart, ok := threadCPUTime()
+	generate()
+	if cpuEnd, ok2 := threadCPUTime(); ok && ok2 {
+		return cpuEnd - cpuStart
+	}
+	return time.Since(start)
+}
+
+// PhaseCompute is the bot compute time of one phase.
+type PhaseCompute struct {
+	PhaseID   string           `json:"phase_id"`
+	ComputeMS int64            `json:"compute_ms"`
+	ByPower   map[string]int64 `json:"by_power"`
+}
+
+// GameComputeReport breaks down a game's bot compute time by phase.
+type GameComputeReport struct {
+	GameID    string         `json:"game_id"`
+	ComputeMS int64          `json:"compute_ms"`
+	CapMS     int64          `json:"cap_ms,omitempty"`
+	Phases    []PhaseCompute `json:"phases"`
+}
+
+// GameReport returns a game's bot compute time per phase and power.
+func (s *ComputeService) GameReport(ctx context.Context, gameID string) (*GameComputeReport, error) {
+	records, err := s.computeRepo.ListByGame(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	report := &GameComputeReport{GameID: gameID, CapMS: s.gameCap.Milliseconds(), Phases: []PhaseCompute{}}
+	index := make(map[string]int)
+	for _, rec := range records {
+		i, ok := index[rec.PhaseID]
+		if !ok {
+			i = len(report.Phases)
+			index[rec.PhaseID] = i
+			report.Phases = append(report.Phases, PhaseCompute{PhaseID: rec.PhaseID, ByPower: make(map[string]int64)})
+		}
+		report.Phases[i].ComputeMS += rec.ComputeMS
+		report.Phases[i].ByPower[rec.Power] += rec.ComputeMS
+		report.ComputeMS += rec.ComputeMS
+	}
+	return report, nil
+}
+
+// TopGames returns the games that used the most bot compute since the given
+// time.
+func (s *ComputeService) TopGames(ctx context.Context, since time.Time, limit int) ([]model.GameCompute, error) {
+	games, err := s.computeRepo.TopGames(ctx, since, limit)
+	return nonNil(games), err
+}
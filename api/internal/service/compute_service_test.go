@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+)
+
+// botGame sets up an active game whose france and germany seats are bots of
+// the given strategies.
+func botGame(t *testing.T, gameRepo *mockGameRepo, phaseRepo *mockPhaseRepo, cache *mockCache, france, germany string) string {
+	t.Helper()
+	gameID, _ := setupActiveGame(t, gameRepo, phaseRepo, cache)
+	for i, p := range gameRepo.players[gameID] {
+		switch p.Power {
+		case "france":
+			gameRepo.players[gameID][i].IsBot, gameRepo.players[gameID][i].BotDifficulty = true, france
+		case "germany":
+			gameRepo.players[gameID][i].IsBot, gameRepo.players[gameID][i].BotDifficulty = true, germany
+		}
+	}
+	return gameID
+}
+
+func TestRecordPhaseDowngradesOverCap(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	computeRepo := &mockComputeRepo{}
+	svc := NewComputeService(computeRepo, gameRepo)
+	svc.SetGameCap(time.Second)
+	var hooked int
+	svc.AddHook(func(_ context.Context, records []model.BotCompute) { hooked += len(records) })
+	ctx := context.Background()
+
+	gameID := botGame(t, gameRepo, phaseRepo, newMockCache(), "hard", "easy")
+	game, _ := gameRepo.FindByID(ctx, gameID)
+
+	under := []model.BotCompute{{GameID: gameID, PhaseID: "p1", Power: "france", Strategy: "hard", ComputeMS: 600}}
+	if downgraded, err := svc.RecordPhase(ctx, game, under); err != nil || len(downgraded) != 0 {
+		t.Fatalf("expected no downgrade under the cap, got %v, %v", downgraded, err)
+	}
+
+	over := []model.BotCompute{{GameID: gameID, PhaseID: "p2", Power: "france", Strategy: "hard", ComputeMS: 600}}
+	downgraded, err := svc.RecordPhase(ctx, game, over)
+	if err != nil {
+		t.Fatalf("RecordPhase: %v", err)
+	}
+	if len(downgraded) != 1 || downgraded["france"] != "medium" {
+		t.Errorf("expected only france downgraded to medium, got %v", downgraded)
+	}
+	for _, p := range gameRepo.players[gameID] {
+		if p.Power == "france" && p.BotDifficulty != "medium" {
+			t.Errorf("expected the stored difficulty to be medium, got %q", p.BotDifficulty)
+		}
+	}
+	if hooked != 2 {
+		t.Errorf("expected the hook to see 2 records, got %d", hooked)
+	}
+
+	// The next step down waits until the game has used twice its cap.
+	still := []model.BotCompute{{GameID: gameID, PhaseID: "p3", Power: "france", Strategy: "medium", ComputeMS: 600}}
+	if downgraded, err := svc.RecordPhase(ctx, game, still); err != nil || len(downgraded) != 0 {
+		t.Fatalf("expected no second downgrade under twice the cap, got %v, %v", downgraded, err)
+	}
+	again := []model.BotCompute{{GameID: gameID, PhaseID: "p4", Power: "france", Strategy: "medium", ComputeMS: 300}}
+	if downgraded, err := svc.RecordPhase(ctx, game, again); err != nil || downgraded["france"] != "easy" {
+		t.Fatalf("expected france downgraded to easy at twice the cap, got %v, %v", downgraded, err)
+	}
+
+	report, err := svc.GameReport(ctx, gameID)
+	if err != nil {
+		t.Fatalf("GameReport: %v", err)
+	}
+	if report.ComputeMS != 2100 || len(report.Phases) != 4 || report.Phases[1].ByPower["france"] != 600 || report.CapMS != 1000 {
+		t.Errorf("unexpected report %+v", report)
+	}
+}
+
+func TestSubmitBotOrdersRecordsCompute(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	cache := newMockCache()
+	computeRepo := &mockComputeRepo{}
+	computeSvc := NewComputeService(computeRepo, gameRepo)
+	computeSvc.SetGameCap(time.Nanosecond) // always over budget
+	bc := &recordingBroadcaster{}
+	phaseSvc := NewPhaseService(gameRepo, phaseRepo, cache, bc)
+	phaseSvc.SetComputeService(computeSvc)
+
+	gameID := botGame(t, gameRepo, phaseRepo, cache, "medium", "hold")
+	if err := phaseSvc.SubmitBotOrders(context.Background(), gameID); err != nil {
+		t.Fatalf("SubmitBotOrders: %v", err)
+	}
+
+	if len(computeRepo.records) != 2 {
+		t.Fatalf("expected a compute record per bot, got %+v", computeRepo.records)
+	}
+	for _, rec := range computeRepo.records {
+		if rec.GameID != gameID || rec.PhaseID == "" || (rec.Strategy != "medium" && rec.Strategy != "hold") {
+			t.Errorf("unexpected record %+v", rec)
+		}
+	}
+	for _, p := range gameRepo.players[gameID] {
+		if p.Power == "france" && p.BotDifficulty != "easy" {
+			t.Errorf("expected medium to be downgraded to easy, got %q", p.BotDifficulty)
+		}
+		if p.Power == "germany" && p.BotDifficulty != "hold" {
+			t.Errorf("expected hold to stay, got %q", p.BotDifficulty)
+		}
+	}
+	if len(bc.eventsOfType("bots_downgraded")) != 1 {
+		t.Error("expected a bots_downgraded event")
+	}
+}
+
+func TestSubmitBotOrdersKeepsStrategyInDuel(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	computeRepo := &mockComputeRepo{}
+	gameSvc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
+	phaseSvc := NewPhaseService(gameRepo, phaseRepo, newMockCache(), &recordingBroadcaster{})
+	phaseSvc.SetComputeService(NewComputeService(computeRepo, gameRepo))
+	ctx := context.Background()
+
+	game, err := gameSvc.CreateGame(ctx, "Duel", "user-1", "", "", "", "", "", "france-austria", false, model.GameRules{})
+	if err != nil {
+		t.Fatalf("CreateGame: %v", err)
+	}
+	for i, p := range gameRepo.players[game.ID] {
+		if p.IsBot {
+			gameRepo.players[game.ID][i].BotDifficulty = "hard"
+		}
+	}
+	if _, err := gameSvc.StartGame(ctx, game.ID, "user-1"); err != nil {
+		t.Fatalf("StartGame: %v", err)
+	}
+	if err := phaseSvc.SubmitBotOrders(ctx, game.ID); err != nil {
+		t.Fatalf("SubmitBotOrders: %v", err)
+	}
+
+	if len(computeRepo.records) != 1 || computeRepo.records[0].Strategy != "hard" {
+		t.Errorf("expected the duel's hard bot to play hard, got %+v", computeRepo.records)
+	}
+}
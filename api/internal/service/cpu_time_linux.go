@@ -0,0 +1,19 @@
+package service
+
+import (
+	"syscall"
+	"time"
+)
+
+// rusageThread is Linux's RUSAGE_THREAD, which package syscall doesn't name.
+const rusageThread = 1
+
+// threadCPUTime returns the CPU time the calling OS thread has used. The
+// caller must be locked to its thread with runtime.LockOSThread.
+func threadCPUTime() (time.Duration, bool) {
+	var ru syscall.Rusage
+	if err := syscall.Getrusage(rusageThread, &ru); err != nil {
+		return 0, false
+	}
+	return time.Duration(ru.Utime.Nano() + ru.Stime.Nano()), true
+}
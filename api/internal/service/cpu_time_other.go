@@ -0,0 +1,11 @@
+//go:build !linux
+
+package service
+
+import "time"
+
+// threadCPUTime reports that per-thread CPU time is unavailable on this
+// platform.
+func threadCPUTime() (time.Duration, bool) {
+	return 0, false
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/freeeve/polite-betrayal/api/internal/bot"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+var ErrNoDaideGame = errors.New("no waiting game is open to DAIDE clients")
+
+// GameWatcher tells in-process listeners when a game has an event, such as
+// the hub that also sends them to the game's WebSocket subscribers.
+type GameWatcher interface {
+	// WatchGame returns a channel that receives a value after each event
+	// of the game, and a function that stops watching.
+	WatchGame(gameID string) (<-chan struct{}, func())
+}
+
+// DaideHost seats DAIDE clients in waiting games that have the daide flag
+// on and plays their orders through the regular order and phase services.
+type DaideHost struct {
+	gameSvc  *GameService
+	orderSvc *OrderService
+	phaseSvc *PhaseService
+	flagSvc  *FlagService
+	keySvc   *APIKeyService
+	watcher  GameWatcher
+}
+
+// NewDaideHost creates a DaideHost. Clients sign in with their owner's
+// public API keys, checked by keySvc.
+func NewDaideHost(gameSvc *GameService, orderSvc *OrderService, phaseSvc *PhaseService, flagSvc *FlagService, keySvc *APIKeyService) *DaideHost {
+	return &DaideHost{gameSvc: gameSvc, orderSvc: orderSvc, phaseSvc: phaseSvc, flagSvc: flagSvc, keySvc: keySvc}
+}
+
+// SetWatcher lets sessions hear about their game's events as they happen
+// instead of polling for them.
+func (h *DaideHost) SetWatcher(w GameWatcher) {
+	h.watcher = w
+}
+
+// Join seats the owner of key in the newest open game flagged for DAIDE,
+// replacing a bot if the game is full. Games on other maps than the
+// standard one, such as duels, are skipped, since sessions speak only the
+// standard map. The key must be an active public API key; the client's name
+// is only logged.
+func (h *DaideHost) Join(ctx context.Context, name, key, version string) (bot.DaideSeat, error) {
+	apiKey, err := h.keySvc.Authenticate(ctx, key)
+	if err != nil {
+		return bot.DaideSeat{}, err
+	}
+	games, err := h.gameSvc.gameRepo.ListOpen(ctx)
+	if err != nil {
+		return bot.DaideSeat{}, err
+	}
+	for _, g := range games {
+		if !h.flagSvc.Enabled(ctx, g.ID, FlagDaide) || gameScenario(&g).Map() != diplomacy.StandardMap() {
+			continue
+		}
+		err := h.gameSvc.JoinGame(ctx, g.ID, apiKey.UserID)
+		if err == nil || errors.Is(err, ErrAlreadyJoined) {
+			return bot.DaideSeat{GameID: g.ID, UserID: apiKey.UserID}, nil
+		}
+		log.Debug().Err(err).Str("gameId", g.ID).Str("name", name).Msg("DAIDE client could not join game")
+	}
+	return bot.DaideSeat{}, ErrNoDaideGame
+}
+
+// Events passes on the watcher's news of the seat's game until ctx is
+// done, or returns nil without a watcher.
+func (h *DaideHost) Events(ctx context.Context, seat bot.DaideSeat) <-chan struct{} {
+	if h.watcher == nil {
+		return nil
+	}
+	ch, stop := h.watcher.WatchGame(seat.GameID)
+	context.AfterFunc(ctx, stop)
+	return ch
+}
+
+// Position reports the seat's power and the game's current phase.
+func (h *DaideHost) Position(ctx context.Context, seat bot.DaideSeat) (*bot.DaidePosition, error) {
+	game, err := h.gameSvc.gameRepo.FindByID(ctx, seat.GameID)
+	if err != nil {
+		return nil, err
+	}
+	if game == nil {
+		return nil, ErrGameNotFound
+	}
+	pos := &bot.DaidePosition{Status: diplomacy.GameStatus(game.Status), Winner: diplomacy.Power(game.Winner)}
+	for _, p := range game.Players {
+		if p.UserID == seat.UserID {
+			pos.Power = diplomacy.Power(p.Power)
+		}
+	}
+	if game.Status == "deleted" {
+		pos.Status = diplomacy.StatusFinished
+	}
+	if game.Status != "active" {
+		return pos, nil
+	}
+	phase, err := h.gameSvc.phaseRepo.CurrentPhase(ctx, seat.GameID)
+	if err != nil || phase == nil {
+		return pos, err
+	}
+	var gs diplomacy.GameState
+	if err := json.Unmarshal(phase.StateBefore, &gs); err != nil {
+		return nil, fmt.Errorf("unmarshal game state: %w", err)
+	}
+	pos.PhaseID, pos.State, pos.Deadline = phase.ID, &gs, phase.Deadline
+	return pos, nil
+}
+
+// Results returns the adjudicated orders of a resolved phase.
+func (h *DaideHost) Results(ctx context.Context, _ bot.DaideSeat, phaseID string) ([]bot.DaideResult, error) {
+	orders, err := h.orderSvc.GetOrders(ctx, phaseID)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]bot.DaideResult, 0, len(orders))
+	for _, o := range orders {
+		results = append(results, bot.DaideResult{
+			Power: o.Power,
+			Order: bot.OrderInput{
+				UnitType:    o.UnitType,
+				Location:    o.Location,
+				OrderType:   o.OrderType,
+				Target:      o.Target,
+				AuxLoc:      o.AuxLoc,
+				AuxTarget:   o.AuxTarget,
+				AuxUnitType: o.AuxUnitType,
+			},
+			Result: o.Result,
+		})
+	}
+	return results, nil
+}
+
+// SubmitOrders replaces the seat's orders for the current phase.
+func (h *DaideHost) SubmitOrders(ctx context.Context, seat bot.DaideSeat, orders []bot.OrderInput) error {
+	inputs := make([]OrderInput, len(orders))
+	for i, o := range orders {
+		inputs[i] = OrderInput(o)
+	}
+	_, err := h.orderSvc.SubmitOrders(ctx, seat.GameID, seat.UserID, inputs)
+	return err
+}
+
+// SetReady marks the seat ready, resolving the phase early once every power
+// is, or takes the mark back.
+func (h *DaideHost) SetReady(ctx context.Context, seat bot.DaideSeat, ready bool) error {
+	if !ready {
+		return h.orderSvc.UnmarkReady(ctx, seat.GameID, seat.UserID)
+	}
+	readyCount, total, err := h.orderSvc.MarkReady(ctx, seat.GameID, seat.UserID)
+	if err != nil {
+		return err
+	}
+	if int(readyCount) < total {
+		return h.phaseSvc.CheckReadyQuorum(ctx, seat.GameID)
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := h.phaseSvc.ResolvePhaseEarly(ctx, seat.GameID); err != nil {
+			log.Error().Err(err).Str("gameId", seat.GameID).Msg("Early resolution failed")
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/freeeve/polite-betrayal/api/internal/bot"
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+func TestDaideHost(t *testing.T) {
+	ctx := context.Background()
+	gameRepo, phaseRepo, cache := newMockGameRepo(), newMockPhaseRepo(), newMockCache()
+	gameSvc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
+	flagSvc := NewFlagService(newMockFlagStore())
+	keySvc := NewAPIKeyService(newMockAPIKeyRepo())
+	host := NewDaideHost(gameSvc, NewOrderService(gameRepo, phaseRepo, cache), NewPhaseService(gameRepo, phaseRepo, cache, nil), flagSvc, keySvc)
+	_, key, err := keySvc.CreateKey(ctx, "user-9", "DumbBot")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := gameSvc.CreateGame(ctx, "Humans only", "user-1", "", "", "", "", "", "", false, model.GameRules{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := host.Join(ctx, "DumbBot", key, "v1"); err != ErrNoDaideGame {
+		t.Fatalf("expected ErrNoDaideGame without a flagged game, got %v", err)
+	}
+	game, _ := gameSvc.CreateGame(ctx, "Open to DAIDE", "user-1", "", "", "", "", "", "", false, model.GameRules{})
+	if err := flagSvc.SetGameFlag(ctx, game.ID, FlagDaide, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := host.Join(ctx, "DumbBot", "pb_forged", "v1"); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Fatalf("expected ErrInvalidAPIKey for an unknown key, got %v", err)
+	}
+	seat, err := host.Join(ctx, "DumbBot", key, "v1")
+	if err != nil {
+		t.Fatalf("Join: %v", err)
+	}
+	if seat.GameID != game.ID || seat.UserID != "user-9" {
+		t.Fatalf("seated %+v, want the key's owner in %s", seat, game.ID)
+	}
+	if again, err := host.Join(ctx, "Albert", key, "v1"); err != nil || again != seat {
+		t.Errorf("rejoining gave %+v, %v; want the same seat", again, err)
+	}
+	if pos, err := host.Position(ctx, seat); err != nil || pos.Status != diplomacy.StatusWaiting || pos.State != nil {
+		t.Fatalf("position before start = %+v, %v", pos, err)
+	}
+
+	if _, err := gameSvc.StartGame(ctx, game.ID, "user-1"); err != nil {
+		t.Fatalf("StartGame: %v", err)
+	}
+	pos, err := host.Position(ctx, seat)
+	if err != nil || pos.Status != diplomacy.StatusActive || pos.Power == "" || pos.State == nil || pos.PhaseID == "" {
+		t.Fatalf("position after start = %+v, %v", pos, err)
+	}
+	unit := pos.State.UnitsOf(pos.Power)[0]
+	hold := bot.OrderInput{UnitType: unit.Type.String(), Location: unit.Province, Coast: string(unit.Coast), OrderType: "hold"}
+	if err := host.SubmitOrders(ctx, seat, []bot.OrderInput{hold}); err != nil {
+		t.Fatalf("SubmitOrders: %v", err)
+	}
+	if err := host.SetReady(ctx, seat, true); err != nil {
+		t.Fatalf("SetReady: %v", err)
+	}
+	if ready, _ := cache.ReadyPowers(ctx, game.ID); len(ready) != 1 || ready[0] != string(pos.Power) {
+		t.Error("expected the seat's power to be ready")
+	}
+}
+
+func TestDaideHostSkipsOtherMaps(t *testing.T) {
+	ctx := context.Background()
+	gameRepo, phaseRepo, cache := newMockGameRepo(), newMockPhaseRepo(), newMockCache()
+	gameSvc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
+	flagSvc := NewFlagService(newMockFlagStore())
+	keySvc := NewAPIKeyService(newMockAPIKeyRepo())
+	host := NewDaideHost(gameSvc, NewOrderService(gameRepo, phaseRepo, cache), NewPhaseService(gameRepo, phaseRepo, cache, nil), flagSvc, keySvc)
+	_, key, err := keySvc.CreateKey(ctx, "user-9", "DumbBot")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	game, err := gameSvc.CreateGame(ctx, "Duel", "user-1", "", "", "", "", "", "france-austria", false, model.GameRules{})
+	if err != nil {
+		t.Fatalf("CreateGame: %v", err)
+	}
+	if err := flagSvc.SetGameFlag(ctx, game.ID, FlagDaide, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := host.Join(ctx, "DumbBot", key, "v1"); err != ErrNoDaideGame {
+		t.Fatalf("expected ErrNoDaideGame for a duel, got %v", err)
+	}
+	for _, p := range gameRepo.players[game.ID] {
+		if p.UserID == "user-9" {
+			t.Error("expected the client not to be seated in the duel")
+		}
+	}
+}
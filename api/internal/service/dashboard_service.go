@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/internal/repository"
+)
+
+// Dashboard aggregates what a user needs to act on across their active
+// games, so the home screen can be drawn from one request.
+type Dashboard struct {
+	Games          []DashboardGame `json:"games"`
+	AwaitingOrders int             `json:"awaiting_orders"` // games waiting on the user's orders
+	UnreadMessages int             `json:"unread_messages"` // across all games
+	PendingDraws   int             `json:"pending_draws"`   // games with a draw vote the user hasn't joined
+
+	// Unavailable lists the sections that could not be loaded, which are
+	// left empty: "orders", "unread_messages" or "draw_votes".
+	Unavailable []string `json:"unavailable,omitempty"`
+}
+
+// Dashboard sections that degrade on their own when their store fails.
+const (
+	dashboardOrders    = "orders"
+	dashboardUnread    = "unread_messages"
+	dashboardDrawVotes = "draw_votes"
+)
+
+// DashboardGame is one active game's pending actions for the user.
+type DashboardGame struct {
+	GameID           string    `json:"game_id"`
+	Name             string    `json:"name"`
+	Power            string    `json:"power"`
+	Year             int       `json:"year"`
+	Season           string    `json:"season"`
+	PhaseType        string    `json:"phase_type"`
+	Deadline         time.Time `json:"deadline"`
+	SecondsRemaining int64     `json:"seconds_remaining"` // 0 once the deadline has passed
+	AwaitingOrders   bool      `json:"awaiting_orders"`   // the user's power is not ready
+	OrdersSubmitted  bool      `json:"orders_submitted"`
+	UnreadMessages   int       `json:"unread_messages"`
+	DrawVotes        []string  `json:"draw_votes,omitempty"` // powers voting for a draw this phase
+	DrawVotePending  bool      `json:"draw_vote_pending"`    // others voted for a draw, the user hasn't
+}
+
+// DashboardService builds the multi-game dashboard.
+type DashboardService struct {
+	gameRepo    repository.GameRepository
+	phaseRepo   repository.PhaseRepository
+	messageRepo repository.MessageRepository
+	cache       repository.GameCache
+}
+
+// NewDashboardService creates a DashboardService.
+func NewDashboardService(gameRepo repository.GameRepository, phaseRepo repository.PhaseRepository, messageRepo repository.MessageRepository, cache repository.GameCache) *DashboardService {
+	return &DashboardService{gameRepo: gameRepo, phaseRepo: phaseRepo, messageRepo: messageRepo, cache: cache}
+}
+
+// Dashboard returns the user's active games with their pending actions,
+// games awaiting the user's orders first, then by soonest deadline. Only
+// failing to load the games fails the request; orders, unread counts and
+// draw votes that cannot be loaded are listed in Unavailable instead.
+func (s *DashboardService) Dashboard(ctx context.Context, userID string) (*Dashboard, error) {
+	games, err := s.gameRepo.ListActiveByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(games))
+	for i, g := range games {
+		ids[i] = g.ID
+	}
+	d := &Dashboard{Games: []DashboardGame{}}
+	if len(ids) == 0 {
+		return d, nil
+	}
+	phases, err := s.phaseRepo.CurrentPhases(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	unread, err := s.messageRepo.CountUnreadByGame(ctx, ids, userID)
+	if err != nil {
+		d.unavailable(dashboardUnread, userID, err)
+	}
+
+	for i := range games {
+		phase, ok := phases[games[i].ID]
+		if !ok {
+			continue
+		}
+		dg := s.dashboardGame(ctx, d, &games[i], phase, userID)
+		if dg == nil {
+			continue
+		}
+		dg.UnreadMessages = unread[dg.GameID]
+		d.Games = append(d.Games, *dg)
+		if dg.AwaitingOrders {
+			d.AwaitingOrders++
+		}
+		if dg.DrawVotePending {
+			d.PendingDraws++
+		}
+		d.UnreadMessages += dg.UnreadMessages
+	}
+	sort.SliceStable(d.Games, func(i, j int) bool {
+		a, b := d.Games[i], d.Games[j]
+		if a.AwaitingOrders != b.AwaitingOrders {
+			return a.AwaitingOrders
+		}
+		return a.Deadline.Before(b.Deadline)
+	})
+	return d, nil
+}
+
+// dashboardGame returns one game's entry, or nil if the user has no power
+// in it. Cache failures mark their section unavailable on d.
+func (s *DashboardService) dashboardGame(ctx context.Context, d *Dashboard, game *model.Game, phase model.Phase, userID string) *DashboardGame {
+	power := ""
+	for _, p := range game.Players {
+		if p.UserID == userID {
+			power = p.Power
+			break
+		}
+	}
+	if power == "" {
+		return nil
+	}
+
+	dg := &DashboardGame{
+		GameID:           game.ID,
+		Name:             game.Name,
+		Power:            power,
+		Year:             phase.Year,
+		Season:           phase.Season,
+		PhaseType:        phase.PhaseType,
+		Deadline:         phase.Deadline,
+		SecondsRemaining: max(0, int64(time.Until(phase.Deadline).Seconds())),
+	}
+	if ready, err := s.cache.ReadyPowers(ctx, game.ID); err != nil {
+		d.unavailable(dashboardOrders, userID, err)
+	} else {
+		dg.AwaitingOrders = !slices.Contains(ready, power)
+	}
+	if orders, err := s.cache.GetOrders(ctx, game.ID, power); err != nil {
+		d.unavailable(dashboardOrders, userID, err)
+	} else {
+		dg.OrdersSubmitted = orders != nil
+	}
+	if votes, err := s.cache.DrawVotePowers(ctx, game.ID); err != nil {
+		d.unavailable(dashboardDrawVotes, userID, err)
+	} else {
+		dg.DrawVotes = votes
+		dg.DrawVotePending = len(votes) > 0 && !slices.Contains(votes, power)
+	}
+	return dg
+}
+
+// unavailable records that section could not be loaded.
+func (d *Dashboard) unavailable(section, userID string, err error) {
+	if slices.Contains(d.Unavailable, section) {
+		return
+	}
+	log.Warn().Err(err).Str("userId", userID).Str("section", section).Msg("Dashboard section unavailable")
+	d.Unavailable = append(d.Unavailable, section)
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+)
+
+func TestDashboard(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	cache := newMockCache()
+	messageRepo := newMockMessageRepo()
+	svc := NewDashboardService(gameRepo, phaseRepo, messageRepo, cache)
+	ctx := context.Background()
+
+	readyGame, _ := setupActiveGame(t, gameRepo, phaseRepo, cache)
+	waitingGame, _ := setupActiveGame(t, gameRepo, phaseRepo, cache)
+	if _, err := NewGameService(gameRepo, phaseRepo, newMockUserRepo()).CreateGame(ctx, "Lobby", "user-1", "24h", "12h", "12h", "", "", "", false, model.GameRules{}); err != nil {
+		t.Fatalf("create game: %v", err)
+	}
+
+	power, _ := playerUnits(t, gameRepo, readyGame, "user-1")
+	cache.MarkReady(ctx, readyGame, power)
+	other, _ := playerUnits(t, gameRepo, waitingGame, "user-2")
+	cache.AddDrawVote(ctx, waitingGame, other)
+
+	messageRepo.Create(ctx, readyGame, "user-2", "", "public", "", nil)
+	messageRepo.Create(ctx, readyGame, "user-3", "user-1", "private to me", "", nil)
+	messageRepo.Create(ctx, readyGame, "user-2", "user-3", "private to others", "", nil)
+	messageRepo.Create(ctx, readyGame, "user-1", "", "my own", "", nil)
+
+	d, err := svc.Dashboard(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("Dashboard: %v", err)
+	}
+	if len(d.Games) != 2 {
+		t.Fatalf("expected 2 active games, got %d", len(d.Games))
+	}
+	if d.AwaitingOrders != 1 || d.UnreadMessages != 2 || d.PendingDraws != 1 {
+		t.Errorf("totals = awaiting %d, unread %d, draws %d; want 1, 2, 1", d.AwaitingOrders, d.UnreadMessages, d.PendingDraws)
+	}
+
+	// Games awaiting orders come first.
+	first, second := d.Games[0], d.Games[1]
+	if first.GameID != waitingGame || !first.AwaitingOrders || !first.DrawVotePending {
+		t.Errorf("first game = %+v, want %s awaiting orders with a pending draw", first, waitingGame)
+	}
+	if second.GameID != readyGame || second.AwaitingOrders || second.UnreadMessages != 2 {
+		t.Errorf("second game = %+v, want %s ready with 2 unread", second, readyGame)
+	}
+	if second.Power != power || second.Season != "spring" || second.SecondsRemaining <= 0 {
+		t.Errorf("second game phase = %s %s, %ds left", second.Power, second.Season, second.SecondsRemaining)
+	}
+
+	messageRepo.MarkRead(ctx, readyGame, "user-1")
+	d, _ = svc.Dashboard(ctx, "user-1")
+	if d.UnreadMessages != 0 {
+		t.Errorf("unread after reading = %d, want 0", d.UnreadMessages)
+	}
+}
+
+// failingUnreadRepo fails every unread count.
+type failingUnreadRepo struct{ *mockMessageRepo }
+
+func (failingUnreadRepo) CountUnreadByGame(context.Context, []string, string) (map[string]int, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestDashboardDegradesUnreadCounts(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	cache := newMockCache()
+	svc := NewDashboardService(gameRepo, phaseRepo, failingUnreadRepo{newMockMessageRepo()}, cache)
+	gameID, _ := setupActiveGame(t, gameRepo, phaseRepo, cache)
+
+	d, err := svc.Dashboard(context.Background(), "user-1")
+	if err != nil {
+		t.Fatalf("Dashboard: %v", err)
+	}
+	if len(d.Games) != 1 || d.Games[0].GameID != gameID || !d.Games[0].AwaitingOrders {
+		t.Errorf("expected the game to be listed despite the failure, got %+v", d.Games)
+	}
+	if !slices.Equal(d.Unavailable, []string{"unread_messages"}) {
+		t.Errorf("unavailable = %v, want [unread_messages]", d.Unavailable)
+	}
+}
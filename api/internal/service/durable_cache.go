@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/freeeve/polite-betrayal/api/internal/repository"
+)
+
+// errNoPhase is returned by DurableCache when a submission is written without
+// naming the phase it belongs to.
+var errNoPhase = errors.New("no phase to record the submission in")
+
+type phaseKey struct{}
+
+// withPhase returns a context naming the phase that orders and ready flags
+// written through it belong to, so DurableCache records them in that phase
+// even if the game has moved on by the time they are written.
+func withPhase(ctx context.Context, phaseID string) context.Context {
+	return context.WithValue(ctx, phaseKey{}, phaseID)
+}
+
+// phaseFromContext returns the phase set by withPhase.
+func phaseFromContext(ctx context.Context) (string, error) {
+	id, _ := ctx.Value(phaseKey{}).(string)
+	if id == "" {
+		return "", errNoPhase
+	}
+	return id, nil
+}
+
+// DurableCache is a GameCache that also records submitted orders and ready
+// flags in Postgres, so recovery after a restart restores them instead of
+// starting the phase over. Orders and ready flags must be written with a
+// context from withPhase. Redis stays the source of truth while the server
+// runs; a failure to record a submission is returned after Redis is updated.
+type DurableCache struct {
+	repository.GameCache
+	phaseRepo repository.PhaseRepository
+}
+
+// NewDurableCache wraps cache so submissions are also recorded in phaseRepo.
+func NewDurableCache(cache repository.GameCache, phaseRepo repository.PhaseRepository) *DurableCache {
+	return &DurableCache{GameCache: cache, phaseRepo: phaseRepo}
+}
+
+func (c *DurableCache) SetOrders(ctx context.Context, gameID, power string, orders json.RawMessage) error {
+	phaseID, err := phaseFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if err := c.GameCache.SetOrders(ctx, gameID, power, orders); err != nil {
+		return err
+	}
+	if err := c.phaseRepo.SaveSubmittedOrders(ctx, phaseID, power, orders); err != nil {
+		return fmt.Errorf("record submitted orders: %w", err)
+	}
+	return nil
+}
+
+func (c *DurableCache) UpdateOrders(ctx context.Context, gameID, power string, update func(orders json.RawMessage) (json.RawMessage, error)) error {
+	phaseID, err := phaseFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	var saved json.RawMessage
+	err = c.GameCache.UpdateOrders(ctx, gameID, power, func(orders json.RawMessage) (json.RawMessage, error) {
+		next, err := update(orders)
+		saved = next
+		return next, err
+	})
+	if err != nil {
+		return err
+	}
+	if err := c.phaseRepo.SaveSubmittedOrders(ctx, phaseID, power, saved); err != nil {
+		return fmt.Errorf("record submitted orders: %w", err)
+	}
+	return nil
+}
+
+func (c *DurableCache) MarkReady(ctx context.Context, gameID, power string) error {
+	return c.setReady(ctx, gameID, power, true)
+}
+
+func (c *DurableCache) UnmarkReady(ctx context.Context, gameID, power string) error {
+	return c.setReady(ctx, gameID, power, false)
+}
+
+func (c *DurableCache) ClearPowerData(ctx context.Context, gameID, power string) error {
+	if err := c.GameCache.ClearPowerData(ctx, gameID, power); err != nil {
+		return err
+	}
+	if err := c.phaseRepo.ClearSubmission(ctx, gameID, power); err != nil {
+		return fmt.Errorf("clear recorded submission: %w", err)
+	}
+	return nil
+}
+
+func (c *DurableCache) setReady(ctx context.Context, gameID, power string, ready bool) error {
+	phaseID, err := phaseFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if ready {
+		err = c.GameCache.MarkReady(ctx, gameID, power)
+	} else {
+		err = c.GameCache.UnmarkReady(ctx, gameID, power)
+	}
+	if err != nil {
+		return err
+	}
+	if err := c.phaseRepo.SetSubmittedReady(ctx, phaseID, power, ready); err != nil {
+		return fmt.Errorf("record ready flag: %w", err)
+	}
+	return nil
+}
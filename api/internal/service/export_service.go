@@ -0,0 +1,333 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/internal/repository"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// ErrGameNotFinished is returned when exporting a game that is still in
+// progress; exports include private press, so only finished games qualify.
+var ErrGameNotFinished = errors.New("game is not finished")
+
+// GameExport is a finished game with everything needed to replay it or to
+// train press-aware models on it.
+type GameExport struct {
+	Game     *model.Game     `json:"game"`
+	Phases   []PhaseExport   `json:"phases"`
+	Channels []model.Channel `json:"channels"`
+}
+
+// PhaseExport is one phase of a GameExport: the board before and after, the
+// orders played, the press sent during the phase, the deals it produced and,
+// once the game has been analyzed, each power's evaluation after it resolved.
+type PhaseExport struct {
+	model.Phase
+	Orders      []model.Order           `json:"orders"`
+	Messages    []model.Message         `json:"messages"`
+	Agreements  []Agreement             `json:"agreements"`
+	Evaluations []model.PhaseEvaluation `json:"evaluations"`
+}
+
+// TrainingSample is one (state, press, deals, orders) tuple from a single
+// power's point of view, the unit of the training-data export.
+type TrainingSample struct {
+	GameID     string          `json:"game_id"`
+	Year       int             `json:"year"`
+	Season     string          `json:"season"`
+	Phase      string          `json:"phase"`
+	Power      string          `json:"power"`
+	DFEN       string          `json:"dfen"`
+	Press      []TrainingPress `json:"press"`
+	Agreements []Agreement     `json:"agreements"`
+	Orders     []model.Order   `json:"orders"`
+	Winner     string          `json:"winner,omitempty"`
+}
+
+// TrainingPress is a message as seen by one power, with users replaced by
+// powers so samples do not depend on account IDs.
+type TrainingPress struct {
+	From    string               `json:"from"`
+	To      string               `json:"to,omitempty"`      // empty = public broadcast or channel
+	Channel []string             `json:"channel,omitempty"` // member powers of a press channel
+	Content string               `json:"content"`
+	Intent  *model.MessageIntent `json:"intent,omitempty"`
+}
+
+// ExportService builds exports of finished games.
+type ExportService struct {
+	gameRepo    repository.GameRepository
+	phaseRepo   repository.PhaseRepository
+	messageRepo repository.MessageRepository
+	channelRepo repository.ChannelRepository // optional: needed to export channel press
+}
+
+// NewExportService creates an ExportService.
+func NewExportService(gameRepo repository.GameRepository, phaseRepo repository.PhaseRepository, messageRepo repository.MessageRepository) *ExportService {
+	return &ExportService{gameRepo: gameRepo, phaseRepo: phaseRepo, messageRepo: messageRepo}
+}
+
+// SetChannelRepo configures the optional channel repository. Without it,
+// exports carry no channels and training samples leave out channel press.
+func (s *ExportService) SetChannelRepo(repo repository.ChannelRepository) {
+	s.channelRepo = repo
+}
+
+// ExportGame returns the full export of a finished game.
+func (s *ExportService) ExportGame(ctx context.Context, gameID string) (*GameExport, error) {
+	game, err := s.finishedGame(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	phases, err := s.phaseRepo.ListPhases(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := s.messageRepo.ListAllByGame(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	byPhase := make(map[string][]model.Message)
+	for _, msg := range messages {
+		byPhase[msg.PhaseID] = append(byPhase[msg.PhaseID], msg)
+	}
+	evals, err := s.phaseRepo.EvaluationsByGame(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	evalsByPhase := make(map[string][]model.PhaseEvaluation)
+	for _, e := range evals {
+		evalsByPhase[e.PhaseID] = append(evalsByPhase[e.PhaseID], e)
+	}
+	powerByUser := powersByUser(game)
+
+	export := &GameExport{Game: game, Phases: make([]PhaseExport, 0, len(phases)), Channels: []model.Channel{}}
+	if s.channelRepo != nil {
+		channels, err := s.channelRepo.ListByGame(ctx, gameID)
+		if err != nil {
+			return nil, err
+		}
+		export.Channels = nonNil(channels)
+	}
+	for _, phase := range phases {
+		orders, err := s.phaseRepo.OrdersByPhase(ctx, phase.ID)
+		if err != nil {
+			return nil, err
+		}
+		msgs := byPhase[phase.ID]
+		export.Phases = append(export.Phases, PhaseExport{
+			Phase:       phase,
+			Orders:      nonNil(orders),
+			Messages:    nonNil(msgs),
+			Agreements:  nonNil(acceptedAgreements(msgs, powerByUser)),
+			Evaluations: nonNil(evalsByPhase[phase.ID]),
+		})
+	}
+	return export, nil
+}
+
+// ExportGameFor returns the export of a finished game as viewerID may see
+// it. Players of the game get every message; anyone else gets only public
+// press and no channels, so private press never leaves the game through an
+// export. Anonymous games show everyone but the viewer by AnonymousID, even
+// after they finish. ExportGame is the unfiltered export for admins and the
+// offline tools.
+func (s *ExportService) ExportGameFor(ctx context.Context, gameID, viewerID string) (*GameExport, error) {
+	export, err := s.ExportGame(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	return redactExport(export, viewerID), nil
+}
+
+// redactExport filters export down to what viewerID may see; see
+// ExportGameFor.
+func redactExport(export *GameExport, viewerID string) *GameExport {
+	game := export.Game
+	player := false
+	for _, p := range game.Players {
+		if p.UserID == viewerID && p.Power != "" {
+			player = true
+		}
+	}
+	hide := game.Anonymous || !player
+	userID := func(id string) string {
+		if !hide || id == "" || id == viewerID {
+			return id
+		}
+		for _, p := range game.Players {
+			if p.UserID == id && p.Power != "" {
+				return AnonymousID(p.Power)
+			}
+		}
+		return ""
+	}
+
+	redacted := &GameExport{Game: game, Phases: make([]PhaseExport, 0, len(export.Phases)), Channels: []model.Channel{}}
+	if hide {
+		anon := *game
+		anon.Players = slices.Clone(game.Players)
+		for i, p := range anon.Players {
+			anon.Players[i].UserID = userID(p.UserID)
+		}
+		anon.CreatorID = userID(game.CreatorID)
+		redacted.Game = &anon
+	}
+	if player {
+		for _, c := range export.Channels {
+			c.CreatedBy = userID(c.CreatedBy)
+			c.Members = slices.Clone(c.Members)
+			for i, m := range c.Members {
+				c.Members[i] = userID(m)
+			}
+			redacted.Channels = append(redacted.Channels, c)
+		}
+	}
+	powerByUser := powersByUser(redacted.Game)
+	for _, phase := range export.Phases {
+		msgs := []model.Message{}
+		for _, msg := range phase.Messages {
+			if !player && (msg.RecipientID != "" || msg.ChannelID != "") {
+				continue
+			}
+			msg.SenderID = userID(msg.SenderID)
+			msg.RecipientID = userID(msg.RecipientID)
+			msgs = append(msgs, msg)
+		}
+		phase.Messages = msgs
+		phase.Agreements = nonNil(acceptedAgreements(msgs, powerByUser))
+		redacted.Phases = append(redacted.Phases, phase)
+	}
+	return redacted
+}
+
+// finishedGame loads a game, returning ErrGameNotFound or ErrGameNotFinished
+// unless it exists and is over.
+func (s *ExportService) finishedGame(ctx context.Context, gameID string) (*model.Game, error) {
+	game, err := s.gameRepo.FindByID(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	if game == nil || game.Status == "deleted" {
+		return nil, ErrGameNotFound
+	}
+	if game.Status != "finished" {
+		return nil, ErrGameNotFinished
+	}
+	return game, nil
+}
+
+// finishedGamesPage is how many finished games EachFinishedGame reads at a
+// time.
+const finishedGamesPage = 100
+
+// EachFinishedGame calls fn with every finished game, most recently finished
+// first and including its players, reading them a page at a time. It stops
+// early if fn returns false.
+func EachFinishedGame(ctx context.Context, gameRepo repository.GameRepository, fn func(model.Game) bool) error {
+	afterID := ""
+	for {
+		games, err := gameRepo.ListFinishedPage(ctx, "", afterID, finishedGamesPage)
+		if err != nil {
+			return err
+		}
+		for _, g := range games {
+			if !fn(g) {
+				return nil
+			}
+		}
+		if len(games) < finishedGamesPage {
+			return nil
+		}
+		afterID = games[len(games)-1].ID
+	}
+}
+
+// TrainingSamples flattens an export into one sample per phase and power,
+// holding only the press that power could see and the deals it is party to.
+func TrainingSamples(export *GameExport) ([]TrainingSample, error) {
+	powerByUser := powersByUser(export.Game)
+	channelPowers := make(map[string][]string)
+	for _, c := range export.Channels {
+		for _, userID := range c.Members {
+			channelPowers[c.ID] = append(channelPowers[c.ID], powerByUser[userID])
+		}
+	}
+	var samples []TrainingSample
+	for _, phase := range export.Phases {
+		var gs diplomacy.GameState
+		if err := json.Unmarshal(phase.StateBefore, &gs); err != nil {
+			return nil, fmt.Errorf("phase %s: unmarshal state: %w", phase.ID, err)
+		}
+		dfen := diplomacy.EncodeDFEN(&gs)
+
+		for _, power := range gs.ActivePowers() {
+			p := string(power)
+			if !gs.PowerIsAlive(power) {
+				continue
+			}
+			sample := TrainingSample{
+				GameID:     export.Game.ID,
+				Year:       phase.Year,
+				Season:     phase.Season,
+				Phase:      phase.PhaseType,
+				Power:      p,
+				DFEN:       dfen,
+				Press:      []TrainingPress{},
+				Agreements: []Agreement{},
+				Orders:     []model.Order{},
+				Winner:     export.Game.Winner,
+			}
+			for _, msg := range phase.Messages {
+				from, to := powerByUser[msg.SenderID], powerByUser[msg.RecipientID]
+				if msg.RecipientID != "" && from != p && to != p {
+					continue
+				}
+				members := channelPowers[msg.ChannelID]
+				if msg.ChannelID != "" && from != p && !slices.Contains(members, p) {
+					continue
+				}
+				sample.Press = append(sample.Press, TrainingPress{From: from, To: to, Channel: members, Content: msg.Content, Intent: msg.Intent})
+			}
+			for _, a := range phase.Agreements {
+				if a.Bound == p || a.With == p {
+					sample.Agreements = append(sample.Agreements, a)
+				}
+			}
+			for _, o := range phase.Orders {
+				if o.Power == p {
+					sample.Orders = append(sample.Orders, o)
+				}
+			}
+			samples = append(samples, sample)
+		}
+	}
+	return samples, nil
+}
+
+// powersByUser maps each player's user ID to the power they play.
+func powersByUser(game *model.Game) map[string]string {
+	m := make(map[string]string, len(game.Players))
+	for _, p := range game.Players {
+		if p.Power != "" {
+			m[p.UserID] = p.Power
+		}
+	}
+	return m
+}
+
+// nonNil returns s, or an empty slice if s is nil, so exports encode [] rather
+// than null.
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
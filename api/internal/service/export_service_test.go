@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+)
+
+// setupFinishedGame starts a standard game, finishes it and returns the game,
+// its only phase ID and a power -> user ID map.
+func setupFinishedGame(t *testing.T, gameRepo *mockGameRepo, phaseRepo *mockPhaseRepo) (*model.Game, string, map[string]string) {
+	t.Helper()
+	gameSvc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
+	game, _ := gameSvc.CreateGame(context.Background(), "Export", "user-1", "", "", "", "", "", "", false, model.GameRules{})
+	if _, err := gameSvc.StartGame(context.Background(), game.ID, "user-1"); err != nil {
+		t.Fatalf("StartGame: %v", err)
+	}
+	gameRepo.SetFinished(context.Background(), game.ID, "france")
+
+	userByPower := make(map[string]string)
+	for _, p := range gameRepo.players[game.ID] {
+		userByPower[p.Power] = p.UserID
+	}
+	var phaseID string
+	for id := range phaseRepo.phases {
+		phaseID = id
+	}
+	return game, phaseID, userByPower
+}
+
+func TestExportGameIncludesAgreements(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	messageRepo := newMockMessageRepo()
+	game, phaseID, users := setupFinishedGame(t, gameRepo, phaseRepo)
+
+	ctx := context.Background()
+	messageRepo.Create(ctx, game.ID, users["france"], users["germany"], "Please don't attack bur, I won't attack yours", phaseID, nil)
+	messageRepo.Create(ctx, game.ID, users["germany"], users["france"], "Agreed", phaseID, nil)
+	messageRepo.Create(ctx, game.ID, users["england"], users["russia"], "Hello", phaseID, nil)
+	phaseRepo.SaveOrders(ctx, []model.Order{
+		{PhaseID: phaseID, Power: "germany", UnitType: "army", Location: "mun", OrderType: "hold"},
+		{PhaseID: phaseID, Power: "france", UnitType: "army", Location: "par", OrderType: "move", Target: "bur"},
+	})
+
+	svc := NewExportService(gameRepo, phaseRepo, messageRepo)
+	export, err := svc.ExportGame(ctx, game.ID)
+	if err != nil {
+		t.Fatalf("ExportGame: %v", err)
+	}
+	if len(export.Phases) != 1 {
+		t.Fatalf("expected 1 phase, got %d", len(export.Phases))
+	}
+	phase := export.Phases[0]
+	if len(phase.Messages) != 3 || len(phase.Orders) != 2 {
+		t.Errorf("expected 3 messages and 2 orders, got %d and %d", len(phase.Messages), len(phase.Orders))
+	}
+	if len(phase.Agreements) != 1 || phase.Agreements[0].Bound != "germany" {
+		t.Fatalf("expected germany bound by one agreement, got %+v", phase.Agreements)
+	}
+
+	samples, err := TrainingSamples(export)
+	if err != nil {
+		t.Fatalf("TrainingSamples: %v", err)
+	}
+	if len(samples) != 7 {
+		t.Fatalf("expected one sample per power, got %d", len(samples))
+	}
+	for _, s := range samples {
+		switch s.Power {
+		case "germany":
+			if len(s.Press) != 2 || len(s.Agreements) != 1 || len(s.Orders) != 1 {
+				t.Errorf("germany: expected 2 press, 1 deal, 1 order, got %d, %d, %d", len(s.Press), len(s.Agreements), len(s.Orders))
+			}
+			if s.Press[0].From != "france" || s.Press[0].To != "germany" {
+				t.Errorf("germany: expected press keyed by power, got %+v", s.Press[0])
+			}
+		case "italy":
+			if len(s.Press) != 0 || len(s.Agreements) != 0 {
+				t.Errorf("italy should see no private press or deals, got %+v", s)
+			}
+		}
+		if s.DFEN == "" || s.Winner != "france" {
+			t.Errorf("%s: expected DFEN and winner, got %q %q", s.Power, s.DFEN, s.Winner)
+		}
+	}
+}
+
+func TestExportGameForRedactsPress(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	messageRepo := newMockMessageRepo()
+	game, phaseID, users := setupFinishedGame(t, gameRepo, phaseRepo)
+
+	ctx := context.Background()
+	messageRepo.Create(ctx, game.ID, users["france"], users["germany"], "Please don't attack bur, I won't attack yours", phaseID, nil)
+	messageRepo.Create(ctx, game.ID, users["germany"], users["france"], "Agreed", phaseID, nil)
+	messageRepo.Create(ctx, game.ID, users["england"], "", "Good game all", phaseID, nil)
+	svc := NewExportService(gameRepo, phaseRepo, messageRepo)
+
+	outsider, err := svc.ExportGameFor(ctx, game.ID, "stranger")
+	if err != nil {
+		t.Fatalf("ExportGameFor: %v", err)
+	}
+	msgs := outsider.Phases[0].Messages
+	if len(msgs) != 1 || msgs[0].SenderID != AnonymousID("england") {
+		t.Errorf("outsider: expected only the public message from power:england, got %+v", msgs)
+	}
+	if len(outsider.Phases[0].Agreements) != 0 {
+		t.Errorf("outsider: expected no agreements, got %+v", outsider.Phases[0].Agreements)
+	}
+	for _, p := range outsider.Game.Players {
+		if p.UserID != AnonymousID(p.Power) {
+			t.Errorf("outsider: expected %s shown by power, got %q", p.Power, p.UserID)
+		}
+	}
+
+	player, err := svc.ExportGameFor(ctx, game.ID, users["italy"])
+	if err != nil {
+		t.Fatalf("ExportGameFor: %v", err)
+	}
+	if len(player.Phases[0].Messages) != 3 || len(player.Phases[0].Agreements) != 1 {
+		t.Errorf("player: expected all 3 messages and 1 agreement, got %d and %d", len(player.Phases[0].Messages), len(player.Phases[0].Agreements))
+	}
+	if player.Phases[0].Messages[0].SenderID != users["france"] {
+		t.Errorf("player: expected real user IDs, got %q", player.Phases[0].Messages[0].SenderID)
+	}
+
+	gameRepo.games[game.ID].Anonymous = true
+	anon, err := svc.ExportGameFor(ctx, game.ID, users["italy"])
+	if err != nil {
+		t.Fatalf("ExportGameFor: %v", err)
+	}
+	if got := anon.Phases[0].Messages[0].SenderID; got != AnonymousID("france") {
+		t.Errorf("anonymous: expected power:france, got %q", got)
+	}
+	for _, p := range anon.Game.Players {
+		want := AnonymousID(p.Power)
+		if p.Power == "italy" {
+			want = users["italy"]
+		}
+		if p.UserID != want {
+			t.Errorf("anonymous: expected %s shown as %q, got %q", p.Power, want, p.UserID)
+		}
+	}
+	if len(anon.Phases[0].Agreements) != 1 {
+		t.Errorf("anonymous: expected agreements to survive anonymization, got %+v", anon.Phases[0].Agreements)
+	}
+}
+
+func TestExportGameNotFinished(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	gameSvc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
+	game, _ := gameSvc.CreateGame(context.Background(), "Live", "user-1", "", "", "", "", "", "", false, model.GameRules{})
+
+	svc := NewExportService(gameRepo, phaseRepo, newMockMessageRepo())
+	if _, err := svc.ExportGame(context.Background(), game.ID); !errors.Is(err, ErrGameNotFinished) {
+		t.Errorf("expected ErrGameNotFinished, got %v", err)
+	}
+}
+
+func TestExportSelfPlay(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	game, phaseID, _ := setupFinishedGame(t, gameRepo, phaseRepo)
+
+	ctx := context.Background()
+	phaseRepo.SaveOrders(ctx, []model.Order{
+		{PhaseID: phaseID, Power: "france", UnitType: "army", Location: "par", OrderType: "move", Target: "bur"},
+		{PhaseID: phaseID, Power: "france", UnitType: "army", Location: "mar", OrderType: "support", AuxUnitType: "army", AuxLoc: "par", AuxTarget: "bur"},
+		{PhaseID: phaseID, Power: "russia", UnitType: "fleet", Location: "stp", OrderType: "move", Target: "bot"},
+		{PhaseID: phaseID, Power: "germany", UnitType: "army", Location: "mun", OrderType: "support", AuxUnitType: "army", AuxLoc: "ber"},
+	})
+	phaseRepo.ResolvePhase(ctx, phaseID, nil)
+	phaseRepo.SaveEvaluations(ctx, []model.PhaseEvaluation{{PhaseID: phaseID, Power: "france", Share: 0.4}})
+
+	svc := NewExportService(gameRepo, phaseRepo, newMockMessageRepo())
+	rec, err := svc.ExportSelfPlay(ctx, game.ID)
+	if err != nil {
+		t.Fatalf("ExportSelfPlay: %v", err)
+	}
+	if rec.Winner == nil || *rec.Winner != "france" || rec.SourceID != game.ID || rec.FinalYear != 1901 {
+		t.Errorf("unexpected record header: %+v", rec)
+	}
+	if len(rec.Phases) != 1 {
+		t.Fatalf("expected 1 phase, got %d", len(rec.Phases))
+	}
+	p := rec.Phases[0]
+	if p.Season != "s" || p.Phase != "m" || !strings.HasPrefix(p.DFEN, "1901sm/") {
+		t.Errorf("unexpected phase header: %+v", p)
+	}
+	want := map[string]string{
+		"france":  "A par - bur ; A mar S A par - bur",
+		"russia":  "F stp - bot",
+		"germany": "A mun S A ber H",
+	}
+	for power, dson := range want {
+		if p.Orders[power] != dson {
+			t.Errorf("%s: expected %q, got %q", power, dson, p.Orders[power])
+		}
+	}
+	if len(p.SCCounts) != 7 || p.SCCounts[2] != 3 || len(p.Values) != 7 || p.Values[2] != 0.4 {
+		t.Errorf("expected per-power arrays in AllPowers order, got sc=%v values=%v", p.SCCounts, p.Values)
+	}
+}
+
+func TestEachFinishedGamePagesThroughAll(t *testing.T) {
+	ctx := context.Background()
+	gameRepo := newMockGameRepo()
+	for range finishedGamesPage + 20 {
+		g, _ := gameRepo.Create(ctx, "Done", "user-1", "24h", "12h", "12h", "random", "", "", model.GameRules{})
+		g.Status = "finished"
+	}
+	gameRepo.Create(ctx, "Playing", "user-1", "24h", "12h", "12h", "random", "", "", model.GameRules{})
+
+	seen := make(map[string]bool)
+	err := EachFinishedGame(ctx, gameRepo, func(g model.Game) bool {
+		seen[g.ID] = true
+		return true
+	})
+	if err != nil || len(seen) != finishedGamesPage+20 {
+		t.Fatalf("expected every finished game once, got %d, %v", len(seen), err)
+	}
+
+	n := 0
+	EachFinishedGame(ctx, gameRepo, func(model.Game) bool {
+		n++
+		return n < 3
+	})
+	if n != 3 {
+		t.Errorf("expected to stop after 3 games, got %d", n)
+	}
+}
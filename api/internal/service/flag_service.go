@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/freeeve/polite-betrayal/api/internal/repository"
+)
+
+// Known per-game feature flags.
+const (
+	FlagRevealDelay   = "reveal_delay"
+	FlagFog           = "fog"
+	FlagCoalitionBots = "coalition_bots"
+	FlagDaide         = "daide" // waiting game open to DAIDE clients
+)
+
+// knownFlags lists every flag that may be set; unknown names are rejected so
+// typos in admin calls don't silently create dead flags.
+var knownFlags = map[string]bool{
+	FlagRevealDelay:   true,
+	FlagFog:           true,
+	FlagCoalitionBots: true,
+	FlagDaide:         true,
+}
+
+var (
+	ErrUnknownFlag    = errors.New("unknown feature flag")
+	ErrInvalidRollout = errors.New("rollout percent must be between 0 and 100")
+)
+
+// FlagService resolves per-game feature flags from explicit overrides and
+// gradual rollout percentages.
+type FlagService struct {
+	store repository.FeatureFlagStore
+}
+
+// NewFlagService creates a FlagService.
+func NewFlagService(store repository.FeatureFlagStore) *FlagService {
+	return &FlagService{store: store}
+}
+
+// KnownFlags returns the names of all supported flags.
+func KnownFlags() []string {
+	return []string{FlagCoalitionBots, FlagDaide, FlagFog, FlagRevealDelay}
+}
+
+// Enabled reports whether a flag is on for a game. Lookup errors are logged
+// and treated as disabled so a Redis hiccup never turns on experimental code.
+func (s *FlagService) Enabled(ctx context.Context, gameID, flag string) bool {
+	flags, err := s.GameFlags(ctx, gameID)
+	if err != nil {
+		log.Warn().Err(err).Str("gameId", gameID).Str("flag", flag).Msg("Failed to read feature flag")
+		return false
+	}
+	return flags[flag]
+}
+
+// GameFlags returns the effective value of every known flag for a game.
+func (s *FlagService) GameFlags(ctx context.Context, gameID string) (map[string]bool, error) {
+	overrides, err := s.store.GameFlags(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	rollouts, err := s.store.FlagRollouts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	flags := make(map[string]bool, len(knownFlags))
+	for flag := range knownFlags {
+		if v, ok := overrides[flag]; ok {
+			flags[flag] = v
+			continue
+		}
+		flags[flag] = inRollout(gameID, flag, rollouts[flag])
+	}
+	return flags, nil
+}
+
+// SetGameFlag overrides a flag for one game.
+func (s *FlagService) SetGameFlag(ctx context.Context, gameID, flag string, enabled bool) error {
+	if !knownFlags[flag] {
+		return ErrUnknownFlag
+	}
+	return s.store.SetGameFlag(ctx, gameID, flag, enabled)
+}
+
+// ClearGameFlag drops a game's override, returning it to the rollout default.
+func (s *FlagService) ClearGameFlag(ctx context.Context, gameID, flag string) error {
+	if !knownFlags[flag] {
+		return ErrUnknownFlag
+	}
+	return s.store.ClearGameFlag(ctx, gameID, flag)
+}
+
+// SetRollout sets the percentage of games that get a flag by default.
+func (s *FlagService) SetRollout(ctx context.Context, flag string, percent int) error {
+	if !knownFlags[flag] {
+		return ErrUnknownFlag
+	}
+	if percent < 0 || percent > 100 {
+		return ErrInvalidRollout
+	}
+	return s.store.SetFlagRollout(ctx, flag, percent)
+}
+
+// inRollout deterministically buckets a game into [0, 100) per flag so the
+// same game keeps its default as the rollout percentage grows.
+func inRollout(gameID, flag string, percent int) bool {
+	if percent <= 0 {
+		return false
+	}
+	if percent >= 100 {
+		return true
+	}
+	h := fnv.New32a()
+	h.Write([]byte(flag))
+	h.Write([]byte{0})
+	h.Write([]byte(gameID))
+	return int(h.Sum32()%100) < percent
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// mockFlagStore implements repository.FeatureFlagStore for testing.
+type mockFlagStore struct {
+	games    map[string]map[string]bool
+	rollouts map[string]int
+}
+
+func newMockFlagStore() *mockFlagStore {
+	return &mockFlagStore{games: make(map[string]map[string]bool), rollouts: make(map[string]int)}
+}
+
+func (m *mockFlagStore) GameFlags(_ context.Context, gameID string) (map[string]bool, error) {
+	out := make(map[string]bool)
+	for k, v := range m.games[gameID] {
+		out[k] = v
+	}
+	return out, nil
+}
+
+func (m *mockFlagStore) SetGameFlag(_ context.Context, gameID, flag string, enabled bool) error {
+	if m.games[gameID] == nil {
+		m.games[gameID] = make(map[string]bool)
+	}
+	m.games[gameID][flag] = enabled
+	return nil
+}
+
+func (m *mockFlagStore) ClearGameFlag(_ context.Context, gameID, flag string) error {
+	delete(m.games[gameID], flag)
+	return nil
+}
+
+func (m *mockFlagStore) FlagRollouts(_ context.Context) (map[string]int, error) {
+	return m.rollouts, nil
+}
+
+func (m *mockFlagStore) SetFlagRollout(_ context.Context, flag string, percent int) error {
+	m.rollouts[flag] = percent
+	return nil
+}
+
+func TestFlagServiceOverride(t *testing.T) {
+	ctx := context.Background()
+	svc := NewFlagService(newMockFlagStore())
+
+	if svc.Enabled(ctx, "g1", FlagFog) {
+		t.Fatal("fog should default to off")
+	}
+	if err := svc.SetGameFlag(ctx, "g1", FlagFog, true); err != nil {
+		t.Fatalf("set flag: %v", err)
+	}
+	if !svc.Enabled(ctx, "g1", FlagFog) {
+		t.Error("fog should be on after override")
+	}
+	if svc.Enabled(ctx, "g2", FlagFog) {
+		t.Error("override must not leak to other games")
+	}
+	if err := svc.ClearGameFlag(ctx, "g1", FlagFog); err != nil {
+		t.Fatalf("clear flag: %v", err)
+	}
+	if svc.Enabled(ctx, "g1", FlagFog) {
+		t.Error("fog should be off after clearing override")
+	}
+}
+
+func TestFlagServiceUnknownFlag(t *testing.T) {
+	svc := NewFlagService(newMockFlagStore())
+	if err := svc.SetGameFlag(context.Background(), "g1", "no_such_flag", true); !errors.Is(err, ErrUnknownFlag) {
+		t.Errorf("expected ErrUnknownFlag, got %v", err)
+	}
+	if err := svc.SetRollout(context.Background(), FlagFog, 101); !errors.Is(err, ErrInvalidRollout) {
+		t.Errorf("expected ErrInvalidRollout, got %v", err)
+	}
+}
+
+func TestFlagServiceRollout(t *testing.T) {
+	ctx := context.Background()
+	store := newMockFlagStore()
+	svc := NewFlagService(store)
+
+	if err := svc.SetRollout(ctx, FlagRevealDelay, 50); err != nil {
+		t.Fatalf("set rollout: %v", err)
+	}
+	on := 0
+	for i := range 200 {
+		if svc.Enabled(ctx, fmt.Sprintf("game-%d", i), FlagRevealDelay) {
+			on++
+		}
+	}
+	if on < 60 || on > 140 {
+		t.Errorf("50%% rollout enabled %d/200 games", on)
+	}
+
+	// An explicit override beats the rollout.
+	svc.SetRollout(ctx, FlagRevealDelay, 100)
+	svc.SetGameFlag(ctx, "game-0", FlagRevealDelay, false)
+	if svc.Enabled(ctx, "game-0", FlagRevealDelay) {
+		t.Error("override should win over 100% rollout")
+	}
+	if !svc.Enabled(ctx, "game-1", FlagRevealDelay) {
+		t.Error("100% rollout should enable games without an override")
+	}
+}
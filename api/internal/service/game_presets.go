@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+)
+
+// ErrUnknownPreset is returned when a game is created with a preset name that
+// does not exist.
+var ErrUnknownPreset = errors.New("unknown game preset")
+
+// GamePreset is a named game speed: phase durations plus how bots and
+// single-option phases behave at that pace.
+type GamePreset struct {
+	Name            string `json:"name"`
+	TurnDuration    string `json:"turn_duration"`
+	RetreatDuration string `json:"retreat_duration"`
+	BuildDuration   string `json:"build_duration"`
+	// AutoSkip resolves retreat and build phases immediately when no power
+	// has a choice to make.
+	AutoSkip bool `json:"auto_skip"`
+	// BotTimeBudget is how long bots may search each phase, at least
+	// minBotTimeout.
+	BotTimeBudget string `json:"bot_time_budget"`
+}
+
+var gamePresets = []GamePreset{
+	{Name: "blitz", TurnDuration: "5m", RetreatDuration: "1m", BuildDuration: "1m", AutoSkip: true, BotTimeBudget: "5s"},
+	{Name: "live", TurnDuration: "15m", RetreatDuration: "5m", BuildDuration: "5m", BotTimeBudget: "10s"},
+	{Name: "async", TurnDuration: "24h", RetreatDuration: "12h", BuildDuration: "12h", BotTimeBudget: "30s"},
+}
+
+// GamePresets returns the available game speed presets, fastest first.
+func GamePresets() []GamePreset {
+	out := make([]GamePreset, len(gamePresets))
+	copy(out, gamePresets)
+	return out
+}
+
+// LookupPreset returns the preset with the given name.
+func LookupPreset(name string) (GamePreset, bool) {
+	for _, p := range gamePresets {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return GamePreset{}, false
+}
+
+// botTimeBudget returns the preset's per-phase bot search budget, or 0 if
+// the preset does not set one.
+func (p GamePreset) botTimeBudget() time.Duration {
+	d, err := time.ParseDuration(p.BotTimeBudget)
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+// CreateGameWithPreset creates a new game using the phase durations of the
+// named preset and records the preset so phase handling can follow it.
+func (s *GameService) CreateGameWithPreset(ctx context.Context, name, creatorID, preset, botDifficulty, powerAssignment, scenario string, botOnly bool, rules model.GameRules) (*model.Game, error) {
+	p, ok := LookupPreset(preset)
+	if !ok {
+		return nil, ErrUnknownPreset
+	}
+	return s.createGame(ctx, name, creatorID, p.TurnDuration, p.RetreatDuration, p.BuildDuration, botDifficulty, powerAssignment, p.Name, scenario, botOnly, rules)
+}
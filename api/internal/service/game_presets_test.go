@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+)
+
+func TestCreateGameWithPreset(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	svc := NewGameService(gameRepo, newMockPhaseRepo(), newMockUserRepo())
+
+	game, err := svc.CreateGameWithPreset(context.Background(), "Blitz", "user-1", "blitz", "", "", "", false, model.GameRules{})
+	if err != nil {
+		t.Fatalf("CreateGameWithPreset: %v", err)
+	}
+	if game.SpeedPreset != "blitz" {
+		t.Errorf("expected speed preset blitz, got %q", game.SpeedPreset)
+	}
+	if game.TurnDuration != "5 minutes" {
+		t.Errorf("expected turn duration '5 minutes', got %s", game.TurnDuration)
+	}
+	if game.RetreatDuration != "1 minutes" || game.BuildDuration != "1 minutes" {
+		t.Errorf("expected 1 minute retreat/build, got %s/%s", game.RetreatDuration, game.BuildDuration)
+	}
+}
+
+func TestCreateGameWithPreset_Unknown(t *testing.T) {
+	svc := NewGameService(newMockGameRepo(), newMockPhaseRepo(), newMockUserRepo())
+
+	_, err := svc.CreateGameWithPreset(context.Background(), "X", "user-1", "turbo", "", "", "", false, model.GameRules{})
+	if !errors.Is(err, ErrUnknownPreset) {
+		t.Errorf("expected ErrUnknownPreset, got %v", err)
+	}
+}
+
+func TestGamePresets_BotBudgetFitsTurn(t *testing.T) {
+	for _, p := range GamePresets() {
+		budget := p.botTimeBudget()
+		if budget <= 0 {
+			t.Errorf("%s: missing bot time budget", p.Name)
+			continue
+		}
+		for _, d := range []string{p.TurnDuration, p.RetreatDuration, p.BuildDuration} {
+			if phase, _ := time.ParseDuration(d); budget*10 > phase {
+				t.Errorf("%s: bot budget %v exceeds a tenth of %s phase", p.Name, budget, d)
+			}
+		}
+	}
+}
+
+func TestBotPhaseTimeout(t *testing.T) {
+	blitz, _ := LookupPreset("blitz")
+	async, _ := LookupPreset("async")
+	cases := []struct {
+		dur       time.Duration
+		preset    GamePreset
+		hasPreset bool
+		want      time.Duration
+	}{
+		{time.Minute, GamePreset{}, false, 30 * time.Second},
+		{20 * time.Second, GamePreset{}, false, 15 * time.Second},
+		{2 * time.Second, GamePreset{}, false, minBotTimeout},
+		{time.Minute, blitz, true, 5 * time.Second},
+		{time.Minute, GamePreset{BotTimeBudget: "1s"}, true, minBotTimeout},
+		{24 * time.Hour, async, true, 30 * time.Second},
+	}
+	for _, c := range cases {
+		if got := botPhaseTimeout(c.dur, c.preset, c.hasPreset); got != c.want {
+			t.Errorf("botPhaseTimeout(%v, %q) = %v, want %v", c.dur, c.preset.BotTimeBudget, got, c.want)
+		}
+	}
+}
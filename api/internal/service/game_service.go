@@ -6,44 +6,173 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/rs/zerolog/log"
+
+	"github.com/freeeve/polite-betrayal/api/internal/bot"
 	"github.com/freeeve/polite-betrayal/api/internal/model"
 	"github.com/freeeve/polite-betrayal/api/internal/repository"
 	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
 )
 
 var (
-	ErrGameNotFound   = errors.New("game not found")
-	ErrGameNotWaiting = errors.New("game is not in waiting status")
-	ErrGameFull       = errors.New("game already has 7 players")
-	ErrNotEnough      = errors.New("need exactly 7 players to start")
-	ErrNotCreator     = errors.New("only the creator can start the game")
-	ErrGameNotActive  = errors.New("game is not active")
-	ErrAlreadyJoined  = errors.New("already joined this game")
-	ErrNotInGame      = errors.New("you are not in this game")
-	ErrPowerTaken     = errors.New("power already assigned to another player")
-	ErrNotManualMode  = errors.New("power assignment is not set to manual")
-	ErrInvalidPower   = errors.New("invalid power")
-	ErrCannotSetPower = errors.New("you can only set your own power or bot powers as creator")
+	ErrGameNotFound       = errors.New("game not found")
+	ErrGameNotWaiting     = errors.New("game is not in waiting status")
+	ErrGameFull           = errors.New("game already has a player for every power")
+	ErrNotEnough          = errors.New("need a player for every power to start")
+	ErrNotCreator         = errors.New("only the creator can start the game")
+	ErrGameNotActive      = errors.New("game is not active")
+	ErrAlreadyJoined      = errors.New("already joined this game")
+	ErrNotInGame          = errors.New("you are not in this game")
+	ErrPowerTaken         = errors.New("power already assigned to another player")
+	ErrNotManualMode      = errors.New("power assignment is not set to manual")
+	ErrInvalidPower       = errors.New("invalid power")
+	ErrCannotSetPower     = errors.New("you can only set your own power or bot powers as creator")
+	ErrGameNotDeleted     = errors.New("game is not deleted")
+	ErrRetentionEnded     = errors.New("game is past its restore window")
+	ErrUnknownScenario    = errors.New("unknown scenario")
+	ErrUnknownStrategy    = errors.New("unknown bot strategy")
+	ErrNotBot             = errors.New("player is not a bot in this game")
+	ErrUnknownPress       = errors.New("bot press must be personality, natural or terse")
+	ErrUnknownPressMode   = errors.New("press mode must be full, public_only or none")
+	ErrPressNotAllowed    = errors.New("this press is not allowed in this game")
+	ErrInvalidMercy       = errors.New("mercy must be 0 to 10 years")
+	ErrMercyUnsupported   = errors.New("bot strategy does not play the mercy setting")
+	ErrInvalidPersonality = errors.New("invalid bot personality")
 )
 
+// MaxMercyYears is the longest a game's bots may spare the human players'
+// home centers.
+const MaxMercyYears = 10
+
+// DefaultDeletedGameRetention is how long a deleted game can be restored
+// before the purge job removes it permanently.
+const DefaultDeletedGameRetention = 30 * 24 * time.Hour
+
 // GameService handles game lifecycle operations.
 type GameService struct {
-	gameRepo  repository.GameRepository
-	phaseRepo repository.PhaseRepository
-	userRepo  repository.UserRepository
+	gameRepo    repository.GameRepository
+	phaseRepo   repository.PhaseRepository
+	userRepo    repository.UserRepository
+	cache       repository.GameCache // optional: clears submissions of powers that change hands
+	broadcaster Broadcaster          // lobby events
+	retention   time.Duration        // restore window for deleted games
+	jitter      time.Duration        // max random delay added to phase deadlines
+	variants    *VariantService      // optional: custom variants as scenarios
 }
 
 // NewGameService creates a GameService.
 func NewGameService(gameRepo repository.GameRepository, phaseRepo repository.PhaseRepository, userRepo repository.UserRepository) *GameService {
-	return &GameService{gameRepo: gameRepo, phaseRepo: phaseRepo, userRepo: userRepo}
+	return &GameService{gameRepo: gameRepo, phaseRepo: phaseRepo, userRepo: userRepo, broadcaster: NoopBroadcaster{}, retention: DefaultDeletedGameRetention}
+}
+
+// SetBroadcaster configures where lobby events are sent when games are
+// created, gain a player or start.
+func (s *GameService) SetBroadcaster(b Broadcaster) {
+	s.broadcaster = b
+}
+
+// broadcastLobby sends a lobby event carrying what the game browser shows
+// of game.
+func (s *GameService) broadcastLobby(game *model.Game, eventType string) {
+	s.broadcaster.BroadcastLobbyEvent(game.ID, eventType, map[string]any{
+		"name":     game.Name,
+		"status":   game.Status,
+		"scenario": game.Scenario,
+		"humans":   humanCount(game),
+		"seats":    len(gameScenario(game).Powers),
+	})
+}
+
+// humanCount returns how many of the game's players are not bots.
+func humanCount(game *model.Game) int {
+	humans := 0
+	for _, p := range game.Players {
+		if !p.IsBot {
+			humans++
+		}
+	}
+	return humans
+}
+
+// SetDeletedRetention configures how long deleted games stay restorable.
+func (s *GameService) SetDeletedRetention(d time.Duration) {
+	s.retention = d
+}
+
+// SetDeadlineJitter spreads the first deadlines of games started together
+// by up to d, so they don't all expire at once.
+func (s *GameService) SetDeadlineJitter(d time.Duration) {
+	s.jitter = d
+}
+
+// SetGameCache configures the live game cache, so a power's submitted
+// orders, ready flag and draw vote are dropped when it changes hands
+// instead of being played or counted for its new player.
+func (s *GameService) SetGameCache(cache repository.GameCache) {
+	s.cache = cache
+}
+
+// SetVariantService lets games be created on uploaded variants. A variant
+// named without a version resolves to its latest one.
+func (s *GameService) SetVariantService(v *VariantService) {
+	s.variants = v
+}
+
+// ListScenarios returns the scenarios a new game can be created with.
+func (s *GameService) ListScenarios(ctx context.Context) ([]diplomacy.Scenario, error) {
+	if s.variants == nil {
+		return diplomacy.Scenarios(), nil
+	}
+	return s.variants.Scenarios(ctx)
+}
+
+// clearPowerData drops what has been submitted for powers in the current
+// phase. Failures are logged: a stale submission is replaced by the next
+// one and gone with the phase anyway.
+func (s *GameService) clearPowerData(ctx context.Context, gameID string, powers ...string) {
+	if s.cache == nil {
+		return
+	}
+	for _, power := range powers {
+		if power == "" {
+			continue
+		}
+		if err := s.cache.ClearPowerData(ctx, gameID, power); err != nil {
+			log.Warn().Err(err).Str("gameId", gameID).Str("power", power).Msg("Failed to clear submissions of reassigned power")
+		}
+	}
+}
+
+// CreateGame creates a new game in "waiting" status with the given house
+// rules. An empty scenario is the standard seven-power game.
+func (s *GameService) CreateGame(ctx context.Context, name, creatorID string, turnDur, retreatDur, buildDur, botDifficulty, powerAssignment, scenario string, botOnly bool, rules model.GameRules) (*model.Game, error) {
+	return s.createGame(ctx, name, creatorID, turnDur, retreatDur, buildDur, botDifficulty, powerAssignment, "", scenario, botOnly, rules)
 }
 
-// CreateGame creates a new game in "waiting" status.
-func (s *GameService) CreateGame(ctx context.Context, name, creatorID string, turnDur, retreatDur, buildDur, botDifficulty, powerAssignment string, botOnly bool) (*model.Game, error) {
+func (s *GameService) createGame(ctx context.Context, name, creatorID, turnDur, retreatDur, buildDur, botDifficulty, powerAssignment, speedPreset, scenario string, botOnly bool, rules model.GameRules) (*model.Game, error) {
+	if s.variants != nil {
+		var err error
+		if scenario, err = s.variants.ResolveScenario(ctx, scenario); err != nil {
+			return nil, err
+		}
+	}
+	sc, ok := diplomacy.LookupScenario(scenario)
+	if !ok {
+		return nil, ErrUnknownScenario
+	}
+	if err := ValidateCivilDisorder(rules.CivilDisorderAfter, rules.CivilDisorderBot); err != nil {
+		return nil, err
+	}
+	quorumDelay, err := ParseReadyQuorum(rules.ReadyQuorum, rules.QuorumDelay)
+	if err != nil {
+		return nil, err
+	}
+	rules.QuorumDelay = quorumInterval(quorumDelay)
 	turnDur = toPgInterval(turnDur, "24 hours")
 	retreatDur = toPgInterval(retreatDur, "12 hours")
 	buildDur = toPgInterval(buildDur, "12 hours")
@@ -54,7 +183,7 @@ func (s *GameService) CreateGame(ctx context.Context, name, creatorID string, tu
 		powerAssignment = "random"
 	}
 
-	game, err := s.gameRepo.Create(ctx, name, creatorID, turnDur, retreatDur, buildDur, powerAssignment)
+	game, err := s.gameRepo.Create(ctx, name, creatorID, turnDur, retreatDur, buildDur, powerAssignment, speedPreset, sc.Name, rules)
 
 	if err != nil {
 		return nil, err
@@ -68,9 +197,9 @@ func (s *GameService) CreateGame(ctx context.Context, name, creatorID string, tu
 	}
 
 	// Fill remaining slots with bots
-	botCount := 6
+	botCount := len(sc.Powers) - 1
 	if botOnly {
-		botCount = 7
+		botCount = len(sc.Powers)
 	}
 	for i := 1; i <= botCount; i++ {
 		providerID := fmt.Sprintf("bot-%d", i)
@@ -84,7 +213,12 @@ func (s *GameService) CreateGame(ctx context.Context, name, creatorID string, tu
 		}
 	}
 
-	return s.gameRepo.FindByID(ctx, game.ID)
+	game, err = s.gameRepo.FindByID(ctx, game.ID)
+	if err != nil {
+		return nil, err
+	}
+	s.broadcastLobby(game, "game_created")
+	return game, nil
 }
 
 // JoinGame adds a player to a waiting game.
@@ -106,27 +240,55 @@ func (s *GameService) JoinGame(ctx context.Context, gameID, userID string) error
 		}
 	}
 
-	count, err := s.gameRepo.PlayerCount(ctx, gameID)
-	if err != nil {
+	// The checks above answer most joins; the claim settles races for the
+	// last seats.
+	seats := len(gameScenario(game).Powers)
+	if err := s.gameRepo.ClaimSeat(ctx, gameID, userID, seats); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrSeatTaken):
+			return ErrGameFull
+		case errors.Is(err, repository.ErrAlreadySeated):
+			return ErrAlreadyJoined
+		case errors.Is(err, repository.ErrNotJoinable):
+			return ErrGameNotWaiting
+		}
 		return err
 	}
 
-	if count >= 7 {
-		// Check if there are bots to replace
-		hasBots := false
-		for _, p := range game.Players {
-			if p.IsBot {
-				hasBots = true
-				break
-			}
+	if game, err := s.gameRepo.FindByID(ctx, gameID); err == nil && game != nil {
+		s.broadcastLobby(game, "seat_filled")
+		if humanCount(game) == seats {
+			s.broadcastLobby(game, "game_full")
 		}
-		if !hasBots {
-			return ErrGameFull
-		}
-		return s.gameRepo.ReplaceBot(ctx, gameID, userID)
 	}
+	return nil
+}
 
-	return s.gameRepo.JoinGame(ctx, gameID, userID)
+// SpectateGame adds a user to a game as a spectator. Spectators follow the
+// game over WebSocket and read its public press, but hold no power, so they
+// see orders only once a phase resolves. A spectator can still join a
+// waiting game as a player.
+func (s *GameService) SpectateGame(ctx context.Context, gameID, userID string) (*model.Game, error) {
+	game, err := s.gameRepo.FindByID(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	if game == nil || game.Status == "deleted" {
+		return nil, ErrGameNotFound
+	}
+	for _, p := range game.Players {
+		if p.UserID == userID {
+			return nil, ErrAlreadyJoined
+		}
+	}
+	if slices.Contains(game.Spectators, userID) {
+		return game, nil
+	}
+	if err := s.gameRepo.Spectate(ctx, gameID, userID); err != nil {
+		return nil, err
+	}
+	game.Spectators = append(game.Spectators, userID)
+	return game, nil
 }
 
 // StartGame assigns powers and creates the first phase.
@@ -144,11 +306,15 @@ func (s *GameService) StartGame(ctx context.Context, gameID, userID string) (*mo
 	if game.CreatorID != userID {
 		return nil, ErrNotCreator
 	}
-	if len(game.Players) != 7 {
+	sc := gameScenario(game)
+	if len(game.Players) != len(sc.Powers) {
 		return nil, ErrNotEnough
 	}
 
-	allPowers := []string{"austria", "england", "france", "germany", "italy", "russia", "turkey"}
+	allPowers := make([]string, len(sc.Powers))
+	for i, p := range sc.Powers {
+		allPowers[i] = string(p)
+	}
 	assignments := make(map[string]string)
 
 	if game.PowerAssignment == "manual" {
@@ -185,19 +351,36 @@ func (s *GameService) StartGame(ctx context.Context, gameID, userID string) (*mo
 	}
 
 	// Create initial game state and first phase
-	initialState := diplomacy.NewInitialState()
+	initialState := sc.InitialState()
+	if game.Garrisons {
+		initialState.AddGarrisons()
+	}
+	initialState.RetreatCredits = game.RetreatCredits
+	if game.MercyYears > 0 {
+		for _, p := range game.Players {
+			if !p.IsBot {
+				initialState.MercyPowers = append(initialState.MercyPowers, diplomacy.Power(assignments[p.UserID]))
+			}
+		}
+		initialState.MercyUntil = initialState.Year + game.MercyYears - 1
+	}
 	stateJSON, err := json.Marshal(initialState)
 	if err != nil {
 		return nil, fmt.Errorf("marshal initial state: %w", err)
 	}
 
-	deadline := time.Now().Add(parseDuration(game.TurnDuration))
-	_, err = s.phaseRepo.CreatePhase(ctx, gameID, 1901, "spring", "movement", stateJSON, deadline)
+	deadline := jitteredDeadline(parseDuration(game.TurnDuration), s.jitter)
+	_, err = s.phaseRepo.CreatePhase(ctx, gameID, initialState.Year, string(initialState.Season), string(initialState.Phase), stateJSON, deadline)
 	if err != nil {
 		return nil, err
 	}
 
-	return s.gameRepo.FindByID(ctx, gameID)
+	game, err = s.gameRepo.FindByID(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	s.broadcastLobby(game, "game_started")
+	return game, nil
 }
 
 // GetGame returns a game by ID.
@@ -206,14 +389,175 @@ func (s *GameService) GetGame(ctx context.Context, gameID string) (*model.Game,
 	if err != nil {
 		return nil, err
 	}
-	if game == nil {
+	if game == nil || game.Status == "deleted" {
 		return nil, ErrGameNotFound
 	}
 	return game, nil
 }
 
-// UpdateBotDifficulty validates and updates a bot's difficulty level.
-func (s *GameService) UpdateBotDifficulty(ctx context.Context, gameID, userID, botUserID, difficulty string) error {
+// UpdateBotDifficulty sets a bot's difficulty to any registered strategy
+// name or alias. Before the game starts the change applies at once; during
+// play it takes effect when the next phase starts, so a bot never switches
+// strategy after submitting its orders, unless now is set. The change is
+// returned as recorded in the bot's history.
+func (s *GameService) UpdateBotDifficulty(ctx context.Context, gameID, userID, botUserID, difficulty string, now bool) (*model.BotDifficultyChange, error) {
+	game, err := s.gameRepo.FindByID(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	if game == nil || game.Status == "deleted" {
+		return nil, ErrGameNotFound
+	}
+	switch game.Status {
+	case "waiting":
+		now = true
+	case "active", "paused":
+	default:
+		return nil, ErrGameNotActive
+	}
+	if game.CreatorID != userID {
+		return nil, ErrNotCreator
+	}
+	if _, ok := bot.LookupStrategy(difficulty); !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownStrategy, difficulty)
+	}
+	if game.MercyYears > 0 {
+		if err := ValidateMercyStrategy(difficulty); err != nil {
+			return nil, err
+		}
+	}
+	botUserID = ResolveUserID(game, botUserID)
+	if !slices.ContainsFunc(game.Players, func(p model.GamePlayer) bool { return p.UserID == botUserID && p.IsBot }) {
+		return nil, ErrNotBot
+	}
+
+	var change *model.BotDifficultyChange
+	if now {
+		change, err = s.gameRepo.UpdateBotDifficulty(ctx, gameID, botUserID, difficulty, model.BotDifficultyByCreator, userID)
+	} else {
+		change, err = s.gameRepo.RequestBotDifficulty(ctx, gameID, botUserID, difficulty, userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if change == nil {
+		return nil, ErrNotBot
+	}
+	return change, nil
+}
+
+// UpdateBotPersonality sets the personality a bot plays its strategy with;
+// nil restores the strategy's usual play. It applies from the bot's next
+// orders, and only the creator may change it before the game ends.
+func (s *GameService) UpdateBotPersonality(ctx context.Context, gameID, userID, botUserID string, personality *bot.Personality) (*model.GamePlayer, error) {
+	game, err := s.gameRepo.FindByID(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	if game == nil || game.Status == "deleted" {
+		return nil, ErrGameNotFound
+	}
+	switch game.Status {
+	case "waiting", "active", "paused":
+	default:
+		return nil, ErrGameNotActive
+	}
+	if game.CreatorID != userID {
+		return nil, ErrNotCreator
+	}
+	var stored *model.BotPersonality
+	if personality != nil {
+		if err := personality.Validate(); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPersonality, err)
+		}
+		p := model.BotPersonality(*personality)
+		stored = &p
+	}
+	botUserID = ResolveUserID(game, botUserID)
+	i := slices.IndexFunc(game.Players, func(p model.GamePlayer) bool { return p.UserID == botUserID && p.IsBot })
+	if i < 0 {
+		return nil, ErrNotBot
+	}
+	if reg, ok := bot.LookupStrategy(game.Players[i].BotDifficulty); personality != nil && ok && !reg.Capabilities.Personality {
+		return nil, fmt.Errorf("%w: %s does not support one", ErrInvalidPersonality, reg.Name)
+	}
+	if err := s.gameRepo.UpdateBotPersonality(ctx, gameID, botUserID, stored); err != nil {
+		return nil, err
+	}
+	player := game.Players[i]
+	player.BotPersonality = stored
+	return &player, nil
+}
+
+// BotDifficultyChanges returns the history of a game's bot difficulty
+// changes, pending ones last. While the game hides identities only its
+// creator may see it.
+func (s *GameService) BotDifficultyChanges(ctx context.Context, gameID, userID string) ([]model.BotDifficultyChange, error) {
+	game, err := s.GetGame(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	if HidesIdentities(game) && game.CreatorID != userID {
+		return nil, ErrNotCreator
+	}
+	changes, err := s.gameRepo.BotDifficultyChanges(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	if changes == nil {
+		changes = []model.BotDifficultyChange{}
+	}
+	return changes, nil
+}
+
+// UpdateBotPress sets how the game's bots word their press: personality,
+// natural or terse. The creator can change it until the game ends.
+func (s *GameService) UpdateBotPress(ctx context.Context, gameID, userID, style string) error {
+	if !bot.ValidPressStyle(style) {
+		return ErrUnknownPress
+	}
+	game, err := s.gameRepo.FindByID(ctx, gameID)
+	if err != nil {
+		return err
+	}
+	if game == nil {
+		return ErrGameNotFound
+	}
+	if game.CreatorID != userID {
+		return ErrNotCreator
+	}
+	if game.Status != "waiting" && game.Status != "active" {
+		return ErrGameNotActive
+	}
+	return s.gameRepo.UpdateBotPress(ctx, gameID, style)
+}
+
+// UpdatePressSettings sets which press a game allows and whether it hides
+// player identities until it finishes. The creator can change them until
+// the game starts.
+func (s *GameService) UpdatePressSettings(ctx context.Context, gameID, userID, pressMode string, anonymous bool) error {
+	if !ValidPressMode(pressMode) {
+		return ErrUnknownPressMode
+	}
+	game, err := s.gameRepo.FindByID(ctx, gameID)
+	if err != nil {
+		return err
+	}
+	if game == nil {
+		return ErrGameNotFound
+	}
+	if game.CreatorID != userID {
+		return ErrNotCreator
+	}
+	if game.Status != "waiting" {
+		return ErrGameNotWaiting
+	}
+	return s.gameRepo.UpdatePressSettings(ctx, gameID, pressMode, anonymous)
+}
+
+// UpdateGarrisons sets whether a game's unowned centers start with neutral
+// garrisons. Only the creator can change it, and only before the game starts.
+func (s *GameService) UpdateGarrisons(ctx context.Context, gameID, userID string, garrisons bool) error {
 	game, err := s.gameRepo.FindByID(ctx, gameID)
 	if err != nil {
 		return err
@@ -221,30 +565,102 @@ func (s *GameService) UpdateBotDifficulty(ctx context.Context, gameID, userID, b
 	if game == nil {
 		return ErrGameNotFound
 	}
+	if game.CreatorID != userID {
+		return ErrNotCreator
+	}
 	if game.Status != "waiting" {
 		return ErrGameNotWaiting
 	}
+	return s.gameRepo.UpdateGarrisons(ctx, gameID, garrisons)
+}
+
+// UpdateRetreatCredits sets whether a game plays the house rule where a
+// retreating unit its power disbands may be rebuilt at the next adjustment.
+// Only the creator can change it, and only before the game starts.
+func (s *GameService) UpdateRetreatCredits(ctx context.Context, gameID, userID string, retreatCredits bool) error {
+	game, err := s.gameRepo.FindByID(ctx, gameID)
+	if err != nil {
+		return err
+	}
+	if game == nil {
+		return ErrGameNotFound
+	}
 	if game.CreatorID != userID {
 		return ErrNotCreator
 	}
-	switch difficulty {
-	case "easy", "medium", "hard":
-	default:
-		return fmt.Errorf("invalid difficulty: must be easy, medium, or hard")
+	if game.Status != "waiting" {
+		return ErrGameNotWaiting
 	}
-	return s.gameRepo.UpdateBotDifficulty(ctx, gameID, botUserID, difficulty)
+	return s.gameRepo.UpdateRetreatCredits(ctx, gameID, retreatCredits)
 }
 
-// UpdatePlayerPower sets a player's power in a manual-assignment lobby.
-func (s *GameService) UpdatePlayerPower(ctx context.Context, gameID, targetUserID, requestingUserID, power string) error {
-	validPowers := map[string]bool{
-		"austria": true, "england": true, "france": true, "germany": true,
-		"italy": true, "russia": true, "turkey": true,
+// UpdateMercyYears sets for how many years, from the first, the game's bots
+// hold off attacking the human players' home centers, giving new players room
+// to learn; 0 turns it off. Only the creator can change it, and only before
+// the game starts.
+func (s *GameService) UpdateMercyYears(ctx context.Context, gameID, userID string, years int) error {
+	if years < 0 || years > MaxMercyYears {
+		return ErrInvalidMercy
 	}
-	if !validPowers[power] {
-		return ErrInvalidPower
+	game, err := s.gameRepo.FindByID(ctx, gameID)
+	if err != nil {
+		return err
+	}
+	if game == nil {
+		return ErrGameNotFound
+	}
+	if game.CreatorID != userID {
+		return ErrNotCreator
 	}
+	if game.Status != "waiting" {
+		return ErrGameNotWaiting
+	}
+	if years > 0 {
+		for _, p := range game.Players {
+			if p.IsBot {
+				if err := ValidateMercyStrategy(p.BotDifficulty); err != nil {
+					return err
+				}
+			}
+		}
+		if err := ValidateMercyStrategy(game.CivilDisorderBot); err != nil {
+			return err
+		}
+	}
+	return s.gameRepo.UpdateMercyYears(ctx, gameID, years)
+}
+
+// ValidateMercyStrategy checks that a bot playing difficulty keeps off the
+// home centers a mercy setting spares. An empty or unknown strategy passes,
+// being checked elsewhere.
+func ValidateMercyStrategy(difficulty string) error {
+	if reg, ok := bot.LookupStrategy(difficulty); ok && !reg.Capabilities.Mercy {
+		return fmt.Errorf("%w: %q", ErrMercyUnsupported, difficulty)
+	}
+	return nil
+}
+
+// ValidPressMode reports whether mode is a known press mode.
+func ValidPressMode(mode string) bool {
+	return mode == model.PressFull || mode == model.PressPublicOnly || mode == model.PressNone
+}
 
+// AllowsPress reports whether a game lets players send press: public press
+// unless the game is gunboat, private and channel press only with full press.
+// Games from before press modes allow everything.
+func AllowsPress(game *model.Game, private bool) bool {
+	switch game.PressMode {
+	case model.PressNone:
+		return false
+	case model.PressPublicOnly:
+		return !private
+	default:
+		return true
+	}
+}
+
+// UpdatePlayerPower sets a player's power in a manual-assignment lobby.
+func (s *GameService) UpdatePlayerPower(ctx context.Context, gameID, targetUserID, requestingUserID, power string) error {
 	game, err := s.gameRepo.FindByID(ctx, gameID)
 	if err != nil {
 		return err
@@ -252,6 +668,9 @@ func (s *GameService) UpdatePlayerPower(ctx context.Context, gameID, targetUserI
 	if game == nil {
 		return ErrGameNotFound
 	}
+	if !gameScenario(game).IsActive(diplomacy.Power(power)) {
+		return ErrInvalidPower
+	}
 	if game.Status != "waiting" {
 		return ErrGameNotWaiting
 	}
@@ -288,10 +707,18 @@ func (s *GameService) UpdatePlayerPower(ctx context.Context, gameID, targetUserI
 		}
 	}
 
-	return s.gameRepo.UpdatePlayerPower(ctx, gameID, targetUserID, power)
+	oldPower := targetPlayer.Power
+	if err := s.gameRepo.UpdatePlayerPower(ctx, gameID, targetUserID, power); err != nil {
+		return err
+	}
+	if oldPower != power {
+		s.clearPowerData(ctx, gameID, oldPower, power)
+	}
+	return nil
 }
 
-// DeleteGame removes a waiting game. Only the game creator can delete a game.
+// DeleteGame soft-deletes a waiting game, hiding it from lists until it is
+// restored or purged. Only the game creator can delete a game.
 func (s *GameService) DeleteGame(ctx context.Context, gameID, userID string) error {
 	game, err := s.gameRepo.FindByID(ctx, gameID)
 	if err != nil {
@@ -306,10 +733,65 @@ func (s *GameService) DeleteGame(ctx context.Context, gameID, userID string) err
 	if game.CreatorID != userID {
 		return ErrNotCreator
 	}
-	return s.gameRepo.Delete(ctx, gameID)
+	return s.gameRepo.SoftDelete(ctx, gameID)
 }
 
-// StopGame ends an active game as a draw. Only the game creator can stop a game.
+// RestoreGame undoes DeleteGame within the retention window. Only the game
+// creator can restore a game.
+func (s *GameService) RestoreGame(ctx context.Context, gameID, userID string) (*model.Game, error) {
+	game, err := s.gameRepo.FindByID(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	if game == nil {
+		return nil, ErrGameNotFound
+	}
+	if game.Status != "deleted" {
+		return nil, ErrGameNotDeleted
+	}
+	if game.CreatorID != userID {
+		return nil, ErrNotCreator
+	}
+	if game.DeletedAt != nil && time.Since(*game.DeletedAt) > s.retention {
+		return nil, ErrRetentionEnded
+	}
+	if err := s.gameRepo.Restore(ctx, gameID); err != nil {
+		return nil, err
+	}
+	return s.gameRepo.FindByID(ctx, gameID)
+}
+
+// PurgeDeletedGames permanently removes games deleted longer ago than the
+// retention window and returns how many were removed.
+func (s *GameService) PurgeDeletedGames(ctx context.Context) (int, error) {
+	return s.gameRepo.PurgeDeleted(ctx, time.Now().Add(-s.retention))
+}
+
+// RunPurgeJob purges expired deleted games every interval until ctx is done.
+func (s *GameService) RunPurgeJob(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	log.Info().Dur("interval", interval).Dur("retention", s.retention).Msg("Deleted game purge job started")
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := s.PurgeDeletedGames(ctx)
+			if err != nil {
+				log.Error().Err(err).Msg("Failed to purge deleted games")
+				continue
+			}
+			if n > 0 {
+				log.Info().Int("count", n).Msg("Purged deleted games")
+			}
+		}
+	}
+}
+
+// StopGame ends an active or paused game as a draw. Only the game creator
+// can stop a game.
 func (s *GameService) StopGame(ctx context.Context, gameID, userID string) (*model.Game, error) {
 	game, err := s.gameRepo.FindByID(ctx, gameID)
 	if err != nil {
@@ -318,7 +800,7 @@ func (s *GameService) StopGame(ctx context.Context, gameID, userID string) (*mod
 	if game == nil {
 		return nil, ErrGameNotFound
 	}
-	if game.Status != "active" {
+	if game.Status != "active" && game.Status != "paused" {
 		return nil, ErrGameNotActive
 	}
 	if game.CreatorID != userID {
@@ -346,6 +828,16 @@ func (s *GameService) ListGames(ctx context.Context, userID string, filter strin
 	}
 }
 
+// gameScenario returns the scenario a game was created with, treating unknown
+// or missing names as the standard game.
+func gameScenario(game *model.Game) diplomacy.Scenario {
+	if sc, ok := diplomacy.LookupScenario(game.Scenario); ok {
+		return sc
+	}
+	sc, _ := diplomacy.LookupScenario(diplomacy.ScenarioStandard)
+	return sc
+}
+
 // toPgInterval converts Go-style duration strings (e.g. "5m", "1h") to
 // PostgreSQL interval format (e.g. "5 minutes", "1 hours"). Returns
 // defaultVal if input is empty.
@@ -2,9 +2,17 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/freeeve/polite-betrayal/api/internal/bot"
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
 )
 
 func TestParseDuration(t *testing.T) {
@@ -32,7 +40,7 @@ func TestCreateGame(t *testing.T) {
 	phaseRepo := newMockPhaseRepo()
 	svc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
 
-	game, err := svc.CreateGame(context.Background(), "Test Game", "user-1", "", "", "", "", "", false)
+	game, err := svc.CreateGame(context.Background(), "Test Game", "user-1", "", "", "", "", "", "", false, model.GameRules{})
 	if err != nil {
 		t.Fatalf("CreateGame: %v", err)
 	}
@@ -70,7 +78,7 @@ func TestCreateGameCustomDurations(t *testing.T) {
 	phaseRepo := newMockPhaseRepo()
 	svc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
 
-	game, err := svc.CreateGame(context.Background(), "Custom", "user-1", "48h", "24h", "24h", "", "", false)
+	game, err := svc.CreateGame(context.Background(), "Custom", "user-1", "48h", "24h", "24h", "", "", "", false, model.GameRules{})
 	if err != nil {
 		t.Fatalf("CreateGame: %v", err)
 	}
@@ -84,7 +92,7 @@ func TestJoinGameReplacesBot(t *testing.T) {
 	phaseRepo := newMockPhaseRepo()
 	svc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
 
-	game, _ := svc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "", false)
+	game, _ := svc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "", "", false, model.GameRules{})
 
 	// Game has 7 players (1 human + 6 bots). Joining should replace a bot.
 	err := svc.JoinGame(context.Background(), game.ID, "user-2")
@@ -107,6 +115,48 @@ func TestJoinGameReplacesBot(t *testing.T) {
 	}
 }
 
+func TestLobbyEvents(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	svc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
+	broadcaster := &recordingBroadcaster{}
+	svc.SetBroadcaster(broadcaster)
+	ctx := context.Background()
+
+	game, err := svc.CreateGame(ctx, "Lobby", "user-1", "", "", "", "", "", "", false, model.GameRules{})
+	if err != nil {
+		t.Fatalf("CreateGame: %v", err)
+	}
+	if err := svc.JoinGame(ctx, game.ID, "user-2"); err != nil {
+		t.Fatalf("JoinGame: %v", err)
+	}
+	if _, err := svc.StartGame(ctx, game.ID, "user-1"); err != nil {
+		t.Fatalf("StartGame: %v", err)
+	}
+
+	want := []struct {
+		eventType, status string
+		humans            int
+	}{
+		{"game_created", "waiting", 1},
+		{"seat_filled", "waiting", 2},
+		{"game_started", "active", 2},
+	}
+	if len(broadcaster.events) != len(want) {
+		t.Fatalf("expected %d lobby events, got %+v", len(want), broadcaster.events)
+	}
+	for i, w := range want {
+		e := broadcaster.events[i]
+		data := e.data.(map[string]any)
+		if !e.lobby || e.eventType != w.eventType || e.gameID != game.ID {
+			t.Errorf("event %d: expected lobby %s for %s, got %+v", i, w.eventType, game.ID, e)
+		}
+		if data["status"] != w.status || data["humans"] != w.humans || data["seats"] != 7 {
+			t.Errorf("%s: unexpected data %v", w.eventType, data)
+		}
+	}
+}
+
 func TestJoinGameNotFound(t *testing.T) {
 	gameRepo := newMockGameRepo()
 	phaseRepo := newMockPhaseRepo()
@@ -123,19 +173,50 @@ func TestJoinGameAlreadyJoined(t *testing.T) {
 	phaseRepo := newMockPhaseRepo()
 	svc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
 
-	game, _ := svc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "", false)
+	game, _ := svc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "", "", false, model.GameRules{})
 	err := svc.JoinGame(context.Background(), game.ID, "user-1")
 	if err != ErrAlreadyJoined {
 		t.Errorf("expected ErrAlreadyJoined, got %v", err)
 	}
 }
 
+func TestSpectateGame(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	svc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
+	ctx := context.Background()
+
+	game, _ := svc.CreateGame(ctx, "Test", "user-1", "", "", "", "", "", "", false, model.GameRules{})
+	if _, err := svc.SpectateGame(ctx, game.ID, "user-1"); err != ErrAlreadyJoined {
+		t.Errorf("expected ErrAlreadyJoined for a player, got %v", err)
+	}
+	g, err := svc.SpectateGame(ctx, game.ID, "user-2")
+	if err != nil {
+		t.Fatalf("SpectateGame: %v", err)
+	}
+	if len(g.Spectators) != 1 || g.Spectators[0] != "user-2" {
+		t.Errorf("expected user-2 spectating, got %v", g.Spectators)
+	}
+	if g, _ = svc.SpectateGame(ctx, game.ID, "user-2"); len(g.Spectators) != 1 {
+		t.Errorf("expected spectating twice to be a no-op, got %v", g.Spectators)
+	}
+
+	// A spectator can still take a seat, and then stops spectating.
+	if err := svc.JoinGame(ctx, game.ID, "user-2"); err != nil {
+		t.Fatalf("JoinGame: %v", err)
+	}
+	g, _ = gameRepo.FindByID(ctx, game.ID)
+	if len(g.Spectators) != 0 || len(g.Players) != 7 {
+		t.Errorf("expected user-2 seated and not spectating, got %d players and spectators %v", len(g.Players), g.Spectators)
+	}
+}
+
 func TestJoinGameFull(t *testing.T) {
 	gameRepo := newMockGameRepo()
 	phaseRepo := newMockPhaseRepo()
 	svc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
 
-	game, _ := svc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "", false)
+	game, _ := svc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "", "", false, model.GameRules{})
 	// Replace all 6 bots with humans
 	for i := 2; i <= 7; i++ {
 		_ = svc.JoinGame(context.Background(), game.ID, fmt.Sprintf("user-%d", i))
@@ -148,12 +229,111 @@ func TestJoinGameFull(t *testing.T) {
 	}
 }
 
+func TestJoinGameConcurrent(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	svc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
+	broadcaster := &recordingBroadcaster{}
+	svc.SetBroadcaster(broadcaster)
+	ctx := context.Background()
+
+	// Two seats: the creator and one bot for the joiners to race for.
+	game, err := svc.CreateGame(ctx, "Duel", "user-1", "", "", "", "", "", "france-austria", false, model.GameRules{})
+	if err != nil {
+		t.Fatalf("CreateGame: %v", err)
+	}
+
+	const joiners = 8
+	errs := make(chan error, joiners)
+	var wg sync.WaitGroup
+	for i := range joiners {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- svc.JoinGame(ctx, game.ID, fmt.Sprintf("user-%d", i+2))
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	joined := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			joined++
+		case !errors.Is(err, ErrGameFull):
+			t.Errorf("expected ErrGameFull for a losing join, got %v", err)
+		}
+	}
+	if joined != 1 {
+		t.Errorf("expected exactly one join to win the last seat, got %d", joined)
+	}
+	g, _ := gameRepo.FindByID(ctx, game.ID)
+	if len(g.Players) != 2 || humanCount(g) != 2 {
+		t.Errorf("expected 2 human players, got %+v", g.Players)
+	}
+
+	full := 0
+	for _, e := range broadcaster.events {
+		if e.eventType == "game_full" {
+			full++
+		}
+	}
+	if full != 1 {
+		t.Errorf("expected one game_full event, got %d", full)
+	}
+}
+
+func TestJoinGameConcurrentSameUser(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	svc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
+	ctx := context.Background()
+
+	game, _ := svc.CreateGame(ctx, "Test", "user-1", "", "", "", "", "", "", false, model.GameRules{})
+
+	errs := make(chan error, 2)
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- svc.JoinGame(ctx, game.ID, "user-2")
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	joined := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			joined++
+		case !errors.Is(err, ErrAlreadyJoined):
+			t.Errorf("expected ErrAlreadyJoined for the duplicate join, got %v", err)
+		}
+	}
+	if joined != 1 {
+		t.Errorf("expected one join to succeed, got %d", joined)
+	}
+	g, _ := gameRepo.FindByID(ctx, game.ID)
+	seated := 0
+	for _, p := range g.Players {
+		if p.UserID == "user-2" {
+			seated++
+		}
+	}
+	if seated != 1 || len(g.Players) != 7 {
+		t.Errorf("expected user-2 seated once among 7 players, got %+v", g.Players)
+	}
+}
+
 func TestJoinGameNotWaiting(t *testing.T) {
 	gameRepo := newMockGameRepo()
 	phaseRepo := newMockPhaseRepo()
 	svc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
 
-	game, _ := svc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "", false)
+	game, _ := svc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "", "", false, model.GameRules{})
 	gameRepo.games[game.ID].Status = "active"
 
 	err := svc.JoinGame(context.Background(), game.ID, "user-2")
@@ -168,7 +348,7 @@ func TestStartGame(t *testing.T) {
 	svc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
 
 	// CreateGame auto-fills with 6 bots (7 players total)
-	game, _ := svc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "", false)
+	game, _ := svc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "", "", false, model.GameRules{})
 
 	result, err := svc.StartGame(context.Background(), game.ID, "user-1")
 	if err != nil {
@@ -202,7 +382,7 @@ func TestStartGameNotCreator(t *testing.T) {
 	phaseRepo := newMockPhaseRepo()
 	svc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
 
-	game, _ := svc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "", false)
+	game, _ := svc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "", "", false, model.GameRules{})
 
 	_, err := svc.StartGame(context.Background(), game.ID, "user-2")
 	if err != ErrNotCreator {
@@ -216,7 +396,7 @@ func TestStartGameImmediatelyAfterCreate(t *testing.T) {
 	svc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
 
 	// CreateGame auto-fills 6 bots, so start should work immediately
-	game, _ := svc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "", false)
+	game, _ := svc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "", "", false, model.GameRules{})
 
 	result, err := svc.StartGame(context.Background(), game.ID, "user-1")
 	if err != nil {
@@ -227,12 +407,59 @@ func TestStartGameImmediatelyAfterCreate(t *testing.T) {
 	}
 }
 
+func TestStartDuelGame(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	svc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
+
+	game, err := svc.CreateGame(context.Background(), "Duel", "user-1", "", "", "", "", "", "france-austria", false, model.GameRules{})
+	if err != nil {
+		t.Fatalf("CreateGame: %v", err)
+	}
+	if len(gameRepo.players[game.ID]) != 2 {
+		t.Fatalf("expected 2 players (1 creator + 1 bot), got %d", len(gameRepo.players[game.ID]))
+	}
+
+	if _, err := svc.StartGame(context.Background(), game.ID, "user-1"); err != nil {
+		t.Fatalf("StartGame: %v", err)
+	}
+	powers := make(map[string]bool)
+	for _, p := range gameRepo.players[game.ID] {
+		powers[p.Power] = true
+	}
+	if !powers["france"] || !powers["austria"] {
+		t.Errorf("expected france and austria assigned, got %v", powers)
+	}
+
+	for _, p := range phaseRepo.phases {
+		var gs diplomacy.GameState
+		if err := json.Unmarshal(p.StateBefore, &gs); err != nil {
+			t.Fatalf("unmarshal state: %v", err)
+		}
+		if gs.Scenario != "france-austria" {
+			t.Errorf("expected duel scenario in state, got %q", gs.Scenario)
+		}
+		if len(gs.Units) != 6 {
+			t.Errorf("expected 6 starting units, got %d", len(gs.Units))
+		}
+	}
+}
+
+func TestCreateGameUnknownScenario(t *testing.T) {
+	svc := NewGameService(newMockGameRepo(), newMockPhaseRepo(), newMockUserRepo())
+
+	_, err := svc.CreateGame(context.Background(), "X", "user-1", "", "", "", "", "", "ffa-13", false, model.GameRules{})
+	if err != ErrUnknownScenario {
+		t.Errorf("expected ErrUnknownScenario, got %v", err)
+	}
+}
+
 func TestDeleteGame(t *testing.T) {
 	gameRepo := newMockGameRepo()
 	phaseRepo := newMockPhaseRepo()
 	svc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
 
-	game, _ := svc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "", false)
+	game, _ := svc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "", "", false, model.GameRules{})
 
 	err := svc.DeleteGame(context.Background(), game.ID, "user-1")
 	if err != nil {
@@ -250,7 +477,7 @@ func TestDeleteGameNotCreator(t *testing.T) {
 	phaseRepo := newMockPhaseRepo()
 	svc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
 
-	game, _ := svc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "", false)
+	game, _ := svc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "", "", false, model.GameRules{})
 
 	err := svc.DeleteGame(context.Background(), game.ID, "user-2")
 	if err != ErrNotCreator {
@@ -263,7 +490,7 @@ func TestDeleteGameNotWaiting(t *testing.T) {
 	phaseRepo := newMockPhaseRepo()
 	svc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
 
-	game, _ := svc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "", false)
+	game, _ := svc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "", "", false, model.GameRules{})
 	svc.StartGame(context.Background(), game.ID, "user-1")
 
 	err := svc.DeleteGame(context.Background(), game.ID, "user-1")
@@ -272,12 +499,81 @@ func TestDeleteGameNotWaiting(t *testing.T) {
 	}
 }
 
+func TestRestoreGame(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	svc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
+	ctx := context.Background()
+
+	game, _ := svc.CreateGame(ctx, "Test", "user-1", "", "", "", "", "", "", false, model.GameRules{})
+	if _, err := svc.RestoreGame(ctx, game.ID, "user-1"); err != ErrGameNotDeleted {
+		t.Errorf("expected ErrGameNotDeleted before delete, got %v", err)
+	}
+	if err := svc.DeleteGame(ctx, game.ID, "user-1"); err != nil {
+		t.Fatalf("DeleteGame: %v", err)
+	}
+	if games, _ := svc.ListGames(ctx, "user-1", "", ""); len(games) != 0 {
+		t.Errorf("expected deleted game hidden from lists, got %d games", len(games))
+	}
+
+	restored, err := svc.RestoreGame(ctx, game.ID, "user-1")
+	if err != nil {
+		t.Fatalf("RestoreGame: %v", err)
+	}
+	if restored.Status != "waiting" || restored.DeletedAt != nil {
+		t.Errorf("expected waiting game after restore, got %s (deleted_at %v)", restored.Status, restored.DeletedAt)
+	}
+}
+
+func TestRestoreGameAfterRetention(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	svc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
+	svc.SetDeletedRetention(time.Hour)
+	ctx := context.Background()
+
+	game, _ := svc.CreateGame(ctx, "Test", "user-1", "", "", "", "", "", "", false, model.GameRules{})
+	svc.DeleteGame(ctx, game.ID, "user-1")
+	old := time.Now().Add(-2 * time.Hour)
+	gameRepo.games[game.ID].DeletedAt = &old
+
+	if _, err := svc.RestoreGame(ctx, game.ID, "user-1"); err != ErrRetentionEnded {
+		t.Errorf("expected ErrRetentionEnded, got %v", err)
+	}
+}
+
+func TestPurgeDeletedGames(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	svc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
+	svc.SetDeletedRetention(time.Hour)
+	ctx := context.Background()
+
+	expired, _ := svc.CreateGame(ctx, "Expired", "user-1", "", "", "", "", "", "", false, model.GameRules{})
+	recent, _ := svc.CreateGame(ctx, "Recent", "user-1", "", "", "", "", "", "", false, model.GameRules{})
+	svc.DeleteGame(ctx, expired.ID, "user-1")
+	svc.DeleteGame(ctx, recent.ID, "user-1")
+	old := time.Now().Add(-2 * time.Hour)
+	gameRepo.games[expired.ID].DeletedAt = &old
+
+	n, err := svc.PurgeDeletedGames(ctx)
+	if err != nil {
+		t.Fatalf("PurgeDeletedGames: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("expected 1 purged game, got %d", n)
+	}
+	if _, ok := gameRepo.games[recent.ID]; !ok {
+		t.Error("expected game inside the retention window to survive")
+	}
+}
+
 func TestStopGame(t *testing.T) {
 	gameRepo := newMockGameRepo()
 	phaseRepo := newMockPhaseRepo()
 	svc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
 
-	game, _ := svc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "", false)
+	game, _ := svc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "", "", false, model.GameRules{})
 	svc.StartGame(context.Background(), game.ID, "user-1")
 
 	result, err := svc.StopGame(context.Background(), game.ID, "user-1")
@@ -297,7 +593,7 @@ func TestStopGameNotCreator(t *testing.T) {
 	phaseRepo := newMockPhaseRepo()
 	svc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
 
-	game, _ := svc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "", false)
+	game, _ := svc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "", "", false, model.GameRules{})
 	svc.StartGame(context.Background(), game.ID, "user-1")
 
 	_, err := svc.StopGame(context.Background(), game.ID, "user-2")
@@ -311,7 +607,7 @@ func TestStopGameNotActive(t *testing.T) {
 	phaseRepo := newMockPhaseRepo()
 	svc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
 
-	game, _ := svc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "", false)
+	game, _ := svc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "", "", false, model.GameRules{})
 
 	_, err := svc.StopGame(context.Background(), game.ID, "user-1")
 	if err != ErrGameNotActive {
@@ -335,7 +631,7 @@ func TestGetGame(t *testing.T) {
 	phaseRepo := newMockPhaseRepo()
 	svc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
 
-	created, _ := svc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "", false)
+	created, _ := svc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "", "", false, model.GameRules{})
 	game, err := svc.GetGame(context.Background(), created.ID)
 	if err != nil {
 		t.Fatalf("GetGame: %v", err)
@@ -361,8 +657,8 @@ func TestListGamesOpen(t *testing.T) {
 	phaseRepo := newMockPhaseRepo()
 	svc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
 
-	svc.CreateGame(context.Background(), "Game1", "user-1", "", "", "", "", "", false)
-	svc.CreateGame(context.Background(), "Game2", "user-2", "", "", "", "", "", false)
+	svc.CreateGame(context.Background(), "Game1", "user-1", "", "", "", "", "", "", false, model.GameRules{})
+	svc.CreateGame(context.Background(), "Game2", "user-2", "", "", "", "", "", "", false, model.GameRules{})
 
 	games, err := svc.ListGames(context.Background(), "user-1", "", "")
 	if err != nil {
@@ -378,8 +674,8 @@ func TestListGamesMy(t *testing.T) {
 	phaseRepo := newMockPhaseRepo()
 	svc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
 
-	svc.CreateGame(context.Background(), "Game1", "user-1", "", "", "", "", "", false)
-	svc.CreateGame(context.Background(), "Game2", "user-2", "", "", "", "", "", false)
+	svc.CreateGame(context.Background(), "Game1", "user-1", "", "", "", "", "", "", false, model.GameRules{})
+	svc.CreateGame(context.Background(), "Game2", "user-2", "", "", "", "", "", "", false, model.GameRules{})
 
 	games, err := svc.ListGames(context.Background(), "user-1", "my", "")
 	if err != nil {
@@ -396,9 +692,9 @@ func TestListGamesBotOnlyVisibleToCreator(t *testing.T) {
 	svc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
 
 	// Create a bot-only game (creator does not join as player)
-	svc.CreateGame(context.Background(), "BotGame", "user-1", "", "", "", "", "", true)
+	svc.CreateGame(context.Background(), "BotGame", "user-1", "", "", "", "", "", "", true, model.GameRules{})
 	// Create a normal game for user-2
-	svc.CreateGame(context.Background(), "NormalGame", "user-2", "", "", "", "", "", false)
+	svc.CreateGame(context.Background(), "NormalGame", "user-2", "", "", "", "", "", "", false, model.GameRules{})
 
 	games, err := svc.ListGames(context.Background(), "user-1", "my", "")
 	if err != nil {
@@ -417,7 +713,7 @@ func TestUpdatePlayerPower(t *testing.T) {
 	phaseRepo := newMockPhaseRepo()
 	svc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
 
-	game, _ := svc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "manual", false)
+	game, _ := svc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "manual", "", false, model.GameRules{})
 
 	// Creator sets own power
 	err := svc.UpdatePlayerPower(context.Background(), game.ID, "user-1", "user-1", "france")
@@ -435,12 +731,41 @@ func TestUpdatePlayerPower(t *testing.T) {
 	}
 }
 
+func TestUpdatePlayerPowerClearsStaleSubmissions(t *testing.T) {
+	ctx := context.Background()
+	gameRepo := newMockGameRepo()
+	cache := newMockCache()
+	svc := NewGameService(gameRepo, newMockPhaseRepo(), newMockUserRepo())
+	svc.SetGameCache(cache)
+
+	game, _ := svc.CreateGame(ctx, "Test", "user-1", "", "", "", "", "manual", "", false, model.GameRules{})
+	if err := svc.UpdatePlayerPower(ctx, game.ID, "user-1", "user-1", "france"); err != nil {
+		t.Fatalf("UpdatePlayerPower: %v", err)
+	}
+	for _, power := range []string{"france", "germany", "italy"} {
+		cache.SetOrders(ctx, game.ID, power, []byte(`[]`))
+		cache.MarkReady(ctx, game.ID, power)
+	}
+
+	if err := svc.UpdatePlayerPower(ctx, game.ID, "user-1", "user-1", "germany"); err != nil {
+		t.Fatalf("UpdatePlayerPower: %v", err)
+	}
+	for _, power := range []string{"france", "germany"} {
+		if orders, _ := cache.GetOrders(ctx, game.ID, power); orders != nil || cache.ready[game.ID][power] {
+			t.Errorf("expected %s's orders and ready flag cleared", power)
+		}
+	}
+	if orders, _ := cache.GetOrders(ctx, game.ID, "italy"); orders == nil || !cache.ready[game.ID]["italy"] {
+		t.Error("expected other powers' submissions kept")
+	}
+}
+
 func TestUpdatePlayerPowerDuplicate(t *testing.T) {
 	gameRepo := newMockGameRepo()
 	phaseRepo := newMockPhaseRepo()
 	svc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
 
-	game, _ := svc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "manual", false)
+	game, _ := svc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "manual", "", false, model.GameRules{})
 
 	// Creator takes france
 	svc.UpdatePlayerPower(context.Background(), game.ID, "user-1", "user-1", "france")
@@ -458,7 +783,7 @@ func TestUpdatePlayerPowerNotManual(t *testing.T) {
 	phaseRepo := newMockPhaseRepo()
 	svc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
 
-	game, _ := svc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "", false)
+	game, _ := svc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "", "", false, model.GameRules{})
 
 	err := svc.UpdatePlayerPower(context.Background(), game.ID, "user-1", "user-1", "france")
 	if err != ErrNotManualMode {
@@ -471,7 +796,7 @@ func TestUpdatePlayerPowerInvalidPower(t *testing.T) {
 	phaseRepo := newMockPhaseRepo()
 	svc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
 
-	game, _ := svc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "manual", false)
+	game, _ := svc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "manual", "", false, model.GameRules{})
 
 	err := svc.UpdatePlayerPower(context.Background(), game.ID, "user-1", "user-1", "narnia")
 	if err != ErrInvalidPower {
@@ -484,7 +809,7 @@ func TestUpdatePlayerPowerBotByNonCreator(t *testing.T) {
 	phaseRepo := newMockPhaseRepo()
 	svc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
 
-	game, _ := svc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "manual", false)
+	game, _ := svc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "manual", "", false, model.GameRules{})
 	svc.JoinGame(context.Background(), game.ID, "user-2")
 
 	// user-2 tries to set a bot power — should fail
@@ -506,7 +831,7 @@ func TestStartGameManualWithPreassigned(t *testing.T) {
 	phaseRepo := newMockPhaseRepo()
 	svc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
 
-	game, _ := svc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "manual", false)
+	game, _ := svc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "manual", "", false, model.GameRules{})
 
 	// Assign a few powers manually
 	svc.UpdatePlayerPower(context.Background(), game.ID, "user-1", "user-1", "france")
@@ -546,3 +871,301 @@ func TestStartGameManualWithPreassigned(t *testing.T) {
 		t.Errorf("expected 7 unique powers, got %d", len(uniquePowers))
 	}
 }
+
+func TestUpdateBotDifficultyRegisteredStrategies(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	svc := NewGameService(gameRepo, newMockPhaseRepo(), newMockUserRepo())
+	ctx := context.Background()
+
+	game, err := svc.CreateGame(ctx, "Bots", "user-1", "24h", "12h", "12h", "easy", "", "", false, model.GameRules{})
+	if err != nil {
+		t.Fatalf("CreateGame: %v", err)
+	}
+	gameRepo.JoinGameAsBot(ctx, game.ID, "bot-1", "easy")
+	for _, name := range []string{"hard", "random", "impossible"} {
+		if _, err := svc.UpdateBotDifficulty(ctx, game.ID, "user-1", "bot-1", name, false); err != nil {
+			t.Errorf("UpdateBotDifficulty(%s): %v", name, err)
+		}
+	}
+	if _, err := svc.UpdateBotDifficulty(ctx, game.ID, "user-1", "bot-1", "grandmaster", false); !errors.Is(err, ErrUnknownStrategy) {
+		t.Errorf("unknown strategy: got %v, want ErrUnknownStrategy", err)
+	}
+	if _, err := svc.UpdateBotDifficulty(ctx, game.ID, "user-1", "user-1", "hard", false); !errors.Is(err, ErrNotBot) {
+		t.Errorf("human player: got %v, want ErrNotBot", err)
+	}
+}
+
+func TestUpdateBotPersonality(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	svc := NewGameService(gameRepo, newMockPhaseRepo(), newMockUserRepo())
+	ctx := context.Background()
+
+	game, err := svc.CreateGame(ctx, "Bots", "user-1", "24h", "12h", "12h", "easy", "", "", false, model.GameRules{})
+	if err != nil {
+		t.Fatalf("CreateGame: %v", err)
+	}
+	gameRepo.JoinGameAsBot(ctx, game.ID, "bot-1", "hard")
+
+	aggressive, _ := bot.LookupPersonality("aggressive")
+	player, err := svc.UpdateBotPersonality(ctx, game.ID, "user-1", "bot-1", &aggressive)
+	if err != nil {
+		t.Fatalf("UpdateBotPersonality: %v", err)
+	}
+	if player.BotPersonality == nil || player.BotPersonality.Aggression != aggressive.Aggression {
+		t.Errorf("expected the aggressive personality, got %+v", player.BotPersonality)
+	}
+	g, _ := gameRepo.FindByID(ctx, game.ID)
+	if i := slices.IndexFunc(g.Players, func(p model.GamePlayer) bool { return p.UserID == "bot-1" }); g.Players[i].BotPersonality == nil {
+		t.Error("expected the personality to be stored")
+	}
+
+	if _, err := svc.UpdateBotPersonality(ctx, game.ID, "user-1", "bot-1", &bot.Personality{Loyalty: 2}); !errors.Is(err, ErrInvalidPersonality) {
+		t.Errorf("out of range trait: got %v, want ErrInvalidPersonality", err)
+	}
+	if _, err := svc.UpdateBotPersonality(ctx, game.ID, "user-2", "bot-1", nil); !errors.Is(err, ErrNotCreator) {
+		t.Errorf("not creator: got %v, want ErrNotCreator", err)
+	}
+	if _, err := svc.UpdateBotPersonality(ctx, game.ID, "user-1", "user-1", nil); !errors.Is(err, ErrNotBot) {
+		t.Errorf("human player: got %v, want ErrNotBot", err)
+	}
+	gameRepo.JoinGameAsBot(ctx, game.ID, "bot-2", "random")
+	if _, err := svc.UpdateBotPersonality(ctx, game.ID, "user-1", "bot-2", &aggressive); !errors.Is(err, ErrInvalidPersonality) {
+		t.Errorf("strategy without personality: got %v, want ErrInvalidPersonality", err)
+	}
+
+	player, err = svc.UpdateBotPersonality(ctx, game.ID, "user-1", "bot-1", nil)
+	if err != nil || player.BotPersonality != nil {
+		t.Errorf("reset: got %+v, %v", player, err)
+	}
+}
+
+func TestUpdateBotDifficultyNextPhase(t *testing.T) {
+	ctx := context.Background()
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	cache := newMockCache()
+	svc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
+	gameID, _ := setupActiveGame(t, gameRepo, phaseRepo, cache)
+	players := gameRepo.players[gameID]
+	players[1].IsBot, players[1].BotDifficulty = true, "random"
+	botID, power := players[1].UserID, players[1].Power
+
+	// Mid-game, the change waits for the next phase.
+	change, err := svc.UpdateBotDifficulty(ctx, gameID, "user-1", botID, "easy", false)
+	if err != nil {
+		t.Fatalf("UpdateBotDifficulty: %v", err)
+	}
+	if change.AppliedAt != nil || players[1].BotDifficulty != "random" {
+		t.Fatalf("expected a pending change, got %+v with difficulty %s", change, players[1].BotDifficulty)
+	}
+	// A second request replaces the pending one.
+	svc.UpdateBotDifficulty(ctx, gameID, "user-1", botID, "random", false)
+
+	phaseSvc := NewPhaseService(gameRepo, phaseRepo, cache, nil)
+	if err := phaseSvc.ResolvePhaseEarly(ctx, gameID); err != nil {
+		t.Fatalf("ResolvePhaseEarly: %v", err)
+	}
+	next, _ := phaseRepo.CurrentPhase(ctx, gameID)
+
+	changes, err := svc.BotDifficultyChanges(ctx, gameID, "user-2")
+	if err != nil {
+		t.Fatalf("BotDifficultyChanges: %v", err)
+	}
+	if len(changes) != 1 || changes[0].AppliedAt == nil || changes[0].PhaseID != next.ID || changes[0].Power != power {
+		t.Fatalf("expected one change applied in the new phase %s, got %+v", next.ID, changes)
+	}
+	if changes[0].From != "random" || changes[0].To != "random" {
+		t.Errorf("expected the replaced request to win, got %s -> %s", changes[0].From, changes[0].To)
+	}
+
+	// Asked to apply now, it changes at once.
+	change, err = svc.UpdateBotDifficulty(ctx, gameID, "user-1", AnonymousID(power), "easy", true)
+	if err != nil || change.AppliedAt == nil || players[1].BotDifficulty != "easy" {
+		t.Fatalf("expected the change applied now, got %+v, %v", change, err)
+	}
+}
+
+func TestUpdateBotPress(t *testing.T) {
+	ctx := context.Background()
+	gameRepo := newMockGameRepo()
+	svc := NewGameService(gameRepo, newMockPhaseRepo(), newMockUserRepo())
+	game, _ := svc.CreateGame(ctx, "Quiet", "user-1", "", "", "", "", "", "", false, model.GameRules{})
+
+	if err := svc.UpdateBotPress(ctx, game.ID, "user-1", "chatty"); !errors.Is(err, ErrUnknownPress) {
+		t.Errorf("expected ErrUnknownPress, got %v", err)
+	}
+	if err := svc.UpdateBotPress(ctx, game.ID, "user-2", "terse"); !errors.Is(err, ErrNotCreator) {
+		t.Errorf("expected ErrNotCreator, got %v", err)
+	}
+	if err := svc.UpdateBotPress(ctx, game.ID, "user-1", "terse"); err != nil {
+		t.Fatalf("UpdateBotPress: %v", err)
+	}
+	if got, _ := gameRepo.FindByID(ctx, game.ID); got.BotPress != "terse" {
+		t.Errorf("expected bot press terse, got %q", got.BotPress)
+	}
+}
+
+func TestUpdatePressSettings(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	svc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
+	ctx := context.Background()
+	game, _ := svc.CreateGame(ctx, "Gunboat", "user-1", "", "", "", "", "", "", false, model.GameRules{})
+
+	if err := svc.UpdatePressSettings(ctx, game.ID, "user-1", "whisper", false); !errors.Is(err, ErrUnknownPressMode) {
+		t.Errorf("expected ErrUnknownPressMode, got %v", err)
+	}
+	if err := svc.UpdatePressSettings(ctx, game.ID, "user-2", model.PressNone, true); !errors.Is(err, ErrNotCreator) {
+		t.Errorf("expected ErrNotCreator, got %v", err)
+	}
+	if err := svc.UpdatePressSettings(ctx, game.ID, "user-1", model.PressNone, true); err != nil {
+		t.Fatalf("UpdatePressSettings: %v", err)
+	}
+	game, _ = gameRepo.FindByID(ctx, game.ID)
+	if game.PressMode != model.PressNone || !game.Anonymous {
+		t.Errorf("expected gunboat anonymous game, got %q %v", game.PressMode, game.Anonymous)
+	}
+	if AllowsPress(game, false) {
+		t.Error("expected no press in a gunboat game")
+	}
+
+	gameRepo.games[game.ID].Status = "active"
+	if err := svc.UpdatePressSettings(ctx, game.ID, "user-1", model.PressFull, false); !errors.Is(err, ErrGameNotWaiting) {
+		t.Errorf("expected ErrGameNotWaiting once started, got %v", err)
+	}
+}
+
+func TestStartGameWithGarrisons(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	svc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
+
+	game, _ := svc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "", "", false, model.GameRules{})
+	if err := svc.UpdateGarrisons(context.Background(), game.ID, "user-2", true); err != ErrNotCreator {
+		t.Errorf("expected ErrNotCreator, got %v", err)
+	}
+	if err := svc.UpdateGarrisons(context.Background(), game.ID, "user-1", true); err != nil {
+		t.Fatalf("UpdateGarrisons: %v", err)
+	}
+	if _, err := svc.StartGame(context.Background(), game.ID, "user-1"); err != nil {
+		t.Fatalf("StartGame: %v", err)
+	}
+
+	var gs diplomacy.GameState
+	for _, p := range phaseRepo.phases {
+		if err := json.Unmarshal(p.StateBefore, &gs); err != nil {
+			t.Fatalf("unmarshal state: %v", err)
+		}
+	}
+	if n := len(gs.Garrisons()); n != 12 {
+		t.Errorf("expected 12 garrisons, got %d", n)
+	}
+	if err := svc.UpdateGarrisons(context.Background(), game.ID, "user-1", false); err == nil {
+		t.Error("expected error changing garrisons of a started game")
+	}
+}
+
+func TestStartGameWithRetreatCredits(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	svc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
+
+	game, _ := svc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "", "", false, model.GameRules{})
+	if err := svc.UpdateRetreatCredits(context.Background(), game.ID, "user-2", true); err != ErrNotCreator {
+		t.Errorf("expected ErrNotCreator, got %v", err)
+	}
+	if err := svc.UpdateRetreatCredits(context.Background(), game.ID, "user-1", true); err != nil {
+		t.Fatalf("UpdateRetreatCredits: %v", err)
+	}
+	if _, err := svc.StartGame(context.Background(), game.ID, "user-1"); err != nil {
+		t.Fatalf("StartGame: %v", err)
+	}
+
+	var gs diplomacy.GameState
+	for _, p := range phaseRepo.phases {
+		if err := json.Unmarshal(p.StateBefore, &gs); err != nil {
+			t.Fatalf("unmarshal state: %v", err)
+		}
+	}
+	if !gs.RetreatCredits {
+		t.Error("expected the initial state to play retreat credits")
+	}
+	if err := svc.UpdateRetreatCredits(context.Background(), game.ID, "user-1", false); err == nil {
+		t.Error("expected error changing retreat credits of a started game")
+	}
+}
+
+func TestStartGameWithMercy(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	svc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
+
+	game, _ := svc.CreateGame(context.Background(), "Test", "user-1", "", "", "", "", "", "", false, model.GameRules{})
+	if err := svc.UpdateMercyYears(context.Background(), game.ID, "user-2", 2); err != ErrNotCreator {
+		t.Errorf("expected ErrNotCreator, got %v", err)
+	}
+	if err := svc.UpdateMercyYears(context.Background(), game.ID, "user-1", MaxMercyYears+1); err != ErrInvalidMercy {
+		t.Errorf("expected ErrInvalidMercy, got %v", err)
+	}
+	if err := svc.UpdateMercyYears(context.Background(), game.ID, "user-1", 2); err != nil {
+		t.Fatalf("UpdateMercyYears: %v", err)
+	}
+	if _, err := svc.StartGame(context.Background(), game.ID, "user-1"); err != nil {
+		t.Fatalf("StartGame: %v", err)
+	}
+
+	var gs diplomacy.GameState
+	for _, p := range phaseRepo.phases {
+		if err := json.Unmarshal(p.StateBefore, &gs); err != nil {
+			t.Fatalf("unmarshal state: %v", err)
+		}
+	}
+	started, _ := gameRepo.FindByID(context.Background(), game.ID)
+	var human diplomacy.Power
+	for _, p := range started.Players {
+		if p.UserID == "user-1" {
+			human = diplomacy.Power(p.Power)
+		}
+	}
+	if len(gs.MercyPowers) != 1 || gs.MercyPowers[0] != human {
+		t.Errorf("expected mercy for the creator's power %s, got %v", human, gs.MercyPowers)
+	}
+	if gs.MercyUntil != 1902 {
+		t.Errorf("expected mercy through 1902, got %d", gs.MercyUntil)
+	}
+	if err := svc.UpdateMercyYears(context.Background(), game.ID, "user-1", 0); err == nil {
+		t.Error("expected error changing mercy of a started game")
+	}
+}
+
+func TestMercyRejectsStrategiesThatIgnoreIt(t *testing.T) {
+	ctx := context.Background()
+	svc := NewGameService(newMockGameRepo(), newMockPhaseRepo(), newMockUserRepo())
+
+	expert, _ := svc.CreateGame(ctx, "Expert", "user-1", "", "", "", "expert", "", "", false, model.GameRules{})
+	if err := svc.UpdateMercyYears(ctx, expert.ID, "user-1", 2); !errors.Is(err, ErrMercyUnsupported) {
+		t.Errorf("expected ErrMercyUnsupported for expert bots, got %v", err)
+	}
+
+	game, _ := svc.CreateGame(ctx, "Teaching", "user-1", "", "", "", "hard", "", "", false, model.GameRules{})
+	if err := svc.UpdateMercyYears(ctx, game.ID, "user-1", 2); err != nil {
+		t.Fatalf("UpdateMercyYears: %v", err)
+	}
+	game, _ = svc.GetGame(ctx, game.ID)
+	var botID string
+	for _, p := range game.Players {
+		if p.IsBot {
+			botID = p.UserID
+			break
+		}
+	}
+	if _, err := svc.UpdateBotDifficulty(ctx, game.ID, "user-1", botID, "hard-gonnx", false); !errors.Is(err, ErrMercyUnsupported) {
+		t.Errorf("expected ErrMercyUnsupported switching a bot to hard-gonnx, got %v", err)
+	}
+	if err := svc.UpdateCivilDisorder(ctx, game.ID, "user-1", 3, "realpolitik"); !errors.Is(err, ErrMercyUnsupported) {
+		t.Errorf("expected ErrMercyUnsupported for a realpolitik civil disorder bot, got %v", err)
+	}
+	if _, err := svc.UpdateBotDifficulty(ctx, game.ID, "user-1", botID, "medium", false); err != nil {
+		t.Errorf("UpdateBotDifficulty to medium: %v", err)
+	}
+}
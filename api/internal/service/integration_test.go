@@ -12,6 +12,7 @@ import (
 
 	goredis "github.com/redis/go-redis/v9"
 
+	"github.com/freeeve/polite-betrayal/api/internal/bot"
 	"github.com/freeeve/polite-betrayal/api/internal/model"
 	"github.com/freeeve/polite-betrayal/api/internal/repository/postgres"
 	redisrepo "github.com/freeeve/polite-betrayal/api/internal/repository/redis"
@@ -74,7 +75,7 @@ func createAndStartGame(t *testing.T, e *testEnv) (*model.Game, []*model.User) {
 	users := createUsers(t, e.userRepo)
 
 	gameSvc := NewGameService(e.gameRepo, e.phaseRepo, e.userRepo)
-	game, err := gameSvc.CreateGame(ctx, "Integration Test", users[0].ID, "24 hours", "12 hours", "12 hours", "", "", false)
+	game, err := gameSvc.CreateGame(ctx, "Integration Test", users[0].ID, "24 hours", "12 hours", "12 hours", "", "", "", false, model.GameRules{})
 	if err != nil {
 		t.Fatalf("create game: %v", err)
 	}
@@ -186,6 +187,33 @@ func TestFullGameLifecycle(t *testing.T) {
 	}
 }
 
+// TestBotPactSurvivesResolution verifies that a bot's pacts are carried
+// from one phase to the next rather than cleared with the phase's orders.
+func TestBotPactSurvivesResolution(t *testing.T) {
+	e := setupEnv(t)
+	ctx := context.Background()
+
+	game, _ := createAndStartGame(t, e)
+	phase, _ := e.phaseRepo.CurrentPhase(ctx, game.ID)
+	var gs diplomacy.GameState
+	json.Unmarshal(phase.StateBefore, &gs)
+
+	phaseSvc := NewPhaseService(e.gameRepo, e.phaseRepo, e.cache, nil)
+	phaseSvc.SetAllianceStore(e.cache)
+	phaseSvc.InitializeGame(ctx, game.ID, &gs, time.Now().Add(24*time.Hour))
+	phaseSvc.updateAlliances(ctx, game.ID, "france", func(a *bot.AllianceState) {
+		a.RecordPact(diplomacy.Germany, 1901)
+	})
+
+	if err := phaseSvc.ResolvePhaseEarly(ctx, game.ID); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	alliances := phaseSvc.loadAlliances(ctx, game.ID, "france")
+	if alliances == nil || alliances.ActivePact(diplomacy.Germany) == nil {
+		t.Fatalf("expected the france-germany pact to survive resolution, got %+v", alliances)
+	}
+}
+
 // TestDefaultOrdersAllHold verifies that resolve without submitted orders defaults to all hold.
 func TestDefaultOrdersAllHold(t *testing.T) {
 	e := setupEnv(t)
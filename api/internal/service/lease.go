@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/freeeve/polite-betrayal/api/internal/repository"
+)
+
+// Lease timings. A lease lapses leaseTTL after its last renewal, so one held
+// by a crashed server frees up quickly, and its holder renews it every
+// leaseRenewal so slow work never outlives it.
+const (
+	leaseTTL     = 30 * time.Second
+	leaseRenewal = leaseTTL / 3
+	leaseRetry   = 100 * time.Millisecond // between attempts to take a held lease
+	leaseWait    = 2 * time.Minute        // longest to wait for a held lease
+)
+
+// timerLeaseKey is the lease whose holder runs the timer listener.
+const timerLeaseKey = "timer:leader"
+
+var (
+	ErrLeaseTimeout = errors.New("timed out waiting for lease")
+	ErrLeaseLost    = errors.New("lease lost while held")
+)
+
+// gameLeaseKey is the lease that serializes work on a game across servers.
+func gameLeaseKey(gameID string) string { return "game:" + gameID }
+
+// lease is a held lease, renewed in the background until released.
+type lease struct {
+	store repository.LeaseStore
+	key   string
+	owner string
+	stop  chan struct{}
+	lost  chan struct{}
+	done  chan struct{}
+}
+
+// tryLease makes one attempt to take the lease on key, returning nil if
+// another owner holds it.
+func tryLease(ctx context.Context, store repository.LeaseStore, key, owner string) (*lease, error) {
+	ok, err := store.AcquireLease(ctx, key, owner, leaseTTL)
+	if err != nil || !ok {
+		return nil, err
+	}
+	l := &lease{
+		store: store,
+		key:   key,
+		owner: owner,
+		stop:  make(chan struct{}),
+		lost:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	go l.renew()
+	return l, nil
+}
+
+// waitLease takes the lease on key, waiting up to leaseWait for its holder
+// to let it go.
+func waitLease(ctx context.Context, store repository.LeaseStore, key, owner string) (*lease, error) {
+	deadline := time.Now().Add(leaseWait)
+	for {
+		l, err := tryLease(ctx, store, key, owner)
+		if err != nil || l != nil {
+			return l, err
+		}
+		if time.Now().After(deadline) {
+			return nil, ErrLeaseTimeout
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(leaseRetry):
+		}
+	}
+}
+
+// renew keeps the lease alive until it is released, closing lost if it
+// turns out another owner has taken it. A failed renewal is retried at the
+// next tick; the lease only lapses if renewals keep failing.
+func (l *lease) renew() {
+	defer close(l.done)
+	ticker := time.NewTicker(leaseRenewal)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			ok, err := l.store.RenewLease(context.Background(), l.key, l.owner, leaseTTL)
+			if err != nil {
+				log.Warn().Err(err).Str("lease", l.key).Msg("Failed to renew lease")
+				continue
+			}
+			if !ok {
+				log.Warn().Str("lease", l.key).Str("owner", l.owner).Msg("Lease lost")
+				close(l.lost)
+				return
+			}
+		}
+	}
+}
+
+// Lost is closed if the lease lapsed while still held.
+func (l *lease) Lost() <-chan struct{} {
+	return l.lost
+}
+
+// Release stops renewing the lease and gives it up.
+func (l *lease) Release() {
+	close(l.stop)
+	<-l.done
+	if err := l.store.ReleaseLease(context.Background(), l.key, l.owner); err != nil {
+		log.Warn().Err(err).Str("lease", l.key).Msg("Failed to release lease")
+	}
+}
+
+// runAsLeader calls run whenever owner holds the lease on key, with a
+// context canceled if the lease is lost, and otherwise retries every
+// leaseRenewal so it takes over soon after the leader stops renewing. It
+// returns once ctx is done.
+func runAsLeader(ctx context.Context, store repository.LeaseStore, key, owner string, run func(ctx context.Context)) {
+	ticker := time.NewTicker(leaseRenewal)
+	defer ticker.Stop()
+	for {
+		l, err := tryLease(ctx, store, key, owner)
+		if err != nil {
+			log.Warn().Err(err).Str("lease", key).Msg("Failed to acquire lease")
+		}
+		if l != nil {
+			log.Info().Str("lease", key).Str("owner", owner).Msg("Elected leader")
+			leadCtx, cancel := context.WithCancel(ctx)
+			go func() {
+				select {
+				case <-l.Lost():
+					cancel()
+				case <-leadCtx.Done():
+				}
+			}()
+			run(leadCtx)
+			cancel()
+			l.Release()
+			log.Info().Str("lease", key).Str("owner", owner).Msg("Stepped down as leader")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
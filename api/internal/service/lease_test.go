@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLockGameAcrossReplicas(t *testing.T) {
+	leases := newMockLeaseStore()
+	a := NewPhaseService(newMockGameRepo(), newMockPhaseRepo(), newMockCache(), nil)
+	a.SetLeaseStore(leases, "replica-a")
+	b := NewPhaseService(newMockGameRepo(), newMockPhaseRepo(), newMockCache(), nil)
+	b.SetLeaseStore(leases, "replica-b")
+	ctx := context.Background()
+
+	unlockA, err := a.lockGame(ctx, "game-1")
+	if err != nil {
+		t.Fatalf("lock on a: %v", err)
+	}
+	if got := leases.owner(gameLeaseKey("game-1")); got != "replica-a" {
+		t.Fatalf("lease owner = %q, want replica-a", got)
+	}
+
+	locked := make(chan func())
+	go func() {
+		unlockB, err := b.lockGame(ctx, "game-1")
+		if err != nil {
+			t.Errorf("lock on b: %v", err)
+		}
+		locked <- unlockB
+	}()
+	select {
+	case <-locked:
+		t.Fatal("b locked the game while a held it")
+	case <-time.After(3 * leaseRetry):
+	}
+
+	unlockA()
+	select {
+	case unlockB := <-locked:
+		if got := leases.owner(gameLeaseKey("game-1")); got != "replica-b" {
+			t.Errorf("lease owner = %q, want replica-b", got)
+		}
+		unlockB()
+	case <-time.After(time.Second):
+		t.Fatal("b never locked the game after a released it")
+	}
+	if got := leases.owner(gameLeaseKey("game-1")); got != "" {
+		t.Errorf("lease still held by %q after unlocking", got)
+	}
+}
+
+func TestRunAsLeader(t *testing.T) {
+	leases := newMockLeaseStore()
+	leases.owners[timerLeaseKey] = "replica-a"
+
+	// A follower never runs while the leader holds the lease.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	runAsLeader(ctx, leases, timerLeaseKey, "replica-b", func(context.Context) {
+		t.Error("follower ran while another replica led")
+	})
+
+	// Once the lease is free it leads, and gives the lease up when done.
+	delete(leases.owners, timerLeaseKey)
+	ctx, cancel = context.WithCancel(context.Background())
+	ran := false
+	runAsLeader(ctx, leases, timerLeaseKey, "replica-b", func(context.Context) {
+		ran = true
+		if got := leases.owner(timerLeaseKey); got != "replica-b" {
+			t.Errorf("lease owner while leading = %q, want replica-b", got)
+		}
+		cancel()
+	})
+	if !ran {
+		t.Error("expected replica-b to lead once the lease was free")
+	}
+	if got := leases.owner(timerLeaseKey); got != "" {
+		t.Errorf("lease still held by %q after stepping down", got)
+	}
+}
@@ -4,47 +4,75 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/internal/repository"
 )
 
 type mockGameRepo struct {
-	games   map[string]*model.Game
-	players map[string][]model.GamePlayer
+	games       map[string]*model.Game
+	players     map[string][]model.GamePlayer
+	spectators  map[string][]string
+	deletedFrom map[string]string // gameID -> status before soft delete
+
+	difficultyChanges map[string][]model.BotDifficultyChange
+	currentPhaseID    map[string]string // phase recorded against immediate difficulty changes
+	assignments       map[string][]model.PowerAssignment
+
+	mu sync.Mutex // held by FindByID and ClaimSeat so concurrent joins can be tested
 }
 
 func newMockGameRepo() *mockGameRepo {
 	return &mockGameRepo{
-		games:   make(map[string]*model.Game),
-		players: make(map[string][]model.GamePlayer),
+		games:       make(map[string]*model.Game),
+		players:     make(map[string][]model.GamePlayer),
+		spectators:  make(map[string][]string),
+		deletedFrom: make(map[string]string),
+
+		difficultyChanges: make(map[string][]model.BotDifficultyChange),
+		currentPhaseID:    make(map[string]string),
+		assignments:       make(map[string][]model.PowerAssignment),
 	}
 }
 
-func (m *mockGameRepo) Create(_ context.Context, name, creatorID, turnDur, retreatDur, buildDur, powerAssignment string) (*model.Game, error) {
+func (m *mockGameRepo) Create(_ context.Context, name, creatorID, turnDur, retreatDur, buildDur, powerAssignment, speedPreset, scenario string, rules model.GameRules) (*model.Game, error) {
 	g := &model.Game{
-		ID:              fmt.Sprintf("game-%d", len(m.games)+1),
-		Name:            name,
-		CreatorID:       creatorID,
-		Status:          "waiting",
-		TurnDuration:    turnDur,
-		RetreatDuration: retreatDur,
-		BuildDuration:   buildDur,
-		PowerAssignment: powerAssignment,
-		CreatedAt:       time.Now(),
+		ID:                 fmt.Sprintf("game-%d", len(m.games)+1),
+		Name:               name,
+		CreatorID:          creatorID,
+		Status:             "waiting",
+		TurnDuration:       turnDur,
+		RetreatDuration:    retreatDur,
+		BuildDuration:      buildDur,
+		PowerAssignment:    powerAssignment,
+		SpeedPreset:        speedPreset,
+		Scenario:           scenario,
+		RetreatCredits:     rules.RetreatCredits,
+		CivilDisorderAfter: rules.CivilDisorderAfter,
+		CivilDisorderBot:   rules.CivilDisorderBot,
+		ReadyQuorum:        rules.ReadyQuorum,
+		QuorumDelay:        rules.QuorumDelay,
+		CreatedAt:          time.Now(),
 	}
 	m.games[g.ID] = g
 	return g, nil
 }
 
 func (m *mockGameRepo) FindByID(_ context.Context, id string) (*model.Game, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	g, ok := m.games[id]
 	if !ok {
 		return nil, nil
 	}
 	cp := *g
-	cp.Players = m.players[id]
+	cp.Players = slices.Clone(m.players[id])
+	cp.Spectators = slices.Clone(m.spectators[id])
 	return &cp, nil
 }
 
@@ -64,7 +92,7 @@ func (m *mockGameRepo) ListByUser(_ context.Context, userID string) ([]model.Gam
 	for gameID, players := range m.players {
 		for _, p := range players {
 			if p.UserID == userID && !seen[gameID] {
-				if g, ok := m.games[gameID]; ok {
+				if g, ok := m.games[gameID]; ok && g.Status != "deleted" {
 					result = append(result, *g)
 					seen[gameID] = true
 				}
@@ -82,6 +110,7 @@ func (m *mockGameRepo) ListByUser(_ context.Context, userID string) ([]model.Gam
 }
 
 func (m *mockGameRepo) JoinGame(_ context.Context, gameID, userID string) error {
+	m.spectators[gameID] = slices.DeleteFunc(m.spectators[gameID], func(id string) bool { return id == userID })
 	m.players[gameID] = append(m.players[gameID], model.GamePlayer{
 		GameID:   gameID,
 		UserID:   userID,
@@ -102,14 +131,26 @@ func (m *mockGameRepo) AssignPowers(_ context.Context, gameID string, assignment
 		}
 	}
 	m.players[gameID] = players
+	now := time.Now()
+	for _, p := range players {
+		if p.Power != "" {
+			m.assignments[gameID] = append(m.assignments[gameID], model.PowerAssignment{Power: p.Power, UserID: p.UserID, IsBot: p.IsBot, AssignedAt: now})
+		}
+	}
 	if g, ok := m.games[gameID]; ok {
 		g.Status = "active"
-		now := time.Now()
 		g.StartedAt = &now
 	}
 	return nil
 }
 
+func (m *mockGameRepo) Spectate(_ context.Context, gameID, userID string) error {
+	if !slices.Contains(m.spectators[gameID], userID) {
+		m.spectators[gameID] = append(m.spectators[gameID], userID)
+	}
+	return nil
+}
+
 func (m *mockGameRepo) JoinGameAsBot(_ context.Context, gameID, userID, difficulty string) error {
 	if difficulty == "" {
 		difficulty = "easy"
@@ -124,19 +165,29 @@ func (m *mockGameRepo) JoinGameAsBot(_ context.Context, gameID, userID, difficul
 	return nil
 }
 
-func (m *mockGameRepo) ReplaceBot(_ context.Context, gameID, newUserID string) error {
+func (m *mockGameRepo) ClaimSeat(_ context.Context, gameID, userID string, seats int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if g, ok := m.games[gameID]; !ok || g.Status != "waiting" {
+		return repository.ErrNotJoinable
+	}
 	players := m.players[gameID]
-	for i, p := range players {
-		if p.IsBot {
-			m.players[gameID] = append(players[:i], append([]model.GamePlayer{{
-				GameID:   gameID,
-				UserID:   newUserID,
-				JoinedAt: time.Now(),
-			}}, players[i+1:]...)...)
-			return nil
+	for _, p := range players {
+		if p.UserID == userID {
+			return repository.ErrAlreadySeated
 		}
 	}
-	return fmt.Errorf("no bot to replace")
+	player := model.GamePlayer{GameID: gameID, UserID: userID, JoinedAt: time.Now()}
+	switch i := slices.IndexFunc(players, func(p model.GamePlayer) bool { return p.IsBot }); {
+	case len(players) < seats:
+		m.players[gameID] = append(players, player)
+	case i >= 0:
+		players[i] = player
+	default:
+		return repository.ErrSeatTaken
+	}
+	m.spectators[gameID] = slices.DeleteFunc(m.spectators[gameID], func(id string) bool { return id == userID })
+	return nil
 }
 
 func (m *mockGameRepo) ListFinished(_ context.Context) ([]model.Game, error) {
@@ -164,15 +215,111 @@ func (m *mockGameRepo) SearchFinished(_ context.Context, search string) ([]model
 	return result, nil
 }
 
-func (m *mockGameRepo) ListActive(_ context.Context) ([]model.Game, error) {
+func (m *mockGameRepo) ListActiveByUser(_ context.Context, userID string) ([]model.Game, error) {
+	var result []model.Game
+	for gameID, players := range m.players {
+		g, ok := m.games[gameID]
+		if !ok || g.Status != "active" {
+			continue
+		}
+		for _, p := range players {
+			if p.UserID == userID {
+				cp := *g
+				cp.Players = players
+				result = append(result, cp)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+func (m *mockGameRepo) CountUserGames(_ context.Context, userID string) (*model.UserStats, error) {
+	stats := &model.UserStats{UserID: userID}
+	for gameID, players := range m.players {
+		g, ok := m.games[gameID]
+		if !ok || (g.Status != "active" && g.Status != "finished") {
+			continue
+		}
+		for _, p := range players {
+			if p.UserID != userID {
+				continue
+			}
+			stats.GamesPlayed++
+			if g.Status == "finished" {
+				stats.GamesFinished++
+				switch g.Winner {
+				case "":
+					stats.Draws++
+				case p.Power:
+					stats.Solos++
+				}
+			}
+			break
+		}
+	}
+	return stats, nil
+}
+
+func (m *mockGameRepo) ListFinishedPage(_ context.Context, search, afterID string, limit int) ([]model.Game, error) {
+	lower := strings.ToLower(search)
+	var result []model.Game
+	for _, g := range m.games {
+		if g.Status == "finished" && g.ID > afterID && strings.Contains(strings.ToLower(g.Name), lower) {
+			cp := *g
+			cp.Players = m.players[g.ID]
+			result = append(result, cp)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (m *mockGameRepo) RankLive(_ context.Context, activity []model.GameActivity, limit int) ([]model.LiveGame, error) {
+	games := []model.LiveGame{}
+	for _, a := range activity {
+		g, ok := m.games[a.GameID]
+		if !ok || (g.Status != "active" && g.Status != "paused") {
+			continue
+		}
+		botOnly := false
+		for _, p := range m.players[a.GameID] {
+			if !p.IsBot {
+				botOnly = false
+				break
+			}
+			botOnly = true
+		}
+		games = append(games, model.LiveGame{GameActivity: a, Name: g.Name, Status: g.Status, Scenario: g.Scenario, Anonymous: g.Anonymous, BotOnly: botOnly})
+	}
+	sort.SliceStable(games, func(i, j int) bool {
+		if games[i].Viewers != games[j].Viewers {
+			return games[i].Viewers > games[j].Viewers
+		}
+		return games[i].LastActivity.After(games[j].LastActivity)
+	})
+	if len(games) > limit {
+		games = games[:limit]
+	}
+	return games, nil
+}
+
+func (m *mockGameRepo) ListActive(_ context.Context, afterID string, limit int) ([]model.Game, error) {
 	var result []model.Game
 	for _, g := range m.games {
-		if g.Status == "active" {
+		if g.Status == "active" && g.ID > afterID {
 			cp := *g
 			cp.Players = m.players[g.ID]
 			result = append(result, cp)
 		}
 	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	if len(result) > limit {
+		result = result[:limit]
+	}
 	return result, nil
 }
 
@@ -190,6 +337,135 @@ func (m *mockGameRepo) Delete(_ context.Context, gameID string) error {
 	return nil
 }
 
+func (m *mockGameRepo) SoftDelete(_ context.Context, gameID string) error {
+	if g, ok := m.games[gameID]; ok && g.Status != "deleted" {
+		now := time.Now()
+		m.deletedFrom[gameID] = g.Status
+		g.Status = "deleted"
+		g.DeletedAt = &now
+	}
+	return nil
+}
+
+func (m *mockGameRepo) Restore(_ context.Context, gameID string) error {
+	if g, ok := m.games[gameID]; ok && g.Status == "deleted" {
+		g.Status = m.deletedFrom[gameID]
+		g.DeletedAt = nil
+		delete(m.deletedFrom, gameID)
+	}
+	return nil
+}
+
+func (m *mockGameRepo) PurgeDeleted(_ context.Context, before time.Time) (int, error) {
+	n := 0
+	for id, g := range m.games {
+		if g.Status == "deleted" && g.DeletedAt != nil && g.DeletedAt.Before(before) {
+			delete(m.games, id)
+			delete(m.players, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *mockGameRepo) UpdateBotPress(_ context.Context, gameID, style string) error {
+	if g, ok := m.games[gameID]; ok {
+		g.BotPress = style
+	}
+	return nil
+}
+
+func (m *mockGameRepo) UpdatePressSettings(_ context.Context, gameID, pressMode string, anonymous bool) error {
+	if g, ok := m.games[gameID]; ok {
+		g.PressMode, g.Anonymous = pressMode, anonymous
+	}
+	return nil
+}
+
+func (m *mockGameRepo) UpdateGarrisons(_ context.Context, gameID string, garrisons bool) error {
+	if g, ok := m.games[gameID]; ok {
+		g.Garrisons = garrisons
+	}
+	return nil
+}
+
+func (m *mockGameRepo) UpdateRetreatCredits(_ context.Context, gameID string, retreatCredits bool) error {
+	if g, ok := m.games[gameID]; ok {
+		g.RetreatCredits = retreatCredits
+	}
+	return nil
+}
+
+func (m *mockGameRepo) UpdateMercyYears(_ context.Context, gameID string, years int) error {
+	if g, ok := m.games[gameID]; ok {
+		g.MercyYears = years
+	}
+	return nil
+}
+
+func (m *mockGameRepo) UpdateReadyQuorum(_ context.Context, gameID string, percent int, delay string) error {
+	if g, ok := m.games[gameID]; ok {
+		g.ReadyQuorum, g.QuorumDelay = percent, delay
+	}
+	return nil
+}
+
+func (m *mockGameRepo) UpdateCivilDisorder(_ context.Context, gameID string, after int, botDifficulty string) error {
+	if g, ok := m.games[gameID]; ok {
+		g.CivilDisorderAfter, g.CivilDisorderBot = after, botDifficulty
+	}
+	return nil
+}
+
+func (m *mockGameRepo) RecordDeadline(_ context.Context, gameID string, missed, acted []string) ([]model.GamePlayer, error) {
+	after := 0
+	if g, ok := m.games[gameID]; ok {
+		after = g.CivilDisorderAfter
+	}
+	var result []model.GamePlayer
+	for i := range m.players[gameID] {
+		p := &m.players[gameID][i]
+		switch {
+		case slices.Contains(acted, p.UserID):
+			p.MissedDeadlines, p.CivilDisorder = 0, false
+		case slices.Contains(missed, p.UserID) && !p.IsBot:
+			p.MissedDeadlines++
+			p.CivilDisorder = p.CivilDisorder || (after > 0 && p.MissedDeadlines >= after)
+			result = append(result, *p)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockGameRepo) ReplaceWithBot(_ context.Context, gameID, userID, botUserID, difficulty string) (*model.GamePlayer, error) {
+	for i := range m.players[gameID] {
+		p := &m.players[gameID][i]
+		if p.UserID == userID && !p.IsBot {
+			*p = model.GamePlayer{GameID: gameID, UserID: botUserID, Power: p.Power, IsBot: true, BotDifficulty: difficulty, JoinedAt: time.Now()}
+			m.spectators[gameID] = append(m.spectators[gameID], userID)
+			m.assignments[gameID] = append(m.assignments[gameID], model.PowerAssignment{Power: p.Power, UserID: botUserID, IsBot: true, AssignedAt: p.JoinedAt})
+			cp := *p
+			return &cp, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockGameRepo) PowerAssignments(_ context.Context, gameID string) ([]model.PowerAssignment, error) {
+	return m.assignments[gameID], nil
+}
+
+func (m *mockGameRepo) SetPaused(_ context.Context, gameID string, paused bool) error {
+	if g, ok := m.games[gameID]; ok {
+		if paused && g.Status == "active" {
+			g.Status = "paused"
+		} else if !paused && g.Status == "paused" {
+			g.Status = "active"
+		}
+	}
+	return nil
+}
+
 func (m *mockGameRepo) UpdatePlayerPower(_ context.Context, gameID, userID, power string) error {
 	players := m.players[gameID]
 	for i, p := range players {
@@ -201,21 +477,99 @@ func (m *mockGameRepo) UpdatePlayerPower(_ context.Context, gameID, userID, powe
 	return fmt.Errorf("player not found")
 }
 
-func (m *mockGameRepo) UpdateBotDifficulty(_ context.Context, gameID, botUserID, difficulty string) error {
+func (m *mockGameRepo) UpdateBotPersonality(_ context.Context, gameID, botUserID string, personality *model.BotPersonality) error {
+	for i, p := range m.players[gameID] {
+		if p.UserID == botUserID && p.IsBot {
+			m.players[gameID][i].BotPersonality = personality
+		}
+	}
+	return nil
+}
+
+func (m *mockGameRepo) SetEliminated(_ context.Context, gameID, power, by string, year int) error {
+	players := m.players[gameID]
+	for i, p := range players {
+		if p.Power == power {
+			players[i].EliminatedBy = by
+			players[i].EliminatedYear = year
+			return nil
+		}
+	}
+	return fmt.Errorf("player not found")
+}
+
+func (m *mockGameRepo) UpdateBotDifficulty(_ context.Context, gameID, botUserID, difficulty, reason, changedBy string) (*model.BotDifficultyChange, error) {
 	players := m.players[gameID]
 	for i, p := range players {
 		if p.UserID == botUserID && p.IsBot {
 			players[i].BotDifficulty = difficulty
-			return nil
+			m.dropPendingDifficulty(gameID, botUserID)
+			now := time.Now()
+			c := model.BotDifficultyChange{
+				ID: int64(len(m.difficultyChanges[gameID]) + 1), GameID: gameID, UserID: botUserID, Power: p.Power,
+				From: p.BotDifficulty, To: difficulty, Reason: reason, ChangedBy: changedBy,
+				PhaseID: m.currentPhaseID[gameID], RequestedAt: now, AppliedAt: &now,
+			}
+			m.difficultyChanges[gameID] = append(m.difficultyChanges[gameID], c)
+			return &c, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockGameRepo) RequestBotDifficulty(_ context.Context, gameID, botUserID, difficulty, changedBy string) (*model.BotDifficultyChange, error) {
+	for _, p := range m.players[gameID] {
+		if p.UserID == botUserID && p.IsBot {
+			m.dropPendingDifficulty(gameID, botUserID)
+			c := model.BotDifficultyChange{
+				ID: int64(len(m.difficultyChanges[gameID]) + 1), GameID: gameID, UserID: botUserID, Power: p.Power,
+				From: p.BotDifficulty, To: difficulty, Reason: model.BotDifficultyByCreator, ChangedBy: changedBy,
+				RequestedAt: time.Now(),
+			}
+			m.difficultyChanges[gameID] = append(m.difficultyChanges[gameID], c)
+			return &c, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockGameRepo) dropPendingDifficulty(gameID, userID string) {
+	m.difficultyChanges[gameID] = slices.DeleteFunc(m.difficultyChanges[gameID], func(c model.BotDifficultyChange) bool {
+		return c.UserID == userID && c.AppliedAt == nil
+	})
+}
+
+func (m *mockGameRepo) ApplyPendingBotDifficulties(_ context.Context, gameID, phaseID string) ([]model.BotDifficultyChange, error) {
+	var applied []model.BotDifficultyChange
+	changes := m.difficultyChanges[gameID]
+	for i := range changes {
+		c := &changes[i]
+		if c.AppliedAt != nil {
+			continue
+		}
+		players := m.players[gameID]
+		for j := range players {
+			if players[j].UserID == c.UserID {
+				c.From = players[j].BotDifficulty
+				players[j].BotDifficulty = c.To
+			}
 		}
+		now := time.Now()
+		c.AppliedAt, c.PhaseID = &now, phaseID
+		applied = append(applied, *c)
 	}
-	return fmt.Errorf("bot not found")
+	return applied, nil
+}
+
+func (m *mockGameRepo) BotDifficultyChanges(_ context.Context, gameID string) ([]model.BotDifficultyChange, error) {
+	return slices.Clone(m.difficultyChanges[gameID]), nil
 }
 
 // mockUserRepo implements repository.UserRepository for testing.
 type mockUserRepo struct {
-	users map[string]*model.User
-	seq   int
+	users   map[string]*model.User
+	seq     int
+	upserts int
 }
 
 func newMockUserRepo() *mockUserRepo {
@@ -240,6 +594,7 @@ func (m *mockUserRepo) FindByProviderID(_ context.Context, provider, providerID
 }
 
 func (m *mockUserRepo) Upsert(_ context.Context, provider, providerID, displayName, avatarURL string) (*model.User, error) {
+	m.upserts++
 	// Check for existing
 	for _, u := range m.users {
 		if u.Provider == provider && u.ProviderID == providerID {
@@ -268,15 +623,44 @@ func (m *mockUserRepo) UpdateDisplayName(_ context.Context, id, displayName stri
 	return nil
 }
 
+func (m *mockUserRepo) UpdateLocale(_ context.Context, id, locale string) error {
+	u, ok := m.users[id]
+	if !ok {
+		return fmt.Errorf("user not found")
+	}
+	u.Locale = locale
+	return nil
+}
+
+func (m *mockUserRepo) UpdateSharePress(_ context.Context, id string, share bool) error {
+	u, ok := m.users[id]
+	if !ok {
+		return fmt.Errorf("user not found")
+	}
+	u.SharePress = share
+	return nil
+}
+
 type mockPhaseRepo struct {
-	phases map[string]*model.Phase
-	orders map[string][]model.Order
+	phases  map[string]*model.Phase
+	orders  map[string][]model.Order
+	evals   map[string][]model.PhaseEvaluation // phaseID -> evaluations
+	reviews map[string][]model.OrderReview     // phaseID -> order reviews
+
+	submissions map[string]map[string]*model.Submission // phaseID -> power -> submission
+
+	openingGames int
+	openings     []model.OpeningTally
 }
 
 func newMockPhaseRepo() *mockPhaseRepo {
 	return &mockPhaseRepo{
-		phases: make(map[string]*model.Phase),
-		orders: make(map[string][]model.Order),
+		phases:  make(map[string]*model.Phase),
+		orders:  make(map[string][]model.Order),
+		evals:   make(map[string][]model.PhaseEvaluation),
+		reviews: make(map[string][]model.OrderReview),
+
+		submissions: make(map[string]map[string]*model.Submission),
 	}
 }
 
@@ -304,6 +688,16 @@ func (m *mockPhaseRepo) CurrentPhase(_ context.Context, gameID string) (*model.P
 	return nil, nil
 }
 
+func (m *mockPhaseRepo) CurrentPhases(ctx context.Context, gameIDs []string) (map[string]model.Phase, error) {
+	result := make(map[string]model.Phase)
+	for _, id := range gameIDs {
+		if p, _ := m.CurrentPhase(ctx, id); p != nil {
+			result[id] = *p
+		}
+	}
+	return result, nil
+}
+
 func (m *mockPhaseRepo) ListPhases(_ context.Context, gameID string) ([]model.Phase, error) {
 	var result []model.Phase
 	for _, p := range m.phases {
@@ -311,6 +705,19 @@ func (m *mockPhaseRepo) ListPhases(_ context.Context, gameID string) ([]model.Ph
 			result = append(result, *p)
 		}
 	}
+	// Chronological, like the Postgres repo.
+	seasonOrder := map[string]int{"spring": 1, "fall": 2}
+	typeOrder := map[string]int{"movement": 1, "retreat": 2, "build": 3}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Year != b.Year {
+			return a.Year < b.Year
+		}
+		if a.Season != b.Season {
+			return seasonOrder[a.Season] < seasonOrder[b.Season]
+		}
+		return typeOrder[a.PhaseType] < typeOrder[b.PhaseType]
+	})
 	return result, nil
 }
 
@@ -320,6 +727,14 @@ func (m *mockPhaseRepo) ResolvePhase(_ context.Context, phaseID string, stateAft
 		now := time.Now()
 		p.ResolvedAt = &now
 	}
+	delete(m.submissions, phaseID)
+	return nil
+}
+
+func (m *mockPhaseRepo) UpdateDeadline(_ context.Context, phaseID string, deadline time.Time) error {
+	if p, ok := m.phases[phaseID]; ok && p.ResolvedAt == nil {
+		p.Deadline = deadline
+	}
 	return nil
 }
 
@@ -334,10 +749,113 @@ func (m *mockPhaseRepo) OrdersByPhase(_ context.Context, phaseID string) ([]mode
 	return m.orders[phaseID], nil
 }
 
+func (m *mockPhaseRepo) OpeningTallies(_ context.Context) (int, []model.OpeningTally, error) {
+	return m.openingGames, m.openings, nil
+}
+
 func (m *mockPhaseRepo) ListExpired(_ context.Context) ([]model.Phase, error) {
 	return nil, nil
 }
 
+func (m *mockPhaseRepo) ListDueBefore(_ context.Context, before time.Time) ([]model.Phase, error) {
+	var result []model.Phase
+	now := time.Now()
+	for _, p := range m.phases {
+		if p.ResolvedAt == nil && p.Deadline.After(now) && !p.Deadline.After(before) {
+			result = append(result, *p)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockPhaseRepo) SaveEvaluations(_ context.Context, evals []model.PhaseEvaluation) error {
+	byPhase := make(map[string][]model.PhaseEvaluation)
+	for _, e := range evals {
+		byPhase[e.PhaseID] = append(byPhase[e.PhaseID], e)
+	}
+	for phaseID, es := range byPhase {
+		m.evals[phaseID] = es
+	}
+	return nil
+}
+
+func (m *mockPhaseRepo) EvaluationsByGame(_ context.Context, gameID string) ([]model.PhaseEvaluation, error) {
+	var result []model.PhaseEvaluation
+	for phaseID, es := range m.evals {
+		if p, ok := m.phases[phaseID]; ok && p.GameID == gameID {
+			result = append(result, es...)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockPhaseRepo) SaveOrderReviews(_ context.Context, reviews []model.OrderReview) error {
+	byPhase := make(map[string][]model.OrderReview)
+	for _, rv := range reviews {
+		byPhase[rv.PhaseID] = append(byPhase[rv.PhaseID], rv)
+	}
+	for phaseID, rvs := range byPhase {
+		m.reviews[phaseID] = rvs
+	}
+	return nil
+}
+
+func (m *mockPhaseRepo) OrderReviewsByGame(_ context.Context, gameID string) ([]model.OrderReview, error) {
+	var result []model.OrderReview
+	for phaseID, rvs := range m.reviews {
+		if p, ok := m.phases[phaseID]; ok && p.GameID == gameID {
+			result = append(result, rvs...)
+		}
+	}
+	return result, nil
+}
+
+// submission returns the power's submission in a phase, creating it, or nil
+// if the phase is unknown or resolved.
+func (m *mockPhaseRepo) submission(phaseID, power string) *model.Submission {
+	if phase := m.phases[phaseID]; phase == nil || phase.ResolvedAt != nil {
+		return nil
+	}
+	if m.submissions[phaseID] == nil {
+		m.submissions[phaseID] = make(map[string]*model.Submission)
+	}
+	sub, ok := m.submissions[phaseID][power]
+	if !ok {
+		sub = &model.Submission{PhaseID: phaseID, Power: power}
+		m.submissions[phaseID][power] = sub
+	}
+	return sub
+}
+
+func (m *mockPhaseRepo) SaveSubmittedOrders(_ context.Context, phaseID, power string, orders json.RawMessage) error {
+	if sub := m.submission(phaseID, power); sub != nil {
+		sub.Orders = orders
+	}
+	return nil
+}
+
+func (m *mockPhaseRepo) SetSubmittedReady(_ context.Context, phaseID, power string, ready bool) error {
+	if sub := m.submission(phaseID, power); sub != nil {
+		sub.Ready = ready
+	}
+	return nil
+}
+
+func (m *mockPhaseRepo) ClearSubmission(_ context.Context, gameID, power string) error {
+	if phase, _ := m.CurrentPhase(context.Background(), gameID); phase != nil {
+		delete(m.submissions[phase.ID], power)
+	}
+	return nil
+}
+
+func (m *mockPhaseRepo) Submissions(_ context.Context, phaseID string) ([]model.Submission, error) {
+	var result []model.Submission
+	for _, sub := range m.submissions[phaseID] {
+		result = append(result, *sub)
+	}
+	return result, nil
+}
+
 // mockCache implements repository.GameCache for testing.
 type mockCache struct {
 	states    map[string]json.RawMessage
@@ -345,6 +863,8 @@ type mockCache struct {
 	ready     map[string]map[string]bool // gameID -> set of powers
 	timers    map[string]time.Time
 	drawVotes map[string]map[string]bool // gameID -> set of powers
+	paused    map[string]time.Duration   // gameID -> time left when paused
+	quorum    map[string]time.Time       // gameID -> deadline a ready quorum pulled forward
 }
 
 func newMockCache() *mockCache {
@@ -354,6 +874,8 @@ func newMockCache() *mockCache {
 		ready:     make(map[string]map[string]bool),
 		timers:    make(map[string]time.Time),
 		drawVotes: make(map[string]map[string]bool),
+		paused:    make(map[string]time.Duration),
+		quorum:    make(map[string]time.Time),
 	}
 }
 
@@ -375,6 +897,15 @@ func (c *mockCache) GetOrders(_ context.Context, gameID, power string) (json.Raw
 	return c.orders[gameID+":"+power], nil
 }
 
+func (c *mockCache) UpdateOrders(_ context.Context, gameID, power string, update func(orders json.RawMessage) (json.RawMessage, error)) error {
+	next, err := update(c.orders[gameID+":"+power])
+	if err != nil {
+		return err
+	}
+	c.orders[gameID+":"+power] = next
+	return nil
+}
+
 func (c *mockCache) GetAllOrders(_ context.Context, gameID string, powers []string) (map[string]json.RawMessage, error) {
 	result := make(map[string]json.RawMessage)
 	for _, power := range powers {
@@ -422,17 +953,48 @@ func (c *mockCache) ClearTimer(_ context.Context, gameID string) error {
 	return nil
 }
 
-func (c *mockCache) AddDrawVote(_ context.Context, gameID, power string) error {
-	if c.drawVotes[gameID] == nil {
-		c.drawVotes[gameID] = make(map[string]bool)
+func (c *mockCache) PauseTimer(_ context.Context, gameID string) (time.Duration, bool, error) {
+	deadline, ok := c.timers[gameID]
+	if !ok {
+		return 0, false, nil
 	}
-	c.drawVotes[gameID][power] = true
-	return nil
+	remaining := max(time.Until(deadline), 0)
+	c.paused[gameID] = remaining
+	delete(c.timers, gameID)
+	return remaining, true, nil
 }
 
-func (c *mockCache) RemoveDrawVote(_ context.Context, gameID, power string) error {
-	if c.drawVotes[gameID] != nil {
-		delete(c.drawVotes[gameID], power)
+func (c *mockCache) ResumeTimer(_ context.Context, gameID string) (time.Duration, bool, error) {
+	remaining, ok := c.paused[gameID]
+	delete(c.paused, gameID)
+	return remaining, ok, nil
+}
+
+func (c *mockCache) SetQuorumDeadline(_ context.Context, gameID string, original time.Time) (bool, error) {
+	if _, ok := c.quorum[gameID]; ok {
+		return false, nil
+	}
+	c.quorum[gameID] = original
+	return true, nil
+}
+
+func (c *mockCache) ClearQuorumDeadline(_ context.Context, gameID string) (time.Time, bool, error) {
+	original, ok := c.quorum[gameID]
+	delete(c.quorum, gameID)
+	return original, ok, nil
+}
+
+func (c *mockCache) AddDrawVote(_ context.Context, gameID, power string) error {
+	if c.drawVotes[gameID] == nil {
+		c.drawVotes[gameID] = make(map[string]bool)
+	}
+	c.drawVotes[gameID][power] = true
+	return nil
+}
+
+func (c *mockCache) RemoveDrawVote(_ context.Context, gameID, power string) error {
+	if c.drawVotes[gameID] != nil {
+		delete(c.drawVotes[gameID], power)
 	}
 	return nil
 }
@@ -453,19 +1015,944 @@ func (c *mockCache) ClearPhaseData(_ context.Context, gameID string, powers []st
 	delete(c.ready, gameID)
 	delete(c.timers, gameID)
 	delete(c.drawVotes, gameID)
+	delete(c.quorum, gameID)
 	for _, power := range powers {
 		delete(c.orders, gameID+":"+power)
 	}
 	return nil
 }
 
+func (c *mockCache) ClearPowerData(_ context.Context, gameID, power string) error {
+	delete(c.orders, gameID+":"+power)
+	delete(c.ready[gameID], power)
+	delete(c.drawVotes[gameID], power)
+	return nil
+}
+
 func (c *mockCache) DeleteGameData(_ context.Context, gameID string, powers []string) error {
 	delete(c.states, gameID)
 	delete(c.ready, gameID)
 	delete(c.timers, gameID)
 	delete(c.drawVotes, gameID)
+	delete(c.paused, gameID)
+	delete(c.quorum, gameID)
 	for _, power := range powers {
 		delete(c.orders, gameID+":"+power)
 	}
 	return nil
 }
+
+// --- Mock MessageRepository ---
+
+type mockMessageRepo struct {
+	messages []model.Message
+	readAt   map[string]time.Time // gameID/userID -> last read
+	channels *mockChannelRepo     // optional: channel membership for visibility
+}
+
+// visible reports whether userID can see msg, like the Postgres visibility rule.
+func (m *mockMessageRepo) visible(msg model.Message, userID string) bool {
+	switch {
+	case msg.SenderID == userID || msg.RecipientID == userID:
+		return true
+	case msg.ChannelID != "":
+		return m.channels != nil && slices.Contains(m.channels.channels[msg.ChannelID].Members, userID)
+	default:
+		return msg.RecipientID == ""
+	}
+}
+
+func newMockMessageRepo() *mockMessageRepo {
+	return &mockMessageRepo{}
+}
+
+func (m *mockMessageRepo) Create(_ context.Context, gameID, senderID, recipientID, content, phaseID string, intent *model.MessageIntent) (*model.Message, error) {
+	msg := &model.Message{
+		ID:          fmt.Sprintf("msg-%d", len(m.messages)+1),
+		GameID:      gameID,
+		SenderID:    senderID,
+		RecipientID: recipientID,
+		Content:     content,
+		PhaseID:     phaseID,
+		Intent:      intent,
+		CreatedAt:   time.Now(),
+	}
+	m.messages = append(m.messages, *msg)
+	return msg, nil
+}
+
+func (m *mockMessageRepo) CreateInChannel(ctx context.Context, gameID, senderID, channelID, content, phaseID string, intent *model.MessageIntent) (*model.Message, error) {
+	msg, _ := m.Create(ctx, gameID, senderID, "", content, phaseID, intent)
+	msg.ChannelID = channelID
+	m.messages[len(m.messages)-1].ChannelID = channelID
+	return msg, nil
+}
+
+func (m *mockMessageRepo) ListByGame(_ context.Context, gameID, userID string) ([]model.Message, error) {
+	var result []model.Message
+	for _, msg := range m.messages {
+		if msg.GameID == gameID && m.visible(msg, userID) {
+			result = append(result, msg)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockMessageRepo) ListAllByGame(_ context.Context, gameID string) ([]model.Message, error) {
+	var result []model.Message
+	for _, msg := range m.messages {
+		if msg.GameID == gameID {
+			result = append(result, msg)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockMessageRepo) FindByID(_ context.Context, id string) (*model.Message, error) {
+	for _, msg := range m.messages {
+		if msg.ID == id {
+			return &msg, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockMessageRepo) MarkRead(_ context.Context, gameID, userID string) error {
+	if m.readAt == nil {
+		m.readAt = make(map[string]time.Time)
+	}
+	m.readAt[gameID+"/"+userID] = time.Now()
+	return nil
+}
+
+func (m *mockMessageRepo) CountUnread(_ context.Context, gameID, userID string) (int, error) {
+	readAt := m.readAt[gameID+"/"+userID]
+	n := 0
+	for _, msg := range m.messages {
+		if msg.GameID == gameID && msg.SenderID != userID && m.visible(msg, userID) &&
+			msg.CreatedAt.After(readAt) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *mockMessageRepo) CountUnreadByGame(ctx context.Context, gameIDs []string, userID string) (map[string]int, error) {
+	result := make(map[string]int)
+	for _, id := range gameIDs {
+		if n, _ := m.CountUnread(ctx, id, userID); n > 0 {
+			result[id] = n
+		}
+	}
+	return result, nil
+}
+
+// --- Mock AchievementRepository ---
+
+type mockAchievementRepo struct {
+	achievements []model.Achievement
+	records      map[string]model.GameRecord
+}
+
+func newMockAchievementRepo() *mockAchievementRepo {
+	return &mockAchievementRepo{records: make(map[string]model.GameRecord)}
+}
+
+func (m *mockAchievementRepo) Award(_ context.Context, a model.Achievement) error {
+	for _, existing := range m.achievements {
+		if existing.UserID == a.UserID && existing.Kind == a.Kind && existing.GameID == a.GameID {
+			return nil
+		}
+	}
+	a.AwardedAt = time.Now()
+	m.achievements = append(m.achievements, a)
+	return nil
+}
+
+func (m *mockAchievementRepo) ListByUser(_ context.Context, userID string) ([]model.Achievement, error) {
+	var result []model.Achievement
+	for _, a := range m.achievements {
+		if a.UserID == userID {
+			result = append(result, a)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockAchievementRepo) SaveRecord(_ context.Context, rec model.GameRecord) error {
+	if _, ok := m.records[rec.GameID]; !ok {
+		m.records[rec.GameID] = rec
+	}
+	return nil
+}
+
+func (m *mockAchievementRepo) FastestSolos(_ context.Context, limit int) ([]model.GameRecord, error) {
+	var result []model.GameRecord
+	for _, rec := range m.records {
+		result = append(result, rec)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Year != result[j].Year {
+			return result[i].Year < result[j].Year
+		}
+		return result[i].SupplyCenters > result[j].SupplyCenters
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (m *mockAchievementRepo) TopAchievers(_ context.Context, limit int) ([]model.AchieverCount, error) {
+	counts := make(map[string]int)
+	for _, a := range m.achievements {
+		counts[a.UserID]++
+	}
+	var result []model.AchieverCount
+	for userID, n := range counts {
+		result = append(result, model.AchieverCount{UserID: userID, Achievements: n})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Achievements != result[j].Achievements {
+			return result[i].Achievements > result[j].Achievements
+		}
+		return result[i].UserID < result[j].UserID
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// --- Mock APIKeyRepository ---
+
+type mockAPIKeyRepo struct {
+	keys   []*model.APIKey
+	hashes map[string]string // key ID -> hash
+}
+
+func newMockAPIKeyRepo() *mockAPIKeyRepo {
+	return &mockAPIKeyRepo{hashes: make(map[string]string)}
+}
+
+func (m *mockAPIKeyRepo) Create(_ context.Context, userID, name, prefix, keyHash string) (*model.APIKey, error) {
+	k := &model.APIKey{ID: fmt.Sprintf("key-%d", len(m.keys)+1), UserID: userID, Name: name, Prefix: prefix, CreatedAt: time.Now()}
+	m.keys = append(m.keys, k)
+	m.hashes[k.ID] = keyHash
+	cp := *k
+	return &cp, nil
+}
+
+func (m *mockAPIKeyRepo) FindByHash(_ context.Context, keyHash string) (*model.APIKey, error) {
+	for _, k := range m.keys {
+		if m.hashes[k.ID] == keyHash {
+			cp := *k
+			return &cp, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockAPIKeyRepo) ListByUser(_ context.Context, userID string) ([]model.APIKey, error) {
+	var result []model.APIKey
+	for _, k := range m.keys {
+		if k.UserID == userID {
+			result = append(result, *k)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockAPIKeyRepo) Revoke(_ context.Context, id, userID string) (bool, error) {
+	for _, k := range m.keys {
+		if k.ID == id && k.UserID == userID && k.RevokedAt == nil {
+			now := time.Now()
+			k.RevokedAt = &now
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *mockAPIKeyRepo) Touch(_ context.Context, id string) error {
+	for _, k := range m.keys {
+		if k.ID == id {
+			now := time.Now()
+			k.LastUsedAt = &now
+		}
+	}
+	return nil
+}
+
+// --- Mock PreferenceRepository ---
+
+type mockPreferenceRepo struct {
+	prefs map[string]map[string]json.RawMessage // user ID -> namespace -> value
+}
+
+func newMockPreferenceRepo() *mockPreferenceRepo {
+	return &mockPreferenceRepo{prefs: make(map[string]map[string]json.RawMessage)}
+}
+
+func (m *mockPreferenceRepo) Get(_ context.Context, userID string) (map[string]json.RawMessage, error) {
+	result := make(map[string]json.RawMessage, len(m.prefs[userID]))
+	for ns, v := range m.prefs[userID] {
+		result[ns] = v
+	}
+	return result, nil
+}
+
+func (m *mockPreferenceRepo) Set(_ context.Context, userID string, values map[string]json.RawMessage) error {
+	if m.prefs[userID] == nil {
+		m.prefs[userID] = make(map[string]json.RawMessage)
+	}
+	for ns, v := range values {
+		if v == nil {
+			delete(m.prefs[userID], ns)
+		} else {
+			m.prefs[userID][ns] = v
+		}
+	}
+	return nil
+}
+
+// --- Mock RatingRepository ---
+
+type mockRatingRepo struct {
+	ratings  map[[2]string]*model.Rating
+	rated    map[string]bool
+	finished []string // finished game IDs in the order they finished
+}
+
+func newMockRatingRepo() *mockRatingRepo {
+	return &mockRatingRepo{ratings: make(map[[2]string]*model.Rating), rated: make(map[string]bool)}
+}
+
+func (m *mockRatingRepo) Find(_ context.Context, subjectType, subjectID string) (*model.Rating, error) {
+	r, ok := m.ratings[[2]string{subjectType, subjectID}]
+	if !ok {
+		return nil, nil
+	}
+	cp := *r
+	return &cp, nil
+}
+
+func (m *mockRatingRepo) ApplyGame(_ context.Context, gameID string, updates []model.RatingUpdate) (bool, error) {
+	if m.rated[gameID] {
+		return false, nil
+	}
+	m.rated[gameID] = true
+	for _, u := range updates {
+		key := [2]string{u.SubjectType, u.SubjectID}
+		r, ok := m.ratings[key]
+		if !ok {
+			r = &model.Rating{SubjectType: u.SubjectType, SubjectID: u.SubjectID, Rating: u.Initial}
+			m.ratings[key] = r
+		}
+		r.Rating += u.Delta
+		r.Games++
+		if u.Won {
+			r.Wins++
+		}
+		r.UpdatedAt = time.Now()
+	}
+	return true, nil
+}
+
+func (m *mockRatingRepo) Top(_ context.Context, subjectType string, limit int) ([]model.Rating, error) {
+	var result []model.Rating
+	for _, r := range m.ratings {
+		if r.SubjectType == subjectType {
+			result = append(result, *r)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Rating > result[j].Rating })
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (m *mockRatingRepo) CountRated(_ context.Context) (int, error) {
+	return len(m.rated), nil
+}
+
+func (m *mockRatingRepo) Reset(_ context.Context) error {
+	clear(m.ratings)
+	clear(m.rated)
+	return nil
+}
+
+func (m *mockRatingRepo) ListUnrated(_ context.Context) ([]string, error) {
+	var ids []string
+	for _, id := range m.finished {
+		if !m.rated[id] {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// --- Mock ChannelRepository ---
+
+type mockChannelRepo struct {
+	channels map[string]*model.Channel
+	order    []string
+}
+
+func newMockChannelRepo() *mockChannelRepo {
+	return &mockChannelRepo{channels: make(map[string]*model.Channel)}
+}
+
+func (m *mockChannelRepo) Create(_ context.Context, gameID, name, createdBy string, members []string) (*model.Channel, error) {
+	c := &model.Channel{
+		ID:        fmt.Sprintf("channel-%d", len(m.order)+1),
+		GameID:    gameID,
+		Name:      name,
+		CreatedBy: createdBy,
+		Members:   slices.Clone(members),
+		CreatedAt: time.Now(),
+	}
+	m.channels[c.ID] = c
+	m.order = append(m.order, c.ID)
+	cp := *c
+	cp.Members = slices.Clone(c.Members)
+	return &cp, nil
+}
+
+func (m *mockChannelRepo) FindByID(_ context.Context, id string) (*model.Channel, error) {
+	c, ok := m.channels[id]
+	if !ok {
+		return nil, nil
+	}
+	cp := *c
+	cp.Members = slices.Clone(c.Members)
+	return &cp, nil
+}
+
+func (m *mockChannelRepo) ListByMember(_ context.Context, gameID, userID string) ([]model.Channel, error) {
+	var result []model.Channel
+	for _, id := range m.order {
+		if c := m.channels[id]; c.GameID == gameID && slices.Contains(c.Members, userID) {
+			result = append(result, *c)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockChannelRepo) ListByGame(_ context.Context, gameID string) ([]model.Channel, error) {
+	var result []model.Channel
+	for _, id := range m.order {
+		if c := m.channels[id]; c.GameID == gameID {
+			result = append(result, *c)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockChannelRepo) AddMember(_ context.Context, channelID, userID string) error {
+	if c, ok := m.channels[channelID]; ok && !slices.Contains(c.Members, userID) {
+		c.Members = append(c.Members, userID)
+	}
+	return nil
+}
+
+func (m *mockChannelRepo) RemoveMember(_ context.Context, channelID, userID string) error {
+	if c, ok := m.channels[channelID]; ok {
+		c.Members = slices.DeleteFunc(c.Members, func(id string) bool { return id == userID })
+	}
+	return nil
+}
+
+// --- Mock ComputeRepository ---
+
+type mockComputeRepo struct {
+	records []model.BotCompute
+}
+
+func (m *mockComputeRepo) Record(_ context.Context, records []model.BotCompute) error {
+	for _, rec := range records {
+		rec.CreatedAt = time.Now()
+		m.records = append(m.records, rec)
+	}
+	return nil
+}
+
+func (m *mockComputeRepo) GameTotal(_ context.Context, gameID string) (int64, error) {
+	var total int64
+	for _, rec := range m.records {
+		if rec.GameID == gameID {
+			total += rec.ComputeMS
+		}
+	}
+	return total, nil
+}
+
+func (m *mockComputeRepo) ListByGame(_ context.Context, gameID string) ([]model.BotCompute, error) {
+	var result []model.BotCompute
+	for _, rec := range m.records {
+		if rec.GameID == gameID {
+			result = append(result, rec)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockComputeRepo) TopGames(_ context.Context, since time.Time, limit int) ([]model.GameCompute, error) {
+	byGame := make(map[string]*model.GameCompute)
+	for _, rec := range m.records {
+		if rec.CreatedAt.Before(since) {
+			continue
+		}
+		gc, ok := byGame[rec.GameID]
+		if !ok {
+			gc = &model.GameCompute{GameID: rec.GameID}
+			byGame[rec.GameID] = gc
+		}
+		gc.ComputeMS += rec.ComputeMS
+		gc.Phases++
+	}
+	var result []model.GameCompute
+	for _, gc := range byGame {
+		result = append(result, *gc)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ComputeMS > result[j].ComputeMS })
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// --- Mock ViewerStore ---
+
+type mockViewerStore struct {
+	viewers   map[string]map[string]int // gameID -> instance -> viewers
+	heartbeat map[string]time.Time
+	activity  map[string]time.Time
+}
+
+func newMockViewerStore() *mockViewerStore {
+	return &mockViewerStore{
+		viewers:   make(map[string]map[string]int),
+		heartbeat: make(map[string]time.Time),
+		activity:  make(map[string]time.Time),
+	}
+}
+
+func (m *mockViewerStore) ReportViewers(_ context.Context, instanceID string, counts map[string]int, activity map[string]time.Time) error {
+	for gameID, n := range counts {
+		if m.viewers[gameID] == nil {
+			m.viewers[gameID] = make(map[string]int)
+		}
+		m.viewers[gameID][instanceID] = n
+	}
+	for gameID, t := range activity {
+		if t.After(m.activity[gameID]) {
+			m.activity[gameID] = t
+		}
+	}
+	m.heartbeat[instanceID] = time.Now()
+	return nil
+}
+
+func (m *mockViewerStore) LiveViewers(_ context.Context, since time.Time) (map[string]int, error) {
+	result := make(map[string]int)
+	for gameID, byInstance := range m.viewers {
+		for instance, n := range byInstance {
+			if n > 0 && !m.heartbeat[instance].Before(since) {
+				result[gameID] += n
+			}
+		}
+	}
+	return result, nil
+}
+
+func (m *mockViewerStore) RecentActivity(_ context.Context, since time.Time) (map[string]time.Time, error) {
+	result := make(map[string]time.Time)
+	for gameID, t := range m.activity {
+		if !t.Before(since) {
+			result[gameID] = t
+		}
+	}
+	return result, nil
+}
+
+// --- Mock LeaseStore ---
+
+type mockLeaseStore struct {
+	mu     sync.Mutex
+	owners map[string]string // key -> owner
+}
+
+func newMockLeaseStore() *mockLeaseStore {
+	return &mockLeaseStore{owners: make(map[string]string)}
+}
+
+func (m *mockLeaseStore) AcquireLease(_ context.Context, key, owner string, _ time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, held := m.owners[key]; held {
+		return false, nil
+	}
+	m.owners[key] = owner
+	return true, nil
+}
+
+func (m *mockLeaseStore) RenewLease(_ context.Context, key, owner string, _ time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.owners[key] == owner, nil
+}
+
+func (m *mockLeaseStore) ReleaseLease(_ context.Context, key, owner string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.owners[key] == owner {
+		delete(m.owners, key)
+	}
+	return nil
+}
+
+func (m *mockLeaseStore) owner(key string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.owners[key]
+}
+
+// --- Mock VariantRepository ---
+
+type mockVariantRepo struct {
+	variants map[string]*model.Variant
+	versions map[string]*model.Variant // keyed by "name@version"
+}
+
+func newMockVariantRepo() *mockVariantRepo {
+	return &mockVariantRepo{variants: make(map[string]*model.Variant), versions: make(map[string]*model.Variant)}
+}
+
+func (m *mockVariantRepo) Save(_ context.Context, v *model.Variant) error {
+	now := time.Now()
+	v.Version = 1
+	if old, ok := m.variants[v.Name]; ok {
+		v.CreatedBy, v.CreatedAt, v.Version = old.CreatedBy, old.CreatedAt, old.Version+1
+	} else {
+		v.CreatedAt = now
+	}
+	v.UpdatedAt = now
+	cp := *v
+	m.variants[v.Name] = &cp
+	version := cp
+	m.versions[fmt.Sprintf("%s@%d", v.Name, v.Version)] = &version
+	return nil
+}
+
+func (m *mockVariantRepo) FindByName(_ context.Context, name string) (*model.Variant, error) {
+	v, ok := m.variants[name]
+	if !ok {
+		return nil, nil
+	}
+	cp := *v
+	return &cp, nil
+}
+
+func (m *mockVariantRepo) FindVersion(_ context.Context, name string, version int) (*model.Variant, error) {
+	v, ok := m.versions[fmt.Sprintf("%s@%d", name, version)]
+	if !ok {
+		return nil, nil
+	}
+	cp := *v
+	return &cp, nil
+}
+
+func (m *mockVariantRepo) List(_ context.Context) ([]model.Variant, error) {
+	var result []model.Variant
+	for _, v := range m.variants {
+		cp := *v
+		cp.Definition = nil
+		result = append(result, cp)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+func (m *mockVariantRepo) ListPlayable(_ context.Context) ([]model.Variant, error) {
+	var result []model.Variant
+	for _, v := range m.variants {
+		if v.Playable {
+			result = append(result, *v)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+// --- Mock SummaryRepository ---
+
+type mockSummaryRepo struct {
+	summaries map[string]*model.GameSummary
+	saves     int
+}
+
+func newMockSummaryRepo() *mockSummaryRepo {
+	return &mockSummaryRepo{summaries: make(map[string]*model.GameSummary)}
+}
+
+func (m *mockSummaryRepo) Save(_ context.Context, s *model.GameSummary) error {
+	s.CreatedAt = time.Now()
+	m.summaries[s.GameID] = s
+	m.saves++
+	return nil
+}
+
+func (m *mockSummaryRepo) FindByGame(_ context.Context, gameID string) (*model.GameSummary, error) {
+	return m.summaries[gameID], nil
+}
+
+// mockNotificationRepo is guarded by a mutex since deliveries run in
+// goroutines without a job queue.
+type mockNotificationRepo struct {
+	mu            sync.Mutex
+	channels      map[string]*model.NotificationChannel
+	notifications map[string]*model.Notification
+	reminders     map[string]bool      // phaseID:event
+	confirmTokens map[string]string    // channelID -> token hash, while unconfirmed
+	confirmSent   map[string]time.Time // channelID -> when its token was set
+	seq           int
+}
+
+func newMockNotificationRepo() *mockNotificationRepo {
+	return &mockNotificationRepo{
+		channels:      make(map[string]*model.NotificationChannel),
+		notifications: make(map[string]*model.Notification),
+		reminders:     make(map[string]bool),
+		confirmTokens: make(map[string]string),
+		confirmSent:   make(map[string]time.Time),
+	}
+}
+
+func (m *mockNotificationRepo) CreateChannel(_ context.Context, userID, kind, target string, events []string, confirmTokenHash string) (*model.NotificationChannel, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.seq++
+	ch := &model.NotificationChannel{ID: fmt.Sprintf("channel-%d", m.seq), UserID: userID, Kind: kind, Target: target, Events: events,
+		Enabled: true, Confirmed: confirmTokenHash == "", CreatedAt: time.Now()}
+	m.channels[ch.ID] = ch
+	if confirmTokenHash != "" {
+		m.confirmTokens[ch.ID], m.confirmSent[ch.ID] = confirmTokenHash, time.Now()
+	}
+	c := *ch
+	return &c, nil
+}
+
+func (m *mockNotificationRepo) ListChannels(_ context.Context, userID string) ([]model.NotificationChannel, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []model.NotificationChannel
+	for _, ch := range m.channels {
+		if ch.UserID == userID {
+			result = append(result, *ch)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockNotificationRepo) FindChannel(_ context.Context, id string) (*model.NotificationChannel, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if ch, ok := m.channels[id]; ok {
+		c := *ch
+		return &c, nil
+	}
+	return nil, nil
+}
+
+func (m *mockNotificationRepo) UpdateChannel(_ context.Context, id, userID string, events []string, enabled bool) (*model.NotificationChannel, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ch, ok := m.channels[id]
+	if !ok || ch.UserID != userID {
+		return nil, nil
+	}
+	ch.Events, ch.Enabled = events, enabled
+	c := *ch
+	return &c, nil
+}
+
+func (m *mockNotificationRepo) DeleteChannel(_ context.Context, id, userID string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ch, ok := m.channels[id]
+	if !ok || ch.UserID != userID {
+		return false, nil
+	}
+	delete(m.channels, id)
+	return true, nil
+}
+
+func (m *mockNotificationRepo) SetConfirmToken(_ context.Context, id, userID, tokenHash string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ch, ok := m.channels[id]
+	if !ok || ch.UserID != userID || ch.Confirmed {
+		return false, nil
+	}
+	m.confirmTokens[id], m.confirmSent[id] = tokenHash, time.Now()
+	return true, nil
+}
+
+func (m *mockNotificationRepo) ConfirmChannel(_ context.Context, tokenHash string, sentAfter time.Time) (*model.NotificationChannel, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, hash := range m.confirmTokens {
+		if hash == tokenHash && m.confirmSent[id].After(sentAfter) {
+			ch := m.channels[id]
+			ch.Confirmed = true
+			delete(m.confirmTokens, id)
+			delete(m.confirmSent, id)
+			c := *ch
+			return &c, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockNotificationRepo) SubscribedChannels(_ context.Context, userIDs []string, event string) ([]model.NotificationChannel, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []model.NotificationChannel
+	for _, ch := range m.channels {
+		if ch.Enabled && ch.Confirmed && slices.Contains(userIDs, ch.UserID) && slices.Contains(ch.Events, event) {
+			result = append(result, *ch)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockNotificationRepo) ClaimReminder(_ context.Context, phaseID, event string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := phaseID + ":" + event
+	if m.reminders[key] {
+		return false, nil
+	}
+	m.reminders[key] = true
+	return true, nil
+}
+
+func (m *mockNotificationRepo) CreateNotifications(_ context.Context, notifications []model.Notification) ([]model.Notification, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	created := make([]model.Notification, 0, len(notifications))
+	for _, n := range notifications {
+		m.seq++
+		n.ID = fmt.Sprintf("notification-%d", m.seq)
+		n.Status = model.NotificationPending
+		n.CreatedAt = time.Now()
+		m.notifications[n.ID] = &n
+		created = append(created, n)
+	}
+	return created, nil
+}
+
+func (m *mockNotificationRepo) FindNotification(_ context.Context, id string) (*model.Notification, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if n, ok := m.notifications[id]; ok {
+		c := *n
+		return &c, nil
+	}
+	return nil, nil
+}
+
+func (m *mockNotificationRepo) RecordAttempt(_ context.Context, id, status, lastError string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if n, ok := m.notifications[id]; ok {
+		n.Status, n.LastError = status, lastError
+		n.Attempts++
+		if status == model.NotificationSent {
+			now := time.Now()
+			n.SentAt = &now
+		}
+	}
+	return nil
+}
+
+func (m *mockNotificationRepo) ListNotifications(_ context.Context, userID string, limit int) ([]model.Notification, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []model.Notification
+	for _, n := range m.notifications {
+		if n.UserID == userID {
+			result = append(result, *n)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.After(result[j].CreatedAt) })
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// recordingBroadcaster captures broadcast events for assertions.
+type recordingBroadcaster struct {
+	mu     sync.Mutex
+	events []recordedEvent
+}
+
+type recordedEvent struct {
+	gameID    string
+	userID    string // set for user-targeted events
+	lobby     bool   // sent to the lobby topic
+	eventType string
+	data      any
+}
+
+func (b *recordingBroadcaster) BroadcastGameEvent(gameID, eventType string, data any) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.events = append(b.events, recordedEvent{gameID: gameID, eventType: eventType, data: data})
+}
+
+func (b *recordingBroadcaster) BroadcastUserEvent(gameID, userID, eventType string, data any) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.events = append(b.events, recordedEvent{gameID: gameID, userID: userID, eventType: eventType, data: data})
+}
+
+func (b *recordingBroadcaster) BroadcastLobbyEvent(gameID, eventType string, data any) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.events = append(b.events, recordedEvent{gameID: gameID, lobby: true, eventType: eventType, data: data})
+}
+
+// eventsOfType returns the recorded events with the given type.
+func (b *recordingBroadcaster) eventsOfType(eventType string) []recordedEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var out []recordedEvent
+	for _, e := range b.events {
+		if e.eventType == eventType {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// mockEvaluationCache is an in-memory EvaluationCache.
+type mockEvaluationCache struct {
+	data map[string][]byte // phaseID:stage -> evaluations
+}
+
+func newMockEvaluationCache() *mockEvaluationCache {
+	return &mockEvaluationCache{data: make(map[string][]byte)}
+}
+
+func (c *mockEvaluationCache) PhaseEvaluations(_ context.Context, phaseID, stage string) ([]byte, error) {
+	return c.data[phaseID+":"+stage], nil
+}
+
+func (c *mockEvaluationCache) SetPhaseEvaluations(_ context.Context, phaseID, stage string, data []byte) error {
+	c.data[phaseID+":"+stage] = data
+	return nil
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/smtp"
+	"net/url"
+	"syscall"
+	"time"
+
+	"github.com/freeeve/polite-betrayal/api/internal/jobs"
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+)
+
+// errNonPublicAddress is returned when a webhook resolves to an address
+// inside a private network.
+var errNonPublicAddress = errors.New("refusing to connect to a non-public address")
+
+// cgnatPrefix is the carrier-grade NAT range, private in practice.
+var cgnatPrefix = netip.MustParsePrefix("100.64.0.0/10")
+
+// notificationHTTPClient returns the client webhooks are sent with. It only
+// connects to public addresses, so users can't point a webhook at the
+// server's own network, and doesn't follow redirects.
+func notificationHTTPClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip, err := netip.ParseAddr(host)
+			if err != nil {
+				return err
+			}
+			if ip = ip.Unmap(); !ip.IsGlobalUnicast() || ip.IsPrivate() || cgnatPrefix.Contains(ip) {
+				return fmt.Errorf("%w %s", errNonPublicAddress, ip)
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   15 * time.Second,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// postJSON posts body as JSON to target. Responses that retrying won't
+// change, such as a 404 for a deleted webhook, are permanent errors.
+func postJSON(ctx context.Context, client *http.Client, target string, body any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return jobs.Permanent(err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(data))
+	if err != nil {
+		return jobs.Permanent(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		if errors.Is(err, errNonPublicAddress) {
+			return jobs.Permanent(err)
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	default:
+		return jobs.Permanent(fmt.Errorf("webhook returned %s", resp.Status))
+	}
+}
+
+// webhookSender posts the notification as JSON.
+type webhookSender struct {
+	client *http.Client
+}
+
+func (s *webhookSender) Send(ctx context.Context, target string, n *model.Notification) error {
+	return postJSON(ctx, s.client, target, map[string]any{
+		"id":         n.ID,
+		"event":      n.Event,
+		"game_id":    n.GameID,
+		"phase_id":   n.PhaseID,
+		"message":    n.Message,
+		"created_at": n.CreatedAt,
+	})
+}
+
+// discordSender posts the notification's message to a Discord webhook.
+type discordSender struct {
+	client *http.Client
+}
+
+func (s *discordSender) Send(ctx context.Context, target string, n *model.Notification) error {
+	return postJSON(ctx, s.client, target, map[string]any{
+		"content":          n.Message,
+		"allowed_mentions": map[string]any{"parse": []string{}},
+	})
+}
+
+// notificationSubjects are the email subjects of each event.
+var notificationSubjects = map[string]string{
+	model.NotifyDeadline:      "Deadline approaching",
+	model.NotifyPhaseResolved: "Phase resolved",
+	model.NotifyOrdersMissing: "Orders missing",
+}
+
+// smtpSender emails notifications through an SMTP server.
+type smtpSender struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+// NewSMTPSender returns a sender that emails notifications from the given
+// address through the server at an smtp://[user:password@]host[:port] URL.
+// The port defaults to 587.
+func NewSMTPSender(rawURL, from string) (NotificationSender, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "smtp" || u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid SMTP URL %q", rawURL)
+	}
+	if from == "" {
+		return nil, errors.New("an SMTP from address is required")
+	}
+	port := u.Port()
+	if port == "" {
+		port = "587"
+	}
+	s := &smtpSender{addr: net.JoinHostPort(u.Hostname(), port), from: from}
+	if u.User != nil {
+		password, _ := u.User.Password()
+		s.auth = smtp.PlainAuth("", u.User.Username(), password, u.Hostname())
+	}
+	return s, nil
+}
+
+func (s *smtpSender) Send(_ context.Context, target string, n *model.Notification) error {
+	return s.mail(target, notificationSubjects[n.Event], n.Message)
+}
+
+// SendConfirmation implements ConfirmationSender.
+func (s *smtpSender) SendConfirmation(_ context.Context, target, link string) error {
+	return s.mail(target, "Confirm your email notifications",
+		"Someone asked for Polite Betrayal game notifications to be sent to this address.\r\n"+
+			"If it was you, confirm by opening:\r\n\r\n"+link+"\r\n\r\n"+
+			"Otherwise ignore this email; nothing more will be sent.")
+}
+
+// mail sends a plain text email to target.
+func (s *smtpSender) mail(target, subject, body string) error {
+	msg := "From: " + s.from + "\r\n" +
+		"To: " + target + "\r\n" +
+		"Subject: Polite Betrayal: " + subject + "\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n" +
+		"\r\n" + body + "\r\n"
+	return smtp.SendMail(s.addr, s.auth, s.from, []string{target}, []byte(msg))
+}
@@ -0,0 +1,565 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/freeeve/polite-betrayal/api/internal/jobs"
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/internal/repository"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// Notification errors.
+var (
+	ErrNotificationKind            = errors.New("notification channel kind must be webhook, discord or email")
+	ErrNotificationTarget          = errors.New("invalid notification target: webhooks need an https URL, discord a Discord webhook URL and email an address")
+	ErrNotificationEvent           = errors.New("notification events must be deadline, phase_resolved, orders_missing or eliminated")
+	ErrNotificationDisabled        = errors.New("this kind of notification is not available on this server")
+	ErrTooManyChannels             = errors.New("too many notification channels")
+	ErrNotificationChannelNotFound = errors.New("notification channel not found")
+	ErrChannelConfirmed            = errors.New("notification channel is already confirmed")
+	ErrConfirmationInvalid         = errors.New("confirmation link is invalid or has expired")
+)
+
+// Notification limits and timings.
+const (
+	MaxNotificationChannels = 5 // per user
+
+	DeadlineReminderLead = time.Hour        // deadline reminders go out this long before
+	OrdersReminderLead   = 15 * time.Minute // missing-order reminders go out this long before
+
+	ChannelConfirmTTL = 24 * time.Hour // how long a confirmation link works
+)
+
+// notificationEvents are the events a channel can subscribe to, in order.
+var notificationEvents = []string{model.NotifyDeadline, model.NotifyPhaseResolved, model.NotifyOrdersMissing, model.NotifyEliminated}
+
+// JobSendNotification is the job kind that delivers one notification.
+const JobSendNotification = "send_notification"
+
+// sendNotificationPayload is the payload of a JobSendNotification job.
+type sendNotificationPayload struct {
+	NotificationID string `json:"notification_id"`
+}
+
+// NotificationSender delivers a notification to a channel's target.
+// Returning an error wrapped with jobs.Permanent gives up on it.
+type NotificationSender interface {
+	Send(ctx context.Context, target string, n *model.Notification) error
+}
+
+// ConfirmationSender sends the link that confirms a channel's owner
+// controls its target. Email senders implement it; email channels can't be
+// registered without one.
+type ConfirmationSender interface {
+	SendConfirmation(ctx context.Context, target, link string) error
+}
+
+// NotificationService sends users notifications about their games over the
+// webhooks, Discord webhooks and email addresses they register: deadline
+// reminders, resolved phases and missing orders. Email addresses get
+// nothing until their owner follows a confirmation link. With a job queue,
+// deliveries are retried with backoff until they succeed.
+type NotificationService struct {
+	notifRepo  repository.NotificationRepository
+	gameRepo   repository.GameRepository
+	phaseRepo  repository.PhaseRepository
+	cache      repository.GameCache
+	senders    map[string]NotificationSender
+	confirmURL string      // confirmation links point here, with ?token=
+	jobs       *jobs.Queue // optional
+}
+
+// NewNotificationService creates a NotificationService that can deliver to
+// webhooks and Discord; email needs SetSender.
+func NewNotificationService(notifRepo repository.NotificationRepository, gameRepo repository.GameRepository, phaseRepo repository.PhaseRepository, cache repository.GameCache) *NotificationService {
+	client := notificationHTTPClient()
+	return &NotificationService{
+		notifRepo: notifRepo,
+		gameRepo:  gameRepo,
+		phaseRepo: phaseRepo,
+		cache:     cache,
+		senders: map[string]NotificationSender{
+			model.NotifyByWebhook: &webhookSender{client: client},
+			model.NotifyByDiscord: &discordSender{client: client},
+		},
+	}
+}
+
+// SetSender sets how notifications of a channel kind are delivered,
+// replacing any previous sender. Channels of a kind without a sender can't
+// be registered.
+func (s *NotificationService) SetSender(kind string, sender NotificationSender) {
+	s.senders[kind] = sender
+}
+
+// SetConfirmURL sets the page confirmation links point to; the token is
+// added as its token query parameter.
+func (s *NotificationService) SetConfirmURL(u string) {
+	s.confirmURL = u
+}
+
+// RegisterJobs registers the delivery job handler with q and queues
+// deliveries on it from then on. Without a queue each notification is
+// attempted once, in a goroutine.
+func (s *NotificationService) RegisterJobs(q *jobs.Queue) {
+	q.Register(JobSendNotification, s.sendNotificationJob)
+	s.jobs = q
+}
+
+func (s *NotificationService) sendNotificationJob(ctx context.Context, payload json.RawMessage) error {
+	var p sendNotificationPayload
+	if err := json.Unmarshal(payload, &p); err != nil || p.NotificationID == "" {
+		return jobs.Permanent(fmt.Errorf("invalid %s payload %s", JobSendNotification, payload))
+	}
+	return s.deliver(ctx, p.NotificationID)
+}
+
+// CreateChannel registers a channel for the user. No events subscribes it
+// to all of them. An email channel is mailed a confirmation link and sends
+// nothing until it is followed.
+func (s *NotificationService) CreateChannel(ctx context.Context, userID, kind, target string, events []string) (*model.NotificationChannel, error) {
+	if !slices.Contains([]string{model.NotifyByWebhook, model.NotifyByDiscord, model.NotifyByEmail}, kind) {
+		return nil, ErrNotificationKind
+	}
+	if s.senders[kind] == nil {
+		return nil, ErrNotificationDisabled
+	}
+	confirmer, _ := s.senders[kind].(ConfirmationSender)
+	if kind == model.NotifyByEmail && (confirmer == nil || s.confirmURL == "") {
+		return nil, ErrNotificationDisabled
+	}
+	target = strings.TrimSpace(target)
+	if !validNotificationTarget(kind, target) {
+		return nil, ErrNotificationTarget
+	}
+	events, err := normalizeNotificationEvents(events)
+	if err != nil {
+		return nil, err
+	}
+	channels, err := s.notifRepo.ListChannels(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(channels) >= MaxNotificationChannels {
+		return nil, ErrTooManyChannels
+	}
+	if kind != model.NotifyByEmail {
+		return s.notifRepo.CreateChannel(ctx, userID, kind, target, events, "")
+	}
+
+	token, err := newConfirmToken()
+	if err != nil {
+		return nil, err
+	}
+	ch, err := s.notifRepo.CreateChannel(ctx, userID, kind, target, events, hashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if err := confirmer.SendConfirmation(ctx, target, s.confirmLink(token)); err != nil {
+		if _, delErr := s.notifRepo.DeleteChannel(context.WithoutCancel(ctx), ch.ID, userID); delErr != nil {
+			log.Error().Err(delErr).Str("channelId", ch.ID).Msg("Failed to remove unconfirmable notification channel")
+		}
+		return nil, fmt.Errorf("send confirmation: %w", err)
+	}
+	return ch, nil
+}
+
+// ResendConfirmation mails one of the user's unconfirmed channels a new
+// confirmation link, replacing the old one.
+func (s *NotificationService) ResendConfirmation(ctx context.Context, userID, id string) error {
+	ch, err := s.notifRepo.FindChannel(ctx, id)
+	if err != nil {
+		return err
+	}
+	if ch == nil || ch.UserID != userID {
+		return ErrNotificationChannelNotFound
+	}
+	if ch.Confirmed {
+		return ErrChannelConfirmed
+	}
+	confirmer, _ := s.senders[ch.Kind].(ConfirmationSender)
+	if confirmer == nil || s.confirmURL == "" {
+		return ErrNotificationDisabled
+	}
+	token, err := newConfirmToken()
+	if err != nil {
+		return err
+	}
+	ok, err := s.notifRepo.SetConfirmToken(ctx, id, userID, hashToken(token))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrChannelConfirmed
+	}
+	if err := confirmer.SendConfirmation(ctx, ch.Target, s.confirmLink(token)); err != nil {
+		return fmt.Errorf("send confirmation: %w", err)
+	}
+	return nil
+}
+
+// ConfirmChannel confirms the channel a confirmation link was sent for, if
+// the link is less than ChannelConfirmTTL old.
+func (s *NotificationService) ConfirmChannel(ctx context.Context, token string) (*model.NotificationChannel, error) {
+	if token == "" {
+		return nil, ErrConfirmationInvalid
+	}
+	ch, err := s.notifRepo.ConfirmChannel(ctx, hashToken(token), time.Now().Add(-ChannelConfirmTTL))
+	if err != nil {
+		return nil, err
+	}
+	if ch == nil {
+		return nil, ErrConfirmationInvalid
+	}
+	return ch, nil
+}
+
+// confirmLink returns the confirmation link for token.
+func (s *NotificationService) confirmLink(token string) string {
+	sep := "?"
+	if strings.Contains(s.confirmURL, "?") {
+		sep = "&"
+	}
+	return s.confirmURL + sep + "token=" + url.QueryEscape(token)
+}
+
+// newConfirmToken returns a random confirmation token.
+func newConfirmToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// ListChannels returns the user's channels.
+func (s *NotificationService) ListChannels(ctx context.Context, userID string) ([]model.NotificationChannel, error) {
+	channels, err := s.notifRepo.ListChannels(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return nonNil(channels), nil
+}
+
+// UpdateChannel changes which events one of the user's channels gets, and
+// whether it is enabled. Nil arguments keep the current value.
+func (s *NotificationService) UpdateChannel(ctx context.Context, userID, id string, events []string, enabled *bool) (*model.NotificationChannel, error) {
+	ch, err := s.notifRepo.FindChannel(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if ch == nil || ch.UserID != userID {
+		return nil, ErrNotificationChannelNotFound
+	}
+	if events != nil {
+		if ch.Events, err = normalizeNotificationEvents(events); err != nil {
+			return nil, err
+		}
+	}
+	if enabled != nil {
+		ch.Enabled = *enabled
+	}
+	ch, err = s.notifRepo.UpdateChannel(ctx, id, userID, ch.Events, ch.Enabled)
+	if err != nil {
+		return nil, err
+	}
+	if ch == nil {
+		return nil, ErrNotificationChannelNotFound
+	}
+	return ch, nil
+}
+
+// DeleteChannel removes one of the user's channels.
+func (s *NotificationService) DeleteChannel(ctx context.Context, userID, id string) error {
+	ok, err := s.notifRepo.DeleteChannel(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotificationChannelNotFound
+	}
+	return nil
+}
+
+// ListNotifications returns the user's most recent notifications and how
+// their delivery went.
+func (s *NotificationService) ListNotifications(ctx context.Context, userID string, limit int) ([]model.Notification, error) {
+	notifications, err := s.notifRepo.ListNotifications(ctx, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return nonNil(notifications), nil
+}
+
+// validNotificationTarget checks a target suits its channel kind.
+func validNotificationTarget(kind, target string) bool {
+	if kind == model.NotifyByEmail {
+		addr, err := mail.ParseAddress(target)
+		return err == nil && addr.Address == target
+	}
+	u, err := url.Parse(target)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" || u.User != nil {
+		return false
+	}
+	if kind == model.NotifyByDiscord {
+		host := u.Hostname()
+		return (host == "discord.com" || host == "discordapp.com" || strings.HasSuffix(host, ".discord.com")) &&
+			strings.HasPrefix(u.Path, "/api/webhooks/")
+	}
+	return true
+}
+
+// normalizeNotificationEvents checks events and returns them without
+// duplicates in a fixed order, or all of them if none are given.
+func normalizeNotificationEvents(events []string) ([]string, error) {
+	if len(events) == 0 {
+		return slices.Clone(notificationEvents), nil
+	}
+	for _, e := range events {
+		if !slices.Contains(notificationEvents, e) {
+			return nil, ErrNotificationEvent
+		}
+	}
+	var out []string
+	for _, e := range notificationEvents {
+		if slices.Contains(events, e) {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+// CheckDeadlines sends the reminders that are due for active phases: a
+// deadline reminder to every player DeadlineReminderLead before, and a
+// missing-orders reminder to players yet to order OrdersReminderLead
+// before. Each goes out once per phase; phases too short to have one due
+// after they open get none. The timer listener calls it periodically.
+func (s *NotificationService) CheckDeadlines(ctx context.Context) {
+	now := time.Now()
+	phases, err := s.phaseRepo.ListDueBefore(ctx, now.Add(DeadlineReminderLead))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list phases due for reminders")
+		return
+	}
+	reminders := []struct {
+		event string
+		lead  time.Duration
+	}{
+		{model.NotifyDeadline, DeadlineReminderLead},
+		{model.NotifyOrdersMissing, OrdersReminderLead},
+	}
+	for i := range phases {
+		p := &phases[i]
+		for _, r := range reminders {
+			if p.Deadline.Sub(now) > r.lead || p.Deadline.Add(-r.lead).Before(p.CreatedAt) {
+				continue
+			}
+			claimed, err := s.notifRepo.ClaimReminder(ctx, p.ID, r.event)
+			if err != nil {
+				log.Error().Err(err).Str("gameId", p.GameID).Str("event", r.event).Msg("Failed to claim reminder")
+				continue
+			}
+			if !claimed {
+				continue
+			}
+			if err := s.remind(ctx, p, r.event); err != nil {
+				log.Error().Err(err).Str("gameId", p.GameID).Str("event", r.event).Msg("Failed to send reminders")
+			}
+		}
+	}
+}
+
+// remind sends a phase's deadline or missing-orders reminder to the human
+// players it concerns.
+func (s *NotificationService) remind(ctx context.Context, phase *model.Phase, event string) error {
+	game, err := s.gameRepo.FindByID(ctx, phase.GameID)
+	if err != nil || game == nil || game.Status != "active" {
+		return err
+	}
+	var gs diplomacy.GameState
+	if err := json.Unmarshal(phase.StateBefore, &gs); err != nil {
+		return fmt.Errorf("unmarshal state for reminders: %w", err)
+	}
+	title := gs.Calendar().PhaseTitle(phase.Year, diplomacy.Season(phase.Season), diplomacy.PhaseType(phase.PhaseType))
+	minutes := int(time.Until(phase.Deadline).Round(time.Minute).Minutes())
+	m := gs.Map()
+
+	var ready []string
+	var orders map[string]json.RawMessage
+	if event == model.NotifyOrdersMissing {
+		if ready, err = s.cache.ReadyPowers(ctx, game.ID); err != nil {
+			return err
+		}
+		if orders, err = s.cache.GetAllOrders(ctx, game.ID, activePowers(game)); err != nil {
+			return err
+		}
+	}
+
+	messages := make(map[string]string)
+	for _, p := range game.Players {
+		power := diplomacy.Power(p.Power)
+		if p.IsBot || p.Power == "" || !gs.PowerIsAlive(power) {
+			continue
+		}
+		switch event {
+		case model.NotifyDeadline:
+			messages[p.UserID] = fmt.Sprintf("%s in %q is due in %d minutes.", title, game.Name, minutes)
+		case model.NotifyOrdersMissing:
+			if !diplomacy.HasOrderChoice(&gs, power, m) || slices.Contains(ready, p.Power) || orders[p.Power] != nil {
+				continue
+			}
+			messages[p.UserID] = fmt.Sprintf("You haven't submitted orders for %s in %q; %s is due in %d minutes.",
+				capitalize(p.Power), game.Name, title, minutes)
+		}
+	}
+	return s.notify(ctx, game.ID, phase.ID, event, messages)
+}
+
+// PhaseResolved tells a game's human players a phase resolved, and what
+// comes next; next is nil once the game is over.
+func (s *NotificationService) PhaseResolved(ctx context.Context, game *model.Game, resolved, next *model.Phase, cal diplomacy.Calendar) error {
+	msg := fmt.Sprintf("%s resolved in %q; ", cal.PhaseTitle(resolved.Year, diplomacy.Season(resolved.Season), diplomacy.PhaseType(resolved.PhaseType)), game.Name)
+	if next == nil {
+		msg += "the game is over."
+	} else {
+		msg += fmt.Sprintf("%s is due %s.", cal.PhaseTitle(next.Year, diplomacy.Season(next.Season), diplomacy.PhaseType(next.PhaseType)),
+			next.Deadline.UTC().Format("Mon 2 Jan 15:04 MST"))
+	}
+	messages := make(map[string]string)
+	for _, p := range game.Players {
+		if !p.IsBot && p.Power != "" {
+			messages[p.UserID] = msg
+		}
+	}
+	return s.notify(ctx, game.ID, resolved.ID, model.NotifyPhaseResolved, messages)
+}
+
+// Eliminated tells a power's player it was eliminated in phase, and the
+// other human players which power is out and who took it.
+func (s *NotificationService) Eliminated(ctx context.Context, game *model.Game, phase *model.Phase, power, by string) error {
+	out := fmt.Sprintf("%s has been eliminated from %q", capitalize(power), game.Name)
+	if by != "" {
+		out += fmt.Sprintf(" by %s", capitalize(by))
+	}
+	messages := make(map[string]string)
+	for _, p := range game.Players {
+		switch {
+		case p.IsBot || p.Power == "":
+		case p.Power == power:
+			messages[p.UserID] = fmt.Sprintf("You lost your last supply center in %q in %d; %s is out of the game.", game.Name, phase.Year, capitalize(power))
+		default:
+			messages[p.UserID] = out + fmt.Sprintf(" in %d.", phase.Year)
+		}
+	}
+	return s.notify(ctx, game.ID, phase.ID, model.NotifyEliminated, messages)
+}
+
+// notify creates a notification on every channel of the users subscribed
+// to the event, with each user's message, and queues their delivery.
+func (s *NotificationService) notify(ctx context.Context, gameID, phaseID, event string, messages map[string]string) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	userIDs := make([]string, 0, len(messages))
+	for id := range messages {
+		userIDs = append(userIDs, id)
+	}
+	slices.Sort(userIDs)
+	channels, err := s.notifRepo.SubscribedChannels(ctx, userIDs, event)
+	if err != nil {
+		return err
+	}
+	pending := make([]model.Notification, 0, len(channels))
+	for _, ch := range channels {
+		pending = append(pending, model.Notification{
+			UserID:    ch.UserID,
+			ChannelID: ch.ID,
+			Event:     event,
+			GameID:    gameID,
+			PhaseID:   phaseID,
+			Message:   messages[ch.UserID],
+		})
+	}
+	created, err := s.notifRepo.CreateNotifications(ctx, pending)
+	if err != nil {
+		return err
+	}
+	for _, n := range created {
+		s.enqueue(n.ID)
+	}
+	return nil
+}
+
+// enqueue queues a notification's delivery, or attempts it in a goroutine
+// without a job queue.
+func (s *NotificationService) enqueue(id string) {
+	if s.jobs != nil {
+		_, err := s.jobs.Enqueue(context.Background(), JobSendNotification, sendNotificationPayload{NotificationID: id})
+		if err == nil {
+			return
+		}
+		log.Warn().Err(err).Str("notificationId", id).Msg("Failed to enqueue notification, sending it now")
+	}
+	go func() {
+		if err := s.deliver(context.Background(), id); err != nil {
+			log.Warn().Err(err).Str("notificationId", id).Msg("Failed to send notification")
+		}
+	}()
+}
+
+// deliver makes one attempt at sending a pending notification and records
+// the outcome. It gives up on the notification after the job queue's last
+// attempt, or the first without a queue.
+func (s *NotificationService) deliver(ctx context.Context, id string) error {
+	n, err := s.notifRepo.FindNotification(ctx, id)
+	if err != nil {
+		return err
+	}
+	if n == nil || n.Status != model.NotificationPending {
+		return nil
+	}
+	ch, err := s.notifRepo.FindChannel(ctx, n.ChannelID)
+	if err != nil {
+		return err
+	}
+	var sendErr error
+	switch {
+	case ch == nil || !ch.Enabled:
+		sendErr = jobs.Permanent(errors.New("channel was disabled"))
+	case !ch.Confirmed:
+		sendErr = jobs.Permanent(errors.New("channel is not confirmed"))
+	case s.senders[ch.Kind] == nil:
+		sendErr = jobs.Permanent(ErrNotificationDisabled)
+	default:
+		sendErr = s.senders[ch.Kind].Send(ctx, ch.Target, n)
+	}
+
+	maxAttempts := 1
+	if s.jobs != nil {
+		maxAttempts = s.jobs.MaxAttempts()
+	}
+	status, lastError := model.NotificationSent, ""
+	if sendErr != nil {
+		status, lastError = model.NotificationPending, sendErr.Error()
+		if jobs.IsPermanent(sendErr) || n.Attempts+1 >= maxAttempts {
+			status = model.NotificationFailed
+		}
+	}
+	if err := s.notifRepo.RecordAttempt(context.WithoutCancel(ctx), n.ID, status, lastError); err != nil {
+		log.Error().Err(err).Str("notificationId", n.ID).Msg("Failed to record notification attempt")
+	}
+	return sendErr
+}
@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+)
+
+// recordingSender records the notifications it is asked to send.
+type recordingSender struct {
+	mu   sync.Mutex
+	wg   sync.WaitGroup
+	sent []model.Notification
+}
+
+func (s *recordingSender) Send(_ context.Context, _ string, n *model.Notification) error {
+	defer s.wg.Done()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, *n)
+	return nil
+}
+
+func TestNotificationChannelValidation(t *testing.T) {
+	ctx := context.Background()
+	svc := NewNotificationService(newMockNotificationRepo(), newMockGameRepo(), newMockPhaseRepo(), newMockCache())
+
+	tests := []struct {
+		name   string
+		kind   string
+		target string
+		events []string
+		want   error
+	}{
+		{"webhook", model.NotifyByWebhook, "https://example.com/hook", nil, nil},
+		{"plain http", model.NotifyByWebhook, "http://example.com/hook", nil, ErrNotificationTarget},
+		{"discord", model.NotifyByDiscord, "https://discord.com/api/webhooks/1/abc", nil, nil},
+		{"not discord", model.NotifyByDiscord, "https://example.com/api/webhooks/1/abc", nil, ErrNotificationTarget},
+		{"email without smtp", model.NotifyByEmail, "player@example.com", nil, ErrNotificationDisabled},
+		{"unknown kind", "pager", "https://example.com", nil, ErrNotificationKind},
+		{"unknown event", model.NotifyByWebhook, "https://example.com/hook", []string{"game_won"}, ErrNotificationEvent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.CreateChannel(ctx, "user-"+tt.name, tt.kind, tt.target, tt.events)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	ch, err := svc.CreateChannel(ctx, "user-1", model.NotifyByWebhook, "https://example.com/hook",
+		[]string{model.NotifyOrdersMissing, model.NotifyDeadline, model.NotifyDeadline})
+	if err != nil {
+		t.Fatalf("CreateChannel: %v", err)
+	}
+	if !slices.Equal(ch.Events, []string{model.NotifyDeadline, model.NotifyOrdersMissing}) {
+		t.Errorf("expected events normalized, got %v", ch.Events)
+	}
+	for range MaxNotificationChannels - 1 {
+		svc.CreateChannel(ctx, "user-1", model.NotifyByWebhook, "https://example.com/hook", nil)
+	}
+	if _, err := svc.CreateChannel(ctx, "user-1", model.NotifyByWebhook, "https://example.com/hook", nil); !errors.Is(err, ErrTooManyChannels) {
+		t.Errorf("expected ErrTooManyChannels, got %v", err)
+	}
+	if _, err := svc.UpdateChannel(ctx, "user-2", ch.ID, nil, nil); !errors.Is(err, ErrNotificationChannelNotFound) {
+		t.Errorf("expected another user's channel not found, got %v", err)
+	}
+}
+
+func TestCheckDeadlines(t *testing.T) {
+	ctx := context.Background()
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	cache := newMockCache()
+	notifRepo := newMockNotificationRepo()
+	gameID, _ := setupActiveGame(t, gameRepo, phaseRepo, cache)
+	players := gameRepo.players[gameID]
+
+	svc := NewNotificationService(notifRepo, gameRepo, phaseRepo, cache)
+	sender := &recordingSender{}
+	svc.SetSender(model.NotifyByWebhook, sender)
+	for _, p := range players[:3] {
+		if _, err := svc.CreateChannel(ctx, p.UserID, model.NotifyByWebhook, "https://example.com/hook", nil); err != nil {
+			t.Fatalf("CreateChannel: %v", err)
+		}
+	}
+	// The first player has ordered and the second is ready.
+	cache.SetOrders(ctx, gameID, players[0].Power, []byte(`[]`))
+	cache.MarkReady(ctx, gameID, players[1].Power)
+
+	phase, _ := phaseRepo.CurrentPhase(ctx, gameID)
+	phase.CreatedAt = time.Now().Add(-24 * time.Hour)
+	phase.Deadline = time.Now().Add(10 * time.Minute)
+
+	sender.wg.Add(4) // three deadline reminders, one missing-orders reminder
+	svc.CheckDeadlines(ctx)
+	sender.wg.Wait()
+	// Reminders already sent for the phase aren't sent again.
+	svc.CheckDeadlines(ctx)
+
+	sender.mu.Lock()
+	defer sender.mu.Unlock()
+	var deadline, missing []string
+	for _, n := range sender.sent {
+		switch n.Event {
+		case model.NotifyDeadline:
+			deadline = append(deadline, n.UserID)
+		case model.NotifyOrdersMissing:
+			missing = append(missing, n.UserID)
+		}
+	}
+	if len(deadline) != 3 || len(sender.sent) != 4 {
+		t.Errorf("expected three deadline reminders of four notifications, got %+v", sender.sent)
+	}
+	if !slices.Equal(missing, []string{players[2].UserID}) {
+		t.Errorf("expected only %s reminded of missing orders, got %v", players[2].UserID, missing)
+	}
+}
+
+func TestNotifyEliminated(t *testing.T) {
+	ctx := context.Background()
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	cache := newMockCache()
+	notifRepo := newMockNotificationRepo()
+	gameID, _ := setupActiveGame(t, gameRepo, phaseRepo, cache)
+	players := gameRepo.players[gameID]
+
+	svc := NewNotificationService(notifRepo, gameRepo, phaseRepo, cache)
+	sender := &recordingSender{}
+	svc.SetSender(model.NotifyByWebhook, sender)
+	for _, p := range players[:2] {
+		if _, err := svc.CreateChannel(ctx, p.UserID, model.NotifyByWebhook, "https://example.com/hook", []string{model.NotifyEliminated}); err != nil {
+			t.Fatalf("CreateChannel: %v", err)
+		}
+	}
+	game, _ := gameRepo.FindByID(ctx, gameID)
+	phase, _ := phaseRepo.CurrentPhase(ctx, gameID)
+
+	sender.wg.Add(2)
+	if err := svc.Eliminated(ctx, game, phase, players[0].Power, players[1].Power); err != nil {
+		t.Fatalf("Eliminated: %v", err)
+	}
+	sender.wg.Wait()
+
+	sender.mu.Lock()
+	defer sender.mu.Unlock()
+	for _, n := range sender.sent {
+		want := "You lost your last supply center"
+		if n.UserID != players[0].UserID {
+			want = capitalize(players[0].Power) + " has been eliminated"
+		}
+		if n.Event != model.NotifyEliminated || !strings.HasPrefix(n.Message, want) {
+			t.Errorf("%s got %s %q, want a message starting %q", n.UserID, n.Event, n.Message, want)
+		}
+	}
+}
+
+func TestDeliverNotificationPermanentFailure(t *testing.T) {
+	ctx := context.Background()
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	notifRepo := newMockNotificationRepo()
+	svc := NewNotificationService(notifRepo, newMockGameRepo(), newMockPhaseRepo(), newMockCache())
+	svc.SetSender(model.NotifyByWebhook, &webhookSender{client: srv.Client()})
+	ch, err := svc.CreateChannel(ctx, "user-1", model.NotifyByWebhook, srv.URL, nil)
+	if err != nil {
+		t.Fatalf("CreateChannel: %v", err)
+	}
+	created, _ := notifRepo.CreateNotifications(ctx, []model.Notification{
+		{UserID: "user-1", ChannelID: ch.ID, Event: model.NotifyPhaseResolved, Message: "Spring 1901 resolved"},
+	})
+
+	if err := svc.deliver(ctx, created[0].ID); err == nil {
+		t.Fatal("expected delivery to a missing webhook to fail")
+	}
+	n, _ := notifRepo.FindNotification(ctx, created[0].ID)
+	if n.Status != model.NotificationFailed || n.Attempts != 1 || n.LastError == "" {
+		t.Errorf("expected the notification failed after one attempt, got %+v", n)
+	}
+}
+
+// confirmingSender records notifications and confirmation links.
+type confirmingSender struct {
+	recordingSender
+	links []string
+}
+
+func (s *confirmingSender) SendConfirmation(_ context.Context, _, link string) error {
+	s.links = append(s.links, link)
+	return nil
+}
+
+func TestEmailChannelNeedsConfirmation(t *testing.T) {
+	ctx := context.Background()
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	cache := newMockCache()
+	notifRepo := newMockNotificationRepo()
+	gameID, _ := setupActiveGame(t, gameRepo, phaseRepo, cache)
+	player := gameRepo.players[gameID][0]
+
+	svc := NewNotificationService(notifRepo, gameRepo, phaseRepo, cache)
+	sender := &confirmingSender{}
+	svc.SetSender(model.NotifyByEmail, sender)
+	if _, err := svc.CreateChannel(ctx, player.UserID, model.NotifyByEmail, "player@example.com", nil); !errors.Is(err, ErrNotificationDisabled) {
+		t.Fatalf("expected email disabled without a confirmation URL, got %v", err)
+	}
+	svc.SetConfirmURL("https://example.com/notifications/confirm")
+
+	ch, err := svc.CreateChannel(ctx, player.UserID, model.NotifyByEmail, "player@example.com", nil)
+	if err != nil {
+		t.Fatalf("CreateChannel: %v", err)
+	}
+	if ch.Confirmed || len(sender.links) != 1 {
+		t.Fatalf("expected an unconfirmed channel and one link, got %+v and %v", ch, sender.links)
+	}
+	game, _ := gameRepo.FindByID(ctx, gameID)
+	phase, _ := phaseRepo.CurrentPhase(ctx, gameID)
+	if err := svc.Eliminated(ctx, game, phase, player.Power, ""); err != nil {
+		t.Fatalf("Eliminated: %v", err)
+	}
+	if n, _ := notifRepo.ListNotifications(ctx, player.UserID, 10); len(n) != 0 {
+		t.Fatalf("expected nothing sent to an unconfirmed address, got %+v", n)
+	}
+
+	if err := svc.ResendConfirmation(ctx, player.UserID, ch.ID); err != nil {
+		t.Fatalf("ResendConfirmation: %v", err)
+	}
+	oldToken := strings.TrimPrefix(sender.links[0], "https://example.com/notifications/confirm?token=")
+	if _, err := svc.ConfirmChannel(ctx, oldToken); !errors.Is(err, ErrConfirmationInvalid) {
+		t.Errorf("expected the replaced link to stop working, got %v", err)
+	}
+	newToken := strings.TrimPrefix(sender.links[1], "https://example.com/notifications/confirm?token=")
+	confirmed, err := svc.ConfirmChannel(ctx, newToken)
+	if err != nil || !confirmed.Confirmed || confirmed.ID != ch.ID {
+		t.Fatalf("expected the channel confirmed, got %+v, %v", confirmed, err)
+	}
+	if err := svc.ResendConfirmation(ctx, player.UserID, ch.ID); !errors.Is(err, ErrChannelConfirmed) {
+		t.Errorf("expected ErrChannelConfirmed, got %v", err)
+	}
+
+	sender.wg.Add(1)
+	if err := svc.Eliminated(ctx, game, phase, player.Power, ""); err != nil {
+		t.Fatalf("Eliminated: %v", err)
+	}
+	sender.wg.Wait()
+}
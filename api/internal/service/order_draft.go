@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/freeeve/polite-betrayal/api/internal/bot"
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// OrderCheck is the validation result of one order of a set.
+type OrderCheck struct {
+	Location string   `json:"location"`
+	Valid    bool     `json:"valid"`
+	Error    string   `json:"error,omitempty"`    // why the order is illegal
+	Warnings []string `json:"warnings,omitempty"` // set-level hints for legal orders
+}
+
+// SaveOrder saves or replaces the order of the unit at location in the
+// user's orders for the current phase, keeping the others. In build phases
+// location is where a unit is built or disbanded. The location of in is
+// taken from location. The set is read and written atomically, so orders
+// saved concurrently for other units are kept. It returns the whole set as
+// SubmitOrders does.
+func (s *OrderService) SaveOrder(ctx context.Context, gameID, userID, location string, in OrderInput) ([]model.Order, error) {
+	game, power, phase, gs, err := s.playerPhase(ctx, gameID, userID)
+	if err != nil {
+		return nil, err
+	}
+	m := gs.Map()
+	in.Location = location
+	in = canonicalInputs([]OrderInput{in})[0]
+
+	var inputs []OrderInput
+	var orders []model.Order
+	err = s.cache.UpdateOrders(withPhase(ctx, phase.ID), gameID, power, func(current json.RawMessage) (json.RawMessage, error) {
+		submitted, err := decodeSubmittedOrders(current, gs.Phase)
+		if err != nil {
+			return nil, err
+		}
+		inputs = inputs[:0]
+		for _, o := range bot.DSONInputs(submitted, diplomacy.Power(power), gs.Phase) {
+			if o.Location != in.Location {
+				inputs = append(inputs, canonicalInputs([]OrderInput{botInputToServiceInput(o)})[0])
+			}
+		}
+		inputs = append(inputs, in)
+		var ordersJSON json.RawMessage
+		ordersJSON, orders, err = encodeOrders(phase.ID, power, gs, m, inputs)
+		return ordersJSON, err
+	})
+	if err != nil {
+		if errors.Is(err, ErrInvalidOrder) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("save order: %w", err)
+	}
+	if gs.Phase == diplomacy.PhaseMovement {
+		s.attachAgreementWarnings(ctx, game, phase.ID, userID, power, gs, m, inputs, orders)
+	}
+	return orders, nil
+}
+
+// ValidateOrders checks each order as the user's power would submit it in
+// the current phase, without saving anything. Legal movement orders are
+// also analyzed as a set for warnings.
+func (s *OrderService) ValidateOrders(ctx context.Context, gameID, userID string, inputs []OrderInput) ([]OrderCheck, error) {
+	_, power, _, gs, err := s.playerPhase(ctx, gameID, userID)
+	if err != nil {
+		return nil, err
+	}
+	m := gs.Map()
+	p := diplomacy.Power(power)
+	inputs = canonicalInputs(inputs)
+
+	checks := make([]OrderCheck, len(inputs))
+	var legal []diplomacy.Order
+	var legalIndex []int
+	for i, in := range inputs {
+		var err error
+		switch gs.Phase {
+		case diplomacy.PhaseRetreat:
+			err = diplomacy.ValidateRetreatOrder(toRetreatOrder(in, p), gs, m)
+		case diplomacy.PhaseBuild:
+			err = diplomacy.ValidateBuildOrder(toBuildOrder(in, p), gs, m)
+		default:
+			o := toEngineOrder(in, p)
+			if err = diplomacy.ValidateOrder(o, gs, m); err == nil {
+				legal = append(legal, o)
+				legalIndex = append(legalIndex, i)
+			}
+		}
+		checks[i] = OrderCheck{Location: in.Location, Valid: err == nil}
+		var ve *diplomacy.ValidationError
+		if errors.As(err, &ve) {
+			checks[i].Error = ve.Message
+		} else if err != nil {
+			checks[i].Error = err.Error()
+		}
+	}
+	for _, w := range diplomacy.AnalyzeOrders(legal, gs, m) {
+		i := legalIndex[w.Index]
+		checks[i].Warnings = append(checks[i].Warnings, w.Message)
+	}
+	return checks, nil
+}
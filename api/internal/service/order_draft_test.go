@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+func TestSaveOrder(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	cache := newMockCache()
+	gameID, _ := setupActiveGame(t, gameRepo, phaseRepo, cache)
+	orderSvc := NewOrderService(gameRepo, phaseRepo, cache)
+	ctx := context.Background()
+
+	_, units := playerUnits(t, gameRepo, gameID, "user-1")
+	first, second := units[0], units[1]
+	if _, err := orderSvc.SaveOrder(ctx, gameID, "user-1", first.Province, OrderInput{UnitType: first.Type.String(), OrderType: "hold"}); err != nil {
+		t.Fatalf("SaveOrder: %v", err)
+	}
+	orders, err := orderSvc.SaveOrder(ctx, gameID, "user-1", second.Province, OrderInput{UnitType: second.Type.String(), OrderType: "hold"})
+	if err != nil {
+		t.Fatalf("SaveOrder: %v", err)
+	}
+	if len(orders) != 2 {
+		t.Fatalf("expected both units' orders, got %+v", orders)
+	}
+
+	// Replacing an order keeps the set's size.
+	orders, err = orderSvc.SaveOrder(ctx, gameID, "user-1", first.Province, OrderInput{UnitType: first.Type.String(), OrderType: "hold"})
+	if err != nil {
+		t.Fatalf("SaveOrder: %v", err)
+	}
+	if len(orders) != 2 {
+		t.Errorf("expected the order to be replaced, got %+v", orders)
+	}
+
+	// An illegal order is rejected and the saved set is untouched.
+	_, err = orderSvc.SaveOrder(ctx, gameID, "user-1", first.Province, OrderInput{UnitType: first.Type.String(), OrderType: "move", Target: "mos"})
+	if !errors.Is(err, ErrInvalidOrder) {
+		t.Errorf("expected ErrInvalidOrder, got %v", err)
+	}
+	saved, err := orderSvc.submittedOrders(ctx, gameID, orders[0].Power, diplomacy.PhaseMovement)
+	if err != nil || len(saved) != 2 {
+		t.Errorf("expected 2 saved orders, got %v (%v)", saved, err)
+	}
+}
+
+func TestValidateOrders(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	cache := newMockCache()
+	gameID, _ := setupActiveGame(t, gameRepo, phaseRepo, cache)
+	orderSvc := NewOrderService(gameRepo, phaseRepo, cache)
+	ctx := context.Background()
+
+	power, units := playerUnits(t, gameRepo, gameID, "user-1")
+	_, otherUnits := playerUnits(t, gameRepo, gameID, "user-2")
+	checks, err := orderSvc.ValidateOrders(ctx, gameID, "user-1", []OrderInput{
+		{UnitType: units[0].Type.String(), Location: units[0].Province, OrderType: "hold"},
+		{UnitType: otherUnits[0].Type.String(), Location: otherUnits[0].Province, OrderType: "hold"},
+		{UnitType: units[1].Type.String(), Location: units[1].Province, OrderType: "move", Target: "xyz"},
+	})
+	if err != nil {
+		t.Fatalf("ValidateOrders: %v", err)
+	}
+	if len(checks) != 3 {
+		t.Fatalf("expected 3 checks, got %+v", checks)
+	}
+	if !checks[0].Valid || checks[0].Error != "" {
+		t.Errorf("expected the hold to be valid, got %+v", checks[0])
+	}
+	if checks[1].Valid || checks[1].Error == "" {
+		t.Errorf("expected an error ordering another power's unit, got %+v", checks[1])
+	}
+	if checks[2].Valid || checks[2].Error != "target province does not exist: xyz" {
+		t.Errorf("expected an unknown target error, got %+v", checks[2])
+	}
+
+	// Nothing is saved.
+	if raw, _ := cache.GetOrders(ctx, gameID, power); raw != nil {
+		t.Errorf("expected no saved orders, got %s", raw)
+	}
+}
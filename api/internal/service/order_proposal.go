@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/freeeve/polite-betrayal/api/internal/bot"
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+var (
+	ErrMessageNotFound  = errors.New("message not found")
+	ErrNoOrderProposal  = errors.New("message has no proposed orders for your units")
+	ErrNotMovementPhase = errors.New("order proposals can only be imported during a movement phase")
+)
+
+// ImportProposedOrders merges the orders proposed in a press message into the
+// user's submitted orders for the current movement phase. Only proposed orders
+// for the user's own units are taken; they replace any existing order for the
+// same unit. The merged set is validated and stored like a normal submission.
+func (s *OrderService) ImportProposedOrders(ctx context.Context, gameID, userID, messageID string) ([]model.Order, error) {
+	if s.messageRepo == nil {
+		return nil, ErrMessageNotFound
+	}
+	msg, err := s.messageRepo.FindByID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if msg == nil || msg.GameID != gameID ||
+		(msg.RecipientID != "" && msg.RecipientID != userID && msg.SenderID != userID) {
+		return nil, ErrMessageNotFound
+	}
+	if msg.ChannelID != "" && !s.canSee(ctx, gameID, userID, msg.ID) {
+		return nil, ErrMessageNotFound
+	}
+	if msg.Intent == nil {
+		return nil, ErrNoOrderProposal
+	}
+	intent, err := bot.IntentFromAttachment(msg.Intent)
+	if err != nil || intent.Type != bot.IntentProposeOrders {
+		return nil, ErrNoOrderProposal
+	}
+
+	_, power, _, gs, err := s.playerPhase(ctx, gameID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if gs.Phase != diplomacy.PhaseMovement {
+		return nil, ErrNotMovementPhase
+	}
+
+	proposed := bot.ProposedOrdersFor(*intent, diplomacy.Power(power), gs)
+	if len(proposed) == 0 {
+		return nil, ErrNoOrderProposal
+	}
+
+	draft, err := s.currentMovementOrders(ctx, gameID, power)
+	if err != nil {
+		return nil, err
+	}
+	covered := make(map[string]bool, len(proposed))
+	for _, o := range proposed {
+		covered[o.Location] = true
+	}
+	var merged []diplomacy.Order
+	for _, o := range draft {
+		if !covered[o.Location] {
+			merged = append(merged, o)
+		}
+	}
+	merged = append(merged, proposed...)
+
+	inputs := make([]OrderInput, 0, len(merged))
+	for _, in := range bot.OrdersToOrderInputs(merged) {
+		inputs = append(inputs, botInputToServiceInput(in))
+	}
+	return s.SubmitOrders(ctx, gameID, userID, inputs)
+}
+
+// currentMovementOrders returns the movement orders a power has already
+// submitted this phase, or nil if it has none.
+func (s *OrderService) currentMovementOrders(ctx context.Context, gameID, power string) ([]diplomacy.Order, error) {
+	raw, err := s.cache.GetOrders(ctx, gameID, power)
+	if err != nil {
+		return nil, fmt.Errorf("get orders: %w", err)
+	}
+	if raw == nil {
+		return nil, nil
+	}
+	var orders []diplomacy.Order
+	if err := json.Unmarshal(raw, &orders); err != nil {
+		return nil, fmt.Errorf("unmarshal orders: %w", err)
+	}
+	return orders, nil
+}
+
+// canSee reports whether a message is among those visible to the user, for
+// channel messages whose visibility depends on channel membership.
+func (s *OrderService) canSee(ctx context.Context, gameID, userID, messageID string) bool {
+	messages, err := s.messageRepo.ListByGame(ctx, gameID, userID)
+	if err != nil {
+		return false
+	}
+	return slices.ContainsFunc(messages, func(m model.Message) bool { return m.ID == messageID })
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// playerUnits returns the power of userID and its starting units.
+func playerUnits(t *testing.T, gameRepo *mockGameRepo, gameID, userID string) (string, []diplomacy.Unit) {
+	t.Helper()
+	var power string
+	for _, p := range gameRepo.players[gameID] {
+		if p.UserID == userID {
+			power = p.Power
+		}
+	}
+	var units []diplomacy.Unit
+	for _, u := range diplomacy.NewInitialState().Units {
+		if string(u.Power) == power {
+			units = append(units, u)
+		}
+	}
+	return power, units
+}
+
+func TestImportProposedOrders(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	cache := newMockCache()
+	msgRepo := newMockMessageRepo()
+	gameID, _ := setupActiveGame(t, gameRepo, phaseRepo, cache)
+	orderSvc := NewOrderService(gameRepo, phaseRepo, cache)
+	orderSvc.SetMessageRepo(msgRepo)
+	ctx := context.Background()
+
+	_, units := playerUnits(t, gameRepo, gameID, "user-1")
+	drafted, proposedUnit := units[0], units[1]
+	if _, err := orderSvc.SubmitOrders(ctx, gameID, "user-1", []OrderInput{
+		{UnitType: drafted.Type.String(), Location: drafted.Province, OrderType: "hold"},
+	}); err != nil {
+		t.Fatalf("SubmitOrders: %v", err)
+	}
+
+	// Propose a hold for one of user-1's units and an order for a unit they don't own.
+	_, otherUnits := playerUnits(t, gameRepo, gameID, "user-2")
+	dson := diplomacy.FormatDSON([]diplomacy.DSONOrder{
+		{Type: diplomacy.DSONHold, UnitType: proposedUnit.Type, Location: proposedUnit.Province},
+		{Type: diplomacy.DSONHold, UnitType: otherUnits[0].Type, Location: otherUnits[0].Province},
+	})
+	msg, _ := msgRepo.Create(ctx, gameID, "user-2", "user-1", "Here's the plan: "+dson, "",
+		&model.MessageIntent{Type: "propose_orders", Orders: dson})
+
+	orders, err := orderSvc.ImportProposedOrders(ctx, gameID, "user-1", msg.ID)
+	if err != nil {
+		t.Fatalf("ImportProposedOrders: %v", err)
+	}
+	locs := map[string]bool{}
+	for _, o := range orders {
+		locs[o.Location] = true
+	}
+	if len(orders) != 2 || !locs[drafted.Province] || !locs[proposedUnit.Province] {
+		t.Errorf("expected draft and proposed order to be merged, got %+v", orders)
+	}
+}
+
+func TestImportProposedOrdersErrors(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	cache := newMockCache()
+	msgRepo := newMockMessageRepo()
+	gameID, _ := setupActiveGame(t, gameRepo, phaseRepo, cache)
+	orderSvc := NewOrderService(gameRepo, phaseRepo, cache)
+	orderSvc.SetMessageRepo(msgRepo)
+	ctx := context.Background()
+
+	plain, _ := msgRepo.Create(ctx, gameID, "user-2", "user-1", "hello", "", nil)
+	if _, err := orderSvc.ImportProposedOrders(ctx, gameID, "user-1", plain.ID); err != ErrNoOrderProposal {
+		t.Errorf("expected ErrNoOrderProposal, got %v", err)
+	}
+
+	private, _ := msgRepo.Create(ctx, gameID, "user-2", "user-3", "Here's the plan: A mun H", "",
+		&model.MessageIntent{Type: "propose_orders", Orders: "A mun H"})
+	if _, err := orderSvc.ImportProposedOrders(ctx, gameID, "user-1", private.ID); err != ErrMessageNotFound {
+		t.Errorf("expected ErrMessageNotFound for someone else's message, got %v", err)
+	}
+
+	if _, err := orderSvc.ImportProposedOrders(ctx, gameID, "user-1", "missing"); err != ErrMessageNotFound {
+		t.Errorf("expected ErrMessageNotFound, got %v", err)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/rs/zerolog/log"
+
 	"github.com/freeeve/polite-betrayal/api/internal/model"
 	"github.com/freeeve/polite-betrayal/api/internal/repository"
 	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
@@ -17,9 +19,25 @@ var (
 	ErrInvalidOrder  = errors.New("invalid order")
 )
 
-// OrderSubmission is the request payload for submitting orders.
+// OrderSubmission is the request payload for submitting orders. Ready also
+// marks the player ready once the orders are accepted.
 type OrderSubmission struct {
 	Orders []OrderInput `json:"orders"`
+	Ready  bool         `json:"ready,omitempty"`
+}
+
+// GameLocker serializes work on a game with the resolution of its phases.
+type GameLocker interface {
+	LockGame(ctx context.Context, gameID string) (func(), error)
+}
+
+// ReadyResult is the outcome of marking a player ready in one game.
+type ReadyResult struct {
+	GameID      string `json:"game_id"`
+	ReadyCount  int64  `json:"ready_count"`
+	TotalPowers int    `json:"total_powers"`
+	AllReady    bool   `json:"all_ready"`
+	Error       string `json:"error,omitempty"`
 }
 
 // OrderInput represents a single order from the client.
@@ -37,9 +55,11 @@ type OrderInput struct {
 
 // OrderService handles order submission and validation.
 type OrderService struct {
-	gameRepo  repository.GameRepository
-	phaseRepo repository.PhaseRepository
-	cache     repository.GameCache
+	gameRepo    repository.GameRepository
+	phaseRepo   repository.PhaseRepository
+	cache       repository.GameCache
+	messageRepo repository.MessageRepository // optional: enables agreement conflict hints
+	locker      GameLocker                   // optional: keeps submit-and-ready within one phase
 }
 
 // NewOrderService creates an OrderService.
@@ -47,20 +67,56 @@ func NewOrderService(gameRepo repository.GameRepository, phaseRepo repository.Ph
 	return &OrderService{gameRepo: gameRepo, phaseRepo: phaseRepo, cache: cache}
 }
 
+// SetMessageRepo configures the optional message repository used to check
+// submitted orders against accepted press agreements.
+func (s *OrderService) SetMessageRepo(repo repository.MessageRepository) {
+	s.messageRepo = repo
+}
+
 // GameRepo returns the game repository for use by handlers.
 func (s *OrderService) GameRepo() repository.GameRepository {
 	return s.gameRepo
 }
 
+// SetGameLocker configures the lock SubmitOrdersAndReady holds, so the
+// phase cannot resolve between storing the orders and marking ready.
+func (s *OrderService) SetGameLocker(l GameLocker) {
+	s.locker = l
+}
+
 // SubmitOrders validates orders and stores them in Redis for the current phase.
 // Dispatches to phase-specific validation based on the current game state phase.
 func (s *OrderService) SubmitOrders(ctx context.Context, gameID, userID string, inputs []OrderInput) ([]model.Order, error) {
-	game, err := s.gameRepo.FindByID(ctx, gameID)
+	game, power, phase, gs, err := s.playerPhase(ctx, gameID, userID)
 	if err != nil {
 		return nil, err
 	}
+
+	m := gs.Map()
+	inputs = canonicalInputs(inputs)
+
+	ordersJSON, orders, err := encodeOrders(phase.ID, power, gs, m, inputs)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.cache.SetOrders(withPhase(ctx, phase.ID), gameID, power, ordersJSON); err != nil {
+		return nil, fmt.Errorf("cache orders: %w", err)
+	}
+	if gs.Phase == diplomacy.PhaseMovement {
+		s.attachAgreementWarnings(ctx, game, phase.ID, userID, power, gs, m, inputs, orders)
+	}
+	return orders, nil
+}
+
+// playerPhase loads a game, the user's power in it, and the current phase
+// with its deserialized state.
+func (s *OrderService) playerPhase(ctx context.Context, gameID, userID string) (*model.Game, string, *model.Phase, *diplomacy.GameState, error) {
+	game, err := s.gameRepo.FindByID(ctx, gameID)
+	if err != nil {
+		return nil, "", nil, nil, err
+	}
 	if game == nil {
-		return nil, ErrGameNotFound
+		return nil, "", nil, nil, ErrGameNotFound
 	}
 
 	// Find the player's power
@@ -72,100 +128,133 @@ func (s *OrderService) SubmitOrders(ctx context.Context, gameID, userID string,
 		}
 	}
 	if power == "" {
-		return nil, ErrNotInGame
+		return nil, "", nil, nil, ErrNotInGame
 	}
 
 	// Get current phase
 	phase, err := s.phaseRepo.CurrentPhase(ctx, gameID)
 	if err != nil {
-		return nil, err
+		return nil, "", nil, nil, err
 	}
 	if phase == nil {
-		return nil, ErrNoActivePhase
+		return nil, "", nil, nil, ErrNoActivePhase
 	}
 
 	// Deserialize game state
 	var gs diplomacy.GameState
 	if err := json.Unmarshal(phase.StateBefore, &gs); err != nil {
-		return nil, fmt.Errorf("unmarshal game state: %w", err)
-	}
-
-	m := diplomacy.StandardMap()
-
-	switch gs.Phase {
-	case diplomacy.PhaseRetreat:
-		return s.submitRetreatOrders(ctx, gameID, phase.ID, power, &gs, m, inputs)
-	case diplomacy.PhaseBuild:
-		return s.submitBuildOrders(ctx, gameID, phase.ID, power, &gs, m, inputs)
-	default:
-		return s.submitMovementOrders(ctx, gameID, phase.ID, power, &gs, m, inputs)
+		return nil, "", nil, nil, fmt.Errorf("unmarshal game state: %w", err)
 	}
+	return game, power, phase, &gs, nil
 }
 
-// submitMovementOrders validates and stores movement phase orders.
-func (s *OrderService) submitMovementOrders(ctx context.Context, gameID, phaseID, power string, gs *diplomacy.GameState, m *diplomacy.DiplomacyMap, inputs []OrderInput) ([]model.Order, error) {
-	var engineOrders []diplomacy.Order
-	for _, in := range inputs {
-		o := toEngineOrder(in, diplomacy.Power(power))
-		if err := diplomacy.ValidateOrder(o, gs, m); err != nil {
-			return nil, fmt.Errorf("%w: %s", ErrInvalidOrder, err)
-		}
-		engineOrders = append(engineOrders, o)
+// attachAgreementWarnings annotates movement orders that conflict with press
+// deals made during the current phase. Failures are logged and never block submission.
+func (s *OrderService) attachAgreementWarnings(ctx context.Context, game *model.Game, phaseID, userID, power string, gs *diplomacy.GameState, m *diplomacy.DiplomacyMap, inputs []OrderInput, orders []model.Order) {
+	if s.messageRepo == nil {
+		return
 	}
-
-	ordersJSON, err := json.Marshal(engineOrders)
+	messages, err := s.messageRepo.ListByGame(ctx, game.ID, userID)
 	if err != nil {
-		return nil, fmt.Errorf("marshal orders: %w", err)
-	}
-	if err := s.cache.SetOrders(ctx, gameID, power, ordersJSON); err != nil {
-		return nil, fmt.Errorf("cache orders: %w", err)
+		log.Warn().Err(err).Str("gameId", game.ID).Msg("Failed to load messages for agreement check")
+		return
 	}
 
-	return inputsToModelOrders(phaseID, power, inputs), nil
-}
-
-// submitRetreatOrders validates and stores retreat phase orders.
-func (s *OrderService) submitRetreatOrders(ctx context.Context, gameID, phaseID, power string, gs *diplomacy.GameState, m *diplomacy.DiplomacyMap, inputs []OrderInput) ([]model.Order, error) {
-	var retreatOrders []diplomacy.RetreatOrder
-	for _, in := range inputs {
-		o := toRetreatOrder(in, diplomacy.Power(power))
-		if err := diplomacy.ValidateRetreatOrder(o, gs, m); err != nil {
-			return nil, fmt.Errorf("%w: %s", ErrInvalidOrder, err)
+	var current []model.Message
+	for _, msg := range messages {
+		if msg.PhaseID == phaseID {
+			current = append(current, msg)
 		}
-		retreatOrders = append(retreatOrders, o)
 	}
 
-	ordersJSON, err := json.Marshal(retreatOrders)
-	if err != nil {
-		return nil, fmt.Errorf("marshal retreat orders: %w", err)
-	}
-	if err := s.cache.SetOrders(ctx, gameID, power, ordersJSON); err != nil {
-		return nil, fmt.Errorf("cache retreat orders: %w", err)
+	agreements := acceptedAgreements(current, powersByUser(game))
+	if len(agreements) == 0 {
+		return
 	}
 
-	return inputsToModelOrders(phaseID, power, inputs), nil
+	engineOrders := make([]diplomacy.Order, len(inputs))
+	for i, in := range inputs {
+		engineOrders[i] = toEngineOrder(in, diplomacy.Power(power))
+	}
+	for i, w := range agreementConflicts(engineOrders, agreements, power, gs, m) {
+		orders[i].Warnings = append(orders[i].Warnings, w...)
+	}
 }
 
-// submitBuildOrders validates and stores build phase orders.
-func (s *OrderService) submitBuildOrders(ctx context.Context, gameID, phaseID, power string, gs *diplomacy.GameState, m *diplomacy.DiplomacyMap, inputs []OrderInput) ([]model.Order, error) {
-	var buildOrders []diplomacy.BuildOrder
-	for _, in := range inputs {
-		o := toBuildOrder(in, diplomacy.Power(power))
-		if err := diplomacy.ValidateBuildOrder(o, gs, m); err != nil {
-			return nil, fmt.Errorf("%w: %s", ErrInvalidOrder, err)
+// encodeOrders validates a power's orders for the current phase and returns
+// them as stored in the game cache, along with the orders to report back.
+// Legal movement orders carry warnings from analyzing them as a set.
+func encodeOrders(phaseID, power string, gs *diplomacy.GameState, m *diplomacy.DiplomacyMap, inputs []OrderInput) (json.RawMessage, []model.Order, error) {
+	p := diplomacy.Power(power)
+	var stored any
+	var engineOrders []diplomacy.Order
+	switch gs.Phase {
+	case diplomacy.PhaseRetreat:
+		var retreatOrders []diplomacy.RetreatOrder
+		for _, in := range inputs {
+			o := diplomacy.InferRetreatCoasts(toRetreatOrder(in, p), gs, m)
+			if err := diplomacy.ValidateRetreatOrder(o, gs, m); err != nil {
+				return nil, nil, fmt.Errorf("%w: %s", ErrInvalidOrder, err)
+			}
+			retreatOrders = append(retreatOrders, o)
 		}
-		buildOrders = append(buildOrders, o)
+		stored = retreatOrders
+	case diplomacy.PhaseBuild:
+		var buildOrders []diplomacy.BuildOrder
+		for _, in := range inputs {
+			o := toBuildOrder(in, p)
+			if err := diplomacy.ValidateBuildOrder(o, gs, m); err != nil {
+				return nil, nil, fmt.Errorf("%w: %s", ErrInvalidOrder, err)
+			}
+			buildOrders = append(buildOrders, o)
+		}
+		stored = buildOrders
+	default:
+		for _, in := range inputs {
+			o := toEngineOrder(in, p)
+			if err := diplomacy.ValidateOrder(o, gs, m); err != nil {
+				return nil, nil, fmt.Errorf("%w: %s", ErrInvalidOrder, err)
+			}
+			engineOrders = append(engineOrders, o)
+		}
+		stored = engineOrders
 	}
 
-	ordersJSON, err := json.Marshal(buildOrders)
+	ordersJSON, err := json.Marshal(stored)
 	if err != nil {
-		return nil, fmt.Errorf("marshal build orders: %w", err)
+		return nil, nil, fmt.Errorf("marshal orders: %w", err)
 	}
-	if err := s.cache.SetOrders(ctx, gameID, power, ordersJSON); err != nil {
-		return nil, fmt.Errorf("cache build orders: %w", err)
+	orders := inputsToModelOrders(phaseID, power, inputs)
+	for _, w := range diplomacy.AnalyzeOrders(engineOrders, gs, m) {
+		orders[w.Index].Warnings = append(orders[w.Index].Warnings, w.Message)
 	}
+	return ordersJSON, orders, nil
+}
 
-	return inputsToModelOrders(phaseID, power, inputs), nil
+// canonicalInputs returns inputs with province and coast aliases (e.g. "lyo",
+// "nat") replaced by their canonical IDs.
+func canonicalInputs(inputs []OrderInput) []OrderInput {
+	out := make([]OrderInput, len(inputs))
+	for i, in := range inputs {
+		in.Location = diplomacy.CanonicalProvince(in.Location)
+		if in.Target != "" {
+			in.Target = diplomacy.CanonicalProvince(in.Target)
+		}
+		if in.AuxLoc != "" {
+			in.AuxLoc = diplomacy.CanonicalProvince(in.AuxLoc)
+		}
+		if in.AuxTarget != "" {
+			in.AuxTarget = diplomacy.CanonicalProvince(in.AuxTarget)
+		}
+		if in.Coast != "" {
+			in.Coast = string(diplomacy.CanonicalCoast(in.Coast))
+		}
+		if in.TargetCoast != "" {
+			in.TargetCoast = string(diplomacy.CanonicalCoast(in.TargetCoast))
+		}
+		out[i] = in
+	}
+	return out
 }
 
 func inputsToModelOrders(phaseID, power string, inputs []OrderInput) []model.Order {
@@ -206,8 +295,15 @@ func (s *OrderService) MarkReady(ctx context.Context, gameID, userID string) (in
 	if power == "" {
 		return 0, 0, ErrNotInGame
 	}
+	phase, err := s.phaseRepo.CurrentPhase(ctx, gameID)
+	if err != nil {
+		return 0, 0, err
+	}
+	if phase == nil {
+		return 0, 0, ErrNoActivePhase
+	}
 
-	if err := s.cache.MarkReady(ctx, gameID, power); err != nil {
+	if err := s.cache.MarkReady(withPhase(ctx, phase.ID), gameID, power); err != nil {
 		return 0, 0, fmt.Errorf("mark ready: %w", err)
 	}
 
@@ -220,6 +316,46 @@ func (s *OrderService) MarkReady(ctx context.Context, gameID, userID string) (in
 	return readyCount, totalPowers, nil
 }
 
+// SubmitOrdersAndReady submits orders like SubmitOrders and, if they are
+// accepted, marks the player ready, returning the orders and the ready
+// count like MarkReady. With a game locker set, both happen in the same
+// phase: a deadline firing meanwhile resolves the phase after them.
+func (s *OrderService) SubmitOrdersAndReady(ctx context.Context, gameID, userID string, inputs []OrderInput) ([]model.Order, int64, int, error) {
+	if s.locker != nil {
+		unlock, err := s.locker.LockGame(ctx, gameID)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		defer unlock()
+	}
+	orders, err := s.SubmitOrders(ctx, gameID, userID, inputs)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	readyCount, total, err := s.MarkReady(ctx, gameID, userID)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return orders, readyCount, total, nil
+}
+
+// MarkReadyMany marks the player ready in each of gameIDs, reporting each
+// game's outcome separately so one failure doesn't hold up the others.
+func (s *OrderService) MarkReadyMany(ctx context.Context, userID string, gameIDs []string) []ReadyResult {
+	results := make([]ReadyResult, 0, len(gameIDs))
+	for _, gameID := range gameIDs {
+		r := ReadyResult{GameID: gameID}
+		readyCount, total, err := s.MarkReady(ctx, gameID, userID)
+		if err != nil {
+			r.Error = err.Error()
+		} else {
+			r.ReadyCount, r.TotalPowers, r.AllReady = readyCount, total, int(readyCount) >= total
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
 // UnmarkReady removes a player's ready status (e.g., when resubmitting orders).
 func (s *OrderService) UnmarkReady(ctx context.Context, gameID, userID string) error {
 	game, err := s.gameRepo.FindByID(ctx, gameID)
@@ -240,8 +376,15 @@ func (s *OrderService) UnmarkReady(ctx context.Context, gameID, userID string) e
 	if power == "" {
 		return ErrNotInGame
 	}
+	phase, err := s.phaseRepo.CurrentPhase(ctx, gameID)
+	if err != nil {
+		return err
+	}
+	if phase == nil {
+		return ErrNoActivePhase
+	}
 
-	return s.cache.UnmarkReady(ctx, gameID, power)
+	return s.cache.UnmarkReady(withPhase(ctx, phase.ID), gameID, power)
 }
 
 // GetOrders returns the orders for a phase from Postgres.
@@ -1,6 +1,10 @@
 package service
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
@@ -89,3 +93,175 @@ func TestToEngineOrderWithCoast(t *testing.T) {
 		t.Errorf("expected coast nc, got %v", order.Coast)
 	}
 }
+
+func TestCanonicalInputs(t *testing.T) {
+	inputs := canonicalInputs([]OrderInput{
+		{UnitType: "fleet", Location: "WME", OrderType: "move", Target: "lyo"},
+		{UnitType: "fleet", Location: "nat", OrderType: "support", AuxLoc: "nwg", AuxTarget: "nts", AuxUnitType: "fleet"},
+		{UnitType: "fleet", Location: "stp", Coast: "ncs", OrderType: "hold"},
+	})
+	if inputs[0].Location != "wes" || inputs[0].Target != "gol" {
+		t.Errorf("input 0 = %s -> %s, want wes -> gol", inputs[0].Location, inputs[0].Target)
+	}
+	if inputs[1].Location != "nao" || inputs[1].AuxLoc != "nrg" || inputs[1].AuxTarget != "nth" {
+		t.Errorf("input 1 = %+v, want nao S nrg - nth", inputs[1])
+	}
+	if inputs[2].Coast != "nc" {
+		t.Errorf("input 2 coast = %q, want nc", inputs[2].Coast)
+	}
+}
+
+func TestSubmitOrdersSetWarnings(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	cache := newMockCache()
+	gameID, _ := setupActiveGame(t, gameRepo, phaseRepo, cache)
+	orderSvc := NewOrderService(gameRepo, phaseRepo, cache)
+	ctx := context.Background()
+
+	// Order one of user-1's units into the province of another that holds.
+	power, units := playerUnits(t, gameRepo, gameID, "user-1")
+	gs := diplomacy.NewInitialState()
+	m := diplomacy.StandardMap()
+	var inputs []OrderInput
+	for _, from := range units {
+		for _, to := range units {
+			o := diplomacy.Order{UnitType: from.Type, Power: diplomacy.Power(power), Location: from.Province, Type: diplomacy.OrderMove, Target: to.Province}
+			if inputs == nil && from != to && diplomacy.ValidateOrder(o, gs, m) == nil {
+				inputs = []OrderInput{
+					{UnitType: from.Type.String(), Location: from.Province, OrderType: "move", Target: to.Province},
+					{UnitType: to.Type.String(), Location: to.Province, OrderType: "hold"},
+				}
+			}
+		}
+	}
+	if inputs == nil {
+		t.Fatalf("no adjacent units for %s", power)
+	}
+
+	orders, err := orderSvc.SubmitOrders(ctx, gameID, "user-1", inputs)
+	if err != nil {
+		t.Fatalf("SubmitOrders: %v", err)
+	}
+	if len(orders[0].Warnings) != 1 || !strings.Contains(orders[0].Warnings[0], "held by your own unit") {
+		t.Errorf("expected a self-bounce warning on the move, got %v", orders[0].Warnings)
+	}
+	if len(orders[1].Warnings) != 0 {
+		t.Errorf("expected no warning on the hold, got %v", orders[1].Warnings)
+	}
+}
+
+func TestSubmitRetreatOrdersCoasts(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	cache := newMockCache()
+	gameID, _ := setupActiveGame(t, gameRepo, phaseRepo, cache)
+	orderSvc := NewOrderService(gameRepo, phaseRepo, cache)
+	ctx := context.Background()
+
+	// user-1's fleet was dislodged from mao and can reach either coast of spa.
+	power, _ := playerUnits(t, gameRepo, gameID, "user-1")
+	gs := diplomacy.NewInitialState()
+	gs.Phase = diplomacy.PhaseRetreat
+	gs.Dislodged = []diplomacy.DislodgedUnit{{
+		Unit:          diplomacy.Unit{Type: diplomacy.Fleet, Power: diplomacy.Power(power), Province: "mao"},
+		DislodgedFrom: "mao",
+		AttackerFrom:  "nao",
+	}}
+	phase, _ := phaseRepo.CurrentPhase(ctx, gameID)
+	phase.StateBefore, _ = json.Marshal(gs)
+
+	retreat := OrderInput{UnitType: "fleet", Location: "mao", OrderType: "retreat_move", Target: "spa"}
+	if _, err := orderSvc.SubmitOrders(ctx, gameID, "user-1", []OrderInput{retreat}); !errors.Is(err, ErrInvalidOrder) ||
+		!strings.Contains(err.Error(), "must specify coast for spa (nc or sc)") {
+		t.Fatalf("expected a missing coast error, got %v", err)
+	}
+	retreat.TargetCoast = "sc"
+	if _, err := orderSvc.SubmitOrders(ctx, gameID, "user-1", []OrderInput{retreat}); err != nil {
+		t.Fatalf("retreat to spa/sc: %v", err)
+	}
+
+	// From the Gulf of Lyon only the south coast is reachable.
+	gs.Dislodged[0].DislodgedFrom, gs.Dislodged[0].Unit.Province, gs.Dislodged[0].AttackerFrom = "gol", "gol", "tys"
+	phase.StateBefore, _ = json.Marshal(gs)
+	retreat = OrderInput{UnitType: "fleet", Location: "gol", OrderType: "retreat_move", Target: "spa"}
+	if _, err := orderSvc.SubmitOrders(ctx, gameID, "user-1", []OrderInput{retreat}); err != nil {
+		t.Fatalf("retreat to spa from gol: %v", err)
+	}
+	cached, _ := cache.GetAllOrders(ctx, gameID, []string{power})
+	var stored []diplomacy.RetreatOrder
+	json.Unmarshal(cached[power], &stored)
+	if len(stored) != 1 || stored[0].TargetCoast != diplomacy.SouthCoast {
+		t.Errorf("expected the south coast inferred, got %+v", stored)
+	}
+}
+
+// recordingLocker is a GameLocker that records whether it is held.
+type recordingLocker struct {
+	held, taken bool
+}
+
+func (l *recordingLocker) LockGame(_ context.Context, _ string) (func(), error) {
+	l.held, l.taken = true, true
+	return func() { l.held = false }, nil
+}
+
+func TestSubmitOrdersAndReady(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	cache := newMockCache()
+	gameID, _ := setupActiveGame(t, gameRepo, phaseRepo, cache)
+	orderSvc := NewOrderService(gameRepo, phaseRepo, cache)
+	locker := &recordingLocker{}
+	orderSvc.SetGameLocker(locker)
+	ctx := context.Background()
+
+	power, units := playerUnits(t, gameRepo, gameID, "user-1")
+	var bad []OrderInput
+	for _, u := range diplomacy.NewInitialState().Units {
+		if string(u.Power) != power {
+			bad = []OrderInput{{UnitType: u.Type.String(), Location: u.Province, OrderType: "hold"}}
+			break
+		}
+	}
+	if _, _, _, err := orderSvc.SubmitOrdersAndReady(ctx, gameID, "user-1", bad); err == nil {
+		t.Fatal("expected an order for someone else's unit to be rejected")
+	}
+	if cache.ready[gameID][power] {
+		t.Fatal("a rejected submission must not mark the player ready")
+	}
+
+	inputs := []OrderInput{{UnitType: units[0].Type.String(), Location: units[0].Province, OrderType: "hold"}}
+	orders, readyCount, total, err := orderSvc.SubmitOrdersAndReady(ctx, gameID, "user-1", inputs)
+	if err != nil {
+		t.Fatalf("SubmitOrdersAndReady: %v", err)
+	}
+	if len(orders) != 1 || readyCount != 1 || total != 7 {
+		t.Errorf("expected 1 order and 1 of 7 ready, got %d orders, %d of %d", len(orders), readyCount, total)
+	}
+	if cached, _ := cache.GetOrders(ctx, gameID, power); cached == nil || !cache.ready[gameID][power] {
+		t.Error("expected the orders cached and the player ready")
+	}
+	if !locker.taken || locker.held {
+		t.Errorf("expected the game lock taken and released, taken %v held %v", locker.taken, locker.held)
+	}
+}
+
+func TestMarkReadyMany(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	cache := newMockCache()
+	gameID, _ := setupActiveGame(t, gameRepo, phaseRepo, cache)
+	orderSvc := NewOrderService(gameRepo, phaseRepo, cache)
+
+	results := orderSvc.MarkReadyMany(context.Background(), "user-1", []string{gameID, "missing"})
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if r := results[0]; r.GameID != gameID || r.Error != "" || r.ReadyCount != 1 || r.TotalPowers != 7 || r.AllReady {
+		t.Errorf("unexpected result for the active game: %+v", r)
+	}
+	if r := results[1]; r.GameID != "missing" || !strings.Contains(r.Error, ErrGameNotFound.Error()) {
+		t.Errorf("expected game not found for the missing game, got %+v", r)
+	}
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+)
+
+// ErrGameNotPaused is returned when resuming a game that isn't paused.
+var ErrGameNotPaused = errors.New("game is not paused")
+
+// minResumeTime is the least time players get after a resume, so a game
+// paused in its last seconds doesn't resolve the moment it resumes.
+const minResumeTime = time.Minute
+
+// PauseGame suspends an active game: its timer stops with the time left
+// stored, and the phase can't resolve until ResumeGame. Only the creator can
+// pause a game.
+func (s *PhaseService) PauseGame(ctx context.Context, gameID, userID string) (*model.Game, error) {
+	s.ensureRecovered(ctx, gameID)
+	unlock, err := s.lockGame(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	game, err := s.gameRepo.FindByID(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	if game == nil {
+		return nil, ErrGameNotFound
+	}
+	if game.CreatorID != userID {
+		return nil, ErrNotCreator
+	}
+	if game.Status != "active" {
+		return nil, ErrGameNotActive
+	}
+
+	remaining, ok, err := s.cache.PauseTimer(ctx, gameID)
+	if err != nil {
+		return nil, fmt.Errorf("pause timer: %w", err)
+	}
+	if !ok {
+		// The timer already fired or was lost; keep what the stored deadline allows.
+		phase, err := s.phaseRepo.CurrentPhase(ctx, gameID)
+		if err != nil {
+			return nil, err
+		}
+		if phase != nil {
+			remaining = max(time.Until(phase.Deadline), 0)
+		}
+	}
+	// A deadline pulled forward by a ready quorum doesn't survive the pause;
+	// ResumeGame applies the quorum again if it still holds.
+	if original, ok, err := s.cache.ClearQuorumDeadline(ctx, gameID); err != nil {
+		return nil, err
+	} else if ok {
+		remaining = max(time.Until(original), 0)
+	}
+	if err := s.gameRepo.SetPaused(ctx, gameID, true); err != nil {
+		return nil, err
+	}
+	game.Status = "paused"
+	s.broadcaster.BroadcastGameEvent(gameID, "game_paused", map[string]any{
+		"remaining_seconds": int(remaining.Seconds()),
+	})
+	return game, nil
+}
+
+// ResumeGame restarts a paused game's timer with the time that was left
+// when it paused, at least minResumeTime, and moves the phase deadline to
+// match. Only the creator can resume a game.
+func (s *PhaseService) ResumeGame(ctx context.Context, gameID, userID string) (*model.Game, error) {
+	unlock, err := s.lockGame(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	game, err := s.gameRepo.FindByID(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	if game == nil {
+		return nil, ErrGameNotFound
+	}
+	if game.CreatorID != userID {
+		return nil, ErrNotCreator
+	}
+	if game.Status != "paused" {
+		return nil, ErrGameNotPaused
+	}
+	phase, err := s.phaseRepo.CurrentPhase(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	if phase == nil {
+		return nil, fmt.Errorf("paused game %s has no current phase", gameID)
+	}
+
+	remaining, ok, err := s.cache.ResumeTimer(ctx, gameID)
+	if err != nil {
+		return nil, fmt.Errorf("resume timer: %w", err)
+	}
+	if !ok {
+		// Redis lost the paused timer; fall back to the stored deadline.
+		remaining = time.Until(phase.Deadline)
+	}
+	deadline := time.Now().Add(max(remaining, minResumeTime)).Truncate(time.Second)
+	if err := s.phaseRepo.UpdateDeadline(ctx, phase.ID, deadline); err != nil {
+		return nil, err
+	}
+	if err := s.gameRepo.SetPaused(ctx, gameID, false); err != nil {
+		return nil, err
+	}
+	game.Status = "active"
+
+	state, err := s.cache.GetGameState(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		// The live state was lost while paused; rebuild it like a restart would.
+		s.recoverGame(ctx, *game)
+	} else if err := s.cache.SetTimer(ctx, gameID, deadline); err != nil {
+		return nil, fmt.Errorf("set timer: %w", err)
+	}
+
+	s.broadcaster.BroadcastGameEvent(gameID, "game_resumed", map[string]any{
+		"deadline":          deadline,
+		"deadline_epoch_ms": deadline.UnixMilli(),
+	})
+	phase.Deadline = deadline
+	if err := s.applyReadyQuorum(ctx, game, phase); err != nil {
+		log.Error().Err(err).Str("gameId", gameID).Msg("Failed to apply ready quorum on resume")
+	}
+	return game, nil
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPauseAndResumeGame(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	cache := newMockCache()
+	bc := &recordingBroadcaster{}
+	phaseSvc := NewPhaseService(gameRepo, phaseRepo, cache, bc)
+	ctx := context.Background()
+
+	gameID, _ := setupActiveGame(t, gameRepo, phaseRepo, cache)
+	cache.timers[gameID] = time.Now().Add(2 * time.Hour)
+
+	if _, err := phaseSvc.PauseGame(ctx, gameID, "user-2"); !errors.Is(err, ErrNotCreator) {
+		t.Fatalf("expected ErrNotCreator, got %v", err)
+	}
+	game, err := phaseSvc.PauseGame(ctx, gameID, "user-1")
+	if err != nil {
+		t.Fatalf("PauseGame: %v", err)
+	}
+	if game.Status != "paused" || gameRepo.games[gameID].Status != "paused" {
+		t.Errorf("expected the game to be paused, got %q", gameRepo.games[gameID].Status)
+	}
+	if _, ok := cache.timers[gameID]; ok {
+		t.Error("expected the timer to be suspended")
+	}
+	if len(bc.eventsOfType("game_paused")) != 1 {
+		t.Error("expected a game_paused event")
+	}
+	if _, err := phaseSvc.PauseGame(ctx, gameID, "user-1"); !errors.Is(err, ErrGameNotActive) {
+		t.Errorf("expected pausing twice to fail with ErrGameNotActive, got %v", err)
+	}
+
+	// A paused game never resolves.
+	phase, _ := phaseRepo.CurrentPhase(ctx, gameID)
+	phase.Deadline = time.Now().Add(-time.Hour)
+	if err := phaseSvc.ResolvePhaseEarly(ctx, gameID); err != nil {
+		t.Fatalf("ResolvePhaseEarly: %v", err)
+	}
+	if phase.ResolvedAt != nil {
+		t.Fatal("expected the paused phase not to resolve")
+	}
+
+	game, err = phaseSvc.ResumeGame(ctx, gameID, "user-1")
+	if err != nil {
+		t.Fatalf("ResumeGame: %v", err)
+	}
+	if game.Status != "active" || gameRepo.games[gameID].Status != "active" {
+		t.Errorf("expected the game to be active again, got %q", gameRepo.games[gameID].Status)
+	}
+	left := time.Until(phase.Deadline)
+	if left < 119*time.Minute || left > 2*time.Hour {
+		t.Errorf("expected about 2h left after resuming, got %v", left)
+	}
+	if !cache.timers[gameID].Equal(phase.Deadline) {
+		t.Errorf("expected the timer to match the new deadline, got %v vs %v", cache.timers[gameID], phase.Deadline)
+	}
+	if _, err := phaseSvc.ResumeGame(ctx, gameID, "user-1"); !errors.Is(err, ErrGameNotPaused) {
+		t.Errorf("expected ErrGameNotPaused, got %v", err)
+	}
+}
+
+func TestResumeGivesMinimumTime(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	cache := newMockCache()
+	phaseSvc := NewPhaseService(gameRepo, phaseRepo, cache, nil)
+	ctx := context.Background()
+
+	gameID, _ := setupActiveGame(t, gameRepo, phaseRepo, cache)
+	cache.timers[gameID] = time.Now().Add(3 * time.Second)
+	if _, err := phaseSvc.PauseGame(ctx, gameID, "user-1"); err != nil {
+		t.Fatalf("PauseGame: %v", err)
+	}
+	if _, err := phaseSvc.ResumeGame(ctx, gameID, "user-1"); err != nil {
+		t.Fatalf("ResumeGame: %v", err)
+	}
+	phase, _ := phaseRepo.CurrentPhase(ctx, gameID)
+	if left := time.Until(phase.Deadline); left < minResumeTime-time.Second {
+		t.Errorf("expected at least %v after resuming, got %v", minResumeTime, left)
+	}
+}
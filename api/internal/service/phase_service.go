@@ -3,13 +3,18 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/rs/zerolog/log"
 
 	"github.com/freeeve/polite-betrayal/api/internal/bot"
+	"github.com/freeeve/polite-betrayal/api/internal/jobs"
 	"github.com/freeeve/polite-betrayal/api/internal/model"
 	"github.com/freeeve/polite-betrayal/api/internal/repository"
 	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
@@ -22,16 +27,34 @@ const phaseGracePeriod = 5 * time.Second
 // PhaseService orchestrates phase transitions: resolution, state advancement,
 // and timer management for the async turn system.
 type PhaseService struct {
-	gameRepo    repository.GameRepository
-	phaseRepo   repository.PhaseRepository
-	cache       repository.GameCache
-	broadcaster Broadcaster
-	messageRepo repository.MessageRepository // optional: enables bot diplomacy messages
+	gameRepo     repository.GameRepository
+	phaseRepo    repository.PhaseRepository
+	cache        repository.GameCache
+	broadcaster  Broadcaster
+	messageRepo  repository.MessageRepository // optional: enables bot diplomacy messages
+	userRepo     repository.UserRepository    // optional: renders bot press in the recipient's locale
+	achievements *AchievementService          // optional: awards achievements when games end
+	analysis     *AnalysisService             // optional: evaluates replays when games end
+	summaries    *SummaryService              // optional: builds summaries when games end
+	ratings      *RatingService               // optional: updates Elo ratings when games end
+	compute      *ComputeService              // optional: accounts and caps bot compute time
+	notifier     *NotificationService         // optional: notifies players when phases resolve
+	games        *GameService                 // optional: tracks missed deadlines for civil disorder
+	jobs         *jobs.Queue                  // optional: runs post-game work from the job queue
+	alliances    repository.AllianceStore     // optional: carries bot pacts and trust across phases
+	jitter       time.Duration                // max random delay added to phase deadlines
 
 	// gameLocks prevents concurrent phase resolution for the same game.
 	// Both the keyspace listener and poller can fire simultaneously;
 	// without locking, both resolve the same phase creating duplicate next phases.
-	gameLocks sync.Map
+	// With a lease store set, each lock is also held across server instances.
+	gameLocks  sync.Map
+	leases     repository.LeaseStore // optional: locks games across replicas
+	leaseOwner string
+
+	// pendingRecovery holds games left unrecovered when the startup recovery
+	// budget ran out; they are recovered on first access instead.
+	pendingRecovery sync.Map
 }
 
 // SetMessageRepo configures the optional message repository for bot diplomacy.
@@ -39,108 +62,264 @@ func (s *PhaseService) SetMessageRepo(repo repository.MessageRepository) {
 	s.messageRepo = repo
 }
 
-// NewPhaseService creates a PhaseService.
-func NewPhaseService(
-	gameRepo repository.GameRepository,
-	phaseRepo repository.PhaseRepository,
-	cache repository.GameCache,
-	broadcaster Broadcaster,
-) *PhaseService {
-	if broadcaster == nil {
-		broadcaster = NoopBroadcaster{}
-	}
-	return &PhaseService{
-		gameRepo:    gameRepo,
-		phaseRepo:   phaseRepo,
-		cache:       cache,
-		broadcaster: broadcaster,
-	}
+// SetDeadlineJitter spreads next-phase deadlines by up to d so games that
+// resolved together don't expire together again.
+func (s *PhaseService) SetDeadlineJitter(d time.Duration) {
+	s.jitter = d
+}
+
+// SetUserRepo configures the optional user repository used to look up the
+// locale bot press is rendered in.
+func (s *PhaseService) SetUserRepo(repo repository.UserRepository) {
+	s.userRepo = repo
 }
 
-// RecoverActiveGames rehydrates Redis state for all active games from Postgres.
-// Called on server startup to restore timers and game state lost during a restart.
-func (s *PhaseService) RecoverActiveGames(ctx context.Context) error {
-	games, err := s.gameRepo.ListActive(ctx)
+// SetAchievementService configures the optional service that awards
+// achievements when a game ends.
+func (s *PhaseService) SetAchievementService(svc *AchievementService) {
+	s.achievements = svc
+}
+
+// awardAchievements evaluates a just-finished game. Failures are logged and
+// never block the game from ending.
+func (s *PhaseService) awardAchievements(ctx context.Context, gameID string) {
+	if s.achievements == nil {
+		return
+	}
+	awarded, err := s.achievements.EvaluateGame(ctx, gameID)
 	if err != nil {
-		return fmt.Errorf("list active games: %w", err)
+		log.Warn().Err(err).Str("gameId", gameID).Msg("Failed to evaluate achievements")
+		return
 	}
-	if len(games) == 0 {
-		log.Info().Msg("No active games to recover")
-		return nil
+	for _, a := range awarded {
+		log.Info().Str("gameId", gameID).Str("userId", a.UserID).Str("kind", a.Kind).Msg("Achievement awarded")
 	}
+}
 
-	log.Info().Int("count", len(games)).Msg("Recovering active games after restart")
+// SetAnalysisService configures the optional service that evaluates every
+// phase of a game for replays once it ends.
+func (s *PhaseService) SetAnalysisService(svc *AnalysisService) {
+	s.analysis = svc
+}
 
-	for _, game := range games {
-		phase, err := s.phaseRepo.CurrentPhase(ctx, game.ID)
-		if err != nil {
-			log.Error().Err(err).Str("gameId", game.ID).Msg("Failed to get current phase during recovery")
-			continue
-		}
-		if phase == nil {
-			log.Warn().Str("gameId", game.ID).Msg("Active game has no current phase, skipping")
-			continue
-		}
+// SetJobQueue configures the optional job queue that post-game work is
+// enqueued into, so it survives restarts and is retried on failure.
+// Without one that work runs in a goroutine.
+func (s *PhaseService) SetJobQueue(q *jobs.Queue) {
+	s.jobs = q
+}
 
-		powers := activePowers(&game)
+// SetAllianceStore configures the optional store that carries each bot's
+// pacts and trust from phase to phase, so bots that search with them can
+// honor agreements and notice betrayals.
+func (s *PhaseService) SetAllianceStore(store repository.AllianceStore) {
+	s.alliances = store
+}
 
-		// Rehydrate game state from the phase's state_before
-		if err := s.cache.SetGameState(ctx, game.ID, phase.StateBefore); err != nil {
-			log.Error().Err(err).Str("gameId", game.ID).Msg("Failed to restore game state")
-			continue
+// loadAlliances returns the alliance state of the bot playing power, or nil
+// if there is no store or it cannot be read. It is a snapshot for the bot to
+// search with; changes are saved through updateAlliances.
+func (s *PhaseService) loadAlliances(ctx context.Context, gameID, power string) *bot.AllianceState {
+	if s.alliances == nil {
+		return nil
+	}
+	data, err := s.alliances.BotAlliances(ctx, gameID, power)
+	if err == nil {
+		var state *bot.AllianceState
+		if state, err = bot.DecodeAllianceState(data); err == nil {
+			return state
 		}
+	}
+	log.Warn().Err(err).Str("gameId", gameID).Str("power", power).Msg("Failed to load bot alliances")
+	return nil
+}
 
-		// Restore timer if deadline is still in the future
-		if time.Now().Before(phase.Deadline) {
-			if err := s.cache.SetTimer(ctx, game.ID, phase.Deadline); err != nil {
-				log.Error().Err(err).Str("gameId", game.ID).Msg("Failed to restore timer")
-			}
+// updateAlliances applies update to the alliance state of the bot playing
+// power. The store applies it atomically, so press handling and order
+// generation running at once don't lose each other's changes.
+func (s *PhaseService) updateAlliances(ctx context.Context, gameID, power string, update func(*bot.AllianceState)) {
+	if s.alliances == nil {
+		return
+	}
+	err := s.alliances.UpdateBotAlliances(ctx, gameID, power, func(data []byte) ([]byte, error) {
+		state, err := bot.DecodeAllianceState(data)
+		if err != nil {
+			return nil, err
 		}
+		update(state)
+		return json.Marshal(state)
+	})
+	if err != nil {
+		log.Warn().Err(err).Str("gameId", gameID).Str("power", power).Msg("Failed to update bot alliances")
+	}
+}
 
-		// Auto-ready eliminated powers
-		var gs diplomacy.GameState
-		if err := json.Unmarshal(phase.StateBefore, &gs); err != nil {
-			log.Error().Err(err).Str("gameId", game.ID).Msg("Failed to unmarshal state for recovery")
-			continue
+// analyzeGame starts the replay analysis of a just-finished game in the
+// background; it can take a while and must not hold up the game ending.
+func (s *PhaseService) analyzeGame(gameID string) {
+	if s.analysis == nil {
+		return
+	}
+	if s.jobs != nil {
+		_, err := s.jobs.Enqueue(context.Background(), JobAnalyzeGame, analyzeGamePayload{GameID: gameID})
+		if err == nil {
+			return
 		}
-		if err := s.autoReadyEliminatedPowers(ctx, game.ID, &gs, powers); err != nil {
-			log.Warn().Err(err).Str("gameId", game.ID).Msg("Failed to auto-ready eliminated powers during recovery")
+		log.Warn().Err(err).Str("gameId", gameID).Msg("Failed to enqueue game analysis, running it now")
+	}
+	go func() {
+		if err := s.analysis.AnalyzeGame(context.Background(), gameID); err != nil {
+			log.Warn().Err(err).Str("gameId", gameID).Msg("Failed to analyze game")
+			return
 		}
+		log.Info().Str("gameId", gameID).Msg("Game analysis stored")
+	}()
+}
 
-		// Submit bot orders in a background goroutine
-		gameCopy := game
-		go func() {
-			botCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-			defer cancel()
-			if err := s.SubmitBotOrders(botCtx, gameCopy.ID); err != nil {
-				log.Error().Err(err).Str("gameId", gameCopy.ID).Msg("Failed to submit bot orders during recovery")
-			}
-		}()
+// SetSummaryService configures the optional service that builds the
+// end-of-game summary.
+func (s *PhaseService) SetSummaryService(svc *SummaryService) {
+	s.summaries = svc
+}
 
-		log.Info().Str("gameId", game.ID).Str("phase", phase.PhaseType).
-			Int("year", phase.Year).Str("season", phase.Season).
-			Time("deadline", phase.Deadline).
-			Msg("Recovered game state")
+// summarizeGame builds the summary of a just-finished game so it is ready
+// by the time clients see game_ended. Failures are logged; the summary is
+// then generated on first request instead.
+func (s *PhaseService) summarizeGame(ctx context.Context, gameID string) {
+	if s.summaries == nil {
+		return
 	}
+	if _, err := s.summaries.GenerateSummary(ctx, gameID); err != nil {
+		log.Warn().Err(err).Str("gameId", gameID).Msg("Failed to generate game summary")
+	}
+}
 
-	return nil
+// SetRatingService configures the optional service that updates player and
+// bot strategy ratings when a game ends.
+func (s *PhaseService) SetRatingService(svc *RatingService) {
+	s.ratings = svc
+}
+
+// rateGame updates ratings from a just-finished game. Failures are logged
+// and never block the game from ending.
+func (s *PhaseService) rateGame(ctx context.Context, gameID string) {
+	if s.ratings == nil {
+		return
+	}
+	if _, err := s.ratings.RateGame(ctx, gameID); err != nil {
+		log.Warn().Err(err).Str("gameId", gameID).Msg("Failed to update ratings")
+	}
+}
+
+// SetComputeService configures the optional service that records how long
+// bots spend on each phase and downgrades bots in games over budget.
+func (s *PhaseService) SetComputeService(svc *ComputeService) {
+	s.compute = svc
+}
+
+// recordBotCompute stores a phase's bot compute records and announces any
+// bots downgraded for exceeding the game's budget. Failures are logged and
+// never block the phase.
+func (s *PhaseService) recordBotCompute(ctx context.Context, game *model.Game, records []model.BotCompute) {
+	if s.compute == nil || len(records) == 0 {
+		return
+	}
+	downgraded, err := s.compute.RecordPhase(ctx, game, records)
+	if err != nil {
+		log.Warn().Err(err).Str("gameId", game.ID).Msg("Failed to record bot compute")
+	}
+	if len(downgraded) > 0 {
+		log.Info().Str("gameId", game.ID).Interface("downgraded", downgraded).Msg("Bot compute budget exceeded, downgrading bots")
+		s.broadcaster.BroadcastGameEvent(game.ID, "bots_downgraded", map[string]any{
+			"powers": downgraded,
+		})
+	}
+}
+
+// SetNotificationService configures the optional service that notifies
+// players over their registered channels when a phase resolves.
+func (s *PhaseService) SetNotificationService(svc *NotificationService) {
+	s.notifier = svc
+}
+
+// notifyPhaseResolved notifies the game's players that a phase resolved, in
+// the background so channel lookups don't hold up the game. next is nil
+// when the game ended.
+func (s *PhaseService) notifyPhaseResolved(game *model.Game, resolved, next *model.Phase, cal diplomacy.Calendar) {
+	if s.notifier == nil {
+		return
+	}
+	go func() {
+		if err := s.notifier.PhaseResolved(context.Background(), game, resolved, next, cal); err != nil {
+			log.Warn().Err(err).Str("gameId", game.ID).Msg("Failed to notify players of resolved phase")
+		}
+	}()
+}
+
+// gameEnded runs the end-of-game hooks after a game is marked finished.
+func (s *PhaseService) gameEnded(ctx context.Context, gameID string) {
+	s.awardAchievements(ctx, gameID)
+	s.rateGame(ctx, gameID)
+	s.summarizeGame(ctx, gameID)
+	s.analyzeGame(gameID)
+}
+
+// broadcastGameEnded tells the game's subscribers and the lobby that the
+// game is over.
+func (s *PhaseService) broadcastGameEnded(gameID string, data map[string]any) {
+	s.broadcaster.BroadcastGameEvent(gameID, "game_ended", data)
+	s.broadcaster.BroadcastLobbyEvent(gameID, "game_finished", data)
+}
+
+// NewPhaseService creates a PhaseService.
+func NewPhaseService(
+	gameRepo repository.GameRepository,
+	phaseRepo repository.PhaseRepository,
+	cache repository.GameCache,
+	broadcaster Broadcaster,
+) *PhaseService {
+	if broadcaster == nil {
+		broadcaster = NoopBroadcaster{}
+	}
+	return &PhaseService{
+		gameRepo:    gameRepo,
+		phaseRepo:   phaseRepo,
+		cache:       cache,
+		broadcaster: broadcaster,
+	}
 }
 
 // ReadyCount returns the number of powers that have marked ready for the current phase.
 func (s *PhaseService) ReadyCount(ctx context.Context, gameID string) (int, error) {
+	s.ensureRecovered(ctx, gameID)
 	count, err := s.cache.ReadyCount(ctx, gameID)
 	return int(count), err
 }
 
 // DrawVoteCount returns the current number of draw votes for a game.
 func (s *PhaseService) DrawVoteCount(ctx context.Context, gameID string) (int, error) {
+	s.ensureRecovered(ctx, gameID)
 	count, err := s.cache.DrawVoteCount(ctx, gameID)
 	return int(count), err
 }
 
+// DrawVotePowers returns the powers currently voting for a draw, sorted.
+// Votes only last for the phase they were cast in.
+func (s *PhaseService) DrawVotePowers(ctx context.Context, gameID string) ([]string, error) {
+	s.ensureRecovered(ctx, gameID)
+	powers, err := s.cache.DrawVotePowers(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(powers)
+	return powers, nil
+}
+
 // VoteForDraw records a power's draw vote. If all alive powers have voted,
-// the game ends as a draw.
+// the game ends as a draw. The vote is cleared when the phase resolves, so
+// powers that still want a draw must vote again in each new phase.
 func (s *PhaseService) VoteForDraw(ctx context.Context, gameID, power string) error {
+	s.ensureRecovered(ctx, gameID)
 	if err := s.cache.AddDrawVote(ctx, gameID, power); err != nil {
 		return fmt.Errorf("add draw vote: %w", err)
 	}
@@ -179,7 +358,8 @@ func (s *PhaseService) VoteForDraw(ctx context.Context, gameID, power string) er
 		if err := s.gameRepo.SetFinished(ctx, gameID, ""); err != nil {
 			return fmt.Errorf("set finished (draw): %w", err)
 		}
-		s.broadcaster.BroadcastGameEvent(gameID, "game_ended", map[string]any{
+		s.gameEnded(ctx, gameID)
+		s.broadcastGameEnded(gameID, map[string]any{
 			"winner": "draw",
 		})
 		return s.cache.DeleteGameData(ctx, gameID, powers)
@@ -190,6 +370,7 @@ func (s *PhaseService) VoteForDraw(ctx context.Context, gameID, power string) er
 
 // RemoveDrawVote removes a power's draw vote and broadcasts the update.
 func (s *PhaseService) RemoveDrawVote(ctx context.Context, gameID, power string) error {
+	s.ensureRecovered(ctx, gameID)
 	if err := s.cache.RemoveDrawVote(ctx, gameID, power); err != nil {
 		return fmt.Errorf("remove draw vote: %w", err)
 	}
@@ -236,12 +417,64 @@ func alivePowers(gs *diplomacy.GameState, powers []string) []string {
 	return alive
 }
 
+// SetLeaseStore makes game locks hold a lease in store as owner, which must
+// be unique among the servers sharing it, so several API replicas never
+// work on the same game at once.
+func (s *PhaseService) SetLeaseStore(store repository.LeaseStore, owner string) {
+	s.leases = store
+	s.leaseOwner = owner
+}
+
 // gameLock returns the mutex for a given game ID.
 func (s *PhaseService) gameLock(gameID string) *sync.Mutex {
 	v, _ := s.gameLocks.LoadOrStore(gameID, &sync.Mutex{})
 	return v.(*sync.Mutex)
 }
 
+// LockGame takes the lock phase resolution holds for the game, so work done
+// under it lands entirely before or after a resolution. It returns the func
+// that releases the lock.
+func (s *PhaseService) LockGame(ctx context.Context, gameID string) (func(), error) {
+	return s.lockGame(ctx, gameID)
+}
+
+// lockGame takes the game's lock, and its lease when a lease store is set,
+// and returns the func that releases them.
+func (s *PhaseService) lockGame(ctx context.Context, gameID string) (func(), error) {
+	_, unlock, err := s.lockGameFenced(ctx, gameID)
+	return unlock, err
+}
+
+// lockGameFenced is lockGame for work that writes the game's shared state.
+// The context it returns is canceled if the game's lease is lost while
+// held, so a replica that lost it stops before it can overwrite the work of
+// the replica that took it over.
+func (s *PhaseService) lockGameFenced(ctx context.Context, gameID string) (context.Context, func(), error) {
+	mu := s.gameLock(gameID)
+	mu.Lock()
+	if s.leases == nil {
+		return ctx, mu.Unlock, nil
+	}
+	l, err := waitLease(ctx, s.leases, gameLeaseKey(gameID), s.leaseOwner)
+	if err != nil {
+		mu.Unlock()
+		return nil, nil, fmt.Errorf("lock game: %w", err)
+	}
+	fenced, cancel := context.WithCancelCause(ctx)
+	go func() {
+		select {
+		case <-l.Lost():
+			cancel(ErrLeaseLost)
+		case <-fenced.Done():
+		}
+	}()
+	return fenced, func() {
+		cancel(nil)
+		l.Release()
+		mu.Unlock()
+	}, nil
+}
+
 // InitializeGame sets up Redis state and timer when a game starts.
 // Called after StartGame assigns powers and creates the first phase.
 func (s *PhaseService) InitializeGame(ctx context.Context, gameID string, state *diplomacy.GameState, deadline time.Time) error {
@@ -261,6 +494,39 @@ func (s *PhaseService) InitializeGame(ctx context.Context, gameID string, state
 // SubmitBotOrders generates and submits orders for all bot powers in a game,
 // marks them ready, and triggers resolution if all powers are ready.
 func (s *PhaseService) SubmitBotOrders(ctx context.Context, gameID string) error {
+	return s.submitBotOrders(ctx, gameID, "")
+}
+
+// RegenerateBotOrders replaces the orders a bot power submitted this phase
+// with fresh ones from its current strategy, after a change of difficulty.
+// The bot is unready while its new orders are generated, so the phase
+// can't resolve early on the old ones; it does not press or vote again.
+// Like SubmitBotOrders it does nothing unless the game is active.
+func (s *PhaseService) RegenerateBotOrders(ctx context.Context, gameID, power string) error {
+	return s.submitBotOrders(ctx, gameID, power)
+}
+
+// botSubmitMinReserve and botSubmitReserveShare set how much of the time
+// left for bot orders is kept back from the search: the larger of the two.
+const (
+	botSubmitMinReserve   = 3 * time.Second
+	botSubmitReserveShare = 5 // a fifth of the time left
+)
+
+// botSubmitReserve returns how much of the time left before the caller's
+// deadline to keep back from the search, for the work that follows it:
+// submitting and readying orders, bot press, compute accounting, the ready
+// quorum and early resolution. It never takes more than half, so a short
+// deadline still leaves the search some time.
+func botSubmitReserve(left time.Duration) time.Duration {
+	reserve := max(botSubmitMinReserve, left/botSubmitReserveShare)
+	return min(reserve, left/2)
+}
+
+// submitBotOrders generates and submits orders for the game's bot powers,
+// or only the given one.
+func (s *PhaseService) submitBotOrders(ctx context.Context, gameID, only string) error {
+	s.ensureRecovered(ctx, gameID)
 	game, err := s.gameRepo.FindByID(ctx, gameID)
 	if err != nil || game == nil {
 		return fmt.Errorf("find game for bot orders: %w", err)
@@ -273,19 +539,33 @@ func (s *PhaseService) SubmitBotOrders(ctx context.Context, gameID string) error
 	if err != nil || phase == nil {
 		return fmt.Errorf("get current phase for bot orders: %w", err)
 	}
+	ctx = withPhase(ctx, phase.ID)
+	if only != "" {
+		if err := s.cache.UnmarkReady(ctx, gameID, only); err != nil {
+			return fmt.Errorf("unmark bot ready for %s: %w", only, err)
+		}
+	}
 
 	var gs diplomacy.GameState
 	if err := json.Unmarshal(phase.StateBefore, &gs); err != nil {
 		return fmt.Errorf("unmarshal state for bot orders: %w", err)
 	}
 
-	m := diplomacy.StandardMap()
+	m := gs.Map()
 
 	// Build per-bot strategy map from player records
 	botStrategies := make(map[string]bot.Strategy)
 	for _, p := range game.Players {
-		if p.IsBot && p.Power != "" {
-			botStrategies[p.Power] = bot.StrategyForDifficulty(p.BotDifficulty)
+		if p.IsBot && p.Power != "" && (only == "" || p.Power == only) {
+			strategy := bot.NewStrategyForMap(p.BotDifficulty, nil, m)
+			if p.BotPersonality != nil {
+				// A personality set before a change to a strategy without one
+				// no longer applies.
+				if _, err := bot.WithPersonality(strategy, bot.Personality(*p.BotPersonality)); err != nil {
+					log.Debug().Err(err).Str("gameId", gameID).Str("power", p.Power).Msg("Bot personality ignored")
+				}
+			}
+			botStrategies[p.Power] = strategy
 		}
 	}
 
@@ -293,7 +573,13 @@ func (s *PhaseService) SubmitBotOrders(ctx context.Context, gameID string) error
 		return nil
 	}
 
-	// Time budgets are handled internally by each strategy.
+	// Bots may search until the caller's deadline, less the time reserved
+	// for saving their orders and press, recording compute and resolving
+	// early. Without one, strategies use their default fixed effort.
+	var budget bot.SearchBudget
+	if d, ok := ctx.Deadline(); ok {
+		budget.Deadline = d.Add(-botSubmitReserve(time.Until(d)))
+	}
 
 	// Generate orders for all bots concurrently.
 	// Order generation is pure computation (reads game state, no I/O).
@@ -301,6 +587,7 @@ func (s *PhaseService) SubmitBotOrders(ctx context.Context, gameID string) error
 		power      string
 		strategy   bot.Strategy
 		ordersJSON []byte
+		compute    time.Duration
 		err        error
 	}
 	resultsCh := make(chan botResult, len(botStrategies))
@@ -308,42 +595,95 @@ func (s *PhaseService) SubmitBotOrders(ctx context.Context, gameID string) error
 	for power, strategy := range botStrategies {
 		go func(power string, strategy bot.Strategy) {
 			dp := diplomacy.Power(power)
-			var ordersJSON []byte
-			var marshalErr error
+			gc := &bot.GameContext{
+				GameID:   gameID,
+				PhaseID:  phase.ID,
+				State:    &gs,
+				Power:    dp,
+				Map:      m,
+				Deadline: phase.Deadline,
+				Budget:   budget,
+			}
+			if _, ok := strategy.(bot.StrategyV2); ok {
+				gc.Received = s.receivedIntents(ctx, gameID, game, power, m)
+				gc.Diplomacy = bot.NewBotDiplomacyState()
+				gc.Diplomacy.ReceivedRequests = gc.Received
+				if gc.Alliances = s.loadAlliances(ctx, gameID, power); gc.Alliances != nil {
+					gc.Alliances.Observe(phase.ID, &gs, dp)
+				}
+			}
 
+			var (
+				inputs []bot.OrderInput
+				err    error
+			)
+			compute := measureCompute(bot.OutOfProcess(strategy), func() {
+				inputs, err = bot.GenerateOrders(ctx, strategy, gc)
+			})
+			if err != nil {
+				resultsCh <- botResult{power: power, strategy: strategy, compute: compute, err: fmt.Errorf("generate: %w", err)}
+				return
+			}
+			if gc.Alliances != nil {
+				s.updateAlliances(ctx, gameID, power, func(a *bot.AllianceState) {
+					a.Observe(phase.ID, &gs, dp)
+					if gs.Phase == diplomacy.PhaseMovement {
+						a.RecordOrders(&gs, dp, inputs)
+					}
+				})
+			}
+
+			var ordersJSON []byte
 			switch gs.Phase {
 			case diplomacy.PhaseRetreat:
-				inputs := strategy.GenerateRetreatOrders(&gs, dp, m)
 				var engineOrders []diplomacy.RetreatOrder
 				for _, in := range inputs {
 					engineOrders = append(engineOrders, toRetreatOrder(botInputToServiceInput(in), dp))
 				}
-				ordersJSON, marshalErr = json.Marshal(engineOrders)
+				ordersJSON, err = json.Marshal(engineOrders)
 			case diplomacy.PhaseBuild:
-				inputs := strategy.GenerateBuildOrders(&gs, dp, m)
 				var engineOrders []diplomacy.BuildOrder
 				for _, in := range inputs {
 					engineOrders = append(engineOrders, toBuildOrder(botInputToServiceInput(in), dp))
 				}
-				ordersJSON, marshalErr = json.Marshal(engineOrders)
+				ordersJSON, err = json.Marshal(engineOrders)
 			default:
-				inputs := strategy.GenerateMovementOrders(&gs, dp, m)
 				var engineOrders []diplomacy.Order
 				for _, in := range inputs {
 					engineOrders = append(engineOrders, toEngineOrder(botInputToServiceInput(in), dp))
 				}
-				ordersJSON, marshalErr = json.Marshal(engineOrders)
+				ordersJSON, err = json.Marshal(engineOrders)
+			}
+			if err != nil {
+				err = fmt.Errorf("marshal: %w", err)
 			}
 
-			resultsCh <- botResult{power: power, strategy: strategy, ordersJSON: ordersJSON, err: marshalErr}
+			resultsCh <- botResult{power: power, strategy: strategy, ordersJSON: ordersJSON, compute: compute, err: err}
 		}(power, strategy)
 	}
 
 	// Collect results and submit orders sequentially (Redis writes).
+	// Compute is recorded even if a bot fails, since the time was spent.
+	var computeRecords []model.BotCompute
+	defer func() { s.recordBotCompute(ctx, game, computeRecords) }()
+	var history []*diplomacy.GameState // loaded for the first value-head draw vote
 	for range botStrategies {
 		res := <-resultsCh
+		computeRecords = append(computeRecords, model.BotCompute{
+			GameID:    gameID,
+			PhaseID:   phase.ID,
+			Power:     res.power,
+			Strategy:  res.strategy.Name(),
+			ComputeMS: res.compute.Milliseconds(),
+		})
 		if res.err != nil {
-			return fmt.Errorf("marshal bot orders for %s: %w", res.power, res.err)
+			return fmt.Errorf("bot orders for %s: %w", res.power, res.err)
+		}
+		if only != "" {
+			// The phase may have resolved on its deadline meanwhile.
+			if current, err := s.phaseRepo.CurrentPhase(ctx, gameID); err != nil || current == nil || current.ID != phase.ID {
+				return err
+			}
 		}
 
 		if err := s.cache.SetOrders(ctx, gameID, res.power, res.ordersJSON); err != nil {
@@ -355,24 +695,36 @@ func (s *PhaseService) SubmitBotOrders(ctx context.Context, gameID string) error
 
 		log.Debug().Str("gameId", gameID).Str("power", res.power).Str("strategy", res.strategy.Name()).Str("phase", string(gs.Phase)).Msg("Bot orders submitted")
 
+		if only != "" {
+			continue
+		}
+
 		// Bot diplomacy: read messages and generate responses
 		s.handleBotDiplomacy(ctx, gameID, phase.ID, game, res.power, res.strategy, &gs, m)
 
 		// Bot draw voting
 		dp := diplomacy.Power(res.power)
-		if voter, ok := res.strategy.(bot.DrawVoter); ok {
-			if voter.ShouldVoteDraw(&gs, dp) {
-				if err := s.cache.AddDrawVote(ctx, gameID, res.power); err != nil {
-					log.Warn().Err(err).Str("power", res.power).Msg("Bot failed to add draw vote")
-				}
-			} else {
-				if err := s.cache.RemoveDrawVote(ctx, gameID, res.power); err != nil {
-					log.Warn().Err(err).Str("power", res.power).Msg("Bot failed to remove draw vote")
+		if voter, ok := res.strategy.(bot.ValueDrawVoter); ok {
+			if history == nil {
+				history = s.drawHistory(ctx, gameID, &gs)
+			}
+			if d, ok := voter.DecideDraw(history, dp, m); ok {
+				s.castBotDrawVote(ctx, gameID, res.power, d.Vote)
+				if d.Concede {
+					log.Info().Str("gameId", gameID).Str("power", res.power).Float64("winProb", d.WinProb).Msg("Bot conceded, voting for a draw")
 				}
+				continue
 			}
 		}
+		if voter, ok := res.strategy.(bot.DrawVoter); ok {
+			s.castBotDrawVote(ctx, gameID, res.power, voter.ShouldVoteDraw(&gs, dp))
+		}
 	}
 
+	// Record before resolving so downgrades apply from the next phase.
+	s.recordBotCompute(ctx, game, computeRecords)
+	computeRecords = nil
+
 	// Check if all powers are now ready
 	readyCount, err := s.cache.ReadyCount(ctx, gameID)
 	if err != nil {
@@ -390,11 +742,66 @@ func (s *PhaseService) SubmitBotOrders(ctx context.Context, gameID string) error
 		if err := s.ResolvePhaseEarly(ctx, gameID); err != nil {
 			return fmt.Errorf("auto-resolve after bot orders: %w", err)
 		}
+	} else if err := s.CheckReadyQuorum(ctx, gameID); err != nil {
+		log.Error().Err(err).Str("gameId", gameID).Msg("Ready quorum check after bot orders failed")
 	}
 
 	return nil
 }
 
+// castBotDrawVote records or withdraws a bot's draw vote.
+func (s *PhaseService) castBotDrawVote(ctx context.Context, gameID, power string, vote bool) {
+	if vote {
+		if err := s.cache.AddDrawVote(ctx, gameID, power); err != nil {
+			log.Warn().Err(err).Str("power", power).Msg("Bot failed to add draw vote")
+		}
+		return
+	}
+	if err := s.cache.RemoveDrawVote(ctx, gameID, power); err != nil {
+		log.Warn().Err(err).Str("power", power).Msg("Bot failed to remove draw vote")
+	}
+}
+
+// drawHistory returns the positions a ValueDrawVoter judges the game's
+// course by: the start of each of the last bot.ConcedeYears-1 years before
+// the current one, oldest first, followed by current. It goes back no
+// further than the first year whose state cannot be read.
+func (s *PhaseService) drawHistory(ctx context.Context, gameID string, current *diplomacy.GameState) []*diplomacy.GameState {
+	history := []*diplomacy.GameState{current}
+	phases, err := s.phaseRepo.ListPhases(ctx, gameID)
+	if err != nil {
+		log.Warn().Err(err).Str("gameId", gameID).Msg("List phases for bot draw votes failed")
+		return history
+	}
+	var earlier []*diplomacy.GameState
+	for _, year := range slices.Backward(yearStarts(phases, current.Year)) {
+		if len(earlier) == bot.ConcedeYears-1 {
+			break
+		}
+		var gs diplomacy.GameState
+		if err := json.Unmarshal(year.StateBefore, &gs); err != nil {
+			break
+		}
+		earlier = append(earlier, &gs)
+	}
+	slices.Reverse(earlier)
+	return append(earlier, history...)
+}
+
+// yearStarts returns the first phase of each year before year, in order.
+func yearStarts(phases []model.Phase, year int) []model.Phase {
+	var out []model.Phase
+	for _, p := range phases {
+		if p.Year >= year {
+			break
+		}
+		if len(out) == 0 || out[len(out)-1].Year != p.Year {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
 // botInputToServiceInput converts a bot.OrderInput to a service.OrderInput.
 func botInputToServiceInput(in bot.OrderInput) OrderInput {
 	return OrderInput{
@@ -427,11 +834,19 @@ func (s *PhaseService) ResolvePhaseEarly(ctx context.Context, gameID string) err
 }
 
 func (s *PhaseService) resolvePhaseInternal(ctx context.Context, gameID string, early bool) error {
-	// Per-game lock prevents concurrent resolution from keyspace + poller
-	// or from early-resolution goroutines racing with timer expiry.
-	mu := s.gameLock(gameID)
-	mu.Lock()
-	defer mu.Unlock()
+	s.ensureRecovered(ctx, gameID)
+
+	// Per-game lock prevents concurrent resolution from keyspace + poller,
+	// from early-resolution goroutines racing with timer expiry, or from
+	// other replicas.
+	// The fenced context is canceled if the game's lease is lost mid-way,
+	// which fails the remaining writes before they can clash with the
+	// replica that took it over.
+	ctx, unlock, err := s.lockGameFenced(ctx, gameID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	game, err := s.gameRepo.FindByID(ctx, gameID)
 	if err != nil || game == nil {
@@ -473,7 +888,7 @@ func (s *PhaseService) resolvePhaseInternal(ctx context.Context, gameID string,
 		return fmt.Errorf("unmarshal state: %w", err)
 	}
 
-	m := diplomacy.StandardMap()
+	m := gs.Map()
 	powers := activePowers(game)
 
 	switch gs.Phase {
@@ -500,7 +915,7 @@ func (s *PhaseService) resolveMovement(
 	m *diplomacy.DiplomacyMap,
 	powers []string,
 ) error {
-	orders, err := s.collectMovementOrders(ctx, game.ID, gs, m, powers)
+	orders, downgraded, err := s.collectMovementOrders(ctx, game.ID, gs, m, powers)
 	if err != nil {
 		return fmt.Errorf("collect orders: %w", err)
 	}
@@ -509,10 +924,11 @@ func (s *PhaseService) resolveMovement(
 	diplomacy.ApplyResolution(gs, m, results, dislodged)
 
 	// Save resolved orders to Postgres
-	modelOrders := resolvedOrdersToModel(phase.ID, results)
+	modelOrders := recordDowngrades(resolvedOrdersToModel(phase.ID, results), phase.ID, downgraded)
 	if err := s.phaseRepo.SaveOrders(ctx, modelOrders); err != nil {
 		return fmt.Errorf("save orders: %w", err)
 	}
+	s.notifyDowngrades(game, phase, downgraded)
 
 	return s.advanceToNextPhase(ctx, game, phase, gs, m, powers, len(dislodged) > 0)
 }
@@ -590,15 +1006,23 @@ func (s *PhaseService) advanceToNextPhase(
 	powers []string,
 	hasDislodgements bool,
 ) error {
-	// After Fall movement/retreat, update SC ownership before saving stateAfter
+	// After Fall (the year's last season) movement/retreat, update SC ownership before saving stateAfter
 	// so the resolved phase reflects the correct final SC distribution. AdvanceState
 	// also calls this, but stateAfter must include it for the UI to display correct
 	// SC counts when viewing historical phases (especially the game-ending phase).
-	if gs.Season == diplomacy.Fall && (gs.Phase == diplomacy.PhaseMovement || gs.Phase == diplomacy.PhaseRetreat) {
+	var eliminated []elimination
+	if gs.IsLastSeason() && (gs.Phase == diplomacy.PhaseMovement || gs.Phase == diplomacy.PhaseRetreat) {
+		before := maps.Clone(gs.SupplyCenters)
 		diplomacy.UpdateSupplyCenterOwnership(gs)
+		eliminated = findEliminations(before, gs, powers)
 	}
+	s.recordEliminations(ctx, game, phase, eliminated, gs)
 
-	// Save state_after for current phase
+	// Save state_after for current phase, unless the game's lease was lost:
+	// another replica may be resolving it now.
+	if err := context.Cause(ctx); errors.Is(err, ErrLeaseLost) {
+		return fmt.Errorf("resolve phase: %w", err)
+	}
 	stateAfterJSON, err := json.Marshal(gs)
 	if err != nil {
 		return fmt.Errorf("marshal state after: %w", err)
@@ -616,9 +1040,11 @@ func (s *PhaseService) advanceToNextPhase(
 		if err := s.gameRepo.SetFinished(ctx, game.ID, string(winner)); err != nil {
 			return fmt.Errorf("set finished: %w", err)
 		}
-		s.broadcaster.BroadcastGameEvent(game.ID, "game_ended", map[string]any{
+		s.gameEnded(ctx, game.ID)
+		s.broadcastGameEnded(game.ID, map[string]any{
 			"winner": string(winner),
 		})
+		s.notifyPhaseResolved(game, phase, nil, gs.Calendar())
 		return s.cache.DeleteGameData(ctx, game.ID, powers)
 	}
 
@@ -628,10 +1054,12 @@ func (s *PhaseService) advanceToNextPhase(
 		if err := s.gameRepo.SetFinished(ctx, game.ID, ""); err != nil {
 			return fmt.Errorf("set finished (year limit): %w", err)
 		}
-		s.broadcaster.BroadcastGameEvent(game.ID, "game_ended", map[string]any{
+		s.gameEnded(ctx, game.ID)
+		s.broadcastGameEnded(game.ID, map[string]any{
 			"winner": "draw",
 			"reason": "year_limit",
 		})
+		s.notifyPhaseResolved(game, phase, nil, gs.Calendar())
 		return s.cache.DeleteGameData(ctx, game.ID, powers)
 	}
 
@@ -652,14 +1080,33 @@ func (s *PhaseService) advanceToNextPhase(
 	}
 
 	dur := phaseDuration(game, gs.Phase)
-	deadline := time.Now().Add(dur)
+	deadline := jitteredDeadline(dur, s.jitter)
 
-	_, err = s.phaseRepo.CreatePhase(ctx, game.ID, gs.Year, string(gs.Season), string(gs.Phase), newStateJSON, deadline)
+	next, err := s.phaseRepo.CreatePhase(ctx, game.ID, gs.Year, string(gs.Season), string(gs.Phase), newStateJSON, deadline)
 	if err != nil {
 		return fmt.Errorf("create next phase: %w", err)
 	}
 
-	// Update Redis: new state, clear old orders/ready, set new timer
+	// Bot difficulty changes requested during the resolved phase take
+	// effect now, before the bots generate orders for the new one.
+	changes, err := s.gameRepo.ApplyPendingBotDifficulties(ctx, game.ID, next.ID)
+	if err != nil {
+		log.Warn().Err(err).Str("gameId", game.ID).Msg("Failed to apply bot difficulty changes")
+	}
+	for _, c := range changes {
+		log.Info().Str("gameId", game.ID).Str("power", c.Power).Str("from", c.From).Str("to", c.To).Msg("Bot difficulty changed")
+	}
+	s.recordDeadline(ctx, game, phase, m, powers)
+
+	// Draw votes expire with the phase; remember who had voted so players can
+	// be told their vote needs re-confirming.
+	expiredVotes, err := s.cache.DrawVotePowers(ctx, game.ID)
+	if err != nil {
+		log.Warn().Err(err).Str("gameId", game.ID).Msg("Failed to read draw votes before reset")
+	}
+	sort.Strings(expiredVotes)
+
+	// Update Redis: new state, clear old orders/ready/draw votes, set new timer
 	if err := s.cache.ClearPhaseData(ctx, game.ID, powers); err != nil {
 		return fmt.Errorf("clear phase data: %w", err)
 	}
@@ -671,10 +1118,22 @@ func (s *PhaseService) advanceToNextPhase(
 	}
 
 	// Auto-ready eliminated powers so the game doesn't stall waiting on them.
-	if err := s.autoReadyEliminatedPowers(ctx, game.ID, gs, powers); err != nil {
+	if err := s.autoReadyEliminatedPowers(withPhase(ctx, next.ID), game.ID, gs, powers); err != nil {
 		log.Warn().Err(err).Str("gameId", game.ID).Msg("Failed to auto-ready eliminated powers")
 	}
 
+	// Presets with auto-skip also ready powers that have no real choice this
+	// phase; if that covers everyone the phase resolves immediately.
+	preset, hasPreset := LookupPreset(game.SpeedPreset)
+	skipPhase := false
+	if hasPreset && preset.AutoSkip && gs.Phase != diplomacy.PhaseMovement {
+		skipped, err := s.autoReadyNoChoicePowers(withPhase(ctx, next.ID), game.ID, gs, powers)
+		if err != nil {
+			log.Warn().Err(err).Str("gameId", game.ID).Msg("Failed to auto-ready powers without choices")
+		}
+		skipPhase = err == nil && skipped == len(powers)
+	}
+
 	log.Info().
 		Str("gameId", game.ID).
 		Str("season", string(gs.Season)).
@@ -692,23 +1151,35 @@ func (s *PhaseService) advanceToNextPhase(
 		"type":     phase.PhaseType,
 	})
 	s.broadcaster.BroadcastGameEvent(game.ID, "phase_changed", map[string]any{
-		"year":     gs.Year,
-		"season":   string(gs.Season),
-		"type":     string(gs.Phase),
-		"deadline": deadline.Format(time.RFC3339),
+		"year":              gs.Year,
+		"season":            string(gs.Season),
+		"type":              string(gs.Phase),
+		"deadline":          deadline.Format(time.RFC3339),
+		"deadline_epoch_ms": deadline.UnixMilli(),
 	})
+	s.notifyPhaseResolved(game, phase, next, gs.Calendar())
+	if len(expiredVotes) > 0 {
+		s.broadcaster.BroadcastGameEvent(game.ID, "draw_votes_reset", map[string]any{
+			"expired_votes":   expiredVotes,
+			"draw_vote_count": 0,
+			"year":            gs.Year,
+			"season":          string(gs.Season),
+			"type":            string(gs.Phase),
+		})
+	}
 
 	// Submit bot orders for the new phase in a separate goroutine.
-	// Give bots at most phase_duration - 5s so they finish before the timer.
-	botTimeout := dur - 5*time.Second
-	if botTimeout > 30*time.Second {
-		botTimeout = 30 * time.Second
-	}
-	if botTimeout < 5*time.Second {
-		botTimeout = 5 * time.Second
-	}
 	go func() {
-		botCtx, cancel := context.WithTimeout(context.Background(), botTimeout)
+		if skipPhase {
+			ctx, cancel := context.WithTimeout(context.Background(), autoSkipTimeout)
+			defer cancel()
+			log.Info().Str("gameId", game.ID).Str("phase", string(gs.Phase)).Msg("No power has a choice, skipping phase")
+			if err := s.ResolvePhaseEarly(ctx, game.ID); err != nil {
+				log.Error().Err(err).Str("gameId", game.ID).Msg("Failed to auto-skip phase")
+			}
+			return
+		}
+		botCtx, cancel := context.WithTimeout(context.Background(), botPhaseTimeout(dur, preset, hasPreset))
 		defer cancel()
 		if err := s.SubmitBotOrders(botCtx, game.ID); err != nil {
 			log.Error().Err(err).Str("gameId", game.ID).Msg("Failed to submit bot orders after phase advance")
@@ -718,6 +1189,154 @@ func (s *PhaseService) advanceToNextPhase(
 	return nil
 }
 
+// Bot order timeouts: bots get the phase less 5s, between minBotTimeout
+// and maxBotTimeout, or their preset's budget but never under the minimum.
+// Resolving an auto-skipped phase gets autoSkipTimeout.
+const (
+	minBotTimeout   = 5 * time.Second
+	maxBotTimeout   = 30 * time.Second
+	autoSkipTimeout = 30 * time.Second
+)
+
+// botPhaseTimeout returns how long bots have to submit orders for a phase
+// lasting dur.
+func botPhaseTimeout(dur time.Duration, preset GamePreset, hasPreset bool) time.Duration {
+	timeout := min(dur-5*time.Second, maxBotTimeout)
+	if budget := preset.botTimeBudget(); hasPreset && budget > 0 {
+		timeout = budget
+	}
+	return max(timeout, minBotTimeout)
+}
+
+// SetGameService enables tracking of missed deadlines, flagging players who
+// abandon their power as in civil disorder.
+func (s *PhaseService) SetGameService(svc *GameService) {
+	s.games = svc
+}
+
+// recordDeadline counts who missed the resolved phase's deadline towards
+// civil disorder, and tells the game about players who fell into it or were
+// replaced by a bot. It must run before the phase's orders are cleared.
+func (s *PhaseService) recordDeadline(ctx context.Context, game *model.Game, phase *model.Phase, m *diplomacy.DiplomacyMap, powers []string) {
+	if s.games == nil || game.CivilDisorderAfter == 0 {
+		return
+	}
+	var gs diplomacy.GameState
+	if err := json.Unmarshal(phase.StateBefore, &gs); err != nil {
+		log.Warn().Err(err).Str("gameId", game.ID).Msg("Failed to read phase state for civil disorder")
+		return
+	}
+	ready, err := s.cache.ReadyPowers(ctx, game.ID)
+	if err != nil {
+		log.Warn().Err(err).Str("gameId", game.ID).Msg("Failed to read ready powers for civil disorder")
+		return
+	}
+	orders, err := s.cache.GetAllOrders(ctx, game.ID, powers)
+	if err != nil {
+		log.Warn().Err(err).Str("gameId", game.ID).Msg("Failed to read orders for civil disorder")
+		return
+	}
+
+	var missed, acted []string
+	for _, p := range game.Players {
+		if p.IsBot || p.Power == "" || !diplomacy.HasOrderChoice(&gs, diplomacy.Power(p.Power), m) {
+			continue
+		}
+		if slices.Contains(ready, p.Power) || orders[p.Power] != nil {
+			acted = append(acted, p.UserID)
+		} else {
+			missed = append(missed, p.UserID)
+		}
+	}
+	disorders, err := s.games.RecordDeadline(ctx, game, missed, acted)
+	if err != nil {
+		log.Warn().Err(err).Str("gameId", game.ID).Msg("Failed to record missed deadlines")
+		return
+	}
+	for _, d := range disorders {
+		log.Info().Str("gameId", game.ID).Str("power", d.Power).Int("missed", d.MissedDeadlines).Msg("Player in civil disorder")
+		s.broadcaster.BroadcastGameEvent(game.ID, "civil_disorder", map[string]any{
+			"power":            d.Power,
+			"missed_deadlines": d.MissedDeadlines,
+		})
+		if d.Replacement != nil {
+			s.broadcaster.BroadcastGameEvent(game.ID, "player_replaced", map[string]any{
+				"power":          d.Power,
+				"bot_difficulty": d.Replacement.BotDifficulty,
+				"reason":         "civil_disorder",
+			})
+		}
+	}
+}
+
+// elimination is a power that lost its last supply center, and the power
+// that took most of its centers; By is empty if they all went neutral.
+type elimination struct {
+	Power string
+	By    string
+}
+
+// findEliminations compares supply center ownership before an update with
+// gs after it and returns the powers left without a center, in powers order.
+// Ties for the eliminating power go to the earlier of powers.
+func findEliminations(before map[string]diplomacy.Power, gs *diplomacy.GameState, powers []string) []elimination {
+	var out []elimination
+	for _, power := range powers {
+		p := diplomacy.Power(power)
+		if gs.SupplyCenterCount(p) > 0 || !slices.Contains(slices.Collect(maps.Values(before)), p) {
+			continue
+		}
+		taken := make(map[string]int)
+		for sc, owner := range before {
+			if owner == p && gs.SupplyCenters[sc] != "" {
+				taken[string(gs.SupplyCenters[sc])]++
+			}
+		}
+		e := elimination{Power: power}
+		for _, other := range powers {
+			if taken[other] > taken[e.By] {
+				e.By = other
+			}
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+// recordEliminations stores each elimination on the player's row, tells the
+// game, and notifies the eliminated player and the survivors.
+func (s *PhaseService) recordEliminations(ctx context.Context, game *model.Game, phase *model.Phase, eliminated []elimination, gs *diplomacy.GameState) {
+	for _, e := range eliminated {
+		log.Info().Str("gameId", game.ID).Str("power", e.Power).Str("by", e.By).Int("year", gs.Year).Msg("Power eliminated")
+		if err := s.gameRepo.SetEliminated(ctx, game.ID, e.Power, e.By, gs.Year); err != nil {
+			log.Warn().Err(err).Str("gameId", game.ID).Str("power", e.Power).Msg("Failed to record elimination")
+		}
+		var survivors []string
+		for _, p := range game.Players {
+			if p.Power != "" && gs.SupplyCenterCount(diplomacy.Power(p.Power)) > 0 {
+				survivors = append(survivors, p.Power)
+			}
+		}
+		sort.Strings(survivors)
+		s.broadcaster.BroadcastGameEvent(game.ID, "power_eliminated", map[string]any{
+			"power":         e.Power,
+			"eliminated_by": e.By,
+			"year":          gs.Year,
+			"survivors":     survivors,
+		})
+	}
+	if s.notifier == nil || len(eliminated) == 0 {
+		return
+	}
+	go func() {
+		for _, e := range eliminated {
+			if err := s.notifier.Eliminated(context.Background(), game, phase, e.Power, e.By); err != nil {
+				log.Warn().Err(err).Str("gameId", game.ID).Str("power", e.Power).Msg("Failed to notify players of an elimination")
+			}
+		}
+	}()
+}
+
 // autoReadyEliminatedPowers marks eliminated powers (0 units AND 0 SCs) as ready
 // so the game doesn't stall waiting for players who can't issue orders.
 func (s *PhaseService) autoReadyEliminatedPowers(ctx context.Context, gameID string, gs *diplomacy.GameState, powers []string) error {
@@ -732,17 +1351,38 @@ func (s *PhaseService) autoReadyEliminatedPowers(ctx context.Context, gameID str
 	return nil
 }
 
-// collectMovementOrders gathers orders from Redis and defaults missing ones to Hold.
+// autoReadyNoChoicePowers marks powers that are eliminated or have no real
+// choice in a retreat or build phase as ready, returning how many powers are
+// ready as a result.
+func (s *PhaseService) autoReadyNoChoicePowers(ctx context.Context, gameID string, gs *diplomacy.GameState, powers []string) (int, error) {
+	m := gs.Map()
+	ready := 0
+	for _, power := range powers {
+		p := diplomacy.Power(power)
+		if gs.PowerIsAlive(p) && diplomacy.HasOrderChoice(gs, p, m) {
+			continue
+		}
+		if err := s.cache.MarkReady(ctx, gameID, power); err != nil {
+			return ready, fmt.Errorf("auto-ready %s: %w", power, err)
+		}
+		ready++
+	}
+	return ready, nil
+}
+
+// collectMovementOrders gathers orders from Redis and defaults missing ones to
+// Hold. Invalid orders are replaced with Hold and also returned as void
+// results carrying the rejection reason.
 func (s *PhaseService) collectMovementOrders(
 	ctx context.Context,
 	gameID string,
 	gs *diplomacy.GameState,
 	m *diplomacy.DiplomacyMap,
 	powers []string,
-) ([]diplomacy.Order, error) {
+) ([]diplomacy.Order, []diplomacy.ResolvedOrder, error) {
 	allOrdersRaw, err := s.cache.GetAllOrders(ctx, gameID, powers)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	var allOrders []diplomacy.Order
@@ -774,8 +1414,58 @@ func (s *PhaseService) collectMovementOrders(
 	}
 
 	// Validate and default (replaces invalid orders with Hold)
-	validated, _ := diplomacy.ValidateAndDefaultOrders(allOrders, gs, m)
-	return validated, nil
+	validated, voids := diplomacy.ValidateAndDefaultOrders(allOrders, gs, m)
+	return validated, voids, nil
+}
+
+// recordDowngrades annotates the executed hold of each downgraded order with
+// the order as submitted and the reason it was rejected. A downgraded order
+// with no executed counterpart (e.g. for a unit that no longer exists) is
+// kept as a void record.
+func recordDowngrades(orders []model.Order, phaseID string, downgraded []diplomacy.ResolvedOrder) []model.Order {
+	for _, d := range downgraded {
+		i := slices.IndexFunc(orders, func(o model.Order) bool {
+			return o.Power == string(d.Order.Power) && o.Location == d.Order.Location
+		})
+		if i < 0 {
+			void := resolvedOrdersToModel(phaseID, []diplomacy.ResolvedOrder{d})[0]
+			orders = append(orders, void)
+			i = len(orders) - 1
+		}
+		orders[i].SubmittedOrder = orderDSON(d.Order)
+		orders[i].DowngradeReason = d.Reason
+	}
+	return orders
+}
+
+// orderDSON formats a single order as DSON, e.g. "A par - bur".
+func orderDSON(o diplomacy.Order) string {
+	return diplomacy.FormatDSON([]diplomacy.DSONOrder{diplomacy.OrderToDSON(o)})
+}
+
+// notifyDowngrades tells each human player whose orders were replaced with
+// holds which orders were changed and why.
+func (s *PhaseService) notifyDowngrades(game *model.Game, phase *model.Phase, downgraded []diplomacy.ResolvedOrder) {
+	byPower := make(map[string][]map[string]string)
+	for _, d := range downgraded {
+		power := string(d.Order.Power)
+		byPower[power] = append(byPower[power], map[string]string{
+			"submitted_order": orderDSON(d.Order),
+			"reason":          d.Reason,
+		})
+	}
+	for _, p := range game.Players {
+		orders, ok := byPower[p.Power]
+		if !ok || p.IsBot {
+			continue
+		}
+		log.Info().Str("gameId", game.ID).Str("power", p.Power).Int("count", len(orders)).Msg("Invalid orders replaced with holds")
+		s.broadcaster.BroadcastUserEvent(game.ID, p.UserID, "orders_downgraded", map[string]any{
+			"phase_id": phase.ID,
+			"power":    p.Power,
+			"orders":   orders,
+		})
+	}
 }
 
 // collectRetreatOrders gathers retreat orders; defaults to disband for missing ones.
@@ -814,11 +1504,12 @@ func (s *PhaseService) collectRetreatOrders(
 	for _, du := range gs.Dislodged {
 		if !ordered[du.DislodgedFrom] {
 			allOrders = append(allOrders, diplomacy.RetreatOrder{
-				UnitType: du.Unit.Type,
-				Power:    du.Unit.Power,
-				Location: du.DislodgedFrom,
-				Coast:    du.Unit.Coast,
-				Type:     diplomacy.RetreatDisband,
+				UnitType:  du.Unit.Type,
+				Power:     du.Unit.Power,
+				Location:  du.DislodgedFrom,
+				Coast:     du.Unit.Coast,
+				Type:      diplomacy.RetreatDisband,
+				Defaulted: true,
 			})
 		}
 	}
@@ -986,22 +1677,25 @@ func orderResultStr(r diplomacy.OrderResult) string {
 	}
 }
 
-// CleanupStoppedGame broadcasts the game_ended event and clears cached game data.
+// CleanupStoppedGame broadcasts the game_ended event, and game_finished to the
+// lobby, and clears cached game data.
 func (s *PhaseService) CleanupStoppedGame(ctx context.Context, gameID string) error {
 	game, err := s.gameRepo.FindByID(ctx, gameID)
 	if err != nil || game == nil {
 		return fmt.Errorf("find game: %w", err)
 	}
 	powers := activePowers(game)
-	s.broadcaster.BroadcastGameEvent(gameID, "game_ended", map[string]any{
+	s.broadcastGameEnded(gameID, map[string]any{
 		"winner": "draw",
 		"reason": "stopped",
 	})
+	s.pendingRecovery.Delete(gameID)
 	return s.cache.DeleteGameData(ctx, gameID, powers)
 }
 
 // handleBotDiplomacy reads messages sent to a bot, generates diplomatic responses,
 // and stores them via the message repository. Requires messageRepo to be set.
+// Bot press is private, so bots stay silent unless the game has full press.
 func (s *PhaseService) handleBotDiplomacy(
 	ctx context.Context,
 	gameID, phaseID string,
@@ -1011,7 +1705,7 @@ func (s *PhaseService) handleBotDiplomacy(
 	gs *diplomacy.GameState,
 	m *diplomacy.DiplomacyMap,
 ) {
-	if s.messageRepo == nil {
+	if s.messageRepo == nil || !AllowsPress(game, true) {
 		return
 	}
 
@@ -1020,35 +1714,122 @@ func (s *PhaseService) handleBotDiplomacy(
 		return
 	}
 
-	// Find the bot's user ID
-	botUserID := ""
+	botUserID := botUserIDFor(game, botPower)
+	if botUserID == "" {
+		return
+	}
+	received := s.receivedIntents(ctx, gameID, game, botPower, m)
+
+	// Generate diplomatic responses
+	dp := diplomacy.Power(botPower)
+	responses := dipStrategy.GenerateDiplomaticMessages(gs, dp, m, received)
+	if _, ok := strategy.(bot.StrategyV2); ok {
+		s.updateAlliances(ctx, gameID, botPower, func(a *bot.AllianceState) {
+			a.RecordIntents(gs.Year, received, responses)
+		})
+	}
+
+	// Send response messages
+	for _, resp := range responses {
+		// Find recipient user ID; channel messages have none
+		recipientUserID := ""
+		if resp.Channel == "" {
+			recipientUserID = botUserIDFor(game, string(resp.To))
+		}
+
+		locale := s.userLocale(ctx, recipientUserID)
+		content := bot.FormatCannedMessageLocale(resp, locale)
+		if content == "" {
+			continue
+		}
+		if game.BotPress != bot.PressStyleTerse {
+			if lang, ok := bot.NormalizeLocale(locale); !ok || lang == bot.DefaultLocale {
+				seed := phaseID + botPower + string(resp.To)
+				difficulty := botDifficultyFor(game, botUserID)
+				if game.BotPress == bot.PressStyleNatural {
+					content = bot.RenderPress(resp, dp, difficulty, seed, m)
+				} else {
+					content = bot.FlavorPress(resp, content, dp, difficulty, seed)
+				}
+			}
+		}
+
+		var err error
+		if resp.Channel != "" {
+			_, err = s.messageRepo.CreateInChannel(ctx, gameID, botUserID, resp.Channel, content, phaseID, bot.IntentAttachment(resp))
+		} else {
+			_, err = s.messageRepo.Create(ctx, gameID, botUserID, recipientUserID, content, phaseID, bot.IntentAttachment(resp))
+		}
+		if err != nil {
+			log.Warn().Err(err).Str("power", botPower).Str("to", string(resp.To)).Msg("Failed to send bot message")
+		}
+	}
+}
+
+// botUserIDFor returns the user ID playing the given power, or "" if none.
+func botUserIDFor(game *model.Game, power string) string {
 	for _, p := range game.Players {
-		if p.Power == botPower {
-			botUserID = p.UserID
-			break
+		if p.Power == power {
+			return p.UserID
 		}
 	}
+	return ""
+}
+
+// botDifficultyFor returns the strategy a bot user plays in game.
+func botDifficultyFor(game *model.Game, userID string) string {
+	for _, p := range game.Players {
+		if p.UserID == userID {
+			return p.BotDifficulty
+		}
+	}
+	return ""
+}
+
+// receivedIntents returns the press other players have sent the bot playing
+// botPower, parsed into intents. Free text is read for keywords and the
+// provinces of m it names, and only read as an acceptance or rejection when
+// it answers a proposal the bot made in the same conversation; messages with
+// no recognizable intent are skipped. It returns nil when no message
+// repository is configured.
+func (s *PhaseService) receivedIntents(ctx context.Context, gameID string, game *model.Game, botPower string, m *diplomacy.DiplomacyMap) []bot.DiplomaticIntent {
+	if s.messageRepo == nil {
+		return nil
+	}
+	botUserID := botUserIDFor(game, botPower)
 	if botUserID == "" {
-		return
+		return nil
 	}
 
 	// Read messages sent to this bot
 	messages, err := s.messageRepo.ListByGame(ctx, gameID, botUserID)
 	if err != nil {
 		log.Warn().Err(err).Str("power", botPower).Msg("Failed to read bot messages")
-		return
+		return nil
 	}
 
-	// Parse received messages into intents
+	// Parse received messages into intents, tracking per conversation
+	// whether the bot's last proposal still awaits an answer.
 	var received []bot.DiplomaticIntent
+	pending := make(map[string]bool)
 	for _, msg := range messages {
 		if msg.SenderID == botUserID {
-			continue // skip own messages
+			if intent, err := bot.IntentFromMessage(msg); err == nil && intent.Type.IsProposal() {
+				pending[conversationKey(msg, msg.RecipientID)] = true
+			}
+			continue
+		}
+		conv := conversationKey(msg, msg.SenderID)
+		intent, err := bot.IntentFromMessage(msg)
+		if err != nil && msg.Intent == nil {
+			intent, err = bot.ParsePress(msg.Content, m, pending[conv])
 		}
-		intent, err := bot.ParseCannedMessage(msg.Content)
 		if err != nil {
 			continue // skip unrecognized messages
 		}
+		if intent.Type == bot.IntentAccept || intent.Type == bot.IntentReject {
+			delete(pending, conv)
+		}
 		// Determine sender power
 		for _, p := range game.Players {
 			if p.UserID == msg.SenderID {
@@ -1057,32 +1838,30 @@ func (s *PhaseService) handleBotDiplomacy(
 			}
 		}
 		intent.To = diplomacy.Power(botPower)
+		intent.Channel = msg.ChannelID
 		received = append(received, *intent)
 	}
+	return received
+}
 
-	// Generate diplomatic responses
-	dp := diplomacy.Power(botPower)
-	responses := dipStrategy.GenerateDiplomaticMessages(gs, dp, m, received)
-
-	// Send response messages
-	for _, resp := range responses {
-		// Find recipient user ID
-		recipientUserID := ""
-		for _, p := range game.Players {
-			if diplomacy.Power(p.Power) == resp.To {
-				recipientUserID = p.UserID
-				break
-			}
-		}
-
-		content := bot.FormatCannedMessage(resp)
-		if content == "" {
-			continue
-		}
+// conversationKey names the conversation a bot's message is part of: its
+// press channel, or else the other player, peer.
+func conversationKey(msg model.Message, peer string) string {
+	if msg.ChannelID != "" {
+		return "channel:" + msg.ChannelID
+	}
+	return "user:" + peer
+}
 
-		_, err := s.messageRepo.Create(ctx, gameID, botUserID, recipientUserID, content, phaseID)
-		if err != nil {
-			log.Warn().Err(err).Str("power", botPower).Str("to", string(resp.To)).Msg("Failed to send bot message")
-		}
+// userLocale returns the press locale for a user, or bot.DefaultLocale when
+// the user is unknown or no user repository is configured.
+func (s *PhaseService) userLocale(ctx context.Context, userID string) string {
+	if s.userRepo == nil || userID == "" {
+		return bot.DefaultLocale
+	}
+	u, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil || u == nil || u.Locale == "" {
+		return bot.DefaultLocale
 	}
+	return u.Locale
 }
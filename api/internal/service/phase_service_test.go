@@ -3,10 +3,13 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
 	"testing"
 	"time"
 
+	"github.com/freeeve/polite-betrayal/api/internal/bot"
 	"github.com/freeeve/polite-betrayal/api/internal/model"
 	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
 )
@@ -18,7 +21,7 @@ func setupActiveGame(t *testing.T, gameRepo *mockGameRepo, phaseRepo *mockPhaseR
 	ctx := context.Background()
 	gameSvc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
 
-	game, err := gameSvc.CreateGame(ctx, "Test Game", "user-1", "24h", "12h", "12h", "", "", false)
+	game, err := gameSvc.CreateGame(ctx, "Test Game", "user-1", "24h", "12h", "12h", "", "", "", false, model.GameRules{})
 	if err != nil {
 		t.Fatalf("create game: %v", err)
 	}
@@ -141,6 +144,65 @@ func TestPhaseServiceResolveWithOrders(t *testing.T) {
 	}
 }
 
+func TestResolveRecordsDowngradedOrders(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	cache := newMockCache()
+	rec := &recordingBroadcaster{}
+	phaseSvc := NewPhaseService(gameRepo, phaseRepo, cache, rec)
+	ctx := context.Background()
+
+	gameID, _ := setupActiveGame(t, gameRepo, phaseRepo, cache)
+	phase, _ := phaseRepo.CurrentPhase(ctx, gameID)
+	game, _ := gameRepo.FindByID(ctx, gameID)
+	var engUser string
+	for _, p := range game.Players {
+		if p.Power == "england" {
+			engUser = p.UserID
+		}
+	}
+
+	// A fleet cannot move inland, so F lon - mun is executed as a hold.
+	orders := []diplomacy.Order{
+		{UnitType: diplomacy.Fleet, Power: diplomacy.England, Location: "lon", Type: diplomacy.OrderMove, Target: "mun"},
+	}
+	ordersJSON, _ := json.Marshal(orders)
+	cache.SetOrders(ctx, gameID, "england", ordersJSON)
+
+	if err := phaseSvc.ResolvePhaseEarly(ctx, gameID); err != nil {
+		t.Fatalf("ResolvePhase: %v", err)
+	}
+
+	saved, _ := phaseRepo.OrdersByPhase(ctx, phase.ID)
+	var lon *model.Order
+	for i, o := range saved {
+		if o.Location == "lon" {
+			lon = &saved[i]
+		} else if o.SubmittedOrder != "" || o.DowngradeReason != "" {
+			t.Errorf("order at %s unexpectedly marked as downgraded", o.Location)
+		}
+	}
+	if lon == nil {
+		t.Fatal("no order recorded for lon")
+	}
+	if lon.OrderType != "hold" || lon.SubmittedOrder != "F lon - mun" || lon.DowngradeReason == "" {
+		t.Errorf("lon order = %s (submitted %q, reason %q), want hold downgraded from F lon - mun",
+			lon.OrderType, lon.SubmittedOrder, lon.DowngradeReason)
+	}
+
+	events := rec.eventsOfType("orders_downgraded")
+	if len(events) != 1 {
+		t.Fatalf("expected 1 orders_downgraded event, got %d", len(events))
+	}
+	if events[0].userID != engUser {
+		t.Errorf("event sent to %q, want england's player %q", events[0].userID, engUser)
+	}
+	data := events[0].data.(map[string]any)
+	if data["phase_id"] != phase.ID || data["power"] != "england" {
+		t.Errorf("event data = %v", data)
+	}
+}
+
 func TestPhaseServiceFullCycleToFallAndBuild(t *testing.T) {
 	gameRepo := newMockGameRepo()
 	phaseRepo := newMockPhaseRepo()
@@ -176,6 +238,76 @@ func TestPhaseServiceFullCycleToFallAndBuild(t *testing.T) {
 	}
 }
 
+func TestPhaseServiceRecordsElimination(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	cache := newMockCache()
+	rec := &recordingBroadcaster{}
+	phaseSvc := NewPhaseService(gameRepo, phaseRepo, cache, rec)
+	ctx := context.Background()
+
+	gameID, _ := setupActiveGame(t, gameRepo, phaseRepo, cache)
+
+	// Italy is down to Naples, which an Austrian army holds in the fall.
+	gs := diplomacy.NewInitialState()
+	gs.Season = diplomacy.Fall
+	delete(gs.SupplyCenters, "ven")
+	delete(gs.SupplyCenters, "rom")
+	gs.Units = slices.DeleteFunc(gs.Units, func(u diplomacy.Unit) bool { return u.Power == diplomacy.Italy })
+	gs.Units = append(gs.Units, diplomacy.Unit{Type: diplomacy.Army, Power: diplomacy.Austria, Province: "nap"})
+	stateJSON, _ := json.Marshal(gs)
+	cache.SetGameState(ctx, gameID, stateJSON)
+
+	if err := phaseSvc.ResolvePhaseEarly(ctx, gameID); err != nil {
+		t.Fatalf("resolve fall movement: %v", err)
+	}
+
+	events := rec.eventsOfType("power_eliminated")
+	if len(events) != 1 {
+		t.Fatalf("expected 1 power_eliminated event, got %d", len(events))
+	}
+	data := events[0].data.(map[string]any)
+	if data["power"] != "italy" || data["eliminated_by"] != "austria" || data["year"] != 1901 {
+		t.Errorf("event data = %v", data)
+	}
+	if survivors := data["survivors"].([]string); len(survivors) != 6 || slices.Contains(survivors, "italy") {
+		t.Errorf("survivors = %v", survivors)
+	}
+	game, _ := gameRepo.FindByID(ctx, gameID)
+	for _, p := range game.Players {
+		if p.Power == "italy" && (p.EliminatedBy != "austria" || p.EliminatedYear != 1901) {
+			t.Errorf("italy's player row = %+v, want eliminated by austria in 1901", p)
+		}
+		if p.Power != "italy" && p.EliminatedYear != 0 {
+			t.Errorf("%s unexpectedly recorded as eliminated", p.Power)
+		}
+	}
+}
+
+func TestFindEliminations(t *testing.T) {
+	before := map[string]diplomacy.Power{"tun": diplomacy.Italy, "nap": diplomacy.Italy, "rom": diplomacy.Italy, "ven": diplomacy.Austria}
+	gs := &diplomacy.GameState{SupplyCenters: map[string]diplomacy.Power{
+		"tun": diplomacy.France, "nap": diplomacy.Austria, "rom": diplomacy.France, "ven": diplomacy.Austria,
+	}}
+	powers := []string{"austria", "france", "italy", "turkey"}
+	got := findEliminations(before, gs, powers)
+	if len(got) != 1 || got[0] != (elimination{Power: "italy", By: "france"}) {
+		t.Errorf("got %+v, want italy eliminated by france", got)
+	}
+
+	// An even split goes to the power listed first.
+	delete(before, "tun")
+	gs.SupplyCenters["rom"] = diplomacy.Turkey
+	if got := findEliminations(before, gs, powers); len(got) != 1 || got[0].By != "austria" {
+		t.Errorf("got %+v, want italy eliminated by austria", got)
+	}
+
+	// A power that had no centers to lose isn't eliminated again.
+	if got := findEliminations(gs.SupplyCenters, gs, powers); len(got) != 0 {
+		t.Errorf("got %+v, want no eliminations", got)
+	}
+}
+
 func TestPhaseServiceResolveNonActiveGame(t *testing.T) {
 	gameRepo := newMockGameRepo()
 	phaseRepo := newMockPhaseRepo()
@@ -223,7 +355,7 @@ func TestOrderServiceMarkReady(t *testing.T) {
 	ctx := context.Background()
 	gameSvc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
 
-	game, _ := gameSvc.CreateGame(ctx, "Test", "user-1", "", "", "", "", "", false)
+	game, _ := gameSvc.CreateGame(ctx, "Test", "user-1", "", "", "", "", "", "", false, model.GameRules{})
 	for i := 2; i <= 7; i++ {
 		gameSvc.JoinGame(ctx, game.ID, fmt.Sprintf("user-%d", i))
 	}
@@ -259,7 +391,7 @@ func TestOrderServiceUnmarkReady(t *testing.T) {
 	ctx := context.Background()
 	gameSvc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
 
-	game, _ := gameSvc.CreateGame(ctx, "Test", "user-1", "", "", "", "", "", false)
+	game, _ := gameSvc.CreateGame(ctx, "Test", "user-1", "", "", "", "", "", "", false, model.GameRules{})
 	for i := 2; i <= 7; i++ {
 		gameSvc.JoinGame(ctx, game.ID, fmt.Sprintf("user-%d", i))
 	}
@@ -285,7 +417,7 @@ func TestOrderServiceMarkReadyNotInGame(t *testing.T) {
 
 	ctx := context.Background()
 	gameSvc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
-	game, _ := gameSvc.CreateGame(ctx, "Test", "user-1", "", "", "", "", "", false)
+	game, _ := gameSvc.CreateGame(ctx, "Test", "user-1", "", "", "", "", "", "", false, model.GameRules{})
 
 	_, _, err := orderSvc.MarkReady(ctx, game.ID, "user-99")
 	if err != ErrNotInGame {
@@ -419,11 +551,57 @@ func TestYearLimitEndsDraw(t *testing.T) {
 	}
 }
 
+func TestDrawVotesExpireWithPhase(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	cache := newMockCache()
+	rec := &recordingBroadcaster{}
+	phaseSvc := NewPhaseService(gameRepo, phaseRepo, cache, rec)
+	ctx := context.Background()
+
+	gameID, _ := setupActiveGame(t, gameRepo, phaseRepo, cache)
+	for _, p := range []string{"italy", "france"} {
+		if err := phaseSvc.VoteForDraw(ctx, gameID, p); err != nil {
+			t.Fatalf("VoteForDraw(%s): %v", p, err)
+		}
+	}
+	votes, _ := phaseSvc.DrawVotePowers(ctx, gameID)
+	if fmt.Sprint(votes) != "[france italy]" {
+		t.Fatalf("votes before resolution = %v, want [france italy]", votes)
+	}
+
+	if err := phaseSvc.ResolvePhaseEarly(ctx, gameID); err != nil {
+		t.Fatalf("ResolvePhase: %v", err)
+	}
+
+	votes, _ = phaseSvc.DrawVotePowers(ctx, gameID)
+	if len(votes) != 0 {
+		t.Errorf("votes after resolution = %v, want none", votes)
+	}
+	resets := rec.eventsOfType("draw_votes_reset")
+	if len(resets) != 1 {
+		t.Fatalf("expected 1 draw_votes_reset event, got %d", len(resets))
+	}
+	data := resets[0].data.(map[string]any)
+	if fmt.Sprint(data["expired_votes"]) != "[france italy]" {
+		t.Errorf("expired_votes = %v, want [france italy]", data["expired_votes"])
+	}
+
+	// With no votes outstanding, the next resolution sends no reset.
+	if err := phaseSvc.ResolvePhaseEarly(ctx, gameID); err != nil {
+		t.Fatalf("ResolvePhase: %v", err)
+	}
+	if n := len(rec.eventsOfType("draw_votes_reset")); n != 1 {
+		t.Errorf("expected no new draw_votes_reset event, got %d total", n)
+	}
+}
+
 func TestCleanupStoppedGame(t *testing.T) {
 	gameRepo := newMockGameRepo()
 	phaseRepo := newMockPhaseRepo()
 	cache := newMockCache()
-	phaseSvc := NewPhaseService(gameRepo, phaseRepo, cache, nil)
+	broadcaster := &recordingBroadcaster{}
+	phaseSvc := NewPhaseService(gameRepo, phaseRepo, cache, broadcaster)
 
 	gameID, _ := setupActiveGame(t, gameRepo, phaseRepo, cache)
 
@@ -432,6 +610,13 @@ func TestCleanupStoppedGame(t *testing.T) {
 		t.Fatalf("CleanupStoppedGame: %v", err)
 	}
 
+	if len(broadcaster.eventsOfType("game_ended")) != 1 {
+		t.Error("expected game_ended for the game's subscribers")
+	}
+	if finished := broadcaster.eventsOfType("game_finished"); len(finished) != 1 || !finished[0].lobby {
+		t.Errorf("expected game_finished for the lobby, got %+v", finished)
+	}
+
 	// Cache should be cleaned up
 	if cache.states[gameID] != nil {
 		t.Error("expected game state cleared from cache")
@@ -454,3 +639,206 @@ func TestActivePowers(t *testing.T) {
 		t.Errorf("expected 2 active powers, got %d", len(powers))
 	}
 }
+
+func TestRecoverActiveGamesPaged(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	cache := newMockCache()
+	phaseSvc := NewPhaseService(gameRepo, phaseRepo, cache, nil)
+
+	var ids []string
+	for range 3 {
+		id, _ := setupActiveGame(t, gameRepo, phaseRepo, cache)
+		ids = append(ids, id)
+	}
+	cache.states = make(map[string]json.RawMessage) // simulate a Redis flush
+
+	opts := RecoveryOptions{PageSize: 2, Workers: 1}
+	if err := phaseSvc.RecoverActiveGames(context.Background(), opts); err != nil {
+		t.Fatalf("RecoverActiveGames: %v", err)
+	}
+	for _, id := range ids {
+		if cache.states[id] == nil {
+			t.Errorf("expected state restored for game %s", id)
+		}
+	}
+}
+
+func TestRecoverActiveGamesDefersPastBudget(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	cache := newMockCache()
+	phaseSvc := NewPhaseService(gameRepo, phaseRepo, cache, nil)
+
+	first, _ := setupActiveGame(t, gameRepo, phaseRepo, cache)
+	second, _ := setupActiveGame(t, gameRepo, phaseRepo, cache)
+	cache.states = make(map[string]json.RawMessage)
+
+	opts := RecoveryOptions{PageSize: 10, Workers: 1, Budget: time.Nanosecond}
+	if err := phaseSvc.RecoverActiveGames(context.Background(), opts); err != nil {
+		t.Fatalf("RecoverActiveGames: %v", err)
+	}
+	if len(cache.states) != 0 {
+		t.Fatalf("expected recovery deferred once the budget elapsed, got %d states", len(cache.states))
+	}
+
+	if _, err := phaseSvc.ReadyCount(context.Background(), first); err != nil {
+		t.Fatalf("ReadyCount: %v", err)
+	}
+	if cache.states[first] == nil {
+		t.Error("expected first access to recover the game")
+	}
+	if cache.states[second] != nil {
+		t.Error("expected untouched game to stay pending")
+	}
+}
+
+func TestRecoverActiveGamesRestoresSubmissions(t *testing.T) {
+	ctx := context.Background()
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	redis := newMockCache()
+	gameID, _ := setupActiveGame(t, gameRepo, phaseRepo, redis)
+	cache := NewDurableCache(redis, phaseRepo)
+	orderSvc := NewOrderService(gameRepo, phaseRepo, cache)
+
+	power, units := playerUnits(t, gameRepo, gameID, "user-1")
+	if _, err := orderSvc.SubmitOrders(ctx, gameID, "user-1", []OrderInput{
+		{UnitType: units[0].Type.String(), Location: units[0].Province, Coast: string(units[0].Coast), OrderType: "hold"},
+	}); err != nil {
+		t.Fatalf("SubmitOrders: %v", err)
+	}
+	if _, _, err := orderSvc.MarkReady(ctx, gameID, "user-1"); err != nil {
+		t.Fatalf("MarkReady: %v", err)
+	}
+	submitted, _ := cache.GetOrders(ctx, gameID, power)
+
+	// Restart with an empty Redis.
+	restarted := NewDurableCache(newMockCache(), phaseRepo)
+	phaseSvc := NewPhaseService(gameRepo, phaseRepo, restarted, nil)
+	if err := phaseSvc.RecoverActiveGames(ctx, RecoveryOptions{Workers: 1}); err != nil {
+		t.Fatalf("RecoverActiveGames: %v", err)
+	}
+	if orders, _ := restarted.GetOrders(ctx, gameID, power); string(orders) != string(submitted) {
+		t.Errorf("expected orders %s restored, got %s", submitted, orders)
+	}
+	if ready, _ := restarted.ReadyPowers(ctx, gameID); !slices.Contains(ready, power) {
+		t.Errorf("expected %s ready after recovery, got %v", power, ready)
+	}
+
+	// Resolving the phase drops its submissions.
+	phase, _ := phaseRepo.CurrentPhase(ctx, gameID)
+	if err := phaseSvc.ResolvePhaseEarly(ctx, gameID); err != nil {
+		t.Fatalf("ResolvePhaseEarly: %v", err)
+	}
+	if subs, _ := phaseRepo.Submissions(ctx, phase.ID); len(subs) != 0 {
+		t.Errorf("expected no submissions left for the resolved phase, got %+v", subs)
+	}
+}
+
+func TestDurableCacheRecordsInNamedPhase(t *testing.T) {
+	ctx := context.Background()
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	redis := newMockCache()
+	gameID, _ := setupActiveGame(t, gameRepo, phaseRepo, redis)
+	cache := NewDurableCache(redis, phaseRepo)
+
+	if err := cache.MarkReady(ctx, gameID, "england"); !errors.Is(err, errNoPhase) {
+		t.Fatalf("expected errNoPhase without a phase, got %v", err)
+	}
+
+	// A submission still in flight when its phase resolves must not land
+	// in the next phase.
+	phase, _ := phaseRepo.CurrentPhase(ctx, gameID)
+	phaseSvc := NewPhaseService(gameRepo, phaseRepo, cache, nil)
+	if err := phaseSvc.ResolvePhaseEarly(ctx, gameID); err != nil {
+		t.Fatalf("ResolvePhaseEarly: %v", err)
+	}
+	if err := cache.SetOrders(withPhase(ctx, phase.ID), gameID, "england", json.RawMessage(`[]`)); err != nil {
+		t.Fatalf("SetOrders: %v", err)
+	}
+	if err := cache.MarkReady(withPhase(ctx, phase.ID), gameID, "england"); err != nil {
+		t.Fatalf("MarkReady: %v", err)
+	}
+	next, _ := phaseRepo.CurrentPhase(ctx, gameID)
+	if subs, _ := phaseRepo.Submissions(ctx, next.ID); len(subs) != 0 {
+		t.Errorf("expected nothing recorded in the next phase, got %+v", subs)
+	}
+}
+
+func TestDrawHistory(t *testing.T) {
+	phaseRepo := newMockPhaseRepo()
+	svc := NewPhaseService(newMockGameRepo(), phaseRepo, newMockCache(), nil)
+	ctx := context.Background()
+
+	for _, p := range []struct {
+		year          int
+		season, phase string
+	}{
+		{1901, "spring", "movement"}, {1901, "fall", "movement"}, {1901, "fall", "build"},
+		{1902, "spring", "movement"}, {1902, "fall", "movement"},
+		{1903, "spring", "movement"},
+	} {
+		gs := diplomacy.NewInitialState()
+		gs.Year = p.year
+		state, _ := json.Marshal(gs)
+		phaseRepo.CreatePhase(ctx, "game-1", p.year, p.season, p.phase, state, time.Now())
+	}
+
+	current := diplomacy.NewInitialState()
+	current.Year = 1903
+	history := svc.drawHistory(ctx, "game-1", current)
+	if len(history) != bot.ConcedeYears {
+		t.Fatalf("expected %d positions, got %d", bot.ConcedeYears, len(history))
+	}
+	if history[len(history)-1] != current {
+		t.Error("expected the history to end with the current position")
+	}
+	for i, gs := range history {
+		if want := 1903 - len(history) + 1 + i; gs.Year != want {
+			t.Errorf("position %d: expected year %d, got %d", i, want, gs.Year)
+		}
+	}
+}
+
+func TestBotSubmitReserve(t *testing.T) {
+	tests := []struct {
+		left, want time.Duration
+	}{
+		{30 * time.Second, 6 * time.Second}, // a fifth of the time left
+		{10 * time.Second, 3 * time.Second}, // at least the minimum
+		{4 * time.Second, 2 * time.Second},  // never more than half
+		{100 * time.Millisecond, 50 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		if got := botSubmitReserve(tt.left); got != tt.want {
+			t.Errorf("botSubmitReserve(%v) = %v, want %v", tt.left, got, tt.want)
+		}
+	}
+}
+
+func TestReceivedIntentsReadAnswersOnlyToProposals(t *testing.T) {
+	ctx := context.Background()
+	msgRepo := newMockMessageRepo()
+	phaseSvc := NewPhaseService(newMockGameRepo(), newMockPhaseRepo(), newMockCache(), nil)
+	phaseSvc.SetMessageRepo(msgRepo)
+	game := &model.Game{ID: "game-1", Players: []model.GamePlayer{
+		{UserID: "user-1", Power: "germany"},
+		{UserID: "bot-1", Power: "france", IsBot: true},
+	}}
+	proposal := bot.IntentAttachment(bot.DiplomaticIntent{Type: bot.IntentProposeAlliance, TargetPower: diplomacy.Russia})
+
+	msgRepo.Create(ctx, game.ID, "user-1", "bot-1", "ok", "", nil)
+	msgRepo.Create(ctx, game.ID, "bot-1", "user-1", "Shall we ally against Russia?", "", proposal)
+	msgRepo.Create(ctx, game.ID, "user-1", "bot-1", "Sure, sounds good", "", nil)
+	msgRepo.Create(ctx, game.ID, "user-1", "bot-1", "yes", "", nil)
+
+	received := phaseSvc.receivedIntents(ctx, game.ID, game, "france", diplomacy.StandardMap())
+	if len(received) != 1 {
+		t.Fatalf("expected only the answer to the proposal, got %+v", received)
+	}
+	if got := received[0]; got.Type != bot.IntentAccept || !got.Guessed || got.From != diplomacy.Germany {
+		t.Errorf("expected a guessed acceptance from Germany, got %+v", got)
+	}
+}
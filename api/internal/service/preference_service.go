@@ -0,0 +1,134 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+
+	"github.com/freeeve/polite-betrayal/api/internal/repository"
+)
+
+// Preference errors.
+var (
+	ErrPreferenceNamespace = errors.New("preference namespace must be 1-64 lowercase letters, digits, '_', '-' or '.', starting with a letter")
+	ErrPreferenceValue     = errors.New("preference value must be a JSON object or null")
+	ErrPreferenceTooLarge  = errors.New("preference value too large")
+	ErrTooManyPreferences  = errors.New("too many preference namespaces")
+	ErrPreferenceSchema    = errors.New("invalid preference")
+)
+
+// Preference limits.
+const (
+	MaxPreferenceBytes      = 16 << 10 // per namespace
+	MaxPreferenceNamespaces = 32       // per user
+)
+
+var preferenceNamespaceRe = regexp.MustCompile(`^[a-z][a-z0-9_.-]{0,63}$`)
+
+// PreferenceValidator checks the value of one preference namespace before it
+// is stored. Returned errors are reported to the client.
+type PreferenceValidator func(value json.RawMessage) error
+
+// PreferenceService stores UI and game preferences per user so they follow
+// the user across devices. Each namespace (e.g. "orders", "map") holds an
+// arbitrary JSON object owned by the client.
+type PreferenceService struct {
+	prefRepo repository.PreferenceRepository
+
+	mu         sync.RWMutex
+	validators map[string]PreferenceValidator
+}
+
+// NewPreferenceService creates a PreferenceService.
+func NewPreferenceService(prefRepo repository.PreferenceRepository) *PreferenceService {
+	return &PreferenceService{prefRepo: prefRepo, validators: make(map[string]PreferenceValidator)}
+}
+
+// SetValidator installs a schema check for a namespace, replacing any
+// previous one. A nil validator removes it.
+func (s *PreferenceService) SetValidator(namespace string, v PreferenceValidator) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if v == nil {
+		delete(s.validators, namespace)
+		return
+	}
+	s.validators[namespace] = v
+}
+
+// GetPreferences returns all of a user's preferences keyed by namespace.
+func (s *PreferenceService) GetPreferences(ctx context.Context, userID string) (map[string]json.RawMessage, error) {
+	return s.prefRepo.Get(ctx, userID)
+}
+
+// UpdatePreferences merges patch into the user's preferences: each namespace
+// in patch is replaced by its value, or deleted if the value is null. It
+// returns the preferences after the update.
+func (s *PreferenceService) UpdatePreferences(ctx context.Context, userID string, patch map[string]json.RawMessage) (map[string]json.RawMessage, error) {
+	values := make(map[string]json.RawMessage, len(patch))
+	for ns, raw := range patch {
+		value, err := s.checkPreference(ns, raw)
+		if err != nil {
+			return nil, err
+		}
+		values[ns] = value
+	}
+
+	prefs, err := s.prefRepo.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for ns, value := range values {
+		if value == nil {
+			delete(prefs, ns)
+		} else {
+			prefs[ns] = value
+		}
+	}
+	if len(prefs) > MaxPreferenceNamespaces {
+		return nil, ErrTooManyPreferences
+	}
+	if len(values) == 0 {
+		return prefs, nil
+	}
+	if err := s.prefRepo.Set(ctx, userID, values); err != nil {
+		return nil, err
+	}
+	return prefs, nil
+}
+
+// checkPreference validates one namespace's new value, returning it
+// compacted, or nil if it deletes the namespace.
+func (s *PreferenceService) checkPreference(ns string, raw json.RawMessage) (json.RawMessage, error) {
+	if !preferenceNamespaceRe.MatchString(ns) {
+		return nil, fmt.Errorf("%w: %q", ErrPreferenceNamespace, ns)
+	}
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return nil, nil
+	}
+	if raw[0] != '{' || !json.Valid(raw) {
+		return nil, fmt.Errorf("%w: %s", ErrPreferenceValue, ns)
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrPreferenceValue, ns)
+	}
+	if buf.Len() > MaxPreferenceBytes {
+		return nil, fmt.Errorf("%w: %s is %d bytes, limit %d", ErrPreferenceTooLarge, ns, buf.Len(), MaxPreferenceBytes)
+	}
+
+	s.mu.RLock()
+	validate := s.validators[ns]
+	s.mu.RUnlock()
+	if validate != nil {
+		if err := validate(buf.Bytes()); err != nil {
+			return nil, fmt.Errorf("%w %s: %v", ErrPreferenceSchema, ns, err)
+		}
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestUpdatePreferences(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockPreferenceRepo()
+	svc := NewPreferenceService(repo)
+
+	prefs, err := svc.UpdatePreferences(ctx, "user-1", map[string]json.RawMessage{
+		"orders": json.RawMessage(`{ "mode": "tap" }`),
+		"map":    json.RawMessage(`{"colors": "classic"}`),
+	})
+	if err != nil {
+		t.Fatalf("UpdatePreferences: %v", err)
+	}
+	if string(prefs["orders"]) != `{"mode":"tap"}` || len(prefs) != 2 {
+		t.Errorf("unexpected preferences %s", prefs)
+	}
+
+	// Namespaces left out are kept; null removes one.
+	prefs, err = svc.UpdatePreferences(ctx, "user-1", map[string]json.RawMessage{"map": json.RawMessage(`null`)})
+	if err != nil {
+		t.Fatalf("UpdatePreferences: %v", err)
+	}
+	if _, ok := prefs["map"]; ok || prefs["orders"] == nil {
+		t.Errorf("expected only orders to remain, got %s", prefs)
+	}
+	if got, _ := svc.GetPreferences(ctx, "user-1"); len(got) != 1 {
+		t.Errorf("expected the deletion to be stored, got %s", got)
+	}
+	if got, _ := svc.GetPreferences(ctx, "user-2"); len(got) != 0 {
+		t.Errorf("expected no preferences for another user, got %s", got)
+	}
+}
+
+func TestUpdatePreferencesValidation(t *testing.T) {
+	ctx := context.Background()
+	svc := NewPreferenceService(newMockPreferenceRepo())
+	svc.SetValidator("orders", func(v json.RawMessage) error {
+		var p struct {
+			Mode string `json:"mode"`
+		}
+		if err := json.Unmarshal(v, &p); err != nil || (p.Mode != "tap" && p.Mode != "drag") {
+			return errors.New("mode must be tap or drag")
+		}
+		return nil
+	})
+
+	tests := []struct {
+		name  string
+		patch map[string]json.RawMessage
+		want  error
+	}{
+		{"bad namespace", map[string]json.RawMessage{"Map Colors": json.RawMessage(`{}`)}, ErrPreferenceNamespace},
+		{"not an object", map[string]json.RawMessage{"map": json.RawMessage(`"classic"`)}, ErrPreferenceValue},
+		{"too large", map[string]json.RawMessage{"map": json.RawMessage(`{"x":"` + strings.Repeat("a", MaxPreferenceBytes) + `"}`)}, ErrPreferenceTooLarge},
+		{"schema", map[string]json.RawMessage{"orders": json.RawMessage(`{"mode":"voice"}`)}, ErrPreferenceSchema},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.UpdatePreferences(ctx, "user-1", tt.patch); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	patch := make(map[string]json.RawMessage)
+	for i := range MaxPreferenceNamespaces + 1 {
+		patch[fmt.Sprintf("ns%d", i)] = json.RawMessage(`{}`)
+	}
+	if _, err := svc.UpdatePreferences(ctx, "user-1", patch); !errors.Is(err, ErrTooManyPreferences) {
+		t.Errorf("expected ErrTooManyPreferences, got %v", err)
+	}
+	if _, err := svc.UpdatePreferences(ctx, "user-1", map[string]json.RawMessage{"orders": json.RawMessage(`{"mode":"drag"}`)}); err != nil {
+		t.Errorf("expected a valid value to pass the validator, got %v", err)
+	}
+}
@@ -0,0 +1,310 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/internal/repository"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// DefaultPublicGamesPage and MaxPublicGamesPage bound how many games one
+// public list request returns.
+const (
+	DefaultPublicGamesPage = 50
+	MaxPublicGamesPage     = 100
+)
+
+// DefaultOpeningStatsTTL is how long the opening aggregation is reused before
+// it is recomputed from the finished games.
+const DefaultOpeningStatsTTL = 10 * time.Minute
+
+// PublicGame is a finished game as exposed by the public API: no account IDs,
+// press or unresolved orders.
+type PublicGame struct {
+	ID         string         `json:"id"`
+	Name       string         `json:"name"`
+	Scenario   string         `json:"scenario"`
+	Winner     string         `json:"winner,omitempty"`
+	StartedAt  *time.Time     `json:"started_at,omitempty"`
+	FinishedAt *time.Time     `json:"finished_at,omitempty"`
+	Players    []PublicPlayer `json:"players"`
+}
+
+// PublicPlayer is one power's seat in a PublicGame.
+type PublicPlayer struct {
+	Power         string `json:"power"`
+	IsBot         bool   `json:"is_bot"`
+	BotDifficulty string `json:"bot_difficulty,omitempty"`
+}
+
+// PublicGameDetail is a PublicGame with its resolved phases.
+type PublicGameDetail struct {
+	PublicGame
+	Phases []PublicPhase `json:"phases"`
+}
+
+// PublicPhase is one resolved phase of a PublicGameDetail.
+type PublicPhase struct {
+	Year        int             `json:"year"`
+	Season      string          `json:"season"`
+	PhaseType   string          `json:"phase_type"`
+	StateBefore json.RawMessage `json:"state_before"`
+	StateAfter  json.RawMessage `json:"state_after,omitempty"`
+	Orders      []PublicOrder   `json:"orders"`
+}
+
+// PublicOrder is an adjudicated order.
+type PublicOrder struct {
+	Power       string `json:"power"`
+	UnitType    string `json:"unit_type"`
+	Location    string `json:"location"`
+	OrderType   string `json:"order_type"`
+	Target      string `json:"target,omitempty"`
+	AuxLoc      string `json:"aux_loc,omitempty"`
+	AuxTarget   string `json:"aux_target,omitempty"`
+	AuxUnitType string `json:"aux_unit_type,omitempty"`
+	Result      string `json:"result,omitempty"`
+}
+
+// OpeningStats counts the Spring 1901 orders played by each power across
+// finished standard games.
+type OpeningStats struct {
+	Games     int                       `json:"games"`
+	Powers    map[string][]OpeningCount `json:"powers"`
+	UpdatedAt time.Time                 `json:"updated_at"`
+}
+
+// OpeningCount is how often a power opened with a set of orders, and how
+// often that power went on to win.
+type OpeningCount struct {
+	Orders string `json:"orders"` // DSON, sorted by unit location
+	Games  int    `json:"games"`
+	Wins   int    `json:"wins"`
+}
+
+// PublicLeaderboard is the hall of fame without account IDs.
+type PublicLeaderboard struct {
+	FastestSolos []PublicSolo     `json:"fastest_solos"`
+	TopAchievers []PublicAchiever `json:"top_achievers"`
+}
+
+// PublicSolo is a solo victory on the leaderboard.
+type PublicSolo struct {
+	GameID        string    `json:"game_id"`
+	GameName      string    `json:"game_name"`
+	DisplayName   string    `json:"display_name"`
+	Power         string    `json:"power"`
+	Year          int       `json:"year"`
+	SupplyCenters int       `json:"supply_centers"`
+	FinishedAt    time.Time `json:"finished_at"`
+}
+
+// PublicAchiever is a player ranked by achievements earned.
+type PublicAchiever struct {
+	DisplayName  string `json:"display_name"`
+	Achievements int    `json:"achievements"`
+}
+
+// PublicService serves the read-only public API. Everything it returns is
+// shaped from finished games only.
+type PublicService struct {
+	gameRepo       repository.GameRepository
+	phaseRepo      repository.PhaseRepository
+	achievementSvc *AchievementService
+	openingTTL     time.Duration
+
+	mu       sync.Mutex
+	openings *OpeningStats
+}
+
+// NewPublicService creates a PublicService.
+func NewPublicService(gameRepo repository.GameRepository, phaseRepo repository.PhaseRepository, achievementSvc *AchievementService) *PublicService {
+	return &PublicService{
+		gameRepo:       gameRepo,
+		phaseRepo:      phaseRepo,
+		achievementSvc: achievementSvc,
+		openingTTL:     DefaultOpeningStatsTTL,
+	}
+}
+
+// SetOpeningStatsTTL overrides how long opening stats are cached.
+func (s *PublicService) SetOpeningStatsTTL(ttl time.Duration) {
+	s.openingTTL = ttl
+}
+
+// ListGames returns up to limit finished games, most recently finished
+// first, optionally filtered by name. Pass the last returned ID as afterID to
+// fetch the next page.
+func (s *PublicService) ListGames(ctx context.Context, search, afterID string, limit int) ([]PublicGame, error) {
+	games, err := s.gameRepo.ListFinishedPage(ctx, search, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]PublicGame, 0, len(games))
+	for i := range games {
+		out = append(out, publicGame(&games[i]))
+	}
+	return out, nil
+}
+
+// GetGame returns a finished game with its resolved phases and orders.
+func (s *PublicService) GetGame(ctx context.Context, gameID string) (*PublicGameDetail, error) {
+	game, err := s.gameRepo.FindByID(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	if game == nil || game.Status == "deleted" {
+		return nil, ErrGameNotFound
+	}
+	if game.Status != "finished" {
+		return nil, ErrGameNotFinished
+	}
+	phases, err := s.phaseRepo.ListPhases(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &PublicGameDetail{PublicGame: publicGame(game), Phases: make([]PublicPhase, 0, len(phases))}
+	for _, phase := range phases {
+		if phase.ResolvedAt == nil {
+			continue
+		}
+		orders, err := s.phaseRepo.OrdersByPhase(ctx, phase.ID)
+		if err != nil {
+			return nil, err
+		}
+		pp := PublicPhase{
+			Year:        phase.Year,
+			Season:      phase.Season,
+			PhaseType:   phase.PhaseType,
+			StateBefore: phase.StateBefore,
+			StateAfter:  phase.StateAfter,
+			Orders:      make([]PublicOrder, 0, len(orders)),
+		}
+		for _, o := range orders {
+			pp.Orders = append(pp.Orders, PublicOrder{
+				Power: o.Power, UnitType: o.UnitType, Location: o.Location, OrderType: o.OrderType,
+				Target: o.Target, AuxLoc: o.AuxLoc, AuxTarget: o.AuxTarget, AuxUnitType: o.AuxUnitType,
+				Result: o.Result,
+			})
+		}
+		detail.Phases = append(detail.Phases, pp)
+	}
+	return detail, nil
+}
+
+// OpeningStats returns the cached opening aggregation, recomputing it once
+// it is older than the TTL.
+func (s *PublicService) OpeningStats(ctx context.Context) (*OpeningStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.openings != nil && time.Since(s.openings.UpdatedAt) < s.openingTTL {
+		return s.openings, nil
+	}
+	stats, err := s.computeOpenings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.openings = stats
+	return stats, nil
+}
+
+func (s *PublicService) computeOpenings(ctx context.Context) (*OpeningStats, error) {
+	games, tallies, err := s.phaseRepo.OpeningTallies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]map[string]*OpeningCount)
+	stats := &OpeningStats{Games: games, Powers: make(map[string][]OpeningCount), UpdatedAt: time.Now()}
+	for _, t := range tallies {
+		dson := openingsByPower(t.Orders)[t.Power]
+		if counts[t.Power] == nil {
+			counts[t.Power] = make(map[string]*OpeningCount)
+		}
+		c := counts[t.Power][dson]
+		if c == nil {
+			c = &OpeningCount{Orders: dson}
+			counts[t.Power][dson] = c
+		}
+		c.Games += t.Games
+		c.Wins += t.Wins
+	}
+	for power, byOrders := range counts {
+		list := make([]OpeningCount, 0, len(byOrders))
+		for _, c := range byOrders {
+			list = append(list, *c)
+		}
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Games != list[j].Games {
+				return list[i].Games > list[j].Games
+			}
+			return list[i].Orders < list[j].Orders
+		})
+		stats.Powers[power] = list
+	}
+	return stats, nil
+}
+
+// openingsByPower renders each power's orders as one DSON string with the
+// units in location order, so identical openings compare equal.
+func openingsByPower(orders []model.Order) map[string]string {
+	byPower := make(map[string][]model.Order)
+	for _, o := range orders {
+		byPower[o.Power] = append(byPower[o.Power], o)
+	}
+	out := make(map[string]string, len(byPower))
+	for power, list := range byPower {
+		sort.Slice(list, func(i, j int) bool { return list[i].Location < list[j].Location })
+		dson := make([]diplomacy.DSONOrder, 0, len(list))
+		for _, o := range list {
+			if d, ok := modelOrderToDSON(o); ok {
+				dson = append(dson, d)
+			}
+		}
+		out[power] = diplomacy.FormatDSON(dson)
+	}
+	return out
+}
+
+// Leaderboard returns the hall of fame with account IDs removed.
+func (s *PublicService) Leaderboard(ctx context.Context, limit int) (*PublicLeaderboard, error) {
+	hof, err := s.achievementSvc.HallOfFame(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	lb := &PublicLeaderboard{
+		FastestSolos: make([]PublicSolo, 0, len(hof.FastestSolos)),
+		TopAchievers: make([]PublicAchiever, 0, len(hof.TopAchievers)),
+	}
+	for _, r := range hof.FastestSolos {
+		lb.FastestSolos = append(lb.FastestSolos, PublicSolo{
+			GameID: r.GameID, GameName: r.GameName, DisplayName: r.DisplayName, Power: r.Power,
+			Year: r.Year, SupplyCenters: r.SupplyCenters, FinishedAt: r.FinishedAt,
+		})
+	}
+	for _, a := range hof.TopAchievers {
+		lb.TopAchievers = append(lb.TopAchievers, PublicAchiever{DisplayName: a.DisplayName, Achievements: a.Achievements})
+	}
+	return lb, nil
+}
+
+func publicGame(g *model.Game) PublicGame {
+	pg := PublicGame{
+		ID:         g.ID,
+		Name:       g.Name,
+		Scenario:   g.Scenario,
+		Winner:     g.Winner,
+		StartedAt:  g.StartedAt,
+		FinishedAt: g.FinishedAt,
+		Players:    make([]PublicPlayer, 0, len(g.Players)),
+	}
+	for _, p := range g.Players {
+		pg.Players = append(pg.Players, PublicPlayer{Power: p.Power, IsBot: p.IsBot, BotDifficulty: p.BotDifficulty})
+	}
+	return pg
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+)
+
+func TestPublicGameOmitsPrivateData(t *testing.T) {
+	ctx := context.Background()
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	game, phaseID, _ := setupFinishedGame(t, gameRepo, phaseRepo)
+	phaseRepo.SaveOrders(ctx, []model.Order{
+		{PhaseID: phaseID, Power: "france", UnitType: "army", Location: "par", OrderType: "move", Target: "bur", Result: "succeeded"},
+	})
+	phaseRepo.ResolvePhase(ctx, phaseID, json.RawMessage(`{}`))
+
+	svc := NewPublicService(gameRepo, phaseRepo, NewAchievementService(gameRepo, phaseRepo, newMockAchievementRepo()))
+	detail, err := svc.GetGame(ctx, game.ID)
+	if err != nil {
+		t.Fatalf("GetGame: %v", err)
+	}
+	if len(detail.Phases) != 1 || len(detail.Phases[0].Orders) != 1 || detail.Winner != "france" {
+		t.Fatalf("unexpected detail: %+v", detail)
+	}
+	data, _ := json.Marshal(detail)
+	for _, p := range gameRepo.players[game.ID] {
+		if strings.Contains(string(data), p.UserID) {
+			t.Errorf("public game leaks user ID %s", p.UserID)
+		}
+	}
+
+	games, err := svc.ListGames(ctx, "", "", DefaultPublicGamesPage)
+	if err != nil || len(games) != 1 || len(games[0].Players) != 7 {
+		t.Errorf("expected one finished game with 7 players, got %+v, %v", games, err)
+	}
+	if next, _ := svc.ListGames(ctx, "", games[0].ID, DefaultPublicGamesPage); len(next) != 0 {
+		t.Errorf("expected no games after the last page, got %+v", next)
+	}
+}
+
+func TestPublicGameRequiresFinished(t *testing.T) {
+	ctx := context.Background()
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	gameSvc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
+	game, _ := gameSvc.CreateGame(ctx, "Live", "user-1", "", "", "", "", "", "", false, model.GameRules{})
+
+	svc := NewPublicService(gameRepo, phaseRepo, nil)
+	if _, err := svc.GetGame(ctx, game.ID); !errors.Is(err, ErrGameNotFinished) {
+		t.Errorf("expected ErrGameNotFinished, got %v", err)
+	}
+	if _, err := svc.GetGame(ctx, "missing"); !errors.Is(err, ErrGameNotFound) {
+		t.Errorf("expected ErrGameNotFound, got %v", err)
+	}
+}
+
+func TestOpeningStats(t *testing.T) {
+	ctx := context.Background()
+	phaseRepo := newMockPhaseRepo()
+	phaseRepo.openingGames = 3
+	phaseRepo.openings = []model.OpeningTally{
+		{Power: "france", Games: 2, Wins: 1, Orders: []model.Order{
+			{Power: "france", UnitType: "fleet", Location: "bre", OrderType: "move", Target: "mao"},
+			{Power: "france", UnitType: "army", Location: "par", OrderType: "move", Target: "bur"},
+		}},
+		{Power: "france", Games: 1, Orders: []model.Order{
+			{Power: "france", UnitType: "army", Location: "par", OrderType: "hold"},
+		}},
+	}
+
+	svc := NewPublicService(newMockGameRepo(), phaseRepo, nil)
+	stats, err := svc.OpeningStats(ctx)
+	if err != nil {
+		t.Fatalf("OpeningStats: %v", err)
+	}
+	france := stats.Powers["france"]
+	if stats.Games != 3 || len(france) != 2 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	if france[0].Orders != "F bre - mao ; A par - bur" || france[0].Games != 2 || france[0].Wins != 1 {
+		t.Errorf("unexpected france opening: %+v", france[0])
+	}
+
+	// Cached until the TTL expires.
+	phaseRepo.openings = nil
+	if again, _ := svc.OpeningStats(ctx); again != stats {
+		t.Error("expected cached stats within the TTL")
+	}
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+var (
+	ErrInvalidQuorum      = errors.New("ready quorum must be 0 (off) or between 50 and 99 percent")
+	ErrInvalidQuorumDelay = errors.New("quorum delay must be a duration of at least one minute")
+)
+
+// Ready quorum limits. A quorum of 100% is the same as everyone marking
+// ready, which already resolves a phase at once.
+const (
+	MinReadyQuorum     = 50
+	MaxReadyQuorum     = 99
+	DefaultQuorumDelay = 10 * time.Minute
+)
+
+// UpdateReadyQuorum sets a game's ready quorum: once percent of its human
+// powers have marked ready for delay, a movement phase resolves without waiting for
+// the rest, whose missing orders default as at the deadline. A percent of 0
+// turns it off. Only the creator can change it, and only before the game
+// starts.
+func (s *GameService) UpdateReadyQuorum(ctx context.Context, gameID, userID string, percent int, delay string) error {
+	d, err := ParseReadyQuorum(percent, delay)
+	if err != nil {
+		return err
+	}
+	game, err := s.gameRepo.FindByID(ctx, gameID)
+	if err != nil {
+		return err
+	}
+	if game == nil {
+		return ErrGameNotFound
+	}
+	if game.CreatorID != userID {
+		return ErrNotCreator
+	}
+	if game.Status != "waiting" {
+		return ErrGameNotWaiting
+	}
+	return s.gameRepo.UpdateReadyQuorum(ctx, gameID, percent, quorumInterval(d))
+}
+
+// quorumInterval formats a quorum delay as HH:MM:SS, the form Postgres
+// returns an interval in, so a game reads back the same delay whether it
+// was just created or loaded by FindByID.
+func quorumInterval(d time.Duration) string {
+	d = d.Truncate(time.Second)
+	return fmt.Sprintf("%02d:%02d:%02d", int(d/time.Hour), int(d/time.Minute)%60, int(d/time.Second)%60)
+}
+
+// ParseReadyQuorum validates a ready quorum percent and returns its delay,
+// DefaultQuorumDelay if delay is empty.
+func ParseReadyQuorum(percent int, delay string) (time.Duration, error) {
+	if percent != 0 && (percent < MinReadyQuorum || percent > MaxReadyQuorum) {
+		return 0, ErrInvalidQuorum
+	}
+	if delay == "" {
+		return DefaultQuorumDelay, nil
+	}
+	d, err := time.ParseDuration(delay)
+	if err != nil || d < time.Minute {
+		return 0, ErrInvalidQuorumDelay
+	}
+	return d, nil
+}
+
+// quorumSize returns how many of total powers make a quorum of percent,
+// rounding up so 85% of seven powers needs six.
+func quorumSize(percent, total int) int {
+	return (percent*total + 99) / 100
+}
+
+// CheckReadyQuorum moves the current phase's deadline after a power marks or
+// unmarks ready in a game with a ready quorum. When the quorum is reached,
+// the deadline is pulled forward to the quorum delay from now; if the quorum
+// breaks before then, the original deadline is restored.
+func (s *PhaseService) CheckReadyQuorum(ctx context.Context, gameID string) error {
+	unlock, err := s.lockGame(ctx, gameID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	game, err := s.gameRepo.FindByID(ctx, gameID)
+	if err != nil {
+		return err
+	}
+	if game == nil || game.ReadyQuorum == 0 || game.Status != "active" {
+		return nil
+	}
+	phase, err := s.phaseRepo.CurrentPhase(ctx, gameID)
+	if err != nil || phase == nil {
+		return err
+	}
+	return s.applyReadyQuorum(ctx, game, phase)
+}
+
+// applyReadyQuorum does the work of CheckReadyQuorum with the game lock held.
+func (s *PhaseService) applyReadyQuorum(ctx context.Context, game *model.Game, phase *model.Phase) error {
+	if game.ReadyQuorum == 0 || phase.PhaseType != string(diplomacy.PhaseMovement) {
+		return nil
+	}
+	readyPowers, err := s.cache.ReadyPowers(ctx, game.ID)
+	if err != nil {
+		return fmt.Errorf("ready powers: %w", err)
+	}
+	ready, total := humanReadiness(game, readyPowers)
+	if total > 0 && ready >= quorumSize(game.ReadyQuorum, total) {
+		deadline := time.Now().Add(parseDuration(game.QuorumDelay)).Truncate(time.Second)
+		if !deadline.Before(phase.Deadline) {
+			return nil
+		}
+		// Only the first power to complete the quorum starts its clock.
+		set, err := s.cache.SetQuorumDeadline(ctx, game.ID, phase.Deadline)
+		if err != nil || !set {
+			return err
+		}
+		log.Info().Str("gameId", game.ID).Int("ready", ready).Int("total", total).
+			Time("deadline", deadline).Msg("Ready quorum reached, pulling deadline forward")
+		return s.moveDeadline(ctx, game.ID, phase, deadline, "ready_quorum")
+	}
+
+	original, ok, err := s.cache.ClearQuorumDeadline(ctx, game.ID)
+	if err != nil || !ok {
+		return err
+	}
+	log.Info().Str("gameId", game.ID).Int("ready", ready).Int("total", total).
+		Time("deadline", original).Msg("Ready quorum lost, restoring deadline")
+	return s.moveDeadline(ctx, game.ID, phase, original, "ready_quorum_lost")
+}
+
+// humanReadiness returns how many of a game's human powers are in ready and
+// how many human powers there are. Bots mark ready as soon as they order, so
+// counting them would let a quorum form without the humans it waits for.
+func humanReadiness(game *model.Game, ready []string) (int, int) {
+	readySet := make(map[string]bool, len(ready))
+	for _, p := range ready {
+		readySet[p] = true
+	}
+	var count, total int
+	for _, p := range game.Players {
+		if p.Power == "" || p.IsBot {
+			continue
+		}
+		total++
+		if readySet[p.Power] {
+			count++
+		}
+	}
+	return count, total
+}
+
+// moveDeadline sets a new deadline for the current phase and tells players.
+func (s *PhaseService) moveDeadline(ctx context.Context, gameID string, phase *model.Phase, deadline time.Time, reason string) error {
+	if err := s.phaseRepo.UpdateDeadline(ctx, phase.ID, deadline); err != nil {
+		return err
+	}
+	if err := s.cache.SetTimer(ctx, gameID, deadline); err != nil {
+		return fmt.Errorf("set timer: %w", err)
+	}
+	phase.Deadline = deadline
+	s.broadcaster.BroadcastGameEvent(gameID, "deadline_changed", map[string]any{
+		"deadline":          deadline,
+		"deadline_epoch_ms": deadline.UnixMilli(),
+		"reason":            reason,
+	})
+	return nil
+}
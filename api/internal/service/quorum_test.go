@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+)
+
+func TestParseReadyQuorum(t *testing.T) {
+	if d, err := ParseReadyQuorum(85, ""); err != nil || d != DefaultQuorumDelay {
+		t.Errorf("expected the default delay, got %v, %v", d, err)
+	}
+	if d, err := ParseReadyQuorum(0, "30m"); err != nil || d != 30*time.Minute {
+		t.Errorf("expected 30m, got %v, %v", d, err)
+	}
+	for _, percent := range []int{49, 100, -1} {
+		if _, err := ParseReadyQuorum(percent, ""); !errors.Is(err, ErrInvalidQuorum) {
+			t.Errorf("%d%%: expected ErrInvalidQuorum, got %v", percent, err)
+		}
+	}
+	for _, delay := range []string{"30s", "soon"} {
+		if _, err := ParseReadyQuorum(85, delay); !errors.Is(err, ErrInvalidQuorumDelay) {
+			t.Errorf("%q: expected ErrInvalidQuorumDelay, got %v", delay, err)
+		}
+	}
+	if n := quorumSize(85, 7); n != 6 {
+		t.Errorf("expected 85%% of 7 to need 6 powers, got %d", n)
+	}
+}
+
+func TestReadyQuorumMovesDeadline(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	cache := newMockCache()
+	bc := &recordingBroadcaster{}
+	phaseSvc := NewPhaseService(gameRepo, phaseRepo, cache, bc)
+	ctx := context.Background()
+
+	gameID, powers := setupActiveGame(t, gameRepo, phaseRepo, cache)
+	gameRepo.games[gameID].ReadyQuorum = 85
+	gameRepo.games[gameID].QuorumDelay = "00:30:00"
+	phase, _ := phaseRepo.CurrentPhase(ctx, gameID)
+	original := phase.Deadline
+
+	for _, p := range powers[:5] {
+		cache.MarkReady(ctx, gameID, p)
+	}
+	if err := phaseSvc.CheckReadyQuorum(ctx, gameID); err != nil {
+		t.Fatalf("CheckReadyQuorum: %v", err)
+	}
+	if !phase.Deadline.Equal(original) {
+		t.Fatalf("expected five of seven ready to leave the deadline, got %v", phase.Deadline)
+	}
+
+	cache.MarkReady(ctx, gameID, powers[5])
+	if err := phaseSvc.CheckReadyQuorum(ctx, gameID); err != nil {
+		t.Fatalf("CheckReadyQuorum: %v", err)
+	}
+	if left := time.Until(phase.Deadline); left < 29*time.Minute || left > 30*time.Minute {
+		t.Fatalf("expected the quorum to leave about 30m, got %v", left)
+	}
+	if !cache.timers[gameID].Equal(phase.Deadline) {
+		t.Errorf("expected the timer to match the new deadline, got %v vs %v", cache.timers[gameID], phase.Deadline)
+	}
+	if len(bc.eventsOfType("deadline_changed")) != 1 {
+		t.Error("expected a deadline_changed event")
+	}
+
+	// Later checks while the quorum holds don't restart its clock.
+	quorumDeadline := phase.Deadline
+	phase.Deadline = phase.Deadline.Add(-time.Minute)
+	if err := phaseSvc.CheckReadyQuorum(ctx, gameID); err != nil {
+		t.Fatalf("CheckReadyQuorum: %v", err)
+	}
+	if !phase.Deadline.Equal(quorumDeadline.Add(-time.Minute)) {
+		t.Errorf("expected the quorum clock to keep running, got %v", phase.Deadline)
+	}
+
+	// Losing the quorum restores the original deadline.
+	cache.UnmarkReady(ctx, gameID, powers[0])
+	if err := phaseSvc.CheckReadyQuorum(ctx, gameID); err != nil {
+		t.Fatalf("CheckReadyQuorum: %v", err)
+	}
+	if !phase.Deadline.Equal(original) {
+		t.Errorf("expected the original deadline back, got %v want %v", phase.Deadline, original)
+	}
+
+	// Once the quorum's deadline passes, the phase resolves with the
+	// missing power's units holding.
+	cache.MarkReady(ctx, gameID, powers[0])
+	if err := phaseSvc.CheckReadyQuorum(ctx, gameID); err != nil {
+		t.Fatalf("CheckReadyQuorum: %v", err)
+	}
+	phase.Deadline = time.Now().Add(-time.Minute)
+	if err := phaseSvc.ResolvePhase(ctx, gameID); err != nil {
+		t.Fatalf("ResolvePhase: %v", err)
+	}
+	if phase.ResolvedAt == nil {
+		t.Error("expected the phase to resolve at the quorum deadline")
+	}
+}
+
+func TestReadyQuorumOff(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	cache := newMockCache()
+	phaseSvc := NewPhaseService(gameRepo, phaseRepo, cache, nil)
+	ctx := context.Background()
+
+	gameID, powers := setupActiveGame(t, gameRepo, phaseRepo, cache)
+	phase, _ := phaseRepo.CurrentPhase(ctx, gameID)
+	original := phase.Deadline
+	for _, p := range powers[:6] {
+		cache.MarkReady(ctx, gameID, p)
+	}
+	if err := phaseSvc.CheckReadyQuorum(ctx, gameID); err != nil {
+		t.Fatalf("CheckReadyQuorum: %v", err)
+	}
+	if !phase.Deadline.Equal(original) {
+		t.Errorf("expected no quorum without the option, got %v", phase.Deadline)
+	}
+
+	gameSvc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
+	if err := gameSvc.UpdateReadyQuorum(ctx, gameID, "user-1", 85, ""); !errors.Is(err, ErrGameNotWaiting) {
+		t.Errorf("expected ErrGameNotWaiting once started, got %v", err)
+	}
+}
+
+func TestReadyQuorumCountsHumansOnly(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	cache := newMockCache()
+	phaseSvc := NewPhaseService(gameRepo, phaseRepo, cache, nil)
+	ctx := context.Background()
+
+	gameID, _ := setupActiveGame(t, gameRepo, phaseRepo, cache)
+	gameRepo.games[gameID].ReadyQuorum = 50
+	gameRepo.games[gameID].QuorumDelay = "00:10:00"
+	phase, _ := phaseRepo.CurrentPhase(ctx, gameID)
+	original := phase.Deadline
+
+	// Five bots and two humans: the bots all ready, one human too.
+	players := gameRepo.players[gameID]
+	for i := range players[2:] {
+		players[i+2].IsBot = true
+		cache.MarkReady(ctx, gameID, players[i+2].Power)
+	}
+	cache.MarkReady(ctx, gameID, players[0].Power)
+	if err := phaseSvc.CheckReadyQuorum(ctx, gameID); err != nil {
+		t.Fatalf("CheckReadyQuorum: %v", err)
+	}
+	if left := time.Until(phase.Deadline); left < 9*time.Minute || left > 10*time.Minute {
+		t.Fatalf("expected one of two humans to make a 50%% quorum, got %v left", left)
+	}
+
+	// With only the bots ready there is no quorum, however many bots.
+	cache.UnmarkReady(ctx, gameID, players[0].Power)
+	if err := phaseSvc.CheckReadyQuorum(ctx, gameID); err != nil {
+		t.Fatalf("CheckReadyQuorum: %v", err)
+	}
+	if !phase.Deadline.Equal(original) {
+		t.Errorf("expected bots alone not to hold a quorum, got %v", phase.Deadline)
+	}
+}
+
+func TestCreateGameWithReadyQuorum(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	gameSvc := NewGameService(gameRepo, newMockPhaseRepo(), newMockUserRepo())
+	ctx := context.Background()
+
+	game, err := gameSvc.CreateGame(ctx, "Quorum", "user-1", "24h", "12h", "12h", "", "", "", false,
+		model.GameRules{ReadyQuorum: 85, QuorumDelay: "90m"})
+	if err != nil {
+		t.Fatalf("CreateGame: %v", err)
+	}
+	if game.ReadyQuorum != 85 || game.QuorumDelay != "01:30:00" {
+		t.Errorf("expected an 85%% quorum held for 01:30:00, got %d%% for %q", game.ReadyQuorum, game.QuorumDelay)
+	}
+	if err := gameSvc.UpdateReadyQuorum(ctx, game.ID, "user-1", 60, ""); err != nil {
+		t.Fatalf("UpdateReadyQuorum: %v", err)
+	}
+	if g := gameRepo.games[game.ID]; g.QuorumDelay != "00:10:00" {
+		t.Errorf("expected an update to store the delay the same way, got %q", g.QuorumDelay)
+	}
+
+	if _, err := gameSvc.CreateGame(ctx, "Quorum", "user-1", "24h", "12h", "12h", "", "", "", false,
+		model.GameRules{ReadyQuorum: 100}); !errors.Is(err, ErrInvalidQuorum) {
+		t.Errorf("expected ErrInvalidQuorum, got %v", err)
+	}
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/internal/repository"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// ErrRatingsExist is returned by Backfill when games have already been
+// rated and a reset wasn't asked for.
+var ErrRatingsExist = errors.New("games have already been rated")
+
+// DefaultRating is the Elo rating new users and strategies start from.
+const DefaultRating = 1500
+
+// eloK is the total K-factor a seat's change is spread over. Each game is
+// scored as N-1 pairwise matches, so every pair gets eloK/(N-1).
+const eloK = 32
+
+// RatingService keeps Elo ratings for human players and bot strategies.
+type RatingService struct {
+	gameRepo   repository.GameRepository
+	phaseRepo  repository.PhaseRepository
+	ratingRepo repository.RatingRepository
+}
+
+// NewRatingService creates a RatingService.
+func NewRatingService(gameRepo repository.GameRepository, phaseRepo repository.PhaseRepository, ratingRepo repository.RatingRepository) *RatingService {
+	return &RatingService{
+		gameRepo:   gameRepo,
+		phaseRepo:  phaseRepo,
+		ratingRepo: ratingRepo,
+	}
+}
+
+// ratedSeat is one power of a finished game and who played it.
+type ratedSeat struct {
+	subjectType string
+	subjectID   string
+	rating      float64
+	won         bool
+	scs         int
+	eliminated  int // year the seat lost its last center, 0 if it never did
+}
+
+// RateGame updates the ratings of everyone who played a finished game. Each
+// pair of seats is scored as a match: a solo winner beats everyone, otherwise
+// the seat with more supply centers wins, then the seat eliminated later,
+// and otherwise they draw. Bots are
+// rated per strategy; seats played by the same subject don't play each
+// other, and their changes are summed. It reports false if the game was
+// already rated or isn't finished.
+func (s *RatingService) RateGame(ctx context.Context, gameID string) (bool, error) {
+	game, err := s.gameRepo.FindByID(ctx, gameID)
+	if err != nil {
+		return false, err
+	}
+	if game == nil {
+		return false, ErrGameNotFound
+	}
+	if game.Status != "finished" {
+		return false, nil
+	}
+	_, gs, err := finalState(ctx, s.phaseRepo, gameID)
+	if err != nil || gs == nil {
+		return false, err
+	}
+
+	ratings := make(map[[2]string]float64)
+	var seats []ratedSeat
+	for _, p := range game.Players {
+		if p.Power == "" {
+			continue
+		}
+		seat := ratedSeat{
+			subjectType: model.RatingSubjectUser,
+			subjectID:   p.UserID,
+			won:         game.Winner == p.Power,
+			scs:         gs.SupplyCenterCount(diplomacy.Power(p.Power)),
+			eliminated:  p.EliminatedYear,
+		}
+		if p.IsBot {
+			seat.subjectType, seat.subjectID = model.RatingSubjectStrategy, botStrategy(p.BotDifficulty)
+		}
+		key := [2]string{seat.subjectType, seat.subjectID}
+		r, ok := ratings[key]
+		if !ok {
+			existing, err := s.ratingRepo.Find(ctx, seat.subjectType, seat.subjectID)
+			if err != nil {
+				return false, err
+			}
+			r = DefaultRating
+			if existing != nil {
+				r = existing.Rating
+			}
+			ratings[key] = r
+		}
+		seat.rating = r
+		seats = append(seats, seat)
+	}
+	if len(seats) < 2 {
+		return false, nil
+	}
+
+	deltas := eloDeltas(seats)
+	index := make(map[[2]string]int)
+	var updates []model.RatingUpdate
+	for i, seat := range seats {
+		key := [2]string{seat.subjectType, seat.subjectID}
+		if n, ok := index[key]; ok {
+			updates[n].Delta += deltas[i]
+			updates[n].Won = updates[n].Won || seat.won
+			continue
+		}
+		index[key] = len(updates)
+		updates = append(updates, model.RatingUpdate{
+			SubjectType: seat.subjectType,
+			SubjectID:   seat.subjectID,
+			Initial:     DefaultRating,
+			Delta:       deltas[i],
+			Won:         seat.won,
+		})
+	}
+	return s.ratingRepo.ApplyGame(ctx, gameID, updates)
+}
+
+// BackfillStats counts the outcome of a ratings backfill.
+type BackfillStats struct {
+	Rated   int // games rated
+	Skipped int // games that can't be rated, e.g. without a final state
+}
+
+// Backfill rates every finished game, oldest first, so ratings build up as
+// if each game had been rated when it finished. Ratings depend on the order
+// games are rated in, so rating older games after newer ones would give
+// different ratings: Backfill returns ErrRatingsExist if any game has been
+// rated, unless reset is set, which first deletes every rating and replays
+// all finished games. progress, if not nil, is called after each game.
+func (s *RatingService) Backfill(ctx context.Context, reset bool, progress func(gameID string, rated bool)) (BackfillStats, error) {
+	var stats BackfillStats
+	if reset {
+		if err := s.ratingRepo.Reset(ctx); err != nil {
+			return stats, err
+		}
+	} else {
+		n, err := s.ratingRepo.CountRated(ctx)
+		if err != nil {
+			return stats, err
+		}
+		if n > 0 {
+			return stats, fmt.Errorf("%w: %d games", ErrRatingsExist, n)
+		}
+	}
+	ids, err := s.ratingRepo.ListUnrated(ctx)
+	if err != nil {
+		return stats, err
+	}
+	for _, id := range ids {
+		rated, err := s.RateGame(ctx, id)
+		if err != nil {
+			return stats, fmt.Errorf("rate game %s: %w", id, err)
+		}
+		if rated {
+			stats.Rated++
+		} else {
+			stats.Skipped++
+		}
+		if progress != nil {
+			progress(id, rated)
+		}
+	}
+	return stats, nil
+}
+
+// eloDeltas returns each seat's rating change from scoring every pair of
+// seats as an Elo match.
+func eloDeltas(seats []ratedSeat) []float64 {
+	deltas := make([]float64, len(seats))
+	k := float64(eloK) / float64(len(seats)-1)
+	for i := range seats {
+		for j := i + 1; j < len(seats); j++ {
+			a, b := seats[i], seats[j]
+			if a.subjectType == b.subjectType && a.subjectID == b.subjectID {
+				continue
+			}
+			expected := 1 / (1 + math.Pow(10, (b.rating-a.rating)/400))
+			change := k * (pairScore(a, b) - expected)
+			deltas[i] += change
+			deltas[j] -= change
+		}
+	}
+	return deltas
+}
+
+// pairScore is a's result against b: 1 for a win, 0.5 for a draw.
+func pairScore(a, b ratedSeat) float64 {
+	switch {
+	case a.won || a.scs > b.scs && !b.won:
+		return 1
+	case b.won || b.scs > a.scs:
+		return 0
+	case outlasted(a, b):
+		return 1
+	case outlasted(b, a):
+		return 0
+	}
+	return 0.5
+}
+
+// outlasted reports whether a was eliminated after b, or not at all while
+// b was.
+func outlasted(a, b ratedSeat) bool {
+	return b.eliminated != 0 && (a.eliminated == 0 || a.eliminated > b.eliminated)
+}
+
+// botStrategy names the strategy a bot seat was played with.
+func botStrategy(difficulty string) string {
+	if difficulty == "" {
+		return "easy"
+	}
+	return difficulty
+}
+
+// Leaderboard returns the top rated users and bot strategies.
+func (s *RatingService) Leaderboard(ctx context.Context, limit int) (*model.Leaderboard, error) {
+	users, err := s.ratingRepo.Top(ctx, model.RatingSubjectUser, limit)
+	if err != nil {
+		return nil, err
+	}
+	strategies, err := s.ratingRepo.Top(ctx, model.RatingSubjectStrategy, limit)
+	if err != nil {
+		return nil, err
+	}
+	return &model.Leaderboard{Users: nonNil(users), Strategies: nonNil(strategies)}, nil
+}
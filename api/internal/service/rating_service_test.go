@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+func TestEloDeltas(t *testing.T) {
+	seats := []ratedSeat{
+		{subjectType: model.RatingSubjectUser, subjectID: "a", rating: 1500, won: true, scs: 18},
+		{subjectType: model.RatingSubjectUser, subjectID: "b", rating: 1500, scs: 5},
+		{subjectType: model.RatingSubjectUser, subjectID: "c", rating: 1500, scs: 5},
+	}
+	deltas := eloDeltas(seats)
+	// K per pair is 16: the winner takes 8 from each, b and c draw.
+	want := []float64{16, -8, -8}
+	for i := range want {
+		if math.Abs(deltas[i]-want[i]) > 1e-9 {
+			t.Errorf("seat %d: expected %v, got %v", i, want[i], deltas[i])
+		}
+	}
+
+	// Seats played by the same subject don't score against each other.
+	seats[2].subjectID = "b"
+	deltas = eloDeltas(seats)
+	if math.Abs(deltas[1]+8) > 1e-9 || math.Abs(deltas[2]+8) > 1e-9 {
+		t.Errorf("expected -8 for each of b's seats, got %v", deltas)
+	}
+}
+
+func TestPairScoreElimination(t *testing.T) {
+	early := ratedSeat{eliminated: 1903}
+	late := ratedSeat{eliminated: 1906}
+	survivor := ratedSeat{}
+	for _, tt := range []struct {
+		a, b ratedSeat
+		want float64
+	}{
+		{late, early, 1},
+		{early, late, 0},
+		{survivor, late, 1},
+		{early, early, 0.5},
+		{ratedSeat{scs: 2}, survivor, 1},
+	} {
+		if got := pairScore(tt.a, tt.b); got != tt.want {
+			t.Errorf("pairScore(%+v, %+v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestRateGame(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	ratingRepo := newMockRatingRepo()
+	svc := NewRatingService(gameRepo, phaseRepo, ratingRepo)
+	ctx := context.Background()
+
+	gameID, _ := setupActiveGame(t, gameRepo, phaseRepo, newMockCache())
+	finishGame(t, gameRepo, phaseRepo, gameID, 1906, diplomacy.Austria, 18, "austria")
+
+	rated, err := svc.RateGame(ctx, gameID)
+	if err != nil || !rated {
+		t.Fatalf("RateGame: rated=%v err=%v", rated, err)
+	}
+	winner := userForPower(gameRepo, gameID, diplomacy.Austria)
+	r, _ := ratingRepo.Find(ctx, model.RatingSubjectUser, winner)
+	if r == nil || r.Rating <= DefaultRating || r.Wins != 1 || r.Games != 1 {
+		t.Fatalf("expected the winner's rating to rise, got %+v", r)
+	}
+	loser := userForPower(gameRepo, gameID, diplomacy.France)
+	if r, _ := ratingRepo.Find(ctx, model.RatingSubjectUser, loser); r == nil || r.Rating >= DefaultRating {
+		t.Errorf("expected a loser's rating to fall, got %+v", r)
+	}
+
+	// Rating a game twice changes nothing.
+	if rated, _ := svc.RateGame(ctx, gameID); rated {
+		t.Error("expected the second RateGame to report false")
+	}
+	if r2, _ := ratingRepo.Find(ctx, model.RatingSubjectUser, winner); r2.Games != 1 {
+		t.Errorf("expected 1 game after re-rating, got %d", r2.Games)
+	}
+
+	lb, err := svc.Leaderboard(ctx, 3)
+	if err != nil {
+		t.Fatalf("Leaderboard: %v", err)
+	}
+	if len(lb.Users) != 3 || lb.Users[0].SubjectID != winner || lb.Strategies == nil {
+		t.Errorf("unexpected leaderboard %+v", lb)
+	}
+}
+
+func TestRateGameSkipsUnfinished(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	ratingRepo := newMockRatingRepo()
+	svc := NewRatingService(gameRepo, phaseRepo, ratingRepo)
+
+	gameID, _ := setupActiveGame(t, gameRepo, phaseRepo, newMockCache())
+	if rated, err := svc.RateGame(context.Background(), gameID); rated || err != nil {
+		t.Errorf("expected an active game not to be rated, got rated=%v err=%v", rated, err)
+	}
+	if len(ratingRepo.ratings) != 0 {
+		t.Errorf("expected no ratings, got %d", len(ratingRepo.ratings))
+	}
+}
+
+func TestBackfill(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	ratingRepo := newMockRatingRepo()
+	svc := NewRatingService(gameRepo, phaseRepo, ratingRepo)
+	ctx := context.Background()
+
+	first, _ := setupActiveGame(t, gameRepo, phaseRepo, newMockCache())
+	finishGame(t, gameRepo, phaseRepo, first, 1906, diplomacy.Austria, 18, "austria")
+	second, _ := setupActiveGame(t, gameRepo, phaseRepo, newMockCache())
+	finishGame(t, gameRepo, phaseRepo, second, 1908, diplomacy.France, 18, "france")
+	ratingRepo.finished = []string{first, second}
+
+	var order []string
+	stats, err := svc.Backfill(ctx, false, func(gameID string, _ bool) { order = append(order, gameID) })
+	if err != nil {
+		t.Fatalf("Backfill: %v", err)
+	}
+	if stats.Rated != 2 || stats.Skipped != 0 {
+		t.Errorf("expected 2 games rated, got %+v", stats)
+	}
+	if len(order) != 2 || order[0] != first || order[1] != second {
+		t.Errorf("expected games rated in the order they finished, got %v", order)
+	}
+	winner := userForPower(gameRepo, first, diplomacy.Austria)
+	if r, _ := ratingRepo.Find(ctx, model.RatingSubjectUser, winner); r == nil || r.Games != 2 {
+		t.Errorf("expected 2 rated games for %s, got %+v", winner, r)
+	}
+
+	// Once games are rated, a rerun refuses rather than rating out of order.
+	if _, err := svc.Backfill(ctx, false, nil); !errors.Is(err, ErrRatingsExist) {
+		t.Errorf("expected ErrRatingsExist, got %v", err)
+	}
+
+	// A reset replays every game from scratch.
+	stats, err = svc.Backfill(ctx, true, nil)
+	if err != nil || stats.Rated != 2 {
+		t.Errorf("expected both games rated again, got %+v (%v)", stats, err)
+	}
+	if r, _ := ratingRepo.Find(ctx, model.RatingSubjectUser, winner); r == nil || r.Games != 2 {
+		t.Errorf("expected a reset to leave 2 rated games for %s, got %+v", winner, r)
+	}
+}
@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// RecoveryOptions bounds the work RecoverActiveGames does before the server
+// starts accepting requests.
+type RecoveryOptions struct {
+	PageSize int           // active games fetched per ListActive call
+	Workers  int           // games recovered concurrently
+	Budget   time.Duration // after this, remaining games recover lazily; 0 disables the budget
+}
+
+// DefaultRecoveryOptions keeps startup under half a minute even with thousands
+// of active bot games.
+var DefaultRecoveryOptions = RecoveryOptions{
+	PageSize: 200,
+	Workers:  8,
+	Budget:   20 * time.Second,
+}
+
+// pendingGame is an active game whose recovery was deferred past startup.
+type pendingGame struct {
+	once sync.Once
+	game model.Game
+}
+
+// recoveryLeaseKey is the lease held by the replica recovering active games.
+const recoveryLeaseKey = "recovery:leader"
+
+// RecoverActiveGames rehydrates Redis state for active games from Postgres.
+// Called on server startup to restore timers and game state lost during a restart.
+// Games are paged from the repository and recovered by a bounded worker pool;
+// any game not reached within opts.Budget is recovered on first access instead.
+// With a lease store set, only one replica recovers at a time: one starting
+// while another is recovering leaves the games to it.
+func (s *PhaseService) RecoverActiveGames(ctx context.Context, opts RecoveryOptions) error {
+	if s.leases != nil {
+		l, err := tryLease(ctx, s.leases, recoveryLeaseKey, s.leaseOwner)
+		if err != nil {
+			return fmt.Errorf("take recovery lease: %w", err)
+		}
+		if l == nil {
+			log.Info().Msg("Another replica is recovering active games, skipping")
+			return nil
+		}
+		defer l.Release()
+	}
+	if opts.PageSize <= 0 {
+		opts.PageSize = DefaultRecoveryOptions.PageSize
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = DefaultRecoveryOptions.Workers
+	}
+	var deadline time.Time
+	if opts.Budget > 0 {
+		deadline = time.Now().Add(opts.Budget)
+	}
+
+	var recovered, deferred atomic.Int64
+	jobs := make(chan model.Game)
+	var wg sync.WaitGroup
+	for range opts.Workers {
+		wg.Go(func() {
+			for game := range jobs {
+				if !deadline.IsZero() && time.Now().After(deadline) {
+					s.pendingRecovery.Store(game.ID, &pendingGame{game: game})
+					deferred.Add(1)
+					continue
+				}
+				if s.recoverGame(ctx, game) {
+					recovered.Add(1)
+				}
+			}
+		})
+	}
+
+	var listErr error
+	afterID := ""
+	for {
+		games, err := s.gameRepo.ListActive(ctx, afterID, opts.PageSize)
+		if err != nil {
+			listErr = fmt.Errorf("list active games: %w", err)
+			break
+		}
+		for _, game := range games {
+			jobs <- game
+		}
+		if len(games) < opts.PageSize {
+			break
+		}
+		afterID = games[len(games)-1].ID
+	}
+	close(jobs)
+	wg.Wait()
+
+	if recovered.Load() == 0 && deferred.Load() == 0 && listErr == nil {
+		log.Info().Msg("No active games to recover")
+		return nil
+	}
+	log.Info().Int64("recovered", recovered.Load()).Int64("deferred", deferred.Load()).
+		Msg("Recovered active games after restart")
+	return listErr
+}
+
+// ensureRecovered recovers a game whose startup recovery was deferred. It is
+// a cheap no-op for games that were recovered at startup. Concurrent callers
+// for the same game block until the single recovery completes.
+func (s *PhaseService) ensureRecovered(ctx context.Context, gameID string) {
+	v, ok := s.pendingRecovery.Load(gameID)
+	if !ok {
+		return
+	}
+	p := v.(*pendingGame)
+	p.once.Do(func() {
+		log.Info().Str("gameId", gameID).Msg("Recovering game on first access")
+		s.recoverGame(context.WithoutCancel(ctx), p.game)
+		s.pendingRecovery.Delete(gameID)
+	})
+}
+
+// recoverGame restores one game's Redis state, submitted orders and ready
+// flags, timer, eliminated-power ready flags, and bot orders. It reports
+// whether the game state was restored. It holds the game's lock, so it never
+// runs alongside a resolution on any replica, and leaves state that is
+// still in Redis alone: then the game only needs orders from the bots that
+// had not submitted yet.
+func (s *PhaseService) recoverGame(ctx context.Context, game model.Game) bool {
+	unlock, err := s.lockGame(ctx, game.ID)
+	if err != nil {
+		log.Error().Err(err).Str("gameId", game.ID).Msg("Failed to lock game for recovery")
+		return false
+	}
+	defer unlock()
+
+	live, err := s.cache.GetGameState(ctx, game.ID)
+	if err != nil {
+		log.Error().Err(err).Str("gameId", game.ID).Msg("Failed to read game state during recovery")
+		return false
+	}
+	if live != nil {
+		s.resumeBots(game)
+		return true
+	}
+
+	phase, err := s.phaseRepo.CurrentPhase(ctx, game.ID)
+	if err != nil {
+		log.Error().Err(err).Str("gameId", game.ID).Msg("Failed to get current phase during recovery")
+		return false
+	}
+	if phase == nil {
+		log.Warn().Str("gameId", game.ID).Msg("Active game has no current phase, skipping")
+		return false
+	}
+
+	powers := activePowers(&game)
+
+	// Rehydrate game state from the phase's state_before
+	if err := s.cache.SetGameState(ctx, game.ID, phase.StateBefore); err != nil {
+		log.Error().Err(err).Str("gameId", game.ID).Msg("Failed to restore game state")
+		return false
+	}
+	if err := s.restoreSubmissions(ctx, game.ID, phase.ID); err != nil {
+		log.Warn().Err(err).Str("gameId", game.ID).Msg("Failed to restore submitted orders")
+	}
+
+	// Restore timer if deadline is still in the future
+	if time.Now().Before(phase.Deadline) {
+		if err := s.cache.SetTimer(ctx, game.ID, phase.Deadline); err != nil {
+			log.Error().Err(err).Str("gameId", game.ID).Msg("Failed to restore timer")
+		}
+	}
+
+	// Auto-ready eliminated powers
+	var gs diplomacy.GameState
+	if err := json.Unmarshal(phase.StateBefore, &gs); err != nil {
+		log.Error().Err(err).Str("gameId", game.ID).Msg("Failed to unmarshal state for recovery")
+		return true
+	}
+	if err := s.autoReadyEliminatedPowers(ctx, game.ID, &gs, powers); err != nil {
+		log.Warn().Err(err).Str("gameId", game.ID).Msg("Failed to auto-ready eliminated powers during recovery")
+	}
+
+	// Submit bot orders in a background goroutine
+	gameID := game.ID
+	go func() {
+		botCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := s.SubmitBotOrders(botCtx, gameID); err != nil {
+			log.Error().Err(err).Str("gameId", gameID).Msg("Failed to submit bot orders during recovery")
+		}
+	}()
+
+	log.Debug().Str("gameId", game.ID).Str("phase", phase.PhaseType).
+		Int("year", phase.Year).Str("season", phase.Season).
+		Time("deadline", phase.Deadline).
+		Msg("Recovered game state")
+	return true
+}
+
+// resumeBots submits orders, in the background, for the game's bots that
+// have not marked ready: their searches died with the server that ran them.
+func (s *PhaseService) resumeBots(game model.Game) {
+	go func() {
+		botCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		ready, err := s.cache.ReadyPowers(botCtx, game.ID)
+		if err != nil {
+			log.Error().Err(err).Str("gameId", game.ID).Msg("Failed to read ready powers during recovery")
+			return
+		}
+		for _, p := range game.Players {
+			if !p.IsBot || p.Power == "" || slices.Contains(ready, p.Power) {
+				continue
+			}
+			if err := s.RegenerateBotOrders(botCtx, game.ID, p.Power); err != nil {
+				log.Error().Err(err).Str("gameId", game.ID).Str("power", p.Power).Msg("Failed to submit bot orders during recovery")
+			}
+		}
+	}()
+}
+
+// restoreSubmissions puts back the orders and ready flags players had
+// submitted in the phase before the restart.
+func (s *PhaseService) restoreSubmissions(ctx context.Context, gameID, phaseID string) error {
+	subs, err := s.phaseRepo.Submissions(ctx, phaseID)
+	if err != nil {
+		return err
+	}
+	ctx = withPhase(ctx, phaseID)
+	for _, sub := range subs {
+		if sub.Orders != nil {
+			if err := s.cache.SetOrders(ctx, gameID, sub.Power, sub.Orders); err != nil {
+				return fmt.Errorf("restore orders for %s: %w", sub.Power, err)
+			}
+		}
+		if sub.Ready {
+			if err := s.cache.MarkReady(ctx, gameID, sub.Power); err != nil {
+				return fmt.Errorf("restore ready for %s: %w", sub.Power, err)
+			}
+		}
+	}
+	if len(subs) > 0 {
+		log.Debug().Str("gameId", gameID).Int("powers", len(subs)).Msg("Restored submitted orders")
+	}
+	return nil
+}
@@ -0,0 +1,301 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/internal/repository"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+var (
+	ErrPhaseNotFound    = errors.New("phase not found")
+	ErrPhaseNotResolved = errors.New("phase has not resolved yet")
+)
+
+// GameReplay is every resolved phase of a game in order, with enough detail
+// to animate the whole game from one response.
+type GameReplay struct {
+	GameID string        `json:"game_id"`
+	Status string        `json:"status"`
+	Phases []ReplayPhase `json:"phases"`
+}
+
+// ReplayPhase is one resolved phase: the board before and after, each
+// power's orders with their results, and the supply centers that changed
+// hands.
+type ReplayPhase struct {
+	ID          string                   `json:"id"`
+	Year        int                      `json:"year"`
+	Season      string                   `json:"season"`
+	PhaseType   string                   `json:"phase_type"`
+	StateBefore json.RawMessage          `json:"state_before"`
+	StateAfter  json.RawMessage          `json:"state_after"`
+	Orders      map[string][]model.Order `json:"orders"`
+	SCChanges   []SCChange               `json:"sc_changes"`
+	SCCounts    map[string]int           `json:"sc_counts"`
+}
+
+// SCChange is a supply center changing owner; an empty From means it was
+// neutral.
+type SCChange struct {
+	Province string `json:"province"`
+	From     string `json:"from,omitempty"`
+	To       string `json:"to"`
+}
+
+// ReplayService reconstructs games turn by turn.
+type ReplayService struct {
+	gameRepo  repository.GameRepository
+	phaseRepo repository.PhaseRepository
+}
+
+// NewReplayService creates a ReplayService.
+func NewReplayService(gameRepo repository.GameRepository, phaseRepo repository.PhaseRepository) *ReplayService {
+	return &ReplayService{gameRepo: gameRepo, phaseRepo: phaseRepo}
+}
+
+// Replay returns a game's resolved phases in order. The current phase is
+// left out so its orders stay hidden until it resolves.
+func (s *ReplayService) Replay(ctx context.Context, gameID string) (*GameReplay, error) {
+	game, err := s.gameRepo.FindByID(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	if game == nil || game.Status == "deleted" {
+		return nil, ErrGameNotFound
+	}
+	phases, err := s.phaseRepo.ListPhases(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	replay := &GameReplay{GameID: gameID, Status: game.Status, Phases: []ReplayPhase{}}
+	for _, phase := range phases {
+		if phase.ResolvedAt == nil || len(phase.StateAfter) == 0 {
+			continue
+		}
+		rp, err := s.replayPhase(ctx, phase)
+		if err != nil {
+			return nil, fmt.Errorf("phase %s: %w", phase.ID, err)
+		}
+		replay.Phases = append(replay.Phases, rp)
+	}
+	return replay, nil
+}
+
+func (s *ReplayService) replayPhase(ctx context.Context, phase model.Phase) (ReplayPhase, error) {
+	var before, after diplomacy.GameState
+	if err := json.Unmarshal(phase.StateBefore, &before); err != nil {
+		return ReplayPhase{}, fmt.Errorf("unmarshal state before: %w", err)
+	}
+	if err := json.Unmarshal(phase.StateAfter, &after); err != nil {
+		return ReplayPhase{}, fmt.Errorf("unmarshal state after: %w", err)
+	}
+	orders, err := s.phaseRepo.OrdersByPhase(ctx, phase.ID)
+	if err != nil {
+		return ReplayPhase{}, err
+	}
+
+	rp := ReplayPhase{
+		ID:          phase.ID,
+		Year:        phase.Year,
+		Season:      phase.Season,
+		PhaseType:   phase.PhaseType,
+		StateBefore: phase.StateBefore,
+		StateAfter:  phase.StateAfter,
+		Orders:      make(map[string][]model.Order),
+		SCChanges:   scChanges(&before, &after),
+		SCCounts:    make(map[string]int),
+	}
+	for _, o := range orders {
+		rp.Orders[o.Power] = append(rp.Orders[o.Power], o)
+	}
+	for _, owner := range after.SupplyCenters {
+		if owner != diplomacy.Neutral {
+			rp.SCCounts[string(owner)]++
+		}
+	}
+	return rp, nil
+}
+
+// scChanges lists the supply centers whose owner differs between two states,
+// by province.
+func scChanges(before, after *diplomacy.GameState) []SCChange {
+	changes := []SCChange{}
+	for prov, owner := range after.SupplyCenters {
+		if prev := before.SupplyCenters[prov]; prev != owner {
+			changes = append(changes, SCChange{Province: prov, From: string(prev), To: string(owner)})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Province < changes[j].Province })
+	return changes
+}
+
+// Resimulation is a resolved phase played again with some powers' orders
+// replaced: the hypothetical board after it, every power's orders with their
+// hypothetical results, and the supply centers that would have changed hands.
+// Nothing about it is stored.
+type Resimulation struct {
+	PhaseID    string                   `json:"phase_id"`
+	Year       int                      `json:"year"`
+	Season     string                   `json:"season"`
+	PhaseType  string                   `json:"phase_type"`
+	Alternate  []string                 `json:"alternate"` // powers whose orders were replaced
+	StateAfter *diplomacy.GameState     `json:"state_after"`
+	Orders     map[string][]model.Order `json:"orders"`
+	SCChanges  []SCChange               `json:"sc_changes"`
+	SCCounts   map[string]int           `json:"sc_counts"`
+}
+
+// Resimulate resolves a game's resolved phase again from its stored
+// state_before, with the orders in alternate replacing those the listed
+// powers played; every other power plays its orders as stored. A power given
+// no orders holds (or, in a build phase, gets the civil disorder default).
+// Alternate orders are validated as if submitted then, and the outcome is
+// returned without being persisted.
+func (s *ReplayService) Resimulate(ctx context.Context, gameID, phaseID string, alternate map[string][]OrderInput) (*Resimulation, error) {
+	game, err := s.gameRepo.FindByID(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	if game == nil || game.Status == "deleted" {
+		return nil, ErrGameNotFound
+	}
+	phases, err := s.phaseRepo.ListPhases(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	var phase *model.Phase
+	for i := range phases {
+		if phases[i].ID == phaseID {
+			phase = &phases[i]
+		}
+	}
+	if phase == nil {
+		return nil, ErrPhaseNotFound
+	}
+	if phase.ResolvedAt == nil {
+		return nil, ErrPhaseNotResolved
+	}
+	for power := range alternate {
+		if !slices.Contains(diplomacy.AllPowers(), diplomacy.Power(power)) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidPower, power)
+		}
+	}
+
+	var gs diplomacy.GameState
+	if err := json.Unmarshal(phase.StateBefore, &gs); err != nil {
+		return nil, fmt.Errorf("unmarshal state before: %w", err)
+	}
+	before := gs.Clone()
+	stored, err := s.phaseRepo.OrdersByPhase(ctx, phaseID)
+	if err != nil {
+		return nil, err
+	}
+	played := make(map[string][]diplomacy.DSONOrder)
+	for _, o := range stored {
+		if _, replaced := alternate[o.Power]; replaced {
+			continue
+		}
+		if d, ok := modelOrderToDSON(o); ok {
+			played[o.Power] = append(played[o.Power], d)
+		}
+	}
+
+	m := gs.Map()
+	var resolved []model.Order
+	switch gs.Phase {
+	case diplomacy.PhaseMovement:
+		var orders []diplomacy.Order
+		for power, list := range played {
+			for _, d := range list {
+				orders = append(orders, diplomacy.DSONToOrder(d, diplomacy.Power(power)))
+			}
+		}
+		for power, inputs := range alternate {
+			for _, in := range canonicalInputs(inputs) {
+				o := toEngineOrder(in, diplomacy.Power(power))
+				if err := diplomacy.ValidateOrder(o, &gs, m); err != nil {
+					return nil, fmt.Errorf("%w: %s", ErrInvalidOrder, err)
+				}
+				orders = append(orders, o)
+			}
+		}
+		results, dislodged := diplomacy.ResolveOrders(orders, &gs, m)
+		diplomacy.ApplyResolution(&gs, m, results, dislodged)
+		resolved = resolvedOrdersToModel(phaseID, results)
+	case diplomacy.PhaseRetreat:
+		var orders []diplomacy.RetreatOrder
+		for power, list := range played {
+			for _, d := range list {
+				orders = append(orders, diplomacy.DSONToRetreatOrder(d, diplomacy.Power(power)))
+			}
+		}
+		for power, inputs := range alternate {
+			for _, in := range canonicalInputs(inputs) {
+				o := diplomacy.InferRetreatCoasts(toRetreatOrder(in, diplomacy.Power(power)), &gs, m)
+				if err := diplomacy.ValidateRetreatOrder(o, &gs, m); err != nil {
+					return nil, fmt.Errorf("%w: %s", ErrInvalidOrder, err)
+				}
+				orders = append(orders, o)
+			}
+		}
+		results := diplomacy.ResolveRetreats(orders, &gs, m)
+		diplomacy.ApplyRetreats(&gs, results, m)
+		resolved = retreatResultsToModel(phaseID, results)
+	case diplomacy.PhaseBuild:
+		var orders []diplomacy.BuildOrder
+		for power, list := range played {
+			for _, d := range list {
+				if d.Type != diplomacy.DSONWaive {
+					orders = append(orders, diplomacy.DSONToBuildOrder(d, diplomacy.Power(power)))
+				}
+			}
+		}
+		for power, inputs := range alternate {
+			for _, in := range canonicalInputs(inputs) {
+				o := toBuildOrder(in, diplomacy.Power(power))
+				if err := diplomacy.ValidateBuildOrder(o, &gs, m); err != nil {
+					return nil, fmt.Errorf("%w: %s", ErrInvalidOrder, err)
+				}
+				orders = append(orders, o)
+			}
+		}
+		results := diplomacy.ResolveBuildOrders(orders, &gs, m)
+		diplomacy.ApplyBuildOrders(&gs, results)
+		resolved = buildResultsToModel(phaseID, results)
+	}
+	// As when the phase resolved, centers change hands after the year's
+	// last movement or retreat phase.
+	if gs.IsLastSeason() && gs.Phase != diplomacy.PhaseBuild {
+		diplomacy.UpdateSupplyCenterOwnership(&gs)
+	}
+
+	sim := &Resimulation{
+		PhaseID:    phaseID,
+		Year:       phase.Year,
+		Season:     phase.Season,
+		PhaseType:  phase.PhaseType,
+		Alternate:  slices.Sorted(maps.Keys(alternate)),
+		StateAfter: &gs,
+		Orders:     make(map[string][]model.Order),
+		SCChanges:  scChanges(before, &gs),
+		SCCounts:   make(map[string]int),
+	}
+	for _, o := range resolved {
+		sim.Orders[o.Power] = append(sim.Orders[o.Power], o)
+	}
+	for _, owner := range gs.SupplyCenters {
+		if owner != diplomacy.Neutral {
+			sim.SCCounts[string(owner)]++
+		}
+	}
+	return sim, nil
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+func TestReplayResolvedPhases(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	ctx := context.Background()
+	gameID, _ := setupActiveGame(t, gameRepo, phaseRepo, newMockCache())
+
+	first, _ := phaseRepo.CurrentPhase(ctx, gameID)
+	phaseRepo.SaveOrders(ctx, []model.Order{
+		{PhaseID: first.ID, Power: "france", UnitType: "army", Location: "par", OrderType: "move", Target: "bur", Result: "succeeded"},
+		{PhaseID: first.ID, Power: "france", UnitType: "fleet", Location: "bre", OrderType: "hold", Result: "succeeded"},
+		{PhaseID: first.ID, Power: "germany", UnitType: "army", Location: "mun", OrderType: "hold", Result: "succeeded"},
+	})
+	after := diplomacy.NewInitialState()
+	after.SupplyCenters["bel"] = diplomacy.France
+	after.SupplyCenters["mun"] = diplomacy.France
+	afterJSON, _ := json.Marshal(after)
+	phaseRepo.ResolvePhase(ctx, first.ID, afterJSON)
+
+	// The next, unresolved phase must not appear.
+	second, _ := phaseRepo.CreatePhase(ctx, gameID, 1901, "fall", "movement", afterJSON, first.Deadline)
+	phaseRepo.SaveOrders(ctx, []model.Order{{PhaseID: second.ID, Power: "france", UnitType: "army", Location: "bur", OrderType: "hold"}})
+
+	replay, err := NewReplayService(gameRepo, phaseRepo).Replay(ctx, gameID)
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	if len(replay.Phases) != 1 {
+		t.Fatalf("expected only the resolved phase, got %d", len(replay.Phases))
+	}
+	rp := replay.Phases[0]
+	if len(rp.Orders["france"]) != 2 || len(rp.Orders["germany"]) != 1 {
+		t.Errorf("expected orders grouped by power, got %v", rp.Orders)
+	}
+	want := []SCChange{{Province: "bel", To: "france"}, {Province: "mun", From: "germany", To: "france"}}
+	if len(rp.SCChanges) != len(want) || rp.SCChanges[0] != want[0] || rp.SCChanges[1] != want[1] {
+		t.Errorf("expected SC changes %v, got %v", want, rp.SCChanges)
+	}
+	if rp.SCCounts["france"] != 5 || rp.SCCounts["germany"] != 2 {
+		t.Errorf("unexpected SC counts %v", rp.SCCounts)
+	}
+}
+
+func TestReplayUnknownGame(t *testing.T) {
+	svc := NewReplayService(newMockGameRepo(), newMockPhaseRepo())
+	if _, err := svc.Replay(context.Background(), "missing"); !errors.Is(err, ErrGameNotFound) {
+		t.Errorf("expected ErrGameNotFound, got %v", err)
+	}
+}
+
+func TestResimulatePhase(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	ctx := context.Background()
+	gameID, _ := setupActiveGame(t, gameRepo, phaseRepo, newMockCache())
+	svc := NewReplayService(gameRepo, phaseRepo)
+
+	first, _ := phaseRepo.CurrentPhase(ctx, gameID)
+	phaseRepo.SaveOrders(ctx, []model.Order{
+		{PhaseID: first.ID, Power: "france", UnitType: "army", Location: "par", OrderType: "move", Target: "bur", Result: "bounced"},
+		{PhaseID: first.ID, Power: "germany", UnitType: "army", Location: "mun", OrderType: "move", Target: "bur", Result: "bounced"},
+	})
+	if _, err := svc.Resimulate(ctx, gameID, first.ID, nil); !errors.Is(err, ErrPhaseNotResolved) {
+		t.Errorf("expected ErrPhaseNotResolved, got %v", err)
+	}
+	phaseRepo.ResolvePhase(ctx, first.ID, first.StateBefore)
+
+	// Without Germany's move, Paris walks into Burgundy.
+	alternate := map[string][]OrderInput{"germany": {{UnitType: "army", Location: "mun", OrderType: "hold"}}}
+	sim, err := svc.Resimulate(ctx, gameID, first.ID, alternate)
+	if err != nil {
+		t.Fatalf("Resimulate: %v", err)
+	}
+	if u := sim.StateAfter.UnitAt("bur"); u == nil || u.Power != diplomacy.France {
+		t.Errorf("expected a French army in bur, got %v", u)
+	}
+	if len(sim.Orders["france"]) != 1 || sim.Orders["france"][0].Result != "succeeds" {
+		t.Errorf("expected France's move to succeed, got %v", sim.Orders["france"])
+	}
+	if len(sim.Alternate) != 1 || sim.Alternate[0] != "germany" {
+		t.Errorf("expected germany as the alternate power, got %v", sim.Alternate)
+	}
+
+	// Nothing is stored.
+	stored, _ := phaseRepo.OrdersByPhase(ctx, first.ID)
+	for _, o := range stored {
+		if o.Power == "germany" && o.OrderType != "move" {
+			t.Errorf("stored orders changed: %v", o)
+		}
+	}
+
+	bad := map[string][]OrderInput{"germany": {{UnitType: "army", Location: "mun", OrderType: "move", Target: "par"}}}
+	if _, err := svc.Resimulate(ctx, gameID, first.ID, bad); !errors.Is(err, ErrInvalidOrder) {
+		t.Errorf("expected ErrInvalidOrder, got %v", err)
+	}
+	if _, err := svc.Resimulate(ctx, gameID, first.ID, map[string][]OrderInput{"prussia": nil}); !errors.Is(err, ErrInvalidPower) {
+		t.Errorf("expected ErrInvalidPower, got %v", err)
+	}
+	if _, err := svc.Resimulate(ctx, gameID, "missing", nil); !errors.Is(err, ErrPhaseNotFound) {
+		t.Errorf("expected ErrPhaseNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,360 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/internal/render"
+	"github.com/freeeve/polite-betrayal/api/internal/repository"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+const (
+	maxPressExcerpts = 5   // per phase
+	maxExcerptLength = 280 // runes
+)
+
+// MapRenderer draws the board as an SVG image with a caption.
+type MapRenderer func(gs *diplomacy.GameState, caption string) []byte
+
+// GameReport is an after-action report of a finished game: a turn-by-turn
+// narration, final statistics and the press players agreed to share.
+type GameReport struct {
+	GameID  string
+	Name    string
+	Winner  string            // empty = draw
+	Players map[string]string // power -> display name
+	Phases  []ReportPhase
+	Summary *model.GameSummary
+	Maps    bool // whether phases carry maps
+}
+
+// ReportPhase narrates one resolved phase.
+type ReportPhase struct {
+	Title      string // e.g. "Spring 1901 Movement"
+	Commentary []string
+	MapSVG     []byte // board after the phase; nil without a renderer
+	Press      []PressExcerpt
+}
+
+// PressExcerpt is a message quoted in a report. To is a power, or empty for
+// public press.
+type PressExcerpt struct {
+	From    string
+	To      string
+	Content string
+}
+
+// ReportService writes after-action reports of finished games, as Markdown
+// or PDF, for community write-ups. Press is quoted only with consent: public
+// press needs its sender to share press, private press its recipient too.
+// Bots always consent; channel press is never quoted.
+type ReportService struct {
+	gameRepo    repository.GameRepository
+	messageRepo repository.MessageRepository
+	userRepo    repository.UserRepository
+	replaySvc   *ReplayService
+	summarySvc  *SummaryService
+	renderMap   MapRenderer
+}
+
+// NewReportService creates a ReportService without maps.
+func NewReportService(gameRepo repository.GameRepository, messageRepo repository.MessageRepository, userRepo repository.UserRepository, replaySvc *ReplayService, summarySvc *SummaryService) *ReportService {
+	return &ReportService{gameRepo: gameRepo, messageRepo: messageRepo, userRepo: userRepo, replaySvc: replaySvc, summarySvc: summarySvc}
+}
+
+// SetMapRenderer adds a map after each phase, plus the final map and
+// supply-center graph, to Markdown reports. PDF reports are text only.
+func (s *ReportService) SetMapRenderer(fn MapRenderer) {
+	s.renderMap = fn
+}
+
+// Report builds the report of a finished game.
+func (s *ReportService) Report(ctx context.Context, gameID string) (*GameReport, error) {
+	game, err := s.gameRepo.FindByID(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	if game == nil || game.Status == "deleted" {
+		return nil, ErrGameNotFound
+	}
+	if game.Status != "finished" {
+		return nil, ErrGameNotFinished
+	}
+	replay, err := s.replaySvc.Replay(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	summary, err := s.summarySvc.Summary(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &GameReport{
+		GameID:  gameID,
+		Name:    game.Name,
+		Winner:  game.Winner,
+		Players: make(map[string]string),
+		Summary: summary,
+		Maps:    s.renderMap != nil,
+	}
+	sharing := make(map[string]bool) // user ID -> consents to quoting
+	for _, p := range game.Players {
+		if p.Power == "" {
+			continue
+		}
+		name := p.Power
+		if u, err := s.userRepo.FindByID(ctx, p.UserID); err != nil {
+			return nil, err
+		} else if u != nil {
+			name = u.DisplayName
+			sharing[p.UserID] = p.IsBot || u.SharePress
+		}
+		report.Players[p.Power] = name
+	}
+	press, err := s.pressExcerpts(ctx, game, sharing)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rp := range replay.Phases {
+		var after diplomacy.GameState
+		if err := json.Unmarshal(rp.StateAfter, &after); err != nil {
+			return nil, fmt.Errorf("phase %s: unmarshal state: %w", rp.ID, err)
+		}
+		phase := ReportPhase{
+			Title:      after.Calendar().PhaseTitle(rp.Year, diplomacy.Season(rp.Season), diplomacy.PhaseType(rp.PhaseType)),
+			Commentary: phaseCommentary(rp),
+			Press:      press[rp.ID],
+		}
+		if s.renderMap != nil {
+			phase.MapSVG = s.renderMap(&after, phase.Title)
+		}
+		report.Phases = append(report.Phases, phase)
+	}
+	return report, nil
+}
+
+// pressExcerpts returns the quotable press of a game by phase, at most
+// maxPressExcerpts per phase.
+func (s *ReportService) pressExcerpts(ctx context.Context, game *model.Game, sharing map[string]bool) (map[string][]PressExcerpt, error) {
+	messages, err := s.messageRepo.ListAllByGame(ctx, game.ID)
+	if err != nil {
+		return nil, err
+	}
+	powerByUser := powersByUser(game)
+	excerpts := make(map[string][]PressExcerpt)
+	for _, msg := range messages {
+		from := powerByUser[msg.SenderID]
+		if msg.PhaseID == "" || msg.ChannelID != "" || from == "" || !sharing[msg.SenderID] {
+			continue
+		}
+		if msg.RecipientID != "" && !sharing[msg.RecipientID] {
+			continue
+		}
+		if len(excerpts[msg.PhaseID]) >= maxPressExcerpts {
+			continue
+		}
+		content := []rune(strings.TrimSpace(msg.Content))
+		if len(content) > maxExcerptLength {
+			content = append(content[:maxExcerptLength], []rune("...")...)
+		}
+		excerpts[msg.PhaseID] = append(excerpts[msg.PhaseID], PressExcerpt{
+			From:    from,
+			To:      powerByUser[msg.RecipientID],
+			Content: string(content),
+		})
+	}
+	return excerpts, nil
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// phaseCommentary describes what happened in a phase: centers changing
+// hands, dislodgements and standoffs, retreats, builds and disbands.
+func phaseCommentary(rp ReplayPhase) []string {
+	var lines []string
+	var standoffs []string
+	for _, p := range diplomacy.AllPowers() {
+		power := string(p)
+		var built, disbanded []string
+		for _, o := range rp.Orders[power] {
+			unit := o.UnitType + " in " + o.Location
+			switch {
+			case o.Result == "dislodged":
+				lines = append(lines, fmt.Sprintf("%s's %s was dislodged.", capitalize(power), unit))
+			case o.OrderType == "move" && o.Result == "bounced" && !slices.Contains(standoffs, o.Target):
+				standoffs = append(standoffs, o.Target)
+			case o.OrderType == "retreat_move" && o.Result == "succeeds":
+				lines = append(lines, fmt.Sprintf("%s's %s retreated to %s.", capitalize(power), unit, o.Target))
+			case o.OrderType == "retreat_disband" || o.OrderType == "retreat_move":
+				lines = append(lines, fmt.Sprintf("%s's %s was destroyed.", capitalize(power), unit))
+			case o.OrderType == "build" && o.Result == "succeeds":
+				built = append(built, unit)
+			case o.OrderType == "disband" && o.Result == "succeeds":
+				disbanded = append(disbanded, unit)
+			}
+		}
+		if len(built) > 0 {
+			lines = append(lines, fmt.Sprintf("%s built: %s.", capitalize(power), strings.Join(built, ", ")))
+		}
+		if len(disbanded) > 0 {
+			lines = append(lines, fmt.Sprintf("%s disbanded: %s.", capitalize(power), strings.Join(disbanded, ", ")))
+		}
+	}
+	if len(standoffs) > 0 {
+		slices.Sort(standoffs)
+		lines = append(lines, "Standoffs in "+strings.Join(standoffs, ", ")+".")
+	}
+
+	if len(rp.SCChanges) == 0 {
+		if rp.PhaseType == "movement" {
+			lines = append(lines, "No supply centers changed hands.")
+		}
+		return lines
+	}
+	for _, c := range rp.SCChanges {
+		switch {
+		case c.To == "":
+			lines = append(lines, fmt.Sprintf("%s lost %s.", capitalize(c.From), c.Province))
+		case c.From == "":
+			lines = append(lines, fmt.Sprintf("%s took %s.", capitalize(c.To), c.Province))
+		default:
+			lines = append(lines, fmt.Sprintf("%s took %s from %s.", capitalize(c.To), c.Province, capitalize(c.From)))
+		}
+	}
+	var counts []string
+	for _, p := range diplomacy.AllPowers() {
+		if n, ok := rp.SCCounts[string(p)]; ok {
+			counts = append(counts, fmt.Sprintf("%s %d", capitalize(string(p)), n))
+		}
+	}
+	return append(lines, "Supply centers: "+strings.Join(counts, ", ")+".")
+}
+
+// result describes how the game ended.
+func (r *GameReport) result() string {
+	if r.Winner != "" {
+		return fmt.Sprintf("%s won in %d.", capitalize(r.Winner), r.Summary.FinalYear)
+	}
+	return fmt.Sprintf("The game ended in a draw in %d.", r.Summary.FinalYear)
+}
+
+// playerLabel names a power and who played it, e.g. "France (alice)".
+func (r *GameReport) playerLabel(power string) string {
+	if name := r.Players[power]; name != "" && name != power {
+		return fmt.Sprintf("%s (%s)", capitalize(power), name)
+	}
+	return capitalize(power)
+}
+
+// speaker describes who sent a press excerpt, e.g. "France to England".
+func (e PressExcerpt) speaker() string {
+	to := "all"
+	if e.To != "" {
+		to = capitalize(e.To)
+	}
+	return capitalize(e.From) + " to " + to
+}
+
+// statLine summarizes a power's game, e.g. "12 centers (peak 14 in 1906),
+// 80/95 orders succeeded".
+func statLine(ps model.PowerSummary) string {
+	line := fmt.Sprintf("%d centers (peak %d in %d), %d/%d orders succeeded",
+		ps.FinalCenters, ps.PeakCenters, ps.PeakYear, ps.OrdersSucceeded, ps.OrdersIssued)
+	if ps.EliminatedYear != 0 {
+		line += fmt.Sprintf(", eliminated in %d", ps.EliminatedYear)
+		if ps.EliminatedBy != "" {
+			line += " by " + capitalize(ps.EliminatedBy)
+		}
+	}
+	return line
+}
+
+// Markdown renders the report as Markdown, with maps as inline SVG images
+// when the report has them.
+func (r *GameReport) Markdown() []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s: after-action report\n\n%s\n\n", r.Name, r.result())
+	b.WriteString("## Players\n\n")
+	for _, p := range diplomacy.AllPowers() {
+		if _, ok := r.Players[string(p)]; ok {
+			fmt.Fprintf(&b, "- %s\n", r.playerLabel(string(p)))
+		}
+	}
+
+	for _, phase := range r.Phases {
+		fmt.Fprintf(&b, "\n## %s\n\n", phase.Title)
+		for _, line := range phase.Commentary {
+			fmt.Fprintf(&b, "- %s\n", line)
+		}
+		if len(phase.MapSVG) > 0 {
+			fmt.Fprintf(&b, "\n%s\n", markdownImage(phase.Title, phase.MapSVG))
+		}
+		for _, e := range phase.Press {
+			fmt.Fprintf(&b, "\n> **%s:** %s\n", e.speaker(), strings.ReplaceAll(e.Content, "\n", "\n> "))
+		}
+	}
+
+	b.WriteString("\n## Final statistics\n\n| Power | Centers | Peak | Orders succeeded | Eliminated |\n|---|---|---|---|---|\n")
+	for _, ps := range r.Summary.Powers {
+		eliminated := ""
+		if ps.EliminatedYear != 0 {
+			eliminated = fmt.Sprintf("%d (#%d)", ps.EliminatedYear, ps.EliminationRank)
+			if ps.EliminatedBy != "" {
+				eliminated += " by " + capitalize(ps.EliminatedBy)
+			}
+		}
+		fmt.Fprintf(&b, "| %s | %d | %d (%d) | %d/%d | %s |\n", strings.ReplaceAll(r.playerLabel(ps.Power), "|", `\|`),
+			ps.FinalCenters, ps.PeakCenters, ps.PeakYear, ps.OrdersSucceeded, ps.OrdersIssued, eliminated)
+	}
+	if r.Maps {
+		fmt.Fprintf(&b, "\n%s\n\n%s\n", markdownImage("Final map", []byte(r.Summary.MapSVG)), markdownImage("Supply centers", []byte(r.Summary.SCGraphSVG)))
+	}
+	return []byte(b.String())
+}
+
+// markdownImage embeds an SVG image as a data URI.
+func markdownImage(alt string, svg []byte) string {
+	return fmt.Sprintf("![%s](data:image/svg+xml;base64,%s)", alt, base64.StdEncoding.EncodeToString(svg))
+}
+
+// PDF renders the report as a text-only PDF.
+func (r *GameReport) PDF() []byte {
+	doc := render.NewPDF()
+	doc.Heading(r.Name + ": after-action report")
+	doc.Paragraph(r.result())
+	var players []string
+	for _, p := range diplomacy.AllPowers() {
+		if _, ok := r.Players[string(p)]; ok {
+			players = append(players, r.playerLabel(string(p)))
+		}
+	}
+	doc.Paragraph("Players: " + strings.Join(players, ", ") + ".")
+
+	for _, phase := range r.Phases {
+		doc.Subheading(phase.Title)
+		for _, line := range phase.Commentary {
+			doc.Paragraph(line)
+		}
+		for _, e := range phase.Press {
+			doc.Quote(e.speaker() + ": " + e.Content)
+		}
+	}
+
+	doc.Heading("Final statistics")
+	for _, ps := range r.Summary.Powers {
+		doc.Paragraph(r.playerLabel(ps.Power) + ": " + statLine(ps) + ".")
+	}
+	return doc.Bytes()
+}
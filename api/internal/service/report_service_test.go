@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+func TestGameReport(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	messageRepo := newMockMessageRepo()
+	userRepo := newMockUserRepo()
+	ctx := context.Background()
+	game, phaseID, users := setupFinishedGame(t, gameRepo, phaseRepo)
+	// user-1 is the only human; pick two bot powers to talk to each other.
+	human, humanPower := "user-1", ""
+	var bots []string
+	for _, p := range diplomacy.AllPowers() {
+		id := users[string(p)]
+		userRepo.users[id] = &model.User{ID: id, DisplayName: string(p) + "-player"}
+		if id == human {
+			humanPower = string(p)
+		} else {
+			bots = append(bots, id)
+		}
+	}
+
+	phaseRepo.SaveOrders(ctx, []model.Order{
+		{PhaseID: phaseID, Power: "france", UnitType: "army", Location: "par", OrderType: "move", Target: "bur", Result: "bounced"},
+		{PhaseID: phaseID, Power: "germany", UnitType: "army", Location: "mun", OrderType: "move", Target: "bur", Result: "bounced"},
+	})
+	after := diplomacy.NewInitialState()
+	after.SupplyCenters["bel"] = diplomacy.France
+	afterJSON, _ := json.Marshal(after)
+	phaseRepo.ResolvePhase(ctx, phaseID, afterJSON)
+
+	messageRepo.Create(ctx, game.ID, human, "", "Vive la France", phaseID, nil)
+	messageRepo.Create(ctx, game.ID, bots[0], human, "Bur is mine", phaseID, nil)
+	messageRepo.Create(ctx, game.ID, bots[0], bots[1], "Let's split France", phaseID, nil)
+	messageRepo.Create(ctx, game.ID, bots[2], "", "Peace in our time", phaseID, nil)
+
+	svc := NewReportService(gameRepo, messageRepo, userRepo, NewReplayService(gameRepo, phaseRepo),
+		NewSummaryService(gameRepo, phaseRepo, newMockSummaryRepo()))
+	report, err := svc.Report(ctx, game.ID)
+	if err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	if len(report.Phases) != 1 {
+		t.Fatalf("expected 1 phase, got %d", len(report.Phases))
+	}
+	phase := report.Phases[0]
+	if phase.Title != "Spring 1901 Movement" {
+		t.Errorf("unexpected title %q", phase.Title)
+	}
+	commentary := strings.Join(phase.Commentary, " ")
+	for _, want := range []string{"Standoffs in bur.", "France took bel.", "Supply centers: Austria 3, England 3, France 4"} {
+		if !strings.Contains(commentary, want) {
+			t.Errorf("expected commentary to contain %q, got %q", want, commentary)
+		}
+	}
+	// Only press between consenting players is quoted; the human hasn't opted in.
+	if len(phase.Press) != 2 || phase.Press[0].Content != "Let's split France" || phase.Press[1].To != "" {
+		t.Errorf("expected only bot press, got %+v", phase.Press)
+	}
+	if phase.MapSVG != nil {
+		t.Error("expected no map without a renderer")
+	}
+
+	userRepo.UpdateSharePress(ctx, human, true)
+	svc.SetMapRenderer(func(gs *diplomacy.GameState, caption string) []byte { return []byte("<svg>" + caption + "</svg>") })
+	report, err = svc.Report(ctx, game.ID)
+	if err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	if len(report.Phases[0].Press) != 4 {
+		t.Errorf("expected all press once the human consents, got %+v", report.Phases[0].Press)
+	}
+	md := string(report.Markdown())
+	for _, want := range []string{"# Export: after-action report", "France won in 1901.", "## Spring 1901 Movement",
+		"to " + capitalize(humanPower) + ":** Bur is mine", "| France (france-player) |", "data:image/svg+xml;base64,"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("expected Markdown to contain %q", want)
+		}
+	}
+	if pdf := report.PDF(); !strings.HasPrefix(string(pdf), "%PDF-") {
+		t.Error("expected a PDF document")
+	}
+}
+
+func TestGameReportRequiresFinishedGame(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	gameID, _ := setupActiveGame(t, gameRepo, phaseRepo, newMockCache())
+	svc := NewReportService(gameRepo, newMockMessageRepo(), newMockUserRepo(), NewReplayService(gameRepo, phaseRepo),
+		NewSummaryService(gameRepo, phaseRepo, newMockSummaryRepo()))
+	if _, err := svc.Report(context.Background(), gameID); !errors.Is(err, ErrGameNotFinished) {
+		t.Errorf("expected ErrGameNotFinished, got %v", err)
+	}
+}
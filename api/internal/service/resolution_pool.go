@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ResolutionPoolOptions sizes the pool that resolves expired phases.
+type ResolutionPoolOptions struct {
+	Workers   int // phases resolved concurrently
+	QueueSize int // games waiting for a worker; further expirations are rejected
+}
+
+// DefaultResolutionPoolOptions keeps a burst of simultaneous expirations
+// (a botmatch batch or tournament round) from hammering Postgres.
+var DefaultResolutionPoolOptions = ResolutionPoolOptions{
+	Workers:   4,
+	QueueSize: 1024,
+}
+
+// ResolutionStats is a snapshot of the pool's load, for backpressure
+// monitoring.
+type ResolutionStats struct {
+	Workers   int   `json:"workers"`
+	Capacity  int   `json:"capacity"`
+	Queued    int   `json:"queued"`
+	InFlight  int   `json:"in_flight"`
+	Completed int64 `json:"completed"`
+	Failed    int64 `json:"failed"`
+	Coalesced int64 `json:"coalesced"` // expirations for a game already queued
+	Rejected  int64 `json:"rejected"`  // expirations dropped because the queue was full
+	AvgWaitMs int64 `json:"avg_wait_ms"`
+	MaxWaitMs int64 `json:"max_wait_ms"`
+}
+
+// queuedGame is a game waiting for a worker.
+type queuedGame struct {
+	gameID   string
+	enqueued time.Time
+}
+
+// ResolutionPool resolves expired phases on a bounded set of workers. Games
+// are served first come, first served and each game is queued at most once:
+// an expiration for a queued game is coalesced, and one for a game being
+// resolved re-queues it at the back once the worker finishes, so a game
+// whose deadlines keep firing can't starve the others.
+type ResolutionPool struct {
+	resolve func(ctx context.Context, gameID string) error
+	opts    ResolutionPoolOptions
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	queue   []queuedGame
+	queued  map[string]bool
+	running map[string]bool // value: expired again while running
+	closed  bool
+	stats   ResolutionStats
+	waitSum time.Duration
+	started int64 // resolutions begun, for the average wait
+}
+
+// NewResolutionPool creates a pool that calls resolve for each expired game.
+// Call Start to run the workers.
+func NewResolutionPool(resolve func(ctx context.Context, gameID string) error, opts ResolutionPoolOptions) *ResolutionPool {
+	if opts.Workers <= 0 {
+		opts.Workers = DefaultResolutionPoolOptions.Workers
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = DefaultResolutionPoolOptions.QueueSize
+	}
+	p := &ResolutionPool{
+		resolve: resolve,
+		opts:    opts,
+		queued:  make(map[string]bool),
+		running: make(map[string]bool),
+	}
+	p.cond = sync.NewCond(&p.mu)
+	return p
+}
+
+// Start runs the workers until ctx is cancelled. Games still queued then are
+// dropped; the deadline poller picks them up after a restart.
+func (p *ResolutionPool) Start(ctx context.Context) {
+	for range p.opts.Workers {
+		go p.work(ctx)
+	}
+	go func() {
+		<-ctx.Done()
+		p.mu.Lock()
+		p.closed = true
+		p.mu.Unlock()
+		p.cond.Broadcast()
+	}()
+}
+
+// Enqueue schedules the game's phase for resolution. It reports false when
+// the queue is full; the poller retries the game on its next pass.
+func (p *ResolutionPool) Enqueue(gameID string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.queued[gameID] {
+		p.stats.Coalesced++
+		return true
+	}
+	if _, ok := p.running[gameID]; ok {
+		p.running[gameID] = true
+		p.stats.Coalesced++
+		return true
+	}
+	if len(p.queue) >= p.opts.QueueSize {
+		p.stats.Rejected++
+		log.Warn().Str("gameId", gameID).Int("queued", len(p.queue)).Msg("Resolution queue full, deferring to poller")
+		return false
+	}
+	p.push(gameID)
+	return true
+}
+
+// push appends a game to the queue. Callers hold p.mu.
+func (p *ResolutionPool) push(gameID string) {
+	p.queue = append(p.queue, queuedGame{gameID: gameID, enqueued: time.Now()})
+	p.queued[gameID] = true
+	p.cond.Signal()
+}
+
+// next blocks until a game is queued and claims it, or returns false once
+// the pool is closed.
+func (p *ResolutionPool) next() (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for len(p.queue) == 0 && !p.closed {
+		p.cond.Wait()
+	}
+	if p.closed {
+		return "", false
+	}
+	g := p.queue[0]
+	p.queue = p.queue[1:]
+	delete(p.queued, g.gameID)
+	p.running[g.gameID] = false
+
+	wait := time.Since(g.enqueued)
+	p.waitSum += wait
+	p.started++
+	p.stats.MaxWaitMs = max(p.stats.MaxWaitMs, wait.Milliseconds())
+	return g.gameID, true
+}
+
+// done releases a game after resolution, re-queueing it at the back if it
+// expired again meanwhile.
+func (p *ResolutionPool) done(gameID string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err != nil {
+		p.stats.Failed++
+	} else {
+		p.stats.Completed++
+	}
+	again := p.running[gameID]
+	delete(p.running, gameID)
+	if again && !p.closed {
+		p.push(gameID)
+	}
+}
+
+func (p *ResolutionPool) work(ctx context.Context) {
+	for {
+		gameID, ok := p.next()
+		if !ok {
+			return
+		}
+		err := p.resolve(ctx, gameID)
+		if err != nil {
+			log.Error().Err(err).Str("gameId", gameID).Msg("Phase resolution failed")
+		}
+		p.done(gameID, err)
+	}
+}
+
+// Stats returns the pool's current load and counters.
+func (p *ResolutionPool) Stats() ResolutionStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := p.stats
+	s.Workers = p.opts.Workers
+	s.Capacity = p.opts.QueueSize
+	s.Queued = len(p.queue)
+	s.InFlight = len(p.running)
+	if p.started > 0 {
+		s.AvgWaitMs = (p.waitSum / time.Duration(p.started)).Milliseconds()
+	}
+	return s
+}
+
+// jitteredDeadline returns now+dur pushed back by a random amount of up to
+// jitter, capped at a tenth of dur so short bot turns stay short. Spreading
+// deadlines keeps games created together from all expiring at once.
+func jitteredDeadline(dur, jitter time.Duration) time.Time {
+	jitter = min(jitter, dur/10)
+	if jitter <= 0 {
+		return time.Now().Add(dur)
+	}
+	return time.Now().Add(dur + rand.N(jitter))
+}
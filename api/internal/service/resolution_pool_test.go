@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingResolver records resolutions in order and blocks each one until
+// released.
+type blockingResolver struct {
+	mu      sync.Mutex
+	order   []string
+	started chan string
+	release chan struct{}
+}
+
+func newBlockingResolver() *blockingResolver {
+	return &blockingResolver{started: make(chan string, 16), release: make(chan struct{})}
+}
+
+func (b *blockingResolver) resolve(ctx context.Context, gameID string) error {
+	b.mu.Lock()
+	b.order = append(b.order, gameID)
+	b.mu.Unlock()
+	b.started <- gameID
+	<-b.release
+	return nil
+}
+
+func (b *blockingResolver) resolved() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return slices.Clone(b.order)
+}
+
+func waitStarted(t *testing.T, b *blockingResolver, want string) {
+	t.Helper()
+	select {
+	case got := <-b.started:
+		if got != want {
+			t.Fatalf("expected %s to be resolved next, got %s", want, got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for %s", want)
+	}
+}
+
+func TestResolutionPoolFairAndCoalesced(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	b := newBlockingResolver()
+	pool := NewResolutionPool(b.resolve, ResolutionPoolOptions{Workers: 1, QueueSize: 10})
+	pool.Start(ctx)
+
+	pool.Enqueue("a")
+	waitStarted(t, b, "a")
+
+	// a expires again while running; b and c queue up, b twice.
+	pool.Enqueue("a")
+	pool.Enqueue("b")
+	pool.Enqueue("b")
+	pool.Enqueue("c")
+
+	stats := pool.Stats()
+	if stats.InFlight != 1 || stats.Queued != 2 || stats.Coalesced != 2 {
+		t.Errorf("unexpected stats while a runs: %+v", stats)
+	}
+
+	// a goes to the back behind b and c rather than running again at once.
+	for _, next := range []string{"b", "c", "a"} {
+		b.release <- struct{}{}
+		waitStarted(t, b, next)
+	}
+	b.release <- struct{}{}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for pool.Stats().Completed < 4 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := b.resolved(); !slices.Equal(got, []string{"a", "b", "c", "a"}) {
+		t.Errorf("expected resolution order a b c a, got %v", got)
+	}
+	if stats := pool.Stats(); stats.Completed != 4 || stats.Queued != 0 || stats.InFlight != 0 {
+		t.Errorf("unexpected final stats: %+v", stats)
+	}
+}
+
+func TestResolutionPoolRejectsWhenFull(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	b := newBlockingResolver()
+	pool := NewResolutionPool(b.resolve, ResolutionPoolOptions{Workers: 1, QueueSize: 2})
+	pool.Start(ctx)
+
+	pool.Enqueue("a")
+	waitStarted(t, b, "a")
+	if !pool.Enqueue("b") || !pool.Enqueue("c") {
+		t.Fatal("expected queue to accept two games")
+	}
+	if pool.Enqueue("d") {
+		t.Error("expected a full queue to reject d")
+	}
+	if stats := pool.Stats(); stats.Rejected != 1 || stats.Queued != 2 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	cancel()
+	close(b.release)
+}
+
+func TestJitteredDeadline(t *testing.T) {
+	start := time.Now()
+	for range 50 {
+		d := jitteredDeadline(time.Hour, time.Minute)
+		if d.Before(start.Add(time.Hour)) || d.After(time.Now().Add(time.Hour+time.Minute)) {
+			t.Fatalf("deadline %v outside [1h, 1h1m] from now", d.Sub(start))
+		}
+	}
+
+	// Jitter is capped at a tenth of the phase so short turns stay short.
+	d := jitteredDeadline(10*time.Second, time.Minute)
+	if d.After(time.Now().Add(11 * time.Second)) {
+		t.Errorf("jitter on a 10s phase should be at most 1s, deadline in %v", time.Until(d))
+	}
+	if d := jitteredDeadline(time.Minute, 0); d.After(time.Now().Add(time.Minute)) {
+		t.Error("zero jitter should not delay the deadline")
+	}
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// SelfPlayRecord is a finished game in the JSONL format written by the Rust
+// selfplay binary and read by cmd/import_selfplay, so server games can be fed
+// back into the training pipeline. Per-power arrays follow
+// diplomacy.AllPowers order.
+type SelfPlayRecord struct {
+	GameID       int             `json:"game_id"`
+	SourceID     string          `json:"source_game_id"` // server game ID; ignored by the importer
+	Winner       *string         `json:"winner"`         // null for a draw
+	FinalYear    int             `json:"final_year"`
+	FinalSCCount []int           `json:"final_sc_counts"`
+	Phases       []SelfPlayPhase `json:"phases"`
+}
+
+// SelfPlayPhase is one phase of a SelfPlayRecord: the position before it
+// resolved and the orders each power played.
+type SelfPlayPhase struct {
+	DFEN     string            `json:"dfen"`
+	Year     int               `json:"year"`
+	Season   string            `json:"season"` // s or f
+	Phase    string            `json:"phase"`  // m, r or b
+	Orders   map[string]string `json:"orders"` // power -> DSON
+	Values   []float64         `json:"values,omitempty"`
+	SCCounts []int             `json:"sc_counts"`
+}
+
+// ExportSelfPlay converts a finished game to a SelfPlayRecord. Values are
+// filled from the post-game evaluations when the game has been analyzed.
+func (s *ExportService) ExportSelfPlay(ctx context.Context, gameID string) (*SelfPlayRecord, error) {
+	game, err := s.finishedGame(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	phases, err := s.phaseRepo.ListPhases(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	evals, err := s.phaseRepo.EvaluationsByGame(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	shares := make(map[string]map[string]float64)
+	for _, e := range evals {
+		if shares[e.PhaseID] == nil {
+			shares[e.PhaseID] = make(map[string]float64)
+		}
+		shares[e.PhaseID][e.Power] = e.Share
+	}
+
+	rec := &SelfPlayRecord{SourceID: game.ID, Phases: make([]SelfPlayPhase, 0, len(phases))}
+	if game.Winner != "" {
+		winner := game.Winner
+		rec.Winner = &winner
+	}
+	var last *diplomacy.GameState
+	for _, phase := range phases {
+		if phase.ResolvedAt == nil {
+			continue
+		}
+		var gs diplomacy.GameState
+		if err := json.Unmarshal(phase.StateBefore, &gs); err != nil {
+			return nil, fmt.Errorf("phase %s: unmarshal state: %w", phase.ID, err)
+		}
+		orders, err := s.phaseRepo.OrdersByPhase(ctx, phase.ID)
+		if err != nil {
+			return nil, err
+		}
+		dfen := diplomacy.EncodeDFEN(&gs)
+		sp := SelfPlayPhase{
+			DFEN:     dfen,
+			Year:     phase.Year,
+			Season:   dfen[4:5],
+			Phase:    dfen[5:6],
+			Orders:   selfPlayOrders(orders),
+			SCCounts: scCounts(&gs),
+		}
+		if byPower := shares[phase.ID]; len(byPower) > 0 {
+			for _, p := range diplomacy.AllPowers() {
+				sp.Values = append(sp.Values, byPower[string(p)])
+			}
+		}
+		rec.Phases = append(rec.Phases, sp)
+
+		last = &gs
+		if len(phase.StateAfter) > 0 {
+			var after diplomacy.GameState
+			if err := json.Unmarshal(phase.StateAfter, &after); err == nil {
+				last = &after
+			}
+		}
+	}
+	if last == nil {
+		return nil, fmt.Errorf("game %s has no resolved phases", gameID)
+	}
+	rec.FinalYear = last.Year
+	rec.FinalSCCount = scCounts(last)
+	return rec, nil
+}
+
+// selfPlayOrders groups a phase's orders into one DSON string per power.
+func selfPlayOrders(orders []model.Order) map[string]string {
+	byPower := make(map[string][]diplomacy.DSONOrder)
+	for _, o := range orders {
+		if d, ok := modelOrderToDSON(o); ok {
+			byPower[o.Power] = append(byPower[o.Power], d)
+		}
+	}
+	out := make(map[string]string, len(byPower))
+	for power, list := range byPower {
+		out[power] = diplomacy.FormatDSON(list)
+	}
+	return out
+}
+
+// modelOrderToDSON converts a stored order of any phase to DSON. Stored
+// locations may carry a coast as "stp/nc".
+func modelOrderToDSON(o model.Order) (diplomacy.DSONOrder, bool) {
+	loc, coast := splitCoast(o.Location)
+	target, targetCoast := splitCoast(o.Target)
+	d := diplomacy.DSONOrder{UnitType: parseUnitType(o.UnitType), Location: loc, Coast: coast}
+	switch o.OrderType {
+	case "hold":
+		d.Type = diplomacy.DSONHold
+	case "move":
+		d.Type = diplomacy.DSONMove
+		d.Target, d.TargetCoast = target, targetCoast
+	case "support":
+		d.AuxUnitType = parseUnitType(o.AuxUnitType)
+		d.AuxLocation, _ = splitCoast(o.AuxLoc)
+		d.Type = diplomacy.DSONSupportHold
+		if o.AuxTarget != "" && o.AuxTarget != o.AuxLoc {
+			d.Type = diplomacy.DSONSupportMove
+			d.AuxTarget, _ = splitCoast(o.AuxTarget)
+		}
+	case "convoy":
+		d.Type = diplomacy.DSONConvoy
+		d.AuxUnitType = diplomacy.Army
+		d.AuxLocation, _ = splitCoast(o.AuxLoc)
+		d.AuxTarget, _ = splitCoast(o.AuxTarget)
+	case "retreat_move":
+		d.Type = diplomacy.DSONRetreat
+		d.Target, d.TargetCoast = target, targetCoast
+	case "retreat_disband", "disband":
+		d.Type = diplomacy.DSONDisband
+	case "build":
+		d.Type = diplomacy.DSONBuild
+	case "waive":
+		d = diplomacy.DSONOrder{Type: diplomacy.DSONWaive}
+	default:
+		return diplomacy.DSONOrder{}, false
+	}
+	return d, true
+}
+
+func splitCoast(s string) (string, diplomacy.Coast) {
+	prov, coast, _ := strings.Cut(s, "/")
+	return prov, diplomacy.Coast(coast)
+}
+
+// scCounts returns each power's supply center count in AllPowers order.
+func scCounts(gs *diplomacy.GameState) []int {
+	counts := make([]int, 0, 7)
+	for _, p := range diplomacy.AllPowers() {
+		counts = append(counts, gs.SupplyCenterCount(p))
+	}
+	return counts
+}
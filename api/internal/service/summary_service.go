@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/internal/render"
+	"github.com/freeeve/polite-betrayal/api/internal/repository"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// SummaryService builds and serves end-of-game summaries.
+type SummaryService struct {
+	gameRepo    repository.GameRepository
+	phaseRepo   repository.PhaseRepository
+	summaryRepo repository.SummaryRepository
+}
+
+// NewSummaryService creates a SummaryService.
+func NewSummaryService(gameRepo repository.GameRepository, phaseRepo repository.PhaseRepository, summaryRepo repository.SummaryRepository) *SummaryService {
+	return &SummaryService{gameRepo: gameRepo, phaseRepo: phaseRepo, summaryRepo: summaryRepo}
+}
+
+// Summary returns a finished game's summary, generating it on first request
+// for games that ended before summaries existed.
+func (s *SummaryService) Summary(ctx context.Context, gameID string) (*model.GameSummary, error) {
+	summary, err := s.summaryRepo.FindByGame(ctx, gameID)
+	if err != nil || summary != nil {
+		return summary, err
+	}
+	return s.GenerateSummary(ctx, gameID)
+}
+
+// GenerateSummary renders the final map and supply-center graph of a
+// finished game, computes per-power statistics and stores the result,
+// replacing any earlier summary.
+func (s *SummaryService) GenerateSummary(ctx context.Context, gameID string) (*model.GameSummary, error) {
+	game, err := s.gameRepo.FindByID(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	if game == nil || game.Status == "deleted" {
+		return nil, ErrGameNotFound
+	}
+	if game.Status != "finished" {
+		return nil, ErrGameNotFinished
+	}
+	phases, err := s.phaseRepo.ListPhases(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	if len(phases) == 0 {
+		return nil, fmt.Errorf("game %s has no phases", gameID)
+	}
+
+	stats := make(map[diplomacy.Power]*model.PowerSummary)
+	for _, p := range game.Players {
+		if p.Power != "" {
+			stats[diplomacy.Power(p.Power)] = &model.PowerSummary{
+				Power: p.Power, UserID: p.UserID, IsBot: p.IsBot,
+				EliminatedYear: p.EliminatedYear, EliminatedBy: p.EliminatedBy,
+			}
+		}
+	}
+	var history []model.SCHistoryPoint
+	var final diplomacy.GameState
+	for _, phase := range phases {
+		var gs diplomacy.GameState
+		if err := json.Unmarshal(phase.StateBefore, &gs); err != nil {
+			return nil, fmt.Errorf("phase %s: unmarshal state: %w", phase.ID, err)
+		}
+		// Spring 1902 opens with the centers held at the end of 1901, and so on.
+		cal := gs.Calendar()
+		if gs.Season == cal.FirstSeason() && gs.Phase == diplomacy.PhaseMovement && gs.Year > cal.StartYear {
+			history = appendSCHistory(history, gs.Year-1, &gs, stats)
+		}
+		orders, err := s.phaseRepo.OrdersByPhase(ctx, phase.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range orders {
+			if ps := stats[diplomacy.Power(o.Power)]; ps != nil {
+				ps.OrdersIssued++
+				if o.Result == "succeeds" {
+					ps.OrdersSucceeded++
+				}
+			}
+		}
+		final = gs
+	}
+
+	// The last phase stores its board before the fall center update, so
+	// apply it here as the game did when it ended.
+	last := phases[len(phases)-1]
+	if len(last.StateAfter) > 0 {
+		if err := json.Unmarshal(last.StateAfter, &final); err != nil {
+			return nil, fmt.Errorf("unmarshal final state: %w", err)
+		}
+		if final.IsLastSeason() && final.Phase != diplomacy.PhaseBuild {
+			diplomacy.UpdateSupplyCenterOwnership(&final)
+		}
+	}
+	history = appendSCHistory(history, final.Year, &final, stats)
+
+	summary := &model.GameSummary{
+		GameID:    gameID,
+		Winner:    game.Winner,
+		FinalYear: final.Year,
+		SCHistory: history,
+		MapSVG:    string(render.MapSVG(&final, summaryCaption(game, &final))),
+	}
+	for _, p := range final.ActivePowers() {
+		ps := stats[p]
+		if ps == nil {
+			ps = &model.PowerSummary{Power: string(p)}
+		}
+		ps.FinalCenters = final.SupplyCenterCount(p)
+		summary.Powers = append(summary.Powers, *ps)
+	}
+	rankEliminations(summary.Powers)
+	years, series := scGraphSeries(history, final.ActivePowers())
+	summary.SCGraphSVG = string(render.SCGraphSVG(years, series, final.VictoryCenters()))
+	if err := s.summaryRepo.Save(ctx, summary); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// appendSCHistory records each power's center count at the end of year,
+// replacing an existing point for the same year, and updates peak and
+// elimination stats.
+func appendSCHistory(history []model.SCHistoryPoint, year int, gs *diplomacy.GameState, stats map[diplomacy.Power]*model.PowerSummary) []model.SCHistoryPoint {
+	point := model.SCHistoryPoint{Year: year, Centers: make(map[string]int)}
+	for _, p := range gs.ActivePowers() {
+		n := gs.SupplyCenterCount(p)
+		point.Centers[string(p)] = n
+		if ps := stats[p]; ps != nil {
+			if n > ps.PeakCenters {
+				ps.PeakCenters, ps.PeakYear = n, year
+			}
+			if ps.EliminatedYear == 0 && !gs.PowerIsAlive(p) {
+				ps.EliminatedYear = year
+			}
+		}
+	}
+	if n := len(history); n > 0 && history[n-1].Year == year {
+		history[n-1] = point
+		return history
+	}
+	return append(history, point)
+}
+
+// rankEliminations sets each eliminated power's place in the order powers
+// went out.
+func rankEliminations(powers []model.PowerSummary) {
+	for i := range powers {
+		if powers[i].EliminatedYear == 0 {
+			continue
+		}
+		powers[i].EliminationRank = 1
+		for _, other := range powers {
+			if other.EliminatedYear != 0 && other.EliminatedYear < powers[i].EliminatedYear {
+				powers[i].EliminationRank++
+			}
+		}
+	}
+}
+
+// scGraphSeries converts the history into the years and per-power counts
+// render.SCGraphSVG draws.
+func scGraphSeries(history []model.SCHistoryPoint, powers []diplomacy.Power) ([]int, map[diplomacy.Power][]int) {
+	years := make([]int, len(history))
+	series := make(map[diplomacy.Power][]int, len(powers))
+	for i, point := range history {
+		years[i] = point.Year
+		for _, p := range powers {
+			series[p] = append(series[p], point.Centers[string(p)])
+		}
+	}
+	return years, series
+}
+
+// summaryCaption describes how the game ended, e.g. "Fall 1908: france wins".
+func summaryCaption(game *model.Game, gs *diplomacy.GameState) string {
+	season := string(gs.Season)
+	if season != "" {
+		season = strings.ToUpper(season[:1]) + season[1:]
+	}
+	result := "draw"
+	if game.Winner != "" {
+		result = game.Winner + " wins"
+	}
+	return fmt.Sprintf("%s %d: %s", season, gs.Year, result)
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+func TestGenerateSummary(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	summaryRepo := newMockSummaryRepo()
+	game, phaseID, _ := setupFinishedGame(t, gameRepo, phaseRepo)
+	ctx := context.Background()
+
+	// Fall 1901: France's army in bel takes the center when the game ends.
+	gs := diplomacy.NewInitialState()
+	gs.Season = diplomacy.Fall
+	gs.Units[0] = diplomacy.Unit{Type: diplomacy.Army, Power: diplomacy.France, Province: "bel"}
+	fall, _ := json.Marshal(gs)
+	phaseRepo.CreatePhase(ctx, game.ID, 1901, "fall", "movement", fall, time.Now())
+	var fallID string
+	for id, p := range phaseRepo.phases {
+		if p.Season == "fall" {
+			fallID = id
+		}
+	}
+	phaseRepo.ResolvePhase(ctx, phaseID, fall)
+	phaseRepo.ResolvePhase(ctx, fallID, fall)
+	phaseRepo.SaveOrders(ctx, []model.Order{
+		{PhaseID: phaseID, Power: "france", UnitType: "army", Location: "pic", OrderType: "move", Target: "bel", Result: "succeeds"},
+		{PhaseID: fallID, Power: "france", UnitType: "army", Location: "bel", OrderType: "hold", Result: "succeeds"},
+		{PhaseID: fallID, Power: "germany", UnitType: "army", Location: "mun", OrderType: "move", Target: "bur", Result: "bounced"},
+	})
+
+	svc := NewSummaryService(gameRepo, phaseRepo, summaryRepo)
+	summary, err := svc.Summary(ctx, game.ID)
+	if err != nil {
+		t.Fatalf("Summary: %v", err)
+	}
+	if summary.Winner != "france" || summary.FinalYear != 1901 || len(summary.Powers) != 7 {
+		t.Fatalf("unexpected summary: winner %q, year %d, %d powers", summary.Winner, summary.FinalYear, len(summary.Powers))
+	}
+	if len(summary.SCHistory) != 1 || summary.SCHistory[0].Centers["france"] != 4 {
+		t.Errorf("expected France on 4 centers at the end of 1901, got %+v", summary.SCHistory)
+	}
+	for _, p := range summary.Powers {
+		switch p.Power {
+		case "france":
+			if p.FinalCenters != 4 || p.PeakCenters != 4 || p.OrdersIssued != 2 || p.OrdersSucceeded != 2 {
+				t.Errorf("france: unexpected stats %+v", p)
+			}
+		case "germany":
+			if p.OrdersIssued != 1 || p.OrdersSucceeded != 0 {
+				t.Errorf("germany: unexpected stats %+v", p)
+			}
+		}
+	}
+	if !strings.Contains(summary.MapSVG, "Fall 1901: france wins") || !strings.Contains(summary.SCGraphSVG, "<polyline") {
+		t.Error("expected rendered map and graph")
+	}
+
+	// A stored summary is served as is.
+	if _, err := svc.Summary(ctx, game.ID); err != nil || summaryRepo.saves != 1 {
+		t.Errorf("expected the stored summary to be reused, got %d saves, %v", summaryRepo.saves, err)
+	}
+}
+
+func TestGenerateSummaryRequiresFinishedGame(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	gameID, _ := setupActiveGame(t, gameRepo, phaseRepo, newMockCache())
+
+	svc := NewSummaryService(gameRepo, phaseRepo, newMockSummaryRepo())
+	if _, err := svc.Summary(context.Background(), gameID); !errors.Is(err, ErrGameNotFinished) {
+		t.Errorf("expected ErrGameNotFinished, got %v", err)
+	}
+	if _, err := svc.Summary(context.Background(), "missing"); !errors.Is(err, ErrGameNotFound) {
+		t.Errorf("expected ErrGameNotFound, got %v", err)
+	}
+}
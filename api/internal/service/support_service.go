@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/freeeve/polite-betrayal/api/internal/bot"
+	"github.com/freeeve/polite-betrayal/api/internal/repository"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// SupportTalkingPoint is a no-cost support one power could give another this
+// phase, phrased for the player who asked.
+type SupportTalkingPoint struct {
+	Supporter   string   `json:"supporter"`
+	Beneficiary string   `json:"beneficiary"`
+	Order       string   `json:"order"`             // the support order, in DSON
+	Target      string   `json:"target"`            // supply center the beneficiary moves into
+	ContestedBy []string `json:"contested_by"`      // third powers that can also reach the target
+	Mutual      bool     `json:"mutual"`            // the other power can also support this one for free
+	Message     string   `json:"message,omitempty"` // canned request the beneficiary can send
+}
+
+// supportCacheGames bounds how many games' opportunities SupportService
+// keeps; the least recently requested game is dropped first.
+const supportCacheGames = 256
+
+// SupportService matches powers that can support each other at no cost.
+// Opportunities are computed once per phase and shared by all players.
+type SupportService struct {
+	gameRepo  repository.GameRepository
+	phaseRepo repository.PhaseRepository
+	userRepo  repository.UserRepository // optional: renders messages in the player's locale
+
+	mu    sync.Mutex
+	cache map[string]phaseSupport // gameID -> opportunities for its current phase
+}
+
+type phaseSupport struct {
+	phaseID string
+	opps    []bot.SupportOpportunity
+	mutual  map[[2]diplomacy.Power]bool
+	used    time.Time
+}
+
+// NewSupportService creates a SupportService.
+func NewSupportService(gameRepo repository.GameRepository, phaseRepo repository.PhaseRepository) *SupportService {
+	return &SupportService{gameRepo: gameRepo, phaseRepo: phaseRepo, cache: make(map[string]phaseSupport)}
+}
+
+// SetUserRepo configures the optional user repository used to render
+// suggested messages in the player's locale.
+func (s *SupportService) SetUserRepo(repo repository.UserRepository) {
+	s.userRepo = repo
+}
+
+// TalkingPoints returns the support opportunities this phase that involve the
+// user's power, either as supporter or beneficiary. Outside movement phases
+// there is nothing to support and the list is empty.
+func (s *SupportService) TalkingPoints(ctx context.Context, gameID, userID string) ([]SupportTalkingPoint, error) {
+	game, err := s.gameRepo.FindByID(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	if game == nil {
+		return nil, ErrGameNotFound
+	}
+	power := ""
+	for _, p := range game.Players {
+		if p.UserID == userID {
+			power = p.Power
+			break
+		}
+	}
+	if power == "" {
+		return nil, ErrNotInGame
+	}
+
+	ps, err := s.phaseOpportunities(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	locale := bot.DefaultLocale
+	if s.userRepo != nil {
+		if u, err := s.userRepo.FindByID(ctx, userID); err == nil && u != nil && u.Locale != "" {
+			locale = u.Locale
+		}
+	}
+
+	points := []SupportTalkingPoint{}
+	for _, o := range ps.opps {
+		if string(o.Supporter) != power && string(o.Beneficiary) != power {
+			continue
+		}
+		pair := [2]diplomacy.Power{o.Supporter, o.Beneficiary}
+		if pair[0] > pair[1] {
+			pair[0], pair[1] = pair[1], pair[0]
+		}
+		tp := SupportTalkingPoint{
+			Supporter:   string(o.Supporter),
+			Beneficiary: string(o.Beneficiary),
+			Order:       diplomacy.FormatDSON([]diplomacy.DSONOrder{diplomacy.OrderToDSON(o.Order())}),
+			Target:      o.Target,
+			ContestedBy: []string{},
+			Mutual:      ps.mutual[pair],
+		}
+		for _, p := range o.ContestedBy {
+			tp.ContestedBy = append(tp.ContestedBy, string(p))
+		}
+		if string(o.Beneficiary) == power {
+			tp.Message = bot.FormatCannedMessageLocale(o.Intent(), locale)
+		}
+		points = append(points, tp)
+	}
+	return points, nil
+}
+
+// phaseOpportunities returns the support opportunities for a game's current
+// phase, computing them on first request in each phase.
+func (s *SupportService) phaseOpportunities(ctx context.Context, gameID string) (phaseSupport, error) {
+	phase, err := s.phaseRepo.CurrentPhase(ctx, gameID)
+	if err != nil {
+		return phaseSupport{}, err
+	}
+	if phase == nil {
+		return phaseSupport{}, ErrNoActivePhase
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if ps, ok := s.cache[gameID]; ok && ps.phaseID == phase.ID {
+		ps.used = time.Now()
+		s.cache[gameID] = ps
+		return ps, nil
+	}
+
+	var gs diplomacy.GameState
+	if err := json.Unmarshal(phase.StateBefore, &gs); err != nil {
+		return phaseSupport{}, fmt.Errorf("unmarshal game state: %w", err)
+	}
+	ps := phaseSupport{phaseID: phase.ID}
+	if gs.Phase == diplomacy.PhaseMovement {
+		ps.opps = bot.SupportOpportunities(&gs, gs.Map())
+		ps.mutual = bot.MutualSupportPairs(ps.opps)
+	}
+	if _, ok := s.cache[gameID]; !ok && len(s.cache) >= supportCacheGames {
+		s.evictOldest()
+	}
+	ps.used = time.Now()
+	s.cache[gameID] = ps
+	return ps, nil
+}
+
+// evictOldest drops the least recently requested game. Callers hold s.mu.
+func (s *SupportService) evictOldest() {
+	var oldest string
+	var at time.Time
+	for id, ps := range s.cache {
+		if oldest == "" || ps.used.Before(at) {
+			oldest, at = id, ps.used
+		}
+	}
+	delete(s.cache, oldest)
+}
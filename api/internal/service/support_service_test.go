@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestSupportTalkingPoints(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	cache := newMockCache()
+	gameID, _ := setupActiveGame(t, gameRepo, phaseRepo, cache)
+	svc := NewSupportService(gameRepo, phaseRepo)
+	ctx := context.Background()
+
+	for _, p := range gameRepo.players[gameID] {
+		points, err := svc.TalkingPoints(ctx, gameID, p.UserID)
+		if err != nil {
+			t.Fatalf("TalkingPoints(%s): %v", p.Power, err)
+		}
+		if points == nil {
+			t.Fatalf("expected an empty list rather than nil for %s", p.Power)
+		}
+		for _, tp := range points {
+			if tp.Supporter != p.Power && tp.Beneficiary != p.Power {
+				t.Errorf("%s got a talking point between %s and %s", p.Power, tp.Supporter, tp.Beneficiary)
+			}
+			if (tp.Beneficiary == p.Power) != (tp.Message != "") {
+				t.Errorf("message should be set only for the beneficiary: %+v", tp)
+			}
+		}
+	}
+
+	if len(svc.cache) != 1 {
+		t.Errorf("expected opportunities cached once for the phase, got %d entries", len(svc.cache))
+	}
+}
+
+func TestSupportTalkingPointsNotInGame(t *testing.T) {
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	gameID, _ := setupActiveGame(t, gameRepo, phaseRepo, newMockCache())
+	svc := NewSupportService(gameRepo, phaseRepo)
+
+	if _, err := svc.TalkingPoints(context.Background(), gameID, "user-99"); err != ErrNotInGame {
+		t.Errorf("expected ErrNotInGame, got %v", err)
+	}
+	if _, err := svc.TalkingPoints(context.Background(), "missing", "user-1"); err != ErrGameNotFound {
+		t.Errorf("expected ErrGameNotFound, got %v", err)
+	}
+}
+
+func TestSupportCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	svc := NewSupportService(nil, nil)
+	now := time.Now()
+	for i := range supportCacheGames {
+		svc.cache[fmt.Sprintf("game-%d", i)] = phaseSupport{used: now.Add(time.Duration(i) * time.Second)}
+	}
+	svc.evictOldest()
+	if len(svc.cache) != supportCacheGames-1 {
+		t.Fatalf("expected %d entries, got %d", supportCacheGames-1, len(svc.cache))
+	}
+	if _, ok := svc.cache["game-0"]; ok {
+		t.Error("expected the least recently used game to be evicted")
+	}
+}
@@ -14,19 +14,68 @@ import (
 // TimerListener listens for Redis keyspace notifications on expired timer keys
 // and triggers phase resolution when a game's timer expires. Also runs a
 // polling fallback to catch expirations if keyspace notifications are unavailable.
+// With a ResolutionPool set, expired games are queued on the pool instead of
+// being resolved one at a time on the listener's goroutine. With a lease
+// store set, only the replica holding the timer lease listens.
 type TimerListener struct {
-	rdb       *redis.Client
-	phaseSvc  *PhaseService
-	phaseRepo repository.PhaseRepository
+	rdb        *redis.Client
+	phaseSvc   *PhaseService
+	phaseRepo  repository.PhaseRepository
+	pool       *ResolutionPool       // optional
+	notifier   *NotificationService  // optional
+	leases     repository.LeaseStore // optional
+	leaseOwner string
 }
 
+// reminderInterval is how often the listener checks for deadline reminders
+// that are due.
+const reminderInterval = time.Minute
+
 // NewTimerListener creates a TimerListener.
 func NewTimerListener(rdb *redis.Client, phaseSvc *PhaseService, phaseRepo repository.PhaseRepository) *TimerListener {
 	return &TimerListener{rdb: rdb, phaseSvc: phaseSvc, phaseRepo: phaseRepo}
 }
 
+// SetResolutionPool routes expirations through a bounded worker pool. The
+// listener starts the pool's workers in Start.
+func (t *TimerListener) SetResolutionPool(pool *ResolutionPool) {
+	t.pool = pool
+}
+
+// SetNotificationService makes the listener send deadline and
+// missing-order reminders as they come due.
+func (t *TimerListener) SetNotificationService(svc *NotificationService) {
+	t.notifier = svc
+}
+
+// SetLeaseStore elects the listener through a lease in store, as owner, so
+// that of several API replicas only one resolves expired phases and sends
+// reminders; another takes over if it stops.
+func (t *TimerListener) SetLeaseStore(store repository.LeaseStore, owner string) {
+	t.leases = store
+	t.leaseOwner = owner
+}
+
 // Start begins listening for expired key events and runs a polling fallback.
+// With a lease store set it first waits to be elected, and stops listening
+// if it loses the lease.
 func (t *TimerListener) Start(ctx context.Context) {
+	if t.pool != nil {
+		t.pool.Start(ctx)
+	}
+	if t.leases != nil {
+		runAsLeader(ctx, t.leases, timerLeaseKey, t.leaseOwner, t.listen)
+		return
+	}
+	t.listen(ctx)
+}
+
+// listen runs the keyspace listener, the deadline poller and, with a
+// notification service, the reminder poller until ctx is done.
+func (t *TimerListener) listen(ctx context.Context) {
+	if t.notifier != nil {
+		go t.pollReminders(ctx)
+	}
 	go t.listenKeyspace(ctx)
 	t.pollExpiredPhases(ctx)
 }
@@ -68,6 +117,20 @@ func (t *TimerListener) pollExpiredPhases(ctx context.Context) {
 	}
 }
 
+// pollReminders periodically sends the deadline reminders that are due.
+func (t *TimerListener) pollReminders(ctx context.Context) {
+	ticker := time.NewTicker(reminderInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			t.notifier.CheckDeadlines(ctx)
+		}
+	}
+}
+
 // checkExpiredPhases finds active phases past their deadline and resolves them.
 func (t *TimerListener) checkExpiredPhases(ctx context.Context) {
 	phases, err := t.phaseRepo.ListExpired(ctx)
@@ -82,9 +145,18 @@ func (t *TimerListener) checkExpiredPhases(ctx context.Context) {
 		log.Info().Str("gameId", p.GameID).Str("phaseType", p.PhaseType).
 			Int("year", p.Year).Str("season", p.Season).
 			Time("deadline", p.Deadline).Msg("Poller resolving expired phase")
-		if err := t.phaseSvc.ResolvePhase(ctx, p.GameID); err != nil {
-			log.Error().Err(err).Str("gameId", p.GameID).Msg("Phase resolution failed from poller")
-		}
+		t.resolve(ctx, p.GameID, "poller")
+	}
+}
+
+// resolve resolves the game's phase, on the pool if one is set.
+func (t *TimerListener) resolve(ctx context.Context, gameID, source string) {
+	if t.pool != nil {
+		t.pool.Enqueue(gameID)
+		return
+	}
+	if err := t.phaseSvc.ResolvePhase(ctx, gameID); err != nil {
+		log.Error().Err(err).Str("gameId", gameID).Str("source", source).Msg("Phase resolution failed")
 	}
 }
 
@@ -101,7 +173,5 @@ func (t *TimerListener) handleExpiry(ctx context.Context, key string) {
 	gameID := parts[1]
 
 	log.Info().Str("gameId", gameID).Msg("Timer expired, triggering phase resolution")
-	if err := t.phaseSvc.ResolvePhase(ctx, gameID); err != nil {
-		log.Error().Err(err).Str("gameId", gameID).Msg("Phase resolution failed after timer expiry")
-	}
+	t.resolve(ctx, gameID, "timer")
 }
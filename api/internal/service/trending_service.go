@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"maps"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/internal/repository"
+)
+
+const (
+	// viewerStaleness is how long an instance's viewer counts are trusted
+	// without a fresh report, e.g. after it crashed.
+	viewerStaleness = time.Minute
+	// trendingWindow is how recent a game's activity must be for it to trend
+	// without viewers.
+	trendingWindow = time.Hour
+)
+
+// MaxTrendingGames caps the games Trending returns.
+const MaxTrendingGames = 20
+
+// ViewerSource reports the viewers and activity of games on this server;
+// the WebSocket hub implements it.
+type ViewerSource interface {
+	ViewerCounts() map[string]int
+	TakeActivity() map[string]time.Time
+}
+
+// TrendingGame is a live game with how many are watching it.
+type TrendingGame struct {
+	GameID       string    `json:"game_id"`
+	Name         string    `json:"name"`
+	Status       string    `json:"status"`
+	Scenario     string    `json:"scenario"`
+	BotOnly      bool      `json:"bot_only"`
+	Viewers      int       `json:"viewers"`
+	LastActivity time.Time `json:"last_activity,omitzero"`
+}
+
+// TrendingService shares live viewer counts between server instances and
+// ranks the games worth watching.
+type TrendingService struct {
+	gameRepo   repository.GameRepository
+	store      repository.ViewerStore
+	instanceID string
+}
+
+// NewTrendingService creates a TrendingService reporting as instanceID,
+// which must be unique among the servers sharing the store.
+func NewTrendingService(gameRepo repository.GameRepository, store repository.ViewerStore, instanceID string) *TrendingService {
+	return &TrendingService{gameRepo: gameRepo, store: store, instanceID: instanceID}
+}
+
+// RunReporter periodically reports this server's viewer counts and game
+// activity until ctx is cancelled. The interval must be well under
+// viewerStaleness.
+func (s *TrendingService) RunReporter(ctx context.Context, source ViewerSource, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	log.Info().Dur("interval", interval).Str("instance", s.instanceID).Msg("Viewer count reporter started")
+	var reported map[string]int
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			var err error
+			reported, err = s.report(ctx, source, reported)
+			if err != nil {
+				log.Error().Err(err).Msg("Failed to report viewer counts")
+			}
+		}
+	}
+}
+
+// report sends one set of counts, with zeroes for the games that had
+// viewers in the previous one so the store clears them. It returns the
+// counts the next report must clear.
+func (s *TrendingService) report(ctx context.Context, source ViewerSource, previous map[string]int) (map[string]int, error) {
+	counts := source.ViewerCounts()
+	for gameID := range previous {
+		if _, ok := counts[gameID]; !ok {
+			counts[gameID] = 0
+		}
+	}
+	if err := s.store.ReportViewers(ctx, s.instanceID, counts, source.TakeActivity()); err != nil {
+		return counts, err
+	}
+	maps.DeleteFunc(counts, func(_ string, n int) bool { return n == 0 })
+	return counts, nil
+}
+
+// Trending returns active and paused games ranked by current viewers, then
+// by most recent activity, ranked by the database in one query. limit is
+// clamped to 1-MaxTrendingGames. Whether an anonymous game is bot-only stays
+// hidden while it hides identities.
+func (s *TrendingService) Trending(ctx context.Context, limit int) ([]TrendingGame, error) {
+	limit = min(max(limit, 1), MaxTrendingGames)
+	now := time.Now()
+	viewers, err := s.store.LiveViewers(ctx, now.Add(-viewerStaleness))
+	if err != nil {
+		return nil, err
+	}
+	activity, err := s.store.RecentActivity(ctx, now.Add(-trendingWindow))
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]model.GameActivity, 0, len(viewers)+len(activity))
+	for gameID, n := range viewers {
+		candidates = append(candidates, model.GameActivity{GameID: gameID, Viewers: n, LastActivity: activity[gameID]})
+	}
+	for gameID, t := range activity {
+		if _, ok := viewers[gameID]; !ok {
+			candidates = append(candidates, model.GameActivity{GameID: gameID, LastActivity: t})
+		}
+	}
+	if len(candidates) == 0 {
+		return []TrendingGame{}, nil
+	}
+	live, err := s.gameRepo.RankLive(ctx, candidates, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	trending := make([]TrendingGame, len(live))
+	for i, g := range live {
+		trending[i] = TrendingGame{
+			GameID:       g.GameID,
+			Name:         g.Name,
+			Status:       g.Status,
+			Scenario:     g.Scenario,
+			BotOnly:      g.BotOnly && !g.Anonymous,
+			Viewers:      g.Viewers,
+			LastActivity: g.LastActivity,
+		}
+	}
+	return trending, nil
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+)
+
+// fakeViewerSource stands in for the WebSocket hub.
+type fakeViewerSource struct {
+	counts   map[string]int
+	activity map[string]time.Time
+}
+
+func (f *fakeViewerSource) ViewerCounts() map[string]int {
+	counts := make(map[string]int, len(f.counts))
+	for id, n := range f.counts {
+		counts[id] = n
+	}
+	return counts
+}
+
+func (f *fakeViewerSource) TakeActivity() map[string]time.Time {
+	activity := f.activity
+	f.activity = nil
+	return activity
+}
+
+func TestTrending(t *testing.T) {
+	ctx := context.Background()
+	gameRepo := newMockGameRepo()
+	store := newMockViewerStore()
+
+	newGame := func(name, status string) string {
+		g, _ := gameRepo.Create(ctx, name, "user-1", "24h", "12h", "12h", "random", "", "standard", model.GameRules{})
+		g.Status = status
+		return g.ID
+	}
+	watched := newGame("Watched", "active")
+	busy := newGame("Busy", "active")
+	quiet := newGame("Quiet", "paused")
+	over := newGame("Over", "finished")
+	newGame("Idle", "active")
+
+	now := time.Now()
+	svc1 := NewTrendingService(gameRepo, store, "server-1")
+	svc2 := NewTrendingService(gameRepo, store, "server-2")
+	source1 := &fakeViewerSource{
+		counts:   map[string]int{watched: 2, over: 9},
+		activity: map[string]time.Time{quiet: now.Add(-30 * time.Minute)},
+	}
+	source2 := &fakeViewerSource{
+		counts:   map[string]int{watched: 1},
+		activity: map[string]time.Time{busy: now.Add(-time.Minute)},
+	}
+	reported1, err := svc1.report(ctx, source1, nil)
+	if err != nil {
+		t.Fatalf("report: %v", err)
+	}
+	if _, err := svc2.report(ctx, source2, nil); err != nil {
+		t.Fatalf("report: %v", err)
+	}
+
+	trending, err := svc1.Trending(ctx, 10)
+	if err != nil {
+		t.Fatalf("Trending: %v", err)
+	}
+	var got []string
+	for _, g := range trending {
+		got = append(got, g.Name)
+	}
+	if len(got) != 3 || got[0] != "Watched" || got[1] != "Busy" || got[2] != "Quiet" {
+		t.Fatalf("expected Watched, Busy, Quiet; got %v", got)
+	}
+	if trending[0].Viewers != 3 {
+		t.Errorf("expected viewers summed over servers, got %d", trending[0].Viewers)
+	}
+	if trending[2].Status != "paused" {
+		t.Errorf("expected paused status, got %q", trending[2].Status)
+	}
+
+	// Viewers who leave are cleared by the next report.
+	source1.counts = map[string]int{}
+	if _, err := svc1.report(ctx, source1, reported1); err != nil {
+		t.Fatalf("report: %v", err)
+	}
+	// A server that stops reporting no longer counts.
+	store.heartbeat["server-2"] = now.Add(-2 * viewerStaleness)
+	trending, err = svc1.Trending(ctx, 1)
+	if err != nil {
+		t.Fatalf("Trending: %v", err)
+	}
+	if len(trending) != 1 || trending[0].Name != "Busy" || trending[0].Viewers != 0 {
+		t.Errorf("expected only Busy without viewers, got %+v", trending)
+	}
+}
+
+func TestTrendingBotOnly(t *testing.T) {
+	ctx := context.Background()
+	gameRepo := newMockGameRepo()
+	phaseRepo := newMockPhaseRepo()
+	store := newMockViewerStore()
+	gameSvc := NewGameService(gameRepo, phaseRepo, newMockUserRepo())
+
+	game, err := gameSvc.CreateGame(ctx, "Arena", "user-1", "24h", "12h", "12h", "", "", "", true, model.GameRules{})
+	if err != nil {
+		t.Fatalf("create game: %v", err)
+	}
+	if _, err := gameSvc.StartGame(ctx, game.ID, "user-1"); err != nil {
+		t.Fatalf("start game: %v", err)
+	}
+	svc := NewTrendingService(gameRepo, store, "server-1")
+	if _, err := svc.report(ctx, &fakeViewerSource{counts: map[string]int{game.ID: 5}}, nil); err != nil {
+		t.Fatalf("report: %v", err)
+	}
+
+	trending, err := svc.Trending(ctx, 10)
+	if err != nil {
+		t.Fatalf("Trending: %v", err)
+	}
+	if len(trending) != 1 || !trending[0].BotOnly {
+		t.Errorf("expected a bot-only game, got %+v", trending)
+	}
+
+	gameRepo.games[game.ID].Anonymous = true
+	trending, _ = svc.Trending(ctx, 10)
+	if len(trending) != 1 || trending[0].BotOnly {
+		t.Errorf("expected an anonymous game not to reveal its bots, got %+v", trending)
+	}
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/internal/repository"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// ErrVariantNotFound is returned for an unknown variant name.
+var ErrVariantNotFound = errors.New("variant not found")
+
+// variantLoadTimeout bounds loading a variant for a game that names it.
+const variantLoadTimeout = 5 * time.Second
+
+// VariantService stores uploaded custom variants and serves the playable
+// ones as scenarios. Every upload is a new immutable version, and games play
+// the version they were created with. Variants are read from the database
+// rather than registered in one process, so every server sees every upload:
+// install LoadScenario with diplomacy.SetScenarioLoader.
+type VariantService struct {
+	variantRepo repository.VariantRepository
+}
+
+// NewVariantService creates a VariantService.
+func NewVariantService(variantRepo repository.VariantRepository) *VariantService {
+	return &VariantService{variantRepo: variantRepo}
+}
+
+// Scenarios returns the built-in scenarios followed by the latest version
+// of every playable variant, named "name@version". Variants whose stored
+// definition no longer decodes or validates are logged and skipped.
+func (s *VariantService) Scenarios(ctx context.Context) ([]diplomacy.Scenario, error) {
+	variants, err := s.variantRepo.ListPlayable(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := diplomacy.Scenarios()
+	for _, v := range variants {
+		sc, err := variantScenario(&v)
+		if err != nil {
+			log.Warn().Err(err).Str("variant", v.Name).Int("version", v.Version).Msg("Skipping unplayable variant")
+			continue
+		}
+		out = append(out, sc)
+	}
+	return out, nil
+}
+
+// LoadScenario is the diplomacy.ScenarioLoader for stored variants. It
+// loads "name@version"; a bare variant name is a game created before
+// variants were versioned, and plays version 1.
+func (s *VariantService) LoadScenario(name string) (diplomacy.Scenario, bool) {
+	base, version := diplomacy.SplitScenarioName(name)
+	if version == 0 {
+		version = 1
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), variantLoadTimeout)
+	defer cancel()
+	v, err := s.variantRepo.FindVersion(ctx, base, version)
+	if err != nil {
+		log.Error().Err(err).Str("scenario", name).Msg("Failed to load variant")
+		return diplomacy.Scenario{}, false
+	}
+	if v == nil {
+		return diplomacy.Scenario{}, false
+	}
+	sc, err := variantScenario(v)
+	if err != nil {
+		log.Warn().Err(err).Str("scenario", name).Msg("Stored variant is not playable")
+		return diplomacy.Scenario{}, false
+	}
+	return sc, true
+}
+
+// ResolveScenario returns the scenario name a new game should store: the
+// latest version of a variant named without one, otherwise name unchanged.
+func (s *VariantService) ResolveScenario(ctx context.Context, name string) (string, error) {
+	if _, version := diplomacy.SplitScenarioName(name); version > 0 || name == "" || name == diplomacy.ScenarioStandard {
+		return name, nil
+	}
+	v, err := s.variantRepo.FindByName(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	if v == nil {
+		return name, nil
+	}
+	return diplomacy.VersionedScenarioName(v.Name, v.Version), nil
+}
+
+// Upload validates a variant and stores it as a new version; games already
+// playing earlier versions are unaffected. Validation failures are returned
+// as *diplomacy.VariantError. Playable variants can be chosen for new games
+// immediately, on every server.
+func (s *VariantService) Upload(ctx context.Context, userID string, def *diplomacy.VariantDefinition) (*model.Variant, error) {
+	if err := def.Validate(); err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(def)
+	if err != nil {
+		return nil, err
+	}
+	v := &model.Variant{
+		Name:        def.Name,
+		Description: def.Description,
+		Playable:    def.Playable(),
+		CreatedBy:   userID,
+		Definition:  raw,
+	}
+	if err := s.variantRepo.Save(ctx, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// List returns the latest version of every uploaded variant without its
+// definition.
+func (s *VariantService) List(ctx context.Context) ([]model.Variant, error) {
+	variants, err := s.variantRepo.List(ctx)
+	return nonNil(variants), err
+}
+
+// Get returns a variant with its full definition: the latest version, or
+// the one named by "name@version" as games store it.
+func (s *VariantService) Get(ctx context.Context, name string) (*model.Variant, error) {
+	var v *model.Variant
+	var err error
+	if base, version := diplomacy.SplitScenarioName(name); version > 0 {
+		v, err = s.variantRepo.FindVersion(ctx, base, version)
+	} else {
+		v, err = s.variantRepo.FindByName(ctx, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, ErrVariantNotFound
+	}
+	return v, nil
+}
+
+// variantScenario decodes a stored variant into the scenario that plays it,
+// named "name@version".
+func variantScenario(v *model.Variant) (diplomacy.Scenario, error) {
+	var def diplomacy.VariantDefinition
+	if err := json.Unmarshal(v.Definition, &def); err != nil {
+		return diplomacy.Scenario{}, fmt.Errorf("decode variant %s: %w", v.Name, err)
+	}
+	if err := def.Validate(); err != nil {
+		return diplomacy.Scenario{}, err
+	}
+	if !def.Playable() {
+		return diplomacy.Scenario{}, fmt.Errorf("variant %s uses powers the engine doesn't know", v.Name)
+	}
+	sc := def.Scenario()
+	sc.Name = diplomacy.VersionedScenarioName(v.Name, v.Version)
+	return sc, nil
+}
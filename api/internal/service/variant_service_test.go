@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/freeeve/polite-betrayal/api/internal/model"
+	"github.com/freeeve/polite-betrayal/api/pkg/diplomacy"
+)
+
+// duelVariant is the standard map with only England and Turkey.
+func duelVariant(name string) *diplomacy.VariantDefinition {
+	v := diplomacy.StandardVariant()
+	v.Name = name
+	v.Powers = []diplomacy.Power{diplomacy.England, diplomacy.Turkey}
+	var units []diplomacy.VariantUnit
+	for _, u := range v.Units {
+		if u.Power == diplomacy.England || u.Power == diplomacy.Turkey {
+			units = append(units, u)
+		}
+	}
+	v.Units = units
+	return v
+}
+
+// withVariantLoader installs svc as the scenario loader for the test.
+func withVariantLoader(t *testing.T, svc *VariantService) {
+	diplomacy.SetScenarioLoader(svc.LoadScenario)
+	t.Cleanup(func() { diplomacy.SetScenarioLoader(nil) })
+}
+
+func TestVariantUploadPlayable(t *testing.T) {
+	repo := newMockVariantRepo()
+	svc := NewVariantService(repo)
+	withVariantLoader(t, svc)
+	ctx := context.Background()
+
+	v, err := svc.Upload(ctx, "admin-1", duelVariant("svc-england-turkey"))
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if !v.Playable || v.CreatedBy != "admin-1" || v.Version != 1 {
+		t.Errorf("unexpected variant %+v", v)
+	}
+
+	gameSvc := NewGameService(newMockGameRepo(), newMockPhaseRepo(), newMockUserRepo())
+	gameSvc.SetVariantService(svc)
+	scenarios, err := gameSvc.ListScenarios(ctx)
+	if err != nil || !slices.ContainsFunc(scenarios, func(s diplomacy.Scenario) bool { return s.Name == "svc-england-turkey@1" }) {
+		t.Fatalf("expected the variant among the scenarios, got %+v, %v", scenarios, err)
+	}
+	game, err := gameSvc.CreateGame(ctx, "Duel", "user-1", "24h", "12h", "12h", "", "", "svc-england-turkey", false, model.GameRules{})
+	if err != nil {
+		t.Fatalf("CreateGame with custom variant: %v", err)
+	}
+	if game.Scenario != "svc-england-turkey@1" {
+		t.Errorf("expected the game to use version 1 of the variant, got %q", game.Scenario)
+	}
+
+	got, err := svc.Get(ctx, "svc-england-turkey")
+	if err != nil || len(got.Definition) == 0 {
+		t.Errorf("expected the stored definition, got %+v, %v", got, err)
+	}
+	if _, err := svc.Get(ctx, "missing"); !errors.Is(err, ErrVariantNotFound) {
+		t.Errorf("expected ErrVariantNotFound, got %v", err)
+	}
+}
+
+func TestVariantReuploadKeepsGameVersion(t *testing.T) {
+	repo := newMockVariantRepo()
+	svc := NewVariantService(repo)
+	withVariantLoader(t, svc)
+	ctx := context.Background()
+	gameSvc := NewGameService(newMockGameRepo(), newMockPhaseRepo(), newMockUserRepo())
+	gameSvc.SetVariantService(svc)
+
+	if _, err := svc.Upload(ctx, "admin-1", duelVariant("svc-reupload")); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	first, err := gameSvc.CreateGame(ctx, "First", "user-1", "24h", "12h", "12h", "", "", "svc-reupload", false, model.GameRules{})
+	if err != nil {
+		t.Fatalf("CreateGame: %v", err)
+	}
+
+	v := duelVariant("svc-reupload")
+	v.VictoryCenters = 20
+	stored, err := svc.Upload(ctx, "admin-1", v)
+	if err != nil || stored.Version != 2 {
+		t.Fatalf("expected version 2, got %+v, %v", stored, err)
+	}
+	second, err := gameSvc.CreateGame(ctx, "Second", "user-1", "24h", "12h", "12h", "", "", "svc-reupload", false, model.GameRules{})
+	if err != nil {
+		t.Fatalf("CreateGame: %v", err)
+	}
+
+	if sc := gameScenario(first); sc.Name != "svc-reupload@1" || sc.VictoryCenters != 18 {
+		t.Errorf("expected the first game to keep version 1, got %s with %d centers", sc.Name, sc.VictoryCenters)
+	}
+	if sc := gameScenario(second); sc.Name != "svc-reupload@2" || sc.VictoryCenters != 20 {
+		t.Errorf("expected the second game on version 2, got %s with %d centers", sc.Name, sc.VictoryCenters)
+	}
+}
+
+func TestVariantUploadCustomMapPlayable(t *testing.T) {
+	repo := newMockVariantRepo()
+	svc := NewVariantService(repo)
+	withVariantLoader(t, svc)
+	v := duelVariant("svc-no-bla")
+	// Cutting the Black Sea out keeps the map valid but not standard.
+	var adj []diplomacy.VariantAdjacency
+	for _, a := range v.Adjacencies {
+		if a.From != "bla" && a.To != "bla" {
+			adj = append(adj, a)
+		}
+	}
+	v.Adjacencies = adj
+	var provs []diplomacy.VariantProvince
+	for _, p := range v.Provinces {
+		if p.ID != "bla" {
+			provs = append(provs, p)
+		}
+	}
+	v.Provinces = provs
+
+	stored, err := svc.Upload(context.Background(), "admin-1", v)
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if !stored.Playable {
+		t.Fatal("expected a custom map with standard powers to be playable")
+	}
+	sc, ok := diplomacy.LookupScenario("svc-no-bla@1")
+	if !ok {
+		t.Fatal("expected the stored variant to load as a scenario")
+	}
+	if _, ok := sc.Map().Provinces["bla"]; ok {
+		t.Error("expected the scenario to play on the variant's own map")
+	}
+}
+
+func TestVariantUploadInvalid(t *testing.T) {
+	repo := newMockVariantRepo()
+	svc := NewVariantService(repo)
+	v := duelVariant("svc-bad")
+	v.VictoryCenters = 5
+
+	_, err := svc.Upload(context.Background(), "admin-1", v)
+	var verr *diplomacy.VariantError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a VariantError, got %v", err)
+	}
+	if len(repo.variants) != 0 {
+		t.Error("expected an invalid variant not to be stored")
+	}
+}
+
+func TestVariantScenariosSkipBadVariants(t *testing.T) {
+	repo := newMockVariantRepo()
+	svc := NewVariantService(repo)
+	ctx := context.Background()
+	if _, err := svc.Upload(ctx, "admin-1", duelVariant("svc-good")); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	repo.Save(ctx, &model.Variant{Name: "svc-broken", Playable: true, Definition: []byte(`{"name":`)})
+
+	scenarios, err := svc.Scenarios(ctx)
+	if err != nil {
+		t.Fatalf("Scenarios: %v", err)
+	}
+	var custom []string
+	for _, sc := range scenarios {
+		if sc.Custom {
+			custom = append(custom, sc.Name)
+		}
+	}
+	if !slices.Contains(custom, "svc-good@1") || slices.Contains(custom, "svc-broken@1") {
+		t.Errorf("expected only the good variant, got %v", custom)
+	}
+	if _, ok := svc.LoadScenario("svc-broken@1"); ok {
+		t.Error("expected a broken variant not to load")
+	}
+}
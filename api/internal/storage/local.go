@@ -0,0 +1,145 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DeriveSecret derives a signing key for one purpose from a longer-lived
+// secret, so URLs handed to clients are never signed with the parent key.
+func DeriveSecret(parent []byte, purpose string) []byte {
+	mac := hmac.New(sha256.New, parent)
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
+
+// LocalStore keeps blobs as files under a directory. Its signed URLs point at
+// its Handler, mounted under /blobs/.
+type LocalStore struct {
+	dir     string
+	baseURL string // origin the API is reached at; empty for same-origin URLs
+	secret  []byte
+	now     func() time.Time
+}
+
+// NewLocalStore returns a store keeping blobs under dir, creating it if
+// needed.
+func NewLocalStore(dir, baseURL string, secret []byte) (*LocalStore, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("local blob store needs a signing secret")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create blob directory: %w", err)
+	}
+	return &LocalStore{dir: dir, baseURL: strings.TrimSuffix(baseURL, "/"), secret: secret, now: time.Now}, nil
+}
+
+func (s *LocalStore) path(key string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(key))
+}
+
+func (s *LocalStore) Put(_ context.Context, key string, data []byte, _ string) error {
+	if !validKey(key) {
+		return ErrInvalidKey
+	}
+	p := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	// Write and rename so readers never see a partial blob.
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".put-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), p)
+}
+
+func (s *LocalStore) Get(_ context.Context, key string) ([]byte, error) {
+	if !validKey(key) {
+		return nil, ErrInvalidKey
+	}
+	data, err := os.ReadFile(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+func (s *LocalStore) Exists(_ context.Context, key string) (bool, error) {
+	if !validKey(key) {
+		return false, ErrInvalidKey
+	}
+	_, err := os.Stat(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *LocalStore) SignedURL(_ context.Context, key string, expiry time.Duration) (string, error) {
+	if !validKey(key) {
+		return "", ErrInvalidKey
+	}
+	expires := strconv.FormatInt(s.now().Add(expiry).Unix(), 10)
+	return s.baseURL + "/blobs/" + key + "?expires=" + expires + "&sig=" + s.sign(key, expires), nil
+}
+
+// sign returns the hex HMAC-SHA256 of key and its expiry.
+func (s *LocalStore) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Handler serves blobs at /blobs/<key> to requests carrying an unexpired
+// signature from SignedURL.
+func (s *LocalStore) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key, ok := strings.CutPrefix(r.URL.Path, "/blobs/")
+		q := r.URL.Query()
+		expires, err := strconv.ParseInt(q.Get("expires"), 10, 64)
+		if !ok || err != nil || !hmac.Equal([]byte(q.Get("sig")), []byte(s.sign(key, q.Get("expires")))) {
+			http.Error(w, "invalid signature", http.StatusForbidden)
+			return
+		}
+		if s.now().Unix() > expires {
+			http.Error(w, "link expired", http.StatusForbidden)
+			return
+		}
+		data, err := s.Get(r.Context(), key)
+		if errors.Is(err, ErrNotFound) || errors.Is(err, ErrInvalidKey) {
+			http.NotFound(w, r)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if ct := mime.TypeByExtension(filepath.Ext(key)); ct != "" {
+			w.Header().Set("Content-Type", ct)
+		}
+		w.Header().Set("Cache-Control", "private, max-age="+strconv.FormatInt(expires-s.now().Unix(), 10))
+		w.Write(data)
+	})
+}
@@ -0,0 +1,91 @@
+// Package storage keeps rendered artifacts and exports as named blobs, on the
+// local filesystem or in an S3-compatible bucket, and hands out signed URLs
+// that serve them without further authentication until they expire.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotFound   = errors.New("blob not found")
+	ErrInvalidKey = errors.New("blob keys are slash-separated names of letters, digits, '.', '-' and '_'")
+)
+
+// Store holds blobs by key, e.g. "games/<id>/report.pdf".
+type Store interface {
+	// Put stores data under key, replacing any blob already there.
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Get returns the blob under key, or ErrNotFound.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Exists reports whether a blob is stored under key.
+	Exists(ctx context.Context, key string) (bool, error)
+	// SignedURL returns a URL serving the blob under key until expiry.
+	SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error)
+}
+
+// Open returns the store described by rawURL:
+//
+//	file:///var/lib/polite-betrayal/blobs[?base_url=https://api.example.com]
+//	s3://[access-key:secret-key@]bucket[?region=eu-west-1][&endpoint=https://minio:9000]
+//
+// Local blobs are served by the store's Handler under /blobs/, at base_url
+// if the API is reached through another origin; their URLs are signed with
+// secret. S3 credentials default to AWS_ACCESS_KEY_ID and
+// AWS_SECRET_ACCESS_KEY, the region to us-east-1, and the endpoint to AWS
+// itself; with an endpoint, buckets are addressed by path.
+func Open(rawURL string, secret []byte) (Store, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid blob store URL: %w", err)
+	}
+	q := u.Query()
+	switch u.Scheme {
+	case "file":
+		if u.Path == "" {
+			return nil, fmt.Errorf("blob store URL %q has no directory", rawURL)
+		}
+		return NewLocalStore(u.Path, q.Get("base_url"), secret)
+	case "s3":
+		if u.Host == "" {
+			return nil, fmt.Errorf("blob store URL %q has no bucket", rawURL)
+		}
+		accessKey, secretKey := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY")
+		if u.User != nil {
+			accessKey = u.User.Username()
+			secretKey, _ = u.User.Password()
+		}
+		region := q.Get("region")
+		if region == "" {
+			region = "us-east-1"
+		}
+		return NewS3Store(u.Host, region, q.Get("endpoint"), accessKey, secretKey)
+	default:
+		return nil, fmt.Errorf("blob store URL %q must be file:// or s3://", rawURL)
+	}
+}
+
+// validKey reports whether key is safe to use as a path on disk and in a
+// URL: no empty, "." or ".." segments and no characters needing escaping.
+func validKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, seg := range strings.Split(key, "/") {
+		if seg == "" || seg == "." || seg == ".." {
+			return false
+		}
+		for _, c := range seg {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '.' || c == '-' || c == '_') {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_games_deleted_at;
+UPDATE games SET status = status_before_delete WHERE status = 'deleted';
+ALTER TABLE games DROP COLUMN status_before_delete;
+ALTER TABLE games DROP COLUMN deleted_at;
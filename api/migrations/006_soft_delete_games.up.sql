@@ -0,0 +1,3 @@
+ALTER TABLE games ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE games ADD COLUMN status_before_delete TEXT;
+CREATE INDEX idx_games_deleted_at ON games (deleted_at) WHERE status = 'deleted';
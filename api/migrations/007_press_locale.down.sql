@@ -0,0 +1,2 @@
+ALTER TABLE messages DROP COLUMN intent;
+ALTER TABLE users DROP COLUMN locale;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS game_records;
+DROP TABLE IF EXISTS user_achievements;
@@ -0,0 +1,21 @@
+CREATE TABLE user_achievements (
+    user_id        UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind           TEXT NOT NULL, -- first_solo, early_solo, last_stand_draw, austrian_solo
+    game_id        UUID NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    power          TEXT NOT NULL,
+    year           INT NOT NULL,
+    supply_centers INT NOT NULL,
+    awarded_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, kind, game_id)
+);
+
+CREATE TABLE game_records (
+    game_id        UUID PRIMARY KEY REFERENCES games(id) ON DELETE CASCADE,
+    winner_id      UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    power          TEXT NOT NULL,
+    year           INT NOT NULL, -- year the solo was reached
+    supply_centers INT NOT NULL,
+    finished_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_game_records_fastest ON game_records (year, supply_centers DESC);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_games_content_hash;
+ALTER TABLE games DROP COLUMN content_hash;
@@ -0,0 +1,2 @@
+ALTER TABLE games ADD COLUMN content_hash TEXT;
+CREATE UNIQUE INDEX idx_games_content_hash ON games (content_hash) WHERE content_hash IS NOT NULL;
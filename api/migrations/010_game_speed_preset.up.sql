@@ -0,0 +1 @@
+ALTER TABLE games ADD COLUMN speed_preset TEXT NOT NULL DEFAULT '';
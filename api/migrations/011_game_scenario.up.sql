@@ -0,0 +1 @@
+ALTER TABLE games ADD COLUMN scenario TEXT NOT NULL DEFAULT 'standard';
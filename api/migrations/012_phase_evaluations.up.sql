@@ -0,0 +1,10 @@
+CREATE TABLE phase_evaluations (
+    phase_id   UUID NOT NULL REFERENCES phases(id) ON DELETE CASCADE,
+    power      TEXT NOT NULL,
+    heuristic  DOUBLE PRECISION NOT NULL,
+    share      DOUBLE PRECISION NOT NULL, -- fraction of the heuristic eval bar, 0..1
+    neural     DOUBLE PRECISION,          -- NULL when no value network was loaded
+    win_prob   DOUBLE PRECISION,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (phase_id, power)
+);
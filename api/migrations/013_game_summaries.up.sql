@@ -0,0 +1,10 @@
+CREATE TABLE game_summaries (
+    game_id      UUID PRIMARY KEY REFERENCES games(id) ON DELETE CASCADE,
+    winner       TEXT NOT NULL DEFAULT '',
+    final_year   INT NOT NULL,
+    powers       JSONB NOT NULL,
+    sc_history   JSONB NOT NULL,
+    map_svg      TEXT NOT NULL,
+    sc_graph_svg TEXT NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,3 @@
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS downgrade_reason,
+    DROP COLUMN IF EXISTS submitted_order;
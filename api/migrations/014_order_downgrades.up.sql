@@ -0,0 +1,3 @@
+ALTER TABLE orders
+    ADD COLUMN submitted_order  TEXT, -- DSON of an invalid order replaced by a hold
+    ADD COLUMN downgrade_reason TEXT; -- why it was rejected
@@ -0,0 +1,6 @@
+CREATE TABLE message_reads (
+    game_id      UUID NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    user_id      UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    last_read_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (game_id, user_id)
+);
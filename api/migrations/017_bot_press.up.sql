@@ -0,0 +1 @@
+ALTER TABLE games ADD COLUMN bot_press TEXT NOT NULL DEFAULT 'personality';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS rated_games;
+DROP TABLE IF EXISTS ratings;
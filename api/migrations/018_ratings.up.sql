@@ -0,0 +1,16 @@
+CREATE TABLE ratings (
+    subject_type TEXT NOT NULL, -- user or strategy
+    subject_id   TEXT NOT NULL, -- user ID or bot strategy name
+    rating       DOUBLE PRECISION NOT NULL,
+    games        INT NOT NULL DEFAULT 0,
+    wins         INT NOT NULL DEFAULT 0,
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (subject_type, subject_id)
+);
+
+CREATE INDEX idx_ratings_leaderboard ON ratings (subject_type, rating DESC);
+
+CREATE TABLE rated_games (
+    game_id  UUID PRIMARY KEY REFERENCES games(id) ON DELETE CASCADE,
+    rated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
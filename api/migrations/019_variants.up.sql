@@ -0,0 +1,9 @@
+CREATE TABLE variants (
+    name        TEXT PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT '',
+    definition  JSONB NOT NULL,          -- diplomacy.VariantDefinition, including geometry
+    playable    BOOLEAN NOT NULL,        -- keeps the standard map, so games can use it
+    created_by  UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
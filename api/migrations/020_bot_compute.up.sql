@@ -0,0 +1,12 @@
+CREATE TABLE bot_compute (
+    id         BIGSERIAL PRIMARY KEY,
+    game_id    UUID NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    phase_id   UUID NOT NULL REFERENCES phases(id) ON DELETE CASCADE,
+    power      TEXT NOT NULL,
+    strategy   TEXT NOT NULL,
+    compute_ms BIGINT NOT NULL, -- time spent generating the bot's orders
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_bot_compute_game ON bot_compute(game_id);
+CREATE INDEX idx_bot_compute_created ON bot_compute(created_at);
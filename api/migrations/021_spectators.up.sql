@@ -0,0 +1 @@
+ALTER TABLE game_players ADD COLUMN spectator BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,3 @@
+ALTER TABLE messages DROP COLUMN IF EXISTS channel_id;
+DROP TABLE IF EXISTS press_channel_members;
+DROP TABLE IF EXISTS press_channels;
@@ -0,0 +1,20 @@
+CREATE TABLE press_channels (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    game_id    UUID NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    name       TEXT NOT NULL,
+    created_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (game_id, name)
+);
+
+CREATE TABLE press_channel_members (
+    channel_id UUID NOT NULL REFERENCES press_channels(id) ON DELETE CASCADE,
+    user_id    UUID NOT NULL REFERENCES users(id),
+    joined_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (channel_id, user_id)
+);
+
+CREATE INDEX idx_press_channel_members_user ON press_channel_members(user_id);
+
+-- NULL = direct message (recipient_id set) or global press (recipient_id NULL)
+ALTER TABLE messages ADD COLUMN channel_id UUID REFERENCES press_channels(id) ON DELETE CASCADE;
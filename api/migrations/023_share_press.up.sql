@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN share_press BOOLEAN NOT NULL DEFAULT false;
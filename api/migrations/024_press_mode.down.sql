@@ -0,0 +1,2 @@
+ALTER TABLE games DROP COLUMN anonymous;
+ALTER TABLE games DROP COLUMN press_mode;
@@ -0,0 +1,2 @@
+ALTER TABLE games ADD COLUMN press_mode TEXT NOT NULL DEFAULT 'full';
+ALTER TABLE games ADD COLUMN anonymous BOOLEAN NOT NULL DEFAULT false;
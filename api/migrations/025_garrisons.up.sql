@@ -0,0 +1 @@
+ALTER TABLE games ADD COLUMN garrisons BOOLEAN NOT NULL DEFAULT false;
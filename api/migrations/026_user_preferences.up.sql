@@ -0,0 +1,7 @@
+CREATE TABLE user_preferences (
+    user_id    UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    namespace  TEXT NOT NULL,  -- e.g. "orders", "map", "notifications"
+    value      JSONB NOT NULL, -- always a JSON object
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, namespace)
+);
@@ -0,0 +1,2 @@
+ALTER TABLE games DROP COLUMN quorum_delay;
+ALTER TABLE games DROP COLUMN ready_quorum;
@@ -0,0 +1,2 @@
+ALTER TABLE games ADD COLUMN ready_quorum SMALLINT NOT NULL DEFAULT 0;
+ALTER TABLE games ADD COLUMN quorum_delay INTERVAL NOT NULL DEFAULT '10 minutes';
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_games_external_id;
+ALTER TABLE games DROP COLUMN external_id;
@@ -0,0 +1,2 @@
+ALTER TABLE games ADD COLUMN external_id TEXT;
+CREATE UNIQUE INDEX idx_games_external_id ON games (external_id) WHERE external_id IS NOT NULL;
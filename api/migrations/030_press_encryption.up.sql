@@ -0,0 +1,7 @@
+CREATE TABLE game_press_keys (
+    game_id     UUID PRIMARY KEY REFERENCES games(id) ON DELETE CASCADE,
+    wrapped_key BYTEA NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE messages ADD COLUMN encrypted BOOLEAN NOT NULL DEFAULT false;
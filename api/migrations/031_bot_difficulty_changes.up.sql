@@ -0,0 +1,19 @@
+-- History of bot strategy changes. A change requested mid-game stays
+-- pending (applied_at NULL) until the next phase starts; a player has at
+-- most one pending change.
+CREATE TABLE bot_difficulty_changes (
+    id              BIGSERIAL PRIMARY KEY,
+    game_id         UUID NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    user_id         UUID NOT NULL REFERENCES users(id),
+    power           TEXT,
+    from_difficulty TEXT NOT NULL,
+    to_difficulty   TEXT NOT NULL,
+    reason          TEXT NOT NULL,          -- creator or compute_cap
+    changed_by      UUID REFERENCES users(id),
+    phase_id        UUID REFERENCES phases(id) ON DELETE SET NULL, -- phase in play when it took effect
+    requested_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    applied_at      TIMESTAMPTZ
+);
+
+CREATE INDEX idx_bot_difficulty_changes_game ON bot_difficulty_changes(game_id, requested_at);
+CREATE UNIQUE INDEX idx_bot_difficulty_changes_pending ON bot_difficulty_changes(game_id, user_id) WHERE applied_at IS NULL;
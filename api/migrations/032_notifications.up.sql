@@ -0,0 +1,38 @@
+CREATE TABLE notification_channels (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id    UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind       TEXT NOT NULL,           -- webhook, discord or email
+    target     TEXT NOT NULL,           -- URL or email address
+    events     TEXT[] NOT NULL,
+    enabled    BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_notification_channels_user ON notification_channels(user_id);
+
+CREATE TABLE notifications (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id    UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    channel_id UUID NOT NULL REFERENCES notification_channels(id) ON DELETE CASCADE,
+    event      TEXT NOT NULL,
+    game_id    UUID NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    phase_id   UUID REFERENCES phases(id) ON DELETE CASCADE,
+    message    TEXT NOT NULL,
+    status     TEXT NOT NULL DEFAULT 'pending', -- pending, sent or failed
+    attempts   INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    sent_at    TIMESTAMPTZ
+);
+
+CREATE INDEX idx_notifications_user ON notifications(user_id, created_at DESC);
+CREATE INDEX idx_notifications_created ON notifications(created_at);
+
+-- Deadline reminders already sent for a phase, so each goes out once
+-- however many servers poll.
+CREATE TABLE notification_reminders (
+    phase_id   UUID NOT NULL REFERENCES phases(id) ON DELETE CASCADE,
+    event      TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (phase_id, event)
+);
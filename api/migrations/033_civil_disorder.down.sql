@@ -0,0 +1,5 @@
+ALTER TABLE game_players DROP COLUMN civil_disorder;
+ALTER TABLE game_players DROP COLUMN missed_deadlines;
+
+ALTER TABLE games DROP COLUMN civil_disorder_bot;
+ALTER TABLE games DROP COLUMN civil_disorder_after;
@@ -0,0 +1,10 @@
+-- Players who miss civil_disorder_after deadlines in a row (0 = never) are
+-- flagged as in civil disorder, and replaced by a bot playing
+-- civil_disorder_bot if it is set. Games created before this keep playing
+-- without civil disorder; new ones default to 3.
+ALTER TABLE games ADD COLUMN civil_disorder_after SMALLINT NOT NULL DEFAULT 0;
+ALTER TABLE games ALTER COLUMN civil_disorder_after SET DEFAULT 3;
+ALTER TABLE games ADD COLUMN civil_disorder_bot TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE game_players ADD COLUMN missed_deadlines SMALLINT NOT NULL DEFAULT 0;
+ALTER TABLE game_players ADD COLUMN civil_disorder BOOLEAN NOT NULL DEFAULT false;
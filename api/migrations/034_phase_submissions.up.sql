@@ -0,0 +1,10 @@
+-- Orders and ready flags submitted during a phase, mirrored from Redis so a
+-- restart mid-phase restores them. Rows go once the phase resolves.
+CREATE TABLE phase_submissions (
+    phase_id   UUID NOT NULL REFERENCES phases(id) ON DELETE CASCADE,
+    power      TEXT NOT NULL,
+    orders     JSONB,
+    ready      BOOLEAN NOT NULL DEFAULT false,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (phase_id, power)
+);
@@ -0,0 +1 @@
+ALTER TABLE games ADD COLUMN retreat_credits BOOLEAN NOT NULL DEFAULT false;
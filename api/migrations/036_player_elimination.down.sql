@@ -0,0 +1,2 @@
+ALTER TABLE game_players DROP COLUMN eliminated_year;
+ALTER TABLE game_players DROP COLUMN eliminated_by;
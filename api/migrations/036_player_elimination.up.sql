@@ -0,0 +1,2 @@
+ALTER TABLE game_players ADD COLUMN eliminated_by TEXT NOT NULL DEFAULT '';
+ALTER TABLE game_players ADD COLUMN eliminated_year INT NOT NULL DEFAULT 0;
@@ -0,0 +1 @@
+ALTER TABLE phase_evaluations DROP COLUMN draw_prob;
@@ -0,0 +1 @@
+ALTER TABLE phase_evaluations ADD COLUMN draw_prob DOUBLE PRECISION;
@@ -0,0 +1,12 @@
+CREATE TABLE order_reviews (
+    phase_id       UUID NOT NULL REFERENCES phases(id) ON DELETE CASCADE,
+    power          TEXT NOT NULL,
+    orders         TEXT NOT NULL,             -- as played, in DSON
+    suggested      TEXT NOT NULL,             -- the review strategy's orders, in DSON
+    eval           DOUBLE PRECISION NOT NULL, -- heuristic share after the played orders
+    suggested_eval DOUBLE PRECISION NOT NULL, -- heuristic share after the suggested orders
+    delta          DOUBLE PRECISION NOT NULL,
+    blunder        BOOLEAN NOT NULL,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (phase_id, power)
+);
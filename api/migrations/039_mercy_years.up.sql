@@ -0,0 +1 @@
+ALTER TABLE games ADD COLUMN mercy_years INT NOT NULL DEFAULT 0;
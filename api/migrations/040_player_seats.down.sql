@@ -0,0 +1,3 @@
+ALTER TABLE game_players DROP CONSTRAINT game_players_seat_check;
+DROP INDEX game_players_seat_key;
+ALTER TABLE game_players DROP COLUMN seat;
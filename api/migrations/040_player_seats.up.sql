@@ -0,0 +1,12 @@
+ALTER TABLE game_players ADD COLUMN seat INT;
+
+UPDATE game_players gp SET seat = numbered.seat
+FROM (
+    SELECT game_id, user_id, row_number() OVER (PARTITION BY game_id ORDER BY joined_at, user_id) - 1 AS seat
+    FROM game_players WHERE NOT spectator
+) numbered
+WHERE gp.game_id = numbered.game_id AND gp.user_id = numbered.user_id;
+
+-- Each player holds one seat and no two players share one; spectators hold none.
+CREATE UNIQUE INDEX game_players_seat_key ON game_players (game_id, seat);
+ALTER TABLE game_players ADD CONSTRAINT game_players_seat_check CHECK (spectator = (seat IS NULL));
@@ -0,0 +1 @@
+ALTER TABLE game_players DROP COLUMN bot_personality;
@@ -0,0 +1 @@
+ALTER TABLE game_players ADD COLUMN bot_personality JSONB;
@@ -0,0 +1,2 @@
+DROP TABLE variant_versions;
+ALTER TABLE variants DROP COLUMN version;
@@ -0,0 +1,26 @@
+-- Every upload of a variant is kept as an immutable version. Games store
+-- "name@version", so re-uploading a variant never changes games in progress.
+ALTER TABLE variants ADD COLUMN version INT NOT NULL DEFAULT 1;
+
+CREATE TABLE variant_versions (
+    name       TEXT NOT NULL REFERENCES variants(name) ON DELETE CASCADE,
+    version    INT NOT NULL,
+    definition JSONB NOT NULL,
+    playable   BOOLEAN NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (name, version)
+);
+
+-- Custom maps are now playable when every power is a standard one.
+UPDATE variants SET playable = NOT EXISTS (
+    SELECT 1 FROM jsonb_array_elements_text(definition->'powers') AS p(power)
+    WHERE p.power NOT IN ('austria', 'england', 'france', 'germany', 'italy', 'russia', 'turkey')
+) AND NOT EXISTS (
+    SELECT 1 FROM jsonb_array_elements(definition->'provinces') AS p(province)
+    WHERE COALESCE(p.province->>'home', '') NOT IN ('', 'austria', 'england', 'france', 'germany', 'italy', 'russia', 'turkey')
+);
+
+-- Games created before versioning store the bare name and play version 1.
+INSERT INTO variant_versions (name, version, definition, playable, created_by, created_at)
+SELECT name, 1, definition, playable, created_by, updated_at FROM variants;
@@ -0,0 +1,4 @@
+DROP INDEX idx_notification_channels_confirm;
+ALTER TABLE notification_channels DROP COLUMN confirm_sent_at;
+ALTER TABLE notification_channels DROP COLUMN confirm_token_hash;
+ALTER TABLE notification_channels DROP COLUMN confirmed;
@@ -0,0 +1,11 @@
+-- Email channels send nothing until their owner follows the link mailed to
+-- the address. confirm_token_hash is set while a confirmation is pending.
+ALTER TABLE notification_channels ADD COLUMN confirmed BOOLEAN NOT NULL DEFAULT true;
+ALTER TABLE notification_channels ADD COLUMN confirm_token_hash TEXT;
+ALTER TABLE notification_channels ADD COLUMN confirm_sent_at TIMESTAMPTZ;
+
+CREATE UNIQUE INDEX idx_notification_channels_confirm ON notification_channels(confirm_token_hash)
+    WHERE confirm_token_hash IS NOT NULL;
+
+-- Addresses registered before confirmation existed were never checked.
+UPDATE notification_channels SET confirmed = false WHERE kind = 'email';
@@ -0,0 +1,20 @@
+-- Who played each power and from when: the players powers were assigned to
+-- when the game started, then each bot that replaced a human.
+CREATE TABLE power_assignments (
+    id          BIGSERIAL PRIMARY KEY,
+    game_id     UUID NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    power       TEXT NOT NULL,
+    user_id     UUID NOT NULL REFERENCES users(id),
+    is_bot      BOOLEAN NOT NULL,
+    assigned_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_power_assignments_game ON power_assignments(game_id, assigned_at);
+
+-- Games already started get their current players. A bot that joined after
+-- the start replaced a human, who was not recorded, so it is assigned from
+-- when it joined.
+INSERT INTO power_assignments (game_id, power, user_id, is_bot, assigned_at)
+SELECT gp.game_id, gp.power, gp.user_id, gp.is_bot, GREATEST(g.started_at, gp.joined_at)
+FROM game_players gp JOIN games g ON g.id = gp.game_id
+WHERE gp.power IS NOT NULL AND NOT gp.spectator AND g.started_at IS NOT NULL;
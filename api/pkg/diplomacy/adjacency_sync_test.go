@@ -2,6 +2,7 @@ package diplomacy
 
 import (
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 	"testing"
@@ -865,7 +866,7 @@ func TestSplitCoastFleetReachability(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(fmt.Sprintf("%s/%s", tt.prov, tt.coast), func(t *testing.T) {
-			actual := m.ProvincesAdjacentTo(tt.prov, tt.coast, true)
+			actual := slices.Clone(m.ProvincesAdjacentTo(tt.prov, tt.coast, true))
 			sort.Strings(actual)
 			expected := make([]string, len(tt.expected))
 			copy(expected, tt.expected)
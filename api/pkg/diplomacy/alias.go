@@ -0,0 +1,112 @@
+package diplomacy
+
+import (
+	"strings"
+	"sync"
+)
+
+// provinceAliases maps alternate province codes used by other platforms
+// (webDiplomacy, Backstabbr, DAIDE, the python diplomacy package, DPjudge)
+// to this engine's canonical IDs.
+var provinceAliases = map[string]string{
+	"lyo": "gol", // Gulf of Lyon
+	"gul": "gol",
+	"nat": "nao", // North Atlantic Ocean
+	"nwg": "nrg", // Norwegian Sea
+	"nts": "nth", // North Sea
+	"mid": "mao", // Mid-Atlantic Ocean
+	"mat": "mao",
+	"gob": "bot", // Gulf of Bothnia
+	"eme": "eas", // Eastern Mediterranean
+	"wme": "wes", // Western Mediterranean
+	"tyn": "tys", // Tyrrhenian Sea
+	"hgb": "hel", // Heligoland Bight
+	"ech": "eng", // English Channel
+	"irs": "iri", // Irish Sea
+	"skg": "ska", // Skagerrak
+	"lvo": "lvn", // Livonia
+	"lpl": "lvp", // Liverpool
+	"nor": "nwy", // Norway
+}
+
+// coastAliases maps alternate coast spellings to canonical coasts.
+var coastAliases = map[string]Coast{
+	"n": NorthCoast, "nc": NorthCoast, "ncs": NorthCoast, "north": NorthCoast, "north coast": NorthCoast,
+	"s": SouthCoast, "sc": SouthCoast, "scs": SouthCoast, "south": SouthCoast, "south coast": SouthCoast,
+	"e": EastCoast, "ec": EastCoast, "ecs": EastCoast, "east": EastCoast, "east coast": EastCoast,
+	"w": WestCoast, "wc": WestCoast, "wcs": WestCoast, "west": WestCoast, "west coast": WestCoast,
+}
+
+var (
+	provinceNamesOnce sync.Once
+	provinceNames     map[string]string // normalized full name -> province ID
+)
+
+// normalizeName lowercases s and drops punctuation that differs between
+// platforms ("St. Petersburg" vs "st petersburg").
+func normalizeName(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.ReplaceAll(s, ".", "")
+	s = strings.ReplaceAll(s, "-", " ")
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// CanonicalProvince returns the canonical province ID for s. It accepts
+// canonical IDs in any case, known alias codes, and full province names.
+// Unknown input is returned lowercased so validators can report it.
+func CanonicalProvince(s string) string {
+	id := strings.ToLower(strings.TrimSpace(s))
+	if alias, ok := provinceAliases[id]; ok {
+		return alias
+	}
+	if len(id) <= 3 {
+		return id
+	}
+
+	provinceNamesOnce.Do(func() {
+		m := StandardMap()
+		provinceNames = make(map[string]string, len(m.Provinces))
+		for pid, p := range m.Provinces {
+			provinceNames[normalizeName(p.Name)] = pid
+		}
+	})
+	if pid, ok := provinceNames[normalizeName(s)]; ok {
+		return pid
+	}
+	return id
+}
+
+// CanonicalCoast returns the canonical coast for s ("north", "NCS", "n" all
+// map to NorthCoast). Unknown input is returned lowercased.
+func CanonicalCoast(s string) Coast {
+	c := normalizeName(s)
+	if coast, ok := coastAliases[c]; ok {
+		return coast
+	}
+	return Coast(c)
+}
+
+// CanonicalizeOrder returns o with every province and coast canonicalized.
+func CanonicalizeOrder(o Order) Order {
+	o.Location = CanonicalProvince(o.Location)
+	o.Coast = canonicalCoastOrNone(o.Coast)
+	o.Target = canonicalProvinceOrNone(o.Target)
+	o.TargetCoast = canonicalCoastOrNone(o.TargetCoast)
+	o.AuxLoc = canonicalProvinceOrNone(o.AuxLoc)
+	o.AuxTarget = canonicalProvinceOrNone(o.AuxTarget)
+	return o
+}
+
+func canonicalProvinceOrNone(s string) string {
+	if s == "" {
+		return ""
+	}
+	return CanonicalProvince(s)
+}
+
+func canonicalCoastOrNone(c Coast) Coast {
+	if c == NoCoast {
+		return NoCoast
+	}
+	return CanonicalCoast(string(c))
+}
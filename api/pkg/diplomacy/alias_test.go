@@ -0,0 +1,103 @@
+package diplomacy
+
+import "testing"
+
+func TestCanonicalProvince(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"gol", "gol"},
+		{"lyo", "gol"},
+		{"LYO", "gol"},
+		{"nat", "nao"},
+		{"nwg", "nrg"},
+		{"mid", "mao"},
+		{"gob", "bot"},
+		{"eme", "eas"},
+		{"wme", "wes"},
+		{"tyn", "tys"},
+		{"hgb", "hel"},
+		{"ech", "eng"},
+		{"nor", "nwy"},
+		{" Vie ", "vie"},
+		{"Gulf of Lyon", "gol"},
+		{"st petersburg", "stp"},
+		{"St. Petersburg", "stp"},
+		{"mid atlantic ocean", "mao"},
+		{"xyz", "xyz"}, // unknown stays as-is for validators to reject
+	}
+	for _, tt := range tests {
+		if got := CanonicalProvince(tt.in); got != tt.want {
+			t.Errorf("CanonicalProvince(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestProvinceAliasesAreUnambiguous(t *testing.T) {
+	m := StandardMap()
+	for alias, id := range provinceAliases {
+		if _, ok := m.Provinces[alias]; ok {
+			t.Errorf("alias %q shadows a canonical province", alias)
+		}
+		if _, ok := m.Provinces[id]; !ok {
+			t.Errorf("alias %q maps to unknown province %q", alias, id)
+		}
+	}
+}
+
+func TestCanonicalCoast(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Coast
+	}{
+		{"nc", NorthCoast},
+		{"NCS", NorthCoast},
+		{"north", NorthCoast},
+		{"South Coast", SouthCoast},
+		{"e", EastCoast},
+	}
+	for _, tt := range tests {
+		if got := CanonicalCoast(tt.in); got != tt.want {
+			t.Errorf("CanonicalCoast(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseDSON_Aliases(t *testing.T) {
+	orders, err := ParseDSON("F wme - lyo ; F nat S F nwg - nth ; F bar - stp/ncs")
+	if err != nil {
+		t.Fatalf("ParseDSON: %v", err)
+	}
+	if len(orders) != 3 {
+		t.Fatalf("got %d orders, want 3", len(orders))
+	}
+	if orders[0].Location != "wes" || orders[0].Target != "gol" {
+		t.Errorf("order 0 = %s -> %s, want wes -> gol", orders[0].Location, orders[0].Target)
+	}
+	if orders[1].Location != "nao" || orders[1].AuxLocation != "nrg" {
+		t.Errorf("order 1 = %s S %s, want nao S nrg", orders[1].Location, orders[1].AuxLocation)
+	}
+	if orders[2].Target != "stp" || orders[2].TargetCoast != NorthCoast {
+		t.Errorf("order 2 target = %s/%s, want stp/nc", orders[2].Target, orders[2].TargetCoast)
+	}
+}
+
+func TestValidateAndDefaultOrders_Aliases(t *testing.T) {
+	gs := NewInitialState()
+	m := StandardMap()
+	orders := []Order{{UnitType: Fleet, Power: England, Location: "lon", Type: OrderMove, Target: "ech"}}
+
+	valid, void := ValidateAndDefaultOrders(orders, gs, m)
+	if len(void) != 0 {
+		t.Fatalf("expected no void orders, got %v", void)
+	}
+	for _, o := range valid {
+		if o.Location == "lon" {
+			if o.Type != OrderMove || o.Target != "eng" {
+				t.Errorf("lon order = %v, want move to eng", o)
+			}
+			return
+		}
+	}
+	t.Fatal("lon order missing")
+}
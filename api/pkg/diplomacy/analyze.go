@@ -0,0 +1,202 @@
+package diplomacy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OrderWarning flags a legal order that cannot do what it appears meant to,
+// given the rest of its power's orders. Unlike a ValidationError it never
+// voids the order.
+type OrderWarning struct {
+	Index   int // position of the order in the analyzed set
+	Message string
+}
+
+// AnalyzeOrders checks a set of valid movement orders as a whole and warns
+// about supports of own units that don't match what those units were
+// ordered to do, including support cycles in which nothing moves; own army
+// convoys with no complete path of the power's own convoying fleets; and
+// moves certain to bounce off the power's own units. Units without an order
+// are taken to hold. Other powers' orders are unknown, so only a power's
+// own units are judged.
+func AnalyzeOrders(orders []Order, gs *GameState, m *DiplomacyMap) []OrderWarning {
+	a := orderAnalysis{orders: orders, gs: gs, m: m, byLoc: make(map[string]int, len(orders))}
+	for i, o := range orders {
+		a.byLoc[o.Location] = i
+	}
+	for i, o := range orders {
+		switch o.Type {
+		case OrderMove:
+			a.checkMove(i, o)
+		case OrderSupport:
+			a.checkSupport(i, o)
+		case OrderConvoy:
+			a.checkConvoy(i, o)
+		}
+	}
+	return a.warnings
+}
+
+// orderAnalysis holds the state of one AnalyzeOrders call.
+type orderAnalysis struct {
+	orders   []Order
+	gs       *GameState
+	m        *DiplomacyMap
+	byLoc    map[string]int // province -> index of the order of its unit
+	warnings []OrderWarning
+}
+
+func (a *orderAnalysis) warn(i int, format string, args ...any) {
+	a.warnings = append(a.warnings, OrderWarning{Index: i, Message: fmt.Sprintf(format, args...)})
+}
+
+// ownOrder returns the order given to the unit at province if it belongs to
+// power. ok is false for other powers' units and empty provinces; an own
+// unit without an order gets a hold.
+func (a *orderAnalysis) ownOrder(province string, power Power) (o Order, ok bool) {
+	u := a.gs.UnitAt(province)
+	if u == nil || u.Power != power {
+		return Order{}, false
+	}
+	if i, ok := a.byLoc[province]; ok {
+		return a.orders[i], true
+	}
+	return Order{UnitType: u.Type, Power: u.Power, Location: province, Coast: u.Coast, Type: OrderHold}, true
+}
+
+// needsConvoy reports whether an army move can only succeed by convoy.
+func (a *orderAnalysis) needsConvoy(o Order) bool {
+	return o.UnitType == Army && !a.m.Adjacent(o.Location, o.Coast, o.Target, NoCoast, false)
+}
+
+// ownSupport counts the power's supports for a move.
+func (a *orderAnalysis) ownSupport(move Order) int {
+	n := 0
+	for _, o := range a.orders {
+		if o.Type == OrderSupport && o.Power == move.Power && o.AuxLoc == move.Location && o.AuxTarget == move.Target {
+			n++
+		}
+	}
+	return n
+}
+
+// checkMove warns about moves into provinces the power's own units won't
+// leave, swaps, and own moves to the same province with equal support.
+func (a *orderAnalysis) checkMove(i int, o Order) {
+	if a.needsConvoy(o) {
+		if !a.hasOwnConvoyPath(o) {
+			a.warn(i, "no complete convoy path from %s to %s with your own fleets", o.Location, o.Target)
+		}
+	} else if occupant, ok := a.ownOrder(o.Target, o.Power); ok {
+		switch {
+		case occupant.Type != OrderMove:
+			a.warn(i, "%s is held by your own unit, which can't be dislodged", o.Target)
+			return
+		case occupant.Target == o.Location && !a.needsConvoy(occupant):
+			a.warn(i, "swaps places with your unit at %s; both moves bounce", o.Target)
+			return
+		}
+	}
+
+	strength := a.ownSupport(o)
+	for j, other := range a.orders {
+		if j != i && other.Type == OrderMove && other.Power == o.Power && other.Target == o.Target && a.ownSupport(other) >= strength {
+			a.warn(i, "bounces with your unit from %s also moving to %s", other.Location, o.Target)
+			return
+		}
+	}
+}
+
+// checkSupport warns about supports of own units that aren't ordered to do
+// what is supported.
+func (a *orderAnalysis) checkSupport(i int, o Order) {
+	supported, ok := a.ownOrder(o.AuxLoc, o.Power)
+	if !ok {
+		return
+	}
+	if o.AuxTarget == "" {
+		if supported.Type == OrderMove {
+			a.warn(i, "unit at %s is ordered to move, so it can't be supported to hold", o.AuxLoc)
+		}
+		return
+	}
+	if supported.Type == OrderMove && supported.Target == o.AuxTarget {
+		return
+	}
+	if cycle := a.supportCycle(o); cycle != nil {
+		a.warn(i, "supports form a cycle (%s) in which no unit moves", strings.Join(cycle, " -> "))
+		return
+	}
+	if _, ordered := a.byLoc[o.AuxLoc]; !ordered {
+		a.warn(i, "unit at %s has no order and will hold instead of moving to %s", o.AuxLoc, o.AuxTarget)
+		return
+	}
+	a.warn(i, "unit at %s is ordered %s, not to move to %s", o.AuxLoc, supported.Describe(), o.AuxTarget)
+}
+
+// supportCycle follows own units supporting each other from start and
+// returns the provinces of the cycle, starting and ending at start, or nil
+// if the chain doesn't lead back to it.
+func (a *orderAnalysis) supportCycle(start Order) []string {
+	path := []string{start.Location}
+	for o := start; len(path) <= len(a.orders); {
+		next, ok := a.ownOrder(o.AuxLoc, o.Power)
+		if !ok || next.Type != OrderSupport {
+			return nil
+		}
+		path = append(path, next.Location)
+		if next.Location == start.Location {
+			return path
+		}
+		o = next
+	}
+	return nil
+}
+
+// checkConvoy warns about convoys of own armies that aren't moving along
+// them or don't need them. Incomplete paths are reported on the move.
+func (a *orderAnalysis) checkConvoy(i int, o Order) {
+	army, ok := a.ownOrder(o.AuxLoc, o.Power)
+	if !ok {
+		return
+	}
+	switch {
+	case army.Type != OrderMove || army.Target != o.AuxTarget:
+		a.warn(i, "army at %s is not ordered to move to %s", o.AuxLoc, o.AuxTarget)
+	case !a.needsConvoy(army):
+		a.warn(i, "army at %s moves to %s directly, without the convoy", o.AuxLoc, o.AuxTarget)
+	case !a.hasOwnConvoyPath(army):
+		a.warn(i, "convoy of %s to %s has no complete path with your own fleets", o.AuxLoc, o.AuxTarget)
+	}
+}
+
+// hasOwnConvoyPath reports whether the power's fleets ordered to convoy a
+// move form a chain of seas from its origin to its target.
+func (a *orderAnalysis) hasOwnConvoyPath(move Order) bool {
+	var fleets []string
+	for _, o := range a.orders {
+		if o.Type == OrderConvoy && o.Power == move.Power && o.AuxLoc == move.Location && o.AuxTarget == move.Target {
+			if p := a.m.Provinces[o.Location]; p != nil && p.Type == Sea {
+				fleets = append(fleets, o.Location)
+			}
+		}
+	}
+
+	visited := make(map[string]bool)
+	queue := []string{move.Location}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current != move.Location && a.m.Adjacent(current, NoCoast, move.Target, NoCoast, true) {
+			return true
+		}
+		for _, f := range fleets {
+			if !visited[f] && a.m.Adjacent(current, NoCoast, f, NoCoast, true) {
+				visited[f] = true
+				queue = append(queue, f)
+			}
+		}
+	}
+	return false
+}
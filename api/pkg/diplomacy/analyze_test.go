@@ -0,0 +1,141 @@
+package diplomacy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeOrders(t *testing.T) {
+	m := StandardMap()
+	army := func(power Power, loc string) Unit { return Unit{Army, power, loc, NoCoast} }
+	fleet := func(power Power, loc string) Unit { return Unit{Fleet, power, loc, NoCoast} }
+	move := func(ut UnitType, loc, target string) Order {
+		return Order{UnitType: ut, Power: France, Location: loc, Type: OrderMove, Target: target}
+	}
+	support := func(ut UnitType, loc, auxLoc, auxTarget string) Order {
+		return Order{UnitType: ut, Power: France, Location: loc, Type: OrderSupport, AuxLoc: auxLoc, AuxTarget: auxTarget}
+	}
+	convoy := func(loc, auxLoc, auxTarget string) Order {
+		return Order{UnitType: Fleet, Power: France, Location: loc, Type: OrderConvoy, AuxLoc: auxLoc, AuxTarget: auxTarget}
+	}
+
+	tests := []struct {
+		name   string
+		units  []Unit
+		orders []Order
+		want   map[int]string // order index -> substring of its warning
+	}{
+		{
+			name:   "consistent support",
+			units:  []Unit{army(France, "par"), army(France, "mar")},
+			orders: []Order{move(Army, "par", "bur"), support(Army, "mar", "par", "bur")},
+		},
+		{
+			name:   "support of a unit moving elsewhere",
+			units:  []Unit{army(France, "par"), army(France, "mar")},
+			orders: []Order{move(Army, "par", "pic"), support(Army, "mar", "par", "bur")},
+			want:   map[int]string{1: "not to move to bur"},
+		},
+		{
+			name:   "support of an unordered unit",
+			units:  []Unit{army(France, "par"), army(France, "mar")},
+			orders: []Order{support(Army, "mar", "par", "bur")},
+			want:   map[int]string{0: "no order"},
+		},
+		{
+			name:   "hold support of a moving unit",
+			units:  []Unit{army(France, "par"), army(France, "bur")},
+			orders: []Order{move(Army, "par", "pic"), support(Army, "bur", "par", "")},
+			want:   map[int]string{1: "can't be supported to hold"},
+		},
+		{
+			name:   "support cycle",
+			units:  []Unit{army(France, "par"), army(France, "bur")},
+			orders: []Order{support(Army, "par", "bur", "pic"), support(Army, "bur", "par", "pic")},
+			want:   map[int]string{0: "cycle (par -> bur -> par)", 1: "cycle (bur -> par -> bur)"},
+		},
+		{
+			name:   "mutual hold supports",
+			units:  []Unit{army(France, "par"), army(France, "bur")},
+			orders: []Order{support(Army, "par", "bur", ""), support(Army, "bur", "par", "")},
+		},
+		{
+			name:   "support of another power's unit",
+			units:  []Unit{army(France, "mar"), army(Germany, "mun")},
+			orders: []Order{support(Army, "mar", "mun", "bur")},
+		},
+		{
+			name:   "complete own convoy",
+			units:  []Unit{army(France, "bre"), fleet(France, "mao")},
+			orders: []Order{move(Army, "bre", "por"), convoy("mao", "bre", "por")},
+		},
+		{
+			name:   "convoy missing a fleet",
+			units:  []Unit{army(France, "bre"), fleet(France, "eng"), fleet(England, "mao")},
+			orders: []Order{move(Army, "bre", "por"), convoy("eng", "bre", "por")},
+			want:   map[int]string{0: "no complete convoy path", 1: "no complete path"},
+		},
+		{
+			name:   "convoy of an army that isn't moving",
+			units:  []Unit{army(France, "bre"), fleet(France, "mao")},
+			orders: []Order{convoy("mao", "bre", "por")},
+			want:   map[int]string{0: "not ordered to move to por"},
+		},
+		{
+			name:   "convoy of a direct move",
+			units:  []Unit{army(France, "bre"), fleet(France, "eng")},
+			orders: []Order{move(Army, "bre", "pic"), convoy("eng", "bre", "pic")},
+			want:   map[int]string{1: "directly"},
+		},
+		{
+			name:   "move into own holding unit",
+			units:  []Unit{army(France, "par"), army(France, "bur")},
+			orders: []Order{move(Army, "par", "bur")},
+			want:   map[int]string{0: "held by your own unit"},
+		},
+		{
+			name:   "swap with own unit",
+			units:  []Unit{army(France, "par"), army(France, "bur")},
+			orders: []Order{move(Army, "par", "bur"), move(Army, "bur", "par")},
+			want:   map[int]string{0: "swaps places", 1: "swaps places"},
+		},
+		{
+			name:   "own moves to the same province",
+			units:  []Unit{army(France, "par"), army(France, "mar")},
+			orders: []Order{move(Army, "par", "bur"), move(Army, "mar", "bur")},
+			want:   map[int]string{0: "bounces", 1: "bounces"},
+		},
+		{
+			name:   "supported move beats own unsupported move",
+			units:  []Unit{army(France, "par"), army(France, "mar"), army(France, "pic")},
+			orders: []Order{move(Army, "par", "bur"), move(Army, "mar", "bur"), support(Army, "pic", "par", "bur")},
+			want:   map[int]string{1: "bounces"},
+		},
+		{
+			name:   "follow-on move",
+			units:  []Unit{army(France, "par"), army(France, "bur")},
+			orders: []Order{move(Army, "par", "bur"), move(Army, "bur", "mun")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings := AnalyzeOrders(tt.orders, stateWith(tt.units...), m)
+			got := make(map[int]string)
+			for _, w := range warnings {
+				if _, dup := got[w.Index]; dup {
+					t.Errorf("order %d has several warnings", w.Index)
+				}
+				got[w.Index] = w.Message
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("expected warnings for %d orders, got %v", len(tt.want), got)
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(got[i], want) {
+					t.Errorf("order %d: expected warning containing %q, got %q", i, want, got[i])
+				}
+			}
+		})
+	}
+}
@@ -1,5 +1,7 @@
 package diplomacy
 
+import "sort"
+
 // BuildOrderType represents a build-phase order.
 type BuildOrderType int
 
@@ -42,8 +44,8 @@ func ValidateBuildOrder(order BuildOrder, gs *GameState, m *DiplomacyMap) error
 }
 
 func validateBuild(order BuildOrder, gs *GameState, m *DiplomacyMap) error {
-	// Power must have more SCs than units
-	if gs.SupplyCenterCount(order.Power) <= gs.UnitCount(order.Power) {
+	// Power must have more SCs than units, or build credits
+	if gs.AdjustmentDelta(order.Power) <= 0 {
 		return &ValidationError{
 			Order:   Order{Location: order.Location, Power: order.Power},
 			Message: "no builds available (units >= supply centers)",
@@ -145,9 +147,7 @@ func ResolveBuildOrders(orders []BuildOrder, gs *GameState, m *DiplomacyMap) []B
 	}
 
 	for _, power := range AllPowers() {
-		scCount := gs.SupplyCenterCount(power)
-		unitCount := gs.UnitCount(power)
-		diff := scCount - unitCount
+		diff := gs.AdjustmentDelta(power)
 
 		submitted := buildsByPower[power]
 
@@ -196,7 +196,13 @@ func ResolveBuildOrders(orders []BuildOrder, gs *GameState, m *DiplomacyMap) []B
 
 			// Civil disorder: auto-disband units furthest from home if not enough disbands
 			if disbanded < needed {
-				autoResults := civilDisorder(power, needed-disbanded, gs, m)
+				ordered := make(map[string]bool)
+				for _, r := range results {
+					if r.Order.Power == power && r.Order.Type == DisbandUnit && r.Result == ResultSucceeded {
+						ordered[r.Order.Location] = true
+					}
+				}
+				autoResults := civilDisorder(power, needed-disbanded, ordered, gs, m)
 				results = append(results, autoResults...)
 			}
 		}
@@ -206,47 +212,16 @@ func ResolveBuildOrders(orders []BuildOrder, gs *GameState, m *DiplomacyMap) []B
 }
 
 // civilDisorder auto-disbands units when a power hasn't submitted enough disband orders.
-// Disbands units furthest from home supply centers (by BFS distance).
-func civilDisorder(power Power, count int, gs *GameState, m *DiplomacyMap) []BuildResult {
-	units := gs.UnitsOf(power)
-	if len(units) == 0 || count == 0 {
-		return nil
-	}
-
-	homes := HomeCenters(power)
-
-	// Calculate minimum distance from each unit to any home SC
-	type unitDist struct {
-		unit Unit
-		dist int
-	}
-	var distances []unitDist
-	for _, u := range units {
-		dist := minDistanceToHome(u.Province, homes, m)
-		distances = append(distances, unitDist{u, dist})
-	}
-
-	// Sort by distance descending (disband furthest first)
-	// Use simple selection since we only need `count` items
+// Units already disbanded by order are skipped; the rest go in DisbandPriority order.
+func civilDisorder(power Power, count int, ordered map[string]bool, gs *GameState, m *DiplomacyMap) []BuildResult {
 	var results []BuildResult
-	disbanded := make(map[string]bool)
-	for range count {
-		maxDist := -1
-		maxIdx := -1
-		for j, ud := range distances {
-			if disbanded[ud.unit.Province] {
-				continue
-			}
-			if ud.dist > maxDist {
-				maxDist = ud.dist
-				maxIdx = j
-			}
-		}
-		if maxIdx < 0 {
+	for _, u := range DisbandPriority(power, gs, m) {
+		if len(results) == count {
 			break
 		}
-		u := distances[maxIdx].unit
-		disbanded[u.Province] = true
+		if ordered[u.Province] {
+			continue
+		}
 		results = append(results, BuildResult{
 			Order: BuildOrder{
 				Power:    power,
@@ -257,10 +232,93 @@ func civilDisorder(power Power, count int, gs *GameState, m *DiplomacyMap) []Bui
 			Result: ResultSucceeded,
 		})
 	}
-
 	return results
 }
 
+// DisbandPriority ranks a power's units in the order civil disorder disbands
+// them: furthest from a home supply center first (by BFS distance), fleets
+// before armies at equal distance, then alphabetically by province. The
+// ranking depends only on the position, so it can be shown to players before
+// the build phase resolves.
+func DisbandPriority(power Power, gs *GameState, m *DiplomacyMap) []Unit {
+	units := gs.UnitsOf(power)
+	if len(units) == 0 {
+		return nil
+	}
+	homes := m.HomeCenters(power)
+	dist := make(map[string]int, len(units))
+	for _, u := range units {
+		dist[u.Province] = minDistanceToHome(u.Province, homes, m)
+	}
+	sort.SliceStable(units, func(i, j int) bool {
+		a, b := units[i], units[j]
+		if dist[a.Province] != dist[b.Province] {
+			return dist[a.Province] > dist[b.Province]
+		}
+		if a.Type != b.Type {
+			return a.Type == Fleet
+		}
+		return a.Province < b.Province
+	})
+	return units
+}
+
+// PendingDisbands returns, for each power with more units than supply centers
+// in a build phase, how many units it must disband.
+func PendingDisbands(gs *GameState) map[Power]int {
+	pending := make(map[Power]int)
+	for _, power := range AllPowers() {
+		if excess := gs.UnitCount(power) - gs.SupplyCenterCount(power); excess > 0 {
+			pending[power] = excess
+		}
+	}
+	return pending
+}
+
+// Adjustment is a power's build-phase arithmetic: how its units compare to
+// its supply centers and where it can build.
+type Adjustment struct {
+	SupplyCenters   int      `json:"supply_centers"`
+	Units           int      `json:"units"`
+	Credits         int      `json:"credits,omitempty"`           // units retreated off the board that may be rebuilt
+	Builds          int      `json:"builds,omitempty"`            // surplus centers or credits, capped by open home centers
+	Disbands        int      `json:"disbands,omitempty"`          // units over the center count
+	OpenHomeCenters []string `json:"open_home_centers,omitempty"` // owned, unoccupied home centers
+}
+
+// Adjustments returns the adjustment for every power that still has units
+// or supply centers.
+func Adjustments(gs *GameState) map[Power]Adjustment {
+	out := make(map[Power]Adjustment)
+	for _, power := range AllPowers() {
+		a := Adjustment{SupplyCenters: gs.SupplyCenterCount(power), Units: gs.UnitCount(power), Credits: gs.BuildCredits[power]}
+		if a.SupplyCenters == 0 && a.Units == 0 {
+			continue
+		}
+		a.OpenHomeCenters = OpenHomeCenters(gs, power)
+		if delta := gs.AdjustmentDelta(power); delta > 0 {
+			a.Builds = min(delta, len(a.OpenHomeCenters))
+		} else {
+			a.Disbands = -delta
+		}
+		out[power] = a
+	}
+	return out
+}
+
+// OpenHomeCenters returns the home supply centers a power owns and has no
+// unit in, sorted; these are where it may build.
+func OpenHomeCenters(gs *GameState, power Power) []string {
+	var open []string
+	for _, sc := range gs.Map().HomeCenters(power) {
+		if gs.SupplyCenters[sc] == power && gs.UnitAt(sc) == nil {
+			open = append(open, sc)
+		}
+	}
+	sort.Strings(open)
+	return open
+}
+
 // minDistanceToHome computes the minimum BFS distance from a province to any home SC.
 func minDistanceToHome(from string, homes []string, m *DiplomacyMap) int {
 	if len(homes) == 0 {
@@ -0,0 +1,122 @@
+package diplomacy
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Calendar is the shape of a scenario's game year: the movement seasons
+// played each year in order and the year play starts in. Supply centers
+// change hands after the last season, which is followed by the build phase.
+// Names gives clients display names for seasons and phase types, keyed by
+// their identifiers ("spring", "build"); unnamed ones fall back to the
+// identifier, capitalized.
+type Calendar struct {
+	StartYear int               `json:"start_year"`
+	Seasons   []Season          `json:"seasons"`
+	Names     map[string]string `json:"names,omitempty"`
+}
+
+// standardCalendar is the Spring/Fall year starting in 1901.
+var standardCalendar = Calendar{
+	StartYear: 1901,
+	Seasons:   []Season{Spring, Fall},
+	Names: map[string]string{
+		string(Spring):        "Spring",
+		string(Fall):          "Fall",
+		string(PhaseMovement): "Movement",
+		string(PhaseRetreat):  "Retreat",
+		string(PhaseBuild):    "Build",
+	},
+}
+
+// StandardCalendar returns the calendar of the standard game.
+func StandardCalendar() Calendar {
+	c := standardCalendar
+	c.Seasons = slices.Clone(c.Seasons)
+	c.Names = maps.Clone(c.Names)
+	return c
+}
+
+// Validate checks that the calendar has at least one season, no season
+// twice, only seasons DFEN can encode, and a start year within MaxYear. It
+// returns a *VariantError.
+func (c Calendar) Validate() error {
+	if problems := c.problems(); len(problems) > 0 {
+		return &VariantError{Problems: problems}
+	}
+	return nil
+}
+
+func (c Calendar) problems() []string {
+	var problems []string
+	if len(c.Seasons) == 0 {
+		problems = append(problems, "calendar needs at least one season")
+	}
+	seen := make(map[Season]bool, len(c.Seasons))
+	for _, s := range c.Seasons {
+		if _, ok := seasonToChar[s]; !ok || seen[s] {
+			problems = append(problems, fmt.Sprintf("calendar season %q is unknown or listed twice", s))
+		}
+		seen[s] = true
+	}
+	if c.StartYear < 1 || c.StartYear > MaxYear {
+		problems = append(problems, fmt.Sprintf("calendar start_year must be between 1 and %d", MaxYear))
+	}
+	return problems
+}
+
+// FirstSeason returns the season each year opens with.
+func (c Calendar) FirstSeason() Season {
+	return c.Seasons[0]
+}
+
+// IsLastSeason reports whether s closes the year, so that supply centers
+// change hands once its movement and retreats are over. A season the
+// calendar doesn't know is treated as the last.
+func (c Calendar) IsLastSeason(s Season) bool {
+	i := slices.Index(c.Seasons, s)
+	return i < 0 || i == len(c.Seasons)-1
+}
+
+// afterMovement returns the phase that follows the movement (and any
+// retreats) of season s: the next season's movement, or the build phase at
+// the end of the year.
+func (c Calendar) afterMovement(s Season) (Season, PhaseType) {
+	if i := slices.Index(c.Seasons, s); i >= 0 && i < len(c.Seasons)-1 {
+		return c.Seasons[i+1], PhaseMovement
+	}
+	return s, PhaseBuild
+}
+
+// Name returns the display name of a season or phase type.
+func (c Calendar) Name(id string) string {
+	if name := c.Names[id]; name != "" {
+		return name
+	}
+	if id == "" {
+		return id
+	}
+	return strings.ToUpper(id[:1]) + id[1:]
+}
+
+// PhaseTitle names a phase for display, e.g. "Spring 1901 Movement".
+func (c Calendar) PhaseTitle(year int, season Season, phase PhaseType) string {
+	return fmt.Sprintf("%s %d %s", c.Name(string(season)), year, c.Name(string(phase)))
+}
+
+// Calendar returns the calendar of the scenario the state is playing.
+func (gs *GameState) Calendar() Calendar {
+	if gs.Scenario == "" {
+		return standardCalendar
+	}
+	return gs.scenario().calendar()
+}
+
+// IsLastSeason reports whether the state is in the season that closes the
+// year, after whose movement and retreats supply centers change hands.
+func (gs *GameState) IsLastSeason() bool {
+	return gs.Calendar().IsLastSeason(gs.Season)
+}
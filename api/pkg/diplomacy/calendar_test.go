@@ -0,0 +1,88 @@
+package diplomacy
+
+import "testing"
+
+func TestStandardCalendarCycle(t *testing.T) {
+	gs := NewInitialState()
+	want := []string{"1901fm", "1901fb", "1902sm"}
+	for _, phase := range want {
+		AdvanceState(gs, false)
+		if got := EncodeDFENPhase(gs); got != phase {
+			t.Fatalf("expected %s, got %s", phase, got)
+		}
+	}
+	if got := gs.Calendar().PhaseTitle(1901, Spring, PhaseMovement); got != "Spring 1901 Movement" {
+		t.Errorf("unexpected title %q", got)
+	}
+}
+
+func TestFourSeasonCalendar(t *testing.T) {
+	v := StandardVariant()
+	v.Name = "test-four-seasons"
+	v.Calendar = &Calendar{
+		StartYear: 1500,
+		Seasons:   []Season{Spring, Summer, Fall, Winter},
+		Names:     map[string]string{"winter": "Hiver", "build": "Adjustments"},
+	}
+	if err := v.Validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if err := RegisterScenario(v.Scenario()); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	sc, _ := LookupScenario("test-four-seasons")
+	gs := sc.InitialState()
+	if gs.Year != 1500 || gs.Season != Spring {
+		t.Fatalf("expected Spring 1500, got %s %d", gs.Season, gs.Year)
+	}
+
+	// Move a French army into Belgium; it only takes the center at the end
+	// of the year.
+	for i := range gs.Units {
+		if gs.Units[i].Province == "par" {
+			gs.Units[i].Province = "bel"
+		}
+	}
+	for _, phase := range []string{"1500um", "1500fm", "1500wm", "1500wb", "1501sm"} {
+		if phase != "1501sm" && gs.SupplyCenters["bel"] == France {
+			t.Fatalf("belgium changed hands before the end of the year (%s)", EncodeDFENPhase(gs))
+		}
+		AdvanceState(gs, false)
+		if got := EncodeDFENPhase(gs); got != phase {
+			t.Fatalf("expected %s, got %s", phase, got)
+		}
+	}
+	if gs.SupplyCenters["bel"] != France {
+		t.Fatal("expected belgium to change hands after winter")
+	}
+
+	if _, season, _, err := DecodeDFENPhase("1500wm"); err != nil || season != Winter {
+		t.Errorf("expected winter to decode from DFEN, got %q, %v", season, err)
+	}
+	if got := gs.Calendar().PhaseTitle(1500, Winter, PhaseBuild); got != "Hiver 1500 Adjustments" {
+		t.Errorf("unexpected title %q", got)
+	}
+
+	gs.Season = Fall
+	if gs.IsLastSeason() {
+		t.Error("expected fall not to close a four-season year")
+	}
+	// Bots ask this of every position they search.
+	if allocs := testing.AllocsPerRun(100, func() { gs.IsLastSeason() }); allocs != 0 {
+		t.Errorf("expected no allocations looking up the calendar, got %v", allocs)
+	}
+}
+
+func TestCalendarValidate(t *testing.T) {
+	v := StandardVariant()
+	v.Name = "test-bad-calendar"
+	v.Calendar = &Calendar{StartYear: 0, Seasons: []Season{Spring, "monsoon", Spring}}
+	err := v.Validate()
+	ve, ok := err.(*VariantError)
+	if !ok || len(ve.Problems) != 3 {
+		t.Fatalf("expected three calendar problems, got %v", err)
+	}
+	if err := (Calendar{StartYear: 1901}).Validate(); err == nil {
+		t.Error("expected a calendar without seasons to be rejected")
+	}
+}
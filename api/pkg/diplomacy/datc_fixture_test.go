@@ -0,0 +1,353 @@
+package diplomacy
+
+import (
+	"bufio"
+	"embed"
+	"fmt"
+	"io/fs"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// DATC fixtures live in testdata/datc, one file per section. Each case is a
+// block of directives:
+//
+//	case 6.A.5 Support to hold yourself is not possible
+//	italy: A ven H
+//	austria: A tyr S A tri - ven ; A tri - ven
+//	expect tri bounced
+//	expect ven succeeded
+//
+// Order lines ("<power>: <DSON>") also place the ordered units, so most
+// cases need nothing else. "units <power>: A vie ; F tri/sc" places units
+// without orders, "centers <power>: vie bud" sets supply center owners and
+// "season fall" the season. "phase retreat" or "phase build" starts the next
+// stage: a retreat stage continues from the movement stage's result.
+// "expect <loc> <result>" checks the outcome of the unit's order in the
+// current stage, where <result> is an OrderResult name, or several joined
+// by "|" when the rules allow either; "void" means the order was rejected
+// by validation (the unit still gets a hold result).
+// "xfail <reason>" marks a case the adjudicator is known to get wrong: it
+// still runs, and is reported as skipped with the mismatches.
+//
+//go:embed testdata/datc/*.txt
+var datcFixtures embed.FS
+
+type datcExpect struct {
+	loc    string
+	result string
+}
+
+type datcStage struct {
+	phase   PhaseType
+	orders  map[Power]string
+	expects []datcExpect
+}
+
+type datcCase struct {
+	id, title string
+	file      string
+	line      int
+	xfail     string
+	season    Season
+	units     []Unit
+	centers   map[string]Power
+	stages    []*datcStage
+}
+
+func loadDATCCases(t *testing.T) []*datcCase {
+	t.Helper()
+	files, err := fs.Glob(datcFixtures, "testdata/datc/*.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cases []*datcCase
+	for _, f := range files {
+		data, err := datcFixtures.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cs, err := parseDATCFile(path.Base(f), string(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		cases = append(cases, cs...)
+	}
+	return cases
+}
+
+func parseDATCFile(name, data string) ([]*datcCase, error) {
+	var cases []*datcCase
+	var c *datcCase
+	sc := bufio.NewScanner(strings.NewReader(data))
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, rest, _ := strings.Cut(line, " ")
+		rest = strings.TrimSpace(rest)
+		if key == "case" {
+			id, title, _ := strings.Cut(rest, " ")
+			c = &datcCase{
+				id: id, title: title, file: name, line: n,
+				season:  Spring,
+				centers: map[string]Power{},
+				stages:  []*datcStage{{phase: PhaseMovement, orders: map[Power]string{}}},
+			}
+			cases = append(cases, c)
+			continue
+		}
+		if c == nil {
+			return nil, fmt.Errorf("%s:%d: directive before first case", name, n)
+		}
+		if err := c.directive(key, rest); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", name, n, err)
+		}
+	}
+	return cases, sc.Err()
+}
+
+func (c *datcCase) directive(key, rest string) error {
+	stage := c.stages[len(c.stages)-1]
+	switch key {
+	case "xfail":
+		c.xfail = rest
+	case "season":
+		c.season = Season(rest)
+	case "phase":
+		if len(c.stages) == 1 && len(stage.orders) == 0 && len(stage.expects) == 0 {
+			stage.phase = PhaseType(rest) // the case starts in this phase
+			break
+		}
+		c.stages = append(c.stages, &datcStage{phase: PhaseType(rest), orders: map[Power]string{}})
+	case "expect":
+		f := strings.Fields(rest)
+		if len(f) != 2 {
+			return fmt.Errorf("expect wants <loc> <result>, got %q", rest)
+		}
+		stage.expects = append(stage.expects, datcExpect{loc: f[0], result: f[1]})
+	case "units":
+		power, list, ok := strings.Cut(rest, ":")
+		if !ok {
+			return fmt.Errorf("units wants <power>: <units>")
+		}
+		for _, s := range strings.Split(list, ";") {
+			u, err := parseDATCUnit(Power(power), strings.TrimSpace(s))
+			if err != nil {
+				return err
+			}
+			c.units = append(c.units, u)
+		}
+	case "centers":
+		power, list, ok := strings.Cut(rest, ":")
+		if !ok {
+			return fmt.Errorf("centers wants <power>: <provinces>")
+		}
+		for _, p := range strings.Fields(list) {
+			c.centers[p] = Power(power)
+		}
+	default:
+		power, ok := strings.CutSuffix(key, ":")
+		if !ok {
+			return fmt.Errorf("unknown directive %q", key)
+		}
+		if prev := stage.orders[Power(power)]; prev != "" {
+			rest = prev + " ; " + rest
+		}
+		stage.orders[Power(power)] = rest
+	}
+	return nil
+}
+
+// parseDATCUnit parses "A vie" or "F stp/sc".
+func parseDATCUnit(power Power, s string) (Unit, error) {
+	f := strings.Fields(s)
+	if len(f) != 2 {
+		return Unit{}, fmt.Errorf("bad unit %q", s)
+	}
+	ut, err := parseDSONUnitChar(f[0])
+	if err != nil {
+		return Unit{}, err
+	}
+	prov, coast, err := parseDSONLocation(f[1])
+	if err != nil {
+		return Unit{}, err
+	}
+	return Unit{Type: ut, Power: power, Province: prov, Coast: coast}, nil
+}
+
+// initialState places the listed units plus every unit ordered in the first
+// stage that isn't already on the board.
+func (c *datcCase) initialState() (*GameState, error) {
+	gs := &GameState{
+		Year:          1901,
+		Season:        c.season,
+		Phase:         c.stages[0].phase,
+		Units:         append([]Unit(nil), c.units...),
+		SupplyCenters: c.centers,
+	}
+	if gs.Phase != PhaseMovement {
+		return gs, nil
+	}
+	for _, power := range slices.Sorted(maps.Keys(c.stages[0].orders)) {
+		orders, err := ParseDSON(c.stages[0].orders[power])
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range orders {
+			if gs.UnitAt(o.Location) == nil {
+				gs.Units = append(gs.Units, Unit{Type: o.UnitType, Power: power, Province: o.Location, Coast: o.Coast})
+			}
+		}
+	}
+	return gs, nil
+}
+
+// run plays every stage of the case and returns the expectation mismatches.
+func (c *datcCase) run(m *DiplomacyMap) ([]string, error) {
+	gs, err := c.initialState()
+	if err != nil {
+		return nil, err
+	}
+	var mismatches []string
+	for i, stage := range c.stages {
+		if i > 0 {
+			gs.Phase = stage.phase
+		}
+		got, err := c.runStage(stage, gs, m)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range stage.expects {
+			if !slices.ContainsFunc(strings.Split(e.result, "|"), func(r string) bool { return got[e.loc][r] }) {
+				mismatches = append(mismatches, fmt.Sprintf("%s %s: want %s, got %s", stage.phase, e.loc, e.result, resultNames(got[e.loc])))
+			}
+		}
+	}
+	return mismatches, nil
+}
+
+// runStage adjudicates one stage's orders, applies them to gs and returns
+// the set of results per unit location.
+func (c *datcCase) runStage(stage *datcStage, gs *GameState, m *DiplomacyMap) (map[string]map[string]bool, error) {
+	got := map[string]map[string]bool{}
+	record := func(loc string, r OrderResult) {
+		if got[loc] == nil {
+			got[loc] = map[string]bool{}
+		}
+		got[loc][r.String()] = true
+	}
+	var dson []DSONOrder
+	var powers []Power
+	for _, power := range slices.Sorted(maps.Keys(stage.orders)) {
+		orders, err := ParseDSON(stage.orders[power])
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range orders {
+			dson = append(dson, o)
+			powers = append(powers, power)
+		}
+	}
+
+	switch stage.phase {
+	case PhaseMovement:
+		orders := make([]Order, len(dson))
+		for i, d := range dson {
+			orders[i] = DSONToOrder(d, powers[i])
+		}
+		orders, voids := ValidateAndDefaultOrders(orders, gs, m)
+		for _, v := range voids {
+			record(v.Order.Location, ResultVoid)
+		}
+		results, dislodged := ResolveOrders(orders, gs, m)
+		for _, r := range results {
+			record(r.Order.Location, r.Result)
+		}
+		ApplyResolution(gs, m, results, dislodged)
+	case PhaseRetreat:
+		orders := make([]RetreatOrder, len(dson))
+		for i, d := range dson {
+			orders[i] = DSONToRetreatOrder(d, powers[i])
+		}
+		results := ResolveRetreats(orders, gs, m)
+		for _, r := range results {
+			record(r.Order.Location, r.Result)
+		}
+		ApplyRetreats(gs, results, m)
+	case PhaseBuild:
+		orders := make([]BuildOrder, len(dson))
+		for i, d := range dson {
+			orders[i] = DSONToBuildOrder(d, powers[i])
+		}
+		results := ResolveBuildOrders(orders, gs, m)
+		for _, r := range results {
+			record(r.Order.Location, r.Result)
+		}
+		ApplyBuildOrders(gs, results)
+	default:
+		return nil, fmt.Errorf("unknown phase %q", stage.phase)
+	}
+	return got, nil
+}
+
+func resultNames(set map[string]bool) string {
+	if len(set) == 0 {
+		return "no order"
+	}
+	var names []string
+	for n := range set {
+		names = append(names, n)
+	}
+	return strings.Join(names, "+")
+}
+
+// TestDATC runs every DATC fixture case as a subtest and logs a pass/fail
+// tally per section.
+func TestDATC(t *testing.T) {
+	m := StandardMap()
+	cases := loadDATCCases(t)
+	if len(cases) == 0 {
+		t.Fatal("no DATC fixtures found")
+	}
+
+	type tally struct{ pass, known, fail int }
+	sections := map[string]*tally{}
+	var order []string
+	for _, c := range cases {
+		section := c.id[:strings.LastIndex(c.id, ".")]
+		if sections[section] == nil {
+			sections[section] = &tally{}
+			order = append(order, section)
+		}
+		s := sections[section]
+		t.Run(c.id, func(t *testing.T) {
+			mismatches, err := c.run(m)
+			if err != nil {
+				s.fail++
+				t.Fatalf("%s:%d: %v", c.file, c.line, err)
+			}
+			switch {
+			case c.xfail != "" && len(mismatches) > 0:
+				s.known++
+				t.Skipf("%s (known failure: %s): %s", c.title, c.xfail, strings.Join(mismatches, "; "))
+			case c.xfail != "":
+				s.fail++
+				t.Errorf("%s:%d: %s now passes; remove its xfail", c.file, c.line, c.title)
+			case len(mismatches) > 0:
+				s.fail++
+				t.Errorf("%s:%d: %s: %s", c.file, c.line, c.title, strings.Join(mismatches, "; "))
+			default:
+				s.pass++
+			}
+		})
+	}
+	for _, name := range order {
+		s := sections[name]
+		t.Logf("DATC %s: %d passed, %d known failures, %d failed", name, s.pass, s.known, s.fail)
+	}
+}
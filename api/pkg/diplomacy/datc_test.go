@@ -12,7 +12,7 @@ func TestDATC_6A1_MoveToNonAdjacentFails(t *testing.T) {
 	m := StandardMap()
 	gs := stateWith(Unit{Fleet, England, "nth", NoCoast})
 	orders := []Order{
-		{Fleet, England, "nth", NoCoast, OrderMove, "pic", NoCoast, "", "", Army},
+		{Fleet, England, "nth", NoCoast, OrderMove, "pic", NoCoast, "", "", Army, NoCoast},
 	}
 	orders, _ = ValidateAndDefaultOrders(orders, gs, m)
 	results, _ := ResolveOrders(orders, gs, m)
@@ -28,11 +28,14 @@ func TestDATC_6A2_ArmyToSea(t *testing.T) {
 	m := StandardMap()
 	gs := stateWith(Unit{Army, England, "lvp", NoCoast})
 	orders := []Order{
-		{Army, England, "lvp", NoCoast, OrderMove, "iri", NoCoast, "", "", Army},
+		{Army, England, "lvp", NoCoast, OrderMove, "iri", NoCoast, "", "", Army, NoCoast},
 	}
 	orders, voids := ValidateAndDefaultOrders(orders, gs, m)
 	if len(voids) == 0 {
-		t.Error("army move to sea should be void")
+		t.Fatal("army move to sea should be void")
+	}
+	if voids[0].Reason == "" {
+		t.Error("void order should record why it was rejected")
 	}
 }
 
@@ -41,7 +44,7 @@ func TestDATC_6A3_FleetToLand(t *testing.T) {
 	m := StandardMap()
 	gs := stateWith(Unit{Fleet, Germany, "kie", NoCoast})
 	orders := []Order{
-		{Fleet, Germany, "kie", NoCoast, OrderMove, "mun", NoCoast, "", "", Army},
+		{Fleet, Germany, "kie", NoCoast, OrderMove, "mun", NoCoast, "", "", Army, NoCoast},
 	}
 	_, voids := ValidateAndDefaultOrders(orders, gs, m)
 	if len(voids) == 0 {
@@ -58,9 +61,9 @@ func TestDATC_6A5_SelfSupportHold(t *testing.T) {
 		Unit{Army, Austria, "tri", NoCoast},
 	)
 	orders := []Order{
-		{Army, Italy, "ven", NoCoast, OrderHold, "", NoCoast, "", "", Army},
-		{Army, Austria, "tyr", NoCoast, OrderSupport, "", NoCoast, "tri", "ven", Army},
-		{Army, Austria, "tri", NoCoast, OrderMove, "ven", NoCoast, "", "", Army},
+		{Army, Italy, "ven", NoCoast, OrderHold, "", NoCoast, "", "", Army, NoCoast},
+		{Army, Austria, "tyr", NoCoast, OrderSupport, "", NoCoast, "tri", "ven", Army, NoCoast},
+		{Army, Austria, "tri", NoCoast, OrderMove, "ven", NoCoast, "", "", Army, NoCoast},
 	}
 	orders, _ = ValidateAndDefaultOrders(orders, gs, m)
 	results, _ := ResolveOrders(orders, gs, m)
@@ -82,9 +85,9 @@ func TestDATC_6A6_UnitMoveWithSupportOrder(t *testing.T) {
 		Unit{Army, Germany, "mun", NoCoast},
 	)
 	orders := []Order{
-		{Army, Germany, "ber", NoCoast, OrderSupport, "", NoCoast, "kie", "mun", Fleet}, // invalid support
-		{Fleet, Germany, "kie", NoCoast, OrderMove, "ber", NoCoast, "", "", Army},
-		{Army, Germany, "mun", NoCoast, OrderMove, "sil", NoCoast, "", "", Army},
+		{Army, Germany, "ber", NoCoast, OrderSupport, "", NoCoast, "kie", "mun", Fleet, NoCoast}, // invalid support
+		{Fleet, Germany, "kie", NoCoast, OrderMove, "ber", NoCoast, "", "", Army, NoCoast},
+		{Army, Germany, "mun", NoCoast, OrderMove, "sil", NoCoast, "", "", Army, NoCoast},
 	}
 	orders, _ = ValidateAndDefaultOrders(orders, gs, m)
 	results, _ := ResolveOrders(orders, gs, m)
@@ -105,7 +108,7 @@ func TestDATC_6B1_FleetMoveToSplitCoastOneOption(t *testing.T) {
 	m := StandardMap()
 	gs := stateWith(Unit{Fleet, France, "gol", NoCoast})
 	orders := []Order{
-		{Fleet, France, "gol", NoCoast, OrderMove, "spa", NoCoast, "", "", Army},
+		{Fleet, France, "gol", NoCoast, OrderMove, "spa", NoCoast, "", "", Army, NoCoast},
 	}
 	orders, voids := ValidateAndDefaultOrders(orders, gs, m)
 	// Only SC reachable from GoL, so it should be accepted
@@ -119,7 +122,7 @@ func TestDATC_6B3_FleetWrongCoast(t *testing.T) {
 	m := StandardMap()
 	gs := stateWith(Unit{Fleet, France, "gol", NoCoast})
 	orders := []Order{
-		{Fleet, France, "gol", NoCoast, OrderMove, "spa", NorthCoast, "", "", Army},
+		{Fleet, France, "gol", NoCoast, OrderMove, "spa", NorthCoast, "", "", Army, NoCoast},
 	}
 	_, voids := ValidateAndDefaultOrders(orders, gs, m)
 	// NC is not reachable from GoL
@@ -140,9 +143,9 @@ func TestDATC_6C1_ThreeArmyCircularMovement(t *testing.T) {
 		Unit{Army, Germany, "sil", NoCoast},
 	)
 	orders := []Order{
-		{Army, Germany, "boh", NoCoast, OrderMove, "mun", NoCoast, "", "", Army},
-		{Army, Germany, "mun", NoCoast, OrderMove, "sil", NoCoast, "", "", Army},
-		{Army, Germany, "sil", NoCoast, OrderMove, "boh", NoCoast, "", "", Army},
+		{Army, Germany, "boh", NoCoast, OrderMove, "mun", NoCoast, "", "", Army, NoCoast},
+		{Army, Germany, "mun", NoCoast, OrderMove, "sil", NoCoast, "", "", Army, NoCoast},
+		{Army, Germany, "sil", NoCoast, OrderMove, "boh", NoCoast, "", "", Army, NoCoast},
 	}
 	orders, _ = ValidateAndDefaultOrders(orders, gs, m)
 	results, _ := ResolveOrders(orders, gs, m)
@@ -165,10 +168,10 @@ func TestDATC_6C2_CircularMovementWithSupport(t *testing.T) {
 		Unit{Army, Germany, "tyr", NoCoast},
 	)
 	orders := []Order{
-		{Army, Germany, "boh", NoCoast, OrderMove, "mun", NoCoast, "", "", Army},
-		{Army, Germany, "mun", NoCoast, OrderMove, "sil", NoCoast, "", "", Army},
-		{Army, Germany, "sil", NoCoast, OrderMove, "boh", NoCoast, "", "", Army},
-		{Army, Germany, "tyr", NoCoast, OrderSupport, "", NoCoast, "boh", "mun", Army},
+		{Army, Germany, "boh", NoCoast, OrderMove, "mun", NoCoast, "", "", Army, NoCoast},
+		{Army, Germany, "mun", NoCoast, OrderMove, "sil", NoCoast, "", "", Army, NoCoast},
+		{Army, Germany, "sil", NoCoast, OrderMove, "boh", NoCoast, "", "", Army, NoCoast},
+		{Army, Germany, "tyr", NoCoast, OrderSupport, "", NoCoast, "boh", "mun", Army, NoCoast},
 	}
 	orders, _ = ValidateAndDefaultOrders(orders, gs, m)
 	results, _ := ResolveOrders(orders, gs, m)
@@ -191,9 +194,9 @@ func TestDATC_6D1_SupportedHold(t *testing.T) {
 		Unit{Army, Russia, "rum", NoCoast},
 	)
 	orders := []Order{
-		{Army, Austria, "bud", NoCoast, OrderHold, "", NoCoast, "", "", Army},
-		{Army, Austria, "ser", NoCoast, OrderSupport, "", NoCoast, "bud", "", Army},
-		{Army, Russia, "rum", NoCoast, OrderMove, "bud", NoCoast, "", "", Army},
+		{Army, Austria, "bud", NoCoast, OrderHold, "", NoCoast, "", "", Army, NoCoast},
+		{Army, Austria, "ser", NoCoast, OrderSupport, "", NoCoast, "bud", "", Army, NoCoast},
+		{Army, Russia, "rum", NoCoast, OrderMove, "bud", NoCoast, "", "", Army, NoCoast},
 	}
 	orders, _ = ValidateAndDefaultOrders(orders, gs, m)
 	results, _ := ResolveOrders(orders, gs, m)
@@ -216,10 +219,10 @@ func TestDATC_6D2_MoveCutsSupportOnHold(t *testing.T) {
 		Unit{Army, Russia, "bul", NoCoast},
 	)
 	orders := []Order{
-		{Army, Austria, "bud", NoCoast, OrderHold, "", NoCoast, "", "", Army},
-		{Army, Austria, "ser", NoCoast, OrderSupport, "", NoCoast, "bud", "", Army},
-		{Army, Russia, "rum", NoCoast, OrderMove, "bud", NoCoast, "", "", Army},
-		{Army, Russia, "bul", NoCoast, OrderMove, "ser", NoCoast, "", "", Army},
+		{Army, Austria, "bud", NoCoast, OrderHold, "", NoCoast, "", "", Army, NoCoast},
+		{Army, Austria, "ser", NoCoast, OrderSupport, "", NoCoast, "bud", "", Army, NoCoast},
+		{Army, Russia, "rum", NoCoast, OrderMove, "bud", NoCoast, "", "", Army, NoCoast},
+		{Army, Russia, "bul", NoCoast, OrderMove, "ser", NoCoast, "", "", Army, NoCoast},
 	}
 	orders, _ = ValidateAndDefaultOrders(orders, gs, m)
 	results, _ := ResolveOrders(orders, gs, m)
@@ -243,10 +246,10 @@ func TestDATC_6D3_MoveCutsSupportOnMove(t *testing.T) {
 		Unit{Army, Turkey, "bul", NoCoast},
 	)
 	orders := []Order{
-		{Army, Austria, "ser", NoCoast, OrderSupport, "", NoCoast, "bud", "rum", Army},
-		{Army, Austria, "bud", NoCoast, OrderMove, "rum", NoCoast, "", "", Army},
-		{Army, Russia, "rum", NoCoast, OrderHold, "", NoCoast, "", "", Army},
-		{Army, Turkey, "bul", NoCoast, OrderMove, "ser", NoCoast, "", "", Army}, // Cuts Serbia's support
+		{Army, Austria, "ser", NoCoast, OrderSupport, "", NoCoast, "bud", "rum", Army, NoCoast},
+		{Army, Austria, "bud", NoCoast, OrderMove, "rum", NoCoast, "", "", Army, NoCoast},
+		{Army, Russia, "rum", NoCoast, OrderHold, "", NoCoast, "", "", Army, NoCoast},
+		{Army, Turkey, "bul", NoCoast, OrderMove, "ser", NoCoast, "", "", Army, NoCoast}, // Cuts Serbia's support
 	}
 	orders, _ = ValidateAndDefaultOrders(orders, gs, m)
 	results, _ := ResolveOrders(orders, gs, m)
@@ -269,9 +272,9 @@ func TestDATC_6D4_SupportToHoldOnUnitSupportingHold(t *testing.T) {
 		Unit{Army, Russia, "pru", NoCoast},
 	)
 	orders := []Order{
-		{Army, Germany, "ber", NoCoast, OrderSupport, "", NoCoast, "kie", "", Fleet},
-		{Fleet, Germany, "kie", NoCoast, OrderSupport, "", NoCoast, "ber", "", Army},
-		{Army, Russia, "pru", NoCoast, OrderMove, "ber", NoCoast, "", "", Army},
+		{Army, Germany, "ber", NoCoast, OrderSupport, "", NoCoast, "kie", "", Fleet, NoCoast},
+		{Fleet, Germany, "kie", NoCoast, OrderSupport, "", NoCoast, "ber", "", Army, NoCoast},
+		{Army, Russia, "pru", NoCoast, OrderMove, "ber", NoCoast, "", "", Army, NoCoast},
 	}
 	orders, _ = ValidateAndDefaultOrders(orders, gs, m)
 	results, _ := ResolveOrders(orders, gs, m)
@@ -292,10 +295,10 @@ func TestDATC_6D7_SupportCantBeCutByTarget(t *testing.T) {
 		Unit{Army, Austria, "boh", NoCoast},
 	)
 	orders := []Order{
-		{Army, Germany, "mun", NoCoast, OrderSupport, "", NoCoast, "sil", "boh", Army},
-		{Army, Germany, "sil", NoCoast, OrderMove, "boh", NoCoast, "", "", Army},
-		{Army, Russia, "war", NoCoast, OrderMove, "sil", NoCoast, "", "", Army},
-		{Army, Austria, "boh", NoCoast, OrderMove, "mun", NoCoast, "", "", Army},
+		{Army, Germany, "mun", NoCoast, OrderSupport, "", NoCoast, "sil", "boh", Army, NoCoast},
+		{Army, Germany, "sil", NoCoast, OrderMove, "boh", NoCoast, "", "", Army, NoCoast},
+		{Army, Russia, "war", NoCoast, OrderMove, "sil", NoCoast, "", "", Army, NoCoast},
+		{Army, Austria, "boh", NoCoast, OrderMove, "mun", NoCoast, "", "", Army, NoCoast},
 	}
 	orders, _ = ValidateAndDefaultOrders(orders, gs, m)
 	results, _ := ResolveOrders(orders, gs, m)
@@ -317,8 +320,8 @@ func TestDATC_6E1_NoSwapWithoutConvoy(t *testing.T) {
 		Unit{Army, Italy, "ven", NoCoast},
 	)
 	orders := []Order{
-		{Army, Italy, "rom", NoCoast, OrderMove, "ven", NoCoast, "", "", Army},
-		{Army, Italy, "ven", NoCoast, OrderMove, "rom", NoCoast, "", "", Army},
+		{Army, Italy, "rom", NoCoast, OrderMove, "ven", NoCoast, "", "", Army, NoCoast},
+		{Army, Italy, "ven", NoCoast, OrderMove, "rom", NoCoast, "", "", Army, NoCoast},
 	}
 	orders, _ = ValidateAndDefaultOrders(orders, gs, m)
 	results, _ := ResolveOrders(orders, gs, m)
@@ -341,9 +344,9 @@ func TestDATC_6E2_SupportedHeadToHead(t *testing.T) {
 		Unit{Army, Italy, "ven", NoCoast},
 	)
 	orders := []Order{
-		{Army, Austria, "tri", NoCoast, OrderSupport, "", NoCoast, "tyr", "ven", Army},
-		{Army, Austria, "tyr", NoCoast, OrderMove, "ven", NoCoast, "", "", Army},
-		{Army, Italy, "ven", NoCoast, OrderMove, "tyr", NoCoast, "", "", Army},
+		{Army, Austria, "tri", NoCoast, OrderSupport, "", NoCoast, "tyr", "ven", Army, NoCoast},
+		{Army, Austria, "tyr", NoCoast, OrderMove, "ven", NoCoast, "", "", Army, NoCoast},
+		{Army, Italy, "ven", NoCoast, OrderMove, "tyr", NoCoast, "", "", Army, NoCoast},
 	}
 	orders, _ = ValidateAndDefaultOrders(orders, gs, m)
 	results, _ := ResolveOrders(orders, gs, m)
@@ -366,9 +369,9 @@ func TestDATC_6E6_BeleagueredGarrison(t *testing.T) {
 		Unit{Army, Italy, "tyr", NoCoast},
 	)
 	orders := []Order{
-		{Army, Germany, "mun", NoCoast, OrderHold, "", NoCoast, "", "", Army},
-		{Army, France, "bur", NoCoast, OrderMove, "mun", NoCoast, "", "", Army},
-		{Army, Italy, "tyr", NoCoast, OrderMove, "mun", NoCoast, "", "", Army},
+		{Army, Germany, "mun", NoCoast, OrderHold, "", NoCoast, "", "", Army, NoCoast},
+		{Army, France, "bur", NoCoast, OrderMove, "mun", NoCoast, "", "", Army, NoCoast},
+		{Army, Italy, "tyr", NoCoast, OrderMove, "mun", NoCoast, "", "", Army, NoCoast},
 	}
 	orders, _ = ValidateAndDefaultOrders(orders, gs, m)
 	results, _ := ResolveOrders(orders, gs, m)
@@ -394,8 +397,8 @@ func TestDATC_6F1_SimpleConvoy(t *testing.T) {
 		Unit{Fleet, England, "nth", NoCoast},
 	)
 	orders := []Order{
-		{Army, England, "lon", NoCoast, OrderMove, "nwy", NoCoast, "", "", Army},
-		{Fleet, England, "nth", NoCoast, OrderConvoy, "", NoCoast, "lon", "nwy", Army},
+		{Army, England, "lon", NoCoast, OrderMove, "nwy", NoCoast, "", "", Army, NoCoast},
+		{Fleet, England, "nth", NoCoast, OrderConvoy, "", NoCoast, "lon", "nwy", Army, NoCoast},
 	}
 	orders, _ = ValidateAndDefaultOrders(orders, gs, m)
 	results, _ := ResolveOrders(orders, gs, m)
@@ -414,10 +417,10 @@ func TestDATC_6F2_DisruptedConvoy(t *testing.T) {
 		Unit{Fleet, France, "bel", NoCoast},
 	)
 	orders := []Order{
-		{Army, England, "lon", NoCoast, OrderMove, "nwy", NoCoast, "", "", Army},
-		{Fleet, England, "nth", NoCoast, OrderConvoy, "", NoCoast, "lon", "nwy", Army},
-		{Fleet, France, "eng", NoCoast, OrderMove, "nth", NoCoast, "", "", Army},
-		{Fleet, France, "bel", NoCoast, OrderSupport, "", NoCoast, "eng", "nth", Fleet},
+		{Army, England, "lon", NoCoast, OrderMove, "nwy", NoCoast, "", "", Army, NoCoast},
+		{Fleet, England, "nth", NoCoast, OrderConvoy, "", NoCoast, "lon", "nwy", Army, NoCoast},
+		{Fleet, France, "eng", NoCoast, OrderMove, "nth", NoCoast, "", "", Army, NoCoast},
+		{Fleet, France, "bel", NoCoast, OrderSupport, "", NoCoast, "eng", "nth", Fleet, NoCoast},
 	}
 	orders, _ = ValidateAndDefaultOrders(orders, gs, m)
 	results, _ := ResolveOrders(orders, gs, m)
@@ -453,7 +456,7 @@ func TestRetreatBasic(t *testing.T) {
 	}
 
 	orders := []RetreatOrder{
-		{Army, Germany, "bur", NoCoast, RetreatMove, "mun", NoCoast},
+		{Army, Germany, "bur", NoCoast, RetreatMove, "mun", NoCoast, false},
 	}
 	results := ResolveRetreats(orders, gs, m)
 	for _, r := range results {
@@ -483,7 +486,7 @@ func TestRetreatCannotGoToAttackerProvince(t *testing.T) {
 	}
 
 	orders := []RetreatOrder{
-		{Army, Germany, "bur", NoCoast, RetreatMove, "par", NoCoast},
+		{Army, Germany, "bur", NoCoast, RetreatMove, "par", NoCoast, false},
 	}
 	results := ResolveRetreats(orders, gs, m)
 	for _, r := range results {
@@ -510,8 +513,8 @@ func TestRetreatBounce(t *testing.T) {
 	}
 
 	orders := []RetreatOrder{
-		{Army, Germany, "mun", NoCoast, RetreatMove, "ruh", NoCoast},
-		{Army, France, "bur", NoCoast, RetreatMove, "ruh", NoCoast},
+		{Army, Germany, "mun", NoCoast, RetreatMove, "ruh", NoCoast, false},
+		{Army, France, "bur", NoCoast, RetreatMove, "ruh", NoCoast, false},
 	}
 	results := ResolveRetreats(orders, gs, m)
 	// Both try to retreat to Ruhr -> both bounced/disbanded
@@ -614,6 +617,61 @@ func TestCivilDisorder(t *testing.T) {
 	}
 }
 
+func TestCivilDisorderSkipsOrderedDisbands(t *testing.T) {
+	m := StandardMap()
+	gs := &GameState{
+		Year:   1901,
+		Season: Fall,
+		Phase:  PhaseBuild,
+		Units: []Unit{
+			{Army, France, "spa", NoCoast},
+			{Army, France, "por", NoCoast},
+			{Army, France, "bur", NoCoast},
+		},
+		SupplyCenters: map[string]Power{"par": France},
+	}
+
+	// France orders Portugal (the furthest unit) disbanded but owes two disbands.
+	orders := []BuildOrder{{Power: France, Type: DisbandUnit, UnitType: Army, Location: "por"}}
+	results := ResolveBuildOrders(orders, gs, m)
+
+	disbanded := map[string]int{}
+	for _, r := range results {
+		if r.Order.Type == DisbandUnit && r.Result == ResultSucceeded {
+			disbanded[r.Order.Location]++
+		}
+	}
+	// spa and bur are both one step from home; the tie goes alphabetically.
+	if len(disbanded) != 2 || disbanded["por"] != 1 || disbanded["bur"] != 1 {
+		t.Errorf("expected por ordered and bur auto-disbanded, got %v", disbanded)
+	}
+}
+
+func TestDisbandPriorityTieBreak(t *testing.T) {
+	m := StandardMap()
+	gs := &GameState{
+		Phase: PhaseBuild,
+		Units: []Unit{
+			{Army, England, "wal", NoCoast},
+			{Fleet, England, "iri", NoCoast},
+			{Army, England, "yor", NoCoast},
+			{Fleet, England, "nwy", NoCoast},
+		},
+		SupplyCenters: map[string]Power{"lon": England},
+	}
+
+	got := DisbandPriority(England, gs, m)
+	want := []string{"nwy", "iri", "wal", "yor"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d units, got %d", len(want), len(got))
+	}
+	for i, u := range got {
+		if u.Province != want[i] {
+			t.Errorf("rank %d: expected %s, got %s", i, want[i], u.Province)
+		}
+	}
+}
+
 // === PHASE SEQUENCING ===
 
 func TestPhaseSequencing(t *testing.T) {
@@ -37,13 +37,17 @@ var powerOrder = []Power{Austria, England, France, Germany, Italy, Russia, Turke
 // seasonToChar maps Season to DFEN character.
 var seasonToChar = map[Season]byte{
 	Spring: 's',
+	Summer: 'u',
 	Fall:   'f',
+	Winter: 'w',
 }
 
 // charToSeason maps DFEN character to Season.
 var charToSeason = map[byte]Season{
 	's': Spring,
+	'u': Summer,
 	'f': Fall,
+	'w': Winter,
 }
 
 // phaseToChar maps PhaseType to DFEN character.
@@ -78,6 +82,13 @@ func EncodeDFEN(gs *GameState) string {
 	return b.String()
 }
 
+// EncodeDFENPhase returns the phase field of a DFEN string, e.g. "1901sm".
+func EncodeDFENPhase(gs *GameState) string {
+	var b strings.Builder
+	encodePhaseInfo(&b, gs)
+	return b.String()
+}
+
 // encodePhaseInfo writes the year+season+phase portion of DFEN.
 func encodePhaseInfo(b *strings.Builder, gs *GameState) {
 	b.WriteString(strconv.Itoa(gs.Year))
@@ -94,7 +105,8 @@ func encodeUnitLocation(b *strings.Builder, province string, coast Coast) {
 	}
 }
 
-// encodeUnits writes the units section, sorted by power then province.
+// encodeUnits writes the units section, sorted by power then province, with
+// neutral garrisons last.
 func encodeUnits(b *strings.Builder, gs *GameState) {
 	if len(gs.Units) == 0 {
 		b.WriteByte('-')
@@ -102,8 +114,11 @@ func encodeUnits(b *strings.Builder, gs *GameState) {
 	}
 
 	grouped := groupUnitsByPower(gs.Units)
+	allPowers := append([]Power{}, powerOrder...)
+	allPowers = append(allPowers, Neutral)
+
 	first := true
-	for _, power := range powerOrder {
+	for _, power := range allPowers {
 		units := grouped[power]
 		sort.Slice(units, func(i, j int) bool {
 			return units[i].Province < units[j].Province
@@ -202,35 +217,168 @@ func groupUnitsByPower(units []Unit) map[Power][]Unit {
 	return grouped
 }
 
-// DecodeDFEN parses a DFEN string into a GameState.
+// DecodeDFEN parses a DFEN string into a GameState. It checks the grammar
+// only and fails on the first malformed token with a *DFENError; use
+// DecodeDFENMode to also check the position or to skip bad entries.
 func DecodeDFEN(s string) (*GameState, error) {
-	parts := strings.SplitN(s, "/", 4)
-	if len(parts) != 4 {
-		return nil, fmt.Errorf("dfen: expected 4 sections separated by '/', got %d", len(parts))
+	return decodeDFEN(s, DFENGrammar)
+}
+
+// DecodeDFENMode parses a DFEN string into a GameState, treating problems as
+// mode says. Strict decoding fails with a *DFENError; lenient decoding
+// returns the position together with a DFENErrors listing the entries it
+// skipped, or fails with a *DFENError if the layout or phase is malformed.
+func DecodeDFENMode(s string, mode DFENMode) (*GameState, error) {
+	return decodeDFEN(s, mode)
+}
+
+// decodeDFEN parses s as mode says.
+func decodeDFEN(s string, mode DFENMode) (*GameState, error) {
+	sections := splitDFEN(s, 0, "/")
+	if len(sections) != 4 {
+		return nil, &DFENError{Section: DFENSectionLayout, Token: s, Reason: fmt.Sprintf("expected 4 sections separated by '/', got %d", len(sections))}
 	}
 
-	gs := &GameState{}
+	gs := &GameState{SupplyCenters: make(map[string]Power)}
+	phase := sections[0]
+	if err := decodePhaseInfo(phase.text, gs); err != nil {
+		return nil, &DFENError{Section: DFENSectionPhase, Offset: phase.offset, Token: phase.text, Reason: err.Error()}
+	}
 
-	if err := decodePhaseInfo(parts[0], gs); err != nil {
+	d := &dfenDecoder{mode: mode, gs: gs}
+	if err := d.units(sections[1]); err != nil {
 		return nil, err
 	}
-	if err := decodeUnits(parts[1], gs); err != nil {
+	if err := d.supplyCenters(sections[2]); err != nil {
 		return nil, err
 	}
-	if err := decodeSupplyCenters(parts[2], gs); err != nil {
+	if err := d.dislodged(sections[3]); err != nil {
 		return nil, err
 	}
-	if err := decodeDislodged(parts[3], gs); err != nil {
-		return nil, err
+	if len(d.skipped) > 0 {
+		return gs, d.skipped
 	}
-
 	return gs, nil
 }
 
+// dfenSpan is a piece of a DFEN string and its byte offset.
+type dfenSpan struct {
+	text   string
+	offset int
+}
+
+// splitDFEN splits s, found at offset in the DFEN string, on sep, keeping
+// each piece's offset.
+func splitDFEN(s string, offset int, sep string) []dfenSpan {
+	var spans []dfenSpan
+	for {
+		piece, rest, found := strings.Cut(s, sep)
+		spans = append(spans, dfenSpan{piece, offset})
+		if !found {
+			return spans
+		}
+		offset += len(piece) + len(sep)
+		s = rest
+	}
+}
+
+// dfenDecoder decodes the entry sections of a DFEN string into gs.
+type dfenDecoder struct {
+	mode     DFENMode
+	gs       *GameState
+	centers  map[string]bool // supply centers listed, for map checks
+	occupied map[string]bool // provinces holding a unit, for map checks
+	skipped  DFENErrors      // entries skipped in lenient mode
+}
+
+// problem reports a bad entry. It returns the error to fail with, or nil
+// if decoding skips the entry and goes on.
+func (d *dfenDecoder) problem(section string, entry dfenSpan, reason string) error {
+	err := &DFENError{Section: section, Offset: entry.offset, Token: entry.text, Reason: reason}
+	if d.mode == DFENLenient {
+		d.skipped = append(d.skipped, err)
+		return nil
+	}
+	return err
+}
+
+// units parses "Aavie,Aabud,Aftri,..." or "-".
+func (d *dfenDecoder) units(section dfenSpan) error {
+	if section.text == "-" {
+		return nil
+	}
+	for _, entry := range splitDFEN(section.text, section.offset, ",") {
+		u, err := parseUnitEntry(entry.text)
+		if err == nil && d.mode != DFENGrammar {
+			err = d.checkUnit(u)
+		}
+		if err != nil {
+			if err := d.problem(DFENSectionUnits, entry, err.Error()); err != nil {
+				return err
+			}
+			continue
+		}
+		d.gs.Units = append(d.gs.Units, u)
+	}
+	return nil
+}
+
+// supplyCenters parses "Abud,Atri,Avie,...", or nothing if no province is
+// owned.
+func (d *dfenDecoder) supplyCenters(section dfenSpan) error {
+	if section.text == "" {
+		return nil
+	}
+	for _, entry := range splitDFEN(section.text, section.offset, ",") {
+		power, prov, err := parseCenterEntry(entry.text)
+		if err == nil && d.mode != DFENGrammar {
+			err = d.checkCenter(prov)
+		}
+		if err != nil {
+			if err := d.problem(DFENSectionCenters, entry, err.Error()); err != nil {
+				return err
+			}
+			continue
+		}
+		d.gs.SupplyCenters[prov] = power
+	}
+	return nil
+}
+
+// dislodged parses "Aaser<bul,Rfsev<bla" or "-".
+func (d *dfenDecoder) dislodged(section dfenSpan) error {
+	if section.text == "-" {
+		return nil
+	}
+	for _, entry := range splitDFEN(section.text, section.offset, ",") {
+		du, err := parseDislodgedEntry(entry.text)
+		if err == nil && d.mode != DFENGrammar {
+			err = d.checkDislodged(du)
+		}
+		if err != nil {
+			if err := d.problem(DFENSectionDislodged, entry, err.Error()); err != nil {
+				return err
+			}
+			continue
+		}
+		d.gs.Dislodged = append(d.gs.Dislodged, du)
+	}
+	return nil
+}
+
+// DecodeDFENPhase parses a DFEN phase field such as "1901sm".
+func DecodeDFENPhase(s string) (int, Season, PhaseType, error) {
+	var gs GameState
+	if err := decodePhaseInfo(s, &gs); err != nil {
+		return 0, "", "", &DFENError{Section: DFENSectionPhase, Token: s, Reason: err.Error()}
+	}
+	return gs.Year, gs.Season, gs.Phase, nil
+}
+
 // decodePhaseInfo parses "1901sm" into year, season, phase.
 func decodePhaseInfo(s string, gs *GameState) error {
 	if len(s) < 3 {
-		return fmt.Errorf("dfen: phase info too short: %q", s)
+		return fmt.Errorf("too short")
 	}
 
 	phaseChar := s[len(s)-1]
@@ -239,17 +387,17 @@ func decodePhaseInfo(s string, gs *GameState) error {
 
 	year, err := strconv.Atoi(yearStr)
 	if err != nil {
-		return fmt.Errorf("dfen: invalid year %q: %w", yearStr, err)
+		return fmt.Errorf("invalid year %q", yearStr)
 	}
 
 	season, ok := charToSeason[seasonChar]
 	if !ok {
-		return fmt.Errorf("dfen: invalid season %q", string(seasonChar))
+		return fmt.Errorf("invalid season %q", string(seasonChar))
 	}
 
 	phase, ok := charToPhase[phaseChar]
 	if !ok {
-		return fmt.Errorf("dfen: invalid phase %q", string(phaseChar))
+		return fmt.Errorf("invalid phase %q", string(phaseChar))
 	}
 
 	gs.Year = year
@@ -258,22 +406,6 @@ func decodePhaseInfo(s string, gs *GameState) error {
 	return nil
 }
 
-// decodeUnits parses "Aavie,Aabud,Aftri,..." or "-".
-func decodeUnits(s string, gs *GameState) error {
-	if s == "-" {
-		return nil
-	}
-
-	for entry := range strings.SplitSeq(s, ",") {
-		u, err := parseUnitEntry(entry)
-		if err != nil {
-			return fmt.Errorf("dfen: unit %q: %w", entry, err)
-		}
-		gs.Units = append(gs.Units, u)
-	}
-	return nil
-}
-
 // parseUnitEntry parses a single unit entry like "Aavie" or "Rfstp.sc".
 func parseUnitEntry(s string) (Unit, error) {
 	if len(s) < 5 {
@@ -281,7 +413,7 @@ func parseUnitEntry(s string) (Unit, error) {
 	}
 
 	power, ok := charToPower[s[0]]
-	if !ok || power == Neutral {
+	if !ok {
 		return Unit{}, fmt.Errorf("invalid power char %q", string(s[0]))
 	}
 
@@ -312,14 +444,14 @@ func parseUnitEntry(s string) (Unit, error) {
 // parseDFENLocation parses a DFEN location like "vie" or "stp.sc".
 func parseDFENLocation(s string) (string, Coast, error) {
 	parts := strings.SplitN(s, ".", 2)
-	province := parts[0]
+	province := CanonicalProvince(parts[0])
 	if len(province) != 3 {
 		return "", NoCoast, fmt.Errorf("invalid province id %q (must be 3 lowercase letters)", province)
 	}
 
 	coast := NoCoast
 	if len(parts) == 2 {
-		c := Coast(parts[1])
+		c := CanonicalCoast(parts[1])
 		switch c {
 		case NorthCoast, SouthCoast, EastCoast:
 			coast = c
@@ -331,40 +463,20 @@ func parseDFENLocation(s string) (string, Coast, error) {
 	return province, coast, nil
 }
 
-// decodeSupplyCenters parses "Abud,Atri,Avie,...".
-func decodeSupplyCenters(s string, gs *GameState) error {
-	gs.SupplyCenters = make(map[string]Power)
-	for entry := range strings.SplitSeq(s, ",") {
-		if len(entry) < 4 {
-			return fmt.Errorf("dfen: sc entry too short: %q", entry)
-		}
-		power, ok := charToPower[entry[0]]
-		if !ok {
-			return fmt.Errorf("dfen: invalid power in sc %q", entry)
-		}
-		prov := entry[1:]
-		if len(prov) != 3 {
-			return fmt.Errorf("dfen: invalid province in sc %q", entry)
-		}
-		gs.SupplyCenters[prov] = power
+// parseCenterEntry parses a supply center entry like "Abud".
+func parseCenterEntry(s string) (Power, string, error) {
+	if len(s) < 4 {
+		return "", "", fmt.Errorf("too short")
 	}
-	return nil
-}
-
-// decodeDislodged parses "Aaser<bul,Rfsev<bla" or "-".
-func decodeDislodged(s string, gs *GameState) error {
-	if s == "-" {
-		return nil
+	power, ok := charToPower[s[0]]
+	if !ok {
+		return "", "", fmt.Errorf("invalid power char %q", string(s[0]))
 	}
-
-	for entry := range strings.SplitSeq(s, ",") {
-		d, err := parseDislodgedEntry(entry)
-		if err != nil {
-			return fmt.Errorf("dfen: dislodged %q: %w", entry, err)
-		}
-		gs.Dislodged = append(gs.Dislodged, d)
+	prov := s[1:]
+	if len(prov) != 3 {
+		return "", "", fmt.Errorf("invalid province id %q (must be 3 lowercase letters)", prov)
 	}
-	return nil
+	return power, prov, nil
 }
 
 // parseDislodgedEntry parses "Aaser<bul" or "Rfstp.sc<rum".
@@ -0,0 +1,138 @@
+package diplomacy
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// DFEN sections, as named in a DFENError.
+const (
+	DFENSectionLayout    = "layout"
+	DFENSectionPhase     = "phase"
+	DFENSectionUnits     = "units"
+	DFENSectionCenters   = "supply centers"
+	DFENSectionDislodged = "dislodged"
+)
+
+// dfenGrammar describes what each DFEN section should look like.
+var dfenGrammar = map[string]string{
+	DFENSectionLayout:    "<phase>/<units>/<supply centers>/<dislodged>",
+	DFENSectionPhase:     "<year><season s|u|f|w><phase m|r|b>, e.g. 1901sm",
+	DFENSectionUnits:     "- or comma-separated <power><a|f><province>[.<coast>], e.g. Aavie,Rfstp.sc",
+	DFENSectionCenters:   "empty or comma-separated <power><province>, e.g. Abud,Nbel",
+	DFENSectionDislodged: "- or comma-separated <power><a|f><province>[.<coast>]<<attacker province>, e.g. Aaser<bul",
+}
+
+// DFENMode selects how DecodeDFENMode treats a malformed or impossible
+// entry.
+type DFENMode int
+
+const (
+	// DFENGrammar checks the grammar only and fails on the first malformed
+	// token. DecodeDFEN decodes this way.
+	DFENGrammar DFENMode = iota
+	// DFENStrict also checks the position against the standard map and
+	// fails on the first problem.
+	DFENStrict
+	// DFENLenient checks as DFENStrict does but skips bad entries, reporting
+	// them all alongside the position.
+	DFENLenient
+)
+
+// DFENError describes a malformed or impossible token in a DFEN string.
+type DFENError struct {
+	Section string // one of the DFENSection constants
+	Offset  int    // byte offset of Token in the DFEN string
+	Token   string
+	Reason  string
+}
+
+// Expected returns the grammar of the section the error is in.
+func (e *DFENError) Expected() string {
+	return dfenGrammar[e.Section]
+}
+
+func (e *DFENError) Error() string {
+	return fmt.Sprintf("dfen: %s %q at offset %d: %s (expected %s)", e.Section, e.Token, e.Offset, e.Reason, e.Expected())
+}
+
+// DFENErrors lists the entries lenient decoding skipped.
+type DFENErrors []*DFENError
+
+func (es DFENErrors) Error() string {
+	msgs := make([]string, len(es))
+	for i, e := range es {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// checkUnit checks that u can stand where it is on the standard map and
+// that no other unit is there.
+func (d *dfenDecoder) checkUnit(u Unit) error {
+	prov, err := checkUnitLocation(u)
+	if err != nil {
+		return err
+	}
+	if d.occupied == nil {
+		d.occupied = make(map[string]bool)
+	}
+	if d.occupied[prov.ID] {
+		return fmt.Errorf("second unit in %s", prov.ID)
+	}
+	d.occupied[prov.ID] = true
+	return nil
+}
+
+// checkCenter checks that id is a supply center on the standard map listed
+// only once.
+func (d *dfenDecoder) checkCenter(id string) error {
+	prov, ok := StandardMap().Provinces[id]
+	if !ok {
+		return fmt.Errorf("unknown province %q", id)
+	}
+	if !prov.IsSupplyCenter {
+		return fmt.Errorf("%s is not a supply center", id)
+	}
+	if d.centers == nil {
+		d.centers = make(map[string]bool)
+	}
+	if d.centers[id] {
+		return fmt.Errorf("%s listed twice", id)
+	}
+	d.centers[id] = true
+	return nil
+}
+
+// checkDislodged checks that du's unit can stand where it is and that it
+// was attacked from a province on the standard map.
+func (d *dfenDecoder) checkDislodged(du DislodgedUnit) error {
+	if _, err := checkUnitLocation(du.Unit); err != nil {
+		return err
+	}
+	if _, ok := StandardMap().Provinces[du.AttackerFrom]; !ok {
+		return fmt.Errorf("unknown attacker province %q", du.AttackerFrom)
+	}
+	return nil
+}
+
+// checkUnitLocation returns the standard map province u is in, checking
+// that its type of unit can be there and that any coast exists.
+func checkUnitLocation(u Unit) (*Province, error) {
+	prov, ok := StandardMap().Provinces[u.Province]
+	if !ok {
+		return nil, fmt.Errorf("unknown province %q", u.Province)
+	}
+	switch {
+	case u.Type == Army && prov.Type == Sea:
+		return nil, fmt.Errorf("army in sea province %s", prov.ID)
+	case u.Type == Fleet && prov.Type == Land:
+		return nil, fmt.Errorf("fleet in inland province %s", prov.ID)
+	case u.Coast != NoCoast && u.Type == Army:
+		return nil, fmt.Errorf("army given a coast in %s", prov.ID)
+	case u.Coast != NoCoast && !slices.Contains(prov.Coasts, u.Coast):
+		return nil, fmt.Errorf("%s has no %s coast", prov.ID, u.Coast)
+	}
+	return prov, nil
+}
@@ -1,6 +1,7 @@
 package diplomacy
 
 import (
+	"errors"
 	"strings"
 	"testing"
 )
@@ -611,3 +612,125 @@ func FuzzDFEN_RoundTrip(f *testing.F) {
 		}
 	})
 }
+
+func TestDFENPhase_RoundTrip(t *testing.T) {
+	gs := NewInitialState()
+	gs.Year, gs.Season, gs.Phase = 1903, Fall, PhaseRetreat
+	key := EncodeDFENPhase(gs)
+	if key != "1903fr" {
+		t.Fatalf("expected 1903fr, got %q", key)
+	}
+	year, season, phase, err := DecodeDFENPhase(key)
+	if err != nil || year != 1903 || season != Fall || phase != PhaseRetreat {
+		t.Errorf("decode %q: got %d %s %s, %v", key, year, season, phase, err)
+	}
+	if _, _, _, err := DecodeDFENPhase("1901xm"); err == nil {
+		t.Error("expected error for invalid season")
+	}
+}
+
+func TestDFEN_RoundTrip_Garrisons(t *testing.T) {
+	gs := NewInitialState()
+	gs.AddGarrisons()
+
+	encoded := EncodeDFEN(gs)
+	units := strings.Split(encoded, "/")[1]
+	if !strings.HasSuffix(units, ",Natun") {
+		t.Errorf("expected garrisons encoded last, got: %s", units)
+	}
+	decoded, err := DecodeDFEN(encoded)
+	if err != nil {
+		t.Fatalf("DecodeDFEN: %v", err)
+	}
+	if got := len(decoded.Garrisons()); got != 12 {
+		t.Errorf("expected 12 garrisons after round trip, got %d", got)
+	}
+	if re := EncodeDFEN(decoded); re != encoded {
+		t.Errorf("round trip mismatch:\n  got  %s\n  want %s", re, encoded)
+	}
+}
+
+func TestDecodeDFEN_ErrorDetails(t *testing.T) {
+	_, err := DecodeDFEN("1901sm/Aavie,Axbud/Abud/-")
+	var de *DFENError
+	if !errors.As(err, &de) {
+		t.Fatalf("expected *DFENError, got %v", err)
+	}
+	if de.Section != DFENSectionUnits || de.Token != "Axbud" || de.Offset != 13 {
+		t.Errorf("got section %q token %q offset %d, want units \"Axbud\" 13", de.Section, de.Token, de.Offset)
+	}
+	if de.Expected() == "" || !strings.Contains(err.Error(), de.Expected()) {
+		t.Errorf("error %q does not give the expected grammar", err)
+	}
+
+	_, err = DecodeDFEN("1901xm/-//-")
+	if !errors.As(err, &de) || de.Section != DFENSectionPhase || de.Offset != 0 {
+		t.Errorf("expected phase error at offset 0, got %v", err)
+	}
+}
+
+func TestDecodeDFEN_EmptyCenters(t *testing.T) {
+	gs, err := DecodeDFEN("1901sm/-//-")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(gs.SupplyCenters) != 0 {
+		t.Errorf("expected no supply centers, got %v", gs.SupplyCenters)
+	}
+}
+
+func TestDecodeDFENMode_Strict(t *testing.T) {
+	tests := []struct {
+		name string
+		dfen string
+	}{
+		{"unknown province", "1901sm/Aaxyz/Avie/-"},
+		{"army at sea", "1901sm/Aanth/Avie/-"},
+		{"fleet inland", "1901sm/Afvie/Avie/-"},
+		{"army with coast", "1901sm/Rastp.nc/Avie/-"},
+		{"missing coast", "1901sm/Rfmos.nc/Avie/-"},
+		{"wrong coast", "1901sm/Rfstp.ec/Avie/-"},
+		{"two units in a province", "1901sm/Aavie,Ravie/Avie/-"},
+		{"center not a supply center", "1901sm/-/Aboh/-"},
+		{"center listed twice", "1901sm/-/Avie,Rvie/-"},
+		{"unknown attacker", "1901fr/Aavie/Avie/Aabud<xyz"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeDFEN(tt.dfen); err != nil {
+				t.Fatalf("grammar-only decode failed: %v", err)
+			}
+			_, err := DecodeDFENMode(tt.dfen, DFENStrict)
+			var de *DFENError
+			if !errors.As(err, &de) {
+				t.Errorf("expected *DFENError, got %v", err)
+			}
+		})
+	}
+
+	initial := EncodeDFEN(NewInitialState())
+	if _, err := DecodeDFENMode(initial, DFENStrict); err != nil {
+		t.Errorf("initial position rejected: %v", err)
+	}
+}
+
+func TestDecodeDFENMode_Lenient(t *testing.T) {
+	gs, err := DecodeDFENMode("1901sm/Aavie,Axbud,Aanth,Aftri/Avie,Aboh/-", DFENLenient)
+	var errs DFENErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected DFENErrors, got %v", err)
+	}
+	if len(errs) != 3 {
+		t.Fatalf("expected 3 skipped entries, got %d: %v", len(errs), errs)
+	}
+	if errs[0].Token != "Axbud" || errs[1].Token != "Aanth" || errs[2].Token != "Aboh" {
+		t.Errorf("unexpected skipped entries: %v", errs)
+	}
+	if gs == nil || len(gs.Units) != 2 || len(gs.SupplyCenters) != 1 {
+		t.Errorf("expected the good entries to be kept, got %+v", gs)
+	}
+
+	if _, err := DecodeDFENMode("1901sm/-/Avie", DFENLenient); err == nil {
+		t.Error("expected a bad layout to fail in lenient mode")
+	}
+}
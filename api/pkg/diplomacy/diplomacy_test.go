@@ -1,6 +1,9 @@
 package diplomacy
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 // Helper to create a game state with specific units (no SCs for resolution tests).
 func stateWith(units ...Unit) *GameState {
@@ -237,3 +240,308 @@ func TestApplyResolution_ThreeWayRotation(t *testing.T) {
 		}
 	}
 }
+
+func TestHasOrderChoice_Build(t *testing.T) {
+	m := StandardMap()
+	gs := NewInitialState()
+	gs.Season, gs.Phase = Fall, PhaseBuild
+
+	if HasOrderChoice(gs, France, m) {
+		t.Error("France has no adjustment and should have no choice")
+	}
+
+	// One build owed but every home center is occupied.
+	gs.SupplyCenters["spa"] = France
+	if HasOrderChoice(gs, France, m) {
+		t.Error("France cannot build with all home centers occupied")
+	}
+
+	// Free up Paris.
+	gs.UnitAt("par").Province = "bur"
+	if !HasOrderChoice(gs, France, m) {
+		t.Error("France should be able to build in Paris")
+	}
+}
+
+func TestHasOrderChoice_Disband(t *testing.T) {
+	m := StandardMap()
+	gs := stateWith(
+		Unit{Army, Italy, "ven", NoCoast},
+		Unit{Army, Italy, "rom", NoCoast},
+	)
+	gs.Season, gs.Phase = Fall, PhaseBuild
+	gs.SupplyCenters["ven"] = Italy
+
+	if !HasOrderChoice(gs, Italy, m) {
+		t.Error("Italy should choose which of two units to disband")
+	}
+
+	delete(gs.SupplyCenters, "ven")
+	if HasOrderChoice(gs, Italy, m) {
+		t.Error("Italy must disband every unit and has no choice")
+	}
+}
+
+func TestAdjustments(t *testing.T) {
+	gs := NewInitialState()
+	gs.Season, gs.Phase = Fall, PhaseBuild
+	gs.SupplyCenters["spa"] = France
+	gs.SupplyCenters["por"] = France
+	gs.UnitAt("par").Province = "bur"
+	gs.SupplyCenters["tri"] = Italy // Austria loses Trieste to Italy
+
+	adj := Adjustments(gs)
+	if len(adj) != 7 {
+		t.Fatalf("expected all 7 powers, got %d", len(adj))
+	}
+	// Two builds owed, but only Paris is open.
+	if fr := adj[France]; fr.SupplyCenters != 5 || fr.Units != 3 || fr.Builds != 1 || strings.Join(fr.OpenHomeCenters, ",") != "par" {
+		t.Errorf("france = %+v, want 5 SCs, 3 units, 1 build in par", fr)
+	}
+	if au := adj[Austria]; au.Disbands != 1 || au.Builds != 0 {
+		t.Errorf("austria = %+v, want 1 disband", au)
+	}
+	if it := adj[Italy]; it.Builds != 0 || it.OpenHomeCenters != nil {
+		t.Errorf("italy = %+v, want no builds: all home centers occupied", it)
+	}
+}
+
+func TestHasOrderChoice_Retreat(t *testing.T) {
+	m := StandardMap()
+	gs := stateWith(
+		Unit{Army, Germany, "mun", NoCoast},
+	)
+	gs.Phase = PhaseRetreat
+	gs.Dislodged = []DislodgedUnit{{
+		Unit:          Unit{Army, Austria, "tyr", NoCoast},
+		DislodgedFrom: "tyr",
+		AttackerFrom:  "mun",
+	}}
+
+	if !HasOrderChoice(gs, Austria, m) {
+		t.Error("Austria should have somewhere to retreat from Tyrolia")
+	}
+	if HasOrderChoice(gs, Germany, m) {
+		t.Error("Germany has no dislodged units and should have no choice")
+	}
+}
+
+// --- Retreats to split-coast provinces ---
+
+func TestRetreatSplitCoasts(t *testing.T) {
+	m := StandardMap()
+	tests := []struct {
+		name    string
+		unit    Unit
+		from    string // attacker's province
+		order   RetreatOrder
+		infer   Coast  // target coast after inference
+		wantErr string // substring of the validation error, "" if valid
+	}{
+		{"ambiguous spa", Unit{Fleet, France, "mao", NoCoast}, "bre", RetreatOrder{Target: "spa"}, NoCoast, "must specify coast for spa (nc or sc)"},
+		{"spa north", Unit{Fleet, France, "mao", NoCoast}, "bre", RetreatOrder{Target: "spa", TargetCoast: NorthCoast}, NorthCoast, ""},
+		{"spa south", Unit{Fleet, France, "mao", NoCoast}, "bre", RetreatOrder{Target: "spa", TargetCoast: SouthCoast}, SouthCoast, ""},
+		{"spa inferred", Unit{Fleet, France, "gol", NoCoast}, "tys", RetreatOrder{Target: "spa"}, SouthCoast, ""},
+		{"spa unreachable coast", Unit{Fleet, France, "gol", NoCoast}, "tys", RetreatOrder{Target: "spa", TargetCoast: NorthCoast}, NorthCoast, "fleet cannot reach spa/nc from gol"},
+		{"ambiguous bul", Unit{Fleet, Turkey, "con", NoCoast}, "smy", RetreatOrder{Target: "bul"}, NoCoast, "must specify coast for bul (ec or sc)"},
+		{"bul east", Unit{Fleet, Turkey, "bla", NoCoast}, "sev", RetreatOrder{Target: "bul"}, EastCoast, ""},
+		{"stp inferred", Unit{Fleet, Russia, "bar", NoCoast}, "nwg", RetreatOrder{Target: "stp"}, NorthCoast, ""},
+		{"stp wrong coast", Unit{Fleet, Russia, "bot", NoCoast}, "swe", RetreatOrder{Target: "stp", TargetCoast: NorthCoast}, NorthCoast, "fleet cannot reach stp/nc from bot"},
+		{"from south coast", Unit{Fleet, Russia, "stp", SouthCoast}, "fin", RetreatOrder{Target: "bar"}, NoCoast, "target not adjacent"},
+		{"wrong source coast", Unit{Fleet, Russia, "stp", SouthCoast}, "fin", RetreatOrder{Coast: NorthCoast, Target: "bot"}, NoCoast, "dislodged unit is not on stp/nc"},
+		{"coast on plain province", Unit{Fleet, France, "mao", NoCoast}, "bre", RetreatOrder{Target: "por", TargetCoast: NorthCoast}, NorthCoast, "por has no coasts"},
+		{"army to a coast", Unit{Army, France, "gas", NoCoast}, "bur", RetreatOrder{Target: "spa", TargetCoast: NorthCoast}, NorthCoast, "army cannot retreat to a coast"},
+		{"army to spa", Unit{Army, France, "gas", NoCoast}, "bur", RetreatOrder{Target: "spa"}, NoCoast, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := stateWith()
+			gs.Phase = PhaseRetreat
+			gs.Dislodged = []DislodgedUnit{{Unit: tt.unit, DislodgedFrom: tt.unit.Province, AttackerFrom: tt.from}}
+			o := tt.order
+			o.UnitType, o.Power, o.Location, o.Type = tt.unit.Type, tt.unit.Power, tt.unit.Province, RetreatMove
+			o = InferRetreatCoasts(o, gs, m)
+			if o.TargetCoast != tt.infer {
+				t.Errorf("inferred target coast %q, want %q", o.TargetCoast, tt.infer)
+			}
+			err := ValidateRetreatOrder(o, gs, m)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("got error %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApplyRetreatInfersCoast(t *testing.T) {
+	m := StandardMap()
+	gs := stateWith()
+	gs.Phase = PhaseRetreat
+	gs.Dislodged = []DislodgedUnit{{Unit: Unit{Fleet, Russia, "stp", SouthCoast}, DislodgedFrom: "stp", AttackerFrom: "lvn"}}
+
+	// The order leaves out both the unit's coast and the target coast.
+	orders := []RetreatOrder{{UnitType: Fleet, Power: Russia, Location: "stp", Type: RetreatMove, Target: "bot"}}
+	ApplyRetreats(gs, ResolveRetreats(orders, gs, m), m)
+	if u := gs.UnitAt("bot"); u == nil || u.Coast != NoCoast {
+		t.Fatalf("expected the fleet in bot, got %+v", gs.Units)
+	}
+
+	gs.Units = nil
+	gs.Dislodged = []DislodgedUnit{{Unit: Unit{Fleet, Russia, "bot", NoCoast}, DislodgedFrom: "bot", AttackerFrom: "swe"}}
+	orders = []RetreatOrder{{UnitType: Fleet, Power: Russia, Location: "bot", Type: RetreatMove, Target: "stp"}}
+	ApplyRetreats(gs, ResolveRetreats(orders, gs, m), m)
+	if u := gs.UnitAt("stp"); u == nil || u.Coast != SouthCoast {
+		t.Errorf("expected the fleet on stp/sc, got %+v", gs.Units)
+	}
+}
+
+// --- Retreat credits ---
+
+func TestRetreatCredits(t *testing.T) {
+	m := StandardMap()
+	gs := NewInitialState()
+	gs.RetreatCredits = true
+	gs.Season, gs.Phase = Fall, PhaseRetreat
+	gs.UnitAt("mun").Province = "ruh"
+	gs.Dislodged = []DislodgedUnit{
+		{Unit: Unit{Army, Germany, "bur", NoCoast}, DislodgedFrom: "bur", AttackerFrom: "par"},
+		{Unit: Unit{Army, Austria, "tyr", NoCoast}, DislodgedFrom: "tyr", AttackerFrom: "ven"},
+	}
+
+	// Germany disbands by choice; Austria gives no order and is disbanded by default.
+	orders := []RetreatOrder{{UnitType: Army, Power: Germany, Location: "bur", Type: RetreatDisband}}
+	ApplyRetreats(gs, ResolveRetreats(orders, gs, m), m)
+	if gs.BuildCredits[Germany] != 1 || gs.BuildCredits[Austria] != 0 {
+		t.Fatalf("credits = %v, want 1 for Germany only", gs.BuildCredits)
+	}
+
+	// Germany's centers match its units, but the credit still earns a build.
+	AdvanceState(gs, false)
+	if gs.Phase != PhaseBuild || !NeedsBuildPhase(gs) {
+		t.Fatalf("expected a build phase, got %s", gs.Phase)
+	}
+	if de := Adjustments(gs)[Germany]; de.Credits != 1 || de.Builds != 1 {
+		t.Errorf("germany = %+v, want 1 credit, 1 build", de)
+	}
+	build := BuildOrder{Power: Germany, Type: BuildUnit, UnitType: Army, Location: "mun"}
+	if err := ValidateBuildOrder(build, gs, m); err != nil {
+		t.Errorf("build in Munich should be allowed: %v", err)
+	}
+	if err := ValidateBuildOrder(BuildOrder{Power: Austria, Type: BuildUnit, UnitType: Army, Location: "vie"}, gs, m); err == nil {
+		t.Error("Austria has no credit and should not build")
+	}
+
+	ApplyBuildOrders(gs, ResolveBuildOrders([]BuildOrder{build}, gs, m))
+	AdvanceState(gs, false)
+	if gs.UnitAt("mun") == nil || gs.BuildCredits != nil {
+		t.Errorf("expected the army rebuilt in Munich and credits cleared, got credits %v", gs.BuildCredits)
+	}
+}
+
+func TestRetreatCreditsOff(t *testing.T) {
+	m := StandardMap()
+	gs := NewInitialState()
+	gs.Phase = PhaseRetreat
+	gs.Dislodged = []DislodgedUnit{
+		{Unit: Unit{Army, Germany, "bur", NoCoast}, DislodgedFrom: "bur", AttackerFrom: "par"},
+	}
+	orders := []RetreatOrder{{UnitType: Army, Power: Germany, Location: "bur", Type: RetreatDisband}}
+	ApplyRetreats(gs, ResolveRetreats(orders, gs, m), m)
+	if gs.BuildCredits != nil {
+		t.Errorf("credits = %v, want none without the house rule", gs.BuildCredits)
+	}
+}
+
+// --- Neutral garrisons ---
+
+func TestGarrisonHoldsAgainstUnsupportedAttack(t *testing.T) {
+	m := StandardMap()
+	gs := stateWith(
+		Unit{Army, France, "bur", NoCoast},
+		Unit{Army, Neutral, "bel", NoCoast},
+	)
+	// No order is given for the garrison; it holds anyway.
+	orders := []Order{{UnitType: Army, Power: France, Location: "bur", Type: OrderMove, Target: "bel"}}
+	results, dislodged := ResolveOrders(orders, gs, m)
+	if r := resultFor(results, "bur"); r != ResultBounced {
+		t.Errorf("expected unsupported attack on garrison to bounce, got %v", r)
+	}
+	if len(dislodged) != 0 {
+		t.Errorf("expected no dislodged units, got %v", dislodged)
+	}
+}
+
+func TestGarrisonDestroyedWhenDislodged(t *testing.T) {
+	m := StandardMap()
+	orders := []Order{
+		{UnitType: Army, Power: France, Location: "bur", Type: OrderMove, Target: "bel"},
+		{UnitType: Army, Power: France, Location: "pic", Type: OrderSupport, AuxLoc: "bur", AuxTarget: "bel", AuxUnitType: Army},
+	}
+	newState := func() *GameState {
+		return stateWith(
+			Unit{Army, France, "bur", NoCoast},
+			Unit{Army, France, "pic", NoCoast},
+			Unit{Army, Neutral, "bel", NoCoast},
+		)
+	}
+
+	gs := newState()
+	results, dislodged := ResolveOrders(orders, gs, m)
+	if r := resultFor(results, "bel"); r != ResultDislodged {
+		t.Errorf("expected garrison to be dislodged, got %v", r)
+	}
+	if len(dislodged) != 0 {
+		t.Fatalf("expected the garrison not to retreat, got %v", dislodged)
+	}
+	ApplyResolution(gs, m, results, dislodged)
+	if u := gs.UnitAt("bel"); u == nil || u.Power != France || len(gs.Garrisons()) != 0 {
+		t.Errorf("expected France alone in Belgium, got %v", gs.Units)
+	}
+
+	// The reusable resolver behaves the same and leaves the orders alone.
+	gs = newState()
+	rv := NewResolver(4)
+	rv.Resolve(orders, gs, m)
+	if rv.HasDislodged() {
+		t.Error("expected the reusable resolver not to report a retreat")
+	}
+	rv.Apply(gs, m)
+	if u := gs.UnitAt("bel"); u == nil || u.Power != France || len(gs.Units) != 2 {
+		t.Errorf("expected France alone in Belgium, got %v", gs.Units)
+	}
+	if len(orders) != 2 {
+		t.Errorf("expected the caller's orders untouched, got %d", len(orders))
+	}
+}
+
+func TestGarrisonCanOnlyHold(t *testing.T) {
+	m := StandardMap()
+	gs := stateWith(Unit{Army, Neutral, "bel", NoCoast})
+	hold := Order{UnitType: Army, Power: Neutral, Location: "bel", Type: OrderHold}
+	if err := ValidateOrder(hold, gs, m); err != nil {
+		t.Errorf("expected garrison hold to be valid, got %v", err)
+	}
+	move := Order{UnitType: Army, Power: Neutral, Location: "bel", Type: OrderMove, Target: "bur"}
+	if err := ValidateOrder(move, gs, m); err == nil {
+		t.Error("expected garrison move to be rejected")
+	}
+}
+
+func TestAddGarrisons(t *testing.T) {
+	gs := NewInitialState()
+	gs.AddGarrisons()
+	garrisons := gs.Garrisons()
+	if len(garrisons) != 12 {
+		t.Fatalf("expected a garrison on each of the 12 neutral centers, got %d", len(garrisons))
+	}
+	for _, g := range garrisons {
+		if gs.SupplyCenters[g.Province] != Neutral || g.Type != Army {
+			t.Errorf("unexpected garrison %v", g)
+		}
+	}
+	if NeedsBuildPhase(gs) {
+		t.Error("expected garrisons not to need adjustments")
+	}
+}
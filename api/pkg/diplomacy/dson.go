@@ -308,16 +308,17 @@ func parseDSONUnitChar(s string) (UnitType, error) {
 }
 
 // parseDSONLocation parses "vie" or "stp/nc" into province and coast.
+// Province and coast aliases ("lyo", "stp/ncs") are canonicalized.
 func parseDSONLocation(s string) (string, Coast, error) {
 	parts := strings.SplitN(s, "/", 2)
-	province := parts[0]
+	province := CanonicalProvince(parts[0])
 	if len(province) != 3 {
 		return "", NoCoast, fmt.Errorf("invalid province %q (must be 3 lowercase letters)", province)
 	}
 
 	coast := NoCoast
 	if len(parts) == 2 {
-		c := Coast(parts[1])
+		c := CanonicalCoast(parts[1])
 		switch c {
 		case NorthCoast, SouthCoast, EastCoast:
 			coast = c
@@ -349,6 +350,7 @@ func OrderToDSON(o Order) DSONOrder {
 		} else {
 			d.Type = DSONSupportMove
 			d.AuxTarget = o.AuxTarget
+			d.AuxTargetCoast = o.AuxTargetCoast
 		}
 		d.AuxUnitType = o.AuxUnitType
 		d.AuxLocation = o.AuxLoc
@@ -420,6 +422,7 @@ func DSONToOrder(d DSONOrder, power Power) Order {
 		o.AuxUnitType = d.AuxUnitType
 		o.AuxLoc = d.AuxLocation
 		o.AuxTarget = d.AuxTarget
+		o.AuxTargetCoast = d.AuxTargetCoast
 	case DSONConvoy:
 		o.Type = OrderConvoy
 		o.AuxLoc = d.AuxLocation
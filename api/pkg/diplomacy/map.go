@@ -1,8 +1,17 @@
 package diplomacy
 
+import (
+	"slices"
+	"sort"
+)
+
 // ProvinceCount is the number of provinces on the standard Diplomacy map.
 const ProvinceCount = 75
 
+// MaxProvinces is the most provinces a map may have; the resolver indexes
+// provinces into fixed-size tables.
+const MaxProvinces = 128
+
 // ProvinceType classifies a province as land, sea, or coastal.
 type ProvinceType int
 
@@ -51,16 +60,21 @@ type adjCacheKey struct {
 	isFleet bool
 }
 
-// DiplomacyMap holds the full province and adjacency graph.
+// DiplomacyMap holds the full province and adjacency graph. A frozen map,
+// such as StandardMap, is shared by every game and goroutine: read its
+// fields but never change them, and use Province or AdjacenciesOf for
+// copies that may be changed.
 type DiplomacyMap struct {
 	Provinces   map[string]*Province
 	Adjacencies map[string][]Adjacency // keyed by from province ID
 	provIndex   map[string]int
-	provNames   [ProvinceCount]string
+	provNames   []string
+	homes       map[Power][]string       // sorted home centers of each power
 	adjCache    map[adjCacheKey][]string // cached ProvincesAdjacentTo results
+	frozen      *mapSnapshot             // set once the map is shared
 }
 
-// ProvinceIndex returns the dense index (0..ProvinceCount-1) for a province ID.
+// ProvinceIndex returns the dense index (0..len(Provinces)-1) for a province ID.
 // Returns -1 if the province is not found.
 func (m *DiplomacyMap) ProvinceIndex(id string) int {
 	idx, ok := m.provIndex[id]
@@ -117,8 +131,8 @@ func (m *DiplomacyMap) FleetCoastsTo(src string, srcCoast Coast, dst string) []C
 
 // ProvincesAdjacentTo returns all province IDs adjacent to the given province
 // accessible by the given unit type. The adjCache must be pre-populated via
-// precomputeAdjCache before concurrent use. The returned slice must not be
-// modified by the caller.
+// precomputeAdjCache before concurrent use. The returned slice is shared:
+// its elements must not be modified, though appending to it makes a copy.
 func (m *DiplomacyMap) ProvincesAdjacentTo(provID string, coast Coast, isFleet bool) []string {
 	key := adjCacheKey{provID, coast, isFleet}
 	if cached, ok := m.adjCache[key]; ok {
@@ -169,8 +183,66 @@ func (m *DiplomacyMap) precomputeAdjCache() {
 	}
 }
 
+// HomeCenters returns the sorted home supply center IDs of a power.
+func (m *DiplomacyMap) HomeCenters(power Power) []string {
+	return m.homes[power]
+}
+
+// finish builds the dense province index, home centers and adjacency cache,
+// then freezes the map. Province indices follow sorted province IDs, so they
+// are deterministic.
+func (m *DiplomacyMap) finish() {
+	keys := make([]string, 0, len(m.Provinces))
+	for id := range m.Provinces {
+		keys = append(keys, id)
+	}
+	sort.Strings(keys)
+	m.provIndex = make(map[string]int, len(keys))
+	m.provNames = keys
+	m.homes = make(map[Power][]string)
+	for i, id := range keys {
+		m.provIndex[id] = i
+		if p := m.Provinces[id]; p.HomePower != Neutral && p.IsSupplyCenter {
+			m.homes[p.HomePower] = append(m.homes[p.HomePower], id)
+		}
+	}
+	for p, ids := range m.homes {
+		m.homes[p] = slices.Clip(ids)
+	}
+	m.precomputeAdjCache()
+	m.freeze()
+}
+
 // HasCoasts returns true if the province has split coasts (e.g. Spain, St Petersburg, Bulgaria).
 func (m *DiplomacyMap) HasCoasts(provID string) bool {
 	p, ok := m.Provinces[provID]
 	return ok && len(p.Coasts) > 0
 }
+
+// CutFromStandard reports whether m is the standard map or a part of it,
+// such as a duel board: every province is a standard one of the same type
+// and coasts, and every adjacency is a standard one. Supply centers and home
+// powers may differ.
+func (m *DiplomacyMap) CutFromStandard() bool {
+	std := StandardMap()
+	if m == std {
+		return true
+	}
+	for id, p := range m.Provinces {
+		sp, ok := std.Provinces[id]
+		if !ok || sp.Type != p.Type || !slices.Equal(sp.Coasts, p.Coasts) {
+			return false
+		}
+	}
+	for from, adjs := range m.Adjacencies {
+		for _, adj := range adjs {
+			if adj.ArmyOK && !std.Adjacent(from, adj.FromCoast, adj.To, adj.ToCoast, false) {
+				return false
+			}
+			if adj.FleetOK && !std.Adjacent(from, adj.FromCoast, adj.To, adj.ToCoast, true) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -1,9 +1,6 @@
 package diplomacy
 
-import (
-	"sort"
-	"sync"
-)
+import "sync"
 
 var (
 	stdMapOnce sync.Once
@@ -12,11 +9,13 @@ var (
 
 // StandardMap returns the standard 75-province Diplomacy map with all
 // provinces and adjacencies. The map is built once and cached; subsequent
-// calls return the same pointer. Callers must not mutate the returned map.
+// calls return the same pointer. The map is frozen: callers must not mutate
+// it, and with SetMapDebug on each call panics if one has.
 func StandardMap() *DiplomacyMap {
 	stdMapOnce.Do(func() {
 		stdMapInst = buildStandardMap()
 	})
+	stdMapInst.checkFrozen()
 	return stdMapInst
 }
 
@@ -444,19 +443,7 @@ func buildStandardMap() *DiplomacyMap {
 	addArmyAdj("lvn", "stp")
 	addArmyAdj("nwy", "stp")
 
-	// Build dense province index (sorted for deterministic ordering).
-	keys := make([]string, 0, len(m.Provinces))
-	for id := range m.Provinces {
-		keys = append(keys, id)
-	}
-	sort.Strings(keys)
-	m.provIndex = make(map[string]int, len(keys))
-	for i, id := range keys {
-		m.provIndex[id] = i
-		m.provNames[i] = id
-	}
-
-	m.precomputeAdjCache()
+	m.finish()
 
 	return m
 }
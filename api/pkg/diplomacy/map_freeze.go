@@ -0,0 +1,135 @@
+package diplomacy
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"sync/atomic"
+)
+
+// MapDebugEnv turns on map mutation checks when set to a non-empty value
+// other than "0": see SetMapDebug.
+const MapDebugEnv = "DIPLOMACY_MAP_DEBUG"
+
+var mapDebug atomic.Bool
+
+func init() {
+	if v := os.Getenv(MapDebugEnv); v != "" && v != "0" {
+		mapDebug.Store(true)
+	}
+}
+
+// SetMapDebug turns map mutation checks on or off. With checks on, every
+// StandardMap call verifies the shared map still matches what was built and
+// panics naming the first province that changed. Go cannot trap writes to
+// maps and slices, so a mutation is caught at the next check rather than
+// where it happened; run the suspect code with checks on and -race to find
+// it.
+func SetMapDebug(on bool) {
+	mapDebug.Store(on)
+}
+
+// mapSnapshot is a deep copy of a map's data taken when it was frozen.
+type mapSnapshot struct {
+	provinces   map[string]Province
+	adjacencies map[string][]Adjacency
+	adjCache    map[adjCacheKey][]string
+}
+
+// freeze clips every shared slice, so a caller appending to one gets a copy
+// instead of writing into memory other goroutines read, and snapshots the
+// map for Verify. The map must not change after it is frozen.
+func (m *DiplomacyMap) freeze() {
+	snap := &mapSnapshot{
+		provinces:   make(map[string]Province, len(m.Provinces)),
+		adjacencies: make(map[string][]Adjacency, len(m.Adjacencies)),
+		adjCache:    make(map[adjCacheKey][]string, len(m.adjCache)),
+	}
+	for id, p := range m.Provinces {
+		p.Coasts = slices.Clip(p.Coasts)
+		snap.provinces[id] = p.clone()
+	}
+	for id, adj := range m.Adjacencies {
+		m.Adjacencies[id] = slices.Clip(adj)
+		snap.adjacencies[id] = slices.Clone(adj)
+	}
+	for k, adj := range m.adjCache {
+		m.adjCache[k] = slices.Clip(adj)
+		snap.adjCache[k] = slices.Clone(adj)
+	}
+	m.frozen = snap
+}
+
+// Frozen reports whether the map is shared and must not be changed.
+func (m *DiplomacyMap) Frozen() bool {
+	return m.frozen != nil
+}
+
+// Verify returns an error naming the first province whose data differs from
+// when the map was frozen, or nil if none does or the map is not frozen.
+func (m *DiplomacyMap) Verify() error {
+	snap := m.frozen
+	if snap == nil {
+		return nil
+	}
+	if len(m.Provinces) != len(snap.provinces) || len(m.Adjacencies) != len(snap.adjacencies) {
+		return fmt.Errorf("diplomacy: frozen map mutated: provinces or adjacencies added or removed")
+	}
+	ids := make([]string, 0, len(snap.provinces))
+	for id := range snap.provinces {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		p := m.Provinces[id]
+		if p == nil || !p.equal(snap.provinces[id]) {
+			return fmt.Errorf("diplomacy: frozen map mutated: province %s changed", id)
+		}
+		if !slices.Equal(m.Adjacencies[id], snap.adjacencies[id]) {
+			return fmt.Errorf("diplomacy: frozen map mutated: adjacencies of %s changed", id)
+		}
+	}
+	for k, adj := range snap.adjCache {
+		if !slices.Equal(m.adjCache[k], adj) {
+			return fmt.Errorf("diplomacy: frozen map mutated: cached neighbours of %s changed", k.prov)
+		}
+	}
+	return nil
+}
+
+// checkFrozen panics if map debugging is on and the map has been mutated.
+func (m *DiplomacyMap) checkFrozen() {
+	if !mapDebug.Load() {
+		return
+	}
+	if err := m.Verify(); err != nil {
+		panic(err)
+	}
+}
+
+// Province returns a copy of the province with the given ID, safe to change.
+func (m *DiplomacyMap) Province(id string) (Province, bool) {
+	p, ok := m.Provinces[id]
+	if !ok {
+		return Province{}, false
+	}
+	return p.clone(), true
+}
+
+// AdjacenciesOf returns a copy of the adjacencies leaving the given
+// province, safe to change.
+func (m *DiplomacyMap) AdjacenciesOf(id string) []Adjacency {
+	return slices.Clone(m.Adjacencies[id])
+}
+
+func (p *Province) clone() Province {
+	c := *p
+	c.Coasts = slices.Clone(p.Coasts)
+	return c
+}
+
+func (p *Province) equal(q Province) bool {
+	return p.ID == q.ID && p.Name == q.Name && p.Type == q.Type && p.IsSupplyCenter == q.IsSupplyCenter &&
+		p.HomePower == q.HomePower && slices.Equal(p.Coasts, q.Coasts)
+}
@@ -0,0 +1,61 @@
+package diplomacy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStandardMapIsFrozen(t *testing.T) {
+	m := StandardMap()
+	if !m.Frozen() {
+		t.Fatal("the standard map should be frozen")
+	}
+	if err := m.Verify(); err != nil {
+		t.Fatalf("standard map differs from its snapshot: %v", err)
+	}
+
+	// Appending to shared slices must not write into the shared arrays.
+	adj := m.ProvincesAdjacentTo("par", NoCoast, false)
+	_ = append(adj, "xxx")
+	edges := m.Adjacencies["par"]
+	_ = append(edges, Adjacency{From: "par", To: "xxx"})
+	if err := m.Verify(); err != nil {
+		t.Fatalf("appending to a shared slice mutated the map: %v", err)
+	}
+
+	p, _ := m.Province("spa")
+	p.Coasts[0] = WestCoast
+	p.HomePower = France
+	a := m.AdjacenciesOf("spa")
+	a[0].To = "xxx"
+	if err := m.Verify(); err != nil {
+		t.Fatalf("changing copies mutated the map: %v", err)
+	}
+}
+
+func TestMapDebugPanicsOnMutation(t *testing.T) {
+	m := buildStandardMap()
+	SetMapDebug(true)
+	defer SetMapDebug(false)
+	m.checkFrozen()
+
+	m.Provinces["par"].HomePower = Germany
+	err := m.Verify()
+	if err == nil || !strings.Contains(err.Error(), "province par") {
+		t.Fatalf("expected par to be reported, got %v", err)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected a panic on the mutated map")
+			}
+		}()
+		m.checkFrozen()
+	}()
+
+	m.Provinces["par"].HomePower = France
+	m.ProvincesAdjacentTo("mun", NoCoast, false)[0] = "xxx"
+	if err := m.Verify(); err == nil || !strings.Contains(err.Error(), "mun") {
+		t.Errorf("expected mun's cached neighbours to be reported, got %v", err)
+	}
+}
@@ -51,6 +51,8 @@ type Order struct {
 	AuxTarget string
 	// For support: the type of the supported unit
 	AuxUnitType UnitType
+	// For support: the coast of AuxTarget the supported fleet moves to, if named
+	AuxTargetCoast Coast
 }
 
 // OrderResult describes the outcome of adjudicating an order.
@@ -88,6 +90,7 @@ func (r OrderResult) String() string {
 type ResolvedOrder struct {
 	Order  Order
 	Result OrderResult
+	Reason string // why a void order was rejected
 }
 
 // Describe returns a human-readable description of the order.
@@ -1,73 +1,133 @@
 package diplomacy
 
-// NextPhase computes the next phase after the current one.
-// Movement -> Retreat (if dislodgements) or straight to Fall Movement / Build.
-// Retreat -> Fall Movement or Build (if Fall).
-// Build -> Spring Movement of next year.
+// NextPhase computes the next phase after the current one, following the
+// scenario's calendar (Spring and Fall in the standard game).
+// Movement -> Retreat (if dislodgements) or straight to the next season's
+// Movement / Build (after the last season).
+// Retreat -> next season's Movement, or Build after the last season.
+// Build -> first season's Movement of next year.
 func NextPhase(gs *GameState, hasDislodgements bool) (Season, PhaseType) {
+	cal := gs.Calendar()
 	switch gs.Phase {
 	case PhaseMovement:
 		if hasDislodgements {
 			return gs.Season, PhaseRetreat
 		}
-		return afterMovement(gs.Season)
+		return cal.afterMovement(gs.Season)
 	case PhaseRetreat:
-		return afterMovement(gs.Season)
-	case PhaseBuild:
-		return Spring, PhaseMovement
-	}
-	return Spring, PhaseMovement
-}
-
-func afterMovement(season Season) (Season, PhaseType) {
-	if season == Spring {
-		return Fall, PhaseMovement
+		return cal.afterMovement(gs.Season)
 	}
-	// After Fall movement, always go to Build phase for adjustments
-	return Fall, PhaseBuild
+	return cal.FirstSeason(), PhaseMovement
 }
 
-// NeedsBuildPhase returns true if any power has a unit/SC mismatch requiring adjustments.
+// NeedsBuildPhase returns true if any power has a unit/SC mismatch requiring
+// adjustments, or build credits to use.
 func NeedsBuildPhase(gs *GameState) bool {
 	for _, power := range AllPowers() {
-		if gs.SupplyCenterCount(power) != gs.UnitCount(power) {
+		if gs.AdjustmentDelta(power) != 0 {
 			return true
 		}
 	}
 	return false
 }
 
+// HasOrderChoice reports whether power has more than one meaningful set of
+// orders in the current phase. It is false when the only option is forced:
+// no dislodged units or nowhere to retreat, no adjustment, no free home
+// center to build on, or every unit must be disbanded.
+func HasOrderChoice(gs *GameState, power Power, m *DiplomacyMap) bool {
+	switch gs.Phase {
+	case PhaseRetreat:
+		for _, d := range gs.Dislodged {
+			if d.Unit.Power != power {
+				continue
+			}
+			for _, target := range m.ProvincesAdjacentTo(d.DislodgedFrom, d.Unit.Coast, d.Unit.Type == Fleet) {
+				if CanRetreatTo(gs, d, target) {
+					return true
+				}
+			}
+		}
+		return false
+	case PhaseBuild:
+		units := gs.UnitCount(power)
+		delta := gs.AdjustmentDelta(power)
+		if delta < 0 {
+			return units > -delta
+		}
+		return delta > 0 && len(OpenHomeCenters(gs, power)) > 0
+	default:
+		return gs.UnitCount(power) > 0
+	}
+}
+
 // MaxYear is the highest year a game can reach before ending as a draw.
 const MaxYear = 3000
 
-// IsYearLimitReached returns true if the game has exceeded the maximum year.
+// LastYear returns the final playable year for the game: YearLimit when set,
+// otherwise MaxYear.
+func (gs *GameState) LastYear() int {
+	if gs.YearLimit > 0 {
+		return gs.YearLimit
+	}
+	return MaxYear
+}
+
+// IsYearLimitReached returns true if the game has exceeded its last playable year.
 func IsYearLimitReached(gs *GameState) bool {
-	return gs.Year > MaxYear
+	return gs.Year > gs.LastYear()
 }
 
-// IsGameOver checks if any single power controls 18+ supply centers (solo victory).
+// IsGameOver checks if any single power controls enough supply centers for a
+// solo victory (18 in the standard game, unless the game sets VictorySCs).
+// With a threshold of half the centers or fewer, several powers can reach it
+// at once; the one with the most centers wins. On partial boards such as
+// duels the game also ends when only one active power remains.
 func IsGameOver(gs *GameState) (bool, Power) {
+	need := gs.VictoryCenters()
+	winner, most := Neutral, 0
 	for _, power := range AllPowers() {
-		if gs.SupplyCenterCount(power) >= 18 {
-			return true, power
+		if sc := gs.SupplyCenterCount(power); sc >= need && sc > most {
+			winner, most = power, sc
+		}
+	}
+	if winner != Neutral {
+		return true, winner
+	}
+	if gs.IsPartialBoard() {
+		alive := Neutral
+		for _, power := range gs.ActivePowers() {
+			if !gs.PowerIsAlive(power) {
+				continue
+			}
+			if alive != Neutral {
+				return false, Neutral
+			}
+			alive = power
+		}
+		if alive != Neutral {
+			return true, alive
 		}
 	}
 	return false, Neutral
 }
 
 // AdvanceState transitions the game state to the next phase.
-// For movement: updates year/season/phase, updates SC ownership after Fall.
+// For movement: updates year/season/phase, updates SC ownership after the
+// last season of the year (Fall in the standard game).
 // Callers must apply resolution results to units before calling this.
 func AdvanceState(gs *GameState, hasDislodgements bool) {
 	nextSeason, nextPhase := NextPhase(gs, hasDislodgements)
 
-	// After Fall movement or Fall retreat, update SC ownership
-	if gs.Season == Fall && (gs.Phase == PhaseMovement || gs.Phase == PhaseRetreat) {
+	// After the last season's movement or retreat, update SC ownership
+	if gs.IsLastSeason() && (gs.Phase == PhaseMovement || gs.Phase == PhaseRetreat) {
 		UpdateSupplyCenterOwnership(gs)
 	}
 
-	if nextSeason == Spring && nextPhase == PhaseMovement {
+	// The build phase closes the year, and any unused build credits with it
+	if gs.Phase == PhaseBuild {
 		gs.Year++
+		gs.BuildCredits = nil
 	}
 	gs.Season = nextSeason
 	gs.Phase = nextPhase
@@ -77,13 +137,14 @@ func AdvanceState(gs *GameState, hasDislodgements bool) {
 }
 
 // UpdateSupplyCenterOwnership assigns SCs to the power whose unit occupies them.
-// This is called automatically by AdvanceState after Fall movement/retreat phases.
+// This is called automatically by AdvanceState after the last season's
+// movement/retreat phases (Fall in the standard game).
 // It is also safe to call explicitly (idempotent) when the caller needs updated
 // SC ownership before AdvanceState runs (e.g. to store the final state_after).
 func UpdateSupplyCenterOwnership(gs *GameState) {
-	stdMap := StandardMap()
+	m := gs.Map()
 	for provID := range gs.SupplyCenters {
-		prov := stdMap.Provinces[provID]
+		prov := m.Provinces[provID]
 		if prov == nil || !prov.IsSupplyCenter {
 			continue
 		}
@@ -94,27 +155,8 @@ func UpdateSupplyCenterOwnership(gs *GameState) {
 	}
 }
 
-// homeCentersCache stores pre-computed home centers for each power.
-// Computed once on first access since home centers never change.
-var homeCentersCache map[Power][]string
-
-// HomeCenters returns the home supply center IDs for a given power.
+// HomeCenters returns the home supply center IDs of a power on the standard
+// map; use GameState.Map().HomeCenters for the map a game is played on.
 func HomeCenters(power Power) []string {
-	if homeCentersCache != nil {
-		if c, ok := homeCentersCache[power]; ok {
-			return c
-		}
-	}
-	stdMap := StandardMap()
-	if homeCentersCache == nil {
-		homeCentersCache = make(map[Power][]string, 7)
-	}
-	var centers []string
-	for _, prov := range stdMap.Provinces {
-		if prov.HomePower == power && prov.IsSupplyCenter {
-			centers = append(centers, prov.ID)
-		}
-	}
-	homeCentersCache[power] = centers
-	return centers
+	return StandardMap().HomeCenters(power)
 }
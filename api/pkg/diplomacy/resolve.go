@@ -1,5 +1,7 @@
 package diplomacy
 
+import "slices"
+
 // Resolution state constants for the Kruijswijk algorithm.
 type resolutionState int
 
@@ -21,19 +23,25 @@ type adjResult struct {
 	attackStr    int
 	holdStr      int
 	preventStr   int
+	guess        int  // when the order was first guessed, counting from 1
+	convoyed     bool // an army move made by convoy rather than over land
 }
 
 // ResolveOrders adjudicates a set of validated orders against the game state.
 // Returns the list of resolved orders with outcomes, and a list of dislodged units.
+// Neutral garrisons hold whether or not orders are given for them; a
+// dislodged garrison is destroyed rather than listed as dislodged.
 func ResolveOrders(orders []Order, gs *GameState, m *DiplomacyMap) ([]ResolvedOrder, []DislodgedUnit) {
 	r := newResolver(orders, gs, m)
 	return r.resolve()
 }
 
 type resolver struct {
-	lookup    [ProvinceCount]int16 // province index -> adjBuf offset (-1 = no order)
-	adjBuf    []adjResult          // dense storage for iteration
+	lookup    [MaxProvinces]int16 // province index -> adjBuf offset (-1 = no order)
+	adjBuf    []adjResult         // dense storage for iteration
 	orderList []Order
+	deps      []int16 // orders resolved while a cycle guess is pending
+	guesses   int     // guesses made so far
 	gs        *GameState
 	m         *DiplomacyMap
 }
@@ -85,9 +93,27 @@ func (r *resolver) initLookup() {
 			r.lookup[pIdx] = int16(i)
 		}
 	}
+	for i := range r.adjBuf {
+		ar := &r.adjBuf[i]
+		switch ar.order.Type {
+		case OrderMove:
+			if r.needsConvoy(ar.order) {
+				ar.convoyed = true
+			}
+		case OrderConvoy:
+			// The 2000 rulebook's intent rule: an army moving to an adjacent
+			// province goes by convoy when its own power orders a fleet to
+			// carry it there.
+			if army := r.orderAt(ar.auxLocIdx); army != nil && army.order.Type == OrderMove &&
+				army.order.UnitType == Army && army.order.Power == ar.order.Power && army.targetIdx == ar.auxTargetIdx {
+				army.convoyed = true
+			}
+		}
+	}
 }
 
 func newResolver(orders []Order, gs *GameState, m *DiplomacyMap) *resolver {
+	orders = withGarrisonHolds(orders, gs, new([]Order))
 	r := &resolver{
 		adjBuf:    make([]adjResult, len(orders)),
 		orderList: orders,
@@ -98,6 +124,35 @@ func newResolver(orders []Order, gs *GameState, m *DiplomacyMap) *resolver {
 	return r
 }
 
+// withGarrisonHolds returns orders plus a hold for every neutral garrison
+// that has no order, built in *buf when there are any. The caller's orders
+// are never modified.
+func withGarrisonHolds(orders []Order, gs *GameState, buf *[]Order) []Order {
+	out, copied := orders, false
+	for _, u := range gs.Units {
+		if u.Power != Neutral || hasOrderAt(orders, u.Province) {
+			continue
+		}
+		if !copied {
+			out, copied = append((*buf)[:0], orders...), true
+		}
+		out = append(out, Order{UnitType: u.Type, Power: Neutral, Location: u.Province, Coast: u.Coast, Type: OrderHold})
+	}
+	if copied {
+		*buf = out
+	}
+	return out
+}
+
+func hasOrderAt(orders []Order, province string) bool {
+	for i := range orders {
+		if orders[i].Location == province {
+			return true
+		}
+	}
+	return false
+}
+
 func (r *resolver) resolve() ([]ResolvedOrder, []DislodgedUnit) {
 	for i := range r.adjBuf {
 		r.adjudicate(r.adjBuf[i].provIdx)
@@ -106,8 +161,11 @@ func (r *resolver) resolve() ([]ResolvedOrder, []DislodgedUnit) {
 }
 
 // adjudicate resolves the order at the given province index.
-// Uses the Kruijswijk approach: when encountering a cycle,
-// guess a resolution, check consistency, back off if inconsistent.
+// Uses the Kruijswijk approach: when encountering a cycle, guess that the
+// order fails, then that it succeeds. Orders resolved while a guess is
+// pending stay provisional on r.deps and are reset once the cycle is
+// settled. A cycle consistent with both guesses, or with neither, is a
+// circular movement or a convoy paradox and is settled by backupRule.
 func (r *resolver) adjudicate(provIdx int16) bool {
 	ar := r.orderAt(provIdx)
 	if ar == nil {
@@ -118,23 +176,85 @@ func (r *resolver) adjudicate(provIdx int16) bool {
 	case rsResolved:
 		return ar.resolution
 	case rsGuessing:
+		r.deps = append(r.deps, provIdx)
 		return ar.resolution
 	}
 
-	// Mark as guessing with initial guess = succeeds.
+	depth := len(r.deps)
+	r.guesses++
+	ar.guess = r.guesses
+	ar.state = rsGuessing
+	ar.resolution = false
+	first := r.resolveOrder(provIdx)
+
+	if len(r.deps) == depth {
+		// No guess was relied on; the result is final.
+		ar.state = rsResolved
+		ar.resolution = first
+		return first
+	}
+	if r.reliesOnEarlierGuess(depth, ar.guess) {
+		// The result relies on a guess made further up; stay provisional.
+		r.deps = append(r.deps, provIdx)
+		ar.resolution = first
+		return first
+	}
+
+	// The result relies on our own guess: try the other one.
+	r.resetDeps(depth)
 	ar.state = rsGuessing
 	ar.resolution = true
+	second := r.resolveOrder(provIdx)
+
+	if first == second {
+		r.resetDeps(depth)
+		ar.state = rsResolved
+		ar.resolution = first
+		return first
+	}
 
-	result := r.resolveOrder(provIdx)
+	r.backupRule(depth)
+	return r.adjudicate(provIdx)
+}
+
+// reliesOnEarlierGuess reports whether any order on r.deps past depth was
+// guessed before the guess numbered guess, so is not part of its cycle.
+func (r *resolver) reliesOnEarlierGuess(depth, guess int) bool {
+	return slices.ContainsFunc(r.deps[depth:], func(idx int16) bool {
+		return r.orderAt(idx).guess < guess
+	})
+}
 
-	if ar.state == rsGuessing && result != ar.resolution {
-		ar.resolution = result
-		result = r.resolveOrder(provIdx)
+// resetDeps makes the orders resolved since r.deps had depth entries
+// unresolved again.
+func (r *resolver) resetDeps(depth int) {
+	for _, idx := range r.deps[depth:] {
+		r.orderAt(idx).state = rsUnresolved
 	}
+	r.deps = r.deps[:depth]
+}
 
-	ar.state = rsResolved
-	ar.resolution = result
-	return result
+// backupRule settles the cycle on r.deps past depth. If it involves a
+// convoy it is a convoy paradox, broken by the Szykman rule: the convoys in
+// it fail, so the armies they carry neither move nor cut support.
+// Otherwise it is a circular movement and every move in it succeeds.
+func (r *resolver) backupRule(depth int) {
+	cycle := r.deps[depth:]
+	paradox := slices.ContainsFunc(cycle, func(idx int16) bool {
+		return r.orderAt(idx).order.Type == OrderConvoy
+	})
+	for _, idx := range cycle {
+		ar := r.orderAt(idx)
+		switch {
+		case paradox && ar.order.Type == OrderConvoy:
+			ar.state, ar.resolution = rsResolved, false
+		case !paradox && ar.order.Type == OrderMove:
+			ar.state, ar.resolution = rsResolved, true
+		default:
+			ar.state = rsUnresolved
+		}
+	}
+	r.deps = r.deps[:depth]
 }
 
 func (r *resolver) resolveOrder(provIdx int16) bool {
@@ -157,25 +277,20 @@ func (r *resolver) resolveOrder(provIdx int16) bool {
 func (r *resolver) resolveMove(provIdx int16) bool {
 	ar := r.orderAt(provIdx)
 
-	if r.needsConvoy(ar.order) && !r.hasConvoyPath(ar.order) {
+	if !r.pathSucceeds(ar) {
 		return false
 	}
 
 	attackStr := r.attackStrength(provIdx)
-	holdStr := r.holdStrength(ar.targetIdx)
-
-	if attackStr <= holdStr {
-		return false
-	}
 
-	// Head-to-head battle: if the defender is moving to our province,
-	// our attack must also exceed the defender's attack strength.
-	defender := r.orderAt(ar.targetIdx)
-	if defender != nil && defender.order.Type == OrderMove && defender.targetIdx == provIdx {
-		defendAttack := r.attackStrength(ar.targetIdx)
-		if attackStr <= defendAttack {
+	// In a head-to-head battle the attack must beat the defender's own
+	// attack; otherwise it must beat the unit holding the target.
+	if opp := r.headToHead(ar); opp != nil {
+		if attackStr <= r.defendStrength(opp.provIdx) {
 			return false
 		}
+	} else if attackStr <= r.holdStrength(ar.targetIdx) {
+		return false
 	}
 
 	// Attack must exceed all other prevent strengths at the target.
@@ -195,6 +310,25 @@ func (r *resolver) resolveMove(provIdx int16) bool {
 	return true
 }
 
+// headToHead returns the move that trades places with ar over land, or nil.
+// Moves by convoy pass each other and never fight head to head.
+func (r *resolver) headToHead(ar *adjResult) *adjResult {
+	if ar.order.Type != OrderMove || ar.convoyed {
+		return nil
+	}
+	opp := r.orderAt(ar.targetIdx)
+	if opp == nil || opp.order.Type != OrderMove || opp.targetIdx != ar.provIdx || opp.convoyed {
+		return nil
+	}
+	return opp
+}
+
+// pathSucceeds reports whether a move can reach its target: always over
+// land, and by convoy only if a chain of successful convoys carries it.
+func (r *resolver) pathSucceeds(ar *adjResult) bool {
+	return !ar.convoyed || r.hasConvoyPath(ar.order)
+}
+
 // resolveSupport determines if support is successfully given (not cut).
 func (r *resolver) resolveSupport(provIdx int16) bool {
 	ar := r.orderAt(provIdx)
@@ -208,18 +342,22 @@ func (r *resolver) resolveSupport(provIdx int16) bool {
 			continue
 		}
 
-		// Support cannot be cut by the unit being supported against.
-		if ar.auxTargetIdx >= 0 && other.provIdx == ar.auxTargetIdx {
+		// Support cannot be cut by a unit of the same power.
+		if other.order.Power == ar.order.Power {
 			continue
 		}
 
-		// Support cannot be cut by a unit of the same power.
-		if other.order.Power == ar.order.Power {
+		// Support cannot be cut by the unit being supported against moving
+		// over land, only by it dislodging the supporter.
+		if ar.auxTargetIdx >= 0 && other.provIdx == ar.auxTargetIdx && !other.convoyed {
+			if r.adjudicate(other.provIdx) {
+				return false
+			}
 			continue
 		}
 
-		// For a convoyed attack, the convoy must succeed for the support to be cut.
-		if r.needsConvoy(other.order) && !r.adjudicate(other.provIdx) {
+		// A convoyed attack cuts support only if its convoy gets through.
+		if !r.pathSucceeds(other) {
 			continue
 		}
 
@@ -242,48 +380,51 @@ func (r *resolver) resolveConvoy(provIdx int16) bool {
 	return true
 }
 
-// attackStrength computes the attack strength of a move order.
+// attackStrength computes the attack strength of a move order. A unit
+// that stays in the target is never dislodged by its own power: a unit of
+// the same power blocks the attack, and support from its power doesn't count.
 func (r *resolver) attackStrength(provIdx int16) int {
 	ar := r.orderAt(provIdx)
-	if ar.order.Type != OrderMove {
+	if ar.order.Type != OrderMove || !r.pathSucceeds(ar) {
 		return 0
 	}
 
-	strength := 1
-
-	// A unit cannot attack a province occupied by a unit of the same power
-	// UNLESS the occupying unit is moving away.
 	occupier := r.gs.UnitAt(ar.order.Target)
-	if occupier != nil && occupier.Power == ar.order.Power {
-		occOrder := r.orderAt(ar.targetIdx)
-		if occOrder == nil || occOrder.order.Type != OrderMove {
-			return 0
-		}
-		if occOrder.targetIdx == provIdx {
-			return 0
-		}
+	if occupier != nil && occupier.Power == ar.order.Power && !r.movesAway(ar) {
+		return 0
 	}
 
 	// Count successful support for this move.
+	strength, occupierSupport := 1, 0
 	for i := range r.adjBuf {
 		other := &r.adjBuf[i]
-		if other.order.Type != OrderSupport {
-			continue
-		}
-		if other.auxLocIdx != provIdx {
-			continue
-		}
-		if other.auxTargetIdx != ar.targetIdx {
+		if !supportsMove(other, ar) {
 			continue
 		}
 		if r.adjudicate(other.provIdx) {
 			strength++
+			if occupier != nil && other.order.Power == occupier.Power {
+				occupierSupport++
+			}
 		}
 	}
 
+	if occupierSupport > 0 && !r.movesAway(ar) {
+		strength -= occupierSupport
+	}
 	return strength
 }
 
+// movesAway reports whether the unit in ar's target leaves it. A unit in a
+// head-to-head battle with ar stays put whatever happens.
+func (r *resolver) movesAway(ar *adjResult) bool {
+	occ := r.orderAt(ar.targetIdx)
+	if occ == nil || occ.order.Type != OrderMove || r.headToHead(ar) != nil {
+		return false
+	}
+	return r.adjudicate(ar.targetIdx)
+}
+
 // holdStrength computes the hold strength of a province.
 func (r *resolver) holdStrength(provIdx int16) int {
 	ar := r.orderAt(provIdx)
@@ -314,34 +455,51 @@ func (r *resolver) holdStrength(provIdx int16) int {
 	return strength
 }
 
-// preventStrength computes the prevent strength of a move order.
+// defendStrength computes the strength a move defends with in a
+// head-to-head battle: the unit plus every successful support for its move.
+func (r *resolver) defendStrength(provIdx int16) int {
+	ar := r.orderAt(provIdx)
+	return 1 + r.moveSupport(ar)
+}
+
+// preventStrength computes the prevent strength of a move order. A move
+// that cannot reach its target, or that loses a head-to-head battle, keeps
+// no one else out.
 func (r *resolver) preventStrength(provIdx int16) int {
 	ar := r.orderAt(provIdx)
-	if ar.order.Type != OrderMove {
+	if ar.order.Type != OrderMove || !r.pathSucceeds(ar) {
 		return 0
 	}
 
-	defender := r.orderAt(ar.targetIdx)
-	if defender != nil && defender.order.Type == OrderMove && defender.targetIdx == provIdx {
-		if !r.adjudicate(provIdx) {
-			return 0
-		}
+	if opp := r.headToHead(ar); opp != nil && r.adjudicate(opp.provIdx) {
+		return 0
 	}
 
-	strength := 1
+	return 1 + r.moveSupport(ar)
+}
+
+// moveSupport counts the successful supports for a move.
+func (r *resolver) moveSupport(ar *adjResult) int {
+	n := 0
 	for i := range r.adjBuf {
 		other := &r.adjBuf[i]
-		if other.order.Type != OrderSupport {
-			continue
-		}
-		if other.auxLocIdx != provIdx || other.auxTargetIdx != ar.targetIdx {
+		if !supportsMove(other, ar) {
 			continue
 		}
 		if r.adjudicate(other.provIdx) {
-			strength++
+			n++
 		}
 	}
-	return strength
+	return n
+}
+
+// supportsMove reports whether sup is a support for the move ar, including
+// the coast it moves to when the support names one.
+func supportsMove(sup, ar *adjResult) bool {
+	if sup.order.Type != OrderSupport || sup.auxLocIdx != ar.provIdx || sup.auxTargetIdx != ar.targetIdx {
+		return false
+	}
+	return sup.order.AuxTargetCoast == NoCoast || sup.order.AuxTargetCoast == ar.order.TargetCoast
 }
 
 // needsConvoy returns true if the move requires a convoy chain.
@@ -456,6 +614,8 @@ func (r *resolver) buildResults() ([]ResolvedOrder, []DislodgedUnit) {
 		if attacker, ok := successfulMoves[o.Location]; ok {
 			if o.Type != OrderMove || !ar.resolution {
 				result = ResultDislodged
+			}
+			if result == ResultDislodged && o.Power != Neutral {
 				dislodged = append(dislodged, DislodgedUnit{
 					Unit: Unit{
 						Type:     o.UnitType,
@@ -465,6 +625,7 @@ func (r *resolver) buildResults() ([]ResolvedOrder, []DislodgedUnit) {
 					},
 					DislodgedFrom: o.Location,
 					AttackerFrom:  attacker,
+					ByConvoy:      r.orderAtLoc(attacker).convoyed,
 				})
 			}
 		}
@@ -489,7 +650,7 @@ type applyMoveEntry struct {
 }
 
 // ApplyResolution updates the game state based on resolved orders.
-// Moves successful units, removes dislodged units from the board.
+// Moves successful units, removes dislodged units and garrisons from the board.
 func ApplyResolution(gs *GameState, m *DiplomacyMap, results []ResolvedOrder, dislodged []DislodgedUnit) {
 	dislodgedSet := make(map[applyUnitKey]bool)
 	for _, d := range dislodged {
@@ -498,6 +659,9 @@ func ApplyResolution(gs *GameState, m *DiplomacyMap, results []ResolvedOrder, di
 
 	moves := make(map[applyUnitKey]applyMoveEntry)
 	for _, ro := range results {
+		if ro.Order.Power == Neutral && ro.Result == ResultDislodged {
+			dislodgedSet[applyUnitKey{Neutral, ro.Order.Location}] = true
+		}
 		if ro.Order.Type == OrderMove && ro.Result == ResultSucceeded {
 			clearCoast := ro.Order.TargetCoast == NoCoast && !m.HasCoasts(ro.Order.Target)
 			moves[applyUnitKey{ro.Order.Power, ro.Order.Location}] = applyMoveEntry{
@@ -508,6 +672,7 @@ func ApplyResolution(gs *GameState, m *DiplomacyMap, results []ResolvedOrder, di
 		}
 	}
 	applyMoves(gs, moves, dislodgedSet, dislodged)
+	gs.Standoffs = standoffs(gs, results, dislodged)
 }
 
 // applyMoves applies move updates and removes dislodged units from the game state.
@@ -534,6 +699,30 @@ func applyMoves(gs *GameState, moves map[applyUnitKey]applyMoveEntry, dislodgedS
 	gs.Dislodged = dislodged
 }
 
+// standoffs lists the provinces left empty after two or more moves into
+// them failed, which dislodged units may not retreat to. It is nil when no
+// unit was dislodged, as there is no retreat phase.
+func standoffs(gs *GameState, results []ResolvedOrder, dislodged []DislodgedUnit) []string {
+	if len(dislodged) == 0 {
+		return nil
+	}
+	var out []string
+	for i, ro := range results {
+		if ro.Order.Type != OrderMove || ro.Result == ResultSucceeded || slices.Contains(out, ro.Order.Target) {
+			continue
+		}
+		for _, other := range results[i+1:] {
+			if other.Order.Type == OrderMove && other.Result != ResultSucceeded && other.Order.Target == ro.Order.Target {
+				if gs.UnitAt(ro.Order.Target) == nil {
+					out = append(out, ro.Order.Target)
+				}
+				break
+			}
+		}
+	}
+	return out
+}
+
 // Resolver is a reusable order adjudicator that minimizes allocations.
 // Allocate once with NewResolver and call Resolve repeatedly in hot loops.
 // The returned slices are owned by the Resolver and overwritten on the next call.
@@ -548,6 +737,8 @@ type Resolver struct {
 	// Apply buffers
 	dislodgedSet map[applyUnitKey]bool
 	movesMap     map[applyUnitKey]applyMoveEntry
+
+	garrisonBuf []Order // orders plus garrison holds, when there are garrisons
 }
 
 // NewResolver creates a reusable resolver. capacity should be the
@@ -584,6 +775,7 @@ func (rv *Resolver) Resolve(orders []Order, gs *GameState, m *DiplomacyMap) ([]R
 
 func (rv *Resolver) reset(orders []Order, gs *GameState, m *DiplomacyMap) {
 	r := &rv.r
+	orders = withGarrisonHolds(orders, gs, &rv.garrisonBuf)
 	n := len(orders)
 	if cap(r.adjBuf) >= n {
 		r.adjBuf = r.adjBuf[:n]
@@ -591,6 +783,8 @@ func (rv *Resolver) reset(orders []Order, gs *GameState, m *DiplomacyMap) {
 		r.adjBuf = make([]adjResult, n)
 	}
 	r.orderList = orders
+	r.deps = r.deps[:0]
+	r.guesses = 0
 	r.gs = gs
 	r.m = m
 	r.initLookup()
@@ -636,6 +830,8 @@ func (rv *Resolver) buildResults() ([]ResolvedOrder, []DislodgedUnit) {
 		if attacker, ok := rv.moveMap[o.Location]; ok {
 			if o.Type != OrderMove || !ar.resolution {
 				result = ResultDislodged
+			}
+			if result == ResultDislodged && o.Power != Neutral {
 				rv.disBuf = append(rv.disBuf, DislodgedUnit{
 					Unit: Unit{
 						Type:     o.UnitType,
@@ -645,6 +841,7 @@ func (rv *Resolver) buildResults() ([]ResolvedOrder, []DislodgedUnit) {
 					},
 					DislodgedFrom: o.Location,
 					AttackerFrom:  attacker,
+					ByConvoy:      r.orderAtLoc(attacker).convoyed,
 				})
 			}
 		}
@@ -666,6 +863,9 @@ func (rv *Resolver) Apply(gs *GameState, m *DiplomacyMap) {
 	}
 
 	for _, ro := range rv.resBuf {
+		if ro.Order.Power == Neutral && ro.Result == ResultDislodged {
+			rv.dislodgedSet[applyUnitKey{Neutral, ro.Order.Location}] = true
+		}
 		if ro.Order.Type == OrderMove && ro.Result == ResultSucceeded {
 			clearCoast := ro.Order.TargetCoast == NoCoast && !m.HasCoasts(ro.Order.Target)
 			rv.movesMap[applyUnitKey{ro.Order.Power, ro.Order.Location}] = applyMoveEntry{
@@ -676,6 +876,7 @@ func (rv *Resolver) Apply(gs *GameState, m *DiplomacyMap) {
 		}
 	}
 	applyMoves(gs, rv.movesMap, rv.dislodgedSet, rv.disBuf)
+	gs.Standoffs = standoffs(gs, rv.resBuf, rv.disBuf)
 }
 
 // HasDislodged returns true if the last Resolve call produced any dislodged units.
@@ -0,0 +1,371 @@
+package diplomacy
+
+import (
+	"fmt"
+	"maps"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// The resolver property harness adjudicates random legal positions and
+// order sets and checks invariants any correct adjudication keeps: units
+// are only lost by being dislodged, no two units end up in one province,
+// units stay on terrain they can occupy, and a dislodged unit was attacked
+// with more strength than it held with.
+//
+// TestResolverProperties runs a fixed budget of seeds on every test run
+// (fewer with -short); FuzzResolverProperties explores further under
+// go test -fuzz. A failing seed is saved to testdata/resolver as a fixture
+// in the DATC fixture format, which TestResolverFixtures replays.
+
+// resolverPropertySeeds is the number of seeds TestResolverProperties runs.
+const resolverPropertySeeds = 2000
+
+func TestResolverProperties(t *testing.T) {
+	n := resolverPropertySeeds
+	if testing.Short() {
+		n /= 10
+	}
+	m := StandardMap()
+	for seed := int64(1); seed <= int64(n); seed++ {
+		checkRandomResolution(t, m, seed)
+	}
+}
+
+func FuzzResolverProperties(f *testing.F) {
+	for seed := int64(1); seed <= 8; seed++ {
+		f.Add(seed)
+	}
+	m := StandardMap()
+	f.Fuzz(func(t *testing.T, seed int64) {
+		checkRandomResolution(t, m, seed)
+	})
+}
+
+// TestResolverFixtures checks the invariants, and any expectations, of
+// every fixture in testdata/resolver. As with DATC fixtures, "xfail" marks
+// a case the resolver is known to get wrong.
+func TestResolverFixtures(t *testing.T) {
+	m := StandardMap()
+	files, err := filepath.Glob("testdata/resolver/*.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cases, err := parseDATCFile(filepath.Base(f), string(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, c := range cases {
+			t.Run(c.id, func(t *testing.T) {
+				gs, err := c.initialState()
+				if err != nil {
+					t.Fatal(err)
+				}
+				orders, err := c.movementOrders()
+				if err != nil {
+					t.Fatal(err)
+				}
+				mismatches, err := c.run(m)
+				if err != nil {
+					t.Fatal(err)
+				}
+				problems := append(resolutionViolations(gs, m, orders), mismatches...)
+				switch {
+				case c.xfail != "" && len(problems) > 0:
+					t.Skipf("%s (known failure: %s): %s", c.title, c.xfail, strings.Join(problems, "; "))
+				case c.xfail != "":
+					t.Errorf("%s:%d: %s now passes; remove its xfail", c.file, c.line, c.title)
+				case len(problems) > 0:
+					t.Errorf("%s:%d: %s", c.file, c.line, strings.Join(problems, "; "))
+				}
+			})
+		}
+	}
+}
+
+// checkRandomResolution adjudicates the position and orders generated from
+// seed, saving them as a fixture if an invariant fails and there is none
+// for the seed yet.
+func checkRandomResolution(t *testing.T, m *DiplomacyMap, seed int64) {
+	t.Helper()
+	rng := rand.New(rand.NewSource(seed))
+	gs := randomPosition(rng, m)
+	orders, _ := ValidateAndDefaultOrders(randomLegalOrders(rng, gs, m), gs, m)
+	violations := resolutionViolations(gs, m, orders)
+	if len(violations) == 0 {
+		return
+	}
+	name := fmt.Sprintf("fuzz-%d.txt", seed)
+	file := filepath.Join("testdata/resolver", name)
+	if data, err := os.ReadFile(file); err == nil {
+		// Already saved; a fixture marked xfail is a known failure.
+		if cases, err := parseDATCFile(name, string(data)); err == nil && len(cases) == 1 && cases[0].xfail != "" {
+			t.Skipf("seed %d (known failure: %s)", seed, cases[0].xfail)
+		}
+	} else if err := writeResolverFixture(file, fmt.Sprintf("fuzz.%d", seed), gs, orders, violations); err != nil {
+		t.Logf("saving fixture: %v", err)
+	}
+	t.Fatalf("seed %d (see testdata/resolver/%s):\n  %s", seed, name, strings.Join(violations, "\n  "))
+}
+
+// randomPosition places between 10 and 34 units of random powers in random
+// provinces, each of a type that can stand there.
+func randomPosition(rng *rand.Rand, m *DiplomacyMap) *GameState {
+	gs := &GameState{
+		Year:          1901,
+		Season:        []Season{Spring, Fall}[rng.Intn(2)],
+		Phase:         PhaseMovement,
+		SupplyCenters: map[string]Power{},
+	}
+	provinces := slices.Sorted(maps.Keys(m.Provinces))
+	rng.Shuffle(len(provinces), func(i, j int) { provinces[i], provinces[j] = provinces[j], provinces[i] })
+	powers := AllPowers()
+	for _, id := range provinces[:10+rng.Intn(25)] {
+		prov := m.Provinces[id]
+		u := Unit{Type: Army, Power: powers[rng.Intn(len(powers))], Province: id}
+		if prov.Type == Sea || (prov.Type == Coastal && rng.Intn(2) == 0) {
+			u.Type = Fleet
+			if len(prov.Coasts) > 0 {
+				u.Coast = prov.Coasts[rng.Intn(len(prov.Coasts))]
+			}
+		}
+		gs.Units = append(gs.Units, u)
+	}
+	return gs
+}
+
+// randomLegalOrders gives every unit an order: holds and moves first, some
+// of them convoyed, then supports mostly matching the supported unit's
+// order and convoys mostly matching a convoyed army's move. Orders the
+// rules reject still become holds in validation.
+func randomLegalOrders(rng *rand.Rand, gs *GameState, m *DiplomacyMap) []Order {
+	orders := make([]Order, len(gs.Units))
+	var supporters, convoyers []int
+	for i, u := range gs.Units {
+		o := Order{UnitType: u.Type, Power: u.Power, Location: u.Province, Coast: u.Coast, Type: OrderHold}
+		adj := m.ProvincesAdjacentTo(u.Province, u.Coast, u.Type == Fleet)
+		switch r := rng.Intn(10); {
+		case r < 2:
+		case r < 6 && len(adj) > 0:
+			o.Type = OrderMove
+			o.Target = adj[rng.Intn(len(adj))]
+			if u.Type == Fleet {
+				if coasts := m.FleetCoastsTo(u.Province, u.Coast, o.Target); len(coasts) > 0 {
+					o.TargetCoast = coasts[rng.Intn(len(coasts))]
+				}
+			}
+		case r < 7 && u.Type == Army:
+			if targets := convoyTargets(m, u.Province); len(targets) > 0 {
+				o.Type = OrderMove
+				o.Target = targets[rng.Intn(len(targets))]
+			}
+		case u.Type == Fleet && m.Provinces[u.Province].Type == Sea && rng.Intn(2) == 0:
+			convoyers = append(convoyers, i)
+		default:
+			supporters = append(supporters, i)
+		}
+		orders[i] = o
+	}
+
+	for _, i := range supporters {
+		u := gs.Units[i]
+		adj := m.ProvincesAdjacentTo(u.Province, u.Coast, u.Type == Fleet)
+		var candidates []Order
+		for j, o := range orders {
+			dest := o.Location
+			if o.Type == OrderMove {
+				dest = o.Target
+			}
+			if j != i && slices.Contains(adj, dest) {
+				candidates = append(candidates, o)
+			}
+		}
+		if len(candidates) == 0 {
+			continue
+		}
+		supported := candidates[rng.Intn(len(candidates))]
+		o := &orders[i]
+		o.Type, o.AuxLoc, o.AuxUnitType = OrderSupport, supported.Location, supported.UnitType
+		switch {
+		case rng.Intn(5) == 0 && slices.Contains(adj, supported.Location):
+			// Support a hold the unit wasn't ordered to make.
+		case supported.Type == OrderMove:
+			o.AuxTarget = supported.Target
+		}
+	}
+
+	var convoyed []Order
+	for _, o := range orders {
+		if o.Type == OrderMove && o.UnitType == Army && !m.Adjacent(o.Location, NoCoast, o.Target, NoCoast, false) {
+			convoyed = append(convoyed, o)
+		}
+	}
+	for _, i := range convoyers {
+		if len(convoyed) == 0 {
+			break
+		}
+		army := convoyed[rng.Intn(len(convoyed))]
+		o := &orders[i]
+		o.Type, o.AuxLoc, o.AuxTarget, o.AuxUnitType = OrderConvoy, army.Location, army.Target, Army
+	}
+	return orders
+}
+
+// convoyTargets returns the coastal provinces an army in prov could reach
+// through at most three sea provinces.
+func convoyTargets(m *DiplomacyMap, prov string) []string {
+	if m.Provinces[prov].Type != Coastal {
+		return nil
+	}
+	seas := map[string]bool{}
+	frontier := []string{prov}
+	for range 3 {
+		var next []string
+		for _, p := range frontier {
+			for _, a := range m.ProvincesAdjacentTo(p, NoCoast, true) {
+				if m.Provinces[a].Type == Sea && !seas[a] {
+					seas[a] = true
+					next = append(next, a)
+				}
+			}
+		}
+		frontier = next
+	}
+	var targets []string
+	for _, sea := range slices.Sorted(maps.Keys(seas)) {
+		for _, a := range m.ProvincesAdjacentTo(sea, NoCoast, true) {
+			if a != prov && m.Provinces[a].Type == Coastal && !slices.Contains(targets, a) {
+				targets = append(targets, a)
+			}
+		}
+	}
+	return targets
+}
+
+// resolutionViolations adjudicates validated orders on a copy of gs and
+// describes every invariant the result breaks.
+func resolutionViolations(gs *GameState, m *DiplomacyMap, orders []Order) []string {
+	var violations []string
+	fail := func(format string, args ...any) {
+		violations = append(violations, fmt.Sprintf(format, args...))
+	}
+	results, dislodged := ResolveOrders(orders, gs, m)
+
+	byLoc := make(map[string]ResolvedOrder, len(results))
+	for _, r := range results {
+		if _, dup := byLoc[r.Order.Location]; dup {
+			fail("%s has more than one result", r.Order.Location)
+		}
+		byLoc[r.Order.Location] = r
+	}
+	for _, u := range gs.Units {
+		if _, ok := byLoc[u.Province]; !ok {
+			fail("unit in %s has no result", u.Province)
+		}
+	}
+
+	// supportCount counts the supports that succeeded for the unit in loc
+	// moving to target, or holding when target is empty.
+	supportCount := func(loc, target string) int {
+		n := 0
+		for _, r := range results {
+			if r.Order.Type == OrderSupport && r.Result == ResultSucceeded && r.Order.AuxLoc == loc && r.Order.AuxTarget == target {
+				n++
+			}
+		}
+		return n
+	}
+	dislodgedAt := map[string]bool{}
+	for _, d := range dislodged {
+		dislodgedAt[d.DislodgedFrom] = true
+		if r := byLoc[d.DislodgedFrom]; r.Result != ResultDislodged {
+			fail("%s is listed as dislodged but its order %s", d.DislodgedFrom, r.Result)
+		}
+		attack, ok := byLoc[d.AttackerFrom]
+		if !ok || attack.Order.Type != OrderMove || attack.Order.Target != d.DislodgedFrom || attack.Result != ResultSucceeded {
+			fail("%s was dislodged from %s without a successful move from there", d.DislodgedFrom, d.AttackerFrom)
+			continue
+		}
+		if attack.Order.Power == d.Unit.Power {
+			fail("%s dislodged its own unit in %s", d.AttackerFrom, d.DislodgedFrom)
+		}
+		attackStr := 1 + supportCount(d.AttackerFrom, d.DislodgedFrom)
+		holdStr := 1
+		if byLoc[d.DislodgedFrom].Order.Type != OrderMove {
+			holdStr += supportCount(d.DislodgedFrom, "")
+		}
+		if attackStr <= holdStr {
+			fail("%s was dislodged by %s with attack strength %d against hold strength %d", d.DislodgedFrom, d.AttackerFrom, attackStr, holdStr)
+		}
+	}
+	for _, r := range results {
+		if r.Result == ResultDislodged && !dislodgedAt[r.Order.Location] {
+			fail("%s was dislodged but isn't listed as dislodged", r.Order.Location)
+		}
+	}
+
+	after := gs.Clone()
+	ApplyResolution(after, m, results, dislodged)
+	if len(after.Units) != len(gs.Units)-len(dislodged) {
+		fail("%d units became %d with %d dislodged", len(gs.Units), len(after.Units), len(dislodged))
+	}
+	occupied := map[string]bool{}
+	for _, u := range after.Units {
+		if occupied[u.Province] {
+			fail("two units in %s", u.Province)
+		}
+		occupied[u.Province] = true
+		prov := m.Provinces[u.Province]
+		switch {
+		case u.Type == Army && prov.Type == Sea:
+			fail("army at sea in %s", u.Province)
+		case u.Type == Fleet && prov.Type == Land:
+			fail("fleet inland in %s", u.Province)
+		case u.Type == Fleet && len(prov.Coasts) > 0 && !slices.Contains(prov.Coasts, u.Coast):
+			fail("fleet in %s on coast %q", u.Province, u.Coast)
+		}
+	}
+	return violations
+}
+
+// movementOrders returns the orders of a case's first stage.
+func (c *datcCase) movementOrders() ([]Order, error) {
+	var orders []Order
+	for _, power := range slices.Sorted(maps.Keys(c.stages[0].orders)) {
+		dson, err := ParseDSON(c.stages[0].orders[power])
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range dson {
+			orders = append(orders, DSONToOrder(d, power))
+		}
+	}
+	return orders, nil
+}
+
+// writeResolverFixture saves a position and its orders as a fixture case
+// that reproduces the violations.
+func writeResolverFixture(file, id string, gs *GameState, orders []Order, violations []string) error {
+	var b strings.Builder
+	for _, v := range violations {
+		fmt.Fprintf(&b, "# %s\n", v)
+	}
+	fmt.Fprintf(&b, "case %s random position\nseason %s\n", id, gs.Season)
+	byPower := map[Power][]DSONOrder{}
+	for _, o := range orders {
+		byPower[o.Power] = append(byPower[o.Power], OrderToDSON(o))
+	}
+	for _, power := range slices.Sorted(maps.Keys(byPower)) {
+		fmt.Fprintf(&b, "%s: %s\n", power, FormatDSON(byPower[power]))
+	}
+	return os.WriteFile(file, []byte(b.String()), 0o644)
+}
@@ -1,5 +1,11 @@
 package diplomacy
 
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
 // RetreatOrderType represents a retreat-phase order.
 type RetreatOrderType int
 
@@ -17,6 +23,7 @@ type RetreatOrder struct {
 	Type        RetreatOrderType
 	Target      string // Destination for retreat move
 	TargetCoast Coast
+	Defaulted   bool `json:",omitempty"` // a disband given for a unit left without orders
 }
 
 // RetreatResult describes the outcome of a retreat order.
@@ -46,17 +53,29 @@ func ValidateRetreatOrder(order RetreatOrder, gs *GameState, m *DiplomacyMap) er
 		}
 	}
 
-	// Cannot retreat to the province the attacker came from
-	if order.Target == dislodged.AttackerFrom {
+	// The unit retreats from the coast it was dislodged on
+	if order.Coast != NoCoast && order.Coast != dislodged.Unit.Coast {
+		return &ValidationError{
+			Order:   Order{Location: order.Location, Power: order.Power},
+			Message: fmt.Sprintf("dislodged unit is not on %s/%s", order.Location, order.Coast),
+		}
+	}
+
+	// Cannot retreat to the province the attacker came from over land
+	if order.Target == dislodged.AttackerFrom && !dislodged.ByConvoy {
 		return &ValidationError{
 			Order:   Order{Location: order.Location, Power: order.Power},
 			Message: "cannot retreat to province attacker came from",
 		}
 	}
 
+	if err := validateRetreatCoast(order, dislodged.Unit.Coast, m); err != nil {
+		return err
+	}
+
 	// Must be adjacent
 	isFleet := order.UnitType == Fleet
-	if !m.Adjacent(order.Location, order.Coast, order.Target, order.TargetCoast, isFleet) {
+	if !m.Adjacent(order.Location, dislodged.Unit.Coast, order.Target, order.TargetCoast, isFleet) {
 		return &ValidationError{
 			Order:   Order{Location: order.Location, Power: order.Power},
 			Message: "target not adjacent for retreat",
@@ -72,12 +91,88 @@ func ValidateRetreatOrder(order RetreatOrder, gs *GameState, m *DiplomacyMap) er
 	}
 
 	// Cannot retreat to a province where a standoff occurred during the movement phase
-	// (This is tracked by the caller — we don't have standoff info in GameState)
-	// For now, this is handled at the service layer
+	if slices.Contains(gs.Standoffs, order.Target) {
+		return &ValidationError{
+			Order:   Order{Location: order.Location, Power: order.Power},
+			Message: "cannot retreat to province left empty by a standoff",
+		}
+	}
 
 	return nil
 }
 
+// CanRetreatTo reports whether target is open to the dislodged unit d: not
+// where its attacker came from over land, not occupied, and not left empty
+// by a standoff. It doesn't check adjacency or coasts.
+func CanRetreatTo(gs *GameState, d DislodgedUnit, target string) bool {
+	return (target != d.AttackerFrom || d.ByConvoy) && gs.UnitAt(target) == nil && !slices.Contains(gs.Standoffs, target)
+}
+
+// validateRetreatCoast checks the target coast of a retreat from a unit on
+// coast: armies never name one, and a fleet retreating to a split-coast
+// province must name a coast it can reach unless only one is.
+func validateRetreatCoast(order RetreatOrder, coast Coast, m *DiplomacyMap) error {
+	invalid := func(msg string) error {
+		return &ValidationError{Order: Order{Location: order.Location, Power: order.Power}, Message: msg}
+	}
+	if order.UnitType != Fleet {
+		if order.TargetCoast != NoCoast {
+			return invalid("army cannot retreat to a coast")
+		}
+		return nil
+	}
+	if !m.HasCoasts(order.Target) {
+		if order.TargetCoast != NoCoast {
+			return invalid(order.Target + " has no coasts")
+		}
+		return nil
+	}
+	coasts := m.FleetCoastsTo(order.Location, coast, order.Target)
+	switch {
+	case len(coasts) == 0:
+		return invalid("fleet cannot reach any coast of " + order.Target)
+	case order.TargetCoast == NoCoast && len(coasts) > 1:
+		names := make([]string, len(coasts))
+		for i, c := range coasts {
+			names[i] = string(c)
+		}
+		return invalid(fmt.Sprintf("must specify coast for %s (%s)", order.Target, strings.Join(names, " or ")))
+	case order.TargetCoast != NoCoast && !slices.Contains(coasts, order.TargetCoast):
+		return invalid(fmt.Sprintf("fleet cannot reach %s/%s from %s", order.Target, order.TargetCoast, order.Location))
+	}
+	return nil
+}
+
+// InferRetreatCoasts fills in the coasts a retreat order leaves implicit:
+// the coast the unit was dislodged on, and the target coast of a fleet
+// that can reach only one coast of a split-coast province.
+func InferRetreatCoasts(order RetreatOrder, gs *GameState, m *DiplomacyMap) RetreatOrder {
+	if order.Type != RetreatMove || order.UnitType != Fleet {
+		return order
+	}
+	for _, d := range gs.Dislodged {
+		if d.DislodgedFrom == order.Location && d.Unit.Power == order.Power && order.Coast == NoCoast {
+			order.Coast = d.Unit.Coast
+		}
+	}
+	if order.TargetCoast == NoCoast && m.HasCoasts(order.Target) {
+		if coasts := m.FleetCoastsTo(order.Location, order.Coast, order.Target); len(coasts) == 1 {
+			order.TargetCoast = coasts[0]
+		}
+	}
+	return order
+}
+
+// RetreatCoasts lists the target coasts a dislodged unit could retreat to in
+// target: each reachable coast for a fleet and a split-coast province,
+// otherwise just NoCoast.
+func RetreatCoasts(d DislodgedUnit, target string, m *DiplomacyMap) []Coast {
+	if d.Unit.Type != Fleet || !m.HasCoasts(target) {
+		return []Coast{NoCoast}
+	}
+	return m.FleetCoastsTo(d.DislodgedFrom, d.Unit.Coast, target)
+}
+
 // ResolveRetreats processes retreat orders. If two units try to retreat to the same
 // province, both are disbanded. Unordered dislodged units are disbanded.
 func ResolveRetreats(orders []RetreatOrder, gs *GameState, m *DiplomacyMap) []RetreatResult {
@@ -94,43 +189,48 @@ func ResolveRetreats(orders []RetreatOrder, gs *GameState, m *DiplomacyMap) []Re
 		if !orderedUnits[d.DislodgedFrom] {
 			results = append(results, RetreatResult{
 				Order: RetreatOrder{
-					UnitType: d.Unit.Type,
-					Power:    d.Unit.Power,
-					Location: d.DislodgedFrom,
-					Coast:    d.Unit.Coast,
-					Type:     RetreatDisband,
+					UnitType:  d.Unit.Type,
+					Power:     d.Unit.Power,
+					Location:  d.DislodgedFrom,
+					Coast:     d.Unit.Coast,
+					Type:      RetreatDisband,
+					Defaulted: true,
 				},
 				Result: ResultSucceeded,
 			})
 		}
 	}
 
-	// Find retreat move conflicts (two units trying to go to the same place)
+	// Validate first: an invalid retreat disbands without bouncing anyone
+	inferred := make([]RetreatOrder, len(orders))
+	void := make([]bool, len(orders))
 	targetCounts := make(map[string]int)
-	for _, o := range orders {
-		if o.Type == RetreatMove {
-			targetCounts[o.Target]++
-		}
-	}
-
-	for _, o := range orders {
+	for i, o := range orders {
 		if o.Type == RetreatDisband {
-			results = append(results, RetreatResult{Order: o, Result: ResultSucceeded})
 			continue
 		}
-
-		// Validate
-		if err := ValidateRetreatOrder(o, gs, m); err != nil {
-			// Invalid retreat -> disband
-			results = append(results, RetreatResult{Order: o, Result: ResultVoid})
+		o = InferRetreatCoasts(o, gs, m)
+		inferred[i] = o
+		if ValidateRetreatOrder(o, gs, m) != nil {
+			void[i] = true
 			continue
 		}
+		// Find retreat move conflicts (two units trying to go to the same place)
+		targetCounts[o.Target]++
+	}
 
-		if targetCounts[o.Target] > 1 {
-			// Two units trying to retreat to the same place: both disband
-			results = append(results, RetreatResult{Order: o, Result: ResultBounced})
-		} else {
+	for i, o := range orders {
+		switch {
+		case o.Type == RetreatDisband:
 			results = append(results, RetreatResult{Order: o, Result: ResultSucceeded})
+		case void[i]:
+			// Invalid retreat -> disband
+			results = append(results, RetreatResult{Order: inferred[i], Result: ResultVoid})
+		case targetCounts[inferred[i].Target] > 1:
+			// Two units trying to retreat to the same place: both disband
+			results = append(results, RetreatResult{Order: inferred[i], Result: ResultBounced})
+		default:
+			results = append(results, RetreatResult{Order: inferred[i], Result: ResultSucceeded})
 		}
 	}
 
@@ -138,8 +238,16 @@ func ResolveRetreats(orders []RetreatOrder, gs *GameState, m *DiplomacyMap) []Re
 }
 
 // ApplyRetreats updates the game state based on resolved retreat orders.
+// Under the retreat credits house rule, each unit its power ordered to
+// disband earns that power a build credit.
 func ApplyRetreats(gs *GameState, results []RetreatResult, m *DiplomacyMap) {
 	for _, r := range results {
+		if gs.RetreatCredits && r.Order.Type == RetreatDisband && !r.Order.Defaulted && r.Result == ResultSucceeded {
+			if gs.BuildCredits == nil {
+				gs.BuildCredits = make(map[Power]int)
+			}
+			gs.BuildCredits[r.Order.Power]++
+		}
 		if r.Order.Type == RetreatMove && r.Result == ResultSucceeded {
 			// Add the unit at its new location
 			coast := r.Order.TargetCoast
@@ -161,4 +269,5 @@ func ApplyRetreats(gs *GameState, results []RetreatResult, m *DiplomacyMap) {
 	}
 
 	gs.Dislodged = nil
+	gs.Standoffs = nil
 }
@@ -0,0 +1,346 @@
+package diplomacy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ScenarioStandard is the name of the standard seven-power game.
+const ScenarioStandard = "standard"
+
+// Scenario is a predefined game type: which powers take part, how many
+// supply centers win and the calendar played, on the standard map or a
+// variant's own. Powers outside Powers are inactive; they start with no units
+// and their home centers are neutral.
+type Scenario struct {
+	Name           string   `json:"name"`
+	Description    string   `json:"description"`
+	Powers         []Power  `json:"powers"`
+	VictoryCenters int      `json:"victory_centers"`
+	Calendar       Calendar `json:"calendar"`
+	Custom         bool     `json:"custom,omitempty"` // registered from an uploaded variant
+
+	units []Unit        // custom starting units; nil uses the standard setup
+	m     *DiplomacyMap // custom map; nil plays on the standard map
+}
+
+var scenarios = []Scenario{
+	{
+		Name:           ScenarioStandard,
+		Description:    "Standard seven-power Diplomacy.",
+		Powers:         []Power{Austria, England, France, Germany, Italy, Russia, Turkey},
+		VictoryCenters: 18,
+		Calendar:       StandardCalendar(),
+	},
+	duelScenario("france-austria", "1v1 France vs Austria on the part of the map around them.", France, Austria),
+	duelScenario("germany-italy", "1v1 Germany vs Italy on the part of the map around them.", Germany, Italy),
+}
+
+// duelReach is how many moves from the duellists' home centers a duel board
+// extends.
+const duelReach = 2
+
+// duelScenario returns a built-in 1v1 scenario played on duelVariant's
+// board. It is built once, when the package loads.
+func duelScenario(name, description string, a, b Power) Scenario {
+	s := duelVariant(name, description, a, b).Scenario()
+	s.Custom = false
+	return s
+}
+
+// duelVariant cuts a board for a duel between a and b from the standard
+// map: the provinces within duelReach moves of either power's home centers,
+// with the other powers' home centers neutral. Winning takes a majority of
+// its supply centers.
+func duelVariant(name, description string, a, b Power) *VariantDefinition {
+	std := StandardVariant()
+	neighbors := make(map[string][]string, len(std.Provinces))
+	for _, adj := range std.Adjacencies {
+		neighbors[adj.From] = append(neighbors[adj.From], adj.To)
+	}
+	reach := make(map[string]int)
+	var queue []string
+	for _, p := range std.Provinces {
+		if p.Home == a || p.Home == b {
+			reach[p.ID] = 0
+			queue = append(queue, p.ID)
+		}
+	}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if reach[id] == duelReach {
+			continue
+		}
+		for _, n := range neighbors[id] {
+			if _, seen := reach[n]; !seen {
+				reach[n] = reach[id] + 1
+				queue = append(queue, n)
+			}
+		}
+	}
+
+	v := &VariantDefinition{Name: name, Description: description, Powers: []Power{a, b}}
+	centers := 0
+	for _, p := range std.Provinces {
+		if _, ok := reach[p.ID]; !ok {
+			continue
+		}
+		if p.Home != a && p.Home != b {
+			p.Home = Neutral
+		}
+		if p.SupplyCenter {
+			centers++
+		}
+		v.Provinces = append(v.Provinces, p)
+	}
+	for _, adj := range std.Adjacencies {
+		_, from := reach[adj.From]
+		_, to := reach[adj.To]
+		if from && to {
+			v.Adjacencies = append(v.Adjacencies, adj)
+		}
+	}
+	for _, u := range std.Units {
+		if u.Power == a || u.Power == b {
+			v.Units = append(v.Units, u)
+		}
+	}
+	v.VictoryCenters = centers/2 + 1
+	return v
+}
+
+var (
+	customMu        sync.RWMutex
+	customScenarios []Scenario
+)
+
+// Scenarios returns all predefined scenarios, standard first, followed by
+// registered custom scenarios.
+func Scenarios() []Scenario {
+	customMu.RLock()
+	defer customMu.RUnlock()
+	out := make([]Scenario, 0, len(scenarios)+len(customScenarios))
+	out = append(out, scenarios...)
+	return append(out, customScenarios...)
+}
+
+// ScenarioLoader finds a scenario that isn't registered in this process,
+// such as a variant stored in the database. It reports false if there is
+// none.
+type ScenarioLoader func(name string) (Scenario, bool)
+
+var (
+	scenarioLoader  atomic.Pointer[ScenarioLoader]
+	loadedScenarios = map[string]Scenario{} // guarded by customMu
+)
+
+// SetScenarioLoader sets the loader LookupScenario falls back to for names
+// it doesn't know. Each loaded scenario is cached, so it is loaded once per
+// process; loaded scenarios aren't listed by Scenarios.
+func SetScenarioLoader(load ScenarioLoader) {
+	scenarioLoader.Store(&load)
+}
+
+// LookupScenario returns the scenario with the given name, asking the
+// scenario loader for names not registered. An empty name is the standard
+// game.
+func LookupScenario(name string) (Scenario, bool) {
+	if name == "" {
+		name = ScenarioStandard
+	}
+	// Bots ask for the scenario of every position they search, so this
+	// stays free of allocations for scenarios already known.
+	for _, s := range scenarios {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	if s, ok := lookupCustomScenario(name); ok {
+		return s, true
+	}
+	load := scenarioLoader.Load()
+	if load == nil || *load == nil {
+		return Scenario{}, false
+	}
+	s, ok := (*load)(name)
+	if !ok {
+		return Scenario{}, false
+	}
+	s.Name, s.Custom = name, true
+	customMu.Lock()
+	loadedScenarios[name] = s
+	customMu.Unlock()
+	return s, true
+}
+
+// lookupCustomScenario returns a registered or already loaded scenario.
+func lookupCustomScenario(name string) (Scenario, bool) {
+	customMu.RLock()
+	defer customMu.RUnlock()
+	for _, s := range customScenarios {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	s, ok := loadedScenarios[name]
+	return s, ok
+}
+
+// VersionedScenarioName returns the scenario name of one version of a
+// custom variant. Variant names can't contain '@', so it never collides with
+// a variant's own name. Games store versioned names: a version never changes,
+// so re-uploading a variant doesn't alter games already playing it.
+func VersionedScenarioName(name string, version int) string {
+	return name + "@" + strconv.Itoa(version)
+}
+
+// SplitScenarioName splits a versioned scenario name into the variant name
+// and version. The version is 0 for a name without one.
+func SplitScenarioName(scenario string) (string, int) {
+	name, v, ok := strings.Cut(scenario, "@")
+	if !ok {
+		return scenario, 0
+	}
+	version, err := strconv.Atoi(v)
+	if err != nil || version < 1 {
+		return scenario, 0
+	}
+	return name, version
+}
+
+// RegisterScenario adds a custom scenario, replacing any custom scenario of
+// the same name. Built-in scenarios can't be replaced.
+func RegisterScenario(s Scenario) error {
+	if isBuiltinScenario(s.Name) {
+		return fmt.Errorf("scenario %q is built in", s.Name)
+	}
+	s.Custom = true
+	customMu.Lock()
+	defer customMu.Unlock()
+	for i := range customScenarios {
+		if customScenarios[i].Name == s.Name {
+			customScenarios[i] = s
+			return nil
+		}
+	}
+	customScenarios = append(customScenarios, s)
+	return nil
+}
+
+func isBuiltinScenario(name string) bool {
+	for _, s := range scenarios {
+		if s.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Map returns the map the scenario is played on.
+func (s Scenario) Map() *DiplomacyMap {
+	if s.m != nil {
+		return s.m
+	}
+	return StandardMap()
+}
+
+// IsActive reports whether power takes part in the scenario.
+func (s Scenario) IsActive(power Power) bool {
+	for _, p := range s.Powers {
+		if p == power {
+			return true
+		}
+	}
+	return false
+}
+
+// InitialState returns the opening position for the scenario, Spring 1901
+// on the standard calendar: the standard setup, or the variant's own units
+// and centers including any neutral garrisons, with inactive powers' units
+// removed and their home centers made neutral.
+func (s Scenario) InitialState() *GameState {
+	gs := NewInitialState()
+	if s.Name == ScenarioStandard {
+		return gs
+	}
+	gs.Scenario = s.Name
+	cal := s.calendar()
+	gs.Year = cal.StartYear
+	gs.Season = cal.FirstSeason()
+	if s.units != nil {
+		gs.Units = append([]Unit(nil), s.units...)
+	}
+	if s.m != nil {
+		gs.SupplyCenters = make(map[string]Power)
+		for id, p := range s.m.Provinces {
+			if p.IsSupplyCenter {
+				gs.SupplyCenters[id] = p.HomePower
+			}
+		}
+	}
+	units := gs.Units[:0]
+	for _, u := range gs.Units {
+		if u.Power == Neutral || s.IsActive(u.Power) {
+			units = append(units, u)
+		}
+	}
+	gs.Units = units
+	for sc, owner := range gs.SupplyCenters {
+		if owner != Neutral && !s.IsActive(owner) {
+			gs.SupplyCenters[sc] = Neutral
+		}
+	}
+	return gs
+}
+
+// scenario returns the scenario the state is playing, falling back to the
+// standard game for unknown or empty names. The standard game is built in and
+// cannot be replaced, so it skips the lookup bots would otherwise pay on every
+// position they evaluate.
+func (gs *GameState) scenario() Scenario {
+	if gs.Scenario == "" || gs.Scenario == ScenarioStandard {
+		return scenarios[0]
+	}
+	if s, ok := LookupScenario(gs.Scenario); ok {
+		return s
+	}
+	s, _ := LookupScenario(ScenarioStandard)
+	return s
+}
+
+// Map returns the map the game is played on.
+func (gs *GameState) Map() *DiplomacyMap {
+	return gs.scenario().Map()
+}
+
+// calendar returns the scenario's calendar, or the standard one if it has
+// none.
+func (s Scenario) calendar() Calendar {
+	if len(s.Calendar.Seasons) == 0 {
+		return standardCalendar
+	}
+	return s.Calendar
+}
+
+// ActivePowers returns the powers taking part in the game.
+func (gs *GameState) ActivePowers() []Power {
+	return gs.scenario().Powers
+}
+
+// VictoryCenters returns the number of supply centers needed for a solo win:
+// VictorySCs when set, otherwise the scenario's.
+func (gs *GameState) VictoryCenters() int {
+	if gs.VictorySCs > 0 {
+		return gs.VictorySCs
+	}
+	return gs.scenario().VictoryCenters
+}
+
+// IsPartialBoard reports whether some powers are inactive in this game.
+func (gs *GameState) IsPartialBoard() bool {
+	return len(gs.ActivePowers()) < len(AllPowers())
+}
@@ -0,0 +1,169 @@
+package diplomacy
+
+import "testing"
+
+func TestScenarioInitialState_FranceAustria(t *testing.T) {
+	sc, ok := LookupScenario("france-austria")
+	if !ok {
+		t.Fatal("france-austria scenario not found")
+	}
+	gs := sc.InitialState()
+
+	if gs.Scenario != "france-austria" {
+		t.Errorf("expected scenario recorded in state, got %q", gs.Scenario)
+	}
+	for _, u := range gs.Units {
+		if u.Power != France && u.Power != Austria {
+			t.Errorf("unexpected %s unit at %s", u.Power, u.Province)
+		}
+	}
+	if got := gs.UnitCount(France) + gs.UnitCount(Austria); got != 6 {
+		t.Errorf("expected 6 units, got %d", got)
+	}
+	if owner, ok := gs.SupplyCenters["lon"]; !ok || owner != Neutral {
+		t.Error("inactive home centers on the board should be neutral")
+	}
+	if _, ok := gs.SupplyCenters["mos"]; ok {
+		t.Error("centers far from the duellists should be off the board")
+	}
+	if gs.SupplyCenters["par"] != France || gs.SupplyCenters["vie"] != Austria {
+		t.Error("active home centers should keep their owners")
+	}
+	m := sc.Map()
+	if m == StandardMap() || len(m.Provinces) >= len(StandardMap().Provinces) {
+		t.Error("duel should be played on a board cut from the standard map")
+	}
+	if _, ok := m.Provinces["stp"]; ok {
+		t.Error("stp should be off the board")
+	}
+	if want := len(gs.SupplyCenters)/2 + 1; sc.VictoryCenters != want {
+		t.Errorf("expected a majority of the board's %d centers, %d, to win; got %d", len(gs.SupplyCenters), want, sc.VictoryCenters)
+	}
+	if !gs.IsPartialBoard() || sc.Custom {
+		t.Error("duel should be a built-in partial board")
+	}
+}
+
+func TestDuelBoardsAreValid(t *testing.T) {
+	for _, name := range []string{"france-austria", "germany-italy"} {
+		sc, _ := LookupScenario(name)
+		v := duelVariant("duel-"+name, sc.Description, sc.Powers[0], sc.Powers[1])
+		if err := v.Validate(); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		gs := sc.InitialState()
+		if !sc.Map().CutFromStandard() {
+			t.Errorf("%s: board should be cut from the standard map", name)
+		}
+		if allocs := testing.AllocsPerRun(100, func() { gs.VictoryCenters() }); allocs != 0 {
+			t.Errorf("%s: looking up the scenario allocates %v times", name, allocs)
+		}
+	}
+}
+
+func TestScenarioStandard(t *testing.T) {
+	sc, ok := LookupScenario("")
+	if !ok || sc.Name != ScenarioStandard {
+		t.Fatalf("empty name should be the standard scenario, got %+v", sc)
+	}
+	gs := sc.InitialState()
+	if gs.Scenario != "" {
+		t.Errorf("standard state should not record a scenario, got %q", gs.Scenario)
+	}
+	if gs.IsPartialBoard() || len(gs.ActivePowers()) != 7 {
+		t.Error("standard game should have all seven powers")
+	}
+	if _, ok := LookupScenario("bogus"); ok {
+		t.Error("unknown scenario should not be found")
+	}
+}
+
+func TestIsGameOver_DuelElimination(t *testing.T) {
+	sc, _ := LookupScenario("france-austria")
+	gs := sc.InitialState()
+
+	if over, _ := IsGameOver(gs); over {
+		t.Fatal("fresh duel should not be over")
+	}
+
+	for sc, owner := range gs.SupplyCenters {
+		if owner == Austria {
+			gs.SupplyCenters[sc] = Neutral
+		}
+	}
+	units := gs.Units[:0]
+	for _, u := range gs.Units {
+		if u.Power != Austria {
+			units = append(units, u)
+		}
+	}
+	gs.Units = units
+
+	over, winner := IsGameOver(gs)
+	if !over || winner != France {
+		t.Errorf("expected France to win once Austria is eliminated, got over=%v winner=%s", over, winner)
+	}
+}
+
+func TestIsGameOver_StandardNeedsCenters(t *testing.T) {
+	gs := NewInitialState()
+	for _, p := range []Power{Austria, England, Germany, Italy, Russia, Turkey} {
+		for sc, owner := range gs.SupplyCenters {
+			if owner == p {
+				gs.SupplyCenters[sc] = Neutral
+			}
+		}
+	}
+	gs.Units = gs.UnitsOf(France)
+
+	if over, _ := IsGameOver(gs); over {
+		t.Error("standard game should not end by elimination below 18 centers")
+	}
+}
+
+func TestIsGameOver_CustomVictorySCs(t *testing.T) {
+	gs := NewInitialState()
+	gs.VictorySCs = 5
+	if over, _ := IsGameOver(gs); over {
+		t.Error("no power starts with 5 centers")
+	}
+	gs.SupplyCenters["bel"] = Russia
+	if over, winner := IsGameOver(gs); !over || winner != Russia {
+		t.Errorf("expected Russia to win with 5 centers, got over=%v winner=%s", over, winner)
+	}
+	if c := gs.Clone(); c.VictoryCenters() != 5 {
+		t.Errorf("clone needs %d centers, want 5", c.VictoryCenters())
+	}
+	var dst GameState
+	gs.CloneInto(&dst)
+	if dst.VictorySCs != 5 {
+		t.Errorf("CloneInto VictorySCs = %d, want 5", dst.VictorySCs)
+	}
+}
+
+func TestScenarioLoader(t *testing.T) {
+	defer SetScenarioLoader(nil)
+	calls := 0
+	SetScenarioLoader(func(name string) (Scenario, bool) {
+		calls++
+		if base, version := SplitScenarioName(name); base != "test-loaded" || version != 2 {
+			return Scenario{}, false
+		}
+		return Scenario{Powers: []Power{France, Germany}, VictoryCenters: 18}, true
+	})
+	for range 2 {
+		s, ok := LookupScenario(VersionedScenarioName("test-loaded", 2))
+		if !ok || s.Name != "test-loaded@2" || !s.Custom {
+			t.Fatalf("expected the loaded scenario, got %+v, %v", s, ok)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected a loaded scenario to be cached, loader called %d times", calls)
+	}
+	if _, ok := LookupScenario("test-loaded@3"); ok {
+		t.Error("expected an unknown version to be missing")
+	}
+	if name, version := SplitScenarioName("test-loaded"); name != "test-loaded" || version != 0 {
+		t.Errorf("unversioned name split into %q, %d", name, version)
+	}
+}
@@ -1,11 +1,20 @@
 package diplomacy
 
+import (
+	"maps"
+	"slices"
+)
+
 // Season represents a game season.
 type Season string
 
+// The standard calendar plays Spring and Fall; variants may use all four
+// (see Calendar).
 const (
 	Spring Season = "spring"
+	Summer Season = "summer"
 	Fall   Season = "fall"
+	Winter Season = "winter"
 )
 
 // PhaseType represents the type of game phase.
@@ -34,6 +43,22 @@ type GameState struct {
 	Units         []Unit
 	SupplyCenters map[string]Power // province ID -> owning power
 	Dislodged     []DislodgedUnit  // Units that need retreat orders (retreat phase only)
+	Standoffs     []string         `json:",omitempty"` // provinces left empty by a standoff (retreat phase only)
+	YearLimit     int              `json:",omitempty"` // last playable year; 0 = MaxYear
+	VictorySCs    int              `json:",omitempty"` // centers for a solo; 0 = the scenario's
+	Scenario      string           `json:",omitempty"` // predefined game type; empty = standard
+
+	// RetreatCredits is the house rule where a dislodged unit its power
+	// chooses to disband retreats off the board, and may be rebuilt at the
+	// next adjustment even if the power has no center surplus for it.
+	// BuildCredits counts each power's units off the board until then.
+	RetreatCredits bool          `json:",omitempty"`
+	BuildCredits   map[Power]int `json:",omitempty"`
+
+	// MercyPowers are the human players' powers in a teaching game, whose
+	// home centers bots hold off attacking through the year MercyUntil.
+	MercyPowers []Power `json:",omitempty"`
+	MercyUntil  int     `json:",omitempty"`
 }
 
 // DislodgedUnit is a unit that was dislodged and needs a retreat order.
@@ -41,6 +66,7 @@ type DislodgedUnit struct {
 	Unit          Unit
 	DislodgedFrom string // Province the unit was dislodged from (same as Unit.Province before dislodgement)
 	AttackerFrom  string // Province the attacker came from (cannot retreat there)
+	ByConvoy      bool   `json:",omitempty"` // the attacker was convoyed, so AttackerFrom stays open
 }
 
 // NewInitialState returns the standard Diplomacy starting position (Spring 1901 Movement).
@@ -86,6 +112,23 @@ func (gs *GameState) UnitCount(power Power) int {
 	return count
 }
 
+// AdjustmentDelta returns how many units power may build (positive) or must
+// disband (negative) in a build phase: its supply centers less its units,
+// raised to its build credits when it has no disbands to make.
+func (gs *GameState) AdjustmentDelta(power Power) int {
+	delta := gs.SupplyCenterCount(power) - gs.UnitCount(power)
+	if credits := gs.BuildCredits[power]; delta >= 0 && credits > delta {
+		return credits
+	}
+	return delta
+}
+
+// MercyProtects reports whether bots are still sparing power's home centers
+// this year under the teaching-game mercy setting.
+func (gs *GameState) MercyProtects(power Power) bool {
+	return gs.Year <= gs.MercyUntil && slices.Contains(gs.MercyPowers, power)
+}
+
 // UnitsOf returns all units belonging to the given power.
 func (gs *GameState) UnitsOf(power Power) []Unit {
 	var units []Unit
@@ -97,6 +140,23 @@ func (gs *GameState) UnitsOf(power Power) []Unit {
 	return units
 }
 
+// Garrisons returns the neutral units on the board. Garrisons always hold,
+// are destroyed when dislodged and are never rebuilt, so taking a garrisoned
+// center needs a supported attack.
+func (gs *GameState) Garrisons() []Unit {
+	return gs.UnitsOf(Neutral)
+}
+
+// AddGarrisons places a neutral army on every unowned supply center that has
+// no unit.
+func (gs *GameState) AddGarrisons() {
+	for _, sc := range slices.Sorted(maps.Keys(gs.SupplyCenters)) {
+		if gs.SupplyCenters[sc] == Neutral && gs.UnitAt(sc) == nil {
+			gs.Units = append(gs.Units, Unit{Type: Army, Power: Neutral, Province: sc})
+		}
+	}
+}
+
 // PowerIsAlive returns true if the power still has at least one supply center or unit.
 func (gs *GameState) PowerIsAlive(power Power) bool {
 	return gs.SupplyCenterCount(power) > 0 || gs.UnitCount(power) > 0
@@ -107,9 +167,17 @@ func (gs *GameState) PowerIsAlive(power Power) bool {
 // that call ApplyResolution on speculative states.
 func (gs *GameState) Clone() *GameState {
 	c := &GameState{
-		Year:   gs.Year,
-		Season: gs.Season,
-		Phase:  gs.Phase,
+		Year:       gs.Year,
+		Season:     gs.Season,
+		Phase:      gs.Phase,
+		YearLimit:  gs.YearLimit,
+		VictorySCs: gs.VictorySCs,
+		Scenario:   gs.Scenario,
+
+		RetreatCredits: gs.RetreatCredits,
+		BuildCredits:   maps.Clone(gs.BuildCredits),
+		MercyPowers:    slices.Clone(gs.MercyPowers),
+		MercyUntil:     gs.MercyUntil,
 	}
 	if gs.Units != nil {
 		c.Units = make([]Unit, len(gs.Units))
@@ -134,6 +202,13 @@ func (gs *GameState) CloneInto(dst *GameState) {
 	dst.Year = gs.Year
 	dst.Season = gs.Season
 	dst.Phase = gs.Phase
+	dst.YearLimit = gs.YearLimit
+	dst.VictorySCs = gs.VictorySCs
+	dst.Scenario = gs.Scenario
+	dst.RetreatCredits = gs.RetreatCredits
+	dst.BuildCredits = maps.Clone(gs.BuildCredits)
+	dst.MercyPowers = slices.Clone(gs.MercyPowers)
+	dst.MercyUntil = gs.MercyUntil
 
 	if gs.Units != nil {
 		if cap(dst.Units) >= len(gs.Units) {
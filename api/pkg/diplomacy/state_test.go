@@ -101,6 +101,25 @@ func TestIsYearLimitReached(t *testing.T) {
 	}
 }
 
+func TestIsYearLimitReachedCustomLimit(t *testing.T) {
+	gs := &GameState{Year: 1920, YearLimit: 1920}
+	if IsYearLimitReached(gs) {
+		t.Error("year 1920 should still be playable with limit 1920")
+	}
+	gs.Year = 1921
+	if !IsYearLimitReached(gs) {
+		t.Error("year 1921 should exceed limit 1920")
+	}
+	if c := gs.Clone(); c.YearLimit != 1920 {
+		t.Errorf("clone YearLimit = %d, want 1920", c.YearLimit)
+	}
+	var dst GameState
+	gs.CloneInto(&dst)
+	if dst.YearLimit != 1920 {
+		t.Errorf("CloneInto YearLimit = %d, want 1920", dst.YearLimit)
+	}
+}
+
 func TestGameState_Clone_Counts(t *testing.T) {
 	gs := NewInitialState()
 	c := gs.Clone()
@@ -11,7 +11,7 @@ const (
 	Italy   Power = "italy"
 	Russia  Power = "russia"
 	Turkey  Power = "turkey"
-	Neutral Power = ""
+	Neutral Power = "" // owner of unowned centers and of garrison units
 )
 
 // AllPowers returns the seven great powers in standard order.
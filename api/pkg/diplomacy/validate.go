@@ -25,6 +25,9 @@ func ValidateOrder(order Order, gs *GameState, m *DiplomacyMap) error {
 	if unit.Type != order.UnitType {
 		return &ValidationError{order, fmt.Sprintf("unit is %s, not %s", unit.Type, order.UnitType)}
 	}
+	if unit.Power == Neutral && order.Type != OrderHold {
+		return &ValidationError{order, "neutral garrisons can only hold"}
+	}
 
 	switch order.Type {
 	case OrderHold:
@@ -41,6 +44,9 @@ func ValidateOrder(order Order, gs *GameState, m *DiplomacyMap) error {
 }
 
 func validateMove(order Order, gs *GameState, m *DiplomacyMap) error {
+	if order.Target == order.Location {
+		return &ValidationError{order, "cannot move to its own province"}
+	}
 	isFleet := order.UnitType == Fleet
 	target := m.Provinces[order.Target]
 	if target == nil {
@@ -111,6 +117,10 @@ func validateSupport(order Order, gs *GameState, m *DiplomacyMap) error {
 		return nil
 	}
 
+	if order.AuxTarget == order.AuxLoc {
+		return &ValidationError{order, "cannot support a move to the unit's own province"}
+	}
+
 	// Support move: supporting unit must be able to move to the target province
 	// (but doesn't need to be adjacent to the supported unit)
 	if !m.Adjacent(order.Location, order.Coast, order.AuxTarget, NoCoast, isFleet) {
@@ -142,6 +152,10 @@ func validateConvoy(order Order, gs *GameState, m *DiplomacyMap) error {
 		return &ValidationError{order, "fleet must be in a sea province to convoy"}
 	}
 
+	if order.AuxTarget == order.AuxLoc {
+		return &ValidationError{order, "cannot convoy a move to the army's own province"}
+	}
+
 	// Convoyed unit must be an army
 	convoyed := gs.UnitAt(order.AuxLoc)
 	if convoyed == nil {
@@ -212,13 +226,15 @@ func canBeConvoyed(src, dst string, gs *GameState, m *DiplomacyMap) bool {
 
 // ValidateAndDefaultOrders takes submitted orders and returns a complete set of orders
 // for all units of all powers. Units without orders get a default Hold.
-// Invalid orders are replaced with Hold and reported as void.
+// Invalid orders are replaced with Hold and reported as void, with the
+// validation message as the reason. Province aliases are canonicalized first.
 func ValidateAndDefaultOrders(orders []Order, gs *GameState, m *DiplomacyMap) ([]Order, []ResolvedOrder) {
 	ordered := make(map[string]bool) // province -> has order
 	var valid []Order
 	var voidResults []ResolvedOrder
 
 	for _, o := range orders {
+		o = CanonicalizeOrder(o)
 		if err := ValidateOrder(o, gs, m); err != nil {
 			// Invalid order -> treat as hold
 			hold := Order{
@@ -229,7 +245,11 @@ func ValidateAndDefaultOrders(orders []Order, gs *GameState, m *DiplomacyMap) ([
 				Type:     OrderHold,
 			}
 			valid = append(valid, hold)
-			voidResults = append(voidResults, ResolvedOrder{Order: o, Result: ResultVoid})
+			reason := err.Error()
+			if ve, ok := err.(*ValidationError); ok {
+				reason = ve.Message
+			}
+			voidResults = append(voidResults, ResolvedOrder{Order: o, Result: ResultVoid, Reason: reason})
 			ordered[o.Location] = true
 			continue
 		}
@@ -0,0 +1,479 @@
+package diplomacy
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// VariantDefinition is an uploaded map and setup: provinces, adjacencies,
+// home centers and starting units, an optional calendar (the standard one if
+// omitted), plus optional geometry for clients to draw it with. Definitions
+// whose powers are all standard powers are playable, on the standard map or
+// their own.
+type VariantDefinition struct {
+	Name           string             `json:"name"`
+	Description    string             `json:"description"`
+	Powers         []Power            `json:"powers"`
+	VictoryCenters int                `json:"victory_centers"`
+	Provinces      []VariantProvince  `json:"provinces"`
+	Adjacencies    []VariantAdjacency `json:"adjacencies"`
+	Units          []VariantUnit      `json:"units"`
+	Calendar       *Calendar          `json:"calendar,omitempty"`
+	Geometry       json.RawMessage    `json:"geometry,omitempty"` // opaque to the server
+}
+
+// VariantProvince is a province of a VariantDefinition. Home may name a power
+// not taking part; its centers then start neutral.
+type VariantProvince struct {
+	ID           string  `json:"id"`
+	Name         string  `json:"name"`
+	Type         string  `json:"type"` // land, sea or coastal
+	SupplyCenter bool    `json:"supply_center"`
+	Home         Power   `json:"home,omitempty"`
+	Coasts       []Coast `json:"coasts,omitempty"` // split coasts only
+}
+
+// VariantAdjacency is a one-way connection; every adjacency must be listed
+// in both directions.
+type VariantAdjacency struct {
+	From      string `json:"from"`
+	FromCoast Coast  `json:"from_coast,omitempty"`
+	To        string `json:"to"`
+	ToCoast   Coast  `json:"to_coast,omitempty"`
+	Army      bool   `json:"army"`
+	Fleet     bool   `json:"fleet"`
+}
+
+// VariantUnit is a starting unit. A unit without a power is a neutral
+// garrison on a center no active power starts with.
+type VariantUnit struct {
+	Power    Power  `json:"power,omitempty"`
+	Type     string `json:"type"` // army or fleet
+	Province string `json:"province"`
+	Coast    Coast  `json:"coast,omitempty"`
+}
+
+// VariantError lists every problem found in a VariantDefinition.
+type VariantError struct {
+	Problems []string
+}
+
+func (e *VariantError) Error() string {
+	return "invalid variant: " + strings.Join(e.Problems, "; ")
+}
+
+var variantNameRe = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,39}$`)
+
+var provinceTypes = map[string]ProvinceType{"land": Land, "sea": Sea, "coastal": Coastal}
+
+// Validate checks that the definition describes a playable board: unique
+// provinces, symmetric adjacencies that respect province types and coasts, a
+// connected map, enough supply centers for the victory condition, and
+// starting units on their own home centers, or for neutral garrisons on
+// centers no active power starts with. It returns a *VariantError.
+func (v *VariantDefinition) Validate() error {
+	var problems []string
+	addf := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if !variantNameRe.MatchString(v.Name) {
+		addf("name must be 1-40 lowercase letters, digits or dashes")
+	} else if isBuiltinScenario(v.Name) {
+		addf("name %q is a built-in scenario", v.Name)
+	}
+
+	active := make(map[Power]bool)
+	for _, p := range v.Powers {
+		if p == Neutral || active[p] {
+			addf("power %q is empty or listed twice", p)
+		}
+		active[p] = true
+	}
+	if len(active) < 2 {
+		addf("at least two powers are needed")
+	}
+
+	if len(v.Provinces) > MaxProvinces {
+		addf("at most %d provinces are allowed", MaxProvinces)
+	}
+	provs := make(map[string]VariantProvince, len(v.Provinces))
+	homes := make(map[Power]int)
+	totalSCs := 0
+	for _, p := range v.Provinces {
+		if p.ID == "" {
+			addf("province with empty id")
+			continue
+		}
+		if _, dup := provs[p.ID]; dup {
+			addf("province %s is defined twice", p.ID)
+			continue
+		}
+		provs[p.ID] = p
+		pt, ok := provinceTypes[p.Type]
+		if !ok {
+			addf("province %s has unknown type %q", p.ID, p.Type)
+		}
+		if p.SupplyCenter {
+			totalSCs++
+			if pt == Sea {
+				addf("sea province %s cannot be a supply center", p.ID)
+			}
+		}
+		if p.Home != Neutral {
+			if !p.SupplyCenter {
+				addf("home province %s must be a supply center", p.ID)
+			}
+			homes[p.Home]++
+		}
+		if len(p.Coasts) > 0 {
+			if pt != Coastal {
+				addf("only coastal provinces can have split coasts (%s)", p.ID)
+			}
+			if len(p.Coasts) < 2 {
+				addf("province %s lists a single coast; split coasts need at least two", p.ID)
+			}
+			seen := make(map[Coast]bool)
+			for _, c := range p.Coasts {
+				if !validCoast(c) || seen[c] {
+					addf("province %s has invalid or repeated coast %q", p.ID, c)
+				}
+				seen[c] = true
+			}
+		}
+	}
+	for p := range active {
+		if homes[p] == 0 {
+			addf("power %s has no home centers", p)
+		}
+	}
+	if v.VictoryCenters <= totalSCs/2 || v.VictoryCenters > totalSCs {
+		addf("victory_centers must be more than half of the %d supply centers and at most all of them", totalSCs)
+	}
+
+	type edge struct {
+		from, to           string
+		fromCoast, toCoast Coast
+	}
+	edges := make(map[edge]VariantAdjacency, len(v.Adjacencies))
+	graph := make(map[string][]string)
+	for _, a := range v.Adjacencies {
+		from, okFrom := provs[a.From]
+		to, okTo := provs[a.To]
+		if !okFrom || !okTo {
+			addf("adjacency %s-%s names an unknown province", a.From, a.To)
+			continue
+		}
+		if a.From == a.To {
+			addf("province %s is adjacent to itself", a.From)
+			continue
+		}
+		if !a.Army && !a.Fleet {
+			addf("adjacency %s-%s allows neither armies nor fleets", a.From, a.To)
+		}
+		if a.Army && (from.Type == "sea" || to.Type == "sea" || a.FromCoast != NoCoast || a.ToCoast != NoCoast) {
+			addf("army adjacency %s-%s must join land or coastal provinces without coasts", a.From, a.To)
+		}
+		if a.Fleet {
+			if from.Type == "land" || to.Type == "land" {
+				addf("fleet adjacency %s-%s touches an inland province", a.From, a.To)
+			}
+			if !coastMatches(from, a.FromCoast) || !coastMatches(to, a.ToCoast) {
+				addf("fleet adjacency %s-%s must name a coast of each split-coast province and only those", a.From, a.To)
+			}
+		}
+		e := edge{a.From, a.To, a.FromCoast, a.ToCoast}
+		if _, dup := edges[e]; dup {
+			addf("adjacency %s-%s is listed twice", a.From, a.To)
+		}
+		edges[e] = a
+		graph[a.From] = append(graph[a.From], a.To)
+	}
+	for e, a := range edges {
+		back, ok := edges[edge{e.to, e.from, e.toCoast, e.fromCoast}]
+		if !ok || back.Army != a.Army || back.Fleet != a.Fleet {
+			addf("adjacency %s-%s has no matching reverse adjacency", a.From, a.To)
+		}
+	}
+	if len(provs) > 0 && len(problems) == 0 {
+		if unreached := unreachable(provs, graph); len(unreached) > 0 {
+			addf("map is not connected; unreachable: %s", strings.Join(unreached, ", "))
+		}
+	}
+
+	occupied := make(map[string]bool)
+	for _, u := range v.Units {
+		p, ok := provs[u.Province]
+		if !ok {
+			addf("unit in unknown province %s", u.Province)
+			continue
+		}
+		switch {
+		case u.Power == Neutral:
+			if !p.SupplyCenter || active[p.Home] {
+				addf("neutral garrison in %s must start on a center no active power starts with", u.Province)
+			}
+		case !active[u.Power]:
+			addf("unit in %s belongs to %q, which is not taking part", u.Province, u.Power)
+		case p.Home != u.Power:
+			addf("unit in %s must start on one of its power's home centers", u.Province)
+		}
+		if occupied[u.Province] {
+			addf("province %s has more than one starting unit", u.Province)
+		}
+		occupied[u.Province] = true
+		switch u.Type {
+		case "army":
+			if p.Type == "sea" || u.Coast != NoCoast {
+				addf("army in %s must stand on land without a coast", u.Province)
+			}
+		case "fleet":
+			if p.Type == "land" || !coastMatches(p, u.Coast) {
+				addf("fleet in %s must stand on water or a coast, naming the coast of split-coast provinces", u.Province)
+			}
+		default:
+			addf("unit in %s has unknown type %q", u.Province, u.Type)
+		}
+	}
+
+	if v.Calendar != nil {
+		problems = append(problems, v.Calendar.problems()...)
+	}
+
+	if len(problems) > 0 {
+		return &VariantError{Problems: problems}
+	}
+	return nil
+}
+
+func validCoast(c Coast) bool {
+	switch c {
+	case NorthCoast, SouthCoast, EastCoast, WestCoast:
+		return true
+	}
+	return false
+}
+
+// coastMatches reports whether c is a valid fleet coast for p: one of its
+// split coasts, or none if it has no split coasts.
+func coastMatches(p VariantProvince, c Coast) bool {
+	if len(p.Coasts) == 0 {
+		return c == NoCoast
+	}
+	for _, pc := range p.Coasts {
+		if pc == c {
+			return true
+		}
+	}
+	return false
+}
+
+// unreachable returns the provinces, sorted, that can't be reached from the
+// first province in ID order.
+func unreachable(provs map[string]VariantProvince, graph map[string][]string) []string {
+	ids := make([]string, 0, len(provs))
+	for id := range provs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	seen := map[string]bool{ids[0]: true}
+	queue := []string{ids[0]}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, next := range graph[cur] {
+			if !seen[next] {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	var out []string
+	for _, id := range ids {
+		if !seen[id] {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// UsesStandardMap reports whether the definition keeps the standard map:
+// the same provinces, types, supply and home centers, coasts and
+// adjacencies. Province names and geometry may differ. Such variants are
+// played on the shared standard map rather than a copy of it.
+func (v *VariantDefinition) UsesStandardMap() bool {
+	std := StandardVariant()
+	if len(v.Provinces) != len(std.Provinces) || len(v.Adjacencies) != len(std.Adjacencies) {
+		return false
+	}
+	for _, p := range v.Powers {
+		if !isStandardPower(p) {
+			return false
+		}
+	}
+	want := make(map[string]VariantProvince, len(std.Provinces))
+	for _, p := range std.Provinces {
+		want[p.ID] = p
+	}
+	for _, p := range v.Provinces {
+		w, ok := want[p.ID]
+		if !ok || p.Type != w.Type || p.SupplyCenter != w.SupplyCenter || p.Home != w.Home || !sameCoasts(p.Coasts, w.Coasts) {
+			return false
+		}
+	}
+	adj := make(map[VariantAdjacency]bool, len(std.Adjacencies))
+	for _, a := range std.Adjacencies {
+		adj[a] = true
+	}
+	for _, a := range v.Adjacencies {
+		if !adj[a] {
+			return false
+		}
+	}
+	return true
+}
+
+func isStandardPower(p Power) bool {
+	for _, sp := range AllPowers() {
+		if p == sp {
+			return true
+		}
+	}
+	return false
+}
+
+func sameCoasts(a, b []Coast) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := make(map[Coast]bool, len(a))
+	for _, c := range a {
+		set[c] = true
+	}
+	for _, c := range b {
+		if !set[c] {
+			return false
+		}
+	}
+	return true
+}
+
+// Playable reports whether games can be played on the variant: the engine
+// knows only the seven standard powers.
+func (v *VariantDefinition) Playable() bool {
+	for _, p := range v.Powers {
+		if !isStandardPower(p) {
+			return false
+		}
+	}
+	for _, p := range v.Provinces {
+		if p.Home != Neutral && !isStandardPower(p.Home) {
+			return false
+		}
+	}
+	return true
+}
+
+// Scenario returns the scenario that plays the variant, on its own map
+// unless it UsesStandardMap. Only meaningful for a valid, Playable
+// definition.
+func (v *VariantDefinition) Scenario() Scenario {
+	units := make([]Unit, 0, len(v.Units))
+	for _, u := range v.Units {
+		t := Army
+		if u.Type == "fleet" {
+			t = Fleet
+		}
+		units = append(units, Unit{Type: t, Power: u.Power, Province: u.Province, Coast: u.Coast})
+	}
+	cal := StandardCalendar()
+	if v.Calendar != nil {
+		cal = *v.Calendar
+	}
+	var m *DiplomacyMap
+	if !v.UsesStandardMap() {
+		m = v.buildMap()
+	}
+	return Scenario{
+		Name:           v.Name,
+		Description:    v.Description,
+		Powers:         append([]Power(nil), v.Powers...),
+		VictoryCenters: v.VictoryCenters,
+		Calendar:       cal,
+		Custom:         true,
+		units:          units,
+		m:              m,
+	}
+}
+
+// buildMap returns the variant's province graph as a frozen map.
+func (v *VariantDefinition) buildMap() *DiplomacyMap {
+	m := &DiplomacyMap{
+		Provinces:   make(map[string]*Province, len(v.Provinces)),
+		Adjacencies: make(map[string][]Adjacency, len(v.Provinces)),
+	}
+	for _, p := range v.Provinces {
+		m.Provinces[p.ID] = &Province{
+			ID:             p.ID,
+			Name:           p.Name,
+			Type:           provinceTypes[p.Type],
+			IsSupplyCenter: p.SupplyCenter,
+			HomePower:      p.Home,
+			Coasts:         append([]Coast(nil), p.Coasts...),
+		}
+	}
+	for _, a := range v.Adjacencies {
+		m.Adjacencies[a.From] = append(m.Adjacencies[a.From], Adjacency{
+			From:      a.From,
+			FromCoast: a.FromCoast,
+			To:        a.To,
+			ToCoast:   a.ToCoast,
+			ArmyOK:    a.Army,
+			FleetOK:   a.Fleet,
+		})
+	}
+	m.finish()
+	return m
+}
+
+// StandardVariant returns the standard game as a VariantDefinition, the
+// starting point for editing a new variant.
+func StandardVariant() *VariantDefinition {
+	m := StandardMap()
+	ids := make([]string, 0, len(m.Provinces))
+	for id := range m.Provinces {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	v := &VariantDefinition{
+		Name:           ScenarioStandard,
+		Description:    "Standard seven-power Diplomacy.",
+		Powers:         AllPowers(),
+		VictoryCenters: 18,
+	}
+	typeNames := map[ProvinceType]string{Land: "land", Sea: "sea", Coastal: "coastal"}
+	for _, id := range ids {
+		p := m.Provinces[id]
+		v.Provinces = append(v.Provinces, VariantProvince{
+			ID:           p.ID,
+			Name:         p.Name,
+			Type:         typeNames[p.Type],
+			SupplyCenter: p.IsSupplyCenter,
+			Home:         p.HomePower,
+			Coasts:       append([]Coast(nil), p.Coasts...),
+		})
+		for _, a := range m.Adjacencies[id] {
+			v.Adjacencies = append(v.Adjacencies, VariantAdjacency{
+				From: a.From, FromCoast: a.FromCoast, To: a.To, ToCoast: a.ToCoast, Army: a.ArmyOK, Fleet: a.FleetOK,
+			})
+		}
+	}
+	for _, u := range initialUnits() {
+		v.Units = append(v.Units, VariantUnit{Power: u.Power, Type: u.Type.String(), Province: u.Province, Coast: u.Coast})
+	}
+	return v
+}
@@ -0,0 +1,224 @@
+package diplomacy
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestStandardVariantValidates(t *testing.T) {
+	v := StandardVariant()
+	v.Name = "classic-copy"
+	if err := v.Validate(); err != nil {
+		t.Fatalf("standard map should validate: %v", err)
+	}
+	if !v.UsesStandardMap() {
+		t.Error("standard variant should use the standard map")
+	}
+	if len(v.Provinces) != ProvinceCount || len(v.Units) != 22 {
+		t.Errorf("expected %d provinces and 22 units, got %d and %d", ProvinceCount, len(v.Provinces), len(v.Units))
+	}
+
+	v.Name = ScenarioStandard
+	if err := v.Validate(); err == nil {
+		t.Error("expected a built-in scenario name to be rejected")
+	}
+}
+
+// tinyVariant is a valid two-power map that isn't the standard map.
+func tinyVariant() *VariantDefinition {
+	return &VariantDefinition{
+		Name:           "tiny",
+		Powers:         []Power{"red", "blue"},
+		VictoryCenters: 3,
+		Provinces: []VariantProvince{
+			{ID: "a", Type: "coastal", SupplyCenter: true, Home: "red"},
+			{ID: "b", Type: "land", SupplyCenter: true},
+			{ID: "c", Type: "coastal", SupplyCenter: true, Home: "blue"},
+			{ID: "s", Type: "sea"},
+		},
+		Adjacencies: []VariantAdjacency{
+			{From: "a", To: "b", Army: true}, {From: "b", To: "a", Army: true},
+			{From: "b", To: "c", Army: true}, {From: "c", To: "b", Army: true},
+			{From: "a", To: "s", Fleet: true}, {From: "s", To: "a", Fleet: true},
+			{From: "c", To: "s", Fleet: true}, {From: "s", To: "c", Fleet: true},
+		},
+		Units: []VariantUnit{
+			{Power: "red", Type: "fleet", Province: "a"},
+			{Power: "blue", Type: "army", Province: "c"},
+		},
+	}
+}
+
+func TestVariantValidateCustomMap(t *testing.T) {
+	v := tinyVariant()
+	if err := v.Validate(); err != nil {
+		t.Fatalf("tiny map should validate: %v", err)
+	}
+	if v.UsesStandardMap() {
+		t.Error("tiny map is not the standard map")
+	}
+}
+
+func TestVariantValidateProblems(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(v *VariantDefinition)
+		want   string
+	}{
+		{"one-way adjacency", func(v *VariantDefinition) { v.Adjacencies = v.Adjacencies[1:] }, "reverse"},
+		{"disconnected", func(v *VariantDefinition) {
+			v.Provinces = append(v.Provinces, VariantProvince{ID: "z", Type: "land"})
+		}, "not connected"},
+		{"fleet inland", func(v *VariantDefinition) {
+			v.Adjacencies = append(v.Adjacencies,
+				VariantAdjacency{From: "b", To: "s", Fleet: true}, VariantAdjacency{From: "s", To: "b", Fleet: true})
+		}, "inland"},
+		{"missing coast", func(v *VariantDefinition) { v.Provinces[0].Coasts = []Coast{NorthCoast, SouthCoast} }, "coast"},
+		{"low victory", func(v *VariantDefinition) { v.VictoryCenters = 1 }, "victory_centers"},
+		{"unit off home", func(v *VariantDefinition) { v.Units[1].Province = "b" }, "home centers"},
+		{"garrison on home", func(v *VariantDefinition) {
+			v.Units[1].Power = Neutral
+		}, "neutral garrison in c"},
+		{"army at sea", func(v *VariantDefinition) {
+			v.Provinces[0].Type = "sea"
+		}, "sea province a cannot be a supply center"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := tinyVariant()
+			tt.mutate(v)
+			err := v.Validate()
+			var verr *VariantError
+			if !errors.As(err, &verr) {
+				t.Fatalf("expected a VariantError, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected a problem mentioning %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestRegisterVariantScenario(t *testing.T) {
+	v := StandardVariant()
+	v.Name = "test-fleet-duel"
+	v.Powers = []Power{England, Turkey}
+	var units []VariantUnit
+	for _, u := range v.Units {
+		if u.Power == England || u.Power == Turkey {
+			u.Type = "fleet"
+			if u.Province == "lvp" || u.Province == "smy" || u.Province == "con" {
+				continue
+			}
+			units = append(units, u)
+		}
+	}
+	v.Units = units
+	if err := v.Validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if err := RegisterScenario(v.Scenario()); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if err := RegisterScenario(Scenario{Name: ScenarioStandard}); err == nil {
+		t.Error("expected built-in scenarios to be protected")
+	}
+
+	sc, ok := LookupScenario("test-fleet-duel")
+	if !ok || !sc.Custom {
+		t.Fatalf("expected the custom scenario to be registered, got %+v", sc)
+	}
+	gs := sc.InitialState()
+	if gs.UnitCount(England) != 2 || gs.UnitCount(Turkey) != 1 || gs.UnitCount(France) != 0 {
+		t.Errorf("unexpected units %+v", gs.Units)
+	}
+	for _, u := range gs.Units {
+		if u.Type != Fleet {
+			t.Errorf("expected only fleets, got %+v", u)
+		}
+	}
+	if gs.SupplyCenters["par"] != Neutral || gs.SupplyCenters["lon"] != England {
+		t.Error("inactive home centers should be neutral and active ones owned")
+	}
+	if len(gs.ActivePowers()) != 2 {
+		t.Errorf("expected 2 active powers, got %v", gs.ActivePowers())
+	}
+}
+
+func TestVariantGarrisons(t *testing.T) {
+	v := StandardVariant()
+	v.Name = "test-garrisons"
+	v.Units = append(v.Units,
+		VariantUnit{Type: "army", Province: "bel"},
+		VariantUnit{Type: "fleet", Province: "nwy"},
+	)
+	if err := v.Validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	gs := v.Scenario().InitialState()
+	if got := len(gs.Garrisons()); got != 2 {
+		t.Errorf("expected 2 garrisons in the initial state, got %d", got)
+	}
+}
+
+func TestCustomMapPlays(t *testing.T) {
+	v := tinyVariant()
+	v.Name = "test-tiny-map"
+	v.Powers = []Power{France, Germany}
+	v.Provinces[0].Home = France
+	v.Provinces[2].Home = Germany
+	v.Units[0].Power = France
+	v.Units[1].Power = Germany
+	if err := v.Validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if !v.Playable() {
+		t.Fatal("a map with standard powers should be playable")
+	}
+	if err := RegisterScenario(v.Scenario()); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	sc, _ := LookupScenario(v.Name)
+	gs := sc.InitialState()
+	m := gs.Map()
+	if m == StandardMap() || len(m.Provinces) != 4 {
+		t.Fatalf("expected the variant's own map, got %d provinces", len(m.Provinces))
+	}
+	if m.CutFromStandard() {
+		t.Error("a map of new provinces is not cut from the standard map")
+	}
+	if len(gs.SupplyCenters) != 3 || gs.SupplyCenters["a"] != France || gs.SupplyCenters["b"] != Neutral {
+		t.Errorf("unexpected centers %v", gs.SupplyCenters)
+	}
+
+	orders := []Order{
+		{UnitType: Fleet, Power: France, Location: "a", Target: "s", Type: OrderMove},
+		{UnitType: Army, Power: Germany, Location: "c", Target: "b", Type: OrderMove},
+	}
+	for _, o := range orders {
+		if err := ValidateOrder(o, gs, m); err != nil {
+			t.Fatalf("validate %v: %v", o, err)
+		}
+	}
+	results, _ := ResolveOrders(orders, gs, m)
+	for _, r := range results {
+		if r.Result != ResultSucceeded {
+			t.Errorf("expected %v to succeed, got %v", r.Order, r.Result)
+		}
+	}
+	ApplyResolution(gs, m, results, nil)
+	UpdateSupplyCenterOwnership(gs)
+	if gs.SupplyCenters["b"] != Germany {
+		t.Errorf("expected Germany to take b, got %v", gs.SupplyCenters)
+	}
+	if got := OpenHomeCenters(gs, France); len(got) != 1 || got[0] != "a" {
+		t.Errorf("expected France to be able to build in a, got %v", got)
+	}
+}
+
+func TestVariantWithUnknownPowersIsNotPlayable(t *testing.T) {
+	if tinyVariant().Playable() {
+		t.Error("powers the engine doesn't know should not be playable")
+	}
+}
@@ -5,17 +5,25 @@ import 'package:web_socket_channel/web_socket_channel.dart';
 
 import 'api_config.dart';
 
-/// WebSocket event from the server.
+/// WebSocket event from the server. Lobby events have [topic] 'lobby'; game
+/// events have no topic.
 class WSEvent {
   final String type;
+  final String topic;
   final String gameId;
   final Map<String, dynamic> data;
 
-  WSEvent({required this.type, required this.gameId, required this.data});
+  WSEvent({
+    required this.type,
+    this.topic = '',
+    required this.gameId,
+    required this.data,
+  });
 
   factory WSEvent.fromJson(Map<String, dynamic> json) {
     return WSEvent(
       type: json['type'] as String,
+      topic: json['topic'] as String? ?? '',
       gameId: json['game_id'] as String,
       data: json['data'] as Map<String, dynamic>? ?? {},
     );
@@ -30,8 +38,10 @@ class WSClient {
   WebSocketChannel? _channel;
   StreamSubscription? _subscription;
   final _eventController = StreamController<WSEvent>.broadcast();
+  final _lobbyController = StreamController<WSEvent>.broadcast();
   final _statusController = StreamController<ConnectionStatus>.broadcast();
   final Set<String> _subscriptions = {};
+  bool _lobby = false;
   final List<Map<String, dynamic>> _pendingMessages = [];
 
   String? _token;
@@ -49,6 +59,9 @@ class WSClient {
   Future<String?> Function()? onTokenExpired;
 
   Stream<WSEvent> get events => _eventController.stream;
+
+  /// Game lifecycle events for the game browser, while following the lobby.
+  Stream<WSEvent> get lobbyEvents => _lobbyController.stream;
   Stream<ConnectionStatus> get statusStream => _statusController.stream;
   bool get isConnected => _connected;
   ConnectionStatus get connectionStatus => _status;
@@ -113,7 +126,12 @@ class WSClient {
           _reconnectAttempts = 0;
           try {
             final json = jsonDecode(message as String) as Map<String, dynamic>;
-            _eventController.add(WSEvent.fromJson(json));
+            final event = WSEvent.fromJson(json);
+            if (event.topic == 'lobby') {
+              _lobbyController.add(event);
+            } else {
+              _eventController.add(event);
+            }
           } catch (_) {}
         },
         onDone: _onDisconnected,
@@ -124,6 +142,9 @@ class WSClient {
       for (final gameId in _subscriptions) {
         _send({'action': 'subscribe', 'game_id': gameId});
       }
+      if (_lobby) {
+        _send({'action': 'subscribe', 'topic': 'lobby'});
+      }
     } catch (_) {
       _onDisconnected();
     }
@@ -189,6 +210,17 @@ class WSClient {
     _send({'action': 'unsubscribe', 'game_id': gameId});
   }
 
+  /// Follows game creation, seats filling, starts and finishes in the lobby.
+  void subscribeLobby() {
+    _lobby = true;
+    _send({'action': 'subscribe', 'topic': 'lobby'});
+  }
+
+  void unsubscribeLobby() {
+    _lobby = false;
+    _send({'action': 'unsubscribe', 'topic': 'lobby'});
+  }
+
   void _send(Map<String, dynamic> message) {
     if (_connected && _channel != null) {
       _channel!.sink.add(jsonEncode(message));
@@ -207,6 +239,7 @@ class WSClient {
     _reconnecting = false;
     _hasEverConnected = false;
     _subscriptions.clear();
+    _lobby = false;
     _pendingMessages.clear();
     _setStatus(ConnectionStatus.disconnected);
   }
@@ -215,6 +248,7 @@ class WSClient {
     _disposed = true;
     disconnect();
     _eventController.close();
+    _lobbyController.close();
     _statusController.close();
   }
 }
@@ -5,6 +5,7 @@ class Message {
   final String? recipientId;
   final String content;
   final String? phaseId;
+  final MessageIntent? intent;
   final DateTime createdAt;
 
   const Message({
@@ -14,6 +15,7 @@ class Message {
     this.recipientId,
     required this.content,
     this.phaseId,
+    this.intent,
     required this.createdAt,
   });
 
@@ -25,6 +27,9 @@ class Message {
       recipientId: json['recipient_id'] as String?,
       content: json['content'] as String,
       phaseId: json['phase_id'] as String?,
+      intent: json['intent'] != null
+          ? MessageIntent.fromJson(json['intent'] as Map<String, dynamic>)
+          : null,
       createdAt: DateTime.parse(json['created_at'] as String),
     );
   }
@@ -35,4 +40,44 @@ class Message {
       };
 
   bool get isPublic => recipientId == null || recipientId!.isEmpty;
+
+  /// Whether the message proposes a set of orders that can be imported.
+  bool get isOrderProposal =>
+      intent?.type == 'propose_orders' && (intent?.orders ?? '').isNotEmpty;
+}
+
+/// Structured intent attached to canned press.
+class MessageIntent {
+  final String type;
+  final List<String> provinces;
+  final String? targetPower;
+
+  /// Proposed order set in DSON, orders separated by ' ; '.
+  final String? orders;
+
+  const MessageIntent({
+    required this.type,
+    this.provinces = const [],
+    this.targetPower,
+    this.orders,
+  });
+
+  factory MessageIntent.fromJson(Map<String, dynamic> json) {
+    return MessageIntent(
+      type: json['type'] as String,
+      provinces: (json['provinces'] as List<dynamic>?)
+              ?.map((e) => e as String)
+              .toList() ??
+          const [],
+      targetPower: json['target_power'] as String?,
+      orders: json['orders'] as String?,
+    );
+  }
+
+  /// The proposed orders, one DSON order each.
+  List<String> get orderList => (orders ?? '')
+      .split(';')
+      .map((o) => o.trim())
+      .where((o) => o.isNotEmpty)
+      .toList();
 }
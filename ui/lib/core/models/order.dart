@@ -44,6 +44,17 @@ class Order {
       createdAt: DateTime.parse(json['created_at'] as String),
     );
   }
+
+  /// The order as input, e.g. to edit it in the order draft.
+  OrderInput toInput() => OrderInput(
+        unitType: unitType,
+        location: location,
+        orderType: orderType,
+        target: target,
+        auxLoc: auxLoc,
+        auxTarget: auxTarget,
+        auxUnitType: auxUnitType,
+      );
 }
 
 /// Order input for submission to backend.
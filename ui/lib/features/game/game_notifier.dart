@@ -3,6 +3,7 @@ import 'dart:convert';
 import 'dart:developer' as dev;
 
 import 'package:flutter_riverpod/flutter_riverpod.dart';
+import 'package:http/http.dart' as http;
 
 import '../../core/api/api_client.dart';
 import '../../core/api/ws_client.dart';
@@ -19,6 +20,7 @@ class GameViewState {
   final GameState? gameState;
   final GameState? previousGameState;
   final List<Order> phaseOrders;
+  final List<Order> importedOrders;
   final List<Order> resolvedOrders;
   final int readyCount;
   final int drawVoteCount;
@@ -31,6 +33,7 @@ class GameViewState {
     this.gameState,
     this.previousGameState,
     this.phaseOrders = const [],
+    this.importedOrders = const [],
     this.resolvedOrders = const [],
     this.readyCount = 0,
     this.drawVoteCount = 0,
@@ -45,6 +48,7 @@ class GameViewState {
     GameState? previousGameState,
     bool clearPreviousGameState = false,
     List<Order>? phaseOrders,
+    List<Order>? importedOrders,
     List<Order>? resolvedOrders,
     int? readyCount,
     int? drawVoteCount,
@@ -59,6 +63,7 @@ class GameViewState {
           ? null
           : (previousGameState ?? this.previousGameState),
       phaseOrders: phaseOrders ?? this.phaseOrders,
+      importedOrders: importedOrders ?? this.importedOrders,
       resolvedOrders: resolvedOrders ?? this.resolvedOrders,
       readyCount: readyCount ?? this.readyCount,
       drawVoteCount: drawVoteCount ?? this.drawVoteCount,
@@ -246,6 +251,9 @@ class GameNotifier extends StateNotifier<GameViewState> {
         if (count is int) {
           state = state.copyWith(drawVoteCount: count);
         }
+      case 'draw_votes_reset':
+        // Votes expire when a phase resolves; players must vote again.
+        state = state.copyWith(drawVoteCount: 0);
       case 'game_ended':
         load();
     }
@@ -273,33 +281,65 @@ class GameNotifier extends StateNotifier<GameViewState> {
     }
   }
 
-  Future<(List<Order>?, String?)> submitOrders(List<OrderInput> orders) async {
+  /// Submits orders, also marking the player ready when [ready] is set so
+  /// both land in the same phase with a single request.
+  Future<(List<Order>?, String?)> submitOrders(List<OrderInput> orders,
+      {bool ready = false}) async {
     try {
       final resp = await _api.post(
         '/games/$gameId/orders',
-        body: {'orders': orders.map((o) => o.toJson()).toList()},
+        body: {
+          'orders': orders.map((o) => o.toJson()).toList(),
+          if (ready) 'ready': true,
+        },
       );
       if (resp.statusCode == 200 || resp.statusCode == 201) {
-        final list = (jsonDecode(resp.body) as List<dynamic>)
+        final decoded = jsonDecode(resp.body);
+        final raw = ready
+            ? (decoded as Map<String, dynamic>)['orders'] as List<dynamic>
+            : decoded as List<dynamic>;
+        final list = raw
             .map((e) => Order.fromJson(e as Map<String, dynamic>))
             .toList();
         state = state.copyWith(phaseOrders: list);
         return (list, null);
       }
-      // Extract error message from response body.
-      String? errorMsg;
-      try {
-        final body = jsonDecode(resp.body) as Map<String, dynamic>;
-        errorMsg = body['error'] as String?;
-      } catch (_) {
-        errorMsg = resp.body;
+      return (null, _errorMessage(resp) ?? 'Order submission failed (${resp.statusCode})');
+    } catch (e) {
+      return (null, 'Connection error — server may be restarting. Try again.');
+    }
+  }
+
+  /// Imports the orders proposed in a press message into the player's
+  /// submitted orders, replacing those for the same units. The game screen
+  /// picks up [GameViewState.importedOrders] into its order draft.
+  Future<(List<Order>?, String?)> importProposedOrders(String messageId) async {
+    try {
+      final resp =
+          await _api.post('/games/$gameId/messages/$messageId/import');
+      if (resp.statusCode == 200) {
+        final list = (jsonDecode(resp.body) as List<dynamic>)
+            .map((e) => Order.fromJson(e as Map<String, dynamic>))
+            .toList();
+        state = state.copyWith(phaseOrders: list, importedOrders: list);
+        return (list, null);
       }
-      return (null, errorMsg ?? 'Order submission failed (${resp.statusCode})');
+      return (null, _errorMessage(resp) ?? 'Import failed (${resp.statusCode})');
     } catch (e) {
       return (null, 'Connection error — server may be restarting. Try again.');
     }
   }
 
+  /// Extracts the error message from a failed response's body.
+  String? _errorMessage(http.Response resp) {
+    try {
+      final body = jsonDecode(resp.body) as Map<String, dynamic>;
+      return body['error'] as String?;
+    } catch (_) {
+      return resp.body;
+    }
+  }
+
   /// Clears the animation snapshot after the animation completes.
   /// If a retreat phase resolved during the movement animation, chains
   /// into the retreat animation instead of returning to the live state.
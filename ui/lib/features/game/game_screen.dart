@@ -191,16 +191,12 @@ class _GameScreenState extends ConsumerState<GameScreen> {
     if (orders.isEmpty) return;
 
     final notifier = ref.read(gameProvider(widget.gameId).notifier);
-    final (result, errorMsg) = await notifier.submitOrders(orders);
+    // Submitting marks ready in the same request.
+    final (result, errorMsg) = await notifier.submitOrders(orders, ready: true);
     if (!mounted) return;
     if (result != null) {
       _orderNotifier.markSubmitted();
-      // Auto-mark ready after successful submit.
-      final ok = await notifier.markReady();
-      if (!mounted) return;
-      if (ok) {
-        _orderNotifier.markReady();
-      }
+      _orderNotifier.markReady();
     } else {
       ScaffoldMessenger.of(context).showSnackBar(
         SnackBar(content: Text(errorMsg ?? 'Failed to submit orders')),
@@ -370,6 +366,13 @@ class _GameScreenState extends ConsumerState<GameScreen> {
   Widget build(BuildContext context) {
     final state = ref.watch(gameProvider(widget.gameId));
     final auth = ref.watch(authProvider);
+    // Orders imported from a press proposal join the draft.
+    ref.listen(gameProvider(widget.gameId).select((s) => s.importedOrders),
+        (_, orders) {
+      if (orders.isNotEmpty) {
+        _orderNotifier.importOrders(orders.map((o) => o.toInput()).toList());
+      }
+    });
 
     if (state.loading) {
       return Scaffold(
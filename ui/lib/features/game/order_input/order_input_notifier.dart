@@ -341,6 +341,18 @@ class OrderInputNotifier extends StateNotifier<OrderInputState> {
     state = state.copyWith(pendingOrders: orders);
   }
 
+  /// Merges orders imported from a press proposal into the draft, replacing
+  /// pending orders for the same units. Unless the player is ready, the
+  /// draft is reopened so the orders can be reviewed and submitted.
+  void importOrders(List<OrderInput> imported) {
+    final locations = {for (final o in imported) o.location};
+    final orders = state.pendingOrders
+        .where((o) => !locations.contains(o.location))
+        .toList()
+      ..addAll(imported);
+    state = state.copyWith(pendingOrders: orders, submitted: state.ready);
+  }
+
   /// Remove a pending order at the given index.
   void removeOrder(int index) {
     if (index < 0 || index >= state.pendingOrders.length) return;
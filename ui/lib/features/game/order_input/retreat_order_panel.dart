@@ -54,12 +54,14 @@ class RetreatOrderPanel extends StatelessWidget {
                 dislodged: d,
                 gameState: gameState,
                 hasOrder: pendingOrders.any((o) => o.location == d.unit.province),
-                onRetreat: (target) {
+                onRetreat: (target, targetCoast) {
                   onAddOrder?.call(OrderInput(
                     unitType: d.unit.type.fullName,
                     location: d.unit.province,
+                    coast: d.unit.coast.isNotEmpty ? d.unit.coast : null,
                     orderType: 'retreat_move',
                     target: target,
+                    targetCoast: targetCoast,
                   ));
                 },
                 onDisband: () {
@@ -75,7 +77,8 @@ class RetreatOrderPanel extends StatelessWidget {
             ...pendingOrders.asMap().entries.map((e) {
               final order = e.value;
               final from = provinces[order.location]?.name ?? order.location;
-              final to = order.target != null ? provinces[order.target]?.name ?? order.target! : '';
+              var to = order.target != null ? provinces[order.target]?.name ?? order.target! : '';
+              if (order.targetCoast != null) to += ' (${order.targetCoast})';
               return ListTile(
                 dense: true,
                 title: Text('$from ${order.orderType.toUpperCase()} ${to.isNotEmpty ? "-> $to" : ""}'),
@@ -96,7 +99,7 @@ class _DislodgedUnitTile extends StatelessWidget {
   final DislodgedUnit dislodged;
   final GameState gameState;
   final bool hasOrder;
-  final void Function(String target) onRetreat;
+  final void Function(String target, String? targetCoast) onRetreat;
   final VoidCallback onDisband;
 
   const _DislodgedUnitTile({
@@ -146,12 +149,20 @@ class _DislodgedUnitTile extends StatelessWidget {
               Wrap(
                 spacing: 8,
                 children: [
-                  ...validTargets.map((t) {
+                  ...validTargets.expand((t) {
                     final tName = provinces[t]?.name ?? t;
-                    return ActionChip(
-                      label: Text(tName),
-                      onPressed: () => onRetreat(t),
-                    );
+                    // A fleet retreating to a split-coast province picks
+                    // one of the coasts it can reach.
+                    final coasts = isFleet
+                        ? reachableCoasts(unit.province, unit.coast, t)
+                        : const <String>[];
+                    if (coasts.isEmpty) {
+                      return [ActionChip(label: Text(tName), onPressed: () => onRetreat(t, null))];
+                    }
+                    return coasts.map((c) => ActionChip(
+                          label: Text('$tName ($c)'),
+                          onPressed: () => onRetreat(t, c),
+                        ));
                   }),
                   ActionChip(
                     avatar: const Icon(Icons.close, size: 16),
@@ -1,20 +1,33 @@
+import 'dart:async';
 import 'dart:convert';
 
 import 'package:flutter_riverpod/flutter_riverpod.dart';
 
 import '../../core/api/api_client.dart';
+import '../../core/api/ws_client.dart';
+import '../../core/auth/auth_notifier.dart';
 import '../../core/models/game.dart';
 
-/// Fetches open games (waiting to be joined).
+/// Fetches open games (waiting to be joined), reloading them as lobby
+/// events arrive.
 class OpenGamesNotifier extends StateNotifier<AsyncValue<List<Game>>> {
   final ApiClient _api;
+  final WSClient _ws;
+  StreamSubscription<WSEvent>? _wsSub;
 
-  OpenGamesNotifier(this._api) : super(const AsyncValue.loading()) {
+  OpenGamesNotifier(this._api, this._ws) : super(const AsyncValue.loading()) {
+    _ws.subscribeLobby();
+    _wsSub = _ws.lobbyEvents.listen((_) => _load());
     refresh();
   }
 
   Future<void> refresh() async {
     state = const AsyncValue.loading();
+    await _load();
+  }
+
+  /// Fetches the list, keeping the current one on screen meanwhile.
+  Future<void> _load() async {
     try {
       final resp = await _api.get('/games?filter=waiting');
       if (resp.statusCode == 200) {
@@ -29,6 +42,13 @@ class OpenGamesNotifier extends StateNotifier<AsyncValue<List<Game>>> {
       state = AsyncValue.error(e, st);
     }
   }
+
+  @override
+  void dispose() {
+    _wsSub?.cancel();
+    _ws.unsubscribeLobby();
+    super.dispose();
+  }
 }
 
 /// Fetches games the current user is in.
@@ -89,7 +109,10 @@ class MyGamesNotifier extends StateNotifier<AsyncValue<List<Game>>> {
 
 final openGamesProvider =
     StateNotifierProvider<OpenGamesNotifier, AsyncValue<List<Game>>>((ref) {
-  return OpenGamesNotifier(ref.watch(apiClientProvider));
+  return OpenGamesNotifier(
+    ref.watch(apiClientProvider),
+    ref.watch(wsClientProvider),
+  );
 });
 
 final myGamesProvider =
@@ -3,17 +3,20 @@ import 'package:flutter/material.dart';
 import '../../core/models/message.dart';
 import '../../core/theme/app_theme.dart';
 
-/// Single message bubble with sender power color.
+/// Single message bubble with sender power color. Proposed order sets are
+/// listed below the text, with an import button when [onImport] is set.
 class MessageBubble extends StatelessWidget {
   final Message message;
   final String? senderPower;
   final bool isMe;
+  final VoidCallback? onImport;
 
   const MessageBubble({
     super.key,
     required this.message,
     this.senderPower,
     this.isMe = false,
+    this.onImport,
   });
 
   @override
@@ -55,6 +58,11 @@ class MessageBubble extends StatelessWidget {
                 ),
               ),
             Text(message.content),
+            if (message.isOrderProposal)
+              _ProposedOrders(
+                orders: message.intent!.orderList,
+                onImport: onImport,
+              ),
             const SizedBox(height: 4),
             Text(
               _formatTime(message.createdAt),
@@ -73,3 +81,51 @@ class MessageBubble extends StatelessWidget {
     return '${local.hour.toString().padLeft(2, '0')}:${local.minute.toString().padLeft(2, '0')}';
   }
 }
+
+/// The orders of a proposal, one per line, and the button to import them.
+class _ProposedOrders extends StatelessWidget {
+  final List<String> orders;
+  final VoidCallback? onImport;
+
+  const _ProposedOrders({required this.orders, this.onImport});
+
+  @override
+  Widget build(BuildContext context) {
+    final theme = Theme.of(context);
+    return Container(
+      margin: const EdgeInsets.only(top: 8),
+      padding: const EdgeInsets.all(8),
+      decoration: BoxDecoration(
+        color: theme.colorScheme.surface,
+        borderRadius: BorderRadius.circular(8),
+        border: Border.all(color: theme.colorScheme.outlineVariant),
+      ),
+      child: Column(
+        crossAxisAlignment: CrossAxisAlignment.start,
+        children: [
+          Text(
+            'Proposed orders',
+            style: theme.textTheme.labelSmall?.copyWith(
+              color: theme.colorScheme.onSurfaceVariant,
+            ),
+          ),
+          const SizedBox(height: 4),
+          for (final order in orders)
+            Text(
+              order,
+              style: const TextStyle(fontFamily: 'monospace', fontSize: 13),
+            ),
+          if (onImport != null)
+            Align(
+              alignment: Alignment.centerRight,
+              child: TextButton.icon(
+                onPressed: onImport,
+                icon: const Icon(Icons.download, size: 18),
+                label: const Text('Import orders'),
+              ),
+            ),
+        ],
+      ),
+    );
+  }
+}
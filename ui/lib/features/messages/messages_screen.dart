@@ -5,6 +5,7 @@ import '../../core/auth/auth_notifier.dart';
 import '../../core/map/adjacency_data.dart';
 import '../../core/map/province_data.dart';
 import '../../core/models/game_state.dart';
+import '../../core/models/message.dart';
 import '../../core/theme/app_theme.dart';
 import '../game/game_notifier.dart';
 import 'message_bubble.dart';
@@ -75,6 +76,21 @@ class _MessagesScreenState extends ConsumerState<MessagesScreen>
     }
   }
 
+  /// Imports a proposal's orders for the player's units into their draft.
+  Future<void> _import(Message message) async {
+    final (orders, errorMsg) = await ref
+        .read(gameProvider(widget.gameId).notifier)
+        .importProposedOrders(message.id);
+    if (!mounted) return;
+    ScaffoldMessenger.of(context).showSnackBar(
+      SnackBar(
+        content: Text(orders != null
+            ? 'Proposed orders imported into your draft'
+            : errorMsg ?? 'Failed to import orders'),
+      ),
+    );
+  }
+
   @override
   Widget build(BuildContext context) {
     final msgState = ref.watch(messagesProvider(widget.gameId));
@@ -82,6 +98,8 @@ class _MessagesScreenState extends ConsumerState<MessagesScreen>
     final userId = auth.user?.id ?? '';
     final gameState = ref.watch(gameProvider(widget.gameId));
     final myPower = gameState.powerForUser(userId);
+    final canImport = myPower != null &&
+        gameState.currentPhase?.phaseType == 'movement';
 
     // Build power-to-userId map.
     final powerToUserId = <String, String>{};
@@ -136,6 +154,7 @@ class _MessagesScreenState extends ConsumerState<MessagesScreen>
                             .toList(),
                         userId: userId,
                         userIdToPower: userIdToPower,
+                        onImport: canImport ? _import : null,
                       ),
                       // Per-power DMs
                       ...allPowers.where((p) => p != myPower).take(6).map((p) {
@@ -150,6 +169,7 @@ class _MessagesScreenState extends ConsumerState<MessagesScreen>
                           }).toList(),
                           userId: userId,
                           userIdToPower: userIdToPower,
+                          onImport: canImport ? _import : null,
                         );
                       }),
                     ],
@@ -173,11 +193,13 @@ class _MessageList extends StatelessWidget {
   final List<dynamic> messages;
   final String userId;
   final Map<String, String> userIdToPower;
+  final ValueChanged<Message>? onImport;
 
   const _MessageList({
     required this.messages,
     required this.userId,
     required this.userIdToPower,
+    this.onImport,
   });
 
   @override
@@ -195,6 +217,9 @@ class _MessageList extends StatelessWidget {
           message: msg,
           senderPower: userIdToPower[msg.senderId],
           isMe: msg.senderId == userId,
+          onImport: onImport != null && msg.senderId != userId
+              ? () => onImport!(msg)
+              : null,
         );
       },
     );
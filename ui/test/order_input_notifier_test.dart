@@ -1,6 +1,7 @@
 import 'package:flutter_test/flutter_test.dart';
 
 import 'package:polite_betrayal/core/models/game_state.dart';
+import 'package:polite_betrayal/core/models/order.dart';
 import 'package:polite_betrayal/features/game/order_input/order_input_notifier.dart';
 import 'package:polite_betrayal/features/game/order_input/order_state.dart';
 
@@ -208,4 +209,27 @@ void main() {
           reason: 'ADR fleet cannot reach SER so support-hold is not valid');
     });
   });
+
+  group('Imported proposals', () {
+    /// Imported orders replace pending orders for the same units and reopen
+    /// a submitted draft.
+    test('merge into the draft', () {
+      final notifier = OrderInputNotifier();
+      notifier.addOrder(const OrderInput(
+          unitType: 'army', location: 'par', orderType: 'hold'));
+      notifier.addOrder(const OrderInput(
+          unitType: 'fleet', location: 'bre', orderType: 'hold'));
+      notifier.markSubmitted();
+
+      notifier.importOrders(const [
+        OrderInput(
+            unitType: 'army', location: 'par', orderType: 'move', target: 'bur'),
+      ]);
+
+      final orders = notifier.state.pendingOrders;
+      expect(orders.map((o) => o.location), ['bre', 'par']);
+      expect(orders.last.target, 'bur');
+      expect(notifier.state.submitted, isFalse);
+    });
+  });
 }